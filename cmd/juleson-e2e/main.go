@@ -0,0 +1,110 @@
+// Command juleson-e2e runs Juleson's end-to-end scenarios against fake Jules
+// and GitHub APIs and the MCP server served over HTTP, and exits non-zero
+// when any fails.
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"regexp"
+	"time"
+
+	"github.com/SamyRai/juleson/internal/e2e"
+	"github.com/SamyRai/juleson/internal/logger"
+)
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdout, os.Stderr))
+}
+
+func run(args []string, stdout, stderr io.Writer) int {
+	flags := flag.NewFlagSet("juleson-e2e", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	var (
+		pattern = flags.String("run", "", "Run only the scenarios whose names match this regular expression")
+		dir     = flags.String("dir", "", "Keep the audit logs, event files, and work trees of each scenario in this directory (default: a temporary directory, removed afterwards)")
+		list    = flags.Bool("list", false, "List the scenarios and exit")
+		asJSON  = flags.Bool("json", false, "Print the results as JSON")
+		verbose = flags.Bool("v", false, "Write Juleson's logs to stderr")
+	)
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+
+	if *list {
+		for _, scenario := range e2e.Scenarios {
+			fmt.Fprintf(stdout, "%-20s %s\n", scenario.Name, scenario.Description)
+		}
+		return 0
+	}
+
+	var match func(string) bool
+	if *pattern != "" {
+		re, err := regexp.Compile(*pattern)
+		if err != nil {
+			fmt.Fprintf(stderr, "invalid -run: %v\n", err)
+			return 2
+		}
+		match = re.MatchString
+	}
+
+	logs := io.Discard
+	if *verbose {
+		logs = stderr
+	}
+	logger.SetupGlobalWithOutput(*verbose, logs)
+
+	if *dir == "" {
+		tmp, err := os.MkdirTemp("", "juleson-e2e-")
+		if err != nil {
+			fmt.Fprintf(stderr, "failed to create a temporary directory: %v\n", err)
+			return 1
+		}
+		defer os.RemoveAll(tmp)
+		*dir = tmp
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	results := e2e.Run(ctx, *dir, match)
+	if len(results) == 0 {
+		fmt.Fprintf(stderr, "no scenario matches %q\n", *pattern)
+		return 2
+	}
+
+	failed := 0
+	for _, result := range results {
+		if !result.Passed {
+			failed++
+		}
+	}
+	if *asJSON {
+		encoder := json.NewEncoder(stdout)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(results); err != nil {
+			fmt.Fprintf(stderr, "failed to write results: %v\n", err)
+			return 1
+		}
+	} else {
+		for _, result := range results {
+			status := "ok  "
+			if !result.Passed {
+				status = "FAIL"
+			}
+			fmt.Fprintf(stdout, "%s %-20s %s\n", status, result.Name, result.Duration.Round(time.Millisecond))
+			if result.Error != "" {
+				fmt.Fprintf(stdout, "     %s\n", result.Error)
+			}
+		}
+		fmt.Fprintf(stdout, "%d passed, %d failed\n", len(results)-failed, failed)
+	}
+	if failed > 0 {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,23 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestRun(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	if code := run([]string{"-list"}, &stdout, &stderr); code != 0 || !strings.Contains(stdout.String(), "session-lifecycle") {
+		t.Fatalf("-list = %d, %q", code, stdout.String())
+	}
+
+	stdout.Reset()
+	if code := run([]string{"-run", "^session-lifecycle$"}, &stdout, &stderr); code != 0 || !strings.Contains(stdout.String(), "1 passed, 0 failed") {
+		t.Fatalf("-run = %d, %q, %q", code, stdout.String(), stderr.String())
+	}
+
+	if code := run([]string{"-run", "missing"}, &stdout, &stderr); code != 2 {
+		t.Errorf("-run with no match = %d, want 2", code)
+	}
+}
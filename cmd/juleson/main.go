@@ -2,10 +2,11 @@ package main
 
 import (
 	"fmt"
-	"log"
+	"log/slog"
 	"os"
 
 	"github.com/SamyRai/juleson/internal/config"
+	"github.com/SamyRai/juleson/internal/jules/sessions"
 	"github.com/SamyRai/juleson/internal/logger"
 	"github.com/SamyRai/juleson/internal/presentation/cli"
 	"github.com/SamyRai/juleson/internal/presentation/cli/core"
@@ -15,6 +16,8 @@ var (
 	version   = "dev"
 	buildTime = "unknown"
 	gitCommit = "unknown"
+	// updatePublicKey is the base64 ed25519 key that signs release checksums.
+	updatePublicKey = ""
 )
 
 func main() {
@@ -25,18 +28,34 @@ func main() {
 	// without JULES_API_KEY.
 	cfg, err := loadConfig(os.Args[1:])
 	if err != nil {
-		log.Fatalf("Failed to load configuration: %v", err)
+		slog.Error("failed to load configuration", "error", err)
+		os.Exit(1)
+	}
+
+	stopOffline := func() {}
+	if core.OfflineRequested(os.Args[1:]) {
+		stopOffline = core.StartOffline(cfg)
 	}
 
 	// Create CLI application
 	app := cli.NewApp(cfg)
 
 	// Setup global logger
-	logger.SetupGlobal(cfg.Jules.DebugLog)
+	if err := core.SetupLogging(cfg, os.Stdout); err != nil {
+		logger.SetupGlobal(cfg.Jules.DebugLog)
+		slog.Warn("invalid log configuration; using defaults", "error", err)
+	}
+	core.ApplyRuntimeSettings(cfg)
 
 	// Execute CLI
-	if err := app.Execute(); err != nil {
+	err = app.Execute()
+	stopOffline()
+	_ = logger.Close()
+	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		if hint := sessions.Remediation(err); hint != "" {
+			fmt.Fprintf(os.Stderr, "💡 %s\n", hint)
+		}
 		os.Exit(1)
 	}
 }
@@ -45,6 +64,8 @@ func applyBuildMetadata() {
 	core.Version = version
 	core.BuildDate = buildTime
 	core.GitCommit = gitCommit
+	core.UpdatePublicKey = updatePublicKey
+	core.FillBuildInfo()
 }
 
 func loadConfig(args []string) (*config.Config, error) {
@@ -55,5 +76,8 @@ func loadConfig(args []string) (*config.Config, error) {
 }
 
 func isConfigValidateCommand(args []string) bool {
+	if len(args) >= 1 && args[0] == "doctor" {
+		return true
+	}
 	return len(args) >= 2 && args[0] == "config" && args[1] == "validate"
 }
@@ -55,6 +55,11 @@ func TestIsConfigValidateCommand(t *testing.T) {
 			args: []string{"config", "validate", "--help"},
 			want: true,
 		},
+		{
+			name: "doctor",
+			args: []string{"doctor", "--offline"},
+			want: true,
+		},
 		{
 			name: "config parent",
 			args: []string{"config"},
@@ -0,0 +1,322 @@
+// Package alerts evaluates alert rules against events and metrics, such as
+// repeated session failures, a growing queue backlog, or a circuit breaker
+// left open, and emits alert.fired events when they trip.
+package alerts
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/SamyRai/juleson/internal/events"
+)
+
+// Metrics evaluated by metric rules.
+const (
+	// MetricQueueBacklog is the number of messages waiting in the message
+	// queue.
+	MetricQueueBacklog = "queue_backlog"
+	// MetricDeadLetters is the number of messages in the dead letter queue.
+	MetricDeadLetters = "dead_letters"
+	// MetricBreakersOpen is the number of open circuit breakers.
+	MetricBreakersOpen = "breakers_open"
+)
+
+// metricNames lists the supported metrics.
+var metricNames = []string{MetricQueueBacklog, MetricDeadLetters, MetricBreakersOpen}
+
+// Defaults for unset settings.
+const (
+	DefaultCooldown = 10 * time.Minute
+	DefaultInterval = 30 * time.Second
+)
+
+// Rule fires when a condition holds. Event rules count events whose type
+// matches Events and fire when more than Threshold arrive within Window.
+// Metric rules fire when Metric stays above Threshold for For.
+type Rule struct {
+	Name string
+	// Events are event type patterns, such as "session.failed".
+	Events []string
+	Window time.Duration
+	Metric string
+	For    time.Duration
+	// Threshold is the value to exceed.
+	Threshold float64
+	Severity  events.Severity
+}
+
+// Condition describes when the rule fires.
+func (r Rule) Condition() string {
+	if r.Metric != "" {
+		condition := fmt.Sprintf("%s > %g", r.Metric, r.Threshold)
+		if r.For > 0 {
+			condition += " for " + r.For.String()
+		}
+		return condition
+	}
+	return fmt.Sprintf("more than %g %v in %s", r.Threshold, r.Events, r.Window)
+}
+
+// Matches reports whether an event rule counts event.
+func (r Rule) Matches(event events.Event) bool {
+	return r.Metric == "" && slices.ContainsFunc(r.Events, func(pattern string) bool {
+		ok, _ := path.Match(pattern, string(event.Type))
+		return ok
+	})
+}
+
+// Config holds the alert rules.
+type Config struct {
+	Rules []Rule
+	// Cooldown is the least time between two alerts of a rule.
+	Cooldown time.Duration
+	// Interval is how often metric rules are evaluated.
+	Interval time.Duration
+}
+
+// ValidateConfig checks that each rule is complete and uniquely named.
+func ValidateConfig(cfg Config) error {
+	var errs []error
+	names := map[string]bool{}
+	for i, rule := range cfg.Rules {
+		label := fmt.Sprintf("rules[%d]", i)
+		if rule.Name == "" {
+			errs = append(errs, fmt.Errorf("%s: name is required", label))
+		} else if names[rule.Name] {
+			errs = append(errs, fmt.Errorf("%s: duplicate name %q", label, rule.Name))
+		}
+		names[rule.Name] = true
+		switch {
+		case rule.Metric != "" && len(rule.Events) > 0:
+			errs = append(errs, fmt.Errorf("%s: set either events or metric, not both", label))
+		case rule.Metric != "":
+			if !slices.Contains(metricNames, rule.Metric) {
+				errs = append(errs, fmt.Errorf("%s: unknown metric %q (use queue_backlog, dead_letters, or breakers_open)", label, rule.Metric))
+			}
+		case len(rule.Events) > 0:
+			if rule.Window <= 0 {
+				errs = append(errs, fmt.Errorf("%s: event rules require a window", label))
+			}
+			for _, pattern := range rule.Events {
+				if _, err := path.Match(pattern, ""); err != nil || pattern == "" {
+					errs = append(errs, fmt.Errorf("%s: invalid event pattern %q", label, pattern))
+				}
+			}
+		default:
+			errs = append(errs, fmt.Errorf("%s: set events or metric", label))
+		}
+		if rule.Threshold < 0 || rule.Window < 0 || rule.For < 0 {
+			errs = append(errs, fmt.Errorf("%s: threshold, window, and for must not be negative", label))
+		}
+	}
+	if cfg.Cooldown < 0 || cfg.Interval < 0 {
+		errs = append(errs, errors.New("cooldown and interval must not be negative"))
+	}
+	return errors.Join(errs...)
+}
+
+// Emitter delivers an alert event.
+type Emitter func(ctx context.Context, event events.Event)
+
+// Engine evaluates rules and emits an alert.fired event each time one
+// trips, at most once per Cooldown per rule.
+type Engine struct {
+	config Config
+	emit   Emitter
+	now    func() time.Time
+
+	mu sync.Mutex
+	// seen holds the times of the events each event rule counted within
+	// its window.
+	seen map[string][]time.Time
+	// breached holds when each metric rule's metric went above threshold.
+	breached map[string]time.Time
+	fired    map[string]time.Time
+}
+
+// NewEngine creates an engine emitting alerts with emit.
+func NewEngine(cfg Config, emit Emitter) *Engine {
+	if cfg.Cooldown == 0 {
+		cfg.Cooldown = DefaultCooldown
+	}
+	if cfg.Interval == 0 {
+		cfg.Interval = DefaultInterval
+	}
+	return &Engine{
+		config:   cfg,
+		emit:     emit,
+		now:      time.Now,
+		seen:     map[string][]time.Time{},
+		breached: map[string]time.Time{},
+		fired:    map[string]time.Time{},
+	}
+}
+
+// Enabled reports whether any rule is configured.
+func (e *Engine) Enabled() bool {
+	return e != nil && len(e.config.Rules) > 0
+}
+
+// Rules returns the configured rules.
+func (e *Engine) Rules() []Rule {
+	return e.config.Rules
+}
+
+// Observe counts event for the event rules matching it and returns the
+// alerts it fired. Events are counted at their timestamp, so a journal can
+// be replayed.
+func (e *Engine) Observe(ctx context.Context, event events.Event) []events.Event {
+	if !e.Enabled() || event.Type == events.EventAlertFired {
+		return nil
+	}
+	at := event.Timestamp
+	if at.IsZero() {
+		at = e.now()
+	}
+
+	var alerts []events.Event
+	e.mu.Lock()
+	for _, rule := range e.config.Rules {
+		if !rule.Matches(event) {
+			continue
+		}
+		seen := append(e.seen[rule.Name], at)
+		cutoff := at.Add(-rule.Window)
+		seen = slices.DeleteFunc(seen, func(t time.Time) bool { return t.Before(cutoff) })
+		e.seen[rule.Name] = seen
+		count := float64(len(seen))
+		if count > rule.Threshold && e.cool(rule, at) {
+			message := fmt.Sprintf("%d events matching %v in %s, more than %g", len(seen), rule.Events, rule.Window, rule.Threshold)
+			alerts = append(alerts, NewAlert(rule, message, count))
+		}
+	}
+	e.mu.Unlock()
+	e.deliver(ctx, alerts)
+	return alerts
+}
+
+// Evaluate checks the metric rules against metrics and returns the alerts
+// it fired. Rules whose metric is missing are skipped.
+func (e *Engine) Evaluate(ctx context.Context, metrics map[string]float64) []events.Event {
+	if !e.Enabled() {
+		return nil
+	}
+	now := e.now()
+
+	var alerts []events.Event
+	e.mu.Lock()
+	for _, rule := range e.config.Rules {
+		value, ok := metrics[rule.Metric]
+		if rule.Metric == "" || !ok {
+			continue
+		}
+		if value <= rule.Threshold {
+			delete(e.breached, rule.Name)
+			continue
+		}
+		since, ok := e.breached[rule.Name]
+		if !ok {
+			since = now
+			e.breached[rule.Name] = now
+		}
+		if now.Sub(since) >= rule.For && e.cool(rule, now) {
+			message := fmt.Sprintf("%s is %g, above %g", rule.Metric, value, rule.Threshold)
+			if rule.For > 0 {
+				message += fmt.Sprintf(" for %s", now.Sub(since).Round(time.Second))
+			}
+			alerts = append(alerts, NewAlert(rule, message, value))
+		}
+	}
+	e.mu.Unlock()
+	e.deliver(ctx, alerts)
+	return alerts
+}
+
+// Watch evaluates the metric rules against metrics every Interval until
+// ctx is done.
+func (e *Engine) Watch(ctx context.Context, metrics func() map[string]float64) {
+	if !e.Enabled() || !slices.ContainsFunc(e.config.Rules, func(rule Rule) bool { return rule.Metric != "" }) {
+		return
+	}
+	ticker := time.NewTicker(e.config.Interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			e.Evaluate(ctx, metrics())
+		}
+	}
+}
+
+// Subscriber returns an event bus subscriber feeding the event rules.
+func (e *Engine) Subscriber() events.Subscriber {
+	return events.Subscriber{
+		ID: "alerts",
+		Filter: func(event events.Event) bool {
+			return slices.ContainsFunc(e.config.Rules, func(rule Rule) bool { return rule.Matches(event) })
+		},
+		Handler: func(ctx context.Context, event events.Event) error {
+			e.Observe(ctx, event)
+			return nil
+		},
+	}
+}
+
+// cool reports whether rule may fire at now, and records it firing. The
+// caller holds e.mu.
+func (e *Engine) cool(rule Rule, now time.Time) bool {
+	if last, ok := e.fired[rule.Name]; ok && now.Sub(last) < e.config.Cooldown {
+		return false
+	}
+	e.fired[rule.Name] = now
+	return true
+}
+
+func (e *Engine) deliver(ctx context.Context, alerts []events.Event) {
+	if e.emit == nil {
+		return
+	}
+	for _, alert := range alerts {
+		e.emit(ctx, alert)
+	}
+}
+
+// NewAlert creates the alert.fired event of a rule.
+func NewAlert(rule Rule, message string, value float64) events.Event {
+	return events.NewEvent(events.EventAlertFired, "alerts", events.AlertEventData{
+		Rule:      rule.Name,
+		Message:   message,
+		Value:     value,
+		Threshold: rule.Threshold,
+	}).WithTopic(events.TopicAlert).WithMetadata("severity", rule.Severity.String())
+}
+
+// CoordinatorMetrics returns the metrics of an event coordinator, which may
+// be nil, and of additional circuit breakers.
+func CoordinatorMetrics(coordinator *events.EventCoordinator, breakers ...*events.CircuitBreaker) map[string]float64 {
+	metrics := map[string]float64{}
+	var open float64
+	if coordinator != nil {
+		if queue, ok := coordinator.GetQueueMetrics(); ok {
+			metrics[MetricQueueBacklog] = float64(queue.MessagesEnqueued - queue.MessagesDequeued)
+			metrics[MetricDeadLetters] = float64(queue.DLQSize)
+		}
+		for _, breaker := range coordinator.GetCircuitBreakers() {
+			breakers = append(breakers, breaker)
+		}
+	}
+	for _, breaker := range breakers {
+		if breaker.GetState() == events.StateOpen {
+			open++
+		}
+	}
+	metrics[MetricBreakersOpen] = open
+	return metrics
+}
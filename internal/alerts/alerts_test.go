@@ -0,0 +1,130 @@
+package alerts
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/SamyRai/juleson/internal/events"
+)
+
+func failedAt(at time.Time) events.Event {
+	event := events.NewEvent(events.EventSessionFailed, "session", events.SessionEventData{SessionID: "1"}).WithTopic(events.TopicSession)
+	event.Timestamp = at
+	return event
+}
+
+func TestEventRuleFiresAboveThresholdWithinWindow(t *testing.T) {
+	var emitted []events.Event
+	engine := NewEngine(Config{
+		Rules:    []Rule{{Name: "session-failures", Events: []string{"session.failed"}, Window: 10 * time.Minute, Threshold: 3, Severity: events.SeverityError}},
+		Cooldown: 30 * time.Minute,
+	}, func(ctx context.Context, event events.Event) { emitted = append(emitted, event) })
+
+	ctx := context.Background()
+	start := time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+	// Four failures, but the first falls out of the window.
+	for _, offset := range []time.Duration{0, 11 * time.Minute, 12 * time.Minute, 13 * time.Minute} {
+		engine.Observe(ctx, failedAt(start.Add(offset)))
+	}
+	engine.Observe(ctx, events.NewEvent(events.EventSessionCompleted, "session", nil))
+	if len(emitted) != 0 {
+		t.Fatalf("alerts before threshold = %+v", emitted)
+	}
+
+	alerts := engine.Observe(ctx, failedAt(start.Add(14*time.Minute)))
+	if len(alerts) != 1 || len(emitted) != 1 {
+		t.Fatalf("alerts = %+v, emitted = %d", alerts, len(emitted))
+	}
+	data, err := events.DecodeData[events.AlertEventData](emitted[0])
+	if err != nil || data.Rule != "session-failures" || data.Value != 4 || !strings.Contains(data.Message, "4 events matching [session.failed] in 10m0s") {
+		t.Fatalf("alert data = %+v, %v", data, err)
+	}
+	if emitted[0].Topic != events.TopicAlert || events.EventSeverity(emitted[0]) != events.SeverityError {
+		t.Errorf("alert topic = %q, severity = %s", emitted[0].Topic, events.EventSeverity(emitted[0]))
+	}
+
+	// The cooldown holds back the next alert.
+	engine.Observe(ctx, failedAt(start.Add(15*time.Minute)))
+	if len(emitted) != 1 {
+		t.Fatalf("alerts during cooldown = %d", len(emitted))
+	}
+	for _, offset := range []time.Duration{45, 46, 47, 48} {
+		engine.Observe(ctx, failedAt(start.Add(offset*time.Minute)))
+	}
+	if len(emitted) != 2 {
+		t.Fatalf("alerts after cooldown = %d, want 2", len(emitted))
+	}
+}
+
+func TestMetricRuleFiresWhenSustained(t *testing.T) {
+	now := time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+	var emitted []events.Event
+	engine := NewEngine(Config{Rules: []Rule{
+		{Name: "breaker-open", Metric: MetricBreakersOpen, For: 5 * time.Minute},
+		{Name: "backlog", Metric: MetricQueueBacklog, Threshold: 100},
+	}}, func(ctx context.Context, event events.Event) { emitted = append(emitted, event) })
+	engine.now = func() time.Time { return now }
+
+	ctx := context.Background()
+	engine.Evaluate(ctx, map[string]float64{MetricBreakersOpen: 1, MetricQueueBacklog: 100})
+	now = now.Add(3 * time.Minute)
+	engine.Evaluate(ctx, map[string]float64{MetricBreakersOpen: 0})
+	now = now.Add(time.Minute)
+	engine.Evaluate(ctx, map[string]float64{MetricBreakersOpen: 1})
+	now = now.Add(4 * time.Minute)
+	if alerts := engine.Evaluate(ctx, map[string]float64{MetricBreakersOpen: 1}); len(alerts) != 0 {
+		t.Fatalf("alerts before the breaker stayed open 5m = %+v", alerts)
+	}
+	now = now.Add(time.Minute)
+	alerts := engine.Evaluate(ctx, map[string]float64{MetricBreakersOpen: 1, MetricQueueBacklog: 250})
+	if len(alerts) != 2 || len(emitted) != 2 {
+		t.Fatalf("alerts = %+v", alerts)
+	}
+	data, _ := events.DecodeData[events.AlertEventData](alerts[0])
+	if data.Rule != "breaker-open" || data.Message != "breakers_open is 1, above 0 for 5m0s" {
+		t.Errorf("breaker alert = %+v", data)
+	}
+	if events.EventSeverity(alerts[1]) != events.SeverityInfo {
+		t.Errorf("backlog alert severity = %s", events.EventSeverity(alerts[1]))
+	}
+}
+
+func TestCoordinatorMetrics(t *testing.T) {
+	breaker := events.NewCircuitBreaker(&events.CircuitBreakerConfig{Name: "jules-api", MaxFailures: 1, Timeout: time.Second, ResetTimeout: time.Hour}, nil)
+	_ = breaker.Execute(context.Background(), func(context.Context) error { return context.DeadlineExceeded })
+
+	metrics := CoordinatorMetrics(nil, breaker)
+	if metrics[MetricBreakersOpen] != 1 {
+		t.Fatalf("metrics = %v", metrics)
+	}
+	if _, ok := metrics[MetricQueueBacklog]; ok {
+		t.Errorf("queue metrics reported without a coordinator: %v", metrics)
+	}
+}
+
+func TestValidateConfig(t *testing.T) {
+	err := ValidateConfig(Config{Rules: []Rule{
+		{Name: "failures", Events: []string{"session.failed"}},
+		{Name: "failures", Metric: "cpu"},
+		{Events: []string{"["}, Metric: MetricQueueBacklog, Threshold: -1},
+		{Name: "empty"},
+	}})
+	for _, want := range []string{
+		"rules[0]: event rules require a window",
+		`rules[1]: duplicate name "failures"`,
+		`rules[1]: unknown metric "cpu"`,
+		"rules[2]: name is required",
+		"rules[2]: set either events or metric, not both",
+		"rules[2]: threshold, window, and for must not be negative",
+		"rules[3]: set events or metric",
+	} {
+		if err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("ValidateConfig() error = %v, want %q", err, want)
+		}
+	}
+	if err := ValidateConfig(Config{Rules: []Rule{{Name: "backlog", Metric: MetricQueueBacklog, Threshold: 100}}}); err != nil {
+		t.Errorf("ValidateConfig(valid) error = %v", err)
+	}
+}
@@ -0,0 +1,207 @@
+package ci
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/SamyRai/juleson/internal/vcs"
+)
+
+// BuildkiteDefaultBaseURL is the Buildkite REST API.
+const BuildkiteDefaultBaseURL = "https://api.buildkite.com/v2"
+
+// Buildkite performs operations through the Buildkite REST API. Projects
+// are pipeline slugs in one organization, and pipelines are builds
+// identified by number.
+type Buildkite struct {
+	api          *apiClient
+	organization string
+}
+
+// NewBuildkite creates a provider for an organization, authenticating with
+// an API access token.
+func NewBuildkite(baseURL, organization, token string) *Buildkite {
+	if baseURL == "" {
+		baseURL = BuildkiteDefaultBaseURL
+	}
+	api := newAPIClient("Buildkite", baseURL, func(req *http.Request) {
+		req.Header.Set("Authorization", "Bearer "+token)
+	})
+	return &Buildkite{api: api, organization: organization}
+}
+
+// Kind returns KindBuildkite.
+func (b *Buildkite) Kind() Kind { return KindBuildkite }
+
+type buildkiteBuild struct {
+	Number    int64     `json:"number"`
+	State     string    `json:"state"`
+	Branch    string    `json:"branch"`
+	Commit    string    `json:"commit"`
+	Message   string    `json:"message"`
+	WebURL    string    `json:"web_url"`
+	CreatedAt time.Time `json:"created_at"`
+	Jobs      []struct {
+		ID   string `json:"id"`
+		Type string `json:"type"`
+		Name string `json:"name"`
+	} `json:"jobs"`
+}
+
+func (b buildkiteBuild) pipeline() Pipeline {
+	return Pipeline{
+		ID:        b.Number,
+		Name:      firstLine(b.Message),
+		Ref:       b.Branch,
+		SHA:       b.Commit,
+		Status:    b.State,
+		URL:       b.WebURL,
+		CreatedAt: b.CreatedAt,
+	}
+}
+
+// ListPipelines lists builds, newest first.
+func (b *Buildkite) ListPipelines(ctx context.Context, project, ref string, limit int) ([]Pipeline, error) {
+	path, err := b.pipelinePath(project)
+	if err != nil {
+		return nil, err
+	}
+	query := url.Values{"per_page": {strconv.Itoa(min(max(limit, 1), 100))}}
+	if ref != "" {
+		query.Set("branch", ref)
+	}
+	var builds []buildkiteBuild
+	if err := b.api.do(ctx, http.MethodGet, path+"/builds?"+query.Encode(), nil, &builds); err != nil {
+		return nil, fmt.Errorf("failed to list builds: %w", err)
+	}
+	result := make([]Pipeline, 0, len(builds))
+	for _, build := range builds {
+		result = append(result, build.pipeline())
+	}
+	return result, nil
+}
+
+// GetPipeline returns a build.
+func (b *Buildkite) GetPipeline(ctx context.Context, project string, id int64) (*Pipeline, error) {
+	build, err := b.build(ctx, project, id)
+	if err != nil {
+		return nil, err
+	}
+	pipeline := build.pipeline()
+	return &pipeline, nil
+}
+
+// TriggerPipeline creates a build of a branch's head, with the variables as
+// environment variables.
+func (b *Buildkite) TriggerPipeline(ctx context.Context, project string, trigger Trigger) (*Pipeline, error) {
+	path, err := b.pipelinePath(project)
+	if err != nil {
+		return nil, err
+	}
+	if trigger.Workflow != "" {
+		return nil, fmt.Errorf("Buildkite builds the pipeline's steps; a workflow is %w", vcs.ErrNotSupported)
+	}
+	body := map[string]any{"commit": "HEAD", "branch": trigger.Ref, "message": "Triggered by Juleson"}
+	if len(trigger.Variables) > 0 {
+		body["env"] = trigger.Variables
+	}
+	var build buildkiteBuild
+	if err := b.api.do(ctx, http.MethodPost, path+"/builds", body, &build); err != nil {
+		return nil, fmt.Errorf("failed to create build: %w", err)
+	}
+	pipeline := build.pipeline()
+	return &pipeline, nil
+}
+
+// PipelineLogs writes the logs of a build's command jobs.
+func (b *Buildkite) PipelineLogs(ctx context.Context, project string, id int64, w io.Writer) error {
+	build, err := b.build(ctx, project, id)
+	if err != nil {
+		return err
+	}
+	path, _ := b.pipelinePath(project)
+	for _, job := range build.Jobs {
+		if job.Type != "script" {
+			continue
+		}
+		if err := writeLogHeader(w, job.Name); err != nil {
+			return err
+		}
+		var log struct {
+			Content string `json:"content"`
+		}
+		if err := b.api.do(ctx, http.MethodGet, fmt.Sprintf("%s/builds/%d/jobs/%s/log", path, id, url.PathEscape(job.ID)), nil, &log); err != nil {
+			return fmt.Errorf("failed to get log of %s: %w", job.Name, err)
+		}
+		if _, err := io.WriteString(w, log.Content); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ListArtifacts lists a build's artifacts.
+func (b *Buildkite) ListArtifacts(ctx context.Context, project string, id int64) ([]Artifact, error) {
+	path, err := b.pipelinePath(project)
+	if err != nil {
+		return nil, err
+	}
+	var items []struct {
+		ID          string `json:"id"`
+		Path        string `json:"path"`
+		FileSize    int64  `json:"file_size"`
+		DownloadURL string `json:"download_url"`
+	}
+	if err := b.api.do(ctx, http.MethodGet, fmt.Sprintf("%s/builds/%d/artifacts?per_page=100", path, id), nil, &items); err != nil {
+		return nil, fmt.Errorf("failed to list artifacts: %w", err)
+	}
+	artifacts := make([]Artifact, 0, len(items))
+	for _, item := range items {
+		artifacts = append(artifacts, Artifact{ID: item.ID, Name: item.Path, Size: item.FileSize, URL: item.DownloadURL})
+	}
+	return artifacts, nil
+}
+
+// DownloadArtifact writes an artifact to w.
+func (b *Buildkite) DownloadArtifact(ctx context.Context, project string, artifact Artifact, w io.Writer) error {
+	if artifact.URL == "" {
+		return fmt.Errorf("artifact %s has no download URL", artifact.Name)
+	}
+	if err := b.api.download(ctx, artifact.URL, w); err != nil {
+		return fmt.Errorf("failed to download artifact %s: %w", artifact.Name, err)
+	}
+	return nil
+}
+
+func (b *Buildkite) build(ctx context.Context, project string, number int64) (*buildkiteBuild, error) {
+	path, err := b.pipelinePath(project)
+	if err != nil {
+		return nil, err
+	}
+	var build buildkiteBuild
+	if err := b.api.do(ctx, http.MethodGet, fmt.Sprintf("%s/builds/%d", path, number), nil, &build); err != nil {
+		return nil, fmt.Errorf("failed to get build: %w", err)
+	}
+	return &build, nil
+}
+
+func (b *Buildkite) pipelinePath(project string) (string, error) {
+	if b.organization == "" {
+		return "", fmt.Errorf("Buildkite organization not configured - set ci.buildkite.organization")
+	}
+	if project == "" || strings.Contains(project, "/") {
+		return "", fmt.Errorf("invalid Buildkite pipeline %q (use the pipeline slug)", project)
+	}
+	return "/organizations/" + url.PathEscape(b.organization) + "/pipelines/" + url.PathEscape(project), nil
+}
+
+func firstLine(text string) string {
+	line, _, _ := strings.Cut(text, "\n")
+	return line
+}
@@ -0,0 +1,206 @@
+// Package ci abstracts the CI operations Juleson uses to triage and monitor
+// builds — listing, starting, and following pipelines, and fetching their
+// logs and artifacts — over the CI of GitHub, GitLab, and Bitbucket, and
+// over CircleCI and Buildkite.
+package ci
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/SamyRai/juleson/internal/vcs"
+)
+
+// Kind names a CI provider.
+type Kind string
+
+// Supported providers. The GitHub, GitLab, and Bitbucket providers are the
+// CI of those code hosts.
+const (
+	KindGitHub    Kind = Kind(vcs.KindGitHub)
+	KindGitLab    Kind = Kind(vcs.KindGitLab)
+	KindBitbucket Kind = Kind(vcs.KindBitbucket)
+	KindCircleCI  Kind = "circleci"
+	KindBuildkite Kind = "buildkite"
+)
+
+// ParseKind parses a provider name.
+func ParseKind(name string) (Kind, error) {
+	kind := Kind(strings.ToLower(strings.TrimSpace(name)))
+	switch kind {
+	case KindGitHub, KindGitLab, KindBitbucket, KindCircleCI, KindBuildkite:
+		return kind, nil
+	default:
+		return "", fmt.Errorf("unknown CI provider %q (use github, gitlab, bitbucket, circleci, or buildkite)", name)
+	}
+}
+
+// Pipeline is a GitHub Actions workflow run, a GitLab, Bitbucket, or
+// CircleCI pipeline, or a Buildkite build. Its ID is the number shown by
+// the provider.
+type Pipeline = vcs.Pipeline
+
+// Trigger describes a pipeline to start.
+type Trigger = vcs.PipelineTrigger
+
+// Artifact is a file produced by a pipeline.
+type Artifact struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+	Size int64  `json:"size,omitempty"`
+	// URL downloads the artifact, for providers that return one.
+	URL string `json:"url,omitempty"`
+	// Archive reports whether the artifact downloads as a zip archive, as
+	// code host artifacts do, rather than as the file itself.
+	Archive bool `json:"archive,omitempty"`
+}
+
+// Provider performs CI operations on projects of one service. A project is
+// a repository path for code host CI, a CircleCI project slug such as
+// gh/owner/name, or a Buildkite pipeline slug. Operations a service has no
+// API for return vcs.ErrNotSupported.
+type Provider interface {
+	Kind() Kind
+	// ListPipelines lists the most recent pipelines, of ref when it is set.
+	ListPipelines(ctx context.Context, project, ref string, limit int) ([]Pipeline, error)
+	GetPipeline(ctx context.Context, project string, id int64) (*Pipeline, error)
+	// TriggerPipeline starts a pipeline. It returns a nil pipeline when the
+	// service does not report the pipeline it started.
+	TriggerPipeline(ctx context.Context, project string, trigger Trigger) (*Pipeline, error)
+	// PipelineLogs writes the log of each of a pipeline's jobs to w, each
+	// under a "==> JOB <==" header.
+	PipelineLogs(ctx context.Context, project string, id int64, w io.Writer) error
+	ListArtifacts(ctx context.Context, project string, id int64) ([]Artifact, error)
+	DownloadArtifact(ctx context.Context, project string, artifact Artifact, w io.Writer) error
+}
+
+// WorkflowLister is implemented by providers that can list the pipelines of
+// one workflow of a project, given by file name or ID. Only GitHub Actions
+// has workflows; other code hosts return vcs.ErrNotSupported.
+type WorkflowLister interface {
+	ListWorkflowPipelines(ctx context.Context, project, workflow, ref string, limit int) ([]Pipeline, error)
+}
+
+var (
+	_ WorkflowLister = (*vcsCI)(nil)
+	_ Provider       = (*vcsCI)(nil)
+	_ Provider       = (*CircleCI)(nil)
+	_ Provider       = (*Buildkite)(nil)
+)
+
+// writeLogHeader starts the log of a job in PipelineLogs output.
+func writeLogHeader(w io.Writer, job string) error {
+	_, err := fmt.Fprintf(w, "==> %s <==\n", job)
+	return err
+}
+
+// apiClient makes JSON requests to a CI service's REST API.
+type apiClient struct {
+	service string
+	baseURL string
+	// authorize sets the credentials of a request.
+	authorize func(req *http.Request)
+	client    *http.Client
+}
+
+func newAPIClient(service, baseURL string, authorize func(req *http.Request)) *apiClient {
+	return &apiClient{
+		service:   service,
+		baseURL:   strings.TrimSuffix(baseURL, "/"),
+		authorize: authorize,
+		client:    &http.Client{Timeout: 60 * time.Second},
+	}
+}
+
+// request builds a request for path, or for an absolute URL.
+func (c *apiClient) request(ctx context.Context, method, path string, body any) (*http.Request, error) {
+	var reader io.Reader
+	if body != nil {
+		payload, err := json.Marshal(body)
+		if err != nil {
+			return nil, err
+		}
+		reader = bytes.NewReader(payload)
+	}
+	target := path
+	if !strings.HasPrefix(path, "https://") && !strings.HasPrefix(path, "http://") {
+		target = c.baseURL + path
+	}
+	req, err := http.NewRequestWithContext(ctx, method, target, reader)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/json")
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	c.authorize(req)
+	return req, nil
+}
+
+func (c *apiClient) do(ctx context.Context, method, path string, body, out any) error {
+	req, err := c.request(ctx, method, path, body)
+	if err != nil {
+		return err
+	}
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if err := c.responseError(resp); err != nil {
+		return err
+	}
+	if out == nil {
+		return nil
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("failed to decode %s response: %w", c.service, err)
+	}
+	return nil
+}
+
+// download writes the body of a GET of path or an absolute URL to w.
+// Credentials are not sent on redirects to other hosts.
+func (c *apiClient) download(ctx context.Context, path string, w io.Writer) error {
+	req, err := c.request(ctx, http.MethodGet, path, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Del("Accept")
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if err := c.responseError(resp); err != nil {
+		return err
+	}
+	_, err = io.Copy(w, resp.Body)
+	return err
+}
+
+// responseError returns the error in an unsuccessful response.
+func (c *apiClient) responseError(resp *http.Response) error {
+	if resp.StatusCode/100 == 2 {
+		return nil
+	}
+	data, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+	var body struct {
+		Message string `json:"message"`
+	}
+	if json.Unmarshal(data, &body) == nil && body.Message != "" {
+		return fmt.Errorf("%s returned %s: %s", c.service, resp.Status, body.Message)
+	}
+	if len(bytes.TrimSpace(data)) == 0 {
+		return errors.New(c.service + " returned " + resp.Status)
+	}
+	return fmt.Errorf("%s returned %s: %s", c.service, resp.Status, bytes.TrimSpace(data))
+}
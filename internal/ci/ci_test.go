@@ -0,0 +1,211 @@
+package ci
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/SamyRai/juleson/internal/vcs"
+)
+
+func TestParseKind(t *testing.T) {
+	if kind, err := ParseKind(" CircleCI "); err != nil || kind != KindCircleCI {
+		t.Fatalf("ParseKind() = %q, %v", kind, err)
+	}
+	if kind, err := ParseKind("bitbucket"); err != nil || kind != KindBitbucket {
+		t.Fatalf("ParseKind(bitbucket) = %q, %v", kind, err)
+	}
+	if _, err := ParseKind("jenkins"); err == nil {
+		t.Fatal("ParseKind(jenkins) succeeded")
+	}
+}
+
+func TestCircleCI(t *testing.T) {
+	var requests []string
+	var bodies []map[string]any
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r.Method+" "+r.URL.RequestURI())
+		if r.URL.Path == "/output/7" {
+			fmt.Fprint(w, `[{"message":"go test ./...\n"},{"message":"ok\n"}]`)
+			return
+		}
+		if r.Header.Get("Circle-Token") != "circle-secret" {
+			http.Error(w, `{"message":"Invalid token provided."}`, http.StatusUnauthorized)
+			return
+		}
+		if r.Method == http.MethodPost {
+			var body map[string]any
+			_ = json.NewDecoder(r.Body).Decode(&body)
+			bodies = append(bodies, body)
+		}
+		pipeline := `{"id":"p1","number":42,"state":"created","created_at":"2026-10-01T12:00:00Z","vcs":{"branch":"main","revision":"abc123"}}`
+		switch r.Method + " " + r.URL.Path {
+		case "GET /api/v2/project/gh/acme/widgets/pipeline":
+			fmt.Fprintf(w, `{"items":[%s]}`, pipeline)
+		case "GET /api/v2/project/gh/acme/widgets/pipeline/42":
+			fmt.Fprint(w, pipeline)
+		case "GET /api/v2/pipeline/p1/workflow":
+			fmt.Fprint(w, `{"items":[{"id":"w1","name":"build","status":"success"},{"id":"w2","name":"deploy","status":"on_hold"}]}`)
+		case "GET /api/v2/workflow/w1/job":
+			fmt.Fprint(w, `{"items":[{"job_number":7,"name":"test","status":"success"}]}`)
+		case "GET /api/v2/workflow/w2/job":
+			fmt.Fprint(w, `{"items":[{"name":"approve","status":"on_hold"}]}`)
+		case "GET /api/v1.1/project/gh/acme/widgets/7":
+			fmt.Fprintf(w, `{"steps":[{"name":"checkout","actions":[{}]},{"name":"test","actions":[{"output_url":%q}]}]}`, server.URL+"/output/7")
+		case "GET /api/v2/project/gh/acme/widgets/7/artifacts":
+			fmt.Fprintf(w, `{"items":[{"path":"coverage/index.html","url":%q}]}`, server.URL+"/files/index.html")
+		case "GET /files/index.html":
+			fmt.Fprint(w, "<html></html>")
+		case "POST /api/v2/project/gh/acme/widgets/pipeline":
+			fmt.Fprint(w, `{"id":"p2","number":43,"state":"created"}`)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	ctx := context.Background()
+	circle := NewCircleCI(server.URL+"/api/v2", "circle-secret")
+
+	pipelines, err := circle.ListPipelines(ctx, "gh/acme/widgets", "main", 5)
+	if err != nil {
+		t.Fatalf("ListPipelines() error = %v", err)
+	}
+	if len(pipelines) != 1 || pipelines[0].ID != 42 || pipelines[0].Status != "on_hold" || pipelines[0].Name != "build,deploy" || pipelines[0].SHA != "abc123" {
+		t.Fatalf("ListPipelines() = %+v", pipelines)
+	}
+	if pipelines[0].URL != "https://app.circleci.com/pipelines/github/acme/widgets/42" {
+		t.Errorf("URL = %q", pipelines[0].URL)
+	}
+	if requests[0] != "GET /api/v2/project/gh/acme/widgets/pipeline?branch=main" {
+		t.Errorf("list request = %q", requests[0])
+	}
+
+	pipeline, err := circle.TriggerPipeline(ctx, "gh/acme/widgets", Trigger{Ref: "release", Variables: map[string]string{"deploy": "true"}})
+	if err != nil || pipeline.ID != 43 || pipeline.Ref != "release" {
+		t.Fatalf("TriggerPipeline() = %+v, %v", pipeline, err)
+	}
+	if want := map[string]any{"branch": "release", "parameters": map[string]any{"deploy": "true"}}; !reflect.DeepEqual(bodies[0], want) {
+		t.Errorf("trigger body = %v", bodies[0])
+	}
+	if _, err := circle.TriggerPipeline(ctx, "gh/acme/widgets", Trigger{Ref: "main", Workflow: "nightly"}); !errors.Is(err, vcs.ErrNotSupported) {
+		t.Errorf("TriggerPipeline(workflow) error = %v", err)
+	}
+
+	var logs bytes.Buffer
+	if err := circle.PipelineLogs(ctx, "gh/acme/widgets", 42, &logs); err != nil {
+		t.Fatalf("PipelineLogs() error = %v", err)
+	}
+	if got := logs.String(); got != "==> test <==\ngo test ./...\nok\n" {
+		t.Errorf("PipelineLogs() = %q", got)
+	}
+
+	artifacts, err := circle.ListArtifacts(ctx, "gh/acme/widgets", 42)
+	if err != nil || len(artifacts) != 1 || artifacts[0].Name != "test/coverage/index.html" || artifacts[0].ID != "7/coverage/index.html" {
+		t.Fatalf("ListArtifacts() = %+v, %v", artifacts, err)
+	}
+	var file bytes.Buffer
+	if err := circle.DownloadArtifact(ctx, "gh/acme/widgets", artifacts[0], &file); err != nil || file.String() != "<html></html>" {
+		t.Fatalf("DownloadArtifact() = %q, %v", file.String(), err)
+	}
+
+	if _, err := circle.ListPipelines(ctx, "acme/widgets", "", 5); err == nil {
+		t.Error("ListPipelines(acme/widgets) succeeded without a VCS prefix")
+	}
+	if _, err := NewCircleCI(server.URL+"/api/v2", "wrong").GetPipeline(ctx, "gh/acme/widgets", 42); err == nil || err.Error() != "failed to get pipeline: CircleCI returned 401 Unauthorized: Invalid token provided." {
+		t.Errorf("GetPipeline(bad token) error = %v", err)
+	}
+}
+
+func TestBuildkite(t *testing.T) {
+	var requests []string
+	var bodies []map[string]any
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r.Method+" "+r.URL.RequestURI())
+		if r.Header.Get("Authorization") != "Bearer bk-secret" {
+			http.Error(w, `{"message":"Authentication required"}`, http.StatusUnauthorized)
+			return
+		}
+		if r.Method == http.MethodPost {
+			var body map[string]any
+			_ = json.NewDecoder(r.Body).Decode(&body)
+			bodies = append(bodies, body)
+		}
+		build := `{"number":9,"state":"failed","branch":"main","commit":"abc123","message":"Fix flaky test\n\nDetails","web_url":"https://buildkite.com/acme/widgets/builds/9","created_at":"2026-10-01T12:00:00Z",
+			"jobs":[{"id":"j1","type":"script","name":"test"},{"id":"j2","type":"waiter"},{"id":"j3","type":"script","name":"lint"}]}`
+		switch r.Method + " " + r.URL.Path {
+		case "GET /organizations/acme/pipelines/widgets/builds":
+			fmt.Fprintf(w, `[%s]`, build)
+		case "GET /organizations/acme/pipelines/widgets/builds/9":
+			fmt.Fprint(w, build)
+		case "GET /organizations/acme/pipelines/widgets/builds/9/jobs/j1/log":
+			fmt.Fprint(w, `{"content":"FAIL TestFlaky\n"}`)
+		case "GET /organizations/acme/pipelines/widgets/builds/9/jobs/j3/log":
+			fmt.Fprint(w, `{"content":"ok\n"}`)
+		case "GET /organizations/acme/pipelines/widgets/builds/9/artifacts":
+			fmt.Fprintf(w, `[{"id":"a1","path":"dist/app.tar.gz","file_size":4,"download_url":%q}]`, server.URL+"/artifacts/a1/download")
+		case "GET /artifacts/a1/download":
+			fmt.Fprint(w, "data")
+		case "POST /organizations/acme/pipelines/widgets/builds":
+			fmt.Fprint(w, `{"number":10,"state":"scheduled","branch":"release","web_url":"https://buildkite.com/acme/widgets/builds/10"}`)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	ctx := context.Background()
+	buildkite := NewBuildkite(server.URL, "acme", "bk-secret")
+
+	pipelines, err := buildkite.ListPipelines(ctx, "widgets", "main", 5)
+	if err != nil {
+		t.Fatalf("ListPipelines() error = %v", err)
+	}
+	if len(pipelines) != 1 || pipelines[0].ID != 9 || pipelines[0].Status != "failed" || pipelines[0].Name != "Fix flaky test" {
+		t.Fatalf("ListPipelines() = %+v", pipelines)
+	}
+	if requests[0] != "GET /organizations/acme/pipelines/widgets/builds?branch=main&per_page=5" {
+		t.Errorf("list request = %q", requests[0])
+	}
+
+	pipeline, err := buildkite.TriggerPipeline(ctx, "widgets", Trigger{Ref: "release", Variables: map[string]string{"DEPLOY": "1"}})
+	if err != nil || pipeline.ID != 10 || pipeline.Status != "scheduled" {
+		t.Fatalf("TriggerPipeline() = %+v, %v", pipeline, err)
+	}
+	want := map[string]any{"commit": "HEAD", "branch": "release", "message": "Triggered by Juleson", "env": map[string]any{"DEPLOY": "1"}}
+	if !reflect.DeepEqual(bodies[0], want) {
+		t.Errorf("trigger body = %v", bodies[0])
+	}
+
+	var logs bytes.Buffer
+	if err := buildkite.PipelineLogs(ctx, "widgets", 9, &logs); err != nil {
+		t.Fatalf("PipelineLogs() error = %v", err)
+	}
+	if got := logs.String(); got != "==> test <==\nFAIL TestFlaky\n==> lint <==\nok\n" {
+		t.Errorf("PipelineLogs() = %q", got)
+	}
+
+	artifacts, err := buildkite.ListArtifacts(ctx, "widgets", 9)
+	if err != nil || len(artifacts) != 1 || artifacts[0].Name != "dist/app.tar.gz" || artifacts[0].Size != 4 || artifacts[0].Archive {
+		t.Fatalf("ListArtifacts() = %+v, %v", artifacts, err)
+	}
+	var file bytes.Buffer
+	if err := buildkite.DownloadArtifact(ctx, "widgets", artifacts[0], &file); err != nil || file.String() != "data" {
+		t.Fatalf("DownloadArtifact() = %q, %v", file.String(), err)
+	}
+
+	if _, err := buildkite.GetPipeline(ctx, "acme/widgets", 9); err == nil {
+		t.Error("GetPipeline(acme/widgets) succeeded")
+	}
+	if _, err := NewBuildkite(server.URL, "", "bk-secret").GetPipeline(ctx, "widgets", 9); err == nil {
+		t.Error("GetPipeline() succeeded without an organization")
+	}
+}
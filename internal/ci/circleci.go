@@ -0,0 +1,341 @@
+package ci
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/SamyRai/juleson/internal/vcs"
+)
+
+// CircleCIDefaultBaseURL is the CircleCI API.
+const CircleCIDefaultBaseURL = "https://circleci.com/api/v2"
+
+// circleCIStatusOrder ranks workflow statuses; a pipeline has the status
+// of its first workflow in this order.
+var circleCIStatusOrder = []string{"failed", "error", "failing", "canceled", "unauthorized", "on_hold", "running", "not_run", "success"}
+
+// CircleCI performs operations through the CircleCI API. Projects are slugs
+// such as gh/owner/name, and pipelines are identified by number.
+type CircleCI struct {
+	api *apiClient
+	// legacy is the v1.1 API, the only one serving step output.
+	legacy string
+}
+
+// NewCircleCI creates a provider for the API at baseURL, authenticating
+// with a personal API token.
+func NewCircleCI(baseURL, token string) *CircleCI {
+	if baseURL == "" {
+		baseURL = CircleCIDefaultBaseURL
+	}
+	api := newAPIClient("CircleCI", baseURL, func(req *http.Request) {
+		req.Header.Set("Circle-Token", token)
+	})
+	return &CircleCI{api: api, legacy: strings.TrimSuffix(api.baseURL, "/v2") + "/v1.1"}
+}
+
+// Kind returns KindCircleCI.
+func (c *CircleCI) Kind() Kind { return KindCircleCI }
+
+type circleCIPipeline struct {
+	ID        string    `json:"id"`
+	Number    int64     `json:"number"`
+	State     string    `json:"state"`
+	CreatedAt time.Time `json:"created_at"`
+	VCS       struct {
+		Branch   string `json:"branch"`
+		Revision string `json:"revision"`
+	} `json:"vcs"`
+}
+
+type circleCIWorkflow struct {
+	ID     string `json:"id"`
+	Name   string `json:"name"`
+	Status string `json:"status"`
+}
+
+// ListPipelines lists pipelines, newest first, with their workflows'
+// status.
+func (c *CircleCI) ListPipelines(ctx context.Context, project, ref string, limit int) ([]Pipeline, error) {
+	if err := validCircleCISlug(project); err != nil {
+		return nil, err
+	}
+	query := url.Values{}
+	if ref != "" {
+		query.Set("branch", ref)
+	}
+	var page struct {
+		Items []circleCIPipeline `json:"items"`
+	}
+	if err := c.api.do(ctx, http.MethodGet, "/project/"+project+"/pipeline?"+query.Encode(), nil, &page); err != nil {
+		return nil, fmt.Errorf("failed to list pipelines: %w", err)
+	}
+	result := make([]Pipeline, 0, min(len(page.Items), max(limit, 1)))
+	for _, item := range page.Items {
+		if len(result) == limit {
+			break
+		}
+		pipeline, err := c.pipeline(ctx, project, item)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, pipeline)
+	}
+	return result, nil
+}
+
+// GetPipeline returns a pipeline by number.
+func (c *CircleCI) GetPipeline(ctx context.Context, project string, id int64) (*Pipeline, error) {
+	if err := validCircleCISlug(project); err != nil {
+		return nil, err
+	}
+	item, err := c.getPipeline(ctx, project, id)
+	if err != nil {
+		return nil, err
+	}
+	pipeline, err := c.pipeline(ctx, project, *item)
+	if err != nil {
+		return nil, err
+	}
+	return &pipeline, nil
+}
+
+// TriggerPipeline runs the project's config on a branch, with the
+// variables as pipeline parameters.
+func (c *CircleCI) TriggerPipeline(ctx context.Context, project string, trigger Trigger) (*Pipeline, error) {
+	if err := validCircleCISlug(project); err != nil {
+		return nil, err
+	}
+	if trigger.Workflow != "" {
+		return nil, fmt.Errorf("CircleCI runs the project's config; a workflow is %w", vcs.ErrNotSupported)
+	}
+	body := map[string]any{"branch": trigger.Ref}
+	if len(trigger.Variables) > 0 {
+		body["parameters"] = trigger.Variables
+	}
+	var item circleCIPipeline
+	if err := c.api.do(ctx, http.MethodPost, "/project/"+project+"/pipeline", body, &item); err != nil {
+		return nil, fmt.Errorf("failed to trigger pipeline: %w", err)
+	}
+	if item.VCS.Branch == "" {
+		item.VCS.Branch = trigger.Ref
+	}
+	pipeline := c.convert(project, item, nil)
+	return &pipeline, nil
+}
+
+type circleCIJob struct {
+	JobNumber int64  `json:"job_number"`
+	Name      string `json:"name"`
+	Status    string `json:"status"`
+}
+
+// PipelineLogs writes the output of each step of a pipeline's jobs.
+func (c *CircleCI) PipelineLogs(ctx context.Context, project string, id int64, w io.Writer) error {
+	jobs, err := c.pipelineJobs(ctx, project, id)
+	if err != nil {
+		return err
+	}
+	for _, job := range jobs {
+		if err := writeLogHeader(w, job.Name); err != nil {
+			return err
+		}
+		var details struct {
+			Steps []struct {
+				Name    string `json:"name"`
+				Actions []struct {
+					OutputURL string `json:"output_url"`
+				} `json:"actions"`
+			} `json:"steps"`
+		}
+		if err := c.api.do(ctx, http.MethodGet, fmt.Sprintf("%s/project/%s/%d", c.legacy, project, job.JobNumber), nil, &details); err != nil {
+			return fmt.Errorf("failed to get job %s: %w", job.Name, err)
+		}
+		for _, step := range details.Steps {
+			for _, action := range step.Actions {
+				if action.OutputURL == "" {
+					continue
+				}
+				if err := c.writeStepOutput(ctx, action.OutputURL, w); err != nil {
+					return fmt.Errorf("failed to get output of %s: %w", step.Name, err)
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// writeStepOutput writes the messages of a step's output, which CircleCI
+// serves as a JSON list.
+func (c *CircleCI) writeStepOutput(ctx context.Context, outputURL string, w io.Writer) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, outputURL, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := c.api.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if err := c.api.responseError(resp); err != nil {
+		return err
+	}
+	var output []struct {
+		Message string `json:"message"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&output); err != nil {
+		return fmt.Errorf("failed to decode step output: %w", err)
+	}
+	for _, entry := range output {
+		if _, err := io.WriteString(w, entry.Message); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ListArtifacts lists the artifacts of a pipeline's jobs, named JOB/PATH.
+func (c *CircleCI) ListArtifacts(ctx context.Context, project string, id int64) ([]Artifact, error) {
+	jobs, err := c.pipelineJobs(ctx, project, id)
+	if err != nil {
+		return nil, err
+	}
+	var artifacts []Artifact
+	for _, job := range jobs {
+		var page struct {
+			Items []struct {
+				Path string `json:"path"`
+				URL  string `json:"url"`
+			} `json:"items"`
+		}
+		if err := c.api.do(ctx, http.MethodGet, fmt.Sprintf("/project/%s/%d/artifacts", project, job.JobNumber), nil, &page); err != nil {
+			return nil, fmt.Errorf("failed to list artifacts of %s: %w", job.Name, err)
+		}
+		for _, item := range page.Items {
+			artifacts = append(artifacts, Artifact{
+				ID:   fmt.Sprintf("%d/%s", job.JobNumber, item.Path),
+				Name: job.Name + "/" + item.Path,
+				URL:  item.URL,
+			})
+		}
+	}
+	return artifacts, nil
+}
+
+// DownloadArtifact writes an artifact to w.
+func (c *CircleCI) DownloadArtifact(ctx context.Context, project string, artifact Artifact, w io.Writer) error {
+	if artifact.URL == "" {
+		return fmt.Errorf("artifact %s has no download URL", artifact.Name)
+	}
+	if err := c.api.download(ctx, artifact.URL, w); err != nil {
+		return fmt.Errorf("failed to download artifact %s: %w", artifact.Name, err)
+	}
+	return nil
+}
+
+func (c *CircleCI) getPipeline(ctx context.Context, project string, number int64) (*circleCIPipeline, error) {
+	var item circleCIPipeline
+	if err := c.api.do(ctx, http.MethodGet, fmt.Sprintf("/project/%s/pipeline/%d", project, number), nil, &item); err != nil {
+		return nil, fmt.Errorf("failed to get pipeline: %w", err)
+	}
+	return &item, nil
+}
+
+func (c *CircleCI) workflows(ctx context.Context, pipelineID string) ([]circleCIWorkflow, error) {
+	var page struct {
+		Items []circleCIWorkflow `json:"items"`
+	}
+	if err := c.api.do(ctx, http.MethodGet, "/pipeline/"+url.PathEscape(pipelineID)+"/workflow", nil, &page); err != nil {
+		return nil, fmt.Errorf("failed to list workflows: %w", err)
+	}
+	return page.Items, nil
+}
+
+func (c *CircleCI) pipelineJobs(ctx context.Context, project string, number int64) ([]circleCIJob, error) {
+	if err := validCircleCISlug(project); err != nil {
+		return nil, err
+	}
+	item, err := c.getPipeline(ctx, project, number)
+	if err != nil {
+		return nil, err
+	}
+	workflows, err := c.workflows(ctx, item.ID)
+	if err != nil {
+		return nil, err
+	}
+	var jobs []circleCIJob
+	for _, workflow := range workflows {
+		var page struct {
+			Items []circleCIJob `json:"items"`
+		}
+		if err := c.api.do(ctx, http.MethodGet, "/workflow/"+url.PathEscape(workflow.ID)+"/job", nil, &page); err != nil {
+			return nil, fmt.Errorf("failed to list jobs of %s: %w", workflow.Name, err)
+		}
+		for _, job := range page.Items {
+			// Approval jobs have no number, logs, or artifacts.
+			if job.JobNumber != 0 {
+				jobs = append(jobs, job)
+			}
+		}
+	}
+	return jobs, nil
+}
+
+func (c *CircleCI) pipeline(ctx context.Context, project string, item circleCIPipeline) (Pipeline, error) {
+	workflows, err := c.workflows(ctx, item.ID)
+	if err != nil {
+		return Pipeline{}, err
+	}
+	return c.convert(project, item, workflows), nil
+}
+
+// convert converts a pipeline, whose status comes from its workflows, or
+// its own state before they start.
+func (c *CircleCI) convert(project string, item circleCIPipeline, workflows []circleCIWorkflow) Pipeline {
+	status := item.State
+	names := make([]string, 0, len(workflows))
+	rank := len(circleCIStatusOrder)
+	for _, workflow := range workflows {
+		names = append(names, workflow.Name)
+		for i, candidate := range circleCIStatusOrder {
+			if workflow.Status == candidate && i < rank {
+				rank, status = i, candidate
+			}
+		}
+	}
+	return Pipeline{
+		ID:        item.Number,
+		Name:      strings.Join(names, ","),
+		Ref:       item.VCS.Branch,
+		SHA:       item.VCS.Revision,
+		Status:    status,
+		URL:       circleCIWebURL(project, item.Number),
+		CreatedAt: item.CreatedAt,
+	}
+}
+
+// circleCIWebURL returns the CircleCI app page of a pipeline.
+func circleCIWebURL(project string, number int64) string {
+	vcsType, rest, _ := strings.Cut(project, "/")
+	switch vcsType {
+	case "gh":
+		vcsType = "github"
+	case "bb":
+		vcsType = "bitbucket"
+	}
+	return fmt.Sprintf("https://app.circleci.com/pipelines/%s/%s/%d", vcsType, rest, number)
+}
+
+func validCircleCISlug(project string) error {
+	parts := strings.Split(project, "/")
+	if len(parts) != 3 || parts[0] == "" || parts[1] == "" || parts[2] == "" {
+		return fmt.Errorf("invalid CircleCI project %q (use a slug such as gh/owner/name)", project)
+	}
+	return nil
+}
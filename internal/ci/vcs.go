@@ -0,0 +1,78 @@
+package ci
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strconv"
+
+	"github.com/SamyRai/juleson/internal/vcs"
+)
+
+// vcsCI is the CI of a code host: GitHub Actions, GitLab CI/CD, or
+// Bitbucket Pipelines.
+type vcsCI struct {
+	provider vcs.Provider
+}
+
+// FromVCS returns the CI of a code host, with repository paths as projects.
+func FromVCS(provider vcs.Provider) Provider {
+	return &vcsCI{provider: provider}
+}
+
+// Kind returns the code host's kind.
+func (c *vcsCI) Kind() Kind { return Kind(c.provider.Kind()) }
+
+// ListPipelines lists the code host's pipelines.
+func (c *vcsCI) ListPipelines(ctx context.Context, project, ref string, limit int) ([]Pipeline, error) {
+	return c.provider.ListPipelines(ctx, project, ref, limit)
+}
+
+// ListWorkflowPipelines lists the pipelines of one workflow, on code hosts
+// that have workflows.
+func (c *vcsCI) ListWorkflowPipelines(ctx context.Context, project, workflow, ref string, limit int) ([]Pipeline, error) {
+	lister, ok := c.provider.(interface {
+		ListWorkflowPipelines(ctx context.Context, repo, workflow, ref string, limit int) ([]vcs.Pipeline, error)
+	})
+	if !ok {
+		return nil, fmt.Errorf("listing the runs of a workflow is %w", vcs.ErrNotSupported)
+	}
+	return lister.ListWorkflowPipelines(ctx, project, workflow, ref, limit)
+}
+
+// GetPipeline returns a pipeline.
+func (c *vcsCI) GetPipeline(ctx context.Context, project string, id int64) (*Pipeline, error) {
+	return c.provider.GetPipeline(ctx, project, id)
+}
+
+// TriggerPipeline starts a pipeline.
+func (c *vcsCI) TriggerPipeline(ctx context.Context, project string, trigger Trigger) (*Pipeline, error) {
+	return c.provider.TriggerPipeline(ctx, project, trigger)
+}
+
+// PipelineLogs writes the logs of a pipeline's jobs.
+func (c *vcsCI) PipelineLogs(ctx context.Context, project string, id int64, w io.Writer) error {
+	return c.provider.PipelineLogs(ctx, project, id, w)
+}
+
+// ListArtifacts lists a pipeline's artifacts.
+func (c *vcsCI) ListArtifacts(ctx context.Context, project string, id int64) ([]Artifact, error) {
+	artifacts, err := c.provider.ListArtifacts(ctx, project, id)
+	if err != nil {
+		return nil, err
+	}
+	result := make([]Artifact, 0, len(artifacts))
+	for _, artifact := range artifacts {
+		result = append(result, Artifact{ID: strconv.FormatInt(artifact.ID, 10), Name: artifact.Name, Size: artifact.Size, Archive: true})
+	}
+	return result, nil
+}
+
+// DownloadArtifact writes an artifact's archive to w.
+func (c *vcsCI) DownloadArtifact(ctx context.Context, project string, artifact Artifact, w io.Writer) error {
+	id, err := strconv.ParseInt(artifact.ID, 10, 64)
+	if err != nil {
+		return fmt.Errorf("invalid artifact ID %q", artifact.ID)
+	}
+	return c.provider.DownloadArtifact(ctx, project, vcs.Artifact{ID: id, Name: artifact.Name, Size: artifact.Size}, w)
+}
@@ -0,0 +1,419 @@
+// Package commitmsg writes conventional-commit messages and CHANGELOG
+// fragments for the changes of Jules sessions, with Gemini when it is
+// configured and from the changes themselves otherwise.
+package commitmsg
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path"
+	"slices"
+	"strings"
+
+	"github.com/SamyRai/go-jules"
+	"github.com/SamyRai/juleson/internal/gemini"
+	"github.com/SamyRai/juleson/internal/jules/workspace"
+)
+
+// Sources of a Result.
+const (
+	SourceGemini    = "gemini"
+	SourceHeuristic = "heuristic"
+)
+
+// Types are the conventional-commit types a Commit may have.
+var Types = []string{"feat", "fix", "docs", "test", "refactor", "perf", "build", "ci", "style", "chore"}
+
+// Sections are the CHANGELOG sections an Entry may belong to, in the order
+// they are rendered.
+var Sections = []string{"Added", "Changed", "Deprecated", "Removed", "Fixed", "Security"}
+
+// maxPromptPatchBytes bounds the patch text sent to Gemini across all
+// changesets.
+const maxPromptPatchBytes = 60000
+
+// maxSubjectLength bounds a commit header, type and scope included.
+const maxSubjectLength = 72
+
+// Changeset is the changes of one session.
+type Changeset struct {
+	SessionID string
+	// Title is the session title; Prompt is the task it was given.
+	Title  string
+	Prompt string
+	// Changes summarizes the files and the commit messages Jules
+	// suggested.
+	Changes *workspace.SessionChanges
+	// Patch is the unified diff, sent to Gemini.
+	Patch string
+}
+
+// Commit is a conventional-commit message.
+type Commit struct {
+	SessionID string `json:"sessionId,omitempty"`
+	Type      string `json:"type"`
+	Scope     string `json:"scope,omitempty"`
+	Subject   string `json:"subject"`
+	Body      string `json:"body,omitempty"`
+	Breaking  bool   `json:"breaking,omitempty"`
+}
+
+// Header returns the first line, such as "feat(config)!: add gemini".
+func (c Commit) Header() string {
+	header := c.Type
+	if c.Scope != "" {
+		header += "(" + c.Scope + ")"
+	}
+	if c.Breaking {
+		header += "!"
+	}
+	return header + ": " + c.Subject
+}
+
+// String returns the full message.
+func (c Commit) String() string {
+	if c.Body == "" {
+		return c.Header()
+	}
+	return c.Header() + "\n\n" + c.Body
+}
+
+// Entry is one line of a CHANGELOG fragment.
+type Entry struct {
+	Section string `json:"section"`
+	Text    string `json:"text"`
+}
+
+// Result is the generated messages and CHANGELOG fragment.
+type Result struct {
+	Commits []Commit `json:"commits"`
+	Entries []Entry  `json:"entries"`
+	// Changelog is Entries rendered as Markdown.
+	Changelog string `json:"changelog"`
+	// Source is SourceGemini or SourceHeuristic.
+	Source string `json:"source"`
+	Model  string `json:"model,omitempty"`
+	// Warning explains why Gemini was not used although it is configured.
+	Warning string `json:"warning,omitempty"`
+	// Usage is what was asked of each Gemini model, including those that
+	// failed before another answered.
+	Usage []gemini.ModelUsage `json:"usage,omitempty"`
+}
+
+// Generate writes one commit message per changeset and a CHANGELOG fragment
+// covering all of them. A nil client uses Heuristic, and so does a Gemini
+// failure, which is reported in Result.Warning.
+func Generate(ctx context.Context, client *gemini.Client, changesets []Changeset) (*Result, error) {
+	if len(changesets) == 0 {
+		return nil, errors.New("no changesets to describe")
+	}
+	if client == nil {
+		return Heuristic(changesets), nil
+	}
+
+	var answer struct {
+		Commits []Commit `json:"commits"`
+		Entries []Entry  `json:"changelog"`
+	}
+	model, err := client.Route(gemini.SiteCommitMessages).Generate(ctx, systemPrompt, prompt(changesets), responseSchema, &answer)
+	if err == nil && len(answer.Commits) != len(changesets) {
+		err = fmt.Errorf("gemini returned %d commit message(s) for %d changeset(s)", len(answer.Commits), len(changesets))
+	}
+	if err != nil {
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		result := Heuristic(changesets)
+		result.Warning = err.Error()
+		result.Usage = client.Usage()
+		return result, nil
+	}
+	result := &Result{Source: SourceGemini, Model: model, Usage: client.Usage()}
+	for i, commit := range answer.Commits {
+		commit.SessionID = changesets[i].SessionID
+		result.Commits = append(result.Commits, normalize(commit))
+	}
+	for _, entry := range answer.Entries {
+		if text := strings.TrimSpace(entry.Text); text != "" && slices.Contains(Sections, entry.Section) {
+			result.Entries = append(result.Entries, Entry{Section: entry.Section, Text: text})
+		}
+	}
+	result.Changelog = Changelog(result.Entries)
+	return result, nil
+}
+
+// Heuristic derives the messages from session titles, Jules' suggested
+// commit messages, and the paths changed.
+func Heuristic(changesets []Changeset) *Result {
+	result := &Result{Source: SourceHeuristic}
+	for _, changeset := range changesets {
+		commit := heuristicCommit(changeset)
+		result.Commits = append(result.Commits, commit)
+		if section := sectionFor(commit.Type); section != "" {
+			result.Entries = append(result.Entries, Entry{Section: section, Text: sentence(commit.Subject)})
+		}
+	}
+	result.Changelog = Changelog(result.Entries)
+	return result
+}
+
+// PullRequest returns a title and Markdown description for a pull request
+// carrying the changes: one session's commit header and body, or a list of
+// every commit, followed by the CHANGELOG fragment.
+func (r *Result) PullRequest() (title, body string) {
+	if len(r.Commits) == 0 {
+		return "", r.Changelog
+	}
+	var b strings.Builder
+	title = r.Commits[0].Header()
+	if len(r.Commits) == 1 {
+		b.WriteString(r.Commits[0].Body)
+	} else {
+		for _, commit := range r.Commits {
+			fmt.Fprintf(&b, "- %s\n", commit.Header())
+		}
+	}
+	if r.Changelog != "" {
+		if b.Len() > 0 {
+			b.WriteString("\n\n")
+		}
+		b.WriteString("## Changelog\n\n" + strings.ReplaceAll(r.Changelog, "### ", "#### "))
+	}
+	return title, strings.TrimSpace(b.String())
+}
+
+// Changelog renders entries as "### Section" lists in Sections order.
+func Changelog(entries []Entry) string {
+	var b strings.Builder
+	for _, section := range Sections {
+		var lines []string
+		for _, entry := range entries {
+			if entry.Section == section {
+				lines = append(lines, "- "+entry.Text)
+			}
+		}
+		if len(lines) == 0 {
+			continue
+		}
+		if b.Len() > 0 {
+			b.WriteString("\n")
+		}
+		fmt.Fprintf(&b, "### %s\n\n%s\n", section, strings.Join(lines, "\n"))
+	}
+	return b.String()
+}
+
+func heuristicCommit(changeset Changeset) Commit {
+	commit := Commit{SessionID: changeset.SessionID}
+	var files []string
+	if changeset.Changes != nil {
+		for _, file := range changeset.Changes.Files {
+			files = append(files, file.Path)
+		}
+		if len(changeset.Changes.SuggestedCommitMessages) > 0 {
+			suggested := strings.TrimSpace(changeset.Changes.SuggestedCommitMessages[0])
+			subject, body, _ := strings.Cut(suggested, "\n")
+			commit.Subject, commit.Body = subject, strings.TrimSpace(body)
+		}
+	}
+	if commit.Subject == "" {
+		commit.Subject = firstLine(changeset.Title)
+	}
+	if commit.Subject == "" {
+		commit.Subject = firstLine(changeset.Prompt)
+	}
+	if commit.Subject == "" {
+		commit.Subject = "apply changes from session " + changeset.SessionID
+	}
+	if parsed, ok := parseHeader(commit.Subject); ok {
+		parsed.SessionID, parsed.Body = commit.SessionID, commit.Body
+		return normalize(parsed)
+	}
+	commit.Type = guessType(commit.Subject, files)
+	commit.Scope = guessScope(files)
+	return normalize(commit)
+}
+
+// parseHeader parses a message that is already a conventional commit.
+func parseHeader(line string) (Commit, bool) {
+	prefix, subject, ok := strings.Cut(line, ": ")
+	if !ok {
+		return Commit{}, false
+	}
+	var commit Commit
+	commit.Breaking = strings.HasSuffix(prefix, "!")
+	prefix = strings.TrimSuffix(prefix, "!")
+	if typ, scope, ok := strings.Cut(prefix, "("); ok {
+		prefix, commit.Scope = typ, strings.TrimSuffix(scope, ")")
+	}
+	if !slices.Contains(Types, prefix) {
+		return Commit{}, false
+	}
+	commit.Type, commit.Subject = prefix, subject
+	return commit, true
+}
+
+func guessType(subject string, files []string) string {
+	words := strings.Fields(strings.ToLower(subject))
+	if len(words) > 0 {
+		switch strings.Trim(words[0], ".,:") {
+		case "fix", "fixes", "fixed", "correct", "resolve", "resolves", "handle", "prevent":
+			return "fix"
+		case "refactor", "refactors", "simplify", "extract", "rename", "move", "reorganize":
+			return "refactor"
+		case "document", "documents", "docs":
+			return "docs"
+		case "test", "tests":
+			return "test"
+		}
+	}
+	if slices.ContainsFunc(words, func(word string) bool { return word == "bug" || word == "crash" || word == "fix" }) {
+		return "fix"
+	}
+	if len(files) > 0 {
+		switch {
+		case all(files, isDoc):
+			return "docs"
+		case all(files, isTest):
+			return "test"
+		case all(files, isCI):
+			return "ci"
+		case all(files, isBuild):
+			return "build"
+		}
+	}
+	return "feat"
+}
+
+// guessScope names the directory all files share, or the one file changed.
+func guessScope(files []string) string {
+	if len(files) == 0 {
+		return ""
+	}
+	dir := path.Dir(files[0])
+	for _, file := range files[1:] {
+		for dir != "." && !strings.HasPrefix(file, dir+"/") {
+			dir = path.Dir(dir)
+		}
+	}
+	if dir == "." {
+		if len(files) == 1 && !strings.HasPrefix(files[0], ".") {
+			return strings.TrimSuffix(files[0], path.Ext(files[0]))
+		}
+		return ""
+	}
+	return path.Base(dir)
+}
+
+// normalize keeps commits within the conventions: a known type, a scope
+// that does not repeat it, a lower-case subject without a trailing period,
+// and a header of at most maxSubjectLength characters.
+func normalize(commit Commit) Commit {
+	commit.Type = strings.ToLower(strings.TrimSpace(commit.Type))
+	if !slices.Contains(Types, commit.Type) {
+		commit.Type = "chore"
+	}
+	commit.Scope = strings.ToLower(strings.TrimSpace(commit.Scope))
+	if commit.Scope == commit.Type {
+		commit.Scope = ""
+	}
+	commit.Subject = strings.TrimRight(firstLine(commit.Subject), ". ")
+	if runes := []rune(commit.Subject); len(runes) > 1 && !isAcronym(runes) {
+		commit.Subject = strings.ToLower(string(runes[:1])) + string(runes[1:])
+	}
+	commit.Body = strings.TrimSpace(commit.Body)
+	if over := len([]rune(commit.Header())) - maxSubjectLength; over > 0 {
+		runes := []rune(commit.Subject)
+		commit.Subject = strings.TrimRight(string(runes[:max(len(runes)-over-1, 1)]), " ") + "…"
+	}
+	return commit
+}
+
+func sectionFor(commitType string) string {
+	switch commitType {
+	case "feat":
+		return "Added"
+	case "fix":
+		return "Fixed"
+	case "refactor", "perf", "build":
+		return "Changed"
+	default:
+		return ""
+	}
+}
+
+// sentence capitalizes a subject and ends it with a period, for a
+// CHANGELOG entry.
+func sentence(subject string) string {
+	subject = strings.TrimSuffix(subject, "…")
+	if subject == "" {
+		return ""
+	}
+	runes := []rune(subject)
+	return strings.ToUpper(string(runes[:1])) + string(runes[1:]) + "."
+}
+
+func firstLine(text string) string {
+	line, _, _ := strings.Cut(strings.TrimSpace(text), "\n")
+	return strings.TrimSpace(line)
+}
+
+// isAcronym reports whether text starts with two capitals, as in "API", so
+// lower-casing it would be wrong.
+func isAcronym(runes []rune) bool {
+	return len(runes) > 1 && strings.ToUpper(string(runes[:2])) == string(runes[:2]) && strings.ToLower(string(runes[:2])) != string(runes[:2])
+}
+
+func all(files []string, match func(string) bool) bool {
+	for _, file := range files {
+		if !match(file) {
+			return false
+		}
+	}
+	return true
+}
+
+func isDoc(file string) bool {
+	ext := path.Ext(file)
+	return ext == ".md" || ext == ".rst" || ext == ".txt" || strings.HasPrefix(file, "docs/")
+}
+
+func isTest(file string) bool {
+	return strings.HasSuffix(file, "_test.go") || strings.Contains(file, ".test.") || strings.Contains(file, ".spec.") ||
+		strings.HasPrefix(file, "test/") || strings.HasPrefix(file, "tests/") || strings.Contains(file, "/testdata/")
+}
+
+func isCI(file string) bool {
+	return strings.HasPrefix(file, ".github/workflows/") || strings.HasPrefix(file, ".circleci/") ||
+		strings.HasPrefix(file, ".buildkite/") || file == ".gitlab-ci.yml"
+}
+
+func isBuild(file string) bool {
+	switch path.Base(file) {
+	case "go.mod", "go.sum", "Makefile", "Dockerfile", "package.json", "package-lock.json", ".goreleaser.yml", ".goreleaser.yaml":
+		return true
+	}
+	return false
+}
+
+// LoadChangeset fetches a session's title, prompt, changes, and patch
+// within the scope of options.
+func LoadChangeset(ctx context.Context, client *jules.Client, sessionID string, options *workspace.PatchApplicationOptions) (Changeset, error) {
+	session, err := client.Sessions().Get(ctx, sessionID)
+	if err != nil {
+		return Changeset{}, fmt.Errorf("failed to get session %s: %w", sessionID, err)
+	}
+	changes, err := workspace.GetSessionChangesWithOptions(ctx, client, sessionID, options)
+	if err != nil {
+		return Changeset{}, err
+	}
+	if changes.TotalPatches == 0 {
+		return Changeset{}, fmt.Errorf("session %s has no changes", sessionID)
+	}
+	patch, err := workspace.SessionPatch(ctx, client, sessionID, options)
+	if err != nil {
+		return Changeset{}, err
+	}
+	return Changeset{SessionID: sessionID, Title: session.Title, Prompt: session.Prompt, Changes: changes, Patch: patch}, nil
+}
@@ -0,0 +1,184 @@
+package commitmsg
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/SamyRai/juleson/internal/gemini"
+	"github.com/SamyRai/juleson/internal/jules/workspace"
+)
+
+func changes(message string, files ...string) *workspace.SessionChanges {
+	result := &workspace.SessionChanges{TotalPatches: 1}
+	if message != "" {
+		result.SuggestedCommitMessages = []string{message}
+	}
+	for _, file := range files {
+		result.Files = append(result.Files, workspace.FileChange{Path: file, LinesAdded: 1})
+	}
+	return result
+}
+
+func TestHeuristic(t *testing.T) {
+	tests := []struct {
+		name      string
+		changeset Changeset
+		want      string
+	}{
+		{
+			name:      "suggested message",
+			changeset: Changeset{Changes: changes("Add retry jitter to the client.", "internal/config/config.go", "internal/config/config_test.go")},
+			want:      "feat(config): add retry jitter to the client",
+		},
+		{
+			name:      "already conventional",
+			changeset: Changeset{Changes: changes("fix(api)!: drop v1 endpoints\n\nThey were deprecated.", "api.go")},
+			want:      "fix(api)!: drop v1 endpoints\n\nThey were deprecated.",
+		},
+		{
+			name:      "fix from title",
+			changeset: Changeset{Title: "Fix crash when the config is empty", Changes: changes("", "cmd/main.go", "internal/app.go")},
+			want:      "fix: fix crash when the config is empty",
+		},
+		{
+			name:      "docs only",
+			changeset: Changeset{Title: "Explain setup", Changes: changes("", "README.md", "docs/setup.md")},
+			want:      "docs: explain setup",
+		},
+		{
+			name:      "acronym kept",
+			changeset: Changeset{Title: "API keys from the keychain", Changes: changes("", "internal/secrets/store.go")},
+			want:      "feat(secrets): API keys from the keychain",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := Heuristic([]Changeset{tt.changeset})
+			if got := result.Commits[0].String(); got != tt.want {
+				t.Errorf("message = %q, want %q", got, tt.want)
+			}
+			if result.Source != SourceHeuristic {
+				t.Errorf("source = %q", result.Source)
+			}
+		})
+	}
+}
+
+func TestHeuristicChangelog(t *testing.T) {
+	result := Heuristic([]Changeset{
+		{SessionID: "1", Title: "Add dark mode", Changes: changes("", "ui/theme.go")},
+		{SessionID: "2", Title: "Fix login redirect", Changes: changes("", "auth/login.go")},
+		{SessionID: "3", Title: "Cover the parser", Changes: changes("", "parser/parser_test.go")},
+	})
+	want := "### Added\n\n- Add dark mode.\n\n### Fixed\n\n- Fix login redirect.\n"
+	if result.Changelog != want {
+		t.Errorf("changelog = %q, want %q", result.Changelog, want)
+	}
+	if result.Commits[2].Type != "test" {
+		t.Errorf("third commit type = %q, want test", result.Commits[2].Type)
+	}
+}
+
+func TestNormalizeTruncatesHeader(t *testing.T) {
+	commit := normalize(Commit{Type: "feat", Scope: "cli", Subject: strings.Repeat("word ", 30)})
+	if got := len([]rune(commit.Header())); got > maxSubjectLength {
+		t.Errorf("header length = %d, want at most %d", got, maxSubjectLength)
+	}
+	if !strings.HasSuffix(commit.Subject, "…") {
+		t.Errorf("subject = %q, want an ellipsis", commit.Subject)
+	}
+}
+
+func TestGenerateWithGemini(t *testing.T) {
+	var prompt string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var request struct {
+			Contents []struct {
+				Parts []struct {
+					Text string `json:"text"`
+				} `json:"parts"`
+			} `json:"contents"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&request)
+		prompt = request.Contents[0].Parts[0].Text
+		answer := `{"commits":[{"type":"Feat","scope":"Theme","subject":"Add dark mode.","body":"Follows the OS."}],` +
+			`"changelog":[{"section":"Added","text":"Dark mode."},{"section":"Misc","text":"dropped"}]}`
+		response, _ := json.Marshal(map[string]any{"candidates": []any{map[string]any{"content": map[string]any{"parts": []any{map[string]string{"text": answer}}}}}})
+		_, _ = w.Write(response)
+	}))
+	defer server.Close()
+
+	client := gemini.NewClient(gemini.Config{APIKey: "k", BaseURL: server.URL})
+	result, err := Generate(context.Background(), client, []Changeset{{
+		SessionID: "s1",
+		Title:     "Dark mode",
+		Changes:   changes("", "ui/theme.go"),
+		Patch:     "diff --git a/ui/theme.go b/ui/theme.go\n",
+	}})
+	if err != nil {
+		t.Fatalf("Generate: %v", err)
+	}
+	if got := result.Commits[0].String(); got != "feat(theme): add dark mode\n\nFollows the OS." {
+		t.Errorf("message = %q", got)
+	}
+	if result.Commits[0].SessionID != "s1" || result.Source != SourceGemini {
+		t.Errorf("result = %+v", result)
+	}
+	if result.Changelog != "### Added\n\n- Dark mode.\n" {
+		t.Errorf("changelog = %q", result.Changelog)
+	}
+	for _, want := range []string{"session s1", "ui/theme.go (+1 -0)", "```diff\ndiff --git"} {
+		if !strings.Contains(prompt, want) {
+			t.Errorf("prompt is missing %q:\n%s", want, prompt)
+		}
+	}
+}
+
+func TestGenerateWithoutChangesets(t *testing.T) {
+	if _, err := Generate(context.Background(), nil, nil); err == nil {
+		t.Fatal("Generate without changesets succeeded")
+	}
+}
+
+func TestGenerateFallsBackWhenGeminiFails(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "quota exceeded", http.StatusTooManyRequests)
+	}))
+	defer server.Close()
+
+	client := gemini.NewClient(gemini.Config{APIKey: "k", BaseURL: server.URL})
+	result, err := Generate(context.Background(), client, []Changeset{{Title: "Add dark mode", Changes: changes("", "ui/theme.go")}})
+	if err != nil {
+		t.Fatalf("Generate: %v", err)
+	}
+	if result.Source != SourceHeuristic || !strings.Contains(result.Warning, "429") {
+		t.Errorf("source = %q, warning = %q", result.Source, result.Warning)
+	}
+	if got := result.Commits[0].Header(); got != "feat(ui): add dark mode" {
+		t.Errorf("header = %q", got)
+	}
+}
+
+func TestPullRequest(t *testing.T) {
+	result := Heuristic([]Changeset{{Changes: changes("Add dark mode\n\nFollows the OS.", "ui/theme.go")}})
+	title, body := result.PullRequest()
+	if title != "feat(ui): add dark mode" {
+		t.Errorf("title = %q", title)
+	}
+	if want := "Follows the OS.\n\n## Changelog\n\n#### Added\n\n- Add dark mode."; body != want {
+		t.Errorf("body = %q, want %q", body, want)
+	}
+
+	result = Heuristic([]Changeset{
+		{Title: "Add dark mode", Changes: changes("", "ui/theme.go")},
+		{Title: "Fix login redirect", Changes: changes("", "auth/login.go")},
+	})
+	_, body = result.PullRequest()
+	if !strings.HasPrefix(body, "- feat(ui): add dark mode\n- fix(auth): fix login redirect\n") {
+		t.Errorf("body = %q", body)
+	}
+}
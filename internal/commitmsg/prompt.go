@@ -0,0 +1,84 @@
+package commitmsg
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/SamyRai/juleson/internal/gemini"
+)
+
+const systemPrompt = `You write commit messages and release notes for code changes.
+For each changeset, in the order given, write one Conventional Commits message:
+a type, an optional scope naming the area changed, an imperative lower-case
+subject without a trailing period, and a body explaining what changed and why
+when the subject is not enough. Mark breaking changes. Then write CHANGELOG
+entries for changes users would notice, each a short sentence in one of the
+Keep a Changelog sections; leave out internal-only changes such as tests and
+refactors.`
+
+var responseSchema = &gemini.Schema{
+	Type: gemini.TypeObject,
+	Properties: map[string]*gemini.Schema{
+		"commits": {
+			Type:        gemini.TypeArray,
+			Description: "One commit message per changeset, in order.",
+			Items: &gemini.Schema{
+				Type: gemini.TypeObject,
+				Properties: map[string]*gemini.Schema{
+					"type":     {Type: gemini.TypeString, Enum: Types},
+					"scope":    {Type: gemini.TypeString},
+					"subject":  {Type: gemini.TypeString},
+					"body":     {Type: gemini.TypeString},
+					"breaking": {Type: gemini.TypeBoolean},
+				},
+				Required: []string{"type", "subject"},
+			},
+		},
+		"changelog": {
+			Type: gemini.TypeArray,
+			Items: &gemini.Schema{
+				Type: gemini.TypeObject,
+				Properties: map[string]*gemini.Schema{
+					"section": {Type: gemini.TypeString, Enum: Sections},
+					"text":    {Type: gemini.TypeString},
+				},
+				Required: []string{"section", "text"},
+			},
+		},
+	},
+	Required: []string{"commits", "changelog"},
+}
+
+// prompt describes the changesets, sharing maxPromptPatchBytes of patch
+// text between them.
+func prompt(changesets []Changeset) string {
+	budget := maxPromptPatchBytes / len(changesets)
+	var b strings.Builder
+	for i, changeset := range changesets {
+		fmt.Fprintf(&b, "## Changeset %d (session %s)\n\n", i+1, changeset.SessionID)
+		if changeset.Title != "" {
+			fmt.Fprintf(&b, "Title: %s\n", changeset.Title)
+		}
+		if changeset.Prompt != "" {
+			fmt.Fprintf(&b, "Task: %s\n", changeset.Prompt)
+		}
+		if changeset.Changes != nil {
+			for _, message := range changeset.Changes.SuggestedCommitMessages {
+				fmt.Fprintf(&b, "Suggested commit message: %s\n", message)
+			}
+			b.WriteString("Files:\n")
+			for _, file := range changeset.Changes.Files {
+				fmt.Fprintf(&b, "- %s (+%d -%d)\n", file.Label(), file.LinesAdded, file.LinesRemoved)
+			}
+		}
+		patch := changeset.Patch
+		if len(patch) > budget {
+			patch = patch[:budget] + "\n[patch truncated]\n"
+		}
+		if patch != "" {
+			fmt.Fprintf(&b, "\n```diff\n%s```\n", patch)
+		}
+		b.WriteString("\n")
+	}
+	return b.String()
+}
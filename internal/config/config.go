@@ -1,20 +1,72 @@
 package config
 
 import (
+	"errors"
 	"fmt"
+	"maps"
+	"net"
+	"net/url"
 	"os"
+	"path"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
 	"time"
 
+	"github.com/SamyRai/juleson/internal/alerts"
+	"github.com/SamyRai/juleson/internal/ci"
+	"github.com/SamyRai/juleson/internal/events"
+	"github.com/SamyRai/juleson/internal/gemini"
+	"github.com/SamyRai/juleson/internal/guard"
+	"github.com/SamyRai/juleson/internal/hooks"
+	"github.com/SamyRai/juleson/internal/integrations"
+	"github.com/SamyRai/juleson/internal/notify"
+	"github.com/SamyRai/juleson/internal/policy"
+	"github.com/SamyRai/juleson/internal/rbac"
+	"github.com/SamyRai/juleson/internal/sandbox"
+	"github.com/SamyRai/juleson/internal/secrets"
+	"github.com/SamyRai/juleson/internal/sinks"
+	"github.com/SamyRai/juleson/pkg/k8s"
+	"github.com/SamyRai/juleson/pkg/osv"
 	"github.com/spf13/viper"
 	gotenv "github.com/subosito/gotenv"
 )
 
 // Config represents the application configuration.
 type Config struct {
-	Templates TemplatesConfig `mapstructure:"templates"`
-	Diff      DiffConfig      `mapstructure:"diff"`
-	GitHub    GitHubConfig    `mapstructure:"github"`
-	Jules     JulesConfig     `mapstructure:"jules"`
+	Templates      TemplatesConfig      `mapstructure:"templates"`
+	Diff           DiffConfig           `mapstructure:"diff"`
+	Log            LogConfig            `mapstructure:"log"`
+	Audit          AuditConfig          `mapstructure:"audit"`
+	History        HistoryConfig        `mapstructure:"history"`
+	Encryption     EncryptionConfig     `mapstructure:"encryption"`
+	Retention      RetentionConfig      `mapstructure:"retention"`
+	Policy         PolicyConfig         `mapstructure:"policy"`
+	Sessions       SessionsConfig       `mapstructure:"sessions"`
+	Notifications  NotificationsConfig  `mapstructure:"notifications"`
+	Integrations   IntegrationsConfig   `mapstructure:"integrations"`
+	Events         EventsConfig         `mapstructure:"events"`
+	Alerts         AlertsConfig         `mapstructure:"alerts"`
+	Watch          WatchConfig          `mapstructure:"watch"`
+	Hooks          HooksConfig          `mapstructure:"hooks"`
+	Guard          GuardConfig          `mapstructure:"guard"`
+	Bench          BenchConfig          `mapstructure:"bench"`
+	Sandbox        SandboxConfig        `mapstructure:"sandbox"`
+	Kubernetes     KubernetesConfig     `mapstructure:"kubernetes"`
+	Analysis       AnalysisConfig       `mapstructure:"analysis"`
+	CircuitBreaker CircuitBreakerConfig `mapstructure:"circuit_breaker"`
+	Health         HealthConfig         `mapstructure:"health"`
+	MCP            MCPConfig            `mapstructure:"mcp"`
+	GitHub         GitHubConfig         `mapstructure:"github"`
+	GitLab         GitLabConfig         `mapstructure:"gitlab"`
+	Bitbucket      BitbucketConfig      `mapstructure:"bitbucket"`
+	CI             CIConfig             `mapstructure:"ci"`
+	Jules          JulesConfig          `mapstructure:"jules"`
+	Gemini         GeminiConfig         `mapstructure:"gemini"`
 }
 
 // JulesConfig contains Jules API configuration.
@@ -23,17 +75,918 @@ type JulesConfig struct {
 	BaseURL       string        `mapstructure:"base_url"`
 	Timeout       time.Duration `mapstructure:"timeout"`
 	RetryAttempts int           `mapstructure:"retry_attempts"`
-	DebugLog      bool          `mapstructure:"debug_log"`
+	// RetryBackoff is the delay before the first retry; each retry doubles
+	// it, up to RetryMaxBackoff.
+	RetryBackoff    time.Duration `mapstructure:"retry_backoff"`
+	RetryMaxBackoff time.Duration `mapstructure:"retry_max_backoff"`
+	// RetryJitter shortens each backoff by a random fraction up to this
+	// value, from 0 to 1, so clients do not retry in lockstep.
+	RetryJitter float64 `mapstructure:"retry_jitter"`
+	RateLimit   float64 `mapstructure:"rate_limit"`
+	DebugLog    bool    `mapstructure:"debug_log"`
+}
+
+// GeminiConfig configures the Gemini API, used to write commit messages and
+// changelog entries for session changes and to rank the parts of attached
+// files by relevance. Without an API key those are derived from the changes
+// and files themselves.
+type GeminiConfig struct {
+	// APIKey falls back to GEMINI_API_KEY and then to the key stored by
+	// `juleson auth login --gemini-api-key`.
+	APIKey         string        `mapstructure:"api_key"`
+	Model          string        `mapstructure:"model"`
+	EmbeddingModel string        `mapstructure:"embedding_model"`
+	BaseURL        string        `mapstructure:"base_url"`
+	Timeout        time.Duration `mapstructure:"timeout"`
+	// FallbackModels follow Model when it fails, such as when it is
+	// rate-limited.
+	FallbackModels []string `mapstructure:"fallback_models"`
+	// Models are the chains of models of each tier, such as fast and
+	// large, and Routes send call sites to tiers.
+	Models map[string][]string `mapstructure:"models"`
+	Routes map[string]string   `mapstructure:"routes"`
+	// Temperature is left to the model when unset.
+	Temperature *float64 `mapstructure:"temperature"`
+	// CacheTTL is how long identical requests are answered from the user
+	// cache directory; 0 disables the cache.
+	CacheTTL time.Duration `mapstructure:"cache_ttl"`
+	// Replay is record or replay to record responses to, or serve them
+	// from, Cassette instead of the API.
+	Replay   string `mapstructure:"replay"`
+	Cassette string `mapstructure:"cassette"`
+}
+
+// LogConfig contains logging settings.
+type LogConfig struct {
+	// Level is debug, info, warn, or error. Empty means info, or debug when
+	// jules.debug_log is set.
+	Level string `mapstructure:"level"`
+	// Format is text, json, or auto (themed output on terminals).
+	Format string `mapstructure:"format"`
+	// Subsystems overrides the level for jules, mcp, events, config, or
+	// gemini.
+	Subsystems map[string]string `mapstructure:"subsystems"`
+	// Sinks lists log destinations. Empty writes to the command's default
+	// output.
+	Sinks []LogSinkConfig `mapstructure:"sinks"`
+}
+
+// LogSinkConfig configures one log destination.
+type LogSinkConfig struct {
+	// Type is stderr, stdout, file, or syslog.
+	Type       string `mapstructure:"type"`
+	Path       string `mapstructure:"path"`
+	Address    string `mapstructure:"address"`
+	Format     string `mapstructure:"format"`
+	Level      string `mapstructure:"level"`
+	MaxSizeMB  int    `mapstructure:"max_size_mb"`
+	MaxBackups int    `mapstructure:"max_backups"`
+}
+
+// AuditConfig contains audit log settings.
+type AuditConfig struct {
+	Enabled bool `mapstructure:"enabled"`
+	// Path is the JSONL audit journal. Empty means audit.jsonl in the user
+	// config directory.
+	Path string `mapstructure:"path"`
+}
+
+// HistoryConfig controls the run history of split template runs.
+type HistoryConfig struct {
+	Enabled bool `mapstructure:"enabled"`
+	// Path is the JSONL run journal. Empty means history.jsonl in the user
+	// config directory.
+	Path string `mapstructure:"path"`
+}
+
+// EncryptionConfig encrypts data at rest with AES-256-GCM: the audit log,
+// run history, their projection snapshots, and downloaded session and CI
+// artifacts. The key is JULESON_ENCRYPTION_KEY, base64, such as one a KMS
+// injects, or else one generated on first use and kept in the keychain or
+// encrypted secrets file.
+type EncryptionConfig struct {
+	Enabled bool `mapstructure:"enabled"`
+}
+
+// RetentionConfig limits how much the audit log, run history, and
+// downloaded artifacts keep. 'juleson gc' applies it, and 'mcp serve'
+// applies it every Interval.
+type RetentionConfig struct {
+	// Interval between compactions while 'mcp serve' runs. Zero disables
+	// them.
+	Interval  time.Duration           `mapstructure:"interval"`
+	Audit     RetentionRuleConfig     `mapstructure:"audit"`
+	History   RetentionRuleConfig     `mapstructure:"history"`
+	Artifacts ArtifactRetentionConfig `mapstructure:"artifacts"`
+}
+
+// RetentionRuleConfig removes entries older than MaxAge, then the oldest
+// until at most MaxSizeMB remain. Zero disables a limit.
+type RetentionRuleConfig struct {
+	MaxAge    time.Duration `mapstructure:"max_age"`
+	MaxSizeMB int           `mapstructure:"max_size_mb"`
+}
+
+// Enabled reports whether the rule limits anything.
+func (r RetentionRuleConfig) Enabled() bool {
+	return r.MaxAge > 0 || r.MaxSizeMB > 0
+}
+
+// ArtifactRetentionConfig prunes the files in the directories artifacts
+// are downloaded into.
+type ArtifactRetentionConfig struct {
+	RetentionRuleConfig `mapstructure:",squash"`
+	Dirs                []string `mapstructure:"dirs"`
+}
+
+// PolicyConfig contains rules for risky operations.
+type PolicyConfig struct {
+	// Rules are evaluated in order; the first match decides.
+	Rules []PolicyRuleConfig `mapstructure:"rules"`
+	// ApprovalsPath is the file shared by requesters and second approvers.
+	// Empty means approvals.json in the user config directory.
+	ApprovalsPath string `mapstructure:"approvals_path"`
+	// Budget caps sessions created and files changed.
+	Budget BudgetConfig `mapstructure:"budget"`
+}
+
+// BudgetConfig caps how much work Jules is asked to do. Zero means no limit.
+type BudgetConfig struct {
+	// MaxSessionsPerDay caps the sessions created in any 24 hours, counted
+	// from the audit log.
+	MaxSessionsPerDay int `mapstructure:"max_sessions_per_day"`
+	// MaxFilesChanged caps the files a single patch apply may change.
+	MaxFilesChanged int `mapstructure:"max_files_changed"`
+	// ConfirmAbove requires --confirm to start work whose estimated session
+	// time exceeds it.
+	ConfirmAbove time.Duration `mapstructure:"confirm_above"`
+}
+
+// PolicyBudget converts the configured budget to a policy.Budget.
+func (c PolicyConfig) PolicyBudget() policy.Budget {
+	return policy.Budget{MaxSessionsPerDay: c.Budget.MaxSessionsPerDay, MaxFilesChanged: c.Budget.MaxFilesChanged}
+}
+
+// PolicyRuleConfig maps an operation, optionally scoped to a tool and a
+// repository, to allow, confirm, approve, or deny.
+type PolicyRuleConfig struct {
+	Operation string `mapstructure:"operation"`
+	Tool      string `mapstructure:"tool"`
+	Repo      string `mapstructure:"repo"`
+	Decision  string `mapstructure:"decision"`
+}
+
+// PolicyRules converts the configured rules for the policy engine.
+func (c PolicyConfig) PolicyRules() []policy.Rule {
+	rules := make([]policy.Rule, 0, len(c.Rules))
+	for _, rule := range c.Rules {
+		rules = append(rules, policy.Rule{
+			Operation: rule.Operation,
+			Tool:      rule.Tool,
+			Repo:      rule.Repo,
+			Decision:  policy.Decision(rule.Decision),
+		})
+	}
+	return rules
+}
+
+// SessionsConfig sets defaults for the sessions Juleson creates.
+type SessionsConfig struct {
+	Defaults SessionDefaultsConfig `mapstructure:"defaults"`
+	// Repos override Defaults for matching repositories, in order.
+	Repos []SessionDefaultsConfig `mapstructure:"repos"`
+}
+
+// SessionDefaultsConfig holds session settings used when a command or tool
+// does not set them. Empty fields leave the setting alone.
+type SessionDefaultsConfig struct {
+	// Repo is an owner/name path.Match glob such as "my-org/*". It is
+	// ignored in sessions.defaults.
+	Repo                string `mapstructure:"repo"`
+	StartingBranch      string `mapstructure:"starting_branch"`
+	RequirePlanApproval *bool  `mapstructure:"require_plan_approval"`
+	AutomationMode      string `mapstructure:"automation_mode"`
+	// Labels are added to pull requests by 'juleson pr label'; Jules opens
+	// the pull requests itself and cannot label them.
+	Labels []string `mapstructure:"labels"`
+}
+
+// For returns the defaults for repo (owner/name, or empty for repoless
+// sessions): sessions.defaults overlaid with each matching sessions.repos
+// entry.
+func (c SessionsConfig) For(repo string) SessionDefaultsConfig {
+	defaults := c.Defaults
+	defaults.Repo = ""
+	for _, entry := range c.Repos {
+		if repo == "" || !repoGlobMatch(entry.Repo, repo) {
+			continue
+		}
+		if entry.StartingBranch != "" {
+			defaults.StartingBranch = entry.StartingBranch
+		}
+		if entry.RequirePlanApproval != nil {
+			defaults.RequirePlanApproval = entry.RequirePlanApproval
+		}
+		if entry.AutomationMode != "" {
+			defaults.AutomationMode = entry.AutomationMode
+		}
+		if entry.Labels != nil {
+			defaults.Labels = entry.Labels
+		}
+	}
+	return defaults
+}
+
+func repoGlobMatch(pattern, repo string) bool {
+	ok, err := path.Match(strings.ToLower(pattern), strings.ToLower(repo))
+	return err == nil && ok
+}
+
+// NotificationsConfig sends key events to Slack, email, and the desktop.
+type NotificationsConfig struct {
+	Slack   SlackNotificationsConfig   `mapstructure:"slack"`
+	Email   EmailNotificationsConfig   `mapstructure:"email"`
+	Desktop DesktopNotificationsConfig `mapstructure:"desktop"`
+	// Rules route events to channels. Without rules, the default key events
+	// go to every configured channel.
+	Rules []NotificationRuleConfig `mapstructure:"rules"`
+}
+
+// SlackNotificationsConfig configures a Slack incoming webhook.
+type SlackNotificationsConfig struct {
+	// WebhookURL falls back to SLACK_WEBHOOK_URL.
+	WebhookURL string `mapstructure:"webhook_url"`
+}
+
+// EmailNotificationsConfig configures an SMTP server.
+type EmailNotificationsConfig struct {
+	Host     string `mapstructure:"host"`
+	Port     int    `mapstructure:"port"`
+	Username string `mapstructure:"username"`
+	// Password falls back to JULESON_SMTP_PASSWORD.
+	Password string   `mapstructure:"password"`
+	From     string   `mapstructure:"from"`
+	To       []string `mapstructure:"to"`
+}
+
+// DesktopNotificationsConfig enables native desktop notifications.
+type DesktopNotificationsConfig struct {
+	Enabled bool `mapstructure:"enabled"`
+}
+
+// NotificationRuleConfig routes events matching every set field to
+// channels.
+type NotificationRuleConfig struct {
+	// Events are event type globs such as "session.*".
+	Events []string `mapstructure:"events"`
+	Topics []string `mapstructure:"topics"`
+	// Repo is an owner/name repository.
+	Repo string `mapstructure:"repo"`
+	// MinSeverity is info, warning, or error.
+	MinSeverity string   `mapstructure:"min_severity"`
+	Channels    []string `mapstructure:"channels"`
+}
+
+// NotifyOptions converts the settings for the notify package. Invalid
+// severities are reported by validation and treated as info.
+func (c NotificationsConfig) NotifyOptions() notify.Config {
+	rules := make([]notify.Rule, 0, len(c.Rules))
+	for _, rule := range c.Rules {
+		severity, _ := events.ParseSeverity(rule.MinSeverity)
+		rules = append(rules, notify.Rule{
+			Types:    rule.Events,
+			Topics:   rule.Topics,
+			Match:    events.EventMatch{Repository: rule.Repo, MinSeverity: severity},
+			Channels: rule.Channels,
+		})
+	}
+	return notify.Config{
+		Slack: notify.SlackConfig{WebhookURL: c.Slack.WebhookURL},
+		Email: notify.EmailConfig{
+			Host:     c.Email.Host,
+			Port:     c.Email.Port,
+			Username: c.Email.Username,
+			Password: c.Email.Password,
+			From:     c.Email.From,
+			To:       c.Email.To,
+		},
+		Desktop: c.Desktop.Enabled,
+		Rules:   rules,
+	}
+}
+
+// IntegrationsConfig mirrors tasks and sessions to issue trackers.
+type IntegrationsConfig struct {
+	Jira   JiraIntegrationConfig   `mapstructure:"jira"`
+	Linear LinearIntegrationConfig `mapstructure:"linear"`
+	// IssuesPath records which issues mirror which sessions. Defaults to
+	// issues.json in the user config directory.
+	IssuesPath string `mapstructure:"issues_path"`
+}
+
+// JiraIntegrationConfig configures a Jira project. It is used when
+// base_url is set.
+type JiraIntegrationConfig struct {
+	BaseURL string `mapstructure:"base_url"`
+	// Email selects basic auth for Jira Cloud; without it Token is sent as
+	// a personal access token.
+	Email string `mapstructure:"email"`
+	// Token falls back to JIRA_API_TOKEN.
+	Token     string   `mapstructure:"token"`
+	Project   string   `mapstructure:"project"`
+	IssueType string   `mapstructure:"issue_type"`
+	Labels    []string `mapstructure:"labels"`
+	// Transitions name the transition or status for started, completed,
+	// and failed work.
+	Transitions IssueStatesConfig `mapstructure:"transitions"`
+}
+
+// LinearIntegrationConfig configures a Linear team. It is used when team_id
+// is set.
+type LinearIntegrationConfig struct {
+	// APIKey falls back to LINEAR_API_KEY.
+	APIKey string `mapstructure:"api_key"`
+	TeamID string `mapstructure:"team_id"`
+	// States name the workflow state for started, completed, and failed
+	// work.
+	States IssueStatesConfig `mapstructure:"states"`
+}
+
+// IssueStatesConfig names tracker states. An empty name leaves the issue
+// where it is.
+type IssueStatesConfig struct {
+	Started   string `mapstructure:"started"`
+	Completed string `mapstructure:"completed"`
+	Failed    string `mapstructure:"failed"`
+}
+
+func (c IssueStatesConfig) byStatus() map[integrations.Status]string {
+	return map[integrations.Status]string{
+		integrations.StatusStarted:   c.Started,
+		integrations.StatusCompleted: c.Completed,
+		integrations.StatusFailed:    c.Failed,
+	}
+}
+
+// IntegrationOptions converts the settings for the integrations package.
+func (c IntegrationsConfig) IntegrationOptions() integrations.Config {
+	return integrations.Config{
+		Jira: integrations.JiraConfig{
+			BaseURL:     c.Jira.BaseURL,
+			Email:       c.Jira.Email,
+			Token:       c.Jira.Token,
+			Project:     c.Jira.Project,
+			IssueType:   c.Jira.IssueType,
+			Labels:      c.Jira.Labels,
+			Transitions: c.Jira.Transitions.byStatus(),
+		},
+		Linear: integrations.LinearConfig{
+			APIKey: c.Linear.APIKey,
+			TeamID: c.Linear.TeamID,
+			States: c.Linear.States.byStatus(),
+		},
+	}
+}
+
+// EventsConfig exports events to external systems.
+type EventsConfig struct {
+	Sinks []EventSinkConfig `mapstructure:"sinks"`
+}
+
+// EventSinkConfig configures a Kafka, webhook, or file sink and the events
+// it receives.
+type EventSinkConfig struct {
+	Name string `mapstructure:"name"`
+	// Type is kafka, webhook, or file.
+	Type string `mapstructure:"type"`
+	// URL is the Kafka REST Proxy or the HTTPS webhook endpoint.
+	URL string `mapstructure:"url"`
+	// Topic is the Kafka topic.
+	Topic    string `mapstructure:"topic"`
+	Username string `mapstructure:"username"`
+	Password string `mapstructure:"password"`
+	// Headers are added to webhook requests.
+	Headers map[string]string `mapstructure:"headers"`
+	// Secret signs webhook bodies with HMAC-SHA256.
+	Secret string `mapstructure:"secret"`
+	// Path is the JSON lines file events are appended to.
+	Path string `mapstructure:"path"`
+	// Events are event type globs such as "session.*".
+	Events        []string      `mapstructure:"events"`
+	Topics        []string      `mapstructure:"topics"`
+	BatchSize     int           `mapstructure:"batch_size"`
+	FlushInterval time.Duration `mapstructure:"flush_interval"`
+	MaxRetries    int           `mapstructure:"max_retries"`
+}
+
+// SinkOptions converts the settings for the sinks package.
+func (c EventsConfig) SinkOptions() []sinks.Config {
+	options := make([]sinks.Config, 0, len(c.Sinks))
+	for _, sink := range c.Sinks {
+		options = append(options, sinks.Config{
+			Name:          sink.Name,
+			Type:          sink.Type,
+			URL:           sink.URL,
+			Topic:         sink.Topic,
+			Username:      sink.Username,
+			Password:      sink.Password,
+			Headers:       sink.Headers,
+			Secret:        sink.Secret,
+			Path:          sink.Path,
+			Topics:        sink.Topics,
+			Types:         sink.Events,
+			BatchSize:     sink.BatchSize,
+			FlushInterval: sink.FlushInterval,
+			MaxRetries:    sink.MaxRetries,
+		})
+	}
+	return options
+}
+
+// AlertsConfig raises alerts when events or metrics cross thresholds.
+type AlertsConfig struct {
+	// Cooldown is the least time between two alerts of a rule.
+	Cooldown time.Duration `mapstructure:"cooldown"`
+	// Interval is how often metric rules are evaluated.
+	Interval time.Duration     `mapstructure:"interval"`
+	Rules    []AlertRuleConfig `mapstructure:"rules"`
+}
+
+// AlertRuleConfig is an event rule, counting events of the Events types
+// within Window, or a metric rule, watching Metric for For. Either fires
+// above Threshold.
+type AlertRuleConfig struct {
+	Name string `mapstructure:"name"`
+	// Events are event type globs such as "session.failed".
+	Events []string      `mapstructure:"events"`
+	Window time.Duration `mapstructure:"window"`
+	// Metric is queue_backlog, dead_letters, or breakers_open.
+	Metric    string        `mapstructure:"metric"`
+	For       time.Duration `mapstructure:"for"`
+	Threshold float64       `mapstructure:"threshold"`
+	// Severity is info, warning, or error; it defaults to warning.
+	Severity string `mapstructure:"severity"`
+}
+
+// AlertOptions converts the settings for the alerts package. Invalid
+// severities are reported by validation and treated as warnings.
+func (c AlertsConfig) AlertOptions() alerts.Config {
+	rules := make([]alerts.Rule, 0, len(c.Rules))
+	for _, rule := range c.Rules {
+		severity, err := events.ParseSeverity(rule.Severity)
+		if err != nil {
+			severity = events.SeverityWarning
+		}
+		rules = append(rules, alerts.Rule{
+			Name:      rule.Name,
+			Events:    rule.Events,
+			Window:    rule.Window,
+			Metric:    rule.Metric,
+			For:       rule.For,
+			Threshold: rule.Threshold,
+			Severity:  severity,
+		})
+	}
+	return alerts.Config{Rules: rules, Cooldown: c.Cooldown, Interval: c.Interval}
+}
+
+// WatchConfig configures juleson watch, which fires triggers when files in
+// the working tree change.
+type WatchConfig struct {
+	// Debounce is how long the tree must be quiet before triggers fire.
+	Debounce time.Duration `mapstructure:"debounce"`
+	// Ignore are file and directory names never watched, in addition to
+	// version control, dependency, and build directories.
+	Ignore   []string             `mapstructure:"ignore"`
+	Triggers []WatchTriggerConfig `mapstructure:"triggers"`
+}
+
+// HooksConfig selects the checks of the git hooks juleson hooks install
+// writes: secrets, smells, tests, and review.
+type HooksConfig struct {
+	PreCommit []string `mapstructure:"pre_commit"`
+	PrePush   []string `mapstructure:"pre_push"`
+	// Strict makes smells and review findings stop the commit or push, as
+	// secrets and failing tests do.
+	Strict bool `mapstructure:"strict"`
+}
+
+// GuardConfig configures the content guard, which scans prompts sent to
+// Jules for prompt injection and dangerous instructions, and the plans and
+// patches Jules produces for dangerous instructions before they are approved
+// or applied.
+type GuardConfig struct {
+	// Prompts and Outputs are block, flag, or off.
+	Prompts string `mapstructure:"prompts"`
+	Outputs string `mapstructure:"outputs"`
+	// Allow lists rule IDs that never match.
+	Allow []string `mapstructure:"allow"`
+}
+
+// GuardOptions converts the configuration to guard options.
+func (c GuardConfig) GuardOptions() guard.Options {
+	return guard.Options{Prompts: c.Prompts, Outputs: c.Outputs, Allow: c.Allow}
+}
+
+// BenchConfig configures juleson dev bench and the benchmark step of
+// juleson dev check, which fails when a benchmark exceeds its budget or
+// regresses from the saved baseline by more than MaxRegression percent.
+type BenchConfig struct {
+	Packages  []string `mapstructure:"packages"`
+	Pattern   string   `mapstructure:"pattern"`
+	Benchtime string   `mapstructure:"benchtime"`
+	Count     int      `mapstructure:"count"`
+	// Baseline is the file juleson dev bench --save-baseline writes.
+	Baseline      string              `mapstructure:"baseline"`
+	MaxRegression float64             `mapstructure:"max_regression"`
+	Budgets       []BenchBudgetConfig `mapstructure:"budgets"`
+}
+
+// Enabled reports whether juleson dev check runs the benchmarks: only
+// when there is something to check them against.
+func (c BenchConfig) Enabled() bool {
+	return len(c.Budgets) > 0 || c.MaxRegression > 0
+}
+
+// BenchBudgetConfig caps the cost per operation of the benchmarks whose
+// names match Name, such as "BenchmarkBusPublish/*". Zero limits are not
+// checked.
+type BenchBudgetConfig struct {
+	Name           string  `mapstructure:"name"`
+	MaxNsPerOp     float64 `mapstructure:"max_ns_per_op"`
+	MaxBytesPerOp  float64 `mapstructure:"max_bytes_per_op"`
+	MaxAllocsPerOp float64 `mapstructure:"max_allocs_per_op"`
+}
+
+// WatchTriggerConfig runs a juleson command line (Run) or another program
+// (Command) when files matching Paths change. Commands are split on
+// whitespace, without a shell.
+type WatchTriggerConfig struct {
+	Name string `mapstructure:"name"`
+	// Paths are globs such as "*.go" or "docs/"; empty matches every file.
+	Paths   []string `mapstructure:"paths"`
+	Run     string   `mapstructure:"run"`
+	Command string   `mapstructure:"command"`
+}
+
+// SandboxConfig limits what MCP clients may run in containers.
+type SandboxConfig struct {
+	// Images are allow-listed image globs such as "golang:*".
+	Images []string `mapstructure:"images"`
+	// Commands are the executables clients may run in those images.
+	Commands  []string      `mapstructure:"commands"`
+	CPUs      float64       `mapstructure:"cpus"`
+	MemoryMB  int           `mapstructure:"memory_mb"`
+	PidsLimit int           `mapstructure:"pids_limit"`
+	Timeout   time.Duration `mapstructure:"timeout"`
+	// Network lets clients request network access; containers have none
+	// otherwise.
+	Network           bool `mapstructure:"network"`
+	WritableWorkspace bool `mapstructure:"writable_workspace"`
+}
+
+// SandboxOptions converts the settings for the sandbox package.
+func (c SandboxConfig) SandboxOptions() sandbox.Config {
+	return sandbox.Config{
+		Images:            c.Images,
+		Commands:          c.Commands,
+		CPUs:              c.CPUs,
+		MemoryMB:          c.MemoryMB,
+		PidsLimit:         c.PidsLimit,
+		Timeout:           c.Timeout,
+		Network:           c.Network,
+		WritableWorkspace: c.WritableWorkspace,
+	}
+}
+
+// KubernetesConfig selects the cluster MCP Kubernetes tools talk to.
+type KubernetesConfig struct {
+	// Kubeconfig is the kubeconfig path. Empty means $KUBECONFIG or
+	// ~/.kube/config.
+	Kubeconfig string `mapstructure:"kubeconfig"`
+	// Context is the default context. Empty means the kubeconfig's current
+	// context.
+	Context string `mapstructure:"context"`
+	// Contexts are context globs clients may select. Empty allows only the
+	// default context.
+	Contexts []string `mapstructure:"contexts"`
+	// Namespace overrides the context's namespace.
+	Namespace string `mapstructure:"namespace"`
+}
+
+// ContextAllowed reports whether clients may select name. An empty name
+// selects the default context and is always allowed.
+func (c KubernetesConfig) ContextAllowed(name string) bool {
+	if name == "" || name == c.Context {
+		return true
+	}
+	for _, pattern := range c.Contexts {
+		if ok, _ := path.Match(pattern, name); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// ClientOptions returns k8s client options for context, or the default
+// context when empty.
+func (c KubernetesConfig) ClientOptions(context string) k8s.Options {
+	if context == "" {
+		context = c.Context
+	}
+	return k8s.Options{Kubeconfig: c.Kubeconfig, Context: context, Namespace: c.Namespace}
+}
+
+// AnalysisConfig configures the dependency audit.
+type AnalysisConfig struct {
+	// OSVURL is the base URL of the OSV vulnerability API.
+	OSVURL string `mapstructure:"osv_url"`
+	// AllowedLicenses are the SPDX identifiers dependencies may use. Empty
+	// disables the license check.
+	AllowedLicenses []string `mapstructure:"allowed_licenses"`
+}
+
+// CircuitBreakerConfig contains circuit breaker settings for event
+// processing and external API calls.
+type CircuitBreakerConfig struct {
+	MaxFailures  int           `mapstructure:"max_failures"`
+	Timeout      time.Duration `mapstructure:"timeout"`
+	ResetTimeout time.Duration `mapstructure:"reset_timeout"`
+}
+
+// HealthConfig configures health check endpoints for long-running commands.
+type HealthConfig struct {
+	// Listen is the address, such as 127.0.0.1:8081, on which 'mcp serve'
+	// serves /healthz and /readyz. Empty disables the endpoints.
+	Listen string `mapstructure:"listen"`
+}
+
+// MCPConfig configures 'mcp serve'.
+type MCPConfig struct {
+	// Tokens authenticate clients of the HTTP transport and grant each a
+	// role. Without tokens the HTTP transport, like stdio, accepts every
+	// client with every tool.
+	Tokens []MCPTokenConfig `mapstructure:"tokens"`
+	// Tenants host several teams on one HTTP server, each selected by the
+	// tenant of its tokens.
+	Tenants []MCPTenantConfig `mapstructure:"tenants"`
+}
+
+// MCPTokenConfig is a bearer token and the role it grants: viewer for
+// read-only tools, operator for mutating ones, or admin for dangerous ones.
+// Set Token, usually as an environment variable reference such as
+// ${JULESON_MCP_TOKEN}, or TokenSHA256 to keep the token out of the config.
+type MCPTokenConfig struct {
+	// Name identifies the token's holder in the audit log.
+	Name        string `mapstructure:"name"`
+	Token       string `mapstructure:"token"`
+	TokenSHA256 string `mapstructure:"token_sha256"`
+	Role        string `mapstructure:"role"`
+	// Tenant names the tenant of mcp.tenants the token's calls act as.
+	// Empty means the configuration itself.
+	Tenant string `mapstructure:"tenant"`
+}
+
+// MCPTenantConfig isolates a team served by 'mcp serve --http': its calls
+// use its own Jules and GitHub credentials and keep their audit log, run
+// history, approvals, and issue links in DataDir, apart from other tenants.
+// Tenants share no other credentials, so GitLab, Bitbucket, CI, Gemini,
+// issue trackers, notifications, and event sinks are off for them, and
+// nothing of the host: their servers have no Git, Dev, Docker, Kubernetes,
+// Terraform, or analysis tools, and no kubeconfig or sandbox settings.
+type MCPTenantConfig struct {
+	Name string `mapstructure:"name"`
+	// JulesAPIKey replaces jules.api_key and is required. GitHubToken
+	// replaces github.token; empty leaves the tenant without GitHub access.
+	// Environment variables are expanded.
+	JulesAPIKey string `mapstructure:"jules_api_key"`
+	GitHubToken string `mapstructure:"github_token"`
+	// DataDir defaults to tenants/NAME in the user config directory.
+	DataDir string `mapstructure:"data_dir"`
+}
+
+// ForTenant returns a copy of c that acts as tenant: with only the tenant's
+// credentials, and with the audit log, run history, approvals, and issue
+// links in the tenant's data directory. It fails when the tenant's Jules
+// API key is empty rather than fall back to the shared one.
+func (c *Config) ForTenant(tenant MCPTenantConfig) (*Config, error) {
+	key := os.ExpandEnv(tenant.JulesAPIKey)
+	if key == "" {
+		return nil, fmt.Errorf("jules_api_key of tenant %s is empty; tenants do not share jules.api_key", tenant.Name)
+	}
+	scoped, err := c.TenantStorage(tenant)
+	if err != nil {
+		return nil, err
+	}
+	scoped.Jules.APIKey = key
+	scoped.GitHub.Token = os.ExpandEnv(tenant.GitHubToken)
+	return scoped, nil
+}
+
+// TenantStorage returns a copy of c with the audit log, run history,
+// approvals, and issue links of tenant and without any credentials,
+// clusters, or sandbox settings, for maintaining the tenant's data such as
+// by gc and export.
+func (c *Config) TenantStorage(tenant MCPTenantConfig) (*Config, error) {
+	dir := os.ExpandEnv(tenant.DataDir)
+	if dir == "" {
+		configDir, err := os.UserConfigDir()
+		if err != nil {
+			return nil, fmt.Errorf("failed to locate user config directory: %w", err)
+		}
+		dir = filepath.Join(configDir, "juleson", "tenants", tenant.Name)
+	}
+	scoped := *c
+	scoped.Jules.APIKey = ""
+	scoped.Gemini.APIKey = ""
+	scoped.GitHub.Token = ""
+	scoped.GitHub.Hosts = make([]GitHubHostConfig, len(c.GitHub.Hosts))
+	for i, host := range c.GitHub.Hosts {
+		host.Token = ""
+		scoped.GitHub.Hosts[i] = host
+	}
+	scoped.GitLab.Token = ""
+	scoped.Bitbucket.Token = ""
+	scoped.CI.CircleCI.Token = ""
+	scoped.CI.Buildkite.Token = ""
+	scoped.Integrations = IntegrationsConfig{IssuesPath: filepath.Join(dir, "issues.json")}
+	scoped.Notifications = NotificationsConfig{}
+	scoped.Events = EventsConfig{}
+	scoped.Kubernetes = KubernetesConfig{}
+	scoped.Sandbox = SandboxConfig{}
+	scoped.Audit.Path = filepath.Join(dir, "audit.jsonl")
+	scoped.History.Path = filepath.Join(dir, "history.jsonl")
+	scoped.Policy.ApprovalsPath = filepath.Join(dir, "approvals.json")
+	scoped.MCP = MCPConfig{}
+	return &scoped, nil
+}
+
+// Tenant returns the tenant named name.
+func (c MCPConfig) Tenant(name string) (MCPTenantConfig, bool) {
+	for _, tenant := range c.Tenants {
+		if tenant.Name == name {
+			return tenant, true
+		}
+	}
+	return MCPTenantConfig{}, false
+}
+
+// RBACTokens converts the tokens for the rbac package, expanding
+// environment variables in Token.
+func (c MCPConfig) RBACTokens() ([]rbac.Token, error) {
+	tokens := make([]rbac.Token, 0, len(c.Tokens))
+	for i, entry := range c.Tokens {
+		role, err := rbac.ParseRole(entry.Role)
+		if err != nil {
+			return nil, fmt.Errorf("mcp.tokens[%d]: %w", i, err)
+		}
+		token := os.ExpandEnv(entry.Token)
+		switch {
+		case token != "" && entry.TokenSHA256 != "":
+			return nil, fmt.Errorf("mcp.tokens[%d]: set token or token_sha256, not both", i)
+		case token != "":
+			tokens = append(tokens, rbac.Token{Name: entry.Name, SHA256: rbac.HashToken(token), Role: role, Tenant: entry.Tenant})
+		case entry.TokenSHA256 != "":
+			tokens = append(tokens, rbac.Token{Name: entry.Name, SHA256: strings.ToLower(entry.TokenSHA256), Role: role, Tenant: entry.Tenant})
+		default:
+			return nil, fmt.Errorf("mcp.tokens[%d]: token or token_sha256 is required", i)
+		}
+	}
+	return tokens, nil
 }
 
 // GitHubConfig contains GitHub API configuration.
 type GitHubConfig struct {
 	Token      string                `mapstructure:"token"`
 	DefaultOrg string                `mapstructure:"default_org"`
+	BaseURL    string                `mapstructure:"base_url"`
+	UploadURL  string                `mapstructure:"upload_url"`
+	Hosts      []GitHubHostConfig    `mapstructure:"hosts"`
 	PR         GitHubPRConfig        `mapstructure:"pr"`
 	Discovery  GitHubDiscoveryConfig `mapstructure:"discovery"`
 }
 
+// GitLabConfig contains API settings for a GitLab instance, used for
+// repositories hosted there instead of on GitHub.
+type GitLabConfig struct {
+	// Host is the GitLab host. Defaults to gitlab.com.
+	Host string `mapstructure:"host"`
+	// BaseURL is the API URL. Defaults to https://HOST/api/v4.
+	BaseURL string `mapstructure:"base_url"`
+	// Token falls back to GITLAB_TOKEN.
+	Token string `mapstructure:"token"`
+	// Repos are group/name path.Match globs of repositories hosted on
+	// GitLab, for commands given a repository without a host.
+	Repos []string `mapstructure:"repos"`
+}
+
+// HostName returns the GitLab host.
+func (c GitLabConfig) HostName() string {
+	if c.Host == "" {
+		return "gitlab.com"
+	}
+	return strings.ToLower(c.Host)
+}
+
+// APIURL returns the GitLab API URL.
+func (c GitLabConfig) APIURL() string {
+	if c.BaseURL != "" {
+		return c.BaseURL
+	}
+	return "https://" + c.HostName() + "/api/v4"
+}
+
+// Hosts reports whether repo, a path without a host, matches Repos.
+func (c GitLabConfig) Hosts(repo string) bool {
+	for _, pattern := range c.Repos {
+		if repoGlobMatch(pattern, repo) {
+			return true
+		}
+	}
+	return false
+}
+
+// BitbucketConfig contains API settings for Bitbucket Cloud.
+type BitbucketConfig struct {
+	// BaseURL is the API URL. Defaults to https://api.bitbucket.org/2.0.
+	BaseURL string `mapstructure:"base_url"`
+	// Username, when set, authenticates with Token as an app password.
+	// Without it, Token is an access token.
+	Username string `mapstructure:"username"`
+	// Token falls back to BITBUCKET_TOKEN.
+	Token string `mapstructure:"token"`
+	// Repos are workspace/name path.Match globs of repositories hosted on
+	// Bitbucket, for commands given a repository without a host.
+	Repos []string `mapstructure:"repos"`
+}
+
+// Hosts reports whether repo, a path without a host, matches Repos.
+func (c BitbucketConfig) Hosts(repo string) bool {
+	for _, pattern := range c.Repos {
+		if repoGlobMatch(pattern, repo) {
+			return true
+		}
+	}
+	return false
+}
+
+// CIConfig selects the CI service of repositories, and holds the
+// credentials of CI services that are not code hosts.
+type CIConfig struct {
+	CircleCI  CircleCIConfig  `mapstructure:"circleci"`
+	Buildkite BuildkiteConfig `mapstructure:"buildkite"`
+	// Repos select the CI of matching repositories. Other repositories use
+	// the CI of their code host.
+	Repos []CIRepoConfig `mapstructure:"repos"`
+}
+
+// CircleCIConfig contains CircleCI API settings.
+type CircleCIConfig struct {
+	// BaseURL defaults to https://circleci.com/api/v2.
+	BaseURL string `mapstructure:"base_url"`
+	// Token is a personal API token. Falls back to CIRCLECI_TOKEN.
+	Token string `mapstructure:"token"`
+}
+
+// BuildkiteConfig contains Buildkite API settings.
+type BuildkiteConfig struct {
+	// BaseURL defaults to https://api.buildkite.com/v2.
+	BaseURL      string `mapstructure:"base_url"`
+	Organization string `mapstructure:"organization"`
+	// Token is an API access token. Falls back to BUILDKITE_API_TOKEN.
+	Token string `mapstructure:"token"`
+}
+
+// CIRepoConfig selects the CI service of repositories.
+type CIRepoConfig struct {
+	// Repo is an owner/name path.Match glob.
+	Repo string `mapstructure:"repo"`
+	// Provider is github, gitlab, bitbucket, circleci, or buildkite.
+	Provider string `mapstructure:"provider"`
+	// Project is the CircleCI project slug, defaulting to gh/OWNER/NAME or
+	// bb/OWNER/NAME, or the Buildkite pipeline slug, defaulting to NAME.
+	Project string `mapstructure:"project"`
+}
+
+// For returns the first entry of Repos matching repo.
+func (c CIConfig) For(repo string) (CIRepoConfig, bool) {
+	for _, entry := range c.Repos {
+		if repoGlobMatch(entry.Repo, repo) {
+			return entry, true
+		}
+	}
+	return CIRepoConfig{}, false
+}
+
+// GitHubHostConfig contains API settings for one GitHub host, such as a
+// GitHub Enterprise Server instance. Empty URLs are derived from Host.
+type GitHubHostConfig struct {
+	Host      string `mapstructure:"host"`
+	Token     string `mapstructure:"token"`
+	BaseURL   string `mapstructure:"base_url"`
+	UploadURL string `mapstructure:"upload_url"`
+}
+
 // GitHubPRConfig contains GitHub PR settings.
 type GitHubPRConfig struct {
 	DefaultMergeMethod string `mapstructure:"default_merge_method"`
@@ -52,6 +1005,9 @@ type TemplatesConfig struct {
 	BuiltinPath  string `mapstructure:"builtin_path"`
 	CustomPath   string `mapstructure:"custom_path"`
 	EnableCustom bool   `mapstructure:"enable_custom"`
+	// IndexURL is a URL or file listing template packages that
+	// "template install <name>" can install by name.
+	IndexURL string `mapstructure:"index_url"`
 }
 
 // DiffConfig contains diff viewing settings.
@@ -60,6 +1016,28 @@ type DiffConfig struct {
 	ForceNative bool   `mapstructure:"force_native"`
 }
 
+var (
+	overridesMu sync.Mutex
+	overrides   []func(*Config)
+)
+
+// AddOverride registers fn to adjust every configuration loaded afterwards,
+// after credentials are resolved and before validation. Offline mode uses it
+// to point commands that reload configuration at its fake backends.
+func AddOverride(fn func(*Config)) {
+	overridesMu.Lock()
+	defer overridesMu.Unlock()
+	overrides = append(overrides, fn)
+}
+
+func applyOverrides(config *Config) {
+	overridesMu.Lock()
+	defer overridesMu.Unlock()
+	for _, fn := range overrides {
+		fn(config)
+	}
+}
+
 // Load loads configuration from file and environment variables.
 func Load() (*Config, error) {
 	return load(true, true)
@@ -110,7 +1088,11 @@ func load(requireJulesAPIKey bool, validateConfig bool) (*Config, error) {
 	// Expand environment variables in paths
 	config.Templates.CustomPath = os.ExpandEnv(config.Templates.CustomPath)
 	config.Templates.BuiltinPath = os.ExpandEnv(config.Templates.BuiltinPath)
+	config.Audit.Path = os.ExpandEnv(config.Audit.Path)
+	config.History.Path = os.ExpandEnv(config.History.Path)
+	config.Policy.ApprovalsPath = os.ExpandEnv(config.Policy.ApprovalsPath)
 	applyCredentialFallbacks(&config)
+	applyOverrides(&config)
 
 	// Validate configuration
 	if validateConfig {
@@ -122,13 +1104,104 @@ func load(requireJulesAPIKey bool, validateConfig bool) (*Config, error) {
 	return &config, nil
 }
 
+// lookupSecret reads credentials stored by `juleson auth login`.
+var lookupSecret = secrets.Lookup
+
 func applyCredentialFallbacks(config *Config) {
 	if config.Jules.APIKey == "" {
 		config.Jules.APIKey = os.Getenv("JULES_API_KEY")
 	}
+	if config.Jules.APIKey == "" {
+		config.Jules.APIKey = lookupSecret(secrets.KeyJulesAPIKey)
+	}
+	if config.Gemini.APIKey == "" {
+		config.Gemini.APIKey = os.Getenv("GEMINI_API_KEY")
+	}
+	if config.Gemini.APIKey == "" {
+		config.Gemini.APIKey = lookupSecret(secrets.KeyGeminiAPIKey)
+	}
 	if config.GitHub.Token == "" {
 		config.GitHub.Token = os.Getenv("GITHUB_TOKEN")
 	}
+	if config.GitHub.Token == "" {
+		config.GitHub.Token = lookupSecret(secrets.KeyGitHubToken)
+	}
+	for i := range config.GitHub.Hosts {
+		if config.GitHub.Hosts[i].Token == "" {
+			config.GitHub.Hosts[i].Token = os.Getenv("GH_ENTERPRISE_TOKEN")
+		}
+	}
+	if config.GitLab.Token == "" {
+		config.GitLab.Token = os.Getenv("GITLAB_TOKEN")
+	}
+	if config.Bitbucket.Token == "" {
+		config.Bitbucket.Token = os.Getenv("BITBUCKET_TOKEN")
+	}
+	if config.CI.CircleCI.Token == "" {
+		config.CI.CircleCI.Token = os.Getenv("CIRCLECI_TOKEN")
+	}
+	if config.CI.Buildkite.Token == "" {
+		config.CI.Buildkite.Token = os.Getenv("BUILDKITE_API_TOKEN")
+	}
+	if config.Notifications.Slack.WebhookURL == "" {
+		config.Notifications.Slack.WebhookURL = os.Getenv("SLACK_WEBHOOK_URL")
+	}
+	if config.Notifications.Email.Password == "" {
+		config.Notifications.Email.Password = os.Getenv("JULESON_SMTP_PASSWORD")
+	}
+	if config.Integrations.Jira.Token == "" {
+		config.Integrations.Jira.Token = os.Getenv("JIRA_API_TOKEN")
+	}
+	if config.Integrations.Linear.APIKey == "" {
+		config.Integrations.Linear.APIKey = os.Getenv("LINEAR_API_KEY")
+	}
+}
+
+// ForHost returns the API settings for a GitHub host. An empty host, or a
+// host without a hosts entry, resolves to the top-level token and URLs.
+func (c GitHubConfig) ForHost(host string) GitHubHostConfig {
+	host = strings.ToLower(strings.TrimSpace(host))
+	for _, entry := range c.Hosts {
+		if host == "" || !strings.EqualFold(entry.Host, host) {
+			continue
+		}
+		resolved := entry
+		if resolved.BaseURL == "" && !isPublicGitHubHost(host) {
+			resolved.BaseURL = "https://" + host + "/api/v3/"
+		}
+		if resolved.UploadURL == "" && !isPublicGitHubHost(host) {
+			resolved.UploadURL = "https://" + host + "/api/uploads/"
+		}
+		return resolved
+	}
+
+	return GitHubHostConfig{
+		Host:      host,
+		Token:     c.Token,
+		BaseURL:   c.BaseURL,
+		UploadURL: c.UploadURL,
+	}
+}
+
+// EnterpriseHosts returns the configured non-github.com hosts, including the
+// host of the top-level base URL when one is set.
+func (c GitHubConfig) EnterpriseHosts() []string {
+	var hosts []string
+	if c.BaseURL != "" {
+		if parsed, err := url.Parse(c.BaseURL); err == nil && parsed.Host != "" && !isPublicGitHubHost(parsed.Host) {
+			hosts = append(hosts, strings.ToLower(parsed.Host))
+		}
+	}
+	for _, entry := range c.Hosts {
+		if entry.Host != "" && !isPublicGitHubHost(entry.Host) {
+			hosts = append(hosts, strings.ToLower(entry.Host))
+		}
+	}
+	return hosts
+}
+
+func isPublicGitHubHost(host string) bool {
+	return strings.EqualFold(host, "github.com") || strings.EqualFold(host, "api.github.com")
 }
 
 // loadEnvFiles loads .env files from multiple possible locations.
@@ -155,10 +1228,89 @@ func setDefaults() {
 	viper.SetDefault("jules.base_url", "https://jules.googleapis.com/v1alpha")
 	viper.SetDefault("jules.timeout", "30s")
 	viper.SetDefault("jules.retry_attempts", 3)
+	viper.SetDefault("jules.retry_backoff", "1s")
+	viper.SetDefault("jules.retry_max_backoff", "30s")
+	viper.SetDefault("jules.retry_jitter", 0.2)
+	viper.SetDefault("jules.rate_limit", 0)
 	viper.SetDefault("jules.debug_log", false)
 
+	viper.SetDefault("gemini.api_key", "")
+	viper.SetDefault("gemini.model", "gemini-2.5-flash")
+	viper.SetDefault("gemini.embedding_model", "text-embedding-004")
+	viper.SetDefault("gemini.base_url", "https://generativelanguage.googleapis.com/v1beta")
+	viper.SetDefault("gemini.timeout", "60s")
+	viper.SetDefault("gemini.cache_ttl", "24h")
+	viper.SetDefault("gemini.replay", "")
+	viper.SetDefault("gemini.cassette", "")
+
+	viper.SetDefault("log.level", "")
+	viper.SetDefault("log.format", "auto")
+
+	viper.SetDefault("audit.enabled", true)
+	viper.SetDefault("audit.path", "")
+
+	viper.SetDefault("history.enabled", true)
+	viper.SetDefault("history.path", "")
+
+	viper.SetDefault("encryption.enabled", false)
+
+	viper.SetDefault("retention.interval", "0s")
+
+	viper.SetDefault("policy.approvals_path", "")
+	viper.SetDefault("policy.budget.max_sessions_per_day", 0)
+	viper.SetDefault("policy.budget.max_files_changed", 0)
+	viper.SetDefault("policy.budget.confirm_above", "0s")
+
+	viper.SetDefault("integrations.jira.issue_type", "Task")
+	viper.SetDefault("integrations.jira.transitions.started", "In Progress")
+	viper.SetDefault("integrations.jira.transitions.completed", "Done")
+	viper.SetDefault("integrations.linear.states.started", "In Progress")
+	viper.SetDefault("integrations.linear.states.completed", "Done")
+	viper.SetDefault("integrations.linear.states.failed", "Canceled")
+
+	viper.SetDefault("sandbox.images", []string{"golang:*"})
+	viper.SetDefault("sandbox.commands", []string{"go", "gofmt", "make"})
+	viper.SetDefault("sandbox.cpus", sandbox.DefaultCPUs)
+	viper.SetDefault("sandbox.memory_mb", sandbox.DefaultMemoryMB)
+	viper.SetDefault("sandbox.pids_limit", sandbox.DefaultPidsLimit)
+	viper.SetDefault("sandbox.timeout", sandbox.DefaultTimeout.String())
+	viper.SetDefault("sandbox.network", false)
+	viper.SetDefault("sandbox.writable_workspace", false)
+
+	viper.SetDefault("watch.debounce", "500ms")
+
+	viper.SetDefault("hooks.pre_commit", hooks.DefaultChecks[hooks.PreCommit])
+	viper.SetDefault("hooks.pre_push", hooks.DefaultChecks[hooks.PrePush])
+	viper.SetDefault("hooks.strict", false)
+	viper.SetDefault("guard.prompts", guard.ModeFlag)
+	viper.SetDefault("guard.outputs", guard.ModeBlock)
+	viper.SetDefault("guard.allow", []string{})
+
+	viper.SetDefault("bench.packages", []string{"./internal/events"})
+	viper.SetDefault("bench.pattern", ".")
+	viper.SetDefault("bench.benchtime", "")
+	viper.SetDefault("bench.count", 1)
+	viper.SetDefault("bench.baseline", ".juleson/bench-baseline.json")
+	viper.SetDefault("bench.max_regression", 0)
+
+	viper.SetDefault("kubernetes.kubeconfig", "")
+	viper.SetDefault("kubernetes.context", "")
+	viper.SetDefault("kubernetes.contexts", []string{})
+	viper.SetDefault("kubernetes.namespace", "")
+
+	viper.SetDefault("analysis.osv_url", osv.DefaultBaseURL)
+	viper.SetDefault("analysis.allowed_licenses", []string{})
+
+	viper.SetDefault("circuit_breaker.max_failures", 5)
+	viper.SetDefault("circuit_breaker.timeout", "30s")
+	viper.SetDefault("circuit_breaker.reset_timeout", "60s")
+
+	viper.SetDefault("health.listen", "")
+
 	viper.SetDefault("github.token", "")
 	viper.SetDefault("github.default_org", "")
+	viper.SetDefault("github.base_url", "")
+	viper.SetDefault("github.upload_url", "")
 	viper.SetDefault("github.pr.default_merge_method", "squash")
 	viper.SetDefault("github.pr.auto_delete_branch", true)
 	viper.SetDefault("github.discovery.enabled", true)
@@ -168,20 +1320,401 @@ func setDefaults() {
 	viper.SetDefault("templates.builtin_path", "./templates/builtin")
 	viper.SetDefault("templates.custom_path", "${JULES_TEMPLATES_CUSTOM_PATH:-./templates/custom}")
 	viper.SetDefault("templates.enable_custom", true)
+	viper.SetDefault("templates.index_url", "")
 
 	viper.SetDefault("diff.tool", "")
 	viper.SetDefault("diff.force_native", false)
 }
 
+// Validate reports every semantic problem in the configuration without
+// requiring a Jules API key.
+func (c *Config) Validate() error {
+	return validate(c, false)
+}
+
 // validate validates the configuration.
 func validate(config *Config, requireJulesAPIKey bool) error {
+	var errs []error
+
 	if config.Jules.APIKey == "" && requireJulesAPIKey {
-		return fmt.Errorf("Jules API key is required - set it in juleson.yaml or JULES_API_KEY environment variable") //nolint:staticcheck
+		errs = append(errs, fmt.Errorf("Jules API key is required - set it in juleson.yaml or JULES_API_KEY environment variable")) //nolint:staticcheck
+	}
+	if err := validateAbsoluteURL(config.Jules.BaseURL); err != nil {
+		errs = append(errs, fmt.Errorf("invalid jules.base_url: %w", err))
+	}
+	if config.Jules.Timeout < 0 {
+		errs = append(errs, fmt.Errorf("jules.timeout must not be negative"))
+	}
+	if config.Jules.RetryAttempts < 0 {
+		errs = append(errs, fmt.Errorf("jules.retry_attempts must not be negative"))
+	}
+	if config.Jules.RetryBackoff < 0 || config.Jules.RetryMaxBackoff < 0 {
+		errs = append(errs, fmt.Errorf("jules.retry_backoff and jules.retry_max_backoff must not be negative"))
+	}
+	if config.Jules.RetryJitter < 0 || config.Jules.RetryJitter > 1 {
+		errs = append(errs, fmt.Errorf("jules.retry_jitter must be between 0 and 1"))
+	}
+	if config.Jules.RateLimit < 0 {
+		errs = append(errs, fmt.Errorf("jules.rate_limit must not be negative"))
+	}
+	if err := validateAbsoluteURL(config.Gemini.BaseURL); err != nil {
+		errs = append(errs, fmt.Errorf("invalid gemini.base_url: %w", err))
+	}
+	if config.Gemini.Timeout < 0 {
+		errs = append(errs, fmt.Errorf("gemini.timeout must not be negative"))
+	}
+	for _, site := range slices.Sorted(maps.Keys(config.Gemini.Routes)) {
+		tier := config.Gemini.Routes[site]
+		if !slices.Contains(gemini.Sites, site) {
+			errs = append(errs, fmt.Errorf("gemini.routes: unknown call site %q; call sites are %s", site, strings.Join(gemini.Sites, ", ")))
+		} else if len(config.Gemini.Models[tier]) == 0 {
+			errs = append(errs, fmt.Errorf("gemini.routes.%s: tier %q has no models in gemini.models", site, tier))
+		}
+	}
+	for _, tier := range slices.Sorted(maps.Keys(config.Gemini.Models)) {
+		if slices.Contains(config.Gemini.Models[tier], "") {
+			errs = append(errs, fmt.Errorf("gemini.models.%s: model names must not be empty", tier))
+		}
+	}
+	if t := config.Gemini.Temperature; t != nil && (*t < 0 || *t > 2) {
+		errs = append(errs, fmt.Errorf("gemini.temperature must be between 0 and 2"))
+	}
+	if config.Gemini.CacheTTL < 0 {
+		errs = append(errs, fmt.Errorf("gemini.cache_ttl must not be negative"))
+	}
+	if config.Gemini.Replay != "" && !slices.Contains(gemini.ReplayModes, config.Gemini.Replay) {
+		errs = append(errs, fmt.Errorf("gemini.replay must be record or replay, got %q", config.Gemini.Replay))
+	}
+	if config.Gemini.Replay != "" && config.Gemini.Cassette == "" {
+		errs = append(errs, fmt.Errorf("gemini.replay needs gemini.cassette"))
+	}
+	if !validLogLevel(config.Log.Level) {
+		errs = append(errs, fmt.Errorf("log.level must be debug, info, warn, or error, got %q", config.Log.Level))
+	}
+	if !validLogFormat(config.Log.Format) {
+		errs = append(errs, fmt.Errorf("log.format must be text, json, or auto, got %q", config.Log.Format))
+	}
+	for name, level := range config.Log.Subsystems {
+		if !validLogLevel(level) {
+			errs = append(errs, fmt.Errorf("log.subsystems.%s must be debug, info, warn, or error, got %q", name, level))
+		}
+	}
+	for i, sink := range config.Log.Sinks {
+		if err := validateLogSink(sink); err != nil {
+			errs = append(errs, fmt.Errorf("log.sinks[%d]: %w", i, err))
+		}
+	}
+	for i, rule := range config.Policy.PolicyRules() {
+		if err := policy.ValidateRule(rule); err != nil {
+			errs = append(errs, fmt.Errorf("policy.rules[%d]: %w", i, err))
+		}
+	}
+	if config.Policy.Budget.MaxSessionsPerDay < 0 || config.Policy.Budget.MaxFilesChanged < 0 || config.Policy.Budget.ConfirmAbove < 0 {
+		errs = append(errs, fmt.Errorf("policy.budget limits must not be negative"))
+	}
+	if config.Policy.Budget.MaxSessionsPerDay > 0 && !config.Audit.Enabled {
+		errs = append(errs, fmt.Errorf("policy.budget.max_sessions_per_day requires audit.enabled, which records the sessions it counts"))
+	}
+	for i, entry := range append([]SessionDefaultsConfig{config.Sessions.Defaults}, config.Sessions.Repos...) {
+		name := "sessions.defaults"
+		if i > 0 {
+			name = fmt.Sprintf("sessions.repos[%d]", i-1)
+			if _, err := path.Match(entry.Repo, ""); err != nil || entry.Repo == "" {
+				errs = append(errs, fmt.Errorf("%s: repo must be an owner/name pattern, got %q", name, entry.Repo))
+			}
+		}
+		switch entry.AutomationMode {
+		case "", "AUTOMATION_MODE_UNSPECIFIED", "AUTO_CREATE_PR":
+		default:
+			errs = append(errs, fmt.Errorf("%s.automation_mode must be AUTO_CREATE_PR or AUTOMATION_MODE_UNSPECIFIED, got %q", name, entry.AutomationMode))
+		}
+	}
+	for i, rule := range config.Notifications.Rules {
+		if rule.MinSeverity != "" {
+			if _, err := events.ParseSeverity(rule.MinSeverity); err != nil {
+				errs = append(errs, fmt.Errorf("notifications.rules[%d]: %w", i, err))
+			}
+		}
+	}
+	if err := notify.ValidateConfig(config.Notifications.NotifyOptions()); err != nil {
+		errs = append(errs, fmt.Errorf("notifications: %w", err))
+	}
+	if err := integrations.ValidateConfig(config.Integrations.IntegrationOptions()); err != nil {
+		errs = append(errs, fmt.Errorf("integrations: %w", err))
+	}
+	for i, rule := range config.Alerts.Rules {
+		if rule.Severity != "" {
+			if _, err := events.ParseSeverity(rule.Severity); err != nil {
+				errs = append(errs, fmt.Errorf("alerts.rules[%d]: %w", i, err))
+			}
+		}
+	}
+	if err := alerts.ValidateConfig(config.Alerts.AlertOptions()); err != nil {
+		errs = append(errs, fmt.Errorf("alerts: %w", err))
+	}
+	if err := hooks.ValidateChecks(config.Hooks.PreCommit); err != nil {
+		errs = append(errs, fmt.Errorf("hooks.pre_commit: %w", err))
+	}
+	if err := hooks.ValidateChecks(config.Hooks.PrePush); err != nil {
+		errs = append(errs, fmt.Errorf("hooks.pre_push: %w", err))
+	}
+	if config.Guard.Prompts != "" && !slices.Contains(guard.Modes, config.Guard.Prompts) {
+		errs = append(errs, fmt.Errorf("guard.prompts must be block, flag, or off, got %q", config.Guard.Prompts))
+	}
+	if config.Guard.Outputs != "" && !slices.Contains(guard.Modes, config.Guard.Outputs) {
+		errs = append(errs, fmt.Errorf("guard.outputs must be block, flag, or off, got %q", config.Guard.Outputs))
+	}
+	if err := guard.ValidateRules(config.Guard.Allow); err != nil {
+		errs = append(errs, fmt.Errorf("guard.allow: %w", err))
+	}
+	if _, err := regexp.Compile(config.Bench.Pattern); err != nil {
+		errs = append(errs, fmt.Errorf("bench.pattern: %w", err))
+	}
+	if config.Bench.Count < 0 {
+		errs = append(errs, fmt.Errorf("bench.count must not be negative"))
+	}
+	if config.Bench.MaxRegression < 0 {
+		errs = append(errs, fmt.Errorf("bench.max_regression must not be negative"))
+	}
+	for i, budget := range config.Bench.Budgets {
+		if _, err := path.Match(budget.Name, ""); err != nil || budget.Name == "" {
+			errs = append(errs, fmt.Errorf("bench.budgets[%d]: name must be a benchmark name pattern, got %q", i, budget.Name))
+		}
+		if budget.MaxNsPerOp < 0 || budget.MaxBytesPerOp < 0 || budget.MaxAllocsPerOp < 0 {
+			errs = append(errs, fmt.Errorf("bench.budgets[%d]: limits must not be negative", i))
+		}
+	}
+	if config.Watch.Debounce < 0 {
+		errs = append(errs, fmt.Errorf("watch.debounce must not be negative"))
+	}
+	names := map[string]bool{}
+	for i, trigger := range config.Watch.Triggers {
+		switch {
+		case trigger.Name == "":
+			errs = append(errs, fmt.Errorf("watch.triggers[%d]: name is required", i))
+		case names[trigger.Name]:
+			errs = append(errs, fmt.Errorf("watch.triggers[%d]: duplicate name %q", i, trigger.Name))
+		}
+		names[trigger.Name] = true
+		if (strings.TrimSpace(trigger.Run) == "") == (strings.TrimSpace(trigger.Command) == "") {
+			errs = append(errs, fmt.Errorf("watch.triggers[%d]: set exactly one of run and command", i))
+		}
+	}
+	if err := sinks.ValidateConfig(config.Events.SinkOptions()); err != nil {
+		errs = append(errs, fmt.Errorf("events: %w", err))
+	}
+	if err := sandbox.ValidateConfig(config.Sandbox.SandboxOptions()); err != nil {
+		errs = append(errs, fmt.Errorf("sandbox: %w", err))
+	}
+	for _, pattern := range config.Kubernetes.Contexts {
+		if _, err := path.Match(pattern, ""); err != nil {
+			errs = append(errs, fmt.Errorf("kubernetes.contexts: invalid pattern %q", pattern))
+		}
+	}
+	if err := validateAbsoluteURL(config.Analysis.OSVURL); err != nil {
+		errs = append(errs, fmt.Errorf("invalid analysis.osv_url: %w", err))
+	}
+	if config.CircuitBreaker.MaxFailures < 0 || config.CircuitBreaker.Timeout < 0 || config.CircuitBreaker.ResetTimeout < 0 {
+		errs = append(errs, fmt.Errorf("circuit_breaker settings must not be negative"))
+	}
+	if config.Health.Listen != "" {
+		if _, _, err := net.SplitHostPort(config.Health.Listen); err != nil {
+			errs = append(errs, fmt.Errorf("invalid health.listen: %w", err))
+		}
+	}
+	if tokens, err := config.MCP.RBACTokens(); err != nil {
+		errs = append(errs, err)
+	} else if _, err := rbac.NewTokens(tokens); err != nil {
+		errs = append(errs, fmt.Errorf("mcp.tokens: %w", err))
+	}
+	if config.Retention.Interval < 0 {
+		errs = append(errs, fmt.Errorf("retention.interval must not be negative"))
+	}
+	for _, rule := range []struct {
+		name string
+		RetentionRuleConfig
+	}{{"audit", config.Retention.Audit}, {"history", config.Retention.History}, {"artifacts", config.Retention.Artifacts.RetentionRuleConfig}} {
+		if rule.MaxAge < 0 || rule.MaxSizeMB < 0 {
+			errs = append(errs, fmt.Errorf("retention.%s: max_age and max_size_mb must not be negative", rule.name))
+		}
+	}
+	// Session budgets count today's sessions from the audit log.
+	if age := config.Retention.Audit.MaxAge; age > 0 && age < 24*time.Hour {
+		errs = append(errs, fmt.Errorf("retention.audit.max_age must be at least 24h, got %s", age))
+	}
+	if config.Retention.Artifacts.RetentionRuleConfig.Enabled() && len(config.Retention.Artifacts.Dirs) == 0 {
+		errs = append(errs, fmt.Errorf("retention.artifacts.dirs is required with max_age or max_size_mb"))
+	}
+	tenants := make(map[string]bool, len(config.MCP.Tenants))
+	for i, tenant := range config.MCP.Tenants {
+		switch {
+		case tenant.Name == "" || tenant.Name == "." || tenant.Name == ".." || strings.ContainsAny(tenant.Name, `/\`):
+			errs = append(errs, fmt.Errorf("mcp.tenants[%d]: invalid name %q", i, tenant.Name))
+		case tenants[tenant.Name]:
+			errs = append(errs, fmt.Errorf("mcp.tenants[%d]: duplicate name %q", i, tenant.Name))
+		}
+		if strings.TrimSpace(tenant.JulesAPIKey) == "" {
+			errs = append(errs, fmt.Errorf("mcp.tenants[%d]: jules_api_key is required", i))
+		}
+		tenants[tenant.Name] = true
+	}
+	for i, token := range config.MCP.Tokens {
+		if token.Tenant != "" && !tenants[token.Tenant] {
+			errs = append(errs, fmt.Errorf("mcp.tokens[%d]: unknown tenant %q", i, token.Tenant))
+		}
 	}
 
+	for _, raw := range []string{config.GitHub.BaseURL, config.GitHub.UploadURL} {
+		if err := validateAbsoluteURL(raw); err != nil {
+			errs = append(errs, fmt.Errorf("invalid github URL: %w", err))
+		}
+	}
+	for _, entry := range config.GitHub.Hosts {
+		if entry.Host == "" {
+			errs = append(errs, fmt.Errorf("github.hosts entries require a host"))
+			continue
+		}
+		for _, raw := range []string{entry.BaseURL, entry.UploadURL} {
+			if err := validateAbsoluteURL(raw); err != nil {
+				errs = append(errs, fmt.Errorf("invalid URL for github host %s: %w", entry.Host, err))
+			}
+		}
+	}
+	if err := validateAbsoluteURL(config.GitLab.BaseURL); err != nil {
+		errs = append(errs, fmt.Errorf("invalid gitlab URL: %w", err))
+	}
+	for _, pattern := range config.GitLab.Repos {
+		if _, err := path.Match(pattern, ""); err != nil || pattern == "" {
+			errs = append(errs, fmt.Errorf("gitlab.repos: invalid pattern %q", pattern))
+		}
+	}
+	if err := validateAbsoluteURL(config.Bitbucket.BaseURL); err != nil {
+		errs = append(errs, fmt.Errorf("invalid bitbucket URL: %w", err))
+	}
+	for _, pattern := range config.Bitbucket.Repos {
+		if _, err := path.Match(pattern, ""); err != nil || pattern == "" {
+			errs = append(errs, fmt.Errorf("bitbucket.repos: invalid pattern %q", pattern))
+		}
+	}
+	for i, entry := range config.CI.Repos {
+		name := fmt.Sprintf("ci.repos[%d]", i)
+		if _, err := path.Match(entry.Repo, ""); err != nil || entry.Repo == "" {
+			errs = append(errs, fmt.Errorf("%s: repo must be an owner/name pattern, got %q", name, entry.Repo))
+		}
+		kind, err := ci.ParseKind(entry.Provider)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", name, err))
+		}
+		if kind == ci.KindBuildkite && config.CI.Buildkite.Organization == "" {
+			errs = append(errs, fmt.Errorf("%s: buildkite requires ci.buildkite.organization", name))
+		}
+	}
+	if err := validateAbsoluteURL(config.CI.CircleCI.BaseURL); err != nil {
+		errs = append(errs, fmt.Errorf("invalid ci.circleci URL: %w", err))
+	}
+	if err := validateAbsoluteURL(config.CI.Buildkite.BaseURL); err != nil {
+		errs = append(errs, fmt.Errorf("invalid ci.buildkite URL: %w", err))
+	}
+	switch config.GitHub.PR.DefaultMergeMethod {
+	case "", "merge", "squash", "rebase":
+	default:
+		errs = append(errs, fmt.Errorf("github.pr.default_merge_method must be merge, squash, or rebase, got %q", config.GitHub.PR.DefaultMergeMethod))
+	}
+
+	return errors.Join(errs...)
+}
+
+func validLogLevel(level string) bool {
+	switch strings.ToLower(level) {
+	case "", "debug", "info", "warn", "error":
+		return true
+	}
+	return false
+}
+
+func validLogFormat(format string) bool {
+	switch strings.ToLower(format) {
+	case "", "auto", "text", "json":
+		return true
+	}
+	return false
+}
+
+func validateLogSink(sink LogSinkConfig) error {
+	switch strings.ToLower(sink.Type) {
+	case "stderr", "stdout":
+	case "file":
+		if sink.Path == "" {
+			return fmt.Errorf("file sinks require a path")
+		}
+	case "syslog":
+		if sink.Address == "" {
+			return fmt.Errorf("syslog sinks require an address")
+		}
+	default:
+		return fmt.Errorf("type must be stderr, stdout, file, or syslog, got %q", sink.Type)
+	}
+	if !validLogLevel(sink.Level) {
+		return fmt.Errorf("level must be debug, info, warn, or error, got %q", sink.Level)
+	}
+	if !validLogFormat(sink.Format) {
+		return fmt.Errorf("format must be text, json, or auto, got %q", sink.Format)
+	}
+	if sink.MaxSizeMB < 0 || sink.MaxBackups < 0 {
+		return fmt.Errorf("max_size_mb and max_backups must not be negative")
+	}
 	return nil
 }
 
+func validateAbsoluteURL(raw string) error {
+	if raw == "" {
+		return nil
+	}
+	parsed, err := url.Parse(raw)
+	if err != nil {
+		return err
+	}
+	if parsed.Scheme == "" || parsed.Host == "" {
+		return fmt.Errorf("%q must be an absolute URL", raw)
+	}
+	return nil
+}
+
+// File returns the path of the config file that was loaded, or an empty
+// string when only defaults and environment variables are in effect.
+func File() string {
+	return viper.ConfigFileUsed()
+}
+
+// UnknownKeys returns keys set in the config file that Juleson does not
+// recognize, which usually indicates a typo.
+func UnknownKeys() []string {
+	known := make(map[string]bool)
+	collectKeys(reflect.TypeOf(Config{}), "", known)
+
+	var unknown []string
+	for _, key := range viper.AllKeys() {
+		if known[key] || strings.HasPrefix(key, "github.hosts") || strings.HasPrefix(key, "log.subsystems.") {
+			continue
+		}
+		unknown = append(unknown, key)
+	}
+	sort.Strings(unknown)
+	return unknown
+}
+
+func collectKeys(t reflect.Type, prefix string, known map[string]bool) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		key := prefix + field.Tag.Get("mapstructure")
+		if field.Type.Kind() == reflect.Struct {
+			collectKeys(field.Type, key+".", known)
+			continue
+		}
+		known[key] = true
+	}
+}
+
 // Save saves the configuration back to the config file.
 func (c *Config) Save() error {
 	// Set the values in viper
@@ -189,10 +1722,138 @@ func (c *Config) Save() error {
 	viper.Set("jules.base_url", c.Jules.BaseURL)
 	viper.Set("jules.timeout", c.Jules.Timeout.String())
 	viper.Set("jules.retry_attempts", c.Jules.RetryAttempts)
+	viper.Set("jules.retry_backoff", c.Jules.RetryBackoff.String())
+	viper.Set("jules.retry_max_backoff", c.Jules.RetryMaxBackoff.String())
+	viper.Set("jules.retry_jitter", c.Jules.RetryJitter)
+	viper.Set("jules.rate_limit", c.Jules.RateLimit)
 	viper.Set("jules.debug_log", c.Jules.DebugLog)
 
+	viper.Set("log.level", c.Log.Level)
+	viper.Set("log.format", c.Log.Format)
+	if len(c.Log.Subsystems) > 0 {
+		viper.Set("log.subsystems", c.Log.Subsystems)
+	}
+	if len(c.Log.Sinks) > 0 {
+		sinks := make([]map[string]interface{}, 0, len(c.Log.Sinks))
+		for _, sink := range c.Log.Sinks {
+			sinks = append(sinks, map[string]interface{}{
+				"type":        sink.Type,
+				"path":        sink.Path,
+				"address":     sink.Address,
+				"format":      sink.Format,
+				"level":       sink.Level,
+				"max_size_mb": sink.MaxSizeMB,
+				"max_backups": sink.MaxBackups,
+			})
+		}
+		viper.Set("log.sinks", sinks)
+	}
+
+	viper.Set("audit.enabled", c.Audit.Enabled)
+	viper.Set("audit.path", c.Audit.Path)
+	viper.Set("encryption.enabled", c.Encryption.Enabled)
+	viper.Set("retention.interval", c.Retention.Interval.String())
+	for name, rule := range map[string]RetentionRuleConfig{"audit": c.Retention.Audit, "history": c.Retention.History, "artifacts": c.Retention.Artifacts.RetentionRuleConfig} {
+		if rule.Enabled() {
+			viper.Set("retention."+name+".max_age", rule.MaxAge.String())
+			viper.Set("retention."+name+".max_size_mb", rule.MaxSizeMB)
+		}
+	}
+	if len(c.Retention.Artifacts.Dirs) > 0 {
+		viper.Set("retention.artifacts.dirs", c.Retention.Artifacts.Dirs)
+	}
+
+	viper.Set("policy.approvals_path", c.Policy.ApprovalsPath)
+	viper.Set("policy.budget.max_sessions_per_day", c.Policy.Budget.MaxSessionsPerDay)
+	viper.Set("policy.budget.max_files_changed", c.Policy.Budget.MaxFilesChanged)
+	viper.Set("policy.budget.confirm_above", c.Policy.Budget.ConfirmAbove.String())
+	if len(c.Policy.Rules) > 0 {
+		rules := make([]map[string]interface{}, 0, len(c.Policy.Rules))
+		for _, rule := range c.Policy.Rules {
+			rules = append(rules, map[string]interface{}{
+				"operation": rule.Operation,
+				"tool":      rule.Tool,
+				"repo":      rule.Repo,
+				"decision":  rule.Decision,
+			})
+		}
+		viper.Set("policy.rules", rules)
+	}
+
+	if defaults := sessionDefaultsMap(c.Sessions.Defaults); len(defaults) > 0 {
+		viper.Set("sessions.defaults", defaults)
+	}
+	if len(c.Sessions.Repos) > 0 {
+		repos := make([]map[string]interface{}, 0, len(c.Sessions.Repos))
+		for _, entry := range c.Sessions.Repos {
+			repo := sessionDefaultsMap(entry)
+			repo["repo"] = entry.Repo
+			repos = append(repos, repo)
+		}
+		viper.Set("sessions.repos", repos)
+	}
+
+	viper.Set("sandbox.images", c.Sandbox.Images)
+	viper.Set("sandbox.commands", c.Sandbox.Commands)
+	viper.Set("sandbox.cpus", c.Sandbox.CPUs)
+	viper.Set("sandbox.memory_mb", c.Sandbox.MemoryMB)
+	viper.Set("sandbox.pids_limit", c.Sandbox.PidsLimit)
+	viper.Set("sandbox.timeout", c.Sandbox.Timeout.String())
+	viper.Set("sandbox.network", c.Sandbox.Network)
+	viper.Set("sandbox.writable_workspace", c.Sandbox.WritableWorkspace)
+
+	viper.Set("kubernetes.kubeconfig", c.Kubernetes.Kubeconfig)
+	viper.Set("kubernetes.context", c.Kubernetes.Context)
+	viper.Set("kubernetes.contexts", c.Kubernetes.Contexts)
+	viper.Set("kubernetes.namespace", c.Kubernetes.Namespace)
+
+	viper.Set("circuit_breaker.max_failures", c.CircuitBreaker.MaxFailures)
+	viper.Set("circuit_breaker.timeout", c.CircuitBreaker.Timeout.String())
+	viper.Set("circuit_breaker.reset_timeout", c.CircuitBreaker.ResetTimeout.String())
+
+	viper.Set("health.listen", c.Health.Listen)
+	if len(c.MCP.Tokens) > 0 {
+		tokens := make([]map[string]interface{}, 0, len(c.MCP.Tokens))
+		for _, entry := range c.MCP.Tokens {
+			tokens = append(tokens, map[string]interface{}{
+				"name":         entry.Name,
+				"token":        entry.Token,
+				"token_sha256": entry.TokenSHA256,
+				"role":         entry.Role,
+				"tenant":       entry.Tenant,
+			})
+		}
+		viper.Set("mcp.tokens", tokens)
+	}
+	if len(c.MCP.Tenants) > 0 {
+		tenants := make([]map[string]interface{}, 0, len(c.MCP.Tenants))
+		for _, entry := range c.MCP.Tenants {
+			tenants = append(tenants, map[string]interface{}{
+				"name":          entry.Name,
+				"jules_api_key": entry.JulesAPIKey,
+				"github_token":  entry.GitHubToken,
+				"data_dir":      entry.DataDir,
+			})
+		}
+		viper.Set("mcp.tenants", tenants)
+	}
+
 	viper.Set("github.token", c.GitHub.Token)
 	viper.Set("github.default_org", c.GitHub.DefaultOrg)
+	viper.Set("github.base_url", c.GitHub.BaseURL)
+	viper.Set("github.upload_url", c.GitHub.UploadURL)
+	if len(c.GitHub.Hosts) > 0 {
+		hosts := make([]map[string]interface{}, 0, len(c.GitHub.Hosts))
+		for _, entry := range c.GitHub.Hosts {
+			hosts = append(hosts, map[string]interface{}{
+				"host":       entry.Host,
+				"token":      entry.Token,
+				"base_url":   entry.BaseURL,
+				"upload_url": entry.UploadURL,
+			})
+		}
+		viper.Set("github.hosts", hosts)
+	}
 	viper.Set("github.pr.default_merge_method", c.GitHub.PR.DefaultMergeMethod)
 	viper.Set("github.pr.auto_delete_branch", c.GitHub.PR.AutoDeleteBranch)
 	viper.Set("github.discovery.enabled", c.GitHub.Discovery.Enabled)
@@ -202,6 +1863,7 @@ func (c *Config) Save() error {
 	viper.Set("templates.builtin_path", c.Templates.BuiltinPath)
 	viper.Set("templates.custom_path", c.Templates.CustomPath)
 	viper.Set("templates.enable_custom", c.Templates.EnableCustom)
+	viper.Set("templates.index_url", c.Templates.IndexURL)
 
 	viper.Set("diff.tool", c.Diff.Tool)
 	viper.Set("diff.force_native", c.Diff.ForceNative)
@@ -217,3 +1879,21 @@ func (c *Config) Save() error {
 
 	return nil
 }
+
+// sessionDefaultsMap returns the settings of entry that are set.
+func sessionDefaultsMap(entry SessionDefaultsConfig) map[string]interface{} {
+	values := map[string]interface{}{}
+	if entry.StartingBranch != "" {
+		values["starting_branch"] = entry.StartingBranch
+	}
+	if entry.RequirePlanApproval != nil {
+		values["require_plan_approval"] = *entry.RequirePlanApproval
+	}
+	if entry.AutomationMode != "" {
+		values["automation_mode"] = entry.AutomationMode
+	}
+	if len(entry.Labels) > 0 {
+		values["labels"] = entry.Labels
+	}
+	return values
+}
@@ -1,9 +1,12 @@
 package config
 
 import (
+	"path/filepath"
 	"testing"
 	"time"
 
+	"github.com/SamyRai/juleson/internal/events"
+	"github.com/SamyRai/juleson/internal/integrations"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -42,6 +45,10 @@ func TestApplyCredentialFallbacks(t *testing.T) {
 		},
 	}
 
+	originalLookup := lookupSecret
+	lookupSecret = func(string) string { return "" }
+	t.Cleanup(func() { lookupSecret = originalLookup })
+
 	for _, tc := range cases {
 		t.Run(tc.name, func(t *testing.T) {
 			// Setup env vars
@@ -58,6 +65,23 @@ func TestApplyCredentialFallbacks(t *testing.T) {
 	}
 }
 
+func TestApplyCredentialFallbacksUsesSecretStore(t *testing.T) {
+	originalLookup := lookupSecret
+	lookupSecret = func(key string) string { return "stored-" + key }
+	t.Cleanup(func() { lookupSecret = originalLookup })
+
+	t.Setenv("JULES_API_KEY", "env-jules")
+	t.Setenv("GITHUB_TOKEN", "")
+	t.Setenv("GEMINI_API_KEY", "")
+
+	cfg := Config{}
+	applyCredentialFallbacks(&cfg)
+
+	assert.Equal(t, "env-jules", cfg.Jules.APIKey)
+	assert.Equal(t, "stored-github_token", cfg.GitHub.Token)
+	assert.Equal(t, "stored-gemini_api_key", cfg.Gemini.APIKey)
+}
+
 func TestValidate(t *testing.T) {
 	cases := []struct {
 		name               string
@@ -132,4 +156,375 @@ func TestSetDefaults(t *testing.T) {
 
 	assert.Equal(t, "squash", cfg.GitHub.PR.DefaultMergeMethod)
 	assert.True(t, cfg.GitHub.PR.AutoDeleteBranch)
+	assert.True(t, cfg.Audit.Enabled)
+}
+
+func TestGitHubConfigForHost(t *testing.T) {
+	cfg := GitHubConfig{
+		Token: "public-token",
+		Hosts: []GitHubHostConfig{
+			{Host: "ghe.example.com", Token: "ghe-token"},
+			{Host: "code.corp.example", Token: "corp-token", BaseURL: "https://code.corp.example/custom/api/"},
+		},
+	}
+
+	defaultHost := cfg.ForHost("")
+	assert.Equal(t, "public-token", defaultHost.Token)
+	assert.Empty(t, defaultHost.BaseURL)
+
+	ghe := cfg.ForHost("GHE.example.com")
+	assert.Equal(t, "ghe-token", ghe.Token)
+	assert.Equal(t, "https://ghe.example.com/api/v3/", ghe.BaseURL)
+	assert.Equal(t, "https://ghe.example.com/api/uploads/", ghe.UploadURL)
+
+	corp := cfg.ForHost("code.corp.example")
+	assert.Equal(t, "https://code.corp.example/custom/api/", corp.BaseURL)
+	assert.Equal(t, "https://code.corp.example/api/uploads/", corp.UploadURL)
+
+	unknown := cfg.ForHost("github.com")
+	assert.Equal(t, "public-token", unknown.Token)
+
+	assert.Equal(t, []string{"ghe.example.com", "code.corp.example"}, cfg.EnterpriseHosts())
+}
+
+func TestValidateGitHubURLs(t *testing.T) {
+	err := validate(&Config{GitHub: GitHubConfig{BaseURL: "ghe.example.com/api/v3"}}, false)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "must be an absolute URL")
+
+	err = validate(&Config{GitHub: GitHubConfig{Hosts: []GitHubHostConfig{{Token: "x"}}}}, false)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "require a host")
+
+	require.NoError(t, validate(&Config{GitHub: GitHubConfig{BaseURL: "https://ghe.example.com/api/v3/"}}, false))
+}
+
+func TestValidateReportsAllProblems(t *testing.T) {
+	err := (&Config{
+		Jules:  JulesConfig{BaseURL: "jules.local", RetryAttempts: -1},
+		GitHub: GitHubConfig{PR: GitHubPRConfig{DefaultMergeMethod: "fast-forward"}},
+	}).Validate()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid jules.base_url")
+	assert.Contains(t, err.Error(), "retry_attempts must not be negative")
+	assert.Contains(t, err.Error(), "default_merge_method")
+}
+
+func TestValidateLogConfig(t *testing.T) {
+	err := validate(&Config{Log: LogConfig{
+		Format:     "xml",
+		Subsystems: map[string]string{"mcp": "loud"},
+		Sinks:      []LogSinkConfig{{Type: "file"}, {Type: "kafka"}},
+	}}, false)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "log.format")
+	assert.Contains(t, err.Error(), "log.subsystems.mcp")
+	assert.Contains(t, err.Error(), "log.sinks[0]: file sinks require a path")
+	assert.Contains(t, err.Error(), "log.sinks[1]: type must be")
+
+	require.NoError(t, validate(&Config{Log: LogConfig{
+		Format:     "json",
+		Subsystems: map[string]string{"jules": "debug"},
+		Sinks: []LogSinkConfig{
+			{Type: "stderr", Level: "warn"},
+			{Type: "file", Path: "/tmp/juleson.log", MaxSizeMB: 10, MaxBackups: 3},
+			{Type: "syslog", Address: "udp://localhost:514"},
+		},
+	}}, false))
+}
+
+func TestValidatePolicyRules(t *testing.T) {
+	err := validate(&Config{Policy: PolicyConfig{Rules: []PolicyRuleConfig{
+		{Operation: "delete_session", Decision: "deny"},
+		{Operation: "rm_rf", Decision: "allow"},
+		{Operation: "docker_exec", Decision: "sometimes"},
+	}}}, false)
+	require.Error(t, err)
+	assert.NotContains(t, err.Error(), "policy.rules[0]")
+	assert.Contains(t, err.Error(), `policy.rules[1]: unknown operation "rm_rf"`)
+	assert.Contains(t, err.Error(), "policy.rules[2]: invalid decision")
+}
+
+func TestValidateSandboxConfig(t *testing.T) {
+	err := validate(&Config{Sandbox: SandboxConfig{
+		Images:   []string{"golang:*", "["},
+		Commands: []string{"go", "--privileged"},
+	}}, false)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `sandbox: invalid image pattern "["`)
+	assert.Contains(t, err.Error(), `invalid command "--privileged"`)
+}
+
+func TestKubernetesContexts(t *testing.T) {
+	cfg := KubernetesConfig{Context: "kind-dev", Contexts: []string{"staging-*"}, Namespace: "ci"}
+
+	assert.True(t, cfg.ContextAllowed(""))
+	assert.True(t, cfg.ContextAllowed("kind-dev"))
+	assert.True(t, cfg.ContextAllowed("staging-eu"))
+	assert.False(t, cfg.ContextAllowed("prod"))
+	assert.Equal(t, "kind-dev", cfg.ClientOptions("").Context)
+	assert.Equal(t, "ci", cfg.ClientOptions("staging-eu").Namespace)
+
+	err := validate(&Config{Kubernetes: KubernetesConfig{Contexts: []string{"["}}}, false)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `kubernetes.contexts: invalid pattern "["`)
+}
+
+func TestSessionsConfigFor(t *testing.T) {
+	yes, no := true, false
+	cfg := SessionsConfig{
+		Defaults: SessionDefaultsConfig{StartingBranch: "main", RequirePlanApproval: &yes, Labels: []string{"jules"}},
+		Repos: []SessionDefaultsConfig{
+			{Repo: "acme/*", StartingBranch: "develop", AutomationMode: "AUTO_CREATE_PR"},
+			{Repo: "Acme/Sandbox", RequirePlanApproval: &no, Labels: []string{}},
+		},
+	}
+
+	defaults := cfg.For("acme/sandbox")
+	assert.Equal(t, "develop", defaults.StartingBranch)
+	assert.Equal(t, "AUTO_CREATE_PR", defaults.AutomationMode)
+	require.NotNil(t, defaults.RequirePlanApproval)
+	assert.False(t, *defaults.RequirePlanApproval)
+	assert.Empty(t, defaults.Labels)
+
+	defaults = cfg.For("other/repo")
+	assert.Equal(t, "main", defaults.StartingBranch)
+	assert.Empty(t, defaults.AutomationMode)
+	assert.Equal(t, []string{"jules"}, defaults.Labels)
+
+	assert.Equal(t, "main", cfg.For("").StartingBranch)
+}
+
+func TestValidateSessionsConfig(t *testing.T) {
+	err := validate(&Config{Sessions: SessionsConfig{
+		Defaults: SessionDefaultsConfig{AutomationMode: "AUTO_MERGE"},
+		Repos:    []SessionDefaultsConfig{{Repo: "acme/*"}, {StartingBranch: "main"}},
+	}}, false)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `sessions.defaults.automation_mode must be AUTO_CREATE_PR or AUTOMATION_MODE_UNSPECIFIED, got "AUTO_MERGE"`)
+	assert.NotContains(t, err.Error(), "sessions.repos[0]")
+	assert.Contains(t, err.Error(), `sessions.repos[1]: repo must be an owner/name pattern`)
+}
+
+func TestValidateNotificationsConfig(t *testing.T) {
+	err := validate(&Config{Notifications: NotificationsConfig{
+		Desktop: DesktopNotificationsConfig{Enabled: true},
+		Rules: []NotificationRuleConfig{
+			{Events: []string{"session.*"}, Channels: []string{"desktop"}},
+			{MinSeverity: "loud", Channels: []string{"slack"}},
+		},
+	}}, false)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "notifications.rules[1]:")
+	assert.Contains(t, err.Error(), `rules[1]: channel "slack" is not configured`)
+	assert.NotContains(t, err.Error(), "rules[0]")
+}
+
+func TestValidateIntegrationsConfig(t *testing.T) {
+	err := validate(&Config{Integrations: IntegrationsConfig{
+		Jira:   JiraIntegrationConfig{BaseURL: "https://acme.atlassian.net", Token: "secret"},
+		Linear: LinearIntegrationConfig{TeamID: "team-1", APIKey: "lin_api_key"},
+	}}, false)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "integrations: jira requires project")
+	assert.NotContains(t, err.Error(), "linear")
+
+	options := IntegrationsConfig{Jira: JiraIntegrationConfig{Transitions: IssueStatesConfig{Started: "In Progress"}}}.IntegrationOptions()
+	assert.Equal(t, "In Progress", options.Jira.Transitions[integrations.StatusStarted])
+	assert.Empty(t, options.Jira.Transitions[integrations.StatusFailed])
+}
+
+func TestValidateWatchConfig(t *testing.T) {
+	err := validate(&Config{Watch: WatchConfig{Triggers: []WatchTriggerConfig{
+		{Name: "lint", Run: "template run code-cleanup"},
+		{Name: "lint", Command: "make lint"},
+		{Run: "analyze security", Command: "make test"},
+	}}}, false)
+	require.Error(t, err)
+	assert.NotContains(t, err.Error(), "watch.triggers[0]")
+	assert.Contains(t, err.Error(), `watch.triggers[1]: duplicate name "lint"`)
+	assert.Contains(t, err.Error(), "watch.triggers[2]: name is required")
+	assert.Contains(t, err.Error(), "watch.triggers[2]: set exactly one of run and command")
+}
+
+func TestValidateGeminiReplay(t *testing.T) {
+	hot := 3.0
+	err := validate(&Config{Gemini: GeminiConfig{Temperature: &hot, Replay: "rewind"}}, false)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "gemini.temperature must be between 0 and 2")
+	assert.Contains(t, err.Error(), `gemini.replay must be record or replay, got "rewind"`)
+	assert.Contains(t, err.Error(), "gemini.replay needs gemini.cassette")
+
+	err = validate(&Config{Gemini: GeminiConfig{Routes: map[string]string{"planning": "large", "review": "huge"}}}, false)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `gemini.routes: unknown call site "planning"`)
+	assert.Contains(t, err.Error(), `gemini.routes.review: tier "huge" has no models`)
+
+	err = validate(&Config{Gemini: GeminiConfig{
+		Replay:   "replay",
+		Cassette: "testdata/gemini.json",
+		Models:   map[string][]string{"large": {"gemini-2.5-pro"}},
+		Routes:   map[string]string{"review": "large"},
+	}}, false)
+	if err != nil {
+		assert.NotContains(t, err.Error(), "gemini.")
+	}
+}
+
+func TestValidateGuardConfig(t *testing.T) {
+	err := validate(&Config{Guard: GuardConfig{Prompts: "warn", Outputs: "block", Allow: []string{"pipe-to-shell", "curl"}}}, false)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `guard.prompts must be block, flag, or off, got "warn"`)
+	assert.NotContains(t, err.Error(), "guard.outputs")
+	assert.Contains(t, err.Error(), `guard.allow: unknown rule "curl"`)
+}
+
+func TestValidateBenchConfig(t *testing.T) {
+	err := validate(&Config{Bench: BenchConfig{Pattern: "Bus(", MaxRegression: -5, Budgets: []BenchBudgetConfig{
+		{Name: "BenchmarkBusPublish/*", MaxNsPerOp: 5000},
+		{Name: "", MaxAllocsPerOp: 10},
+		{Name: "BenchmarkStoreAppend", MaxBytesPerOp: -1},
+	}}}, false)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "bench.pattern:")
+	assert.Contains(t, err.Error(), "bench.max_regression must not be negative")
+	assert.NotContains(t, err.Error(), "bench.budgets[0]")
+	assert.Contains(t, err.Error(), `bench.budgets[1]: name must be a benchmark name pattern, got ""`)
+	assert.Contains(t, err.Error(), "bench.budgets[2]: limits must not be negative")
+}
+
+func TestValidateHooksConfig(t *testing.T) {
+	err := validate(&Config{Hooks: HooksConfig{PreCommit: []string{"secrets", "lint"}, PrePush: []string{"tests"}}}, false)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `hooks.pre_commit: unknown check "lint"`)
+	assert.NotContains(t, err.Error(), "hooks.pre_push")
+}
+
+func TestGitLabConfig(t *testing.T) {
+	cfg := GitLabConfig{Repos: []string{"platform/*"}}
+	assert.Equal(t, "gitlab.com", cfg.HostName())
+	assert.Equal(t, "https://gitlab.com/api/v4", cfg.APIURL())
+	assert.True(t, cfg.Hosts("Platform/api"))
+	assert.False(t, cfg.Hosts("platform/tools/cli"))
+
+	cfg = GitLabConfig{Host: "GitLab.Acme.dev"}
+	assert.Equal(t, "https://gitlab.acme.dev/api/v4", cfg.APIURL())
+
+	err := validate(&Config{GitLab: GitLabConfig{BaseURL: "gitlab.acme.dev", Repos: []string{"["}}}, false)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid gitlab URL")
+	assert.Contains(t, err.Error(), `gitlab.repos: invalid pattern "["`)
+}
+
+func TestBitbucketConfig(t *testing.T) {
+	cfg := BitbucketConfig{Repos: []string{"acme/mobile-*"}}
+	assert.True(t, cfg.Hosts("acme/mobile-ios"))
+	assert.False(t, cfg.Hosts("acme/web"))
+
+	err := validate(&Config{Bitbucket: BitbucketConfig{BaseURL: "api.bitbucket.org", Repos: []string{""}}}, false)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid bitbucket URL")
+	assert.Contains(t, err.Error(), `bitbucket.repos: invalid pattern ""`)
+}
+
+func TestCIConfig(t *testing.T) {
+	cfg := CIConfig{Repos: []CIRepoConfig{{Repo: "acme/*", Provider: "buildkite"}, {Repo: "*/*", Provider: "circleci"}}}
+	entry, ok := cfg.For("acme/widgets")
+	assert.True(t, ok)
+	assert.Equal(t, "buildkite", entry.Provider)
+	_, ok = cfg.For("platform/tools/cli")
+	assert.False(t, ok)
+
+	err := validate(&Config{CI: CIConfig{Repos: []CIRepoConfig{{Repo: "acme/*", Provider: "jenkins"}, {Repo: "acme/api", Provider: "buildkite"}}}}, false)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `unknown CI provider "jenkins"`)
+	assert.Contains(t, err.Error(), "ci.buildkite.organization")
+}
+
+func TestMCPTenantConfig(t *testing.T) {
+	t.Setenv("TEAM_A_JULES_KEY", "jules-a")
+	base := &Config{
+		Jules:         JulesConfig{APIKey: "jules-default", RateLimit: 2},
+		GitHub:        GitHubConfig{Token: "gh-default", Hosts: []GitHubHostConfig{{Host: "ghe.acme.dev", Token: "ghe-default"}}},
+		GitLab:        GitLabConfig{Token: "gl-default"},
+		CI:            CIConfig{Buildkite: BuildkiteConfig{Token: "bk-default"}},
+		Gemini:        GeminiConfig{APIKey: "gemini-default"},
+		Integrations:  IntegrationsConfig{Linear: LinearIntegrationConfig{APIKey: "lin-default", TeamID: "ENG"}},
+		Notifications: NotificationsConfig{Slack: SlackNotificationsConfig{WebhookURL: "https://hooks.slack.com/x"}},
+		Audit:         AuditConfig{Enabled: true},
+		MCP: MCPConfig{
+			Tokens:  []MCPTokenConfig{{Name: "team-a-bot", Token: "secret", Role: "operator", Tenant: "team-a"}},
+			Tenants: []MCPTenantConfig{{Name: "team-a", JulesAPIKey: "${TEAM_A_JULES_KEY}", DataDir: "/srv/juleson/team-a"}},
+		},
+	}
+	tenant, ok := base.MCP.Tenant("team-a")
+	require.True(t, ok)
+	scoped, err := base.ForTenant(tenant)
+	require.NoError(t, err)
+	assert.Equal(t, "jules-a", scoped.Jules.APIKey)
+	assert.Equal(t, 2.0, scoped.Jules.RateLimit)
+	assert.Empty(t, scoped.GitHub.Token)
+	assert.Empty(t, scoped.GitHub.Hosts[0].Token)
+	assert.Equal(t, "ghe-default", base.GitHub.Hosts[0].Token)
+	assert.Empty(t, scoped.GitLab.Token)
+	assert.Empty(t, scoped.CI.Buildkite.Token)
+	assert.Empty(t, scoped.Gemini.APIKey)
+	assert.Empty(t, scoped.Integrations.Linear.APIKey)
+	assert.Empty(t, scoped.Notifications.Slack.WebhookURL)
+	assert.Equal(t, filepath.Join("/srv/juleson/team-a", "audit.jsonl"), scoped.Audit.Path)
+	assert.Equal(t, filepath.Join("/srv/juleson/team-a", "approvals.json"), scoped.Policy.ApprovalsPath)
+	assert.Empty(t, scoped.MCP.Tokens)
+	assert.Equal(t, "jules-default", base.Jules.APIKey)
+	assert.Empty(t, base.Audit.Path)
+	require.NoError(t, validate(base, false))
+
+	t.Setenv("TEAM_A_JULES_KEY", "")
+	_, err = base.ForTenant(tenant)
+	require.Error(t, err, "a tenant without a Jules API key must not fall back to the shared one")
+
+	err = validate(&Config{MCP: MCPConfig{
+		Tokens:  []MCPTokenConfig{{Name: "bot", Token: "secret", Role: "viewer", Tenant: "team-c"}},
+		Tenants: []MCPTenantConfig{{Name: "team-a"}, {Name: "team-a"}, {Name: "../etc"}},
+	}}, false)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `mcp.tenants[1]: duplicate name "team-a"`)
+	assert.Contains(t, err.Error(), `mcp.tenants[2]: invalid name "../etc"`)
+	assert.Contains(t, err.Error(), "mcp.tenants[0]: jules_api_key is required")
+	assert.Contains(t, err.Error(), `mcp.tokens[0]: unknown tenant "team-c"`)
+}
+
+func TestValidateRetentionConfig(t *testing.T) {
+	err := validate(&Config{Retention: RetentionConfig{
+		Audit:     RetentionRuleConfig{MaxAge: time.Hour},
+		History:   RetentionRuleConfig{MaxSizeMB: -1},
+		Artifacts: ArtifactRetentionConfig{RetentionRuleConfig: RetentionRuleConfig{MaxAge: 24 * time.Hour}},
+	}}, false)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "retention.audit.max_age must be at least 24h, got 1h0m0s")
+	assert.Contains(t, err.Error(), "retention.history: max_age and max_size_mb must not be negative")
+	assert.Contains(t, err.Error(), "retention.artifacts.dirs is required")
+}
+
+func TestEventsConfig(t *testing.T) {
+	cfg := EventsConfig{Sinks: []EventSinkConfig{{Name: "warehouse", Type: "kafka", URL: "https://kafka-rest.acme.dev", Topic: "juleson", Events: []string{"session.*"}, BatchSize: 50}}}
+	options := cfg.SinkOptions()
+	require.Len(t, options, 1)
+	assert.Equal(t, []string{"session.*"}, options[0].Types)
+	assert.Equal(t, 50, options[0].BatchSize)
+
+	err := validate(&Config{Events: EventsConfig{Sinks: []EventSinkConfig{{Name: "hook", Type: "webhook", URL: "http://hooks.acme.dev"}}}}, false)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "events: sinks[0]: invalid webhook URL")
+}
+
+func TestAlertsConfig(t *testing.T) {
+	cfg := AlertsConfig{Rules: []AlertRuleConfig{{Name: "backlog", Metric: "queue_backlog", Threshold: 100}}}
+	options := cfg.AlertOptions()
+	require.Len(t, options.Rules, 1)
+	assert.Equal(t, events.SeverityWarning, options.Rules[0].Severity)
+
+	err := validate(&Config{Alerts: AlertsConfig{Rules: []AlertRuleConfig{{Name: "cpu", Metric: "cpu", Severity: "loud"}}}}, false)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `alerts: rules[0]: unknown metric "cpu"`)
+	assert.Contains(t, err.Error(), "loud")
 }
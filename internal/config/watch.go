@@ -0,0 +1,161 @@
+package config
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"path/filepath"
+	"reflect"
+	"sync"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// ReloadFunc is called after the config file was reloaded successfully.
+// changed lists the dotted keys whose values differ from previous.
+type ReloadFunc func(previous, current *Config, changed []string)
+
+// Watcher reloads the config file whenever it changes on disk. Invalid
+// configs are logged and ignored so a bad edit never takes down a running
+// process.
+type Watcher struct {
+	path     string
+	current  *Config
+	handlers []ReloadFunc
+	debounce time.Duration
+	logger   *slog.Logger
+	mu       sync.Mutex
+}
+
+// NewWatcher creates a watcher for the config file that produced cfg.
+func NewWatcher(cfg *Config, logger *slog.Logger) *Watcher {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	return &Watcher{
+		path:     File(),
+		current:  cfg,
+		debounce: 200 * time.Millisecond,
+		logger:   logger,
+	}
+}
+
+// Path returns the watched config file, or an empty string when no config
+// file was loaded.
+func (w *Watcher) Path() string {
+	return w.path
+}
+
+// OnReload registers a handler for successful reloads.
+func (w *Watcher) OnReload(fn ReloadFunc) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.handlers = append(w.handlers, fn)
+}
+
+// Run watches the config file until ctx is cancelled. The parent directory
+// is watched so editors that replace the file on save are handled.
+func (w *Watcher) Run(ctx context.Context) error {
+	if w.path == "" {
+		return fmt.Errorf("no config file to watch")
+	}
+
+	fsWatcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return fmt.Errorf("failed to create config watcher: %w", err)
+	}
+	defer fsWatcher.Close()
+
+	path := filepath.Clean(w.path)
+	if err := fsWatcher.Add(filepath.Dir(path)); err != nil {
+		return fmt.Errorf("failed to watch %s: %w", filepath.Dir(path), err)
+	}
+	w.logger.Debug("watching config file", "path", path)
+
+	var timer *time.Timer
+	reload := make(chan struct{}, 1)
+	defer func() {
+		if timer != nil {
+			timer.Stop()
+		}
+	}()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case event, ok := <-fsWatcher.Events:
+			if !ok {
+				return nil
+			}
+			if filepath.Clean(event.Name) != path || !event.Has(fsnotify.Write|fsnotify.Create|fsnotify.Rename) {
+				continue
+			}
+			if timer != nil {
+				timer.Stop()
+			}
+			timer = time.AfterFunc(w.debounce, func() {
+				select {
+				case reload <- struct{}{}:
+				default:
+				}
+			})
+		case <-reload:
+			if err := w.Reload(); err != nil {
+				w.logger.Warn("config reload failed; keeping previous config", "path", path, "error", err)
+			}
+		case err, ok := <-fsWatcher.Errors:
+			if !ok {
+				return nil
+			}
+			w.logger.Warn("config watcher error", "error", err)
+		}
+	}
+}
+
+// Reload reads the config file again and notifies handlers when it is
+// valid.
+func (w *Watcher) Reload() error {
+	next, err := load(false, true)
+	if err != nil {
+		return err
+	}
+
+	w.mu.Lock()
+	previous := w.current
+	w.current = next
+	handlers := append([]ReloadFunc(nil), w.handlers...)
+	w.mu.Unlock()
+
+	changed := ChangedKeys(previous, next)
+	w.logger.Info("config reloaded", "path", w.path, "changed", changed)
+	for _, handler := range handlers {
+		handler(previous, next, changed)
+	}
+	return nil
+}
+
+// ChangedKeys returns the dotted keys whose values differ between a and b.
+func ChangedKeys(a, b *Config) []string {
+	if a == nil || b == nil {
+		return nil
+	}
+	var changed []string
+	diffKeys(reflect.ValueOf(*a), reflect.ValueOf(*b), "", &changed)
+	return changed
+}
+
+func diffKeys(a, b reflect.Value, prefix string, changed *[]string) {
+	for i := 0; i < a.NumField(); i++ {
+		field := a.Type().Field(i)
+		key := prefix + field.Tag.Get("mapstructure")
+		if field.Type.Kind() == reflect.Struct {
+			diffKeys(a.Field(i), b.Field(i), key+".", changed)
+			continue
+		}
+		if !reflect.DeepEqual(a.Field(i).Interface(), b.Field(i).Interface()) {
+			*changed = append(*changed, key)
+		}
+	}
+}
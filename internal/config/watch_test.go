@@ -0,0 +1,89 @@
+package config
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWatcherReloadsOnFileChange(t *testing.T) {
+	viper.Reset()
+	t.Cleanup(viper.Reset)
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("JULES_API_KEY", "")
+	t.Setenv("GITHUB_TOKEN", "")
+
+	dir := t.TempDir()
+	t.Chdir(dir)
+	path := filepath.Join(dir, "juleson.yaml")
+	require.NoError(t, os.WriteFile(path, []byte("log:\n  level: info\n"), 0o600))
+
+	cfg, err := LoadOptional()
+	require.NoError(t, err)
+
+	watcher := NewWatcher(cfg, nil)
+	watcher.debounce = 10 * time.Millisecond
+	require.Equal(t, path, filepath.Clean(watcher.Path()))
+
+	reloaded := make(chan []string, 1)
+	watcher.OnReload(func(previous, current *Config, changed []string) {
+		assert.Equal(t, "info", previous.Log.Level)
+		assert.Equal(t, "debug", current.Log.Level)
+		reloaded <- changed
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	done := make(chan error, 1)
+	go func() { done <- watcher.Run(ctx) }()
+
+	// Give the watcher time to register before editing the file.
+	time.Sleep(50 * time.Millisecond)
+	require.NoError(t, os.WriteFile(path, []byte("log:\n  level: debug\n"), 0o600))
+
+	select {
+	case changed := <-reloaded:
+		assert.Equal(t, []string{"log.level"}, changed)
+	case <-time.After(5 * time.Second):
+		t.Fatal("config was not reloaded")
+	}
+
+	cancel()
+	require.NoError(t, <-done)
+}
+
+func TestWatcherKeepsPreviousConfigWhenInvalid(t *testing.T) {
+	viper.Reset()
+	t.Cleanup(viper.Reset)
+	t.Setenv("HOME", t.TempDir())
+
+	dir := t.TempDir()
+	t.Chdir(dir)
+	path := filepath.Join(dir, "juleson.yaml")
+	require.NoError(t, os.WriteFile(path, []byte("log:\n  level: info\n"), 0o600))
+
+	cfg, err := LoadOptional()
+	require.NoError(t, err)
+
+	watcher := NewWatcher(cfg, nil)
+	called := false
+	watcher.OnReload(func(_, _ *Config, _ []string) { called = true })
+
+	require.NoError(t, os.WriteFile(path, []byte("log:\n  level: loud\n"), 0o600))
+	require.Error(t, watcher.Reload())
+	assert.False(t, called)
+}
+
+func TestChangedKeys(t *testing.T) {
+	a := &Config{Jules: JulesConfig{RateLimit: 1}, CircuitBreaker: CircuitBreakerConfig{MaxFailures: 5}}
+	b := &Config{Jules: JulesConfig{RateLimit: 2}, CircuitBreaker: CircuitBreakerConfig{MaxFailures: 3}}
+
+	assert.Equal(t, []string{"circuit_breaker.max_failures", "jules.rate_limit"}, ChangedKeys(a, b))
+	assert.Empty(t, ChangedKeys(a, a))
+}
@@ -0,0 +1,257 @@
+package contextpack
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"path"
+	"regexp"
+	"strings"
+)
+
+// maxBlockLines bounds the chunks of files without a language-aware
+// splitter.
+const maxBlockLines = 40
+
+// Chunk is a contiguous range of lines of a file: a declaration with the
+// comments above it, the file's preamble, or a block of other text.
+type Chunk struct {
+	Path string
+	// Symbol names the declaration, such as "Server.Start" or "parse"; it is
+	// empty for preambles and plain blocks.
+	Symbol string
+	// Signature is the line that introduces the chunk, used in outlines.
+	Signature string
+	// StartLine and EndLine are 1-based and inclusive.
+	StartLine int
+	EndLine   int
+	Text      string
+	// Preamble marks the package clause, imports, and module header, which
+	// are kept whenever any other part of the file is.
+	Preamble bool
+}
+
+var (
+	jsDefinitionPattern = regexp.MustCompile(`^(?:export\s+)?(?:default\s+)?(?:declare\s+)?(?:abstract\s+)?(?:async\s+)?(?:function\*?|class|const|let|var|interface|type|enum)\s+([A-Za-z_$][\w$]*)`)
+	pyDefinitionPattern = regexp.MustCompile(`^(?:async\s+def|def|class)\s+([A-Za-z_]\w*)`)
+)
+
+var jsExtensions = map[string]bool{".js": true, ".jsx": true, ".ts": true, ".tsx": true, ".mjs": true, ".cjs": true}
+
+// Split divides a file into chunks that cover every line in order: Go files
+// by top-level declaration, JavaScript, TypeScript, and Python files by
+// top-level definition, and other files by blocks of paragraphs.
+func Split(file, content string) []Chunk {
+	lines := strings.SplitAfter(content, "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	if len(lines) == 0 {
+		return nil
+	}
+	ext := strings.ToLower(path.Ext(file))
+	var chunks []Chunk
+	switch {
+	case ext == ".go":
+		chunks = splitGo(file, content, lines)
+	case jsExtensions[ext]:
+		chunks = splitDefinitions(file, lines, jsDefinitionPattern, "//", "/*", "*")
+	case ext == ".py":
+		chunks = splitDefinitions(file, lines, pyDefinitionPattern, "#", "@")
+	}
+	if chunks == nil {
+		chunks = splitBlocks(file, lines)
+	}
+	return chunks
+}
+
+// newChunk returns the chunk of lines start to end, 1-based and inclusive.
+func newChunk(file string, lines []string, start, end int) Chunk {
+	return Chunk{Path: file, StartLine: start, EndLine: end, Text: strings.Join(lines[start-1:end], "")}
+}
+
+// splitGo chunks a Go file by top-level declaration. Each chunk starts after
+// the previous one, so comments between declarations belong to the one
+// below them. Files that do not parse return nil.
+func splitGo(file, content string, lines []string) []Chunk {
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, file, content, parser.ParseComments|parser.SkipObjectResolution)
+	if err != nil {
+		return nil
+	}
+	line := func(pos token.Pos) int { return fset.Position(pos).Line }
+
+	preambleEnd := line(f.Name.End())
+	var decls []ast.Decl
+	for _, decl := range f.Decls {
+		if gen, ok := decl.(*ast.GenDecl); ok && gen.Tok == token.IMPORT {
+			preambleEnd = line(gen.End())
+			continue
+		}
+		decls = append(decls, decl)
+	}
+	preamble := newChunk(file, lines, 1, preambleEnd)
+	preamble.Preamble = true
+	chunks := []Chunk{preamble}
+	for i, decl := range decls {
+		start := chunks[len(chunks)-1].EndLine + 1
+		end := line(decl.End())
+		if i == len(decls)-1 {
+			end = len(lines)
+		}
+		chunk := newChunk(file, lines, start, end)
+		chunk.Symbol = goSymbol(decl)
+		chunk.Signature = strings.TrimSpace(lines[line(decl.Pos())-1])
+		if fn, ok := decl.(*ast.FuncDecl); ok && fn.Body != nil {
+			chunk.Signature = strings.Join(strings.Fields(content[fset.Position(fn.Pos()).Offset:fset.Position(fn.Body.Lbrace).Offset]), " ")
+		}
+		chunks = append(chunks, chunk)
+	}
+	if len(decls) == 0 && preambleEnd < len(lines) {
+		chunks[0] = newChunk(file, lines, 1, len(lines))
+		chunks[0].Preamble = true
+	}
+	return chunks
+}
+
+// goSymbol names a declaration: Type.Method for methods, and the names of a
+// declaration group joined with commas.
+func goSymbol(decl ast.Decl) string {
+	switch d := decl.(type) {
+	case *ast.FuncDecl:
+		if d.Recv != nil && len(d.Recv.List) > 0 {
+			recv := d.Recv.List[0].Type
+			if star, ok := recv.(*ast.StarExpr); ok {
+				recv = star.X
+			}
+			switch t := recv.(type) {
+			case *ast.IndexExpr:
+				recv = t.X
+			case *ast.IndexListExpr:
+				recv = t.X
+			}
+			if ident, ok := recv.(*ast.Ident); ok {
+				return ident.Name + "." + d.Name.Name
+			}
+		}
+		return d.Name.Name
+	case *ast.GenDecl:
+		var names []string
+		for _, spec := range d.Specs {
+			switch s := spec.(type) {
+			case *ast.TypeSpec:
+				names = append(names, s.Name.Name)
+			case *ast.ValueSpec:
+				for _, name := range s.Names {
+					names = append(names, name.Name)
+				}
+			}
+		}
+		return strings.Join(names, ", ")
+	}
+	return ""
+}
+
+// splitDefinitions chunks a file at unindented lines matching pattern. A
+// definition's chunk starts at the comment or decorator lines directly above
+// it, those starting with one of leaders.
+func splitDefinitions(file string, lines []string, pattern *regexp.Regexp, leaders ...string) []Chunk {
+	type boundary struct {
+		line   int
+		symbol string
+		def    int
+	}
+	var boundaries []boundary
+	for i, text := range lines {
+		match := pattern.FindStringSubmatch(text)
+		if match == nil {
+			continue
+		}
+		start := i
+		for start > 0 && hasLeader(lines[start-1], leaders) {
+			start--
+		}
+		if len(boundaries) > 0 && start <= boundaries[len(boundaries)-1].line {
+			start = i
+		}
+		boundaries = append(boundaries, boundary{line: start, symbol: match[1], def: i})
+	}
+	if len(boundaries) == 0 {
+		return nil
+	}
+	var chunks []Chunk
+	if boundaries[0].line > 0 {
+		preamble := newChunk(file, lines, 1, boundaries[0].line)
+		preamble.Preamble = true
+		chunks = append(chunks, preamble)
+	}
+	for i, b := range boundaries {
+		end := len(lines)
+		if i+1 < len(boundaries) {
+			end = boundaries[i+1].line
+		}
+		chunk := newChunk(file, lines, b.line+1, end)
+		chunk.Symbol = b.symbol
+		chunk.Signature = strings.TrimRight(strings.TrimSpace(lines[b.def]), "{ ")
+		chunks = append(chunks, chunk)
+	}
+	return chunks
+}
+
+func hasLeader(line string, leaders []string) bool {
+	trimmed := strings.TrimSpace(line)
+	if trimmed == "" || strings.HasPrefix(line, " ") && !strings.HasPrefix(trimmed, "*") {
+		return false
+	}
+	for _, leader := range leaders {
+		if strings.HasPrefix(trimmed, leader) {
+			return true
+		}
+	}
+	return false
+}
+
+// splitBlocks chunks text at blank lines, merging paragraphs into blocks of
+// up to maxBlockLines lines. Longer paragraphs are cut at the limit.
+func splitBlocks(file string, lines []string) []Chunk {
+	var chunks []Chunk
+	start := 1
+	flush := func(end int) {
+		if end < start {
+			return
+		}
+		chunk := newChunk(file, lines, start, end)
+		for _, text := range lines[start-1 : end] {
+			if text = strings.TrimSpace(text); text != "" {
+				chunk.Signature = shorten(text, 80)
+				break
+			}
+		}
+		chunks = append(chunks, chunk)
+		start = end + 1
+	}
+	lastBlank := 0
+	for i, text := range lines {
+		n := i + 1
+		if strings.TrimSpace(text) == "" {
+			lastBlank = n
+		}
+		if n-start+1 >= maxBlockLines {
+			if lastBlank >= start {
+				flush(lastBlank)
+			} else {
+				flush(n)
+			}
+		}
+	}
+	flush(len(lines))
+	return chunks
+}
+
+func shorten(s string, n int) string {
+	runes := []rune(s)
+	if len(runes) <= n {
+		return s
+	}
+	return string(runes[:n-1]) + "…"
+}
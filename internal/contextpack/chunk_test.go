@@ -0,0 +1,118 @@
+package contextpack
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const goSource = `// Package server serves requests.
+package server
+
+import (
+	"fmt"
+	"net/http"
+)
+
+// Server handles requests.
+type Server struct {
+	addr string
+}
+
+// Start listens on the server's address.
+func (s *Server) Start(
+	handler http.Handler,
+) error {
+	return http.ListenAndServe(s.addr, handler)
+}
+
+func parseHeader(line string) (string, string) {
+	name, value, _ := strings.Cut(line, ":")
+	return name, fmt.Sprint(value)
+}
+`
+
+func symbols(chunks []Chunk) []string {
+	var out []string
+	for _, chunk := range chunks {
+		out = append(out, chunk.Symbol)
+	}
+	return out
+}
+
+// checkCoverage fails unless the chunks cover content exactly, in order.
+func checkCoverage(t *testing.T, chunks []Chunk, content string) {
+	t.Helper()
+	var b strings.Builder
+	line := 1
+	for _, chunk := range chunks {
+		if chunk.StartLine != line {
+			t.Fatalf("chunk %q starts at line %d, want %d", chunk.Symbol, chunk.StartLine, line)
+		}
+		line = chunk.EndLine + 1
+		b.WriteString(chunk.Text)
+	}
+	if b.String() != content {
+		t.Fatalf("chunks do not add up to the file:\n%s", b.String())
+	}
+}
+
+func TestSplitGo(t *testing.T) {
+	chunks := Split("server/server.go", goSource)
+	checkCoverage(t, chunks, goSource)
+	if got := symbols(chunks); !reflect.DeepEqual(got, []string{"", "Server", "Server.Start", "parseHeader"}) {
+		t.Fatalf("symbols = %q", got)
+	}
+	if !chunks[0].Preamble || !strings.Contains(chunks[0].Text, `"net/http"`) {
+		t.Errorf("preamble = %+v", chunks[0])
+	}
+	if !strings.HasPrefix(chunks[2].Text, "\n// Start listens") {
+		t.Errorf("the doc comment is not part of Start: %q", chunks[2].Text)
+	}
+	if want := "func (s *Server) Start( handler http.Handler, ) error"; chunks[2].Signature != want {
+		t.Errorf("signature = %q, want %q", chunks[2].Signature, want)
+	}
+}
+
+func TestSplitDefinitions(t *testing.T) {
+	ts := "import { x } from './x'\n\n/**\n * Adds.\n */\nexport function add(a: number) {\n  return a\n}\n\nexport class Box {\n  value = 1\n}\n"
+	chunks := Split("src/math.ts", ts)
+	checkCoverage(t, chunks, ts)
+	if got := symbols(chunks); !reflect.DeepEqual(got, []string{"", "add", "Box"}) {
+		t.Fatalf("symbols = %q", got)
+	}
+	if !strings.HasPrefix(chunks[1].Text, "/**") || chunks[1].Signature != "export function add(a: number)" {
+		t.Errorf("add = %+v", chunks[1])
+	}
+
+	py := "import os\n\n\n@cache\ndef load(path):\n    return os.read(path)\n\nclass Store:\n    def get(self):\n        pass\n"
+	chunks = Split("store.py", py)
+	checkCoverage(t, chunks, py)
+	if got := symbols(chunks); !reflect.DeepEqual(got, []string{"", "load", "Store"}) {
+		t.Fatalf("symbols = %q", got)
+	}
+	if !strings.HasPrefix(chunks[1].Text, "@cache\n") {
+		t.Errorf("the decorator is not part of load: %q", chunks[1].Text)
+	}
+}
+
+func TestSplitBlocks(t *testing.T) {
+	var b strings.Builder
+	for i := range 100 {
+		b.WriteString("line\n")
+		if i%10 == 9 {
+			b.WriteString("\n")
+		}
+	}
+	content := b.String()
+	chunks := Split("notes.md", content)
+	checkCoverage(t, chunks, content)
+	for _, chunk := range chunks {
+		if n := chunk.EndLine - chunk.StartLine + 1; n > maxBlockLines {
+			t.Errorf("block of %d lines", n)
+		}
+	}
+	if len(Split("broken.go", "package x\nfunc (")) == 0 {
+		t.Error("a Go file that does not parse is not split into blocks")
+	}
+}
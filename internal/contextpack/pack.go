@@ -0,0 +1,225 @@
+// Package contextpack fits source files into the token budget of a Jules or
+// Gemini prompt. Files are split into language-aware chunks, such as Go
+// declarations and JavaScript or Python top-level definitions. When the files
+// do not fit whole, each keeps its preamble, an outline of every symbol, and
+// the chunks most relevant to the prompt, ranked by embeddings or by the
+// identifiers they share with it.
+package contextpack
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"slices"
+	"strings"
+)
+
+// BytesPerToken is the estimate of source bytes per model token.
+const BytesPerToken = 4
+
+// DefaultBudget is the tokens all files may take together when Options
+// leaves Budget unset.
+const DefaultBudget = 24 << 10
+
+// EstimateTokens estimates the tokens text takes in a prompt.
+func EstimateTokens(text string) int {
+	return (len(text) + BytesPerToken - 1) / BytesPerToken
+}
+
+// File is a file to include in a prompt.
+type File struct {
+	Path    string
+	Content string
+}
+
+// Options configures Pack. Zero values use the defaults.
+type Options struct {
+	// Budget is the tokens all files may take together.
+	Budget int
+	// MaxFileTokens limits each file; zero means Budget.
+	MaxFileTokens int
+	// Ranker orders chunks by relevance; nil uses LexicalRanker.
+	Ranker Ranker
+}
+
+// PackedFile is a file as included in a prompt.
+type PackedFile struct {
+	Path    string `json:"path"`
+	Content string `json:"content"`
+	// Whole reports whether Content is the file unchanged.
+	Whole bool `json:"whole"`
+	// Included are the symbols kept in full, and Outlined those reduced to
+	// their signature.
+	Included []string `json:"included,omitempty"`
+	Outlined []string `json:"outlined,omitempty"`
+	Tokens   int      `json:"tokens"`
+}
+
+// Result is the packed files, in their original order.
+type Result struct {
+	Files  []PackedFile `json:"files"`
+	Tokens int          `json:"tokens"`
+	// Omitted are the files left out because not even their outline fit.
+	Omitted []string `json:"omitted,omitempty"`
+	// Notes describe fallbacks, such as ranking without embeddings.
+	Notes []string `json:"notes,omitempty"`
+}
+
+// packing is the state of one file while chunks are chosen.
+type packing struct {
+	file     File
+	chunks   []Chunk
+	included []bool
+	tokens   int
+}
+
+// Pack fits files into the budget. Files that fit whole together are kept
+// whole. Otherwise every file keeps its preamble and an outline line for each
+// chunk, and the chunks most relevant to query replace their outline lines,
+// as long as the file and the total stay within budget. Files whose outline
+// alone does not fit are left out, later files first.
+func Pack(ctx context.Context, query string, files []File, options Options) *Result {
+	if options.Budget <= 0 {
+		options.Budget = DefaultBudget
+	}
+	if options.MaxFileTokens <= 0 || options.MaxFileTokens > options.Budget {
+		options.MaxFileTokens = options.Budget
+	}
+	if options.Ranker == nil {
+		options.Ranker = LexicalRanker{}
+	}
+	result := &Result{Files: []PackedFile{}}
+
+	total, fits := 0, true
+	for _, file := range files {
+		tokens := EstimateTokens(file.Content)
+		total += tokens
+		fits = fits && tokens <= options.MaxFileTokens
+	}
+	if fits && total <= options.Budget {
+		for _, file := range files {
+			result.Files = append(result.Files, PackedFile{Path: file.Path, Content: file.Content, Whole: true, Tokens: EstimateTokens(file.Content)})
+		}
+		result.Tokens = total
+		return result
+	}
+
+	remaining := options.Budget
+	var packings []*packing
+	// candidates are the chunks that may be included, with their file.
+	var candidates []Chunk
+	var owners []*packing
+	var indexes []int
+	for _, file := range files {
+		p := &packing{file: file, chunks: Split(file.Path, file.Content)}
+		p.included = make([]bool, len(p.chunks))
+		for i, chunk := range p.chunks {
+			if chunk.Preamble {
+				p.included[i] = true
+				p.tokens += EstimateTokens(chunk.Text)
+			} else {
+				p.tokens += EstimateTokens(outlineLine(chunk))
+			}
+		}
+		if p.tokens > min(remaining, options.MaxFileTokens) {
+			result.Omitted = append(result.Omitted, file.Path)
+			continue
+		}
+		remaining -= p.tokens
+		packings = append(packings, p)
+		for i, chunk := range p.chunks {
+			if !p.included[i] {
+				candidates = append(candidates, chunk)
+				owners = append(owners, p)
+				indexes = append(indexes, i)
+			}
+		}
+	}
+
+	scores, err := options.Ranker.Rank(ctx, query, candidates)
+	if err != nil || len(scores) != len(candidates) {
+		if err == nil {
+			err = fmt.Errorf("got %d scores for %d chunks", len(scores), len(candidates))
+		}
+		result.Notes = append(result.Notes, fmt.Sprintf("ranked by shared identifiers: %v", err))
+		scores, _ = LexicalRanker{}.Rank(ctx, query, candidates)
+	}
+	order := make([]int, len(candidates))
+	for i := range order {
+		order[i] = i
+	}
+	slices.SortStableFunc(order, func(a, b int) int {
+		switch {
+		case scores[a] > scores[b]:
+			return -1
+		case scores[a] < scores[b]:
+			return 1
+		}
+		return 0
+	})
+	for _, i := range order {
+		chunk, p := candidates[i], owners[i]
+		cost := EstimateTokens(chunk.Text) - EstimateTokens(outlineLine(chunk))
+		if cost > remaining || p.tokens+cost > options.MaxFileTokens {
+			continue
+		}
+		remaining -= cost
+		p.tokens += cost
+		p.included[indexes[i]] = true
+	}
+
+	for _, p := range packings {
+		packed := render(p)
+		result.Tokens += packed.Tokens
+		result.Files = append(result.Files, packed)
+	}
+	return result
+}
+
+// render writes a file's preamble and included chunks in order, with an
+// outline line in place of each other chunk.
+func render(p *packing) PackedFile {
+	packed := PackedFile{Path: p.file.Path, Whole: true}
+	var b strings.Builder
+	for i, chunk := range p.chunks {
+		if p.included[i] {
+			b.WriteString(chunk.Text)
+			if chunk.Symbol != "" {
+				packed.Included = append(packed.Included, chunk.Symbol)
+			}
+			continue
+		}
+		packed.Whole = false
+		b.WriteString(outlineLine(chunk))
+		if chunk.Symbol != "" {
+			packed.Outlined = append(packed.Outlined, chunk.Symbol)
+		}
+	}
+	packed.Content = b.String()
+	if packed.Whole {
+		packed.Content = p.file.Content
+	}
+	packed.Tokens = EstimateTokens(packed.Content)
+	return packed
+}
+
+// outlineLine stands in for an omitted chunk, as a comment in the file's
+// language.
+func outlineLine(chunk Chunk) string {
+	prefix := commentPrefix(chunk.Path)
+	if chunk.Signature == "" {
+		return fmt.Sprintf("%s… lines %d-%d omitted\n", prefix, chunk.StartLine, chunk.EndLine)
+	}
+	return fmt.Sprintf("%s… %s (lines %d-%d omitted)\n", prefix, chunk.Signature, chunk.StartLine, chunk.EndLine)
+}
+
+func commentPrefix(file string) string {
+	ext := strings.ToLower(path.Ext(file))
+	switch {
+	case ext == ".go" || jsExtensions[ext] || ext == ".java" || ext == ".c" || ext == ".cc" || ext == ".rs" || ext == ".swift" || ext == ".kt":
+		return "// "
+	case ext == ".py" || ext == ".sh" || ext == ".rb" || ext == ".yaml" || ext == ".yml" || ext == ".toml":
+		return "# "
+	}
+	return ""
+}
@@ -0,0 +1,115 @@
+package contextpack
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// bigGoFile returns a Go file with one long function per name.
+func bigGoFile(names ...string) string {
+	var b strings.Builder
+	b.WriteString("package big\n\nimport \"fmt\"\n")
+	for _, name := range names {
+		fmt.Fprintf(&b, "\nfunc %s() {\n", name)
+		for i := range 50 {
+			fmt.Fprintf(&b, "\tfmt.Println(%q, %d)\n", "step of "+name, i)
+		}
+		b.WriteString("}\n")
+	}
+	return b.String()
+}
+
+func TestPackKeepsFilesThatFit(t *testing.T) {
+	files := []File{{Path: "a.go", Content: "package a\n"}, {Path: "b.txt", Content: "hello\n"}}
+	result := Pack(context.Background(), "anything", files, Options{Budget: 100})
+	if len(result.Files) != 2 || !result.Files[0].Whole || result.Files[1].Content != "hello\n" {
+		t.Errorf("result = %+v", result)
+	}
+}
+
+func TestPackSelectsRelevantChunks(t *testing.T) {
+	content := bigGoFile("renderInvoice", "sendEmail", "computeTax")
+	files := []File{{Path: "big.go", Content: content}}
+	budget := EstimateTokens(content) / 2
+
+	result := Pack(context.Background(), "Fix the rounding bug in computeTax", files, Options{Budget: budget})
+	packed := result.Files[0]
+	if packed.Whole || !reflect.DeepEqual(packed.Included, []string{"computeTax"}) {
+		t.Fatalf("included = %v, outlined = %v", packed.Included, packed.Outlined)
+	}
+	if !reflect.DeepEqual(packed.Outlined, []string{"renderInvoice", "sendEmail"}) {
+		t.Errorf("outlined = %v", packed.Outlined)
+	}
+	if !strings.HasPrefix(packed.Content, "package big\n\nimport \"fmt\"\n") ||
+		!strings.Contains(packed.Content, "// … func sendEmail() (lines ") ||
+		!strings.Contains(packed.Content, `"step of computeTax", 49`) {
+		t.Errorf("content:\n%s", packed.Content)
+	}
+	if result.Tokens > budget {
+		t.Errorf("tokens = %d, over the budget of %d", result.Tokens, budget)
+	}
+}
+
+func TestPackLimitsEachFileAndOmitsWhatDoesNotFit(t *testing.T) {
+	first := bigGoFile("alpha", "beta")
+	files := []File{
+		{Path: "first.go", Content: first},
+		{Path: "second.go", Content: bigGoFile("gamma")},
+	}
+	budget := EstimateTokens(first)
+	result := Pack(context.Background(), "alpha beta gamma", files, Options{Budget: budget, MaxFileTokens: budget / 2})
+	for _, packed := range result.Files {
+		if packed.Tokens > budget/2 {
+			t.Errorf("%s takes %d tokens, over the file limit of %d", packed.Path, packed.Tokens, budget/2)
+		}
+	}
+
+	tiny := Pack(context.Background(), "", files, Options{Budget: 12})
+	if !reflect.DeepEqual(tiny.Omitted, []string{"first.go", "second.go"}) || len(tiny.Files) != 0 {
+		t.Errorf("with a tiny budget: files = %d, omitted = %v", len(tiny.Files), tiny.Omitted)
+	}
+}
+
+func TestPackEmbeddingRanker(t *testing.T) {
+	content := bigGoFile("one", "two", "three")
+	files := []File{{Path: "big.go", Content: content}}
+	budget := EstimateTokens(content) / 2
+	// The query points the same way as chunks mentioning "two".
+	embed := func(_ context.Context, texts []string) ([][]float32, error) {
+		vectors := make([][]float32, len(texts))
+		for i, text := range texts {
+			vectors[i] = []float32{1, 0}
+			if i > 0 && !strings.Contains(text, "func two") {
+				vectors[i] = []float32{0, 1}
+			}
+		}
+		return vectors, nil
+	}
+	result := Pack(context.Background(), "query", files, Options{Budget: budget, Ranker: EmbeddingRanker{Embed: embed}})
+	if got := result.Files[0].Included; !reflect.DeepEqual(got, []string{"two"}) {
+		t.Errorf("included = %v, want two", got)
+	}
+
+	failing := EmbeddingRanker{Embed: func(context.Context, []string) ([][]float32, error) {
+		return nil, errors.New("quota exceeded")
+	}}
+	result = Pack(context.Background(), "three", files, Options{Budget: budget, Ranker: failing})
+	if len(result.Notes) != 1 || !strings.Contains(result.Notes[0], "quota exceeded") {
+		t.Errorf("notes = %v", result.Notes)
+	}
+	if got := result.Files[0].Included; !reflect.DeepEqual(got, []string{"three"}) {
+		t.Errorf("included after the fallback = %v, want three", got)
+	}
+}
+
+func TestTerms(t *testing.T) {
+	got := terms("parseHTTPHeader read_config_file")
+	want := []string{"parsehttpheader", "parse", "http", "header", "read_config_file", "read", "config", "file"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("terms = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,165 @@
+package contextpack
+
+import (
+	"context"
+	"math"
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+// maxEmbedBytes is the most of a chunk sent to be embedded; embedding models
+// read only the first couple of thousand tokens anyway.
+const maxEmbedBytes = 8 << 10
+
+// Ranker scores how relevant each chunk is to a query, higher being more
+// relevant. Scores are only compared with each other.
+type Ranker interface {
+	Rank(ctx context.Context, query string, chunks []Chunk) ([]float64, error)
+}
+
+// LexicalRanker scores chunks by the identifiers and words they share with
+// the query, weighted by how rare each is across the chunks. A chunk whose
+// symbol the query names scores highest.
+type LexicalRanker struct{}
+
+var wordPattern = regexp.MustCompile(`[A-Za-z][A-Za-z0-9_]*`)
+
+// terms splits text into lowercase words, also splitting identifiers at
+// underscores and camel-case humps, so "parseHTTPHeader" yields "parse",
+// "http", "header", and "parsehttpheader".
+func terms(text string) []string {
+	var out []string
+	for _, word := range wordPattern.FindAllString(text, -1) {
+		lower := strings.ToLower(word)
+		out = append(out, lower)
+		parts := splitIdentifier(word)
+		if len(parts) > 1 {
+			for _, part := range parts {
+				out = append(out, strings.ToLower(part))
+			}
+		}
+	}
+	filtered := out[:0]
+	for _, term := range out {
+		if len(term) > 2 && !stopWords[term] {
+			filtered = append(filtered, term)
+		}
+	}
+	return filtered
+}
+
+func splitIdentifier(word string) []string {
+	var parts []string
+	for _, part := range strings.Split(word, "_") {
+		runes := []rune(part)
+		start := 0
+		for i := 1; i < len(runes); i++ {
+			upper := unicode.IsUpper(runes[i])
+			lowerBefore := unicode.IsLower(runes[i-1]) || unicode.IsDigit(runes[i-1])
+			acronymEnd := unicode.IsUpper(runes[i-1]) && i+1 < len(runes) && unicode.IsLower(runes[i+1])
+			if upper && (lowerBefore || acronymEnd) {
+				parts = append(parts, string(runes[start:i]))
+				start = i
+			}
+		}
+		if start < len(runes) {
+			parts = append(parts, string(runes[start:]))
+		}
+	}
+	return parts
+}
+
+// stopWords are common English and keyword terms that say nothing about
+// relevance.
+var stopWords = map[string]bool{
+	"the": true, "and": true, "for": true, "with": true, "that": true, "this": true, "from": true,
+	"into": true, "are": true, "was": true, "not": true, "but": true, "all": true, "any": true,
+	"func": true, "return": true, "var": true, "const": true, "type": true, "struct": true,
+	"string": true, "int": true, "err": true, "nil": true, "error": true, "def": true, "self": true,
+	"function": true, "import": true, "export": true, "let": true, "new": true, "true": true, "false": true,
+}
+
+// Rank implements Ranker.
+func (LexicalRanker) Rank(_ context.Context, query string, chunks []Chunk) ([]float64, error) {
+	queryTerms := map[string]bool{}
+	for _, term := range terms(query) {
+		queryTerms[term] = true
+	}
+	queryLower := strings.ToLower(query)
+
+	counts := make([]map[string]int, len(chunks))
+	documents := map[string]int{}
+	for i, chunk := range chunks {
+		counts[i] = map[string]int{}
+		for _, term := range terms(chunk.Text) {
+			if queryTerms[term] {
+				counts[i][term]++
+			}
+		}
+		for term := range counts[i] {
+			documents[term]++
+		}
+	}
+
+	scores := make([]float64, len(chunks))
+	for i, chunk := range chunks {
+		var score float64
+		for term, n := range counts[i] {
+			idf := math.Log(1 + float64(len(chunks))/float64(documents[term]))
+			score += (1 + math.Log(float64(n))) * idf
+		}
+		// Normalize so long chunks do not win by size alone.
+		score /= math.Sqrt(1 + float64(len(chunk.Text))/1000)
+		if symbol := chunk.Symbol; symbol != "" {
+			name := symbol[strings.LastIndex(symbol, ".")+1:]
+			if len(name) > 2 && strings.Contains(queryLower, strings.ToLower(name)) {
+				score += 10
+			}
+		}
+		scores[i] = score
+	}
+	return scores, nil
+}
+
+// EmbeddingRanker scores chunks by the cosine similarity of their embedding
+// to the query's.
+type EmbeddingRanker struct {
+	// Embed returns a vector for each text, such as gemini.Client.Embed.
+	Embed func(ctx context.Context, texts []string) ([][]float32, error)
+}
+
+// Rank implements Ranker.
+func (r EmbeddingRanker) Rank(ctx context.Context, query string, chunks []Chunk) ([]float64, error) {
+	texts := make([]string, 0, len(chunks)+1)
+	texts = append(texts, query)
+	for _, chunk := range chunks {
+		text := chunk.Path + "\n" + chunk.Text
+		if len(text) > maxEmbedBytes {
+			text = text[:maxEmbedBytes]
+		}
+		texts = append(texts, text)
+	}
+	vectors, err := r.Embed(ctx, texts)
+	if err != nil {
+		return nil, err
+	}
+	scores := make([]float64, len(chunks))
+	for i := range chunks {
+		scores[i] = cosine(vectors[0], vectors[i+1])
+	}
+	return scores, nil
+}
+
+func cosine(a, b []float32) float64 {
+	var dot, na, nb float64
+	for i := range min(len(a), len(b)) {
+		dot += float64(a[i]) * float64(b[i])
+		na += float64(a[i]) * float64(a[i])
+		nb += float64(b[i]) * float64(b[i])
+	}
+	if na == 0 || nb == 0 {
+		return 0
+	}
+	return dot / math.Sqrt(na*nb)
+}
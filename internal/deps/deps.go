@@ -0,0 +1,327 @@
+// Package deps finds outdated Go and npm dependencies of a project and
+// groups them into upgrades that can each be made and reviewed at once.
+package deps
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+
+	"golang.org/x/mod/semver"
+)
+
+// Ecosystems.
+const (
+	Go  = "go"
+	NPM = "npm"
+)
+
+// Bumps, from the least to the most disruptive.
+const (
+	BumpPatch = "patch"
+	BumpMinor = "minor"
+	BumpMajor = "major"
+)
+
+// Dependency is a direct dependency with a newer version available.
+type Dependency struct {
+	Ecosystem string `json:"ecosystem"`
+	Name      string `json:"name"`
+	Current   string `json:"current"`
+	// Wanted is the newest version compatible with the declared
+	// requirement: the newest minor or patch release for Go modules, and the
+	// newest version in package.json's range for npm.
+	Wanted string `json:"wanted"`
+	Latest string `json:"latest"`
+}
+
+// Upgrade is the version a dependency is upgraded to.
+type Upgrade struct {
+	Dependency
+	Target string `json:"target"`
+	Bump   string `json:"bump"`
+}
+
+// Detect lists the outdated direct dependencies of the project in dir: Go
+// modules when it has a go.mod, npm packages when it has a package.json.
+// Ecosystems limits the detection; empty means all.
+func Detect(ctx context.Context, dir string, ecosystems []string) ([]Dependency, error) {
+	enabled := func(ecosystem string) bool {
+		return len(ecosystems) == 0 || slices.Contains(ecosystems, ecosystem)
+	}
+	var found []Dependency
+	if enabled(Go) && fileExists(dir, "go.mod") {
+		output, err := run(ctx, dir, "go", "list", "-u", "-m", "-json", "all")
+		if err != nil {
+			return nil, err
+		}
+		deps, err := ParseGoList(output)
+		if err != nil {
+			return nil, err
+		}
+		found = append(found, deps...)
+	}
+	if enabled(NPM) && fileExists(dir, "package.json") {
+		// npm outdated exits 1 when something is outdated, which is no error.
+		output, err := run(ctx, dir, "npm", "outdated", "--json")
+		var exitErr *exec.ExitError
+		if err != nil && !(errors.As(err, &exitErr) && exitErr.ExitCode() == 1) {
+			return nil, err
+		}
+		deps, err := ParseNPMOutdated(output)
+		if err != nil {
+			return nil, err
+		}
+		found = append(found, deps...)
+	}
+	return found, nil
+}
+
+// ParseGoList reads the output of `go list -u -m -json all` and returns the
+// direct requirements with an update.
+func ParseGoList(output []byte) ([]Dependency, error) {
+	var deps []Dependency
+	decoder := json.NewDecoder(bytes.NewReader(output))
+	for {
+		var module struct {
+			Path     string
+			Version  string
+			Main     bool
+			Indirect bool
+			Update   *struct{ Version string }
+		}
+		if err := decoder.Decode(&module); err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, fmt.Errorf("failed to parse go list output: %w", err)
+		}
+		if module.Main || module.Indirect || module.Update == nil {
+			continue
+		}
+		deps = append(deps, Dependency{
+			Ecosystem: Go,
+			Name:      module.Path,
+			Current:   module.Version,
+			Wanted:    module.Update.Version,
+			Latest:    module.Update.Version,
+		})
+	}
+	return deps, nil
+}
+
+// ParseNPMOutdated reads the output of `npm outdated --json`. Packages that
+// are not installed are skipped, since there is no current version to
+// upgrade from.
+func ParseNPMOutdated(output []byte) ([]Dependency, error) {
+	if len(bytes.TrimSpace(output)) == 0 {
+		return nil, nil
+	}
+	var packages map[string]struct {
+		Current string `json:"current"`
+		Wanted  string `json:"wanted"`
+		Latest  string `json:"latest"`
+	}
+	if err := json.Unmarshal(output, &packages); err != nil {
+		return nil, fmt.Errorf("failed to parse npm outdated output: %w", err)
+	}
+	var deps []Dependency
+	for name, pkg := range packages {
+		if pkg.Current == "" {
+			continue
+		}
+		deps = append(deps, Dependency{Ecosystem: NPM, Name: name, Current: pkg.Current, Wanted: pkg.Wanted, Latest: pkg.Latest})
+	}
+	slices.SortFunc(deps, func(a, b Dependency) int { return strings.Compare(a.Name, b.Name) })
+	return deps, nil
+}
+
+// Bump classifies the change from one version to another. Minor releases
+// before v1 may break compatibility, so they count as major.
+func Bump(from, to string) string {
+	from, to = canonical(from), canonical(to)
+	switch {
+	case semver.Major(from) != semver.Major(to):
+		return BumpMajor
+	case semver.MajorMinor(from) == semver.MajorMinor(to):
+		return BumpPatch
+	case semver.Major(from) == "v0":
+		return BumpMajor
+	}
+	return BumpMinor
+}
+
+// Plan picks the version to upgrade each dependency to: the newest release
+// without breaking changes, or with major the newest release.
+func Plan(deps []Dependency, major bool) []Upgrade {
+	var upgrades []Upgrade
+	for _, dep := range deps {
+		target := dep.Latest
+		if Bump(dep.Current, target) == BumpMajor && !major {
+			target = dep.Wanted
+		}
+		if target == "" || semver.Compare(canonical(target), canonical(dep.Current)) <= 0 {
+			continue
+		}
+		bump := Bump(dep.Current, target)
+		if bump == BumpMajor && !major {
+			continue
+		}
+		upgrades = append(upgrades, Upgrade{Dependency: dep, Target: target, Bump: bump})
+	}
+	return upgrades
+}
+
+// Group is a set of upgrades made in one session and reviewed in one pull
+// request.
+type Group struct {
+	Name      string    `json:"name"`
+	Ecosystem string    `json:"ecosystem"`
+	Upgrades  []Upgrade `json:"upgrades"`
+}
+
+// Major reports whether the group upgrades across a major version.
+func (g Group) Major() bool {
+	return len(g.Upgrades) == 1 && g.Upgrades[0].Bump == BumpMajor
+}
+
+// Title describes the group in a commit header.
+func (g Group) Title() string {
+	if len(g.Upgrades) == 1 {
+		u := g.Upgrades[0]
+		return fmt.Sprintf("upgrade %s from %s to %s", u.Name, u.Current, u.Target)
+	}
+	return fmt.Sprintf("upgrade %d %s dependencies", len(g.Upgrades), g.Ecosystem)
+}
+
+var branchUnsafe = regexp.MustCompile(`[^a-zA-Z0-9._-]+`)
+
+// Branch returns the name of the branch the group's changes are pushed to.
+func (g Group) Branch() string {
+	return "juleson/deps/" + strings.Trim(branchUnsafe.ReplaceAllString(g.Name, "-"), "-.")
+}
+
+// GroupUpgrades groups the upgrades per ecosystem: the patch and minor
+// upgrades together, since they should not break anything, and each major
+// upgrade alone, since it may need code changes of its own.
+func GroupUpgrades(upgrades []Upgrade) []Group {
+	var groups []Group
+	safe := map[string]int{}
+	for _, u := range upgrades {
+		if u.Bump == BumpMajor {
+			groups = append(groups, Group{Name: u.Ecosystem + "-" + u.Name + "-" + majorOf(u.Target), Ecosystem: u.Ecosystem, Upgrades: []Upgrade{u}})
+			continue
+		}
+		i, ok := safe[u.Ecosystem]
+		if !ok {
+			i = len(groups)
+			safe[u.Ecosystem] = i
+			groups = append(groups, Group{Name: u.Ecosystem + "-minor", Ecosystem: u.Ecosystem})
+		}
+		groups[i].Upgrades = append(groups[i].Upgrades, u)
+	}
+	// Safe groups first: they are the most likely to be merged.
+	slices.SortStableFunc(groups, func(a, b Group) int {
+		switch {
+		case a.Major() == b.Major():
+			return 0
+		case b.Major():
+			return -1
+		}
+		return 1
+	})
+	return groups
+}
+
+// Prompt asks Jules to make a group's upgrades, reading the changelogs of
+// the versions skipped.
+func Prompt(g Group) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Upgrade these %s dependencies:\n\n", ecosystemName(g.Ecosystem))
+	for _, u := range g.Upgrades {
+		fmt.Fprintf(&b, "- %s from %s to %s (%s)\n", u.Name, u.Current, u.Target, u.Bump)
+	}
+	b.WriteString("\nRun:\n\n")
+	for _, command := range UpgradeCommands(g) {
+		fmt.Fprintf(&b, "    %s\n", command)
+	}
+	b.WriteString(`
+Before changing code, read each dependency's changelog, release notes, or
+migration guide for every version after the current one up to the target.
+Adapt the code to breaking changes and replace APIs they deprecate, and do
+not upgrade anything else. Keep the build and the existing tests passing;
+only change a test when the changelog documents the behavior it checks as
+changed.
+
+End with a short summary per dependency of the changelog entries that matter
+to this project and the code changes they needed.
+`)
+	return b.String()
+}
+
+// UpgradeCommands returns the commands that upgrade a group.
+func UpgradeCommands(g Group) []string {
+	var targets []string
+	for _, u := range g.Upgrades {
+		targets = append(targets, u.Name+"@"+u.Target)
+	}
+	if g.Ecosystem == NPM {
+		return []string{"npm install " + strings.Join(targets, " ")}
+	}
+	return []string{"go get " + strings.Join(targets, " "), "go mod tidy"}
+}
+
+// Description returns a pull request description listing the upgrades.
+func Description(g Group) string {
+	var b strings.Builder
+	b.WriteString("| Dependency | From | To | Bump |\n| --- | --- | --- | --- |\n")
+	for _, u := range g.Upgrades {
+		fmt.Fprintf(&b, "| `%s` | %s | %s | %s |\n", u.Name, u.Current, u.Target, u.Bump)
+	}
+	return b.String()
+}
+
+func ecosystemName(ecosystem string) string {
+	if ecosystem == NPM {
+		return "npm"
+	}
+	return "Go module"
+}
+
+// canonical returns a version in the form the semver package expects.
+func canonical(version string) string {
+	version = strings.TrimPrefix(version, "=")
+	if !strings.HasPrefix(version, "v") {
+		version = "v" + version
+	}
+	return version
+}
+
+func majorOf(version string) string {
+	return semver.Major(canonical(version))
+}
+
+func fileExists(dir, name string) bool {
+	_, err := os.Stat(filepath.Join(dir, name))
+	return err == nil
+}
+
+func run(ctx context.Context, dir, name string, args ...string) ([]byte, error) {
+	cmd := exec.CommandContext(ctx, name, args...)
+	cmd.Dir = dir
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	output, err := cmd.Output()
+	if err != nil {
+		return output, fmt.Errorf("%s %s failed: %w\n%s", name, strings.Join(args, " "), err, strings.TrimSpace(stderr.String()))
+	}
+	return output, nil
+}
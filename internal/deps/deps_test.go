@@ -0,0 +1,121 @@
+package deps
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseGoList(t *testing.T) {
+	output := []byte(`{"Path": "example.com/app", "Main": true}
+{"Path": "github.com/a/lib", "Version": "v1.2.0", "Update": {"Path": "github.com/a/lib", "Version": "v1.4.1"}}
+{"Path": "github.com/b/indirect", "Version": "v0.1.0", "Indirect": true, "Update": {"Version": "v0.2.0"}}
+{"Path": "github.com/c/current", "Version": "v2.0.0"}
+`)
+	deps, err := ParseGoList(output)
+	if err != nil {
+		t.Fatalf("ParseGoList() error = %v", err)
+	}
+	want := []Dependency{{Ecosystem: Go, Name: "github.com/a/lib", Current: "v1.2.0", Wanted: "v1.4.1", Latest: "v1.4.1"}}
+	if !reflect.DeepEqual(deps, want) {
+		t.Errorf("deps = %+v, want %+v", deps, want)
+	}
+
+	if _, err := ParseGoList([]byte("{")); err == nil {
+		t.Error("ParseGoList() of truncated output succeeded")
+	}
+}
+
+func TestParseNPMOutdated(t *testing.T) {
+	output := []byte(`{
+  "react": {"current": "18.2.0", "wanted": "18.3.1", "latest": "19.0.0"},
+  "left-pad": {"wanted": "1.3.0", "latest": "1.3.0"},
+  "chalk": {"current": "5.0.0", "wanted": "5.3.0", "latest": "5.3.0"}
+}`)
+	deps, err := ParseNPMOutdated(output)
+	if err != nil {
+		t.Fatalf("ParseNPMOutdated() error = %v", err)
+	}
+	want := []Dependency{
+		{Ecosystem: NPM, Name: "chalk", Current: "5.0.0", Wanted: "5.3.0", Latest: "5.3.0"},
+		{Ecosystem: NPM, Name: "react", Current: "18.2.0", Wanted: "18.3.1", Latest: "19.0.0"},
+	}
+	if !reflect.DeepEqual(deps, want) {
+		t.Errorf("deps = %+v, want %+v", deps, want)
+	}
+	if deps, err := ParseNPMOutdated(nil); err != nil || deps != nil {
+		t.Errorf("ParseNPMOutdated(nil) = %v, %v", deps, err)
+	}
+}
+
+func TestBump(t *testing.T) {
+	tests := []struct{ from, to, want string }{
+		{"v1.2.0", "v1.2.3", BumpPatch},
+		{"v1.2.0", "v1.4.0", BumpMinor},
+		{"18.2.0", "19.0.0", BumpMajor},
+		{"v0.3.0", "v0.4.0", BumpMajor},
+		{"v0.3.0", "v0.3.1", BumpPatch},
+	}
+	for _, tt := range tests {
+		if got := Bump(tt.from, tt.to); got != tt.want {
+			t.Errorf("Bump(%s, %s) = %s, want %s", tt.from, tt.to, got, tt.want)
+		}
+	}
+}
+
+func TestPlanAndGroup(t *testing.T) {
+	deps := []Dependency{
+		{Ecosystem: Go, Name: "github.com/a/lib", Current: "v1.2.0", Wanted: "v1.4.1", Latest: "v1.4.1"},
+		{Ecosystem: Go, Name: "github.com/z/zero", Current: "v0.3.0", Wanted: "v0.4.0", Latest: "v0.4.0"},
+		{Ecosystem: NPM, Name: "react", Current: "18.2.0", Wanted: "18.3.1", Latest: "19.0.0"},
+		{Ecosystem: NPM, Name: "vite", Current: "4.0.0", Wanted: "4.0.0", Latest: "5.0.0"},
+	}
+
+	groups := GroupUpgrades(Plan(deps, false))
+	if len(groups) != 2 {
+		t.Fatalf("safe groups = %+v", groups)
+	}
+	if groups[0].Name != "go-minor" || len(groups[0].Upgrades) != 1 || groups[0].Upgrades[0].Target != "v1.4.1" {
+		t.Errorf("go group = %+v", groups[0])
+	}
+	if groups[1].Name != "npm-minor" || groups[1].Upgrades[0].Target != "18.3.1" {
+		t.Errorf("npm group = %+v", groups[1])
+	}
+
+	groups = GroupUpgrades(Plan(deps, true))
+	var names []string
+	for _, g := range groups {
+		names = append(names, g.Branch())
+	}
+	want := []string{"juleson/deps/go-minor", "juleson/deps/go-github.com-z-zero-v0", "juleson/deps/npm-react-v19", "juleson/deps/npm-vite-v5"}
+	if !reflect.DeepEqual(names, want) {
+		t.Errorf("branches = %q, want %q", names, want)
+	}
+	if !groups[2].Major() || groups[2].Title() != "upgrade react from 18.2.0 to 19.0.0" {
+		t.Errorf("react group = %+v, title %q", groups[2], groups[2].Title())
+	}
+}
+
+func TestPrompt(t *testing.T) {
+	group := Group{Name: "go-minor", Ecosystem: Go, Upgrades: []Upgrade{
+		{Dependency: Dependency{Name: "github.com/a/lib", Current: "v1.2.0"}, Target: "v1.4.1", Bump: BumpMinor},
+		{Dependency: Dependency{Name: "github.com/b/lib", Current: "v2.0.0"}, Target: "v2.0.3", Bump: BumpPatch},
+	}}
+	prompt := Prompt(group)
+	for _, want := range []string{
+		"- github.com/a/lib from v1.2.0 to v1.4.1 (minor)",
+		"go get github.com/a/lib@v1.4.1 github.com/b/lib@v2.0.3",
+		"go mod tidy",
+		"changelog",
+	} {
+		if !strings.Contains(prompt, want) {
+			t.Errorf("prompt is missing %q:\n%s", want, prompt)
+		}
+	}
+	if got := group.Title(); got != "upgrade 2 go dependencies" {
+		t.Errorf("Title() = %q", got)
+	}
+	if got := UpgradeCommands(Group{Ecosystem: NPM, Upgrades: group.Upgrades[:1]}); !reflect.DeepEqual(got, []string{"npm install github.com/a/lib@v1.4.1"}) {
+		t.Errorf("npm commands = %q", got)
+	}
+}
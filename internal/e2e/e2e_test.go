@@ -0,0 +1,20 @@
+package e2e
+
+import (
+	"context"
+	"os/exec"
+	"testing"
+)
+
+func TestScenarios(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+	for _, scenario := range Scenarios {
+		t.Run(scenario.Name, func(t *testing.T) {
+			if err := RunScenario(context.Background(), t.TempDir(), scenario); err != nil {
+				t.Fatal(err)
+			}
+		})
+	}
+}
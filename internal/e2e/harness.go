@@ -0,0 +1,230 @@
+// Package e2e drives Juleson end to end against fake Jules and GitHub APIs.
+//
+// A Harness starts the fakes, serves the MCP server over streamable HTTP
+// with a client connected to it, and points the audit log and a file event
+// sink at a scratch directory. Scenarios run representative workflows
+// through MCP tools and CLI commands and check the events and artifacts
+// they leave behind, so regressions anywhere in the orchestration pipeline
+// fail a scenario. cmd/juleson-e2e runs them as a standalone binary.
+package e2e
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/SamyRai/juleson/internal/config"
+	"github.com/SamyRai/juleson/internal/events"
+	"github.com/SamyRai/juleson/internal/github/githubtest"
+	"github.com/SamyRai/juleson/internal/guard"
+	"github.com/SamyRai/juleson/internal/jules/julestest"
+	jmcp "github.com/SamyRai/juleson/internal/mcp"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/spf13/cobra"
+)
+
+// Fake API identity and demo repository.
+const (
+	apiKey = "e2e"
+	login  = "e2e-user"
+	// Owner and Repo name the repository seeded in both fakes.
+	Owner = "juleson-e2e"
+	Repo  = "service"
+)
+
+// Harness is a running set of fakes and an MCP client connected to the
+// MCP server over HTTP.
+type Harness struct {
+	Jules  *julestest.Server
+	GitHub *githubtest.Server
+	Config *config.Config
+	// Dir holds the audit log, the event sink file, and scratch work trees.
+	Dir string
+	// SourceID is the Jules source of the seeded repository.
+	SourceID string
+
+	mcpServer *httptest.Server
+	session   *mcp.ClientSession
+}
+
+// Start starts a harness whose files are kept in dir. Call Close when done.
+func Start(ctx context.Context, dir string) (*Harness, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+	h := &Harness{
+		Jules:  julestest.NewServer(),
+		GitHub: githubtest.NewServer(login),
+		Dir:    dir,
+	}
+	h.Jules.APIKey = apiKey
+	h.Jules.Simulate = true
+	h.GitHub.AddRepository(Owner, Repo, "main")
+	h.SourceID = h.Jules.AddSource(Owner, Repo, "main").ID
+
+	h.Config = &config.Config{
+		Jules:  config.JulesConfig{APIKey: apiKey, BaseURL: h.Jules.URL()},
+		GitHub: config.GitHubConfig{Token: apiKey, BaseURL: h.GitHub.URL(), UploadURL: h.GitHub.UploadURL()},
+		Audit:  config.AuditConfig{Enabled: true, Path: filepath.Join(dir, "audit.jsonl")},
+		Events: config.EventsConfig{Sinks: []config.EventSinkConfig{
+			{Name: "e2e", Type: "file", Path: h.eventsPath()},
+		}},
+		Guard: config.GuardConfig{Prompts: guard.ModeBlock, Outputs: guard.ModeBlock},
+	}
+
+	handler, err := jmcp.NewHTTPHandler(jmcp.ServerOptions{Config: h.Config})
+	if err != nil {
+		h.Close()
+		return nil, err
+	}
+	h.mcpServer = httptest.NewServer(handler)
+	client := mcp.NewClient(&mcp.Implementation{Name: "juleson-e2e", Version: "v1"}, nil)
+	h.session, err = client.Connect(ctx, &mcp.StreamableClientTransport{
+		Endpoint:             h.mcpServer.URL,
+		DisableStandaloneSSE: true,
+		MaxRetries:           -1,
+	}, nil)
+	if err != nil {
+		h.Close()
+		return nil, fmt.Errorf("failed to connect to the MCP server: %w", err)
+	}
+	return h, nil
+}
+
+// Close disconnects the MCP client and stops the servers.
+func (h *Harness) Close() {
+	if h.session != nil {
+		_ = h.session.Close()
+	}
+	if h.mcpServer != nil {
+		h.mcpServer.Close()
+	}
+	h.Jules.Close()
+	h.GitHub.Close()
+}
+
+// CallTool calls an MCP tool with args and decodes its structured result
+// into out, which may be nil. A tool error is returned as an error.
+func (h *Harness) CallTool(ctx context.Context, name string, args, out any) error {
+	result, err := h.session.CallTool(ctx, &mcp.CallToolParams{Name: name, Arguments: args})
+	if err != nil {
+		return fmt.Errorf("%s: %w", name, err)
+	}
+	if result.IsError {
+		var text []string
+		for _, content := range result.Content {
+			if content, ok := content.(*mcp.TextContent); ok {
+				text = append(text, content.Text)
+			}
+		}
+		return &ToolError{Tool: name, Message: strings.Join(text, "\n")}
+	}
+	if out == nil {
+		return nil
+	}
+	raw, err := json.Marshal(result.StructuredContent)
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(raw, out); err != nil {
+		return fmt.Errorf("%s: failed to decode result: %w", name, err)
+	}
+	return nil
+}
+
+// ToolError is an error result of an MCP tool.
+type ToolError struct {
+	Tool    string
+	Message string
+}
+
+func (e *ToolError) Error() string {
+	return e.Tool + " failed: " + e.Message
+}
+
+// RunCommand runs a CLI command built for the harness configuration with
+// args and returns what it wrote to its output.
+func (h *Harness) RunCommand(ctx context.Context, newCommand func(*config.Config) *cobra.Command, args ...string) (string, error) {
+	cmd := newCommand(h.Config)
+	var out bytes.Buffer
+	cmd.SetOut(&out)
+	cmd.SetErr(&out)
+	cmd.SetArgs(args)
+	err := cmd.ExecuteContext(ctx)
+	return out.String(), err
+}
+
+func (h *Harness) eventsPath() string {
+	return filepath.Join(h.Dir, "events.jsonl")
+}
+
+// Events returns the events delivered to the file sink, oldest first.
+func (h *Harness) Events() ([]events.Event, error) {
+	file, err := os.Open(h.eventsPath())
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	var found []events.Event
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 0, 64*1024), 10*1024*1024)
+	for scanner.Scan() {
+		var event events.Event
+		if err := json.Unmarshal(scanner.Bytes(), &event); err != nil {
+			return nil, fmt.Errorf("malformed event %q: %w", scanner.Text(), err)
+		}
+		found = append(found, event)
+	}
+	return found, scanner.Err()
+}
+
+// Audited returns the audit records delivered to the file sink, oldest
+// first.
+func (h *Harness) Audited() ([]events.AuditData, error) {
+	found, err := h.Events()
+	if err != nil {
+		return nil, err
+	}
+	var audited []events.AuditData
+	for _, event := range found {
+		if event.Type != events.EventAuditRecorded {
+			continue
+		}
+		data, err := events.DecodeAuditData(event)
+		if err != nil {
+			return nil, err
+		}
+		audited = append(audited, data)
+	}
+	return audited, nil
+}
+
+// ExpectAudit checks that the audit records delivered to the file sink
+// include, in order, one for each action, each succeeding or failing as
+// success says.
+func (h *Harness) ExpectAudit(success bool, actions ...string) error {
+	audited, err := h.Audited()
+	if err != nil {
+		return err
+	}
+	next := 0
+	for _, data := range audited {
+		if next < len(actions) && data.Action == actions[next] && data.Success == success {
+			next++
+		}
+	}
+	if next < len(actions) {
+		return fmt.Errorf("no audit event %s with success=%t in %+v", actions[next], success, audited)
+	}
+	return nil
+}
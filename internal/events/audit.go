@@ -0,0 +1,43 @@
+package events
+
+import (
+	"log/slog"
+	"path/filepath"
+	"time"
+
+	"github.com/SamyRai/juleson/internal/secrets"
+)
+
+// OpenAuditLog opens the append-only audit journal at path. The store keeps
+// every entry and never writes snapshot files. A non-nil cipher encrypts
+// new entries.
+func OpenAuditLog(path string, cipher secrets.Sealer, logger *slog.Logger) (*EventStore, error) {
+	return NewEventStore(&EventStoreConfig{
+		StorageDir:  filepath.Dir(path),
+		JournalPath: path,
+		Cipher:      cipher,
+	}, logger)
+}
+
+// NewAuditEvent creates an audit event for a mutating operation.
+func NewAuditEvent(source string, data AuditData) Event {
+	return NewEvent(EventAuditRecorded, source, data).WithTopic(TopicAudit)
+}
+
+// AuditEntries returns the audit events recorded at or after since, oldest
+// first. A zero since returns every entry.
+func AuditEntries(store *EventStore, since time.Time) []StoredEvent {
+	var entries []StoredEvent
+	for _, event := range store.GetByType(EventAuditRecorded) {
+		if !since.IsZero() && event.Timestamp.Before(since) {
+			continue
+		}
+		entries = append(entries, event)
+	}
+	return entries
+}
+
+// DecodeAuditData returns the AuditData of an audit event.
+func DecodeAuditData(event Event) (AuditData, error) {
+	return DecodeData[AuditData](event)
+}
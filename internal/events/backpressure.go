@@ -0,0 +1,274 @@
+package events
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+// OverflowPolicy decides what happens to an event when a subscriber's
+// buffer is full.
+type OverflowPolicy string
+
+// Overflow policies.
+const (
+	// OverflowDropOldest drops the oldest buffered event to make room.
+	OverflowDropOldest OverflowPolicy = "drop-oldest"
+	// OverflowBlock makes the publisher wait for room.
+	OverflowBlock OverflowPolicy = "block"
+	// OverflowSpill enqueues the event on a message queue instead.
+	OverflowSpill OverflowPolicy = "spill"
+)
+
+// BackpressureConfig configures Backpressure.
+type BackpressureConfig struct {
+	// Rate limits deliveries per subscriber, in events per second. Zero
+	// means unlimited.
+	Rate float64
+	// Burst is how many events may be delivered at once before Rate
+	// applies (default 1).
+	Burst int
+	// BufferSize is how many events each subscriber may have pending
+	// (default 100).
+	BufferSize int
+	// Overflow is the policy for full buffers (default drop-oldest).
+	Overflow OverflowPolicy
+	// SpillQueue and SpillQueueName receive overflowing events with the
+	// spill policy, as messages whose payload is the event.
+	SpillQueue     *MessageQueue
+	SpillQueueName string
+}
+
+// BackpressureMetrics counts events passing through Backpressure.
+type BackpressureMetrics struct {
+	Delivered int64
+	Dropped   int64
+	Spilled   int64
+	// DroppedBySubscriber counts dropped events per subscriber ID.
+	DroppedBySubscriber map[string]int64
+}
+
+// Backpressure decouples publishers from slow subscribers. Each subscriber
+// gets a bounded buffer drained by its own worker at up to the configured
+// rate, so publishing returns once the event is buffered. Handler errors are
+// logged instead of returned to the publisher.
+type Backpressure struct {
+	config BackpressureConfig
+	logger *slog.Logger
+	// mu is held for reading while events are buffered, and for writing
+	// by Close, so buffers are never closed during a send.
+	mu        sync.RWMutex
+	closed    bool
+	buffersMu sync.Mutex
+	buffers   map[string]chan bufferedEvent
+	wg        sync.WaitGroup
+
+	metricsMu sync.Mutex
+	metrics   BackpressureMetrics
+}
+
+type bufferedEvent struct {
+	ctx   context.Context
+	next  EventHandler
+	event Event
+}
+
+// NewBackpressure creates backpressure with the given config.
+func NewBackpressure(config BackpressureConfig, logger *slog.Logger) (*Backpressure, error) {
+	if config.BufferSize <= 0 {
+		config.BufferSize = 100
+	}
+	if config.Burst <= 0 {
+		config.Burst = 1
+	}
+	switch config.Overflow {
+	case "":
+		config.Overflow = OverflowDropOldest
+	case OverflowDropOldest, OverflowBlock:
+	case OverflowSpill:
+		if config.SpillQueue == nil || config.SpillQueueName == "" {
+			return nil, fmt.Errorf("overflow policy %s requires a spill queue", OverflowSpill)
+		}
+	default:
+		return nil, fmt.Errorf("unknown overflow policy %q (use drop-oldest, block, or spill)", config.Overflow)
+	}
+	if logger == nil {
+		logger = slog.Default()
+	}
+	return &Backpressure{
+		config:  config,
+		logger:  logger,
+		buffers: make(map[string]chan bufferedEvent),
+		metrics: BackpressureMetrics{DroppedBySubscriber: make(map[string]int64)},
+	}, nil
+}
+
+// Middleware returns the middleware that buffers events per subscriber.
+func (b *Backpressure) Middleware() Middleware {
+	return func(next EventHandler) EventHandler {
+		return func(ctx context.Context, event Event) error {
+			b.mu.RLock()
+			defer b.mu.RUnlock()
+			if b.closed {
+				return fmt.Errorf("backpressure is closed")
+			}
+			subscriber := SubscriberID(ctx)
+			return b.push(ctx, subscriber, b.buffer(subscriber), bufferedEvent{
+				ctx:   context.WithoutCancel(ctx),
+				next:  next,
+				event: event,
+			})
+		}
+	}
+}
+
+// buffer returns the subscriber's buffer, starting its worker on first use.
+func (b *Backpressure) buffer(subscriber string) chan bufferedEvent {
+	b.buffersMu.Lock()
+	defer b.buffersMu.Unlock()
+	buffer, ok := b.buffers[subscriber]
+	if !ok {
+		buffer = make(chan bufferedEvent, b.config.BufferSize)
+		b.buffers[subscriber] = buffer
+		b.wg.Add(1)
+		go b.drain(subscriber, buffer)
+	}
+	return buffer
+}
+
+func (b *Backpressure) push(ctx context.Context, subscriber string, buffer chan bufferedEvent, item bufferedEvent) error {
+	for {
+		select {
+		case buffer <- item:
+			return nil
+		default:
+		}
+
+		switch b.config.Overflow {
+		case OverflowBlock:
+			select {
+			case buffer <- item:
+				return nil
+			case <-ctx.Done():
+				b.recordDropped(subscriber)
+				return ctx.Err()
+			}
+		case OverflowSpill:
+			err := b.config.SpillQueue.Enqueue(Message{
+				Queue:    b.config.SpillQueueName,
+				Type:     string(item.event.Type),
+				Payload:  item.event,
+				Metadata: map[string]interface{}{"subscriber_id": subscriber},
+			})
+			if err != nil {
+				b.recordDropped(subscriber)
+				return fmt.Errorf("failed to spill event: %w", err)
+			}
+			b.metricsMu.Lock()
+			b.metrics.Spilled++
+			b.metricsMu.Unlock()
+			return nil
+		default:
+			select {
+			case <-buffer:
+				b.recordDropped(subscriber)
+			default:
+			}
+		}
+	}
+}
+
+func (b *Backpressure) drain(subscriber string, buffer chan bufferedEvent) {
+	defer b.wg.Done()
+	limiter := newTokenBucket(b.config.Rate, b.config.Burst)
+	for item := range buffer {
+		limiter.wait()
+		if err := item.next(item.ctx, item.event); err != nil {
+			b.logger.Error("buffered subscriber error",
+				"subscriber_id", subscriber,
+				"event_id", item.event.ID,
+				"error", err)
+		}
+		b.metricsMu.Lock()
+		b.metrics.Delivered++
+		b.metricsMu.Unlock()
+	}
+}
+
+func (b *Backpressure) recordDropped(subscriber string) {
+	b.metricsMu.Lock()
+	b.metrics.Dropped++
+	b.metrics.DroppedBySubscriber[subscriber]++
+	b.metricsMu.Unlock()
+	b.logger.Warn("event dropped by backpressure", "subscriber_id", subscriber)
+}
+
+// Metrics returns the current counts.
+func (b *Backpressure) Metrics() BackpressureMetrics {
+	b.metricsMu.Lock()
+	defer b.metricsMu.Unlock()
+	metrics := b.metrics
+	metrics.DroppedBySubscriber = make(map[string]int64, len(b.metrics.DroppedBySubscriber))
+	for id, dropped := range b.metrics.DroppedBySubscriber {
+		metrics.DroppedBySubscriber[id] = dropped
+	}
+	return metrics
+}
+
+// Close stops accepting events and waits for buffered events to be
+// delivered.
+func (b *Backpressure) Close(ctx context.Context) error {
+	b.mu.Lock()
+	if !b.closed {
+		b.closed = true
+		b.buffersMu.Lock()
+		for _, buffer := range b.buffers {
+			close(buffer)
+		}
+		b.buffersMu.Unlock()
+	}
+	b.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		b.wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("backpressure close timeout: %w", ctx.Err())
+	}
+}
+
+// tokenBucket paces deliveries to rate per second with bursts of burst.
+type tokenBucket struct {
+	rate   float64
+	burst  float64
+	tokens float64
+	last   time.Time
+}
+
+func newTokenBucket(rate float64, burst int) *tokenBucket {
+	return &tokenBucket{rate: rate, burst: float64(burst), tokens: float64(burst), last: time.Now()}
+}
+
+// wait blocks until a token is available and takes it.
+func (t *tokenBucket) wait() {
+	if t.rate <= 0 {
+		return
+	}
+	now := time.Now()
+	t.tokens = min(t.burst, t.tokens+now.Sub(t.last).Seconds()*t.rate)
+	t.last = now
+	if t.tokens < 1 {
+		delay := time.Duration((1 - t.tokens) / t.rate * float64(time.Second))
+		time.Sleep(delay)
+		t.tokens = 1
+		t.last = time.Now()
+	}
+	t.tokens--
+}
@@ -0,0 +1,95 @@
+package events
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBackpressureDropOldestKeepsPublishersMoving(t *testing.T) {
+	pressure, err := NewBackpressure(BackpressureConfig{BufferSize: 2}, nil)
+	require.NoError(t, err)
+	bus := NewEventBus(nil)
+	bus.Use(pressure.Middleware())
+
+	release := make(chan struct{})
+	var mu sync.Mutex
+	var received []string
+	require.NoError(t, bus.Subscribe(TopicTask, Subscriber{ID: "slow", Handler: func(ctx context.Context, e Event) error {
+		if e.ID == "e1" {
+			<-release
+		}
+		mu.Lock()
+		received = append(received, e.ID)
+		mu.Unlock()
+		return nil
+	}}))
+
+	ctx := context.Background()
+	publish := func(id string) {
+		event := NewEvent(EventTaskStarted, "test", nil)
+		event.ID = id
+		require.NoError(t, bus.Publish(ctx, TopicTask, event))
+	}
+	publish("e1")
+	require.Eventually(t, func() bool { return len(pressure.buffers["slow"]) == 0 }, time.Second, time.Millisecond)
+	// e1 is being handled; the buffer holds two more and the oldest are
+	// dropped without blocking the publisher.
+	for _, id := range []string{"e2", "e3", "e4", "e5"} {
+		publish(id)
+	}
+	close(release)
+	require.NoError(t, pressure.Close(ctx))
+
+	assert.Equal(t, []string{"e1", "e4", "e5"}, received)
+	metrics := pressure.Metrics()
+	assert.Equal(t, int64(2), metrics.Dropped)
+	assert.Equal(t, int64(2), metrics.DroppedBySubscriber["slow"])
+	assert.Equal(t, int64(3), metrics.Delivered)
+}
+
+func TestBackpressureSpillsToQueue(t *testing.T) {
+	queue := NewMessageQueue(nil, nil)
+	require.NoError(t, queue.CreateQueue("overflow", 10))
+	pressure, err := NewBackpressure(BackpressureConfig{BufferSize: 1, Overflow: OverflowSpill, SpillQueue: queue, SpillQueueName: "overflow"}, nil)
+	require.NoError(t, err)
+
+	release := make(chan struct{})
+	handler := pressure.Middleware()(func(ctx context.Context, e Event) error {
+		<-release
+		return nil
+	})
+	ctx := context.WithValue(context.Background(), subscriberIDKey{}, "slow")
+	for i := 0; i < 4; i++ {
+		require.NoError(t, handler(ctx, NewEvent(EventTaskStarted, "test", nil)))
+	}
+	close(release)
+	require.NoError(t, pressure.Close(context.Background()))
+
+	// One event is handled, one buffered, and the rest spilled, though the
+	// worker may take the first before the second arrives.
+	metrics := pressure.Metrics()
+	assert.Equal(t, int64(4), metrics.Delivered+metrics.Spilled)
+	assert.Equal(t, int(metrics.Spilled), queue.GetQueueSize("overflow"))
+	assert.Zero(t, metrics.Dropped)
+}
+
+func TestBackpressureRateLimit(t *testing.T) {
+	pressure, err := NewBackpressure(BackpressureConfig{Rate: 100, Burst: 1}, nil)
+	require.NoError(t, err)
+	handler := pressure.Middleware()(func(ctx context.Context, e Event) error { return nil })
+
+	start := time.Now()
+	for i := 0; i < 5; i++ {
+		require.NoError(t, handler(context.Background(), NewEvent(EventTaskStarted, "test", nil)))
+	}
+	require.NoError(t, pressure.Close(context.Background()))
+	assert.GreaterOrEqual(t, time.Since(start), 35*time.Millisecond)
+
+	_, err = NewBackpressure(BackpressureConfig{Overflow: OverflowSpill}, nil)
+	assert.ErrorContains(t, err, "requires a spill queue")
+}
@@ -0,0 +1,254 @@
+package events
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// The benchmarks below are run by juleson dev bench and dev check, which
+// compare them with the budgets in the bench section of juleson.yaml.
+// Renaming one orphans its budget.
+
+func benchLogger() *slog.Logger {
+	return slog.New(slog.NewTextHandler(io.Discard, nil))
+}
+
+func BenchmarkBusPublish(b *testing.B) {
+	for _, subscribers := range []int{1, 4, 16} {
+		b.Run(fmt.Sprintf("subscribers=%d", subscribers), func(b *testing.B) {
+			bus := NewEventBus(benchLogger())
+			var delivered atomic.Int64
+			for i := range subscribers {
+				err := bus.Subscribe("bench", Subscriber{
+					ID: fmt.Sprintf("sub-%d", i),
+					Handler: func(context.Context, Event) error {
+						delivered.Add(1)
+						return nil
+					},
+				})
+				if err != nil {
+					b.Fatal(err)
+				}
+			}
+			event := NewEvent(EventSessionCreated, "bench", SessionEventData{SessionID: "s-1"})
+			ctx := context.Background()
+
+			b.ReportAllocs()
+			b.ResetTimer()
+			for b.Loop() {
+				if err := bus.Publish(ctx, "bench", event); err != nil {
+					b.Fatal(err)
+				}
+			}
+			b.StopTimer()
+			if got, want := delivered.Load(), int64(b.N*subscribers); got != want {
+				b.Fatalf("delivered %d events, want %d", got, want)
+			}
+		})
+	}
+}
+
+func BenchmarkBusPublishFiltered(b *testing.B) {
+	bus := NewEventBus(benchLogger())
+	for i, pattern := range []string{"task.*", "agent.*", "session.*"} {
+		err := bus.Subscribe(TopicAll, Subscriber{
+			ID:      fmt.Sprintf("sub-%d", i),
+			Types:   []string{pattern},
+			Handler: func(context.Context, Event) error { return nil },
+		})
+		if err != nil {
+			b.Fatal(err)
+		}
+	}
+	event := NewEvent(EventSessionCreated, "bench", SessionEventData{SessionID: "s-1"})
+	ctx := context.Background()
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for b.Loop() {
+		if err := bus.Publish(ctx, "bench", event); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkBusPublishParallel(b *testing.B) {
+	bus := NewEventBus(benchLogger())
+	err := bus.Subscribe("bench", Subscriber{
+		ID:      "sub",
+		Handler: func(context.Context, Event) error { return nil },
+	})
+	if err != nil {
+		b.Fatal(err)
+	}
+	event := NewEvent(EventSessionCreated, "bench", SessionEventData{SessionID: "s-1"})
+	ctx := context.Background()
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			if err := bus.Publish(ctx, "bench", event); err != nil {
+				b.Error(err)
+				return
+			}
+		}
+	})
+}
+
+func BenchmarkNewEvent(b *testing.B) {
+	data := SessionEventData{SessionID: "s-1"}
+
+	b.ReportAllocs()
+	for b.Loop() {
+		_ = NewEvent(EventActivityReceived, "bench", data).WithTopic(TopicSession)
+	}
+}
+
+func BenchmarkPriorityQueuePushPop(b *testing.B) {
+	queue := NewPriorityQueue(0)
+	item := QueueItem{Message: Message{ID: "m-1", Queue: "bench"}, EnqueueAt: time.Now()}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; b.Loop(); i++ {
+		item.Priority = i % 4
+		if err := queue.Push(item); err != nil {
+			b.Fatal(err)
+		}
+		if _, ok := queue.Pop(); !ok {
+			b.Fatal("queue is empty after a push")
+		}
+	}
+}
+
+// BenchmarkQueueEnqueueDequeue measures the latency from Enqueue to the
+// worker's handler receiving the message.
+func BenchmarkQueueEnqueueDequeue(b *testing.B) {
+	queue := NewMessageQueue(&QueueConfig{MaxRetries: 1}, benchLogger())
+	if err := queue.CreateQueue("bench", 0); err != nil {
+		b.Fatal(err)
+	}
+	received := make(chan struct{})
+	if _, err := queue.RegisterWorker("bench", func(context.Context, Message) error {
+		received <- struct{}{}
+		return nil
+	}); err != nil {
+		b.Fatal(err)
+	}
+	b.Cleanup(func() { _ = queue.Shutdown(context.Background()) })
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for b.Loop() {
+		if err := queue.Enqueue(Message{Queue: "bench", Type: "bench", Payload: "payload"}); err != nil {
+			b.Fatal(err)
+		}
+		<-received
+	}
+}
+
+func BenchmarkStoreAppend(b *testing.B) {
+	event := NewEvent(EventSessionCreated, "bench", SessionEventData{SessionID: "s-1", Title: "Add request logging"})
+	for _, tc := range []struct {
+		name      string
+		maxEvents int
+		journal   bool
+	}{
+		{name: "memory"},
+		{name: "bounded", maxEvents: 1000},
+		{name: "journal", journal: true},
+	} {
+		b.Run(tc.name, func(b *testing.B) {
+			store := benchStore(b, tc.maxEvents, tc.journal)
+
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; b.Loop(); i++ {
+				event.ID = fmt.Sprintf("evt-%d", i)
+				if err := store.Store(event); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+// BenchmarkStoreAppendBatch reports the cost per event of appending
+// batches of 100, comparable with BenchmarkStoreAppend.
+func BenchmarkStoreAppendBatch(b *testing.B) {
+	const batchSize = 100
+	batch := make([]Event, batchSize)
+	for i := range batch {
+		batch[i] = NewEvent(EventSessionCreated, "bench", SessionEventData{SessionID: "s-1", Title: "Add request logging"})
+	}
+	for _, tc := range []struct {
+		name      string
+		maxEvents int
+		journal   bool
+	}{
+		{name: "memory"},
+		{name: "bounded", maxEvents: 1000},
+		{name: "journal", journal: true},
+	} {
+		b.Run(tc.name, func(b *testing.B) {
+			store := benchStore(b, tc.maxEvents, tc.journal)
+
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; b.Loop(); i += batchSize {
+				for j := range batch {
+					batch[j].ID = fmt.Sprintf("evt-%d", i+j)
+				}
+				if err := store.AppendBatch(batch); err != nil {
+					b.Fatal(err)
+				}
+			}
+			b.StopTimer()
+			b.ReportMetric(float64(b.Elapsed().Nanoseconds())/float64(b.N*batchSize), "ns/event")
+		})
+	}
+}
+
+func BenchmarkStoreEvents(b *testing.B) {
+	store := benchStore(b, 0, false)
+	batch := make([]Event, 10000)
+	for i := range batch {
+		batch[i] = NewEvent(EventSessionCreated, "bench", SessionEventData{SessionID: "s-1"})
+	}
+	if err := store.AppendBatch(batch); err != nil {
+		b.Fatal(err)
+	}
+	ctx := context.Background()
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for b.Loop() {
+		for _, err := range store.Events(ctx, 0) {
+			if err != nil {
+				b.Fatal(err)
+			}
+		}
+	}
+}
+
+func benchStore(b *testing.B, maxEvents int, journal bool) *EventStore {
+	b.Helper()
+	dir := b.TempDir()
+	config := &EventStoreConfig{StorageDir: dir, MaxEvents: maxEvents}
+	if journal {
+		config.JournalPath = filepath.Join(dir, "events.jsonl")
+	}
+	store, err := NewEventStore(config, benchLogger())
+	if err != nil {
+		b.Fatal(err)
+	}
+	b.Cleanup(func() { _ = store.Shutdown(context.Background()) })
+	return store
+}
@@ -3,29 +3,63 @@ package events
 import (
 	"context"
 	"fmt"
+	"hash/maphash"
 	"log/slog"
+	"reflect"
+	"slices"
 	"sync"
+	"sync/atomic"
 	"time"
 )
 
+// topicShards is how many locks the bus spreads its topics over, so
+// publishers on different topics do not contend.
+const topicShards = 16
+
 // EventBus provides centralized event distribution for the application.
 // It supports pub/sub pattern with topic-based routing and priority queues.
+//
+// Publishing to synchronous subscribers does not allocate: each topic's
+// subscribers are an immutable slice replaced on every change, subscriber
+// handlers are wrapped in the middleware when they subscribe rather than on
+// every publish, and the state of a publish is pooled.
 type EventBus struct {
-	subscribers map[string][]Subscriber
-	mu          sync.RWMutex
-	logger      *slog.Logger
-	metrics     *BusMetrics
-	middleware  []Middleware
-	stopping    bool
-	stopChan    chan struct{}
-	wg          sync.WaitGroup
+	shards [topicShards]topicShard
+	seed   maphash.Seed
+	// mu serializes subscription and middleware changes.
+	mu         sync.Mutex
+	logger     *slog.Logger
+	metrics    busMetrics
+	middleware []Middleware
+	// ordered holds the pending deliveries of each subscriber and order key
+	// while they are being delivered.
+	ordered   map[string][]func()
+	orderedMu sync.Mutex
+	stopping  atomic.Bool
+	wg        sync.WaitGroup
+}
+
+// topicShard holds the subscriptions of the topics hashed to it, highest
+// priority first.
+type topicShard struct {
+	mu     sync.RWMutex
+	topics map[string][]*subscription
 }
 
 // Subscriber represents an event subscriber
 type Subscriber struct {
-	ID       string
-	Handler  EventHandler
-	Filter   EventFilter
+	ID      string
+	Handler EventHandler
+	Filter  EventFilter
+	// Types limits delivery to events whose type matches one of these
+	// patterns, such as "session.*" or "task.failed".
+	Types []string
+	// Match limits delivery to events about a session or repository, or of
+	// a minimum severity.
+	Match *EventMatch
+	// OrderKey, when set, delivers events with the same non-empty key, such
+	// as SessionKey, to this subscriber one at a time in publish order.
+	OrderKey func(Event) string
 	Priority int // Higher priority subscribers receive events first
 	Async    bool
 }
@@ -39,14 +73,99 @@ type EventFilter func(event Event) bool
 // Middleware provides event processing middleware
 type Middleware func(next EventHandler) EventHandler
 
-// BusMetrics tracks event bus metrics
+// subscriberIDKey carries the ID of the subscriber an event is being
+// delivered to in the handler context.
+type subscriberIDKey struct{}
+
+// SubscriberID returns the ID of the subscriber receiving the event, or an
+// empty string outside event delivery.
+func SubscriberID(ctx context.Context) string {
+	id, _ := ctx.Value(subscriberIDKey{}).(string)
+	return id
+}
+
+// subscription is a subscriber with its handler wrapped in the bus
+// middleware.
+type subscription struct {
+	Subscriber
+	handler EventHandler
+	// last is the context of the latest delivery, reused while publishers
+	// pass the same parent context.
+	last atomic.Pointer[subscriberContext]
+}
+
+// subscriberContext carries the subscriber ID to handlers.
+type subscriberContext struct {
+	context.Context
+	// id is boxed once so Value does not allocate.
+	id any
+}
+
+func (c *subscriberContext) Value(key any) any {
+	if key == (subscriberIDKey{}) {
+		return c.id
+	}
+	return c.Context.Value(key)
+}
+
+// context returns the context to deliver an event published with parent
+// in.
+func (s *subscription) context(parent context.Context) context.Context {
+	if last := s.last.Load(); last != nil && sameContext(last.Context, parent) {
+		return last
+	}
+	ctx := &subscriberContext{Context: parent, id: s.ID}
+	s.last.Store(ctx)
+	return ctx
+}
+
+// sameContext reports whether a and b are the same context, comparing only
+// pointers and the background contexts, whose comparison cannot panic.
+func sameContext(a, b context.Context) bool {
+	switch {
+	case a == nil || b == nil:
+		return false
+	case reflect.TypeOf(a).Kind() == reflect.Pointer:
+		return a == b
+	default:
+		background := context.Background()
+		return a == background && b == background
+	}
+}
+
+// BusMetrics is a snapshot of event bus metrics
 type BusMetrics struct {
 	EventsPublished int64
 	EventsDelivered int64
 	EventsFailed    int64
+	// EventsRejected counts publishes refused because the bus is stopping.
+	EventsRejected  int64
 	SubscriberCount int
 	AverageLatency  time.Duration
-	mu              sync.RWMutex
+}
+
+// busMetrics tracks event bus metrics without locking.
+type busMetrics struct {
+	published   atomic.Int64
+	delivered   atomic.Int64
+	failed      atomic.Int64
+	rejected    atomic.Int64
+	subscribers atomic.Int64
+	// latency is the running average publish latency in nanoseconds.
+	latency atomic.Int64
+}
+
+func (m *busMetrics) observeLatency(latency time.Duration) {
+	for {
+		previous := m.latency.Load()
+		average := int64(latency)
+		if previous != 0 {
+			average = (previous + average) / 2
+		}
+		if m.latency.CompareAndSwap(previous, average) {
+			return
+		}
+	}
 }
 
 // NewEventBus creates a new event bus
@@ -55,16 +174,42 @@ func NewEventBus(logger *slog.Logger) *EventBus {
 		logger = slog.Default()
 	}
 
-	return &EventBus{
-		subscribers: make(map[string][]Subscriber),
-		logger:      logger,
-		metrics:     &BusMetrics{},
-		middleware:  make([]Middleware, 0),
-		stopChan:    make(chan struct{}),
+	eb := &EventBus{
+		seed:       maphash.MakeSeed(),
+		logger:     logger,
+		middleware: make([]Middleware, 0),
+		ordered:    make(map[string][]func()),
+	}
+	for i := range eb.shards {
+		eb.shards[i].topics = make(map[string][]*subscription)
+	}
+	return eb
+}
+
+func (eb *EventBus) shard(topic string) *topicShard {
+	return &eb.shards[maphash.String(eb.seed, topic)%topicShards]
+}
+
+// subscriptions returns the topic's subscriptions. The slice is never
+// modified, so it may be read without holding the lock.
+func (s *topicShard) subscriptions(topic string) []*subscription {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.topics[topic]
+}
+
+// wrap returns the subscription for subscriber with the current
+// middleware. eb.mu must be held.
+func (eb *EventBus) wrap(subscriber Subscriber) *subscription {
+	handler := subscriber.Handler
+	for i := len(eb.middleware) - 1; i >= 0; i-- {
+		handler = eb.middleware[i](handler)
 	}
+	return &subscription{Subscriber: subscriber, handler: handler}
 }
 
-// Subscribe subscribes to events on a topic
+// Subscribe subscribes to events on a topic. Subscribing to TopicAll with
+// Types set receives matching events from every topic.
 func (eb *EventBus) Subscribe(topic string, subscriber Subscriber) error {
 	if topic == "" {
 		return fmt.Errorf("topic cannot be empty")
@@ -75,36 +220,36 @@ func (eb *EventBus) Subscribe(topic string, subscriber Subscriber) error {
 	if subscriber.Handler == nil {
 		return fmt.Errorf("subscriber handler cannot be nil")
 	}
+	if err := validateTypePatterns(subscriber.Types); err != nil {
+		return err
+	}
 
 	eb.mu.Lock()
 	defer eb.mu.Unlock()
 
-	if eb.stopping {
+	if eb.stopping.Load() {
 		return fmt.Errorf("event bus is stopping")
 	}
 
+	shard := eb.shard(topic)
+	shard.mu.Lock()
+	subs := shard.topics[topic]
 	// Check for duplicate subscriber ID
-	for _, sub := range eb.subscribers[topic] {
+	for _, sub := range subs {
 		if sub.ID == subscriber.ID {
+			shard.mu.Unlock()
 			return fmt.Errorf("subscriber with ID %s already exists for topic %s", subscriber.ID, topic)
 		}
 	}
 
-	eb.subscribers[topic] = append(eb.subscribers[topic], subscriber)
-
-	// Sort by priority (higher first)
-	subs := eb.subscribers[topic]
-	for i := 0; i < len(subs); i++ {
-		for j := i + 1; j < len(subs); j++ {
-			if subs[j].Priority > subs[i].Priority {
-				subs[i], subs[j] = subs[j], subs[i]
-			}
-		}
-	}
+	// Sort by priority (higher first), keeping subscription order among
+	// equal priorities.
+	subs = append(slices.Clip(subs), eb.wrap(subscriber))
+	slices.SortStableFunc(subs, func(a, b *subscription) int { return b.Priority - a.Priority })
+	shard.topics[topic] = subs
+	shard.mu.Unlock()
 
-	eb.metrics.mu.Lock()
-	eb.metrics.SubscriberCount++
-	eb.metrics.mu.Unlock()
+	eb.metrics.subscribers.Add(1)
 
 	eb.logger.Info("subscriber added",
 		"topic", topic,
@@ -120,53 +265,66 @@ func (eb *EventBus) Unsubscribe(topic, subscriberID string) error {
 	eb.mu.Lock()
 	defer eb.mu.Unlock()
 
-	subs, exists := eb.subscribers[topic]
+	shard := eb.shard(topic)
+	shard.mu.Lock()
+	defer shard.mu.Unlock()
+
+	subs, exists := shard.topics[topic]
 	if !exists {
 		return fmt.Errorf("topic %s not found", topic)
 	}
 
-	for i, sub := range subs {
-		if sub.ID == subscriberID {
-			eb.subscribers[topic] = append(subs[:i], subs[i+1:]...)
-
-			eb.metrics.mu.Lock()
-			eb.metrics.SubscriberCount--
-			eb.metrics.mu.Unlock()
-
-			eb.logger.Info("subscriber removed",
-				"topic", topic,
-				"subscriber_id", subscriberID)
-			return nil
-		}
+	i := slices.IndexFunc(subs, func(sub *subscription) bool { return sub.ID == subscriberID })
+	if i < 0 {
+		return fmt.Errorf("subscriber %s not found for topic %s", subscriberID, topic)
 	}
+	shard.topics[topic] = slices.Delete(slices.Clone(subs), i, i+1)
+	eb.metrics.subscribers.Add(-1)
 
-	return fmt.Errorf("subscriber %s not found for topic %s", subscriberID, topic)
+	eb.logger.Info("subscriber removed",
+		"topic", topic,
+		"subscriber_id", subscriberID)
+	return nil
+}
+
+// publication is the state of one Publish call. Publish waits for every
+// delivery it starts, so publications are pooled.
+type publication struct {
+	bus    *EventBus
+	ctx    context.Context
+	event  Event
+	wg     sync.WaitGroup
+	mu     sync.Mutex
+	failed int
+	first  error
 }
 
+var publications = sync.Pool{New: func() any { return new(publication) }}
+
 // Publish publishes an event to all subscribers of a topic
 func (eb *EventBus) Publish(ctx context.Context, topic string, event Event) error {
 	if topic == "" {
 		return fmt.Errorf("topic cannot be empty")
 	}
 
-	eb.mu.RLock()
-	if eb.stopping {
-		eb.mu.RUnlock()
+	if eb.stopping.Load() {
+		eb.metrics.rejected.Add(1)
 		return fmt.Errorf("event bus is stopping")
 	}
 
-	subs, exists := eb.subscribers[topic]
-	if !exists || len(subs) == 0 {
-		eb.mu.RUnlock()
-		eb.logger.Debug("no subscribers for topic", "topic", topic)
+	// The topic's subscribers, then TopicAll's.
+	subs := eb.shard(topic).subscriptions(topic)
+	var all []*subscription
+	if topic != TopicAll {
+		all = eb.shard(TopicAll).subscriptions(TopicAll)
+	}
+	if len(subs)+len(all) == 0 {
+		if eb.logger.Enabled(ctx, slog.LevelDebug) {
+			eb.logger.Debug("no subscribers for topic", "topic", topic)
+		}
 		return nil
 	}
 
-	// Make a copy to avoid holding lock during delivery
-	subsCopy := make([]Subscriber, len(subs))
-	copy(subsCopy, subs)
-	eb.mu.RUnlock()
-
 	// Set topic if not already set
 	if event.Topic == "" {
 		event.Topic = topic
@@ -177,125 +335,162 @@ func (eb *EventBus) Publish(ctx context.Context, topic string, event Event) erro
 		event.Timestamp = time.Now()
 	}
 
-	eb.metrics.mu.Lock()
-	eb.metrics.EventsPublished++
-	eb.metrics.mu.Unlock()
+	eb.metrics.published.Add(1)
 
 	startTime := time.Now()
 
-	// Deliver to all matching subscribers
-	var wg sync.WaitGroup
-	errors := make([]error, 0)
-	errorsMu := sync.Mutex{}
+	p := publications.Get().(*publication)
+	p.bus, p.ctx, p.event = eb, ctx, event
+	p.deliver(subs)
+	p.deliver(all)
+	p.wg.Wait()
+	failed, first := p.failed, p.first
+	p.bus, p.ctx, p.event, p.failed, p.first = nil, nil, Event{}, 0, nil
+	publications.Put(p)
+
+	latency := time.Since(startTime)
+	eb.metrics.observeLatency(latency)
+
+	if failed > 0 {
+		return fmt.Errorf("event delivery had %d errors: %v", failed, first)
+	}
+
+	if eb.logger.Enabled(ctx, slog.LevelDebug) {
+		eb.logger.Debug("event published",
+			"topic", topic,
+			"event_type", event.Type,
+			"subscribers", len(subs)+len(all),
+			"latency", latency)
+	}
+
+	return nil
+}
 
-	for _, sub := range subsCopy {
-		// Apply filter if present
-		if sub.Filter != nil && !sub.Filter(event) {
+// deliver delivers the event to the subscriptions that accept it:
+// synchronous ones in turn, ordered and asynchronous ones concurrently.
+func (p *publication) deliver(subs []*subscription) {
+	for _, sub := range subs {
+		// Apply type patterns, match, and filter
+		if !sub.accepts(p.event) {
 			continue
 		}
 
-		// Build handler chain with middleware
-		handler := sub.Handler
-		for i := len(eb.middleware) - 1; i >= 0; i-- {
-			handler = eb.middleware[i](handler)
+		key := ""
+		if sub.OrderKey != nil {
+			key = sub.OrderKey(p.event)
 		}
-
-		if sub.Async {
-			wg.Add(1)
-			eb.wg.Add(1)
-			go func(s Subscriber, h EventHandler) {
-				defer wg.Done()
-				defer eb.wg.Done()
-
-				if err := h(ctx, event); err != nil {
-					errorsMu.Lock()
-					errors = append(errors, fmt.Errorf("subscriber %s failed: %w", s.ID, err))
-					errorsMu.Unlock()
-
-					eb.metrics.mu.Lock()
-					eb.metrics.EventsFailed++
-					eb.metrics.mu.Unlock()
-
-					eb.logger.Error("async subscriber error",
-						"subscriber_id", s.ID,
-						"topic", topic,
-						"error", err)
-				} else {
-					eb.metrics.mu.Lock()
-					eb.metrics.EventsDelivered++
-					eb.metrics.mu.Unlock()
-				}
-			}(sub, handler)
-		} else {
-			if err := handler(ctx, event); err != nil {
-				errors = append(errors, fmt.Errorf("subscriber %s failed: %w", sub.ID, err))
-
-				eb.metrics.mu.Lock()
-				eb.metrics.EventsFailed++
-				eb.metrics.mu.Unlock()
-
-				eb.logger.Error("subscriber error",
-					"subscriber_id", sub.ID,
-					"topic", topic,
-					"error", err)
-			} else {
-				eb.metrics.mu.Lock()
-				eb.metrics.EventsDelivered++
-				eb.metrics.mu.Unlock()
-			}
+		switch {
+		case key != "":
+			p.wg.Add(1)
+			p.bus.deliverOrdered(sub.ID+"\x00"+key, func() {
+				defer p.wg.Done()
+				p.handle(sub)
+			})
+		case sub.Async:
+			p.wg.Add(1)
+			p.bus.wg.Add(1)
+			go func() {
+				defer p.wg.Done()
+				defer p.bus.wg.Done()
+				p.handle(sub)
+			}()
+		default:
+			p.handle(sub)
 		}
 	}
+}
 
-	wg.Wait()
-
-	// Update average latency
-	latency := time.Since(startTime)
-	eb.metrics.mu.Lock()
-	if eb.metrics.AverageLatency == 0 {
-		eb.metrics.AverageLatency = latency
-	} else {
-		eb.metrics.AverageLatency = (eb.metrics.AverageLatency + latency) / 2
+func (p *publication) handle(sub *subscription) {
+	eb := p.bus
+	err := sub.handler(sub.context(p.ctx), p.event)
+	if err == nil {
+		eb.metrics.delivered.Add(1)
+		return
 	}
-	eb.metrics.mu.Unlock()
 
-	if len(errors) > 0 {
-		return fmt.Errorf("event delivery had %d errors: %v", len(errors), errors[0])
+	p.mu.Lock()
+	if p.failed == 0 {
+		p.first = fmt.Errorf("subscriber %s failed: %w", sub.ID, err)
 	}
+	p.failed++
+	p.mu.Unlock()
 
-	eb.logger.Debug("event published",
-		"topic", topic,
-		"event_type", event.Type,
-		"subscribers", len(subsCopy),
-		"latency", latency)
+	eb.metrics.failed.Add(1)
 
-	return nil
+	eb.logger.Error("subscriber error",
+		"subscriber_id", sub.ID,
+		"topic", p.event.Topic,
+		"async", sub.Async,
+		"error", err)
 }
 
-// Use adds middleware to the event bus
+// deliverOrdered runs deliveries sharing a key one at a time, in the order
+// they were queued. Deliveries with different keys run concurrently.
+func (eb *EventBus) deliverOrdered(key string, delivery func()) {
+	eb.orderedMu.Lock()
+	queue, running := eb.ordered[key]
+	eb.ordered[key] = append(queue, delivery)
+	eb.orderedMu.Unlock()
+	if running {
+		return
+	}
+
+	eb.wg.Add(1)
+	go func() {
+		defer eb.wg.Done()
+		for {
+			eb.orderedMu.Lock()
+			queue := eb.ordered[key]
+			if len(queue) == 0 {
+				delete(eb.ordered, key)
+				eb.orderedMu.Unlock()
+				return
+			}
+			next := queue[0]
+			eb.ordered[key] = queue[1:]
+			eb.orderedMu.Unlock()
+			next()
+		}
+	}()
+}
+
+// Use adds middleware to the event bus, wrapping the handlers of existing
+// and future subscribers.
 func (eb *EventBus) Use(middleware Middleware) {
 	eb.mu.Lock()
 	defer eb.mu.Unlock()
 	eb.middleware = append(eb.middleware, middleware)
+
+	for i := range eb.shards {
+		shard := &eb.shards[i]
+		shard.mu.Lock()
+		for topic, subs := range shard.topics {
+			wrapped := make([]*subscription, len(subs))
+			for j, sub := range subs {
+				wrapped[j] = eb.wrap(sub.Subscriber)
+			}
+			shard.topics[topic] = wrapped
+		}
+		shard.mu.Unlock()
+	}
 }
 
 // GetMetrics returns current bus metrics
 func (eb *EventBus) GetMetrics() BusMetrics {
-	eb.metrics.mu.RLock()
-	defer eb.metrics.mu.RUnlock()
-
 	return BusMetrics{
-		EventsPublished: eb.metrics.EventsPublished,
-		EventsDelivered: eb.metrics.EventsDelivered,
-		EventsFailed:    eb.metrics.EventsFailed,
-		SubscriberCount: eb.metrics.SubscriberCount,
-		AverageLatency:  eb.metrics.AverageLatency,
+		EventsPublished: eb.metrics.published.Load(),
+		EventsDelivered: eb.metrics.delivered.Load(),
+		EventsFailed:    eb.metrics.failed.Load(),
+		EventsRejected:  eb.metrics.rejected.Load(),
+		SubscriberCount: int(eb.metrics.subscribers.Load()),
+		AverageLatency:  time.Duration(eb.metrics.latency.Load()),
 	}
 }
 
 // Shutdown gracefully shuts down the event bus
 func (eb *EventBus) Shutdown(ctx context.Context) error {
 	eb.mu.Lock()
-	eb.stopping = true
+	eb.stopping.Store(true)
 	eb.mu.Unlock()
 
 	// Wait for all async handlers to complete
@@ -319,36 +514,35 @@ func (eb *EventBus) Clear() {
 	eb.mu.Lock()
 	defer eb.mu.Unlock()
 
-	eb.subscribers = make(map[string][]Subscriber)
-	eb.metrics.mu.Lock()
-	eb.metrics.SubscriberCount = 0
-	eb.metrics.mu.Unlock()
+	for i := range eb.shards {
+		shard := &eb.shards[i]
+		shard.mu.Lock()
+		shard.topics = make(map[string][]*subscription)
+		shard.mu.Unlock()
+	}
+	eb.metrics.subscribers.Store(0)
 
 	eb.logger.Info("event bus cleared")
 }
 
 // GetTopics returns all topics with subscribers
 func (eb *EventBus) GetTopics() []string {
-	eb.mu.RLock()
-	defer eb.mu.RUnlock()
-
-	topics := make([]string, 0, len(eb.subscribers))
-	for topic := range eb.subscribers {
-		topics = append(topics, topic)
+	topics := make([]string, 0)
+	for i := range eb.shards {
+		shard := &eb.shards[i]
+		shard.mu.RLock()
+		for topic := range shard.topics {
+			topics = append(topics, topic)
+		}
+		shard.mu.RUnlock()
 	}
+	slices.Sort(topics)
 	return topics
 }
 
 // GetSubscribers returns all subscribers for a topic
 func (eb *EventBus) GetSubscribers(topic string) []string {
-	eb.mu.RLock()
-	defer eb.mu.RUnlock()
-
-	subs, exists := eb.subscribers[topic]
-	if !exists {
-		return []string{}
-	}
-
+	subs := eb.shard(topic).subscriptions(topic)
 	ids := make([]string, len(subs))
 	for i, sub := range subs {
 		ids[i] = sub.ID
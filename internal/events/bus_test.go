@@ -2,8 +2,10 @@ package events
 
 import (
 	"context"
+	"fmt"
 	"sync"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -97,3 +99,71 @@ func TestEventBusFilter(t *testing.T) {
 	assert.Equal(t, 1, count)
 	mu.Unlock()
 }
+
+func TestEventBusDeliversToTopicAll(t *testing.T) {
+	bus := NewEventBus(nil)
+	bus.Use(DeduplicationMiddleware(time.Minute))
+
+	var received []string
+	var mu sync.Mutex
+	record := func(id string) Subscriber {
+		return Subscriber{ID: id, Handler: func(ctx context.Context, e Event) error {
+			mu.Lock()
+			received = append(received, SubscriberID(ctx)+":"+e.Topic)
+			mu.Unlock()
+			return nil
+		}}
+	}
+	require.NoError(t, bus.Subscribe(TopicOrchestration, record("workflow")))
+	require.NoError(t, bus.Subscribe(TopicAll, record("all")))
+
+	event := NewEvent(EventWorkflowStarted, "test", nil).WithTopic(TopicOrchestration)
+	require.NoError(t, bus.Publish(context.Background(), TopicOrchestration, event))
+	require.NoError(t, bus.Publish(context.Background(), TopicOrchestration, event))
+	require.NoError(t, bus.Publish(context.Background(), TopicTask, NewEvent(EventTaskStarted, "test", nil)))
+
+	assert.Equal(t, []string{"workflow:orchestration", "all:orchestration", "all:task"}, received)
+}
+
+func TestEventBusPublishDoesNotAllocate(t *testing.T) {
+	if raceEnabled {
+		t.Skip("allocations are not measurable under the race detector")
+	}
+	bus := NewEventBus(nil)
+	for i, types := range [][]string{nil, {"session.*"}, {"task.*"}} {
+		require.NoError(t, bus.Subscribe(TopicAll, Subscriber{
+			ID:      fmt.Sprintf("sub-%d", i),
+			Types:   types,
+			Handler: func(context.Context, Event) error { return nil },
+		}))
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	event := NewEvent(EventSessionCreated, "test", SessionEventData{SessionID: "s-1"}).WithTopic(TopicSession)
+
+	allocs := testing.AllocsPerRun(100, func() {
+		if err := bus.Publish(ctx, TopicSession, event); err != nil {
+			t.Fatal(err)
+		}
+	})
+	assert.Zero(t, allocs)
+	assert.Equal(t, int64(202), bus.GetMetrics().EventsDelivered)
+}
+
+func TestEventBusUseWrapsExistingSubscribers(t *testing.T) {
+	bus := NewEventBus(nil)
+	var calls []string
+	require.NoError(t, bus.Subscribe("test.topic", Subscriber{ID: "sub-1", Handler: func(ctx context.Context, e Event) error {
+		calls = append(calls, "handler:"+SubscriberID(ctx))
+		return nil
+	}}))
+	bus.Use(func(next EventHandler) EventHandler {
+		return func(ctx context.Context, e Event) error {
+			calls = append(calls, "middleware")
+			return next(ctx, e)
+		}
+	})
+
+	require.NoError(t, bus.Publish(context.Background(), "test.topic", Event{Type: "TEST"}))
+	assert.Equal(t, []string{"middleware", "handler:sub-1"}, calls)
+}
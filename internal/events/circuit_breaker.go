@@ -200,6 +200,26 @@ func (cb *CircuitBreaker) Reset() {
 	cb.logger.Info("circuit breaker manually reset", "name", cb.name)
 }
 
+// Configure updates failure threshold and timeouts without resetting state.
+// Zero values keep the current setting
+func (cb *CircuitBreaker) Configure(maxFailures int, timeout, resetTimeout time.Duration) {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	if maxFailures > 0 {
+		cb.maxFailures = maxFailures
+	}
+	if timeout > 0 {
+		cb.timeout = timeout
+	}
+	if resetTimeout > 0 {
+		cb.resetTimeout = resetTimeout
+	}
+	if cb.state == StateClosed && cb.failures >= cb.maxFailures {
+		cb.setState(StateOpen)
+	}
+}
+
 // CircuitBreakerMiddleware creates middleware from a circuit breaker
 func CircuitBreakerMiddleware(cb *CircuitBreaker) Middleware {
 	return func(next EventHandler) EventHandler {
@@ -214,6 +234,7 @@ func CircuitBreakerMiddleware(cb *CircuitBreaker) Middleware {
 // CircuitBreakerPool manages multiple circuit breakers
 type CircuitBreakerPool struct {
 	breakers map[string]*CircuitBreaker
+	defaults *CircuitBreakerConfig
 	mu       sync.RWMutex
 	logger   *slog.Logger
 }
@@ -251,6 +272,11 @@ func (cbp *CircuitBreakerPool) GetOrCreate(name string, config *CircuitBreakerCo
 
 	if config == nil {
 		config = DefaultCircuitBreakerConfig(name)
+		if cbp.defaults != nil {
+			config.MaxFailures = cbp.defaults.MaxFailures
+			config.Timeout = cbp.defaults.Timeout
+			config.ResetTimeout = cbp.defaults.ResetTimeout
+		}
 	}
 
 	cb = NewCircuitBreaker(config, cbp.logger)
@@ -281,6 +307,37 @@ func (cbp *CircuitBreakerPool) GetAll() map[string]*CircuitBreaker {
 	return result
 }
 
+// Configure applies new settings to every breaker in the pool and uses them
+// as defaults for breakers created later
+func (cbp *CircuitBreakerPool) Configure(maxFailures int, timeout, resetTimeout time.Duration) {
+	cbp.mu.Lock()
+	defaults := DefaultCircuitBreakerConfig("")
+	if maxFailures > 0 {
+		defaults.MaxFailures = maxFailures
+	}
+	if timeout > 0 {
+		defaults.Timeout = timeout
+	}
+	if resetTimeout > 0 {
+		defaults.ResetTimeout = resetTimeout
+	}
+	cbp.defaults = defaults
+	breakers := make([]*CircuitBreaker, 0, len(cbp.breakers))
+	for _, cb := range cbp.breakers {
+		breakers = append(breakers, cb)
+	}
+	cbp.mu.Unlock()
+
+	for _, cb := range breakers {
+		cb.Configure(maxFailures, timeout, resetTimeout)
+	}
+
+	cbp.logger.Info("circuit breakers reconfigured",
+		"max_failures", defaults.MaxFailures,
+		"timeout", defaults.Timeout,
+		"reset_timeout", defaults.ResetTimeout)
+}
+
 // ResetAll resets all circuit breakers
 func (cbp *CircuitBreakerPool) ResetAll() {
 	cbp.mu.RLock()
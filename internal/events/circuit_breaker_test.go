@@ -122,3 +122,24 @@ func TestCircuitBreakerPool_GetOrCreate(t *testing.T) {
 	pool.ResetAll()
 	assert.Equal(t, StateClosed, cb1.GetState())
 }
+
+func TestCircuitBreakerPool_Configure(t *testing.T) {
+	pool := NewCircuitBreakerPool(nil)
+	existing := pool.GetOrCreate("existing", nil)
+
+	ctx := context.Background()
+	failFunc := func(ctx context.Context) error { return errors.New("fail") }
+	_ = existing.Execute(ctx, failFunc)
+	_ = existing.Execute(ctx, failFunc)
+	assert.Equal(t, StateClosed, existing.GetState())
+
+	// Lowering the threshold below the recorded failures opens the circuit
+	pool.Configure(2, 0, 0)
+	assert.Equal(t, StateOpen, existing.GetState())
+
+	created := pool.GetOrCreate("created", nil)
+	_ = created.Execute(ctx, failFunc)
+	assert.Equal(t, StateClosed, created.GetState())
+	_ = created.Execute(ctx, failFunc)
+	assert.Equal(t, StateOpen, created.GetState())
+}
@@ -0,0 +1,123 @@
+package events
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"time"
+
+	"github.com/SamyRai/juleson/internal/secrets"
+)
+
+// CompactStats reports the events CompactJournal removed, or would remove.
+type CompactStats struct {
+	Kept           int
+	Removed        int
+	KeptBytes      int64
+	ReclaimedBytes int64
+}
+
+// CompactJournal removes the events of the journal at path recorded before
+// cutoff, then the oldest until the journal is at most maxBytes. A zero
+// cutoff or maxBytes disables that limit. With dryRun the journal is left
+// as is. Kept lines are copied byte for byte, so encrypted lines stay
+// encrypted; cipher only reads their timestamps. Lines that cannot be read
+// are kept. The journal lock is held from the read to the rename, so
+// appends by event stores wait rather than get lost. Event stores that
+// loaded the journal keep the removed events in memory until reopened.
+func CompactJournal(path string, cipher secrets.Sealer, cutoff time.Time, maxBytes int64, dryRun bool) (CompactStats, error) {
+	var stats CompactStats
+	if _, err := os.Stat(path); errors.Is(err, fs.ErrNotExist) {
+		return stats, nil
+	}
+	unlock, err := lockJournal(path)
+	if err != nil {
+		return stats, err
+	}
+	defer unlock()
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return stats, nil
+	}
+	if err != nil {
+		return stats, fmt.Errorf("failed to read event journal: %w", err)
+	}
+
+	var lines [][]byte
+	for rest := data; len(rest) > 0; {
+		end := bytes.IndexByte(rest, '\n') + 1
+		if end == 0 {
+			end = len(rest)
+		}
+		line := rest[:end]
+		rest = rest[end:]
+		if len(bytes.TrimSpace(line)) == 0 {
+			continue
+		}
+		if !cutoff.IsZero() {
+			if recorded, ok := journalTimestamp(cipher, line); ok && recorded.Before(cutoff) {
+				stats.Removed++
+				stats.ReclaimedBytes += int64(len(line))
+				continue
+			}
+		}
+		lines = append(lines, line)
+		stats.KeptBytes += int64(len(line))
+	}
+	for maxBytes > 0 && stats.KeptBytes > maxBytes && len(lines) > 0 {
+		stats.Removed++
+		stats.ReclaimedBytes += int64(len(lines[0]))
+		stats.KeptBytes -= int64(len(lines[0]))
+		lines = lines[1:]
+	}
+	stats.Kept = len(lines)
+	if dryRun || stats.Removed == 0 {
+		return stats, nil
+	}
+
+	return stats, rewriteJournal(path, lines)
+}
+
+// journalTimestamp returns the timestamp of the event on a journal line.
+func journalTimestamp(cipher secrets.Sealer, line []byte) (time.Time, bool) {
+	data, err := openRecord(cipher, bytes.TrimSpace(line))
+	if err != nil {
+		return time.Time{}, false
+	}
+	var event struct {
+		Timestamp time.Time `json:"timestamp"`
+	}
+	if err := json.Unmarshal(data, &event); err != nil || event.Timestamp.IsZero() {
+		return time.Time{}, false
+	}
+	return event.Timestamp, true
+}
+
+// rewriteJournal replaces the journal at path with lines through a
+// temporary file renamed into place. The journal lock must be held.
+func rewriteJournal(path string, lines [][]byte) error {
+	tmp := path + ".compact"
+	file, err := os.OpenFile(tmp, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0o600)
+	if err != nil {
+		return fmt.Errorf("failed to compact event journal: %w", err)
+	}
+	for _, line := range lines {
+		if _, err = file.Write(line); err != nil {
+			break
+		}
+	}
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(tmp, path)
+	}
+	if err != nil {
+		_ = os.Remove(tmp)
+		return fmt.Errorf("failed to compact event journal: %w", err)
+	}
+	return nil
+}
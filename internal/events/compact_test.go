@@ -0,0 +1,99 @@
+package events
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/SamyRai/juleson/internal/secrets"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCompactJournal(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.jsonl")
+	cipher, err := secrets.NewCipher(make([]byte, 32))
+	require.NoError(t, err)
+	plain, err := OpenAuditLog(path, nil, nil)
+	require.NoError(t, err)
+	sealed, err := OpenAuditLog(path, cipher, nil)
+	require.NoError(t, err)
+
+	now := time.Now()
+	for i, store := range []*EventStore{plain, sealed, plain, sealed} {
+		event := NewAuditEvent("cli", AuditData{Action: "session.create", Target: "sessions/" + string(rune('a'+i))})
+		event.Timestamp = now.Add(time.Duration(i-3) * 24 * time.Hour)
+		require.NoError(t, store.Store(event))
+	}
+	before, err := os.ReadFile(path)
+	require.NoError(t, err)
+
+	stats, err := CompactJournal(path, cipher, now.Add(-36*time.Hour), 0, true)
+	require.NoError(t, err)
+	assert.Equal(t, 2, stats.Removed)
+	assert.Equal(t, 2, stats.Kept)
+	after, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, before, after, "a dry run leaves the journal as is")
+
+	stats, err = CompactJournal(path, cipher, now.Add(-36*time.Hour), 0, false)
+	require.NoError(t, err)
+	assert.Equal(t, int64(len(before)), stats.KeptBytes+stats.ReclaimedBytes)
+
+	reopened, err := OpenAuditLog(path, cipher, nil)
+	require.NoError(t, err)
+	entries := AuditEntries(reopened, time.Time{})
+	require.Len(t, entries, 2)
+	data, err := DecodeAuditData(entries[0].Event)
+	require.NoError(t, err)
+	assert.Equal(t, "sessions/c", data.Target)
+
+	stats, err = CompactJournal(path, cipher, time.Time{}, stats.KeptBytes-1, false)
+	require.NoError(t, err)
+	assert.Equal(t, 1, stats.Kept, "the oldest events go first to fit the size")
+
+	stats, err = CompactJournal(filepath.Join(t.TempDir(), "missing.jsonl"), nil, now, 0, false)
+	require.NoError(t, err)
+	assert.Zero(t, stats)
+}
+
+func TestCompactJournalKeepsConcurrentAppends(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.jsonl")
+	store, err := OpenAuditLog(path, nil, nil)
+	require.NoError(t, err)
+
+	now := time.Now()
+	const appends = 500
+	done := make(chan struct{})
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for {
+			select {
+			case <-done:
+				return
+			default:
+			}
+			_, err := CompactJournal(path, nil, now.Add(-24*time.Hour), 0, false)
+			assert.NoError(t, err)
+		}
+	}()
+	for i := 0; i < appends; i++ {
+		expired := NewAuditEvent("cli", AuditData{Action: "session.create"})
+		expired.Timestamp = now.Add(-48 * time.Hour)
+		require.NoError(t, store.Store(expired))
+		require.NoError(t, store.Store(NewAuditEvent("cli", AuditData{Action: "session.create"})))
+	}
+	close(done)
+	wg.Wait()
+
+	_, err = CompactJournal(path, nil, now.Add(-24*time.Hour), 0, false)
+	require.NoError(t, err)
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, appends, bytes.Count(data, []byte("\n")), "every recent event survives compaction")
+}
@@ -15,6 +15,7 @@ type EventCoordinator struct {
 	queue    *MessageQueue
 	store    *EventStore
 	breakers *CircuitBreakerPool
+	pressure *Backpressure
 	logger   *slog.Logger
 	mu       sync.RWMutex
 	started  bool
@@ -26,7 +27,11 @@ type CoordinatorConfig struct {
 	QueueConfig      *QueueConfig
 	EnableStore      bool
 	EnableQueue      bool
-	Logger           *slog.Logger
+	// Backpressure, when set, buffers and rate limits delivery to each
+	// subscriber. With the spill policy and no SpillQueue, overflowing
+	// events go to SpillQueueName on the coordinator's message queue.
+	Backpressure *BackpressureConfig
+	Logger       *slog.Logger
 }
 
 // DefaultCoordinatorConfig returns default configuration
@@ -69,6 +74,25 @@ func NewEventCoordinator(config *CoordinatorConfig) (*EventCoordinator, error) {
 		ec.queue = NewMessageQueue(config.QueueConfig, config.Logger)
 	}
 
+	if config.Backpressure != nil {
+		pressureConfig := *config.Backpressure
+		if pressureConfig.Overflow == OverflowSpill && pressureConfig.SpillQueue == nil && ec.queue != nil && pressureConfig.SpillQueueName != "" {
+			size := DefaultQueueConfig().MaxQueueSize
+			if config.QueueConfig != nil {
+				size = config.QueueConfig.MaxQueueSize
+			}
+			if err := ec.queue.CreateQueue(pressureConfig.SpillQueueName, size); err != nil {
+				return nil, fmt.Errorf("failed to create spill queue: %w", err)
+			}
+			pressureConfig.SpillQueue = ec.queue
+		}
+		pressure, err := NewBackpressure(pressureConfig, config.Logger)
+		if err != nil {
+			return nil, fmt.Errorf("failed to configure backpressure: %w", err)
+		}
+		ec.pressure = pressure
+	}
+
 	// Set up standard middleware
 	ec.setupMiddleware()
 
@@ -86,6 +110,11 @@ func (ec *EventCoordinator) setupMiddleware() {
 	// Add deduplication middleware (5 minute window)
 	ec.bus.Use(DeduplicationMiddleware(5 * time.Minute))
 
+	// Buffer and rate limit each subscriber (innermost)
+	if ec.pressure != nil {
+		ec.bus.Use(ec.pressure.Middleware())
+	}
+
 	// Subscribe to all events for storage
 	if ec.store != nil {
 		ec.bus.Subscribe(TopicAll, Subscriber{
@@ -116,6 +145,13 @@ func (ec *EventCoordinator) Start(ctx context.Context) error {
 	return nil
 }
 
+// Running reports whether the coordinator has started and not shut down
+func (ec *EventCoordinator) Running() bool {
+	ec.mu.RLock()
+	defer ec.mu.RUnlock()
+	return ec.started
+}
+
 // PublishEvent publishes an event to the event bus
 func (ec *EventCoordinator) PublishEvent(ctx context.Context, event Event) error {
 	return ec.bus.Publish(ctx, event.Topic, event)
@@ -147,6 +183,31 @@ func (ec *EventCoordinator) CreateQueue(name string, maxSize int) error {
 	return ec.queue.CreateQueue(name, maxSize)
 }
 
+// CreateQueueWithOptions creates a message queue with a weight and worker
+// scaling
+func (ec *EventCoordinator) CreateQueueWithOptions(name string, options QueueOptions) error {
+	if ec.queue == nil {
+		return fmt.Errorf("message queue not enabled")
+	}
+	return ec.queue.CreateQueueWithOptions(name, options)
+}
+
+// ScaleWorkers sets the number of workers of a message queue
+func (ec *EventCoordinator) ScaleWorkers(name string, n int) error {
+	if ec.queue == nil {
+		return fmt.Errorf("message queue not enabled")
+	}
+	return ec.queue.ScaleWorkers(name, n)
+}
+
+// SetQueueWeight changes a message queue's share of worker slots
+func (ec *EventCoordinator) SetQueueWeight(name string, weight int) error {
+	if ec.queue == nil {
+		return fmt.Errorf("message queue not enabled")
+	}
+	return ec.queue.SetQueueWeight(name, weight)
+}
+
 // RegisterWorker registers a message worker
 func (ec *EventCoordinator) RegisterWorker(queueName string, handler MessageHandler) (string, error) {
 	if ec.queue == nil {
@@ -160,6 +221,25 @@ func (ec *EventCoordinator) GetCircuitBreaker(name string, config *CircuitBreake
 	return ec.breakers.GetOrCreate(name, config)
 }
 
+// ConfigureCircuitBreakers updates settings for all circuit breakers
+func (ec *EventCoordinator) ConfigureCircuitBreakers(maxFailures int, timeout, resetTimeout time.Duration) {
+	ec.breakers.Configure(maxFailures, timeout, resetTimeout)
+}
+
+// GetCircuitBreakers returns the circuit breakers by name
+func (ec *EventCoordinator) GetCircuitBreakers() map[string]*CircuitBreaker {
+	return ec.breakers.GetAll()
+}
+
+// GetQueueMetrics returns the message queue metrics, or false when the
+// queue is not enabled
+func (ec *EventCoordinator) GetQueueMetrics() (QueueMetrics, bool) {
+	if ec.queue == nil {
+		return QueueMetrics{}, false
+	}
+	return ec.queue.GetMetrics(), true
+}
+
 // GetEventStore returns the event store
 func (ec *EventCoordinator) GetEventStore() *EventStore {
 	return ec.store
@@ -184,6 +264,11 @@ func (ec *EventCoordinator) GetMetrics() map[string]interface{} {
 		}
 	}
 
+	// Backpressure metrics
+	if ec.pressure != nil {
+		metrics["backpressure"] = ec.pressure.Metrics()
+	}
+
 	// Circuit breaker metrics
 	breakerMetrics := make(map[string]interface{})
 	for name, cb := range ec.breakers.GetAll() {
@@ -194,46 +279,19 @@ func (ec *EventCoordinator) GetMetrics() map[string]interface{} {
 	return metrics
 }
 
-// Shutdown gracefully shuts down all components
+// Shutdown gracefully shuts down all components. It is Drain without
+// processing the queue backlog or persisting unprocessed messages.
 func (ec *EventCoordinator) Shutdown(ctx context.Context) error {
-	ec.mu.Lock()
-	if !ec.started {
-		ec.mu.Unlock()
-		return nil
-	}
-	ec.mu.Unlock()
-
-	ec.logger.Info("shutting down event coordinator")
-
-	// Shutdown components in order
-	var shutdownErrors []error
-
-	if ec.queue != nil {
-		if err := ec.queue.Shutdown(ctx); err != nil {
-			shutdownErrors = append(shutdownErrors, fmt.Errorf("queue shutdown error: %w", err))
-		}
-	}
-
-	if err := ec.bus.Shutdown(ctx); err != nil {
-		shutdownErrors = append(shutdownErrors, fmt.Errorf("bus shutdown error: %w", err))
-	}
-
-	if ec.store != nil {
-		if err := ec.store.Shutdown(ctx); err != nil {
-			shutdownErrors = append(shutdownErrors, fmt.Errorf("store shutdown error: %w", err))
-		}
-	}
-
-	ec.mu.Lock()
-	ec.started = false
-	ec.mu.Unlock()
+	_, err := ec.Drain(ctx, DrainOptions{})
+	return err
+}
 
-	if len(shutdownErrors) > 0 {
-		return fmt.Errorf("shutdown errors: %v", shutdownErrors)
+// RestoreMessages enqueues messages persisted by an earlier Drain
+func (ec *EventCoordinator) RestoreMessages(path string) (int, error) {
+	if ec.queue == nil {
+		return 0, fmt.Errorf("message queue not enabled")
 	}
-
-	ec.logger.Info("event coordinator shutdown complete")
-	return nil
+	return ec.queue.RestoreMessages(path)
 }
 
 // Helper methods for common operations
@@ -2,6 +2,7 @@ package events
 
 import (
 	"context"
+	"path/filepath"
 	"testing"
 	"time"
 
@@ -64,3 +65,87 @@ func TestCoordinatorPublishSubscribe(t *testing.T) {
 	err = coordinator.Shutdown(ctx)
 	require.NoError(t, err)
 }
+
+func TestCoordinatorDrainPersistsUnprocessedMessages(t *testing.T) {
+	config := DefaultCoordinatorConfig()
+	config.EnableStore = false
+	config.QueueConfig = &QueueConfig{MaxQueueSize: 10, MaxRetries: 1}
+
+	coordinator, err := NewEventCoordinator(config)
+	require.NoError(t, err)
+	ctx := context.Background()
+	require.NoError(t, coordinator.Start(ctx))
+	require.NoError(t, coordinator.CreateQueue("work", 10))
+
+	started := make(chan struct{})
+	release := make(chan struct{})
+	_, err = coordinator.RegisterWorker("work", func(ctx context.Context, msg Message) error {
+		if msg.ID == "first" {
+			close(started)
+			<-release
+		}
+		return nil
+	})
+	require.NoError(t, err)
+
+	require.NoError(t, coordinator.EnqueueMessage(Message{ID: "first", Queue: "work"}))
+	<-started
+	require.NoError(t, coordinator.EnqueueMessage(Message{ID: "second", Queue: "work", Payload: map[string]interface{}{"n": 2}}))
+	require.NoError(t, coordinator.EnqueueMessage(Message{ID: "third", Queue: "work"}))
+
+	path := filepath.Join(t.TempDir(), "pending.jsonl")
+	go func() {
+		time.Sleep(20 * time.Millisecond)
+		close(release)
+	}()
+	report, err := coordinator.Drain(ctx, DrainOptions{PersistPath: path})
+	require.NoError(t, err)
+
+	assert.False(t, report.TimedOut)
+	assert.Equal(t, int64(1), report.MessagesProcessed, "the in-flight message finishes")
+	assert.Equal(t, map[string]int{"work": 2}, report.MessagesUnprocessed)
+	assert.Equal(t, 2, report.MessagesPersisted)
+	assert.Equal(t, 0, report.MessagesDropped())
+
+	assert.ErrorIs(t, coordinator.EnqueueMessage(Message{Queue: "work"}), ErrQueueStopping)
+	assert.Error(t, coordinator.EmitSessionEvent(ctx, EventSessionCreated, SessionEventData{SessionID: "s1"}))
+
+	restarted := NewMessageQueue(&QueueConfig{MaxQueueSize: 10}, nil)
+	require.NoError(t, restarted.CreateQueue("work", 10))
+	restored, err := restarted.RestoreMessages(path)
+	require.NoError(t, err)
+	assert.Equal(t, 2, restored)
+	assert.Equal(t, 2, restarted.GetQueueSize("work"))
+	assert.NoFileExists(t, path)
+}
+
+func TestCoordinatorDrainProcessesBacklog(t *testing.T) {
+	config := DefaultCoordinatorConfig()
+	config.EnableStore = false
+
+	coordinator, err := NewEventCoordinator(config)
+	require.NoError(t, err)
+	ctx := context.Background()
+	require.NoError(t, coordinator.Start(ctx))
+	require.NoError(t, coordinator.CreateQueue("work", 10))
+	for i := 0; i < 5; i++ {
+		require.NoError(t, coordinator.EnqueueMessage(Message{Queue: "work"}))
+	}
+	release := make(chan struct{})
+	_, err = coordinator.RegisterWorker("work", func(ctx context.Context, msg Message) error {
+		<-release
+		return nil
+	})
+	require.NoError(t, err)
+
+	go func() {
+		time.Sleep(20 * time.Millisecond)
+		close(release)
+	}()
+	drainCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+	report, err := coordinator.Drain(drainCtx, DrainOptions{ProcessBacklog: true})
+	require.NoError(t, err)
+	assert.Equal(t, int64(5), report.MessagesProcessed)
+	assert.Empty(t, report.MessagesUnprocessed)
+}
@@ -68,6 +68,22 @@
 // - TopicGitHub - GitHub integration events
 // - TopicAll - Subscribe to all events
 //
+// Subscribers can narrow what they receive without filtering in their
+// handler. Types takes event type patterns, and Match filters by session,
+// repository, or minimum severity (ParseEventMatch parses expressions like
+// "session=123 severity=error"):
+//
+//	coordinator.Subscribe(events.TopicAll, events.Subscriber{
+//	    ID:      "failures",
+//	    Types:   []string{"session.*", "task.failed"},
+//	    Match:   &events.EventMatch{MinSeverity: events.SeverityError},
+//	    Handler: handler,
+//	})
+//
+// Setting OrderKey (for example to SessionKey) delivers events with the same
+// key to a subscriber one at a time in publish order, while events with
+// other keys are still delivered concurrently.
+//
 // # Message Queue
 //
 // For asynchronous task processing:
@@ -90,6 +106,17 @@
 //	    Payload: data,
 //	})
 //
+// With QueueConfig.MaxConcurrent set, workers of all queues share that many
+// slots, handed out by queue weight. CreateQueueWithOptions sets a queue's
+// weight and lets it start extra workers, up to MaxWorkers, while it has a
+// backlog; they stop again once idle. ScaleWorkers and SetQueueWeight change
+// a queue at runtime.
+//
+// Messages with an IdempotencyKey are accepted once per queue within
+// QueueConfig.IdempotencyWindow; repeats return ErrDuplicateMessage, which
+// producers handling redeliveries can treat as success. A message that ends
+// in the dead letter queue releases its key so a redelivery can retry it.
+//
 // # Circuit Breaker
 //
 // For fault-tolerant external API calls:
@@ -116,6 +143,32 @@
 //	    return nil
 //	})
 //
+// Producers that emit many events at once store them with AppendBatch,
+// which writes the journal once per batch. Readers that follow the store
+// page through it with ReadFrom and resume from the returned Cursor, or
+// range over Events. A store with a journal serves them from the journal
+// file, starting at the cursor's byte offset, and loads it into memory only
+// for queries such as GetRecent:
+//
+//	for stored, err := range store.Events(ctx, cursor) {
+//	    if err != nil {
+//	        return err
+//	    }
+//	    // Process stored.Event
+//	}
+//
+// # Payloads and Schema Versions
+//
+// Each event type has a registered payload struct, such as TaskEventData
+// for task events. Events read back from JSON carry their data as maps;
+// DecodeData converts it to the payload type:
+//
+//	data, err := events.DecodeData[events.TaskEventData](event)
+//
+// Events record the SchemaVersion they were written with. The event store
+// upgrades loaded events with MigrateEvent, running any migration registered
+// with RegisterMigration, and decodes their data with Typed.
+//
 // # Middleware
 //
 // The event bus supports middleware for cross-cutting concerns:
@@ -126,6 +179,17 @@
 // - FilterMiddleware - Filters events
 // - DeduplicationMiddleware - Prevents duplicate processing
 //
+// Middleware wraps a subscriber's handler once, when it subscribes or when
+// Use is called, not on every publish; publishing to synchronous
+// subscribers does not allocate unless the middleware does.
+//
+// Backpressure keeps a slow subscriber from stalling publishers: each
+// subscriber gets a bounded buffer drained at a configurable rate, and a
+// full buffer drops its oldest event, blocks the publisher, or spills the
+// event to a message queue. Set CoordinatorConfig.Backpressure, or use
+// NewBackpressure(...).Middleware() directly; Metrics reports dropped events
+// per subscriber.
+//
 // # Integration
 //
 // The event system integrates with Juleson components:
@@ -139,6 +203,23 @@
 //	// In Jules client
 //	c.eventCoordinator.EmitActivityEvent(ctx, events.EventActivityReceived, data)
 //
+// # Draining
+//
+// Shutdown stops the coordinator after in-flight handlers and messages
+// finish. Drain does the same with a bounded context and returns a
+// ShutdownReport of what was processed, rejected, and left behind; it can
+// also work through the queue backlog first and persist the messages it
+// could not process:
+//
+//	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+//	defer cancel()
+//	report, err := coordinator.Drain(ctx, events.DrainOptions{
+//	    ProcessBacklog: true,
+//	    PersistPath:    "pending-messages.jsonl",
+//	})
+//
+// On the next start, RestoreMessages enqueues the persisted messages again.
+//
 // # Metrics
 //
 // Get comprehensive metrics from all components:
@@ -0,0 +1,296 @@
+package events
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"sort"
+	"time"
+)
+
+// DrainOptions configures EventCoordinator.Drain.
+type DrainOptions struct {
+	// ProcessBacklog keeps queue workers processing waiting messages until
+	// the context ends, instead of stopping after their current message.
+	ProcessBacklog bool
+	// PersistPath, when set, is a JSONL file that messages left unprocessed
+	// are appended to, for RestoreMessages to enqueue on the next start.
+	PersistPath string
+}
+
+// ShutdownReport describes what a drain completed and what it left behind.
+type ShutdownReport struct {
+	StartedAt time.Time     `json:"started_at"`
+	Duration  time.Duration `json:"duration"`
+	// TimedOut is set when handlers or workers were still running when the
+	// drain's context ended.
+	TimedOut bool `json:"timed_out"`
+	// EventsRejected and MessagesRejected count publishes and enqueues
+	// refused because the coordinator was stopping.
+	EventsRejected   int64 `json:"events_rejected"`
+	MessagesRejected int64 `json:"messages_rejected"`
+	// EventsDropped counts events backpressure dropped while running.
+	EventsDropped int64 `json:"events_dropped"`
+	// MessagesProcessed counts messages processed during the drain.
+	MessagesProcessed int64 `json:"messages_processed"`
+	// MessagesUnprocessed counts the messages left waiting, by queue.
+	MessagesUnprocessed map[string]int `json:"messages_unprocessed,omitempty"`
+	// MessagesPersisted counts the unprocessed messages written to
+	// PersistPath; the rest were dropped.
+	MessagesPersisted int      `json:"messages_persisted"`
+	PersistPath       string   `json:"persist_path,omitempty"`
+	Errors            []string `json:"errors,omitempty"`
+}
+
+// MessagesDropped returns the number of unprocessed messages that were not
+// persisted.
+func (r ShutdownReport) MessagesDropped() int {
+	total := 0
+	for _, n := range r.MessagesUnprocessed {
+		total += n
+	}
+	return total - r.MessagesPersisted
+}
+
+// Drain shuts the coordinator down gracefully. It stops accepting publishes
+// and messages, waits until ctx ends for in-flight handlers and queue
+// workers, persists the messages left unprocessed when opts.PersistPath is
+// set, and reports what was completed and dropped.
+func (ec *EventCoordinator) Drain(ctx context.Context, opts DrainOptions) (ShutdownReport, error) {
+	report := ShutdownReport{StartedAt: time.Now(), PersistPath: opts.PersistPath}
+
+	ec.mu.Lock()
+	if !ec.started {
+		ec.mu.Unlock()
+		return report, nil
+	}
+	ec.mu.Unlock()
+
+	ec.logger.Info("draining event coordinator", "process_backlog", opts.ProcessBacklog)
+
+	var shutdownErrors []error
+	fail := func(err error) {
+		if errors.Is(err, context.DeadlineExceeded) || errors.Is(err, context.Canceled) {
+			report.TimedOut = true
+		}
+		shutdownErrors = append(shutdownErrors, err)
+		report.Errors = append(report.Errors, err.Error())
+	}
+
+	// Stop publishes first so nothing new reaches subscribers or queues,
+	// then let buffered deliveries and async handlers finish.
+	if err := ec.bus.Shutdown(ctx); err != nil {
+		fail(fmt.Errorf("bus shutdown error: %w", err))
+	}
+
+	if ec.pressure != nil {
+		if err := ec.pressure.Close(ctx); err != nil {
+			fail(fmt.Errorf("backpressure shutdown error: %w", err))
+		}
+		report.EventsDropped = ec.pressure.Metrics().Dropped
+	}
+
+	if ec.queue != nil {
+		processed := ec.queue.GetMetrics().MessagesProcessed
+		pending, err := ec.queue.Drain(ctx, opts.ProcessBacklog)
+		if err != nil {
+			fail(fmt.Errorf("queue shutdown error: %w", err))
+		}
+		metrics := ec.queue.GetMetrics()
+		report.MessagesProcessed = metrics.MessagesProcessed - processed
+		report.MessagesRejected = metrics.MessagesRejected
+
+		if len(pending) > 0 {
+			report.MessagesUnprocessed = make(map[string]int)
+			for _, msg := range pending {
+				report.MessagesUnprocessed[msg.Queue]++
+			}
+			if opts.PersistPath != "" {
+				if err := persistMessages(opts.PersistPath, pending); err != nil {
+					fail(err)
+				} else {
+					report.MessagesPersisted = len(pending)
+				}
+			}
+		}
+	}
+
+	if ec.store != nil {
+		if err := ec.store.Shutdown(ctx); err != nil {
+			fail(fmt.Errorf("store shutdown error: %w", err))
+		}
+	}
+
+	report.EventsRejected = ec.bus.GetMetrics().EventsRejected
+	report.Duration = time.Since(report.StartedAt)
+
+	ec.mu.Lock()
+	ec.started = false
+	ec.mu.Unlock()
+
+	ec.logger.Info("event coordinator drained",
+		"duration", report.Duration,
+		"timed_out", report.TimedOut,
+		"messages_processed", report.MessagesProcessed,
+		"messages_persisted", report.MessagesPersisted,
+		"messages_dropped", report.MessagesDropped(),
+		"events_rejected", report.EventsRejected)
+
+	if len(shutdownErrors) > 0 {
+		return report, fmt.Errorf("shutdown errors: %v", shutdownErrors)
+	}
+	return report, nil
+}
+
+// Drain stops accepting messages and stops the workers, first letting them
+// process the waiting messages until ctx ends when processBacklog is set.
+// It removes and returns the messages still waiting, in queue name and
+// priority order.
+func (mq *MessageQueue) Drain(ctx context.Context, processBacklog bool) ([]Message, error) {
+	mq.mu.Lock()
+	mq.stopping = true
+	mq.mu.Unlock()
+
+	var err error
+	if processBacklog {
+		err = mq.waitEmpty(ctx)
+	}
+	if stopErr := mq.stopWorkers(ctx); err == nil {
+		err = stopErr
+	}
+	return mq.takePending(), err
+}
+
+// waitEmpty waits until every queue is empty or ctx ends.
+func (mq *MessageQueue) waitEmpty(ctx context.Context) error {
+	ticker := time.NewTicker(10 * time.Millisecond)
+	defer ticker.Stop()
+	for {
+		if mq.pendingCount() == 0 {
+			return nil
+		}
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("drain timeout: %w", ctx.Err())
+		case <-ticker.C:
+		}
+	}
+}
+
+func (mq *MessageQueue) pendingCount() int {
+	mq.mu.RLock()
+	defer mq.mu.RUnlock()
+	total := 0
+	for _, queue := range mq.queues {
+		total += queue.Size()
+	}
+	return total
+}
+
+func (mq *MessageQueue) takePending() []Message {
+	mq.mu.RLock()
+	defer mq.mu.RUnlock()
+
+	names := make([]string, 0, len(mq.queues))
+	for name := range mq.queues {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var pending []Message
+	for _, name := range names {
+		for {
+			item, ok := mq.queues[name].Pop()
+			if !ok {
+				break
+			}
+			if key := idempotencyKey(item.Message); key != "" {
+				mq.idempotent.Delete(key)
+			}
+			pending = append(pending, item.Message)
+		}
+	}
+	return pending
+}
+
+// persistMessages appends messages to a JSONL file.
+func persistMessages(path string, messages []Message) error {
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o600)
+	if err != nil {
+		return fmt.Errorf("failed to persist messages: %w", err)
+	}
+	encoder := json.NewEncoder(file)
+	for _, msg := range messages {
+		if err := encoder.Encode(msg); err != nil {
+			file.Close()
+			return fmt.Errorf("failed to persist message %s: %w", msg.ID, err)
+		}
+	}
+	if err := file.Close(); err != nil {
+		return fmt.Errorf("failed to persist messages: %w", err)
+	}
+	return nil
+}
+
+// RestoreMessages enqueues the messages a drain persisted to path and
+// removes the file. Messages that cannot be enqueued, such as those for
+// queues that no longer exist, are written back to it. Payloads are
+// restored as decoded JSON, so handlers see maps rather than the original
+// structs. A missing file restores nothing.
+func (mq *MessageQueue) RestoreMessages(path string) (int, error) {
+	file, err := os.Open(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to open persisted messages: %w", err)
+	}
+
+	var messages []Message
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 0, 64*1024), 10*1024*1024)
+	for scanner.Scan() {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		var msg Message
+		if err := json.Unmarshal(scanner.Bytes(), &msg); err != nil {
+			file.Close()
+			return 0, fmt.Errorf("failed to read persisted message: %w", err)
+		}
+		messages = append(messages, msg)
+	}
+	file.Close()
+	if err := scanner.Err(); err != nil {
+		return 0, fmt.Errorf("failed to read persisted messages: %w", err)
+	}
+
+	restored := 0
+	var failed []Message
+	var restoreErrors []error
+	for _, msg := range messages {
+		if err := mq.Enqueue(msg); err != nil && !errors.Is(err, ErrDuplicateMessage) {
+			failed = append(failed, msg)
+			restoreErrors = append(restoreErrors, err)
+			continue
+		}
+		restored++
+	}
+
+	if err := os.Remove(path); err != nil {
+		return restored, fmt.Errorf("failed to remove persisted messages: %w", err)
+	}
+	if len(failed) > 0 {
+		if err := persistMessages(path, failed); err != nil {
+			return restored, err
+		}
+		return restored, fmt.Errorf("failed to restore %d messages: %w", len(failed), errors.Join(restoreErrors...))
+	}
+
+	mq.logger.Info("messages restored", "path", path, "count", restored)
+	return restored, nil
+}
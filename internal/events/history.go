@@ -0,0 +1,180 @@
+package events
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"log/slog"
+	"path/filepath"
+	"slices"
+	"time"
+
+	"github.com/SamyRai/juleson/internal/secrets"
+)
+
+// Run statuses.
+const (
+	RunStatusRunning   = "running"
+	RunStatusCompleted = "completed"
+	RunStatusFailed    = "failed"
+)
+
+// RunTask is one task of a run.
+type RunTask struct {
+	Name      string        `json:"name"`
+	Status    string        `json:"status"`
+	SessionID string        `json:"session_id,omitempty"`
+	Duration  time.Duration `json:"duration,omitempty"`
+	Error     string        `json:"error,omitempty"`
+}
+
+// RunSession is one Jules session a run created.
+type RunSession struct {
+	ID    string `json:"id"`
+	Task  string `json:"task,omitempty"`
+	State string `json:"state,omitempty"`
+	URL   string `json:"url,omitempty"`
+}
+
+// RunRecord is the history of one run, rebuilt from its events.
+type RunRecord struct {
+	ID           string        `json:"id"`
+	Workflow     string        `json:"workflow"`
+	Status       string        `json:"status"`
+	Started      time.Time     `json:"started"`
+	Finished     time.Time     `json:"finished,omitempty"`
+	Duration     time.Duration `json:"duration,omitempty"`
+	Error        string        `json:"error,omitempty"`
+	TotalTasks   int           `json:"total_tasks"`
+	Tasks        []RunTask     `json:"tasks,omitempty"`
+	Sessions     []RunSession  `json:"sessions,omitempty"`
+	PullRequests []string      `json:"pull_requests,omitempty"`
+}
+
+// TaskCounts returns how many of the run's tasks completed and failed.
+func (r RunRecord) TaskCounts() (completed, failed int) {
+	for _, task := range r.Tasks {
+		switch task.Status {
+		case "completed":
+			completed++
+		case "failed":
+			failed++
+		}
+	}
+	return completed, failed
+}
+
+// RunHistory maps run IDs to their records.
+type RunHistory map[string]RunRecord
+
+// Sorted returns the runs, most recent first.
+func (h RunHistory) Sorted() []RunRecord {
+	runs := make([]RunRecord, 0, len(h))
+	for _, run := range h {
+		runs = append(runs, run)
+	}
+	slices.SortFunc(runs, func(a, b RunRecord) int { return b.Started.Compare(a.Started) })
+	return runs
+}
+
+// NewRunID returns a new run ID, sortable by start time.
+func NewRunID() string {
+	buf := make([]byte, 3)
+	_, _ = rand.Read(buf)
+	return time.Now().UTC().Format("20060102-150405") + "-" + hex.EncodeToString(buf)
+}
+
+// RunKey returns the ID of the run an event belongs to.
+func RunKey(event Event) string {
+	return eventFields(event).value("run_id")
+}
+
+// OpenRunHistory opens the append-only journal of run events at path. A
+// non-nil cipher encrypts new events.
+func OpenRunHistory(path string, cipher secrets.Sealer, logger *slog.Logger) (*EventStore, error) {
+	return NewEventStore(&EventStoreConfig{
+		StorageDir:  filepath.Dir(path),
+		JournalPath: path,
+		Cipher:      cipher,
+	}, logger)
+}
+
+// NewRunHistoryProjection projects workflow, task, session, and pull
+// request events carrying a run_id into per-run records.
+func NewRunHistoryProjection() *StateProjection[RunHistory] {
+	return NewStateProjection(ProjectionRunHistory,
+		func() RunHistory { return make(RunHistory) },
+		func(history *RunHistory, event Event) error {
+			id := RunKey(event)
+			if id == "" {
+				return nil
+			}
+			run, ok := (*history)[id]
+			if !ok {
+				run = RunRecord{ID: id, Status: RunStatusRunning, Started: event.Timestamp}
+			}
+			applyRunEvent(&run, event)
+			(*history)[id] = run
+			return nil
+		})
+}
+
+func applyRunEvent(run *RunRecord, event Event) {
+	switch event.Type {
+	case EventWorkflowStarted, EventWorkflowCompleted, EventWorkflowFailed:
+		data, err := DecodeData[WorkflowEventData](event)
+		if err != nil {
+			return
+		}
+		run.Workflow = data.WorkflowName
+		run.TotalTasks = data.TotalPhases
+		switch event.Type {
+		case EventWorkflowStarted:
+			run.Started = event.Timestamp
+		case EventWorkflowCompleted:
+			run.Status = RunStatusCompleted
+		case EventWorkflowFailed:
+			run.Status = RunStatusFailed
+			run.Error = data.Error
+		}
+		if event.Type != EventWorkflowStarted {
+			run.Finished = event.Timestamp
+			run.Duration = data.Duration
+		}
+	case EventTaskStarted, EventTaskCompleted, EventTaskFailed:
+		data, err := DecodeData[TaskEventData](event)
+		if err != nil {
+			return
+		}
+		task := RunTask{Name: data.TaskID, Status: data.Status, SessionID: SessionKey(event), Duration: data.Duration, Error: data.Error}
+		if i := slices.IndexFunc(run.Tasks, func(t RunTask) bool { return t.Name == task.Name }); i >= 0 {
+			run.Tasks[i] = task
+		} else {
+			run.Tasks = append(run.Tasks, task)
+		}
+	case EventSessionCreated, EventSessionCompleted, EventSessionFailed, EventPlanAwaitingApproval:
+		data, err := DecodeData[SessionEventData](event)
+		if err != nil || data.SessionID == "" {
+			return
+		}
+		i := slices.IndexFunc(run.Sessions, func(s RunSession) bool { return s.ID == data.SessionID })
+		if i < 0 {
+			run.Sessions = append(run.Sessions, RunSession{ID: data.SessionID})
+			i = len(run.Sessions) - 1
+		}
+		session := &run.Sessions[i]
+		if task := eventFields(event).value("task"); task != "" {
+			session.Task = task
+		}
+		if data.State != "" {
+			session.State = data.State
+		}
+		if data.URL != "" {
+			session.URL = data.URL
+		}
+	case EventPRCreated:
+		data, err := DecodeData[GitHubEventData](event)
+		if err == nil && data.PRURL != "" && !slices.Contains(run.PullRequests, data.PRURL) {
+			run.PullRequests = append(run.PullRequests, data.PRURL)
+		}
+	}
+}
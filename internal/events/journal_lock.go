@@ -0,0 +1,25 @@
+package events
+
+import (
+	"fmt"
+	"os"
+)
+
+// lockJournal locks the journal at path against appends and compaction, by
+// this process and others sharing the journal, until the returned function
+// is called. The lock is held on path+".lock", since compaction replaces
+// the journal file itself.
+func lockJournal(path string) (func(), error) {
+	file, err := os.OpenFile(path+".lock", os.O_CREATE|os.O_RDWR, 0o600)
+	if err != nil {
+		return nil, fmt.Errorf("failed to lock event journal: %w", err)
+	}
+	if err := lockFile(file); err != nil {
+		_ = file.Close()
+		return nil, fmt.Errorf("failed to lock event journal: %w", err)
+	}
+	return func() {
+		_ = unlockFile(file)
+		_ = file.Close()
+	}, nil
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"log/slog"
+	"sync"
 	"time"
 )
 
@@ -220,11 +221,13 @@ func DeduplicationMiddleware(window time.Duration) Middleware {
 
 	return func(next EventHandler) EventHandler {
 		return func(ctx context.Context, event Event) error {
-			if seen.Has(event.ID) {
+			// Each subscriber receives an event once.
+			key := SubscriberID(ctx) + "/" + event.ID
+			if seen.Has(key) {
 				return nil // Skip duplicate
 			}
 
-			seen.Add(event.ID)
+			seen.Add(key)
 			return next(ctx, event)
 		}
 	}
@@ -232,6 +235,7 @@ func DeduplicationMiddleware(window time.Duration) Middleware {
 
 // seenCache tracks recently seen event IDs
 type seenCache struct {
+	mu     sync.Mutex
 	items  map[string]time.Time
 	window time.Duration
 }
@@ -244,6 +248,8 @@ func newSeenCache(window time.Duration) *seenCache {
 }
 
 func (sc *seenCache) Has(id string) bool {
+	sc.mu.Lock()
+	defer sc.mu.Unlock()
 	expiry, exists := sc.items[id]
 	if !exists {
 		return false
@@ -257,9 +263,36 @@ func (sc *seenCache) Has(id string) bool {
 	return true
 }
 
+// AddIfAbsent adds id unless it was seen within the window, reporting
+// whether it was added.
+func (sc *seenCache) AddIfAbsent(id string) bool {
+	sc.mu.Lock()
+	defer sc.mu.Unlock()
+	if seen, exists := sc.items[id]; exists && time.Since(seen) <= sc.window {
+		return false
+	}
+	sc.items[id] = time.Now()
+	sc.cleanup()
+	return true
+}
+
+// Delete forgets id.
+func (sc *seenCache) Delete(id string) {
+	sc.mu.Lock()
+	defer sc.mu.Unlock()
+	delete(sc.items, id)
+}
+
 func (sc *seenCache) Add(id string) {
+	sc.mu.Lock()
+	defer sc.mu.Unlock()
 	sc.items[id] = time.Now()
+	sc.cleanup()
+}
 
+// cleanup drops expired entries once the cache grows. The caller holds
+// sc.mu.
+func (sc *seenCache) cleanup() {
 	// Clean up old entries periodically
 	if len(sc.items) > 1000 {
 		now := time.Now()
@@ -0,0 +1,217 @@
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+// Projection is a read model rebuilt from stored events.
+type Projection interface {
+	// Name identifies the projection and its snapshot file.
+	Name() string
+	// Apply folds one event into the read model.
+	Apply(event Event) error
+	// Snapshot returns the read model as JSON.
+	Snapshot() (json.RawMessage, error)
+	// Restore replaces the read model with a snapshot, or resets it when
+	// state is nil.
+	Restore(state json.RawMessage) error
+}
+
+// StateProjection is a Projection of a state S updated by a reducer.
+type StateProjection[S any] struct {
+	name    string
+	initial func() S
+	reduce  func(*S, Event) error
+	state   S
+}
+
+// NewStateProjection creates a projection that starts from initial() and
+// applies reduce to each event.
+func NewStateProjection[S any](name string, initial func() S, reduce func(*S, Event) error) *StateProjection[S] {
+	return &StateProjection[S]{name: name, initial: initial, reduce: reduce, state: initial()}
+}
+
+// Name returns the projection's name.
+func (p *StateProjection[S]) Name() string { return p.name }
+
+// State returns the current read model.
+func (p *StateProjection[S]) State() S { return p.state }
+
+// Apply reduces one event into the state.
+func (p *StateProjection[S]) Apply(event Event) error { return p.reduce(&p.state, event) }
+
+// Snapshot encodes the state as JSON.
+func (p *StateProjection[S]) Snapshot() (json.RawMessage, error) { return json.Marshal(p.state) }
+
+// Restore decodes a snapshot into a fresh state, or resets it when state is
+// nil.
+func (p *StateProjection[S]) Restore(state json.RawMessage) error {
+	p.state = p.initial()
+	if state == nil {
+		return nil
+	}
+	return json.Unmarshal(state, &p.state)
+}
+
+// projectionSnapshot is the file a projection's snapshot is saved in.
+type projectionSnapshot struct {
+	// LastEventID is the last event applied to the state.
+	LastEventID string          `json:"last_event_id"`
+	State       json.RawMessage `json:"state"`
+}
+
+// Projector rebuilds projections by replaying an event store. With a
+// snapshot directory, each projection resumes from its last snapshot and
+// only applies newer events. Snapshots are encrypted with the store's
+// cipher.
+type Projector struct {
+	store       *EventStore
+	snapshotDir string
+	projections []Projection
+}
+
+// NewProjector creates a projector for store. snapshotDir may be empty to
+// always replay every event.
+func NewProjector(store *EventStore, snapshotDir string, projections ...Projection) *Projector {
+	return &Projector{store: store, snapshotDir: snapshotDir, projections: projections}
+}
+
+// Rebuild brings every projection up to date with the store and saves new
+// snapshots. A snapshot whose last event is no longer stored is discarded
+// and the projection rebuilt from the start.
+func (p *Projector) Rebuild(ctx context.Context) error {
+	for _, projection := range p.projections {
+		if err := p.rebuild(ctx, projection); err != nil {
+			return fmt.Errorf("projection %s: %w", projection.Name(), err)
+		}
+	}
+	return nil
+}
+
+func (p *Projector) rebuild(ctx context.Context, projection Projection) error {
+	snapshot, err := p.loadSnapshot(projection.Name())
+	if err != nil {
+		return err
+	}
+
+	cursor, ok := p.store.cursorAfter(snapshot.LastEventID)
+	if ok && snapshot.LastEventID != "" {
+		err = projection.Restore(snapshot.State)
+	} else {
+		cursor = 0
+		err = projection.Restore(nil)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to restore snapshot: %w", err)
+	}
+
+	lastEventID := snapshot.LastEventID
+	applied := 0
+	for stored, err := range p.store.Events(ctx, cursor) {
+		if err != nil {
+			if ctx.Err() != nil {
+				return fmt.Errorf("rebuild cancelled: %w", err)
+			}
+			return fmt.Errorf("failed to read events: %w", err)
+		}
+		if err := projection.Apply(stored.Event); err != nil {
+			return fmt.Errorf("failed to apply event %s: %w", stored.ID, err)
+		}
+		lastEventID = stored.ID
+		applied++
+	}
+	if applied == 0 && ok {
+		return nil
+	}
+	return p.saveSnapshot(projection, lastEventID)
+}
+
+func (p *Projector) snapshotPath(name string) string {
+	return filepath.Join(p.snapshotDir, name+".snapshot.json")
+}
+
+func (p *Projector) loadSnapshot(name string) (projectionSnapshot, error) {
+	var snapshot projectionSnapshot
+	if p.snapshotDir == "" {
+		return snapshot, nil
+	}
+	data, err := os.ReadFile(p.snapshotPath(name))
+	if errors.Is(err, fs.ErrNotExist) {
+		return snapshot, nil
+	}
+	if err != nil {
+		return snapshot, fmt.Errorf("failed to read snapshot: %w", err)
+	}
+	data, err = openRecord(p.store.cipher, data)
+	if err != nil {
+		// An unreadable snapshot only costs a full replay, like a corrupt one.
+		return projectionSnapshot{}, nil
+	}
+	if err := json.Unmarshal(data, &snapshot); err != nil {
+		// A corrupt snapshot only costs a full replay.
+		return projectionSnapshot{}, nil
+	}
+	return snapshot, nil
+}
+
+func (p *Projector) saveSnapshot(projection Projection, lastEventID string) error {
+	if p.snapshotDir == "" || lastEventID == "" {
+		return nil
+	}
+	state, err := projection.Snapshot()
+	if err != nil {
+		return fmt.Errorf("failed to encode snapshot: %w", err)
+	}
+	data, err := json.Marshal(projectionSnapshot{LastEventID: lastEventID, State: state})
+	if err != nil {
+		return fmt.Errorf("failed to encode snapshot: %w", err)
+	}
+	if data, err = sealRecord(p.store.cipher, data); err != nil {
+		return fmt.Errorf("failed to encode snapshot: %w", err)
+	}
+	if err := os.MkdirAll(p.snapshotDir, 0o755); err != nil {
+		return fmt.Errorf("failed to create snapshot directory: %w", err)
+	}
+	path := p.snapshotPath(projection.Name())
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o600); err != nil {
+		return fmt.Errorf("failed to write snapshot: %w", err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		return fmt.Errorf("failed to write snapshot: %w", err)
+	}
+	return nil
+}
+
+// cursorAfter returns the cursor of the event after the one with the given
+// ID, or of the oldest event when id is empty. ok is false when the event
+// is not in the store. A journal is searched on disk.
+func (es *EventStore) cursorAfter(id string) (Cursor, bool) {
+	if id == "" {
+		return 0, true
+	}
+	if es.journalPath != "" {
+		var (
+			cursor Cursor
+			found  bool
+		)
+		err := es.scanJournal(0, func(stored StoredEvent, after Cursor) bool {
+			cursor, found = after, stored.ID == id
+			return !found
+		})
+		return cursor, err == nil && found
+	}
+	es.mu.RLock()
+	defer es.mu.RUnlock()
+	i, ok := es.index.byID[id]
+	if !ok {
+		return 0, false
+	}
+	return Cursor(es.base + int64(i) + 1), true
+}
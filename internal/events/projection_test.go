@@ -0,0 +1,106 @@
+package events
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProjectorRebuildsSessionTimelines(t *testing.T) {
+	store, err := NewEventStore(&EventStoreConfig{StorageDir: t.TempDir()}, nil)
+	require.NoError(t, err)
+	require.NoError(t, store.Store(NewAuditEvent("cli", AuditData{Action: "session.create", Target: "s1", Success: true})))
+	require.NoError(t, store.Store(NewEvent(EventSessionCreated, "session", SessionEventData{SessionID: "s1", State: "QUEUED", SourceID: "sources/github/owner/name"})))
+	require.NoError(t, store.Store(NewEvent(EventTaskFailed, "task", TaskEventData{TaskID: "build", Status: "failed", Metadata: map[string]interface{}{"session_id": "s1"}})))
+	require.NoError(t, store.Store(NewAuditEvent("cli", AuditData{Action: "patch.apply", Target: "s1", Success: true})))
+
+	timelines := NewSessionTimelinesProjection()
+	stats := NewRepositoryStatsProjection()
+	require.NoError(t, NewProjector(store, "", timelines, stats).Rebuild(context.Background()))
+
+	var summaries []string
+	for _, entry := range timelines.State()["s1"] {
+		summaries = append(summaries, entry.Summary)
+	}
+	assert.Equal(t, []string{"session.create", "QUEUED", "build · failed"}, summaries)
+	assert.Equal(t, RepositoryStats{SessionsCreated: 1}, stats.State()["sources/github/owner/name"])
+}
+
+func TestProjectorResumesFromSnapshot(t *testing.T) {
+	store, err := NewEventStore(&EventStoreConfig{StorageDir: t.TempDir()}, nil)
+	require.NoError(t, err)
+	snapshots := t.TempDir()
+	ctx := context.Background()
+
+	applied := 0
+	counter := func() *StateProjection[int] {
+		return NewStateProjection("count", func() int { return 0 }, func(count *int, event Event) error {
+			applied++
+			*count++
+			return nil
+		})
+	}
+
+	require.NoError(t, store.Store(NewEvent(EventSystemStarted, "test", nil)))
+	require.NoError(t, store.Store(NewEvent(EventSystemStarted, "test", nil)))
+	require.NoError(t, NewProjector(store, snapshots, counter()).Rebuild(ctx))
+	assert.Equal(t, 2, applied)
+
+	require.NoError(t, store.Store(NewEvent(EventSystemStarted, "test", nil)))
+	resumed := counter()
+	require.NoError(t, NewProjector(store, snapshots, resumed).Rebuild(ctx))
+	assert.Equal(t, 3, applied, "only the new event is applied")
+	assert.Equal(t, 3, resumed.State())
+
+	// A snapshot whose last event is gone is discarded.
+	store.Clear()
+	require.NoError(t, store.Store(NewEvent(EventSystemStarted, "test", nil)))
+	rebuilt := counter()
+	require.NoError(t, NewProjector(store, snapshots, rebuilt).Rebuild(ctx))
+	assert.Equal(t, 1, rebuilt.State())
+}
+
+func TestProjectorRebuildsRunHistory(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "history.jsonl")
+	store, err := OpenRunHistory(path, nil, nil)
+	require.NoError(t, err)
+	run := func(event Event) Event { return event.WithMetadata("run_id", "r1") }
+	for _, event := range []Event{
+		run(NewEvent(EventWorkflowStarted, "workflow", WorkflowEventData{WorkflowName: "refactor", TotalPhases: 2})),
+		run(NewEvent(EventTaskStarted, "task", TaskEventData{TaskID: "api", Status: "started"})),
+		run(NewEvent(EventSessionCreated, "session", SessionEventData{SessionID: "s1", State: "QUEUED", Metadata: map[string]interface{}{"task": "api"}})),
+		run(NewEvent(EventSessionCompleted, "session", SessionEventData{SessionID: "s1", State: "COMPLETED"})),
+		run(NewEvent(EventPRCreated, "session", GitHubEventData{PRURL: "https://github.com/acme/api/pull/7"})),
+		run(NewEvent(EventTaskCompleted, "task", TaskEventData{TaskID: "api", Status: "completed", Duration: time.Minute, Metadata: map[string]interface{}{"session_id": "s1"}})),
+		run(NewEvent(EventTaskFailed, "task", TaskEventData{TaskID: "web", Status: "failed", Error: "boom"})),
+		run(NewEvent(EventWorkflowFailed, "workflow", WorkflowEventData{WorkflowName: "refactor", TotalPhases: 2, Error: "1 task failed", Duration: 2 * time.Minute})),
+		NewEvent(EventSessionCreated, "session", SessionEventData{SessionID: "other"}),
+	} {
+		require.NoError(t, store.Store(event))
+	}
+
+	// Rebuild from the journal, as 'juleson history' does.
+	reopened, err := OpenRunHistory(path, nil, nil)
+	require.NoError(t, err)
+	history := NewRunHistoryProjection()
+	require.NoError(t, NewProjector(reopened, "", history).Rebuild(context.Background()))
+
+	require.Len(t, history.State(), 1)
+	record := history.State()["r1"]
+	assert.Equal(t, "refactor", record.Workflow)
+	assert.Equal(t, RunStatusFailed, record.Status)
+	assert.Equal(t, "1 task failed", record.Error)
+	assert.Equal(t, 2*time.Minute, record.Duration)
+	assert.Equal(t, []RunTask{
+		{Name: "api", Status: "completed", SessionID: "s1", Duration: time.Minute},
+		{Name: "web", Status: "failed", Error: "boom"},
+	}, record.Tasks)
+	assert.Equal(t, []RunSession{{ID: "s1", Task: "api", State: "COMPLETED"}}, record.Sessions)
+	assert.Equal(t, []string{"https://github.com/acme/api/pull/7"}, record.PullRequests)
+	completed, failed := record.TaskCounts()
+	assert.Equal(t, [2]int{1, 1}, [2]int{completed, failed})
+}
@@ -0,0 +1,129 @@
+package events
+
+import (
+	"strings"
+	"time"
+)
+
+// Names of the built-in projections.
+const (
+	ProjectionSessionTimelines = "session-timelines"
+	ProjectionRepositoryStats  = "repository-stats"
+	ProjectionRunHistory       = "run-history"
+)
+
+// TimelineEntry is one step in a session's timeline.
+type TimelineEntry struct {
+	Time    time.Time `json:"time"`
+	Type    EventType `json:"type"`
+	Summary string    `json:"summary"`
+	EventID string    `json:"event_id"`
+}
+
+// SessionTimelines maps session IDs to their timelines, oldest first.
+type SessionTimelines map[string][]TimelineEntry
+
+// NewSessionTimelinesProjection projects session, task, and activity events,
+// and audit entries for session operations, into per-session timelines.
+func NewSessionTimelinesProjection() *StateProjection[SessionTimelines] {
+	return NewStateProjection(ProjectionSessionTimelines,
+		func() SessionTimelines { return make(SessionTimelines) },
+		func(timelines *SessionTimelines, event Event) error {
+			session, summary := timelineStep(event)
+			if session == "" {
+				return nil
+			}
+			(*timelines)[session] = append((*timelines)[session], TimelineEntry{
+				Time:    event.Timestamp,
+				Type:    event.Type,
+				Summary: summary,
+				EventID: event.ID,
+			})
+			return nil
+		})
+}
+
+// timelineStep returns the session an event belongs to and a one-line
+// summary of it.
+func timelineStep(event Event) (string, string) {
+	switch event.Type {
+	case EventAuditRecorded:
+		data, err := DecodeData[AuditData](event)
+		if err != nil || !strings.HasPrefix(data.Action, "session.") {
+			return "", ""
+		}
+		summary := data.Action
+		if !data.Success {
+			summary += " failed: " + data.Error
+		}
+		return data.Target, summary
+	}
+
+	session := SessionKey(event)
+	if session == "" {
+		return "", ""
+	}
+	switch {
+	case strings.HasPrefix(string(event.Type), "session."):
+		data, _ := DecodeData[SessionEventData](event)
+		return session, joinSummary(data.State, data.Title, data.Error)
+	case strings.HasPrefix(string(event.Type), "task."):
+		data, _ := DecodeData[TaskEventData](event)
+		return session, joinSummary(data.TaskID, data.Status, data.Error)
+	case strings.HasPrefix(string(event.Type), "activity."):
+		data, _ := DecodeData[ActivityEventData](event)
+		return session, joinSummary(data.ActivityType, data.Originator, data.Description)
+	default:
+		return session, string(event.Type)
+	}
+}
+
+func joinSummary(parts ...string) string {
+	var kept []string
+	for _, part := range parts {
+		if part != "" {
+			kept = append(kept, part)
+		}
+	}
+	return strings.Join(kept, " · ")
+}
+
+// RepositoryStats counts the sessions and tasks of one repository.
+type RepositoryStats struct {
+	SessionsCreated   int `json:"sessions_created"`
+	SessionsCompleted int `json:"sessions_completed"`
+	SessionsFailed    int `json:"sessions_failed"`
+	TasksFailed       int `json:"tasks_failed"`
+}
+
+// NewRepositoryStatsProjection projects session and task events into
+// per-repository counts, keyed by the events' repository or source_id.
+func NewRepositoryStatsProjection() *StateProjection[map[string]RepositoryStats] {
+	return NewStateProjection(ProjectionRepositoryStats,
+		func() map[string]RepositoryStats { return make(map[string]RepositoryStats) },
+		func(stats *map[string]RepositoryStats, event Event) error {
+			fields := eventFields(event)
+			repo := fields.value("repository")
+			if repo == "" {
+				repo = fields.value("source_id")
+			}
+			if repo == "" {
+				return nil
+			}
+			counts := (*stats)[repo]
+			switch event.Type {
+			case EventSessionCreated:
+				counts.SessionsCreated++
+			case EventSessionCompleted:
+				counts.SessionsCompleted++
+			case EventSessionFailed:
+				counts.SessionsFailed++
+			case EventTaskFailed:
+				counts.TasksFailed++
+			default:
+				return nil
+			}
+			(*stats)[repo] = counts
+			return nil
+		})
+}
@@ -0,0 +1,150 @@
+package events
+
+import "time"
+
+// DefaultQueryLimit is the page size of queries that do not set a limit.
+const DefaultQueryLimit = 100
+
+// Query selects stored events. Empty fields match every event.
+type Query struct {
+	// Types holds event type patterns, such as "session.*" or "task.failed".
+	Types []string
+	Topic string
+	// SessionID matches events about this session (see SessionKey).
+	SessionID string
+	// Since and Until bound event timestamps, inclusive and exclusive.
+	Since time.Time
+	Until time.Time
+	// Offset skips this many matching events; Limit caps the page size,
+	// DefaultQueryLimit when zero.
+	Offset int
+	Limit  int
+}
+
+// QueryResult is one page of matching events, oldest first.
+type QueryResult struct {
+	Events []StoredEvent `json:"events"`
+	// Total is the number of events matching the query across all pages.
+	Total int `json:"total"`
+	// NextOffset is the offset of the next page, or zero on the last page.
+	NextOffset int `json:"next_offset,omitempty"`
+}
+
+// Query returns the page of stored events matching q. Session and exact
+// type lookups use the store's indexes instead of scanning every event.
+func (es *EventStore) Query(q Query) QueryResult {
+	es.ensureLoaded()
+	es.mu.RLock()
+	defer es.mu.RUnlock()
+
+	limit := q.Limit
+	if limit <= 0 {
+		limit = DefaultQueryLimit
+	}
+	result := QueryResult{Events: make([]StoredEvent, 0)}
+	for _, i := range es.index.candidates(q, len(es.events)) {
+		event := es.events[i]
+		if !q.matches(event.Event) {
+			continue
+		}
+		if result.Total >= q.Offset && len(result.Events) < limit {
+			result.Events = append(result.Events, event)
+		}
+		result.Total++
+	}
+	if next := q.Offset + len(result.Events); next < result.Total {
+		result.NextOffset = next
+	}
+	return result
+}
+
+func (q Query) matches(event Event) bool {
+	if q.Topic != "" && event.Topic != q.Topic {
+		return false
+	}
+	if !matchesTypes(q.Types, event.Type) {
+		return false
+	}
+	if !q.Since.IsZero() && event.Timestamp.Before(q.Since) {
+		return false
+	}
+	if !q.Until.IsZero() && !event.Timestamp.Before(q.Until) {
+		return false
+	}
+	return q.SessionID == "" || SessionKey(event) == q.SessionID
+}
+
+// storeIndex maps event IDs, types, and session IDs to positions in the
+// store's events.
+type storeIndex struct {
+	byID      map[string]int
+	byType    map[EventType][]int
+	bySession map[string][]int
+}
+
+func (idx *storeIndex) add(i int, event Event) {
+	if idx.byID == nil {
+		idx.byID = make(map[string]int)
+		idx.byType = make(map[EventType][]int)
+		idx.bySession = make(map[string][]int)
+	}
+	idx.byID[event.ID] = i
+	idx.byType[event.Type] = append(idx.byType[event.Type], i)
+	if session := SessionKey(event); session != "" {
+		idx.bySession[session] = append(idx.bySession[session], i)
+	}
+}
+
+func (idx *storeIndex) rebuild(events []StoredEvent) {
+	*idx = storeIndex{}
+	for i := range events {
+		idx.add(i, events[i].Event)
+	}
+}
+
+// candidates returns the positions, in order, of the events that may match
+// q: a session's events, the events of exact types, or every event.
+func (idx *storeIndex) candidates(q Query, count int) []int {
+	if q.SessionID != "" {
+		return idx.bySession[q.SessionID]
+	}
+	if len(q.Types) > 0 && !hasPattern(q.Types) {
+		var positions []int
+		for _, eventType := range q.Types {
+			positions = mergeSorted(positions, idx.byType[EventType(eventType)])
+		}
+		return positions
+	}
+	positions := make([]int, count)
+	for i := range positions {
+		positions[i] = i
+	}
+	return positions
+}
+
+func hasPattern(types []string) bool {
+	for _, eventType := range types {
+		for _, c := range eventType {
+			if c == '*' || c == '?' || c == '[' || c == '\\' {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// mergeSorted merges two ascending position lists, dropping duplicates.
+func mergeSorted(a, b []int) []int {
+	merged := make([]int, 0, len(a)+len(b))
+	for len(a) > 0 && len(b) > 0 {
+		switch {
+		case a[0] < b[0]:
+			merged, a = append(merged, a[0]), a[1:]
+		case b[0] < a[0]:
+			merged, b = append(merged, b[0]), b[1:]
+		default:
+			merged, a, b = append(merged, a[0]), a[1:], b[1:]
+		}
+	}
+	return append(append(merged, a...), b...)
+}
@@ -0,0 +1,49 @@
+package events
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEventStoreQuery(t *testing.T) {
+	store, err := NewEventStore(&EventStoreConfig{StorageDir: t.TempDir(), MaxEvents: 100}, nil)
+	require.NoError(t, err)
+
+	start := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	add := func(minute int, eventType EventType, topic string, data interface{}) {
+		event := NewEvent(eventType, "test", data).WithTopic(topic)
+		event.Timestamp = start.Add(time.Duration(minute) * time.Minute)
+		require.NoError(t, store.Store(event))
+	}
+	add(0, EventSessionCreated, TopicSession, SessionEventData{SessionID: "s1"})
+	add(1, EventTaskStarted, TopicTask, TaskEventData{TaskID: "a", Metadata: map[string]interface{}{"session_id": "s1"}})
+	add(2, EventSessionCreated, TopicSession, SessionEventData{SessionID: "s2"})
+	add(3, EventTaskFailed, TopicTask, TaskEventData{TaskID: "b", Metadata: map[string]interface{}{"session_id": "s2"}})
+	add(4, EventSessionCompleted, TopicSession, SessionEventData{SessionID: "s1"})
+
+	types := func(result QueryResult) []EventType {
+		var out []EventType
+		for _, event := range result.Events {
+			out = append(out, event.Type)
+		}
+		return out
+	}
+
+	assert.Equal(t, []EventType{EventSessionCreated, EventTaskStarted, EventSessionCompleted}, types(store.Query(Query{SessionID: "s1"})))
+	assert.Equal(t, []EventType{EventSessionCreated, EventSessionCreated, EventSessionCompleted}, types(store.Query(Query{Types: []string{"session.*"}})))
+	assert.Equal(t, []EventType{EventSessionCreated, EventSessionCreated, EventTaskFailed}, types(store.Query(Query{Types: []string{"task.failed", "session.created"}})))
+	assert.Equal(t, []EventType{EventTaskStarted, EventTaskFailed}, types(store.Query(Query{Topic: TopicTask})))
+	assert.Equal(t, []EventType{EventTaskStarted, EventSessionCreated}, types(store.Query(Query{Since: start.Add(time.Minute), Until: start.Add(3 * time.Minute)})))
+
+	page := store.Query(Query{Offset: 1, Limit: 2})
+	assert.Equal(t, 5, page.Total)
+	assert.Equal(t, 3, page.NextOffset)
+	assert.Equal(t, []EventType{EventTaskStarted, EventSessionCreated}, types(page))
+
+	last := store.Query(Query{Offset: 4, Limit: 2})
+	assert.Equal(t, 0, last.NextOffset)
+	assert.Len(t, last.Events, 1)
+}
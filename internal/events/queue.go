@@ -2,6 +2,7 @@ package events
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log/slog"
 	"sync"
@@ -13,6 +14,9 @@ import (
 type MessageQueue struct {
 	queues     map[string]*PriorityQueue
 	workers    map[string][]*Worker
+	pools      map[string]*workerPool
+	scheduler  *fairScheduler
+	idempotent *seenCache
 	dlq        *DeadLetterQueue
 	mu         sync.RWMutex
 	logger     *slog.Logger
@@ -21,6 +25,7 @@ type MessageQueue struct {
 	retryDelay time.Duration
 	stopping   bool
 	stopChan   chan struct{}
+	stopOnce   sync.Once
 	wg         sync.WaitGroup
 }
 
@@ -35,8 +40,24 @@ type Message struct {
 	Retries    int
 	MaxRetries int
 	Timeout    time.Duration
+	// IdempotencyKey, when set, makes the queue accept the message once per
+	// idempotency window, so redelivered requests are not processed twice.
+	IdempotencyKey string
 }
 
+// ErrDuplicateMessage is returned by Enqueue for a message whose
+// idempotency key was already enqueued within the window. Producers can
+// treat it as success.
+var ErrDuplicateMessage = errors.New("duplicate message")
+
+// ErrQueueStopping is returned by Enqueue once the queue is shutting down
+// or draining.
+var ErrQueueStopping = errors.New("message queue is stopping")
+
+// defaultIdempotencyWindow is how long idempotency keys are remembered
+// when QueueConfig does not say.
+const defaultIdempotencyWindow = 10 * time.Minute
+
 // QueueConfig configures the message queue
 type QueueConfig struct {
 	MaxQueueSize int
@@ -44,16 +65,24 @@ type QueueConfig struct {
 	RetryDelay   time.Duration
 	WorkerCount  int
 	DLQMaxSize   int
+	// MaxConcurrent caps how many messages are processed at once across
+	// all queues, sharing the slots between queues by weight. Zero means
+	// every worker runs freely.
+	MaxConcurrent int
+	// IdempotencyWindow is how long message idempotency keys are remembered
+	// (default 10 minutes).
+	IdempotencyWindow time.Duration
 }
 
 // DefaultQueueConfig returns default queue configuration
 func DefaultQueueConfig() *QueueConfig {
 	return &QueueConfig{
-		MaxQueueSize: 10000,
-		MaxRetries:   3,
-		RetryDelay:   5 * time.Second,
-		WorkerCount:  5,
-		DLQMaxSize:   1000,
+		MaxQueueSize:      10000,
+		MaxRetries:        3,
+		RetryDelay:        5 * time.Second,
+		WorkerCount:       5,
+		DLQMaxSize:        1000,
+		IdempotencyWindow: defaultIdempotencyWindow,
 	}
 }
 
@@ -66,9 +95,22 @@ func NewMessageQueue(config *QueueConfig, logger *slog.Logger) *MessageQueue {
 		logger = slog.Default()
 	}
 
+	window := config.IdempotencyWindow
+	if window <= 0 {
+		window = defaultIdempotencyWindow
+	}
+
+	var scheduler *fairScheduler
+	if config.MaxConcurrent > 0 {
+		scheduler = newFairScheduler(config.MaxConcurrent)
+	}
+
 	return &MessageQueue{
 		queues:     make(map[string]*PriorityQueue),
 		workers:    make(map[string][]*Worker),
+		pools:      make(map[string]*workerPool),
+		scheduler:  scheduler,
+		idempotent: newSeenCache(window),
 		dlq:        NewDeadLetterQueue(config.DLQMaxSize),
 		logger:     logger,
 		metrics:    &QueueMetrics{},
@@ -80,22 +122,7 @@ func NewMessageQueue(config *QueueConfig, logger *slog.Logger) *MessageQueue {
 
 // CreateQueue creates a new queue with the specified configuration
 func (mq *MessageQueue) CreateQueue(name string, maxSize int) error {
-	if name == "" {
-		return fmt.Errorf("queue name cannot be empty")
-	}
-
-	mq.mu.Lock()
-	defer mq.mu.Unlock()
-
-	if _, exists := mq.queues[name]; exists {
-		return fmt.Errorf("queue %s already exists", name)
-	}
-
-	mq.queues[name] = NewPriorityQueue(maxSize)
-	mq.workers[name] = make([]*Worker, 0)
-
-	mq.logger.Info("queue created", "queue", name, "max_size", maxSize)
-	return nil
+	return mq.CreateQueueWithOptions(name, QueueOptions{MaxSize: maxSize})
 }
 
 // RegisterWorker registers a worker for a queue
@@ -108,23 +135,10 @@ func (mq *MessageQueue) RegisterWorker(queueName string, handler MessageHandler)
 		return "", fmt.Errorf("queue %s not found", queueName)
 	}
 
-	workerID := fmt.Sprintf("worker-%s-%d", queueName, len(mq.workers[queueName])+1)
-	worker := &Worker{
-		ID:       workerID,
-		Queue:    queueName,
-		Handler:  handler,
-		stopChan: make(chan struct{}),
-		logger:   mq.logger,
-	}
-
-	mq.workers[queueName] = append(mq.workers[queueName], worker)
-
-	// Start worker
-	mq.wg.Add(1)
-	go mq.runWorker(worker, queue)
+	worker := mq.startWorkerLocked(queueName, queue, handler, false)
 
-	mq.logger.Info("worker registered", "worker_id", workerID, "queue", queueName)
-	return workerID, nil
+	mq.logger.Info("worker registered", "worker_id", worker.ID, "queue", queueName)
+	return worker.ID, nil
 }
 
 // Enqueue adds a message to a queue
@@ -135,8 +149,13 @@ func (mq *MessageQueue) Enqueue(msg Message) error {
 
 	mq.mu.RLock()
 	queue, exists := mq.queues[msg.Queue]
+	stopping := mq.stopping
 	mq.mu.RUnlock()
 
+	if stopping {
+		mq.metrics.recordRejected()
+		return ErrQueueStopping
+	}
 	if !exists {
 		return fmt.Errorf("queue %s not found", msg.Queue)
 	}
@@ -164,11 +183,24 @@ func (mq *MessageQueue) Enqueue(msg Message) error {
 		Attempts:  0,
 	}
 
+	key := idempotencyKey(msg)
+	if key != "" && !mq.idempotent.AddIfAbsent(key) {
+		mq.metrics.recordDeduplicated()
+		mq.logger.Debug("duplicate message dropped",
+			"queue", msg.Queue,
+			"idempotency_key", msg.IdempotencyKey)
+		return fmt.Errorf("%w: idempotency key %s", ErrDuplicateMessage, msg.IdempotencyKey)
+	}
+
 	if err := queue.Push(item); err != nil {
+		if key != "" {
+			mq.idempotent.Delete(key)
+		}
 		return fmt.Errorf("failed to enqueue message: %w", err)
 	}
 
 	mq.metrics.recordEnqueued()
+	mq.autoscale(msg.Queue)
 
 	mq.logger.Debug("message enqueued",
 		"message_id", msg.ID,
@@ -178,6 +210,14 @@ func (mq *MessageQueue) Enqueue(msg Message) error {
 	return nil
 }
 
+// idempotencyKey scopes a message's idempotency key to its queue.
+func idempotencyKey(msg Message) string {
+	if msg.IdempotencyKey == "" {
+		return ""
+	}
+	return msg.Queue + "/" + msg.IdempotencyKey
+}
+
 // GetQueueSize returns the size of a queue
 func (mq *MessageQueue) GetQueueSize(queueName string) int {
 	mq.mu.RLock()
@@ -196,14 +236,25 @@ func (mq *MessageQueue) GetDLQMessages() []DeadLetterMessage {
 	return mq.dlq.GetAll()
 }
 
-// Shutdown gracefully shuts down the message queue
+// Shutdown gracefully shuts down the message queue. Workers finish the
+// message they are processing; waiting messages are left in the queues.
 func (mq *MessageQueue) Shutdown(ctx context.Context) error {
 	mq.mu.Lock()
 	mq.stopping = true
-	close(mq.stopChan)
 	mq.mu.Unlock()
 
-	// Wait for all workers to finish
+	if err := mq.stopWorkers(ctx); err != nil {
+		return err
+	}
+	mq.logger.Info("message queue shutdown complete")
+	return nil
+}
+
+// stopWorkers stops all workers and waits for them to finish their current
+// message.
+func (mq *MessageQueue) stopWorkers(ctx context.Context) error {
+	mq.stopOnce.Do(func() { close(mq.stopChan) })
+
 	done := make(chan struct{})
 	go func() {
 		mq.wg.Wait()
@@ -212,7 +263,6 @@ func (mq *MessageQueue) Shutdown(ctx context.Context) error {
 
 	select {
 	case <-done:
-		mq.logger.Info("message queue shutdown complete")
 		return nil
 	case <-ctx.Done():
 		return fmt.Errorf("shutdown timeout: %w", ctx.Err())
@@ -8,8 +8,14 @@ type QueueMetrics struct {
 	MessagesDequeued  int64
 	MessagesProcessed int64
 	MessagesFailed    int64
-	DLQSize           int64
-	mu                sync.RWMutex
+	// MessagesDeduplicated counts messages rejected for a repeated
+	// idempotency key.
+	MessagesDeduplicated int64
+	// MessagesRejected counts messages refused because the queue is
+	// stopping.
+	MessagesRejected int64
+	DLQSize          int64
+	mu               sync.RWMutex
 }
 
 // GetMetrics returns current queue metrics.
@@ -18,11 +24,13 @@ func (mq *MessageQueue) GetMetrics() QueueMetrics {
 	defer mq.metrics.mu.RUnlock()
 
 	return QueueMetrics{
-		MessagesEnqueued:  mq.metrics.MessagesEnqueued,
-		MessagesDequeued:  mq.metrics.MessagesDequeued,
-		MessagesProcessed: mq.metrics.MessagesProcessed,
-		MessagesFailed:    mq.metrics.MessagesFailed,
-		DLQSize:           mq.metrics.DLQSize,
+		MessagesEnqueued:     mq.metrics.MessagesEnqueued,
+		MessagesDequeued:     mq.metrics.MessagesDequeued,
+		MessagesProcessed:    mq.metrics.MessagesProcessed,
+		MessagesFailed:       mq.metrics.MessagesFailed,
+		MessagesDeduplicated: mq.metrics.MessagesDeduplicated,
+		MessagesRejected:     mq.metrics.MessagesRejected,
+		DLQSize:              mq.metrics.DLQSize,
 	}
 }
 
@@ -50,3 +58,15 @@ func (m *QueueMetrics) recordFailed() {
 	m.MessagesFailed++
 	m.DLQSize++
 }
+
+func (m *QueueMetrics) recordDeduplicated() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.MessagesDeduplicated++
+}
+
+func (m *QueueMetrics) recordRejected() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.MessagesRejected++
+}
@@ -0,0 +1,325 @@
+package events
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Queue scaling defaults.
+const (
+	defaultScaleUpBacklog = 10
+	defaultIdleTimeout    = 30 * time.Second
+)
+
+// QueueOptions configures a queue created with CreateQueueWithOptions.
+type QueueOptions struct {
+	// MaxSize caps the number of waiting messages; zero means unlimited.
+	MaxSize int
+	// Weight is the queue's share of worker slots when QueueConfig sets
+	// MaxConcurrent (default 1). While several queues have messages, a
+	// queue of weight 3 is served three times as often as one of weight 1.
+	Weight int
+	// MaxWorkers lets the queue start workers beyond those registered while
+	// it has a backlog, up to this many in total. Zero disables scaling.
+	MaxWorkers int
+	// ScaleUpBacklog is the number of waiting messages per worker at which
+	// another worker is started (default 10).
+	ScaleUpBacklog int
+	// IdleTimeout stops workers started by scaling once they have been idle
+	// this long (default 30s).
+	IdleTimeout time.Duration
+}
+
+// QueueStats describes a queue at runtime.
+type QueueStats struct {
+	Name    string `json:"name"`
+	Size    int    `json:"size"`
+	Workers int    `json:"workers"`
+	Weight  int    `json:"weight"`
+}
+
+// workerPool is the runtime state of a queue's workers.
+type workerPool struct {
+	options QueueOptions
+	// handler is the first registered worker's handler, used for workers
+	// started by scaling.
+	handler MessageHandler
+	nextID  int
+}
+
+// CreateQueueWithOptions creates a queue with a weight and worker scaling.
+func (mq *MessageQueue) CreateQueueWithOptions(name string, options QueueOptions) error {
+	if name == "" {
+		return fmt.Errorf("queue name cannot be empty")
+	}
+	if options.Weight <= 0 {
+		options.Weight = 1
+	}
+	if options.ScaleUpBacklog <= 0 {
+		options.ScaleUpBacklog = defaultScaleUpBacklog
+	}
+	if options.IdleTimeout <= 0 {
+		options.IdleTimeout = defaultIdleTimeout
+	}
+
+	mq.mu.Lock()
+	defer mq.mu.Unlock()
+
+	if _, exists := mq.queues[name]; exists {
+		return fmt.Errorf("queue %s already exists", name)
+	}
+
+	mq.queues[name] = NewPriorityQueue(options.MaxSize)
+	mq.workers[name] = make([]*Worker, 0)
+	mq.pools[name] = &workerPool{options: options}
+	if mq.scheduler != nil {
+		mq.scheduler.setWeight(name, options.Weight)
+	}
+
+	mq.logger.Info("queue created",
+		"queue", name,
+		"max_size", options.MaxSize,
+		"weight", options.Weight,
+		"max_workers", options.MaxWorkers)
+	return nil
+}
+
+// SetQueueWeight changes a queue's share of worker slots.
+func (mq *MessageQueue) SetQueueWeight(name string, weight int) error {
+	if weight <= 0 {
+		return fmt.Errorf("queue weight must be positive")
+	}
+	mq.mu.Lock()
+	defer mq.mu.Unlock()
+
+	pool, exists := mq.pools[name]
+	if !exists {
+		return fmt.Errorf("queue %s not found", name)
+	}
+	pool.options.Weight = weight
+	if mq.scheduler != nil {
+		mq.scheduler.setWeight(name, weight)
+	}
+	return nil
+}
+
+// ScaleWorkers starts or stops workers so the queue has n, all running the
+// handler of its first registered worker.
+func (mq *MessageQueue) ScaleWorkers(name string, n int) error {
+	if n < 1 {
+		return fmt.Errorf("worker count must be at least 1")
+	}
+	mq.mu.Lock()
+	defer mq.mu.Unlock()
+
+	queue, exists := mq.queues[name]
+	if !exists {
+		return fmt.Errorf("queue %s not found", name)
+	}
+	pool := mq.pools[name]
+	if pool.handler == nil {
+		return fmt.Errorf("queue %s has no registered worker to scale", name)
+	}
+	for len(mq.workers[name]) < n {
+		mq.startWorkerLocked(name, queue, pool.handler, false)
+	}
+	for len(mq.workers[name]) > n {
+		workers := mq.workers[name]
+		close(workers[len(workers)-1].stopChan)
+		mq.workers[name] = workers[:len(workers)-1]
+	}
+	mq.logger.Info("queue workers scaled", "queue", name, "workers", n)
+	return nil
+}
+
+// GetQueueStats returns the size, worker count, and weight of a queue.
+func (mq *MessageQueue) GetQueueStats(name string) (QueueStats, error) {
+	mq.mu.RLock()
+	defer mq.mu.RUnlock()
+
+	queue, exists := mq.queues[name]
+	if !exists {
+		return QueueStats{}, fmt.Errorf("queue %s not found", name)
+	}
+	return QueueStats{
+		Name:    name,
+		Size:    queue.Size(),
+		Workers: len(mq.workers[name]),
+		Weight:  mq.pools[name].options.Weight,
+	}, nil
+}
+
+// startWorkerLocked starts a worker for a queue. The caller holds mq.mu.
+func (mq *MessageQueue) startWorkerLocked(name string, queue *PriorityQueue, handler MessageHandler, scaled bool) *Worker {
+	pool := mq.pools[name]
+	if pool.handler == nil {
+		pool.handler = handler
+	}
+	pool.nextID++
+	worker := &Worker{
+		ID:       fmt.Sprintf("worker-%s-%d", name, pool.nextID),
+		Queue:    name,
+		Handler:  handler,
+		stopChan: make(chan struct{}),
+		logger:   mq.logger,
+		scaled:   scaled,
+	}
+	mq.workers[name] = append(mq.workers[name], worker)
+
+	mq.wg.Add(1)
+	go mq.runWorker(worker, queue)
+	return worker
+}
+
+// autoscale starts another worker when a queue's backlog per worker reaches
+// its ScaleUpBacklog and it is below MaxWorkers.
+func (mq *MessageQueue) autoscale(name string) {
+	mq.mu.Lock()
+	defer mq.mu.Unlock()
+
+	pool, exists := mq.pools[name]
+	if !exists || mq.stopping || pool.handler == nil {
+		return
+	}
+	workers := len(mq.workers[name])
+	if workers == 0 || workers >= pool.options.MaxWorkers {
+		return
+	}
+	queue := mq.queues[name]
+	if queue.Size() < pool.options.ScaleUpBacklog*workers {
+		return
+	}
+	worker := mq.startWorkerLocked(name, queue, pool.handler, true)
+	mq.logger.Info("queue scaled up", "queue", name, "worker_id", worker.ID, "workers", workers+1)
+}
+
+// idleTimeout returns how long a worker may idle before retiring, or zero
+// for workers that never retire.
+func (mq *MessageQueue) idleTimeout(worker *Worker) time.Duration {
+	if !worker.scaled {
+		return 0
+	}
+	mq.mu.RLock()
+	defer mq.mu.RUnlock()
+	return mq.pools[worker.Queue].options.IdleTimeout
+}
+
+// retire removes a scaled worker that has been idle. It reports whether the
+// worker should stop.
+func (mq *MessageQueue) retire(worker *Worker) bool {
+	mq.mu.Lock()
+	defer mq.mu.Unlock()
+
+	workers := mq.workers[worker.Queue]
+	for i, w := range workers {
+		if w == worker {
+			mq.workers[worker.Queue] = append(workers[:i:i], workers[i+1:]...)
+			mq.logger.Info("queue scaled down", "queue", worker.Queue, "worker_id", worker.ID, "workers", len(workers)-1)
+			return true
+		}
+	}
+	return false
+}
+
+// fairScheduler hands out a fixed number of worker slots, choosing between
+// queues with waiting workers by smooth weighted round robin.
+type fairScheduler struct {
+	mu      sync.Mutex
+	free    int
+	weights map[string]int
+	current map[string]int
+	waiters map[string][]chan struct{}
+}
+
+func newFairScheduler(slots int) *fairScheduler {
+	return &fairScheduler{
+		free:    slots,
+		weights: make(map[string]int),
+		current: make(map[string]int),
+		waiters: make(map[string][]chan struct{}),
+	}
+}
+
+func (s *fairScheduler) setWeight(queue string, weight int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.weights[queue] = weight
+}
+
+// acquire waits for a slot for a worker of queue. It returns false, without
+// a slot, once either stop channel closes.
+func (s *fairScheduler) acquire(queue string, stop, shutdown <-chan struct{}) bool {
+	s.mu.Lock()
+	grant := make(chan struct{}, 1)
+	s.waiters[queue] = append(s.waiters[queue], grant)
+	s.grantLocked()
+	s.mu.Unlock()
+
+	select {
+	case <-grant:
+		return true
+	case <-stop:
+	case <-shutdown:
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i, waiter := range s.waiters[queue] {
+		if waiter == grant {
+			s.waiters[queue] = append(s.waiters[queue][:i:i], s.waiters[queue][i+1:]...)
+			return false
+		}
+	}
+	// The slot was granted while stopping; hand it on.
+	s.free++
+	s.grantLocked()
+	return false
+}
+
+func (s *fairScheduler) release() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.free++
+	s.grantLocked()
+}
+
+func (s *fairScheduler) grantLocked() {
+	for s.free > 0 {
+		queue := s.pickLocked()
+		if queue == "" {
+			return
+		}
+		grant := s.waiters[queue][0]
+		s.waiters[queue] = s.waiters[queue][1:]
+		s.free--
+		grant <- struct{}{}
+	}
+}
+
+// pickLocked chooses the next queue with waiting workers by smooth weighted
+// round robin.
+func (s *fairScheduler) pickLocked() string {
+	queues := make([]string, 0, len(s.waiters))
+	for queue, waiters := range s.waiters {
+		if len(waiters) > 0 {
+			queues = append(queues, queue)
+		}
+	}
+	sort.Strings(queues)
+
+	best, total := "", 0
+	for _, queue := range queues {
+		weight := max(s.weights[queue], 1)
+		s.current[queue] += weight
+		total += weight
+		if best == "" || s.current[queue] > s.current[best] {
+			best = queue
+		}
+	}
+	if best != "" {
+		s.current[best] -= total
+	}
+	return best
+}
@@ -2,9 +2,11 @@ package events
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"io"
 	"log/slog"
+	"sync"
 	"testing"
 	"time"
 )
@@ -100,3 +102,135 @@ func eventually(t *testing.T, condition func() bool) {
 	}
 	t.Fatal("condition was not satisfied before timeout")
 }
+
+func TestMessageQueueSharesSlotsByWeight(t *testing.T) {
+	queue := NewMessageQueue(&QueueConfig{MaxConcurrent: 1, MaxRetries: 1}, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	defer queue.Shutdown(context.Background())
+	if err := queue.CreateQueueWithOptions("heavy", QueueOptions{Weight: 3}); err != nil {
+		t.Fatalf("create queue: %v", err)
+	}
+	if err := queue.CreateQueueWithOptions("light", QueueOptions{Weight: 1}); err != nil {
+		t.Fatalf("create queue: %v", err)
+	}
+	for i := 0; i < 20; i++ {
+		for _, name := range []string{"heavy", "light"} {
+			if err := queue.Enqueue(Message{Queue: name}); err != nil {
+				t.Fatalf("enqueue: %v", err)
+			}
+		}
+	}
+
+	var mu sync.Mutex
+	var processed []string
+	handler := func(ctx context.Context, msg Message) error {
+		mu.Lock()
+		processed = append(processed, msg.Queue)
+		mu.Unlock()
+		time.Sleep(time.Millisecond)
+		return nil
+	}
+	for _, name := range []string{"heavy", "light"} {
+		if _, err := queue.RegisterWorker(name, handler); err != nil {
+			t.Fatalf("register worker: %v", err)
+		}
+		if err := queue.ScaleWorkers(name, 3); err != nil {
+			t.Fatalf("scale workers: %v", err)
+		}
+	}
+
+	eventually(t, func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+		return len(processed) >= 16
+	})
+	mu.Lock()
+	heavy := 0
+	for _, name := range processed[:16] {
+		if name == "heavy" {
+			heavy++
+		}
+	}
+	mu.Unlock()
+	if heavy < 10 || heavy > 14 {
+		t.Fatalf("heavy queue got %d of the first 16 slots, want about 12", heavy)
+	}
+}
+
+func TestMessageQueueScalesWorkersWithBacklog(t *testing.T) {
+	queue := NewMessageQueue(DefaultQueueConfig(), slog.New(slog.NewTextHandler(io.Discard, nil)))
+	defer queue.Shutdown(context.Background())
+	if err := queue.CreateQueueWithOptions("work", QueueOptions{MaxWorkers: 3, ScaleUpBacklog: 1, IdleTimeout: 20 * time.Millisecond}); err != nil {
+		t.Fatalf("create queue: %v", err)
+	}
+	release := make(chan struct{})
+	if _, err := queue.RegisterWorker("work", func(ctx context.Context, msg Message) error {
+		<-release
+		return nil
+	}); err != nil {
+		t.Fatalf("register worker: %v", err)
+	}
+	for i := 0; i < 6; i++ {
+		if err := queue.Enqueue(Message{Queue: "work"}); err != nil {
+			t.Fatalf("enqueue: %v", err)
+		}
+	}
+
+	workers := func() int {
+		stats, err := queue.GetQueueStats("work")
+		if err != nil {
+			t.Fatalf("stats: %v", err)
+		}
+		return stats.Workers
+	}
+	if got := workers(); got != 3 {
+		t.Fatalf("workers = %d, want 3 while backlogged", got)
+	}
+	close(release)
+	eventually(t, func() bool { return workers() == 1 })
+
+	if err := queue.ScaleWorkers("work", 2); err != nil {
+		t.Fatalf("scale workers: %v", err)
+	}
+	if got := workers(); got != 2 {
+		t.Fatalf("workers = %d after ScaleWorkers, want 2", got)
+	}
+}
+
+func TestMessageQueueDropsDuplicateIdempotencyKeys(t *testing.T) {
+	queue := NewMessageQueue(&QueueConfig{MaxRetries: 1, RetryDelay: time.Millisecond, DLQMaxSize: 10}, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	defer queue.Shutdown(context.Background())
+	for _, name := range []string{"sessions", "issues"} {
+		if err := queue.CreateQueue(name, 10); err != nil {
+			t.Fatalf("create queue: %v", err)
+		}
+	}
+
+	if err := queue.Enqueue(Message{Queue: "sessions", IdempotencyKey: "delivery-1"}); err != nil {
+		t.Fatalf("enqueue: %v", err)
+	}
+	err := queue.Enqueue(Message{Queue: "sessions", IdempotencyKey: "delivery-1"})
+	if !errors.Is(err, ErrDuplicateMessage) {
+		t.Fatalf("second enqueue error = %v, want ErrDuplicateMessage", err)
+	}
+	// Keys are scoped to their queue.
+	if err := queue.Enqueue(Message{Queue: "issues", IdempotencyKey: "delivery-1"}); err != nil {
+		t.Fatalf("enqueue on another queue: %v", err)
+	}
+	if got := queue.GetQueueSize("sessions"); got != 1 {
+		t.Fatalf("sessions queue size = %d, want 1", got)
+	}
+	if got := queue.GetMetrics().MessagesDeduplicated; got != 1 {
+		t.Fatalf("deduplicated = %d, want 1", got)
+	}
+
+	// A message that ends in the DLQ releases its key for redelivery.
+	if _, err := queue.RegisterWorker("sessions", func(ctx context.Context, msg Message) error {
+		return fmt.Errorf("failed")
+	}); err != nil {
+		t.Fatalf("register worker: %v", err)
+	}
+	eventually(t, func() bool { return len(queue.GetDLQMessages()) == 1 })
+	if err := queue.Enqueue(Message{Queue: "sessions", IdempotencyKey: "delivery-1"}); err != nil {
+		t.Fatalf("redelivery after failure: %v", err)
+	}
+}
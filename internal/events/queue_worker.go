@@ -14,6 +14,8 @@ type Worker struct {
 	Handler  MessageHandler
 	stopChan chan struct{}
 	logger   *slog.Logger
+	// scaled workers were started by autoscaling and retire when idle.
+	scaled bool
 }
 
 // MessageHandler processes a message.
@@ -24,6 +26,12 @@ func (mq *MessageQueue) runWorker(worker *Worker, queue *PriorityQueue) {
 
 	mq.logger.Info("worker started", "worker_id", worker.ID, "queue", worker.Queue)
 
+	idleTimeout := mq.idleTimeout(worker)
+	poll := 1 * time.Second
+	if idleTimeout > 0 && idleTimeout < poll {
+		poll = idleTimeout
+	}
+	idleSince := time.Now()
 	for {
 		select {
 		case <-worker.stopChan:
@@ -33,8 +41,10 @@ func (mq *MessageQueue) runWorker(worker *Worker, queue *PriorityQueue) {
 			mq.logger.Info("worker stopped (queue shutdown)", "worker_id", worker.ID)
 			return
 		default:
-			item, ok := queue.Pop()
-			if !ok {
+			if queue.Size() == 0 {
+				if idleTimeout > 0 && time.Since(idleSince) >= idleTimeout && mq.retire(worker) {
+					return
+				}
 				select {
 				case <-queue.notEmpty:
 					continue
@@ -42,11 +52,21 @@ func (mq *MessageQueue) runWorker(worker *Worker, queue *PriorityQueue) {
 					return
 				case <-mq.stopChan:
 					return
-				case <-time.After(1 * time.Second):
+				case <-time.After(poll):
 					continue
 				}
 			}
-			mq.processMessage(worker, item)
+			if mq.scheduler != nil && !mq.scheduler.acquire(worker.Queue, worker.stopChan, mq.stopChan) {
+				return
+			}
+			item, ok := queue.Pop()
+			if ok {
+				mq.processMessage(worker, item)
+				idleSince = time.Now()
+			}
+			if mq.scheduler != nil {
+				mq.scheduler.release()
+			}
 		}
 	}
 }
@@ -102,6 +122,10 @@ func (mq *MessageQueue) handleMessageFailure(worker *Worker, item QueueItem, err
 		return
 	}
 
+	// Let a redelivery of the failed message try again.
+	if key := idempotencyKey(msg); key != "" {
+		mq.idempotent.Delete(key)
+	}
 	mq.logger.Warn("message moved to DLQ",
 		"message_id", msg.ID,
 		"attempts", item.Attempts)
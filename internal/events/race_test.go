@@ -0,0 +1,7 @@
+//go:build race
+
+package events
+
+// raceEnabled skips allocation checks, which the race detector's
+// instrumentation and sync.Pool's random drops under it would fail.
+const raceEnabled = true
@@ -0,0 +1,137 @@
+package events
+
+import (
+	"encoding/json"
+	"fmt"
+	"sync"
+)
+
+// CurrentSchemaVersion is the schema version of events created by NewEvent.
+// Events without a version predate versioning and are treated as version 0.
+const CurrentSchemaVersion = 1
+
+// Migration upgrades an event from one schema version to the next. It
+// returns the upgraded event; MigrateEvent sets its version.
+type Migration func(Event) (Event, error)
+
+type payloadDecoder func(raw []byte) (interface{}, error)
+
+var (
+	schemaMu   sync.RWMutex
+	payloads   = make(map[EventType]payloadDecoder)
+	migrations = make(map[int]Migration)
+)
+
+func init() {
+	RegisterPayload[AgentStateChangedData](EventAgentStateChanged)
+	RegisterPayload[AgentDecisionData](EventAgentDecision)
+	RegisterPayload[AgentProgressData](EventAgentProgress)
+	RegisterPayload[TaskEventData](EventTaskCreated, EventTaskStarted, EventTaskCompleted, EventTaskFailed, EventTaskRetrying)
+	RegisterPayload[SessionEventData](EventSessionCreated, EventSessionUpdated, EventSessionCompleted, EventSessionFailed, EventSessionCancelled, EventPlanAwaitingApproval)
+	RegisterPayload[PlanAdaptedData](EventPlanAdapted)
+	RegisterPayload[ActivityEventData](EventActivityReceived, EventActivityProcessed)
+	RegisterPayload[ToolEventData](EventToolInvoked, EventToolCompleted, EventToolFailed)
+	RegisterPayload[ReviewEventData](EventReviewStarted, EventReviewCompleted, EventReviewRejected)
+	RegisterPayload[ChangeEventData](EventChangeDetected, EventChangeApplied, EventChangeReverted)
+	RegisterPayload[WorkflowEventData](EventWorkflowStarted, EventWorkflowCompleted, EventWorkflowFailed, EventPhaseStarted, EventPhaseCompleted)
+	RegisterPayload[GitHubEventData](EventPRCreated, EventPRMerged, EventPRClosed)
+	RegisterPayload[ConfigReloadedData](EventConfigReloaded)
+	RegisterPayload[CircuitBreakerEventData](EventCircuitBreakerOpened)
+	RegisterPayload[AuditData](EventAuditRecorded)
+	RegisterPayload[AlertEventData](EventAlertFired)
+}
+
+// RegisterPayload registers T as the payload of the given event types, so
+// Typed and the event store decode their data into a T.
+func RegisterPayload[T any](types ...EventType) {
+	schemaMu.Lock()
+	defer schemaMu.Unlock()
+	for _, eventType := range types {
+		payloads[eventType] = func(raw []byte) (interface{}, error) {
+			var data T
+			err := json.Unmarshal(raw, &data)
+			return data, err
+		}
+	}
+}
+
+// RegisterMigration registers the migration that upgrades events from
+// schema version from to from+1. Versions without a migration are upgraded
+// unchanged.
+func RegisterMigration(from int, migration Migration) {
+	schemaMu.Lock()
+	defer schemaMu.Unlock()
+	migrations[from] = migration
+}
+
+// MigrateEvent upgrades an event to CurrentSchemaVersion, running the
+// registered migrations in order.
+func MigrateEvent(event Event) (Event, error) {
+	if event.SchemaVersion > CurrentSchemaVersion {
+		return event, fmt.Errorf("event %s has schema version %d, newer than supported version %d", event.ID, event.SchemaVersion, CurrentSchemaVersion)
+	}
+	for event.SchemaVersion < CurrentSchemaVersion {
+		schemaMu.RLock()
+		migration := migrations[event.SchemaVersion]
+		schemaMu.RUnlock()
+
+		version := event.SchemaVersion + 1
+		if migration != nil {
+			migrated, err := migration(event)
+			if err != nil {
+				return event, fmt.Errorf("failed to migrate event %s to schema version %d: %w", event.ID, version, err)
+			}
+			event = migrated
+		}
+		event.SchemaVersion = version
+	}
+	return event, nil
+}
+
+// Typed returns the event with its data decoded into the payload registered
+// for its type. Events loaded from JSON carry their data as generic maps;
+// events of unregistered types are returned unchanged.
+func Typed(event Event) (Event, error) {
+	schemaMu.RLock()
+	decode, ok := payloads[event.Type]
+	schemaMu.RUnlock()
+	if !ok || event.Data == nil {
+		return event, nil
+	}
+	raw, err := json.Marshal(event.Data)
+	if err != nil {
+		return event, fmt.Errorf("failed to encode %s data: %w", event.Type, err)
+	}
+	data, err := decode(raw)
+	if err != nil {
+		return event, fmt.Errorf("failed to decode %s data: %w", event.Type, err)
+	}
+	event.Data = data
+	return event, nil
+}
+
+// DecodeData returns an event's data as a T, converting data loaded from
+// JSON.
+func DecodeData[T any](event Event) (T, error) {
+	if data, ok := event.Data.(T); ok {
+		return data, nil
+	}
+	var data T
+	raw, err := json.Marshal(event.Data)
+	if err != nil {
+		return data, fmt.Errorf("failed to encode %s data: %w", event.Type, err)
+	}
+	if err := json.Unmarshal(raw, &data); err != nil {
+		return data, fmt.Errorf("failed to decode %s data: %w", event.Type, err)
+	}
+	return data, nil
+}
+
+// upgradeEvent migrates an event read from disk and decodes its data.
+func upgradeEvent(event Event) (Event, error) {
+	event, err := MigrateEvent(event)
+	if err != nil {
+		return event, err
+	}
+	return Typed(event)
+}
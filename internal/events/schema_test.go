@@ -0,0 +1,59 @@
+package events
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTypedDecodesRegisteredPayloads(t *testing.T) {
+	event := NewEvent(EventTaskFailed, "task", TaskEventData{TaskID: "build", Status: "failed", Error: "boom"})
+	assert.Equal(t, CurrentSchemaVersion, event.SchemaVersion)
+
+	raw, err := json.Marshal(event)
+	require.NoError(t, err)
+	var loaded Event
+	require.NoError(t, json.Unmarshal(raw, &loaded))
+	assert.IsType(t, map[string]interface{}{}, loaded.Data)
+
+	typed, err := Typed(loaded)
+	require.NoError(t, err)
+	assert.Equal(t, TaskEventData{TaskID: "build", Status: "failed", Error: "boom"}, typed.Data)
+
+	data, err := DecodeData[TaskEventData](loaded)
+	require.NoError(t, err)
+	assert.Equal(t, "build", data.TaskID)
+
+	unregistered := NewEvent(EventSystemStarted, "system", "data")
+	typed, err = Typed(unregistered)
+	require.NoError(t, err)
+	assert.Equal(t, "data", typed.Data)
+}
+
+func TestMigrateEvent(t *testing.T) {
+	legacy := Event{ID: "evt_1", Type: EventSessionCreated, Data: map[string]interface{}{"session_id": "s1"}}
+	migrated, err := MigrateEvent(legacy)
+	require.NoError(t, err)
+	assert.Equal(t, CurrentSchemaVersion, migrated.SchemaVersion)
+
+	_, err = MigrateEvent(Event{ID: "evt_2", SchemaVersion: CurrentSchemaVersion + 1})
+	assert.ErrorContains(t, err, "newer than supported version")
+}
+
+func TestEventStore_JournalUpgradesLegacyEvents(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "events.jsonl")
+	legacy := `{"id":"evt_1","type":"session.created","topic":"session","timestamp":"2026-01-02T03:04:05Z","data":{"session_id":"s1","state":"QUEUED"}}`
+	require.NoError(t, os.WriteFile(path, []byte(legacy+"\n"), 0o600))
+
+	store, err := NewEventStore(&EventStoreConfig{StorageDir: filepath.Dir(path), JournalPath: path}, nil)
+	require.NoError(t, err)
+
+	loaded, err := store.Get("evt_1")
+	require.NoError(t, err)
+	assert.Equal(t, CurrentSchemaVersion, loaded.SchemaVersion)
+	assert.Equal(t, SessionEventData{SessionID: "s1", State: "QUEUED"}, loaded.Data)
+}
@@ -0,0 +1,92 @@
+package events
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/SamyRai/juleson/internal/secrets"
+)
+
+// ErrEncrypted is returned when reading encrypted data without a cipher.
+var ErrEncrypted = errors.New("data is encrypted; configure the encryption key that wrote it")
+
+// sealedPrefix starts every encrypted journal line and file, followed by
+// the base64 sealed data. JSON never starts with it, so stores encrypted
+// after they were written in plaintext still read their older records.
+var sealedPrefix = []byte("enc:")
+
+// sealRecord encrypts data with cipher. A nil cipher leaves it as is.
+func sealRecord(cipher secrets.Sealer, data []byte) ([]byte, error) {
+	if cipher == nil {
+		return data, nil
+	}
+	sealed, err := cipher.Seal(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encrypt: %w", err)
+	}
+	record := make([]byte, len(sealedPrefix)+base64.StdEncoding.EncodedLen(len(sealed)))
+	copy(record, sealedPrefix)
+	base64.StdEncoding.Encode(record[len(sealedPrefix):], sealed)
+	return record, nil
+}
+
+// openRecord decrypts a record written by sealRecord. Plaintext records are
+// returned as is.
+func openRecord(cipher secrets.Sealer, record []byte) ([]byte, error) {
+	if !bytes.HasPrefix(record, sealedPrefix) {
+		return record, nil
+	}
+	if cipher == nil {
+		return nil, ErrEncrypted
+	}
+	sealed, err := base64.StdEncoding.DecodeString(string(record[len(sealedPrefix):]))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode encrypted record: %w", err)
+	}
+	return cipher.Open(sealed)
+}
+
+// DecryptRecords copies the journal or snapshot read from r to w with its
+// encrypted records decrypted.
+func DecryptRecords(cipher secrets.Sealer, r io.Reader, w io.Writer) error {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 10*1024*1024)
+	for line := 1; scanner.Scan(); line++ {
+		data, err := openRecord(cipher, scanner.Bytes())
+		if err != nil {
+			return fmt.Errorf("line %d: %w", line, err)
+		}
+		if _, err := w.Write(append(data, '\n')); err != nil {
+			return err
+		}
+	}
+	return scanner.Err()
+}
+
+// EncryptRecords copies the journal read from r to w with its plaintext
+// records encrypted by cipher. Records already encrypted are copied as is.
+func EncryptRecords(cipher secrets.Sealer, r io.Reader, w io.Writer) error {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 10*1024*1024)
+	for line := 1; scanner.Scan(); line++ {
+		record := scanner.Bytes()
+		if len(bytes.TrimSpace(record)) == 0 {
+			continue
+		}
+		if !bytes.HasPrefix(record, sealedPrefix) {
+			sealed, err := sealRecord(cipher, record)
+			if err != nil {
+				return fmt.Errorf("line %d: %w", line, err)
+			}
+			record = sealed
+		}
+		if _, err := w.Write(append(record, '\n')); err != nil {
+			return err
+		}
+	}
+	return scanner.Err()
+}
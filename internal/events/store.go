@@ -1,23 +1,40 @@
 package events
 
 import (
+	"bufio"
+	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
+	"io/fs"
+	"iter"
 	"log/slog"
 	"os"
 	"path/filepath"
 	"sync"
 	"time"
+
+	"github.com/SamyRai/juleson/internal/secrets"
 )
 
 // EventStore provides event persistence and replay capabilities.
 // It stores events on disk for audit trails, debugging, and system recovery.
 type EventStore struct {
-	events        []StoredEvent
+	events []StoredEvent
+	// base is how many events were trimmed or cleared from the front of
+	// events since the store was loaded, so cursors stay valid.
+	base  int64
+	index storeIndex
+	// loaded is false until a journal is loaded into memory, which waits
+	// for the first method that needs it; streaming reads never do.
+	loaded        bool
 	mu            sync.RWMutex
 	logger        *slog.Logger
 	storageDir    string
+	journalPath   string
+	cipher        secrets.Sealer
 	maxEvents     int
 	autoFlush     bool
 	flushInterval time.Duration
@@ -34,7 +51,15 @@ type StoredEvent struct {
 
 // EventStoreConfig configures the event store
 type EventStoreConfig struct {
-	StorageDir    string
+	StorageDir string
+	// JournalPath, when set, makes the store append-only: every stored event
+	// is appended to this JSONL file immediately. The journal, not the
+	// snapshot files, is loaded on the first query, and ReadFrom, Events,
+	// and Replay stream it from disk without loading it.
+	JournalPath string
+	// Cipher, when set, encrypts the journal and snapshot files. Records
+	// written without it are still read.
+	Cipher        secrets.Sealer
 	MaxEvents     int
 	AutoFlush     bool
 	FlushInterval time.Duration
@@ -68,15 +93,27 @@ func NewEventStore(config *EventStoreConfig, logger *slog.Logger) (*EventStore,
 		events:        make([]StoredEvent, 0),
 		logger:        logger,
 		storageDir:    config.StorageDir,
+		journalPath:   config.JournalPath,
+		cipher:        config.Cipher,
 		maxEvents:     config.MaxEvents,
 		autoFlush:     config.AutoFlush,
 		flushInterval: config.FlushInterval,
 		stopChan:      make(chan struct{}),
 	}
 
-	// Load existing events
-	if err := es.load(); err != nil {
-		logger.Warn("failed to load existing events", "error", err)
+	// Load existing events. Encrypted events without a cipher fail the
+	// store rather than hide them, or mix plaintext into an encrypted one.
+	if es.journalPath != "" {
+		if err := es.checkJournal(); errors.Is(err, ErrEncrypted) {
+			return nil, err
+		} else if err != nil {
+			logger.Warn("failed to read event journal", "error", err)
+		}
+	} else {
+		es.loaded = true
+		if err := es.load(); err != nil {
+			logger.Warn("failed to load existing events", "error", err)
+		}
 	}
 
 	// Start auto-flush if enabled
@@ -93,41 +130,102 @@ func (es *EventStore) Store(event Event) error {
 	es.mu.Lock()
 	defer es.mu.Unlock()
 
-	storedEvent := StoredEvent{
-		Event:    event,
-		StoredAt: time.Now(),
-		Sequence: int64(len(es.events) + 1),
+	if es.journalPath != "" {
+		if err := es.appendJournal(event); err != nil {
+			return err
+		}
+	}
+	es.appendLocked(event)
+
+	es.logger.Debug("event stored",
+		"event_id", event.ID,
+		"event_type", event.Type)
+
+	return nil
+}
+
+// AppendBatch stores events in order with one lock acquisition, one
+// journal write, and one trim, so high-frequency producers do not pay the
+// per-event overhead of Store. When an event cannot be encoded, none is
+// stored.
+func (es *EventStore) AppendBatch(events []Event) error {
+	if len(events) == 0 {
+		return nil
+	}
+
+	es.mu.Lock()
+	defer es.mu.Unlock()
+
+	if es.journalPath != "" {
+		if err := es.appendJournal(events...); err != nil {
+			return err
+		}
 	}
+	es.appendLocked(events...)
+
+	es.logger.Debug("event batch stored", "count", len(events))
+	return nil
+}
 
-	es.events = append(es.events, storedEvent)
+// appendLocked adds events to memory, unless the journal is not loaded yet,
+// and trims the oldest beyond maxEvents. es.mu must be held.
+func (es *EventStore) appendLocked(events ...Event) {
+	if !es.loaded {
+		return
+	}
+	now := time.Now()
+	for _, event := range events {
+		es.events = append(es.events, StoredEvent{
+			Event:    event,
+			StoredAt: now,
+			Sequence: int64(len(es.events) + 1),
+		})
+		es.index.add(len(es.events)-1, event)
+	}
 
 	// Trim if exceeds max
 	if es.maxEvents > 0 && len(es.events) > es.maxEvents {
 		// Keep most recent events
-		es.events = es.events[len(es.events)-es.maxEvents:]
+		trimmed := len(es.events) - es.maxEvents
+		es.events = es.events[trimmed:]
+		es.base += int64(trimmed)
 		// Renumber sequences
 		for i := range es.events {
 			es.events[i].Sequence = int64(i + 1)
 		}
+		es.index.rebuild(es.events)
 	}
+}
 
-	es.logger.Debug("event stored",
-		"event_id", event.ID,
-		"event_type", event.Type,
-		"sequence", storedEvent.Sequence)
+// ensureLoaded loads the journal into memory on first use by a method
+// that queries the events in memory.
+func (es *EventStore) ensureLoaded() {
+	es.mu.RLock()
+	loaded := es.loaded
+	es.mu.RUnlock()
+	if loaded {
+		return
+	}
 
-	return nil
+	es.mu.Lock()
+	defer es.mu.Unlock()
+	if es.loaded {
+		return
+	}
+	es.loaded = true
+	if err := es.loadJournal(); err != nil {
+		es.logger.Warn("failed to load existing events", "error", err)
+	}
 }
 
 // Get retrieves an event by ID
 func (es *EventStore) Get(eventID string) (*StoredEvent, error) {
+	es.ensureLoaded()
 	es.mu.RLock()
 	defer es.mu.RUnlock()
 
-	for i := range es.events {
-		if es.events[i].ID == eventID {
-			return &es.events[i], nil
-		}
+	if i, ok := es.index.byID[eventID]; ok {
+		return &es.events[i], nil
 	}
 
 	return nil, fmt.Errorf("event not found: %s", eventID)
@@ -135,14 +233,13 @@ func (es *EventStore) Get(eventID string) (*StoredEvent, error) {
 
 // GetByType retrieves all events of a specific type
 func (es *EventStore) GetByType(eventType EventType) []StoredEvent {
+	es.ensureLoaded()
 	es.mu.RLock()
 	defer es.mu.RUnlock()
 
-	result := make([]StoredEvent, 0)
-	for _, event := range es.events {
-		if event.Type == eventType {
-			result = append(result, event)
-		}
+	result := make([]StoredEvent, 0, len(es.index.byType[eventType]))
+	for _, i := range es.index.byType[eventType] {
+		result = append(result, es.events[i])
 	}
 
 	return result
@@ -150,6 +247,7 @@ func (es *EventStore) GetByType(eventType EventType) []StoredEvent {
 
 // GetByTimeRange retrieves events within a time range
 func (es *EventStore) GetByTimeRange(start, end time.Time) []StoredEvent {
+	es.ensureLoaded()
 	es.mu.RLock()
 	defer es.mu.RUnlock()
 
@@ -165,6 +263,7 @@ func (es *EventStore) GetByTimeRange(start, end time.Time) []StoredEvent {
 
 // GetRecent retrieves the most recent N events
 func (es *EventStore) GetRecent(count int) []StoredEvent {
+	es.ensureLoaded()
 	es.mu.RLock()
 	defer es.mu.RUnlock()
 
@@ -179,33 +278,102 @@ func (es *EventStore) GetRecent(count int) []StoredEvent {
 	return result
 }
 
-// Replay replays events by calling a handler for each event
-func (es *EventStore) Replay(ctx context.Context, handler func(Event) error) error {
-	es.mu.RLock()
-	eventsCopy := make([]StoredEvent, len(es.events))
-	copy(eventsCopy, es.events)
-	es.mu.RUnlock()
+// Cursor is a position in an event store, returned by ReadFrom to resume
+// reading after the events already read. Cursors stay valid while older
+// events are trimmed. The zero Cursor is the oldest stored event. The
+// cursors of a store with a journal are byte offsets in it, which
+// compacting the journal invalidates.
+type Cursor int64
+
+// ErrCursorExpired is returned when the events at a cursor were trimmed or
+// cleared from the store before they were read.
+var ErrCursorExpired = errors.New("events at cursor are no longer stored")
+
+// readBatchSize is how many events Events copies per lock acquisition.
+const readBatchSize = 1000
+
+// ReadFrom returns up to limit events from cursor on, oldest first, and the
+// cursor of the event after the last one returned. A limit of zero or less
+// reads DefaultQueryLimit events. A store with a journal reads the page
+// from disk, so the journal is never loaded whole; those events are all in
+// the journal regardless of MaxEvents, and their Sequence is not set.
+func (es *EventStore) ReadFrom(cursor Cursor, limit int) ([]StoredEvent, Cursor, error) {
+	if limit <= 0 {
+		limit = DefaultQueryLimit
+	}
+	if es.journalPath != "" {
+		return es.readJournal(cursor, limit)
+	}
 
-	es.logger.Info("replaying events", "count", len(eventsCopy))
+	es.mu.RLock()
+	defer es.mu.RUnlock()
+	start := int64(cursor) - es.base
+	if cursor == 0 {
+		start = 0
+	}
+	if start < 0 {
+		return nil, cursor, fmt.Errorf("%w: %d event(s) trimmed", ErrCursorExpired, -start)
+	}
+	start = min(start, int64(len(es.events)))
+	end := min(start+int64(limit), int64(len(es.events)))
+	page := append([]StoredEvent(nil), es.events[start:end]...)
+	return page, Cursor(es.base + end), nil
+}
 
-	for i, storedEvent := range eventsCopy {
-		select {
-		case <-ctx.Done():
-			return fmt.Errorf("replay cancelled: %w", ctx.Err())
-		default:
+// Events iterates over the events from cursor on, oldest first, including
+// events stored during the iteration. It reads readBatchSize events at a
+// time, so replaying a large store holds neither the lock nor a copy of
+// every event. Iteration stops after yielding an error.
+func (es *EventStore) Events(ctx context.Context, cursor Cursor) iter.Seq2[StoredEvent, error] {
+	return func(yield func(StoredEvent, error) bool) {
+		for {
+			if err := ctx.Err(); err != nil {
+				yield(StoredEvent{}, err)
+				return
+			}
+			page, next, err := es.ReadFrom(cursor, readBatchSize)
+			if err != nil {
+				yield(StoredEvent{}, err)
+				return
+			}
+			for _, stored := range page {
+				if !yield(stored, nil) {
+					return
+				}
+			}
+			if len(page) < readBatchSize {
+				return
+			}
+			cursor = next
 		}
+	}
+}
 
-		if err := handler(storedEvent.Event); err != nil {
-			return fmt.Errorf("replay failed at event %d: %w", i, err)
+// Replay replays events by calling a handler for each event
+func (es *EventStore) Replay(ctx context.Context, handler func(Event) error) error {
+	es.logger.Info("replaying events")
+
+	replayed := 0
+	for stored, err := range es.Events(ctx, 0) {
+		if err != nil {
+			if ctx.Err() != nil {
+				return fmt.Errorf("replay cancelled: %w", err)
+			}
+			return fmt.Errorf("replay failed at event %d: %w", replayed, err)
 		}
+		if err := handler(stored.Event); err != nil {
+			return fmt.Errorf("replay failed at event %d: %w", replayed, err)
+		}
+		replayed++
 	}
 
-	es.logger.Info("replay complete", "events_replayed", len(eventsCopy))
+	es.logger.Info("replay complete", "events_replayed", replayed)
 	return nil
 }
 
 // Flush writes events to disk
 func (es *EventStore) Flush() error {
+	es.ensureLoaded()
 	es.mu.RLock()
 	eventsCopy := make([]StoredEvent, len(es.events))
 	copy(eventsCopy, es.events)
@@ -221,8 +389,16 @@ func (es *EventStore) Flush() error {
 	if err != nil {
 		return fmt.Errorf("failed to marshal events: %w", err)
 	}
+	if data, err = sealRecord(es.cipher, data); err != nil {
+		return fmt.Errorf("failed to write events to file: %w", err)
+	}
 
-	if err := os.WriteFile(filename, data, 0644); err != nil {
+	// Encrypted snapshots are kept private like the journal.
+	perm := os.FileMode(0644)
+	if es.cipher != nil {
+		perm = 0o600
+	}
+	if err := os.WriteFile(filename, data, perm); err != nil {
 		return fmt.Errorf("failed to write events to file: %w", err)
 	}
 
@@ -233,7 +409,174 @@ func (es *EventStore) Flush() error {
 	return nil
 }
 
-// load loads events from the most recent file
+// appendJournal writes events as JSON lines in a single write. The file is
+// opened per write, under the journal lock, so several processes can share
+// a journal and CompactJournal never drops an append.
+func (es *EventStore) appendJournal(events ...Event) error {
+	var lines []byte
+	for _, event := range events {
+		line, err := json.Marshal(event)
+		if err != nil {
+			return fmt.Errorf("failed to marshal event: %w", err)
+		}
+		if line, err = sealRecord(es.cipher, line); err != nil {
+			return fmt.Errorf("failed to append to event journal: %w", err)
+		}
+		lines = append(append(lines, line...), '\n')
+	}
+	unlock, err := lockJournal(es.journalPath)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+	file, err := os.OpenFile(es.journalPath, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o600)
+	if err != nil {
+		return fmt.Errorf("failed to open event journal: %w", err)
+	}
+	if _, err := file.Write(lines); err != nil {
+		file.Close()
+		return fmt.Errorf("failed to append to event journal: %w", err)
+	}
+	return file.Close()
+}
+
+// readJournal returns up to limit events of the journal from the byte
+// offset cursor on.
+func (es *EventStore) readJournal(cursor Cursor, limit int) ([]StoredEvent, Cursor, error) {
+	var page []StoredEvent
+	next := cursor
+	err := es.scanJournal(cursor, func(stored StoredEvent, after Cursor) bool {
+		page = append(page, stored)
+		next = after
+		return len(page) < limit
+	})
+	return page, next, err
+}
+
+// scanJournal calls fn with each event of the journal from the byte offset
+// cursor on, and the cursor after it, until fn returns false. A last line
+// without a newline is still being appended and is left for later.
+func (es *EventStore) scanJournal(cursor Cursor, fn func(StoredEvent, Cursor) bool) error {
+	file, err := os.Open(es.journalPath)
+	if errors.Is(err, fs.ErrNotExist) && cursor == 0 {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to open event journal: %w", err)
+	}
+	defer file.Close()
+	info, err := file.Stat()
+	if err != nil {
+		return fmt.Errorf("failed to open event journal: %w", err)
+	}
+	if int64(cursor) > info.Size() {
+		return fmt.Errorf("%w: the journal was compacted", ErrCursorExpired)
+	}
+	if _, err := file.Seek(int64(cursor), io.SeekStart); err != nil {
+		return fmt.Errorf("failed to read event journal: %w", err)
+	}
+
+	reader := bufio.NewReaderSize(file, 64*1024)
+	offset := int64(cursor)
+	for {
+		line, err := reader.ReadBytes('\n')
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("failed to read event journal: %w", err)
+		}
+		start := offset
+		offset += int64(len(line))
+		record := bytes.TrimSpace(line)
+		if len(record) == 0 {
+			continue
+		}
+		event, err := es.decodeRecord(record)
+		if err != nil {
+			return fmt.Errorf("event journal offset %d: %w", start, err)
+		}
+		if !fn(StoredEvent{Event: event, StoredAt: event.Timestamp}, Cursor(offset)) {
+			return nil
+		}
+	}
+}
+
+// decodeRecord decodes and upgrades the event of a journal record.
+func (es *EventStore) decodeRecord(record []byte) (Event, error) {
+	data, err := openRecord(es.cipher, record)
+	if err != nil {
+		return Event{}, err
+	}
+	var event Event
+	if err := json.Unmarshal(data, &event); err != nil {
+		return Event{}, fmt.Errorf("failed to parse event: %w", err)
+	}
+	return upgradeEvent(event)
+}
+
+// checkJournal returns ErrEncrypted when the journal has encrypted records
+// and the store no cipher. It reads the journal without keeping it.
+func (es *EventStore) checkJournal() error {
+	if es.cipher != nil {
+		return nil
+	}
+	file, err := os.Open(es.journalPath)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to open event journal: %w", err)
+	}
+	defer file.Close()
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 64*1024), 10*1024*1024)
+	for scanner.Scan() {
+		if bytes.HasPrefix(scanner.Bytes(), sealedPrefix) {
+			return ErrEncrypted
+		}
+	}
+	return scanner.Err()
+}
+
+// loadJournal loads the journal, keeping the most recent maxEvents in
+// memory. Older events are dropped while reading, so a journal of millions
+// of events never holds more than twice maxEvents at once. es.mu must be
+// held, so no event is stored between reading the journal and keeping it.
+func (es *EventStore) loadJournal() error {
+	var (
+		events  []StoredEvent
+		dropped int64
+	)
+	err := es.scanJournal(0, func(stored StoredEvent, _ Cursor) bool {
+		events = append(events, stored)
+		if es.maxEvents > 0 && len(events) >= 2*es.maxEvents {
+			dropped += int64(len(events) - es.maxEvents)
+			events = append(events[:0], events[len(events)-es.maxEvents:]...)
+		}
+		return true
+	})
+	if err != nil {
+		return err
+	}
+
+	if es.maxEvents > 0 && len(events) > es.maxEvents {
+		dropped += int64(len(events) - es.maxEvents)
+		events = events[len(events)-es.maxEvents:]
+	}
+	for i := range events {
+		events[i].Sequence = int64(i + 1)
+	}
+
+	es.events = events
+	es.base = dropped
+	es.index.rebuild(events)
+	return nil
+}
+
+// load loads events from the most recent snapshot file of a store without
+// a journal. Loaded events are migrated to the current schema version and
+// their data decoded into typed payloads.
 func (es *EventStore) load() error {
 	files, err := filepath.Glob(filepath.Join(es.storageDir, "events_*.json"))
 	if err != nil {
@@ -252,14 +595,25 @@ func (es *EventStore) load() error {
 	if err != nil {
 		return fmt.Errorf("failed to read event file: %w", err)
 	}
+	if data, err = openRecord(es.cipher, data); err != nil {
+		return fmt.Errorf("event file %s: %w", mostRecent, err)
+	}
 
 	var events []StoredEvent
 	if err := json.Unmarshal(data, &events); err != nil {
 		return fmt.Errorf("failed to unmarshal events: %w", err)
 	}
+	for i := range events {
+		event, err := upgradeEvent(events[i].Event)
+		if err != nil {
+			return err
+		}
+		events[i].Event = event
+	}
 
 	es.mu.Lock()
 	es.events = events
+	es.index.rebuild(events)
 	es.mu.Unlock()
 
 	es.logger.Info("events loaded from disk",
@@ -294,14 +648,18 @@ func (es *EventStore) autoFlushLoop() {
 
 // Clear clears all events from the store
 func (es *EventStore) Clear() {
+	es.ensureLoaded()
 	es.mu.Lock()
 	defer es.mu.Unlock()
+	es.base += int64(len(es.events))
 	es.events = make([]StoredEvent, 0)
+	es.index.rebuild(nil)
 	es.logger.Info("event store cleared")
 }
 
 // Count returns the total number of events in the store
 func (es *EventStore) Count() int {
+	es.ensureLoaded()
 	es.mu.RLock()
 	defer es.mu.RUnlock()
 	return len(es.events)
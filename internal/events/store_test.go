@@ -2,10 +2,13 @@ package events
 
 import (
 	"context"
+	"fmt"
+	"os"
 	"path/filepath"
 	"testing"
 	"time"
 
+	"github.com/SamyRai/juleson/internal/secrets"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -80,3 +83,245 @@ func TestEventStore_Replay(t *testing.T) {
 	require.NoError(t, err)
 	assert.Len(t, replayed, 2)
 }
+
+func TestEventStore_Journal(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.jsonl")
+
+	store, err := OpenAuditLog(path, nil, nil)
+	require.NoError(t, err)
+
+	old := NewAuditEvent("cli", AuditData{Action: "session.create", Target: "sessions/1", Success: true})
+	old.Timestamp = time.Now().Add(-48 * time.Hour)
+	require.NoError(t, store.Store(old))
+	require.NoError(t, store.Store(NewAuditEvent("mcp", AuditData{Action: "patch.apply", Target: "sessions/1", Error: "conflict"})))
+	require.NoError(t, store.Shutdown(context.Background()))
+
+	reopened, err := OpenAuditLog(path, nil, nil)
+	require.NoError(t, err)
+	assert.Equal(t, 2, reopened.Count())
+
+	entries := AuditEntries(reopened, time.Now().Add(-time.Hour))
+	require.Len(t, entries, 1)
+	assert.Equal(t, "mcp", entries[0].Source)
+
+	data, err := DecodeAuditData(entries[0].Event)
+	require.NoError(t, err)
+	assert.Equal(t, AuditData{Action: "patch.apply", Target: "sessions/1", Error: "conflict"}, data)
+
+	require.NoError(t, reopened.Store(NewAuditEvent("cli", AuditData{Action: "session.delete", Success: true})))
+	again, err := OpenAuditLog(path, nil, nil)
+	require.NoError(t, err)
+	assert.Equal(t, 3, again.Count(), "journal entries are appended, not rewritten")
+}
+
+func TestEventStore_EncryptedJournal(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.jsonl")
+	cipher, err := secrets.NewCipher(make([]byte, 32))
+	require.NoError(t, err)
+
+	plain, err := OpenAuditLog(path, nil, nil)
+	require.NoError(t, err)
+	require.NoError(t, plain.Store(NewAuditEvent("cli", AuditData{Action: "session.create", Target: "sessions/1"})))
+
+	sealed, err := OpenAuditLog(path, cipher, nil)
+	require.NoError(t, err)
+	require.NoError(t, sealed.Store(NewAuditEvent("cli", AuditData{Action: "patch.apply", Target: "sessions/secret-2"})))
+
+	raw, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Contains(t, string(raw), "sessions/1", "entries written before encryption stay readable")
+	assert.NotContains(t, string(raw), "secret-2")
+
+	reopened, err := OpenAuditLog(path, cipher, nil)
+	require.NoError(t, err)
+	entries := AuditEntries(reopened, time.Time{})
+	require.Len(t, entries, 2)
+	data, err := DecodeAuditData(entries[1].Event)
+	require.NoError(t, err)
+	assert.Equal(t, "sessions/secret-2", data.Target)
+
+	_, err = OpenAuditLog(path, nil, nil)
+	assert.ErrorIs(t, err, ErrEncrypted)
+}
+
+func TestEventStore_EncryptedSnapshotIsPrivate(t *testing.T) {
+	dir := t.TempDir()
+	cipher, err := secrets.NewCipher(make([]byte, 32))
+	require.NoError(t, err)
+	store, err := NewEventStore(&EventStoreConfig{StorageDir: dir, Cipher: cipher}, nil)
+	require.NoError(t, err)
+	require.NoError(t, store.Store(NewEvent(EventSystemStarted, "source", nil)))
+	require.NoError(t, store.Flush())
+
+	snapshots, err := filepath.Glob(filepath.Join(dir, "events_*.json"))
+	require.NoError(t, err)
+	require.Len(t, snapshots, 1)
+	info, err := os.Stat(snapshots[0])
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0o600), info.Mode().Perm())
+}
+
+func TestEventStore_AppendBatch(t *testing.T) {
+	dir := t.TempDir()
+	config := &EventStoreConfig{StorageDir: dir, MaxEvents: 3, JournalPath: filepath.Join(dir, "events.jsonl")}
+	store, err := NewEventStore(config, nil)
+	require.NoError(t, err)
+
+	var batch []Event
+	for i := range 5 {
+		batch = append(batch, NewEvent(EventSystemStarted, fmt.Sprintf("source-%d", i), nil))
+	}
+	require.NoError(t, store.AppendBatch(batch))
+	require.NoError(t, store.AppendBatch(nil))
+
+	events := store.GetRecent(0)
+	require.Len(t, events, 3, "the batch is trimmed to MaxEvents")
+	assert.Equal(t, batch[2].ID, events[0].ID)
+	assert.Equal(t, int64(1), events[0].Sequence)
+	_, err = store.Get(batch[0].ID)
+	assert.Error(t, err)
+
+	journaled, _, err := store.ReadFrom(0, 0)
+	require.NoError(t, err)
+	assert.Len(t, journaled, 5, "the journal keeps the whole batch")
+
+	reopened, err := NewEventStore(config, nil)
+	require.NoError(t, err)
+	assert.Equal(t, 3, reopened.Count())
+	latest, err := reopened.Get(batch[4].ID)
+	require.NoError(t, err)
+	assert.Equal(t, "source-4", latest.Source)
+}
+
+func TestEventStore_Cursor(t *testing.T) {
+	store, err := NewEventStore(&EventStoreConfig{StorageDir: t.TempDir(), MaxEvents: 4}, nil)
+	require.NoError(t, err)
+	for i := range 3 {
+		require.NoError(t, store.Store(NewEvent(EventSystemStarted, fmt.Sprintf("source-%d", i), nil)))
+	}
+
+	page, cursor, err := store.ReadFrom(0, 2)
+	require.NoError(t, err)
+	require.Len(t, page, 2)
+	assert.Equal(t, "source-1", page[1].Source)
+
+	// Trimming the oldest event leaves the cursor pointing at source-2.
+	for i := 3; i < 6; i++ {
+		require.NoError(t, store.Store(NewEvent(EventSystemStarted, fmt.Sprintf("source-%d", i), nil)))
+	}
+	page, cursor, err = store.ReadFrom(cursor, 10)
+	require.NoError(t, err)
+	require.Len(t, page, 4)
+	assert.Equal(t, "source-2", page[0].Source)
+
+	page, _, err = store.ReadFrom(cursor, 10)
+	require.NoError(t, err)
+	assert.Empty(t, page)
+
+	_, _, err = store.ReadFrom(1, 10)
+	assert.ErrorIs(t, err, ErrCursorExpired)
+
+	store.Clear()
+	require.NoError(t, store.Store(NewEvent(EventSystemStarted, "source-6", nil)))
+	page, _, err = store.ReadFrom(cursor, 10)
+	require.NoError(t, err)
+	require.Len(t, page, 1, "a cursor at the end of a cleared store reads new events")
+	assert.Equal(t, "source-6", page[0].Source)
+}
+
+func TestEventStore_Events(t *testing.T) {
+	store, err := NewEventStore(&EventStoreConfig{StorageDir: t.TempDir()}, nil)
+	require.NoError(t, err)
+	total := readBatchSize + 10
+	batch := make([]Event, total)
+	for i := range batch {
+		batch[i] = NewEvent(EventSystemStarted, "source", nil)
+	}
+	require.NoError(t, store.AppendBatch(batch))
+
+	seen := 0
+	for stored, err := range store.Events(context.Background(), 0) {
+		require.NoError(t, err)
+		if seen == 0 {
+			// Events stored during the iteration are included.
+			require.NoError(t, store.Store(NewEvent(EventSystemStopping, "source", nil)))
+		}
+		seen++
+		if seen == total+1 {
+			assert.Equal(t, EventSystemStopping, stored.Type)
+		}
+	}
+	assert.Equal(t, total+1, seen)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	for _, err := range store.Events(ctx, 0) {
+		assert.ErrorIs(t, err, context.Canceled)
+	}
+}
+
+func TestEventStore_LoadLargeJournal(t *testing.T) {
+	dir := t.TempDir()
+	config := &EventStoreConfig{StorageDir: dir, JournalPath: filepath.Join(dir, "events.jsonl")}
+	writer, err := NewEventStore(config, nil)
+	require.NoError(t, err)
+	batch := make([]Event, 50)
+	for i := range batch {
+		batch[i] = NewEvent(EventSystemStarted, fmt.Sprintf("source-%d", i), nil)
+	}
+	require.NoError(t, writer.AppendBatch(batch))
+
+	config.MaxEvents = 7
+	reader, err := NewEventStore(config, nil)
+	require.NoError(t, err)
+	recent := reader.GetRecent(0)
+	require.Len(t, recent, 7)
+	assert.Equal(t, "source-43", recent[0].Source)
+	assert.Equal(t, "source-49", recent[6].Source)
+
+	// Reads stream the whole journal, page by page, from byte offsets.
+	page, next, err := reader.ReadFrom(0, 20)
+	require.NoError(t, err)
+	require.Len(t, page, 20)
+	assert.Equal(t, "source-0", page[0].Source)
+	page, _, err = reader.ReadFrom(next, 0)
+	require.NoError(t, err)
+	require.Len(t, page, 30)
+	assert.Equal(t, "source-20", page[0].Source)
+
+	info, err := os.Stat(config.JournalPath)
+	require.NoError(t, err)
+	_, _, err = reader.ReadFrom(Cursor(info.Size()+1), 1)
+	assert.ErrorIs(t, err, ErrCursorExpired)
+}
+
+func TestEventStore_StreamJournal(t *testing.T) {
+	dir := t.TempDir()
+	config := &EventStoreConfig{StorageDir: dir, JournalPath: filepath.Join(dir, "events.jsonl")}
+	writer, err := NewEventStore(config, nil)
+	require.NoError(t, err)
+	batch := make([]Event, readBatchSize+250)
+	for i := range batch {
+		batch[i] = NewEvent(EventSystemStarted, fmt.Sprintf("source-%d", i), nil)
+	}
+	require.NoError(t, writer.AppendBatch(batch))
+
+	reader, err := NewEventStore(config, nil)
+	require.NoError(t, err)
+	seen := 0
+	for stored, err := range reader.Events(context.Background(), 0) {
+		require.NoError(t, err)
+		assert.Equal(t, batch[seen].ID, stored.ID)
+		seen++
+	}
+	assert.Equal(t, len(batch), seen)
+	require.NoError(t, reader.Store(NewEvent(EventSystemStopping, "source", nil)))
+
+	reader.mu.RLock()
+	loaded, held := reader.loaded, len(reader.events)
+	reader.mu.RUnlock()
+	assert.False(t, loaded, "iterating a journal does not load it")
+	assert.Zero(t, held)
+
+	assert.Equal(t, len(batch)+1, reader.Count(), "queries load it on first use")
+}
@@ -0,0 +1,201 @@
+package events
+
+import (
+	"encoding/json"
+	"fmt"
+	"path"
+	"strings"
+)
+
+// Severity ranks events for EventMatch filters.
+type Severity int
+
+// Event severities, lowest first.
+const (
+	SeverityInfo Severity = iota
+	SeverityWarning
+	SeverityError
+)
+
+// String returns the severity's name.
+func (s Severity) String() string {
+	switch s {
+	case SeverityWarning:
+		return "warning"
+	case SeverityError:
+		return "error"
+	default:
+		return "info"
+	}
+}
+
+// ParseSeverity parses "info", "warning", or "error".
+func ParseSeverity(value string) (Severity, error) {
+	switch strings.ToLower(value) {
+	case "info":
+		return SeverityInfo, nil
+	case "warning", "warn":
+		return SeverityWarning, nil
+	case "error":
+		return SeverityError, nil
+	default:
+		return SeverityInfo, fmt.Errorf("unknown severity %q (use info, warning, or error)", value)
+	}
+}
+
+// EventSeverity returns an event's severity: its "severity" metadata when
+// set, otherwise error for failure types (task.failed, agent.error,
+// review.rejected), warning for retries and cancellations, and info for the
+// rest.
+func EventSeverity(event Event) Severity {
+	if value, ok := event.Metadata["severity"].(string); ok {
+		if severity, err := ParseSeverity(value); err == nil {
+			return severity
+		}
+	}
+	eventType := string(event.Type)
+	switch {
+	case strings.HasSuffix(eventType, ".failed"), strings.HasSuffix(eventType, ".error"), strings.HasSuffix(eventType, ".rejected"):
+		return SeverityError
+	case strings.HasSuffix(eventType, ".retrying"), strings.HasSuffix(eventType, ".cancelled"), event.Type == EventCircuitBreakerOpened:
+		return SeverityWarning
+	default:
+		return SeverityInfo
+	}
+}
+
+// EventMatch filters events by the session and repository they concern and
+// by their severity. Empty fields match every event.
+type EventMatch struct {
+	// SessionID matches events whose data or metadata has this session_id.
+	SessionID string
+	// Repository matches events whose repository, or source_id, is this
+	// owner/name repository.
+	Repository string
+	// MinSeverity matches events at least this severe.
+	MinSeverity Severity
+}
+
+// ParseEventMatch parses a filter expression of space-separated key=value
+// terms, such as "session=123 repo=owner/name severity=error".
+func ParseEventMatch(expression string) (EventMatch, error) {
+	var match EventMatch
+	for _, term := range strings.Fields(expression) {
+		key, value, ok := strings.Cut(term, "=")
+		if !ok || value == "" {
+			return EventMatch{}, fmt.Errorf("invalid filter term %q: expected key=value", term)
+		}
+		switch key {
+		case "session":
+			match.SessionID = value
+		case "repo":
+			match.Repository = value
+		case "severity":
+			severity, err := ParseSeverity(value)
+			if err != nil {
+				return EventMatch{}, err
+			}
+			match.MinSeverity = severity
+		default:
+			return EventMatch{}, fmt.Errorf("unknown filter key %q (use session, repo, or severity)", key)
+		}
+	}
+	return match, nil
+}
+
+// Matches reports whether the event passes the filter.
+func (m EventMatch) Matches(event Event) bool {
+	if EventSeverity(event) < m.MinSeverity {
+		return false
+	}
+	if m.SessionID == "" && m.Repository == "" {
+		return true
+	}
+	fields := eventFields(event)
+	if m.SessionID != "" && fields.value("session_id") != m.SessionID {
+		return false
+	}
+	if m.Repository != "" && !matchesRepository(fields.value("repository"), m.Repository) &&
+		!matchesRepository(fields.value("source_id"), m.Repository) {
+		return false
+	}
+	return true
+}
+
+// matchesRepository reports whether value names repo, either directly or
+// as the end of a source name such as "sources/github/owner/name".
+func matchesRepository(value, repo string) bool {
+	return value != "" && (value == repo || strings.HasSuffix(value, "/"+repo))
+}
+
+// fieldSet looks up string fields in an event's metadata, then its data,
+// then the data's own metadata.
+type fieldSet []map[string]interface{}
+
+func eventFields(event Event) fieldSet {
+	fields := fieldSet{event.Metadata}
+	raw, err := json.Marshal(event.Data)
+	if err != nil {
+		return fields
+	}
+	var data map[string]interface{}
+	if json.Unmarshal(raw, &data) != nil {
+		return fields
+	}
+	fields = append(fields, data)
+	if metadata, ok := data["metadata"].(map[string]interface{}); ok {
+		fields = append(fields, metadata)
+	}
+	return fields
+}
+
+func (f fieldSet) value(key string) string {
+	for _, fields := range f {
+		if value, ok := fields[key].(string); ok && value != "" {
+			return value
+		}
+	}
+	return ""
+}
+
+// SessionKey returns the session ID an event concerns, for use as a
+// Subscriber OrderKey.
+func SessionKey(event Event) string {
+	return eventFields(event).value("session_id")
+}
+
+// validateTypePatterns checks that every event type pattern is well formed.
+func validateTypePatterns(patterns []string) error {
+	for _, pattern := range patterns {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return fmt.Errorf("invalid event type pattern %q: %w", pattern, err)
+		}
+	}
+	return nil
+}
+
+// matchesTypes reports whether an event type matches any of the patterns,
+// or whether there are none.
+func matchesTypes(patterns []string, eventType EventType) bool {
+	if len(patterns) == 0 {
+		return true
+	}
+	for _, pattern := range patterns {
+		if ok, _ := path.Match(pattern, string(eventType)); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// accepts reports whether the subscriber's types, match, and filter all
+// accept the event.
+func (s Subscriber) accepts(event Event) bool {
+	if !matchesTypes(s.Types, event.Type) {
+		return false
+	}
+	if s.Match != nil && !s.Match.Matches(event) {
+		return false
+	}
+	return s.Filter == nil || s.Filter(event)
+}
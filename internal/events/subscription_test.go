@@ -0,0 +1,119 @@
+package events
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEventBusTypePatternsAndMatch(t *testing.T) {
+	bus := NewEventBus(nil)
+	ctx := context.Background()
+
+	var mu sync.Mutex
+	received := make(map[string][]EventType)
+	record := func(id string) EventHandler {
+		return func(ctx context.Context, e Event) error {
+			mu.Lock()
+			received[id] = append(received[id], e.Type)
+			mu.Unlock()
+			return nil
+		}
+	}
+
+	require.NoError(t, bus.Subscribe(TopicAll, Subscriber{ID: "sessions", Types: []string{"session.*"}, Handler: record("sessions")}))
+	require.NoError(t, bus.Subscribe(TopicAll, Subscriber{ID: "failures", Types: []string{"task.failed", "session.failed"}, Handler: record("failures")}))
+	require.NoError(t, bus.Subscribe(TopicAll, Subscriber{ID: "s1", Match: &EventMatch{SessionID: "s1"}, Handler: record("s1")}))
+	require.NoError(t, bus.Subscribe(TopicAll, Subscriber{ID: "repo", Match: &EventMatch{Repository: "owner/name"}, Handler: record("repo")}))
+	require.NoError(t, bus.Subscribe(TopicAll, Subscriber{ID: "errors", Match: &EventMatch{MinSeverity: SeverityError}, Handler: record("errors")}))
+
+	publish := func(topic string, e Event) {
+		require.NoError(t, bus.Publish(ctx, topic, e))
+	}
+	publish(TopicSession, NewEvent(EventSessionCreated, "session", SessionEventData{SessionID: "s1", SourceID: "sources/github/owner/name"}))
+	publish(TopicSession, NewEvent(EventSessionFailed, "session", SessionEventData{SessionID: "s2"}))
+	publish(TopicTask, NewEvent(EventTaskFailed, "task", TaskEventData{TaskID: "t", Metadata: map[string]interface{}{"session_id": "s1"}}))
+	publish(TopicTask, NewEvent(EventTaskStarted, "task", TaskEventData{TaskID: "t"}))
+
+	mu.Lock()
+	defer mu.Unlock()
+	assert.Equal(t, []EventType{EventSessionCreated, EventSessionFailed}, received["sessions"])
+	assert.Equal(t, []EventType{EventSessionFailed, EventTaskFailed}, received["failures"])
+	assert.Equal(t, []EventType{EventSessionCreated, EventTaskFailed}, received["s1"])
+	assert.Equal(t, []EventType{EventSessionCreated}, received["repo"])
+	assert.Equal(t, []EventType{EventSessionFailed, EventTaskFailed}, received["errors"])
+}
+
+func TestEventBusRejectsInvalidTypePattern(t *testing.T) {
+	bus := NewEventBus(nil)
+	err := bus.Subscribe(TopicAll, Subscriber{ID: "bad", Types: []string{"session.["}, Handler: func(context.Context, Event) error { return nil }})
+	assert.ErrorContains(t, err, "invalid event type pattern")
+}
+
+func TestParseEventMatch(t *testing.T) {
+	match, err := ParseEventMatch("session=123 repo=owner/name severity=warning")
+	require.NoError(t, err)
+	assert.Equal(t, EventMatch{SessionID: "123", Repository: "owner/name", MinSeverity: SeverityWarning}, match)
+
+	_, err = ParseEventMatch("state=done")
+	assert.ErrorContains(t, err, "unknown filter key")
+	_, err = ParseEventMatch("severity=loud")
+	assert.ErrorContains(t, err, "unknown severity")
+}
+
+func TestEventBusOrderKeyDeliversSequentiallyPerKey(t *testing.T) {
+	bus := NewEventBus(nil)
+	ctx := context.Background()
+
+	started := make(chan string, 4)
+	release := make(chan struct{})
+	var mu sync.Mutex
+	var order []string
+	require.NoError(t, bus.Subscribe(TopicSession, Subscriber{
+		ID:       "ui",
+		OrderKey: SessionKey,
+		Handler: func(ctx context.Context, e Event) error {
+			data := e.Data.(SessionEventData)
+			started <- data.State
+			if data.SessionID == "s1" && data.State == "first" {
+				<-release
+			}
+			mu.Lock()
+			order = append(order, data.SessionID+"/"+data.State)
+			mu.Unlock()
+			return nil
+		},
+	}))
+
+	publish := func(session, state string) chan error {
+		done := make(chan error, 1)
+		go func() {
+			done <- bus.Publish(ctx, TopicSession, NewEvent(EventSessionUpdated, "session", SessionEventData{SessionID: session, State: state}))
+		}()
+		return done
+	}
+
+	first := publish("s1", "first")
+	assert.Equal(t, "first", <-started)
+	second := publish("s1", "second")
+
+	// Another session is not held up by s1.
+	require.NoError(t, <-publish("s2", "other"))
+	assert.Equal(t, "other", <-started)
+	select {
+	case state := <-started:
+		t.Fatalf("%s delivered before the first s1 event finished", state)
+	default:
+	}
+
+	close(release)
+	require.NoError(t, <-first)
+	require.NoError(t, <-second)
+
+	mu.Lock()
+	defer mu.Unlock()
+	assert.Equal(t, []string{"s2/other", "s1/first", "s1/second"}, order)
+}
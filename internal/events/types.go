@@ -2,22 +2,26 @@ package events
 
 import (
 	"encoding/json"
-	"fmt"
+	"strconv"
+	"sync/atomic"
 	"time"
 )
 
-// Event represents a generic event in the system
+// Event represents a generic event in the system. Data holds the payload
+// registered for Type (see RegisterPayload), and SchemaVersion the version
+// of that payload's schema.
 type Event struct {
-	ID        string                 `json:"id"`
-	Type      EventType              `json:"type"`
-	Topic     string                 `json:"topic"`
-	Source    string                 `json:"source"`
-	Timestamp time.Time              `json:"timestamp"`
-	Data      interface{}            `json:"data"`
-	Metadata  map[string]interface{} `json:"metadata,omitempty"`
-	Priority  int                    `json:"priority,omitempty"`
-	TTL       time.Duration          `json:"ttl,omitempty"`
-	Retries   int                    `json:"retries,omitempty"`
+	ID            string                 `json:"id"`
+	SchemaVersion int                    `json:"schema_version,omitempty"`
+	Type          EventType              `json:"type"`
+	Topic         string                 `json:"topic"`
+	Source        string                 `json:"source"`
+	Timestamp     time.Time              `json:"timestamp"`
+	Data          interface{}            `json:"data"`
+	Metadata      map[string]interface{} `json:"metadata,omitempty"`
+	Priority      int                    `json:"priority,omitempty"`
+	TTL           time.Duration          `json:"ttl,omitempty"`
+	Retries       int                    `json:"retries,omitempty"`
 }
 
 // EventType represents the type of event
@@ -52,6 +56,12 @@ const (
 	EventActivityProcessed EventType = "activity.processed"
 	EventPlanGenerated     EventType = "plan.generated"
 	EventPlanApproved      EventType = "plan.approved"
+	// EventPlanAwaitingApproval is emitted when a session stops for its
+	// plan to be approved.
+	EventPlanAwaitingApproval EventType = "plan.awaiting_approval"
+	// EventPlanAdapted is emitted when a saved plan template is adapted to
+	// a revised plan.
+	EventPlanAdapted EventType = "plan.adapted"
 
 	// Tool Events
 	EventToolInvoked   EventType = "tool.invoked"
@@ -84,6 +94,18 @@ const (
 	EventSystemStarted  EventType = "system.started"
 	EventSystemStopping EventType = "system.stopping"
 	EventSystemError    EventType = "system.error"
+	// EventCircuitBreakerOpened is emitted when a circuit breaker opens and
+	// starts rejecting calls.
+	EventCircuitBreakerOpened EventType = "system.circuit_breaker_opened"
+
+	// Config Events
+	EventConfigReloaded EventType = "config.reloaded"
+
+	// Audit Events
+	EventAuditRecorded EventType = "audit.recorded"
+
+	// Alert Events
+	EventAlertFired EventType = "alert.fired"
 )
 
 // Event Topics for pub/sub
@@ -98,6 +120,9 @@ const (
 	TopicOrchestration = "orchestration"
 	TopicGitHub        = "github"
 	TopicSystem        = "system"
+	TopicConfig        = "config"
+	TopicAudit         = "audit"
+	TopicAlert         = "alert"
 	TopicAll           = "*" // Subscribe to all events
 )
 
@@ -164,6 +189,23 @@ type ActivityEventData struct {
 	Metadata     map[string]interface{} `json:"metadata,omitempty"`
 }
 
+// PlanAdaptedData represents a plan template adapted to a revised plan
+type PlanAdaptedData struct {
+	SessionID      string               `json:"session_id"`
+	PlanID         string               `json:"plan_id"`
+	PreviousPlanID string               `json:"previous_plan_id"`
+	Template       string               `json:"template"`
+	Adaptations    []PlanAdaptationData `json:"adaptations"`
+}
+
+// PlanAdaptationData represents one task added, removed, moved, or edited
+// when adapting a plan template
+type PlanAdaptationData struct {
+	Action string `json:"action"`
+	Task   string `json:"task"`
+	Step   string `json:"step"`
+}
+
 // ToolEventData represents tool event data
 type ToolEventData struct {
 	ToolName   string                 `json:"tool_name"`
@@ -214,16 +256,54 @@ type GitHubEventData struct {
 	Error      string `json:"error,omitempty"`
 }
 
-// NewEvent creates a new event with default values
+// CircuitBreakerEventData represents circuit breaker state change event data
+type CircuitBreakerEventData struct {
+	Name          string        `json:"name"`
+	State         string        `json:"state"`
+	PreviousState string        `json:"previous_state"`
+	ResetTimeout  time.Duration `json:"reset_timeout,omitempty"`
+}
+
+// ConfigReloadedData represents config reload event data
+type ConfigReloadedData struct {
+	Path        string   `json:"path"`
+	ChangedKeys []string `json:"changed_keys"`
+	Applied     []string `json:"applied,omitempty"`
+	NeedRestart []string `json:"need_restart,omitempty"`
+}
+
+// AuditData represents one mutating operation recorded in the audit log
+type AuditData struct {
+	// Actor identifies who performed the action when the surface knows,
+	// such as the name of the MCP token that called a tool.
+	Actor   string                 `json:"actor,omitempty"`
+	Action  string                 `json:"action"`
+	Target  string                 `json:"target,omitempty"`
+	Success bool                   `json:"success"`
+	Error   string                 `json:"error,omitempty"`
+	Details map[string]interface{} `json:"details,omitempty"`
+}
+
+// AlertEventData represents an alert rule firing
+type AlertEventData struct {
+	Rule      string  `json:"rule"`
+	Message   string  `json:"message"`
+	Value     float64 `json:"value"`
+	Threshold float64 `json:"threshold"`
+}
+
+// NewEvent creates a new event with default values. Its Metadata is nil
+// until WithMetadata adds an entry.
 func NewEvent(eventType EventType, source string, data interface{}) Event {
+	now := time.Now()
 	return Event{
-		ID:        generateEventID(),
-		Type:      eventType,
-		Source:    source,
-		Timestamp: time.Now(),
-		Data:      data,
-		Metadata:  make(map[string]interface{}),
-		Priority:  0,
+		ID:            eventID(now),
+		SchemaVersion: CurrentSchemaVersion,
+		Type:          eventType,
+		Source:        source,
+		Timestamp:     now,
+		Data:          data,
+		Priority:      0,
 	}
 }
 
@@ -316,7 +396,21 @@ func (e *Event) UnmarshalJSON(data []byte) error {
 	return nil
 }
 
+// eventSequence makes event IDs generated in the same nanosecond unique.
+var eventSequence atomic.Uint64
+
 // generateEventID generates a unique event ID
 func generateEventID() string {
-	return fmt.Sprintf("evt_%d_%d", time.Now().UnixNano(), time.Now().Nanosecond())
+	return eventID(time.Now())
+}
+
+// eventID returns a unique ID of the form evt_<unix nanoseconds>_<sequence>
+// with a single allocation.
+func eventID(now time.Time) string {
+	var buf [48]byte
+	id := append(buf[:0], "evt_"...)
+	id = strconv.AppendInt(id, now.UnixNano(), 10)
+	id = append(id, '_')
+	id = strconv.AppendUint(id, eventSequence.Add(1), 10)
+	return string(id)
 }
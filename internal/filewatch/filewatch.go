@@ -0,0 +1,272 @@
+// Package filewatch watches a working tree for file changes and reports them
+// in debounced batches, so a burst of saves, a checkout, or a formatter
+// rewriting many files is handled once.
+package filewatch
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/SamyRai/juleson/internal/jules/workspace"
+	"github.com/fsnotify/fsnotify"
+)
+
+// DefaultDebounce is how long the tree must be quiet before a batch is
+// reported when Options leaves Debounce unset.
+const DefaultDebounce = 500 * time.Millisecond
+
+// DefaultIgnore are the names never watched: version control and dependency
+// directories, build output, and editor temporary files. A pattern matches
+// any element of a path.
+var DefaultIgnore = []string{
+	".git", ".hg", ".svn", ".juleson", "node_modules", "vendor", ".venv", "__pycache__",
+	"dist", "build", "coverage", ".idea", ".vscode", "*.swp", "*.swx", "*~", ".#*", "4913",
+}
+
+// Change types reported in Change.Type.
+const (
+	Created  = "created"
+	Modified = "modified"
+	Deleted  = "deleted"
+	Renamed  = "renamed"
+)
+
+// Change is a changed file, with its slash-separated path relative to the
+// watched root and when the change was last seen.
+type Change struct {
+	Path string    `json:"path"`
+	Type string    `json:"type"`
+	Time time.Time `json:"time"`
+}
+
+// Options configures a Watcher. Zero values use the defaults.
+type Options struct {
+	// Debounce is how long the tree must be quiet before a batch is
+	// reported.
+	Debounce time.Duration
+	// Include limits the reported files to those matching one of these
+	// patterns, as in workspace.MatchPath; empty reports every file.
+	Include []string
+	// Ignore are names skipped in addition to DefaultIgnore.
+	Ignore []string
+	Logger *slog.Logger
+}
+
+// Watcher watches every directory under a root that is not ignored,
+// including directories created while it runs.
+type Watcher struct {
+	root    string
+	options Options
+	ignore  []string
+}
+
+// New returns a watcher for the directory root.
+func New(root string, options Options) (*Watcher, error) {
+	abs, err := filepath.Abs(root)
+	if err != nil {
+		return nil, err
+	}
+	info, err := os.Stat(abs)
+	if err != nil {
+		return nil, err
+	}
+	if !info.IsDir() {
+		return nil, fmt.Errorf("%s is not a directory", root)
+	}
+	if options.Debounce <= 0 {
+		options.Debounce = DefaultDebounce
+	}
+	if options.Logger == nil {
+		options.Logger = slog.Default()
+	}
+	return &Watcher{
+		root:    abs,
+		options: options,
+		ignore:  append(append([]string(nil), DefaultIgnore...), options.Ignore...),
+	}, nil
+}
+
+// Root returns the absolute path of the watched directory.
+func (w *Watcher) Root() string {
+	return w.root
+}
+
+// Ignored reports whether a slash-separated path relative to the root is
+// skipped because one of its elements matches an ignore pattern.
+func (w *Watcher) Ignored(rel string) bool {
+	for _, element := range strings.Split(rel, "/") {
+		for _, pattern := range w.ignore {
+			if ok, err := path.Match(pattern, element); err == nil && ok {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Included reports whether a change to rel is reported.
+func (w *Watcher) Included(rel string) bool {
+	if rel == "." || w.Ignored(rel) {
+		return false
+	}
+	return len(w.options.Include) == 0 || workspace.MatchPath(w.options.Include, rel)
+}
+
+// Run watches the tree until ctx is cancelled, calling handle with each
+// batch of changes, sorted by path. handle runs on the watching goroutine,
+// so changes made while it runs are reported in the next batch.
+func (w *Watcher) Run(ctx context.Context, handle func([]Change)) error {
+	fsWatcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return fmt.Errorf("failed to create file watcher: %w", err)
+	}
+	defer fsWatcher.Close()
+
+	if err := w.addTree(fsWatcher, w.root); err != nil {
+		return err
+	}
+	w.options.Logger.Debug("watching working tree", "root", w.root)
+
+	pending := map[string]Change{}
+	var timer *time.Timer
+	flush := make(chan struct{}, 1)
+	defer func() {
+		if timer != nil {
+			timer.Stop()
+		}
+	}()
+	schedule := func() {
+		if timer != nil {
+			timer.Stop()
+		}
+		timer = time.AfterFunc(w.options.Debounce, func() {
+			select {
+			case flush <- struct{}{}:
+			default:
+			}
+		})
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case event, ok := <-fsWatcher.Events:
+			if !ok {
+				return nil
+			}
+			rel, err := filepath.Rel(w.root, event.Name)
+			if err != nil {
+				continue
+			}
+			rel = filepath.ToSlash(rel)
+			if w.Ignored(rel) {
+				continue
+			}
+			changeType := changeTypeOf(event.Op)
+			if changeType == "" {
+				continue
+			}
+			if changeType == Created {
+				if info, err := os.Stat(event.Name); err == nil && info.IsDir() {
+					// Files created in the directory before it was
+					// watched are reported as created.
+					w.addNewTree(fsWatcher, event.Name, pending)
+					schedule()
+					continue
+				}
+			}
+			if !w.Included(rel) {
+				continue
+			}
+			if pending[rel].Type == Created && changeType == Modified {
+				changeType = Created
+			}
+			pending[rel] = Change{Path: rel, Type: changeType, Time: time.Now()}
+			schedule()
+		case <-flush:
+			if len(pending) == 0 {
+				continue
+			}
+			changes := make([]Change, 0, len(pending))
+			for _, change := range pending {
+				changes = append(changes, change)
+			}
+			sort.Slice(changes, func(i, j int) bool { return changes[i].Path < changes[j].Path })
+			pending = map[string]Change{}
+			handle(changes)
+		case err, ok := <-fsWatcher.Errors:
+			if !ok {
+				return nil
+			}
+			w.options.Logger.Warn("file watcher error", "error", err)
+		}
+	}
+}
+
+// changeTypeOf maps an fsnotify operation to a change type, or "" for
+// permission changes, which are not reported.
+func changeTypeOf(op fsnotify.Op) string {
+	switch {
+	case op.Has(fsnotify.Create):
+		return Created
+	case op.Has(fsnotify.Remove):
+		return Deleted
+	case op.Has(fsnotify.Rename):
+		return Renamed
+	case op.Has(fsnotify.Write):
+		return Modified
+	}
+	return ""
+}
+
+// addTree watches dir and every directory below it that is not ignored.
+func (w *Watcher) addTree(fsWatcher *fsnotify.Watcher, dir string) error {
+	return filepath.WalkDir(dir, func(name string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			if name == dir {
+				return err
+			}
+			// The directory may be gone already.
+			return nil
+		}
+		if !entry.IsDir() {
+			return nil
+		}
+		rel, _ := filepath.Rel(w.root, name)
+		if rel != "." && w.Ignored(filepath.ToSlash(rel)) {
+			return filepath.SkipDir
+		}
+		if err := fsWatcher.Add(name); err != nil {
+			return fmt.Errorf("failed to watch %s: %w", name, err)
+		}
+		return nil
+	})
+}
+
+// addNewTree watches a directory created while running and records the
+// files already in it.
+func (w *Watcher) addNewTree(fsWatcher *fsnotify.Watcher, dir string, pending map[string]Change) {
+	if err := w.addTree(fsWatcher, dir); err != nil {
+		w.options.Logger.Warn("failed to watch new directory", "path", dir, "error", err)
+		return
+	}
+	_ = filepath.WalkDir(dir, func(name string, entry fs.DirEntry, err error) error {
+		if err != nil || entry.IsDir() {
+			return nil
+		}
+		rel, err := filepath.Rel(w.root, name)
+		if rel = filepath.ToSlash(rel); err == nil && w.Included(rel) {
+			pending[rel] = Change{Path: rel, Type: Created, Time: time.Now()}
+		}
+		return nil
+	})
+}
@@ -0,0 +1,98 @@
+package filewatch
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestIncluded(t *testing.T) {
+	w, err := New(t.TempDir(), Options{Include: []string{"*.go", "docs/"}, Ignore: []string{"testdata"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := map[string]bool{
+		"main.go":                   true,
+		"pkg/git/git.go":            true,
+		"docs/README.md":            true,
+		"README.md":                 false,
+		"vendor/x/y.go":             false,
+		"web/node_modules/a/b.go":   false,
+		"pkg/testdata/fixture.go":   false,
+		".git/index":                false,
+		"internal/.main.go.swp":     false,
+		"internal/config/config.go": true,
+	}
+	for rel, want := range tests {
+		if got := w.Included(rel); got != want {
+			t.Errorf("Included(%q) = %v, want %v", rel, got, want)
+		}
+	}
+}
+
+func TestRunDebouncesChanges(t *testing.T) {
+	root := t.TempDir()
+	if err := os.WriteFile(filepath.Join(root, "main.go"), []byte("package main\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Mkdir(filepath.Join(root, "node_modules"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	w, err := New(root, Options{Debounce: 200 * time.Millisecond})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	batches := make(chan []Change, 4)
+	done := make(chan error, 1)
+	go func() { done <- w.Run(ctx, func(changes []Change) { batches <- changes }) }()
+	// Give the watcher time to register the tree.
+	time.Sleep(200 * time.Millisecond)
+
+	for i := 0; i < 3; i++ {
+		if err := os.WriteFile(filepath.Join(root, "main.go"), []byte("package main\n// edit\n"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.WriteFile(filepath.Join(root, "node_modules", "dep.js"), []byte("x"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(filepath.Join(root, "pkg", "util"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(root, "pkg", "util", "util.go"), []byte("package util\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case changes := <-batches:
+		var got []string
+		for _, change := range changes {
+			got = append(got, change.Path+" "+change.Type)
+			if change.Time.IsZero() {
+				t.Errorf("%s has no time", change.Path)
+			}
+		}
+		want := []string{"main.go modified", "pkg/util/util.go created"}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("changes = %v, want %v", got, want)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("no batch reported")
+	}
+	select {
+	case changes := <-batches:
+		t.Errorf("unexpected second batch %+v", changes)
+	case <-time.After(400 * time.Millisecond):
+	}
+
+	cancel()
+	if err := <-done; err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,194 @@
+package gemini
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// Replay modes.
+const (
+	// ReplayRecord calls the API and records each response in the cassette.
+	ReplayRecord = "record"
+	// ReplayReplay serves every response from the cassette and never calls
+	// the API, so runs that prompt Gemini are reproducible.
+	ReplayReplay = "replay"
+)
+
+// ReplayModes are the valid values of Config.Replay besides "".
+var ReplayModes = []string{ReplayRecord, ReplayReplay}
+
+// DefaultCacheDir returns the directory for cached responses under the user
+// cache directory.
+func DefaultCacheDir() (string, error) {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "juleson", "gemini"), nil
+}
+
+// requestKey identifies a request by its endpoint, which names the model,
+// and its body, which holds the prompt, schema, and temperature. The API key
+// is sent in a header and is not part of it.
+func requestKey(path string, payload []byte) string {
+	sum := sha256.New()
+	sum.Write([]byte(path))
+	sum.Write([]byte{0})
+	sum.Write(payload)
+	return hex.EncodeToString(sum.Sum(nil))
+}
+
+type cachedResponse struct {
+	Created  time.Time       `json:"created"`
+	Response json.RawMessage `json:"response"`
+}
+
+// responseCache keeps responses in files named by their request key.
+type responseCache struct {
+	dir string
+	ttl time.Duration
+}
+
+// get returns the response stored for key, unless it is older than the TTL.
+func (c *responseCache) get(key string, now time.Time) (json.RawMessage, bool) {
+	data, err := os.ReadFile(filepath.Join(c.dir, key+".json"))
+	if err != nil {
+		return nil, false
+	}
+	var cached cachedResponse
+	if json.Unmarshal(data, &cached) != nil || now.Sub(cached.Created) > c.ttl {
+		return nil, false
+	}
+	return cached.Response, true
+}
+
+// put stores response for key. Caching is an optimization, so failures are
+// ignored.
+func (c *responseCache) put(key string, response json.RawMessage, now time.Time) {
+	data, err := json.Marshal(cachedResponse{Created: now, Response: response})
+	if err != nil {
+		return
+	}
+	_ = writeFileAtomic(filepath.Join(c.dir, key+".json"), data)
+}
+
+// Interaction is a request and its response, recorded in a cassette.
+type Interaction struct {
+	Key      string          `json:"key"`
+	Path     string          `json:"path"`
+	Request  json.RawMessage `json:"request"`
+	Response json.RawMessage `json:"response"`
+}
+
+type cassetteFile struct {
+	Interactions []Interaction `json:"interactions"`
+}
+
+// cassette is a file of recorded interactions.
+type cassette struct {
+	path string
+	mu   sync.Mutex
+	// loaded is set once the file has been read; err is the reason it
+	// could not be.
+	loaded       bool
+	err          error
+	interactions []Interaction
+}
+
+func (c *cassette) load() error {
+	if c.loaded {
+		return c.err
+	}
+	c.loaded = true
+	data, err := os.ReadFile(c.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		c.err = fmt.Errorf("failed to read cassette: %w", err)
+		return c.err
+	}
+	var file cassetteFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		c.err = fmt.Errorf("failed to decode cassette %s: %w", c.path, err)
+		return c.err
+	}
+	c.interactions = file.Interactions
+	return nil
+}
+
+// find returns the response recorded for key.
+func (c *cassette) find(key string) (json.RawMessage, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err := c.load(); err != nil {
+		return nil, err
+	}
+	for _, interaction := range c.interactions {
+		if interaction.Key == key {
+			return interaction.Response, nil
+		}
+	}
+	return nil, nil
+}
+
+// record adds interaction, replacing one recorded for the same request, and
+// writes the cassette.
+func (c *cassette) record(interaction Interaction) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err := c.load(); err != nil {
+		return err
+	}
+	replaced := false
+	for i := range c.interactions {
+		if c.interactions[i].Key == interaction.Key {
+			c.interactions[i] = interaction
+			replaced = true
+		}
+	}
+	if !replaced {
+		c.interactions = append(c.interactions, interaction)
+	}
+	data, err := json.MarshalIndent(cassetteFile{Interactions: c.interactions}, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := writeFileAtomic(c.path, append(data, '\n')); err != nil {
+		return fmt.Errorf("failed to write cassette: %w", err)
+	}
+	return nil
+}
+
+// writeFileAtomic writes data to a temporary file and renames it to path, so
+// readers never see a partial file.
+func writeFileAtomic(path string, data []byte) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,393 @@
+// Package gemini is a small client for the Gemini API, limited to prompts
+// answered with JSON that follows a schema and to text embeddings. Each call
+// site is routed to a chain of models, tried in order until one answers, and
+// the usage of each model is tallied. Responses
+// can be cached for reuse by identical requests, and recorded to or replayed
+// from a cassette file so runs that prompt Gemini are reproducible.
+package gemini
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// Defaults used when Config leaves a field empty.
+const (
+	DefaultModel          = "gemini-2.5-flash"
+	DefaultEmbeddingModel = "text-embedding-004"
+	DefaultBaseURL        = "https://generativelanguage.googleapis.com/v1beta"
+	DefaultTimeout        = 60 * time.Second
+)
+
+// maxEmbedBatch is the most texts one batchEmbedContents request accepts.
+const maxEmbedBatch = 100
+
+// Config configures a Client.
+type Config struct {
+	APIKey string
+	// Model, followed by Fallbacks, answers call sites whose tier has no
+	// models.
+	Model     string
+	Fallbacks []string
+	// Models are the chains of models of each tier, such as TierFast, and
+	// Routes send call sites to tiers, overriding DefaultRoutes.
+	Models map[string][]string
+	Routes map[string]string
+	// EmbeddingModel has no fallback, since vectors from different models
+	// cannot be compared.
+	EmbeddingModel string
+	BaseURL        string
+	Timeout        time.Duration
+	// Temperature is left to the model when nil.
+	Temperature *float64
+	// CacheDir stores responses for CacheTTL; either being empty or zero
+	// disables the cache.
+	CacheDir string
+	CacheTTL time.Duration
+	// Replay is ReplayRecord or ReplayReplay to record responses to, or
+	// replay them from, Cassette. Replaying needs no API key.
+	Replay   string
+	Cassette string
+	// Logger reports fallbacks; nil discards them.
+	Logger *slog.Logger
+}
+
+// Schema describes the JSON a response must follow, in the OpenAPI subset
+// Gemini accepts.
+type Schema struct {
+	Type        Type               `json:"type"`
+	Description string             `json:"description,omitempty"`
+	Enum        []string           `json:"enum,omitempty"`
+	Properties  map[string]*Schema `json:"properties,omitempty"`
+	Required    []string           `json:"required,omitempty"`
+	Items       *Schema            `json:"items,omitempty"`
+}
+
+// Type is a schema value type.
+type Type string
+
+// Schema value types.
+const (
+	TypeString  Type = "STRING"
+	TypeBoolean Type = "BOOLEAN"
+	TypeInteger Type = "INTEGER"
+	TypeArray   Type = "ARRAY"
+	TypeObject  Type = "OBJECT"
+)
+
+// Client calls the Gemini API.
+type Client struct {
+	cfg      Config
+	client   *http.Client
+	cache    *responseCache
+	cassette *cassette
+	now      func() time.Time
+	// models are the models tried, in order; see Route.
+	models []string
+	ledger *ledger
+	logger *slog.Logger
+}
+
+// NewClient creates a client. It returns nil when cfg has no API key, and is
+// not replaying, so callers can fall back to generating content themselves.
+func NewClient(cfg Config) *Client {
+	if cfg.APIKey == "" && cfg.Replay != ReplayReplay {
+		return nil
+	}
+	if cfg.Model == "" {
+		cfg.Model = DefaultModel
+	}
+	if cfg.EmbeddingModel == "" {
+		cfg.EmbeddingModel = DefaultEmbeddingModel
+	}
+	if cfg.BaseURL == "" {
+		cfg.BaseURL = DefaultBaseURL
+	}
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = DefaultTimeout
+	}
+	if cfg.Logger == nil {
+		cfg.Logger = slog.New(slog.DiscardHandler)
+	}
+	c := &Client{
+		cfg:    cfg,
+		client: &http.Client{Timeout: cfg.Timeout},
+		now:    time.Now,
+		models: append([]string{cfg.Model}, cfg.Fallbacks...),
+		ledger: &ledger{},
+		logger: cfg.Logger,
+	}
+	if cfg.CacheDir != "" && cfg.CacheTTL > 0 {
+		c.cache = &responseCache{dir: cfg.CacheDir, ttl: cfg.CacheTTL}
+	}
+	if cfg.Replay != "" && cfg.Cassette != "" {
+		c.cassette = &cassette{path: cfg.Cassette}
+	}
+	return c
+}
+
+// Model returns the first model the client prompts.
+func (c *Client) Model() string { return c.models[0] }
+
+type content struct {
+	Role  string `json:"role,omitempty"`
+	Parts []part `json:"parts"`
+}
+
+type part struct {
+	Text string `json:"text"`
+}
+
+type generateRequest struct {
+	SystemInstruction *content         `json:"systemInstruction,omitempty"`
+	Contents          []content        `json:"contents"`
+	GenerationConfig  generationConfig `json:"generationConfig"`
+}
+
+type generationConfig struct {
+	ResponseMimeType string   `json:"responseMimeType"`
+	ResponseSchema   *Schema  `json:"responseSchema,omitempty"`
+	Temperature      *float64 `json:"temperature,omitempty"`
+}
+
+type generateResponse struct {
+	Candidates []struct {
+		Content      content `json:"content"`
+		FinishReason string  `json:"finishReason"`
+	} `json:"candidates"`
+	PromptFeedback struct {
+		BlockReason string `json:"blockReason"`
+	} `json:"promptFeedback"`
+	UsageMetadata struct {
+		PromptTokenCount     int `json:"promptTokenCount"`
+		CandidatesTokenCount int `json:"candidatesTokenCount"`
+	} `json:"usageMetadata"`
+}
+
+// errBlocked marks prompts Gemini refused to answer.
+var errBlocked = errors.New("prompt was blocked")
+
+// GenerateJSON sends prompt, with system instructions, and decodes the JSON
+// answer, which follows schema, into out.
+func (c *Client) GenerateJSON(ctx context.Context, system, prompt string, schema *Schema, out any) error {
+	_, err := c.Generate(ctx, system, prompt, schema, out)
+	return err
+}
+
+// Generate is GenerateJSON, returning the model that answered. Each model
+// of the client is tried in turn while the previous one fails with an error
+// another model may not have, such as a rate limit.
+func (c *Client) Generate(ctx context.Context, system, prompt string, schema *Schema, out any) (string, error) {
+	var errs []error
+	for i, model := range c.models {
+		cached, err := c.generate(ctx, model, system, prompt, schema, out)
+		c.ledger.record(model, func(usage *ModelUsage) {
+			usage.Requests++
+			switch {
+			case err != nil:
+				usage.Failures++
+			case i > 0:
+				usage.Fallbacks++
+			}
+			if cached {
+				usage.CacheHits++
+			}
+		})
+		if err == nil {
+			return model, nil
+		}
+		errs = append(errs, fmt.Errorf("%s: %w", model, err))
+		if i == len(c.models)-1 || !fallsBack(ctx, err) {
+			break
+		}
+		c.logger.Warn("gemini model failed, falling back", "model", model, "next", c.models[i+1], "error", err)
+	}
+	if len(errs) == 1 {
+		// Without a fallback the model is the one configured.
+		return "", errors.Unwrap(errs[0])
+	}
+	return "", errors.Join(errs...)
+}
+
+// generate prompts model and reports whether the response was recorded
+// rather than requested.
+func (c *Client) generate(ctx context.Context, model, system, prompt string, schema *Schema, out any) (bool, error) {
+	request := generateRequest{
+		Contents:         []content{{Role: "user", Parts: []part{{Text: prompt}}}},
+		GenerationConfig: generationConfig{ResponseMimeType: "application/json", ResponseSchema: schema, Temperature: c.cfg.Temperature},
+	}
+	if system != "" {
+		request.SystemInstruction = &content{Parts: []part{{Text: system}}}
+	}
+	var response generateResponse
+	cached, err := c.post(ctx, "/models/"+url.PathEscape(model)+":generateContent", request, &response)
+	if err != nil {
+		return cached, fmt.Errorf("failed to generate content: %w", err)
+	}
+	c.ledger.record(model, func(usage *ModelUsage) {
+		usage.PromptTokens += response.UsageMetadata.PromptTokenCount
+		usage.OutputTokens += response.UsageMetadata.CandidatesTokenCount
+	})
+	if reason := response.PromptFeedback.BlockReason; reason != "" {
+		return cached, fmt.Errorf("%w: %s", errBlocked, reason)
+	}
+	if len(response.Candidates) == 0 {
+		return cached, errors.New("no candidates were returned")
+	}
+	var text strings.Builder
+	for _, p := range response.Candidates[0].Content.Parts {
+		text.WriteString(p.Text)
+	}
+	if text.Len() == 0 {
+		return cached, fmt.Errorf("empty response (finish reason %s)", response.Candidates[0].FinishReason)
+	}
+	if err := json.Unmarshal([]byte(text.String()), out); err != nil {
+		return cached, fmt.Errorf("failed to decode generated JSON: %w", err)
+	}
+	return cached, nil
+}
+
+type embedRequest struct {
+	Model   string  `json:"model"`
+	Content content `json:"content"`
+}
+
+type batchEmbedRequest struct {
+	Requests []embedRequest `json:"requests"`
+}
+
+type batchEmbedResponse struct {
+	Embeddings []struct {
+		Values []float32 `json:"values"`
+	} `json:"embeddings"`
+}
+
+// Embed returns an embedding vector for each text, in order, from the
+// embedding model.
+func (c *Client) Embed(ctx context.Context, texts []string) ([][]float32, error) {
+	model := "models/" + c.cfg.EmbeddingModel
+	vectors := make([][]float32, 0, len(texts))
+	for start := 0; start < len(texts); start += maxEmbedBatch {
+		batch := texts[start:min(start+maxEmbedBatch, len(texts))]
+		request := batchEmbedRequest{Requests: make([]embedRequest, len(batch))}
+		for i, text := range batch {
+			request.Requests[i] = embedRequest{Model: model, Content: content{Parts: []part{{Text: text}}}}
+		}
+		var response batchEmbedResponse
+		cached, err := c.post(ctx, "/models/"+url.PathEscape(c.cfg.EmbeddingModel)+":batchEmbedContents", request, &response)
+		c.ledger.record(c.cfg.EmbeddingModel, func(usage *ModelUsage) {
+			usage.Requests++
+			if err != nil {
+				usage.Failures++
+			}
+			if cached {
+				usage.CacheHits++
+			}
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to embed text: %w", err)
+		}
+		if len(response.Embeddings) != len(batch) {
+			return nil, fmt.Errorf("got %d embeddings for %d texts", len(response.Embeddings), len(batch))
+		}
+		for _, embedding := range response.Embeddings {
+			vectors = append(vectors, embedding.Values)
+		}
+	}
+	return vectors, nil
+}
+
+// post sends body to path and decodes the response into out. Responses come
+// from the cassette when replaying, then from the cache, and then from the
+// API; when recording, each is added to the cassette. It reports whether the
+// response came from the cassette or the cache.
+func (c *Client) post(ctx context.Context, path string, body, out any) (bool, error) {
+	payload, err := json.Marshal(body)
+	if err != nil {
+		return false, err
+	}
+	key := requestKey(path, payload)
+	var response json.RawMessage
+	switch {
+	case c.cfg.Replay == ReplayReplay:
+		if c.cassette == nil {
+			return false, errors.New("replaying needs a cassette")
+		}
+		if response, err = c.cassette.find(key); err != nil {
+			return false, err
+		}
+		if response == nil {
+			return false, fmt.Errorf("%s has no response recorded for this %s request (key %s)", c.cfg.Cassette, path, key[:12])
+		}
+	case c.cache != nil:
+		if cached, ok := c.cache.get(key, c.now()); ok {
+			response = cached
+		}
+	}
+	cached := response != nil
+	if response == nil {
+		if response, err = c.send(ctx, path, payload); err != nil {
+			return false, err
+		}
+		if c.cache != nil {
+			c.cache.put(key, response, c.now())
+		}
+	}
+	if c.cfg.Replay == ReplayRecord && c.cassette != nil {
+		if err := c.cassette.record(Interaction{Key: key, Path: path, Request: payload, Response: response}); err != nil {
+			return cached, err
+		}
+	}
+	if err := json.Unmarshal(response, out); err != nil {
+		return cached, fmt.Errorf("failed to decode response: %w", err)
+	}
+	return cached, nil
+}
+
+// statusError is a response with a status other than 2xx.
+type statusError struct {
+	path    string
+	status  string
+	code    int
+	message []byte
+}
+
+func (e *statusError) Error() string {
+	return fmt.Sprintf("%s returned %s: %s", e.path, e.status, e.message)
+}
+
+// send posts payload to the API and returns the response body.
+func (c *Client) send(ctx context.Context, path string, payload []byte) (json.RawMessage, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, strings.TrimRight(c.cfg.BaseURL, "/")+path, bytes.NewReader(payload))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("x-goog-api-key", c.cfg.APIKey)
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		message, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return nil, &statusError{path: req.URL.Path, status: resp.Status, code: resp.StatusCode, message: bytes.TrimSpace(message)}
+	}
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response: %w", err)
+	}
+	if !json.Valid(data) {
+		return nil, errors.New("failed to decode response: invalid JSON")
+	}
+	return data, nil
+}
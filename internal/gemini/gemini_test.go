@@ -0,0 +1,258 @@
+package gemini
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestNewClientWithoutKey(t *testing.T) {
+	if client := NewClient(Config{}); client != nil {
+		t.Fatalf("NewClient without a key = %v, want nil", client)
+	}
+}
+
+func TestGenerateJSON(t *testing.T) {
+	var request generateRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/models/test-model:generateContent" {
+			t.Errorf("path = %q", r.URL.Path)
+		}
+		if got := r.Header.Get("x-goog-api-key"); got != "secret" {
+			t.Errorf("api key = %q", got)
+		}
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+			t.Errorf("decode request: %v", err)
+		}
+		_, _ = w.Write([]byte(`{"candidates":[{"content":{"parts":[{"text":"{\"subject\":"},{"text":"\"hello\"}"}]}}]}`))
+	}))
+	defer server.Close()
+
+	client := NewClient(Config{APIKey: "secret", Model: "test-model", BaseURL: server.URL})
+	schema := &Schema{Type: TypeObject, Properties: map[string]*Schema{"subject": {Type: TypeString}}, Required: []string{"subject"}}
+	var out struct {
+		Subject string `json:"subject"`
+	}
+	if err := client.GenerateJSON(context.Background(), "be brief", "say hello", schema, &out); err != nil {
+		t.Fatalf("GenerateJSON: %v", err)
+	}
+	if out.Subject != "hello" {
+		t.Errorf("subject = %q, want hello", out.Subject)
+	}
+	if request.GenerationConfig.ResponseMimeType != "application/json" || request.GenerationConfig.ResponseSchema == nil {
+		t.Errorf("generation config = %+v", request.GenerationConfig)
+	}
+	if request.SystemInstruction == nil || request.SystemInstruction.Parts[0].Text != "be brief" {
+		t.Errorf("system instruction = %+v", request.SystemInstruction)
+	}
+}
+
+func TestGenerateJSONErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		status int
+		body   string
+		want   string
+	}{
+		{"http error", http.StatusForbidden, `{"error":"denied"}`, "403"},
+		{"blocked", http.StatusOK, `{"promptFeedback":{"blockReason":"SAFETY"}}`, "blocked: SAFETY"},
+		{"no candidates", http.StatusOK, `{}`, "no candidates"},
+		{"not json", http.StatusOK, `{"candidates":[{"content":{"parts":[{"text":"nope"}]}}]}`, "decode generated JSON"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tt.status)
+				_, _ = w.Write([]byte(tt.body))
+			}))
+			defer server.Close()
+
+			var out map[string]any
+			err := NewClient(Config{APIKey: "k", BaseURL: server.URL}).GenerateJSON(context.Background(), "", "p", nil, &out)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Fatalf("error = %v, want it to contain %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestEmbed(t *testing.T) {
+	var requests []batchEmbedRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/models/text-embedding-004:batchEmbedContents" {
+			t.Errorf("path = %q", r.URL.Path)
+		}
+		var request batchEmbedRequest
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+			t.Errorf("decode request: %v", err)
+		}
+		requests = append(requests, request)
+		var response batchEmbedResponse
+		for i := range request.Requests {
+			response.Embeddings = append(response.Embeddings, struct {
+				Values []float32 `json:"values"`
+			}{Values: []float32{float32(i), 1}})
+		}
+		_ = json.NewEncoder(w).Encode(response)
+	}))
+	defer server.Close()
+
+	texts := make([]string, maxEmbedBatch+2)
+	for i := range texts {
+		texts[i] = "text"
+	}
+	vectors, err := NewClient(Config{APIKey: "k", BaseURL: server.URL}).Embed(context.Background(), texts)
+	if err != nil {
+		t.Fatalf("Embed: %v", err)
+	}
+	if len(vectors) != len(texts) || vectors[maxEmbedBatch+1][0] != 1 {
+		t.Errorf("got %d vectors, last %v", len(vectors), vectors[len(vectors)-1])
+	}
+	if len(requests) != 2 || len(requests[1].Requests) != 2 || requests[0].Requests[0].Model != "models/text-embedding-004" {
+		t.Errorf("requests were not batched by %d: %d request(s)", maxEmbedBatch, len(requests))
+	}
+}
+
+// countingServer answers every generateContent request with the number of
+// requests it has served.
+func countingServer(t *testing.T) (*httptest.Server, *int) {
+	t.Helper()
+	calls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		fmt.Fprintf(w, `{"candidates":[{"content":{"parts":[{"text":"{\"n\":%d}"}]}}]}`, calls)
+	}))
+	t.Cleanup(server.Close)
+	return server, &calls
+}
+
+func generateN(t *testing.T, client *Client, prompt string) int {
+	t.Helper()
+	var out struct {
+		N int `json:"n"`
+	}
+	if err := client.GenerateJSON(context.Background(), "", prompt, nil, &out); err != nil {
+		t.Fatalf("GenerateJSON(%q): %v", prompt, err)
+	}
+	return out.N
+}
+
+func TestResponseCache(t *testing.T) {
+	server, calls := countingServer(t)
+	cfg := Config{APIKey: "k", BaseURL: server.URL, CacheDir: t.TempDir(), CacheTTL: time.Hour}
+	client := NewClient(cfg)
+	now := time.Now()
+	client.now = func() time.Time { return now }
+
+	if n := generateN(t, client, "a"); n != 1 {
+		t.Fatalf("first answer = %d", n)
+	}
+	if n := generateN(t, client, "a"); n != 1 || *calls != 1 {
+		t.Errorf("repeated prompt answered %d after %d call(s), want the cached 1", n, *calls)
+	}
+	if n := generateN(t, client, "b"); n != 2 {
+		t.Errorf("another prompt answered %d, want 2", n)
+	}
+	warm := 0.7
+	cfg.Temperature = &warm
+	if n := generateN(t, NewClient(cfg), "a"); n != 3 {
+		t.Errorf("another temperature answered %d, want 3", n)
+	}
+
+	now = now.Add(2 * time.Hour)
+	if n := generateN(t, client, "a"); n != 4 {
+		t.Errorf("expired entry answered %d, want 4", n)
+	}
+}
+
+func TestRecordAndReplay(t *testing.T) {
+	server, calls := countingServer(t)
+	path := filepath.Join(t.TempDir(), "gemini.json")
+	recorder := NewClient(Config{APIKey: "k", BaseURL: server.URL, Replay: ReplayRecord, Cassette: path})
+	generateN(t, recorder, "a")
+	generateN(t, recorder, "b")
+	generateN(t, recorder, "a")
+
+	var recorded cassetteFile
+	data, err := os.ReadFile(path)
+	if err != nil || json.Unmarshal(data, &recorded) != nil || len(recorded.Interactions) != 2 {
+		t.Fatalf("cassette = %s, %v", data, err)
+	}
+
+	// Replaying needs no key and never reaches the API.
+	replayer := NewClient(Config{BaseURL: "http://127.0.0.1:1", Replay: ReplayReplay, Cassette: path})
+	if replayer == nil {
+		t.Fatal("NewClient in replay mode without a key = nil")
+	}
+	if a, b := generateN(t, replayer, "a"), generateN(t, replayer, "b"); a != 3 || b != 2 {
+		t.Errorf("replayed a=%d b=%d, want the last recorded 3 and 2", a, b)
+	}
+	var out map[string]any
+	err = replayer.GenerateJSON(context.Background(), "", "c", nil, &out)
+	if err == nil || !strings.Contains(err.Error(), "no response recorded") {
+		t.Errorf("unrecorded prompt error = %v", err)
+	}
+	if *calls != 3 {
+		t.Errorf("API calls = %d, want 3", *calls)
+	}
+}
+
+func TestRouteAndFallback(t *testing.T) {
+	var models []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		model := strings.TrimSuffix(strings.TrimPrefix(r.URL.Path, "/models/"), ":generateContent")
+		models = append(models, model)
+		switch model {
+		case "busy":
+			w.WriteHeader(http.StatusTooManyRequests)
+		case "locked":
+			w.WriteHeader(http.StatusForbidden)
+		default:
+			_, _ = w.Write([]byte(`{"candidates":[{"content":{"parts":[{"text":"{}"}]}}],"usageMetadata":{"promptTokenCount":10,"candidatesTokenCount":2}}`))
+		}
+	}))
+	defer server.Close()
+
+	client := NewClient(Config{
+		APIKey:    "k",
+		BaseURL:   server.URL,
+		Model:     "locked",
+		Fallbacks: []string{"spare"},
+		Models:    map[string][]string{TierLarge: {"busy", "big"}},
+		Routes:    map[string]string{SiteCommitMessages: TierLarge},
+	})
+	var out map[string]any
+
+	model, err := client.Route(SiteCommitMessages).Generate(context.Background(), "", "p", nil, &out)
+	if err != nil || model != "big" {
+		t.Fatalf("routed Generate() = %q, %v, want big after busy is rate-limited", model, err)
+	}
+	if got := client.Route(SiteReview).Models(); !reflect.DeepEqual(got, []string{"busy", "big"}) {
+		t.Errorf("review models = %v, want the large tier by default", got)
+	}
+
+	models = nil
+	if err := client.GenerateJSON(context.Background(), "", "p", nil, &out); err == nil || !strings.Contains(err.Error(), "403") {
+		t.Fatalf("GenerateJSON() with rejected credentials error = %v", err)
+	}
+	if !reflect.DeepEqual(models, []string{"locked"}) {
+		t.Errorf("models tried after a 403 = %v, want no fallback", models)
+	}
+
+	want := []ModelUsage{
+		{Model: "big", Requests: 1, Fallbacks: 1, PromptTokens: 10, OutputTokens: 2},
+		{Model: "busy", Requests: 1, Failures: 1},
+		{Model: "locked", Requests: 1, Failures: 1},
+	}
+	if got := client.Usage(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Usage() = %+v, want %+v", got, want)
+	}
+}
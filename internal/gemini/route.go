@@ -0,0 +1,120 @@
+package gemini
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"slices"
+	"sort"
+	"sync"
+)
+
+// Call sites, which Config.Routes sends to a model tier.
+const (
+	// SiteCommitMessages writes commit messages and CHANGELOG entries.
+	SiteCommitMessages = "commit_messages"
+	// SiteReview reviews a diff before it is committed or pushed.
+	SiteReview = "review"
+)
+
+// Sites are every call site.
+var Sites = []string{SiteCommitMessages, SiteReview}
+
+// Model tiers.
+const (
+	// TierFast holds fast, cheap models for short decisions.
+	TierFast = "fast"
+	// TierLarge holds large models for planning and review.
+	TierLarge = "large"
+)
+
+// DefaultRoutes are the tiers of the call sites Config.Routes leaves out.
+var DefaultRoutes = map[string]string{
+	SiteCommitMessages: TierFast,
+	SiteReview:         TierLarge,
+}
+
+// chain returns the models tried, in order, for site: those of its tier
+// when configured, and otherwise Model followed by Fallbacks.
+func (cfg Config) chain(site string) []string {
+	tier, ok := cfg.Routes[site]
+	if !ok {
+		tier = DefaultRoutes[site]
+	}
+	if models := cfg.Models[tier]; len(models) > 0 {
+		return models
+	}
+	return append([]string{cfg.Model}, cfg.Fallbacks...)
+}
+
+// Route returns a client that prompts the models routed to site. It shares
+// the usage ledger, cache, and cassette of c.
+func (c *Client) Route(site string) *Client {
+	routed := *c
+	routed.models = c.cfg.chain(site)
+	return &routed
+}
+
+// Models returns the models the client tries, in order.
+func (c *Client) Models() []string { return slices.Clone(c.models) }
+
+// fallsBack reports whether a failed request should be retried with the
+// next model. Cancellation, rejected credentials, and blocked prompts would
+// fail the same way with every model.
+func fallsBack(ctx context.Context, err error) bool {
+	if ctx.Err() != nil || errors.Is(err, errBlocked) {
+		return false
+	}
+	var status *statusError
+	if errors.As(err, &status) {
+		return status.code != http.StatusUnauthorized && status.code != http.StatusForbidden
+	}
+	return true
+}
+
+// ModelUsage is what a client asked of one model.
+type ModelUsage struct {
+	Model    string `json:"model"`
+	Requests int    `json:"requests"`
+	Failures int    `json:"failures"`
+	// Fallbacks are the requests the model answered after an earlier model
+	// in the chain failed.
+	Fallbacks int `json:"fallbacks"`
+	// CacheHits are the requests answered from the cache or a cassette.
+	CacheHits    int `json:"cache_hits"`
+	PromptTokens int `json:"prompt_tokens"`
+	OutputTokens int `json:"output_tokens"`
+}
+
+// ledger tallies usage per model.
+type ledger struct {
+	mu     sync.Mutex
+	models map[string]*ModelUsage
+}
+
+func (l *ledger) record(model string, update func(*ModelUsage)) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.models == nil {
+		l.models = make(map[string]*ModelUsage)
+	}
+	usage, ok := l.models[model]
+	if !ok {
+		usage = &ModelUsage{Model: model}
+		l.models[model] = usage
+	}
+	update(usage)
+}
+
+// Usage returns what the client, and the clients routed from it, asked of
+// each model, sorted by model.
+func (c *Client) Usage() []ModelUsage {
+	c.ledger.mu.Lock()
+	defer c.ledger.mu.Unlock()
+	usage := make([]ModelUsage, 0, len(c.ledger.models))
+	for _, model := range c.ledger.models {
+		usage = append(usage, *model)
+	}
+	sort.Slice(usage, func(i, j int) bool { return usage[i].Model < usage[j].Model })
+	return usage
+}
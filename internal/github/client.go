@@ -2,6 +2,10 @@ package github
 
 import (
 	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
 
 	"github.com/SamyRai/go-jules"
 	"github.com/google/go-github/v76/github"
@@ -20,7 +24,10 @@ type Client struct {
 	Repositories *RepositoryService
 	PullRequests *PullRequestService
 	Sessions     *SessionService
+	Releases     *ReleaseService
+	CodeScanning *CodeScanningService
 	token        string
+	host         string
 }
 
 // NewClient creates a new GitHub client with authentication and initializes all services
@@ -30,18 +37,73 @@ func NewClient(token string, julesClient *jules.Client) *Client {
 		return nil
 	}
 
-	ts := oauth2.StaticTokenSource(&oauth2.Token{AccessToken: token})
-	tc := oauth2.NewClient(context.Background(), ts)
+	client := &Client{
+		Client: github.NewClient(newTokenHTTPClient(token)),
+		token:  token,
+	}
+	client.initServices(julesClient)
+
+	return client
+}
+
+// NewPublicClient creates an unauthenticated github.com client for public
+// data such as releases. Unauthenticated requests have a lower rate limit.
+func NewPublicClient() *Client {
+	client := &Client{Client: github.NewClient(&http.Client{Transport: newThrottledTransport(nil)})}
+	client.initServices(nil)
+	return client
+}
+
+// NewEnterpriseClient creates a GitHub client for a GitHub Enterprise Server
+// instance. When uploadURL is empty it is derived from the host of baseURL.
+func NewEnterpriseClient(token, baseURL, uploadURL string, julesClient *jules.Client) (*Client, error) {
+	if token == "" {
+		return nil, fmt.Errorf("GitHub token is required")
+	}
+
+	parsed, err := url.Parse(baseURL)
+	if err != nil || parsed.Host == "" {
+		return nil, fmt.Errorf("invalid GitHub Enterprise base URL: %s", baseURL)
+	}
+	if uploadURL == "" {
+		uploadURL = parsed.Scheme + "://" + parsed.Host + "/api/uploads/"
+	}
+
+	ghClient, err := github.NewClient(newTokenHTTPClient(token)).WithEnterpriseURLs(baseURL, uploadURL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to configure GitHub Enterprise URLs: %w", err)
+	}
 
 	client := &Client{
-		Client: github.NewClient(tc),
+		Client: ghClient,
 		token:  token,
+		host:   strings.ToLower(parsed.Host),
 	}
+	client.initServices(julesClient)
 
-	// Initialize specialized services with proper dependency injection
-	client.Repositories = NewRepositoryService(client, julesClient)
-	client.PullRequests = NewPullRequestService(client, julesClient)
-	client.Sessions = NewSessionService(client, julesClient, client.Repositories)
+	return client, nil
+}
 
+// Host returns the GitHub Enterprise host, or an empty string for github.com.
+func (c *Client) Host() string {
+	return c.host
+}
+
+// newTokenHTTPClient returns an HTTP client that authenticates with token
+// and throttles requests to stay under GitHub's secondary rate limits.
+func newTokenHTTPClient(token string) *http.Client {
+	ts := oauth2.StaticTokenSource(&oauth2.Token{AccessToken: token})
+	client := oauth2.NewClient(context.Background(), ts)
+	client.Transport = newThrottledTransport(client.Transport)
 	return client
 }
+
+// initServices wires the specialized services to the client.
+func (c *Client) initServices(julesClient *jules.Client) {
+	// Initialize specialized services with proper dependency injection
+	c.Repositories = NewRepositoryService(c, julesClient)
+	c.PullRequests = NewPullRequestService(c, julesClient)
+	c.Sessions = NewSessionService(c, julesClient, c.Repositories)
+	c.Releases = NewReleaseService(c)
+	c.CodeScanning = NewCodeScanningService(c)
+}
@@ -28,5 +28,21 @@ func TestNewClient(t *testing.T) {
 		assert.NotNil(t, client.Repositories)
 		assert.NotNil(t, client.PullRequests)
 		assert.NotNil(t, client.Sessions)
+		assert.NotNil(t, client.Releases)
 	})
 }
+
+func TestNewEnterpriseClient(t *testing.T) {
+	client, err := NewEnterpriseClient("dummy_token", "https://ghe.example.com/api/v3/", "", nil)
+	assert.NoError(t, err)
+	assert.Equal(t, "ghe.example.com", client.Host())
+	assert.Equal(t, "https://ghe.example.com/api/v3/", client.BaseURL.String())
+	assert.Equal(t, "https://ghe.example.com/api/uploads/", client.UploadURL.String())
+	assert.NotNil(t, client.Releases)
+
+	_, err = NewEnterpriseClient("", "https://ghe.example.com/api/v3/", "", nil)
+	assert.Error(t, err)
+
+	_, err = NewEnterpriseClient("dummy_token", "not a url", "", nil)
+	assert.Error(t, err)
+}
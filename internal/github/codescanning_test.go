@@ -0,0 +1,51 @@
+package github
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"io"
+	"net/http"
+	"testing"
+
+	"github.com/jarcoal/httpmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCodeScanningServiceUploadSARIF(t *testing.T) {
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+
+	var payload map[string]string
+	httpmock.RegisterResponder("POST", "https://api.github.com/repos/acme/widgets/code-scanning/sarifs",
+		func(req *http.Request) (*http.Response, error) {
+			if err := json.NewDecoder(req.Body).Decode(&payload); err != nil {
+				return nil, err
+			}
+			return httpmock.NewJsonResponse(202, map[string]any{"id": "upload-1"})
+		})
+
+	client := NewClient("token", nil)
+	id, err := client.CodeScanning.UploadSARIF(context.Background(), "acme", "widgets", []byte(`{"version":"2.1.0"}`), UploadSARIFOptions{
+		CommitSHA: "abc123",
+		Ref:       "refs/heads/main",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "upload-1", id)
+	assert.Equal(t, "abc123", payload["commit_sha"])
+	assert.Equal(t, "refs/heads/main", payload["ref"])
+
+	compressed, err := base64.StdEncoding.DecodeString(payload["sarif"])
+	require.NoError(t, err)
+	reader, err := gzip.NewReader(bytes.NewReader(compressed))
+	require.NoError(t, err)
+	sarif, err := io.ReadAll(reader)
+	require.NoError(t, err)
+	assert.Equal(t, `{"version":"2.1.0"}`, string(sarif))
+
+	_, err = client.CodeScanning.UploadSARIF(context.Background(), "acme", "widgets", nil, UploadSARIFOptions{})
+	assert.Error(t, err)
+}
@@ -7,11 +7,20 @@ import (
 )
 
 // GitRemoteParser handles parsing of Git remote URLs and repository detection.
-type GitRemoteParser struct{}
+type GitRemoteParser struct {
+	hosts []string
+}
 
-// NewGitRemoteParser creates a new git remote parser.
-func NewGitRemoteParser() *GitRemoteParser {
-	return &GitRemoteParser{}
+// NewGitRemoteParser creates a new git remote parser. Remotes on github.com
+// are always accepted; enterpriseHosts adds GitHub Enterprise Server hosts.
+func NewGitRemoteParser(enterpriseHosts ...string) *GitRemoteParser {
+	hosts := []string{"github.com"}
+	for _, host := range enterpriseHosts {
+		if host != "" {
+			hosts = append(hosts, strings.ToLower(host))
+		}
+	}
+	return &GitRemoteParser{hosts: hosts}
 }
 
 // GetRepoFromGitRemote detects the GitHub repository from the current directory's git remote.
@@ -47,32 +56,39 @@ func (p *GitRemoteParser) GetRepoFromGitRemote() (*Repository, error) {
 }
 
 // ParseGitHubURL parses a GitHub URL and extracts owner and repository name
-// Supports both HTTPS and SSH URL formats:
+// Supports both HTTPS and SSH URL formats on github.com and configured
+// enterprise hosts:
 // - https://github.com/owner/repo.git
 // - git@github.com:owner/repo.git.
 func (p *GitRemoteParser) ParseGitHubURL(remoteURL string) (*Repository, error) {
 	// Remove .git suffix if present
 	remoteURL = strings.TrimSuffix(remoteURL, ".git")
 
-	var owner, repo string
+	var host, owner, repo string
 
-	if strings.HasPrefix(remoteURL, "https://github.com/") {
-		// HTTPS URL: https://github.com/owner/repo
-		path := strings.TrimPrefix(remoteURL, "https://github.com/")
-		parts := strings.Split(path, "/")
-		if len(parts) >= 2 {
-			owner = parts[0]
-			repo = parts[1]
+	for _, candidate := range p.knownHosts() {
+		var path string
+		switch {
+		case strings.HasPrefix(remoteURL, "https://"+candidate+"/"):
+			// HTTPS URL: https://host/owner/repo
+			path = strings.TrimPrefix(remoteURL, "https://"+candidate+"/")
+		case strings.HasPrefix(remoteURL, "git@"+candidate+":"):
+			// SSH URL: git@host:owner/repo
+			path = strings.TrimPrefix(remoteURL, "git@"+candidate+":")
+		default:
+			continue
 		}
-	} else if strings.HasPrefix(remoteURL, "git@github.com:") {
-		// SSH URL: git@github.com:owner/repo
-		path := strings.TrimPrefix(remoteURL, "git@github.com:")
+
+		host = candidate
 		parts := strings.Split(path, "/")
 		if len(parts) >= 2 {
 			owner = parts[0]
 			repo = parts[1]
 		}
-	} else {
+		break
+	}
+
+	if host == "" {
 		return nil, fmt.Errorf("unsupported GitHub URL format: %s", remoteURL)
 	}
 
@@ -80,9 +96,21 @@ func (p *GitRemoteParser) ParseGitHubURL(remoteURL string) (*Repository, error)
 		return nil, fmt.Errorf("failed to parse owner/repo from URL: %s", remoteURL)
 	}
 
-	return &Repository{
+	repository := &Repository{
 		Owner:    owner,
 		Name:     repo,
 		FullName: fmt.Sprintf("%s/%s", owner, repo),
-	}, nil
+	}
+	if host != "github.com" {
+		repository.Host = host
+	}
+
+	return repository, nil
+}
+
+func (p *GitRemoteParser) knownHosts() []string {
+	if len(p.hosts) == 0 {
+		return []string{"github.com"}
+	}
+	return p.hosts
 }
@@ -68,6 +68,22 @@ func TestParseGitHubURL(t *testing.T) {
 	}
 }
 
+func TestParseGitHubURLEnterpriseHost(t *testing.T) {
+	parser := NewGitRemoteParser("ghe.example.com")
+
+	repo, err := parser.ParseGitHubURL("git@ghe.example.com:platform/api.git")
+	require.NoError(t, err)
+	assert.Equal(t, "ghe.example.com", repo.Host)
+	assert.Equal(t, "platform/api", repo.FullName)
+
+	repo, err = parser.ParseGitHubURL("https://github.com/SamyRai/juleson")
+	require.NoError(t, err)
+	assert.Empty(t, repo.Host)
+
+	_, err = NewGitRemoteParser().ParseGitHubURL("https://ghe.example.com/platform/api")
+	require.Error(t, err)
+}
+
 func TestGetCurrentRepo(t *testing.T) {
 	parser := NewGitRemoteParser()
 	repo, err := parser.GetRepoFromGitRemote()
@@ -0,0 +1,222 @@
+// Package githubtest provides a fake GitHub REST API covering the
+// repository and pull request endpoints Juleson uses, for tests and offline
+// mode.
+package githubtest
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/go-github/v76/github"
+)
+
+// Server is an in-memory fake of the GitHub REST API, served the way GitHub
+// Enterprise Server serves it, under /api/v3/.
+type Server struct {
+	server *httptest.Server
+
+	mu    sync.Mutex
+	login string
+	repos []*github.Repository
+	pulls map[string][]*github.PullRequest
+	diffs map[string]string
+}
+
+// NewServer starts a fake GitHub API authenticated as login. Call Close
+// when done.
+func NewServer(login string) *Server {
+	s := &Server{
+		login: login,
+		pulls: map[string][]*github.PullRequest{},
+		diffs: map[string]string{},
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /api/v3/user", s.getUser)
+	mux.HandleFunc("GET /api/v3/user/repos", s.listRepos)
+	mux.HandleFunc("GET /api/v3/repos/{owner}/{repo}", s.getRepo)
+	mux.HandleFunc("GET /api/v3/repos/{owner}/{repo}/pulls", s.listPulls)
+	mux.HandleFunc("GET /api/v3/repos/{owner}/{repo}/pulls/{number}", s.getPull)
+	mux.HandleFunc("PUT /api/v3/repos/{owner}/{repo}/pulls/{number}/merge", s.mergePull)
+	mux.HandleFunc("GET /api/v3/repos/{owner}/{repo}/releases", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, []*github.RepositoryRelease{})
+	})
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusNotFound, map[string]string{"message": "Not Found"})
+	})
+	s.server = httptest.NewServer(mux)
+	return s
+}
+
+// Close shuts the server down.
+func (s *Server) Close() {
+	s.server.Close()
+}
+
+// URL returns the API base URL, for github.base_url.
+func (s *Server) URL() string {
+	return s.server.URL + "/api/v3/"
+}
+
+// UploadURL returns the upload base URL, for github.upload_url.
+func (s *Server) UploadURL() string {
+	return s.server.URL + "/api/uploads/"
+}
+
+// AddRepository adds a repository owned by owner.
+func (s *Server) AddRepository(owner, name, defaultBranch string) *github.Repository {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	repo := &github.Repository{
+		ID:            github.Ptr(int64(len(s.repos) + 1)),
+		Name:          github.Ptr(name),
+		FullName:      github.Ptr(owner + "/" + name),
+		Owner:         &github.User{Login: github.Ptr(owner)},
+		DefaultBranch: github.Ptr(defaultBranch),
+		HTMLURL:       github.Ptr("https://github.com/" + owner + "/" + name),
+		CloneURL:      github.Ptr("https://github.com/" + owner + "/" + name + ".git"),
+		Private:       github.Ptr(false),
+	}
+	s.repos = append(s.repos, repo)
+	return repo
+}
+
+// AddPullRequest adds an open pull request to owner/repo, numbered after
+// the existing ones, with the given title, head branch, and diff.
+func (s *Server) AddPullRequest(owner, repo, title, head, diff string) *github.PullRequest {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	key := owner + "/" + repo
+	number := len(s.pulls[key]) + 1
+	now := github.Timestamp{Time: time.Now().UTC()}
+	pr := &github.PullRequest{
+		Number:    github.Ptr(number),
+		State:     github.Ptr("open"),
+		Title:     github.Ptr(title),
+		HTMLURL:   github.Ptr(fmt.Sprintf("https://github.com/%s/pull/%d", key, number)),
+		User:      &github.User{Login: github.Ptr("google-labs-jules[bot]")},
+		Head:      &github.PullRequestBranch{Ref: github.Ptr(head)},
+		Base:      &github.PullRequestBranch{Ref: github.Ptr("main")},
+		Mergeable: github.Ptr(true),
+		Merged:    github.Ptr(false),
+		CreatedAt: &now,
+		UpdatedAt: &now,
+	}
+	if diff != "" {
+		pr.ChangedFiles = github.Ptr(strings.Count(diff, "diff --git "))
+	}
+	s.pulls[key] = append(s.pulls[key], pr)
+	s.diffs[fmt.Sprintf("%s#%d", key, number)] = diff
+	return pr
+}
+
+// PullRequest returns a copy of pull request number of owner/repo.
+func (s *Server) PullRequest(owner, repo string, number int) (github.PullRequest, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, pr := range s.pulls[owner+"/"+repo] {
+		if pr.GetNumber() == number {
+			return *pr, true
+		}
+	}
+	return github.PullRequest{}, false
+}
+
+func (s *Server) getUser(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	writeJSON(w, http.StatusOK, &github.User{Login: github.Ptr(s.login)})
+}
+
+func (s *Server) listRepos(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	writeJSON(w, http.StatusOK, s.repos)
+}
+
+func (s *Server) getRepo(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, repo := range s.repos {
+		if strings.EqualFold(repo.GetFullName(), r.PathValue("owner")+"/"+r.PathValue("repo")) {
+			writeJSON(w, http.StatusOK, repo)
+			return
+		}
+	}
+	writeJSON(w, http.StatusNotFound, map[string]string{"message": "Not Found"})
+}
+
+func (s *Server) listPulls(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	state := r.URL.Query().Get("state")
+	pulls := []*github.PullRequest{}
+	for _, pr := range s.pulls[r.PathValue("owner")+"/"+r.PathValue("repo")] {
+		if state == "all" || pr.GetState() == state || (state == "" && pr.GetState() == "open") {
+			pulls = append(pulls, pr)
+		}
+	}
+	writeJSON(w, http.StatusOK, pulls)
+}
+
+// pull returns the pull request named by the request path, or nil after
+// writing a 404.
+func (s *Server) pull(w http.ResponseWriter, r *http.Request) (*github.PullRequest, string) {
+	key := r.PathValue("owner") + "/" + r.PathValue("repo")
+	number, _ := strconv.Atoi(r.PathValue("number"))
+	for _, pr := range s.pulls[key] {
+		if pr.GetNumber() == number {
+			return pr, fmt.Sprintf("%s#%d", key, number)
+		}
+	}
+	writeJSON(w, http.StatusNotFound, map[string]string{"message": "Not Found"})
+	return nil, ""
+}
+
+func (s *Server) getPull(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	pr, key := s.pull(w, r)
+	if pr == nil {
+		return
+	}
+	if strings.Contains(r.Header.Get("Accept"), "diff") {
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		_, _ = io.WriteString(w, s.diffs[key])
+		return
+	}
+	writeJSON(w, http.StatusOK, pr)
+}
+
+func (s *Server) mergePull(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	pr, _ := s.pull(w, r)
+	if pr == nil {
+		return
+	}
+	if pr.GetMerged() {
+		writeJSON(w, http.StatusMethodNotAllowed, map[string]string{"message": "Pull Request is not mergeable"})
+		return
+	}
+	pr.Merged = github.Ptr(true)
+	pr.State = github.Ptr("closed")
+	writeJSON(w, http.StatusOK, &github.PullRequestMergeResult{
+		SHA:     github.Ptr(fmt.Sprintf("%040d", pr.GetNumber())),
+		Merged:  github.Ptr(true),
+		Message: github.Ptr("Pull Request successfully merged"),
+	})
+}
+
+func writeJSON(w http.ResponseWriter, status int, value any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(value)
+}
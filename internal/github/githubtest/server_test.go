@@ -0,0 +1,49 @@
+package githubtest
+
+import (
+	"context"
+	"testing"
+
+	ghclient "github.com/SamyRai/juleson/internal/github"
+	"github.com/google/go-github/v76/github"
+)
+
+func TestServerRepositoriesAndPullRequests(t *testing.T) {
+	server := NewServer("demo-user")
+	defer server.Close()
+	server.AddRepository("octo", "demo", "main")
+	pr := server.AddPullRequest("octo", "demo", "Fix it", "jules/fix", "diff --git a/x b/x\n")
+
+	client, err := ghclient.NewEnterpriseClient("token", server.URL(), server.UploadURL(), nil)
+	if err != nil {
+		t.Fatalf("NewEnterpriseClient: %v", err)
+	}
+	ctx := context.Background()
+
+	user, _, err := client.Users.Get(ctx, "")
+	if err != nil || user.GetLogin() != "demo-user" {
+		t.Fatalf("Users.Get = %v, %v", user, err)
+	}
+	repo, _, err := client.Client.Repositories.Get(ctx, "octo", "demo")
+	if err != nil || repo.GetDefaultBranch() != "main" {
+		t.Fatalf("Repositories.Get = %v, %v", repo, err)
+	}
+	diff, _, err := client.Client.PullRequests.GetRaw(ctx, "octo", "demo", pr.GetNumber(), github.RawOptions{Type: github.Diff})
+	if err != nil || diff != "diff --git a/x b/x\n" {
+		t.Fatalf("GetRaw = %q, %v", diff, err)
+	}
+
+	if err := client.PullRequests.MergePullRequest(ctx, pr.GetHTMLURL(), "squash"); err != nil {
+		t.Fatalf("MergePullRequest: %v", err)
+	}
+	merged, _, err := client.Client.PullRequests.Get(ctx, "octo", "demo", pr.GetNumber())
+	if err != nil || !merged.GetMerged() || merged.GetState() != "closed" {
+		t.Fatalf("merged pull request = %v, %v", merged, err)
+	}
+	if err := client.PullRequests.MergePullRequest(ctx, pr.GetHTMLURL(), "squash"); err == nil {
+		t.Fatal("merging a merged pull request succeeded")
+	}
+	if _, _, err := client.Client.Repositories.Get(ctx, "octo", "missing"); err == nil {
+		t.Fatal("Repositories.Get found a missing repository")
+	}
+}
@@ -78,6 +78,20 @@ func (s *PullRequestService) MergePullRequest(ctx context.Context, prURL string,
 	return nil
 }
 
+// AddLabels adds labels to a PR.
+func (s *PullRequestService) AddLabels(ctx context.Context, prURL string, labels []string) error {
+	owner, repo, prNumber, err := s.parsePRURL(prURL)
+	if err != nil {
+		return err
+	}
+
+	if _, _, err := s.client.Client.Issues.AddLabelsToIssue(ctx, owner, repo, prNumber, labels); err != nil {
+		return fmt.Errorf("failed to add labels: %w", err)
+	}
+
+	return nil
+}
+
 // GetPullRequestDiff retrieves the diff for a PR created by a Jules session.
 func (s *PullRequestService) GetPullRequestDiff(ctx context.Context, sessionID string) (string, error) {
 	if s.julesClient == nil {
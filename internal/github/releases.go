@@ -0,0 +1,305 @@
+package github
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/go-github/v76/github"
+)
+
+// ReleaseService handles release and release asset operations.
+type ReleaseService struct {
+	client *Client
+}
+
+// NewReleaseService creates a new release service.
+func NewReleaseService(client *Client) *ReleaseService {
+	return &ReleaseService{
+		client: client,
+	}
+}
+
+// CreateReleaseOptions configures a new release.
+type CreateReleaseOptions struct {
+	TagName    string
+	Name       string
+	Target     string
+	Body       string
+	Draft      bool
+	Prerelease bool
+	// Changelog appends a list of pull requests merged since the previous
+	// release to the release body.
+	Changelog bool
+}
+
+// ListReleases lists the most recent releases of a repository.
+func (s *ReleaseService) ListReleases(ctx context.Context, owner, repo string, limit int) ([]*Release, error) {
+	if s.client == nil {
+		return nil, fmt.Errorf("GitHub client not configured")
+	}
+
+	perPage := limit
+	if perPage <= 0 || perPage > 100 {
+		perPage = 100
+	}
+	opts := &github.ListOptions{PerPage: perPage}
+
+	var releases []*Release
+	for {
+		page, resp, err := s.client.Client.Repositories.ListReleases(ctx, owner, repo, opts)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list releases: %w", err)
+		}
+
+		for _, release := range page {
+			releases = append(releases, mapGitHubRelease(release))
+			if limit > 0 && len(releases) >= limit {
+				return releases, nil
+			}
+		}
+
+		if resp.NextPage == 0 {
+			break
+		}
+		opts.Page = resp.NextPage
+	}
+
+	return releases, nil
+}
+
+// LatestRelease returns the latest published, non-prerelease release.
+func (s *ReleaseService) LatestRelease(ctx context.Context, owner, repo string) (*Release, error) {
+	if s.client == nil {
+		return nil, fmt.Errorf("GitHub client not configured")
+	}
+
+	release, _, err := s.client.Client.Repositories.GetLatestRelease(ctx, owner, repo)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get latest release: %w", err)
+	}
+
+	return mapGitHubRelease(release), nil
+}
+
+// GetReleaseByTag returns the release for a tag.
+func (s *ReleaseService) GetReleaseByTag(ctx context.Context, owner, repo, tag string) (*Release, error) {
+	if s.client == nil {
+		return nil, fmt.Errorf("GitHub client not configured")
+	}
+
+	release, _, err := s.client.Client.Repositories.GetReleaseByTag(ctx, owner, repo, tag)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get release %s: %w", tag, err)
+	}
+
+	return mapGitHubRelease(release), nil
+}
+
+// CreateRelease creates a release, optionally generating a changelog from
+// pull requests merged since the previous release.
+func (s *ReleaseService) CreateRelease(ctx context.Context, owner, repo string, opts CreateReleaseOptions) (*Release, error) {
+	if s.client == nil {
+		return nil, fmt.Errorf("GitHub client not configured")
+	}
+	if opts.TagName == "" {
+		return nil, fmt.Errorf("release tag is required")
+	}
+
+	body := opts.Body
+	if opts.Changelog {
+		changelog, err := s.GenerateChangelog(ctx, owner, repo, "")
+		if err != nil {
+			return nil, err
+		}
+		if body != "" {
+			body += "\n\n"
+		}
+		body += changelog
+	}
+
+	name := opts.Name
+	if name == "" {
+		name = opts.TagName
+	}
+
+	request := &github.RepositoryRelease{
+		TagName:    github.Ptr(opts.TagName),
+		Name:       github.Ptr(name),
+		Body:       github.Ptr(body),
+		Draft:      github.Ptr(opts.Draft),
+		Prerelease: github.Ptr(opts.Prerelease),
+	}
+	if opts.Target != "" {
+		request.TargetCommitish = github.Ptr(opts.Target)
+	}
+
+	release, _, err := s.client.Client.Repositories.CreateRelease(ctx, owner, repo, request)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create release: %w", err)
+	}
+
+	return mapGitHubRelease(release), nil
+}
+
+// UploadAsset uploads a local file as an asset of an existing release.
+func (s *ReleaseService) UploadAsset(ctx context.Context, owner, repo string, releaseID int64, path string) (*ReleaseAsset, error) {
+	if s.client == nil {
+		return nil, fmt.Errorf("GitHub client not configured")
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open asset: %w", err)
+	}
+	defer func() { _ = file.Close() }()
+
+	asset, _, err := s.client.Client.Repositories.UploadReleaseAsset(ctx, owner, repo, releaseID, &github.UploadOptions{
+		Name: filepath.Base(path),
+	}, file)
+	if err != nil {
+		return nil, fmt.Errorf("failed to upload asset %s: %w", filepath.Base(path), err)
+	}
+
+	return mapGitHubReleaseAsset(asset), nil
+}
+
+// GenerateChangelog lists pull requests merged since sinceTag. When sinceTag
+// is empty the latest release is used; repositories without releases include
+// every merged pull request.
+func (s *ReleaseService) GenerateChangelog(ctx context.Context, owner, repo, sinceTag string) (string, error) {
+	if s.client == nil {
+		return "", fmt.Errorf("GitHub client not configured")
+	}
+
+	since, err := s.releaseCutoff(ctx, owner, repo, sinceTag)
+	if err != nil {
+		return "", err
+	}
+
+	opts := &github.PullRequestListOptions{
+		State:       "closed",
+		Sort:        "updated",
+		Direction:   "desc",
+		ListOptions: github.ListOptions{PerPage: 100},
+	}
+
+	var merged []*github.PullRequest
+	for {
+		prs, resp, err := s.client.Client.PullRequests.List(ctx, owner, repo, opts)
+		if err != nil {
+			return "", fmt.Errorf("failed to list pull requests: %w", err)
+		}
+
+		reachedCutoff := false
+		for _, pr := range prs {
+			// Results are sorted by update time, so anything last updated
+			// before the cutoff cannot have been merged after it.
+			if !since.IsZero() && pr.GetUpdatedAt().Before(since) {
+				reachedCutoff = true
+				break
+			}
+			if pr.MergedAt == nil || pr.GetMergedAt().Before(since) {
+				continue
+			}
+			merged = append(merged, pr)
+		}
+
+		if reachedCutoff || resp.NextPage == 0 {
+			break
+		}
+		opts.Page = resp.NextPage
+	}
+
+	return FormatChangelog(merged), nil
+}
+
+// releaseCutoff resolves the publish time of the release that a changelog
+// should start from.
+func (s *ReleaseService) releaseCutoff(ctx context.Context, owner, repo, sinceTag string) (time.Time, error) {
+	var (
+		release *github.RepositoryRelease
+		resp    *github.Response
+		err     error
+	)
+	if sinceTag != "" {
+		release, resp, err = s.client.Client.Repositories.GetReleaseByTag(ctx, owner, repo, sinceTag)
+	} else {
+		release, resp, err = s.client.Client.Repositories.GetLatestRelease(ctx, owner, repo)
+	}
+	if err != nil {
+		if sinceTag == "" && resp != nil && resp.StatusCode == 404 {
+			return time.Time{}, nil
+		}
+		return time.Time{}, fmt.Errorf("failed to resolve previous release: %w", err)
+	}
+
+	if release.PublishedAt != nil {
+		return release.GetPublishedAt().Time, nil
+	}
+	return release.GetCreatedAt().Time, nil
+}
+
+// FormatChangelog renders merged pull requests as a Markdown list ordered by
+// merge time, oldest first.
+func FormatChangelog(prs []*github.PullRequest) string {
+	if len(prs) == 0 {
+		return "## Changes\n\n- No merged pull requests since the previous release.\n"
+	}
+
+	sorted := make([]*github.PullRequest, len(prs))
+	copy(sorted, prs)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].GetMergedAt().Before(sorted[j].GetMergedAt().Time)
+	})
+
+	var b strings.Builder
+	b.WriteString("## Changes\n\n")
+	for _, pr := range sorted {
+		fmt.Fprintf(&b, "- %s (#%d)", strings.TrimSpace(pr.GetTitle()), pr.GetNumber())
+		if login := pr.GetUser().GetLogin(); login != "" {
+			fmt.Fprintf(&b, " @%s", login)
+		}
+		b.WriteString("\n")
+	}
+
+	return b.String()
+}
+
+// mapGitHubRelease converts a github.RepositoryRelease to our Release type.
+func mapGitHubRelease(ghRelease *github.RepositoryRelease) *Release {
+	release := &Release{
+		ID:         ghRelease.GetID(),
+		TagName:    ghRelease.GetTagName(),
+		Name:       ghRelease.GetName(),
+		Body:       ghRelease.GetBody(),
+		URL:        ghRelease.GetHTMLURL(),
+		UploadURL:  ghRelease.GetUploadURL(),
+		Draft:      ghRelease.GetDraft(),
+		Prerelease: ghRelease.GetPrerelease(),
+	}
+	if ghRelease.PublishedAt != nil {
+		release.PublishedAt = ghRelease.GetPublishedAt().Format("2006-01-02T15:04:05Z")
+	}
+	for _, asset := range ghRelease.Assets {
+		release.Assets = append(release.Assets, mapGitHubReleaseAsset(asset))
+	}
+
+	return release
+}
+
+// mapGitHubReleaseAsset converts a github.ReleaseAsset to our ReleaseAsset type.
+func mapGitHubReleaseAsset(ghAsset *github.ReleaseAsset) *ReleaseAsset {
+	return &ReleaseAsset{
+		ID:            ghAsset.GetID(),
+		Name:          ghAsset.GetName(),
+		ContentType:   ghAsset.GetContentType(),
+		DownloadURL:   ghAsset.GetBrowserDownloadURL(),
+		Size:          ghAsset.GetSize(),
+		DownloadCount: ghAsset.GetDownloadCount(),
+	}
+}
@@ -0,0 +1,86 @@
+package github
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/google/go-github/v76/github"
+	"github.com/jarcoal/httpmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFormatChangelog(t *testing.T) {
+	older := &github.Timestamp{Time: time.Date(2026, 6, 1, 0, 0, 0, 0, time.UTC)}
+	newer := &github.Timestamp{Time: time.Date(2026, 6, 2, 0, 0, 0, 0, time.UTC)}
+
+	changelog := FormatChangelog([]*github.PullRequest{
+		{Number: github.Ptr(12), Title: github.Ptr("Add releases"), MergedAt: newer, User: &github.User{Login: github.Ptr("octo")}},
+		{Number: github.Ptr(7), Title: github.Ptr(" Fix install "), MergedAt: older},
+	})
+
+	assert.Equal(t, "## Changes\n\n- Fix install (#7)\n- Add releases (#12) @octo\n", changelog)
+	assert.Contains(t, FormatChangelog(nil), "No merged pull requests")
+}
+
+func TestReleaseServiceCreateReleaseWithChangelog(t *testing.T) {
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+
+	published := time.Date(2026, 6, 1, 0, 0, 0, 0, time.UTC)
+	httpmock.RegisterResponder("GET", "https://api.github.com/repos/acme/widgets/releases/latest",
+		httpmock.NewJsonResponderOrPanic(200, map[string]any{
+			"id":           1,
+			"tag_name":     "v0.1.0",
+			"published_at": published.Format(time.RFC3339),
+		}))
+	httpmock.RegisterResponder("GET", `=~^https://api\.github\.com/repos/acme/widgets/pulls\?`,
+		httpmock.NewJsonResponderOrPanic(200, []map[string]any{
+			{"number": 3, "title": "Merged after release", "updated_at": published.Add(48 * time.Hour).Format(time.RFC3339), "merged_at": published.Add(24 * time.Hour).Format(time.RFC3339)},
+			{"number": 2, "title": "Closed without merge", "updated_at": published.Add(12 * time.Hour).Format(time.RFC3339)},
+			{"number": 1, "title": "Before release", "updated_at": published.Add(-time.Hour).Format(time.RFC3339), "merged_at": published.Add(-time.Hour).Format(time.RFC3339)},
+		}))
+
+	var body string
+	httpmock.RegisterResponder("POST", "https://api.github.com/repos/acme/widgets/releases",
+		func(req *http.Request) (*http.Response, error) {
+			var payload github.RepositoryRelease
+			if err := json.NewDecoder(req.Body).Decode(&payload); err != nil {
+				return nil, err
+			}
+			body = payload.GetBody()
+			return httpmock.NewJsonResponse(201, map[string]any{
+				"id":         2,
+				"tag_name":   payload.GetTagName(),
+				"name":       payload.GetName(),
+				"body":       payload.GetBody(),
+				"draft":      payload.GetDraft(),
+				"prerelease": payload.GetPrerelease(),
+			})
+		})
+
+	client := NewClient("dummy_token", nil)
+	release, err := client.Releases.CreateRelease(context.Background(), "acme", "widgets", CreateReleaseOptions{
+		TagName:    "v0.2.0",
+		Draft:      true,
+		Prerelease: true,
+		Changelog:  true,
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, "v0.2.0", release.TagName)
+	assert.Equal(t, "v0.2.0", release.Name)
+	assert.True(t, release.Draft)
+	assert.True(t, release.Prerelease)
+	assert.Equal(t, "## Changes\n\n- Merged after release (#3)\n", body)
+}
+
+func TestReleaseServiceRequiresTag(t *testing.T) {
+	client := NewClient("dummy_token", nil)
+
+	_, err := client.Releases.CreateRelease(context.Background(), "acme", "widgets", CreateReleaseOptions{})
+	assert.EqualError(t, err, "release tag is required")
+}
@@ -18,10 +18,15 @@ type RepositoryService struct {
 
 // NewRepositoryService creates a new repository service.
 func NewRepositoryService(client *Client, julesClient *jules.Client) *RepositoryService {
+	var enterpriseHosts []string
+	if client != nil && client.host != "" {
+		enterpriseHosts = append(enterpriseHosts, client.host)
+	}
+
 	return &RepositoryService{
 		client:      client,
 		julesClient: julesClient,
-		gitParser:   NewGitRemoteParser(),
+		gitParser:   NewGitRemoteParser(enterpriseHosts...),
 	}
 }
 
@@ -0,0 +1,230 @@
+package github
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// GitHub's secondary rate limits cap concurrent requests and bursts of
+// content-creating requests, and answer with 403 or 429 when they are hit.
+// throttledTransport keeps clients under them: requests to a host share a
+// concurrency limit that halves when GitHub reports a secondary limit and
+// grows back one request at a time after successes, every request waits out
+// the retry delay GitHub asks for, and writes are spaced a second apart as
+// GitHub recommends. Requests that hit a limit are retried.
+const (
+	// maxConcurrentRequests is the concurrency limit of a host before any
+	// secondary limit is hit.
+	maxConcurrentRequests = 8
+	// maxThrottleRetries is how often a request is retried after hitting a
+	// secondary limit.
+	maxThrottleRetries = 3
+	// maxThrottleWait is the longest retry delay a request waits for;
+	// longer ones return the rate limit error.
+	maxThrottleWait = 2 * time.Minute
+	// writeInterval spaces requests that create or change content.
+	writeInterval = time.Second
+)
+
+// secondaryLimitBackoff is the retry delay when GitHub gives none. GitHub
+// asks clients to wait at least a minute. Tests shorten it.
+var secondaryLimitBackoff = time.Minute
+
+// throttles holds the throttle of each host, shared by every client in the
+// process since GitHub's limits apply per user rather than per client.
+var throttles = struct {
+	sync.Mutex
+	hosts map[string]*hostThrottle
+}{hosts: make(map[string]*hostThrottle)}
+
+func throttleFor(host string) *hostThrottle {
+	throttles.Lock()
+	defer throttles.Unlock()
+	throttle, ok := throttles.hosts[host]
+	if !ok {
+		throttle = newHostThrottle(maxConcurrentRequests)
+		throttles.hosts[host] = throttle
+	}
+	return throttle
+}
+
+// throttledTransport throttles requests to GitHub and retries those that
+// hit a secondary rate limit.
+type throttledTransport struct {
+	base http.RoundTripper
+}
+
+func newThrottledTransport(base http.RoundTripper) http.RoundTripper {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return &throttledTransport{base: base}
+}
+
+func (t *throttledTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	throttle := throttleFor(req.URL.Host)
+	write := req.Method != http.MethodGet && req.Method != http.MethodHead
+	for attempt := 0; ; attempt++ {
+		if err := throttle.acquire(req.Context(), write); err != nil {
+			return nil, err
+		}
+		resp, err := t.base.RoundTrip(req)
+		delay, limited := secondaryLimit(resp, attempt)
+		throttle.release(limited, delay)
+
+		if !limited || attempt == maxThrottleRetries || delay > maxThrottleWait {
+			return resp, err
+		}
+		retry, ok := rewind(req)
+		if !ok {
+			return resp, err
+		}
+		_, _ = io.Copy(io.Discard, resp.Body)
+		_ = resp.Body.Close()
+		req = retry
+	}
+}
+
+// rewind returns a copy of req that can be sent again, with a fresh body.
+func rewind(req *http.Request) (*http.Request, bool) {
+	if req.Body == nil || req.Body == http.NoBody {
+		return req, true
+	}
+	if req.GetBody == nil {
+		return nil, false
+	}
+	body, err := req.GetBody()
+	if err != nil {
+		return nil, false
+	}
+	retry := req.Clone(req.Context())
+	retry.Body = body
+	return retry, true
+}
+
+// secondaryLimit reports whether resp is a secondary rate limit response,
+// and how long to wait before retrying: the Retry-After delay, the time
+// until the limit resets, or an exponential backoff. Exhausting the primary
+// rate limit is not a secondary limit; go-github reports it.
+func secondaryLimit(resp *http.Response, attempt int) (time.Duration, bool) {
+	if resp == nil || (resp.StatusCode != http.StatusForbidden && resp.StatusCode != http.StatusTooManyRequests) {
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil {
+		return time.Duration(seconds) * time.Second, true
+	}
+
+	// Peek at the body, leaving it for the caller to read.
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 64<<10))
+	resp.Body = struct {
+		io.Reader
+		io.Closer
+	}{io.MultiReader(bytes.NewReader(body), resp.Body), resp.Body}
+	if !strings.Contains(strings.ToLower(string(body)), "secondary rate limit") {
+		return 0, false
+	}
+	if resp.Header.Get("X-RateLimit-Remaining") == "0" {
+		if reset, err := strconv.ParseInt(resp.Header.Get("X-RateLimit-Reset"), 10, 64); err == nil {
+			return max(time.Until(time.Unix(reset, 0)), 0), true
+		}
+	}
+	return secondaryLimitBackoff << attempt, true
+}
+
+// hostThrottle limits the requests in flight to one host, adapting the
+// limit to the secondary rate limits the host reports.
+type hostThrottle struct {
+	mu     sync.Mutex
+	max    int
+	limit  int
+	active int
+	// successes counts requests since the limit last changed.
+	successes int
+	// pausedUntil holds every request back after a rate limit response.
+	pausedUntil time.Time
+	// nextWrite is when the next write may be sent.
+	nextWrite time.Time
+	// changed is closed and replaced whenever a slot frees up.
+	changed chan struct{}
+}
+
+func newHostThrottle(limit int) *hostThrottle {
+	return &hostThrottle{max: limit, limit: limit, changed: make(chan struct{})}
+}
+
+// acquire waits for a free slot and for any pause to end, and for writes,
+// for the write interval to pass.
+func (t *hostThrottle) acquire(ctx context.Context, write bool) error {
+	for {
+		t.mu.Lock()
+		wait := time.Until(t.pausedUntil)
+		if wait <= 0 && t.active < t.limit {
+			t.active++
+			if write {
+				now := time.Now()
+				wait = max(t.nextWrite.Sub(now), 0)
+				t.nextWrite = now.Add(wait + writeInterval)
+			}
+			t.mu.Unlock()
+			if wait > 0 {
+				if err := sleep(ctx, wait); err != nil {
+					t.release(false, 0)
+					return err
+				}
+			}
+			return nil
+		}
+		changed := t.changed
+		t.mu.Unlock()
+
+		if wait > 0 {
+			if err := sleep(ctx, wait); err != nil {
+				return err
+			}
+			continue
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-changed:
+		}
+	}
+}
+
+// release frees a slot. A rate limited request halves the limit and pauses
+// every request for delay; the limit grows by one after as many successes
+// in a row as the limit.
+func (t *hostThrottle) release(limited bool, delay time.Duration) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.active--
+	if limited {
+		t.limit = max(t.limit/2, 1)
+		t.successes = 0
+		if until := time.Now().Add(delay); until.After(t.pausedUntil) {
+			t.pausedUntil = until
+		}
+	} else if t.successes++; t.successes >= t.limit && t.limit < t.max {
+		t.limit++
+		t.successes = 0
+	}
+	close(t.changed)
+	t.changed = make(chan struct{})
+}
+
+func sleep(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
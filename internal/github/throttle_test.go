@@ -0,0 +1,106 @@
+package github
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestThrottledTransportRetriesSecondaryLimit(t *testing.T) {
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		assert.Equal(t, `{"title":"x"}`, string(body))
+		switch requests.Add(1) {
+		case 1:
+			w.Header().Set("Retry-After", "0")
+			w.WriteHeader(http.StatusForbidden)
+			_, _ = io.WriteString(w, `{"message":"You have exceeded a secondary rate limit"}`)
+		case 2:
+			w.WriteHeader(http.StatusTooManyRequests)
+			_, _ = io.WriteString(w, `{"message":"You have exceeded a secondary rate limit"}`)
+		default:
+			_, _ = io.WriteString(w, "ok")
+		}
+	}))
+	defer server.Close()
+	defer setSecondaryLimitBackoff(time.Millisecond)()
+
+	// Reads are not spaced like writes, so the retries are immediate.
+	req, err := http.NewRequest(http.MethodGet, server.URL, strings.NewReader(`{"title":"x"}`))
+	require.NoError(t, err)
+	client := &http.Client{Transport: newThrottledTransport(nil)}
+	resp, err := client.Do(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(resp.Body)
+
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "ok", string(body))
+	assert.Equal(t, int32(3), requests.Load())
+
+	throttle := throttleFor(strings.TrimPrefix(server.URL, "http://"))
+	throttle.mu.Lock()
+	defer throttle.mu.Unlock()
+	assert.Equal(t, maxConcurrentRequests/4, throttle.limit, "each secondary limit halves the concurrency limit")
+}
+
+func TestThrottledTransportPassesOtherErrors(t *testing.T) {
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		w.WriteHeader(http.StatusForbidden)
+		_, _ = io.WriteString(w, `{"message":"Resource not accessible by integration"}`)
+	}))
+	defer server.Close()
+
+	client := &http.Client{Transport: newThrottledTransport(nil)}
+	resp, err := client.Get(server.URL)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(resp.Body)
+
+	assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+	assert.Contains(t, string(body), "Resource not accessible", "the peeked body is left for the caller")
+	assert.Equal(t, int32(1), requests.Load())
+}
+
+func TestHostThrottleLimitsConcurrency(t *testing.T) {
+	throttle := newHostThrottle(2)
+	ctx := context.Background()
+	require.NoError(t, throttle.acquire(ctx, false))
+	require.NoError(t, throttle.acquire(ctx, false))
+
+	blocked, cancel := context.WithTimeout(ctx, 20*time.Millisecond)
+	defer cancel()
+	assert.ErrorIs(t, throttle.acquire(blocked, false), context.DeadlineExceeded)
+
+	throttle.release(true, 0)
+	throttle.mu.Lock()
+	assert.Equal(t, 1, throttle.limit, "a secondary limit halves the concurrency limit")
+	throttle.mu.Unlock()
+
+	// The limit grows back by one after as many successes as the limit,
+	// which frees a slot for the waiting request.
+	acquired := make(chan error, 1)
+	go func() { acquired <- throttle.acquire(ctx, false) }()
+	throttle.release(false, 0)
+	require.NoError(t, <-acquired)
+	throttle.mu.Lock()
+	assert.Equal(t, 2, throttle.limit)
+	throttle.mu.Unlock()
+}
+
+func setSecondaryLimitBackoff(d time.Duration) func() {
+	previous := secondaryLimitBackoff
+	secondaryLimitBackoff = d
+	return func() { secondaryLimitBackoff = previous }
+}
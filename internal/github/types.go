@@ -5,6 +5,7 @@ type Repository struct {
 	Owner         string `json:"owner"`
 	Name          string `json:"name"`
 	FullName      string `json:"full_name"`
+	Host          string `json:"host,omitempty"`
 	Description   string `json:"description,omitempty"`
 	DefaultBranch string `json:"default_branch"`
 	URL           string `json:"url"`
@@ -14,3 +15,27 @@ type Repository struct {
 	HasIssues     bool   `json:"has_issues"`
 	Private       bool   `json:"private"`
 }
+
+// Release represents a GitHub release with its uploaded assets.
+type Release struct {
+	ID          int64           `json:"id"`
+	TagName     string          `json:"tag_name"`
+	Name        string          `json:"name"`
+	Body        string          `json:"body,omitempty"`
+	URL         string          `json:"url"`
+	UploadURL   string          `json:"upload_url,omitempty"`
+	PublishedAt string          `json:"published_at,omitempty"`
+	Assets      []*ReleaseAsset `json:"assets,omitempty"`
+	Draft       bool            `json:"draft"`
+	Prerelease  bool            `json:"prerelease"`
+}
+
+// ReleaseAsset represents a file attached to a GitHub release.
+type ReleaseAsset struct {
+	ID            int64  `json:"id"`
+	Name          string `json:"name"`
+	ContentType   string `json:"content_type,omitempty"`
+	DownloadURL   string `json:"download_url"`
+	Size          int    `json:"size"`
+	DownloadCount int    `json:"download_count"`
+}
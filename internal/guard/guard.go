@@ -0,0 +1,212 @@
+// Package guard scans text for prompt injection and dangerous instructions:
+// prompts assembled from repository content and issues before they are sent
+// to Jules, and plans and patches produced by Jules before they are approved
+// or applied. Each direction is configured to block, flag, or skip.
+package guard
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// Modes of a direction.
+const (
+	// ModeBlock refuses content with findings.
+	ModeBlock = "block"
+	// ModeFlag reports findings and lets the content through.
+	ModeFlag = "flag"
+	// ModeOff skips scanning.
+	ModeOff = "off"
+)
+
+// Modes are the valid modes.
+var Modes = []string{ModeBlock, ModeFlag, ModeOff}
+
+// Directions of content.
+const (
+	// DirectionPrompt is text sent to an agent, scanned with every rule.
+	DirectionPrompt = "prompt"
+	// DirectionOutput is text an agent produced, scanned with the
+	// KindDangerous rules.
+	DirectionOutput = "output"
+)
+
+// AllowMarker on a line suppresses findings for that line, such as a
+// documented install command in a README.
+const AllowMarker = "juleson:allow-unsafe"
+
+// maxMatch bounds the matched text repeated in a finding.
+const maxMatch = 80
+
+// Options configures a Guard.
+type Options struct {
+	// Prompts and Outputs are the modes of each direction; empty means
+	// ModeFlag for prompts and ModeBlock for outputs.
+	Prompts string
+	Outputs string
+	// Allow are rule IDs that never match.
+	Allow []string
+}
+
+// Guard scans content with DefaultRules.
+type Guard struct {
+	prompts string
+	outputs string
+	rules   []Rule
+}
+
+// New creates a guard.
+func New(options Options) *Guard {
+	g := &Guard{prompts: options.Prompts, outputs: options.Outputs}
+	if g.prompts == "" {
+		g.prompts = ModeFlag
+	}
+	if g.outputs == "" {
+		g.outputs = ModeBlock
+	}
+	for _, rule := range DefaultRules {
+		if !slices.Contains(options.Allow, rule.ID) {
+			g.rules = append(g.rules, rule)
+		}
+	}
+	return g
+}
+
+// Mode returns the mode of direction.
+func (g *Guard) Mode(direction string) string {
+	if direction == DirectionPrompt {
+		return g.prompts
+	}
+	return g.outputs
+}
+
+// ValidateRules rejects unknown rule IDs.
+func ValidateRules(ids []string) error {
+	for _, id := range ids {
+		if !slices.ContainsFunc(DefaultRules, func(rule Rule) bool { return rule.ID == id }) {
+			return fmt.Errorf("unknown rule %q", id)
+		}
+	}
+	return nil
+}
+
+// Finding is unsafe content.
+type Finding struct {
+	RuleID      string `json:"rule_id"`
+	Kind        string `json:"kind"`
+	Description string `json:"description"`
+	File        string `json:"file,omitempty"`
+	Line        int    `json:"line"`
+	Match       string `json:"match"`
+}
+
+func (f Finding) String() string {
+	location := "line " + strconv.Itoa(f.Line)
+	if f.File != "" {
+		location = f.File + ":" + strconv.Itoa(f.Line)
+	}
+	// %+q escapes the invisible characters some rules match.
+	return fmt.Sprintf("%s: %s (%s: %+q)", f.RuleID, f.Description, location, f.Match)
+}
+
+// Scan scans text line by line with the rules of direction.
+func (g *Guard) Scan(direction, text string) []Finding {
+	if g.Mode(direction) == ModeOff {
+		return nil
+	}
+	var findings []Finding
+	for i, line := range strings.Split(text, "\n") {
+		findings = append(findings, g.scanLine(direction, "", i+1, line)...)
+	}
+	return findings
+}
+
+// ScanPatch scans only the lines a unified diff adds, as output, reporting
+// them with their file and line number in the new version.
+func (g *Guard) ScanPatch(patch string) []Finding {
+	if g.outputs == ModeOff {
+		return nil
+	}
+	var (
+		findings []Finding
+		file     string
+		line     int
+	)
+	for _, text := range strings.Split(patch, "\n") {
+		switch {
+		case strings.HasPrefix(text, "+++ "):
+			file = strings.TrimPrefix(strings.TrimSpace(strings.TrimPrefix(text, "+++ ")), "b/")
+		case strings.HasPrefix(text, "@@"):
+			// @@ -a,b +c,d @@
+			if fields := strings.Fields(text); len(fields) >= 3 {
+				start, _, _ := strings.Cut(strings.TrimPrefix(fields[2], "+"), ",")
+				line, _ = strconv.Atoi(start)
+			}
+		case strings.HasPrefix(text, "+"):
+			findings = append(findings, g.scanLine(DirectionOutput, file, line, text[1:])...)
+			line++
+		case strings.HasPrefix(text, " "):
+			line++
+		}
+	}
+	return findings
+}
+
+func (g *Guard) scanLine(direction, file string, line int, text string) []Finding {
+	if strings.Contains(text, AllowMarker) {
+		return nil
+	}
+	var findings []Finding
+	for _, rule := range g.rules {
+		if direction == DirectionOutput && rule.Kind != KindDangerous {
+			continue
+		}
+		if match := rule.Pattern.FindString(text); match != "" {
+			if runes := []rune(match); len(runes) > maxMatch {
+				match = string(runes[:maxMatch]) + "…"
+			}
+			findings = append(findings, Finding{
+				RuleID:      rule.ID,
+				Kind:        rule.Kind,
+				Description: rule.Description,
+				File:        file,
+				Line:        line,
+				Match:       match,
+			})
+		}
+	}
+	return findings
+}
+
+// BlockedError reports content refused in ModeBlock.
+type BlockedError struct {
+	Direction string
+	// Subject names the content, such as "the prompt of a new session".
+	Subject  string
+	Findings []Finding
+}
+
+func (e *BlockedError) Error() string {
+	setting := "guard.prompts"
+	if e.Direction == DirectionOutput {
+		setting = "guard.outputs"
+	}
+	parts := make([]string, len(e.Findings))
+	for i, finding := range e.Findings {
+		parts[i] = finding.String()
+	}
+	return fmt.Sprintf("the content guard blocked %s: %s; set %s to flag to let it through",
+		e.Subject, strings.Join(parts, "; "), setting)
+}
+
+// Check scans text and returns the findings, with a *BlockedError when the
+// direction's mode is ModeBlock and there are any.
+func (g *Guard) Check(direction, subject, text string) ([]Finding, error) {
+	findings := g.Scan(direction, text)
+	if len(findings) > 0 && g.Mode(direction) == ModeBlock {
+		return findings, &BlockedError{Direction: direction, Subject: subject, Findings: findings}
+	}
+	return findings, nil
+}
@@ -0,0 +1,109 @@
+package guard
+
+import "regexp"
+
+// Rule kinds.
+const (
+	// KindInjection marks text that tries to override an agent's
+	// instructions or hide from the user.
+	KindInjection = "injection"
+	// KindDangerous marks instructions that destroy data or leak secrets.
+	KindDangerous = "dangerous"
+)
+
+// Rule matches one pattern of unsafe content.
+type Rule struct {
+	Pattern     *regexp.Regexp
+	ID          string
+	Kind        string
+	Description string
+}
+
+// DefaultRules cover common prompt-injection phrasings and destructive or
+// exfiltrating instructions, in shell and in prose.
+var DefaultRules = []Rule{
+	{
+		ID:          "ignore-instructions",
+		Kind:        KindInjection,
+		Description: "Asks to ignore earlier instructions",
+		Pattern:     regexp.MustCompile(`(?i)\b(?:ignore|disregard|forget|override)\b.{0,20}\b(?:all|any|the|your|previous|prior|above|earlier|preceding)\b.{0,20}\b(?:instructions|prompts?|rules|directions|guidelines)\b`),
+	},
+	{
+		ID:          "role-override",
+		Kind:        KindInjection,
+		Description: "Assigns the agent an unrestricted role",
+		Pattern:     regexp.MustCompile(`(?i)\byou are now (?:in )?(?:developer mode|god mode|jailbroken|DAN|unrestricted)\b|\bact as\b.{0,30}\bwithout (?:any )?(?:restrictions|limits|rules|filters)\b`),
+	},
+	{
+		ID:          "prompt-leak",
+		Kind:        KindInjection,
+		Description: "Asks the agent to reveal its instructions",
+		Pattern:     regexp.MustCompile(`(?i)\b(?:reveal|print|show|repeat|output)\b.{0,20}\b(?:system prompt|your (?:hidden |system )?instructions|hidden instructions)\b`),
+	},
+	{
+		ID:          "chat-markup",
+		Kind:        KindInjection,
+		Description: "Chat template markup that fakes a system or assistant turn",
+		Pattern:     regexp.MustCompile(`<\|im_start\|>|<\|im_end\|>|<\|system\|>|<\|assistant\|>|\[/?INST\]|<</?SYS>>`),
+	},
+	{
+		ID:          "hidden-characters",
+		Kind:        KindInjection,
+		Description: "Invisible or bidirectional control characters",
+		Pattern:     regexp.MustCompile(`[\x{200B}\x{200C}\x{200E}\x{200F}\x{202A}-\x{202E}\x{2066}-\x{2069}\x{E0000}-\x{E007F}]`),
+	},
+	{
+		ID:          "conceal-from-user",
+		Kind:        KindInjection,
+		Description: "Asks to keep actions from the user",
+		Pattern:     regexp.MustCompile(`(?i)\b(?:do not|don't|never)\s+(?:tell|inform|mention|reveal|show)\b.{0,20}\b(?:the )?(?:user|human|developer|reviewer|maintainers?)\b`),
+	},
+	{
+		ID:          "delete-git",
+		Kind:        KindDangerous,
+		Description: "Deletes the git directory",
+		Pattern:     regexp.MustCompile(`(?i)\brm\s+-[a-z]*r[a-z]*\s+(?:\S*/)?\.git(?:\s|/?$|/?[;&|'"\x60)])|\b(?:delete|remove|wipe|erase)\b.{0,20}\.git\b(?:\s+(?:directory|folder|dir))?(?:[^/\w-]|$)`),
+	},
+	{
+		ID:          "delete-root",
+		Kind:        KindDangerous,
+		Description: "Recursively deletes the root or home directory",
+		Pattern:     regexp.MustCompile(`\brm\s+-[a-zA-Z]*[rR][a-zA-Z]*\s+(?:--no-preserve-root\s+)?(?:/|/\*|~/?|\$HOME/?|\$\{HOME\}/?)(?:\s|[;&|]|$)`),
+	},
+	{
+		ID:          "wipe-disk",
+		Kind:        KindDangerous,
+		Description: "Overwrites a disk or file system",
+		Pattern:     regexp.MustCompile(`\bmkfs(?:\.\w+)?\s+/dev/|\bdd\s+if=\S+\s+of=/dev/(?:sd|hd|nvme|disk|xvd)`),
+	},
+	{
+		ID:          "fork-bomb",
+		Kind:        KindDangerous,
+		Description: "Fork bomb",
+		Pattern:     regexp.MustCompile(`:\(\)\s*\{\s*:\s*\|\s*:\s*&\s*\}\s*;\s*:`),
+	},
+	{
+		ID:          "pipe-to-shell",
+		Kind:        KindDangerous,
+		Description: "Runs a downloaded script",
+		Pattern:     regexp.MustCompile(`(?i)\b(?:curl|wget)\b[^|\n]*\|\s*(?:sudo\s+)?(?:ba|z|da)?sh\b`),
+	},
+	{
+		ID:          "exfiltrate-secrets",
+		Kind:        KindDangerous,
+		Description: "Sends credentials or the environment over the network",
+		Pattern:     regexp.MustCompile(`(?i)\b(?:curl|wget|nc|ncat|Invoke-WebRequest|Invoke-RestMethod)\b.{0,200}(?:\$\{?\w*(?:TOKEN|SECRET|API_KEY|PASSWORD|CREDENTIALS)\w*\}?|\$\{\{\s*secrets\.|\.ssh/id_|\.aws/credentials|\.netrc|/etc/shadow|\$\(\s*(?:env|printenv)\b)|\b(?:env|printenv|cat\s+\S*(?:\.env|id_rsa|id_ed25519|credentials|\.netrc))\b[^|\n]*\|\s*(?:curl|wget|nc|ncat)\b`),
+	},
+	{
+		ID:          "exfiltrate-secrets-prose",
+		Kind:        KindDangerous,
+		Description: "Asks to send credentials somewhere",
+		Pattern:     regexp.MustCompile(`(?i)\b(?:send|upload|post|exfiltrate|leak|email)\b.{0,40}\b(?:secrets|credentials|api keys?|access tokens?|tokens|passwords|private keys?|ssh keys?|\.env)\b.{0,40}\b(?:to|at)\s+(?:https?://|\S+@\S+\.\w+|an? (?:external|remote|third-party) (?:server|url|endpoint|address))`),
+	},
+	{
+		ID:          "force-push-default-branch",
+		Kind:        KindDangerous,
+		Description: "Force-pushes the default branch",
+		Pattern:     regexp.MustCompile(`\bgit\s+push\b[^\n]*(?:--force\b|\s-f\b)[^\n]*\b(?:main|master)\b|\bgit\s+push\b[^\n]*\b(?:main|master)\b[^\n]*(?:--force\b|\s-f\b)`),
+	},
+}
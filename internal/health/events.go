@@ -0,0 +1,78 @@
+package health
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/SamyRai/juleson/internal/events"
+)
+
+// CoordinatorComponents returns live components for an event coordinator's
+// event bus, message queue, and circuit breakers.
+func CoordinatorComponents(coordinator *events.EventCoordinator) []Component {
+	components := []Component{
+		{
+			Name:     "event-bus",
+			Live:     true,
+			Required: true,
+			Check: func(context.Context) Result {
+				if !coordinator.Running() {
+					return Down("event coordinator is not running")
+				}
+				return Up("running")
+			},
+		},
+		{
+			Name: "circuit-breakers",
+			Live: true,
+			Check: func(context.Context) Result {
+				return circuitBreakersResult(coordinator.GetCircuitBreakers())
+			},
+		},
+	}
+
+	if _, ok := coordinator.GetQueueMetrics(); ok {
+		components = append(components, Component{
+			Name:     "message-queue",
+			Live:     true,
+			Required: true,
+			Check: func(context.Context) Result {
+				if !coordinator.Running() {
+					return Down("event coordinator is not running")
+				}
+				metrics, _ := coordinator.GetQueueMetrics()
+				if metrics.DLQSize > 0 {
+					return Degraded(fmt.Sprintf("%d messages in the dead letter queue", metrics.DLQSize))
+				}
+				return Up(fmt.Sprintf("%d enqueued, %d processed", metrics.MessagesEnqueued, metrics.MessagesProcessed))
+			},
+		})
+	}
+
+	return components
+}
+
+func circuitBreakersResult(breakers map[string]*events.CircuitBreaker) Result {
+	var open, halfOpen []string
+	for name, breaker := range breakers {
+		switch breaker.GetState() {
+		case events.StateOpen:
+			open = append(open, name)
+		case events.StateHalfOpen:
+			halfOpen = append(halfOpen, name)
+		}
+	}
+	sort.Strings(open)
+	sort.Strings(halfOpen)
+
+	switch {
+	case len(open) > 0:
+		return Degraded("open: " + strings.Join(open, ", "))
+	case len(halfOpen) > 0:
+		return Degraded("half-open: " + strings.Join(halfOpen, ", "))
+	default:
+		return Up(fmt.Sprintf("%d closed", len(breakers)))
+	}
+}
@@ -0,0 +1,189 @@
+// Package health reports whether Juleson's long-running components are
+// working. Components such as the event bus or the Jules API register a
+// check; a Checker runs them concurrently and serves the results over HTTP
+// as /healthz (liveness) and /readyz (readiness).
+package health
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Status is the state of a component or of the whole process.
+type Status string
+
+// Statuses, from best to worst.
+const (
+	StatusUp       Status = "up"
+	StatusDegraded Status = "degraded"
+	StatusDown     Status = "down"
+)
+
+func (s Status) rank() int {
+	switch s {
+	case StatusUp:
+		return 0
+	case StatusDegraded:
+		return 1
+	default:
+		return 2
+	}
+}
+
+// Result is the outcome of one check.
+type Result struct {
+	Status Status
+	Detail string
+}
+
+// Up, Degraded, and Down build results.
+func Up(detail string) Result       { return Result{Status: StatusUp, Detail: detail} }
+func Degraded(detail string) Result { return Result{Status: StatusDegraded, Detail: detail} }
+func Down(detail string) Result     { return Result{Status: StatusDown, Detail: detail} }
+
+// Component is something whose health is checked.
+type Component struct {
+	Name string
+	// Check reports the component's status. It should return promptly
+	// once ctx is done.
+	Check func(ctx context.Context) Result
+	// Live marks in-process components included in liveness: /healthz
+	// fails while one is down. Checks of external services should not be
+	// live, so an outage elsewhere does not restart the process.
+	Live bool
+	// Required makes readiness fail while the component is down.
+	Required bool
+}
+
+// ComponentReport is the result of checking one component.
+type ComponentReport struct {
+	Name     string        `json:"name"`
+	Status   Status        `json:"status"`
+	Detail   string        `json:"detail,omitempty"`
+	Live     bool          `json:"live,omitempty"`
+	Required bool          `json:"required,omitempty"`
+	Duration time.Duration `json:"duration"`
+}
+
+// Report is the result of checking a set of components.
+type Report struct {
+	// Status is the worst status of the components.
+	Status     Status            `json:"status"`
+	Components []ComponentReport `json:"components"`
+	CheckedAt  time.Time         `json:"checked_at"`
+}
+
+// Live reports whether no live component is down.
+func (r Report) Live() bool {
+	for _, c := range r.Components {
+		if c.Live && c.Status == StatusDown {
+			return false
+		}
+	}
+	return true
+}
+
+// Ready reports whether no required component is down.
+func (r Report) Ready() bool {
+	for _, c := range r.Components {
+		if c.Required && c.Status == StatusDown {
+			return false
+		}
+	}
+	return true
+}
+
+// Checker runs component checks.
+type Checker struct {
+	// Timeout bounds each check (default 10s).
+	Timeout time.Duration
+
+	mu         sync.RWMutex
+	components []Component
+}
+
+// NewChecker creates a checker for the given components.
+func NewChecker(components ...Component) *Checker {
+	c := &Checker{Timeout: 10 * time.Second}
+	for _, component := range components {
+		c.Register(component)
+	}
+	return c
+}
+
+// Register adds a component, replacing any with the same name.
+func (c *Checker) Register(component Component) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for i, existing := range c.components {
+		if existing.Name == component.Name {
+			c.components[i] = component
+			return
+		}
+	}
+	c.components = append(c.components, component)
+}
+
+// Check runs every component's check concurrently. With liveOnly, only live
+// components are checked.
+func (c *Checker) Check(ctx context.Context, liveOnly bool) Report {
+	c.mu.RLock()
+	components := make([]Component, 0, len(c.components))
+	for _, component := range c.components {
+		if !liveOnly || component.Live {
+			components = append(components, component)
+		}
+	}
+	c.mu.RUnlock()
+
+	timeout := c.Timeout
+	if timeout <= 0 {
+		timeout = 10 * time.Second
+	}
+
+	report := Report{Status: StatusUp, Components: make([]ComponentReport, len(components)), CheckedAt: time.Now()}
+	var wg sync.WaitGroup
+	for i, component := range components {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			report.Components[i] = runCheck(ctx, component, timeout)
+		}()
+	}
+	wg.Wait()
+
+	sort.Slice(report.Components, func(i, j int) bool {
+		return report.Components[i].Name < report.Components[j].Name
+	})
+	for _, component := range report.Components {
+		if component.Status.rank() > report.Status.rank() {
+			report.Status = component.Status
+		}
+	}
+	return report
+}
+
+func runCheck(ctx context.Context, component Component, timeout time.Duration) (report ComponentReport) {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	start := time.Now()
+	report = ComponentReport{Name: component.Name, Live: component.Live, Required: component.Required}
+	defer func() {
+		if r := recover(); r != nil {
+			report.Status = StatusDown
+			report.Detail = "check panicked"
+		}
+		report.Duration = time.Since(start)
+	}()
+
+	result := component.Check(ctx)
+	if result.Status == "" {
+		result.Status = StatusDown
+	}
+	report.Status = result.Status
+	report.Detail = result.Detail
+	return report
+}
@@ -0,0 +1,93 @@
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/SamyRai/juleson/internal/events"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheckerReportsWorstStatus(t *testing.T) {
+	checker := NewChecker(
+		Component{Name: "bus", Live: true, Required: true, Check: func(context.Context) Result { return Up("running") }},
+		Component{Name: "github", Check: func(context.Context) Result { return Degraded("not configured") }},
+		Component{Name: "panics", Check: func(context.Context) Result { panic("boom") }},
+	)
+
+	report := checker.Check(context.Background(), false)
+	assert.Equal(t, StatusDown, report.Status)
+	assert.True(t, report.Live())
+	assert.True(t, report.Ready(), "only required components decide readiness")
+
+	names := make([]string, 0, len(report.Components))
+	for _, component := range report.Components {
+		names = append(names, component.Name)
+	}
+	assert.Equal(t, []string{"bus", "github", "panics"}, names)
+	assert.Equal(t, "check panicked", report.Components[2].Detail)
+
+	live := checker.Check(context.Background(), true)
+	require.Len(t, live.Components, 1)
+	assert.Equal(t, StatusUp, live.Status)
+}
+
+func TestHandlerServesLivenessAndReadiness(t *testing.T) {
+	jules := Down("unreachable")
+	checker := NewChecker(
+		Component{Name: "bus", Live: true, Required: true, Check: func(context.Context) Result { return Up("running") }},
+		Component{Name: "jules-api", Required: true, Check: func(context.Context) Result { return jules }},
+	)
+	server := httptest.NewServer(checker.Handler())
+	defer server.Close()
+
+	get := func(path string) (int, Report) {
+		resp, err := http.Get(server.URL + path)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		var report Report
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&report))
+		return resp.StatusCode, report
+	}
+
+	code, report := get("/healthz")
+	assert.Equal(t, http.StatusOK, code, "an external outage does not fail liveness")
+	assert.Len(t, report.Components, 1)
+
+	code, report = get("/readyz")
+	assert.Equal(t, http.StatusServiceUnavailable, code)
+	assert.Equal(t, StatusDown, report.Status)
+
+	jules = Up("reachable")
+	code, _ = get("/readyz")
+	assert.Equal(t, http.StatusOK, code)
+}
+
+func TestCoordinatorComponents(t *testing.T) {
+	config := events.DefaultCoordinatorConfig()
+	config.EnableStore = false
+	coordinator, err := events.NewEventCoordinator(config)
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	checker := NewChecker(CoordinatorComponents(coordinator)...)
+	assert.False(t, checker.Check(ctx, true).Live(), "not started")
+
+	require.NoError(t, coordinator.Start(ctx))
+	report := checker.Check(ctx, true)
+	assert.True(t, report.Live())
+	assert.Equal(t, StatusUp, report.Status)
+
+	breaker := coordinator.GetCircuitBreaker("jules-api", &events.CircuitBreakerConfig{Name: "jules-api", MaxFailures: 1})
+	_ = breaker.Execute(ctx, func(context.Context) error { return assert.AnError })
+	report = checker.Check(ctx, true)
+	assert.Equal(t, StatusDegraded, report.Status)
+	assert.True(t, report.Ready())
+
+	require.NoError(t, coordinator.Shutdown(ctx))
+	assert.False(t, checker.Check(ctx, true).Live())
+}
@@ -0,0 +1,69 @@
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"time"
+)
+
+// Handler serves /healthz, which checks live components and fails while
+// one is down, and /readyz, which checks every component and fails while a
+// required one is down. Both respond with the report as JSON, with status
+// 200 or 503.
+func (c *Checker) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /healthz", func(w http.ResponseWriter, r *http.Request) {
+		report := c.Check(r.Context(), true)
+		writeReport(w, report, report.Live())
+	})
+	mux.HandleFunc("GET /readyz", func(w http.ResponseWriter, r *http.Request) {
+		report := c.Check(r.Context(), false)
+		writeReport(w, report, report.Ready())
+	})
+	return mux
+}
+
+func writeReport(w http.ResponseWriter, report Report, ok bool) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	if ok {
+		w.WriteHeader(http.StatusOK)
+	} else {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}
+	_ = json.NewEncoder(w).Encode(report)
+}
+
+// Serve listens on addr and serves the checker's endpoints until ctx is
+// cancelled.
+func (c *Checker) Serve(ctx context.Context, addr string, logger *slog.Logger) error {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return fmt.Errorf("failed to listen for health checks: %w", err)
+	}
+
+	server := &http.Server{
+		Handler:           c.Handler(),
+		ReadHeaderTimeout: 5 * time.Second,
+	}
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), 5*time.Second)
+		defer cancel()
+		_ = server.Shutdown(shutdownCtx)
+	}()
+
+	logger.Info("serving health checks", "addr", listener.Addr().String())
+	if err := server.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return fmt.Errorf("health server failed: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,381 @@
+package hooks
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/SamyRai/juleson/internal/gemini"
+	"github.com/SamyRai/juleson/internal/intelligence"
+	"github.com/SamyRai/juleson/pkg/git"
+)
+
+// Checks a hook can run.
+const (
+	CheckSecrets = "secrets"
+	CheckSmells  = "smells"
+	CheckTests   = "tests"
+	CheckReview  = "review"
+)
+
+// Checks are every check, in the order they run.
+var Checks = []string{CheckSecrets, CheckSmells, CheckTests, CheckReview}
+
+// DefaultChecks are the checks of each hook when the configuration names
+// none: the fast ones before a commit, and the tests before a push.
+var DefaultChecks = map[string][]string{
+	PreCommit: {CheckSecrets, CheckSmells},
+	PrePush:   {CheckSecrets, CheckTests},
+}
+
+// blocking are the checks whose findings stop the commit or push; the
+// others only warn unless Options.Strict is set.
+var blocking = map[string]bool{CheckSecrets: true, CheckTests: true}
+
+// Result statuses.
+const (
+	StatusPassed  = "passed"
+	StatusFailed  = "failed"
+	StatusWarned  = "warned"
+	StatusSkipped = "skipped"
+)
+
+// zeroCommit is the object ID git passes to pre-push for a ref that does
+// not exist on one side.
+const zeroCommit = "0000000000000000000000000000000000000000"
+
+// Changes are what a commit or push adds.
+type Changes struct {
+	Diff string
+	// Truncated reports that Diff was cut at git.MaxDiffBytes.
+	Truncated bool
+	// Files are the slash-separated paths, relative to the repository
+	// root, of the files changed, including deleted ones.
+	Files []string
+}
+
+// StagedChanges returns the changes staged for the next commit.
+func StagedChanges(ctx context.Context, repo *git.Repo) (*Changes, error) {
+	diff, truncated, err := repo.Diff(ctx, git.DiffOptions{Staged: true})
+	if err != nil {
+		return nil, err
+	}
+	return &Changes{Diff: diff, Truncated: truncated, Files: diffFiles(diff)}, nil
+}
+
+// PushChanges returns the changes a push adds, reading the refs being
+// pushed from refs, in the "<local ref> <local sha> <remote ref> <remote
+// sha>" lines git gives pre-push. A new remote branch is compared with the
+// history already on a remote.
+func PushChanges(ctx context.Context, repo *git.Repo, refs io.Reader) (*Changes, error) {
+	changes := &Changes{}
+	scanner := bufio.NewScanner(refs)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) != 4 || fields[1] == zeroCommit {
+			// Deleting a remote ref adds nothing.
+			continue
+		}
+		local, base := fields[1], fields[3]
+		if base == zeroCommit {
+			var err error
+			if base, err = repo.OutgoingBase(ctx, local); err != nil {
+				return nil, err
+			}
+		}
+		if base == local {
+			continue
+		}
+		diff, truncated, err := repo.Diff(ctx, git.DiffOptions{Base: base, Target: local})
+		if err != nil {
+			return nil, err
+		}
+		changes.Diff += diff
+		changes.Truncated = changes.Truncated || truncated
+		for _, file := range diffFiles(diff) {
+			if !slices.Contains(changes.Files, file) {
+				changes.Files = append(changes.Files, file)
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return changes, nil
+}
+
+// diffFiles returns the paths a unified diff changes, from its ---/+++
+// headers.
+func diffFiles(diff string) []string {
+	var files []string
+	for _, line := range strings.Split(diff, "\n") {
+		var name string
+		switch {
+		case strings.HasPrefix(line, "--- a/"):
+			name = strings.TrimPrefix(line, "--- a/")
+		case strings.HasPrefix(line, "+++ b/"):
+			name = strings.TrimPrefix(line, "+++ b/")
+		default:
+			continue
+		}
+		if name = strings.TrimSuffix(name, "\t"); !slices.Contains(files, name) {
+			files = append(files, name)
+		}
+	}
+	return files
+}
+
+// Options configures Run.
+type Options struct {
+	// Checks are the checks to run; empty means DefaultChecks of the hook.
+	Checks []string
+	// Skip are checks not to run, such as those in SkipEnv.
+	Skip []string
+	// Strict makes the findings of every check stop the commit or push.
+	Strict bool
+	Smells intelligence.SmellOptions
+	// Gemini reviews the diff; nil skips the review.
+	Gemini *gemini.Client
+	// Output receives the output of test commands as they run; nil
+	// discards it.
+	Output io.Writer
+}
+
+// Finding is a problem a check found.
+type Finding struct {
+	File    string `json:"file,omitempty"`
+	Line    int    `json:"line,omitempty"`
+	Message string `json:"message"`
+}
+
+func (f Finding) String() string {
+	switch {
+	case f.File != "" && f.Line > 0:
+		return fmt.Sprintf("%s:%d: %s", f.File, f.Line, f.Message)
+	case f.File != "":
+		return fmt.Sprintf("%s: %s", f.File, f.Message)
+	}
+	return f.Message
+}
+
+// Result is the outcome of one check.
+type Result struct {
+	Check    string        `json:"check"`
+	Status   string        `json:"status"`
+	Findings []Finding     `json:"findings,omitempty"`
+	Note     string        `json:"note,omitempty"`
+	Duration time.Duration `json:"duration"`
+}
+
+// Report is the outcome of a hook's checks.
+type Report struct {
+	Hook    string   `json:"hook"`
+	Files   int      `json:"files"`
+	Results []Result `json:"results"`
+}
+
+// Failed reports whether a check stops the commit or push.
+func (r *Report) Failed() bool {
+	for _, result := range r.Results {
+		if result.Status == StatusFailed {
+			return true
+		}
+	}
+	return false
+}
+
+// ParseSkip reads the value of SkipEnv into check names.
+func ParseSkip(value string) []string {
+	value = strings.TrimSpace(value)
+	switch strings.ToLower(value) {
+	case "", "0", "false":
+		return nil
+	case "1", "true", "all":
+		return Checks
+	}
+	var skip []string
+	for _, check := range strings.Split(value, ",") {
+		if check = strings.TrimSpace(check); check != "" {
+			skip = append(skip, check)
+		}
+	}
+	return skip
+}
+
+// ValidateChecks rejects unknown check names.
+func ValidateChecks(checks []string) error {
+	for _, check := range checks {
+		if !slices.Contains(Checks, check) {
+			return fmt.Errorf("unknown check %q; checks are %s", check, strings.Join(Checks, ", "))
+		}
+	}
+	return nil
+}
+
+// Run runs the checks of hook on changes in the repository's working tree.
+// Every check runs, so the report shows all problems at once.
+func Run(ctx context.Context, repo *git.Repo, hook string, changes *Changes, options Options) *Report {
+	checks := options.Checks
+	if len(checks) == 0 {
+		checks = DefaultChecks[hook]
+	}
+	report := &Report{Hook: hook, Files: len(changes.Files), Results: []Result{}}
+	for _, check := range Checks {
+		if !slices.Contains(checks, check) {
+			continue
+		}
+		started := time.Now()
+		var result Result
+		switch {
+		case slices.Contains(options.Skip, check):
+			result = Result{Status: StatusSkipped, Note: "skipped on request"}
+		case len(changes.Files) == 0:
+			result = Result{Status: StatusSkipped, Note: "nothing changed"}
+		default:
+			result = runCheck(ctx, repo, check, changes, options)
+		}
+		result.Check = check
+		result.Duration = time.Since(started)
+		if result.Status == "" {
+			result.Status = StatusPassed
+			if len(result.Findings) > 0 {
+				result.Status = StatusWarned
+				if blocking[check] || options.Strict {
+					result.Status = StatusFailed
+				}
+			}
+		}
+		report.Results = append(report.Results, result)
+	}
+	return report
+}
+
+func runCheck(ctx context.Context, repo *git.Repo, check string, changes *Changes, options Options) Result {
+	switch check {
+	case CheckSecrets:
+		return checkSecrets(changes)
+	case CheckSmells:
+		return checkSmells(ctx, repo, changes, options.Smells)
+	case CheckTests:
+		return checkTests(ctx, repo, changes, options.Output)
+	case CheckReview:
+		return checkReview(ctx, options.Gemini, changes)
+	}
+	return Result{Status: StatusFailed, Note: "unknown check"}
+}
+
+// checkSecrets scans only the added lines.
+func checkSecrets(changes *Changes) Result {
+	var result Result
+	for _, finding := range intelligence.ScanPatchSecrets(changes.Diff) {
+		result.Findings = append(result.Findings, Finding{
+			File:    finding.File,
+			Line:    finding.Line,
+			Message: fmt.Sprintf("%s: %s (%s)", finding.RuleID, finding.Description, finding.Match),
+		})
+	}
+	if changes.Truncated {
+		result.Note = fmt.Sprintf("only the first %d bytes of the diff were scanned", git.MaxDiffBytes)
+	}
+	return result
+}
+
+// checkSmells analyzes the project, reusing cached results for unchanged
+// files, and reports the smells of the changed Go files.
+func checkSmells(ctx context.Context, repo *git.Repo, changes *Changes, options intelligence.SmellOptions) Result {
+	var goFiles []string
+	for _, file := range changes.Files {
+		if path.Ext(file) == ".go" && !strings.HasSuffix(file, "_test.go") {
+			goFiles = append(goFiles, file)
+		}
+	}
+	if len(goFiles) == 0 {
+		return Result{Status: StatusSkipped, Note: "no Go files changed"}
+	}
+	if options.Cache == nil {
+		options.Cache = intelligence.OpenProjectCache(repo.Root)
+	}
+	smells, err := intelligence.DetectSmells(ctx, repo.Root, options)
+	if err != nil {
+		return Result{Status: StatusFailed, Note: err.Error()}
+	}
+	_ = options.Cache.Save()
+	var result Result
+	for _, smell := range smells {
+		if slices.Contains(goFiles, smell.File) {
+			result.Findings = append(result.Findings, Finding{File: smell.File, Line: smell.Line, Message: smell.Name + ": " + smell.Message})
+		}
+	}
+	return result
+}
+
+// checkTests runs the tests of the packages and modules the changes affect,
+// found by the project's import graph.
+func checkTests(ctx context.Context, repo *git.Repo, changes *Changes, output io.Writer) Result {
+	cache := intelligence.OpenProjectCache(repo.Root)
+	graph, err := intelligence.BuildProjectGraphCached(ctx, repo.Root, cache)
+	if err != nil {
+		return Result{Status: StatusFailed, Note: fmt.Sprintf("dependency analysis failed: %v", err)}
+	}
+	_ = cache.Save()
+	commands := graph.ImpactAnalysis(changes.Files).TestCommands()
+	if len(commands) == 0 {
+		return Result{Status: StatusSkipped, Note: "no tests affected"}
+	}
+	if output == nil {
+		output = io.Discard
+	}
+	var result Result
+	for _, command := range commands {
+		fields := strings.Fields(command)
+		cmd := exec.CommandContext(ctx, fields[0], fields[1:]...)
+		cmd.Dir = repo.Root
+		var tail tailWriter
+		cmd.Stdout = io.MultiWriter(output, &tail)
+		cmd.Stderr = io.MultiWriter(output, &tail)
+		cmd.Env = os.Environ()
+		if err := cmd.Run(); err != nil {
+			message := fmt.Sprintf("%s: %v", command, err)
+			if lines := tail.String(); lines != "" {
+				message += "\n" + lines
+			}
+			result.Findings = append(result.Findings, Finding{Message: message})
+		}
+	}
+	result.Note = strings.Join(commands, "; ")
+	return result
+}
+
+// tailWriter keeps the last lines written to it.
+type tailWriter struct {
+	lines []string
+	part  string
+}
+
+const tailLines = 20
+
+func (w *tailWriter) Write(p []byte) (int, error) {
+	text := w.part + string(p)
+	lines := strings.Split(text, "\n")
+	w.part = lines[len(lines)-1]
+	w.lines = append(w.lines, lines[:len(lines)-1]...)
+	if len(w.lines) > tailLines {
+		w.lines = w.lines[len(w.lines)-tailLines:]
+	}
+	return len(p), nil
+}
+
+func (w *tailWriter) String() string {
+	lines := w.lines
+	if w.part != "" {
+		lines = append(lines, w.part)
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,176 @@
+// Package hooks installs git hooks that run juleson checks, and runs those
+// checks on what a commit or push adds: a secret scan of the added lines,
+// code smells in the changed Go files, the tests the changes affect, and a
+// Gemini review of the diff. Each check takes a fast path when the changes
+// give it nothing to do.
+package hooks
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/SamyRai/juleson/pkg/git"
+)
+
+// Hooks juleson installs.
+const (
+	PreCommit = "pre-commit"
+	PrePush   = "pre-push"
+)
+
+// Names are the hooks juleson can install.
+var Names = []string{PreCommit, PrePush}
+
+// SkipEnv skips checks for one git command, such as
+// JULESON_SKIP_HOOKS=review git commit: "1" or "all" skips every check, and
+// otherwise it is a comma-separated list of checks.
+const SkipEnv = "JULESON_SKIP_HOOKS"
+
+// marker identifies the hooks Install writes.
+const marker = "# Installed by juleson hooks install."
+
+// backupSuffix is added to a hook Install replaced with --force, and
+// restored by Uninstall.
+const backupSuffix = ".juleson-backup"
+
+// Hook states reported by State.
+const (
+	StateInstalled = "installed"
+	StateOther     = "other"
+	StateMissing   = "missing"
+)
+
+// Script returns the hook script for hook. It runs juleson from PATH, or
+// from executable when juleson is not on PATH, passing on the hook's
+// arguments and standard input.
+func Script(hook, executable string) string {
+	return fmt.Sprintf(`#!/bin/sh
+%s
+# Bypass with git --no-verify, or skip checks with %s=all or
+# %s=review,tests.
+if command -v juleson >/dev/null 2>&1; then
+	exec juleson hooks run %s "$@"
+fi
+exec %s hooks run %s "$@"
+`, marker, SkipEnv, SkipEnv, hook, shellQuote(executable), hook)
+}
+
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// Installed describes a hook written by Install.
+type Installed struct {
+	Hook string `json:"hook"`
+	Path string `json:"path"`
+	// Backup is where a hook that was replaced was moved.
+	Backup string `json:"backup,omitempty"`
+}
+
+// Install writes the hooks in names to the repository's hooks directory. A
+// hook juleson did not write is left alone unless force is set, in which
+// case it is moved aside for Uninstall to restore. A hook is never moved
+// over an earlier backup.
+func Install(ctx context.Context, repo *git.Repo, names []string, executable string, force bool) ([]Installed, error) {
+	dir, err := repo.GitPath(ctx, "hooks")
+	if err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+	var installed []Installed
+	for _, hook := range names {
+		if !slices.Contains(Names, hook) {
+			return installed, fmt.Errorf("unknown hook %q; hooks are %s", hook, strings.Join(Names, ", "))
+		}
+		path := filepath.Join(dir, hook)
+		result := Installed{Hook: hook, Path: path}
+		switch state, err := fileState(path); {
+		case err != nil:
+			return installed, err
+		case state == StateOther && !force:
+			return installed, fmt.Errorf("%s already exists and was not installed by juleson; pass --force to replace it", path)
+		case state == StateOther:
+			result.Backup = path + backupSuffix
+			if _, err := os.Lstat(result.Backup); err == nil {
+				return installed, fmt.Errorf("%s already exists; move it away before replacing %s", result.Backup, path)
+			} else if !errors.Is(err, os.ErrNotExist) {
+				return installed, err
+			}
+			if err := os.Rename(path, result.Backup); err != nil {
+				return installed, err
+			}
+		}
+		if err := os.WriteFile(path, []byte(Script(hook, executable)), 0o755); err != nil {
+			return installed, err
+		}
+		installed = append(installed, result)
+	}
+	return installed, nil
+}
+
+// Uninstall removes the hooks in names that juleson wrote, restoring the
+// hooks they replaced, and returns the paths removed.
+func Uninstall(ctx context.Context, repo *git.Repo, names []string) ([]string, error) {
+	dir, err := repo.GitPath(ctx, "hooks")
+	if err != nil {
+		return nil, err
+	}
+	var removed []string
+	for _, hook := range names {
+		path := filepath.Join(dir, hook)
+		state, err := fileState(path)
+		if err != nil {
+			return removed, err
+		}
+		if state != StateInstalled {
+			continue
+		}
+		if err := os.Remove(path); err != nil {
+			return removed, err
+		}
+		removed = append(removed, path)
+		if err := os.Rename(path+backupSuffix, path); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return removed, err
+		}
+	}
+	return removed, nil
+}
+
+// State reports, for each hook juleson can install, whether it is
+// installed, another hook is in its place, or there is none.
+func State(ctx context.Context, repo *git.Repo) (map[string]string, error) {
+	dir, err := repo.GitPath(ctx, "hooks")
+	if err != nil {
+		return nil, err
+	}
+	states := make(map[string]string, len(Names))
+	for _, hook := range Names {
+		state, err := fileState(filepath.Join(dir, hook))
+		if err != nil {
+			return nil, err
+		}
+		states[hook] = state
+	}
+	return states, nil
+}
+
+func fileState(path string) (string, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return StateMissing, nil
+	}
+	if err != nil {
+		return "", err
+	}
+	if strings.Contains(string(data), marker) {
+		return StateInstalled, nil
+	}
+	return StateOther, nil
+}
@@ -0,0 +1,205 @@
+package hooks
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/SamyRai/juleson/internal/intelligence"
+	"github.com/SamyRai/juleson/pkg/git"
+)
+
+// testAWSKey is split so this file does not trip secret scanners.
+const testAWSKey = "AKIA" + "QYLPMN5HHHFPZAM2"
+
+func newTestRepo(t *testing.T) *git.Repo {
+	t.Helper()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+	dir := t.TempDir()
+	for _, args := range [][]string{
+		{"init", "-q", "-b", "main"},
+		{"config", "user.email", "test@example.com"},
+		{"config", "user.name", "Test"},
+		{"config", "commit.gpgsign", "false"},
+	} {
+		runGit(t, dir, args...)
+	}
+	return &git.Repo{Root: dir}
+}
+
+func runGit(t *testing.T, dir string, args ...string) string {
+	t.Helper()
+	out, err := exec.Command("git", append([]string{"-C", dir}, args...)...).CombinedOutput()
+	if err != nil {
+		t.Fatalf("git %v: %v: %s", args, err, out)
+	}
+	return strings.TrimSpace(string(out))
+}
+
+func writeFile(t *testing.T, dir, name, content string) {
+	t.Helper()
+	path := filepath.Join(dir, filepath.FromSlash(name))
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestInstallAndUninstall(t *testing.T) {
+	ctx := context.Background()
+	repo := newTestRepo(t)
+	hooksDir := filepath.Join(repo.Root, ".git", "hooks")
+	writeFile(t, hooksDir, PrePush, "#!/bin/sh\necho mine\n")
+
+	if _, err := Install(ctx, repo, Names, "/usr/local/bin/juleson", false); err == nil || !strings.Contains(err.Error(), "--force") {
+		t.Fatalf("Install() over a foreign hook error = %v", err)
+	}
+	installed, err := Install(ctx, repo, Names, "/usr/local/bin/juleson", true)
+	if err != nil {
+		t.Fatalf("Install() error = %v", err)
+	}
+	if len(installed) != 2 || installed[1].Backup != filepath.Join(hooksDir, PrePush+backupSuffix) {
+		t.Fatalf("installed = %+v", installed)
+	}
+	script, err := os.ReadFile(filepath.Join(hooksDir, PreCommit))
+	if err != nil || !strings.Contains(string(script), "exec juleson hooks run pre-commit") || !strings.Contains(string(script), "'/usr/local/bin/juleson'") {
+		t.Fatalf("pre-commit script = %q, %v", script, err)
+	}
+	states, err := State(ctx, repo)
+	if err != nil || states[PreCommit] != StateInstalled || states[PrePush] != StateInstalled {
+		t.Fatalf("State() = %v, %v", states, err)
+	}
+
+	removed, err := Uninstall(ctx, repo, Names)
+	if err != nil || len(removed) != 2 {
+		t.Fatalf("Uninstall() = %v, %v", removed, err)
+	}
+	restored, err := os.ReadFile(filepath.Join(hooksDir, PrePush))
+	if err != nil || !strings.Contains(string(restored), "echo mine") {
+		t.Errorf("pre-push was not restored: %q, %v", restored, err)
+	}
+	states, _ = State(ctx, repo)
+	if states[PreCommit] != StateMissing || states[PrePush] != StateOther {
+		t.Errorf("State() after Uninstall() = %v", states)
+	}
+}
+
+func TestInstallKeepsExistingBackup(t *testing.T) {
+	ctx := context.Background()
+	repo := newTestRepo(t)
+	hooksDir := filepath.Join(repo.Root, ".git", "hooks")
+	writeFile(t, hooksDir, PrePush, "#!/bin/sh\necho mine\n")
+	writeFile(t, hooksDir, PrePush+backupSuffix, "#!/bin/sh\necho older\n")
+
+	if _, err := Install(ctx, repo, []string{PrePush}, "/usr/local/bin/juleson", true); err == nil || !strings.Contains(err.Error(), backupSuffix) {
+		t.Fatalf("Install() over an existing backup error = %v", err)
+	}
+	for name, want := range map[string]string{PrePush: "echo mine", PrePush + backupSuffix: "echo older"} {
+		if data, err := os.ReadFile(filepath.Join(hooksDir, name)); err != nil || !strings.Contains(string(data), want) {
+			t.Errorf("%s = %q, %v; want it unchanged", name, data, err)
+		}
+	}
+}
+
+func TestRunPreCommit(t *testing.T) {
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+	ctx := context.Background()
+	repo := newTestRepo(t)
+	writeFile(t, repo.Root, "go.mod", "module example.com/app\n\ngo 1.22\n")
+	runGit(t, repo.Root, "add", "go.mod")
+	runGit(t, repo.Root, "commit", "-q", "-m", "init")
+
+	changes, err := StagedChanges(ctx, repo)
+	if err != nil {
+		t.Fatal(err)
+	}
+	report := Run(ctx, repo, PreCommit, changes, Options{})
+	if report.Failed() || len(report.Results) != 2 || report.Results[0].Note != "nothing changed" {
+		t.Fatalf("report with nothing staged = %+v", report)
+	}
+
+	writeFile(t, repo.Root, "config.go", "package app\n\nvar key = \""+testAWSKey+"\"\n")
+	writeFile(t, repo.Root, "README.md", "docs\n")
+	runGit(t, repo.Root, "add", "config.go", "README.md")
+	changes, err = StagedChanges(ctx, repo)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(changes.Files, []string{"README.md", "config.go"}) {
+		t.Errorf("Files = %v", changes.Files)
+	}
+	report = Run(ctx, repo, PreCommit, changes, Options{Smells: intelligence.DefaultSmellOptions()})
+	if !report.Failed() {
+		t.Fatalf("a staged secret did not block: %+v", report)
+	}
+	secrets := report.Results[0]
+	if secrets.Check != CheckSecrets || secrets.Status != StatusFailed || secrets.Findings[0].File != "config.go" || secrets.Findings[0].Line != 3 {
+		t.Errorf("secrets = %+v", secrets)
+	}
+	if smells := report.Results[1]; smells.Status != StatusPassed {
+		t.Errorf("smells = %+v", smells)
+	}
+
+	report = Run(ctx, repo, PreCommit, changes, Options{Skip: ParseSkip("secrets")})
+	if report.Failed() || report.Results[0].Status != StatusSkipped {
+		t.Errorf("skipped secrets still ran: %+v", report.Results[0])
+	}
+}
+
+func TestPushChanges(t *testing.T) {
+	ctx := context.Background()
+	repo := newTestRepo(t)
+	writeFile(t, repo.Root, "a.txt", "a\n")
+	runGit(t, repo.Root, "add", "a.txt")
+	runGit(t, repo.Root, "commit", "-q", "-m", "a")
+	pushed := runGit(t, repo.Root, "rev-parse", "HEAD")
+	writeFile(t, repo.Root, "b.txt", "b\n")
+	runGit(t, repo.Root, "add", "b.txt")
+	runGit(t, repo.Root, "commit", "-q", "-m", "b")
+	head := runGit(t, repo.Root, "rev-parse", "HEAD")
+
+	refs := "refs/heads/main " + head + " refs/heads/main " + pushed + "\n" +
+		"(delete) " + zeroCommit + " refs/heads/old " + pushed + "\n"
+	changes, err := PushChanges(ctx, repo, strings.NewReader(refs))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(changes.Files, []string{"b.txt"}) {
+		t.Errorf("Files of an update = %v", changes.Files)
+	}
+
+	runGit(t, repo.Root, "update-ref", "refs/remotes/origin/main", pushed)
+	changes, err = PushChanges(ctx, repo, strings.NewReader("refs/heads/feature "+head+" refs/heads/feature "+zeroCommit+"\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(changes.Files, []string{"b.txt"}) {
+		t.Errorf("Files of a new branch = %v", changes.Files)
+	}
+}
+
+func TestParseSkip(t *testing.T) {
+	tests := map[string][]string{
+		"":              nil,
+		"0":             nil,
+		"all":           Checks,
+		"1":             Checks,
+		"review, tests": {"review", "tests"},
+	}
+	for value, want := range tests {
+		if got := ParseSkip(value); !reflect.DeepEqual(got, want) {
+			t.Errorf("ParseSkip(%q) = %v, want %v", value, got, want)
+		}
+	}
+	if err := ValidateChecks([]string{"secrets", "lint"}); err == nil {
+		t.Error("ValidateChecks() accepted an unknown check")
+	}
+}
@@ -0,0 +1,56 @@
+package hooks
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/SamyRai/juleson/internal/gemini"
+)
+
+// maxReviewBytes is the largest diff sent for review; larger changes take
+// the fast path and are not reviewed.
+const maxReviewBytes = 64 << 10
+
+const reviewPrompt = `You review a code change before it is committed or pushed.
+Report only problems a careful reviewer would block on: bugs, unhandled
+errors, security issues, race conditions, and leftover debugging code. Do not
+comment on style or naming. Give the file and the line in the new version for
+each issue. Return no issues when the change looks correct.`
+
+var reviewSchema = &gemini.Schema{
+	Type: gemini.TypeObject,
+	Properties: map[string]*gemini.Schema{
+		"issues": {
+			Type: gemini.TypeArray,
+			Items: &gemini.Schema{
+				Type: gemini.TypeObject,
+				Properties: map[string]*gemini.Schema{
+					"file":    {Type: gemini.TypeString},
+					"line":    {Type: gemini.TypeInteger},
+					"message": {Type: gemini.TypeString},
+				},
+				Required: []string{"message"},
+			},
+		},
+	},
+	Required: []string{"issues"},
+}
+
+// checkReview asks Gemini to review the diff.
+func checkReview(ctx context.Context, client *gemini.Client, changes *Changes) Result {
+	if client == nil {
+		return Result{Status: StatusSkipped, Note: "Gemini is not configured"}
+	}
+	if len(changes.Diff) > maxReviewBytes || changes.Truncated {
+		return Result{Status: StatusSkipped, Note: fmt.Sprintf("the diff exceeds %d KiB", maxReviewBytes>>10)}
+	}
+	var answer struct {
+		Issues []Finding `json:"issues"`
+	}
+	model, err := client.Route(gemini.SiteReview).Generate(ctx, reviewPrompt, "```diff\n"+changes.Diff+"```\n", reviewSchema, &answer)
+	if err != nil {
+		// An unreachable reviewer must not block work.
+		return Result{Status: StatusSkipped, Note: fmt.Sprintf("review failed: %v", err)}
+	}
+	return Result{Findings: answer.Issues, Note: "reviewed by " + model}
+}
@@ -0,0 +1,189 @@
+// Package insights computes which templates, tasks, and operator decisions
+// go with successful runs, from the run history and the audit log.
+package insights
+
+import (
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/SamyRai/juleson/internal/events"
+)
+
+// Outcome counts successes among attempts.
+type Outcome struct {
+	Attempts  int `json:"attempts"`
+	Succeeded int `json:"succeeded"`
+}
+
+// SuccessRate is the share of attempts that succeeded, from 0 to 1.
+func (o Outcome) SuccessRate() float64 {
+	if o.Attempts == 0 {
+		return 0
+	}
+	return float64(o.Succeeded) / float64(o.Attempts)
+}
+
+func (o *Outcome) add(succeeded bool) {
+	o.Attempts++
+	if succeeded {
+		o.Succeeded++
+	}
+}
+
+// TemplateInsight is how a template's runs went.
+type TemplateInsight struct {
+	Template string `json:"template"`
+	Outcome
+	// AverageDuration is the mean duration of finished runs.
+	AverageDuration time.Duration `json:"average_duration"`
+}
+
+// TaskInsight is how one task of a template, and so its prompt, went
+// across runs.
+type TaskInsight struct {
+	Template string `json:"template"`
+	Task     string `json:"task"`
+	Outcome
+	AverageDuration time.Duration `json:"average_duration"`
+	// LastError is the error of the most recent failure.
+	LastError string `json:"last_error,omitempty"`
+}
+
+// DecisionInsight compares the tasks whose session got a decision, such as a
+// plan approval or a message, with the tasks whose session did not.
+type DecisionInsight struct {
+	Action  string  `json:"action"`
+	With    Outcome `json:"with"`
+	Without Outcome `json:"without"`
+}
+
+// Lift is the difference in success rate between tasks with and without the
+// decision, from -1 to 1.
+func (d DecisionInsight) Lift() float64 {
+	return d.With.SuccessRate() - d.Without.SuccessRate()
+}
+
+// Insights are computed over the finished runs in the history.
+type Insights struct {
+	Runs      int               `json:"runs"`
+	Templates []TemplateInsight `json:"templates"`
+	Tasks     []TaskInsight     `json:"tasks"`
+	Decisions []DecisionInsight `json:"decisions"`
+}
+
+// Compute derives insights from runs. decisions maps session IDs to the
+// audited actions taken on them. Runs still running are skipped.
+func Compute(runs []events.RunRecord, decisions map[string][]string) Insights {
+	type durations struct {
+		total time.Duration
+		count int
+	}
+	average := func(d durations) time.Duration {
+		if d.count == 0 {
+			return 0
+		}
+		return (d.total / time.Duration(d.count)).Round(time.Second)
+	}
+
+	templates := map[string]*TemplateInsight{}
+	templateDurations := map[string]*durations{}
+	tasks := map[string]*TaskInsight{}
+	taskDurations := map[string]*durations{}
+	actions := map[string]*DecisionInsight{}
+	result := Insights{Templates: []TemplateInsight{}, Tasks: []TaskInsight{}, Decisions: []DecisionInsight{}}
+
+	// Collect the actions first so every task counts toward each action's
+	// with or without side.
+	for _, sessionActions := range decisions {
+		for _, action := range sessionActions {
+			if actions[action] == nil {
+				actions[action] = &DecisionInsight{Action: action}
+			}
+		}
+	}
+
+	sorted := slices.Clone(runs)
+	slices.SortFunc(sorted, func(a, b events.RunRecord) int { return a.Started.Compare(b.Started) })
+	for _, run := range sorted {
+		if run.Status == events.RunStatusRunning {
+			continue
+		}
+		result.Runs++
+		template := templates[run.Workflow]
+		if template == nil {
+			template = &TemplateInsight{Template: run.Workflow}
+			templates[run.Workflow] = template
+			templateDurations[run.Workflow] = &durations{}
+		}
+		template.add(run.Status == events.RunStatusCompleted)
+		if run.Duration > 0 {
+			templateDurations[run.Workflow].total += run.Duration
+			templateDurations[run.Workflow].count++
+		}
+
+		for _, task := range run.Tasks {
+			if task.Status != "completed" && task.Status != "failed" {
+				continue
+			}
+			succeeded := task.Status == "completed"
+			key := run.Workflow + "\x00" + task.Name
+			insight := tasks[key]
+			if insight == nil {
+				insight = &TaskInsight{Template: run.Workflow, Task: task.Name}
+				tasks[key] = insight
+				taskDurations[key] = &durations{}
+			}
+			insight.add(succeeded)
+			if !succeeded && task.Error != "" {
+				insight.LastError = task.Error
+			}
+			if task.Duration > 0 {
+				taskDurations[key].total += task.Duration
+				taskDurations[key].count++
+			}
+
+			taken := decisions[task.SessionID]
+			for action, decision := range actions {
+				if task.SessionID != "" && slices.Contains(taken, action) {
+					decision.With.add(succeeded)
+				} else {
+					decision.Without.add(succeeded)
+				}
+			}
+		}
+	}
+
+	for name, template := range templates {
+		template.AverageDuration = average(*templateDurations[name])
+		result.Templates = append(result.Templates, *template)
+	}
+	for key, task := range tasks {
+		task.AverageDuration = average(*taskDurations[key])
+		result.Tasks = append(result.Tasks, *task)
+	}
+	for _, decision := range actions {
+		if decision.With.Attempts > 0 {
+			result.Decisions = append(result.Decisions, *decision)
+		}
+	}
+
+	// Most attempted first, then by name, so the output is stable.
+	slices.SortFunc(result.Templates, func(a, b TemplateInsight) int {
+		return byAttempts(a.Outcome, b.Outcome, a.Template, b.Template)
+	})
+	slices.SortFunc(result.Tasks, func(a, b TaskInsight) int {
+		return byAttempts(a.Outcome, b.Outcome, a.Template+"/"+a.Task, b.Template+"/"+b.Task)
+	})
+	slices.SortFunc(result.Decisions, func(a, b DecisionInsight) int {
+		return byAttempts(a.With, b.With, a.Action, b.Action)
+	})
+	return result
+}
+
+func byAttempts(a, b Outcome, aName, bName string) int {
+	if a.Attempts != b.Attempts {
+		return b.Attempts - a.Attempts
+	}
+	return strings.Compare(aName, bName)
+}
@@ -0,0 +1,76 @@
+package insights
+
+import (
+	"testing"
+	"time"
+
+	"github.com/SamyRai/juleson/internal/events"
+)
+
+func run(id, template, status string, started time.Time, duration time.Duration, tasks ...events.RunTask) events.RunRecord {
+	return events.RunRecord{ID: id, Workflow: template, Status: status, Started: started, Duration: duration, Tasks: tasks}
+}
+
+func TestCompute(t *testing.T) {
+	start := time.Date(2026, 10, 1, 9, 0, 0, 0, time.UTC)
+	runs := []events.RunRecord{
+		run("r2", "refactor", events.RunStatusFailed, start.Add(time.Hour), 3*time.Minute,
+			events.RunTask{Name: "api", Status: "completed", SessionID: "s3", Duration: time.Minute},
+			events.RunTask{Name: "web", Status: "failed", SessionID: "s4", Error: "tests failed"}),
+		run("r1", "refactor", events.RunStatusCompleted, start, time.Minute,
+			events.RunTask{Name: "api", Status: "completed", SessionID: "s1", Duration: 3 * time.Minute},
+			events.RunTask{Name: "web", Status: "completed", SessionID: "s2"}),
+		run("r3", "docs", events.RunStatusCompleted, start, 0,
+			events.RunTask{Name: "readme", Status: "completed", SessionID: "s5"},
+			events.RunTask{Name: "skipped", Status: "started"}),
+		run("r4", "docs", events.RunStatusRunning, start, 0),
+	}
+	decisions := map[string][]string{
+		"s1": {"session.approve_plan"},
+		"s3": {"session.approve_plan", "session.message"},
+		"s4": {"session.message"},
+	}
+
+	result := Compute(runs, decisions)
+	if result.Runs != 3 {
+		t.Fatalf("Runs = %d, want 3", result.Runs)
+	}
+
+	if len(result.Templates) != 2 {
+		t.Fatalf("Templates = %+v", result.Templates)
+	}
+	refactor := result.Templates[0]
+	if refactor.Template != "refactor" || refactor.Attempts != 2 || refactor.Succeeded != 1 || refactor.AverageDuration != 2*time.Minute {
+		t.Errorf("refactor = %+v", refactor)
+	}
+
+	if len(result.Tasks) != 3 {
+		t.Fatalf("Tasks = %+v", result.Tasks)
+	}
+	api := result.Tasks[0]
+	if api.Task != "api" || api.SuccessRate() != 1 || api.AverageDuration != 2*time.Minute {
+		t.Errorf("api = %+v", api)
+	}
+	web := result.Tasks[1]
+	if web.Task != "web" || web.Succeeded != 1 || web.LastError != "tests failed" {
+		t.Errorf("web = %+v", web)
+	}
+
+	if len(result.Decisions) != 2 {
+		t.Fatalf("Decisions = %+v", result.Decisions)
+	}
+	approve, message := result.Decisions[0], result.Decisions[1]
+	if approve.Action != "session.approve_plan" || approve.With != (Outcome{2, 2}) || approve.Without != (Outcome{3, 2}) {
+		t.Errorf("approve = %+v", approve)
+	}
+	if message.Action != "session.message" || message.With != (Outcome{2, 1}) || message.Lift() >= 0 {
+		t.Errorf("message = %+v, lift %v", message, message.Lift())
+	}
+}
+
+func TestComputeEmpty(t *testing.T) {
+	result := Compute(nil, nil)
+	if result.Runs != 0 || result.Templates == nil || result.Tasks == nil || result.Decisions == nil {
+		t.Errorf("Compute(nil) = %+v", result)
+	}
+}
@@ -0,0 +1,251 @@
+package integrations
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/SamyRai/juleson/internal/events"
+)
+
+type fakeTracker struct {
+	name    string
+	created []NewIssue
+	calls   []string
+}
+
+func (t *fakeTracker) Name() string { return t.name }
+
+func (t *fakeTracker) CreateIssue(ctx context.Context, issue NewIssue) (IssueRef, error) {
+	t.created = append(t.created, issue)
+	key := "ABC-" + string(rune('0'+len(t.created)))
+	return IssueRef{Key: key, URL: "https://tracker.example.com/" + key}, nil
+}
+
+func (t *fakeTracker) Transition(ctx context.Context, key string, status Status) error {
+	t.calls = append(t.calls, "transition "+key+" "+string(status))
+	return nil
+}
+
+func (t *fakeTracker) Link(ctx context.Context, key, title, url string) error {
+	t.calls = append(t.calls, "link "+key+" "+url)
+	return nil
+}
+
+func (t *fakeTracker) Comment(ctx context.Context, key, body string) error {
+	t.calls = append(t.calls, "comment "+key+" "+body)
+	return nil
+}
+
+func taskEvent(eventType events.EventType, errMessage string) events.Event {
+	return events.NewEvent(eventType, "task", events.TaskEventData{
+		TaskID:   "lint",
+		TaskName: "Fix lint warnings",
+		Error:    errMessage,
+		Metadata: map[string]interface{}{"workflow": "cleanup"},
+	}).WithTopic(events.TopicTask)
+}
+
+func TestMirrorLinksTaskSessionsToTaskIssue(t *testing.T) {
+	tracker := &fakeTracker{name: "jira"}
+	store := NewStore(filepath.Join(t.TempDir(), "issues.json"))
+	mirror := NewMirror([]Tracker{tracker}, store, nil)
+	ctx := context.Background()
+
+	for _, event := range []events.Event{
+		taskEvent(events.EventTaskStarted, ""),
+		events.NewEvent(events.EventSessionCreated, "session", events.SessionEventData{
+			SessionID: "s1", URL: "https://jules.google.com/session/s1",
+			Metadata: map[string]interface{}{"workflow": "cleanup", "task": "lint"},
+		}).WithTopic(events.TopicSession),
+		events.NewEvent(events.EventSessionCompleted, "session", events.SessionEventData{SessionID: "s1"}).WithTopic(events.TopicSession),
+		events.NewEvent(events.EventPRCreated, "session", events.GitHubEventData{
+			Repository: "acme/widgets", PRNumber: 7, PRURL: "https://github.com/acme/widgets/pull/7",
+		}).WithTopic(events.TopicGitHub).WithMetadata("session_id", "s1"),
+		taskEvent(events.EventTaskFailed, "checks failed"),
+	} {
+		if err := mirror.Handle(ctx, event); err != nil {
+			t.Fatalf("Handle(%s) error = %v", event.Type, err)
+		}
+	}
+
+	if len(tracker.created) != 1 || tracker.created[0].Title != "cleanup: lint" || tracker.created[0].Description != "Fix lint warnings" {
+		t.Fatalf("created = %+v", tracker.created)
+	}
+	want := []string{
+		"transition ABC-1 started",
+		"link ABC-1 https://jules.google.com/session/s1",
+		"link ABC-1 https://github.com/acme/widgets/pull/7",
+		"comment ABC-1 Task failed: checks failed",
+		"transition ABC-1 failed",
+	}
+	if !reflect.DeepEqual(tracker.calls, want) {
+		t.Fatalf("calls = %q, want %q", tracker.calls, want)
+	}
+
+	stored, ok := NewStore(store.path).Session("s1")
+	if !ok || stored.Task != "cleanup/lint" || stored.Issues["jira"].Key != "ABC-1" {
+		t.Fatalf("stored = %+v, %v", stored, ok)
+	}
+}
+
+func TestMirrorCreatesIssueForStandaloneSession(t *testing.T) {
+	tracker := &fakeTracker{name: "linear"}
+	mirror := NewMirror([]Tracker{tracker}, nil, nil)
+	failed := events.NewEvent(events.EventSessionFailed, "session", events.SessionEventData{
+		SessionID: "s2", Title: "Upgrade deps", SourceID: "sources/github/acme/widgets", Error: "quota exceeded",
+	}).WithTopic(events.TopicSession)
+
+	if err := mirror.Handle(context.Background(), failed); err != nil {
+		t.Fatalf("Handle() error = %v", err)
+	}
+	if len(tracker.created) != 1 || tracker.created[0].Title != "Upgrade deps" ||
+		tracker.created[0].Description != "Jules session s2 on sources/github/acme/widgets" {
+		t.Fatalf("created = %+v", tracker.created)
+	}
+	want := []string{"transition ABC-1 started", "comment ABC-1 Session failed: quota exceeded", "transition ABC-1 failed"}
+	if !reflect.DeepEqual(tracker.calls, want) {
+		t.Fatalf("calls = %q, want %q", tracker.calls, want)
+	}
+}
+
+func TestJiraTracker(t *testing.T) {
+	var requests []string
+	var created map[string]map[string]any
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r.Method+" "+r.URL.Path)
+		if user, token, ok := r.BasicAuth(); !ok || user != "bot@acme.com" || token != "secret" {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		switch r.Method + " " + r.URL.Path {
+		case "POST /rest/api/2/issue":
+			_ = json.NewDecoder(r.Body).Decode(&created)
+			_, _ = w.Write([]byte(`{"key":"WID-5"}`))
+		case "GET /rest/api/2/issue/WID-5/transitions":
+			_, _ = w.Write([]byte(`{"transitions":[{"id":"11","name":"Start","to":{"name":"In Progress"}},{"id":"31","name":"Finish","to":{"name":"Done"}}]}`))
+		case "POST /rest/api/2/issue/WID-5/transitions":
+			var body map[string]map[string]string
+			_ = json.NewDecoder(r.Body).Decode(&body)
+			if body["transition"]["id"] != "11" {
+				http.Error(w, "wrong transition", http.StatusBadRequest)
+			}
+		default:
+			w.WriteHeader(http.StatusCreated)
+		}
+	}))
+	defer server.Close()
+
+	tracker := NewJiraTracker(JiraConfig{
+		BaseURL: server.URL + "/", Email: "bot@acme.com", Token: "secret", Project: "WID",
+		Labels: []string{"jules"}, Transitions: map[Status]string{StatusStarted: "in progress"},
+	})
+	ctx := context.Background()
+	ref, err := tracker.CreateIssue(ctx, NewIssue{Title: "Fix lint", Description: "details"})
+	if err != nil || ref.Key != "WID-5" || ref.URL != server.URL+"/browse/WID-5" {
+		t.Fatalf("CreateIssue() = %+v, %v", ref, err)
+	}
+	if created["fields"]["summary"] != "Fix lint" || !reflect.DeepEqual(created["fields"]["issuetype"], map[string]any{"name": "Task"}) {
+		t.Fatalf("create body = %v", created)
+	}
+	if err := tracker.Transition(ctx, "WID-5", StatusStarted); err != nil {
+		t.Fatalf("Transition() error = %v", err)
+	}
+	if err := tracker.Transition(ctx, "WID-5", StatusCompleted); err != nil {
+		t.Fatalf("Transition(unconfigured) error = %v", err)
+	}
+	if err := tracker.Link(ctx, "WID-5", "PR", "https://github.com/acme/widgets/pull/7"); err != nil {
+		t.Fatalf("Link() error = %v", err)
+	}
+	want := []string{
+		"POST /rest/api/2/issue",
+		"GET /rest/api/2/issue/WID-5/transitions",
+		"POST /rest/api/2/issue/WID-5/transitions",
+		"POST /rest/api/2/issue/WID-5/remotelink",
+	}
+	if !reflect.DeepEqual(requests, want) {
+		t.Fatalf("requests = %q, want %q", requests, want)
+	}
+
+	tracker.cfg.Transitions[StatusFailed] = "Rejected"
+	if err := tracker.Transition(ctx, "WID-5", StatusFailed); err == nil || !strings.Contains(err.Error(), `no transition to "Rejected"`) {
+		t.Fatalf("Transition(missing) error = %v", err)
+	}
+}
+
+func TestLinearTracker(t *testing.T) {
+	var queries []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "lin_api_key" {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		var body struct {
+			Query     string         `json:"query"`
+			Variables map[string]any `json:"variables"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		var operation string
+		for _, name := range []string{"issueCreate", "issueUpdate", "attachmentLinkURL", "team"} {
+			if strings.Contains(body.Query, name+"(") {
+				operation = name
+				break
+			}
+		}
+		queries = append(queries, operation)
+		switch operation {
+		case "issueCreate":
+			_, _ = w.Write([]byte(`{"data":{"issueCreate":{"success":true,"issue":{"identifier":"ENG-9","url":"https://linear.app/acme/issue/ENG-9"}}}}`))
+		case "team":
+			_, _ = w.Write([]byte(`{"data":{"team":{"states":{"nodes":[{"id":"state-1","name":"In Progress"},{"id":"state-2","name":"Done"}]}}}}`))
+		case "issueUpdate":
+			if body.Variables["stateId"] != "state-2" {
+				_, _ = w.Write([]byte(`{"errors":[{"message":"wrong state"}]}`))
+				return
+			}
+			_, _ = w.Write([]byte(`{"data":{"issueUpdate":{"success":true}}}`))
+		case "attachmentLinkURL":
+			_, _ = w.Write([]byte(`{"errors":[{"message":"Entity not found"}]}`))
+		}
+	}))
+	defer server.Close()
+
+	tracker := NewLinearTracker(LinearConfig{
+		APIKey: "lin_api_key", TeamID: "team-1", Endpoint: server.URL,
+		States: map[Status]string{StatusCompleted: "done", StatusFailed: "Canceled"},
+	})
+	ctx := context.Background()
+	ref, err := tracker.CreateIssue(ctx, NewIssue{Title: "Fix lint"})
+	if err != nil || ref.Key != "ENG-9" || ref.URL != "https://linear.app/acme/issue/ENG-9" {
+		t.Fatalf("CreateIssue() = %+v, %v", ref, err)
+	}
+	if err := tracker.Transition(ctx, "ENG-9", StatusCompleted); err != nil {
+		t.Fatalf("Transition() error = %v", err)
+	}
+	if err := tracker.Transition(ctx, "ENG-9", StatusFailed); err == nil || !strings.Contains(err.Error(), `no workflow state "Canceled"`) {
+		t.Fatalf("Transition(missing) error = %v", err)
+	}
+	if err := tracker.Link(ctx, "ENG-9", "PR", "https://github.com/acme/widgets/pull/7"); err == nil || !strings.Contains(err.Error(), "Entity not found") {
+		t.Fatalf("Link() error = %v", err)
+	}
+	if want := []string{"issueCreate", "team", "issueUpdate", "attachmentLinkURL"}; !reflect.DeepEqual(queries, want) {
+		t.Fatalf("queries = %q, want %q", queries, want)
+	}
+}
+
+func TestValidateConfig(t *testing.T) {
+	err := ValidateConfig(Config{Jira: JiraConfig{BaseURL: "https://acme.atlassian.net"}, Linear: LinearConfig{TeamID: "team-1"}})
+	for _, want := range []string{"jira requires project", "jira requires token", "linear requires api_key"} {
+		if err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("ValidateConfig() error = %v, want %q", err, want)
+		}
+	}
+	if err := ValidateConfig(Config{}); err != nil {
+		t.Fatalf("ValidateConfig(empty) error = %v", err)
+	}
+}
@@ -0,0 +1,125 @@
+package integrations
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// JiraConfig configures a Jira Cloud or Jira Server project.
+type JiraConfig struct {
+	// BaseURL is the site URL, such as https://acme.atlassian.net.
+	BaseURL string
+	// Email and Token authenticate with basic auth on Jira Cloud. Without
+	// Email, Token is sent as a bearer personal access token.
+	Email     string
+	Token     string
+	Project   string
+	IssueType string
+	Labels    []string
+	// Transitions name the transition, or target status, for each Status.
+	Transitions map[Status]string
+}
+
+// JiraTracker mirrors issues to Jira through its REST API.
+type JiraTracker struct {
+	cfg    JiraConfig
+	client *http.Client
+}
+
+// NewJiraTracker creates a tracker for the configured project. IssueType
+// defaults to Task.
+func NewJiraTracker(cfg JiraConfig) *JiraTracker {
+	cfg.BaseURL = strings.TrimSuffix(cfg.BaseURL, "/")
+	if cfg.IssueType == "" {
+		cfg.IssueType = "Task"
+	}
+	return &JiraTracker{cfg: cfg, client: &http.Client{Timeout: requestTimeout}}
+}
+
+// Name returns "jira".
+func (t *JiraTracker) Name() string { return "jira" }
+
+// CreateIssue creates an issue in the configured project.
+func (t *JiraTracker) CreateIssue(ctx context.Context, issue NewIssue) (IssueRef, error) {
+	fields := map[string]any{
+		"project":     map[string]string{"key": t.cfg.Project},
+		"issuetype":   map[string]string{"name": t.cfg.IssueType},
+		"summary":     issue.Title,
+		"description": issue.Description,
+	}
+	if len(t.cfg.Labels) > 0 {
+		fields["labels"] = t.cfg.Labels
+	}
+	var created struct {
+		Key string `json:"key"`
+	}
+	if err := t.do(ctx, http.MethodPost, "/rest/api/2/issue", map[string]any{"fields": fields}, &created); err != nil {
+		return IssueRef{}, fmt.Errorf("failed to create Jira issue: %w", err)
+	}
+	return IssueRef{Key: created.Key, URL: t.cfg.BaseURL + "/browse/" + created.Key}, nil
+}
+
+// Transition applies the transition configured for status, matched by
+// transition name or target status name.
+func (t *JiraTracker) Transition(ctx context.Context, key string, status Status) error {
+	want := t.cfg.Transitions[status]
+	if want == "" {
+		return nil
+	}
+	path := "/rest/api/2/issue/" + url.PathEscape(key) + "/transitions"
+	var available struct {
+		Transitions []struct {
+			ID   string `json:"id"`
+			Name string `json:"name"`
+			To   struct {
+				Name string `json:"name"`
+			} `json:"to"`
+		} `json:"transitions"`
+	}
+	if err := t.do(ctx, http.MethodGet, path, nil, &available); err != nil {
+		return fmt.Errorf("failed to list transitions of %s: %w", key, err)
+	}
+	for _, transition := range available.Transitions {
+		if strings.EqualFold(transition.Name, want) || strings.EqualFold(transition.To.Name, want) {
+			body := map[string]any{"transition": map[string]string{"id": transition.ID}}
+			if err := t.do(ctx, http.MethodPost, path, body, nil); err != nil {
+				return fmt.Errorf("failed to transition %s to %s: %w", key, want, err)
+			}
+			return nil
+		}
+	}
+	return fmt.Errorf("no transition to %q is available for %s", want, key)
+}
+
+// Link adds a remote link to the issue.
+func (t *JiraTracker) Link(ctx context.Context, key, title, link string) error {
+	body := map[string]any{"object": map[string]string{"url": link, "title": title}}
+	if err := t.do(ctx, http.MethodPost, "/rest/api/2/issue/"+url.PathEscape(key)+"/remotelink", body, nil); err != nil {
+		return fmt.Errorf("failed to link %s: %w", key, err)
+	}
+	return nil
+}
+
+// Comment adds a comment to the issue.
+func (t *JiraTracker) Comment(ctx context.Context, key, body string) error {
+	if err := t.do(ctx, http.MethodPost, "/rest/api/2/issue/"+url.PathEscape(key)+"/comment", map[string]string{"body": body}, nil); err != nil {
+		return fmt.Errorf("failed to comment on %s: %w", key, err)
+	}
+	return nil
+}
+
+func (t *JiraTracker) do(ctx context.Context, method, path string, body, out any) error {
+	req, err := http.NewRequest(method, t.cfg.BaseURL+path, nil)
+	if err != nil {
+		return err
+	}
+	if t.cfg.Email != "" {
+		req.SetBasicAuth(t.cfg.Email, t.cfg.Token)
+	} else {
+		req.Header.Set("Authorization", "Bearer "+t.cfg.Token)
+	}
+	return doJSON(ctx, t.client, req, body, out)
+}
@@ -0,0 +1,193 @@
+package integrations
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+)
+
+// linearEndpoint is Linear's GraphQL API.
+const linearEndpoint = "https://api.linear.app/graphql"
+
+// LinearConfig configures a Linear team.
+type LinearConfig struct {
+	APIKey string
+	TeamID string
+	// States name the workflow state for each Status.
+	States map[Status]string
+	// Endpoint overrides the GraphQL API URL.
+	Endpoint string
+}
+
+// LinearTracker mirrors issues to Linear through its GraphQL API.
+type LinearTracker struct {
+	cfg    LinearConfig
+	client *http.Client
+
+	mu       sync.Mutex
+	stateIDs map[string]string
+}
+
+// NewLinearTracker creates a tracker for the configured team.
+func NewLinearTracker(cfg LinearConfig) *LinearTracker {
+	if cfg.Endpoint == "" {
+		cfg.Endpoint = linearEndpoint
+	}
+	return &LinearTracker{cfg: cfg, client: &http.Client{Timeout: requestTimeout}}
+}
+
+// Name returns "linear".
+func (t *LinearTracker) Name() string { return "linear" }
+
+// CreateIssue creates an issue in the configured team.
+func (t *LinearTracker) CreateIssue(ctx context.Context, issue NewIssue) (IssueRef, error) {
+	var data struct {
+		IssueCreate struct {
+			Success bool `json:"success"`
+			Issue   struct {
+				Identifier string `json:"identifier"`
+				URL        string `json:"url"`
+			} `json:"issue"`
+		} `json:"issueCreate"`
+	}
+	err := t.query(ctx, `mutation($input: IssueCreateInput!) {
+  issueCreate(input: $input) { success issue { identifier url } }
+}`, map[string]any{"input": map[string]string{
+		"teamId":      t.cfg.TeamID,
+		"title":       issue.Title,
+		"description": issue.Description,
+	}}, &data)
+	if err == nil && !data.IssueCreate.Success {
+		err = errors.New("issue was not created")
+	}
+	if err != nil {
+		return IssueRef{}, fmt.Errorf("failed to create Linear issue: %w", err)
+	}
+	return IssueRef{Key: data.IssueCreate.Issue.Identifier, URL: data.IssueCreate.Issue.URL}, nil
+}
+
+// Transition moves the issue to the workflow state configured for status.
+func (t *LinearTracker) Transition(ctx context.Context, key string, status Status) error {
+	want := t.cfg.States[status]
+	if want == "" {
+		return nil
+	}
+	stateID, err := t.stateID(ctx, want)
+	if err != nil {
+		return err
+	}
+	var data struct {
+		IssueUpdate struct {
+			Success bool `json:"success"`
+		} `json:"issueUpdate"`
+	}
+	err = t.query(ctx, `mutation($id: String!, $stateId: String!) {
+  issueUpdate(id: $id, input: { stateId: $stateId }) { success }
+}`, map[string]any{"id": key, "stateId": stateID}, &data)
+	if err == nil && !data.IssueUpdate.Success {
+		err = errors.New("issue was not updated")
+	}
+	if err != nil {
+		return fmt.Errorf("failed to move %s to %s: %w", key, want, err)
+	}
+	return nil
+}
+
+// Link attaches a URL to the issue.
+func (t *LinearTracker) Link(ctx context.Context, key, title, url string) error {
+	var data struct {
+		AttachmentLinkURL struct {
+			Success bool `json:"success"`
+		} `json:"attachmentLinkURL"`
+	}
+	err := t.query(ctx, `mutation($issueId: String!, $url: String!, $title: String) {
+  attachmentLinkURL(issueId: $issueId, url: $url, title: $title) { success }
+}`, map[string]any{"issueId": key, "url": url, "title": title}, &data)
+	if err == nil && !data.AttachmentLinkURL.Success {
+		err = errors.New("link was not attached")
+	}
+	if err != nil {
+		return fmt.Errorf("failed to link %s: %w", key, err)
+	}
+	return nil
+}
+
+// Comment adds a comment to the issue.
+func (t *LinearTracker) Comment(ctx context.Context, key, body string) error {
+	var data struct {
+		CommentCreate struct {
+			Success bool `json:"success"`
+		} `json:"commentCreate"`
+	}
+	err := t.query(ctx, `mutation($input: CommentCreateInput!) {
+  commentCreate(input: $input) { success }
+}`, map[string]any{"input": map[string]string{"issueId": key, "body": body}}, &data)
+	if err == nil && !data.CommentCreate.Success {
+		err = errors.New("comment was not created")
+	}
+	if err != nil {
+		return fmt.Errorf("failed to comment on %s: %w", key, err)
+	}
+	return nil
+}
+
+// stateID resolves a workflow state name of the team, caching the team's
+// states.
+func (t *LinearTracker) stateID(ctx context.Context, name string) (string, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.stateIDs == nil {
+		var data struct {
+			Team struct {
+				States struct {
+					Nodes []struct {
+						ID   string `json:"id"`
+						Name string `json:"name"`
+					} `json:"nodes"`
+				} `json:"states"`
+			} `json:"team"`
+		}
+		if err := t.query(ctx, `query($id: String!) { team(id: $id) { states { nodes { id name } } } }`,
+			map[string]any{"id": t.cfg.TeamID}, &data); err != nil {
+			return "", fmt.Errorf("failed to list workflow states: %w", err)
+		}
+		t.stateIDs = make(map[string]string, len(data.Team.States.Nodes))
+		for _, state := range data.Team.States.Nodes {
+			t.stateIDs[strings.ToLower(state.Name)] = state.ID
+		}
+	}
+	id, ok := t.stateIDs[strings.ToLower(name)]
+	if !ok {
+		return "", fmt.Errorf("team %s has no workflow state %q", t.cfg.TeamID, name)
+	}
+	return id, nil
+}
+
+func (t *LinearTracker) query(ctx context.Context, query string, variables map[string]any, out any) error {
+	req, err := http.NewRequest(http.MethodPost, t.cfg.Endpoint, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", t.cfg.APIKey)
+	var response struct {
+		Data   any `json:"data"`
+		Errors []struct {
+			Message string `json:"message"`
+		} `json:"errors"`
+	}
+	response.Data = out
+	if err := doJSON(ctx, t.client, req, map[string]any{"query": query, "variables": variables}, &response); err != nil {
+		return err
+	}
+	if len(response.Errors) > 0 {
+		messages := make([]string, 0, len(response.Errors))
+		for _, e := range response.Errors {
+			messages = append(messages, e.Message)
+		}
+		return errors.New(strings.Join(messages, "; "))
+	}
+	return nil
+}
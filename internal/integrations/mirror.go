@@ -0,0 +1,267 @@
+package integrations
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+
+	"github.com/SamyRai/juleson/internal/events"
+)
+
+// Config selects the trackers to mirror to.
+type Config struct {
+	// Jira is used when BaseURL is set.
+	Jira JiraConfig
+	// Linear is used when TeamID is set.
+	Linear LinearConfig
+}
+
+// Trackers returns the configured trackers.
+func (c Config) Trackers() []Tracker {
+	var trackers []Tracker
+	if c.Jira.BaseURL != "" {
+		trackers = append(trackers, NewJiraTracker(c.Jira))
+	}
+	if c.Linear.TeamID != "" {
+		trackers = append(trackers, NewLinearTracker(c.Linear))
+	}
+	return trackers
+}
+
+// ValidateConfig checks that configured trackers have what they need to
+// create issues.
+func ValidateConfig(cfg Config) error {
+	var errs []error
+	if cfg.Jira.BaseURL != "" {
+		if cfg.Jira.Project == "" {
+			errs = append(errs, errors.New("jira requires project"))
+		}
+		if cfg.Jira.Token == "" {
+			errs = append(errs, errors.New("jira requires token"))
+		}
+	}
+	if cfg.Linear.TeamID != "" && cfg.Linear.APIKey == "" {
+		errs = append(errs, errors.New("linear requires api_key"))
+	}
+	return errors.Join(errs...)
+}
+
+// Mirror keeps issues in step with task and session events: it creates an
+// issue when a task starts or a session is created, links the session and
+// its pull requests, and transitions the issue when the task or session
+// finishes. Sessions created for a task are linked to the task's issue.
+type Mirror struct {
+	trackers []Tracker
+	store    *Store
+	logger   *slog.Logger
+
+	mu    sync.Mutex
+	tasks map[string]map[string]IssueRef
+}
+
+// NewMirror creates a mirror to trackers, remembering session issues in
+// store.
+func NewMirror(trackers []Tracker, store *Store, logger *slog.Logger) *Mirror {
+	if store == nil {
+		store = NewStore("")
+	}
+	if logger == nil {
+		logger = slog.Default()
+	}
+	return &Mirror{trackers: trackers, store: store, logger: logger, tasks: map[string]map[string]IssueRef{}}
+}
+
+// Enabled reports whether any tracker is configured.
+func (m *Mirror) Enabled() bool {
+	return m != nil && len(m.trackers) > 0
+}
+
+// Handle mirrors event, returning the tracker errors.
+func (m *Mirror) Handle(ctx context.Context, event events.Event) error {
+	if !m.Enabled() {
+		return nil
+	}
+	switch event.Type {
+	case events.EventTaskStarted, events.EventTaskCompleted, events.EventTaskFailed:
+		data, err := events.DecodeData[events.TaskEventData](event)
+		if err != nil {
+			return err
+		}
+		return m.task(ctx, event.Type, data)
+	case events.EventSessionCreated, events.EventSessionCompleted, events.EventSessionFailed:
+		data, err := events.DecodeData[events.SessionEventData](event)
+		if err != nil {
+			return err
+		}
+		return m.session(ctx, event.Type, data)
+	case events.EventPRCreated:
+		data, err := events.DecodeData[events.GitHubEventData](event)
+		if err != nil {
+			return err
+		}
+		sessionID, _ := event.Metadata["session_id"].(string)
+		return m.pullRequest(ctx, sessionID, data)
+	}
+	return nil
+}
+
+// Subscriber returns an event bus subscriber that mirrors the events it
+// receives. It is synchronous, so a task's issue exists before its
+// session's events arrive; tracker errors are logged and never fail the
+// publisher.
+func (m *Mirror) Subscriber() events.Subscriber {
+	return events.Subscriber{
+		ID: "issue-mirror",
+		Filter: func(event events.Event) bool {
+			return event.Topic == events.TopicTask || event.Topic == events.TopicSession || event.Topic == events.TopicGitHub
+		},
+		Handler: func(ctx context.Context, event events.Event) error {
+			if err := m.Handle(ctx, event); err != nil {
+				m.logger.Warn("failed to mirror event to issue trackers", "type", event.Type, "error", err)
+			}
+			return nil
+		},
+	}
+}
+
+func (m *Mirror) task(ctx context.Context, eventType events.EventType, data events.TaskEventData) error {
+	workflow, _ := data.Metadata["workflow"].(string)
+	key := workflow + "/" + data.TaskID
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	issues, ok := m.tasks[key]
+	var errs []error
+	if !ok {
+		title := data.TaskID
+		if workflow != "" {
+			title = workflow + ": " + data.TaskID
+		}
+		issues, errs = m.create(ctx, NewIssue{Title: title, Description: data.TaskName})
+		m.tasks[key] = issues
+	}
+	switch eventType {
+	case events.EventTaskStarted:
+		errs = append(errs, m.transition(ctx, issues, StatusStarted))
+	case events.EventTaskCompleted:
+		errs = append(errs, m.transition(ctx, issues, StatusCompleted))
+	case events.EventTaskFailed:
+		if data.Error != "" {
+			errs = append(errs, m.comment(ctx, issues, "Task failed: "+data.Error))
+		}
+		errs = append(errs, m.transition(ctx, issues, StatusFailed))
+	}
+	return errors.Join(errs...)
+}
+
+func (m *Mirror) session(ctx context.Context, eventType events.EventType, data events.SessionEventData) error {
+	var errs []error
+	entry, ok := m.store.Session(data.SessionID)
+	if !ok {
+		task, _ := data.Metadata["task"].(string)
+		workflow, _ := data.Metadata["workflow"].(string)
+		m.mu.Lock()
+		taskIssues, isTask := m.tasks[workflow+"/"+task]
+		m.mu.Unlock()
+		if task != "" && isTask {
+			entry = SessionIssues{Task: workflow + "/" + task, Issues: taskIssues}
+		} else {
+			title := data.Title
+			if title == "" {
+				title = "Jules session " + data.SessionID
+			}
+			entry.Issues, errs = m.create(ctx, NewIssue{Title: title, Description: sessionDescription(data)})
+			errs = append(errs, m.transition(ctx, entry.Issues, StatusStarted))
+		}
+		if data.URL != "" {
+			errs = append(errs, m.link(ctx, entry.Issues, "Jules session "+data.SessionID, data.URL))
+		}
+		errs = append(errs, m.store.SaveSession(data.SessionID, entry))
+	}
+	if entry.Task != "" {
+		return errors.Join(errs...)
+	}
+	switch eventType {
+	case events.EventSessionCompleted:
+		errs = append(errs, m.transition(ctx, entry.Issues, StatusCompleted))
+	case events.EventSessionFailed:
+		if data.Error != "" {
+			errs = append(errs, m.comment(ctx, entry.Issues, "Session failed: "+data.Error))
+		}
+		errs = append(errs, m.transition(ctx, entry.Issues, StatusFailed))
+	}
+	return errors.Join(errs...)
+}
+
+func (m *Mirror) pullRequest(ctx context.Context, sessionID string, data events.GitHubEventData) error {
+	entry, ok := m.store.Session(sessionID)
+	if !ok || data.PRURL == "" {
+		return nil
+	}
+	title := "Pull request"
+	if data.Repository != "" {
+		title = fmt.Sprintf("Pull request %s#%d", data.Repository, data.PRNumber)
+	}
+	return m.link(ctx, entry.Issues, title, data.PRURL)
+}
+
+func sessionDescription(data events.SessionEventData) string {
+	description := "Jules session " + data.SessionID
+	if data.SourceID != "" {
+		description += " on " + data.SourceID
+	}
+	if data.URL != "" {
+		description += "\n" + data.URL
+	}
+	return description
+}
+
+// create creates an issue in every tracker, returning those created.
+func (m *Mirror) create(ctx context.Context, issue NewIssue) (map[string]IssueRef, []error) {
+	issues := make(map[string]IssueRef, len(m.trackers))
+	var errs []error
+	for _, tracker := range m.trackers {
+		ref, err := tracker.CreateIssue(ctx, issue)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", tracker.Name(), err))
+			continue
+		}
+		issues[tracker.Name()] = ref
+	}
+	return issues, errs
+}
+
+func (m *Mirror) transition(ctx context.Context, issues map[string]IssueRef, status Status) error {
+	return m.each(issues, func(tracker Tracker, key string) error {
+		return tracker.Transition(ctx, key, status)
+	})
+}
+
+func (m *Mirror) link(ctx context.Context, issues map[string]IssueRef, title, url string) error {
+	return m.each(issues, func(tracker Tracker, key string) error {
+		return tracker.Link(ctx, key, title, url)
+	})
+}
+
+func (m *Mirror) comment(ctx context.Context, issues map[string]IssueRef, body string) error {
+	return m.each(issues, func(tracker Tracker, key string) error {
+		return tracker.Comment(ctx, key, body)
+	})
+}
+
+// each calls fn for every tracker with an issue in issues.
+func (m *Mirror) each(issues map[string]IssueRef, fn func(tracker Tracker, key string) error) error {
+	var errs []error
+	for _, tracker := range m.trackers {
+		ref, ok := issues[tracker.Name()]
+		if !ok {
+			continue
+		}
+		if err := fn(tracker, ref.Key); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", tracker.Name(), err))
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,94 @@
+package integrations
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// SessionIssues records the issues mirroring a Jules session.
+type SessionIssues struct {
+	// Task is the workflow/task whose issue the session was linked to, if
+	// any. Task events own the state of such issues.
+	Task string `json:"task,omitempty"`
+	// Issues are keyed by tracker name.
+	Issues map[string]IssueRef `json:"issues"`
+}
+
+// Store remembers which issues mirror which sessions, so later commands
+// update the same issues. A store without a path keeps them in memory.
+type Store struct {
+	path string
+
+	mu       sync.Mutex
+	loaded   bool
+	sessions map[string]SessionIssues
+}
+
+// NewStore creates a store saved as JSON at path.
+func NewStore(path string) *Store {
+	return &Store{path: path}
+}
+
+// Session returns the issues mirroring sessionID.
+func (s *Store) Session(sessionID string) (SessionIssues, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.load()
+	issues, ok := s.sessions[sessionID]
+	return issues, ok
+}
+
+// Sessions returns every mirrored session by ID.
+func (s *Store) Sessions() map[string]SessionIssues {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.load()
+	sessions := make(map[string]SessionIssues, len(s.sessions))
+	for id, issues := range s.sessions {
+		sessions[id] = issues
+	}
+	return sessions
+}
+
+// SaveSession records the issues mirroring sessionID.
+func (s *Store) SaveSession(sessionID string, issues SessionIssues) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.load()
+	s.sessions[sessionID] = issues
+	if s.path == "" {
+		return nil
+	}
+	data, err := json.MarshalIndent(s.sessions, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(s.path), 0o755); err != nil {
+		return fmt.Errorf("failed to create issue store directory: %w", err)
+	}
+	if err := os.WriteFile(s.path, data, 0o644); err != nil {
+		return fmt.Errorf("failed to write issue store: %w", err)
+	}
+	return nil
+}
+
+// load reads the store once. An unreadable store starts empty.
+func (s *Store) load() {
+	if s.loaded {
+		return
+	}
+	s.loaded = true
+	s.sessions = map[string]SessionIssues{}
+	if s.path == "" {
+		return
+	}
+	if data, err := os.ReadFile(s.path); err == nil {
+		_ = json.Unmarshal(data, &s.sessions)
+	}
+	if s.sessions == nil {
+		s.sessions = map[string]SessionIssues{}
+	}
+}
@@ -0,0 +1,83 @@
+// Package integrations mirrors orchestrator tasks and Jules sessions to
+// issues in Jira and Linear.
+package integrations
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+)
+
+// Status is the stage of a task or session mirrored to its issue.
+type Status string
+
+// Statuses that trackers transition issues to.
+const (
+	StatusStarted   Status = "started"
+	StatusCompleted Status = "completed"
+	StatusFailed    Status = "failed"
+)
+
+// requestTimeout bounds each tracker API call.
+const requestTimeout = 15 * time.Second
+
+// IssueRef identifies an issue in a tracker.
+type IssueRef struct {
+	// Key is the human-readable issue key, such as ABC-12.
+	Key string `json:"key"`
+	URL string `json:"url,omitempty"`
+}
+
+// NewIssue describes an issue to create.
+type NewIssue struct {
+	Title       string
+	Description string
+}
+
+// Tracker creates and updates issues in an issue tracker.
+type Tracker interface {
+	// Name is the tracker's configuration name, such as "jira".
+	Name() string
+	CreateIssue(ctx context.Context, issue NewIssue) (IssueRef, error)
+	// Transition moves the issue to the state configured for status. It
+	// does nothing when no state is configured for status.
+	Transition(ctx context.Context, key string, status Status) error
+	// Link attaches a link, such as a session or pull request URL.
+	Link(ctx context.Context, key, title, url string) error
+	Comment(ctx context.Context, key, body string) error
+}
+
+// doJSON sends a JSON request and decodes a JSON response into out, when
+// out is not nil.
+func doJSON(ctx context.Context, client *http.Client, req *http.Request, body, out any) error {
+	if body != nil {
+		payload, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		req.Body = io.NopCloser(bytes.NewReader(payload))
+		req.ContentLength = int64(len(payload))
+		req.Header.Set("Content-Type", "application/json")
+	}
+	req.Header.Set("Accept", "application/json")
+	resp, err := client.Do(req.WithContext(ctx))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		message, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("%s %s returned %s: %s", req.Method, req.URL.Path, resp.Status, bytes.TrimSpace(message))
+	}
+	if out == nil {
+		return nil
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("failed to decode response: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,183 @@
+package intelligence
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
+)
+
+// cacheVersion changes whenever cached analysis results change shape or
+// meaning, which discards every existing cache.
+const cacheVersion = "1"
+
+// AnalysisCache stores per-file analysis results keyed by the file's content
+// hash, so repeated analyses of a project only re-parse files that changed.
+// Results are also keyed by the Go version, whose parser produces them. A nil
+// *AnalysisCache disables caching.
+type AnalysisCache struct {
+	path    string
+	version string
+
+	mu      sync.Mutex
+	entries map[string]cacheEntry
+	seen    map[string]bool
+	kinds   map[string]bool
+	dirty   bool
+	hits    int
+	misses  int
+}
+
+type cacheEntry struct {
+	Hash  string          `json:"hash"`
+	Value json.RawMessage `json:"value"`
+}
+
+type cacheFile struct {
+	Version string                `json:"version"`
+	Entries map[string]cacheEntry `json:"entries"`
+}
+
+// DefaultCacheDir returns the directory for analysis caches under the user
+// cache directory.
+func DefaultCacheDir() (string, error) {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "juleson", "analysis"), nil
+}
+
+// OpenAnalysisCache opens the cache for the project at root, stored in dir.
+// A missing, unreadable, or outdated cache starts empty.
+func OpenAnalysisCache(dir, root string) (*AnalysisCache, error) {
+	root, err := filepath.Abs(root)
+	if err != nil {
+		return nil, err
+	}
+	sum := sha256.Sum256([]byte(root))
+	cache := &AnalysisCache{
+		path:    filepath.Join(dir, hex.EncodeToString(sum[:8])+".json"),
+		version: cacheVersion + "/" + runtime.Version(),
+		entries: make(map[string]cacheEntry),
+		seen:    make(map[string]bool),
+		kinds:   make(map[string]bool),
+	}
+	data, err := os.ReadFile(cache.path)
+	if err != nil {
+		return cache, nil
+	}
+	var stored cacheFile
+	if json.Unmarshal(data, &stored) == nil && stored.Version == cache.version && stored.Entries != nil {
+		cache.entries = stored.Entries
+	}
+	return cache, nil
+}
+
+// OpenProjectCache opens the cache for root in DefaultCacheDir. Caching is
+// an optimization, so when the cache directory is unavailable it returns nil,
+// which analyzes without a cache.
+func OpenProjectCache(root string) *AnalysisCache {
+	dir, err := DefaultCacheDir()
+	if err != nil {
+		return nil
+	}
+	cache, err := OpenAnalysisCache(dir, root)
+	if err != nil {
+		return nil
+	}
+	return cache
+}
+
+// Stats reports cache hits and misses since the cache was opened.
+func (c *AnalysisCache) Stats() (hits, misses int) {
+	if c == nil {
+		return 0, 0
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.hits, c.misses
+}
+
+// Save writes the cache if anything changed. Entries of an analysis kind that
+// was used but not seen for a file, such as deleted files, are dropped.
+func (c *AnalysisCache) Save() error {
+	if c == nil {
+		return nil
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for key := range c.entries {
+		kind, _, _ := strings.Cut(key, "\x00")
+		if c.kinds[kind] && !c.seen[key] {
+			delete(c.entries, key)
+			c.dirty = true
+		}
+	}
+	if !c.dirty {
+		return nil
+	}
+	data, err := json.Marshal(cacheFile{Version: c.version, Entries: c.entries})
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(c.path), 0o700); err != nil {
+		return fmt.Errorf("failed to create cache directory: %w", err)
+	}
+	tmp := c.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o600); err != nil {
+		return fmt.Errorf("failed to write analysis cache: %w", err)
+	}
+	if err := os.Rename(tmp, c.path); err != nil {
+		return fmt.Errorf("failed to write analysis cache: %w", err)
+	}
+	c.dirty = false
+	return nil
+}
+
+// cached returns the stored kind result for file rel when content is
+// unchanged, and otherwise computes and stores it.
+func cached[T any](c *AnalysisCache, kind, rel string, content []byte, compute func() (T, error)) (T, error) {
+	if c == nil {
+		return compute()
+	}
+	sum := sha256.Sum256(content)
+	hash := hex.EncodeToString(sum[:])
+	key := kind + "\x00" + rel
+
+	c.mu.Lock()
+	c.kinds[kind] = true
+	c.seen[key] = true
+	entry, ok := c.entries[key]
+	c.mu.Unlock()
+
+	if ok && entry.Hash == hash {
+		var value T
+		if json.Unmarshal(entry.Value, &value) == nil {
+			c.mu.Lock()
+			c.hits++
+			c.mu.Unlock()
+			return value, nil
+		}
+	}
+
+	value, err := compute()
+	if err != nil {
+		return value, err
+	}
+	raw, err := json.Marshal(value)
+	if err != nil {
+		return value, err
+	}
+	c.mu.Lock()
+	c.entries[key] = cacheEntry{Hash: hash, Value: raw}
+	c.misses++
+	c.dirty = true
+	c.mu.Unlock()
+	return value, nil
+}
@@ -0,0 +1,87 @@
+package intelligence
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestAnalysisCacheReusesUnchangedFiles(t *testing.T) {
+	ctx := context.Background()
+	root := t.TempDir()
+	cacheDir := t.TempDir()
+	writeTree(t, root, map[string]string{
+		"go.mod":      "module example.com/app\n\ngo 1.25\n",
+		"a/a.go":      "package a\nimport \"example.com/app/b\"\nfunc A(x int) int { if x > 0 { return b.B() }; return 0 }\n",
+		"b/b.go":      "package b\nfunc B() int { return 1 }\n",
+		"b/b_test.go": "package b\n",
+		"c/c.go":      "package c\n",
+	})
+
+	run := func() (*ProjectGraph, []FunctionComplexity, int, int) {
+		t.Helper()
+		cache, err := OpenAnalysisCache(cacheDir, root)
+		if err != nil {
+			t.Fatal(err)
+		}
+		graph, err := BuildProjectGraphCached(ctx, root, cache)
+		if err != nil {
+			t.Fatal(err)
+		}
+		functions, err := AnalyzeComplexityIncremental(ctx, root, cache)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := cache.Save(); err != nil {
+			t.Fatal(err)
+		}
+		hits, misses := cache.Stats()
+		return graph, functions, hits, misses
+	}
+
+	graph, functions, hits, misses := run()
+	if hits != 0 || misses != 7 {
+		t.Errorf("first run hits=%d misses=%d, want 0 and 7", hits, misses)
+	}
+	if len(functions) != 2 || functions[0].FuncName != "A" || functions[0].Complexity != 2 {
+		t.Errorf("functions = %+v", functions)
+	}
+	want := graph.Nodes["a"].Imports
+
+	graph, _, hits, misses = run()
+	if hits != 7 || misses != 0 {
+		t.Errorf("second run hits=%d misses=%d, want 7 and 0", hits, misses)
+	}
+	if got := graph.Nodes["a"].Imports; !reflect.DeepEqual(got, want) {
+		t.Errorf("cached imports = %v, want %v", got, want)
+	}
+
+	// Changing one file re-analyzes only that file; a new import is picked up.
+	writeTree(t, root, map[string]string{"c/c.go": "package c\nimport \"example.com/app/b\"\nvar _ = b.B\n"})
+	if err := os.Remove(filepath.Join(root, "b", "b_test.go")); err != nil {
+		t.Fatal(err)
+	}
+	graph, _, hits, misses = run()
+	if hits != 4 || misses != 2 {
+		t.Errorf("incremental run hits=%d misses=%d, want 4 and 2", hits, misses)
+	}
+	if got := graph.Nodes["c"].Imports; !reflect.DeepEqual(got, []string{"b"}) {
+		t.Errorf("c imports = %v", got)
+	}
+	if graph.Nodes["b"].Test {
+		t.Error("b should have no tests after b_test.go was removed")
+	}
+}
+
+func TestNilAnalysisCache(t *testing.T) {
+	var cache *AnalysisCache
+	if err := cache.Save(); err != nil {
+		t.Fatal(err)
+	}
+	got, err := cached(cache, "kind", "file", []byte("x"), func() (int, error) { return 42, nil })
+	if err != nil || got != 42 {
+		t.Fatalf("cached() = %d, %v", got, err)
+	}
+}
@@ -4,7 +4,12 @@ import (
 	"context"
 	"fmt"
 	"go/ast"
+	"go/build"
+	"go/parser"
 	"go/token"
+	"io/fs"
+	"os"
+	"path/filepath"
 	"sort"
 	"strings"
 
@@ -48,18 +53,7 @@ func AnalyzeComplexity(ctx context.Context, path string) ([]FunctionComplexity,
 					complexity := calculateComplexity(funcDecl)
 					pos := pkg.Fset.Position(funcDecl.Pos())
 
-					// Build the full function name including receiver if present
-					funcName := funcDecl.Name.Name
-					if funcDecl.Recv != nil && len(funcDecl.Recv.List) > 0 {
-						switch recvType := funcDecl.Recv.List[0].Type.(type) {
-						case *ast.Ident:
-							funcName = fmt.Sprintf("(%s).%s", recvType.Name, funcName)
-						case *ast.StarExpr:
-							if ident, ok := recvType.X.(*ast.Ident); ok {
-								funcName = fmt.Sprintf("(*%s).%s", ident.Name, funcName)
-							}
-						}
-					}
+					funcName := functionName(funcDecl)
 
 					fileName := pos.Filename
 					if i := strings.LastIndex(fileName, "/"); i >= 0 {
@@ -86,6 +80,22 @@ func AnalyzeComplexity(ctx context.Context, path string) ([]FunctionComplexity,
 	return results, nil
 }
 
+// functionName returns the function name including its receiver, if any.
+func functionName(funcDecl *ast.FuncDecl) string {
+	funcName := funcDecl.Name.Name
+	if funcDecl.Recv != nil && len(funcDecl.Recv.List) > 0 {
+		switch recvType := funcDecl.Recv.List[0].Type.(type) {
+		case *ast.Ident:
+			funcName = fmt.Sprintf("(%s).%s", recvType.Name, funcName)
+		case *ast.StarExpr:
+			if ident, ok := recvType.X.(*ast.Ident); ok {
+				funcName = fmt.Sprintf("(*%s).%s", ident.Name, funcName)
+			}
+		}
+	}
+	return funcName
+}
+
 func calculateComplexity(fn *ast.FuncDecl) int {
 	complexity := 1 // Base complexity
 
@@ -111,3 +121,95 @@ func calculateComplexity(fn *ast.FuncDecl) int {
 
 	return complexity
 }
+
+// AnalyzeComplexityIncremental computes the same metrics as AnalyzeComplexity
+// file by file from syntax alone, reusing cached results for files whose
+// content is unchanged. It needs no type information, so it also works on
+// packages that do not currently build. Test files and files excluded by
+// build constraints for the current platform are skipped. A nil cache
+// analyzes every file.
+func AnalyzeComplexityIncremental(ctx context.Context, path string, cache *AnalysisCache) ([]FunctionComplexity, error) {
+	root, err := filepath.Abs(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var results []FunctionComplexity
+	err = walkGoFiles(ctx, root, false, func(rel string, data []byte) error {
+		functions, err := cached(cache, "go-complexity", rel, data, func() ([]FunctionComplexity, error) {
+			return fileComplexity(filepath.Base(rel), data)
+		})
+		if err != nil {
+			return fmt.Errorf("failed to parse %s: %w", rel, err)
+		}
+		results = append(results, functions...)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sort.SliceStable(results, func(i, j int) bool {
+		return results[i].Complexity > results[j].Complexity
+	})
+	return results, nil
+}
+
+// walkGoFiles calls fn with the slash-separated path relative to root and the
+// content of each Go file that builds on the current platform, skipping the
+// same directories as BuildProjectGraph.
+func walkGoFiles(ctx context.Context, root string, tests bool, fn func(rel string, data []byte) error) error {
+	return filepath.WalkDir(root, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return ctxErr
+		}
+		name := d.Name()
+		if d.IsDir() {
+			if p != root && (skippedDirs[name] || strings.HasPrefix(name, ".")) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !strings.HasSuffix(name, ".go") || (!tests && strings.HasSuffix(name, "_test.go")) {
+			return nil
+		}
+		if match, err := build.Default.MatchFile(filepath.Dir(p), name); err != nil || !match {
+			return nil
+		}
+		rel, err := filepath.Rel(root, p)
+		if err != nil {
+			return err
+		}
+		data, err := os.ReadFile(p)
+		if err != nil {
+			return err
+		}
+		return fn(filepath.ToSlash(rel), data)
+	})
+}
+
+func fileComplexity(name string, data []byte) ([]FunctionComplexity, error) {
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, name, data, parser.SkipObjectResolution)
+	if err != nil {
+		return nil, err
+	}
+	var results []FunctionComplexity
+	for _, decl := range file.Decls {
+		funcDecl, ok := decl.(*ast.FuncDecl)
+		if !ok {
+			continue
+		}
+		results = append(results, FunctionComplexity{
+			PkgName:    file.Name.Name,
+			FuncName:   functionName(funcDecl),
+			FileName:   name,
+			Line:       fset.Position(funcDecl.Pos()).Line,
+			Complexity: calculateComplexity(funcDecl),
+		})
+	}
+	return results, nil
+}
@@ -0,0 +1,128 @@
+package intelligence
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// PackageCoverage is the statement coverage of one Go package.
+type PackageCoverage struct {
+	// Dir is the package directory relative to the module root, "." for
+	// the root package.
+	Dir        string `json:"dir"`
+	Covered    int    `json:"covered"`
+	Statements int    `json:"statements"`
+	// HasTests reports whether the directory has _test.go files.
+	HasTests bool `json:"hasTests"`
+}
+
+// Coverage returns the covered fraction of statements, from 0 to 1.
+func (p PackageCoverage) Coverage() float64 {
+	return fraction(p.Covered, p.Statements)
+}
+
+// Uncovered returns the number of statements no test runs.
+func (p PackageCoverage) Uncovered() int {
+	return p.Statements - p.Covered
+}
+
+// CoverageReport is the statement coverage of a module, per package.
+type CoverageReport struct {
+	Module string `json:"module"`
+	// Packages are ordered by directory.
+	Packages   []PackageCoverage `json:"packages"`
+	Covered    int               `json:"covered"`
+	Statements int               `json:"statements"`
+}
+
+// Coverage returns the covered fraction of all statements, from 0 to 1.
+func (r *CoverageReport) Coverage() float64 {
+	return fraction(r.Covered, r.Statements)
+}
+
+// Package returns the coverage of the package in dir.
+func (r *CoverageReport) Package(dir string) (PackageCoverage, bool) {
+	for _, pkg := range r.Packages {
+		if pkg.Dir == dir {
+			return pkg, true
+		}
+	}
+	return PackageCoverage{}, false
+}
+
+// ReadPackageCoverage groups the statements of a Go cover profile by
+// package directory, relative to root.
+func ReadPackageCoverage(profile, root string) (*CoverageReport, error) {
+	statements, err := readCoverStatements(profile, root)
+	if err != nil {
+		return nil, err
+	}
+	byDir := map[string]*PackageCoverage{}
+	report := &CoverageReport{Module: readModulePath(root)}
+	for rel, counts := range statements {
+		dir := path.Dir(rel)
+		pkg := byDir[dir]
+		if pkg == nil {
+			pkg = &PackageCoverage{Dir: dir, HasTests: hasTestFiles(filepath.Join(root, filepath.FromSlash(dir)))}
+			byDir[dir] = pkg
+		}
+		pkg.Covered += counts.covered
+		pkg.Statements += counts.total
+		report.Covered += counts.covered
+		report.Statements += counts.total
+	}
+	for _, pkg := range byDir {
+		report.Packages = append(report.Packages, *pkg)
+	}
+	slices.SortFunc(report.Packages, func(a, b PackageCoverage) int { return strings.Compare(a.Dir, b.Dir) })
+	return report, nil
+}
+
+// MeasureCoverage runs the tests of the Go module at root with a cover
+// profile and returns the coverage per package. Packages without tests are
+// included with no statements covered. A failing test fails the
+// measurement.
+func MeasureCoverage(ctx context.Context, root string) (*CoverageReport, error) {
+	profile, err := os.CreateTemp("", "juleson-cover-*.out")
+	if err != nil {
+		return nil, err
+	}
+	_ = profile.Close()
+	defer func() { _ = os.Remove(profile.Name()) }()
+
+	cmd := exec.CommandContext(ctx, "go", "test", "-coverprofile="+profile.Name(), "./...")
+	cmd.Dir = root
+	var output bytes.Buffer
+	cmd.Stdout, cmd.Stderr = &output, &output
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("go test -coverprofile failed: %w\n%s", err, bytes.TrimSpace(lastBytes(output.Bytes(), 4096)))
+	}
+	return ReadPackageCoverage(profile.Name(), root)
+}
+
+func hasTestFiles(dir string) bool {
+	matches, _ := filepath.Glob(filepath.Join(dir, "*_test.go"))
+	return len(matches) > 0
+}
+
+func fraction(part, total int) float64 {
+	if total == 0 {
+		return 0
+	}
+	return float64(part) / float64(total)
+}
+
+// lastBytes returns at most the last n bytes of data.
+func lastBytes(data []byte, n int) []byte {
+	if len(data) <= n {
+		return data
+	}
+	return data[len(data)-n:]
+}
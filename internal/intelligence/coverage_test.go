@@ -0,0 +1,67 @@
+package intelligence
+
+import (
+	"context"
+	"os/exec"
+	"path/filepath"
+	"testing"
+)
+
+func TestReadPackageCoverage(t *testing.T) {
+	root := t.TempDir()
+	writeTree(t, root, map[string]string{
+		"go.mod":          "module example.com/app\n\ngo 1.25\n",
+		"api/api_test.go": "package api\n",
+		"cover.out": "mode: set\n" +
+			"example.com/app/main.go:3.13,3.14 2 1\n" +
+			"example.com/app/api/api.go:3.13,3.14 3 1\n" +
+			"example.com/app/api/api.go:5.13,6.14 1 0\n" +
+			"example.com/app/api/util.go:1.1,2.2 4 0\n" +
+			"example.com/other/x.go:1.1,2.2 5 1\n",
+	})
+
+	report, err := ReadPackageCoverage(filepath.Join(root, "cover.out"), root)
+	if err != nil {
+		t.Fatalf("ReadPackageCoverage() error = %v", err)
+	}
+	if report.Module != "example.com/app" || report.Covered != 5 || report.Statements != 10 || report.Coverage() != 0.5 {
+		t.Errorf("report = %+v", report)
+	}
+	if len(report.Packages) != 2 {
+		t.Fatalf("packages = %+v", report.Packages)
+	}
+	root0, api := report.Packages[0], report.Packages[1]
+	if root0.Dir != "." || root0.Coverage() != 1 || root0.HasTests {
+		t.Errorf("root package = %+v", root0)
+	}
+	if api.Dir != "api" || api.Covered != 3 || api.Statements != 8 || api.Uncovered() != 5 || !api.HasTests {
+		t.Errorf("api package = %+v", api)
+	}
+	if pkg, ok := report.Package("api"); !ok || pkg != api {
+		t.Errorf("Package(api) = %+v, %v", pkg, ok)
+	}
+}
+
+func TestMeasureCoverage(t *testing.T) {
+	if _, err := exec.LookPath("go"); err != nil {
+		t.Skip("go is not installed")
+	}
+	if testing.Short() {
+		t.Skip("runs go test")
+	}
+	root := t.TempDir()
+	writeTree(t, root, map[string]string{
+		"go.mod":            "module example.com/app\n\ngo 1.25\n",
+		"calc/calc.go":      "package calc\n\nfunc Add(a, b int) int { return a + b }\n\nfunc Sub(a, b int) int { return a - b }\n",
+		"calc/calc_test.go": "package calc\n\nimport \"testing\"\n\nfunc TestAdd(t *testing.T) {\n\tif Add(1, 2) != 3 {\n\t\tt.Fatal()\n\t}\n}\n",
+	})
+
+	report, err := MeasureCoverage(context.Background(), root)
+	if err != nil {
+		t.Fatalf("MeasureCoverage() error = %v", err)
+	}
+	calc, ok := report.Package("calc")
+	if !ok || calc.Covered != 1 || calc.Statements != 2 {
+		t.Errorf("calc = %+v, report = %+v", calc, report)
+	}
+}
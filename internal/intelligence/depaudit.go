@@ -0,0 +1,366 @@
+package intelligence
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/SamyRai/juleson/pkg/osv"
+	"golang.org/x/mod/modfile"
+	"golang.org/x/mod/module"
+)
+
+// License statuses of an audited dependency.
+const (
+	LicenseAllowed   = "allowed"
+	LicenseDenied    = "denied"
+	LicenseUnknown   = "unknown"
+	LicenseUnchecked = "unchecked"
+)
+
+var (
+	pyRequirementPattern = regexp.MustCompile(`^([A-Za-z0-9][A-Za-z0-9._-]*)\s*(?:\[[^\]]*\])?\s*(?:===?\s*([^\s;,]+))?`)
+	exactVersionPattern  = regexp.MustCompile(`^v?\d+(\.\d+)*([-+][0-9A-Za-z.-]+)?$`)
+	pythonNameSeparators = regexp.MustCompile(`[-_.]+`)
+)
+
+// Dependency is one package a project manifest requires. Version is empty
+// when the manifest and lock file do not pin an exact version.
+type Dependency struct {
+	Name      string `json:"name"`
+	Version   string `json:"version,omitempty"`
+	Ecosystem string `json:"ecosystem"`
+	Manifest  string `json:"manifest"`
+	License   string `json:"license,omitempty"`
+	Direct    bool   `json:"direct"`
+	Dev       bool   `json:"dev,omitempty"`
+}
+
+// DependencyVulnerability is a known vulnerability affecting a dependency.
+type DependencyVulnerability struct {
+	ID       string   `json:"id"`
+	Summary  string   `json:"summary,omitempty"`
+	Severity string   `json:"severity"`
+	Aliases  []string `json:"aliases,omitempty"`
+	Fixed    []string `json:"fixed,omitempty"`
+}
+
+// AuditedDependency is a dependency with its vulnerabilities and license
+// status.
+type AuditedDependency struct {
+	Dependency
+	Vulnerabilities []DependencyVulnerability `json:"vulnerabilities,omitempty"`
+	LicenseStatus   string                    `json:"license_status"`
+}
+
+// DependencyAudit is the result of AuditDependencies.
+type DependencyAudit struct {
+	Dependencies []AuditedDependency `json:"dependencies"`
+	// VulnerabilitiesChecked is false when no OSV client was given, and
+	// LicensesChecked when no licenses were allowed.
+	VulnerabilitiesChecked bool `json:"vulnerabilities_checked"`
+	LicensesChecked        bool `json:"licenses_checked"`
+	Vulnerable             int  `json:"vulnerable"`
+	LicenseViolations      int  `json:"license_violations"`
+	Unpinned               int  `json:"unpinned"`
+}
+
+// Failed reports whether the audit found vulnerabilities or license
+// violations.
+func (a *DependencyAudit) Failed() bool {
+	return a.Vulnerable > 0 || a.LicenseViolations > 0
+}
+
+// DependencyAuditOptions configures AuditDependencies.
+type DependencyAuditOptions struct {
+	// OSV looks up vulnerabilities; nil skips the lookup.
+	OSV *osv.Client
+	// AllowedLicenses are SPDX identifiers dependencies may use. Empty skips
+	// the license check.
+	AllowedLicenses []string
+}
+
+// AuditDependencies reads the go.mod, package.json, and requirements files
+// under root, looks up known vulnerabilities of pinned versions in OSV, and
+// checks the licenses found locally against the allowlist.
+func AuditDependencies(ctx context.Context, root string, options DependencyAuditOptions) (*DependencyAudit, error) {
+	deps, err := ReadDependencies(ctx, root)
+	if err != nil {
+		return nil, err
+	}
+	audit := &DependencyAudit{
+		Dependencies:    make([]AuditedDependency, len(deps)),
+		LicensesChecked: len(options.AllowedLicenses) > 0,
+	}
+	for i, dep := range deps {
+		audit.Dependencies[i] = AuditedDependency{Dependency: dep, LicenseStatus: LicenseUnchecked}
+		if dep.Version == "" {
+			audit.Unpinned++
+		}
+		if audit.LicensesChecked {
+			audit.Dependencies[i].LicenseStatus = licenseStatus(dep.License, options.AllowedLicenses)
+			if audit.Dependencies[i].LicenseStatus == LicenseDenied {
+				audit.LicenseViolations++
+			}
+		}
+	}
+
+	if options.OSV != nil {
+		if err := lookupVulnerabilities(ctx, options.OSV, audit.Dependencies); err != nil {
+			return nil, err
+		}
+		audit.VulnerabilitiesChecked = true
+		for _, dep := range audit.Dependencies {
+			if len(dep.Vulnerabilities) > 0 {
+				audit.Vulnerable++
+			}
+		}
+	}
+	return audit, nil
+}
+
+func lookupVulnerabilities(ctx context.Context, client *osv.Client, deps []AuditedDependency) error {
+	var (
+		queries []osv.Query
+		indexes []int
+	)
+	for i, dep := range deps {
+		if dep.Version == "" {
+			continue
+		}
+		version := dep.Version
+		if dep.Ecosystem == osv.EcosystemGo {
+			version = strings.TrimPrefix(version, "v")
+		}
+		queries = append(queries, osv.Query{Package: osv.Package{Name: dep.Name, Ecosystem: dep.Ecosystem}, Version: version})
+		indexes = append(indexes, i)
+	}
+	if len(queries) == 0 {
+		return nil
+	}
+	results, err := client.QueryBatch(ctx, queries)
+	if err != nil {
+		return err
+	}
+
+	records := map[string]*osv.Vulnerability{}
+	for n, ids := range results {
+		dep := &deps[indexes[n]]
+		for _, id := range ids {
+			record, ok := records[id]
+			if !ok {
+				if record, err = client.GetVulnerability(ctx, id); err != nil {
+					return err
+				}
+				records[id] = record
+			}
+			dep.Vulnerabilities = append(dep.Vulnerabilities, DependencyVulnerability{
+				ID:       record.ID,
+				Summary:  record.Summary,
+				Severity: record.SeverityLabel(),
+				Aliases:  record.Aliases,
+				Fixed:    record.FixedVersions(queries[n].Package),
+			})
+		}
+	}
+	return nil
+}
+
+// ReadDependencies lists the dependencies declared by every go.mod,
+// package.json, and requirements*.txt under root, with the licenses that can
+// be found locally in the Go module cache, node_modules, or a virtualenv.
+func ReadDependencies(ctx context.Context, root string) ([]Dependency, error) {
+	root, err := filepath.Abs(root)
+	if err != nil {
+		return nil, err
+	}
+	var deps []Dependency
+	err = filepath.WalkDir(root, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return ctxErr
+		}
+		name := d.Name()
+		if d.IsDir() {
+			if p != root && (skippedDirs[name] || strings.HasPrefix(name, ".")) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		rel, err := filepath.Rel(root, p)
+		if err != nil {
+			return err
+		}
+		rel = filepath.ToSlash(rel)
+
+		var found []Dependency
+		switch {
+		case name == "go.mod":
+			found, err = goModDependencies(p, rel)
+		case name == "package.json":
+			found, err = npmDependencies(p, rel)
+		case strings.HasPrefix(name, "requirements") && strings.HasSuffix(name, ".txt"):
+			found, err = pythonRequirements(p, rel)
+		}
+		if err != nil {
+			return fmt.Errorf("%s: %w", rel, err)
+		}
+		deps = append(deps, found...)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	slices.SortStableFunc(deps, func(a, b Dependency) int {
+		return strings.Compare(a.Manifest+"\x00"+a.Name, b.Manifest+"\x00"+b.Name)
+	})
+	return deps, nil
+}
+
+func goModDependencies(file, rel string) ([]Dependency, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	mod, err := modfile.Parse(file, data, nil)
+	if err != nil {
+		return nil, err
+	}
+	replaced := map[string]module.Version{}
+	for _, replace := range mod.Replace {
+		replaced[replace.Old.Path] = replace.New
+	}
+
+	modCache := goModCache()
+	var deps []Dependency
+	for _, req := range mod.Require {
+		dep := req.Mod
+		if replacement, ok := replaced[dep.Path]; ok {
+			// Local replacements have no version and are not published modules.
+			if replacement.Version == "" {
+				continue
+			}
+			dep = replacement
+		}
+		deps = append(deps, Dependency{
+			Name:      dep.Path,
+			Version:   dep.Version,
+			Ecosystem: osv.EcosystemGo,
+			Manifest:  rel,
+			Direct:    !req.Indirect,
+			License:   goModuleLicense(modCache, dep),
+		})
+	}
+	return deps, nil
+}
+
+func npmDependencies(file, rel string) ([]Dependency, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	var manifest struct {
+		Dependencies    map[string]string `json:"dependencies"`
+		DevDependencies map[string]string `json:"devDependencies"`
+	}
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		return nil, err
+	}
+
+	dir := filepath.Dir(file)
+	locked := npmLockedVersions(dir)
+	var deps []Dependency
+	for _, group := range []struct {
+		specs map[string]string
+		dev   bool
+	}{{manifest.Dependencies, false}, {manifest.DevDependencies, true}} {
+		for name, spec := range group.specs {
+			version := locked[name]
+			if version == "" && exactVersionPattern.MatchString(spec) {
+				version = strings.TrimPrefix(spec, "v")
+			}
+			deps = append(deps, Dependency{
+				Name:      name,
+				Version:   version,
+				Ecosystem: osv.EcosystemNPM,
+				Manifest:  rel,
+				Direct:    true,
+				Dev:       group.dev,
+				License:   npmPackageLicense(dir, name),
+			})
+		}
+	}
+	return deps, nil
+}
+
+// npmLockedVersions reads the installed top-level versions from
+// package-lock.json, lockfile version 2 or later.
+func npmLockedVersions(dir string) map[string]string {
+	data, err := os.ReadFile(filepath.Join(dir, "package-lock.json"))
+	if err != nil {
+		return nil
+	}
+	var lock struct {
+		Packages map[string]struct {
+			Version string `json:"version"`
+		} `json:"packages"`
+	}
+	if json.Unmarshal(data, &lock) != nil {
+		return nil
+	}
+	versions := map[string]string{}
+	for key, pkg := range lock.Packages {
+		if name, ok := strings.CutPrefix(key, "node_modules/"); ok && !strings.Contains(name, "/node_modules/") {
+			versions[name] = pkg.Version
+		}
+	}
+	return versions
+}
+
+func pythonRequirements(file, rel string) ([]Dependency, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	dir := filepath.Dir(file)
+	var deps []Dependency
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		line, _, _ := strings.Cut(scanner.Text(), "#")
+		line = strings.TrimSpace(line)
+		// Options such as -r, -e, and --index-url, and URL requirements,
+		// name no index package.
+		if line == "" || strings.HasPrefix(line, "-") || strings.Contains(line, "://") {
+			continue
+		}
+		m := pyRequirementPattern.FindStringSubmatch(line)
+		if m == nil {
+			continue
+		}
+		name := normalizePythonName(m[1])
+		deps = append(deps, Dependency{
+			Name:      name,
+			Version:   m[2],
+			Ecosystem: osv.EcosystemPyPI,
+			Manifest:  rel,
+			Direct:    true,
+			License:   pythonPackageLicense(dir, name),
+		})
+	}
+	return deps, scanner.Err()
+}
+
+// normalizePythonName applies the PEP 503 normalization PyPI uses.
+func normalizePythonName(name string) string {
+	return strings.ToLower(pythonNameSeparators.ReplaceAllString(name, "-"))
+}
@@ -0,0 +1,140 @@
+package intelligence
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/SamyRai/juleson/pkg/osv"
+)
+
+func TestAuditDependencies(t *testing.T) {
+	root := t.TempDir()
+	modCache := t.TempDir()
+	t.Setenv("GOMODCACHE", modCache)
+	writeTree(t, modCache, map[string]string{
+		"golang.org/x/net@v0.1.0/LICENSE":             "Redistribution and use in source and binary forms ... Neither the name of Google",
+		"github.com/!burnt!sushi/toml@v1.0.0/COPYING": "GNU GENERAL PUBLIC LICENSE\nVersion 3, 29 June 2007",
+	})
+	writeTree(t, root, map[string]string{
+		"go.mod": `module example.com/app
+
+go 1.25
+
+require (
+	golang.org/x/net v0.1.0
+	github.com/BurntSushi/toml v1.0.0 // indirect
+	example.com/local v0.0.0
+)
+
+replace example.com/local => ../local
+`,
+		"web/package.json":                       `{"dependencies":{"left-pad":"^1.3.0","lodash":"4.17.20"},"devDependencies":{"jest":"^29.0.0"}}`,
+		"web/package-lock.json":                  `{"lockfileVersion":3,"packages":{"":{},"node_modules/left-pad":{"version":"1.3.0"},"node_modules/left-pad/node_modules/x":{"version":"9.9.9"}}}`,
+		"web/node_modules/left-pad/package.json": `{"license":"WTFPL"}`,
+		"web/node_modules/lodash/package.json":   `{"license":{"type":"MIT"}}`,
+		"requirements.txt":                       "# pinned\nRequests[socks]==2.19.0 ; python_version >= '3'\nflask>=2\n-r dev.txt\n",
+		".venv/lib/python3.12/site-packages/requests-2.19.0.dist-info/METADATA": "Metadata-Version: 2.1\nName: requests\nClassifier: License :: OSI Approved :: Apache Software License\n\nbody",
+	})
+
+	var queries []osv.Query
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/v1/querybatch":
+			var body struct {
+				Queries []osv.Query `json:"queries"`
+			}
+			_ = json.NewDecoder(r.Body).Decode(&body)
+			queries = body.Queries
+			results := make([]map[string]any, len(body.Queries))
+			for i, q := range body.Queries {
+				results[i] = map[string]any{}
+				if q.Package.Name == "golang.org/x/net" || q.Package.Name == "lodash" {
+					results[i]["vulns"] = []map[string]string{{"id": "OSV-" + q.Package.Name}}
+				}
+			}
+			_ = json.NewEncoder(w).Encode(map[string]any{"results": results})
+		case "/v1/vulns/OSV-golang.org/x/net":
+			_, _ = w.Write([]byte(`{"id":"OSV-golang.org/x/net","summary":"bad","affected":[{"package":{"name":"golang.org/x/net","ecosystem":"Go"},"ranges":[{"type":"SEMVER","events":[{"introduced":"0"},{"fixed":"0.7.0"}]}]}]}`))
+		case "/v1/vulns/OSV-lodash":
+			_, _ = w.Write([]byte(`{"id":"OSV-lodash","database_specific":{"severity":"HIGH"}}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	audit, err := AuditDependencies(context.Background(), root, DependencyAuditOptions{
+		OSV:             osv.NewClient(server.URL, nil),
+		AllowedLicenses: []string{"MIT", "BSD-3-Clause", "Apache-2.0"},
+	})
+	if err != nil {
+		t.Fatalf("AuditDependencies() error = %v", err)
+	}
+
+	byName := map[string]AuditedDependency{}
+	for _, dep := range audit.Dependencies {
+		byName[dep.Name] = dep
+	}
+	if _, ok := byName["example.com/local"]; ok {
+		t.Error("locally replaced module should be skipped")
+	}
+	for name, want := range map[string]struct {
+		version, license, status string
+		vulns                    int
+	}{
+		"golang.org/x/net":           {"v0.1.0", "BSD-3-Clause", LicenseAllowed, 1},
+		"github.com/BurntSushi/toml": {"v1.0.0", "GPL-3.0", LicenseDenied, 0},
+		"left-pad":                   {"1.3.0", "WTFPL", LicenseDenied, 0},
+		"lodash":                     {"4.17.20", "MIT", LicenseAllowed, 1},
+		"jest":                       {"", "", LicenseUnknown, 0},
+		"requests":                   {"2.19.0", "Apache-2.0", LicenseAllowed, 0},
+		"flask":                      {"", "", LicenseUnknown, 0},
+	} {
+		dep, ok := byName[name]
+		if !ok {
+			t.Errorf("missing dependency %s", name)
+			continue
+		}
+		if dep.Version != want.version || dep.License != want.license || dep.LicenseStatus != want.status || len(dep.Vulnerabilities) != want.vulns {
+			t.Errorf("%s = %+v, want %+v", name, dep, want)
+		}
+	}
+	if vulns := byName["golang.org/x/net"].Vulnerabilities; len(vulns) == 1 && (vulns[0].Fixed[0] != "0.7.0" || vulns[0].Severity != "UNKNOWN") {
+		t.Errorf("x/net vulnerability = %+v", vulns[0])
+	}
+	if !byName["jest"].Dev || byName["github.com/BurntSushi/toml"].Direct {
+		t.Error("dev and indirect flags not set")
+	}
+	if audit.Vulnerable != 2 || audit.LicenseViolations != 2 || audit.Unpinned != 2 || !audit.Failed() {
+		t.Errorf("audit totals = %+v", audit)
+	}
+	// Unpinned dependencies are not queried, and Go versions drop the v.
+	if len(queries) != 5 {
+		t.Errorf("queried %d packages, want 5: %+v", len(queries), queries)
+	}
+	for _, q := range queries {
+		if q.Package.Name == "golang.org/x/net" && q.Version != "0.1.0" {
+			t.Errorf("Go query version = %q", q.Version)
+		}
+	}
+}
+
+func TestLicenseStatus(t *testing.T) {
+	allowed := []string{"MIT", "Apache-2.0"}
+	for expression, want := range map[string]string{
+		"MIT":                LicenseAllowed,
+		"mit":                LicenseAllowed,
+		"(MIT OR GPL-3.0)":   LicenseAllowed,
+		"MIT AND GPL-3.0":    LicenseDenied,
+		"Apache-2.0 AND MIT": LicenseAllowed,
+		"GPL-3.0-only":       LicenseDenied,
+		"":                   LicenseUnknown,
+	} {
+		if got := licenseStatus(expression, allowed); got != want {
+			t.Errorf("licenseStatus(%q) = %q, want %q", expression, got, want)
+		}
+	}
+}
@@ -0,0 +1,236 @@
+package intelligence
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/SamyRai/juleson/pkg/git"
+)
+
+// Hotspot is a Go file ranked by how often it changes and how complex it is.
+// Coverage is the statement coverage from a cover profile, nil when the
+// profile does not include the file.
+type Hotspot struct {
+	File          string   `json:"file"`
+	Coverage      *float64 `json:"coverage,omitempty"`
+	Commits       int      `json:"commits"`
+	LinesChanged  int      `json:"lines_changed"`
+	Complexity    int      `json:"complexity"`
+	MaxComplexity int      `json:"max_complexity"`
+	Functions     int      `json:"functions"`
+	Score         float64  `json:"score"`
+}
+
+// HotspotOptions configures AnalyzeHotspots.
+type HotspotOptions struct {
+	// Since limits churn to commits after this time; zero means all history.
+	Since time.Time
+	// Cache reuses per-file complexity for unchanged files; it may be nil.
+	Cache *AnalysisCache
+	// CoverProfile is a go test -coverprofile file; empty skips coverage.
+	CoverProfile string
+	// Limit keeps only the top hotspots; zero keeps all.
+	Limit int
+}
+
+// HotspotReport is the ranked result of AnalyzeHotspots.
+type HotspotReport struct {
+	Root      string    `json:"root"`
+	Since     time.Time `json:"since,omitzero"`
+	Generated time.Time `json:"generated"`
+	Hotspots  []Hotspot `json:"hotspots"`
+}
+
+// AnalyzeHotspots ranks the Go files under root by churn times complexity,
+// weighted up to twice as heavily for untested code when a cover profile is
+// given. Churn is the number of non-merge commits that touched a file, so
+// the root must be inside a git working tree. Files that did not change or
+// have no functions are left out.
+func AnalyzeHotspots(ctx context.Context, root string, options HotspotOptions) (*HotspotReport, error) {
+	root, err := filepath.Abs(root)
+	if err != nil {
+		return nil, err
+	}
+	repo, err := git.Open(ctx, root)
+	if err != nil {
+		return nil, err
+	}
+	prefix, err := filepath.Rel(repo.Root, root)
+	if err != nil {
+		return nil, err
+	}
+	prefix = filepath.ToSlash(prefix)
+	churn, err := repo.Churn(ctx, git.ChurnOptions{Since: options.Since, Paths: []string{prefix}})
+	if err != nil {
+		return nil, err
+	}
+
+	var coverage map[string]float64
+	if options.CoverProfile != "" {
+		if coverage, err = readCoverProfile(options.CoverProfile, root); err != nil {
+			return nil, err
+		}
+	}
+
+	report := &HotspotReport{Root: root, Since: options.Since, Generated: time.Now(), Hotspots: []Hotspot{}}
+	err = walkGoFiles(ctx, root, false, func(rel string, data []byte) error {
+		changes, ok := churn[strings.TrimPrefix(prefix+"/"+rel, "./")]
+		if !ok {
+			return nil
+		}
+		functions, err := cached(options.Cache, "go-complexity", rel, data, func() ([]FunctionComplexity, error) {
+			return fileComplexity(filepath.Base(rel), data)
+		})
+		if err != nil || len(functions) == 0 {
+			return nil
+		}
+
+		hotspot := Hotspot{
+			File:         rel,
+			Commits:      changes.Commits,
+			LinesChanged: changes.Added + changes.Deleted,
+			Functions:    len(functions),
+		}
+		for _, fn := range functions {
+			hotspot.Complexity += fn.Complexity
+			hotspot.MaxComplexity = max(hotspot.MaxComplexity, fn.Complexity)
+		}
+		weight := 1.0
+		if covered, ok := coverage[rel]; ok {
+			hotspot.Coverage = &covered
+			weight = 2 - covered
+		}
+		hotspot.Score = float64(hotspot.Commits*hotspot.Complexity) * weight
+		report.Hotspots = append(report.Hotspots, hotspot)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	slices.SortStableFunc(report.Hotspots, func(a, b Hotspot) int {
+		if a.Score != b.Score {
+			if a.Score > b.Score {
+				return -1
+			}
+			return 1
+		}
+		return strings.Compare(a.File, b.File)
+	})
+	if options.Limit > 0 && len(report.Hotspots) > options.Limit {
+		report.Hotspots = report.Hotspots[:options.Limit]
+	}
+	return report, nil
+}
+
+// readCoverProfile returns the statement coverage, from 0 to 1, of each file
+// in a Go cover profile, keyed by path relative to root. Profile paths are
+// import paths, so those outside root's module are dropped.
+func readCoverProfile(profile, root string) (map[string]float64, error) {
+	statements, err := readCoverStatements(profile, root)
+	if err != nil {
+		return nil, err
+	}
+	coverage := make(map[string]float64, len(statements))
+	for rel, counts := range statements {
+		if counts.total > 0 {
+			coverage[rel] = float64(counts.covered) / float64(counts.total)
+		}
+	}
+	return coverage, nil
+}
+
+// readModulePath returns the module path in root's go.mod, or "" without
+// one.
+func readModulePath(root string) string {
+	data, err := os.ReadFile(filepath.Join(root, "go.mod"))
+	if err != nil {
+		return ""
+	}
+	if m := goModulePattern.FindSubmatch(data); m != nil {
+		return string(m[1])
+	}
+	return ""
+}
+
+// coveredStatements counts the covered and total statements of one file.
+type coveredStatements struct {
+	covered, total int
+}
+
+// readCoverStatements counts the statements of each file in a Go cover
+// profile, keyed by path relative to root.
+func readCoverStatements(profile, root string) (map[string]coveredStatements, error) {
+	modulePath := readModulePath(root)
+
+	f, err := os.Open(profile)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = f.Close() }()
+
+	type block struct {
+		statements int
+		covered    bool
+	}
+	// Blocks repeat across packages with -coverpkg; a block is covered if
+	// any run covered it.
+	blocks := map[string]map[string]*block{}
+	scanner := bufio.NewScanner(f)
+	for line := 1; scanner.Scan(); line++ {
+		text := scanner.Text()
+		if line == 1 && strings.HasPrefix(text, "mode:") {
+			continue
+		}
+		// name.go:line.column,line.column statements count
+		fields := strings.Fields(text)
+		if len(fields) != 3 {
+			continue
+		}
+		name, position, ok := strings.Cut(fields[0], ".go:")
+		if !ok {
+			return nil, fmt.Errorf("%s:%d: malformed cover profile line", profile, line)
+		}
+		statements, err1 := strconv.Atoi(fields[1])
+		count, err2 := strconv.Atoi(fields[2])
+		if err1 != nil || err2 != nil {
+			return nil, fmt.Errorf("%s:%d: malformed cover profile line", profile, line)
+		}
+		rel, ok := strings.CutPrefix(name+".go", modulePath+"/")
+		if modulePath == "" || !ok {
+			continue
+		}
+		if blocks[rel] == nil {
+			blocks[rel] = map[string]*block{}
+		}
+		b := blocks[rel][position]
+		if b == nil {
+			b = &block{statements: statements}
+			blocks[rel][position] = b
+		}
+		b.covered = b.covered || count > 0
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	counts := make(map[string]coveredStatements, len(blocks))
+	for rel, fileBlocks := range blocks {
+		var c coveredStatements
+		for _, b := range fileBlocks {
+			c.total += b.statements
+			if b.covered {
+				c.covered += b.statements
+			}
+		}
+		counts[rel] = c
+	}
+	return counts, nil
+}
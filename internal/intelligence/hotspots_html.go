@@ -0,0 +1,58 @@
+package intelligence
+
+import (
+	"fmt"
+	"html/template"
+	"io"
+)
+
+var hotspotTemplate = template.Must(template.New("hotspots").Funcs(template.FuncMap{
+	"heat": func(score, top float64) template.CSS {
+		// Shade from pale yellow to red by the share of the top score.
+		share := 0.0
+		if top > 0 {
+			share = score / top
+		}
+		return template.CSS(fmt.Sprintf("background: hsl(%d, 90%%, %d%%)", int(50-50*share), int(90-35*share)))
+	},
+	"percent": func(value *float64) string {
+		if value == nil {
+			return "–"
+		}
+		return fmt.Sprintf("%.0f%%", *value*100)
+	},
+}).Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>Hotspots: {{.Root}}</title>
+<style>
+body { font-family: system-ui, sans-serif; margin: 2rem; color: #222; }
+table { border-collapse: collapse; width: 100%; }
+th, td { padding: 0.35rem 0.6rem; text-align: right; border-bottom: 1px solid #ddd; }
+th:first-child, td:first-child { text-align: left; }
+td.file { font-family: ui-monospace, monospace; }
+</style>
+</head>
+<body>
+<h1>Hotspots</h1>
+<p>{{.Root}} &middot; generated {{.Generated.Format "2006-01-02 15:04"}}{{if not .Since.IsZero}} &middot; churn since {{.Since.Format "2006-01-02"}}{{end}}</p>
+<p>Score is commits &times; total cyclomatic complexity, weighted up to twice as heavily for untested code.</p>
+<table>
+<tr><th>File</th><th>Score</th><th>Commits</th><th>Lines changed</th><th>Complexity</th><th>Max</th><th>Functions</th><th>Coverage</th></tr>
+{{- $top := 0.0}}{{if .Hotspots}}{{$top = (index .Hotspots 0).Score}}{{end}}
+{{- range .Hotspots}}
+<tr style="{{heat .Score $top}}"><td class="file">{{.File}}</td><td>{{printf "%.0f" .Score}}</td><td>{{.Commits}}</td><td>{{.LinesChanged}}</td><td>{{.Complexity}}</td><td>{{.MaxComplexity}}</td><td>{{.Functions}}</td><td>{{percent .Coverage}}</td></tr>
+{{- else}}
+<tr><td colspan="8">No changed Go files in this period.</td></tr>
+{{- end}}
+</table>
+</body>
+</html>
+`))
+
+// WriteHTML renders the report as a standalone HTML page with rows shaded by
+// score.
+func (r *HotspotReport) WriteHTML(w io.Writer) error {
+	return hotspotTemplate.Execute(w, r)
+}
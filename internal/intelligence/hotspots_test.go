@@ -0,0 +1,77 @@
+package intelligence
+
+import (
+	"bytes"
+	"context"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestAnalyzeHotspots(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+	repo := t.TempDir()
+	gitRun := func(args ...string) {
+		t.Helper()
+		cmd := exec.Command("git", append([]string{"-c", "user.name=Dev", "-c", "user.email=dev@example.com", "-c", "commit.gpgsign=false"}, args...)...)
+		cmd.Dir = repo
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v: %s", args, err, out)
+		}
+	}
+	gitRun("init", "--quiet")
+
+	root := filepath.Join(repo, "svc")
+	branchy := "package svc\n\nfunc Branchy(x int) int {\n\tif x > 1 {\n\t\treturn 1\n\t}\n\treturn 0\n}\n"
+	writeTree(t, root, map[string]string{
+		"go.mod":    "module example.com/svc\n\ngo 1.25\n",
+		"hot.go":    branchy,
+		"cold.go":   "package svc\n\nfunc Cold() {}\n",
+		"README.md": "docs\n",
+	})
+	gitRun("add", ".")
+	gitRun("commit", "--quiet", "-m", "initial")
+	for _, body := range []string{"\n// v2\n", "\n// v3\n"} {
+		writeTree(t, root, map[string]string{"hot.go": branchy + body})
+		gitRun("commit", "--quiet", "-am", "change hot")
+	}
+	writeTree(t, repo, map[string]string{"cover.out": "mode: set\n" +
+		"example.com/svc/hot.go:3.25,4.11 1 1\n" +
+		"example.com/svc/hot.go:4.11,6.3 1 0\n" +
+		"example.com/svc/hot.go:4.11,6.3 1 0\n" +
+		"example.com/other/x.go:1.1,2.2 5 1\n"})
+
+	report, err := AnalyzeHotspots(context.Background(), root, HotspotOptions{CoverProfile: filepath.Join(repo, "cover.out")})
+	if err != nil {
+		t.Fatalf("AnalyzeHotspots() error = %v", err)
+	}
+	if len(report.Hotspots) != 2 {
+		t.Fatalf("hotspots = %+v", report.Hotspots)
+	}
+	hot, cold := report.Hotspots[0], report.Hotspots[1]
+	if hot.File != "hot.go" || hot.Commits != 3 || hot.Complexity != 2 || hot.Coverage == nil || *hot.Coverage != 0.5 {
+		t.Errorf("hot = %+v", hot)
+	}
+	if hot.Score != 3*2*1.5 {
+		t.Errorf("hot score = %v, want 9", hot.Score)
+	}
+	if cold.File != "cold.go" || cold.Commits != 1 || cold.Coverage != nil || cold.Score != 1 {
+		t.Errorf("cold = %+v", cold)
+	}
+
+	var html bytes.Buffer
+	if err := report.WriteHTML(&html); err != nil {
+		t.Fatalf("WriteHTML() error = %v", err)
+	}
+	if !strings.Contains(html.String(), "<td class=\"file\">hot.go</td>") || !strings.Contains(html.String(), "50%") {
+		t.Errorf("HTML report missing rows:\n%s", html.String())
+	}
+
+	limited, err := AnalyzeHotspots(context.Background(), root, HotspotOptions{Limit: 1})
+	if err != nil || len(limited.Hotspots) != 1 || limited.Hotspots[0].Coverage != nil {
+		t.Errorf("limited = %+v, %v", limited, err)
+	}
+}
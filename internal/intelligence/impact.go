@@ -0,0 +1,482 @@
+package intelligence
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"go/parser"
+	"go/token"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// Languages understood by the project graph.
+const (
+	LanguageGo         = "go"
+	LanguageJavaScript = "javascript"
+	LanguagePython     = "python"
+)
+
+// SourceNode is one unit in the project graph: a Go package directory, or a
+// single JavaScript, TypeScript, or Python module file. IDs are slash-separated
+// paths relative to the graph root, with "." for a Go package at the root.
+type SourceNode struct {
+	ID       string   `json:"id"`
+	Language string   `json:"language"`
+	Files    []string `json:"files"`
+	Imports  []string `json:"imports,omitempty"`
+	// Test is set for Go packages with _test.go files and for JavaScript and
+	// Python test modules.
+	Test bool `json:"test,omitempty"`
+}
+
+// ProjectGraph maps in-project imports between Go packages and JavaScript,
+// TypeScript, and Python modules. Imports of third-party code are dropped.
+type ProjectGraph struct {
+	Root  string                 `json:"root"`
+	Nodes map[string]*SourceNode `json:"nodes"`
+
+	dependents map[string][]string
+}
+
+// skippedDirs are never walked when building a project graph.
+var skippedDirs = map[string]bool{
+	"node_modules": true, "vendor": true, "testdata": true, "dist": true,
+	"build": true, "venv": true, "__pycache__": true, "site-packages": true,
+}
+
+var jsExtensions = []string{".ts", ".tsx", ".js", ".jsx", ".mjs", ".cjs"}
+
+var (
+	jsFromPattern     = regexp.MustCompile(`(?:import|export)\s[^'";]*?\bfrom\s*['"]([^'"]+)['"]`)
+	jsBarePattern     = regexp.MustCompile(`import\s*['"]([^'"]+)['"]`)
+	jsCallPattern     = regexp.MustCompile(`(?:require|import)\s*\(\s*['"]([^'"]+)['"]\s*\)`)
+	pyImportPattern   = regexp.MustCompile(`^\s*import\s+(.+)$`)
+	pyFromPattern     = regexp.MustCompile(`^\s*from\s+(\.*)([\w.]*)\s+import\s+(.+)$`)
+	goModulePattern   = regexp.MustCompile(`(?m)^\s*module\s+"?([^\s"]+)"?`)
+	jsTestFilePattern = regexp.MustCompile(`\.(test|spec)\.[cm]?[jt]sx?$`)
+)
+
+// BuildProjectGraph walks root and records in-project imports for Go,
+// JavaScript/TypeScript, and Python sources. Files that fail to parse keep
+// their node but contribute no imports, so a broken file never hides the rest
+// of the graph.
+func BuildProjectGraph(ctx context.Context, root string) (*ProjectGraph, error) {
+	return BuildProjectGraphCached(ctx, root, nil)
+}
+
+// BuildProjectGraphCached is BuildProjectGraph with the imports of each file
+// taken from cache while its content is unchanged. Imports are re-resolved
+// on every build, so added and removed files are always reflected.
+func BuildProjectGraphCached(ctx context.Context, root string, cache *AnalysisCache) (*ProjectGraph, error) {
+	root, err := filepath.Abs(root)
+	if err != nil {
+		return nil, err
+	}
+	graph := &ProjectGraph{Root: root, Nodes: make(map[string]*SourceNode)}
+	goModules := map[string]string{} // module directory -> module path
+	var goFiles, jsFiles, pyFiles []string
+
+	err = filepath.WalkDir(root, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return ctxErr
+		}
+		name := d.Name()
+		if d.IsDir() {
+			if p != root && (skippedDirs[name] || strings.HasPrefix(name, ".")) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		rel, err := filepath.Rel(root, p)
+		if err != nil {
+			return err
+		}
+		rel = filepath.ToSlash(rel)
+		switch ext := path.Ext(name); {
+		case name == "go.mod":
+			data, err := os.ReadFile(p)
+			if err != nil {
+				return err
+			}
+			if m := goModulePattern.FindSubmatch(data); m != nil {
+				goModules[path.Dir(rel)] = string(m[1])
+			}
+		case ext == ".go":
+			goFiles = append(goFiles, rel)
+		case ext == ".py":
+			pyFiles = append(pyFiles, rel)
+		case slices.Contains(jsExtensions, ext) && !strings.HasSuffix(name, ".d.ts"):
+			jsFiles = append(jsFiles, rel)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to walk %s: %w", root, err)
+	}
+
+	graph.addGo(cache, goFiles, goModules)
+	graph.addJavaScript(cache, jsFiles)
+	graph.addPython(cache, pyFiles)
+
+	graph.dependents = make(map[string][]string)
+	for id, node := range graph.Nodes {
+		slices.Sort(node.Files)
+		slices.Sort(node.Imports)
+		node.Imports = slices.Compact(node.Imports)
+		for _, imp := range node.Imports {
+			graph.dependents[imp] = append(graph.dependents[imp], id)
+		}
+	}
+	return graph, nil
+}
+
+// importSpecs returns the raw import specifiers of file rel as extracted by
+// extract, through cache.
+func (g *ProjectGraph) importSpecs(cache *AnalysisCache, kind, rel string, extract func(rel string, data []byte) []string) []string {
+	data, err := os.ReadFile(filepath.Join(g.Root, filepath.FromSlash(rel)))
+	if err != nil {
+		return nil
+	}
+	specs, _ := cached(cache, kind, rel, data, func() ([]string, error) {
+		return extract(rel, data), nil
+	})
+	return specs
+}
+
+func goImportSpecs(rel string, data []byte) []string {
+	file, err := parser.ParseFile(token.NewFileSet(), rel, data, parser.ImportsOnly)
+	if err != nil {
+		return nil
+	}
+	var specs []string
+	for _, spec := range file.Imports {
+		if importPath, err := strconv.Unquote(spec.Path.Value); err == nil {
+			specs = append(specs, importPath)
+		}
+	}
+	return specs
+}
+
+func (g *ProjectGraph) addGo(cache *AnalysisCache, files []string, modules map[string]string) {
+	for _, rel := range files {
+		dir := path.Dir(rel)
+		node := g.Nodes[dir]
+		if node == nil {
+			node = &SourceNode{ID: dir, Language: LanguageGo}
+			g.Nodes[dir] = node
+		}
+		node.Files = append(node.Files, rel)
+		if strings.HasSuffix(rel, "_test.go") {
+			node.Test = true
+		}
+		for _, importPath := range g.importSpecs(cache, "go-imports", rel, goImportSpecs) {
+			if target, ok := goImportDir(importPath, modules); ok && target != dir {
+				node.Imports = append(node.Imports, target)
+			}
+		}
+	}
+	// Imports of directories without Go files are not packages here.
+	for _, node := range g.Nodes {
+		node.Imports = slices.DeleteFunc(node.Imports, func(id string) bool { return g.Nodes[id] == nil })
+	}
+}
+
+// goImportDir maps an import path to a directory using the longest matching
+// module path.
+func goImportDir(importPath string, modules map[string]string) (string, bool) {
+	best, bestDir := "", ""
+	for dir, modulePath := range modules {
+		if (importPath == modulePath || strings.HasPrefix(importPath, modulePath+"/")) && len(modulePath) > len(best) {
+			best, bestDir = modulePath, dir
+		}
+	}
+	if best == "" {
+		return "", false
+	}
+	return path.Clean(path.Join(bestDir, strings.TrimPrefix(importPath, best))), true
+}
+
+func jsImportSpecs(_ string, data []byte) []string {
+	var specs []string
+	for _, pattern := range []*regexp.Regexp{jsFromPattern, jsBarePattern, jsCallPattern} {
+		for _, m := range pattern.FindAllSubmatch(data, -1) {
+			specs = append(specs, string(m[1]))
+		}
+	}
+	return specs
+}
+
+func (g *ProjectGraph) addJavaScript(cache *AnalysisCache, files []string) {
+	for _, rel := range files {
+		g.Nodes[rel] = &SourceNode{
+			ID:       rel,
+			Language: LanguageJavaScript,
+			Files:    []string{rel},
+			Test:     jsTestFilePattern.MatchString(rel) || strings.Contains("/"+rel, "/__tests__/"),
+		}
+	}
+	for _, rel := range files {
+		node := g.Nodes[rel]
+		for _, spec := range g.importSpecs(cache, "js-imports", rel, jsImportSpecs) {
+			if target := g.resolveJS(path.Dir(rel), spec); target != "" && target != rel {
+				node.Imports = append(node.Imports, target)
+			}
+		}
+	}
+}
+
+// resolveJS resolves a relative specifier the way bundlers and TypeScript do:
+// the exact file, the file with a known extension, a directory index, or a
+// .js specifier that refers to a .ts source.
+func (g *ProjectGraph) resolveJS(dir, spec string) string {
+	if !strings.HasPrefix(spec, "./") && !strings.HasPrefix(spec, "../") {
+		return ""
+	}
+	base := path.Join(dir, spec)
+	candidates := []string{base}
+	for _, ext := range jsExtensions {
+		candidates = append(candidates, base+ext)
+	}
+	for _, ext := range jsExtensions {
+		candidates = append(candidates, base+"/index"+ext)
+	}
+	if trimmed, ok := strings.CutSuffix(base, ".js"); ok {
+		candidates = append(candidates, trimmed+".ts", trimmed+".tsx")
+	}
+	for _, candidate := range candidates {
+		if node := g.Nodes[candidate]; node != nil && node.Language == LanguageJavaScript {
+			return candidate
+		}
+	}
+	return ""
+}
+
+// pythonImportSpecs lists imported modules as written, with leading dots for
+// relative imports. A name imported from a module is appended after a colon,
+// since it may itself be a submodule: "from .core import a" yields ".core"
+// and ".core:a".
+func pythonImportSpecs(_ string, data []byte) []string {
+	var specs []string
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		line := scanner.Text()
+		if m := pyFromPattern.FindStringSubmatch(line); m != nil {
+			module := m[1] + m[2]
+			specs = append(specs, module)
+			for _, name := range strings.Split(strings.Trim(m[3], "()"), ",") {
+				if fields := strings.Fields(name); len(fields) > 0 {
+					specs = append(specs, module+":"+fields[0])
+				}
+			}
+		} else if m := pyImportPattern.FindStringSubmatch(line); m != nil {
+			for _, name := range strings.Split(m[1], ",") {
+				if fields := strings.Fields(name); len(fields) > 0 {
+					specs = append(specs, fields[0])
+				}
+			}
+		}
+	}
+	return specs
+}
+
+func (g *ProjectGraph) addPython(cache *AnalysisCache, files []string) {
+	for _, rel := range files {
+		name := path.Base(rel)
+		g.Nodes[rel] = &SourceNode{
+			ID:       rel,
+			Language: LanguagePython,
+			Files:    []string{rel},
+			Test:     strings.HasPrefix(name, "test_") || strings.HasSuffix(name, "_test.py"),
+		}
+	}
+	for _, rel := range files {
+		node := g.Nodes[rel]
+		for _, spec := range g.importSpecs(cache, "python-imports", rel, pythonImportSpecs) {
+			if target := g.resolvePythonSpec(rel, spec); target != "" && target != rel {
+				node.Imports = append(node.Imports, target)
+			}
+		}
+	}
+}
+
+// resolvePythonSpec resolves a spec from pythonImportSpecs imported by rel.
+// Absolute imports are looked up from the project root and from src/.
+func (g *ProjectGraph) resolvePythonSpec(rel, spec string) string {
+	module, name, _ := strings.Cut(spec, ":")
+	trimmed := strings.TrimLeft(module, ".")
+	bases := []string{".", "src"}
+	if dots := len(module) - len(trimmed); dots > 0 {
+		base := path.Dir(rel)
+		for range dots - 1 {
+			base = path.Dir(base)
+		}
+		bases = []string{base}
+	}
+	for _, base := range bases {
+		modulePath := path.Join(base, strings.ReplaceAll(trimmed, ".", "/"))
+		if name != "" {
+			modulePath = path.Join(modulePath, name)
+		}
+		if target := g.resolvePython(modulePath); target != "" {
+			return target
+		}
+	}
+	return ""
+}
+
+func (g *ProjectGraph) resolvePython(modulePath string) string {
+	for _, candidate := range []string{modulePath + ".py", modulePath + "/__init__.py"} {
+		candidate = path.Clean(candidate)
+		if node := g.Nodes[candidate]; node != nil && node.Language == LanguagePython {
+			return candidate
+		}
+	}
+	return ""
+}
+
+// TestTarget is a test run scoped by an impact analysis: a Go package
+// pattern such as ./internal/mcp, or a JavaScript or Python test file.
+type TestTarget struct {
+	Language string `json:"language"`
+	Target   string `json:"target"`
+}
+
+// Impact is the result of ImpactAnalysis.
+type Impact struct {
+	ChangedFiles []string `json:"changed_files"`
+	// Changed are the nodes containing changed files.
+	Changed []string `json:"changed"`
+	// Affected are the changed nodes and everything that imports them,
+	// directly or transitively.
+	Affected []string `json:"affected"`
+	// Unmapped are changed files that are not in the graph, such as docs or
+	// deleted modules.
+	Unmapped    []string     `json:"unmapped,omitempty"`
+	TestTargets []TestTarget `json:"test_targets"`
+}
+
+// manifestLanguages maps dependency manifests to the language whose nodes
+// they affect under the manifest's directory.
+var manifestLanguages = map[string]string{
+	"go.mod":            LanguageGo,
+	"go.sum":            LanguageGo,
+	"package.json":      LanguageJavaScript,
+	"package-lock.json": LanguageJavaScript,
+	"yarn.lock":         LanguageJavaScript,
+	"pnpm-lock.yaml":    LanguageJavaScript,
+	"tsconfig.json":     LanguageJavaScript,
+	"pyproject.toml":    LanguagePython,
+	"requirements.txt":  LanguagePython,
+	"setup.py":          LanguagePython,
+	"uv.lock":           LanguagePython,
+}
+
+// ImpactAnalysis returns the nodes affected by changedFiles, which may be
+// absolute or relative to the graph root, and the tests that cover them.
+// A changed dependency manifest affects every node of its language below it.
+func (g *ProjectGraph) ImpactAnalysis(changedFiles []string) *Impact {
+	impact := &Impact{ChangedFiles: []string{}, Changed: []string{}, Affected: []string{}, TestTargets: []TestTarget{}}
+	changed := map[string]bool{}
+	for _, file := range changedFiles {
+		rel := file
+		if filepath.IsAbs(file) {
+			r, err := filepath.Rel(g.Root, file)
+			if err != nil || strings.HasPrefix(r, "..") {
+				impact.Unmapped = append(impact.Unmapped, file)
+				continue
+			}
+			rel = r
+		}
+		rel = path.Clean(filepath.ToSlash(rel))
+		impact.ChangedFiles = append(impact.ChangedFiles, rel)
+
+		if language, ok := manifestLanguages[path.Base(rel)]; ok {
+			dir := path.Dir(rel)
+			for id, node := range g.Nodes {
+				if node.Language == language && (dir == "." || id == dir || strings.HasPrefix(id, dir+"/")) {
+					changed[id] = true
+				}
+			}
+			continue
+		}
+		switch {
+		case g.Nodes[rel] != nil:
+			changed[rel] = true
+		case path.Ext(rel) == ".go" && g.Nodes[path.Dir(rel)] != nil:
+			// Also covers Go files deleted from a package that still exists.
+			changed[path.Dir(rel)] = true
+		default:
+			impact.Unmapped = append(impact.Unmapped, rel)
+		}
+	}
+
+	affected := map[string]bool{}
+	queue := make([]string, 0, len(changed))
+	for id := range changed {
+		impact.Changed = append(impact.Changed, id)
+		queue = append(queue, id)
+	}
+	for len(queue) > 0 {
+		id := queue[0]
+		queue = queue[1:]
+		if affected[id] {
+			continue
+		}
+		affected[id] = true
+		queue = append(queue, g.dependents[id]...)
+	}
+	for id := range affected {
+		impact.Affected = append(impact.Affected, id)
+		node := g.Nodes[id]
+		if !node.Test {
+			continue
+		}
+		target := id
+		if node.Language == LanguageGo && id != "." {
+			target = "./" + id
+		}
+		impact.TestTargets = append(impact.TestTargets, TestTarget{Language: node.Language, Target: target})
+	}
+
+	slices.Sort(impact.Changed)
+	slices.Sort(impact.Affected)
+	slices.SortFunc(impact.TestTargets, func(a, b TestTarget) int {
+		if c := strings.Compare(a.Language, b.Language); c != 0 {
+			return c
+		}
+		return strings.Compare(a.Target, b.Target)
+	})
+	return impact
+}
+
+// TestCommands returns commands that run only the impacted tests, one per
+// language: go test for Go packages, npm test for JavaScript test files, and
+// pytest for Python test files.
+func (i *Impact) TestCommands() []string {
+	byLanguage := map[string][]string{}
+	for _, target := range i.TestTargets {
+		byLanguage[target.Language] = append(byLanguage[target.Language], target.Target)
+	}
+	var commands []string
+	if targets := byLanguage[LanguageGo]; len(targets) > 0 {
+		commands = append(commands, "go test "+strings.Join(targets, " "))
+	}
+	if targets := byLanguage[LanguageJavaScript]; len(targets) > 0 {
+		commands = append(commands, "npm test -- "+strings.Join(targets, " "))
+	}
+	if targets := byLanguage[LanguagePython]; len(targets) > 0 {
+		commands = append(commands, "pytest "+strings.Join(targets, " "))
+	}
+	return commands
+}
@@ -0,0 +1,98 @@
+package intelligence
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func writeTree(t *testing.T, root string, files map[string]string) {
+	t.Helper()
+	for name, content := range files {
+		p := filepath.Join(root, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(p, []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestImpactAnalysis(t *testing.T) {
+	root := t.TempDir()
+	writeTree(t, root, map[string]string{
+		"go.mod":              "module example.com/app\n\ngo 1.25\n",
+		"main.go":             "package main\nimport \"example.com/app/api\"\nfunc main() { api.Serve() }\n",
+		"api/api.go":          "package api\nimport (\n\t\"fmt\"\n\t\"example.com/app/store\"\n)\nfunc Serve() { fmt.Println(store.Get()) }\n",
+		"api/api_test.go":     "package api\n",
+		"store/store.go":      "package store\nfunc Get() int { return 1 }\n",
+		"store/store_test.go": "package store\n",
+		"util/util.go":        "package util\n",
+
+		"web/src/format.ts":       "export const fmt = (s: string) => s\n",
+		"web/src/view.tsx":        "import { fmt } from './format'\nexport default fmt\n",
+		"web/src/view.test.tsx":   "import View from \"./view.js\"\nimport 'react'\n",
+		"web/src/legacy.js":       "const f = require('./format')\n",
+		"web/node_modules/x/a.js": "import '../../src/format'\n",
+		"web/package.json":        "{}\n",
+
+		"pkg/__init__.py":       "",
+		"pkg/core.py":           "import os\n",
+		"pkg/service.py":        "from . import core\nfrom .core import thing\n",
+		"tests/test_service.py": "from pkg.service import run\nimport pkg.core as c\n",
+		"scripts/broken.py":     "from pkg import (\n",
+	})
+
+	graph, err := BuildProjectGraph(context.Background(), root)
+	if err != nil {
+		t.Fatalf("BuildProjectGraph() error = %v", err)
+	}
+	if got := graph.Nodes["api"].Imports; !reflect.DeepEqual(got, []string{"store"}) {
+		t.Errorf("api imports = %v", got)
+	}
+	if got := graph.Nodes["web/src/view.test.tsx"].Imports; !reflect.DeepEqual(got, []string{"web/src/view.tsx"}) {
+		t.Errorf("view.test imports = %v", got)
+	}
+	if graph.Nodes["web/node_modules/x/a.js"] != nil {
+		t.Error("node_modules should be skipped")
+	}
+	if got := graph.Nodes["tests/test_service.py"].Imports; !reflect.DeepEqual(got, []string{"pkg/core.py", "pkg/service.py"}) {
+		t.Errorf("test_service imports = %v", got)
+	}
+
+	impact := graph.ImpactAnalysis([]string{
+		"store/store.go",
+		filepath.Join(root, "web", "src", "format.ts"),
+		"pkg/core.py",
+		"README.md",
+	})
+	if want := []string{".", "api", "store", "pkg/core.py", "pkg/service.py", "tests/test_service.py",
+		"web/src/format.ts", "web/src/legacy.js", "web/src/view.test.tsx", "web/src/view.tsx"}; len(impact.Affected) != len(want) {
+		t.Errorf("Affected = %v", impact.Affected)
+	}
+	if !reflect.DeepEqual(impact.Unmapped, []string{"README.md"}) {
+		t.Errorf("Unmapped = %v", impact.Unmapped)
+	}
+	wantTargets := []TestTarget{
+		{Language: LanguageGo, Target: "./api"},
+		{Language: LanguageGo, Target: "./store"},
+		{Language: LanguageJavaScript, Target: "web/src/view.test.tsx"},
+		{Language: LanguagePython, Target: "tests/test_service.py"},
+	}
+	if !reflect.DeepEqual(impact.TestTargets, wantTargets) {
+		t.Errorf("TestTargets = %v", impact.TestTargets)
+	}
+	wantCommands := []string{"go test ./api ./store", "npm test -- web/src/view.test.tsx", "pytest tests/test_service.py"}
+	if got := impact.TestCommands(); !reflect.DeepEqual(got, wantCommands) {
+		t.Errorf("TestCommands() = %v", got)
+	}
+
+	// A manifest change affects every node of its language beneath it.
+	impact = graph.ImpactAnalysis([]string{"go.mod"})
+	if !reflect.DeepEqual(impact.Changed, []string{".", "api", "store", "util"}) {
+		t.Errorf("Changed for go.mod = %v", impact.Changed)
+	}
+}
@@ -0,0 +1,240 @@
+package intelligence
+
+import (
+	"bufio"
+	"encoding/json"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"golang.org/x/mod/module"
+)
+
+// licenseTexts identifies common licenses by distinctive phrases, most
+// specific first. All phrases of an entry must appear.
+var licenseTexts = []struct {
+	id      string
+	phrases []string
+}{
+	{"AGPL-3.0", []string{"GNU AFFERO GENERAL PUBLIC LICENSE"}},
+	{"LGPL-3.0", []string{"GNU LESSER GENERAL PUBLIC LICENSE", "Version 3"}},
+	{"LGPL-2.1", []string{"GNU LESSER GENERAL PUBLIC LICENSE"}},
+	{"GPL-3.0", []string{"GNU GENERAL PUBLIC LICENSE", "Version 3"}},
+	{"GPL-2.0", []string{"GNU GENERAL PUBLIC LICENSE"}},
+	{"MPL-2.0", []string{"Mozilla Public License", "2.0"}},
+	{"Apache-2.0", []string{"Apache License", "Version 2.0"}},
+	{"BSD-3-Clause", []string{"Redistribution and use in source and binary forms", "Neither the name"}},
+	{"BSD-2-Clause", []string{"Redistribution and use in source and binary forms"}},
+	{"ISC", []string{"Permission to use, copy, modify, and/or distribute this software for any purpose"}},
+	{"MIT", []string{"Permission is hereby granted, free of charge"}},
+	{"Unlicense", []string{"This is free and unencumbered software released into the public domain"}},
+}
+
+// classifiedLicenses maps PyPI trove classifier suffixes to SPDX identifiers.
+var classifiedLicenses = map[string]string{
+	"MIT License":                                   "MIT",
+	"Apache Software License":                       "Apache-2.0",
+	"BSD License":                                   "BSD-3-Clause",
+	"ISC License (ISCL)":                            "ISC",
+	"Mozilla Public License 2.0 (MPL 2.0)":          "MPL-2.0",
+	"GNU General Public License v2 (GPLv2)":         "GPL-2.0",
+	"GNU General Public License v3 (GPLv3)":         "GPL-3.0",
+	"GNU Lesser General Public License v3 (LGPLv3)": "LGPL-3.0",
+	"GNU Affero General Public License v3":          "AGPL-3.0",
+	"The Unlicense (Unlicense)":                     "Unlicense",
+}
+
+// identifyLicense returns the SPDX identifier of a license text, or "".
+func identifyLicense(text string) string {
+	text = strings.Join(strings.Fields(text), " ")
+	for _, license := range licenseTexts {
+		matched := true
+		for _, phrase := range license.phrases {
+			if !strings.Contains(text, phrase) {
+				matched = false
+				break
+			}
+		}
+		if matched {
+			return license.id
+		}
+	}
+	return ""
+}
+
+// licenseStatus checks an SPDX expression against allowed identifiers. An
+// OR expression is allowed when any alternative is; an AND expression only
+// when every part is. Parentheses are ignored.
+func licenseStatus(expression string, allowed []string) string {
+	expression = strings.NewReplacer("(", " ", ")", " ").Replace(expression)
+	if strings.TrimSpace(expression) == "" {
+		return LicenseUnknown
+	}
+	for _, alternative := range strings.Split(expression, " OR ") {
+		ok := true
+		for _, part := range strings.Split(alternative, " AND ") {
+			if !licenseAllowed(strings.TrimSpace(part), allowed) {
+				ok = false
+				break
+			}
+		}
+		if ok {
+			return LicenseAllowed
+		}
+	}
+	return LicenseDenied
+}
+
+func licenseAllowed(id string, allowed []string) bool {
+	id = strings.TrimSuffix(id, "-only")
+	for _, candidate := range allowed {
+		if strings.EqualFold(strings.TrimSuffix(candidate, "-only"), id) {
+			return true
+		}
+	}
+	return false
+}
+
+// goModCache returns the Go module cache directory, or "".
+func goModCache() string {
+	if dir := os.Getenv("GOMODCACHE"); dir != "" {
+		return dir
+	}
+	if out, err := exec.Command("go", "env", "GOMODCACHE").Output(); err == nil {
+		return strings.TrimSpace(string(out))
+	}
+	return ""
+}
+
+// goModuleLicense identifies the license file of a downloaded module.
+func goModuleLicense(modCache string, mod module.Version) string {
+	if modCache == "" {
+		return ""
+	}
+	escapedPath, err := module.EscapePath(mod.Path)
+	if err != nil {
+		return ""
+	}
+	escapedVersion, err := module.EscapeVersion(mod.Version)
+	if err != nil {
+		return ""
+	}
+	return licenseFileIn(filepath.Join(modCache, escapedPath+"@"+escapedVersion))
+}
+
+// licenseFileIn identifies the first LICENSE, LICENCE, or COPYING file in dir.
+func licenseFileIn(dir string) string {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return ""
+	}
+	for _, entry := range entries {
+		name := strings.ToUpper(entry.Name())
+		if entry.IsDir() || !(strings.HasPrefix(name, "LICENSE") || strings.HasPrefix(name, "LICENCE") || strings.HasPrefix(name, "COPYING")) {
+			continue
+		}
+		data, err := os.ReadFile(filepath.Join(dir, entry.Name()))
+		if err != nil {
+			continue
+		}
+		if id := identifyLicense(string(data)); id != "" {
+			return id
+		}
+	}
+	return ""
+}
+
+// npmPackageLicense reads the license of an installed npm package.
+func npmPackageLicense(dir, name string) string {
+	data, err := os.ReadFile(filepath.Join(dir, "node_modules", filepath.FromSlash(name), "package.json"))
+	if err != nil {
+		return ""
+	}
+	var manifest struct {
+		License  json.RawMessage `json:"license"`
+		Licenses []struct {
+			Type string `json:"type"`
+		} `json:"licenses"`
+	}
+	if json.Unmarshal(data, &manifest) != nil {
+		return ""
+	}
+	var license string
+	if json.Unmarshal(manifest.License, &license) == nil && license != "" {
+		return license
+	}
+	// Older packages use {"type": "MIT"} or a "licenses" array.
+	var typed struct {
+		Type string `json:"type"`
+	}
+	if json.Unmarshal(manifest.License, &typed) == nil && typed.Type != "" {
+		return typed.Type
+	}
+	types := make([]string, 0, len(manifest.Licenses))
+	for _, l := range manifest.Licenses {
+		types = append(types, l.Type)
+	}
+	return strings.Join(types, " OR ")
+}
+
+// pythonPackageLicense reads the license of a package installed in a
+// virtualenv (.venv or venv) next to the requirements file.
+func pythonPackageLicense(dir, name string) string {
+	for _, venv := range []string{".venv", "venv"} {
+		matches, _ := filepath.Glob(filepath.Join(dir, venv, "lib", "python*", "site-packages", "*.dist-info"))
+		for _, distInfo := range matches {
+			distName, _, _ := strings.Cut(filepath.Base(distInfo), "-")
+			if normalizePythonName(distName) != name {
+				continue
+			}
+			if license := pythonMetadataLicense(filepath.Join(distInfo, "METADATA")); license != "" {
+				return license
+			}
+			return licenseFileIn(filepath.Join(distInfo, "licenses"))
+		}
+	}
+	return ""
+}
+
+// pythonMetadataLicense reads License-Expression, a trove classifier, or a
+// short License field from core metadata, in that order of preference.
+func pythonMetadataLicense(file string) string {
+	f, err := os.Open(file)
+	if err != nil {
+		return ""
+	}
+	defer func() { _ = f.Close() }()
+
+	var expression, classifier, field string
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if line == "" {
+			break // the body follows the headers
+		}
+		key, value, ok := strings.Cut(line, ": ")
+		if !ok {
+			continue
+		}
+		switch key {
+		case "License-Expression":
+			expression = value
+		case "Classifier":
+			if suffix, ok := strings.CutPrefix(value, "License :: OSI Approved :: "); ok && classifier == "" {
+				classifier = classifiedLicenses[suffix]
+			}
+		case "License":
+			// Some packages put the whole license text here.
+			if len(value) < 40 {
+				field = value
+			}
+		}
+	}
+	for _, license := range []string{expression, classifier, field} {
+		if license != "" {
+			return license
+		}
+	}
+	return ""
+}
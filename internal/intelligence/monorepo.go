@@ -0,0 +1,362 @@
+package intelligence
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"golang.org/x/mod/modfile"
+	"gopkg.in/yaml.v3"
+)
+
+// Monorepo tools and layouts recognized by DetectMonorepo.
+const (
+	ToolGoWork        = "go.work"
+	ToolGoModules     = "go modules"
+	ToolNPMWorkspaces = "npm workspaces"
+	ToolPNPM          = "pnpm workspaces"
+	ToolNx            = "nx"
+	ToolTurborepo     = "turborepo"
+)
+
+// Sub-project kinds.
+const (
+	ProjectGo   = "go"
+	ProjectNode = "node"
+)
+
+// SubProject is one independently built project of a repository: a Go
+// module or a JavaScript package.
+type SubProject struct {
+	// Dir is the slash-separated project directory relative to the root, "."
+	// for the root itself.
+	Dir string `json:"dir"`
+	// Name is the Go module path or the package name.
+	Name string `json:"name"`
+	Kind string `json:"kind"`
+	// DependsOn are the directories of the other sub-projects this one
+	// requires.
+	DependsOn []string `json:"depends_on,omitempty"`
+}
+
+// Monorepo is the layout of a repository's sub-projects. A repository with a
+// single project has one sub-project at ".".
+type Monorepo struct {
+	Root string `json:"root"`
+	// Tools are the monorepo tools and layouts found, such as go.work or nx.
+	Tools    []string     `json:"tools,omitempty"`
+	Projects []SubProject `json:"projects"`
+}
+
+// IsMonorepo reports whether the repository has more than one sub-project.
+func (m *Monorepo) IsMonorepo() bool {
+	return len(m.Projects) > 1
+}
+
+// Project returns the sub-project in dir.
+func (m *Monorepo) Project(dir string) (SubProject, bool) {
+	for _, project := range m.Projects {
+		if project.Dir == dir {
+			return project, true
+		}
+	}
+	return SubProject{}, false
+}
+
+// ProjectOf returns the innermost sub-project containing file, a
+// slash-separated path relative to the root.
+func (m *Monorepo) ProjectOf(file string) (SubProject, bool) {
+	file = path.Clean(file)
+	var best SubProject
+	found := false
+	for _, project := range m.Projects {
+		if project.Dir != "." && file != project.Dir && !strings.HasPrefix(file, project.Dir+"/") {
+			continue
+		}
+		if !found || len(project.Dir) > len(best.Dir) || best.Dir == "." {
+			best, found = project, true
+		}
+	}
+	return best, found
+}
+
+// Affected returns the sub-projects containing changedFiles and those that
+// depend on them, directly or transitively, ordered by directory.
+func (m *Monorepo) Affected(changedFiles []string) []SubProject {
+	dependents := map[string][]string{}
+	for _, project := range m.Projects {
+		for _, dep := range project.DependsOn {
+			dependents[dep] = append(dependents[dep], project.Dir)
+		}
+	}
+	affected := map[string]bool{}
+	var queue []string
+	for _, file := range changedFiles {
+		if project, ok := m.ProjectOf(filepath.ToSlash(file)); ok {
+			queue = append(queue, project.Dir)
+		}
+	}
+	for len(queue) > 0 {
+		dir := queue[0]
+		queue = queue[1:]
+		if affected[dir] {
+			continue
+		}
+		affected[dir] = true
+		queue = append(queue, dependents[dir]...)
+	}
+	var projects []SubProject
+	for _, project := range m.Projects {
+		if affected[project.Dir] {
+			projects = append(projects, project)
+		}
+	}
+	return projects
+}
+
+// Rel returns file, relative to the root, as a path relative to the
+// sub-project in dir.
+func Rel(dir, file string) string {
+	if dir == "." {
+		return file
+	}
+	return strings.TrimPrefix(strings.TrimPrefix(file, dir), "/")
+}
+
+// DetectMonorepo finds the sub-projects of the repository at root: the
+// modules of a go.work file, or else every go.mod; the packages of npm,
+// yarn, or pnpm workspaces; and nx projects. A repository without any of
+// these has its root as the only sub-project, when it has a manifest.
+func DetectMonorepo(ctx context.Context, root string) (*Monorepo, error) {
+	root, err := filepath.Abs(root)
+	if err != nil {
+		return nil, err
+	}
+	m := &Monorepo{Root: root, Projects: []SubProject{}}
+	dirs := map[string]string{} // project directory -> kind
+
+	if data, err := os.ReadFile(filepath.Join(root, "go.work")); err == nil {
+		work, err := modfile.ParseWork("go.work", data, nil)
+		if err != nil {
+			return nil, err
+		}
+		m.Tools = append(m.Tools, ToolGoWork)
+		for _, use := range work.Use {
+			dirs[path.Clean(filepath.ToSlash(use.Path))] = ProjectGo
+		}
+	}
+
+	var workspaceGlobs []string
+	if data, err := os.ReadFile(filepath.Join(root, "package.json")); err == nil {
+		var manifest struct {
+			Workspaces json.RawMessage `json:"workspaces"`
+		}
+		if json.Unmarshal(data, &manifest) == nil && len(manifest.Workspaces) > 0 {
+			var globs []string
+			var nested struct {
+				Packages []string `json:"packages"`
+			}
+			if json.Unmarshal(manifest.Workspaces, &globs) != nil && json.Unmarshal(manifest.Workspaces, &nested) == nil {
+				globs = nested.Packages
+			}
+			if len(globs) > 0 {
+				m.Tools = append(m.Tools, ToolNPMWorkspaces)
+				workspaceGlobs = append(workspaceGlobs, globs...)
+			}
+		}
+	}
+	if data, err := os.ReadFile(filepath.Join(root, "pnpm-workspace.yaml")); err == nil {
+		var manifest struct {
+			Packages []string `yaml:"packages"`
+		}
+		if err := yaml.Unmarshal(data, &manifest); err != nil {
+			return nil, fmt.Errorf("failed to parse pnpm-workspace.yaml: %w", err)
+		}
+		m.Tools = append(m.Tools, ToolPNPM)
+		workspaceGlobs = append(workspaceGlobs, manifest.Packages...)
+	}
+	nx := fileExistsIn(root, "nx.json")
+	if nx {
+		m.Tools = append(m.Tools, ToolNx)
+	}
+	if fileExistsIn(root, "turbo.json") {
+		m.Tools = append(m.Tools, ToolTurborepo)
+	}
+	for _, dir := range expandWorkspaceGlobs(root, workspaceGlobs) {
+		dirs[dir] = ProjectNode
+	}
+
+	// Walk for nested Go modules without a go.work, and for nx projects.
+	goWork := slices.Contains(m.Tools, ToolGoWork)
+	var goMods []string
+	err = filepath.WalkDir(root, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return ctxErr
+		}
+		if d.IsDir() {
+			if p != root && (skippedDirs[d.Name()] || strings.HasPrefix(d.Name(), ".")) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		rel, err := filepath.Rel(root, filepath.Dir(p))
+		if err != nil {
+			return err
+		}
+		rel = filepath.ToSlash(rel)
+		switch d.Name() {
+		case "go.mod":
+			goMods = append(goMods, rel)
+		case "project.json":
+			if _, ok := dirs[rel]; nx && !ok && rel != "." {
+				dirs[rel] = ProjectNode
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if !goWork {
+		if len(goMods) > 1 {
+			m.Tools = append(m.Tools, ToolGoModules)
+		}
+		for _, dir := range goMods {
+			dirs[dir] = ProjectGo
+		}
+	}
+	// A manifest at the root that is not a workspace root is a project.
+	if len(dirs) == 0 && fileExistsIn(root, "package.json") {
+		dirs["."] = ProjectNode
+	}
+
+	for dir, kind := range dirs {
+		project := SubProject{Dir: dir, Kind: kind}
+		abs := filepath.Join(root, filepath.FromSlash(dir))
+		switch kind {
+		case ProjectGo:
+			project.Name = readModulePath(abs)
+		case ProjectNode:
+			project.Name = readPackageName(abs)
+		}
+		m.Projects = append(m.Projects, project)
+	}
+	slices.SortFunc(m.Projects, func(a, b SubProject) int { return strings.Compare(a.Dir, b.Dir) })
+	linkProjects(m)
+	return m, nil
+}
+
+// expandWorkspaceGlobs returns the directories with a package.json matching
+// npm or pnpm workspace globs. Negated globs exclude directories.
+func expandWorkspaceGlobs(root string, globs []string) []string {
+	matched := map[string]bool{}
+	for _, glob := range globs {
+		exclude := strings.HasPrefix(glob, "!")
+		glob = strings.TrimSuffix(strings.TrimPrefix(strings.TrimPrefix(glob, "!"), "./"), "/")
+		// ** is treated as a single level, which covers the usual
+		// packages/** layouts.
+		pattern := strings.ReplaceAll(glob, "**", "*")
+		matches, _ := filepath.Glob(filepath.Join(root, filepath.FromSlash(pattern)))
+		for _, match := range matches {
+			if !fileExistsIn(match, "package.json") {
+				continue
+			}
+			rel, err := filepath.Rel(root, match)
+			if err != nil {
+				continue
+			}
+			rel = filepath.ToSlash(rel)
+			if exclude {
+				delete(matched, rel)
+			} else {
+				matched[rel] = true
+			}
+		}
+	}
+	var dirs []string
+	for dir := range matched {
+		dirs = append(dirs, dir)
+	}
+	slices.Sort(dirs)
+	return dirs
+}
+
+// linkProjects records which sub-projects require which: Go modules by
+// their go.mod requirements, and packages by their package.json
+// dependencies.
+func linkProjects(m *Monorepo) {
+	byName := map[string]string{}
+	for _, project := range m.Projects {
+		if project.Name != "" {
+			byName[project.Kind+" "+project.Name] = project.Dir
+		}
+	}
+	for i := range m.Projects {
+		project := &m.Projects[i]
+		abs := filepath.Join(m.Root, filepath.FromSlash(project.Dir))
+		var names []string
+		switch project.Kind {
+		case ProjectGo:
+			data, err := os.ReadFile(filepath.Join(abs, "go.mod"))
+			if err != nil {
+				continue
+			}
+			mod, err := modfile.ParseLax("go.mod", data, nil)
+			if err != nil {
+				continue
+			}
+			for _, req := range mod.Require {
+				names = append(names, req.Mod.Path)
+			}
+		case ProjectNode:
+			data, err := os.ReadFile(filepath.Join(abs, "package.json"))
+			if err != nil {
+				continue
+			}
+			var manifest map[string]json.RawMessage
+			if json.Unmarshal(data, &manifest) != nil {
+				continue
+			}
+			for _, field := range []string{"dependencies", "devDependencies", "peerDependencies"} {
+				var deps map[string]string
+				if json.Unmarshal(manifest[field], &deps) == nil {
+					for name := range deps {
+						names = append(names, name)
+					}
+				}
+			}
+		}
+		for _, name := range names {
+			if dir, ok := byName[project.Kind+" "+name]; ok && dir != project.Dir && !slices.Contains(project.DependsOn, dir) {
+				project.DependsOn = append(project.DependsOn, dir)
+			}
+		}
+		slices.Sort(project.DependsOn)
+	}
+}
+
+func readPackageName(dir string) string {
+	data, err := os.ReadFile(filepath.Join(dir, "package.json"))
+	if err != nil {
+		return ""
+	}
+	var manifest struct {
+		Name string `json:"name"`
+	}
+	_ = json.Unmarshal(data, &manifest)
+	return manifest.Name
+}
+
+func fileExistsIn(dir, name string) bool {
+	_, err := os.Stat(filepath.Join(dir, name))
+	return err == nil
+}
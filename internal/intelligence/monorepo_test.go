@@ -0,0 +1,110 @@
+package intelligence
+
+import (
+	"context"
+	"reflect"
+	"testing"
+)
+
+func projectDirs(projects []SubProject) []string {
+	dirs := []string{}
+	for _, project := range projects {
+		dirs = append(dirs, project.Dir)
+	}
+	return dirs
+}
+
+func TestDetectMonorepoGoWork(t *testing.T) {
+	root := t.TempDir()
+	writeTree(t, root, map[string]string{
+		"go.work":            "go 1.25\n\nuse (\n\t./api\n\t./lib\n)\n",
+		"api/go.mod":         "module example.com/api\n\ngo 1.25\n\nrequire example.com/lib v0.0.0\n",
+		"api/main.go":        "package main\n",
+		"lib/go.mod":         "module example.com/lib\n\ngo 1.25\n",
+		"lib/lib.go":         "package lib\n",
+		"tools/gen/go.mod":   "module example.com/gen\n\ngo 1.25\n",
+		"vendor/x/go.mod":    "module example.com/x\n",
+		"lib/sub/helpers.go": "package sub\n",
+	})
+
+	m, err := DetectMonorepo(context.Background(), root)
+	if err != nil {
+		t.Fatalf("DetectMonorepo() error = %v", err)
+	}
+	if !reflect.DeepEqual(m.Tools, []string{ToolGoWork}) {
+		t.Errorf("Tools = %v", m.Tools)
+	}
+	if got := projectDirs(m.Projects); !reflect.DeepEqual(got, []string{"api", "lib"}) {
+		t.Fatalf("projects = %v, want only the go.work modules", got)
+	}
+	api, _ := m.Project("api")
+	if api.Name != "example.com/api" || !reflect.DeepEqual(api.DependsOn, []string{"lib"}) {
+		t.Errorf("api = %+v", api)
+	}
+	if project, ok := m.ProjectOf("lib/sub/helpers.go"); !ok || project.Dir != "lib" {
+		t.Errorf("ProjectOf(lib/sub/helpers.go) = %+v, %v", project, ok)
+	}
+	if _, ok := m.ProjectOf("README.md"); ok {
+		t.Error("ProjectOf(README.md) found a project outside every module")
+	}
+
+	if got := projectDirs(m.Affected([]string{"lib/lib.go"})); !reflect.DeepEqual(got, []string{"api", "lib"}) {
+		t.Errorf("Affected(lib) = %v, want lib and its dependent api", got)
+	}
+	if got := projectDirs(m.Affected([]string{"api/main.go", "README.md"})); !reflect.DeepEqual(got, []string{"api"}) {
+		t.Errorf("Affected(api) = %v", got)
+	}
+}
+
+func TestDetectMonorepoWorkspaces(t *testing.T) {
+	root := t.TempDir()
+	writeTree(t, root, map[string]string{
+		"package.json":                `{"name": "root", "private": true, "workspaces": ["packages/*", "!packages/skip"]}`,
+		"turbo.json":                  "{}\n",
+		"packages/ui/package.json":    `{"name": "@acme/ui", "dependencies": {"react": "^18"}}`,
+		"packages/web/package.json":   `{"name": "@acme/web", "dependencies": {"@acme/ui": "*"}}`,
+		"packages/skip/package.json":  `{"name": "skip"}`,
+		"packages/notes/README.md":    "not a package\n",
+		"node_modules/x/package.json": `{"name": "x"}`,
+	})
+
+	m, err := DetectMonorepo(context.Background(), root)
+	if err != nil {
+		t.Fatalf("DetectMonorepo() error = %v", err)
+	}
+	if !reflect.DeepEqual(m.Tools, []string{ToolNPMWorkspaces, ToolTurborepo}) {
+		t.Errorf("Tools = %v", m.Tools)
+	}
+	if got := projectDirs(m.Projects); !reflect.DeepEqual(got, []string{"packages/ui", "packages/web"}) {
+		t.Fatalf("projects = %v", got)
+	}
+	if !m.IsMonorepo() {
+		t.Error("IsMonorepo() = false")
+	}
+	web, _ := m.Project("packages/web")
+	if web.Kind != ProjectNode || web.Name != "@acme/web" || !reflect.DeepEqual(web.DependsOn, []string{"packages/ui"}) {
+		t.Errorf("web = %+v", web)
+	}
+	if got := Rel("packages/web", "packages/web/src/index.ts"); got != "src/index.ts" {
+		t.Errorf("Rel() = %q", got)
+	}
+}
+
+func TestDetectMonorepoSingleProject(t *testing.T) {
+	root := t.TempDir()
+	writeTree(t, root, map[string]string{
+		"go.mod":  "module example.com/app\n\ngo 1.25\n",
+		"main.go": "package main\n",
+	})
+
+	m, err := DetectMonorepo(context.Background(), root)
+	if err != nil {
+		t.Fatalf("DetectMonorepo() error = %v", err)
+	}
+	if m.IsMonorepo() || len(m.Tools) != 0 {
+		t.Errorf("monorepo = %+v, want a single project", m)
+	}
+	if project, ok := m.ProjectOf("cmd/app/main.go"); !ok || project.Dir != "." || project.Name != "example.com/app" {
+		t.Errorf("ProjectOf() = %+v, %v", project, ok)
+	}
+}
@@ -0,0 +1,298 @@
+package intelligence
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// languageExtensions maps source file extensions to language names.
+var languageExtensions = map[string]string{
+	".go":    "Go",
+	".js":    "JavaScript",
+	".jsx":   "JavaScript",
+	".mjs":   "JavaScript",
+	".cjs":   "JavaScript",
+	".ts":    "TypeScript",
+	".tsx":   "TypeScript",
+	".py":    "Python",
+	".rs":    "Rust",
+	".java":  "Java",
+	".kt":    "Kotlin",
+	".rb":    "Ruby",
+	".php":   "PHP",
+	".cs":    "C#",
+	".c":     "C",
+	".h":     "C",
+	".cc":    "C++",
+	".cpp":   "C++",
+	".hpp":   "C++",
+	".swift": "Swift",
+	".sh":    "Shell",
+	".tf":    "Terraform",
+	".proto": "Protocol Buffers",
+	".sql":   "SQL",
+}
+
+// knownFrameworks maps dependency names to the frameworks they indicate. Go
+// entries are module path prefixes.
+var knownFrameworks = map[string]string{
+	"github.com/gin-gonic/gin":               "Gin",
+	"github.com/labstack/echo":               "Echo",
+	"github.com/gofiber/fiber":               "Fiber",
+	"github.com/go-chi/chi":                  "chi",
+	"github.com/gorilla/mux":                 "Gorilla Mux",
+	"github.com/spf13/cobra":                 "Cobra",
+	"google.golang.org/grpc":                 "gRPC",
+	"gorm.io/gorm":                           "GORM",
+	"github.com/modelcontextprotocol/go-sdk": "MCP Go SDK",
+	"github.com/stretchr/testify":            "testify",
+	"k8s.io/client-go":                       "Kubernetes client-go",
+	"sigs.k8s.io/controller-runtime":         "controller-runtime",
+	"react":                                  "React",
+	"next":                                   "Next.js",
+	"vue":                                    "Vue",
+	"nuxt":                                   "Nuxt",
+	"svelte":                                 "Svelte",
+	"@angular/core":                          "Angular",
+	"express":                                "Express",
+	"@nestjs/core":                           "NestJS",
+	"fastify":                                "Fastify",
+	"jest":                                   "Jest",
+	"vitest":                                 "Vitest",
+	"django":                                 "Django",
+	"flask":                                  "Flask",
+	"fastapi":                                "FastAPI",
+	"pytest":                                 "pytest",
+	"sqlalchemy":                             "SQLAlchemy",
+	"github.com/aws/aws-sdk-go-v2":           "AWS SDK",
+	"github.com/hashicorp/terraform-plugin-sdk": "Terraform Plugin SDK",
+}
+
+// LanguageStat counts the source files of one language.
+type LanguageStat struct {
+	Name  string `json:"name"`
+	Files int    `json:"files"`
+	Lines int    `json:"lines"`
+}
+
+// ProjectContext summarizes a project for planning: what it is written in,
+// what it builds on, how well it is tested, and what needs attention.
+type ProjectContext struct {
+	Root         string         `json:"root"`
+	Languages    []LanguageStat `json:"languages"`
+	Frameworks   []string       `json:"frameworks"`
+	Manifests    []string       `json:"manifests"`
+	Dependencies int            `json:"dependencies"`
+	TestFiles    int            `json:"test_files"`
+	// Coverage is the statement coverage from a cover profile, nil when no
+	// profile was given.
+	Coverage *float64        `json:"coverage,omitempty"`
+	Smells   []Smell         `json:"smells"`
+	Secrets  []SecretFinding `json:"secrets"`
+}
+
+// ProjectContextOptions configures AnalyzeProject.
+type ProjectContextOptions struct {
+	// Cache reuses per-file results for unchanged files; it may be nil.
+	Cache *AnalysisCache
+	// CoverProfile is a go test -coverprofile file; empty skips coverage.
+	CoverProfile string
+	// Smells sets the smell thresholds; nil skips smell detection.
+	Smells *SmellOptions
+	// SkipSecrets leaves out the secret scan.
+	SkipSecrets bool
+}
+
+// AnalyzeProject builds the ProjectContext of the project under root from
+// its files and manifests. It reads only the local tree, so it needs no
+// network access.
+func AnalyzeProject(ctx context.Context, root string, options ProjectContextOptions) (*ProjectContext, error) {
+	root, err := filepath.Abs(root)
+	if err != nil {
+		return nil, err
+	}
+	project := &ProjectContext{Root: root, Languages: []LanguageStat{}, Frameworks: []string{}, Manifests: []string{}, Smells: []Smell{}, Secrets: []SecretFinding{}}
+
+	languages := map[string]*LanguageStat{}
+	err = filepath.WalkDir(root, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return ctxErr
+		}
+		name := d.Name()
+		if d.IsDir() {
+			if p != root && (skippedDirs[name] || strings.HasPrefix(name, ".")) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		language, ok := languageExtensions[strings.ToLower(filepath.Ext(name))]
+		if !ok || !d.Type().IsRegular() {
+			return nil
+		}
+		stat := languages[language]
+		if stat == nil {
+			stat = &LanguageStat{Name: language}
+			languages[language] = stat
+		}
+		stat.Files++
+		if info, err := d.Info(); err == nil && info.Size() <= maxScanFileBytes {
+			stat.Lines += countLines(p)
+		}
+		if isTestFile(name) {
+			project.TestFiles++
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	for _, stat := range languages {
+		project.Languages = append(project.Languages, *stat)
+	}
+	slices.SortFunc(project.Languages, func(a, b LanguageStat) int {
+		if a.Lines != b.Lines {
+			return b.Lines - a.Lines
+		}
+		return strings.Compare(a.Name, b.Name)
+	})
+
+	dependencies, err := ReadDependencies(ctx, root)
+	if err != nil {
+		return nil, err
+	}
+	project.Dependencies = len(dependencies)
+	frameworks := map[string]bool{}
+	for _, dep := range dependencies {
+		if !slices.Contains(project.Manifests, dep.Manifest) {
+			project.Manifests = append(project.Manifests, dep.Manifest)
+		}
+		if framework := frameworkOf(dep.Name); framework != "" {
+			frameworks[framework] = true
+		}
+	}
+	for framework := range frameworks {
+		project.Frameworks = append(project.Frameworks, framework)
+	}
+	slices.Sort(project.Frameworks)
+	slices.Sort(project.Manifests)
+
+	if options.CoverProfile != "" {
+		statements, err := readCoverStatements(options.CoverProfile, root)
+		if err != nil {
+			return nil, err
+		}
+		var total coveredStatements
+		for _, counts := range statements {
+			total.covered += counts.covered
+			total.total += counts.total
+		}
+		if total.total > 0 {
+			coverage := float64(total.covered) / float64(total.total)
+			project.Coverage = &coverage
+		}
+	}
+
+	if options.Smells != nil {
+		smellOptions := *options.Smells
+		if smellOptions.Cache == nil {
+			smellOptions.Cache = options.Cache
+		}
+		smells, err := DetectSmells(ctx, root, smellOptions)
+		if err != nil {
+			return nil, err
+		}
+		project.Smells = append(project.Smells, smells...)
+	}
+	if !options.SkipSecrets {
+		secrets, err := ScanProjectSecrets(ctx, root)
+		if err != nil {
+			return nil, err
+		}
+		project.Secrets = append(project.Secrets, secrets...)
+	}
+	return project, nil
+}
+
+// frameworkOf returns the framework a dependency indicates, or "". Go module
+// paths match by prefix so major versions and subpackages count.
+func frameworkOf(name string) string {
+	if framework, ok := knownFrameworks[name]; ok {
+		return framework
+	}
+	for prefix, framework := range knownFrameworks {
+		if strings.Contains(prefix, "/") && strings.HasPrefix(name, prefix+"/") {
+			return framework
+		}
+	}
+	return ""
+}
+
+// countLines returns the number of lines in a file, or 0 if it cannot be
+// read.
+func countLines(file string) int {
+	data, err := os.ReadFile(file)
+	if err != nil || len(data) == 0 {
+		return 0
+	}
+	lines := bytes.Count(data, []byte("\n"))
+	if data[len(data)-1] != '\n' {
+		lines++
+	}
+	return lines
+}
+
+// isTestFile reports whether a file name follows a common test naming
+// convention.
+func isTestFile(name string) bool {
+	base := strings.TrimSuffix(name, filepath.Ext(name))
+	return strings.HasSuffix(name, "_test.go") ||
+		strings.HasSuffix(base, ".test") || strings.HasSuffix(base, ".spec") ||
+		(strings.HasSuffix(name, ".py") && (strings.HasPrefix(name, "test_") || strings.HasSuffix(base, "_test")))
+}
+
+// PromptContext renders the project context as a Markdown section for a
+// planning prompt, listing at most ten smells.
+func (p *ProjectContext) PromptContext() string {
+	var b strings.Builder
+	b.WriteString("### Project Context\n")
+	if len(p.Languages) > 0 {
+		parts := make([]string, 0, len(p.Languages))
+		for _, language := range p.Languages {
+			parts = append(parts, fmt.Sprintf("%s (%d files, %d lines)", language.Name, language.Files, language.Lines))
+		}
+		fmt.Fprintf(&b, "- Languages: %s\n", strings.Join(parts, ", "))
+	}
+	if len(p.Frameworks) > 0 {
+		fmt.Fprintf(&b, "- Frameworks: %s\n", strings.Join(p.Frameworks, ", "))
+	}
+	if len(p.Manifests) > 0 {
+		fmt.Fprintf(&b, "- Dependencies: %d from %s\n", p.Dependencies, strings.Join(p.Manifests, ", "))
+	}
+	fmt.Fprintf(&b, "- Test files: %d\n", p.TestFiles)
+	if p.Coverage != nil {
+		fmt.Fprintf(&b, "- Statement coverage: %.1f%%\n", *p.Coverage*100)
+	}
+	if len(p.Secrets) > 0 {
+		fmt.Fprintf(&b, "- Possible secrets committed: %d; do not copy or extend them\n", len(p.Secrets))
+	}
+	if len(p.Smells) > 0 {
+		fmt.Fprintf(&b, "\nDetected issues (%d):\n", len(p.Smells))
+		for i, smell := range p.Smells {
+			if i == 10 {
+				fmt.Fprintf(&b, "- ... and %d more\n", len(p.Smells)-10)
+				break
+			}
+			fmt.Fprintf(&b, "- `%s:%d` %s: %s\n", smell.File, smell.Line, smell.Name, smell.Message)
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,97 @@
+package intelligence
+
+import (
+	"fmt"
+	"path"
+	"strings"
+
+	"github.com/SamyRai/juleson/pkg/sarif"
+)
+
+// smellRules describe the SARIF rules for each smell kind.
+var smellRules = map[string]string{
+	SmellComplexity:     "Function has high cyclomatic complexity",
+	SmellLongFunction:   "Function is too long",
+	SmellManyParameters: "Function has too many parameters",
+	SmellUnusedExport:   "Exported identifier is not used outside its package",
+}
+
+// AddSmellResults adds smells to a SARIF run as warnings. Prefix is joined to
+// each file so paths are relative to the repository root.
+func AddSmellResults(b *sarif.Builder, prefix string, smells []Smell) {
+	for _, smell := range smells {
+		id := "smell/" + smell.Kind
+		b.AddRule(sarif.Rule{
+			ID:                   id,
+			ShortDescription:     sarif.Message{Text: smellRules[smell.Kind]},
+			DefaultConfiguration: &sarif.RuleDefaults{Level: sarif.LevelWarning},
+			Properties:           map[string]any{"tags": []string{"maintainability"}},
+		})
+		b.AddResult(id, "", fmt.Sprintf("%s: %s", smell.Name, smell.Message), path.Join(prefix, smell.File), smell.Line)
+	}
+}
+
+// AddSecretResults adds secret findings to a SARIF run as errors.
+func AddSecretResults(b *sarif.Builder, prefix string, findings []SecretFinding) {
+	for _, finding := range findings {
+		id := "secret/" + finding.RuleID
+		b.AddRule(sarif.Rule{
+			ID:                   id,
+			ShortDescription:     sarif.Message{Text: finding.Description + " committed to the repository"},
+			Help:                 &sarif.Message{Text: "Revoke the credential, remove it from the code, and load it from the environment or a secret store."},
+			DefaultConfiguration: &sarif.RuleDefaults{Level: sarif.LevelError},
+			Properties:           map[string]any{"tags": []string{"security"}, "security-severity": "8.0"},
+		})
+		b.AddResult(id, "", fmt.Sprintf("Possible %s: %s", strings.ToLower(finding.Description), finding.Match), path.Join(prefix, finding.File), finding.Line)
+	}
+}
+
+// securitySeverities maps OSV severity labels to code scanning scores.
+var securitySeverities = map[string]string{
+	"CRITICAL": "9.5",
+	"HIGH":     "8.0",
+	"MODERATE": "5.5",
+	"MEDIUM":   "5.5",
+	"LOW":      "2.0",
+}
+
+// AddDependencyResults adds the vulnerabilities and license violations of a
+// dependency audit to a SARIF run, located at the manifest that declares
+// each dependency.
+func AddDependencyResults(b *sarif.Builder, prefix string, audit *DependencyAudit) {
+	for _, dep := range audit.Dependencies {
+		manifest := path.Join(prefix, dep.Manifest)
+		for _, vuln := range dep.Vulnerabilities {
+			severity, ok := securitySeverities[vuln.Severity]
+			if !ok {
+				severity = "5.5"
+			}
+			summary := vuln.Summary
+			if summary == "" {
+				summary = "Known vulnerability " + vuln.ID
+			}
+			b.AddRule(sarif.Rule{
+				ID:                   vuln.ID,
+				ShortDescription:     sarif.Message{Text: summary},
+				Help:                 &sarif.Message{Text: "See https://osv.dev/vulnerability/" + vuln.ID},
+				DefaultConfiguration: &sarif.RuleDefaults{Level: sarif.LevelError},
+				Properties:           map[string]any{"tags": []string{"security", "dependency"}, "security-severity": severity},
+			})
+			message := fmt.Sprintf("%s %s is affected by %s", dep.Name, dep.Version, vuln.ID)
+			if len(vuln.Fixed) > 0 {
+				message += "; fixed in " + strings.Join(vuln.Fixed, ", ")
+			}
+			b.AddResult(vuln.ID, "", message, manifest, 0)
+		}
+		if dep.LicenseStatus == LicenseDenied {
+			b.AddRule(sarif.Rule{
+				ID:                   "license/denied",
+				ShortDescription:     sarif.Message{Text: "Dependency license is not allowed"},
+				Help:                 &sarif.Message{Text: "Replace the dependency or add its license to analysis.allowed_licenses."},
+				DefaultConfiguration: &sarif.RuleDefaults{Level: sarif.LevelError},
+				Properties:           map[string]any{"tags": []string{"license", "dependency"}},
+			})
+			b.AddResult("license/denied", "", fmt.Sprintf("%s is licensed %s", dep.Name, dep.License), manifest, 0)
+		}
+	}
+}
@@ -0,0 +1,37 @@
+package intelligence
+
+import (
+	"testing"
+
+	"github.com/SamyRai/juleson/pkg/sarif"
+)
+
+func TestSARIFResults(t *testing.T) {
+	b := sarif.NewBuilder("juleson", "dev", "")
+	AddSmellResults(b, "svc", []Smell{{Kind: SmellComplexity, File: "a.go", Name: "F", Line: 4, Message: "too complex"}})
+	AddSecretResults(b, "svc", []SecretFinding{{RuleID: "github-token", Description: "GitHub token", File: "b.env", Line: 1, Match: "ghp_…"}})
+	AddDependencyResults(b, "svc", &DependencyAudit{Dependencies: []AuditedDependency{{
+		Dependency:      Dependency{Name: "lodash", Version: "4.17.20", Manifest: "web/package.json", License: "GPL-3.0"},
+		Vulnerabilities: []DependencyVulnerability{{ID: "GHSA-1", Severity: "HIGH", Fixed: []string{"4.17.21"}}},
+		LicenseStatus:   LicenseDenied,
+	}}})
+
+	run := b.Log().Runs[0]
+	if len(run.Results) != 4 || len(run.Tool.Driver.Rules) != 4 {
+		t.Fatalf("run = %+v", run)
+	}
+	for i, want := range []struct{ rule, level, uri string }{
+		{"smell/complexity", sarif.LevelWarning, "svc/a.go"},
+		{"secret/github-token", sarif.LevelError, "svc/b.env"},
+		{"GHSA-1", sarif.LevelError, "svc/web/package.json"},
+		{"license/denied", sarif.LevelError, "svc/web/package.json"},
+	} {
+		result := run.Results[i]
+		if result.RuleID != want.rule || result.Level != want.level || result.Locations[0].PhysicalLocation.ArtifactLocation.URI != want.uri {
+			t.Errorf("result %d = %+v, want %+v", i, result, want)
+		}
+	}
+	if severity := run.Tool.Driver.Rules[2].Properties["security-severity"]; severity != "8.0" {
+		t.Errorf("vulnerability security-severity = %v", severity)
+	}
+}
@@ -0,0 +1,209 @@
+package intelligence
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"io/fs"
+	"math"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// maxScanFileBytes skips large files, which are rarely hand-written config.
+const maxScanFileBytes = 1 << 20
+
+// allowSecretMarkers on a line suppress findings for that line.
+var allowSecretMarkers = []string{"juleson:allow-secret", "gitleaks:allow"}
+
+// SecretRule matches one kind of credential. Group selects the submatch that
+// holds the secret, 0 for the whole match. Rules with MinEntropy only match
+// values at least that random, which filters out placeholders.
+type SecretRule struct {
+	Pattern     *regexp.Regexp
+	ID          string
+	Description string
+	Group       int
+	MinEntropy  float64
+}
+
+// DefaultSecretRules follow the gitleaks rule set for common providers plus a
+// generic assignment rule gated by entropy.
+var DefaultSecretRules = []SecretRule{
+	{ID: "private-key", Description: "Private key", Pattern: regexp.MustCompile(`-----BEGIN[ A-Z0-9_-]{0,100}PRIVATE KEY( BLOCK)?-----`)},
+	{ID: "aws-access-key-id", Description: "AWS access key ID", Pattern: regexp.MustCompile(`\b((?:A3T[A-Z0-9]|AKIA|ASIA|ABIA|ACCA)[A-Z2-7]{16})\b`), Group: 1},
+	{ID: "aws-secret-access-key", Description: "AWS secret access key", Pattern: regexp.MustCompile(`(?i)aws.{0,20}secret.{0,20}['"=:\s]([A-Za-z0-9/+=]{40})\b`), Group: 1, MinEntropy: 4},
+	{ID: "github-token", Description: "GitHub token", Pattern: regexp.MustCompile(`\b(gh[pousr]_[A-Za-z0-9]{36,255})\b`), Group: 1},
+	{ID: "github-fine-grained-token", Description: "GitHub fine-grained token", Pattern: regexp.MustCompile(`\b(github_pat_[A-Za-z0-9_]{82})\b`), Group: 1},
+	{ID: "gitlab-token", Description: "GitLab personal access token", Pattern: regexp.MustCompile(`\b(glpat-[A-Za-z0-9_-]{20})\b`), Group: 1},
+	{ID: "slack-token", Description: "Slack token", Pattern: regexp.MustCompile(`\b(xox[abposr]-[0-9A-Za-z-]{10,})\b`), Group: 1},
+	{ID: "slack-webhook", Description: "Slack webhook URL", Pattern: regexp.MustCompile(`https://hooks\.slack\.com/(?:services|workflows)/[A-Za-z0-9+/]{43,}`)},
+	{ID: "google-api-key", Description: "Google API key", Pattern: regexp.MustCompile(`\b(AIza[0-9A-Za-z_-]{35})\b`), Group: 1},
+	{ID: "stripe-secret-key", Description: "Stripe secret key", Pattern: regexp.MustCompile(`\b((?:sk|rk)_live_[0-9A-Za-z]{24,})\b`), Group: 1},
+	{ID: "openai-api-key", Description: "OpenAI API key", Pattern: regexp.MustCompile(`\b(sk-(?:proj-|svcacct-|admin-)?[A-Za-z0-9_-]{20,}T3BlbkFJ[A-Za-z0-9_-]{20,})\b`), Group: 1},
+	{ID: "anthropic-api-key", Description: "Anthropic API key", Pattern: regexp.MustCompile(`\b(sk-ant-(?:api|admin)\d{2}-[A-Za-z0-9_-]{80,})`), Group: 1},
+	{ID: "npm-token", Description: "npm access token", Pattern: regexp.MustCompile(`\b(npm_[A-Za-z0-9]{36})\b`), Group: 1},
+	{
+		ID:          "generic-secret",
+		Description: "Secret assigned in code or config",
+		Pattern:     regexp.MustCompile(`(?i)(?:api[_-]?key|secret|token|passw(?:or)?d|credential)[\w.-]{0,20}["']?\s*(?::=|=>|[:=])\s*["']([^"'\s]{16,})["']`),
+		Group:       1,
+		MinEntropy:  3.5,
+	},
+}
+
+// SecretFinding is a likely credential. Match is redacted so reports never
+// repeat the secret.
+type SecretFinding struct {
+	RuleID      string `json:"rule_id"`
+	Description string `json:"description"`
+	File        string `json:"file"`
+	Match       string `json:"match"`
+	Line        int    `json:"line"`
+}
+
+// ScanSecrets scans content line by line with the default rules.
+func ScanSecrets(file string, content []byte) []SecretFinding {
+	var findings []SecretFinding
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	scanner.Buffer(make([]byte, 0, 64*1024), maxScanFileBytes)
+	for line := 1; scanner.Scan(); line++ {
+		findings = append(findings, scanSecretLine(file, line, scanner.Text())...)
+	}
+	return findings
+}
+
+func scanSecretLine(file string, line int, text string) []SecretFinding {
+	for _, marker := range allowSecretMarkers {
+		if strings.Contains(text, marker) {
+			return nil
+		}
+	}
+	var findings []SecretFinding
+	for _, rule := range DefaultSecretRules {
+		for _, m := range rule.Pattern.FindAllStringSubmatch(text, -1) {
+			secret := m[rule.Group]
+			if rule.MinEntropy > 0 && (looksLikePlaceholder(secret) || shannonEntropy(secret) < rule.MinEntropy) {
+				continue
+			}
+			findings = append(findings, SecretFinding{
+				RuleID:      rule.ID,
+				Description: rule.Description,
+				File:        file,
+				Line:        line,
+				Match:       redactSecret(secret),
+			})
+		}
+	}
+	return findings
+}
+
+// looksLikePlaceholder reports template references and obvious dummies.
+func looksLikePlaceholder(value string) bool {
+	lower := strings.ToLower(value)
+	for _, marker := range []string{"${", "{{", "<", "example", "changeme", "placeholder", "xxxx", "dummy", "your_", "your-", "redacted"} {
+		if strings.Contains(lower, marker) {
+			return true
+		}
+	}
+	return false
+}
+
+// shannonEntropy returns the bits of entropy per character of value.
+func shannonEntropy(value string) float64 {
+	if value == "" {
+		return 0
+	}
+	counts := map[rune]int{}
+	for _, r := range value {
+		counts[r]++
+	}
+	length := float64(len([]rune(value)))
+	entropy := 0.0
+	for _, count := range counts {
+		p := float64(count) / length
+		entropy -= p * math.Log2(p)
+	}
+	return entropy
+}
+
+func redactSecret(secret string) string {
+	if len(secret) <= 8 {
+		return "****"
+	}
+	return secret[:4] + strings.Repeat("*", min(len(secret)-4, 12))
+}
+
+// ScanPatchSecrets scans only the lines a unified diff adds, reporting them
+// with their file and line number in the new version.
+func ScanPatchSecrets(patch string) []SecretFinding {
+	var (
+		findings []SecretFinding
+		file     string
+		line     int
+	)
+	for _, text := range strings.Split(patch, "\n") {
+		switch {
+		case strings.HasPrefix(text, "+++ "):
+			file = strings.TrimPrefix(strings.TrimSpace(strings.TrimPrefix(text, "+++ ")), "b/")
+		case strings.HasPrefix(text, "@@"):
+			// @@ -a,b +c,d @@
+			if fields := strings.Fields(text); len(fields) >= 3 {
+				start, _, _ := strings.Cut(strings.TrimPrefix(fields[2], "+"), ",")
+				line, _ = strconv.Atoi(start)
+			}
+		case strings.HasPrefix(text, "+"):
+			findings = append(findings, scanSecretLine(file, line, text[1:])...)
+			line++
+		case strings.HasPrefix(text, " "):
+			line++
+		}
+	}
+	return findings
+}
+
+// ScanProjectSecrets scans the text files under root, skipping the same
+// directories as BuildProjectGraph, binary files, and files over 1 MiB.
+func ScanProjectSecrets(ctx context.Context, root string) ([]SecretFinding, error) {
+	root, err := filepath.Abs(root)
+	if err != nil {
+		return nil, err
+	}
+	var findings []SecretFinding
+	err = filepath.WalkDir(root, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return ctxErr
+		}
+		name := d.Name()
+		if d.IsDir() {
+			if p != root && (skippedDirs[name] || strings.HasPrefix(name, ".")) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !d.Type().IsRegular() {
+			return nil
+		}
+		if info, err := d.Info(); err != nil || info.Size() > maxScanFileBytes {
+			return nil
+		}
+		data, err := os.ReadFile(p)
+		if err != nil || bytes.IndexByte(data, 0) >= 0 {
+			return nil
+		}
+		rel, err := filepath.Rel(root, p)
+		if err != nil {
+			return err
+		}
+		findings = append(findings, ScanSecrets(path.Clean(filepath.ToSlash(rel)), data)...)
+		return nil
+	})
+	return findings, err
+}
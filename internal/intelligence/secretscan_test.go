@@ -0,0 +1,80 @@
+package intelligence
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+// Test credentials are assembled at runtime so this file itself does not
+// trip secret scanners.
+var (
+	testAWSKey    = "AKIA" + "QYLPMN5HHHFPZAM2"
+	testGitHubPAT = "ghp_" + strings.Repeat("aB3dE6gH9k", 4)[:36]
+	testGeneric   = "q8Vz" + "T1mK7rXw2LpY9sNd"
+)
+
+func TestScanSecrets(t *testing.T) {
+	content := strings.Join([]string{
+		`aws_key = "` + testAWSKey + `"`,
+		`token := "` + testGitHubPAT + `"`,
+		`API_KEY: "` + testGeneric + `"`,
+		`password = "${DB_PASSWORD_FROM_ENV}"`,
+		`api_key = "aaaaaaaaaaaaaaaaaaaa"`,
+		`secret = "` + testGeneric + `" // juleson:allow-secret`,
+		"-----BEGIN RSA " + "PRIVATE KEY-----",
+	}, "\n")
+
+	findings := ScanSecrets("config.go", []byte(content))
+	got := map[string]int{}
+	for _, f := range findings {
+		got[f.RuleID] = f.Line
+		if strings.Contains(f.Match, testGeneric) || strings.Contains(f.Match, testGitHubPAT) {
+			t.Errorf("finding %s is not redacted: %q", f.RuleID, f.Match)
+		}
+	}
+	want := map[string]int{"aws-access-key-id": 1, "github-token": 2, "generic-secret": 3, "private-key": 7}
+	if len(got) != len(want) {
+		t.Errorf("findings = %+v", findings)
+	}
+	for rule, line := range want {
+		if got[rule] != line {
+			t.Errorf("rule %s line = %d, want %d", rule, got[rule], line)
+		}
+	}
+}
+
+func TestScanPatchSecretsOnlyAddedLines(t *testing.T) {
+	patch := "diff --git a/app.env b/app.env\n" +
+		"--- a/app.env\n" +
+		"+++ b/app.env\n" +
+		"@@ -10,3 +10,3 @@\n" +
+		" HOST=example.com\n" +
+		"-OLD_KEY=" + testAWSKey + "\n" +
+		"+NEW_KEY=" + testAWSKey + "\n" +
+		" PORT=80\n"
+
+	findings := ScanPatchSecrets(patch)
+	if len(findings) != 1 {
+		t.Fatalf("findings = %+v", findings)
+	}
+	if f := findings[0]; f.File != "app.env" || f.Line != 11 || f.RuleID != "aws-access-key-id" {
+		t.Errorf("finding = %+v", f)
+	}
+}
+
+func TestScanProjectSecrets(t *testing.T) {
+	root := t.TempDir()
+	writeTree(t, root, map[string]string{
+		"config/prod.yaml":         "github_token: " + testGitHubPAT + "\n",
+		"node_modules/pkg/key.txt": testAWSKey,
+		"clean.go":                 "package main\n",
+	})
+	findings, err := ScanProjectSecrets(context.Background(), root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(findings) != 1 || findings[0].File != "config/prod.yaml" {
+		t.Errorf("findings = %+v", findings)
+	}
+}
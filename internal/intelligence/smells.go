@@ -0,0 +1,280 @@
+package intelligence
+
+import (
+	"context"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// Smell kinds reported by DetectSmells.
+const (
+	SmellComplexity     = "complexity"
+	SmellLongFunction   = "long-function"
+	SmellManyParameters = "many-parameters"
+	SmellUnusedExport   = "unused-export"
+)
+
+// Smell is one finding from DetectSmells. Value and Limit are set for the
+// threshold-based kinds.
+type Smell struct {
+	Kind    string `json:"kind"`
+	Package string `json:"package"`
+	File    string `json:"file"`
+	Name    string `json:"name"`
+	Message string `json:"message"`
+	Line    int    `json:"line"`
+	Value   int    `json:"value,omitempty"`
+	Limit   int    `json:"limit,omitempty"`
+}
+
+// SmellOptions sets the thresholds for DetectSmells. A zero threshold
+// disables its check.
+type SmellOptions struct {
+	// Cache reuses per-file results for unchanged files; it may be nil.
+	Cache         *AnalysisCache
+	MaxComplexity int
+	MaxLines      int
+	MaxParams     int
+	// UnusedExports reports exported identifiers of internal packages that no
+	// other package in the module refers to.
+	UnusedExports bool
+}
+
+// DefaultSmellOptions returns the thresholds used by juleson dev smells.
+func DefaultSmellOptions() SmellOptions {
+	return SmellOptions{MaxComplexity: 10, MaxLines: 80, MaxParams: 5, UnusedExports: true}
+}
+
+// goFileSummary is the cached syntax summary of one Go file.
+type goFileSummary struct {
+	Package   string              `json:"package"`
+	Functions []functionSummary   `json:"functions,omitempty"`
+	Exports   []exportSummary     `json:"exports,omitempty"`
+	Uses      map[string][]string `json:"uses,omitempty"` // import path -> selected names, "*" for dot imports
+	Test      bool                `json:"test,omitempty"`
+}
+
+type functionSummary struct {
+	Name       string `json:"name"`
+	Line       int    `json:"line"`
+	Lines      int    `json:"lines"`
+	Params     int    `json:"params"`
+	Complexity int    `json:"complexity"`
+}
+
+type exportSummary struct {
+	Name string `json:"name"`
+	Kind string `json:"kind"`
+	Line int    `json:"line"`
+}
+
+func summarizeGoFile(rel string, data []byte) (*goFileSummary, error) {
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, rel, data, parser.SkipObjectResolution)
+	if err != nil {
+		return nil, err
+	}
+	summary := &goFileSummary{
+		Package: file.Name.Name,
+		Uses:    map[string][]string{},
+		Test:    strings.HasSuffix(rel, "_test.go"),
+	}
+
+	aliases := map[string]string{}
+	for _, spec := range file.Imports {
+		importPath, err := strconv.Unquote(spec.Path.Value)
+		if err != nil {
+			continue
+		}
+		name := path.Base(importPath)
+		if spec.Name != nil {
+			name = spec.Name.Name
+		}
+		switch name {
+		case "_":
+		case ".":
+			summary.Uses[importPath] = []string{"*"}
+		default:
+			aliases[name] = importPath
+		}
+	}
+	ast.Inspect(file, func(node ast.Node) bool {
+		if sel, ok := node.(*ast.SelectorExpr); ok {
+			if ident, ok := sel.X.(*ast.Ident); ok {
+				if importPath, ok := aliases[ident.Name]; ok {
+					summary.Uses[importPath] = append(summary.Uses[importPath], sel.Sel.Name)
+				}
+			}
+		}
+		return true
+	})
+
+	for _, decl := range file.Decls {
+		switch decl := decl.(type) {
+		case *ast.FuncDecl:
+			params := 0
+			for _, field := range decl.Type.Params.List {
+				params += max(len(field.Names), 1)
+			}
+			summary.Functions = append(summary.Functions, functionSummary{
+				Name:       functionName(decl),
+				Line:       fset.Position(decl.Pos()).Line,
+				Lines:      fset.Position(decl.End()).Line - fset.Position(decl.Pos()).Line + 1,
+				Params:     params,
+				Complexity: calculateComplexity(decl),
+			})
+			// Methods can satisfy interfaces, so only functions count as exports.
+			if decl.Recv == nil && decl.Name.IsExported() {
+				summary.Exports = append(summary.Exports, exportSummary{Name: decl.Name.Name, Kind: "func", Line: fset.Position(decl.Pos()).Line})
+			}
+		case *ast.GenDecl:
+			for _, spec := range decl.Specs {
+				switch spec := spec.(type) {
+				case *ast.TypeSpec:
+					if spec.Name.IsExported() {
+						summary.Exports = append(summary.Exports, exportSummary{Name: spec.Name.Name, Kind: "type", Line: fset.Position(spec.Pos()).Line})
+					}
+				case *ast.ValueSpec:
+					for _, name := range spec.Names {
+						if name.IsExported() {
+							summary.Exports = append(summary.Exports, exportSummary{Name: name.Name, Kind: decl.Tok.String(), Line: fset.Position(name.Pos()).Line})
+						}
+					}
+				}
+			}
+		}
+	}
+	for importPath, names := range summary.Uses {
+		slices.Sort(names)
+		summary.Uses[importPath] = slices.Compact(names)
+	}
+	return summary, nil
+}
+
+// DetectSmells reports overly complex, long, and wide functions, and unused
+// exports, in the Go files under root. It parses syntax only, so it needs no
+// external tools and works on code that does not build. Files that fail to
+// parse are skipped.
+func DetectSmells(ctx context.Context, root string, options SmellOptions) ([]Smell, error) {
+	root, err := filepath.Abs(root)
+	if err != nil {
+		return nil, err
+	}
+	summaries := map[string]*goFileSummary{}
+	err = walkGoFiles(ctx, root, true, func(rel string, data []byte) error {
+		summary, err := cached(options.Cache, "go-summary", rel, data, func() (*goFileSummary, error) {
+			return summarizeGoFile(rel, data)
+		})
+		if err == nil {
+			summaries[rel] = summary
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	files := make([]string, 0, len(summaries))
+	for rel := range summaries {
+		files = append(files, rel)
+	}
+	slices.Sort(files)
+
+	var smells []Smell
+	for _, rel := range files {
+		summary := summaries[rel]
+		if summary.Test {
+			continue
+		}
+		for _, fn := range summary.Functions {
+			smell := Smell{Package: path.Dir(rel), File: rel, Name: fn.Name, Line: fn.Line}
+			if options.MaxComplexity > 0 && fn.Complexity > options.MaxComplexity {
+				smell.Kind, smell.Value, smell.Limit = SmellComplexity, fn.Complexity, options.MaxComplexity
+				smell.Message = fmt.Sprintf("cyclomatic complexity %d exceeds %d", fn.Complexity, options.MaxComplexity)
+				smells = append(smells, smell)
+			}
+			if options.MaxLines > 0 && fn.Lines > options.MaxLines {
+				smell.Kind, smell.Value, smell.Limit = SmellLongFunction, fn.Lines, options.MaxLines
+				smell.Message = fmt.Sprintf("%d lines exceeds %d", fn.Lines, options.MaxLines)
+				smells = append(smells, smell)
+			}
+			if options.MaxParams > 0 && fn.Params > options.MaxParams {
+				smell.Kind, smell.Value, smell.Limit = SmellManyParameters, fn.Params, options.MaxParams
+				smell.Message = fmt.Sprintf("%d parameters exceeds %d", fn.Params, options.MaxParams)
+				smells = append(smells, smell)
+			}
+		}
+	}
+	if options.UnusedExports {
+		unused, err := unusedExports(root, files, summaries)
+		if err != nil {
+			return nil, err
+		}
+		smells = append(smells, unused...)
+	}
+	return smells, nil
+}
+
+// unusedExports finds exported functions, types, variables, and constants of
+// internal packages that no other package refers to. Only internal packages
+// are checked because their importers are all inside the module. Packages
+// named main, and exports used through dot imports, are never reported.
+func unusedExports(root string, files []string, summaries map[string]*goFileSummary) ([]Smell, error) {
+	modulePath := ""
+	if data, err := os.ReadFile(filepath.Join(root, "go.mod")); err == nil {
+		if m := goModulePattern.FindSubmatch(data); m != nil {
+			modulePath = string(m[1])
+		}
+	}
+	if modulePath == "" {
+		return nil, nil
+	}
+
+	// used[import path] holds names referenced from other packages.
+	used := map[string]map[string]bool{}
+	for _, rel := range files {
+		for importPath, names := range summaries[rel].Uses {
+			if used[importPath] == nil {
+				used[importPath] = map[string]bool{}
+			}
+			for _, name := range names {
+				used[importPath][name] = true
+			}
+		}
+	}
+
+	var smells []Smell
+	for _, rel := range files {
+		summary := summaries[rel]
+		dir := path.Dir(rel)
+		if summary.Test || summary.Package == "main" || !slices.Contains(strings.Split(dir, "/"), "internal") {
+			continue
+		}
+		importPath := modulePath + "/" + dir
+		if used[importPath]["*"] {
+			continue
+		}
+		for _, export := range summary.Exports {
+			if used[importPath][export.Name] {
+				continue
+			}
+			smells = append(smells, Smell{
+				Kind:    SmellUnusedExport,
+				Package: dir,
+				File:    rel,
+				Name:    export.Name,
+				Line:    export.Line,
+				Message: fmt.Sprintf("exported %s %s is not used outside its package", export.Kind, export.Name),
+			})
+		}
+	}
+	return smells, nil
+}
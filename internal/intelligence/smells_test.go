@@ -0,0 +1,84 @@
+package intelligence
+
+import (
+	"context"
+	"testing"
+)
+
+func TestDetectSmells(t *testing.T) {
+	root := t.TempDir()
+	writeTree(t, root, map[string]string{
+		"go.mod": "module example.com/app\n\ngo 1.25\n",
+		"main.go": `package main
+
+import (
+	"example.com/app/internal/store"
+	. "example.com/app/internal/dotted"
+)
+
+func main() { store.Open(); Helper() }
+`,
+		"internal/store/store.go": `package store
+
+// Open is used by main.
+func Open() {}
+
+// Close is never used outside the package.
+func Close() {}
+
+type Config struct{}
+
+func (c *Config) Exported() {}
+
+const Limit = 3
+
+func Wide(a, b, c int, d string, e, f bool) {}
+
+func Branchy(x int) int {
+	if x > 1 && x < 10 || x == 20 {
+		return 1
+	}
+	return 0
+}
+`,
+		"internal/store/store_test.go": "package store\n\nfunc TestOnly(a, b, c, d, e, f int) {}\n",
+		"internal/dotted/dotted.go":    "package dotted\n\nfunc Helper() {}\n\nfunc Other() {}\n",
+		"pkg/api/api.go":               "package api\n\nfunc Public() {}\n",
+		"internal/broken/broken.go":    "package broken\n\nfunc {\n",
+	})
+
+	options := SmellOptions{MaxComplexity: 3, MaxLines: 3, MaxParams: 5, UnusedExports: true}
+	smells, err := DetectSmells(context.Background(), root, options)
+	if err != nil {
+		t.Fatalf("DetectSmells() error = %v", err)
+	}
+
+	got := map[string]bool{}
+	for _, smell := range smells {
+		got[smell.Kind+" "+smell.Name] = true
+	}
+	for _, want := range []string{
+		"complexity Branchy",
+		"long-function Branchy",
+		"many-parameters Wide",
+		"unused-export Close",
+		"unused-export Config",
+		"unused-export Limit",
+		"unused-export Wide",
+	} {
+		if !got[want] {
+			t.Errorf("missing smell %q in %+v", want, smells)
+		}
+	}
+	for _, unwanted := range []string{
+		"unused-export Open",     // used by main
+		"unused-export Exported", // methods may satisfy interfaces
+		"unused-export Other",    // package is dot-imported
+		"unused-export Public",   // pkg/ is public API
+		"many-parameters TestOnly",
+	} {
+		if got[unwanted] {
+			t.Errorf("unexpected smell %q", unwanted)
+		}
+	}
+}
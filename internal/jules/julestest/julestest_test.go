@@ -0,0 +1,145 @@
+package julestest
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/SamyRai/go-jules"
+	"github.com/SamyRai/juleson/internal/jules/sessions"
+)
+
+func TestServerSessionLifecycle(t *testing.T) {
+	server := NewServer()
+	defer server.Close()
+	server.APIKey = "test-key"
+	source := server.AddSource("octo", "demo", "main")
+	client := server.Client()
+	ctx := context.Background()
+
+	session, err := client.Sessions().Create(ctx, &jules.CreateSessionRequest{
+		Prompt:              "Fix the flaky test\nwith details",
+		SourceContext:       &jules.SourceContext{Source: source.Name, GithubRepoContext: &jules.GithubRepoContext{StartingBranch: "main"}},
+		RequirePlanApproval: true,
+	})
+	if err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	if session.ID != "session-1" || session.Title != "Fix the flaky test" || session.State != jules.SessionStateQueued {
+		t.Fatalf("created session = %+v", session)
+	}
+
+	err = client.Sessions().ApprovePlan(ctx, session.ID)
+	if !errors.Is(sessions.Classify(err), sessions.ErrPlanNotReady) {
+		t.Fatalf("ApprovePlan before a plan = %v, want ErrPlanNotReady", err)
+	}
+
+	server.PostPlan(session.ID, jules.Plan{ID: "plan-1", Steps: []jules.Step{{Title: "Reproduce"}}})
+	if err := client.Sessions().ApprovePlan(ctx, session.ID); err != nil {
+		t.Fatalf("ApprovePlan: %v", err)
+	}
+	if got, _ := server.Session(session.ID); got.State != jules.SessionStateInProgress {
+		t.Fatalf("state after approval = %s", got.State)
+	}
+
+	server.SetState(session.ID, jules.SessionStateCompleted)
+	err = client.Sessions().SendMessage(ctx, session.ID, &jules.SendMessageRequest{Prompt: "one more thing"})
+	if !errors.Is(sessions.Classify(err), sessions.ErrSessionTerminal) {
+		t.Fatalf("SendMessage to a completed session = %v, want ErrSessionTerminal", err)
+	}
+
+	activities, err := client.Activities().List(ctx, session.ID, &jules.ListActivitiesOptions{PageSize: 1})
+	if err != nil {
+		t.Fatalf("List activities: %v", err)
+	}
+	if len(activities.Activities) != 1 || activities.Activities[0].PlanGenerated == nil || activities.NextPageToken == "" {
+		t.Fatalf("first activity page = %+v", activities)
+	}
+
+	if _, err := server.Client(jules.WithRetryAttempts(0)).Sessions().Get(ctx, "missing"); !errors.Is(sessions.Classify(err), sessions.ErrNotFound) {
+		t.Fatalf("Get missing session = %v, want ErrNotFound", err)
+	}
+	server.APIKey = "other-key"
+	if _, err := client.Sessions().Get(ctx, session.ID); !errors.Is(sessions.Classify(err), sessions.ErrUnauthorized) {
+		t.Fatalf("Get with a wrong key = %v, want ErrUnauthorized", err)
+	}
+}
+
+func TestServerFaults(t *testing.T) {
+	server := NewServer()
+	defer server.Close()
+	session := server.AddSession(jules.Session{Title: "demo"})
+	client := server.Client()
+	ctx := context.Background()
+
+	server.Fail(http.MethodGet, "/sessions/*", Fault{Status: http.StatusServiceUnavailable, Times: 1})
+	if _, err := client.Sessions().Get(ctx, session.ID); !errors.Is(sessions.Classify(err), sessions.ErrUnavailable) {
+		t.Fatalf("Get during a fault = %v, want ErrUnavailable", err)
+	}
+	if _, err := client.Sessions().Get(ctx, session.ID); err != nil {
+		t.Fatalf("Get after the fault = %v", err)
+	}
+
+	requests := server.Requests()
+	if len(requests) != 2 || requests[1].Path != "/sessions/"+session.ID {
+		t.Fatalf("requests = %+v", requests)
+	}
+}
+
+func TestRecordAndReplay(t *testing.T) {
+	server := NewServer()
+	defer server.Close()
+	server.APIKey = "secret-key"
+	server.AddSource("octo", "demo", "main")
+	session := server.AddSession(jules.Session{Title: "demo", Prompt: "token ghp_secret"})
+	ctx := context.Background()
+
+	recorder := NewRecorder(nil, "ghp_secret")
+	client := server.Client(jules.WithHTTPClient(&http.Client{Transport: recorder}))
+	if _, err := client.Sessions().Get(ctx, session.ID); err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	if _, err := client.Sources().List(ctx, nil); err != nil {
+		t.Fatalf("List sources: %v", err)
+	}
+	if _, err := client.Sessions().Get(ctx, "missing"); err == nil {
+		t.Fatal("Get missing session succeeded")
+	}
+
+	path := filepath.Join(t.TempDir(), "testdata", "cassette.json")
+	if err := recorder.Cassette().Save(path); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+	cassette, err := LoadCassette(path)
+	if err != nil {
+		t.Fatalf("LoadCassette: %v", err)
+	}
+	if len(cassette.Interactions) != 3 {
+		t.Fatalf("recorded %d interactions, want 3", len(cassette.Interactions))
+	}
+	for _, interaction := range cassette.Interactions {
+		if strings.Contains(string(interaction.ResponseBody), "ghp_secret") {
+			t.Fatalf("secret was recorded: %s", interaction.ResponseBody)
+		}
+	}
+
+	replayer := NewReplayer(cassette)
+	offline := jules.NewClient("", jules.WithBaseURL("https://jules.invalid"+APIVersion), jules.WithRetryAttempts(0),
+		jules.WithHTTPClient(&http.Client{Transport: replayer}))
+	got, err := offline.Sessions().Get(ctx, session.ID)
+	if err != nil || got.Title != "demo" || got.Prompt != "token "+Redacted {
+		t.Fatalf("replayed Get = %+v, %v", got, err)
+	}
+	if _, err := offline.Sessions().Get(ctx, "missing"); !errors.Is(sessions.Classify(err), sessions.ErrNotFound) {
+		t.Fatalf("replayed missing Get = %v, want ErrNotFound", err)
+	}
+	if _, err := offline.Sessions().Get(ctx, session.ID); err == nil {
+		t.Fatal("an interaction was replayed twice")
+	}
+	if remaining := replayer.Remaining(); len(remaining) != 1 || remaining[0].Path != "/sources" {
+		t.Fatalf("remaining = %+v", remaining)
+	}
+}
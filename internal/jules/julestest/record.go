@@ -0,0 +1,224 @@
+package julestest
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// Redacted replaces credentials and other secrets in recorded interactions.
+const Redacted = "REDACTED"
+
+// Interaction is one recorded request and its response. Headers are not
+// recorded, so credentials sent in them never reach a cassette.
+type Interaction struct {
+	Method string `json:"method"`
+	// Path is the request path after APIVersion.
+	Path         string          `json:"path"`
+	Query        string          `json:"query,omitempty"`
+	RequestBody  json.RawMessage `json:"request_body,omitempty"`
+	Status       int             `json:"status"`
+	ResponseBody json.RawMessage `json:"response_body,omitempty"`
+}
+
+// Cassette is a sequence of recorded interactions.
+type Cassette struct {
+	Interactions []Interaction `json:"interactions"`
+}
+
+// LoadCassette reads a cassette saved with Save.
+func LoadCassette(path string) (*Cassette, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read cassette: %w", err)
+	}
+	var cassette Cassette
+	if err := json.Unmarshal(data, &cassette); err != nil {
+		return nil, fmt.Errorf("failed to parse cassette %s: %w", path, err)
+	}
+	return &cassette, nil
+}
+
+// Save writes the cassette as indented JSON, creating its directory.
+func (c *Cassette) Save(path string) error {
+	data, err := json.MarshalIndent(c, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode cassette: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("failed to create cassette directory: %w", err)
+	}
+	return os.WriteFile(path, append(data, '\n'), 0o644)
+}
+
+// Recorder is an http.RoundTripper that records the interactions it passes
+// to Next. The API key, and any value in Redact, is replaced with Redacted
+// wherever it appears in a query or body.
+type Recorder struct {
+	Next   http.RoundTripper
+	Redact []string
+
+	mu       sync.Mutex
+	cassette Cassette
+}
+
+// NewRecorder creates a recorder that sends requests with next, or
+// http.DefaultTransport when next is nil.
+func NewRecorder(next http.RoundTripper, redact ...string) *Recorder {
+	if next == nil {
+		next = http.DefaultTransport
+	}
+	return &Recorder{Next: next, Redact: redact}
+}
+
+// RoundTrip sends req and records it with its response.
+func (r *Recorder) RoundTrip(req *http.Request) (*http.Response, error) {
+	var requestBody []byte
+	if req.Body != nil {
+		var err error
+		if requestBody, err = io.ReadAll(req.Body); err != nil {
+			return nil, err
+		}
+		req.Body.Close()
+		req = req.Clone(req.Context())
+		req.Body = io.NopCloser(bytes.NewReader(requestBody))
+	}
+
+	resp, err := r.Next.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	responseBody, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(responseBody))
+
+	secrets := append([]string{req.Header.Get("X-Goog-Api-Key")}, r.Redact...)
+	query := req.URL.Query()
+	query.Del("key")
+	path, _ := strings.CutPrefix(req.URL.EscapedPath(), APIVersion)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.cassette.Interactions = append(r.cassette.Interactions, Interaction{
+		Method:       req.Method,
+		Path:         path,
+		Query:        redact(query.Encode(), secrets),
+		RequestBody:  redactJSON(requestBody, secrets),
+		Status:       resp.StatusCode,
+		ResponseBody: redactJSON(responseBody, secrets),
+	})
+	return resp, nil
+}
+
+// Cassette returns the interactions recorded so far.
+func (r *Recorder) Cassette() *Cassette {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return &Cassette{Interactions: append([]Interaction(nil), r.cassette.Interactions...)}
+}
+
+func redact(s string, secrets []string) string {
+	for _, secret := range secrets {
+		if secret != "" {
+			s = strings.ReplaceAll(s, secret, Redacted)
+		}
+	}
+	return s
+}
+
+// redactJSON redacts a body, keeping it as raw JSON when it is valid and
+// as a JSON string otherwise.
+func redactJSON(body []byte, secrets []string) json.RawMessage {
+	if len(bytes.TrimSpace(body)) == 0 {
+		return nil
+	}
+	redacted := redact(string(body), secrets)
+	if json.Valid([]byte(redacted)) {
+		var compact bytes.Buffer
+		if json.Compact(&compact, []byte(redacted)) == nil {
+			return compact.Bytes()
+		}
+	}
+	quoted, _ := json.Marshal(redacted)
+	return quoted
+}
+
+// Replayer serves the interactions of a cassette, either as an
+// http.RoundTripper for jules.WithHTTPClient or as an http.Handler. Each
+// interaction answers one request with the same method, path, and query,
+// in recorded order; requests with no unused match get a 404.
+type Replayer struct {
+	mu        sync.Mutex
+	remaining []Interaction
+}
+
+// NewReplayer creates a replayer for the cassette.
+func NewReplayer(cassette *Cassette) *Replayer {
+	return &Replayer{remaining: append([]Interaction(nil), cassette.Interactions...)}
+}
+
+// Remaining returns the interactions not yet replayed.
+func (r *Replayer) Remaining() []Interaction {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]Interaction(nil), r.remaining...)
+}
+
+// RoundTrip answers req from the cassette without sending it.
+func (r *Replayer) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Body != nil {
+		req.Body.Close()
+	}
+	status, body := r.replay(req.Method, req.URL)
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", status, http.StatusText(status)),
+		StatusCode:    status,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        http.Header{"Content-Type": {"application/json"}},
+		Body:          io.NopCloser(bytes.NewReader(body)),
+		ContentLength: int64(len(body)),
+		Request:       req,
+	}, nil
+}
+
+// ServeHTTP answers the request from the cassette.
+func (r *Replayer) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	status, body := r.replay(req.Method, req.URL)
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_, _ = w.Write(body)
+}
+
+func (r *Replayer) replay(method string, u *url.URL) (int, []byte) {
+	path, _ := strings.CutPrefix(u.EscapedPath(), APIVersion)
+	query := u.Query()
+	query.Del("key")
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for i, interaction := range r.remaining {
+		if interaction.Method != method || interaction.Path != path || !sameQuery(interaction.Query, query) {
+			continue
+		}
+		r.remaining = append(r.remaining[:i], r.remaining[i+1:]...)
+		return interaction.Status, interaction.ResponseBody
+	}
+	return http.StatusNotFound, []byte(ErrorBody(http.StatusNotFound, fmt.Sprintf("julestest: no recorded interaction for %s %s", method, path)))
+}
+
+func sameQuery(recorded string, query url.Values) bool {
+	values, err := url.ParseQuery(recorded)
+	return err == nil && values.Encode() == query.Encode()
+}
@@ -0,0 +1,524 @@
+// Package julestest provides a fake Jules API server and record/replay of
+// real API interactions, so tests of code built on jules.Client run offline
+// without credentials.
+//
+// A Server keeps sessions, activities, and sources in memory and implements
+// the endpoints jules.Client calls, including the state rules the real API
+// enforces for approving plans and sending messages. A Recorder captures
+// real interactions, with credentials removed, into a cassette file that a
+// Replayer serves back.
+package julestest
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/SamyRai/go-jules"
+)
+
+// APIVersion is the path prefix served by Server and Replayer.
+const APIVersion = "/v1alpha"
+
+// Request is a request received by a Server.
+type Request struct {
+	Method string
+	// Path is the request path after APIVersion, e.g. "/sessions/s1".
+	Path  string
+	Query url.Values
+	Body  string
+}
+
+// Fault is a canned error response returned instead of handling a request.
+type Fault struct {
+	Status int
+	// Body defaults to a Google API error for Status.
+	Body string
+	// Times is how many matching requests fail; zero means every one.
+	Times int
+}
+
+// Server is an in-memory fake of the Jules API.
+type Server struct {
+	// APIKey, when set, is required in the X-Goog-Api-Key header.
+	APIKey string
+	// PageSize is the default page size for list endpoints (default 50).
+	PageSize int
+	// Simulate makes created sessions advance on their own: each posts a
+	// plan right away and completes once the plan is approved, or at once
+	// when approval is not required.
+	Simulate bool
+
+	server *httptest.Server
+
+	mu         sync.Mutex
+	now        func() time.Time
+	nextID     int
+	sessions   []*jules.Session
+	activities map[string][]jules.Activity
+	sources    []jules.Source
+	faults     map[string]*Fault
+	requests   []Request
+}
+
+// NewServer starts a fake Jules API server. Call Close when done.
+func NewServer() *Server {
+	s := &Server{
+		PageSize:   50,
+		now:        time.Now,
+		activities: map[string][]jules.Activity{},
+		faults:     map[string]*Fault{},
+	}
+	s.server = httptest.NewServer(http.HandlerFunc(s.serveHTTP))
+	return s
+}
+
+// Close shuts the server down.
+func (s *Server) Close() {
+	s.server.Close()
+}
+
+// URL returns the base URL to pass to jules.WithBaseURL or jules.base_url.
+func (s *Server) URL() string {
+	return s.server.URL + APIVersion
+}
+
+// Client returns a Jules client for the server, without retries.
+func (s *Server) Client(options ...jules.ClientOption) *jules.Client {
+	options = append([]jules.ClientOption{
+		jules.WithBaseURL(s.URL()),
+		jules.WithRetryAttempts(0),
+	}, options...)
+	return jules.NewClient(s.APIKey, options...)
+}
+
+// AddSource adds a GitHub source named "sources/github/OWNER/REPO".
+func (s *Server) AddSource(owner, repo, defaultBranch string) jules.Source {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	source := jules.Source{
+		Name: "sources/github/" + owner + "/" + repo,
+		ID:   "github/" + owner + "/" + repo,
+		GithubRepo: &jules.GithubRepo{
+			Owner:         owner,
+			Repo:          repo,
+			DefaultBranch: &jules.Branch{DisplayName: defaultBranch},
+			Branches:      []jules.Branch{{DisplayName: defaultBranch}},
+		},
+	}
+	s.sources = append(s.sources, source)
+	return source
+}
+
+// AddSession adds a session, filling in its ID, name, URL, timestamps, and
+// state when they are empty, and returns the stored copy.
+func (s *Server) AddSession(session jules.Session) jules.Session {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return *s.addSessionLocked(session)
+}
+
+func (s *Server) addSessionLocked(session jules.Session) *jules.Session {
+	if session.ID == "" {
+		s.nextID++
+		session.ID = "session-" + strconv.Itoa(s.nextID)
+	}
+	session.Name = "sessions/" + session.ID
+	if session.URL == "" {
+		session.URL = "https://jules.google.com/session/" + session.ID
+	}
+	if session.State == "" {
+		session.State = jules.SessionStateQueued
+	}
+	now := s.now().UTC()
+	if session.CreateTime.IsZero() {
+		session.CreateTime = now
+	}
+	session.UpdateTime = now
+	stored := &session
+	s.sessions = append(s.sessions, stored)
+	return stored
+}
+
+// Session returns a copy of the session with the given ID.
+func (s *Server) Session(id string) (jules.Session, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	session := s.sessionLocked(id)
+	if session == nil {
+		return jules.Session{}, false
+	}
+	return *session, true
+}
+
+func (s *Server) sessionLocked(id string) *jules.Session {
+	for _, session := range s.sessions {
+		if session.ID == id {
+			return session
+		}
+	}
+	return nil
+}
+
+// SetState changes a session's state, as Jules does while it works.
+func (s *Server) SetState(id string, state jules.SessionState) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if session := s.sessionLocked(id); session != nil {
+		session.State = state
+		session.UpdateTime = s.now().UTC()
+	}
+}
+
+// SetOutputs sets the outputs, such as pull requests, of a session.
+func (s *Server) SetOutputs(id string, outputs ...jules.Output) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if session := s.sessionLocked(id); session != nil {
+		session.Outputs = outputs
+	}
+}
+
+// AddActivities appends activities to a session, filling in their IDs,
+// names, and creation times when they are empty.
+func (s *Server) AddActivities(sessionID string, activities ...jules.Activity) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.addActivitiesLocked(sessionID, activities...)
+}
+
+func (s *Server) addActivitiesLocked(sessionID string, activities ...jules.Activity) {
+	for _, activity := range activities {
+		if activity.ID == "" {
+			activity.ID = "activity-" + strconv.Itoa(len(s.activities[sessionID])+1)
+		}
+		activity.Name = "sessions/" + sessionID + "/activities/" + activity.ID
+		if activity.CreateTime.IsZero() {
+			activity.CreateTime = s.now().UTC()
+		}
+		s.activities[sessionID] = append(s.activities[sessionID], activity)
+	}
+}
+
+// PostPlan adds a planGenerated activity to a session and moves it to
+// AWAITING_PLAN_APPROVAL.
+func (s *Server) PostPlan(sessionID string, plan jules.Plan) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.addActivitiesLocked(sessionID, jules.Activity{
+		Originator:    jules.ActivityOriginatorAgent,
+		PlanGenerated: &jules.PlanGenerated{Plan: plan},
+	})
+	if session := s.sessionLocked(sessionID); session != nil {
+		session.State = jules.SessionStateAwaitingPlanApproval
+		session.UpdateTime = s.now().UTC()
+	}
+}
+
+// Fail makes requests whose method and path match fail with fault. The path
+// is relative to APIVersion, e.g. "/sessions/s1:approvePlan"; a trailing
+// "*" matches any suffix.
+func (s *Server) Fail(method, path string, fault Fault) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if fault.Body == "" {
+		fault.Body = ErrorBody(fault.Status, http.StatusText(fault.Status))
+	}
+	s.faults[method+" "+path] = &fault
+}
+
+// Requests returns the requests the server has received, oldest first.
+func (s *Server) Requests() []Request {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return slices.Clone(s.requests)
+}
+
+// ErrorBody returns a Google API error response body.
+func ErrorBody(status int, message string) string {
+	body, _ := json.Marshal(map[string]any{"error": map[string]any{
+		"code":    status,
+		"message": message,
+		"status":  googleStatus(status),
+	}})
+	return string(body)
+}
+
+func googleStatus(status int) string {
+	switch status {
+	case http.StatusBadRequest:
+		return "FAILED_PRECONDITION"
+	case http.StatusUnauthorized:
+		return "UNAUTHENTICATED"
+	case http.StatusForbidden:
+		return "PERMISSION_DENIED"
+	case http.StatusNotFound:
+		return "NOT_FOUND"
+	case http.StatusTooManyRequests:
+		return "RESOURCE_EXHAUSTED"
+	case http.StatusServiceUnavailable:
+		return "UNAVAILABLE"
+	default:
+		return "INTERNAL"
+	}
+}
+
+func (s *Server) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	body, _ := io.ReadAll(r.Body)
+	path, ok := strings.CutPrefix(r.URL.EscapedPath(), APIVersion)
+	if unescaped, err := url.PathUnescape(path); err == nil {
+		path = unescaped
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.requests = append(s.requests, Request{Method: r.Method, Path: path, Query: r.URL.Query(), Body: string(body)})
+
+	switch {
+	case !ok:
+		writeError(w, http.StatusNotFound, "unknown API version")
+		return
+	case s.APIKey != "" && r.Header.Get("X-Goog-Api-Key") != s.APIKey:
+		writeError(w, http.StatusUnauthorized, "API key not valid")
+		return
+	}
+	if fault := s.faultLocked(r.Method, path); fault != nil {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(fault.Status)
+		_, _ = io.WriteString(w, fault.Body)
+		return
+	}
+	s.route(w, r, path, body)
+}
+
+func (s *Server) faultLocked(method, path string) *Fault {
+	for key, fault := range s.faults {
+		pattern, found := strings.CutPrefix(key, method+" ")
+		if !found {
+			continue
+		}
+		prefix, wildcard := strings.CutSuffix(pattern, "*")
+		if pattern != path && !(wildcard && strings.HasPrefix(path, prefix)) {
+			continue
+		}
+		if fault.Times > 0 {
+			fault.Times--
+			if fault.Times == 0 {
+				delete(s.faults, key)
+			}
+		}
+		return fault
+	}
+	return nil
+}
+
+func (s *Server) route(w http.ResponseWriter, r *http.Request, path string, body []byte) {
+	resource, action, _ := strings.Cut(path, ":")
+	parts := strings.Split(strings.Trim(resource, "/"), "/")
+
+	switch {
+	case parts[0] == "sessions" && len(parts) == 1 && action == "":
+		switch r.Method {
+		case http.MethodGet:
+			s.listSessions(w, r)
+		case http.MethodPost:
+			s.createSession(w, body)
+		default:
+			writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+		}
+	case parts[0] == "sessions" && len(parts) == 2:
+		s.sessionAction(w, r.Method, parts[1], action, body)
+	case parts[0] == "sessions" && len(parts) >= 3 && parts[2] == "activities" && r.Method == http.MethodGet:
+		s.activity(w, r, parts[1], parts[3:])
+	case parts[0] == "sources" && r.Method == http.MethodGet:
+		s.source(w, r, strings.Join(parts[1:], "/"))
+	default:
+		writeError(w, http.StatusNotFound, "unknown resource "+path)
+	}
+}
+
+func (s *Server) listSessions(w http.ResponseWriter, r *http.Request) {
+	sessions := make([]jules.Session, 0, len(s.sessions))
+	for i := len(s.sessions) - 1; i >= 0; i-- {
+		sessions = append(sessions, *s.sessions[i])
+	}
+	slices.SortStableFunc(sessions, func(a, b jules.Session) int {
+		return b.CreateTime.Compare(a.CreateTime)
+	})
+	page, next := paginate(sessions, r.URL.Query(), s.PageSize)
+	writeJSON(w, jules.SessionsResponse{Sessions: page, NextPageToken: next})
+}
+
+func (s *Server) createSession(w http.ResponseWriter, body []byte) {
+	var req jules.CreateSessionRequest
+	if err := json.Unmarshal(body, &req); err != nil || strings.TrimSpace(req.Prompt) == "" {
+		writeError(w, http.StatusBadRequest, "prompt is required")
+		return
+	}
+	if req.SourceContext != nil && !slices.ContainsFunc(s.sources, func(source jules.Source) bool {
+		return source.Name == req.SourceContext.Source
+	}) {
+		writeError(w, http.StatusNotFound, "source not found: "+req.SourceContext.Source)
+		return
+	}
+	title := req.Title
+	if title == "" {
+		title, _, _ = strings.Cut(req.Prompt, "\n")
+	}
+	session := s.addSessionLocked(jules.Session{
+		Title:               title,
+		Prompt:              req.Prompt,
+		SourceContext:       req.SourceContext,
+		RequirePlanApproval: req.RequirePlanApproval,
+		AutomationMode:      req.AutomationMode,
+	})
+	if s.Simulate {
+		s.addActivitiesLocked(session.ID, jules.Activity{
+			Originator:    jules.ActivityOriginatorAgent,
+			PlanGenerated: &jules.PlanGenerated{Plan: jules.Plan{ID: "plan-" + session.ID, Steps: []jules.Step{{Title: title}}}},
+		})
+		session.State = jules.SessionStateAwaitingPlanApproval
+		if !session.RequirePlanApproval {
+			s.completeLocked(session)
+		}
+	}
+	writeJSON(w, session)
+}
+
+func (s *Server) sessionAction(w http.ResponseWriter, method, id, action string, body []byte) {
+	session := s.sessionLocked(id)
+	if session == nil {
+		writeError(w, http.StatusNotFound, "session not found: "+id)
+		return
+	}
+
+	switch {
+	case action == "" && method == http.MethodGet:
+		writeJSON(w, session)
+	case action == "" && method == http.MethodDelete:
+		s.sessions = slices.DeleteFunc(s.sessions, func(other *jules.Session) bool { return other.ID == id })
+		delete(s.activities, id)
+		writeJSON(w, struct{}{})
+	case action == "approvePlan" && method == http.MethodPost:
+		if session.State != jules.SessionStateAwaitingPlanApproval {
+			writeError(w, http.StatusBadRequest, fmt.Sprintf("session is %s, not awaiting plan approval", session.State))
+			return
+		}
+		planID := ""
+		for _, activity := range s.activities[id] {
+			if activity.PlanGenerated != nil {
+				planID = activity.PlanGenerated.Plan.ID
+			}
+		}
+		s.addActivitiesLocked(id, jules.Activity{Originator: jules.ActivityOriginatorUser, PlanApproved: &jules.PlanApproved{PlanID: planID}})
+		session.State = jules.SessionStateInProgress
+		session.UpdateTime = s.now().UTC()
+		if s.Simulate {
+			s.completeLocked(session)
+		}
+		writeJSON(w, struct{}{})
+	case action == "sendMessage" && method == http.MethodPost:
+		var req jules.SendMessageRequest
+		if err := json.Unmarshal(body, &req); err != nil || strings.TrimSpace(req.Prompt) == "" {
+			writeError(w, http.StatusBadRequest, "prompt is required")
+			return
+		}
+		if session.State.IsTerminal() {
+			writeError(w, http.StatusBadRequest, fmt.Sprintf("session is %s", session.State))
+			return
+		}
+		s.addActivitiesLocked(id, jules.Activity{Originator: jules.ActivityOriginatorUser, UserMessaged: &jules.UserMessaged{UserMessage: req.Prompt}})
+		session.UpdateTime = s.now().UTC()
+		writeJSON(w, struct{}{})
+	case (action == "archive" || action == "unarchive") && method == http.MethodPost:
+		session.Archived = action == "archive"
+		session.UpdateTime = s.now().UTC()
+		writeJSON(w, session)
+	default:
+		writeError(w, http.StatusNotFound, "unknown session method "+action)
+	}
+}
+
+// completeLocked finishes a simulated session.
+func (s *Server) completeLocked(session *jules.Session) {
+	s.addActivitiesLocked(session.ID,
+		jules.Activity{Originator: jules.ActivityOriginatorAgent, ProgressUpdated: &jules.ProgressUpdated{Title: "Applied the plan"}},
+		jules.Activity{Originator: jules.ActivityOriginatorSystem, SessionCompleted: &jules.SessionCompleted{}},
+	)
+	session.State = jules.SessionStateCompleted
+	session.UpdateTime = s.now().UTC()
+}
+
+func (s *Server) activity(w http.ResponseWriter, r *http.Request, sessionID string, rest []string) {
+	if s.sessionLocked(sessionID) == nil {
+		writeError(w, http.StatusNotFound, "session not found: "+sessionID)
+		return
+	}
+	activities := s.activities[sessionID]
+	if len(rest) == 0 {
+		page, next := paginate(activities, r.URL.Query(), s.PageSize)
+		writeJSON(w, jules.ActivitiesResponse{Activities: page, NextPageToken: next})
+		return
+	}
+	for _, activity := range activities {
+		if len(rest) == 1 && activity.ID == rest[0] {
+			writeJSON(w, activity)
+			return
+		}
+	}
+	writeError(w, http.StatusNotFound, "activity not found: "+strings.Join(rest, "/"))
+}
+
+func (s *Server) source(w http.ResponseWriter, r *http.Request, id string) {
+	if id == "" {
+		page, next := paginate(s.sources, r.URL.Query(), s.PageSize)
+		writeJSON(w, jules.SourcesResponse{Sources: page, NextPageToken: next})
+		return
+	}
+	for _, source := range s.sources {
+		if source.ID == id {
+			writeJSON(w, source)
+			return
+		}
+	}
+	writeError(w, http.StatusNotFound, "source not found: "+id)
+}
+
+// paginate returns the page selected by the pageSize and pageToken query
+// parameters, and the token of the next page. Tokens are offsets.
+func paginate[T any](items []T, query url.Values, defaultSize int) ([]T, string) {
+	size, err := strconv.Atoi(query.Get("pageSize"))
+	if err != nil || size <= 0 {
+		size = defaultSize
+	}
+	if size <= 0 {
+		size = 50
+	}
+	start, _ := strconv.Atoi(query.Get("pageToken"))
+	start = min(max(start, 0), len(items))
+	end := min(start+size, len(items))
+	next := ""
+	if end < len(items) {
+		next = strconv.Itoa(end)
+	}
+	return slices.Clone(items[start:end]), next
+}
+
+func writeJSON(w http.ResponseWriter, value any) {
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(value)
+}
+
+func writeError(w http.ResponseWriter, status int, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_, _ = io.WriteString(w, ErrorBody(status, message))
+}
@@ -0,0 +1,219 @@
+package sessions
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/SamyRai/juleson/pkg/git"
+)
+
+// Attachment size limits, in bytes.
+const (
+	// DefaultMaxAttachmentBytes limits each attachment.
+	DefaultMaxAttachmentBytes = 32 << 10
+	// DefaultMaxAttachmentsBytes limits all attachments of a prompt together.
+	DefaultMaxAttachmentsBytes = 96 << 10
+	// minAttachmentBytes is the least budget worth spending on an
+	// attachment; below it the attachment is left out.
+	minAttachmentBytes = 256
+)
+
+// Attachment is file content or a diff embedded in a session prompt.
+type Attachment struct {
+	// Label names the attachment, such as a path or "git diff HEAD~3".
+	Label string
+	// Language is the code fence language, such as "go" or "diff".
+	Language string
+	Content  string
+	// Diff marks unified diffs, which are truncated by whole files.
+	Diff bool
+}
+
+// LoadFileAttachment reads a text file to attach.
+func LoadFileAttachment(path string) (Attachment, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return Attachment{}, fmt.Errorf("failed to inspect attachment: %w", err)
+	}
+	if info.IsDir() {
+		return Attachment{}, fmt.Errorf("attachment is a directory: %s", path)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Attachment{}, fmt.Errorf("failed to read attachment: %w", err)
+	}
+	if bytes.IndexByte(data, 0) >= 0 {
+		return Attachment{}, fmt.Errorf("attachment is a binary file: %s", path)
+	}
+	return Attachment{
+		Label:    filepath.ToSlash(path),
+		Language: strings.TrimPrefix(filepath.Ext(path), "."),
+		Content:  string(data),
+	}, nil
+}
+
+// LoadDiffAttachment diffs the working tree of the repository at dir
+// against base.
+func LoadDiffAttachment(ctx context.Context, dir, base string) (Attachment, error) {
+	repo, err := git.Open(ctx, dir)
+	if err != nil {
+		return Attachment{}, err
+	}
+	diff, _, err := repo.Diff(ctx, git.DiffOptions{Base: base})
+	if err != nil {
+		return Attachment{}, fmt.Errorf("failed to diff against %s: %w", base, err)
+	}
+	if strings.TrimSpace(diff) == "" {
+		return Attachment{}, fmt.Errorf("no changes since %s", base)
+	}
+	return Attachment{Label: "git diff " + base, Language: "diff", Content: diff, Diff: true}, nil
+}
+
+// ComposeOptions limits the attachments of a composed prompt. Zero values
+// use the defaults.
+type ComposeOptions struct {
+	MaxAttachmentBytes  int
+	MaxAttachmentsBytes int
+}
+
+// ComposePrompt appends attachments to prompt as fenced sections, in order.
+// Attachments over the per-attachment limit are truncated: files keep their
+// beginning and end, and diffs keep whole files. Once the total limit is
+// reached, later attachments are left out. The notes describe what was cut.
+func ComposePrompt(prompt string, attachments []Attachment, options ComposeOptions) (string, []string) {
+	if options.MaxAttachmentBytes <= 0 {
+		options.MaxAttachmentBytes = DefaultMaxAttachmentBytes
+	}
+	if options.MaxAttachmentsBytes <= 0 {
+		options.MaxAttachmentsBytes = DefaultMaxAttachmentsBytes
+	}
+	if len(attachments) == 0 {
+		return prompt, nil
+	}
+
+	var b strings.Builder
+	var notes []string
+	b.WriteString(strings.TrimRight(prompt, "\n"))
+	b.WriteString("\n\n## Attached Context\n")
+	remaining := options.MaxAttachmentsBytes
+	for _, attachment := range attachments {
+		limit := min(options.MaxAttachmentBytes, remaining)
+		if limit < minAttachmentBytes {
+			notes = append(notes, fmt.Sprintf("left out %s: the attachments exceed %d bytes", attachment.Label, options.MaxAttachmentsBytes))
+			continue
+		}
+		content, cut := attachment.Content, ""
+		if len(content) > limit {
+			if attachment.Diff {
+				content, cut = truncateDiff(content, limit)
+			} else {
+				content, cut = truncateText(content, limit)
+			}
+			notes = append(notes, fmt.Sprintf("truncated %s to %d bytes (%s)", attachment.Label, len(content), cut))
+		}
+		remaining -= len(content)
+
+		fence := codeFence(content)
+		fmt.Fprintf(&b, "\n### %s\n", attachment.Label)
+		if cut != "" {
+			fmt.Fprintf(&b, "_Truncated: %s._\n", cut)
+		}
+		fmt.Fprintf(&b, "%s%s\n%s", fence, attachment.Language, content)
+		if !strings.HasSuffix(content, "\n") {
+			b.WriteString("\n")
+		}
+		b.WriteString(fence + "\n")
+	}
+	return b.String(), notes
+}
+
+// truncateText keeps whole lines from the start and end of content, about
+// two thirds from the start, and marks the lines omitted between them.
+func truncateText(content string, limit int) (string, string) {
+	lines := strings.SplitAfter(content, "\n")
+	head, size := 0, 0
+	for head < len(lines) && size+len(lines[head]) <= limit*2/3 {
+		size += len(lines[head])
+		head++
+	}
+	tail := len(lines)
+	for tail > head && size+len(lines[tail-1]) <= limit {
+		size += len(lines[tail-1])
+		tail--
+	}
+	omitted := tail - head
+	if head == 0 && tail == len(lines) {
+		// A single line longer than the limit.
+		for limit > 0 && !utf8.RuneStart(content[limit]) {
+			limit--
+		}
+		return content[:limit] + "\n…\n", fmt.Sprintf("%d bytes omitted", len(content)-limit)
+	}
+	marker := fmt.Sprintf("\n… %d lines omitted …\n\n", omitted)
+	return strings.Join(lines[:head], "") + marker + strings.Join(lines[tail:], ""), fmt.Sprintf("%d lines omitted", omitted)
+}
+
+// truncateDiff keeps the per-file sections of a unified diff that fit in
+// limit and names the files left out. A first section larger than limit is
+// truncated as text.
+func truncateDiff(diff string, limit int) (string, string) {
+	sections := splitDiff(diff)
+	var kept strings.Builder
+	var omitted []string
+	for _, section := range sections {
+		if len(omitted) == 0 && kept.Len()+len(section) <= limit {
+			kept.WriteString(section)
+			continue
+		}
+		omitted = append(omitted, diffSectionPath(section))
+	}
+	if kept.Len() == 0 {
+		content, _ := truncateText(sections[0], limit)
+		kept.WriteString(content)
+		omitted = omitted[1:]
+		if len(omitted) == 0 {
+			return kept.String(), "the diff is shortened"
+		}
+	}
+	return kept.String(), fmt.Sprintf("diffs omitted for %s", strings.Join(omitted, ", "))
+}
+
+// splitDiff splits a unified diff before each "diff --git" header.
+func splitDiff(diff string) []string {
+	var sections []string
+	for {
+		next := strings.Index(diff[1:], "\ndiff --git ")
+		if next < 0 {
+			return append(sections, diff)
+		}
+		sections = append(sections, diff[:next+2])
+		diff = diff[next+2:]
+	}
+}
+
+func diffSectionPath(section string) string {
+	header, _, _ := strings.Cut(section, "\n")
+	if _, path, ok := strings.Cut(header, " b/"); ok {
+		return path
+	}
+	return strings.TrimPrefix(header, "diff --git ")
+}
+
+// codeFence returns a backtick fence longer than any backtick run in content.
+func codeFence(content string) string {
+	longest, run := 0, 0
+	for _, r := range content {
+		if r == '`' {
+			run++
+			longest = max(longest, run)
+		} else {
+			run = 0
+		}
+	}
+	return strings.Repeat("`", max(3, longest+1))
+}
@@ -0,0 +1,124 @@
+package sessions
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestComposePromptEmbedsAttachments(t *testing.T) {
+	prompt, notes := ComposePrompt("Fix the handler.\n", []Attachment{
+		{Label: "handler.go", Language: "go", Content: "package api\n"},
+		{Label: "README.md", Language: "md", Content: "Run ```make``` first"},
+	}, ComposeOptions{})
+
+	if len(notes) != 0 {
+		t.Errorf("notes = %q, want none", notes)
+	}
+	for _, want := range []string{
+		"Fix the handler.\n\n## Attached Context\n",
+		"### handler.go\n```go\npackage api\n```\n",
+		"### README.md\n````md\nRun ```make``` first\n````\n",
+	} {
+		if !strings.Contains(prompt, want) {
+			t.Errorf("prompt missing %q:\n%s", want, prompt)
+		}
+	}
+}
+
+func TestComposePromptTruncatesFiles(t *testing.T) {
+	var content strings.Builder
+	for i := 1; i <= 200; i++ {
+		fmt.Fprintf(&content, "line %03d\n", i)
+	}
+	prompt, notes := ComposePrompt("Task", []Attachment{{Label: "big.txt", Content: content.String()}}, ComposeOptions{MaxAttachmentBytes: 900})
+
+	if len(notes) != 1 || !strings.Contains(notes[0], "truncated big.txt") {
+		t.Fatalf("notes = %q", notes)
+	}
+	for _, want := range []string{"line 001\n", "line 200\n", "lines omitted"} {
+		if !strings.Contains(prompt, want) {
+			t.Errorf("prompt missing %q", want)
+		}
+	}
+	if strings.Contains(prompt, "line 100\n") {
+		t.Error("prompt kept the middle of the file")
+	}
+}
+
+func TestComposePromptTruncatesDiffsByFile(t *testing.T) {
+	section := func(path string, lines int) string {
+		return fmt.Sprintf("diff --git a/%s b/%s\n--- a/%s\n+++ b/%s\n@@ -1 +1 @@\n%s", path, path, path, path, strings.Repeat("+added line\n", lines))
+	}
+	diff := section("a.go", 10) + section("b.go", 100) + section("c.go", 5)
+
+	prompt, notes := ComposePrompt("Task", []Attachment{{Label: "git diff HEAD~1", Language: "diff", Content: diff, Diff: true}}, ComposeOptions{MaxAttachmentBytes: 600})
+
+	if !strings.Contains(prompt, "diff --git a/a.go b/a.go") || strings.Contains(prompt, "diff --git a/b.go") || strings.Contains(prompt, "diff --git a/c.go") {
+		t.Errorf("prompt should keep only a.go:\n%s", prompt)
+	}
+	if len(notes) != 1 || !strings.Contains(notes[0], "diffs omitted for b.go, c.go") {
+		t.Errorf("notes = %q", notes)
+	}
+}
+
+func TestComposePromptLeavesOutAttachmentsOverTotal(t *testing.T) {
+	content := strings.Repeat("x\n", 400)
+	_, notes := ComposePrompt("Task", []Attachment{
+		{Label: "one.txt", Content: content},
+		{Label: "two.txt", Content: content},
+	}, ComposeOptions{MaxAttachmentBytes: 1000, MaxAttachmentsBytes: 1000})
+
+	if len(notes) != 1 || !strings.HasPrefix(notes[0], "left out two.txt") {
+		t.Errorf("notes = %q", notes)
+	}
+}
+
+func TestLoadAttachments(t *testing.T) {
+	dir := t.TempDir()
+	run := func(args ...string) {
+		cmd := exec.Command("git", args...)
+		cmd.Dir = dir
+		cmd.Env = append(os.Environ(), "GIT_AUTHOR_NAME=t", "GIT_AUTHOR_EMAIL=t@example.com", "GIT_COMMITTER_NAME=t", "GIT_COMMITTER_EMAIL=t@example.com")
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v\n%s", args, err, out)
+		}
+	}
+	path := filepath.Join(dir, "main.go")
+	if err := os.WriteFile(path, []byte("package main\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	run("init", "-q")
+	run("add", ".")
+	run("commit", "-q", "-m", "initial")
+
+	file, err := LoadFileAttachment(path)
+	if err != nil || file.Language != "go" || file.Content != "package main\n" {
+		t.Fatalf("LoadFileAttachment = %+v, %v", file, err)
+	}
+	if _, err := LoadFileAttachment(dir); err == nil {
+		t.Error("LoadFileAttachment accepted a directory")
+	}
+	binary := filepath.Join(dir, "blob.bin")
+	if err := os.WriteFile(binary, []byte{0x7f, 0x00, 0x01}, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadFileAttachment(binary); err == nil {
+		t.Error("LoadFileAttachment accepted a binary file")
+	}
+
+	if _, err := LoadDiffAttachment(context.Background(), dir, "HEAD"); err == nil {
+		t.Error("LoadDiffAttachment succeeded without changes")
+	}
+	if err := os.WriteFile(path, []byte("package main\n\nfunc main() {}\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	diff, err := LoadDiffAttachment(context.Background(), dir, "HEAD")
+	if err != nil || !diff.Diff || !strings.Contains(diff.Content, "+func main() {}") {
+		t.Fatalf("LoadDiffAttachment = %+v, %v", diff, err)
+	}
+}
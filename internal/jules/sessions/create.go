@@ -4,6 +4,8 @@ import (
 	"errors"
 
 	"github.com/SamyRai/go-jules"
+	"github.com/SamyRai/juleson/internal/config"
+	"github.com/SamyRai/juleson/internal/policy"
 )
 
 var ErrStartingBranchRequiresSource = errors.New("starting branch requires source")
@@ -22,6 +24,27 @@ func NormalizeSourceID(sourceID string) string {
 	return jules.NormalizeSourceName(sourceID)
 }
 
+// ApplyDefaults fills the settings options leaves unset from the configured
+// session defaults for its repository. RequirePlanApproval comes from the
+// defaults only when planApprovalSet is false, that is when the caller did
+// not choose it.
+func ApplyDefaults(options *CreateSessionRequestOptions, sessions config.SessionsConfig, planApprovalSet bool) {
+	repo := ""
+	if !options.NoSource {
+		repo = policy.RepoFromSource(NormalizeSourceID(options.Source))
+	}
+	defaults := sessions.For(repo)
+	if options.StartingBranch == "" && options.Source != "" && !options.NoSource {
+		options.StartingBranch = defaults.StartingBranch
+	}
+	if options.AutomationMode == "" {
+		options.AutomationMode = defaults.AutomationMode
+	}
+	if !planApprovalSet && defaults.RequirePlanApproval != nil {
+		options.RequirePlanApproval = *defaults.RequirePlanApproval
+	}
+}
+
 func BuildCreateSessionRequest(options CreateSessionRequestOptions) (*jules.CreateSessionRequest, error) {
 	req := &jules.CreateSessionRequest{
 		Prompt:              options.Prompt,
@@ -5,6 +5,7 @@ import (
 	"testing"
 
 	"github.com/SamyRai/go-jules"
+	"github.com/SamyRai/juleson/internal/config"
 )
 
 func TestBuildCreateSessionRequest(t *testing.T) {
@@ -88,3 +89,34 @@ func TestNormalizeSourceID(t *testing.T) {
 		}
 	}
 }
+
+func TestApplyDefaults(t *testing.T) {
+	yes := true
+	sessions := config.SessionsConfig{
+		Defaults: config.SessionDefaultsConfig{StartingBranch: "main", AutomationMode: "AUTO_CREATE_PR"},
+		Repos: []config.SessionDefaultsConfig{
+			{Repo: "acme/widgets", StartingBranch: "develop", RequirePlanApproval: &yes},
+		},
+	}
+
+	options := CreateSessionRequestOptions{Source: "github/acme/widgets"}
+	ApplyDefaults(&options, sessions, false)
+	if options.StartingBranch != "develop" || !options.RequirePlanApproval || options.AutomationMode != "AUTO_CREATE_PR" {
+		t.Fatalf("defaults not applied: %+v", options)
+	}
+
+	options = CreateSessionRequestOptions{Source: "github/acme/widgets", StartingBranch: "hotfix", AutomationMode: "AUTOMATION_MODE_UNSPECIFIED"}
+	ApplyDefaults(&options, sessions, true)
+	if options.StartingBranch != "hotfix" || options.RequirePlanApproval || options.AutomationMode != "AUTOMATION_MODE_UNSPECIFIED" {
+		t.Fatalf("explicit options overridden: %+v", options)
+	}
+
+	options = CreateSessionRequestOptions{NoSource: true}
+	ApplyDefaults(&options, sessions, false)
+	if options.StartingBranch != "" {
+		t.Fatalf("repoless session got starting branch %q", options.StartingBranch)
+	}
+	if _, err := BuildCreateSessionRequest(options); err != nil {
+		t.Fatalf("BuildCreateSessionRequest returned error: %v", err)
+	}
+}
@@ -0,0 +1,99 @@
+package sessions
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/SamyRai/go-jules"
+)
+
+// Kinds of Jules API failure. Errors returned by this package match them
+// with errors.Is; Classify does the same for errors from jules.Client.
+var (
+	ErrNotFound      = errors.New("not found")
+	ErrUnauthorized  = errors.New("not authorized")
+	ErrQuotaExceeded = errors.New("quota exceeded")
+	ErrUnavailable   = errors.New("jules API unavailable")
+	// ErrPlanNotReady means the session has no plan awaiting approval.
+	ErrPlanNotReady = errors.New("plan not ready")
+	// ErrSessionTerminal means the session has completed or failed and no
+	// longer accepts work.
+	ErrSessionTerminal = errors.New("session has ended")
+)
+
+var errorKinds = []error{ErrNotFound, ErrUnauthorized, ErrQuotaExceeded, ErrUnavailable, ErrPlanNotReady, ErrSessionTerminal}
+
+// Error is a Jules API error of a known kind. errors.Is matches the kind,
+// and errors.As still reaches the underlying *jules.APIError.
+type Error struct {
+	Kind error
+	Err  error
+}
+
+func (e *Error) Error() string { return e.Err.Error() }
+
+func (e *Error) Unwrap() []error { return []error{e.Kind, e.Err} }
+
+// Classify wraps a Jules API error with its kind. Errors of no known kind,
+// and nil, are returned unchanged.
+func Classify(err error) error {
+	kind := Kind(err)
+	if kind == nil || errors.Is(err, kind) {
+		return err
+	}
+	return &Error{Kind: kind, Err: err}
+}
+
+// Kind returns the kind of err, one of the Err variables, or nil.
+func Kind(err error) error {
+	for _, kind := range errorKinds {
+		if errors.Is(err, kind) {
+			return kind
+		}
+	}
+	var apiErr *jules.APIError
+	if !errors.As(err, &apiErr) {
+		return nil
+	}
+	switch status := apiErr.StatusCode; {
+	case status == http.StatusNotFound:
+		return ErrNotFound
+	case status == http.StatusUnauthorized || status == http.StatusForbidden:
+		return ErrUnauthorized
+	case status == http.StatusTooManyRequests:
+		return ErrQuotaExceeded
+	case status >= 500:
+		return ErrUnavailable
+	case status == http.StatusBadRequest || status == http.StatusConflict || status == http.StatusPreconditionFailed:
+		precondition := status != http.StatusBadRequest || strings.Contains(apiErr.Body, "FAILED_PRECONDITION")
+		switch {
+		case precondition && strings.HasSuffix(apiErr.Path, ":approvePlan"):
+			return ErrPlanNotReady
+		case precondition && strings.HasSuffix(apiErr.Path, ":sendMessage"):
+			return ErrSessionTerminal
+		}
+	}
+	return nil
+}
+
+// Remediation returns advice for resolving err, or an empty string when
+// its kind is unknown.
+func Remediation(err error) string {
+	switch Kind(err) {
+	case ErrNotFound:
+		return "Check the ID with 'juleson sessions list' or 'juleson sources list'."
+	case ErrUnauthorized:
+		return "Check the Jules API key with 'juleson doctor', or run 'juleson auth login'."
+	case ErrQuotaExceeded:
+		return "Jules usage limits were reached; wait before retrying, or lower jules.rate_limit."
+	case ErrUnavailable:
+		return "The Jules API is failing or unreachable; retry later, and check 'juleson status'."
+	case ErrPlanNotReady:
+		return "Wait for Jules to post a plan with 'juleson sessions watch SESSION_ID', then approve it."
+	case ErrSessionTerminal:
+		return "The session has ended; start a new one with 'juleson sessions create'."
+	default:
+		return ""
+	}
+}
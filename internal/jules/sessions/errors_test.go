@@ -0,0 +1,70 @@
+package sessions
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/SamyRai/go-jules"
+)
+
+func TestClassify(t *testing.T) {
+	tests := []struct {
+		err  *jules.APIError
+		want error
+		name string
+	}{
+		{name: "not found", err: &jules.APIError{StatusCode: http.StatusNotFound}, want: ErrNotFound},
+		{name: "unauthorized", err: &jules.APIError{StatusCode: http.StatusUnauthorized}, want: ErrUnauthorized},
+		{name: "forbidden", err: &jules.APIError{StatusCode: http.StatusForbidden}, want: ErrUnauthorized},
+		{name: "rate limited", err: &jules.APIError{StatusCode: http.StatusTooManyRequests}, want: ErrQuotaExceeded},
+		{name: "server error", err: &jules.APIError{StatusCode: http.StatusBadGateway}, want: ErrUnavailable},
+		{
+			name: "plan not ready",
+			err:  &jules.APIError{StatusCode: http.StatusBadRequest, Path: "/v1alpha/sessions/s1:approvePlan", Body: `{"error":{"status":"FAILED_PRECONDITION"}}`},
+			want: ErrPlanNotReady,
+		},
+		{
+			name: "session ended",
+			err:  &jules.APIError{StatusCode: http.StatusConflict, Path: "/v1alpha/sessions/s1:sendMessage"},
+			want: ErrSessionTerminal,
+		},
+		{
+			name: "invalid argument",
+			err:  &jules.APIError{StatusCode: http.StatusBadRequest, Path: "/v1alpha/sessions/s1:approvePlan", Body: `{"error":{"status":"INVALID_ARGUMENT"}}`},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := Classify(fmt.Errorf("request failed: %w", tt.err))
+			if got := Kind(err); got != tt.want {
+				t.Fatalf("Kind = %v, want %v", got, tt.want)
+			}
+			if tt.want != nil && !errors.Is(err, tt.want) {
+				t.Errorf("errors.Is(%v, %v) = false", err, tt.want)
+			}
+			var apiErr *jules.APIError
+			if !errors.As(err, &apiErr) || apiErr != tt.err {
+				t.Error("classified error does not unwrap to the API error")
+			}
+			if (Remediation(err) != "") != (tt.want != nil) {
+				t.Errorf("Remediation = %q", Remediation(err))
+			}
+		})
+	}
+}
+
+func TestClassifyLeavesOtherErrorsUnchanged(t *testing.T) {
+	if Classify(nil) != nil {
+		t.Error("Classify(nil) != nil")
+	}
+	plain := errors.New("boom")
+	if Classify(plain) != plain {
+		t.Error("Classify wrapped an error of no known kind")
+	}
+	typed := fmt.Errorf("%w: session s1 failed", ErrSessionTerminal)
+	if Classify(typed) != typed {
+		t.Error("Classify rewrapped an error that already has a kind")
+	}
+}
@@ -0,0 +1,63 @@
+package sessions
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/SamyRai/go-jules"
+)
+
+// estimateHistorySize is how many recent sessions estimates are based on.
+const estimateHistorySize = 100
+
+// Estimate predicts how long new sessions will run from the durations of
+// recently completed ones. Jules does not report tokens or cost, so time is
+// the only measure.
+type Estimate struct {
+	// Sessions is the number of sessions that would be created.
+	Sessions int `json:"sessions"`
+	// Phases is the number of ordered phases each session is asked to do.
+	Phases int `json:"phases"`
+	// PerSession is the median duration of the completed sessions sampled.
+	PerSession time.Duration `json:"per_session"`
+	// Total is the session time for all sessions combined.
+	Total time.Duration `json:"total"`
+	// Samples is the number of completed sessions the estimate is based on;
+	// with none, PerSession and Total are zero.
+	Samples int `json:"samples"`
+}
+
+// EstimateSessions estimates the time for sessions new sessions of phases
+// phases each from history.
+func EstimateSessions(history []jules.Session, sessions, phases int) Estimate {
+	var durations []time.Duration
+	for _, session := range history {
+		if session.State != jules.SessionStateCompleted || session.CreateTime.IsZero() || !session.UpdateTime.After(session.CreateTime) {
+			continue
+		}
+		durations = append(durations, session.UpdateTime.Sub(session.CreateTime))
+	}
+	estimate := Estimate{Sessions: sessions, Phases: phases, Samples: len(durations)}
+	if len(durations) == 0 {
+		return estimate
+	}
+	slices.Sort(durations)
+	estimate.PerSession = durations[len(durations)/2]
+	if len(durations)%2 == 0 {
+		estimate.PerSession = (durations[len(durations)/2-1] + estimate.PerSession) / 2
+	}
+	estimate.Total = estimate.PerSession * time.Duration(sessions)
+	return estimate
+}
+
+// EstimateFromHistory estimates new sessions from the most recent sessions
+// in the account.
+func EstimateFromHistory(ctx context.Context, client *jules.Client, sessions, phases int) (Estimate, error) {
+	response, err := client.Sessions().List(ctx, &jules.ListSessionsOptions{PageSize: estimateHistorySize})
+	if err != nil {
+		return Estimate{}, fmt.Errorf("failed to list sessions for the estimate: %w", Classify(err))
+	}
+	return EstimateSessions(response.Sessions, sessions, phases), nil
+}
@@ -0,0 +1,36 @@
+package sessions
+
+import (
+	"testing"
+	"time"
+
+	"github.com/SamyRai/go-jules"
+)
+
+func TestEstimateSessionsUsesMedianOfCompleted(t *testing.T) {
+	start := time.Date(2026, 6, 1, 9, 0, 0, 0, time.UTC)
+	session := func(state jules.SessionState, d time.Duration) jules.Session {
+		return jules.Session{State: state, CreateTime: start, UpdateTime: start.Add(d)}
+	}
+	history := []jules.Session{
+		session(jules.SessionStateCompleted, 10*time.Minute),
+		session(jules.SessionStateCompleted, 40*time.Minute),
+		session(jules.SessionStateCompleted, 20*time.Minute),
+		session(jules.SessionStateCompleted, 30*time.Minute),
+		session(jules.SessionStateFailed, 5*time.Hour),
+		session(jules.SessionStateInProgress, time.Minute),
+		{State: jules.SessionStateCompleted},
+	}
+
+	estimate := EstimateSessions(history, 3, 2)
+	if estimate.Samples != 4 || estimate.PerSession != 25*time.Minute {
+		t.Fatalf("estimate = %+v, want median 25m of 4 samples", estimate)
+	}
+	if estimate.Total != 75*time.Minute || estimate.Sessions != 3 || estimate.Phases != 2 {
+		t.Errorf("estimate = %+v, want 75m for 3 sessions of 2 phases", estimate)
+	}
+
+	if empty := EstimateSessions(nil, 1, 1); empty.Samples != 0 || empty.Total != 0 {
+		t.Errorf("estimate without history = %+v", empty)
+	}
+}
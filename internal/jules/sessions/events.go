@@ -0,0 +1,73 @@
+package sessions
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/SamyRai/go-jules"
+	"github.com/SamyRai/juleson/internal/events"
+)
+
+// StateEvents returns the events for a session having reached its current
+// state: plan.awaiting_approval, session.completed, or session.failed, and
+// github.pr.created for each pull request a completed session opened. Other
+// states produce none.
+func StateEvents(session *jules.Session) []events.Event {
+	var eventType events.EventType
+	switch session.State {
+	case jules.SessionStateAwaitingPlanApproval:
+		eventType = events.EventPlanAwaitingApproval
+	case jules.SessionStateCompleted:
+		eventType = events.EventSessionCompleted
+	case jules.SessionStateFailed:
+		eventType = events.EventSessionFailed
+	default:
+		return nil
+	}
+	result := []events.Event{sessionEvent(eventType, session)}
+	if session.State != jules.SessionStateCompleted {
+		return result
+	}
+	for _, output := range session.Outputs {
+		if output.PullRequest == nil || output.PullRequest.URL == "" {
+			continue
+		}
+		repo, number := pullRequestRef(output.PullRequest.URL)
+		result = append(result, events.NewEvent(events.EventPRCreated, "session", events.GitHubEventData{
+			Repository: repo,
+			PRNumber:   number,
+			PRURL:      output.PullRequest.URL,
+			Action:     "opened",
+		}).WithTopic(events.TopicGitHub).WithMetadata("session_id", session.ID))
+	}
+	return result
+}
+
+// CreatedEvent returns the session.created event for a new session.
+func CreatedEvent(session *jules.Session) events.Event {
+	return sessionEvent(events.EventSessionCreated, session)
+}
+
+func sessionEvent(eventType events.EventType, session *jules.Session) events.Event {
+	data := events.SessionEventData{
+		SessionID: session.ID,
+		State:     string(session.State),
+		Title:     session.Title,
+		URL:       session.URL,
+	}
+	if session.SourceContext != nil {
+		data.SourceID = session.SourceContext.Source
+	}
+	return events.NewEvent(eventType, "session", data).WithTopic(events.TopicSession)
+}
+
+// pullRequestRef returns the owner/name repository and number of a pull
+// request URL such as https://github.com/owner/name/pull/12.
+func pullRequestRef(url string) (string, int) {
+	parts := strings.Split(strings.TrimSuffix(url, "/"), "/")
+	if len(parts) < 5 || parts[len(parts)-2] != "pull" {
+		return "", 0
+	}
+	number, _ := strconv.Atoi(parts[len(parts)-1])
+	return parts[len(parts)-4] + "/" + parts[len(parts)-3], number
+}
@@ -0,0 +1,43 @@
+package sessions
+
+import (
+	"testing"
+
+	"github.com/SamyRai/go-jules"
+	"github.com/SamyRai/juleson/internal/events"
+)
+
+func TestStateEvents(t *testing.T) {
+	session := &jules.Session{
+		ID:            "session-1",
+		Title:         "Fix tests",
+		State:         jules.SessionStateCompleted,
+		SourceContext: &jules.SourceContext{Source: "sources/github/acme/widgets"},
+		Outputs: []jules.Output{
+			{PullRequest: &jules.PullRequest{URL: "https://github.com/acme/widgets/pull/12"}},
+			{},
+		},
+	}
+
+	got := StateEvents(session)
+	if len(got) != 2 || got[0].Type != events.EventSessionCompleted || got[1].Type != events.EventPRCreated {
+		t.Fatalf("StateEvents() = %+v", got)
+	}
+	data, err := events.DecodeData[events.SessionEventData](got[0])
+	if err != nil || data.SessionID != "session-1" || data.SourceID != "sources/github/acme/widgets" || got[0].Topic != events.TopicSession {
+		t.Fatalf("session event = %+v, %v", data, err)
+	}
+	pr, err := events.DecodeData[events.GitHubEventData](got[1])
+	if err != nil || pr.Repository != "acme/widgets" || pr.PRNumber != 12 || got[1].Topic != events.TopicGitHub {
+		t.Fatalf("pr event = %+v, %v", pr, err)
+	}
+
+	session.State = jules.SessionStateAwaitingPlanApproval
+	if got := StateEvents(session); len(got) != 1 || got[0].Type != events.EventPlanAwaitingApproval {
+		t.Fatalf("StateEvents(awaiting) = %+v", got)
+	}
+	session.State = jules.SessionStateInProgress
+	if got := StateEvents(session); got != nil {
+		t.Fatalf("StateEvents(in progress) = %+v", got)
+	}
+}
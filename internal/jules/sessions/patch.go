@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 
+	"github.com/SamyRai/juleson/internal/guard"
 	"github.com/SamyRai/juleson/internal/jules/workspace"
 )
 
@@ -18,6 +19,15 @@ type PatchRequest struct {
 	CreateBackup      bool
 	HasArtifactIndex  bool
 	AllowBaseMismatch bool
+	AllowSecrets      bool
+	AllowUnsafe       bool
+	// Guard scans patches for dangerous instructions; see
+	// workspace.PatchApplicationOptions.
+	Guard *guard.Guard
+	// Only and SelectHunk narrow the patches to some files and hunks; see
+	// workspace.PatchApplicationOptions.
+	Only       []string
+	SelectHunk workspace.HunkSelector
 }
 
 type PatchPreparation struct {
@@ -40,6 +50,11 @@ func PreparePatchApplication(ctx context.Context, request PatchRequest) (*PatchP
 			ArtifactIndex:     request.ArtifactIndex,
 			HasArtifactIndex:  request.HasArtifactIndex,
 			AllowBaseMismatch: request.AllowBaseMismatch,
+			AllowSecrets:      request.AllowSecrets,
+			Guard:             request.Guard,
+			AllowUnsafe:       request.AllowUnsafe,
+			Only:              request.Only,
+			SelectHunk:        request.SelectHunk,
 		},
 	}
 
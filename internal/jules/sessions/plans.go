@@ -1,7 +1,10 @@
 package sessions
 
 import (
+	"context"
+	"fmt"
 	"sort"
+	"strings"
 	"time"
 
 	"github.com/SamyRai/go-jules"
@@ -24,6 +27,115 @@ type PlanSummary struct {
 	ActivityCreateTime time.Time         `json:"activity_create_time,omitempty"`
 	PlanCreateTime     time.Time         `json:"plan_create_time,omitempty"`
 	Approved           bool              `json:"approved"`
+	// Changes compares the plan with the one before it, if any.
+	Changes *PlanChanges `json:"changes,omitempty"`
+}
+
+// PlanChanges describes how a revised plan differs from the previous one.
+// Steps are matched by title.
+type PlanChanges struct {
+	Added   []string `json:"added,omitempty"`
+	Removed []string `json:"removed,omitempty"`
+	// Moved lists kept steps whose order relative to the other kept steps
+	// changed.
+	Moved []string `json:"moved,omitempty"`
+	// Edited lists kept steps whose description changed.
+	Edited []string `json:"edited,omitempty"`
+}
+
+// ComparePlans returns the changes from previous to next.
+func ComparePlans(previous, next PlanSummary) PlanChanges {
+	key := func(step PlanStepSummary) string { return strings.ToLower(strings.TrimSpace(step.Title)) }
+	before := make(map[string]PlanStepSummary, len(previous.Steps))
+	for _, step := range previous.Steps {
+		if _, ok := before[key(step)]; !ok {
+			before[key(step)] = step
+		}
+	}
+	unmatched := make(map[string]int, len(next.Steps))
+	for _, step := range next.Steps {
+		unmatched[key(step)]++
+	}
+
+	var changes PlanChanges
+	var keptOrder []string
+	kept := make(map[string]int, len(previous.Steps))
+	for _, step := range previous.Steps {
+		if unmatched[key(step)] > 0 {
+			unmatched[key(step)]--
+			kept[key(step)]++
+			keptOrder = append(keptOrder, key(step))
+		} else {
+			changes.Removed = append(changes.Removed, step.Title)
+		}
+	}
+	position := 0
+	for _, step := range next.Steps {
+		if kept[key(step)] == 0 {
+			changes.Added = append(changes.Added, step.Title)
+			continue
+		}
+		kept[key(step)]--
+		if keptOrder[position] != key(step) {
+			changes.Moved = append(changes.Moved, step.Title)
+		}
+		if before[key(step)].Description != step.Description {
+			changes.Edited = append(changes.Edited, step.Title)
+		}
+		position++
+	}
+	return changes
+}
+
+// IsEmpty reports whether the plans have the same steps in the same order.
+func (c PlanChanges) IsEmpty() bool {
+	return len(c.Added) == 0 && len(c.Removed) == 0 && len(c.Moved) == 0 && len(c.Edited) == 0
+}
+
+// String summarizes the changes, such as "1 added, 2 moved".
+func (c PlanChanges) String() string {
+	var parts []string
+	for _, part := range []struct {
+		steps []string
+		verb  string
+	}{{c.Added, "added"}, {c.Removed, "removed"}, {c.Moved, "moved"}, {c.Edited, "edited"}} {
+		if len(part.steps) > 0 {
+			parts = append(parts, fmt.Sprintf("%d %s", len(part.steps), part.verb))
+		}
+	}
+	if len(parts) == 0 {
+		return "no changes"
+	}
+	return strings.Join(parts, ", ")
+}
+
+// PlanFeedback asks Jules to replace a generated plan before starting work.
+type PlanFeedback struct {
+	Message string
+	// Step is the 1-based step the feedback is about, or 0 for the whole
+	// plan.
+	Step int
+	// Reject discards the plan instead of revising it.
+	Reject bool
+}
+
+// Prompt returns the message that sends the feedback on plan to Jules.
+func (f PlanFeedback) Prompt(plan PlanSummary) (string, error) {
+	message := strings.TrimSpace(f.Message)
+	if message == "" {
+		return "", fmt.Errorf("plan feedback is empty")
+	}
+	if f.Step < 0 || f.Step > len(plan.Steps) {
+		return "", fmt.Errorf("plan %s has no step %d; it has %d steps", plan.PlanID, f.Step, len(plan.Steps))
+	}
+	about := "the plan"
+	if f.Step > 0 {
+		about = fmt.Sprintf("step %d of the plan (%q)", f.Step, plan.Steps[f.Step-1].Title)
+	}
+	if f.Reject {
+		return fmt.Sprintf("I reject %s. Generate a new plan before starting work. Reason: %s", about, message), nil
+	}
+	return fmt.Sprintf("Revise %s before starting work: %s", about, message), nil
 }
 
 func ExtractPlanSummaries(activities []jules.Activity) []PlanSummary {
@@ -58,6 +170,10 @@ func ExtractPlanSummaries(activities []jules.Activity) []PlanSummary {
 		plans = append(plans, summary)
 	}
 	sortPlanSummaries(plans)
+	for i := 1; i < len(plans); i++ {
+		changes := ComparePlans(plans[i-1], plans[i])
+		plans[i].Changes = &changes
+	}
 	return plans
 }
 
@@ -71,6 +187,36 @@ func LatestPlanSummary(plans []PlanSummary) *PlanSummary {
 	return &latest
 }
 
+// WaitForPlan polls a session every interval until Jules generates a plan
+// other than previousPlanID, which may be empty, and returns the latest one.
+// It fails if the session ends, or stops to ask for feedback, first.
+func WaitForPlan(ctx context.Context, client *jules.Client, sessionID, previousPlanID string, interval time.Duration) (*PlanSummary, error) {
+	for {
+		activities, err := client.Activities().ListAll(ctx, sessionID, 100)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list activities: %w", Classify(err))
+		}
+		if plan := LatestPlanSummary(ExtractPlanSummaries(activities)); plan != nil && plan.PlanID != previousPlanID {
+			return plan, nil
+		}
+		session, err := client.Sessions().Get(ctx, sessionID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get session: %w", Classify(err))
+		}
+		if session.State.IsTerminal() {
+			return nil, fmt.Errorf("%w: session %s is %s without a plan", ErrSessionTerminal, sessionID, session.State)
+		}
+		if session.State == jules.SessionStateAwaitingUserFeedback {
+			return nil, fmt.Errorf("%w: session %s is %s", ErrPlanNotReady, sessionID, session.State)
+		}
+		select {
+		case <-ctx.Done():
+			return nil, fmt.Errorf("%w after waiting: %w", ErrPlanNotReady, ctx.Err())
+		case <-time.After(interval):
+		}
+	}
+}
+
 func approvedPlanActivities(activities []jules.Activity) map[string]string {
 	approved := make(map[string]string)
 	for i := range activities {
@@ -1,12 +1,17 @@
 package sessions
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
+	"net/http"
+	"reflect"
 	"strings"
 	"testing"
 	"time"
 
 	"github.com/SamyRai/go-jules"
+	"github.com/jarcoal/httpmock"
 )
 
 func TestExtractPlanSummariesIncludesFullSteps(t *testing.T) {
@@ -95,3 +100,113 @@ func TestPlanSummaryJSONFieldsAreStable(t *testing.T) {
 		}
 	}
 }
+
+func TestWaitForPlan(t *testing.T) {
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+
+	client := jules.NewClient("test-api-key", jules.WithBaseURL("https://jules.googleapis.com/v1alpha"), jules.WithRetryAttempts(0))
+	polls := 0
+	httpmock.RegisterResponder("GET", "=~^https://jules.googleapis.com/v1alpha/sessions/session-1/activities",
+		func(req *http.Request) (*http.Response, error) {
+			polls++
+			if polls == 1 {
+				return httpmock.NewJsonResponse(200, jules.ActivitiesResponse{})
+			}
+			return httpmock.NewJsonResponse(200, jules.ActivitiesResponse{Activities: []jules.Activity{{
+				ID:            "activity-plan",
+				PlanGenerated: &jules.PlanGenerated{Plan: jules.Plan{ID: "plan-1", Steps: []jules.Step{{Title: "Inspect"}}}},
+			}}})
+		})
+	httpmock.RegisterResponder("GET", "https://jules.googleapis.com/v1alpha/sessions/session-1",
+		httpmock.NewJsonResponderOrPanic(200, jules.Session{ID: "session-1", State: jules.SessionStatePlanning}))
+
+	plan, err := WaitForPlan(context.Background(), client, "session-1", "", time.Millisecond)
+	if err != nil {
+		t.Fatalf("WaitForPlan returned error: %v", err)
+	}
+	if plan.PlanID != "plan-1" || polls != 2 {
+		t.Fatalf("plan = %+v after %d polls", plan, polls)
+	}
+
+	// A revision waits for a plan other than the previous one.
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if _, err := WaitForPlan(ctx, client, "session-1", "plan-1", time.Millisecond); !errors.Is(err, ErrPlanNotReady) {
+		t.Fatalf("WaitForPlan error = %v, want ErrPlanNotReady", err)
+	}
+}
+
+func TestWaitForPlanStopsWhenSessionEnds(t *testing.T) {
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+
+	client := jules.NewClient("test-api-key", jules.WithBaseURL("https://jules.googleapis.com/v1alpha"), jules.WithRetryAttempts(0))
+	httpmock.RegisterResponder("GET", "=~^https://jules.googleapis.com/v1alpha/sessions/session-1/activities",
+		httpmock.NewJsonResponderOrPanic(200, jules.ActivitiesResponse{}))
+	httpmock.RegisterResponder("GET", "https://jules.googleapis.com/v1alpha/sessions/session-1",
+		httpmock.NewJsonResponderOrPanic(200, jules.Session{ID: "session-1", State: jules.SessionStateFailed}))
+
+	_, err := WaitForPlan(context.Background(), client, "session-1", "", time.Millisecond)
+	if !errors.Is(err, ErrSessionTerminal) || !strings.Contains(err.Error(), "without a plan") {
+		t.Fatalf("WaitForPlan error = %v", err)
+	}
+}
+
+func TestComparePlans(t *testing.T) {
+	steps := func(titles ...string) PlanSummary {
+		plan := PlanSummary{}
+		for _, title := range titles {
+			plan.Steps = append(plan.Steps, PlanStepSummary{Title: title, Description: "do " + title})
+		}
+		return plan
+	}
+	previous := steps("Inspect", "Patch", "Test", "Docs")
+	next := steps("Inspect", "Test", "Patch", "Benchmark")
+	next.Steps[0].Description = "read more files"
+
+	changes := ComparePlans(previous, next)
+	want := PlanChanges{
+		Added:   []string{"Benchmark"},
+		Removed: []string{"Docs"},
+		Moved:   []string{"Test", "Patch"},
+		Edited:  []string{"Inspect"},
+	}
+	if !reflect.DeepEqual(changes, want) {
+		t.Fatalf("changes = %+v, want %+v", changes, want)
+	}
+	if got := changes.String(); got != "1 added, 1 removed, 2 moved, 1 edited" {
+		t.Errorf("String() = %q", got)
+	}
+	if same := ComparePlans(previous, previous); !same.IsEmpty() {
+		t.Errorf("identical plans changed: %+v", same)
+	}
+	// Duplicate titles are matched one to one.
+	if dup := ComparePlans(steps("Test"), steps("Test", "Test")); !reflect.DeepEqual(dup.Added, []string{"Test"}) {
+		t.Errorf("duplicate step changes = %+v", dup)
+	}
+}
+
+func TestPlanFeedbackPrompt(t *testing.T) {
+	plan := PlanSummary{PlanID: "plan-1", Steps: []PlanStepSummary{{Title: "Inspect"}, {Title: "Patch"}}}
+	tests := []struct {
+		feedback PlanFeedback
+		want     string
+	}{
+		{PlanFeedback{Message: "add tests"}, "Revise the plan before starting work: add tests"},
+		{PlanFeedback{Message: "too broad", Step: 2}, `Revise step 2 of the plan ("Patch") before starting work: too broad`},
+		{PlanFeedback{Message: "wrong package", Reject: true}, "I reject the plan. Generate a new plan before starting work. Reason: wrong package"},
+	}
+	for _, tt := range tests {
+		got, err := tt.feedback.Prompt(plan)
+		if err != nil || got != tt.want {
+			t.Errorf("Prompt(%+v) = %q, %v; want %q", tt.feedback, got, err, tt.want)
+		}
+	}
+	if _, err := (PlanFeedback{Message: "x", Step: 3}).Prompt(plan); err == nil {
+		t.Error("Prompt accepted a step past the end of the plan")
+	}
+	if _, err := (PlanFeedback{Message: "  "}).Prompt(plan); err == nil {
+		t.Error("Prompt accepted empty feedback")
+	}
+}
@@ -7,7 +7,10 @@ import (
 	"strings"
 
 	"github.com/SamyRai/go-jules"
+	"github.com/SamyRai/juleson/internal/guard"
+	"github.com/SamyRai/juleson/internal/intelligence"
 	"github.com/SamyRai/juleson/internal/jules/workspace"
+	"github.com/SamyRai/juleson/pkg/sarif"
 )
 
 type ReviewRequest struct {
@@ -26,14 +29,16 @@ type ReviewNextAction struct {
 
 //nolint:govet // Field order follows the public JSON review contract.
 type PatchPreviewSummary struct {
-	Files                   []workspace.FileChange `json:"files,omitempty"`
-	SuggestedCommitMessages []string               `json:"suggested_commit_messages,omitempty"`
-	Warnings                []string               `json:"warnings,omitempty"`
-	BaseCommitMismatches    []string               `json:"base_commit_mismatches,omitempty"`
-	Error                   string                 `json:"error,omitempty"`
-	Summary                 string                 `json:"summary"`
-	TotalPatches            int                    `json:"total_patches"`
-	CanApply                bool                   `json:"can_apply"`
+	Files                   []workspace.FileChange       `json:"files,omitempty"`
+	SuggestedCommitMessages []string                     `json:"suggested_commit_messages,omitempty"`
+	Warnings                []string                     `json:"warnings,omitempty"`
+	BaseCommitMismatches    []string                     `json:"base_commit_mismatches,omitempty"`
+	SecretFindings          []intelligence.SecretFinding `json:"secret_findings,omitempty"`
+	GuardFindings           []guard.Finding              `json:"guard_findings,omitempty"`
+	Error                   string                       `json:"error,omitempty"`
+	Summary                 string                       `json:"summary"`
+	TotalPatches            int                          `json:"total_patches"`
+	CanApply                bool                         `json:"can_apply"`
 }
 
 type WorktreeReview struct {
@@ -52,6 +57,7 @@ type SessionReview struct {
 	Outputs                 []jules.Output               `json:"outputs"`
 	ArtifactManifests       []workspace.ArtifactManifest `json:"artifact_manifests"`
 	PatchPreview            PatchPreviewSummary          `json:"patch_preview"`
+	Impact                  *intelligence.Impact         `json:"impact,omitempty"`
 	Worktree                WorktreeReview               `json:"worktree"`
 	Warnings                []string                     `json:"warnings,omitempty"`
 	Blockers                []string                     `json:"blockers,omitempty"`
@@ -69,11 +75,11 @@ func BuildSessionReview(ctx context.Context, client *jules.Client, request Revie
 	}
 	session, err := client.Sessions().Get(ctx, request.SessionID)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get session: %w", err)
+		return nil, fmt.Errorf("failed to get session: %w", Classify(err))
 	}
 	activities, err := client.Activities().ListAll(ctx, request.SessionID, 100)
 	if err != nil {
-		return nil, fmt.Errorf("failed to list activities: %w", err)
+		return nil, fmt.Errorf("failed to list activities: %w", Classify(err))
 	}
 
 	plans := ExtractPlanSummaries(activities)
@@ -96,10 +102,22 @@ func BuildSessionReview(ctx context.Context, client *jules.Client, request Revie
 	}
 	changes, previewErr := workspace.PreviewSessionPatchesWithOptions(ctx, client, request.SessionID, patchOptions)
 	review.PatchPreview = buildPatchPreview(changes, previewErr)
+	if len(review.PatchPreview.Files) > 0 {
+		review.Impact = analyzeImpact(ctx, request.WorkingDir, review.PatchPreview.Files)
+		if review.Impact != nil {
+			review.VerificationSuggestions = append(scopedVerificationSuggestions(request.WorkingDir, review.Impact), review.VerificationSuggestions...)
+		}
+	}
 	review.Warnings = append(review.Warnings, review.PatchPreview.Warnings...)
 	if len(review.PatchPreview.BaseCommitMismatches) > 0 {
 		review.Blockers = append(review.Blockers, "patch base commit does not match target HEAD; inspect before applying")
 	}
+	if len(review.PatchPreview.SecretFindings) > 0 {
+		review.Blockers = append(review.Blockers, fmt.Sprintf("patch adds %d possible secret(s); remove them before applying or merging", len(review.PatchPreview.SecretFindings)))
+	}
+	if len(review.PatchPreview.GuardFindings) > 0 {
+		review.Blockers = append(review.Blockers, fmt.Sprintf("patch adds %d dangerous instruction(s); inspect them before applying or merging", len(review.PatchPreview.GuardFindings)))
+	}
 	if previewErr != nil {
 		review.Blockers = append(review.Blockers, "patch dry-run preview failed")
 	}
@@ -160,6 +178,8 @@ func buildPatchPreview(changes *workspace.SessionChanges, err error) PatchPrevie
 		preview.SuggestedCommitMessages = changes.SuggestedCommitMessages
 		preview.Warnings = changes.Warnings
 		preview.BaseCommitMismatches = changes.BaseCommitMismatches
+		preview.SecretFindings = changes.SecretFindings
+		preview.GuardFindings = changes.GuardFindings
 		preview.Summary, _, _ = SessionChangesSummary(changes)
 	}
 	if err != nil {
@@ -198,6 +218,31 @@ func inspectWorktree(ctx context.Context, workingDir string) WorktreeReview {
 	return review
 }
 
+// analyzeImpact maps the patched files onto the project dependency graph.
+// It is best effort: a project that cannot be walked gets no impact section.
+func analyzeImpact(ctx context.Context, workingDir string, files []workspace.FileChange) *intelligence.Impact {
+	cache := intelligence.OpenProjectCache(workingDir)
+	graph, err := intelligence.BuildProjectGraphCached(ctx, workingDir, cache)
+	if err != nil {
+		return nil
+	}
+	_ = cache.Save()
+	paths := make([]string, 0, len(files))
+	for _, file := range files {
+		paths = append(paths, file.Path)
+	}
+	return graph.ImpactAnalysis(paths)
+}
+
+// scopedVerificationSuggestions runs only the tests affected by the patch.
+func scopedVerificationSuggestions(workingDir string, impact *intelligence.Impact) []string {
+	var suggestions []string
+	for _, command := range impact.TestCommands() {
+		suggestions = append(suggestions, fmt.Sprintf("cd %s && %s", shellQuote(workingDir), command))
+	}
+	return suggestions
+}
+
 func verificationSuggestions(workingDir string) []string {
 	if workingDir == "" {
 		return []string{"juleson sessions apply <session-id> <project-path>", "go test ./..."}
@@ -231,3 +276,26 @@ func shellQuote(value string) string {
 	}
 	return "'" + strings.ReplaceAll(value, "'", "'\\''") + "'"
 }
+
+// AddSARIFResults adds the review's findings to a SARIF run: secrets added
+// by the patch at their file and line, and blockers and warnings without a
+// location.
+func (r *SessionReview) AddSARIFResults(b *sarif.Builder) {
+	intelligence.AddSecretResults(b, "", r.PatchPreview.SecretFindings)
+	b.AddRule(sarif.Rule{
+		ID:                   "review/blocker",
+		ShortDescription:     sarif.Message{Text: "Session patch is not ready to apply"},
+		DefaultConfiguration: &sarif.RuleDefaults{Level: sarif.LevelError},
+	})
+	b.AddRule(sarif.Rule{
+		ID:                   "review/warning",
+		ShortDescription:     sarif.Message{Text: "Session review warning"},
+		DefaultConfiguration: &sarif.RuleDefaults{Level: sarif.LevelWarning},
+	})
+	for _, blocker := range r.Blockers {
+		b.AddResult("review/blocker", "", fmt.Sprintf("Session %s: %s", r.SessionID, blocker), "", 0)
+	}
+	for _, warning := range r.Warnings {
+		b.AddResult("review/warning", "", fmt.Sprintf("Session %s: %s", r.SessionID, warning), "", 0)
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"os"
 	"os/exec"
 	"regexp"
+	"strings"
 	"testing"
 	"time"
 
@@ -140,6 +141,35 @@ func TestBuildSessionReviewBaseMismatchBlocksApply(t *testing.T) {
 	}
 }
 
+func TestBuildSessionReviewSecretBlocksApply(t *testing.T) {
+	tmpDir := cleanGitRepo(t)
+	base := gitHead(t, tmpDir)
+	// Assembled at runtime so this file does not trip secret scanners.
+	patch := strings.Replace(filePatch(base), "+two", "+key=AKIA"+"QYLPMN5HHHFPZAM2", 1)
+	client := mockedReviewClient(t, &jules.Session{ID: "session-1", State: jules.SessionStateCompleted}, []jules.Activity{
+		{
+			ID: "activity-patch",
+			Artifacts: []jules.Artifact{
+				{ChangeSet: &jules.ChangeSet{GitPatch: &jules.GitPatch{
+					BaseCommitID: base,
+					UnidiffPatch: patch,
+				}}},
+			},
+		},
+	})
+
+	review, err := BuildSessionReview(context.Background(), client, ReviewRequest{SessionID: "session-1", WorkingDir: tmpDir})
+	if err != nil {
+		t.Fatalf("BuildSessionReview returned error: %v", err)
+	}
+	if len(review.PatchPreview.SecretFindings) != 1 || !hasBlocker(review.Blockers, "possible secret") {
+		t.Fatalf("secret not blocked: preview=%+v blockers=%+v", review.PatchPreview, review.Blockers)
+	}
+	if hasAction(review.NextActions, "apply patches") {
+		t.Fatalf("unexpected apply action: %+v", review.NextActions)
+	}
+}
+
 func TestBuildSessionReviewDirtyWorktreeBlocksApply(t *testing.T) {
 	tmpDir := cleanGitRepo(t)
 	base := gitHead(t, tmpDir)
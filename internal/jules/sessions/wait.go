@@ -0,0 +1,40 @@
+package sessions
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/SamyRai/go-jules"
+)
+
+// WaitForSession polls a session every interval until it completes,
+// calling onState, which may be nil, whenever its state changes. It fails if
+// the session fails. Sessions waiting on plan approval or feedback are
+// waited on, since a user can respond to them in the meantime.
+func WaitForSession(ctx context.Context, client *jules.Client, sessionID string, interval time.Duration, onState func(jules.SessionState)) (*jules.Session, error) {
+	var last jules.SessionState
+	for {
+		session, err := client.Sessions().Get(ctx, sessionID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get session: %w", Classify(err))
+		}
+		if session.State != last {
+			last = session.State
+			if onState != nil {
+				onState(session.State)
+			}
+		}
+		switch session.State {
+		case jules.SessionStateCompleted:
+			return session, nil
+		case jules.SessionStateFailed:
+			return session, fmt.Errorf("%w: session %s failed", ErrSessionTerminal, sessionID)
+		}
+		select {
+		case <-ctx.Done():
+			return session, fmt.Errorf("waiting for session %s: %w", sessionID, ctx.Err())
+		case <-time.After(interval):
+		}
+	}
+}
@@ -0,0 +1,44 @@
+package sessions
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/SamyRai/go-jules"
+	"github.com/jarcoal/httpmock"
+)
+
+func TestWaitForSession(t *testing.T) {
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+
+	client := jules.NewClient("test-api-key", jules.WithBaseURL("https://jules.googleapis.com/v1alpha"), jules.WithRetryAttempts(0))
+	states := []jules.SessionState{jules.SessionStatePlanning, jules.SessionStatePlanning, jules.SessionStateInProgress, jules.SessionStateCompleted}
+	polls := 0
+	httpmock.RegisterResponder("GET", "https://jules.googleapis.com/v1alpha/sessions/session-1",
+		func(req *http.Request) (*http.Response, error) {
+			state := states[min(polls, len(states)-1)]
+			polls++
+			return httpmock.NewJsonResponse(200, jules.Session{ID: "session-1", State: state})
+		})
+	httpmock.RegisterResponder("GET", "https://jules.googleapis.com/v1alpha/sessions/session-2",
+		httpmock.NewJsonResponderOrPanic(200, jules.Session{ID: "session-2", State: jules.SessionStateFailed}))
+
+	var seen []jules.SessionState
+	session, err := WaitForSession(context.Background(), client, "session-1", time.Millisecond, func(state jules.SessionState) {
+		seen = append(seen, state)
+	})
+	if err != nil || session.State != jules.SessionStateCompleted {
+		t.Fatalf("WaitForSession = %+v, %v", session, err)
+	}
+	if len(seen) != 3 {
+		t.Errorf("state changes = %v, want planning, in progress, completed", seen)
+	}
+
+	if _, err := WaitForSession(context.Background(), client, "session-2", time.Millisecond, nil); !errors.Is(err, ErrSessionTerminal) {
+		t.Fatalf("WaitForSession error = %v, want ErrSessionTerminal", err)
+	}
+}
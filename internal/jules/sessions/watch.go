@@ -71,7 +71,7 @@ type WatchSnapshot struct {
 func CurrentWatchSnapshot(ctx context.Context, client *jules.Client, sessionID string, cursor time.Time, options CurrentWatchOptions) (WatchSnapshot, error) {
 	session, err := client.Sessions().Get(ctx, sessionID)
 	if err != nil {
-		return WatchSnapshot{}, fmt.Errorf("failed to get session: %w", err)
+		return WatchSnapshot{}, fmt.Errorf("failed to get session: %w", Classify(err))
 	}
 
 	snapshot := WatchSnapshot{
@@ -0,0 +1,462 @@
+package workspace
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/SamyRai/go-jules"
+	"github.com/SamyRai/juleson/internal/secrets"
+)
+
+// DefaultArtifactConcurrency is how many artifacts are written at once when
+// ArtifactDownloadOptions.Concurrency is not set.
+const DefaultArtifactConcurrency = 4
+
+// artifactChunkSize is how much of an artifact is written between progress
+// reports and cancellation checks.
+const artifactChunkSize = 1 << 20
+
+// PartialSuffix marks a file still being written. A later download of the
+// same artifact resumes from it.
+const PartialSuffix = ".part"
+
+// ArtifactProgress reports how far the download of one artifact file got.
+type ArtifactProgress struct {
+	File    string // File name in the destination directory
+	Written int64  // Bytes of the file on disk, including resumed bytes
+	Total   int64  // Size of the complete file
+	Resumed int64  // Bytes kept from an earlier partial download
+	SHA256  string // Checksum of the verified file, set once Done
+	Done    bool   // The file is complete and its checksum verified
+	Skipped bool   // The file already existed with the same content
+	Err     error  // Why the download failed
+}
+
+// artifactJob is one artifact to write, with the index its file is named
+// after.
+type artifactJob struct {
+	index    int
+	artifact jules.Artifact
+}
+
+// downloadArtifacts writes jobs with up to options.Concurrency workers. It
+// returns the files written in job order and the error of the first job
+// that failed, after which no new job starts.
+func downloadArtifacts(ctx context.Context, jobs []artifactJob, options *ArtifactDownloadOptions) ([]string, error) {
+	options, err := prepareDownload(options)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var progressMu sync.Mutex
+	report := func(progress ArtifactProgress) {
+		if options.Progress == nil {
+			return
+		}
+		progressMu.Lock()
+		defer progressMu.Unlock()
+		options.Progress(progress)
+	}
+
+	filenames := make([]string, len(jobs))
+	errs := make([]error, len(jobs))
+	semaphore := make(chan struct{}, options.Concurrency)
+	var wg sync.WaitGroup
+	for i, job := range jobs {
+		// Jobs start in order, so a failure leaves the earliest files
+		// written.
+		semaphore <- struct{}{}
+		if err := ctx.Err(); err != nil {
+			<-semaphore
+			errs[i] = err
+			continue
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer func() { <-semaphore }()
+			filenames[i], errs[i] = downloadSingleArtifact(ctx, job.index, job.artifact, options, report)
+			if errs[i] != nil {
+				cancel()
+			}
+		}()
+	}
+	wg.Wait()
+
+	var downloaded []string
+	for i, filename := range filenames {
+		if errs[i] != nil {
+			// Jobs cancelled because of an earlier failure report that
+			// failure rather than their own cancellation.
+			if errors.Is(errs[i], context.Canceled) {
+				if first := firstFailure(errs); first >= 0 {
+					i = first
+				}
+			}
+			return downloaded, fmt.Errorf("failed to download artifact %d: %w", jobs[i].index, errs[i])
+		}
+		downloaded = append(downloaded, filename)
+	}
+	return downloaded, nil
+}
+
+// firstFailure returns the index of the first error that is not a
+// cancellation, or -1.
+func firstFailure(errs []error) int {
+	for i, err := range errs {
+		if err != nil && !errors.Is(err, context.Canceled) {
+			return i
+		}
+	}
+	return -1
+}
+
+// prepareDownload returns a copy of options with defaults applied, and
+// creates the destination directory when asked to.
+func prepareDownload(options *ArtifactDownloadOptions) (*ArtifactDownloadOptions, error) {
+	prepared := ArtifactDownloadOptions{}
+	if options != nil {
+		prepared = *options
+	}
+	if prepared.DestinationDir == "" {
+		prepared.DestinationDir = "."
+	}
+	if prepared.Concurrency <= 0 {
+		prepared.Concurrency = DefaultArtifactConcurrency
+	}
+	if prepared.CreateDir {
+		if err := os.MkdirAll(prepared.DestinationDir, 0755); err != nil {
+			return nil, fmt.Errorf("failed to create destination directory: %w", err)
+		}
+	}
+	return &prepared, nil
+}
+
+func downloadSingleArtifact(ctx context.Context, artifactIndex int, artifact jules.Artifact, options *ArtifactDownloadOptions, report func(ArtifactProgress)) (string, error) {
+	filename := GenerateArtifactFilename(artifact, artifactIndex)
+
+	open := func() io.Reader {
+		reader, err := ArtifactReader(artifact)
+		if err != nil {
+			return errReader{err}
+		}
+		return reader
+	}
+	write := writeArtifactFile
+	if options.Cipher != nil {
+		filename += EncryptedSuffix
+		write = func(ctx context.Context, dir, filename string, open func() io.Reader, overwrite bool, report func(ArtifactProgress)) error {
+			return writeSealedArtifactFile(ctx, dir, filename, open, options.Cipher, overwrite, report)
+		}
+	}
+	if err := write(ctx, options.DestinationDir, filename, open, options.Overwrite, report); err != nil {
+		report(ArtifactProgress{File: filename, Err: err})
+		return "", err
+	}
+	return filename, nil
+}
+
+// writeSealedArtifactFile writes the content read from open to filename in
+// dir as a chunked stream encrypted with cipher. Like writeArtifactFile, it
+// streams the content a chunk at a time through a partial file, resumes one
+// left by an interrupted download after its last complete chunk, and only
+// renames it into place once it decrypts to the content. A file that
+// decrypts to the same content is kept.
+func writeSealedArtifactFile(ctx context.Context, dir, filename string, open func() io.Reader, cipher secrets.Sealer, overwrite bool, report func(ArtifactProgress)) error {
+	filePath := filepath.Join(dir, filename)
+	sum, total, err := readerSHA256(open(), -1)
+	if err != nil {
+		return fmt.Errorf("failed to read embedded artifact content: %w", err)
+	}
+	checksum := hex.EncodeToString(sum[:])
+
+	existing, err := sealedFileSHA256(filePath, cipher)
+	switch {
+	case err == nil && existing == sum:
+		report(ArtifactProgress{File: filename, Written: total, Total: total, Resumed: total, SHA256: checksum, Done: true, Skipped: true})
+		return nil
+	case errors.Is(err, fs.ErrNotExist):
+	case !overwrite:
+		return fmt.Errorf("file already exists: %s", filePath)
+	}
+
+	partPath := filePath + PartialSuffix
+	resume := sealedResumePoint(partPath, cipher, open, total)
+	var file *os.File
+	if resume.chunks > 0 {
+		file, err = os.OpenFile(partPath, os.O_WRONLY, 0600)
+		if err == nil {
+			err = file.Truncate(resume.size)
+		}
+		if err == nil {
+			_, err = file.Seek(resume.size, io.SeekStart)
+		}
+	} else {
+		file, err = os.OpenFile(partPath, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0600)
+	}
+	if err != nil {
+		if file != nil {
+			_ = file.Close()
+		}
+		return fmt.Errorf("failed to write file: %w", err)
+	}
+
+	sealed := secrets.ResumeChunkWriter(file, cipher, resume.chunks)
+	if resume.chunks == 0 {
+		sealed, err = secrets.NewChunkWriter(file, cipher)
+		if err != nil {
+			_ = file.Close()
+			return fmt.Errorf("failed to write file: %w", err)
+		}
+	}
+	content := open()
+	if _, err := io.CopyN(io.Discard, content, resume.plain); err != nil {
+		_ = file.Close()
+		return fmt.Errorf("failed to read embedded artifact content: %w", err)
+	}
+	progress := ArtifactProgress{File: filename, Written: resume.plain, Total: total, Resumed: resume.plain}
+	for progress.Written < total {
+		if err := ctx.Err(); err != nil {
+			_ = file.Close()
+			return err
+		}
+		n, err := io.CopyN(sealed, content, min(secrets.ChunkSize, total-progress.Written))
+		progress.Written += n
+		if err != nil {
+			_ = file.Close()
+			return fmt.Errorf("failed to write file: %w", err)
+		}
+		report(progress)
+	}
+	if !resume.final {
+		if err := sealed.Close(); err != nil {
+			_ = file.Close()
+			return fmt.Errorf("failed to write file: %w", err)
+		}
+	}
+	if err := file.Sync(); err != nil {
+		_ = file.Close()
+		return fmt.Errorf("failed to write file: %w", err)
+	}
+	if err := file.Close(); err != nil {
+		return fmt.Errorf("failed to write file: %w", err)
+	}
+
+	written, err := sealedFileSHA256(partPath, cipher)
+	if err != nil || written != sum {
+		_ = os.Remove(partPath)
+		return fmt.Errorf("failed to verify encrypted %s: it does not decrypt to the content", filename)
+	}
+	if err := os.Rename(partPath, filePath); err != nil {
+		return fmt.Errorf("failed to write file: %w", err)
+	}
+
+	progress.SHA256 = checksum
+	progress.Done = true
+	report(progress)
+	return nil
+}
+
+// sealedResume is where an encrypted partial file can be resumed.
+type sealedResume struct {
+	chunks uint64 // Complete chunks to keep
+	size   int64  // Bytes of the file they take
+	plain  int64  // Bytes of content they hold
+	final  bool   // The final chunk is among them
+}
+
+// sealedResumePoint returns how much of the content read from open the
+// encrypted partial file at path already holds in complete chunks, or the
+// zero sealedResume when it is missing or holds something else. Content of
+// total bytes ends with the final chunk.
+func sealedResumePoint(path string, cipher secrets.Sealer, open func() io.Reader, total int64) sealedResume {
+	file, err := os.Open(path)
+	if err != nil {
+		return sealedResume{}
+	}
+	defer func() { _ = file.Close() }()
+
+	chunks := secrets.NewChunkReader(bufio.NewReader(file), cipher)
+	hash := sha256.New()
+	var plain int64
+	for {
+		chunk, err := chunks.Next()
+		if err != nil {
+			break
+		}
+		hash.Write(chunk)
+		plain += int64(len(chunk))
+	}
+	if chunks.Chunks() == 0 || plain > total || (chunks.Final() && plain != total) {
+		return sealedResume{}
+	}
+	expected, n, err := readerSHA256(open(), plain)
+	if err != nil || n != plain || !bytes.Equal(hash.Sum(nil), expected[:]) {
+		return sealedResume{}
+	}
+	return sealedResume{chunks: chunks.Chunks(), size: chunks.Offset(), plain: plain, final: chunks.Final()}
+}
+
+// sealedFileSHA256 returns the checksum of the plaintext of the encrypted
+// file at path.
+func sealedFileSHA256(path string, cipher secrets.Sealer) ([sha256.Size]byte, error) {
+	var sum [sha256.Size]byte
+	file, err := os.Open(path)
+	if err != nil {
+		return sum, err
+	}
+	defer func() { _ = file.Close() }()
+
+	hash := sha256.New()
+	if err := secrets.OpenStream(cipher, file, hash); err != nil {
+		return sum, err
+	}
+	copy(sum[:], hash.Sum(nil))
+	return sum, nil
+}
+
+// writeArtifactFile writes the content read from open to filename in dir
+// through a partial file, resuming from one left by an interrupted download
+// when it holds the start of the content. The content is streamed, and read
+// again for each check, rather than held in memory. The file is only renamed
+// into place once its checksum matches the content. A file that already has
+// the same content is kept.
+func writeArtifactFile(ctx context.Context, dir, filename string, open func() io.Reader, overwrite bool, report func(ArtifactProgress)) error {
+	filePath := filepath.Join(dir, filename)
+	sum, total, err := readerSHA256(open(), -1)
+	if err != nil {
+		return fmt.Errorf("failed to read embedded artifact content: %w", err)
+	}
+	checksum := hex.EncodeToString(sum[:])
+
+	existing, err := fileSHA256(filePath, -1)
+	switch {
+	case err == nil && existing == sum:
+		report(ArtifactProgress{File: filename, Written: total, Total: total, Resumed: total, SHA256: checksum, Done: true, Skipped: true})
+		return nil
+	case err == nil && !overwrite:
+		return fmt.Errorf("file already exists: %s", filePath)
+	case err != nil && !errors.Is(err, fs.ErrNotExist):
+		return fmt.Errorf("failed to check existing file: %w", err)
+	}
+
+	partPath := filePath + PartialSuffix
+	offset := resumeOffset(partPath, open, total)
+	flags := os.O_CREATE | os.O_WRONLY | os.O_TRUNC
+	if offset > 0 {
+		flags = os.O_WRONLY | os.O_APPEND
+	}
+	file, err := os.OpenFile(partPath, flags, 0600)
+	if err != nil {
+		return fmt.Errorf("failed to write file: %w", err)
+	}
+	content := open()
+	if _, err := io.CopyN(io.Discard, content, offset); err != nil {
+		_ = file.Close()
+		return fmt.Errorf("failed to read embedded artifact content: %w", err)
+	}
+	progress := ArtifactProgress{File: filename, Written: offset, Total: total, Resumed: offset}
+	for progress.Written < total {
+		if err := ctx.Err(); err != nil {
+			_ = file.Close()
+			return err
+		}
+		n, err := io.CopyN(file, content, min(artifactChunkSize, total-progress.Written))
+		progress.Written += n
+		if err != nil {
+			_ = file.Close()
+			return fmt.Errorf("failed to write file: %w", err)
+		}
+		report(progress)
+	}
+	if err := file.Sync(); err != nil {
+		_ = file.Close()
+		return fmt.Errorf("failed to write file: %w", err)
+	}
+	if err := file.Close(); err != nil {
+		return fmt.Errorf("failed to write file: %w", err)
+	}
+
+	written, err := fileSHA256(partPath, -1)
+	if err != nil {
+		return fmt.Errorf("failed to verify file: %w", err)
+	}
+	if written != sum {
+		_ = os.Remove(partPath)
+		return fmt.Errorf("checksum mismatch for %s: wrote %s, want %s", filename, hex.EncodeToString(written[:]), checksum)
+	}
+	if err := os.Rename(partPath, filePath); err != nil {
+		return fmt.Errorf("failed to write file: %w", err)
+	}
+
+	progress.SHA256 = checksum
+	progress.Done = true
+	report(progress)
+	return nil
+}
+
+// resumeOffset returns how many bytes of the content read from open the
+// partial file at path already holds, or zero when it is missing or holds
+// something else.
+func resumeOffset(path string, open func() io.Reader, total int64) int64 {
+	info, err := os.Stat(path)
+	if err != nil || info.Size() == 0 || info.Size() > total {
+		return 0
+	}
+	partial, err := fileSHA256(path, info.Size())
+	if err != nil {
+		return 0
+	}
+	expected, _, err := readerSHA256(open(), info.Size())
+	if err != nil || partial != expected {
+		return 0
+	}
+	return info.Size()
+}
+
+// fileSHA256 returns the checksum of the first n bytes of the file at path,
+// or of the whole file when n is negative.
+func fileSHA256(path string, n int64) ([sha256.Size]byte, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return [sha256.Size]byte{}, err
+	}
+	defer func() { _ = file.Close() }()
+
+	sum, _, err := readerSHA256(file, n)
+	return sum, err
+}
+
+// readerSHA256 returns the checksum and length of the first n bytes read
+// from r, or of everything when n is negative.
+func readerSHA256(r io.Reader, n int64) ([sha256.Size]byte, int64, error) {
+	var sum [sha256.Size]byte
+	if n >= 0 {
+		r = io.LimitReader(r, n)
+	}
+	hash := sha256.New()
+	read, err := io.Copy(hash, r)
+	if err != nil {
+		return sum, read, err
+	}
+	copy(sum[:], hash.Sum(nil))
+	return sum, read, nil
+}
+
+// errReader fails every read with err.
+type errReader struct{ err error }
+
+func (r errReader) Read([]byte) (int, error) { return 0, r.err }
@@ -0,0 +1,188 @@
+package workspace
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/SamyRai/go-jules"
+	"github.com/SamyRai/juleson/internal/secrets"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriteArtifactFileResumesPartialDownload(t *testing.T) {
+	dir := t.TempDir()
+	content := bytes.Repeat([]byte("0123456789"), artifactChunkSize/4)
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "media_0.png.part"), content[:artifactChunkSize+3], 0600))
+
+	var reports []ArtifactProgress
+	err := writeArtifactFile(context.Background(), dir, "media_0.png", contentOf(content), false, func(progress ArtifactProgress) {
+		reports = append(reports, progress)
+	})
+	require.NoError(t, err)
+
+	written, err := os.ReadFile(filepath.Join(dir, "media_0.png"))
+	require.NoError(t, err)
+	assert.Equal(t, content, written)
+	assert.NoFileExists(t, filepath.Join(dir, "media_0.png.part"))
+
+	require.Len(t, reports, 3, "two chunks after the resumed bytes, then done")
+	last := reports[len(reports)-1]
+	sum := sha256.Sum256(content)
+	assert.True(t, last.Done)
+	assert.Equal(t, int64(artifactChunkSize+3), last.Resumed)
+	assert.Equal(t, int64(len(content)), last.Written)
+	assert.Equal(t, hex.EncodeToString(sum[:]), last.SHA256)
+}
+
+func TestWriteArtifactFileRestartsMismatchedPartial(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "out.txt.part"), []byte("stale"), 0600))
+
+	var last ArtifactProgress
+	err := writeArtifactFile(context.Background(), dir, "out.txt", contentOf([]byte("hello world")), false, func(progress ArtifactProgress) {
+		last = progress
+	})
+	require.NoError(t, err)
+	assert.Zero(t, last.Resumed)
+	written, err := os.ReadFile(filepath.Join(dir, "out.txt"))
+	require.NoError(t, err)
+	assert.Equal(t, "hello world", string(written))
+}
+
+func TestWriteArtifactFileExistingFile(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "out.txt")
+	require.NoError(t, os.WriteFile(path, []byte("hello"), 0600))
+
+	var last ArtifactProgress
+	report := func(progress ArtifactProgress) { last = progress }
+	require.NoError(t, writeArtifactFile(context.Background(), dir, "out.txt", contentOf([]byte("hello")), false, report))
+	assert.True(t, last.Skipped, "a file with the same content counts as downloaded")
+
+	err := writeArtifactFile(context.Background(), dir, "out.txt", contentOf([]byte("changed")), false, report)
+	assert.ErrorContains(t, err, "file already exists")
+
+	require.NoError(t, writeArtifactFile(context.Background(), dir, "out.txt", contentOf([]byte("changed")), true, report))
+	written, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, "changed", string(written))
+}
+
+func TestWriteSealedArtifactFile(t *testing.T) {
+	dir := t.TempDir()
+	cipher, err := secrets.NewCipher(make([]byte, 32))
+	require.NoError(t, err)
+	path := filepath.Join(dir, "patch_0.diff.enc")
+
+	var last ArtifactProgress
+	report := func(progress ArtifactProgress) { last = progress }
+	require.NoError(t, writeSealedArtifactFile(context.Background(), dir, "patch_0.diff.enc", contentOf([]byte("+API_KEY=secret")), cipher, false, report))
+	assert.True(t, last.Done)
+	sealed, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.NotContains(t, string(sealed), "API_KEY")
+	var plaintext bytes.Buffer
+	require.NoError(t, secrets.OpenStream(cipher, bytes.NewReader(sealed), &plaintext))
+	assert.Equal(t, "+API_KEY=secret", plaintext.String())
+
+	require.NoError(t, writeSealedArtifactFile(context.Background(), dir, "patch_0.diff.enc", contentOf([]byte("+API_KEY=secret")), cipher, false, report))
+	assert.True(t, last.Skipped, "a file decrypting to the same content counts as downloaded")
+	err = writeSealedArtifactFile(context.Background(), dir, "patch_0.diff.enc", contentOf([]byte("changed")), cipher, false, report)
+	assert.ErrorContains(t, err, "file already exists")
+}
+
+func TestWriteSealedArtifactFileResumesPartialDownload(t *testing.T) {
+	dir := t.TempDir()
+	cipher, err := secrets.NewCipher(make([]byte, 32))
+	require.NoError(t, err)
+	content := bytes.Repeat([]byte("0123456789"), secrets.ChunkSize/4)
+
+	// An interrupted download left one complete chunk and part of the next.
+	var partial bytes.Buffer
+	writer, err := secrets.NewChunkWriter(&partial, cipher)
+	require.NoError(t, err)
+	_, err = writer.Write(content[:secrets.ChunkSize+3])
+	require.NoError(t, err)
+	partial.WriteString("torn")
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "media_0.png.enc.part"), partial.Bytes(), 0600))
+
+	var last ArtifactProgress
+	err = writeSealedArtifactFile(context.Background(), dir, "media_0.png.enc", contentOf(content), cipher, false, func(progress ArtifactProgress) {
+		last = progress
+	})
+	require.NoError(t, err)
+	assert.True(t, last.Done)
+	assert.Equal(t, int64(secrets.ChunkSize), last.Resumed, "the complete chunk is kept")
+	assert.NoFileExists(t, filepath.Join(dir, "media_0.png.enc.part"))
+
+	file, err := os.Open(filepath.Join(dir, "media_0.png.enc"))
+	require.NoError(t, err)
+	defer file.Close()
+	var plaintext bytes.Buffer
+	require.NoError(t, secrets.OpenStream(cipher, file, &plaintext))
+	assert.Equal(t, content, plaintext.Bytes())
+}
+
+func TestArtifactReaderMatchesArtifactContent(t *testing.T) {
+	for _, artifact := range []Artifact{
+		{BashOutput: &BashOutput{Command: "go test ./...", Output: "ok", ExitCode: 1}},
+		{BashOutput: &BashOutput{Output: "done\n"}},
+		{ChangeSet: &ChangeSet{GitPatch: &GitPatch{UnidiffPatch: "--- a/x\n+++ b/x\n"}}},
+		{Media: &Media{MimeType: "image/png", Data: "aGVsbG8="}},
+	} {
+		want, err := jules.ArtifactContent(artifact)
+		require.NoError(t, err)
+		reader, err := ArtifactReader(artifact)
+		require.NoError(t, err)
+		got, err := io.ReadAll(reader)
+		require.NoError(t, err)
+		assert.Equal(t, string(want), string(got))
+	}
+
+	_, err := ArtifactReader(Artifact{})
+	assert.Error(t, err)
+}
+
+func TestDownloadArtifactsConcurrently(t *testing.T) {
+	dir := t.TempDir()
+	var jobs []artifactJob
+	for i := range 10 {
+		data := base64.StdEncoding.EncodeToString(bytes.Repeat([]byte{byte('a' + i)}, 100))
+		jobs = append(jobs, artifactJob{index: i, artifact: Artifact{Media: &Media{MimeType: "image/png", Data: data}}})
+	}
+	done := 0
+	files, err := downloadArtifacts(context.Background(), jobs, &ArtifactDownloadOptions{
+		DestinationDir: filepath.Join(dir, "out"),
+		CreateDir:      true,
+		Concurrency:    3,
+		Progress: func(progress ArtifactProgress) {
+			if progress.Done {
+				done++
+			}
+		},
+	})
+	require.NoError(t, err)
+	require.Len(t, files, 10)
+	assert.Equal(t, 10, done)
+	for i, file := range files {
+		assert.Equal(t, GenerateArtifactFilename(jobs[i].artifact, i), file, "files are returned in artifact order")
+	}
+
+	// An invalid artifact fails the download and is reported by index.
+	jobs[4].artifact.Media.Data = "not base64!"
+	files, err = downloadArtifacts(context.Background(), jobs, &ArtifactDownloadOptions{DestinationDir: filepath.Join(dir, "out"), Concurrency: 2})
+	assert.ErrorContains(t, err, "failed to download artifact 4")
+	assert.Len(t, files, 4, "the files before the failed artifact are returned")
+}
+
+func contentOf(content []byte) func() io.Reader {
+	return func() io.Reader { return bytes.NewReader(content) }
+}
@@ -2,21 +2,28 @@ package workspace
 
 import (
 	"context"
+	"encoding/base64"
 	"fmt"
-	"os"
-	"path/filepath"
+	"io"
 	"strings"
 
 	"github.com/SamyRai/go-jules"
+	"github.com/SamyRai/juleson/internal/secrets"
 )
 
 // ArtifactDownloadOptions represents options for downloading artifacts.
 type ArtifactDownloadOptions struct {
-	DestinationDir string // Directory to save artifacts (default: current directory)
-	Overwrite      bool   // Whether to overwrite existing files
-	CreateDir      bool   // Whether to create destination directory if it doesn't exist
+	DestinationDir string                 // Directory to save artifacts (default: current directory)
+	Overwrite      bool                   // Whether to overwrite existing files with different content
+	CreateDir      bool                   // Whether to create destination directory if it doesn't exist
+	Concurrency    int                    // Artifacts written at once (default: DefaultArtifactConcurrency)
+	Progress       func(ArtifactProgress) // Called as files are written; calls are serialized
+	Cipher         secrets.Sealer         // Encrypts each file, saved as its name plus EncryptedSuffix
 }
 
+// EncryptedSuffix is appended to the names of artifacts saved encrypted.
+const EncryptedSuffix = ".enc"
+
 // DownloadArtifactFromActivity downloads artifacts from a specific activity.
 func DownloadArtifactFromActivity(ctx context.Context, client *jules.Client, sessionID, activityID string, options *ArtifactDownloadOptions) ([]string, error) {
 	activity, err := client.Activities().Get(ctx, sessionID, activityID)
@@ -24,50 +31,39 @@ func DownloadArtifactFromActivity(ctx context.Context, client *jules.Client, ses
 		return nil, fmt.Errorf("failed to get activity: %w", err)
 	}
 
-	var downloadedFiles []string
+	jobs := make([]artifactJob, len(activity.Artifacts))
 	for i, artifact := range activity.Artifacts {
-		filename, err := downloadSingleArtifact(i, artifact, options)
-		if err != nil {
-			return downloadedFiles, fmt.Errorf("failed to download artifact %d: %w", i, err)
-		}
-		downloadedFiles = append(downloadedFiles, filename)
+		jobs[i] = artifactJob{index: i, artifact: artifact}
 	}
-
-	return downloadedFiles, nil
+	return downloadArtifacts(ctx, jobs, options)
 }
 
-func downloadSingleArtifact(artifactIndex int, artifact jules.Artifact, options *ArtifactDownloadOptions) (string, error) {
-	if options == nil {
-		options = &ArtifactDownloadOptions{}
-	}
-	if options.DestinationDir == "" {
-		options.DestinationDir = "."
-	}
-
-	if options.CreateDir {
-		if err := os.MkdirAll(options.DestinationDir, 0755); err != nil {
-			return "", fmt.Errorf("failed to create destination directory: %w", err)
+// ArtifactReader returns a reader of the content jules.ArtifactContent
+// returns, without copying it: patches and command output are read in
+// place and media is decoded from base64 as it is read.
+func ArtifactReader(artifact jules.Artifact) (io.Reader, error) {
+	switch {
+	case artifact.BashOutput != nil:
+		output := artifact.BashOutput
+		var readers []io.Reader
+		if output.Command != "" {
+			readers = append(readers, strings.NewReader("$ "+output.Command+"\n"))
 		}
-	}
-
-	filename := GenerateArtifactFilename(artifact, artifactIndex)
-	filePath := filepath.Join(options.DestinationDir, filename)
-	if !options.Overwrite {
-		if _, err := os.Stat(filePath); err == nil {
-			return "", fmt.Errorf("file already exists: %s", filePath)
+		if output.Output != "" {
+			readers = append(readers, strings.NewReader(output.Output))
+			if !strings.HasSuffix(output.Output, "\n") {
+				readers = append(readers, strings.NewReader("\n"))
+			}
 		}
+		readers = append(readers, strings.NewReader(fmt.Sprintf("exit code: %d\n", output.ExitCode)))
+		return io.MultiReader(readers...), nil
+	case artifact.ChangeSet != nil && artifact.ChangeSet.GitPatch != nil:
+		return strings.NewReader(artifact.ChangeSet.GitPatch.UnidiffPatch), nil
+	case artifact.Media != nil:
+		return base64.NewDecoder(base64.StdEncoding, strings.NewReader(artifact.Media.Data)), nil
+	default:
+		return nil, fmt.Errorf("artifact has no documented content")
 	}
-
-	content, err := jules.ArtifactContent(artifact)
-	if err != nil {
-		return "", fmt.Errorf("failed to read embedded artifact content: %w", err)
-	}
-
-	if err := os.WriteFile(filePath, content, 0600); err != nil {
-		return "", fmt.Errorf("failed to write file: %w", err)
-	}
-
-	return filename, nil
 }
 
 // GenerateArtifactFilename generates a filename for an artifact based on its type.
@@ -105,26 +101,22 @@ func extensionFromMimeType(mimeType string) string {
 	}
 }
 
-// DownloadAllSessionArtifacts downloads all artifacts from all activities in a session.
+// DownloadAllSessionArtifacts downloads all artifacts from all activities in a
+// session, several at a time. Artifacts are numbered across the session so
+// files from different activities do not collide.
 func DownloadAllSessionArtifacts(ctx context.Context, client *jules.Client, sessionID string, options *ArtifactDownloadOptions) ([]string, error) {
-	response, err := client.Activities().List(ctx, sessionID, &jules.ListActivitiesOptions{PageSize: 100})
+	activities, err := client.Activities().ListAll(ctx, sessionID, 100)
 	if err != nil {
 		return nil, fmt.Errorf("failed to list activities: %w", err)
 	}
 
-	var allDownloadedFiles []string
-	for _, activity := range response.Activities {
-		if len(activity.Artifacts) == 0 {
-			continue
-		}
-		files, err := DownloadArtifactFromActivity(ctx, client, sessionID, activity.ID, options)
-		if err != nil {
-			return allDownloadedFiles, fmt.Errorf("failed to download artifacts for activity %s: %w", activity.ID, err)
+	var jobs []artifactJob
+	for _, activity := range activities {
+		for _, artifact := range activity.Artifacts {
+			jobs = append(jobs, artifactJob{index: len(jobs), artifact: artifact})
 		}
-		allDownloadedFiles = append(allDownloadedFiles, files...)
 	}
-
-	return allDownloadedFiles, nil
+	return downloadArtifacts(ctx, jobs, options)
 }
 
 // DownloadAllArtifacts downloads all artifacts from a session.
@@ -116,6 +116,44 @@ func (suite *ArtifactsTestSuite) TestDownloadAllSessionArtifacts() {
 	assert.FileExists(suite.T(), filePath)
 }
 
+func (suite *ArtifactsTestSuite) TestDownloadAllSessionArtifactsNumbersAcrossActivities() {
+	tempDir := suite.T().TempDir()
+
+	pages := map[string]ActivitiesResponse{
+		"": {
+			Activities:    []Activity{{ID: "activity-1", Artifacts: []Artifact{{BashOutput: &BashOutput{Command: "make", Output: "ok"}}}}},
+			NextPageToken: "page-2",
+		},
+		"page-2": {
+			Activities: []Activity{{ID: "activity-2", Artifacts: []Artifact{
+				{BashOutput: &BashOutput{Command: "make test", Output: "PASS"}},
+				{Media: &Media{MimeType: "image/png", Data: "aGVsbG8="}},
+			}}},
+		},
+	}
+	httpmock.RegisterResponder("GET", "https://jules.googleapis.com/v1alpha/sessions/session-1/activities",
+		func(req *http.Request) (*http.Response, error) {
+			return httpmock.NewJsonResponse(200, pages[req.URL.Query().Get("pageToken")])
+		})
+
+	var done []string
+	files, err := DownloadAllSessionArtifacts(context.Background(), suite.client, "session-1", &ArtifactDownloadOptions{
+		DestinationDir: tempDir,
+		Progress: func(progress ArtifactProgress) {
+			if progress.Done {
+				done = append(done, progress.File)
+			}
+		},
+	})
+
+	require.NoError(suite.T(), err)
+	assert.Equal(suite.T(), []string{"bash_output_0.txt", "bash_output_1.txt", "media_2.png"}, files)
+	assert.ElementsMatch(suite.T(), files, done)
+	content, err := os.ReadFile(filepath.Join(tempDir, "bash_output_1.txt"))
+	require.NoError(suite.T(), err)
+	assert.Contains(suite.T(), string(content), "PASS")
+}
+
 func (suite *ArtifactsTestSuite) TestDownloadMediaFromEmbeddedBase64() {
 	tempDir, err := os.MkdirTemp("", "jules_test_*")
 	require.NoError(suite.T(), err)
@@ -1,17 +1,62 @@
 package workspace
 
 import (
+	"fmt"
 	"strconv"
 	"strings"
+
+	"github.com/SamyRai/juleson/internal/guard"
+	"github.com/SamyRai/juleson/internal/intelligence"
+	"github.com/bluekeyes/go-gitdiff/gitdiff"
+)
+
+// File change statuses.
+const (
+	FileModified = "modified"
+	FileAdded    = "added"
+	FileDeleted  = "deleted"
+	FileRenamed  = "renamed"
+	FileCopied   = "copied"
 )
 
 // FileChange represents changes to a single file.
 type FileChange struct {
-	Path         string `json:"path"`
+	Path string `json:"path"`
+	// OldPath is the source of a rename or copy.
+	OldPath string `json:"oldPath,omitempty"`
+	Status  string `json:"status,omitempty"`
+	// OldMode and NewMode are set when the file mode changes, such as
+	// 100644 to 100755.
+	OldMode      string `json:"oldMode,omitempty"`
+	NewMode      string `json:"newMode,omitempty"`
+	Binary       bool   `json:"binary,omitempty"`
 	LinesAdded   int    `json:"linesAdded"`
 	LinesRemoved int    `json:"linesRemoved"`
 }
 
+// Label describes the file for summaries, such as "old.go → new.go
+// (renamed)" or "run.sh (mode 100644 → 100755)".
+func (f FileChange) Label() string {
+	label := f.Path
+	if f.OldPath != "" && f.OldPath != f.Path {
+		label = f.OldPath + " → " + f.Path
+	}
+	var notes []string
+	if f.Status != "" && f.Status != FileModified {
+		notes = append(notes, f.Status)
+	}
+	if f.OldMode != "" && f.NewMode != "" && f.OldMode != f.NewMode {
+		notes = append(notes, "mode "+f.OldMode+" → "+f.NewMode)
+	}
+	if f.Binary {
+		notes = append(notes, "binary")
+	}
+	if len(notes) > 0 {
+		label += " (" + strings.Join(notes, ", ") + ")"
+	}
+	return label
+}
+
 // SessionChanges represents a summary of changes in a session.
 type SessionChanges struct {
 	SessionID               string       `json:"sessionId"`
@@ -19,11 +64,52 @@ type SessionChanges struct {
 	SuggestedCommitMessages []string     `json:"suggestedCommitMessages,omitempty"`
 	Warnings                []string     `json:"warnings,omitempty"`
 	BaseCommitMismatches    []string     `json:"baseCommitMismatches,omitempty"`
-	TotalPatches            int          `json:"totalPatches"`
+	// SecretFindings are likely credentials added by the patches.
+	SecretFindings []intelligence.SecretFinding `json:"secretFindings,omitempty"`
+	// GuardFindings are dangerous instructions added by the patches.
+	GuardFindings []guard.Finding `json:"guardFindings,omitempty"`
+	TotalPatches  int             `json:"totalPatches"`
 }
 
-// parsePatchFiles extracts file changes from a git patch.
+// parsePatchFiles extracts file changes from a git patch, including renames,
+// copies, mode changes, and binary files. Patches go-gitdiff cannot parse
+// fall back to counting lines per "diff --git" section.
 func parsePatchFiles(patch string) []FileChange {
+	files, _, err := gitdiff.Parse(strings.NewReader(patch))
+	if err != nil || len(files) == 0 {
+		return scanPatchFiles(patch)
+	}
+	changes := make([]FileChange, 0, len(files))
+	for _, file := range files {
+		change := FileChange{Path: file.NewName, Status: FileModified, Binary: file.IsBinary}
+		switch {
+		case file.IsNew:
+			change.Status = FileAdded
+		case file.IsDelete:
+			change.Path = file.OldName
+			change.Status = FileDeleted
+		case file.IsRename:
+			change.OldPath = file.OldName
+			change.Status = FileRenamed
+		case file.IsCopy:
+			change.OldPath = file.OldName
+			change.Status = FileCopied
+		}
+		if file.OldMode != 0 && file.NewMode != 0 && file.OldMode != file.NewMode {
+			change.OldMode = fmt.Sprintf("%o", file.OldMode)
+			change.NewMode = fmt.Sprintf("%o", file.NewMode)
+		}
+		for _, fragment := range file.TextFragments {
+			change.LinesAdded += int(fragment.LinesAdded)
+			change.LinesRemoved += int(fragment.LinesDeleted)
+		}
+		changes = append(changes, change)
+	}
+	return changes
+}
+
+// scanPatchFiles counts added and removed lines per "diff --git" section.
+func scanPatchFiles(patch string) []FileChange {
 	var changes []FileChange
 	lines := strings.Split(patch, "\n")
 
@@ -0,0 +1,235 @@
+package workspace
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"strings"
+
+	"github.com/SamyRai/go-jules"
+	"github.com/bluekeyes/go-gitdiff/gitdiff"
+)
+
+// PatchHunk is one hunk of a session patch, offered to a HunkSelector.
+// Binary files, and files whose only change is a mode change or rename, are
+// a single hunk with an empty Header.
+type PatchHunk struct {
+	ActivityID    string `json:"activityId"`
+	ArtifactIndex int    `json:"artifactIndex"`
+	File          string `json:"file"`
+	// Index is the position of the hunk within its file, from 0.
+	Index int `json:"index"`
+	// Count is the number of hunks in the file.
+	Count        int    `json:"count"`
+	Header       string `json:"header,omitempty"`
+	Text         string `json:"text,omitempty"`
+	LinesAdded   int    `json:"linesAdded"`
+	LinesRemoved int    `json:"linesRemoved"`
+}
+
+// Key identifies the hunk within a session.
+func (h PatchHunk) Key() string {
+	return fmt.Sprintf("%s/%d/%s#%d", h.ActivityID, h.ArtifactIndex, h.File, h.Index)
+}
+
+// HunkSelector reports whether a hunk should be applied.
+type HunkSelector func(PatchHunk) bool
+
+// SelectHunkKeys returns a selector that keeps the hunks with the given keys.
+func SelectHunkKeys(keys []string) HunkSelector {
+	selected := make(map[string]bool, len(keys))
+	for _, key := range keys {
+		selected[key] = true
+	}
+	return func(hunk PatchHunk) bool { return selected[hunk.Key()] }
+}
+
+// MatchPath reports whether a repository-relative path matches one of
+// patterns. A pattern matches the whole path or its base name, as in
+// path.Match, and a pattern ending in /, /**, or /... matches everything
+// under that directory.
+func MatchPath(patterns []string, name string) bool {
+	for _, pattern := range patterns {
+		for _, suffix := range []string{"/**", "/..."} {
+			if dir, ok := strings.CutSuffix(pattern, suffix); ok {
+				pattern = dir + "/"
+			}
+		}
+		if strings.HasSuffix(pattern, "/") {
+			if strings.HasPrefix(name, pattern) {
+				return true
+			}
+			continue
+		}
+		if ok, err := path.Match(pattern, name); err == nil && ok {
+			return true
+		}
+		if ok, err := path.Match(pattern, path.Base(name)); err == nil && ok {
+			return true
+		}
+	}
+	return false
+}
+
+// hasSelection reports whether options narrow the patches to some files or
+// hunks.
+func (o *PatchApplicationOptions) hasSelection() bool {
+	return len(o.Only) > 0 || o.SelectHunk != nil
+}
+
+// SelectPatch returns the part of a patch that changes files matching only
+// (all files when empty) and whose hunks select keeps (all hunks when nil).
+// base fills in the activity and artifact of the hunks offered to select.
+// The result is empty when nothing is selected.
+func SelectPatch(patch string, only []string, selectHunk HunkSelector, base PatchHunk) (string, error) {
+	files, err := parseSelectablePatch(patch)
+	if err != nil {
+		return "", err
+	}
+	var b strings.Builder
+	for _, file := range files {
+		name := patchFileName(file)
+		if len(only) > 0 && !MatchPath(only, name) {
+			continue
+		}
+		if selectHunk == nil {
+			b.WriteString(file.String())
+			continue
+		}
+
+		hunks := fileHunks(file, base)
+		if len(file.TextFragments) == 0 {
+			if selectHunk(hunks[0]) {
+				b.WriteString(file.String())
+			}
+			continue
+		}
+		var kept []*gitdiff.TextFragment
+		// Dropped hunks shift the new line numbers of the hunks after them.
+		var shift int64
+		for i, fragment := range file.TextFragments {
+			if !selectHunk(hunks[i]) {
+				shift += fragment.LinesAdded - fragment.LinesDeleted
+				continue
+			}
+			selected := *fragment
+			selected.NewPosition -= shift
+			kept = append(kept, &selected)
+		}
+		if len(kept) == 0 {
+			continue
+		}
+		// A new or deleted file is created or removed whole.
+		if (file.IsNew || file.IsDelete) && len(kept) < len(file.TextFragments) {
+			continue
+		}
+		selected := *file
+		selected.TextFragments = kept
+		b.WriteString(selected.String())
+	}
+	return b.String(), nil
+}
+
+// PatchHunks lists the hunks of a patch in files matching only.
+func PatchHunks(patch string, only []string, base PatchHunk) ([]PatchHunk, error) {
+	files, err := parseSelectablePatch(patch)
+	if err != nil {
+		return nil, err
+	}
+	var hunks []PatchHunk
+	for _, file := range files {
+		if len(only) > 0 && !MatchPath(only, patchFileName(file)) {
+			continue
+		}
+		hunks = append(hunks, fileHunks(file, base)...)
+	}
+	return hunks, nil
+}
+
+// ListSessionHunks lists the hunks of a session's patches within the
+// activity, artifact, and Only scope of options, for choosing a SelectHunk.
+func ListSessionHunks(ctx context.Context, client *jules.Client, sessionID string, options *PatchApplicationOptions) ([]PatchHunk, error) {
+	if options == nil {
+		options = &PatchApplicationOptions{}
+	}
+	activities, err := sessionActivities(ctx, client, sessionID, options)
+	if err != nil {
+		return nil, err
+	}
+
+	var hunks []PatchHunk
+	for _, activity := range activities {
+		for i, artifact := range activity.Artifacts {
+			if options.HasArtifactIndex && i != options.ArtifactIndex {
+				continue
+			}
+			if artifact.ChangeSet == nil || artifact.ChangeSet.GitPatch == nil || artifact.ChangeSet.GitPatch.UnidiffPatch == "" {
+				continue
+			}
+			artifactHunks, err := PatchHunks(artifact.ChangeSet.GitPatch.UnidiffPatch, options.Only, PatchHunk{ActivityID: activity.ID, ArtifactIndex: i})
+			if err != nil {
+				return nil, fmt.Errorf("activity %s artifact %d: %w", activity.ID, i, err)
+			}
+			hunks = append(hunks, artifactHunks...)
+		}
+	}
+	return hunks, nil
+}
+
+func fileHunks(file *gitdiff.File, base PatchHunk) []PatchHunk {
+	name := patchFileName(file)
+	if len(file.TextFragments) == 0 {
+		hunk := base
+		hunk.File, hunk.Index, hunk.Count = name, 0, 1
+		return []PatchHunk{hunk}
+	}
+	hunks := make([]PatchHunk, 0, len(file.TextFragments))
+	for i, fragment := range file.TextFragments {
+		hunk := base
+		hunk.File = name
+		hunk.Index = i
+		hunk.Count = len(file.TextFragments)
+		hunk.Header = fmt.Sprintf("@@ -%d,%d +%d,%d @@", fragment.OldPosition, fragment.OldLines, fragment.NewPosition, fragment.NewLines)
+		if fragment.Comment != "" {
+			hunk.Header += " " + fragment.Comment
+		}
+		var text strings.Builder
+		for _, line := range fragment.Lines {
+			text.WriteString(line.String())
+		}
+		hunk.Text = text.String()
+		hunk.LinesAdded = int(fragment.LinesAdded)
+		hunk.LinesRemoved = int(fragment.LinesDeleted)
+		hunks = append(hunks, hunk)
+	}
+	return hunks
+}
+
+// parseSelectablePatch parses a patch for selection. Patches without a git
+// header keep their a/ and b/ prefixes in file names, which are dropped so
+// the rewritten patch applies with the usual strip of one component.
+func parseSelectablePatch(patch string) ([]*gitdiff.File, error) {
+	files, _, err := gitdiff.Parse(strings.NewReader(patch))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse patch: %w", err)
+	}
+	if !strings.HasPrefix(patch, "diff --git ") && !strings.Contains(patch, "\ndiff --git ") {
+		for _, file := range files {
+			for _, name := range []*string{&file.OldName, &file.NewName} {
+				if trimmed, ok := strings.CutPrefix(*name, "a/"); ok {
+					*name = trimmed
+				} else if trimmed, ok := strings.CutPrefix(*name, "b/"); ok {
+					*name = trimmed
+				}
+			}
+		}
+	}
+	return files, nil
+}
+
+func patchFileName(file *gitdiff.File) string {
+	if file.IsDelete {
+		return file.OldName
+	}
+	return file.NewName
+}
@@ -0,0 +1,99 @@
+package workspace
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const selectablePatch = `diff --git a/a.txt b/a.txt
+--- a/a.txt
++++ b/a.txt
+@@ -1,3 +1,4 @@
+ 1
++1.5
+ 2
+ 3
+@@ -8,3 +9,3 @@
+ 8
+-9
++nine
+ 10
+diff --git a/docs/b.md b/docs/b.md
+--- a/docs/b.md
++++ b/docs/b.md
+@@ -1 +1 @@
+-old
++new
+`
+
+func TestMatchPath(t *testing.T) {
+	tests := []struct {
+		pattern, name string
+		want          bool
+	}{
+		{"*.go", "internal/app/main.go", true},
+		{"internal/*/main.go", "internal/app/main.go", true},
+		{"internal/", "internal/app/main.go", true},
+		{"internal/**", "internal/app/main.go", true},
+		{"internal/...", "internal/app/main.go", true},
+		{"docs/**", "internal/app/main.go", false},
+		{"*.md", "main.go", false},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, MatchPath([]string{tt.pattern}, tt.name), "MatchPath(%q, %q)", tt.pattern, tt.name)
+	}
+}
+
+func TestPatchHunks(t *testing.T) {
+	hunks, err := PatchHunks(selectablePatch, nil, PatchHunk{ActivityID: "act", ArtifactIndex: 2})
+	require.NoError(t, err)
+	require.Len(t, hunks, 3)
+	assert.Equal(t, "act/2/a.txt#1", hunks[1].Key())
+	assert.Equal(t, 2, hunks[1].Count)
+	assert.Equal(t, "@@ -8,3 +9,3 @@", hunks[1].Header)
+	assert.Contains(t, hunks[1].Text, "+nine\n")
+
+	hunks, err = PatchHunks(selectablePatch, []string{"docs/"}, PatchHunk{})
+	require.NoError(t, err)
+	require.Len(t, hunks, 1)
+	assert.Equal(t, "docs/b.md", hunks[0].File)
+}
+
+func TestSelectPatchFiles(t *testing.T) {
+	selected, err := SelectPatch(selectablePatch, []string{"*.md"}, nil, PatchHunk{})
+	require.NoError(t, err)
+	assert.NotContains(t, selected, "a.txt")
+	assert.Contains(t, selected, "+new")
+
+	selected, err = SelectPatch(selectablePatch, []string{"*.go"}, nil, PatchHunk{})
+	require.NoError(t, err)
+	assert.Empty(t, selected)
+}
+
+func TestSelectPatchHunksApplies(t *testing.T) {
+	repo := initWorktreeRepo(t)
+	require.NoError(t, os.WriteFile(filepath.Join(repo, "a.txt"), []byte("1\n2\n3\n4\n5\n6\n7\n8\n9\n10\n"), 0o644))
+
+	// Keep only the second hunk of a.txt, whose new position must shift back
+	// by the line the first hunk would have added.
+	keep := SelectHunkKeys([]string{"act/0/a.txt#1"})
+	selected, err := SelectPatch(selectablePatch, nil, keep, PatchHunk{ActivityID: "act"})
+	require.NoError(t, err)
+	assert.Contains(t, selected, "@@ -8,3 +8,3 @@")
+	assert.NotContains(t, selected, "docs/b.md")
+
+	patchFile := filepath.Join(t.TempDir(), "selected.patch")
+	require.NoError(t, os.WriteFile(patchFile, []byte(selected), 0o600))
+	files, err := NewGitClient(repo).ApplyPatch(context.Background(), patchFile, false, 1, false)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"a.txt"}, files)
+
+	data, err := os.ReadFile(filepath.Join(repo, "a.txt"))
+	require.NoError(t, err)
+	assert.Equal(t, "1\n2\n3\n4\n5\n6\n7\n8\nnine\n10\n", string(data))
+}
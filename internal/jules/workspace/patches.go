@@ -8,6 +8,8 @@ import (
 	"strings"
 
 	"github.com/SamyRai/go-jules"
+	"github.com/SamyRai/juleson/internal/guard"
+	"github.com/SamyRai/juleson/internal/intelligence"
 )
 
 // PatchApplicationOptions represents options for applying patches.
@@ -21,6 +23,26 @@ type PatchApplicationOptions struct {
 	Force             bool
 	HasArtifactIndex  bool
 	AllowBaseMismatch bool
+	// AllowSecrets applies patches that add likely credentials.
+	AllowSecrets bool
+	// Guard scans patches for dangerous instructions; nil uses the default
+	// guard, which blocks them.
+	Guard *guard.Guard
+	// AllowUnsafe applies patches the guard blocks.
+	AllowUnsafe bool
+	// Only applies just the changes to files matching these globs; see
+	// MatchPath.
+	Only []string
+	// SelectHunk applies just the hunks it keeps. Nil keeps every hunk.
+	SelectHunk HunkSelector
+}
+
+// guard returns the guard that scans patches.
+func (o *PatchApplicationOptions) guard() *guard.Guard {
+	if o.Guard == nil {
+		return guard.New(guard.Options{})
+	}
+	return o.Guard
 }
 
 // PatchApplicationResult represents the result of applying patches.
@@ -30,6 +52,8 @@ type PatchApplicationResult struct {
 	SuggestedCommitMessages []string
 	Warnings                []string
 	BaseCommitMismatches    []string
+	SecretFindings          []intelligence.SecretFinding
+	GuardFindings           []guard.Finding
 	Errors                  []string
 	PatchesApplied          int
 	PatchesFailed           int
@@ -96,6 +120,8 @@ func (s *PatchService) ApplySessionPatches(ctx context.Context, sessionID string
 			result.SuggestedCommitMessages = appendUniqueStrings(result.SuggestedCommitMessages, activityResult.SuggestedCommitMessages...)
 			result.Warnings = append(result.Warnings, activityResult.Warnings...)
 			result.BaseCommitMismatches = append(result.BaseCommitMismatches, activityResult.BaseCommitMismatches...)
+			result.SecretFindings = append(result.SecretFindings, activityResult.SecretFindings...)
+			result.GuardFindings = append(result.GuardFindings, activityResult.GuardFindings...)
 			result.Errors = append(result.Errors, activityResult.Errors...)
 		}
 	}
@@ -134,6 +160,7 @@ func (s *PatchService) applyActivityPatches(ctx context.Context, sessionID, acti
 		DryRun:     options.DryRun,
 	}
 
+	contentGuard := options.guard()
 	for i, artifact := range activity.Artifacts {
 		if options.HasArtifactIndex && i != options.ArtifactIndex {
 			continue
@@ -147,6 +174,18 @@ func (s *PatchService) applyActivityPatches(ctx context.Context, sessionID, acti
 				result.PatchesFailed++
 				continue
 			}
+			if options.hasSelection() {
+				selected, err := SelectPatch(patchContent, options.Only, options.SelectHunk, PatchHunk{ActivityID: activity.ID, ArtifactIndex: i})
+				if err != nil {
+					result.Errors = append(result.Errors, fmt.Sprintf("Artifact %d: %v", i, err))
+					result.PatchesFailed++
+					continue
+				}
+				if selected == "" {
+					continue
+				}
+				patchContent = selected
+			}
 
 			if gitPatch.BaseCommitID != "" {
 				mismatch, warning, err := s.checkBaseCommitMismatch(ctx, gitClient, gitPatch.BaseCommitID, i)
@@ -166,6 +205,28 @@ func (s *PatchService) applyActivityPatches(ctx context.Context, sessionID, acti
 				}
 			}
 
+			if findings := intelligence.ScanPatchSecrets(patchContent); len(findings) > 0 {
+				warning := fmt.Sprintf("Artifact %d adds %d possible secret(s)", i, len(findings))
+				result.Warnings = append(result.Warnings, warning)
+				result.SecretFindings = append(result.SecretFindings, findings...)
+				if !options.DryRun && !options.AllowSecrets {
+					result.Errors = append(result.Errors, warning+"; pass --allow-secrets to apply anyway")
+					result.PatchesFailed++
+					continue
+				}
+			}
+
+			if findings := contentGuard.ScanPatch(patchContent); len(findings) > 0 {
+				warning := fmt.Sprintf("Artifact %d adds %d dangerous instruction(s)", i, len(findings))
+				result.Warnings = append(result.Warnings, warning)
+				result.GuardFindings = append(result.GuardFindings, findings...)
+				if !options.DryRun && !options.AllowUnsafe && contentGuard.Mode(guard.DirectionOutput) == guard.ModeBlock {
+					result.Errors = append(result.Errors, warning+"; pass --allow-unsafe to apply anyway")
+					result.PatchesFailed++
+					continue
+				}
+			}
+
 			files, err := s.applyGitPatch(ctx, patchContent, options, gitClient)
 			if err != nil {
 				result.Errors = append(result.Errors, fmt.Sprintf("Artifact %d: %v", i, err))
@@ -253,26 +314,71 @@ func GetSessionChangesWithOptions(ctx context.Context, client *jules.Client, ses
 	if options == nil {
 		options = &PatchApplicationOptions{}
 	}
+	activities, err := sessionActivities(ctx, client, sessionID, options)
+	if err != nil {
+		return nil, err
+	}
+	return changesFromActivities(sessionID, activities, options), nil
+}
+
+// SessionPatch returns the session's patches within the activity, artifact,
+// and selection scope of options, joined into one patch.
+func SessionPatch(ctx context.Context, client *jules.Client, sessionID string, options *PatchApplicationOptions) (string, error) {
+	if options == nil {
+		options = &PatchApplicationOptions{}
+	}
+	activities, err := sessionActivities(ctx, client, sessionID, options)
+	if err != nil {
+		return "", err
+	}
+	var b strings.Builder
+	for _, activity := range activities {
+		for i, artifact := range activity.Artifacts {
+			if options.HasArtifactIndex && i != options.ArtifactIndex {
+				continue
+			}
+			if artifact.ChangeSet == nil || artifact.ChangeSet.GitPatch == nil || artifact.ChangeSet.GitPatch.UnidiffPatch == "" {
+				continue
+			}
+			patch := artifact.ChangeSet.GitPatch.UnidiffPatch
+			if options.hasSelection() {
+				selected, err := SelectPatch(patch, options.Only, options.SelectHunk, PatchHunk{ActivityID: activity.ID, ArtifactIndex: i})
+				if err != nil {
+					return "", fmt.Errorf("activity %s artifact %d: %w", activity.ID, i, err)
+				}
+				patch = selected
+			}
+			b.WriteString(patch)
+			if patch != "" && !strings.HasSuffix(patch, "\n") {
+				b.WriteString("\n")
+			}
+		}
+	}
+	return b.String(), nil
+}
+
+// sessionActivities returns the activity options names, or every activity of
+// the session.
+func sessionActivities(ctx context.Context, client *jules.Client, sessionID string, options *PatchApplicationOptions) ([]jules.Activity, error) {
 	if options.ActivityID != "" {
 		activity, err := client.Activities().Get(ctx, sessionID, options.ActivityID)
 		if err != nil {
 			return nil, fmt.Errorf("failed to get activity: %w", err)
 		}
-		return changesFromActivities(sessionID, []jules.Activity{*activity}, options), nil
+		return []jules.Activity{*activity}, nil
 	}
-
 	response, err := client.Activities().List(ctx, sessionID, &jules.ListActivitiesOptions{PageSize: 100})
 	if err != nil {
 		return nil, fmt.Errorf("failed to list activities: %w", err)
 	}
-
-	return changesFromActivities(sessionID, response.Activities, options), nil
+	return response.Activities, nil
 }
 
 func changesFromActivities(sessionID string, activities []jules.Activity, options *PatchApplicationOptions) *SessionChanges {
 	changes := &SessionChanges{
 		SessionID: sessionID,
 	}
+	contentGuard := options.guard()
 
 	for _, activity := range activities {
 		for i, artifact := range activity.Artifacts {
@@ -280,10 +386,21 @@ func changesFromActivities(sessionID string, activities []jules.Activity, option
 				continue
 			}
 			if artifact.ChangeSet != nil && artifact.ChangeSet.GitPatch != nil {
+				patch := artifact.ChangeSet.GitPatch.UnidiffPatch
+				if options.hasSelection() {
+					selected, err := SelectPatch(patch, options.Only, options.SelectHunk, PatchHunk{ActivityID: activity.ID, ArtifactIndex: i})
+					if err == nil && selected == "" {
+						continue
+					}
+					if err == nil {
+						patch = selected
+					}
+				}
 				changes.TotalPatches++
 				changes.SuggestedCommitMessages = appendUniqueStrings(changes.SuggestedCommitMessages, artifact.ChangeSet.GitPatch.SuggestedCommitMessage)
 
-				patch := artifact.ChangeSet.GitPatch.UnidiffPatch
+				changes.SecretFindings = append(changes.SecretFindings, intelligence.ScanPatchSecrets(patch)...)
+				changes.GuardFindings = append(changes.GuardFindings, contentGuard.ScanPatch(patch)...)
 				fileChanges := parsePatchFiles(patch)
 
 				for _, fc := range fileChanges {
@@ -82,6 +82,42 @@ index 1234567..abcdefg 100644
 	assert.Equal(suite.T(), 1, changes.Files[0].LinesRemoved)
 }
 
+func (suite *PatchesTestSuite) TestGetSessionChangesGuardFindings() {
+	activitiesResponse := ActivitiesResponse{
+		Activities: []Activity{
+			{
+				ID: "activity-1",
+				Artifacts: []Artifact{
+					{
+						ChangeSet: &ChangeSet{
+							GitPatch: &GitPatch{
+								UnidiffPatch: `diff --git a/Makefile b/Makefile
+--- a/Makefile
++++ b/Makefile
+@@ -1,2 +1,3 @@
+ clean:
++	rm -rf .git
+ 	rm -rf build
+`,
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+	httpmock.RegisterResponder("GET", "https://jules.googleapis.com/sessions/session-123/activities",
+		httpmock.NewJsonResponderOrPanic(200, activitiesResponse))
+
+	changes, err := GetSessionChanges(context.Background(), suite.client, "session-123")
+
+	require.NoError(suite.T(), err)
+	require.Len(suite.T(), changes.GuardFindings, 1)
+	assert.Equal(suite.T(), "delete-git", changes.GuardFindings[0].RuleID)
+	assert.Equal(suite.T(), "Makefile", changes.GuardFindings[0].File)
+	assert.Equal(suite.T(), 2, changes.GuardFindings[0].Line)
+}
+
 func (suite *PatchesTestSuite) TestParsePatchFiles() {
 	patch := `diff --git a/file1.go b/file1.go
 index 1234567..abcdefg 100644
@@ -352,3 +388,42 @@ func (suite *PatchesTestSuite) TestGetSessionChangesMultiplePatches() {
 	assert.Equal(suite.T(), 2, changes.Files[0].LinesAdded)
 	assert.Equal(suite.T(), 0, changes.Files[0].LinesRemoved)
 }
+
+func (suite *PatchesTestSuite) TestParsePatchFilesStatusesAndModes() {
+	patch := `diff --git a/run.sh b/run.sh
+old mode 100644
+new mode 100755
+diff --git a/old.go b/new.go
+similarity index 90%
+rename from old.go
+rename to new.go
+index 1111111..2222222 100644
+--- a/old.go
++++ b/new.go
+@@ -1,2 +1,2 @@
+ package demo
+-var x = 1
++var x = 2
+diff --git a/logo.png b/logo.png
+new file mode 100644
+index 0000000..3333333
+Binary files /dev/null and b/logo.png differ
+diff --git a/gone.txt b/gone.txt
+deleted file mode 100644
+index 4444444..0000000
+--- a/gone.txt
++++ /dev/null
+@@ -1 +0,0 @@
+----not a header
+`
+
+	changes := parsePatchFiles(patch)
+
+	require.Len(suite.T(), changes, 4)
+	assert.Equal(suite.T(), FileChange{Path: "run.sh", Status: FileModified, OldMode: "100644", NewMode: "100755"}, changes[0])
+	assert.Equal(suite.T(), "run.sh (mode 100644 → 100755)", changes[0].Label())
+	assert.Equal(suite.T(), FileChange{Path: "new.go", OldPath: "old.go", Status: FileRenamed, LinesAdded: 1, LinesRemoved: 1}, changes[1])
+	assert.Equal(suite.T(), "old.go → new.go (renamed)", changes[1].Label())
+	assert.Equal(suite.T(), FileChange{Path: "logo.png", Status: FileAdded, Binary: true}, changes[2])
+	assert.Equal(suite.T(), FileChange{Path: "gone.txt", Status: FileDeleted, LinesRemoved: 1}, changes[3])
+}
@@ -0,0 +1,60 @@
+package workspace
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// sourceCacheFile records the Jules source connected for each GitHub
+// repository, so resolving a source does not list every source each time.
+const sourceCacheFile = "sources.json"
+
+func sourceCachePath() (string, error) {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "juleson", sourceCacheFile), nil
+}
+
+func readSourceCache() map[string]string {
+	cache := map[string]string{}
+	path, err := sourceCachePath()
+	if err != nil {
+		return cache
+	}
+	if data, err := os.ReadFile(path); err == nil {
+		_ = json.Unmarshal(data, &cache)
+	}
+	return cache
+}
+
+// CachedSource returns the source last connected for owner/repo.
+func CachedSource(owner, repo string) (string, bool) {
+	source, ok := readSourceCache()[strings.ToLower(owner+"/"+repo)]
+	return source, ok && source != ""
+}
+
+// CacheSource records the source connected for owner/repo.
+func CacheSource(owner, repo, source string) error {
+	path, err := sourceCachePath()
+	if err != nil {
+		return err
+	}
+	cache := readSourceCache()
+	cache[strings.ToLower(owner+"/"+repo)] = source
+	data, err := json.MarshalIndent(cache, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("failed to create cache directory: %w", err)
+	}
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		return fmt.Errorf("failed to write source cache: %w", err)
+	}
+	return nil
+}
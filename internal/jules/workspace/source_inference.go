@@ -2,6 +2,7 @@ package workspace
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"net/url"
 	"os/exec"
@@ -19,7 +20,19 @@ func InferSourceFromGitRemote(ctx context.Context, client *jules.Client, project
 	if err != nil {
 		return nil, err
 	}
+	source, err := FindSource(ctx, client, owner, repo)
+	if errors.Is(err, ErrSourceNotConnected) {
+		return nil, fmt.Errorf("no connected Jules source matches git remote %s/%s", owner, repo)
+	}
+	return source, err
+}
 
+// ErrSourceNotConnected means a GitHub repository has no connected Jules
+// source.
+var ErrSourceNotConnected = errors.New("repository is not connected to Jules")
+
+// FindSource returns the connected Jules source for a GitHub repository.
+func FindSource(ctx context.Context, client *jules.Client, owner, repo string) (*jules.Source, error) {
 	sources, err := client.Sources().ListAll(ctx, 100, "")
 	if err != nil {
 		return nil, fmt.Errorf("failed to list Jules sources: %w", err)
@@ -39,7 +52,7 @@ func InferSourceFromGitRemote(ctx context.Context, client *jules.Client, project
 
 	switch len(matches) {
 	case 0:
-		return nil, fmt.Errorf("no connected Jules source matches git remote %s/%s", owner, repo)
+		return nil, fmt.Errorf("%w: %s/%s", ErrSourceNotConnected, owner, repo)
 	case 1:
 		return &matches[0], nil
 	default:
@@ -47,12 +60,26 @@ func InferSourceFromGitRemote(ctx context.Context, client *jules.Client, project
 		for _, match := range matches {
 			names = append(names, match.Name)
 		}
-		return nil, fmt.Errorf("multiple Jules sources match git remote %s/%s: %s", owner, repo, strings.Join(names, ", "))
+		return nil, fmt.Errorf("multiple Jules sources match %s/%s: %s", owner, repo, strings.Join(names, ", "))
 	}
 }
 
 // GitRemoteOwnerRepo returns the GitHub owner/repo for origin in a local git repository.
 func GitRemoteOwnerRepo(ctx context.Context, projectPath string) (string, string, error) {
+	remoteURL, err := GitRemoteURL(ctx, projectPath)
+	if err != nil {
+		return "", "", err
+	}
+	owner, repo, err := ParseGitHubRemoteURL(remoteURL)
+	if err != nil {
+		return "", "", err
+	}
+	return owner, repo, nil
+}
+
+// GitRemoteURL returns the URL of the origin remote of the repository in
+// projectPath.
+func GitRemoteURL(ctx context.Context, projectPath string) (string, error) {
 	if projectPath == "" {
 		projectPath = "."
 	}
@@ -60,13 +87,9 @@ func GitRemoteOwnerRepo(ctx context.Context, projectPath string) (string, string
 	cmd.Dir = projectPath
 	output, err := cmd.Output()
 	if err != nil {
-		return "", "", fmt.Errorf("failed to read git origin remote in %s: %w", projectPath, err)
+		return "", fmt.Errorf("failed to read git origin remote in %s: %w", projectPath, err)
 	}
-	owner, repo, err := ParseGitHubRemoteURL(strings.TrimSpace(string(output)))
-	if err != nil {
-		return "", "", err
-	}
-	return owner, repo, nil
+	return strings.TrimSpace(string(output)), nil
 }
 
 // ParseGitHubRemoteURL parses common GitHub HTTPS and SSH remote URL forms.
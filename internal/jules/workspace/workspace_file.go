@@ -0,0 +1,226 @@
+package workspace
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/SamyRai/go-jules"
+	"gopkg.in/yaml.v3"
+)
+
+// FileName is the workspace file. Like go.work, it applies to the directory
+// it is in and every directory below.
+const FileName = "juleson-workspace.yaml"
+
+// ErrNoWorkspace means no workspace file was found in a directory or its
+// parents.
+var ErrNoWorkspace = errors.New("no " + FileName + " found")
+
+// Repo maps a local directory to its Jules source and GitHub, GitLab, or
+// Bitbucket repository.
+type Repo struct {
+	// Path is relative to the workspace root, with forward slashes.
+	Path string `yaml:"path"`
+	// Source is the Jules source name, such as sources/github/owner/repo.
+	Source string `yaml:"source,omitempty"`
+	// GitHub is owner/name, or HOST/owner/name for GitHub Enterprise hosts.
+	GitHub string `yaml:"github,omitempty"`
+	// GitLab is group/name, or HOST/group/name, for repositories hosted on
+	// GitLab instead of GitHub.
+	GitLab string `yaml:"gitlab,omitempty"`
+	// Bitbucket is workspace/name, for repositories hosted on Bitbucket
+	// Cloud.
+	Bitbucket string `yaml:"bitbucket,omitempty"`
+}
+
+// Workspace is a set of local repositories managed together.
+type Workspace struct {
+	// Root is the directory containing the workspace file.
+	Root  string `yaml:"-"`
+	Repos []Repo `yaml:"repos"`
+}
+
+// Init creates an empty workspace file in dir.
+func Init(dir string) (*Workspace, error) {
+	root, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := os.Stat(filepath.Join(root, FileName)); err == nil {
+		return nil, fmt.Errorf("%s already exists in %s", FileName, root)
+	}
+	ws := &Workspace{Root: root, Repos: []Repo{}}
+	if err := ws.Save(); err != nil {
+		return nil, err
+	}
+	return ws, nil
+}
+
+// Find loads the workspace file in dir or its nearest parent that has one.
+func Find(dir string) (*Workspace, error) {
+	current, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, err
+	}
+	for {
+		if _, err := os.Stat(filepath.Join(current, FileName)); err == nil {
+			return Load(current)
+		}
+		parent := filepath.Dir(current)
+		if parent == current {
+			return nil, ErrNoWorkspace
+		}
+		current = parent
+	}
+}
+
+// Load reads the workspace file in root.
+func Load(root string) (*Workspace, error) {
+	data, err := os.ReadFile(filepath.Join(root, FileName))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read workspace file: %w", err)
+	}
+	ws := &Workspace{Root: root}
+	if err := yaml.Unmarshal(data, ws); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", filepath.Join(root, FileName), err)
+	}
+	return ws, nil
+}
+
+// Save writes the workspace file, with repositories sorted by path.
+func (w *Workspace) Save() error {
+	slices.SortFunc(w.Repos, func(a, b Repo) int { return strings.Compare(a.Path, b.Path) })
+	data, err := yaml.Marshal(w)
+	if err != nil {
+		return fmt.Errorf("failed to marshal workspace file: %w", err)
+	}
+	if err := os.WriteFile(filepath.Join(w.Root, FileName), data, 0644); err != nil {
+		return fmt.Errorf("failed to write workspace file: %w", err)
+	}
+	return nil
+}
+
+// Add adds the repository in dir, which must be inside the workspace, or
+// replaces the entry already there. It returns the stored entry.
+func (w *Workspace) Add(dir string, repo Repo) (Repo, error) {
+	rel, err := w.relative(dir)
+	if err != nil {
+		return Repo{}, err
+	}
+	repo.Path = rel
+	if repo.Source != "" {
+		repo.Source = jules.NormalizeSourceName(repo.Source)
+	}
+	for i, existing := range w.Repos {
+		if existing.Path == rel {
+			w.Repos[i] = repo
+			return repo, nil
+		}
+	}
+	w.Repos = append(w.Repos, repo)
+	return repo, nil
+}
+
+// Remove removes the repository in dir. It reports whether there was one.
+func (w *Workspace) Remove(dir string) (bool, error) {
+	rel, err := w.relative(dir)
+	if err != nil {
+		return false, err
+	}
+	before := len(w.Repos)
+	w.Repos = slices.DeleteFunc(w.Repos, func(repo Repo) bool { return repo.Path == rel })
+	return len(w.Repos) != before, nil
+}
+
+// Lookup returns the repository containing dir, preferring the innermost
+// when repositories are nested.
+func (w *Workspace) Lookup(dir string) (Repo, bool) {
+	rel, err := w.relative(dir)
+	if err != nil {
+		return Repo{}, false
+	}
+	var found Repo
+	best := -1
+	for _, repo := range w.Repos {
+		depth := 0
+		switch {
+		case repo.Path == ".":
+		case rel == repo.Path || strings.HasPrefix(rel, repo.Path+"/"):
+			depth = len(repo.Path)
+		default:
+			continue
+		}
+		if depth > best {
+			found, best = repo, depth
+		}
+	}
+	return found, best >= 0
+}
+
+// Dir returns the absolute directory of repo.
+func (w *Workspace) Dir(repo Repo) string {
+	return filepath.Join(w.Root, filepath.FromSlash(repo.Path))
+}
+
+// relative returns dir relative to the workspace root, with forward slashes.
+func (w *Workspace) relative(dir string) (string, error) {
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		return "", err
+	}
+	rel, err := filepath.Rel(w.Root, abs)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("%s is outside the workspace at %s", abs, w.Root)
+	}
+	return filepath.ToSlash(rel), nil
+}
+
+// LookupRepo returns the workspace repository containing dir, if dir is
+// inside a workspace that lists one.
+func LookupRepo(dir string) (Repo, bool) {
+	ws, err := Find(dir)
+	if err != nil {
+		return Repo{}, false
+	}
+	return ws.Lookup(dir)
+}
+
+// ResolveSource returns the Jules source for dir: the one its workspace
+// entry records, or else the connected source matching its origin remote,
+// which is cached for next time.
+func ResolveSource(ctx context.Context, client *jules.Client, dir string) (string, error) {
+	if repo, ok := LookupRepo(dir); ok && repo.Source != "" {
+		return repo.Source, nil
+	}
+	owner, name, err := GitRemoteOwnerRepo(ctx, dir)
+	if err != nil {
+		return "", err
+	}
+	if source, ok := CachedSource(owner, name); ok {
+		return source, nil
+	}
+	source, err := FindSource(ctx, client, owner, name)
+	if err != nil {
+		return "", err
+	}
+	_ = CacheSource(owner, name, source.Name)
+	return source.Name, nil
+}
+
+// DescribeRemote returns the GitHub repository and default Jules source name
+// for the git repository in dir, from its origin remote.
+func DescribeRemote(ctx context.Context, dir string) (Repo, error) {
+	owner, name, err := GitRemoteOwnerRepo(ctx, dir)
+	if err != nil {
+		return Repo{}, err
+	}
+	return Repo{
+		Source: fmt.Sprintf("sources/github/%s/%s", owner, name),
+		GitHub: owner + "/" + name,
+	}, nil
+}
@@ -0,0 +1,94 @@
+package workspace
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWorkspaceFileRoundTrip(t *testing.T) {
+	root := t.TempDir()
+	ws, err := Init(root)
+	require.NoError(t, err)
+	_, err = Init(root)
+	assert.Error(t, err, "init must not overwrite an existing workspace")
+
+	for _, dir := range []string{"web", "services/api", "services/api/worker"} {
+		require.NoError(t, os.MkdirAll(filepath.Join(root, dir), 0755))
+	}
+	added, err := ws.Add(filepath.Join(root, "web"), Repo{Source: "github/acme/web", GitHub: "acme/web"})
+	require.NoError(t, err)
+	assert.Equal(t, Repo{Path: "web", Source: "sources/github/acme/web", GitHub: "acme/web"}, added)
+	_, err = ws.Add(filepath.Join(root, "services", "api"), Repo{Source: "sources/github/acme/api", GitHub: "ghe.example.com/acme/api"})
+	require.NoError(t, err)
+	_, err = ws.Add(filepath.Join(root, "services", "api", "worker"), Repo{GitHub: "acme/worker"})
+	require.NoError(t, err)
+	_, err = ws.Add(filepath.Dir(root), Repo{})
+	assert.Error(t, err, "directories outside the workspace are rejected")
+	require.NoError(t, ws.Save())
+
+	found, err := Find(filepath.Join(root, "services", "api", "worker", "cmd"))
+	require.NoError(t, err)
+	assert.Equal(t, root, found.Root)
+	assert.Equal(t, []string{"services/api", "services/api/worker", "web"}, repoPaths(found.Repos))
+
+	repo, ok := found.Lookup(filepath.Join(root, "services", "api", "worker"))
+	require.True(t, ok)
+	assert.Equal(t, "acme/worker", repo.GitHub, "the innermost repository wins")
+	repo, ok = found.Lookup(filepath.Join(root, "services", "api", "internal"))
+	require.True(t, ok)
+	assert.Equal(t, "services/api", repo.Path)
+	_, ok = found.Lookup(filepath.Join(root, "services"))
+	assert.False(t, ok)
+	assert.Equal(t, filepath.Join(root, "services", "api"), found.Dir(repo))
+
+	removed, err := found.Remove(filepath.Join(root, "web"))
+	require.NoError(t, err)
+	assert.True(t, removed)
+	removed, err = found.Remove(filepath.Join(root, "web"))
+	require.NoError(t, err)
+	assert.False(t, removed)
+}
+
+func TestFindWithoutWorkspace(t *testing.T) {
+	_, err := Find(t.TempDir())
+	assert.ErrorIs(t, err, ErrNoWorkspace)
+}
+
+func repoPaths(repos []Repo) []string {
+	paths := make([]string, 0, len(repos))
+	for _, repo := range repos {
+		paths = append(paths, repo.Path)
+	}
+	return paths
+}
+
+func TestResolveSourcePrefersWorkspaceThenCache(t *testing.T) {
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+	root := t.TempDir()
+	for _, args := range [][]string{{"init"}, {"remote", "add", "origin", "git@github.com:acme/widgets.git"}} {
+		cmd := exec.Command("git", args...)
+		cmd.Dir = root
+		require.NoError(t, cmd.Run())
+	}
+	ctx := context.Background()
+
+	require.NoError(t, CacheSource("acme", "widgets", "sources/github/acme/widgets-cached"))
+	source, err := ResolveSource(ctx, nil, root)
+	require.NoError(t, err)
+	assert.Equal(t, "sources/github/acme/widgets-cached", source)
+
+	ws, err := Init(root)
+	require.NoError(t, err)
+	_, err = ws.Add(root, Repo{Source: "github/acme/widgets-workspace"})
+	require.NoError(t, err)
+	require.NoError(t, ws.Save())
+	source, err = ResolveSource(ctx, nil, root)
+	require.NoError(t, err)
+	assert.Equal(t, "sources/github/acme/widgets-workspace", source)
+}
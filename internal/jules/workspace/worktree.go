@@ -0,0 +1,343 @@
+package workspace
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/SamyRai/go-jules"
+)
+
+// WorktreeManager applies patches in a temporary git worktree checked out at
+// HEAD, validates the result there, and only then merges the changes back
+// into the user's working tree. Local uncommitted changes are never touched
+// until validation passes, and a merge that would conflict with them is
+// refused.
+type WorktreeManager struct {
+	// RepoDir is the project directory, anywhere inside the repository.
+	RepoDir string
+	// TempDir is where worktrees are created; empty uses the system default.
+	TempDir string
+	// Base is the commit or branch worktrees are checked out at; empty is
+	// HEAD.
+	Base string
+}
+
+// Worktree is a temporary detached checkout of the repository.
+type Worktree struct {
+	// Root is the top-level directory of the temporary worktree.
+	Root string
+	// Dir is the project directory inside Root that matches RepoDir.
+	Dir string
+	// Base is the commit the worktree was created at.
+	Base string
+
+	repoRoot string
+}
+
+// NewWorktreeManager creates a WorktreeManager for the repository containing repoDir.
+func NewWorktreeManager(repoDir string) *WorktreeManager {
+	return &WorktreeManager{RepoDir: repoDir}
+}
+
+func worktreeGit(ctx context.Context, dir string, args ...string) (string, error) {
+	cmd := exec.CommandContext(ctx, "git", args...)
+	cmd.Dir = dir
+	cmd.Env = append(cmd.Environ(), "GIT_TERMINAL_PROMPT=0", "LC_ALL=C")
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		return "", fmt.Errorf("git %s failed: %w\nOutput: %s", args[0], err, strings.TrimSpace(string(output)))
+	}
+	return strings.TrimSpace(string(output)), nil
+}
+
+// Create checks out Base into a new temporary worktree.
+func (m *WorktreeManager) Create(ctx context.Context) (*Worktree, error) {
+	repoDir, err := filepath.Abs(m.RepoDir)
+	if err != nil {
+		return nil, err
+	}
+	repoRoot, err := worktreeGit(ctx, repoDir, "rev-parse", "--show-toplevel")
+	if err != nil {
+		return nil, fmt.Errorf("%s is not in a git repository: %w", repoDir, err)
+	}
+	prefix, err := worktreeGit(ctx, repoDir, "rev-parse", "--show-prefix")
+	if err != nil {
+		return nil, err
+	}
+	ref := m.Base
+	if ref == "" {
+		ref = "HEAD"
+	}
+	base, err := worktreeGit(ctx, repoRoot, "rev-parse", "--verify", ref+"^{commit}")
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve %s: %w", ref, err)
+	}
+
+	root, err := os.MkdirTemp(m.TempDir, "juleson-worktree-*")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create worktree directory: %w", err)
+	}
+	if _, err := worktreeGit(ctx, repoRoot, "worktree", "add", "--detach", "--quiet", root, base); err != nil {
+		_ = os.RemoveAll(root)
+		return nil, err
+	}
+	return &Worktree{
+		Root:     root,
+		Dir:      filepath.Join(root, filepath.FromSlash(prefix)),
+		Base:     base,
+		repoRoot: repoRoot,
+	}, nil
+}
+
+// Remove deletes the worktree and its administrative files.
+func (m *WorktreeManager) Remove(ctx context.Context, wt *Worktree) error {
+	_, err := worktreeGit(ctx, wt.repoRoot, "worktree", "remove", "--force", wt.Root)
+	if err != nil {
+		if rmErr := os.RemoveAll(wt.Root); rmErr != nil {
+			return rmErr
+		}
+		_, err = worktreeGit(ctx, wt.repoRoot, "worktree", "prune")
+	}
+	return err
+}
+
+// Diff stages everything in the worktree and returns a binary-safe patch of
+// its changes against Base, with the paths it touches.
+func (m *WorktreeManager) Diff(ctx context.Context, wt *Worktree) (string, []string, error) {
+	if _, err := worktreeGit(ctx, wt.Root, "add", "--all"); err != nil {
+		return "", nil, err
+	}
+	names, err := worktreeGit(ctx, wt.Root, "diff", "--cached", "--name-only", wt.Base)
+	if err != nil || names == "" {
+		return "", nil, err
+	}
+	cmd := exec.CommandContext(ctx, "git", "diff", "--cached", "--binary", "--no-color", wt.Base)
+	cmd.Dir = wt.Root
+	patch, err := cmd.Output()
+	if err != nil {
+		return "", nil, fmt.Errorf("git diff failed: %w", err)
+	}
+	return string(patch), strings.Split(names, "\n"), nil
+}
+
+// Merge applies the worktree's changes to the user's working tree. The patch
+// is checked first, so nothing is written when it conflicts with local
+// changes. It returns the merged paths, or nil when there was nothing to merge.
+func (m *WorktreeManager) Merge(ctx context.Context, wt *Worktree) ([]string, error) {
+	patch, files, err := m.Diff(ctx, wt)
+	if err != nil || patch == "" {
+		return nil, err
+	}
+	head, err := worktreeGit(ctx, wt.repoRoot, "rev-parse", "HEAD")
+	if err != nil {
+		return nil, err
+	}
+	if head != wt.Base {
+		return nil, fmt.Errorf("HEAD moved from %s to %s while validating; re-run apply", shortCommit(wt.Base), shortCommit(head))
+	}
+
+	tmpFile, err := os.CreateTemp("", "juleson-worktree-*.patch")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create temp file: %w", err)
+	}
+	defer func() { _ = os.Remove(tmpFile.Name()) }()
+	if _, err := tmpFile.WriteString(patch); err != nil {
+		tmpFile.Close()
+		return nil, fmt.Errorf("failed to write patch: %w", err)
+	}
+	tmpFile.Close()
+
+	if _, err := worktreeGit(ctx, wt.repoRoot, "apply", "--check", tmpFile.Name()); err != nil {
+		return nil, fmt.Errorf("validated changes conflict with the working tree: %w", err)
+	}
+	if _, err := worktreeGit(ctx, wt.repoRoot, "apply", tmpFile.Name()); err != nil {
+		return nil, err
+	}
+	return files, nil
+}
+
+// Commit commits the worktree's changes to a new branch and returns the
+// commit. The branch must not exist yet.
+func (m *WorktreeManager) Commit(ctx context.Context, wt *Worktree, branch, message string) (string, error) {
+	if _, err := worktreeGit(ctx, wt.Root, "add", "--all"); err != nil {
+		return "", err
+	}
+	if _, err := worktreeGit(ctx, wt.Root, "diff", "--cached", "--quiet", wt.Base); err == nil {
+		return "", fmt.Errorf("no changes to commit")
+	}
+	if _, err := worktreeGit(ctx, wt.Root, "switch", "--quiet", "--create", branch); err != nil {
+		return "", err
+	}
+	if _, err := worktreeGit(ctx, wt.Root, "commit", "--quiet", "--message", message); err != nil {
+		return "", err
+	}
+	return worktreeGit(ctx, wt.Root, "rev-parse", "HEAD")
+}
+
+// Push pushes a branch of the worktree to remote.
+func (m *WorktreeManager) Push(ctx context.Context, wt *Worktree, remote, branch string) error {
+	_, err := worktreeGit(ctx, wt.Root, "push", "--quiet", remote, branch+":"+branch)
+	return err
+}
+
+func shortCommit(commit string) string {
+	if len(commit) > 12 {
+		return commit[:12]
+	}
+	return commit
+}
+
+// DefaultWorktreeChecks returns the validation commands for a project: build,
+// vet, and tests for Go modules. Other projects return nil, which runs the
+// detected test command from VerifyProjectChanges.
+func DefaultWorktreeChecks(dir string) []string {
+	if fileExists(dir, "go.mod") {
+		return []string{"go build ./...", "go vet ./...", "go test ./..."}
+	}
+	return nil
+}
+
+// Validate runs checks in the worktree in order and stops at the first
+// failure. With no checks it uses DefaultWorktreeChecks.
+func (m *WorktreeManager) Validate(ctx context.Context, wt *Worktree, checks []string) ([]*VerificationResult, bool, error) {
+	if len(checks) == 0 {
+		checks = DefaultWorktreeChecks(wt.Dir)
+	}
+	if len(checks) == 0 {
+		checks = []string{""}
+	}
+	var results []*VerificationResult
+	for _, check := range checks {
+		result, err := VerifyProjectChanges(ctx, VerificationOptions{WorkingDir: wt.Dir, Command: check})
+		if err != nil {
+			return results, false, err
+		}
+		results = append(results, result)
+		if !result.Success {
+			return results, false, nil
+		}
+	}
+	return results, true, nil
+}
+
+// IsolatedApplyOptions controls ApplySessionPatchesIsolated.
+type IsolatedApplyOptions struct {
+	// Patch selects and applies patches; WorkingDir is the user's project
+	// directory. With DryRun the changes are validated but not merged.
+	Patch *PatchApplicationOptions
+	// Checks are validation commands run in the worktree.
+	Checks []string
+	// KeepWorktree keeps the worktree after a failure for inspection.
+	KeepWorktree bool
+	// Branch, when set, commits the validated changes to a new branch with
+	// CommitMessage instead of merging them into the working tree.
+	Branch        string
+	CommitMessage string
+	// Remote, when set with Branch, is pushed the branch.
+	Remote string
+	// Base is the commit or branch the worktree starts from; empty is HEAD.
+	// Changes are only merged into the working tree from HEAD, so other
+	// bases need Branch.
+	Base string
+}
+
+// IsolatedApplyResult reports patch application, validation, and merge.
+type IsolatedApplyResult struct {
+	Patch  *PatchApplicationResult
+	Checks []*VerificationResult
+	// WorktreePath is set when the worktree was kept.
+	WorktreePath string
+	FilesMerged  []string
+	Validated    bool
+	Merged       bool
+	// Commit is the commit made on IsolatedApplyOptions.Branch.
+	Commit string
+	Pushed bool
+}
+
+// ApplySessionPatchesIsolated applies a session's patches in a temporary
+// worktree, validates them there, and merges them back only when every patch
+// applied and every check passed.
+func ApplySessionPatchesIsolated(ctx context.Context, client *jules.Client, sessionID string, options IsolatedApplyOptions) (*IsolatedApplyResult, error) {
+	patchOptions := PatchApplicationOptions{}
+	if options.Patch != nil {
+		patchOptions = *options.Patch
+	}
+	if patchOptions.WorkingDir == "" {
+		wd, err := os.Getwd()
+		if err != nil {
+			return nil, fmt.Errorf("failed to get working directory: %w", err)
+		}
+		patchOptions.WorkingDir = wd
+	}
+	dryRun := patchOptions.DryRun
+
+	manager := NewWorktreeManager(patchOptions.WorkingDir)
+	manager.Base = options.Base
+	wt, err := manager.Create(ctx)
+	if err != nil {
+		return nil, err
+	}
+	result := &IsolatedApplyResult{}
+	keep := false
+	defer func() {
+		if keep {
+			result.WorktreePath = wt.Root
+			return
+		}
+		_ = manager.Remove(context.WithoutCancel(ctx), wt)
+	}()
+
+	// The worktree is disposable, so patches are really applied there even
+	// for a dry run, and backups would only end up in the merged changes.
+	// Secrets only block what would be merged.
+	patchOptions.WorkingDir = wt.Dir
+	patchOptions.DryRun = false
+	patchOptions.CreateBackup = false
+	patchOptions.AllowSecrets = patchOptions.AllowSecrets || dryRun
+	result.Patch, err = ApplySessionPatches(ctx, client, sessionID, &patchOptions)
+	if err != nil {
+		keep = options.KeepWorktree
+		return result, err
+	}
+	result.Patch.DryRun = dryRun
+	if len(result.Patch.Errors) > 0 {
+		keep = options.KeepWorktree
+		return result, fmt.Errorf("%d patch(es) failed to apply in the worktree", len(result.Patch.Errors))
+	}
+
+	result.Checks, result.Validated, err = manager.Validate(ctx, wt, options.Checks)
+	if err != nil || !result.Validated {
+		keep = options.KeepWorktree
+		if err == nil {
+			err = fmt.Errorf("validation failed; the working tree was not changed")
+		}
+		return result, err
+	}
+	if dryRun {
+		return result, nil
+	}
+	if options.Branch != "" {
+		result.Commit, err = manager.Commit(ctx, wt, options.Branch, options.CommitMessage)
+		if err == nil && options.Remote != "" {
+			err = manager.Push(ctx, wt, options.Remote, options.Branch)
+			result.Pushed = err == nil
+		}
+		if err != nil {
+			keep = options.KeepWorktree
+		}
+		return result, err
+	}
+
+	result.FilesMerged, err = manager.Merge(ctx, wt)
+	if err != nil {
+		keep = options.KeepWorktree
+		return result, err
+	}
+	result.Merged = len(result.FilesMerged) > 0
+	return result, nil
+}
@@ -0,0 +1,137 @@
+package workspace
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func initWorktreeRepo(t *testing.T) string {
+	t.Helper()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "a.txt"), []byte("one\n"), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "b.txt"), []byte("two\n"), 0o644))
+	for _, args := range [][]string{
+		{"init", "--quiet"},
+		{"-c", "user.email=dev@example.com", "-c", "user.name=Dev", "-c", "commit.gpgsign=false", "add", "."},
+		{"-c", "user.email=dev@example.com", "-c", "user.name=Dev", "-c", "commit.gpgsign=false", "commit", "--quiet", "-m", "initial"},
+	} {
+		out, err := exec.Command("git", append([]string{"-C", dir}, args...)...).CombinedOutput()
+		require.NoError(t, err, string(out))
+	}
+	return dir
+}
+
+func TestWorktreeValidateAndMergeKeepsLocalChanges(t *testing.T) {
+	ctx := context.Background()
+	repo := initWorktreeRepo(t)
+	manager := &WorktreeManager{RepoDir: repo, TempDir: t.TempDir()}
+
+	// An unrelated local edit must survive the merge.
+	require.NoError(t, os.WriteFile(filepath.Join(repo, "b.txt"), []byte("two, edited\n"), 0o644))
+
+	wt, err := manager.Create(ctx)
+	require.NoError(t, err)
+	defer func() { _ = manager.Remove(ctx, wt) }()
+
+	data, err := os.ReadFile(filepath.Join(wt.Dir, "b.txt"))
+	require.NoError(t, err)
+	assert.Equal(t, "two\n", string(data), "worktree starts from HEAD, not the dirty tree")
+
+	require.NoError(t, os.WriteFile(filepath.Join(wt.Dir, "a.txt"), []byte("one, patched\n"), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(wt.Dir, "new.txt"), []byte("new\n"), 0o644))
+
+	results, ok, err := manager.Validate(ctx, wt, []string{"false"})
+	require.NoError(t, err)
+	assert.False(t, ok)
+	assert.Len(t, results, 1)
+
+	results, ok, err = manager.Validate(ctx, wt, []string{"true", "git status"})
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.Len(t, results, 2)
+
+	files, err := manager.Merge(ctx, wt)
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{"a.txt", "new.txt"}, files)
+
+	for name, want := range map[string]string{"a.txt": "one, patched\n", "b.txt": "two, edited\n", "new.txt": "new\n"} {
+		data, err := os.ReadFile(filepath.Join(repo, name))
+		require.NoError(t, err)
+		assert.Equal(t, want, string(data), name)
+	}
+}
+
+func TestWorktreeMergeRefusesConflicts(t *testing.T) {
+	ctx := context.Background()
+	repo := initWorktreeRepo(t)
+	manager := &WorktreeManager{RepoDir: repo, TempDir: t.TempDir()}
+
+	wt, err := manager.Create(ctx)
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(filepath.Join(wt.Dir, "a.txt"), []byte("from patch\n"), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(repo, "a.txt"), []byte("local edit\n"), 0o644))
+
+	_, err = manager.Merge(ctx, wt)
+	require.Error(t, err)
+	data, err := os.ReadFile(filepath.Join(repo, "a.txt"))
+	require.NoError(t, err)
+	assert.Equal(t, "local edit\n", string(data))
+
+	require.NoError(t, manager.Remove(ctx, wt))
+	_, err = os.Stat(wt.Root)
+	assert.True(t, os.IsNotExist(err))
+}
+
+func TestWorktreeCommitToBranch(t *testing.T) {
+	ctx := context.Background()
+	repo := initWorktreeRepo(t)
+	remote := t.TempDir()
+	out, err := exec.Command("git", "init", "--quiet", "--bare", remote).CombinedOutput()
+	require.NoError(t, err, string(out))
+	for key, value := range map[string]string{
+		"GIT_AUTHOR_NAME": "Dev", "GIT_AUTHOR_EMAIL": "dev@example.com",
+		"GIT_COMMITTER_NAME": "Dev", "GIT_COMMITTER_EMAIL": "dev@example.com",
+		"GIT_CONFIG_COUNT": "1", "GIT_CONFIG_KEY_0": "commit.gpgsign", "GIT_CONFIG_VALUE_0": "false",
+	} {
+		t.Setenv(key, value)
+	}
+	manager := &WorktreeManager{RepoDir: repo, TempDir: t.TempDir()}
+
+	wt, err := manager.Create(ctx)
+	require.NoError(t, err)
+	defer func() { _ = manager.Remove(ctx, wt) }()
+
+	_, err = manager.Commit(ctx, wt, "empty", "nothing")
+	require.Error(t, err)
+
+	require.NoError(t, os.WriteFile(filepath.Join(wt.Dir, "a.txt"), []byte("one, patched\n"), 0o644))
+	commit, err := manager.Commit(ctx, wt, "upgrade", "Patch a")
+	require.NoError(t, err)
+	require.NoError(t, manager.Push(ctx, wt, remote, "upgrade"))
+
+	out, err = exec.Command("git", "-C", remote, "log", "--format=%H %s", "upgrade").CombinedOutput()
+	require.NoError(t, err, string(out))
+	assert.Contains(t, string(out), commit+" Patch a")
+
+	data, err := os.ReadFile(filepath.Join(repo, "a.txt"))
+	require.NoError(t, err)
+	assert.Equal(t, "one\n", string(data), "the working tree is left alone")
+
+	next := &WorktreeManager{RepoDir: repo, TempDir: t.TempDir(), Base: "upgrade"}
+	wt2, err := next.Create(ctx)
+	require.NoError(t, err)
+	defer func() { _ = next.Remove(ctx, wt2) }()
+	assert.Equal(t, commit, wt2.Base)
+	data, err = os.ReadFile(filepath.Join(wt2.Dir, "a.txt"))
+	require.NoError(t, err)
+	assert.Equal(t, "one, patched\n", string(data), "the worktree starts from the branch")
+}
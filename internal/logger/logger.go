@@ -2,6 +2,7 @@ package logger
 
 import (
 	"context"
+	"fmt"
 	"io"
 	"log/slog"
 	"os"
@@ -17,7 +18,9 @@ const LevelSuccess = slog.Level(2)
 
 // Config holds logger configuration.
 type Config struct {
-	Output     io.Writer
+	Output io.Writer
+	// Level overrides Debug when set, allowing the level to change at runtime.
+	Level      slog.Leveler
 	Debug      bool
 	FormatJSON bool
 }
@@ -30,33 +33,33 @@ func New(cfg Config) *slog.Logger {
 		out = os.Stderr
 	}
 
-	level := slog.LevelInfo
+	var level slog.Leveler = slog.LevelInfo
 	if cfg.Debug {
 		level = slog.LevelDebug
 	}
+	if cfg.Level != nil {
+		level = cfg.Level
+	}
 
 	opts := &slog.HandlerOptions{
 		Level:       level,
 		ReplaceAttr: redactSecrets,
 	}
 
-	// Determine if we should use TTY theme
-	isTerminal := false
-	if f, ok := out.(*os.File); ok {
-		isTerminal = isatty.IsTerminal(f.Fd()) || isatty.IsCygwinTerminal(f.Fd())
-	}
-
 	if cfg.FormatJSON {
 		handler = slog.NewJSONHandler(out, opts)
-	} else if isTerminal {
-		handler = NewThemeHandler(out, opts)
 	} else {
-		handler = slog.NewTextHandler(out, opts)
+		handler = newAutoHandler(out, opts)
 	}
 
 	return slog.New(handler)
 }
 
+func isTerminalWriter(out io.Writer) bool {
+	f, ok := out.(*os.File)
+	return ok && (isatty.IsTerminal(f.Fd()) || isatty.IsCygwinTerminal(f.Fd()))
+}
+
 // Success is a helper to log a success message since slog doesn't have it built-in.
 func Success(l *slog.Logger, msg string, args ...any) {
 	l.Log(context.Background(), LevelSuccess, msg, args...)
@@ -69,12 +72,28 @@ func SetupGlobal(debug bool) {
 
 // SetupGlobalWithOutput configures the global slog logger with a specific output.
 func SetupGlobalWithOutput(debug bool, out io.Writer) {
-	l := New(Config{
-		Debug:      debug,
-		FormatJSON: false,
-		Output:     out,
-	})
-	slog.SetDefault(l)
+	// Without sinks Setup only fails on invalid levels, which are not set here.
+	_ = Setup(Options{Debug: debug, Output: out})
+}
+
+// globalLevel backs the global logger so its level can change at runtime.
+var globalLevel = new(slog.LevelVar)
+
+// SetLevel changes the level of the global logger without rebuilding it.
+func SetLevel(level slog.Level) {
+	globalLevel.Set(level)
+}
+
+// ParseLevel parses debug, info, warn, or error. An empty string is info.
+func ParseLevel(s string) (slog.Level, error) {
+	if s == "" {
+		return slog.LevelInfo, nil
+	}
+	var level slog.Level
+	if err := level.UnmarshalText([]byte(s)); err != nil {
+		return slog.LevelInfo, fmt.Errorf("invalid log level %q", s)
+	}
+	return level, nil
 }
 
 var secretPatterns = []*regexp.Regexp{
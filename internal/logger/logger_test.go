@@ -2,6 +2,9 @@ package logger
 
 import (
 	"bytes"
+	"io"
+	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 )
@@ -41,3 +44,59 @@ func TestRedactSecrets(t *testing.T) {
 		t.Errorf("Secret in error string was not redacted: %s", output)
 	}
 }
+
+func TestSetupSubsystemLevels(t *testing.T) {
+	var buf bytes.Buffer
+	if err := Setup(Options{
+		Output:     &buf,
+		Level:      "warn",
+		Format:     "text",
+		Subsystems: map[string]string{SubsystemJules: "debug"},
+	}); err != nil {
+		t.Fatalf("Setup() error = %v", err)
+	}
+	t.Cleanup(func() { SetupGlobalWithOutput(false, io.Discard) })
+
+	For(SubsystemMCP).Info("mcp info")
+	For(SubsystemJules).Debug("jules debug")
+
+	output := buf.String()
+	if strings.Contains(output, "mcp info") {
+		t.Errorf("mcp info should be filtered at warn: %s", output)
+	}
+	if !strings.Contains(output, "jules debug") || !strings.Contains(output, "subsystem=jules") {
+		t.Errorf("expected jules debug record, got: %s", output)
+	}
+}
+
+func TestSetupFileSinkRotates(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "juleson.log")
+	f, err := openRotatingFile(path, 0, 2)
+	if err != nil {
+		t.Fatalf("openRotatingFile() error = %v", err)
+	}
+	f.maxSize = 16
+	for i := 0; i < 4; i++ {
+		if _, err := f.Write([]byte("0123456789\n")); err != nil {
+			t.Fatalf("Write() error = %v", err)
+		}
+	}
+	if err := f.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+
+	for _, name := range []string{path, path + ".1", path + ".2"} {
+		if _, err := os.Stat(name); err != nil {
+			t.Errorf("expected %s: %v", name, err)
+		}
+	}
+	if _, err := os.Stat(path + ".3"); err == nil {
+		t.Errorf("expected at most 2 backups")
+	}
+}
+
+func TestSetupRejectsUnknownSink(t *testing.T) {
+	if err := Setup(Options{Sinks: []SinkOptions{{Type: "kafka"}}}); err == nil {
+		t.Fatal("Setup() with unknown sink should fail")
+	}
+}
@@ -0,0 +1,321 @@
+package logger
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+	"sync"
+)
+
+// SubsystemKey is the attribute that names the component a record came from.
+const SubsystemKey = "subsystem"
+
+// Subsystem names used with For.
+const (
+	SubsystemJules  = "jules"
+	SubsystemMCP    = "mcp"
+	SubsystemEvents = "events"
+	SubsystemConfig = "config"
+	SubsystemGemini = "gemini"
+)
+
+// Options configures the global logger.
+type Options struct {
+	// Output receives logs when no sinks are configured.
+	Output io.Writer
+	// Level is the default level; empty falls back to Debug.
+	Level string
+	// Format is text, json, or auto (themed output on terminals).
+	Format string
+	// Subsystems overrides the level for individual subsystems.
+	Subsystems map[string]string
+	Sinks      []SinkOptions
+	Debug      bool
+}
+
+// SinkOptions configures one log destination.
+type SinkOptions struct {
+	// Type is stderr, stdout, file, or syslog.
+	Type string
+	// Path is the log file for file sinks.
+	Path string
+	// Address is network://host:port for syslog sinks, e.g. udp://localhost:514.
+	Address string
+	// Format overrides Options.Format for this sink.
+	Format string
+	// Level drops records below it for this sink only.
+	Level      string
+	MaxSizeMB  int
+	MaxBackups int
+}
+
+var (
+	subsystemMu     sync.RWMutex
+	subsystemLevels = map[string]slog.Level{}
+
+	closersMu sync.Mutex
+	closers   []io.Closer
+)
+
+// Setup replaces the global logger with one that writes to the configured
+// sinks and honours per-subsystem levels. Previously opened sinks are closed.
+func Setup(opts Options) error {
+	level := slog.LevelInfo
+	if opts.Debug {
+		level = slog.LevelDebug
+	}
+	if opts.Level != "" {
+		parsed, err := ParseLevel(opts.Level)
+		if err != nil {
+			return err
+		}
+		level = parsed
+	}
+	if err := SetSubsystemLevels(opts.Subsystems); err != nil {
+		return err
+	}
+
+	specs := opts.Sinks
+	if len(specs) == 0 {
+		specs = []SinkOptions{{Type: "output"}}
+	}
+
+	var (
+		sinks   []*sink
+		opened  []io.Closer
+		openErr error
+	)
+	for _, spec := range specs {
+		s, closer, err := newSink(spec, opts)
+		if err != nil {
+			openErr = err
+			break
+		}
+		sinks = append(sinks, s)
+		if closer != nil {
+			opened = append(opened, closer)
+		}
+	}
+	if openErr != nil {
+		for _, c := range opened {
+			_ = c.Close()
+		}
+		return openErr
+	}
+
+	globalLevel.Set(level)
+	slog.SetDefault(slog.New(&routerHandler{sinks: sinks}))
+
+	closersMu.Lock()
+	previous := closers
+	closers = opened
+	closersMu.Unlock()
+	for _, c := range previous {
+		_ = c.Close()
+	}
+	return nil
+}
+
+// Close flushes and closes file and syslog sinks.
+func Close() error {
+	closersMu.Lock()
+	previous := closers
+	closers = nil
+	closersMu.Unlock()
+
+	var errs []error
+	for _, c := range previous {
+		errs = append(errs, c.Close())
+	}
+	return errors.Join(errs...)
+}
+
+// For returns the global logger tagged with a subsystem so its level can be
+// tuned independently.
+func For(subsystem string) *slog.Logger {
+	return slog.Default().With(SubsystemKey, subsystem)
+}
+
+// SetSubsystemLevels replaces the per-subsystem level overrides.
+func SetSubsystemLevels(levels map[string]string) error {
+	parsed := make(map[string]slog.Level, len(levels))
+	for name, raw := range levels {
+		level, err := ParseLevel(raw)
+		if err != nil {
+			return fmt.Errorf("subsystem %s: %w", name, err)
+		}
+		parsed[strings.ToLower(name)] = level
+	}
+
+	subsystemMu.Lock()
+	subsystemLevels = parsed
+	subsystemMu.Unlock()
+	return nil
+}
+
+func subsystemLevel(name string) (slog.Level, bool) {
+	subsystemMu.RLock()
+	defer subsystemMu.RUnlock()
+	level, ok := subsystemLevels[name]
+	return level, ok
+}
+
+func newSink(spec SinkOptions, opts Options) (*sink, io.Closer, error) {
+	format := spec.Format
+	if format == "" {
+		format = opts.Format
+	}
+
+	s := &sink{minLevel: slog.LevelDebug - 4}
+	if spec.Level != "" {
+		level, err := ParseLevel(spec.Level)
+		if err != nil {
+			return nil, nil, fmt.Errorf("%s sink: %w", spec.Type, err)
+		}
+		s.minLevel = level
+	}
+
+	var (
+		out    io.Writer
+		closer io.Closer
+	)
+	switch strings.ToLower(spec.Type) {
+	case "output":
+		out = opts.Output
+		if out == nil {
+			out = os.Stderr
+		}
+	case "", "stderr":
+		out = os.Stderr
+	case "stdout":
+		out = os.Stdout
+	case "file":
+		if spec.Path == "" {
+			return nil, nil, fmt.Errorf("file sink requires a path")
+		}
+		file, err := openRotatingFile(os.ExpandEnv(spec.Path), spec.MaxSizeMB, spec.MaxBackups)
+		if err != nil {
+			return nil, nil, err
+		}
+		out, closer = file, file
+		if format == "" || format == "auto" {
+			format = "text"
+		}
+	case "syslog":
+		writer, err := newSyslogWriter(spec.Address)
+		if err != nil {
+			return nil, nil, err
+		}
+		s.syslog = writer
+		s.mu = &sync.Mutex{}
+		out, closer = writer, writer
+		if format == "" || format == "auto" {
+			format = "text"
+		}
+	default:
+		return nil, nil, fmt.Errorf("unknown log sink type %q: expected stderr, stdout, file, or syslog", spec.Type)
+	}
+
+	handlerOpts := &slog.HandlerOptions{
+		Level:       slog.LevelDebug - 4,
+		ReplaceAttr: redactSecrets,
+	}
+	switch strings.ToLower(format) {
+	case "json":
+		s.handler = slog.NewJSONHandler(out, handlerOpts)
+	case "text":
+		s.handler = slog.NewTextHandler(out, handlerOpts)
+	case "", "auto":
+		s.handler = newAutoHandler(out, handlerOpts)
+	default:
+		if closer != nil {
+			_ = closer.Close()
+		}
+		return nil, nil, fmt.Errorf("unknown log format %q: expected text, json, or auto", format)
+	}
+
+	return s, closer, nil
+}
+
+// sink is one destination with its own minimum level.
+type sink struct {
+	handler  slog.Handler
+	minLevel slog.Level
+	// syslog sinks need the record level to pick a severity; mu keeps the
+	// level and the write together.
+	syslog *syslogWriter
+	mu     *sync.Mutex
+}
+
+func (s *sink) with(handler slog.Handler) *sink {
+	clone := *s
+	clone.handler = handler
+	return &clone
+}
+
+// routerHandler applies global and per-subsystem levels, then fans records
+// out to every sink.
+type routerHandler struct {
+	sinks     []*sink
+	subsystem string
+}
+
+func (h *routerHandler) Enabled(_ context.Context, level slog.Level) bool {
+	if h.subsystem != "" {
+		if min, ok := subsystemLevel(h.subsystem); ok {
+			return level >= min
+		}
+	}
+	return level >= globalLevel.Level()
+}
+
+func (h *routerHandler) Handle(ctx context.Context, r slog.Record) error {
+	var errs []error
+	for _, s := range h.sinks {
+		if r.Level < s.minLevel {
+			continue
+		}
+		if s.syslog != nil {
+			s.mu.Lock()
+			s.syslog.level = r.Level
+			errs = append(errs, s.handler.Handle(ctx, r.Clone()))
+			s.mu.Unlock()
+			continue
+		}
+		errs = append(errs, s.handler.Handle(ctx, r.Clone()))
+	}
+	return errors.Join(errs...)
+}
+
+func (h *routerHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	next := &routerHandler{subsystem: h.subsystem, sinks: make([]*sink, len(h.sinks))}
+	for _, attr := range attrs {
+		if attr.Key == SubsystemKey {
+			next.subsystem = strings.ToLower(attr.Value.String())
+		}
+	}
+	for i, s := range h.sinks {
+		next.sinks[i] = s.with(s.handler.WithAttrs(attrs))
+	}
+	return next
+}
+
+func (h *routerHandler) WithGroup(name string) slog.Handler {
+	next := &routerHandler{subsystem: h.subsystem, sinks: make([]*sink, len(h.sinks))}
+	for i, s := range h.sinks {
+		next.sinks[i] = s.with(s.handler.WithGroup(name))
+	}
+	return next
+}
+
+// newAutoHandler uses the themed handler on terminals and text otherwise.
+func newAutoHandler(out io.Writer, opts *slog.HandlerOptions) slog.Handler {
+	if isTerminalWriter(out) {
+		return NewThemeHandler(out, opts)
+	}
+	return slog.NewTextHandler(out, opts)
+}
@@ -0,0 +1,206 @@
+package logger
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// rotatingFile is an append-only log file that is rotated to path.1,
+// path.2, ... once it grows past maxSize.
+type rotatingFile struct {
+	path       string
+	maxSize    int64
+	maxBackups int
+	mu         sync.Mutex
+	file       *os.File
+	size       int64
+}
+
+func openRotatingFile(path string, maxSizeMB, maxBackups int) (*rotatingFile, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create log directory: %w", err)
+	}
+	f := &rotatingFile{
+		path:       path,
+		maxSize:    int64(maxSizeMB) * 1024 * 1024,
+		maxBackups: maxBackups,
+	}
+	if err := f.open(); err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+func (f *rotatingFile) open() error {
+	file, err := os.OpenFile(f.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o600)
+	if err != nil {
+		return fmt.Errorf("failed to open log file: %w", err)
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return fmt.Errorf("failed to stat log file: %w", err)
+	}
+	f.file = file
+	f.size = info.Size()
+	return nil
+}
+
+// Write appends p, rotating first when it would exceed the size limit.
+func (f *rotatingFile) Write(p []byte) (int, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.file == nil {
+		return 0, os.ErrClosed
+	}
+	if f.maxSize > 0 && f.size > 0 && f.size+int64(len(p)) > f.maxSize {
+		if err := f.rotate(); err != nil {
+			return 0, err
+		}
+	}
+	n, err := f.file.Write(p)
+	f.size += int64(n)
+	return n, err
+}
+
+func (f *rotatingFile) rotate() error {
+	if err := f.file.Close(); err != nil {
+		return fmt.Errorf("failed to close log file: %w", err)
+	}
+	f.file = nil
+
+	if f.maxBackups <= 0 {
+		if err := os.Remove(f.path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return fmt.Errorf("failed to truncate log file: %w", err)
+		}
+		return f.open()
+	}
+
+	for i := f.maxBackups - 1; i >= 1; i-- {
+		src := fmt.Sprintf("%s.%d", f.path, i)
+		if err := os.Rename(src, fmt.Sprintf("%s.%d", f.path, i+1)); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return fmt.Errorf("failed to rotate log file: %w", err)
+		}
+	}
+	if err := os.Rename(f.path, f.path+".1"); err != nil {
+		return fmt.Errorf("failed to rotate log file: %w", err)
+	}
+	return f.open()
+}
+
+// Close closes the current file.
+func (f *rotatingFile) Close() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.file == nil {
+		return nil
+	}
+	err := f.file.Close()
+	f.file = nil
+	return err
+}
+
+// syslogWriter sends each write as one RFC 5424 message to a remote syslog
+// server over UDP or TCP. It does not depend on log/syslog, which is not
+// available on Windows.
+type syslogWriter struct {
+	network  string
+	address  string
+	hostname string
+	level    slog.Level
+	mu       sync.Mutex
+	conn     net.Conn
+}
+
+func newSyslogWriter(address string) (*syslogWriter, error) {
+	network, host, ok := strings.Cut(address, "://")
+	if !ok {
+		network, host = "udp", address
+	}
+	if network != "udp" && network != "tcp" {
+		return nil, fmt.Errorf("syslog address %q must use udp:// or tcp://", address)
+	}
+	if host == "" {
+		return nil, fmt.Errorf("syslog sink requires an address")
+	}
+	if _, _, err := net.SplitHostPort(host); err != nil {
+		host = net.JoinHostPort(host, "514")
+	}
+
+	hostname, err := os.Hostname()
+	if err != nil || hostname == "" {
+		hostname = "-"
+	}
+	return &syslogWriter{network: network, address: host, hostname: hostname}, nil
+}
+
+// Write sends p with the severity of the record being written. Callers hold
+// the sink mutex while setting level and writing.
+func (w *syslogWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	msg := fmt.Sprintf("<%d>1 %s %s juleson %d - - %s",
+		8+syslogSeverity(w.level), // facility user
+		time.Now().Format(time.RFC3339),
+		w.hostname,
+		os.Getpid(),
+		strings.TrimRight(string(p), "\n"),
+	)
+	if w.network == "tcp" {
+		msg += "\n"
+	}
+
+	for attempt := 0; attempt < 2; attempt++ {
+		if w.conn == nil {
+			conn, err := net.DialTimeout(w.network, w.address, 5*time.Second)
+			if err != nil {
+				return 0, fmt.Errorf("failed to connect to syslog: %w", err)
+			}
+			w.conn = conn
+		}
+		if _, err := w.conn.Write([]byte(msg)); err != nil {
+			w.conn.Close()
+			w.conn = nil
+			continue
+		}
+		return len(p), nil
+	}
+	return 0, fmt.Errorf("failed to write to syslog at %s", w.address)
+}
+
+// Close closes the connection.
+func (w *syslogWriter) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.conn == nil {
+		return nil
+	}
+	err := w.conn.Close()
+	w.conn = nil
+	return err
+}
+
+func syslogSeverity(level slog.Level) int {
+	switch {
+	case level >= slog.LevelError:
+		return 3
+	case level >= slog.LevelWarn:
+		return 4
+	case level >= LevelSuccess:
+		return 5
+	case level >= slog.LevelInfo:
+		return 6
+	default:
+		return 7
+	}
+}
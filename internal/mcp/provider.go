@@ -1,9 +1,15 @@
 package jmcp
 
 import (
+	"context"
 	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
 
 	"github.com/SamyRai/go-jules"
+	julessessions "github.com/SamyRai/juleson/internal/jules/sessions"
+	"github.com/SamyRai/juleson/internal/policy"
 	"github.com/modelcontextprotocol/go-sdk/mcp"
 )
 
@@ -15,6 +21,17 @@ type ToolProvider interface {
 // clientFactory is a function type that returns a Jules client or an error if not configured.
 type clientFactory func() (*jules.Client, error)
 
+// auditFunc records a mutating tool call in the audit log, attributed to
+// the caller in ctx.
+type auditFunc func(ctx context.Context, action, target string, err error, details map[string]interface{})
+
+// policyFunc checks a risky tool call against the configured policy.
+type policyFunc func(check policy.Check) error
+
+// budgetFunc checks that creating n more sessions stays within the
+// configured budget.
+type budgetFunc func(n int) error
+
 // requireConfirm is a helper to ensure dangerous actions are confirmed.
 func requireConfirm(confirm bool, action string) error {
 	if !confirm {
@@ -28,6 +45,10 @@ func wrapAPIError(action string, err error) error {
 	if err == nil {
 		return nil
 	}
+	err = julessessions.Classify(err)
+	if hint := julessessions.Remediation(err); hint != "" {
+		return fmt.Errorf("Jules API error during %s: %w (%s)", action, err, hint)
+	}
 	return fmt.Errorf("Jules API error during %s: %w", action, err)
 }
 
@@ -39,6 +60,46 @@ func optionalString(value *string) string {
 	return *value
 }
 
+// projectDir resolves dir against the server working directory and rejects
+// paths outside it, including through symlinks. Empty dir means the working
+// directory.
+func projectDir(dir string) (string, error) {
+	root, err := os.Getwd()
+	if err != nil {
+		return "", err
+	}
+	if root, err = filepath.EvalSymlinks(root); err != nil {
+		return "", err
+	}
+	if dir == "" {
+		return root, nil
+	}
+	if !filepath.IsAbs(dir) {
+		dir = filepath.Join(root, dir)
+	}
+	resolved, err := filepath.EvalSymlinks(dir)
+	if err != nil {
+		return "", fmt.Errorf("invalid directory: %w", err)
+	}
+	if !within(root, resolved) {
+		return "", fmt.Errorf("directory %s is outside the working directory %s", dir, root)
+	}
+	info, err := os.Stat(resolved)
+	if err != nil {
+		return "", err
+	}
+	if !info.IsDir() {
+		return "", fmt.Errorf("%s is not a directory", dir)
+	}
+	return resolved, nil
+}
+
+// within reports whether path is root or below it.
+func within(root, path string) bool {
+	rel, err := filepath.Rel(root, path)
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
+
 type emptyInput struct{}
 
 type sessionIDInput struct {
@@ -46,8 +107,9 @@ type sessionIDInput struct {
 }
 
 type confirmSessionInput struct {
-	SessionID string `json:"session_id"`
-	Confirm   bool   `json:"confirm"`
+	ApprovalID *string `json:"approval_id,omitempty" jsonschema:"Approval ID from a second approver when policy requires one"`
+	SessionID  string  `json:"session_id"`
+	Confirm    bool    `json:"confirm"`
 }
 
 type actionOutput struct {
@@ -0,0 +1,133 @@
+package jmcp
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"time"
+
+	"github.com/SamyRai/juleson/internal/config"
+	"github.com/SamyRai/juleson/internal/intelligence"
+	"github.com/SamyRai/juleson/pkg/osv"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+type analyzeProvider struct {
+	cfg *config.Config
+}
+
+// NewAnalyzeProvider creates a ToolProvider for project dependency and code
+// health analysis.
+func NewAnalyzeProvider(cfg *config.Config) ToolProvider {
+	return &analyzeProvider{cfg: cfg}
+}
+
+func (p *analyzeProvider) Register(server *mcp.Server) {
+	mcp.AddTool(server, &mcp.Tool{
+		Name: "analyze_deps",
+		Description: "Audit go.mod, package.json, and requirements.txt dependencies: known vulnerabilities of pinned " +
+			"versions from OSV, and licenses checked against analysis.allowed_licenses or allowed_licenses.",
+	}, p.deps)
+	mcp.AddTool(server, &mcp.Tool{
+		Name: "analyze_hotspots",
+		Description: "Rank Go files by git churn times cyclomatic complexity, weighted for untested code when a " +
+			"go test cover profile is given, to prioritize refactoring.",
+	}, p.hotspots)
+	mcp.AddTool(server, &mcp.Tool{
+		Name: "analyze_project",
+		Description: "Summarize a project for planning: languages, frameworks from its manifests, test files, " +
+			"statement coverage from a go test cover profile, code smells, and possible committed secrets.",
+	}, p.project)
+}
+
+type analyzeDepsInput struct {
+	Dir             *string  `json:"dir,omitempty" jsonschema:"Project root, relative to the working directory"`
+	AllowedLicenses []string `json:"allowed_licenses,omitempty" jsonschema:"SPDX identifiers to allow instead of the configured list"`
+	Offline         bool     `json:"offline,omitempty" jsonschema:"Skip the OSV vulnerability lookup"`
+}
+
+func (p *analyzeProvider) deps(ctx context.Context, _ *mcp.CallToolRequest, in analyzeDepsInput) (*mcp.CallToolResult, *intelligence.DependencyAudit, error) {
+	dir, err := projectDir(optionalString(in.Dir))
+	if err != nil {
+		return nil, nil, err
+	}
+	options := intelligence.DependencyAuditOptions{AllowedLicenses: p.cfg.Analysis.AllowedLicenses}
+	if len(in.AllowedLicenses) > 0 {
+		options.AllowedLicenses = in.AllowedLicenses
+	}
+	if !in.Offline {
+		options.OSV = osv.NewClient(p.cfg.Analysis.OSVURL, nil)
+	}
+	audit, err := intelligence.AuditDependencies(ctx, dir, options)
+	if err != nil {
+		return nil, nil, err
+	}
+	if audit.Dependencies == nil {
+		audit.Dependencies = []intelligence.AuditedDependency{}
+	}
+	return nil, audit, nil
+}
+
+type analyzeHotspotsInput struct {
+	Dir          *string `json:"dir,omitempty" jsonschema:"Project root, relative to the working directory"`
+	CoverProfile *string `json:"cover_profile,omitempty" jsonschema:"go test -coverprofile file, relative to the project root"`
+	Days         int     `json:"days,omitempty" jsonschema:"Count churn over this many days; default 180, negative for all history"`
+	Top          int     `json:"top,omitempty" jsonschema:"Return only the top N hotspots; default 20"`
+}
+
+func (p *analyzeProvider) hotspots(ctx context.Context, _ *mcp.CallToolRequest, in analyzeHotspotsInput) (*mcp.CallToolResult, *intelligence.HotspotReport, error) {
+	dir, err := projectDir(optionalString(in.Dir))
+	if err != nil {
+		return nil, nil, err
+	}
+	options := intelligence.HotspotOptions{Limit: in.Top, Cache: intelligence.OpenProjectCache(dir)}
+	if options.Limit <= 0 {
+		options.Limit = 20
+	}
+	switch {
+	case in.Days == 0:
+		options.Since = time.Now().AddDate(0, 0, -180)
+	case in.Days > 0:
+		options.Since = time.Now().AddDate(0, 0, -in.Days)
+	}
+	if profile := optionalString(in.CoverProfile); profile != "" {
+		options.CoverProfile = filepath.Join(dir, profile)
+		if filepath.IsAbs(profile) || !within(dir, options.CoverProfile) {
+			return nil, nil, fmt.Errorf("cover_profile must be inside the project root")
+		}
+	}
+
+	report, err := intelligence.AnalyzeHotspots(ctx, dir, options)
+	if err != nil {
+		return nil, nil, err
+	}
+	_ = options.Cache.Save()
+	return nil, report, nil
+}
+
+type analyzeProjectInput struct {
+	Dir          *string `json:"dir,omitempty" jsonschema:"Project root, relative to the working directory"`
+	CoverProfile *string `json:"cover_profile,omitempty" jsonschema:"go test -coverprofile file, relative to the project root"`
+}
+
+func (p *analyzeProvider) project(ctx context.Context, _ *mcp.CallToolRequest, in analyzeProjectInput) (*mcp.CallToolResult, *intelligence.ProjectContext, error) {
+	dir, err := projectDir(optionalString(in.Dir))
+	if err != nil {
+		return nil, nil, err
+	}
+	smells := intelligence.DefaultSmellOptions()
+	options := intelligence.ProjectContextOptions{Cache: intelligence.OpenProjectCache(dir), Smells: &smells}
+	if profile := optionalString(in.CoverProfile); profile != "" {
+		options.CoverProfile = filepath.Join(dir, profile)
+		if filepath.IsAbs(profile) || !within(dir, options.CoverProfile) {
+			return nil, nil, fmt.Errorf("cover_profile must be inside the project root")
+		}
+	}
+
+	project, err := intelligence.AnalyzeProject(ctx, dir, options)
+	if err != nil {
+		return nil, nil, err
+	}
+	_ = options.Cache.Save()
+	return nil, project, nil
+}
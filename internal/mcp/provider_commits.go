@@ -0,0 +1,49 @@
+package jmcp
+
+import (
+	"context"
+	"errors"
+
+	"github.com/SamyRai/juleson/internal/commitmsg"
+	"github.com/SamyRai/juleson/internal/config"
+	"github.com/SamyRai/juleson/internal/jules/workspace"
+	"github.com/SamyRai/juleson/internal/presentation/cli/core"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+type commitsProvider struct {
+	cfg *config.Config
+	cf  clientFactory
+}
+
+// NewCommitsProvider creates a ToolProvider for commit messages and
+// CHANGELOG fragments written from session changes.
+func NewCommitsProvider(cfg *config.Config, cf clientFactory) ToolProvider {
+	return &commitsProvider{cfg: cfg, cf: cf}
+}
+
+func (p *commitsProvider) Register(server *mcp.Server) {
+	mcp.AddTool(server, &mcp.Tool{
+		Name: "generate_commit_messages",
+		Description: "Write a conventional-commit message for the changes of each Jules session and a CHANGELOG " +
+			"fragment covering all of them, with Gemini when it is configured.",
+	}, p.generate)
+}
+
+type generateCommitMessagesInput struct {
+	SessionIDs []string `json:"session_ids" jsonschema:"Jules sessions whose changes to describe"`
+	Only       []string `json:"only,omitempty" jsonschema:"Describe only changes to files matching these globs"`
+	NoAI       bool     `json:"no_ai,omitempty" jsonschema:"Derive the messages from the changes without Gemini"`
+}
+
+func (p *commitsProvider) generate(ctx context.Context, _ *mcp.CallToolRequest, in generateCommitMessagesInput) (*mcp.CallToolResult, *commitmsg.Result, error) {
+	if len(in.SessionIDs) == 0 {
+		return nil, nil, errors.New("session_ids is required")
+	}
+	client, err := p.cf()
+	if err != nil {
+		return nil, nil, err
+	}
+	result, err := core.DescribeSessions(ctx, p.cfg, client, in.SessionIDs, &workspace.PatchApplicationOptions{Only: in.Only}, !in.NoAI)
+	return nil, result, err
+}
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"time"
 
+	"github.com/SamyRai/juleson/internal/intelligence"
 	"github.com/SamyRai/juleson/pkg/build"
 	"github.com/SamyRai/juleson/pkg/builder"
 	"github.com/modelcontextprotocol/go-sdk/mcp"
@@ -32,6 +33,21 @@ func (p *devProvider) Register(server *mcp.Server) {
 		Name:        "dev_check",
 		Description: "Run Juleson quality checks through the builder service. Requires confirm=true because formatting may modify files.",
 	}, p.devCheck)
+	mcp.AddTool(server, &mcp.Tool{
+		Name: "dev_impact",
+		Description: "List the Go packages and JavaScript, TypeScript, and Python modules affected by changed files, " +
+			"with the test targets and commands scoped to them.",
+	}, p.devImpact)
+	mcp.AddTool(server, &mcp.Tool{
+		Name: "dev_smells",
+		Description: "Find Go functions over complexity, length, or parameter thresholds and unused exports of internal " +
+			"packages, from syntax alone. Thresholds default to 10, 80, and 5; a negative value disables the check.",
+	}, p.devSmells)
+	mcp.AddTool(server, &mcp.Tool{
+		Name: "dev_secrets",
+		Description: "Scan project files for likely leaked credentials such as cloud keys, API tokens, and private keys, " +
+			"using provider patterns plus an entropy check. Matches are redacted.",
+	}, p.devSecrets)
 }
 
 type devBuildInput struct {
@@ -115,3 +131,97 @@ func (p *devProvider) devCheck(ctx context.Context, _ *mcp.CallToolRequest, in d
 	})
 	return nil, summary, err
 }
+
+type devImpactInput struct {
+	Dir   *string  `json:"dir,omitempty" jsonschema:"Project root, relative to the working directory"`
+	Files []string `json:"files" jsonschema:"Changed files, relative to the project root"`
+}
+
+type devImpactOutput struct {
+	*intelligence.Impact
+	TestCommands []string `json:"test_commands"`
+}
+
+func (p *devProvider) devImpact(ctx context.Context, _ *mcp.CallToolRequest, in devImpactInput) (*mcp.CallToolResult, *devImpactOutput, error) {
+	dir, err := projectDir(optionalString(in.Dir))
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(in.Files) == 0 {
+		return nil, nil, fmt.Errorf("files are required")
+	}
+	cache := intelligence.OpenProjectCache(dir)
+	graph, err := intelligence.BuildProjectGraphCached(ctx, dir, cache)
+	if err != nil {
+		return nil, nil, err
+	}
+	_ = cache.Save()
+	impact := graph.ImpactAnalysis(in.Files)
+	return nil, &devImpactOutput{Impact: impact, TestCommands: impact.TestCommands()}, nil
+}
+
+type devSmellsInput struct {
+	Dir           *string `json:"dir,omitempty" jsonschema:"Project root, relative to the working directory"`
+	MaxComplexity int     `json:"max_complexity,omitempty"`
+	MaxLines      int     `json:"max_lines,omitempty"`
+	MaxParams     int     `json:"max_params,omitempty"`
+	SkipUnused    bool    `json:"skip_unused_exports,omitempty"`
+}
+
+type devSmellsOutput struct {
+	Smells []intelligence.Smell `json:"smells"`
+}
+
+func (p *devProvider) devSmells(ctx context.Context, _ *mcp.CallToolRequest, in devSmellsInput) (*mcp.CallToolResult, *devSmellsOutput, error) {
+	dir, err := projectDir(optionalString(in.Dir))
+	if err != nil {
+		return nil, nil, err
+	}
+	options := intelligence.DefaultSmellOptions()
+	for _, limit := range []struct {
+		value  int
+		target *int
+	}{{in.MaxComplexity, &options.MaxComplexity}, {in.MaxLines, &options.MaxLines}, {in.MaxParams, &options.MaxParams}} {
+		switch {
+		case limit.value < 0:
+			*limit.target = 0
+		case limit.value > 0:
+			*limit.target = limit.value
+		}
+	}
+	options.UnusedExports = !in.SkipUnused
+	options.Cache = intelligence.OpenProjectCache(dir)
+
+	smells, err := intelligence.DetectSmells(ctx, dir, options)
+	if err != nil {
+		return nil, nil, err
+	}
+	_ = options.Cache.Save()
+	if smells == nil {
+		smells = []intelligence.Smell{}
+	}
+	return nil, &devSmellsOutput{Smells: smells}, nil
+}
+
+type devSecretsInput struct {
+	Dir *string `json:"dir,omitempty" jsonschema:"Project root, relative to the working directory"`
+}
+
+type devSecretsOutput struct {
+	Findings []intelligence.SecretFinding `json:"findings"`
+}
+
+func (p *devProvider) devSecrets(ctx context.Context, _ *mcp.CallToolRequest, in devSecretsInput) (*mcp.CallToolResult, *devSecretsOutput, error) {
+	dir, err := projectDir(optionalString(in.Dir))
+	if err != nil {
+		return nil, nil, err
+	}
+	findings, err := intelligence.ScanProjectSecrets(ctx, dir)
+	if err != nil {
+		return nil, nil, err
+	}
+	if findings == nil {
+		findings = []intelligence.SecretFinding{}
+	}
+	return nil, &devSecretsOutput{Findings: findings}, nil
+}
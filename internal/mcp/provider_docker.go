@@ -0,0 +1,219 @@
+package jmcp
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"regexp"
+	"time"
+
+	"github.com/SamyRai/juleson/internal/policy"
+	"github.com/SamyRai/juleson/internal/presentation/cli/core"
+	"github.com/SamyRai/juleson/internal/sandbox"
+	"github.com/SamyRai/juleson/pkg/docker"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// sandboxFactory returns the sandbox built from the current configuration.
+type sandboxFactory func() (*sandbox.Sandbox, error)
+
+// Limits for following container logs.
+const (
+	defaultLogFollow = time.Minute
+	maxLogFollow     = 30 * time.Minute
+	maxLogLines      = 1000
+)
+
+var (
+	containerRef = regexp.MustCompile(`^[a-zA-Z0-9][a-zA-Z0-9_.-]*$`)
+	logTail      = regexp.MustCompile(`^(all|[0-9]+)$`)
+)
+
+type dockerProvider struct {
+	sandbox sandboxFactory
+	docker  func() (*docker.Client, error)
+	audit   auditFunc
+	policy  policyFunc
+}
+
+// NewDockerProvider creates a ToolProvider for sandboxed container runs and
+// container logs. Every run is checked against the docker_exec policy and audited; audit and
+// enforce may be nil.
+func NewDockerProvider(sf sandboxFactory, audit auditFunc, enforce policyFunc) ToolProvider {
+	if audit == nil {
+		audit = func(context.Context, string, string, error, map[string]interface{}) {}
+	}
+	if enforce == nil {
+		enforce = func(policy.Check) error { return nil }
+	}
+	return &dockerProvider{sandbox: sf, docker: docker.NewClient, audit: audit, policy: enforce}
+}
+
+func (p *dockerProvider) Register(server *mcp.Server) {
+	mcp.AddTool(server, &mcp.Tool{
+		Name: "docker_run",
+		Description: "Run an allow-listed command in an allow-listed Docker image with a project directory mounted at /workspace. " +
+			"Containers have no network unless sandbox.network allows it and run with CPU, memory, and time limits. Requires confirm=true.",
+	}, p.dockerRun)
+	mcp.AddTool(server, &mcp.Tool{
+		Name: "docker_logs",
+		Description: "Read a container's logs, optionally following them. Each line is sent as a progress notification while it arrives; " +
+			"the result holds the last lines. Stderr lines are prefixed with \"stderr: \".",
+	}, p.dockerLogs)
+}
+
+type dockerRunInput struct {
+	ApprovalID     *string           `json:"approval_id,omitempty" jsonschema:"Approval ID from a second approver when policy requires one"`
+	Dir            *string           `json:"dir,omitempty" jsonschema:"Directory to mount at /workspace, inside the server working directory"`
+	Env            map[string]string `json:"env,omitempty"`
+	Image          string            `json:"image" jsonschema:"Allow-listed image such as golang:1.23"`
+	Command        []string          `json:"command" jsonschema:"Executable and arguments; the executable must be allow-listed"`
+	TimeoutSeconds int               `json:"timeout_seconds,omitempty"`
+	Network        bool              `json:"network,omitempty"`
+	Confirm        bool              `json:"confirm"`
+}
+
+func (p *dockerProvider) dockerRun(ctx context.Context, _ *mcp.CallToolRequest, in dockerRunInput) (*mcp.CallToolResult, *sandbox.Result, error) {
+	if err := requireConfirm(in.Confirm, "docker_run"); err != nil {
+		return nil, nil, err
+	}
+	sb, err := p.sandbox()
+	if err != nil {
+		return nil, nil, err
+	}
+	spec := sandbox.Spec{
+		Image:   in.Image,
+		Command: in.Command,
+		Dir:     optionalString(in.Dir),
+		Env:     in.Env,
+		Network: in.Network,
+		Timeout: time.Duration(in.TimeoutSeconds) * time.Second,
+	}
+	if err := sb.Validate(spec); err != nil {
+		return nil, nil, err
+	}
+	err = p.policy(policy.Check{
+		Request:    policy.Request{Operation: policy.OpDockerExec, Tool: "docker_run", Target: in.Image},
+		Confirmed:  true,
+		ApprovalID: optionalString(in.ApprovalID),
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	result, err := sb.Run(ctx, spec)
+	details := map[string]interface{}{"command": in.Command, "network": in.Network}
+	if result != nil {
+		details["exit_code"] = result.ExitCode
+		details["timed_out"] = result.TimedOut
+	}
+	p.audit(ctx, core.AuditDockerRun, in.Image, err, details)
+	return nil, result, err
+}
+
+type dockerLogsInput struct {
+	Since          *string `json:"since,omitempty" jsonschema:"Only logs after this time: a duration such as 10m, RFC3339, or a Unix timestamp"`
+	Tail           *string `json:"tail,omitempty" jsonschema:"Number of lines from the end of the logs, or all"`
+	Container      string  `json:"container" jsonschema:"Container name or ID"`
+	TimeoutSeconds int     `json:"timeout_seconds,omitempty" jsonschema:"How long to follow, default 60 and at most 1800"`
+	Follow         bool    `json:"follow,omitempty"`
+	Timestamps     bool    `json:"timestamps,omitempty"`
+}
+
+type dockerLogsOutput struct {
+	Container string   `json:"container"`
+	Lines     []string `json:"lines"`
+	// Truncated is set when earlier lines were dropped from the result.
+	Truncated bool `json:"truncated"`
+	// Stopped is set when following ended at the timeout rather than when
+	// the container exited.
+	Stopped bool `json:"stopped"`
+}
+
+func (p *dockerProvider) dockerLogs(ctx context.Context, req *mcp.CallToolRequest, in dockerLogsInput) (*mcp.CallToolResult, *dockerLogsOutput, error) {
+	if !containerRef.MatchString(in.Container) {
+		return nil, nil, fmt.Errorf("invalid container %q", in.Container)
+	}
+	tail := optionalString(in.Tail)
+	if tail != "" && !logTail.MatchString(tail) {
+		return nil, nil, fmt.Errorf("tail must be a number of lines or all")
+	}
+	timeout := defaultLogFollow
+	if in.TimeoutSeconds > 0 {
+		timeout = min(time.Duration(in.TimeoutSeconds)*time.Second, maxLogFollow)
+	}
+
+	cli, err := p.docker()
+	if err != nil {
+		return nil, nil, err
+	}
+	defer cli.Close()
+
+	out := &dockerLogsOutput{Container: in.Container, Lines: []string{}}
+	var progressToken any
+	if req != nil && req.Params != nil {
+		progressToken = req.Params.GetProgressToken()
+	}
+	emit := func(line string) {
+		out.Lines = append(out.Lines, line)
+		if len(out.Lines) > maxLogLines {
+			out.Lines = out.Lines[1:]
+			out.Truncated = true
+		}
+		if progressToken != nil && req.Session != nil {
+			_ = req.Session.NotifyProgress(ctx, &mcp.ProgressNotificationParams{
+				ProgressToken: progressToken,
+				Message:       line,
+				Progress:      float64(len(out.Lines)),
+			})
+		}
+	}
+	stdout := &lineWriter{emit: emit}
+	stderr := &lineWriter{prefix: "stderr: ", emit: emit}
+
+	logCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	err = cli.Logs(logCtx, in.Container, docker.LogsOptions{
+		Follow:     in.Follow,
+		Since:      optionalString(in.Since),
+		Tail:       tail,
+		Timestamps: in.Timestamps,
+	}, stdout, stderr)
+	stdout.Flush()
+	stderr.Flush()
+	if err != nil && ctx.Err() == nil && logCtx.Err() != nil {
+		out.Stopped = true
+		return nil, out, nil
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	return nil, out, nil
+}
+
+// lineWriter calls emit for each complete line written to it.
+type lineWriter struct {
+	prefix string
+	emit   func(line string)
+	buf    []byte
+}
+
+func (w *lineWriter) Write(p []byte) (int, error) {
+	w.buf = append(w.buf, p...)
+	for {
+		i := bytes.IndexByte(w.buf, '\n')
+		if i < 0 {
+			return len(p), nil
+		}
+		w.emit(w.prefix + string(bytes.TrimSuffix(w.buf[:i], []byte("\r"))))
+		w.buf = w.buf[i+1:]
+	}
+}
+
+// Flush emits a final line that did not end in a newline.
+func (w *lineWriter) Flush() {
+	if len(w.buf) > 0 {
+		w.emit(w.prefix + string(w.buf))
+		w.buf = nil
+	}
+}
@@ -0,0 +1,284 @@
+package jmcp
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/SamyRai/juleson/internal/presentation/cli/core"
+	"github.com/SamyRai/juleson/pkg/git"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// maxLogCount caps git_log results.
+const maxLogCount = 200
+
+type gitProvider struct {
+	audit auditFunc
+}
+
+// NewGitProvider creates a ToolProvider for managing the local working tree
+// around patch application. Commits, checkouts, branch creation, and stash
+// changes are audited; audit may be nil.
+func NewGitProvider(audit auditFunc) ToolProvider {
+	if audit == nil {
+		audit = func(context.Context, string, string, error, map[string]interface{}) {}
+	}
+	return &gitProvider{audit: audit}
+}
+
+func (p *gitProvider) Register(server *mcp.Server) {
+	mcp.AddTool(server, &mcp.Tool{
+		Name:        "git_status",
+		Description: "Show the current branch, upstream tracking, and changed, staged, and untracked files.",
+	}, p.status)
+	mcp.AddTool(server, &mcp.Tool{
+		Name:        "git_diff",
+		Description: "Show a unified diff of unstaged changes, staged changes, or changes against a commit.",
+	}, p.diff)
+	mcp.AddTool(server, &mcp.Tool{
+		Name:        "git_branch",
+		Description: "List local branches, or create a branch without switching to it.",
+	}, p.branch)
+	mcp.AddTool(server, &mcp.Tool{
+		Name:        "git_commit",
+		Description: "Stage the given paths and commit. Hooks do not run. Requires confirm=true.",
+	}, p.commit)
+	mcp.AddTool(server, &mcp.Tool{
+		Name:        "git_checkout",
+		Description: "Switch to a branch, create one, or detach at a commit. Refuses while tracked files have local changes or an untracked file is in the way. Requires confirm=true.",
+	}, p.checkout)
+	mcp.AddTool(server, &mcp.Tool{
+		Name:        "git_stash",
+		Description: "List, push, pop, apply, or drop stashes. Everything except list requires confirm=true.",
+	}, p.stash)
+	mcp.AddTool(server, &mcp.Tool{
+		Name:        "git_log",
+		Description: "List recent commits, optionally for a ref or paths.",
+	}, p.log)
+}
+
+// repo opens the working tree containing dir, which must be inside the
+// server working directory.
+func (p *gitProvider) repo(ctx context.Context, dir *string) (*git.Repo, error) {
+	resolved, err := projectDir(optionalString(dir))
+	if err != nil {
+		return nil, err
+	}
+	return git.Open(ctx, resolved)
+}
+
+type gitDirInput struct {
+	Dir *string `json:"dir,omitempty" jsonschema:"Directory inside the repository, relative to the working directory"`
+}
+
+func (p *gitProvider) status(ctx context.Context, _ *mcp.CallToolRequest, in gitDirInput) (*mcp.CallToolResult, *git.Status, error) {
+	repo, err := p.repo(ctx, in.Dir)
+	if err != nil {
+		return nil, nil, err
+	}
+	status, err := repo.Status(ctx)
+	return nil, status, err
+}
+
+type gitDiffInput struct {
+	Dir          *string  `json:"dir,omitempty" jsonschema:"Directory inside the repository, relative to the working directory"`
+	Base         *string  `json:"base,omitempty" jsonschema:"Commit or branch to compare against"`
+	Paths        []string `json:"paths,omitempty"`
+	ContextLines int      `json:"context_lines,omitempty"`
+	Staged       bool     `json:"staged,omitempty"`
+}
+
+type gitDiffOutput struct {
+	Diff      string `json:"diff"`
+	Truncated bool   `json:"truncated"`
+}
+
+func (p *gitProvider) diff(ctx context.Context, _ *mcp.CallToolRequest, in gitDiffInput) (*mcp.CallToolResult, *gitDiffOutput, error) {
+	repo, err := p.repo(ctx, in.Dir)
+	if err != nil {
+		return nil, nil, err
+	}
+	diff, truncated, err := repo.Diff(ctx, git.DiffOptions{
+		Staged:  in.Staged,
+		Base:    optionalString(in.Base),
+		Paths:   in.Paths,
+		Context: in.ContextLines,
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	return nil, &gitDiffOutput{Diff: diff, Truncated: truncated}, nil
+}
+
+type gitBranchInput struct {
+	Dir    *string `json:"dir,omitempty" jsonschema:"Directory inside the repository, relative to the working directory"`
+	Create *string `json:"create,omitempty" jsonschema:"Name of a branch to create"`
+	Start  *string `json:"start,omitempty" jsonschema:"Commit the new branch starts at, default HEAD"`
+}
+
+type gitBranchOutput struct {
+	Branches []git.Branch `json:"branches"`
+}
+
+func (p *gitProvider) branch(ctx context.Context, _ *mcp.CallToolRequest, in gitBranchInput) (*mcp.CallToolResult, *gitBranchOutput, error) {
+	repo, err := p.repo(ctx, in.Dir)
+	if err != nil {
+		return nil, nil, err
+	}
+	if name := optionalString(in.Create); name != "" {
+		err := repo.CreateBranch(ctx, name, optionalString(in.Start))
+		p.audit(ctx, core.AuditGitBranch, name, err, map[string]interface{}{"repo": repo.Root, "start": optionalString(in.Start)})
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+	branches, err := repo.Branches(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	return nil, &gitBranchOutput{Branches: branches}, nil
+}
+
+type gitCommitInput struct {
+	Dir        *string  `json:"dir,omitempty" jsonschema:"Directory inside the repository, relative to the working directory"`
+	Message    string   `json:"message"`
+	Paths      []string `json:"paths,omitempty" jsonschema:"Paths to stage before committing; empty commits what is already staged"`
+	All        bool     `json:"all,omitempty" jsonschema:"Also stage all modified and deleted tracked files"`
+	AllowEmpty bool     `json:"allow_empty,omitempty"`
+	Confirm    bool     `json:"confirm"`
+}
+
+type gitCommitOutput struct {
+	Commit string `json:"commit"`
+}
+
+func (p *gitProvider) commit(ctx context.Context, _ *mcp.CallToolRequest, in gitCommitInput) (*mcp.CallToolResult, *gitCommitOutput, error) {
+	if err := requireConfirm(in.Confirm, "git_commit"); err != nil {
+		return nil, nil, err
+	}
+	repo, err := p.repo(ctx, in.Dir)
+	if err != nil {
+		return nil, nil, err
+	}
+	hash, err := repo.Commit(ctx, git.CommitOptions{
+		Message:    in.Message,
+		Paths:      in.Paths,
+		All:        in.All,
+		AllowEmpty: in.AllowEmpty,
+	})
+	p.audit(ctx, core.AuditGitCommit, repo.Root, err, map[string]interface{}{"commit": hash, "paths": in.Paths, "all": in.All})
+	if err != nil {
+		return nil, nil, err
+	}
+	return nil, &gitCommitOutput{Commit: hash}, nil
+}
+
+type gitCheckoutInput struct {
+	Dir     *string `json:"dir,omitempty" jsonschema:"Directory inside the repository, relative to the working directory"`
+	Start   *string `json:"start,omitempty" jsonschema:"Start commit when creating a branch, default HEAD"`
+	Ref     string  `json:"ref" jsonschema:"Branch to switch to or create, or a commit when detach is set"`
+	Create  bool    `json:"create,omitempty"`
+	Detach  bool    `json:"detach,omitempty"`
+	Confirm bool    `json:"confirm"`
+}
+
+func (p *gitProvider) checkout(ctx context.Context, _ *mcp.CallToolRequest, in gitCheckoutInput) (*mcp.CallToolResult, *git.Status, error) {
+	if err := requireConfirm(in.Confirm, "git_checkout"); err != nil {
+		return nil, nil, err
+	}
+	if in.Create && in.Detach {
+		return nil, nil, fmt.Errorf("create and detach cannot be combined")
+	}
+	repo, err := p.repo(ctx, in.Dir)
+	if err != nil {
+		return nil, nil, err
+	}
+	err = repo.Checkout(ctx, git.CheckoutOptions{Ref: in.Ref, Create: in.Create, Start: optionalString(in.Start), Detach: in.Detach})
+	p.audit(ctx, core.AuditGitCheckout, in.Ref, err, map[string]interface{}{"repo": repo.Root, "create": in.Create, "detach": in.Detach})
+	if err != nil {
+		return nil, nil, err
+	}
+	status, err := repo.Status(ctx)
+	return nil, status, err
+}
+
+type gitStashInput struct {
+	Dir              *string `json:"dir,omitempty" jsonschema:"Directory inside the repository, relative to the working directory"`
+	Message          *string `json:"message,omitempty" jsonschema:"Message for push"`
+	Stash            *string `json:"stash,omitempty" jsonschema:"Stash for pop, apply, or drop such as stash@{1}; default the latest"`
+	Action           string  `json:"action" jsonschema:"list, push, pop, apply, or drop"`
+	IncludeUntracked bool    `json:"include_untracked,omitempty"`
+	Confirm          bool    `json:"confirm,omitempty"`
+}
+
+type gitStashOutput struct {
+	// Stashed is set when push saved changes; it is false for a clean tree.
+	Stashed bool        `json:"stashed"`
+	Stashes []git.Stash `json:"stashes"`
+}
+
+func (p *gitProvider) stash(ctx context.Context, _ *mcp.CallToolRequest, in gitStashInput) (*mcp.CallToolResult, *gitStashOutput, error) {
+	switch in.Action {
+	case "list":
+	case "push", "pop", "apply", "drop":
+		if err := requireConfirm(in.Confirm, "git_stash "+in.Action); err != nil {
+			return nil, nil, err
+		}
+	default:
+		return nil, nil, fmt.Errorf("action must be list, push, pop, apply, or drop")
+	}
+	repo, err := p.repo(ctx, in.Dir)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	out := &gitStashOutput{}
+	ref := optionalString(in.Stash)
+	switch in.Action {
+	case "push":
+		out.Stashed, err = repo.StashPush(ctx, optionalString(in.Message), in.IncludeUntracked)
+	case "pop":
+		err = repo.StashPop(ctx, ref)
+	case "apply":
+		err = repo.StashApply(ctx, ref)
+	case "drop":
+		err = repo.StashDrop(ctx, ref)
+	}
+	if in.Action != "list" {
+		p.audit(ctx, core.AuditGitStash, repo.Root, err, map[string]interface{}{"action": in.Action, "stash": ref})
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	if out.Stashes, err = repo.Stashes(ctx); err != nil {
+		return nil, nil, err
+	}
+	return nil, out, nil
+}
+
+type gitLogInput struct {
+	Dir      *string  `json:"dir,omitempty" jsonschema:"Directory inside the repository, relative to the working directory"`
+	Ref      *string  `json:"ref,omitempty" jsonschema:"Branch or commit to start from, default HEAD"`
+	Paths    []string `json:"paths,omitempty"`
+	MaxCount int      `json:"max_count,omitempty" jsonschema:"Number of commits, default 20 and at most 200"`
+}
+
+type gitLogOutput struct {
+	Commits []git.Commit `json:"commits"`
+}
+
+func (p *gitProvider) log(ctx context.Context, _ *mcp.CallToolRequest, in gitLogInput) (*mcp.CallToolResult, *gitLogOutput, error) {
+	repo, err := p.repo(ctx, in.Dir)
+	if err != nil {
+		return nil, nil, err
+	}
+	commits, err := repo.Log(ctx, git.LogOptions{
+		Ref:      optionalString(in.Ref),
+		Paths:    in.Paths,
+		MaxCount: min(in.MaxCount, maxLogCount),
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	return nil, &gitLogOutput{Commits: commits}, nil
+}
@@ -0,0 +1,59 @@
+package jmcp
+
+import (
+	"context"
+
+	"github.com/SamyRai/juleson/internal/config"
+	"github.com/SamyRai/juleson/internal/presentation/cli/core"
+	"github.com/SamyRai/juleson/internal/report"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+type historyProvider struct {
+	cfg *config.Config
+	cf  clientFactory
+}
+
+// NewHistoryProvider creates a ToolProvider for the run history.
+func NewHistoryProvider(cfg *config.Config, cf clientFactory) ToolProvider {
+	return &historyProvider{cfg: cfg, cf: cf}
+}
+
+func (p *historyProvider) Register(server *mcp.Server) {
+	mcp.AddTool(server, &mcp.Tool{
+		Name: "get_run_report",
+		Description: "Report on a split template run from the run history, as Markdown or HTML: outcome, per-task " +
+			"results, each session's latest plan, diff stats, and review findings, audited decisions, and links to " +
+			"the sessions and pull requests.",
+	}, p.runReport)
+}
+
+type runReportInput struct {
+	RunID  *string `json:"run_id,omitempty" jsonschema:"Run ID from the history; defaults to the most recent run"`
+	Format *string `json:"format,omitempty" jsonschema:"markdown (default) or html"`
+}
+
+type runReportOutput struct {
+	RunID  string `json:"run_id"`
+	Format string `json:"format"`
+	Report string `json:"report"`
+}
+
+func (p *historyProvider) runReport(ctx context.Context, _ *mcp.CallToolRequest, in runReportInput) (*mcp.CallToolResult, runReportOutput, error) {
+	run, err := core.LoadRunRecord(ctx, p.cfg, optionalString(in.RunID))
+	if err != nil {
+		return nil, runReportOutput{}, err
+	}
+	format := optionalString(in.Format)
+	if format == "" {
+		format = report.FormatMarkdown
+	}
+	// Without a Jules client the report leaves out plans, changes, and
+	// review findings.
+	client, _ := p.cf()
+	content, err := core.BuildRunReport(ctx, p.cfg, client, run).Render(format)
+	if err != nil {
+		return nil, runReportOutput{}, err
+	}
+	return nil, runReportOutput{RunID: run.ID, Format: format, Report: content}, nil
+}
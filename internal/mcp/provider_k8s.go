@@ -0,0 +1,338 @@
+package jmcp
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"regexp"
+	"time"
+
+	"github.com/SamyRai/juleson/internal/config"
+	"github.com/SamyRai/juleson/internal/policy"
+	"github.com/SamyRai/juleson/internal/presentation/cli/core"
+	"github.com/SamyRai/juleson/pkg/k8s"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// Limits for Kubernetes tool inputs and outputs.
+const (
+	maxManifestBytes = 1 << 20
+	maxPodLogBytes   = 1 << 20
+)
+
+var (
+	k8sNamespace = regexp.MustCompile(`^[a-z0-9]([-a-z0-9]*[a-z0-9])?$`)
+	k8sName      = regexp.MustCompile(`^[a-z0-9]([-a-z0-9.]*[a-z0-9])?$`)
+)
+
+type k8sProvider struct {
+	cfg    *config.Config
+	audit  auditFunc
+	policy policyFunc
+}
+
+// NewK8sProvider creates a ToolProvider for applying manifests and checking
+// workloads in the configured Kubernetes contexts. Applies and restarts are
+// checked against the k8s_apply and k8s_restart policies and audited; audit
+// and enforce may be nil.
+func NewK8sProvider(cfg *config.Config, audit auditFunc, enforce policyFunc) ToolProvider {
+	if audit == nil {
+		audit = func(context.Context, string, string, error, map[string]interface{}) {}
+	}
+	if enforce == nil {
+		enforce = func(policy.Check) error { return nil }
+	}
+	return &k8sProvider{cfg: cfg, audit: audit, policy: enforce}
+}
+
+func (p *k8sProvider) Register(server *mcp.Server) {
+	mcp.AddTool(server, &mcp.Tool{
+		Name:        "k8s_contexts",
+		Description: "List kubeconfig contexts and which ones Kubernetes tools may use.",
+	}, p.contexts)
+	mcp.AddTool(server, &mcp.Tool{
+		Name: "k8s_apply",
+		Description: "Apply Kubernetes YAML or JSON manifests with server-side apply. " +
+			"dry_run=true validates them against the cluster without changes; otherwise requires confirm=true.",
+	}, p.apply)
+	mcp.AddTool(server, &mcp.Tool{
+		Name:        "k8s_pods",
+		Description: "List pods with phase, readiness, restarts, and the reason a pod is not healthy.",
+	}, p.pods)
+	mcp.AddTool(server, &mcp.Tool{
+		Name:        "k8s_logs",
+		Description: "Read a pod's logs, optionally from the previous container instance.",
+	}, p.logs)
+	mcp.AddTool(server, &mcp.Tool{
+		Name:        "k8s_rollout_restart",
+		Description: "Restart a deployment's pods like kubectl rollout restart. Requires confirm=true.",
+	}, p.rolloutRestart)
+}
+
+// client connects to name, or the default context when empty, if config
+// allows it.
+func (p *k8sProvider) client(name string) (*k8s.Client, error) {
+	if !p.cfg.Kubernetes.ContextAllowed(name) && name != p.defaultContext() {
+		return nil, fmt.Errorf("kubernetes context %q is not allowed; add it to kubernetes.contexts", name)
+	}
+	return k8s.NewClient(p.cfg.Kubernetes.ClientOptions(name))
+}
+
+// defaultContext is kubernetes.context, or the kubeconfig's current context.
+func (p *k8sProvider) defaultContext() string {
+	if p.cfg.Kubernetes.Context != "" {
+		return p.cfg.Kubernetes.Context
+	}
+	_, current, _ := k8s.Contexts(p.cfg.Kubernetes.Kubeconfig)
+	return current
+}
+
+func validNamespace(namespace string) error {
+	if namespace != "" && !k8sNamespace.MatchString(namespace) {
+		return fmt.Errorf("invalid namespace %q", namespace)
+	}
+	return nil
+}
+
+type k8sContext struct {
+	Name    string `json:"name"`
+	Allowed bool   `json:"allowed"`
+}
+
+type k8sContextsOutput struct {
+	Kubeconfig     string       `json:"kubeconfig"`
+	CurrentContext string       `json:"current_context"`
+	DefaultContext string       `json:"default_context"`
+	Contexts       []k8sContext `json:"contexts"`
+}
+
+func (p *k8sProvider) contexts(context.Context, *mcp.CallToolRequest, emptyInput) (*mcp.CallToolResult, *k8sContextsOutput, error) {
+	path := k8s.KubeconfigPath(p.cfg.Kubernetes.Kubeconfig)
+	names, current, err := k8s.Contexts(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	out := &k8sContextsOutput{Kubeconfig: path, CurrentContext: current, DefaultContext: p.defaultContext()}
+	for _, name := range names {
+		out.Contexts = append(out.Contexts, k8sContext{
+			Name:    name,
+			Allowed: name == out.DefaultContext || p.cfg.Kubernetes.ContextAllowed(name),
+		})
+	}
+	return nil, out, nil
+}
+
+type k8sApplyInput struct {
+	ApprovalID *string `json:"approval_id,omitempty" jsonschema:"Approval ID from a second approver when policy requires one"`
+	Context    *string `json:"context,omitempty" jsonschema:"Kubeconfig context, default from kubernetes.context"`
+	Namespace  *string `json:"namespace,omitempty" jsonschema:"Namespace for objects without metadata.namespace"`
+	Manifest   string  `json:"manifest" jsonschema:"YAML or JSON manifests, multiple documents separated by ---"`
+	DryRun     bool    `json:"dry_run,omitempty"`
+	Force      bool    `json:"force,omitempty" jsonschema:"Take over fields owned by another field manager"`
+	Confirm    bool    `json:"confirm,omitempty"`
+}
+
+type k8sApplyOutput struct {
+	Context string              `json:"context"`
+	DryRun  bool                `json:"dry_run"`
+	Objects []k8s.AppliedObject `json:"objects"`
+}
+
+func (p *k8sProvider) apply(ctx context.Context, _ *mcp.CallToolRequest, in k8sApplyInput) (*mcp.CallToolResult, *k8sApplyOutput, error) {
+	if !in.DryRun {
+		if err := requireConfirm(in.Confirm, "k8s_apply"); err != nil {
+			return nil, nil, err
+		}
+	}
+	if len(in.Manifest) > maxManifestBytes {
+		return nil, nil, fmt.Errorf("manifest is larger than %d bytes", maxManifestBytes)
+	}
+	namespace := optionalString(in.Namespace)
+	if err := validNamespace(namespace); err != nil {
+		return nil, nil, err
+	}
+	objects, err := k8s.ParseManifests([]byte(in.Manifest))
+	if err != nil {
+		return nil, nil, err
+	}
+	cli, err := p.client(optionalString(in.Context))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	opts := k8s.ApplyOptions{Namespace: namespace, DryRun: in.DryRun, Force: in.Force}
+	if in.DryRun {
+		applied, err := cli.Apply(ctx, objects, opts)
+		if err != nil {
+			return nil, nil, err
+		}
+		return nil, &k8sApplyOutput{Context: cli.Context(), DryRun: true, Objects: applied}, nil
+	}
+
+	err = p.policy(policy.Check{
+		Request:    policy.Request{Operation: policy.OpK8sApply, Tool: "k8s_apply", Target: cli.Context()},
+		Confirmed:  true,
+		ApprovalID: optionalString(in.ApprovalID),
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	applied, err := cli.Apply(ctx, objects, opts)
+	names := make([]string, 0, len(applied))
+	for _, obj := range applied {
+		names = append(names, obj.String())
+	}
+	p.audit(ctx, core.AuditK8sApply, cli.Context(), err, map[string]interface{}{"objects": names, "force": in.Force})
+	if err != nil {
+		return nil, nil, err
+	}
+	return nil, &k8sApplyOutput{Context: cli.Context(), Objects: applied}, nil
+}
+
+type k8sPodsInput struct {
+	Context   *string `json:"context,omitempty" jsonschema:"Kubeconfig context, default from kubernetes.context"`
+	Namespace *string `json:"namespace,omitempty"`
+	Selector  *string `json:"selector,omitempty" jsonschema:"Label selector such as app=api"`
+	Name      *string `json:"name,omitempty" jsonschema:"Only this pod"`
+}
+
+type k8sPodsOutput struct {
+	Context   string          `json:"context"`
+	Namespace string          `json:"namespace"`
+	Pods      []k8s.PodStatus `json:"pods"`
+}
+
+func (p *k8sProvider) pods(ctx context.Context, _ *mcp.CallToolRequest, in k8sPodsInput) (*mcp.CallToolResult, *k8sPodsOutput, error) {
+	namespace := optionalString(in.Namespace)
+	if err := validNamespace(namespace); err != nil {
+		return nil, nil, err
+	}
+	cli, err := p.client(optionalString(in.Context))
+	if err != nil {
+		return nil, nil, err
+	}
+	if namespace == "" {
+		namespace = cli.Namespace()
+	}
+	pods, err := cli.Pods(ctx, namespace, k8s.PodListOptions{
+		LabelSelector: optionalString(in.Selector),
+		Name:          optionalString(in.Name),
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	return nil, &k8sPodsOutput{Context: cli.Context(), Namespace: namespace, Pods: pods}, nil
+}
+
+type k8sLogsInput struct {
+	Context      *string `json:"context,omitempty" jsonschema:"Kubeconfig context, default from kubernetes.context"`
+	Namespace    *string `json:"namespace,omitempty"`
+	Container    *string `json:"container,omitempty" jsonschema:"Container name, required for pods with several containers"`
+	Pod          string  `json:"pod"`
+	TailLines    int     `json:"tail_lines,omitempty"`
+	SinceSeconds int     `json:"since_seconds,omitempty"`
+	Previous     bool    `json:"previous,omitempty" jsonschema:"Logs of the previous, crashed container instance"`
+	Timestamps   bool    `json:"timestamps,omitempty"`
+}
+
+type k8sLogsOutput struct {
+	Context   string `json:"context"`
+	Namespace string `json:"namespace"`
+	Pod       string `json:"pod"`
+	Logs      string `json:"logs"`
+	// Truncated is set when the logs reached the size limit.
+	Truncated bool `json:"truncated"`
+}
+
+func (p *k8sProvider) logs(ctx context.Context, _ *mcp.CallToolRequest, in k8sLogsInput) (*mcp.CallToolResult, *k8sLogsOutput, error) {
+	namespace := optionalString(in.Namespace)
+	if err := validNamespace(namespace); err != nil {
+		return nil, nil, err
+	}
+	if !k8sName.MatchString(in.Pod) {
+		return nil, nil, fmt.Errorf("invalid pod %q", in.Pod)
+	}
+	cli, err := p.client(optionalString(in.Context))
+	if err != nil {
+		return nil, nil, err
+	}
+	if namespace == "" {
+		namespace = cli.Namespace()
+	}
+
+	var buf bytes.Buffer
+	err = cli.Logs(ctx, namespace, in.Pod, k8s.LogsOptions{
+		Container:  optionalString(in.Container),
+		TailLines:  in.TailLines,
+		Since:      time.Duration(in.SinceSeconds) * time.Second,
+		Previous:   in.Previous,
+		Timestamps: in.Timestamps,
+		LimitBytes: maxPodLogBytes,
+	}, &buf)
+	if err != nil {
+		return nil, nil, err
+	}
+	return nil, &k8sLogsOutput{
+		Context:   cli.Context(),
+		Namespace: namespace,
+		Pod:       in.Pod,
+		Logs:      buf.String(),
+		Truncated: buf.Len() >= maxPodLogBytes,
+	}, nil
+}
+
+type k8sRolloutRestartInput struct {
+	ApprovalID *string `json:"approval_id,omitempty" jsonschema:"Approval ID from a second approver when policy requires one"`
+	Context    *string `json:"context,omitempty" jsonschema:"Kubeconfig context, default from kubernetes.context"`
+	Namespace  *string `json:"namespace,omitempty"`
+	Deployment string  `json:"deployment"`
+	Confirm    bool    `json:"confirm"`
+}
+
+type k8sRolloutRestartOutput struct {
+	Context     string    `json:"context"`
+	Namespace   string    `json:"namespace"`
+	Deployment  string    `json:"deployment"`
+	RestartedAt time.Time `json:"restarted_at"`
+}
+
+func (p *k8sProvider) rolloutRestart(ctx context.Context, _ *mcp.CallToolRequest, in k8sRolloutRestartInput) (*mcp.CallToolResult, *k8sRolloutRestartOutput, error) {
+	if err := requireConfirm(in.Confirm, "k8s_rollout_restart"); err != nil {
+		return nil, nil, err
+	}
+	namespace := optionalString(in.Namespace)
+	if err := validNamespace(namespace); err != nil {
+		return nil, nil, err
+	}
+	if !k8sName.MatchString(in.Deployment) {
+		return nil, nil, fmt.Errorf("invalid deployment %q", in.Deployment)
+	}
+	cli, err := p.client(optionalString(in.Context))
+	if err != nil {
+		return nil, nil, err
+	}
+	if namespace == "" {
+		namespace = cli.Namespace()
+	}
+
+	target := cli.Context() + "/" + namespace + "/" + in.Deployment
+	err = p.policy(policy.Check{
+		Request:    policy.Request{Operation: policy.OpK8sRestart, Tool: "k8s_rollout_restart", Target: target},
+		Confirmed:  true,
+		ApprovalID: optionalString(in.ApprovalID),
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	restartedAt, err := cli.RolloutRestart(ctx, namespace, in.Deployment)
+	p.audit(ctx, core.AuditK8sRestart, target, err, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	return nil, &k8sRolloutRestartOutput{
+		Context:     cli.Context(),
+		Namespace:   namespace,
+		Deployment:  in.Deployment,
+		RestartedAt: restartedAt,
+	}, nil
+}
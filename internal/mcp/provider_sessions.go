@@ -7,17 +7,36 @@ import (
 	"time"
 
 	"github.com/SamyRai/go-jules"
+	"github.com/SamyRai/juleson/internal/config"
 	julessessions "github.com/SamyRai/juleson/internal/jules/sessions"
+	"github.com/SamyRai/juleson/internal/policy"
+	"github.com/SamyRai/juleson/internal/presentation/cli/core"
 	"github.com/modelcontextprotocol/go-sdk/mcp"
 )
 
 type sessionsProvider struct {
 	clientFactory clientFactory
+	audit         auditFunc
+	policy        policyFunc
+	budget        budgetFunc
+	defaults      config.SessionsConfig
 }
 
-// NewSessionsProvider creates a ToolProvider for session management.
-func NewSessionsProvider(cf clientFactory) ToolProvider {
-	return &sessionsProvider{clientFactory: cf}
+// NewSessionsProvider creates a ToolProvider for session management. audit
+// is called after every mutating tool call, enforce before risky ones, and
+// budget before sessions are created; any may be nil. defaults fill the
+// session settings a create_session call leaves out.
+func NewSessionsProvider(cf clientFactory, audit auditFunc, enforce policyFunc, budget budgetFunc, defaults config.SessionsConfig) ToolProvider {
+	if audit == nil {
+		audit = func(context.Context, string, string, error, map[string]interface{}) {}
+	}
+	if enforce == nil {
+		enforce = func(policy.Check) error { return nil }
+	}
+	if budget == nil {
+		budget = func(int) error { return nil }
+	}
+	return &sessionsProvider{clientFactory: cf, audit: audit, policy: enforce, budget: budget, defaults: defaults}
 }
 
 func (p *sessionsProvider) Register(server *mcp.Server) {
@@ -108,9 +127,10 @@ type createSessionInput struct {
 	Title               *string `json:"title,omitempty"`
 	StartingBranch      *string `json:"starting_branch,omitempty"`
 	AutomationMode      *string `json:"automation_mode,omitempty"`
+	ApprovalID          *string `json:"approval_id,omitempty" jsonschema:"Approval ID from a second approver when policy requires one"`
 	Prompt              string  `json:"prompt"`
 	NoSource            bool    `json:"no_source,omitempty"`
-	RequirePlanApproval bool    `json:"require_plan_approval,omitempty"`
+	RequirePlanApproval *bool   `json:"require_plan_approval,omitempty" jsonschema:"Defaults to the configured session defaults, or false"`
 }
 
 func (p *sessionsProvider) createSession(ctx context.Context, _ *mcp.CallToolRequest, in createSessionInput) (*mcp.CallToolResult, *jules.Session, error) {
@@ -118,25 +138,47 @@ func (p *sessionsProvider) createSession(ctx context.Context, _ *mcp.CallToolReq
 	if err != nil {
 		return nil, nil, err
 	}
-	req := &jules.CreateSessionRequest{
-		Prompt:              in.Prompt,
-		Title:               optionalString(in.Title),
-		RequirePlanApproval: in.RequirePlanApproval,
-		AutomationMode:      jules.AutomationMode(optionalString(in.AutomationMode)),
+	if !in.NoSource && optionalString(in.SourceID) == "" {
+		return nil, nil, fmt.Errorf("source_id is required unless no_source=true")
 	}
-	if !in.NoSource {
-		sourceID := optionalString(in.SourceID)
-		if sourceID == "" {
-			return nil, nil, fmt.Errorf("source_id is required unless no_source=true")
-		}
-		req.SourceContext = &jules.SourceContext{
-			Source: sourceID,
-		}
-		if startingBranch := optionalString(in.StartingBranch); startingBranch != "" {
-			req.SourceContext.GithubRepoContext = &jules.GithubRepoContext{StartingBranch: startingBranch}
+	requestOptions := julessessions.CreateSessionRequestOptions{
+		Prompt:         in.Prompt,
+		Source:         optionalString(in.SourceID),
+		NoSource:       in.NoSource,
+		Title:          optionalString(in.Title),
+		StartingBranch: optionalString(in.StartingBranch),
+		AutomationMode: optionalString(in.AutomationMode),
+	}
+	if in.RequirePlanApproval != nil {
+		requestOptions.RequirePlanApproval = *in.RequirePlanApproval
+	}
+	julessessions.ApplyDefaults(&requestOptions, p.defaults, in.RequirePlanApproval != nil)
+	req, err := julessessions.BuildCreateSessionRequest(requestOptions)
+	if err != nil {
+		return nil, nil, err
+	}
+	if !requestOptions.RequirePlanApproval {
+		err := p.policy(policy.Check{
+			Request: policy.Request{
+				Operation: policy.OpAutoApprovePlan,
+				Tool:      "create_session",
+				Repo:      policy.RepoFromSource(optionalString(in.SourceID)),
+			},
+			ApprovalID: optionalString(in.ApprovalID),
+		})
+		if err != nil {
+			return nil, nil, fmt.Errorf("%w (set require_plan_approval=true to review the plan first)", err)
 		}
 	}
+	if err := p.budget(1); err != nil {
+		return nil, nil, err
+	}
 	session, err := client.Sessions().Create(ctx, req)
+	target := optionalString(in.SourceID)
+	if session != nil {
+		target = session.ID
+	}
+	p.audit(ctx, core.AuditSessionCreate, target, err, map[string]interface{}{"source": optionalString(in.SourceID)})
 	return nil, session, wrapAPIError("create session", err)
 }
 
@@ -148,7 +190,9 @@ func (p *sessionsProvider) approvePlan(ctx context.Context, _ *mcp.CallToolReque
 	if err != nil {
 		return nil, actionOutput{}, err
 	}
-	if err := client.Sessions().ApprovePlan(ctx, in.SessionID); err != nil {
+	err = client.Sessions().ApprovePlan(ctx, in.SessionID)
+	p.audit(ctx, core.AuditSessionApprovePlan, in.SessionID, err, nil)
+	if err != nil {
 		return nil, actionOutput{}, wrapAPIError("approve session plan", err)
 	}
 	return nil, actionOutput{OK: true, Message: "plan approved"}, nil
@@ -164,7 +208,9 @@ func (p *sessionsProvider) sendMessage(ctx context.Context, _ *mcp.CallToolReque
 	if err != nil {
 		return nil, actionOutput{}, err
 	}
-	if err := client.Sessions().SendMessage(ctx, in.SessionID, &jules.SendMessageRequest{Prompt: in.Message}); err != nil {
+	err = client.Sessions().SendMessage(ctx, in.SessionID, &jules.SendMessageRequest{Prompt: in.Message})
+	p.audit(ctx, core.AuditSessionMessage, in.SessionID, err, nil)
+	if err != nil {
 		return nil, actionOutput{}, wrapAPIError("send session message", err)
 	}
 	return nil, actionOutput{OK: true, Message: "message sent"}, nil
@@ -178,7 +224,17 @@ func (p *sessionsProvider) deleteSession(ctx context.Context, _ *mcp.CallToolReq
 	if err != nil {
 		return nil, actionOutput{}, err
 	}
-	if err := client.Sessions().Delete(ctx, in.SessionID); err != nil {
+	err = p.policy(policy.Check{
+		Request:    policy.Request{Operation: policy.OpDeleteSession, Tool: "delete_session", Target: in.SessionID},
+		Confirmed:  true,
+		ApprovalID: optionalString(in.ApprovalID),
+	})
+	if err != nil {
+		return nil, actionOutput{}, err
+	}
+	err = client.Sessions().Delete(ctx, in.SessionID)
+	p.audit(ctx, core.AuditSessionDelete, in.SessionID, err, nil)
+	if err != nil {
 		return nil, actionOutput{}, wrapAPIError("delete session", err)
 	}
 	return nil, actionOutput{OK: true, Message: "session deleted"}, nil
@@ -0,0 +1,68 @@
+package jmcp
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/SamyRai/go-jules"
+	"github.com/SamyRai/juleson/internal/config"
+	"github.com/SamyRai/juleson/internal/jules/julestest"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+func TestSessionsToolsAgainstFakeAPI(t *testing.T) {
+	fake := julestest.NewServer()
+	defer fake.Close()
+	session := fake.AddSession(jules.Session{Title: "Fix the build", State: jules.SessionStatePlanning})
+
+	server := mcp.NewServer(&mcp.Implementation{Name: "test-server", Version: "v0.0.1"}, nil)
+	NewSessionsProvider(func() (*jules.Client, error) { return fake.Client(), nil }, nil, nil, nil, config.SessionsConfig{}).Register(server)
+
+	ctx := context.Background()
+	serverTransport, clientTransport := mcp.NewInMemoryTransports()
+	serverSession, err := server.Connect(ctx, serverTransport, nil)
+	if err != nil {
+		t.Fatalf("server connect: %v", err)
+	}
+	defer func() { _ = serverSession.Close() }()
+	clientSession, err := mcp.NewClient(&mcp.Implementation{Name: "test-client", Version: "v0.0.1"}, nil).Connect(ctx, clientTransport, nil)
+	if err != nil {
+		t.Fatalf("client connect: %v", err)
+	}
+	defer func() { _ = clientSession.Close() }()
+
+	approve := func() *mcp.CallToolResult {
+		result, err := clientSession.CallTool(ctx, &mcp.CallToolParams{
+			Name:      "approve_session_plan",
+			Arguments: map[string]any{"session_id": session.ID, "confirm": true},
+		})
+		if err != nil {
+			t.Fatalf("call approve_session_plan: %v", err)
+		}
+		return result
+	}
+
+	result := approve()
+	if !result.IsError || !strings.Contains(toolText(result), "juleson sessions watch") {
+		t.Fatalf("approving without a plan = %s, want a remediation hint", toolText(result))
+	}
+
+	fake.PostPlan(session.ID, jules.Plan{ID: "plan-1", Steps: []jules.Step{{Title: "Fix"}}})
+	if result := approve(); result.IsError {
+		t.Fatalf("approving the plan failed: %s", toolText(result))
+	}
+	if got, _ := fake.Session(session.ID); got.State != jules.SessionStateInProgress {
+		t.Fatalf("state after approval = %s", got.State)
+	}
+}
+
+func toolText(result *mcp.CallToolResult) string {
+	var text []string
+	for _, content := range result.Content {
+		if c, ok := content.(*mcp.TextContent); ok {
+			text = append(text, c.Text)
+		}
+	}
+	return strings.Join(text, "\n")
+}
@@ -0,0 +1,192 @@
+package jmcp
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/SamyRai/go-jules"
+	"github.com/SamyRai/juleson/internal/config"
+	julessessions "github.com/SamyRai/juleson/internal/jules/sessions"
+	"github.com/SamyRai/juleson/internal/policy"
+	"github.com/SamyRai/juleson/internal/presentation/cli/core"
+	"github.com/SamyRai/juleson/internal/templates"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+type templatesProvider struct {
+	cfg           *config.Config
+	clientFactory clientFactory
+	audit         auditFunc
+	policy        policyFunc
+	budget        budgetFunc
+}
+
+// NewTemplatesProvider creates a ToolProvider for listing automation
+// templates and creating Jules sessions from them. audit, enforce, and
+// budget may be nil.
+func NewTemplatesProvider(cfg *config.Config, cf clientFactory, audit auditFunc, enforce policyFunc, budget budgetFunc) ToolProvider {
+	if audit == nil {
+		audit = func(context.Context, string, string, error, map[string]interface{}) {}
+	}
+	if enforce == nil {
+		enforce = func(policy.Check) error { return nil }
+	}
+	if budget == nil {
+		budget = func(int) error { return nil }
+	}
+	return &templatesProvider{cfg: cfg, clientFactory: cf, audit: audit, policy: enforce, budget: budget}
+}
+
+func (p *templatesProvider) Register(server *mcp.Server) {
+	mcp.AddTool(server, &mcp.Tool{
+		Name:        "list_templates",
+		Description: "List built-in and custom automation templates, optionally by category or search query.",
+	}, p.list)
+	mcp.AddTool(server, &mcp.Tool{
+		Name: "execute_template",
+		Description: "Render a template's task prompts with variables and create a Jules session. Every task becomes an " +
+			"ordered phase of one session unless task is set; dry_run returns the prompt without creating a session.",
+	}, p.execute)
+}
+
+func (p *templatesProvider) manager() (*templates.Manager, error) {
+	return templates.NewManager(p.cfg.Templates.BuiltinPath, p.cfg.Templates.CustomPath, p.cfg.Templates.EnableCustom)
+}
+
+type listTemplatesInput struct {
+	Category *string `json:"category,omitempty" jsonschema:"Only templates in this category"`
+	Query    *string `json:"query,omitempty" jsonschema:"Search names, descriptions, and tags"`
+}
+
+type templateSummary struct {
+	Name        string   `json:"name"`
+	Category    string   `json:"category"`
+	Description string   `json:"description"`
+	Tags        []string `json:"tags,omitempty"`
+}
+
+type listTemplatesOutput struct {
+	Templates []templateSummary `json:"templates"`
+}
+
+func (p *templatesProvider) list(_ context.Context, _ *mcp.CallToolRequest, in listTemplatesInput) (*mcp.CallToolResult, listTemplatesOutput, error) {
+	manager, err := p.manager()
+	if err != nil {
+		return nil, listTemplatesOutput{}, err
+	}
+	var found []templates.RegistryTemplate
+	switch {
+	case optionalString(in.Query) != "":
+		found = manager.SearchTemplates(optionalString(in.Query))
+	case optionalString(in.Category) != "":
+		found = manager.ListTemplatesByCategory(optionalString(in.Category))
+	default:
+		found = manager.ListTemplates()
+	}
+	out := listTemplatesOutput{Templates: []templateSummary{}}
+	for _, t := range found {
+		if category := optionalString(in.Category); category != "" && t.Category != category {
+			continue
+		}
+		out.Templates = append(out.Templates, templateSummary{Name: t.Name, Category: t.Category, Description: t.Description, Tags: t.Tags})
+	}
+	return nil, out, nil
+}
+
+type executeTemplateInput struct {
+	Name                string            `json:"name" jsonschema:"Template name"`
+	SourceID            *string           `json:"source_id,omitempty" jsonschema:"Jules source ID; required unless no_source=true or dry_run=true"`
+	Variables           map[string]string `json:"variables,omitempty" jsonschema:"Values for the template's prompt variables, checked against declared types and enums"`
+	Task                *string           `json:"task,omitempty" jsonschema:"Run only this task instead of every phase"`
+	Title               *string           `json:"title,omitempty" jsonschema:"Session title; defaults to the template name"`
+	StartingBranch      *string           `json:"starting_branch,omitempty"`
+	AutomationMode      *string           `json:"automation_mode,omitempty"`
+	ApprovalID          *string           `json:"approval_id,omitempty" jsonschema:"Approval ID granted by a second approver when policy requires one"`
+	NoSource            bool              `json:"no_source,omitempty"`
+	RequirePlanApproval *bool             `json:"require_plan_approval,omitempty" jsonschema:"Always true for templates that set requires_approval; otherwise defaults to the configured session defaults"`
+	DryRun              bool              `json:"dry_run,omitempty"`
+}
+
+type executeTemplateOutput struct {
+	Prompt  string         `json:"prompt"`
+	Session *jules.Session `json:"session,omitempty"`
+}
+
+func (p *templatesProvider) execute(ctx context.Context, _ *mcp.CallToolRequest, in executeTemplateInput) (*mcp.CallToolResult, executeTemplateOutput, error) {
+	manager, err := p.manager()
+	if err != nil {
+		return nil, executeTemplateOutput{}, err
+	}
+	template, err := manager.LoadTemplate(in.Name)
+	if err != nil {
+		return nil, executeTemplateOutput{}, err
+	}
+	task := optionalString(in.Task)
+	prompt, err := template.RenderPrompt(in.Variables, task)
+	if err != nil {
+		return nil, executeTemplateOutput{}, err
+	}
+	out := executeTemplateOutput{Prompt: prompt}
+	if in.DryRun {
+		return nil, out, nil
+	}
+
+	sourceID := optionalString(in.SourceID)
+	if !in.NoSource && sourceID == "" {
+		return nil, out, fmt.Errorf("source_id is required unless no_source=true")
+	}
+	title := optionalString(in.Title)
+	if title == "" {
+		title = template.Metadata.Name
+		if task != "" {
+			title += ": " + task
+		}
+	}
+	requestOptions := julessessions.CreateSessionRequestOptions{
+		Prompt:         prompt,
+		Source:         sourceID,
+		NoSource:       in.NoSource,
+		Title:          title,
+		StartingBranch: optionalString(in.StartingBranch),
+		AutomationMode: optionalString(in.AutomationMode),
+	}
+	if in.RequirePlanApproval != nil {
+		requestOptions.RequirePlanApproval = *in.RequirePlanApproval
+	}
+	julessessions.ApplyDefaults(&requestOptions, p.cfg.Sessions, in.RequirePlanApproval != nil)
+	requireApproval := requestOptions.RequirePlanApproval || template.Config.RequiresApproval
+	requestOptions.RequirePlanApproval = requireApproval
+	req, err := julessessions.BuildCreateSessionRequest(requestOptions)
+	if err != nil {
+		return nil, out, err
+	}
+	if !requireApproval {
+		err := p.policy(policy.Check{
+			Request: policy.Request{
+				Operation: policy.OpAutoApprovePlan,
+				Tool:      "execute_template",
+				Repo:      policy.RepoFromSource(sourceID),
+			},
+			ApprovalID: optionalString(in.ApprovalID),
+		})
+		if err != nil {
+			return nil, out, fmt.Errorf("%w (set require_plan_approval=true to review the plan first)", err)
+		}
+	}
+
+	client, err := p.clientFactory()
+	if err != nil {
+		return nil, out, err
+	}
+	if err := p.budget(1); err != nil {
+		return nil, out, err
+	}
+	session, err := client.Sessions().Create(ctx, req)
+	target := sourceID
+	if session != nil {
+		target = session.ID
+	}
+	p.audit(ctx, core.AuditSessionCreate, target, err, map[string]interface{}{"source": sourceID, "template": template.Metadata.Name})
+	out.Session = session
+	return nil, out, wrapAPIError("create session", err)
+}
@@ -0,0 +1,118 @@
+package jmcp
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"time"
+
+	"github.com/SamyRai/juleson/pkg/terraform"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// Limits for terraform runs.
+const (
+	defaultTerraformTimeout = 10 * time.Minute
+	maxTerraformTimeout     = 30 * time.Minute
+)
+
+type terraformProvider struct {
+	runner func(dir string) (*terraform.Runner, error)
+}
+
+// NewTerraformProvider creates a ToolProvider for validating and planning
+// Terraform configurations. Nothing is applied; plans are summarized and run
+// through the review gate so a person can decide whether to apply them.
+func NewTerraformProvider() ToolProvider {
+	return &terraformProvider{runner: terraform.NewRunner}
+}
+
+func (p *terraformProvider) Register(server *mcp.Server) {
+	mcp.AddTool(server, &mcp.Tool{
+		Name:        "terraform_validate",
+		Description: "Run terraform validate in a directory inside the working directory and return its diagnostics.",
+	}, p.validate)
+	mcp.AddTool(server, &mcp.Tool{
+		Name: "terraform_plan",
+		Description: "Run terraform plan without applying or locking state, summarize resource changes, and review them. " +
+			"The review verdict is pass, review, or block; block means the plan destroys data or exposes resources publicly.",
+	}, p.plan)
+}
+
+// terraformContext bounds a run by timeoutSeconds, capped at the maximum.
+func terraformContext(ctx context.Context, timeoutSeconds int) (context.Context, context.CancelFunc) {
+	timeout := defaultTerraformTimeout
+	if timeoutSeconds > 0 {
+		timeout = min(time.Duration(timeoutSeconds)*time.Second, maxTerraformTimeout)
+	}
+	return context.WithTimeout(ctx, timeout)
+}
+
+type terraformValidateInput struct {
+	Dir            *string `json:"dir,omitempty" jsonschema:"Root module directory, relative to the working directory"`
+	TimeoutSeconds int     `json:"timeout_seconds,omitempty"`
+}
+
+func (p *terraformProvider) validate(ctx context.Context, _ *mcp.CallToolRequest, in terraformValidateInput) (*mcp.CallToolResult, *terraform.ValidateResult, error) {
+	dir, err := projectDir(optionalString(in.Dir))
+	if err != nil {
+		return nil, nil, err
+	}
+	runner, err := p.runner(dir)
+	if err != nil {
+		return nil, nil, err
+	}
+	ctx, cancel := terraformContext(ctx, in.TimeoutSeconds)
+	defer cancel()
+
+	result, err := runner.Validate(ctx)
+	return nil, result, err
+}
+
+type terraformPlanInput struct {
+	Dir            *string           `json:"dir,omitempty" jsonschema:"Root module directory, relative to the working directory"`
+	Vars           map[string]string `json:"vars,omitempty" jsonschema:"Input variables passed with -var"`
+	VarFiles       []string          `json:"var_files,omitempty" jsonschema:"Variable files inside the module directory"`
+	TimeoutSeconds int               `json:"timeout_seconds,omitempty"`
+	Destroy        bool              `json:"destroy,omitempty"`
+}
+
+type terraformPlanOutput struct {
+	Dir     string                 `json:"dir"`
+	Summary string                 `json:"summary"`
+	Plan    *terraform.PlanSummary `json:"plan"`
+	Review  *terraform.Review      `json:"review"`
+}
+
+func (p *terraformProvider) plan(ctx context.Context, _ *mcp.CallToolRequest, in terraformPlanInput) (*mcp.CallToolResult, *terraformPlanOutput, error) {
+	dir, err := projectDir(optionalString(in.Dir))
+	if err != nil {
+		return nil, nil, err
+	}
+	for _, f := range in.VarFiles {
+		path := f
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(dir, path)
+		}
+		if !within(dir, filepath.Clean(path)) {
+			return nil, nil, fmt.Errorf("var file %s is outside %s", f, dir)
+		}
+	}
+	runner, err := p.runner(dir)
+	if err != nil {
+		return nil, nil, err
+	}
+	ctx, cancel := terraformContext(ctx, in.TimeoutSeconds)
+	defer cancel()
+
+	plan, err := runner.Plan(ctx, terraform.PlanOptions{VarFiles: in.VarFiles, Vars: in.Vars, Destroy: in.Destroy})
+	if err != nil {
+		return nil, nil, err
+	}
+	return nil, &terraformPlanOutput{
+		Dir:     dir,
+		Summary: plan.String(),
+		Plan:    plan,
+		Review:  terraform.ReviewPlan(plan),
+	}, nil
+}
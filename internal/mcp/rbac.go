@@ -0,0 +1,164 @@
+package jmcp
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/SamyRai/juleson/internal/presentation/cli/core"
+	"github.com/SamyRai/juleson/internal/rbac"
+	"github.com/modelcontextprotocol/go-sdk/auth"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// toolRoles is the least role that may call each tool when clients
+// authenticate with mcp.tokens. Viewers may only read; operators may also
+// change sessions, the working tree, run builds, and read container and pod
+// logs, which can hold secrets, and validate Terraform; admins may also
+// delete sessions, run containers, change clusters, plan Terraform, and
+// move or stash the working tree. Terraform configurations can run
+// arbitrary programs: validate installs the providers and modules they
+// name, and plan also runs their external data sources. Tools missing here
+// need admin.
+var toolRoles = map[string]rbac.Role{
+	"version":                rbac.RoleViewer,
+	"config_status":          rbac.RoleViewer,
+	"list_sources":           rbac.RoleViewer,
+	"get_source":             rbac.RoleViewer,
+	"list_sessions":          rbac.RoleViewer,
+	"get_session":            rbac.RoleViewer,
+	"get_session_outputs":    rbac.RoleViewer,
+	"get_session_plans":      rbac.RoleViewer,
+	"list_activities":        rbac.RoleViewer,
+	"get_activity":           rbac.RoleViewer,
+	"list_session_artifacts": rbac.RoleViewer,
+	"watch_session":          rbac.RoleViewer,
+	"review_session":         rbac.RoleViewer,
+	"list_templates":         rbac.RoleViewer,
+	"get_run_report":         rbac.RoleViewer,
+	"git_status":             rbac.RoleViewer,
+	"git_diff":               rbac.RoleViewer,
+	"git_log":                rbac.RoleViewer,
+	"k8s_contexts":           rbac.RoleViewer,
+	"k8s_pods":               rbac.RoleViewer,
+	"analyze_deps":           rbac.RoleViewer,
+	"analyze_hotspots":       rbac.RoleViewer,
+	"analyze_project":        rbac.RoleViewer,
+	"dev_impact":             rbac.RoleViewer,
+	"dev_smells":             rbac.RoleViewer,
+	"dev_secrets":            rbac.RoleViewer,
+
+	"create_session":           rbac.RoleOperator,
+	"approve_session_plan":     rbac.RoleOperator,
+	"send_session_message":     rbac.RoleOperator,
+	"execute_template":         rbac.RoleOperator,
+	"generate_commit_messages": rbac.RoleOperator,
+	"git_branch":               rbac.RoleOperator,
+	"git_commit":               rbac.RoleOperator,
+	"dev_build":                rbac.RoleOperator,
+	"dev_test":                 rbac.RoleOperator,
+	"dev_check":                rbac.RoleOperator,
+	"terraform_validate":       rbac.RoleOperator,
+	"docker_logs":              rbac.RoleOperator,
+	"k8s_logs":                 rbac.RoleOperator,
+
+	"delete_session":      rbac.RoleAdmin,
+	"docker_run":          rbac.RoleAdmin,
+	"k8s_apply":           rbac.RoleAdmin,
+	"k8s_rollout_restart": rbac.RoleAdmin,
+	"git_checkout":        rbac.RoleAdmin,
+	"git_stash":           rbac.RoleAdmin,
+	"terraform_plan":      rbac.RoleAdmin,
+}
+
+// toolRole returns the least role that may call tool.
+func toolRole(tool string) rbac.Role {
+	if role, ok := toolRoles[tool]; ok {
+		return role
+	}
+	return rbac.RoleAdmin
+}
+
+// authorize limits the tools an authenticated caller sees and calls to
+// those of its role, and passes the caller to tool handlers for audit
+// attribution. Denied calls are audited. Requests without a bearer token,
+// over stdio or HTTP without mcp.tokens, are not limited.
+func authorize(audit auditFunc) mcp.Middleware {
+	return func(next mcp.MethodHandler) mcp.MethodHandler {
+		return func(ctx context.Context, method string, req mcp.Request) (mcp.Result, error) {
+			caller, ok := callerOf(req)
+			if !ok {
+				return next(ctx, method, req)
+			}
+			ctx = rbac.WithCaller(ctx, caller)
+
+			switch params := req.GetParams().(type) {
+			case *mcp.CallToolParamsRaw:
+				if required := toolRole(params.Name); !caller.Role.Allows(required) {
+					err := fmt.Errorf("tool %s requires the %s role; token %s has %s", params.Name, required, caller.Name, caller.Role)
+					audit(ctx, core.AuditMCPDenied, params.Name, err, map[string]interface{}{"role": string(caller.Role)})
+					return nil, err
+				}
+			case *mcp.ListToolsParams:
+				result, err := next(ctx, method, req)
+				if list, ok := result.(*mcp.ListToolsResult); ok && err == nil {
+					allowed := list.Tools[:0:0]
+					for _, tool := range list.Tools {
+						if caller.Role.Allows(toolRole(tool.Name)) {
+							allowed = append(allowed, tool)
+						}
+					}
+					list.Tools = allowed
+				}
+				return result, err
+			}
+			return next(ctx, method, req)
+		}
+	}
+}
+
+// callerOf returns the caller authenticated by the bearer token of req.
+func callerOf(req mcp.Request) (rbac.Caller, bool) {
+	extra := req.GetExtra()
+	if extra == nil || extra.TokenInfo == nil || len(extra.TokenInfo.Scopes) == 0 {
+		return rbac.Caller{}, false
+	}
+	tenant, _ := extra.TokenInfo.Extra["tenant"].(string)
+	return rbac.Caller{Name: extra.TokenInfo.UserID, Role: rbac.Role(extra.TokenInfo.Scopes[0]), Tenant: tenant}, true
+}
+
+// verifyToken checks bearer tokens against tokens, recording the holder as
+// the user, the role as the only scope, and the tenant as the "tenant"
+// extra. Configured tokens do not expire.
+func verifyToken(tokens *rbac.Tokens) auth.TokenVerifier {
+	return func(_ context.Context, token string, _ *http.Request) (*auth.TokenInfo, error) {
+		caller, ok := tokens.Verify(token)
+		if !ok {
+			return nil, fmt.Errorf("unknown token: %w", auth.ErrInvalidToken)
+		}
+		return &auth.TokenInfo{
+			UserID:     caller.Name,
+			Scopes:     []string{string(caller.Role)},
+			Expiration: time.Now().Add(24 * time.Hour),
+			Extra:      map[string]any{"tenant": caller.Tenant},
+		}, nil
+	}
+}
+
+// tenantOf returns the tenant of the bearer token authenticating r.
+func tenantOf(r *http.Request) string {
+	info := auth.TokenInfoFromContext(r.Context())
+	if info == nil {
+		return ""
+	}
+	tenant, _ := info.Extra["tenant"].(string)
+	return tenant
+}
+
+// loopback reports whether addr only accepts local connections.
+func loopback(addr net.Addr) bool {
+	tcp, ok := addr.(*net.TCPAddr)
+	return ok && tcp.IP.IsLoopback()
+}
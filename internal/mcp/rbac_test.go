@@ -0,0 +1,184 @@
+package jmcp
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/SamyRai/juleson/internal/config"
+	"github.com/SamyRai/juleson/internal/presentation/cli/core"
+	"github.com/SamyRai/juleson/internal/rbac"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+func TestToolRolesCoverRegisteredTools(t *testing.T) {
+	server, err := NewServer(ServerOptions{Config: &config.Config{}})
+	if err != nil {
+		t.Fatalf("NewServer: %v", err)
+	}
+	ctx := context.Background()
+	serverTransport, clientTransport := mcp.NewInMemoryTransports()
+	serverSession, err := server.Connect(ctx, serverTransport, nil)
+	if err != nil {
+		t.Fatalf("server connect: %v", err)
+	}
+	defer func() { _ = serverSession.Close() }()
+	client := mcp.NewClient(&mcp.Implementation{Name: "test-client", Version: "v0.0.1"}, nil)
+	clientSession, err := client.Connect(ctx, clientTransport, nil)
+	if err != nil {
+		t.Fatalf("client connect: %v", err)
+	}
+	defer func() { _ = clientSession.Close() }()
+
+	// Unclassified tools fall back to admin; classify new tools explicitly.
+	for tool, err := range clientSession.Tools(ctx, nil) {
+		if err != nil {
+			t.Fatalf("list tools: %v", err)
+		}
+		if _, ok := toolRoles[tool.Name]; !ok {
+			t.Errorf("tool %q has no role in toolRoles", tool.Name)
+		}
+	}
+}
+
+// bearerTransport adds a bearer token to requests.
+type bearerTransport struct{ token string }
+
+func (b bearerTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	req = req.Clone(req.Context())
+	req.Header.Set("Authorization", "Bearer "+b.token)
+	return http.DefaultTransport.RoundTrip(req)
+}
+
+func TestHTTPHandlerEnforcesTokenRoles(t *testing.T) {
+	auditPath := filepath.Join(t.TempDir(), "audit.jsonl")
+	cfg := &config.Config{
+		Audit: config.AuditConfig{Enabled: true, Path: auditPath},
+		MCP: config.MCPConfig{Tokens: []config.MCPTokenConfig{
+			{Name: "dashboard", Token: "view-secret", Role: "viewer"},
+			{Name: "ci-bot", TokenSHA256: rbac.HashToken("ops-secret"), Role: "operator"},
+		}},
+	}
+	handler, err := NewHTTPHandler(ServerOptions{Config: cfg})
+	if err != nil {
+		t.Fatalf("NewHTTPHandler: %v", err)
+	}
+	server := httptest.NewServer(handler)
+	// Registered first so it runs after the client sessions close.
+	t.Cleanup(server.Close)
+
+	resp, err := http.Post(server.URL, "application/json", strings.NewReader(`{}`))
+	if err != nil {
+		t.Fatalf("unauthenticated request: %v", err)
+	}
+	_ = resp.Body.Close()
+	if resp.StatusCode != http.StatusUnauthorized {
+		t.Errorf("request without a token = %s, want 401", resp.Status)
+	}
+
+	ctx := context.Background()
+	connect := func(token string) *mcp.ClientSession {
+		t.Helper()
+		client := mcp.NewClient(&mcp.Implementation{Name: "test-client", Version: "v0.0.1"}, nil)
+		session, err := client.Connect(ctx, &mcp.StreamableClientTransport{
+			Endpoint:   server.URL,
+			HTTPClient: &http.Client{Transport: bearerTransport{token: token}},
+			MaxRetries: -1,
+		}, nil)
+		if err != nil {
+			t.Fatalf("connect with %s: %v", token, err)
+		}
+		t.Cleanup(func() { _ = session.Close() })
+		return session
+	}
+
+	viewer := connect("view-secret")
+	tools := map[string]bool{}
+	for tool, err := range viewer.Tools(ctx, nil) {
+		if err != nil {
+			t.Fatalf("list tools: %v", err)
+		}
+		tools[tool.Name] = true
+	}
+	if !tools["list_sessions"] || tools["create_session"] || tools["docker_run"] || tools["docker_logs"] {
+		t.Errorf("viewer tools = %v, want read-only tools only", tools)
+	}
+	if _, err := viewer.CallTool(ctx, &mcp.CallToolParams{Name: "version"}); err != nil {
+		t.Errorf("viewer calling version: %v", err)
+	}
+	if _, err := viewer.CallTool(ctx, &mcp.CallToolParams{Name: "git_commit", Arguments: map[string]any{"confirm": true}}); err == nil || !strings.Contains(err.Error(), "requires the operator role") {
+		t.Errorf("viewer calling git_commit = %v, want a role error", err)
+	}
+
+	operator := connect("ops-secret")
+	if _, err := operator.CallTool(ctx, &mcp.CallToolParams{Name: "delete_session", Arguments: map[string]any{"session_id": "s-1", "confirm": true}}); err == nil || !strings.Contains(err.Error(), "requires the admin role") {
+		t.Errorf("operator calling delete_session = %v, want a role error", err)
+	}
+
+	journal, err := os.ReadFile(auditPath)
+	if err != nil {
+		t.Fatalf("read audit log: %v", err)
+	}
+	for _, want := range []string{`"actor":"dashboard","action":"` + core.AuditMCPDenied + `","target":"git_commit"`, `"actor":"ci-bot","action":"` + core.AuditMCPDenied + `","target":"delete_session"`} {
+		if !strings.Contains(string(journal), want) {
+			t.Errorf("audit log lacks %s:\n%s", want, journal)
+		}
+	}
+}
+
+func TestHTTPHandlerRoutesTenants(t *testing.T) {
+	dir := t.TempDir()
+	cfg := &config.Config{
+		Audit: config.AuditConfig{Enabled: true, Path: filepath.Join(dir, "audit.jsonl")},
+		MCP: config.MCPConfig{
+			Tokens: []config.MCPTokenConfig{
+				{Name: "a-bot", Token: "a-secret", Role: "viewer", Tenant: "team-a"},
+				{Name: "b-bot", Token: "b-secret", Role: "viewer", Tenant: "team-b"},
+			},
+			Tenants: []config.MCPTenantConfig{
+				{Name: "team-a", JulesAPIKey: "jules-a", DataDir: filepath.Join(dir, "team-a")},
+				{Name: "team-b", JulesAPIKey: "jules-b", DataDir: filepath.Join(dir, "team-b")},
+			},
+		},
+	}
+	handler, err := NewHTTPHandler(ServerOptions{Config: cfg})
+	if err != nil {
+		t.Fatalf("NewHTTPHandler: %v", err)
+	}
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	ctx := context.Background()
+	for _, token := range []string{"a-secret", "b-secret"} {
+		client := mcp.NewClient(&mcp.Implementation{Name: "test-client", Version: "v0.0.1"}, nil)
+		session, err := client.Connect(ctx, &mcp.StreamableClientTransport{
+			Endpoint:   server.URL,
+			HTTPClient: &http.Client{Transport: bearerTransport{token: token}},
+			MaxRetries: -1,
+		}, nil)
+		if err != nil {
+			t.Fatalf("connect with %s: %v", token, err)
+		}
+		t.Cleanup(func() { _ = session.Close() })
+		if _, err := session.CallTool(ctx, &mcp.CallToolParams{Name: "git_commit"}); err == nil {
+			t.Errorf("viewer %s calling git_commit succeeded", token)
+		}
+	}
+
+	for tenant, want := range map[string]string{"team-a": `"actor":"a-bot"`, "team-b": `"actor":"b-bot"`} {
+		journal, err := os.ReadFile(filepath.Join(dir, tenant, "audit.jsonl"))
+		if err != nil {
+			t.Fatalf("read %s audit log: %v", tenant, err)
+		}
+		if !strings.Contains(string(journal), want) || strings.Count(string(journal), "\n") != 1 {
+			t.Errorf("%s audit log = %s, want only the entry of %s", tenant, journal, want)
+		}
+	}
+	if _, err := os.Stat(cfg.Audit.Path); !os.IsNotExist(err) {
+		t.Errorf("tenant calls reached the shared audit log: %v", err)
+	}
+}
@@ -2,12 +2,21 @@ package jmcp
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"net"
+	"net/http"
+	"time"
 
 	"github.com/SamyRai/go-jules"
 	"github.com/SamyRai/juleson/internal/config"
+	"github.com/SamyRai/juleson/internal/logger"
+	"github.com/SamyRai/juleson/internal/policy"
 	"github.com/SamyRai/juleson/internal/presentation/cli/core"
+	"github.com/SamyRai/juleson/internal/rbac"
+	"github.com/SamyRai/juleson/internal/sandbox"
 	"github.com/SamyRai/juleson/pkg/builder"
+	"github.com/modelcontextprotocol/go-sdk/auth"
 	"github.com/modelcontextprotocol/go-sdk/mcp"
 )
 
@@ -15,6 +24,12 @@ const ServerName = "juleson"
 
 type ServerOptions struct {
 	Config *config.Config
+	// Tenant names the mcp.tenants entry the server acts for. A tenant's
+	// server only has the tools that act through its own Jules and GitHub
+	// credentials: the host-local Git, Dev, Docker, Kubernetes, Terraform,
+	// and analysis tools would reach the operator's working tree, containers,
+	// and clusters, which tenants must not share.
+	Tenant string
 }
 
 func NewServer(options ServerOptions) (*mcp.Server, error) {
@@ -33,19 +48,44 @@ func NewServer(options ServerOptions) (*mcp.Server, error) {
 		return core.NewJulesClient(options.Config), nil
 	}
 
-	devSvc := builder.NewService(builder.DefaultConfig("dev", "", ""))
+	audit := func(ctx context.Context, action, target string, err error, details map[string]interface{}) {
+		caller, _ := rbac.CallerFrom(ctx)
+		core.RecordAuditAs(options.Config, core.AuditSourceMCP, caller.Name, action, target, err, details)
+	}
+	enforce := func(check policy.Check) error {
+		return core.EnforcePolicy(options.Config, check, false)
+	}
+	budget := func(n int) error {
+		return core.CheckSessionBudget(options.Config, core.AuditSourceMCP, n)
+	}
+	sf := func() (*sandbox.Sandbox, error) {
+		return sandbox.New(options.Config.Sandbox.SandboxOptions())
+	}
 
 	providers := []ToolProvider{
 		NewCoreProvider(options.Config),
-		NewSessionsProvider(cf),
+		NewSessionsProvider(cf, audit, enforce, budget, options.Config.Sessions),
 		NewSourcesProvider(cf),
 		NewArtifactsProvider(cf),
-		NewDevProvider(devSvc),
+		NewTemplatesProvider(options.Config, cf, audit, enforce, budget),
+		NewHistoryProvider(options.Config, cf),
+		NewCommitsProvider(options.Config, cf),
+	}
+	if options.Tenant == "" {
+		providers = append(providers,
+			NewDevProvider(builder.NewService(builder.DefaultConfig("dev", "", ""))),
+			NewDockerProvider(sf, audit, enforce),
+			NewK8sProvider(options.Config, audit, enforce),
+			NewTerraformProvider(),
+			NewGitProvider(audit),
+			NewAnalyzeProvider(options.Config),
+		)
 	}
 
 	for _, p := range providers {
 		p.Register(server)
 	}
+	server.AddReceivingMiddleware(authorize(audit))
 
 	return server, nil
 }
@@ -55,5 +95,105 @@ func RunStdio(ctx context.Context, cfg *config.Config) error {
 	if err != nil {
 		return err
 	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	startBackground(ctx, cfg)
+
 	return server.Run(ctx, &mcp.StdioTransport{})
 }
+
+// NewHTTPHandler creates a handler serving the MCP server over the
+// streamable HTTP transport. When mcp.tokens are configured, requests need
+// one of them as a bearer token, and its role limits the tools they see and
+// call. Tokens of a tenant in mcp.tenants are served by a server of their
+// own, configured for that tenant and without host-local tools.
+func NewHTTPHandler(options ServerOptions) (http.Handler, error) {
+	configured, err := options.Config.MCP.RBACTokens()
+	if err != nil {
+		return nil, err
+	}
+	servers := make(map[string]*mcp.Server, len(options.Config.MCP.Tenants)+1)
+	if servers[""], err = NewServer(options); err != nil {
+		return nil, err
+	}
+	for _, tenant := range options.Config.MCP.Tenants {
+		cfg, err := options.Config.ForTenant(tenant)
+		if err != nil {
+			return nil, fmt.Errorf("mcp.tenants %s: %w", tenant.Name, err)
+		}
+		if servers[tenant.Name], err = NewServer(ServerOptions{Config: cfg, Tenant: tenant.Name}); err != nil {
+			return nil, fmt.Errorf("mcp.tenants %s: %w", tenant.Name, err)
+		}
+	}
+	for _, token := range configured {
+		if _, ok := servers[token.Tenant]; !ok {
+			return nil, fmt.Errorf("mcp.tokens %s: unknown tenant %q", token.Name, token.Tenant)
+		}
+	}
+
+	var handler http.Handler = mcp.NewStreamableHTTPHandler(func(r *http.Request) *mcp.Server {
+		return servers[tenantOf(r)]
+	}, &mcp.StreamableHTTPOptions{
+		Logger: logger.For(logger.SubsystemMCP),
+	})
+	if len(configured) == 0 {
+		return handler, nil
+	}
+	tokens, err := rbac.NewTokens(configured)
+	if err != nil {
+		return nil, fmt.Errorf("mcp.tokens: %w", err)
+	}
+	return auth.RequireBearerToken(verifyToken(tokens), nil)(handler), nil
+}
+
+// RunHTTP serves the MCP server over streamable HTTP on addr until ctx is
+// canceled.
+func RunHTTP(ctx context.Context, cfg *config.Config, addr string) error {
+	handler, err := NewHTTPHandler(ServerOptions{Config: cfg})
+	if err != nil {
+		return err
+	}
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return fmt.Errorf("failed to listen for MCP: %w", err)
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	startBackground(ctx, cfg)
+
+	server := &http.Server{
+		Handler:           handler,
+		ReadHeaderTimeout: 5 * time.Second,
+	}
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), 5*time.Second)
+		defer cancel()
+		_ = server.Shutdown(shutdownCtx)
+	}()
+
+	log := logger.For(logger.SubsystemMCP)
+	log.Info("serving MCP over HTTP", "addr", listener.Addr().String(), "tokens", len(cfg.MCP.Tokens), "tenants", len(cfg.MCP.Tenants))
+	if len(cfg.MCP.Tokens) == 0 && !loopback(listener.Addr()) {
+		log.Warn("MCP over HTTP accepts every client with every tool; set mcp.tokens to require bearer tokens", "addr", listener.Addr().String())
+	}
+	if err := server.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return fmt.Errorf("MCP server failed: %w", err)
+	}
+	return nil
+}
+
+// startBackground hot-reloads cfg, evaluates alerts, and applies retention
+// until ctx is canceled. Retention covers the journals of every tenant;
+// alerts are the operator's, since tenants have no notification channels.
+func startBackground(ctx context.Context, cfg *config.Config) {
+	go func() {
+		if err := core.WatchConfig(ctx, cfg, nil); err != nil {
+			logger.For(logger.SubsystemMCP).Warn("config hot reload disabled", "error", err)
+		}
+	}()
+	_ = core.StartAlerts(ctx, cfg, nil)
+	core.StartRetention(ctx, cfg)
+}
@@ -3,6 +3,8 @@ package jmcp
 import (
 	"context"
 	"encoding/json"
+	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/SamyRai/juleson/internal/config"
@@ -43,7 +45,7 @@ func TestServerRegistersCoreToolsAndVersion(t *testing.T) {
 		}
 		tools[tool.Name] = true
 	}
-	for _, name := range []string{"version", "list_sources", "get_session_plans", "review_session", "dev_build"} {
+	for _, name := range []string{"version", "list_sources", "get_session_plans", "review_session", "dev_build", "docker_run", "docker_logs", "k8s_apply", "k8s_pods", "terraform_plan", "git_status", "git_commit", "dev_impact", "dev_smells", "dev_secrets", "analyze_deps", "analyze_hotspots", "analyze_project", "list_templates", "execute_template", "get_run_report", "generate_commit_messages"} {
 		if !tools[name] {
 			t.Fatalf("expected tool %q to be registered; got %#v", name, tools)
 		}
@@ -65,3 +67,76 @@ func TestServerRegistersCoreToolsAndVersion(t *testing.T) {
 		t.Fatalf("version = %q, want test-version", output.Version)
 	}
 }
+
+func TestTenantServerOmitsHostTools(t *testing.T) {
+	server, err := NewServer(ServerOptions{Config: &config.Config{}, Tenant: "team-a"})
+	if err != nil {
+		t.Fatalf("NewServer: %v", err)
+	}
+
+	ctx := context.Background()
+	client := mcp.NewClient(&mcp.Implementation{Name: "test-client", Version: "v0.0.1"}, nil)
+	serverTransport, clientTransport := mcp.NewInMemoryTransports()
+	serverSession, err := server.Connect(ctx, serverTransport, nil)
+	if err != nil {
+		t.Fatalf("server connect: %v", err)
+	}
+	defer func() { _ = serverSession.Close() }()
+	clientSession, err := client.Connect(ctx, clientTransport, nil)
+	if err != nil {
+		t.Fatalf("client connect: %v", err)
+	}
+	defer func() { _ = clientSession.Close() }()
+
+	tools := map[string]bool{}
+	for tool, err := range clientSession.Tools(ctx, nil) {
+		if err != nil {
+			t.Fatalf("list tools: %v", err)
+		}
+		tools[tool.Name] = true
+	}
+	if !tools["list_sessions"] || !tools["execute_template"] {
+		t.Errorf("tenant tools = %v, want the Jules tools", tools)
+	}
+	for _, name := range []string{"git_status", "git_commit", "git_checkout", "git_stash", "dev_build", "docker_run", "docker_logs", "k8s_pods", "k8s_logs", "k8s_apply", "k8s_rollout_restart", "terraform_validate", "terraform_plan", "analyze_project"} {
+		if tools[name] {
+			t.Errorf("tenant server registers host-local tool %q", name)
+		}
+	}
+}
+
+func TestLineWriterSplitsLines(t *testing.T) {
+	var lines []string
+	w := &lineWriter{prefix: "stderr: ", emit: func(line string) { lines = append(lines, line) }}
+
+	_, _ = w.Write([]byte("first\r\nsec"))
+	_, _ = w.Write([]byte("ond\nthird"))
+	w.Flush()
+
+	want := []string{"stderr: first", "stderr: second", "stderr: third"}
+	if len(lines) != len(want) {
+		t.Fatalf("lines = %q, want %q", lines, want)
+	}
+	for i := range want {
+		if lines[i] != want[i] {
+			t.Errorf("lines[%d] = %q, want %q", i, lines[i], want[i])
+		}
+	}
+}
+
+func TestProjectDirStaysInWorkingDirectory(t *testing.T) {
+	root := t.TempDir()
+	if err := os.Mkdir(filepath.Join(root, "infra"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	t.Chdir(root)
+
+	if _, err := projectDir("infra"); err != nil {
+		t.Errorf("projectDir(infra) error = %v", err)
+	}
+	for _, dir := range []string{"..", t.TempDir(), "missing"} {
+		if _, err := projectDir(dir); err == nil {
+			t.Errorf("projectDir(%q) should fail", dir)
+		}
+	}
+}
@@ -0,0 +1,271 @@
+// Package migrate plans a language or framework version migration as a
+// sequence of phases, one version step each, so every step can be built,
+// checked, and reviewed before the next one starts.
+package migrate
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/SamyRai/juleson/internal/jules/workspace"
+)
+
+// riskyFileCount is the number of changed files past which a phase is
+// reviewed by a person before the migration goes on.
+const riskyFileCount = 25
+
+// Version is a named version such as go1.21, react@18, or node20.
+type Version struct {
+	Name string
+	// Separator is what stood between the name and the number: "@", "-",
+	// or nothing.
+	Separator string
+	// Numbers are the dotted version components, such as [1 21].
+	Numbers []int
+}
+
+var versionPattern = regexp.MustCompile(`^(@?[A-Za-z][\w./-]*?)([@-]?)v?(\d+(?:\.\d+){0,2})$`)
+
+// ParseVersion parses a name followed by a version number.
+func ParseVersion(s string) (Version, error) {
+	match := versionPattern.FindStringSubmatch(strings.TrimSpace(s))
+	if match == nil {
+		return Version{}, fmt.Errorf("invalid version %q: expected a name and a number, such as go1.21 or react@18", s)
+	}
+	v := Version{Name: strings.ToLower(match[1]), Separator: match[2]}
+	for _, part := range strings.Split(match[3], ".") {
+		n, _ := strconv.Atoi(part)
+		v.Numbers = append(v.Numbers, n)
+	}
+	return v, nil
+}
+
+// String formats the version as it was written.
+func (v Version) String() string {
+	parts := make([]string, len(v.Numbers))
+	for i, n := range v.Numbers {
+		parts[i] = strconv.Itoa(n)
+	}
+	return v.Name + v.Separator + strings.Join(parts, ".")
+}
+
+// IsGo reports whether the version is a Go toolchain version.
+func (v Version) IsGo() bool {
+	return v.Name == "go" && len(v.Numbers) >= 2 && v.Numbers[0] == 1
+}
+
+// number returns the i-th component, zero when it is missing.
+func (v Version) number(i int) int {
+	if i < len(v.Numbers) {
+		return v.Numbers[i]
+	}
+	return 0
+}
+
+func (v Version) compare(other Version) int {
+	for i := range max(len(v.Numbers), len(other.Numbers)) {
+		if d := v.number(i) - other.number(i); d != 0 {
+			return d
+		}
+	}
+	return 0
+}
+
+// with returns the version with the given components.
+func (v Version) with(numbers ...int) Version {
+	v.Numbers = numbers
+	return v
+}
+
+// Phase is one version step of a migration.
+type Phase struct {
+	Number int    `json:"number"`
+	From   string `json:"from"`
+	To     string `json:"to"`
+	// Notes are the changes of the version worth acting on.
+	Notes []string `json:"notes,omitempty"`
+	// Risks are known reasons for a person to review the phase before the
+	// migration goes on.
+	Risks []string `json:"risks,omitempty"`
+	// ReleaseNotes links the version's release notes, when known.
+	ReleaseNotes string `json:"releaseNotes,omitempty"`
+}
+
+// Branch returns the name of the branch a phase is committed to.
+func (p Phase) Branch() string {
+	return "juleson/migrate/" + strings.Trim(branchUnsafe.ReplaceAllString(p.To, "-"), "-.")
+}
+
+var branchUnsafe = regexp.MustCompile(`[^a-zA-Z0-9._-]+`)
+
+// Plan is a migration split into phases.
+type Plan struct {
+	From   string  `json:"from"`
+	To     string  `json:"to"`
+	Phases []Phase `json:"phases"`
+}
+
+// NewPlan splits the migration from one version to another into phases: one
+// per minor release for Go, which changes the language in minor releases, and
+// one per major release otherwise.
+func NewPlan(from, to string) (*Plan, error) {
+	start, err := ParseVersion(from)
+	if err != nil {
+		return nil, err
+	}
+	end, err := ParseVersion(to)
+	if err != nil {
+		return nil, err
+	}
+	if start.Name != end.Name {
+		return nil, fmt.Errorf("cannot migrate from %s to %s: they are not versions of the same thing", from, to)
+	}
+	if start.compare(end) >= 0 {
+		return nil, fmt.Errorf("%s is not newer than %s", end, start)
+	}
+
+	// steps are the versions each phase migrates to, end last.
+	var steps []Version
+	switch {
+	case start.IsGo() && end.IsGo():
+		for minor := start.number(1) + 1; minor < end.number(1); minor++ {
+			steps = append(steps, end.with(1, minor))
+		}
+	default:
+		for major := start.number(0) + 1; major < end.number(0); major++ {
+			steps = append(steps, end.with(major))
+		}
+	}
+	steps = append(steps, end)
+
+	plan := &Plan{From: start.String(), To: end.String()}
+	previous := start
+	for i, step := range steps {
+		phase := Phase{Number: i + 1, From: previous.String(), To: step.String()}
+		if step.IsGo() {
+			notes := goReleases[step.number(1)]
+			phase.Notes, phase.Risks = notes.notes, notes.risks
+			phase.ReleaseNotes = fmt.Sprintf("https://go.dev/doc/go1.%d", step.number(1))
+		} else if step.number(0) != previous.number(0) {
+			phase.Risks = []string{fmt.Sprintf("major release of %s, which may break compatibility", step.Name)}
+		}
+		plan.Phases = append(plan.Phases, phase)
+		previous = step
+	}
+	return plan, nil
+}
+
+// goRelease is what a Go release changes for existing code.
+type goRelease struct {
+	notes []string
+	risks []string
+}
+
+// goReleases are the changes of recent Go releases, by minor version, that a
+// migration may act on.
+var goReleases = map[int]goRelease{
+	21: {notes: []string{
+		"min, max, and clear are built in, and the log/slog, slices, maps, and cmp packages join the standard library",
+		"the go line in go.mod is a minimum requirement, enforced by the toolchain, which may add a toolchain line",
+	}},
+	22: {
+		notes: []string{
+			"loop variables are created per iteration; copies such as x := x that only avoided sharing them can go",
+			"for loops can range over integers, and math/rand/v2 is added",
+			"net/http.ServeMux patterns accept methods and wildcards",
+		},
+		risks: []string{"loop variables become per-iteration, which changes code that relied on sharing them across iterations"},
+	},
+	23: {
+		notes: []string{
+			"for loops can range over iterator functions, with the iter package",
+			"unstopped timers and tickers are garbage collected, and their channels are unbuffered",
+		},
+		risks: []string{"timer and ticker channels become unbuffered, which changes code that relied on a stale value after Reset or Stop"},
+	},
+	24: {notes: []string{
+		"type aliases can be generic",
+		"tool dependencies are tracked with tool directives in go.mod",
+		"go vet reports Printf-style calls with a non-constant format and no other arguments",
+		"os.Root confines file access to a directory, and encoding/json supports the omitzero option",
+	}},
+}
+
+// Prompt asks Jules to carry out one phase of a plan.
+func Prompt(plan *Plan, phase Phase) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Migrate this project from %s to %s.", phase.From, phase.To)
+	if len(plan.Phases) > 1 {
+		fmt.Fprintf(&b, " This is phase %d of %d of a migration from %s to %s; do only this step.", phase.Number, len(plan.Phases), plan.From, plan.To)
+	}
+	b.WriteString("\n\n")
+	to, _ := ParseVersion(phase.To)
+	if to.IsGo() {
+		fmt.Fprintf(&b, "Set the go line in go.mod (and the toolchain line, if there is one) to %s, and update the Go version used by CI and container images to match.\n", strings.TrimPrefix(phase.To, "go"))
+	} else {
+		fmt.Fprintf(&b, "Upgrade %s to %s wherever the project declares its version, following the official upgrade or migration guide.\n", to.Name, strings.TrimPrefix(phase.To, to.Name+to.Separator))
+	}
+	if phase.ReleaseNotes != "" {
+		fmt.Fprintf(&b, "Read the release notes at %s.\n", phase.ReleaseNotes)
+	}
+	if len(phase.Notes) > 0 {
+		b.WriteString("\nChanges in this release that may affect the code:\n\n")
+		for _, note := range phase.Notes {
+			fmt.Fprintf(&b, "- %s\n", note)
+		}
+	}
+	if len(phase.Risks) > 0 {
+		b.WriteString("\nCheck carefully, and explain in your summary, where the code depends on:\n\n")
+		for _, risk := range phase.Risks {
+			fmt.Fprintf(&b, "- %s\n", risk)
+		}
+	}
+	b.WriteString(`
+Replace APIs the release deprecates or removes, and adopt new features only
+where they replace code the release makes unnecessary. Do not make unrelated
+changes. The project must build and pass its static checks and tests after
+this step on its own.
+
+End with a summary of the changes and of anything a reviewer should check.
+`)
+	return b.String()
+}
+
+// DefaultChecks returns the compilation checks run between phases for the
+// project in dir: build and vet for Go modules. Other projects return nil,
+// which runs their detected test command.
+func DefaultChecks(dir string) []string {
+	if _, err := os.Stat(filepath.Join(dir, "go.mod")); err == nil {
+		return []string{"go build ./...", "go vet ./..."}
+	}
+	return nil
+}
+
+// Review sorts what a phase's changes need before the migration goes on, as
+// the session review does: blockers stop it, and risks need a person to
+// approve continuing.
+func Review(changes *workspace.SessionChanges) (blockers, risks []string) {
+	if changes == nil {
+		return nil, nil
+	}
+	if n := len(changes.SecretFindings); n > 0 {
+		blockers = append(blockers, fmt.Sprintf("the changes add %d possible secret(s)", n))
+	}
+	var deleted []string
+	for _, file := range changes.Files {
+		if file.Status == workspace.FileDeleted {
+			deleted = append(deleted, file.Path)
+		}
+	}
+	if len(changes.Files) > riskyFileCount {
+		risks = append(risks, fmt.Sprintf("the changes touch %d files", len(changes.Files)))
+	}
+	if len(deleted) > 0 {
+		risks = append(risks, "the changes delete "+strings.Join(deleted, ", "))
+	}
+	return blockers, risks
+}
@@ -0,0 +1,119 @@
+package migrate
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/SamyRai/juleson/internal/intelligence"
+	"github.com/SamyRai/juleson/internal/jules/workspace"
+)
+
+func TestParseVersion(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+		name string
+	}{
+		{"go1.21", "go1.21", "go"},
+		{"react@18", "react@18", "react"},
+		{"node20", "node20", "node"},
+		{"@angular/core@v17.1", "@angular/core@17.1", "@angular/core"},
+		{"Spring-Boot-3", "spring-boot-3", "spring-boot"},
+	}
+	for _, tt := range tests {
+		v, err := ParseVersion(tt.in)
+		if err != nil {
+			t.Fatalf("ParseVersion(%q) error = %v", tt.in, err)
+		}
+		if v.String() != tt.want || v.Name != tt.name {
+			t.Errorf("ParseVersion(%q) = %s (name %q), want %s (name %q)", tt.in, v, v.Name, tt.want, tt.name)
+		}
+	}
+	if _, err := ParseVersion("latest"); err == nil {
+		t.Error("ParseVersion(latest) succeeded")
+	}
+}
+
+func TestNewPlan(t *testing.T) {
+	plan, err := NewPlan("go1.21", "go1.23.2")
+	if err != nil {
+		t.Fatalf("NewPlan() error = %v", err)
+	}
+	var steps []string
+	for _, phase := range plan.Phases {
+		steps = append(steps, phase.From+"→"+phase.To)
+	}
+	if want := []string{"go1.21→go1.22", "go1.22→go1.23.2"}; !reflect.DeepEqual(steps, want) {
+		t.Errorf("phases = %q, want %q", steps, want)
+	}
+	first := plan.Phases[0]
+	if first.Branch() != "juleson/migrate/go1.22" || first.ReleaseNotes != "https://go.dev/doc/go1.22" || len(first.Risks) != 1 {
+		t.Errorf("first phase = %+v", first)
+	}
+
+	plan, err = NewPlan("react@16", "react@18")
+	if err != nil {
+		t.Fatalf("NewPlan() error = %v", err)
+	}
+	if len(plan.Phases) != 2 || plan.Phases[0].To != "react@17" || plan.Phases[1].To != "react@18" || len(plan.Phases[1].Risks) != 1 {
+		t.Errorf("react phases = %+v", plan.Phases)
+	}
+
+	for _, tt := range [][2]string{{"go1.23", "go1.21"}, {"react@17", "vue@3"}, {"go1.21", "go1.21"}} {
+		if _, err := NewPlan(tt[0], tt[1]); err == nil {
+			t.Errorf("NewPlan(%s, %s) succeeded", tt[0], tt[1])
+		}
+	}
+}
+
+func TestPrompt(t *testing.T) {
+	plan, err := NewPlan("go1.21", "go1.23")
+	if err != nil {
+		t.Fatal(err)
+	}
+	prompt := Prompt(plan, plan.Phases[1])
+	for _, want := range []string{
+		"from go1.22 to go1.23",
+		"phase 2 of 2 of a migration from go1.21 to go1.23",
+		"go line in go.mod (and the toolchain line, if there is one) to 1.23",
+		"https://go.dev/doc/go1.23",
+		"iterator functions",
+		"timer and ticker channels",
+	} {
+		if !strings.Contains(prompt, want) {
+			t.Errorf("prompt is missing %q:\n%s", want, prompt)
+		}
+	}
+
+	plan, err = NewPlan("react@17", "react@18")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if prompt := Prompt(plan, plan.Phases[0]); !strings.Contains(prompt, "Upgrade react to 18 wherever") || strings.Contains(prompt, "phase 1") {
+		t.Errorf("react prompt = %s", prompt)
+	}
+}
+
+func TestReview(t *testing.T) {
+	changes := &workspace.SessionChanges{
+		Files:          []workspace.FileChange{{Path: "go.mod"}, {Path: "old.go", Status: workspace.FileDeleted}},
+		SecretFindings: []intelligence.SecretFinding{{}},
+	}
+	blockers, risks := Review(changes)
+	if len(blockers) != 1 || !strings.Contains(blockers[0], "1 possible secret") {
+		t.Errorf("blockers = %q", blockers)
+	}
+	if !reflect.DeepEqual(risks, []string{"the changes delete old.go"}) {
+		t.Errorf("risks = %q", risks)
+	}
+
+	changes = &workspace.SessionChanges{}
+	for i := range riskyFileCount + 1 {
+		changes.Files = append(changes.Files, workspace.FileChange{Path: fmt.Sprintf("f%d.go", i)})
+	}
+	if blockers, risks := Review(changes); blockers != nil || len(risks) != 1 {
+		t.Errorf("Review() = %q, %q", blockers, risks)
+	}
+}
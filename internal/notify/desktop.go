@@ -0,0 +1,82 @@
+package notify
+
+import (
+	"context"
+	"fmt"
+	"os/exec"
+	"runtime"
+	"strings"
+
+	"github.com/SamyRai/juleson/internal/events"
+)
+
+// runCommand runs a notification command, replaced in tests.
+var runCommand = func(ctx context.Context, name string, args ...string) error {
+	output, err := exec.CommandContext(ctx, name, args...).CombinedOutput()
+	if err != nil && len(output) > 0 {
+		return fmt.Errorf("%w: %s", err, strings.TrimSpace(string(output)))
+	}
+	return err
+}
+
+// DesktopSender shows messages as native desktop notifications: with
+// notify-send on Linux, osascript on macOS, and PowerShell on Windows.
+type DesktopSender struct {
+	goos string
+}
+
+// NewDesktopSender creates a sender for the current platform.
+func NewDesktopSender() *DesktopSender {
+	return &DesktopSender{goos: runtime.GOOS}
+}
+
+// Send shows message on the desktop.
+func (s *DesktopSender) Send(ctx context.Context, message Message) error {
+	name, args, err := desktopCommand(s.goos, message)
+	if err != nil {
+		return err
+	}
+	if err := runCommand(ctx, name, args...); err != nil {
+		return fmt.Errorf("failed to run %s: %w", name, err)
+	}
+	return nil
+}
+
+// desktopCommand returns the command that shows message on goos.
+func desktopCommand(goos string, message Message) (string, []string, error) {
+	body := message.Body
+	if message.URL != "" {
+		body = strings.TrimSpace(body + "\n" + message.URL)
+	}
+	switch goos {
+	case "linux", "freebsd", "openbsd", "netbsd":
+		urgency := "normal"
+		if message.Severity >= events.SeverityError {
+			urgency = "critical"
+		}
+		return "notify-send", []string{"--app-name=juleson", "--urgency=" + urgency, message.Title, body}, nil
+	case "darwin":
+		script := fmt.Sprintf("display notification %s with title %s", appleScriptString(body), appleScriptString(message.Title))
+		return "osascript", []string{"-e", script}, nil
+	case "windows":
+		script := "Add-Type -AssemblyName System.Windows.Forms; " +
+			"$n = New-Object System.Windows.Forms.NotifyIcon; " +
+			"$n.Icon = [System.Drawing.SystemIcons]::Information; $n.Visible = $true; " +
+			fmt.Sprintf("$n.ShowBalloonTip(10000, %s, %s, 'Info'); Start-Sleep -Seconds 10; $n.Dispose()",
+				powerShellString(message.Title), powerShellString(body))
+		return "powershell", []string{"-NoProfile", "-NonInteractive", "-Command", script}, nil
+	default:
+		return "", nil, fmt.Errorf("desktop notifications are not supported on %s", goos)
+	}
+}
+
+func appleScriptString(value string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(value) + `"`
+}
+
+func powerShellString(value string) string {
+	if value == "" {
+		value = " "
+	}
+	return "'" + strings.ReplaceAll(value, "'", "''") + "'"
+}
@@ -0,0 +1,77 @@
+package notify
+
+import (
+	"context"
+	"fmt"
+	"mime"
+	"net"
+	"net/smtp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// EmailConfig configures delivery through an SMTP server.
+type EmailConfig struct {
+	Host string
+	// Port defaults to 587.
+	Port int
+	// Username and Password authenticate with PLAIN auth when Username is
+	// set.
+	Username string
+	Password string
+	From     string
+	To       []string
+}
+
+// sendMail is smtp.SendMail, replaced in tests.
+var sendMail = smtp.SendMail
+
+// EmailSender mails messages through an SMTP server.
+type EmailSender struct {
+	cfg EmailConfig
+}
+
+// NewEmailSender creates a sender for the configured server.
+func NewEmailSender(cfg EmailConfig) *EmailSender {
+	if cfg.Port == 0 {
+		cfg.Port = 587
+	}
+	return &EmailSender{cfg: cfg}
+}
+
+// Send mails message to the configured recipients.
+func (s *EmailSender) Send(ctx context.Context, message Message) error {
+	var auth smtp.Auth
+	if s.cfg.Username != "" {
+		auth = smtp.PlainAuth("", s.cfg.Username, s.cfg.Password, s.cfg.Host)
+	}
+	addr := net.JoinHostPort(s.cfg.Host, strconv.Itoa(s.cfg.Port))
+	done := make(chan error, 1)
+	go func() {
+		done <- sendMail(addr, auth, s.cfg.From, s.cfg.To, s.compose(message, time.Now()))
+	}()
+	select {
+	case err := <-done:
+		if err != nil {
+			return fmt.Errorf("failed to send email: %w", err)
+		}
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("failed to send email: %w", ctx.Err())
+	}
+}
+
+// compose returns message as an RFC 5322 email.
+func (s *EmailSender) compose(message Message, now time.Time) []byte {
+	var b strings.Builder
+	fmt.Fprintf(&b, "From: %s\r\n", s.cfg.From)
+	fmt.Fprintf(&b, "To: %s\r\n", strings.Join(s.cfg.To, ", "))
+	fmt.Fprintf(&b, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", "[juleson] "+message.Title))
+	fmt.Fprintf(&b, "Date: %s\r\n", now.Format(time.RFC1123Z))
+	b.WriteString("MIME-Version: 1.0\r\n")
+	b.WriteString("Content-Type: text/plain; charset=utf-8\r\n\r\n")
+	b.WriteString(strings.ReplaceAll(message.Text(), "\n", "\r\n"))
+	b.WriteString("\r\n")
+	return []byte(b.String())
+}
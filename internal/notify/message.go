@@ -0,0 +1,84 @@
+package notify
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/SamyRai/juleson/internal/events"
+)
+
+// Message is a notification rendered from an event.
+type Message struct {
+	Title string
+	Body  string
+	// URL links to the session, pull request, or other subject, if any.
+	URL      string
+	Severity events.Severity
+}
+
+// Text returns the message as plain text.
+func (m Message) Text() string {
+	text := m.Title
+	if m.Body != "" {
+		text += "\n" + m.Body
+	}
+	if m.URL != "" {
+		text += "\n" + m.URL
+	}
+	return text
+}
+
+// Render describes event for a person.
+func Render(event events.Event) Message {
+	message := Message{Severity: events.EventSeverity(event)}
+	switch event.Type {
+	case events.EventSessionCompleted, events.EventSessionFailed, events.EventPlanAwaitingApproval:
+		data, _ := events.DecodeData[events.SessionEventData](event)
+		name := data.Title
+		if name == "" {
+			name = data.SessionID
+		}
+		switch event.Type {
+		case events.EventSessionCompleted:
+			message.Title = "Jules session completed: " + name
+		case events.EventSessionFailed:
+			message.Title = "Jules session failed: " + name
+		default:
+			message.Title = "Jules plan awaiting approval: " + name
+		}
+		var lines []string
+		if data.SourceID != "" {
+			lines = append(lines, "Source: "+data.SourceID)
+		}
+		if data.Error != "" {
+			lines = append(lines, "Error: "+data.Error)
+		}
+		if event.Type == events.EventPlanAwaitingApproval {
+			lines = append(lines, fmt.Sprintf("Review it with 'juleson sessions plan %s'.", data.SessionID))
+		}
+		message.Body = strings.Join(lines, "\n")
+		message.URL = data.URL
+	case events.EventPRCreated:
+		data, _ := events.DecodeData[events.GitHubEventData](event)
+		message.Title = fmt.Sprintf("Pull request opened: %s#%d", data.Repository, data.PRNumber)
+		message.URL = data.PRURL
+	case events.EventCircuitBreakerOpened:
+		data, _ := events.DecodeData[events.CircuitBreakerEventData](event)
+		message.Title = fmt.Sprintf("Circuit breaker %s opened", data.Name)
+		message.Body = "Calls are rejected after repeated failures"
+		if data.ResetTimeout > 0 {
+			message.Body += fmt.Sprintf(" and will be retried after %s", data.ResetTimeout)
+		}
+		message.Body += "."
+	case events.EventAlertFired:
+		data, _ := events.DecodeData[events.AlertEventData](event)
+		message.Title = "Alert: " + data.Rule
+		message.Body = data.Message
+	default:
+		message.Title = string(event.Type)
+		if session := events.SessionKey(event); session != "" {
+			message.Body = "Session: " + session
+		}
+	}
+	return message
+}
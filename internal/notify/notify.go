@@ -0,0 +1,236 @@
+// Package notify delivers key events, such as finished sessions and plans
+// awaiting approval, to Slack, email, and desktop notifications.
+package notify
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"path"
+	"slices"
+	"time"
+
+	"github.com/SamyRai/juleson/internal/events"
+)
+
+// Channel names used in routing rules.
+const (
+	ChannelSlack   = "slack"
+	ChannelEmail   = "email"
+	ChannelDesktop = "desktop"
+)
+
+// DefaultEvents are the event types sent to every configured channel when
+// no rules are configured.
+var DefaultEvents = []string{
+	string(events.EventSessionCompleted),
+	string(events.EventSessionFailed),
+	string(events.EventPlanAwaitingApproval),
+	string(events.EventPRCreated),
+	string(events.EventCircuitBreakerOpened),
+	string(events.EventAlertFired),
+}
+
+// sendTimeout bounds each delivery.
+const sendTimeout = 10 * time.Second
+
+// Sender delivers messages to one channel.
+type Sender interface {
+	Send(ctx context.Context, message Message) error
+}
+
+// Rule routes matching events to channels. Empty Types and Topics match
+// every event.
+type Rule struct {
+	// Types are event type patterns, such as "session.*".
+	Types  []string
+	Topics []string
+	// Match limits the rule to a session, a repository, or a minimum
+	// severity.
+	Match    events.EventMatch
+	Channels []string
+}
+
+// Matches reports whether the rule applies to event.
+func (r Rule) Matches(event events.Event) bool {
+	if len(r.Topics) > 0 && !slices.Contains(r.Topics, event.Topic) {
+		return false
+	}
+	if len(r.Types) > 0 && !slices.ContainsFunc(r.Types, func(pattern string) bool {
+		ok, _ := path.Match(pattern, string(event.Type))
+		return ok
+	}) {
+		return false
+	}
+	return r.Match.Matches(event)
+}
+
+// Config selects the channels to deliver to and how events are routed.
+type Config struct {
+	Slack   SlackConfig
+	Email   EmailConfig
+	Desktop bool
+	// Rules route events to channels. Each event goes to the channels of
+	// every matching rule, once each. Without rules, DefaultEvents go to
+	// every configured channel.
+	Rules []Rule
+}
+
+// Channels returns the names of the configured channels.
+func (c Config) Channels() []string {
+	var channels []string
+	if c.Slack.WebhookURL != "" {
+		channels = append(channels, ChannelSlack)
+	}
+	if c.Email.Host != "" {
+		channels = append(channels, ChannelEmail)
+	}
+	if c.Desktop {
+		channels = append(channels, ChannelDesktop)
+	}
+	return channels
+}
+
+// ValidateConfig checks that channels are complete and that rules name
+// configured channels and well-formed event patterns.
+func ValidateConfig(cfg Config) error {
+	var errs []error
+	if cfg.Email.Host != "" && (cfg.Email.From == "" || len(cfg.Email.To) == 0) {
+		errs = append(errs, errors.New("email requires from and to"))
+	}
+	if cfg.Email.Port < 0 || cfg.Email.Port > 65535 {
+		errs = append(errs, fmt.Errorf("invalid email port %d", cfg.Email.Port))
+	}
+	configured := cfg.Channels()
+	for i, rule := range cfg.Rules {
+		if len(rule.Channels) == 0 {
+			errs = append(errs, fmt.Errorf("rules[%d]: no channels", i))
+		}
+		for _, channel := range rule.Channels {
+			switch channel {
+			case ChannelSlack, ChannelEmail, ChannelDesktop:
+				if !slices.Contains(configured, channel) {
+					errs = append(errs, fmt.Errorf("rules[%d]: channel %q is not configured", i, channel))
+				}
+			default:
+				errs = append(errs, fmt.Errorf("rules[%d]: unknown channel %q (use slack, email, or desktop)", i, channel))
+			}
+		}
+		for _, pattern := range rule.Types {
+			if _, err := path.Match(pattern, ""); err != nil || pattern == "" {
+				errs = append(errs, fmt.Errorf("rules[%d]: invalid event pattern %q", i, pattern))
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Notifier routes events to channels by rule.
+type Notifier struct {
+	senders map[string]Sender
+	rules   []Rule
+	logger  *slog.Logger
+}
+
+// New creates a notifier for the configured channels.
+func New(cfg Config, logger *slog.Logger) *Notifier {
+	senders := map[string]Sender{}
+	if cfg.Slack.WebhookURL != "" {
+		senders[ChannelSlack] = NewSlackSender(cfg.Slack)
+	}
+	if cfg.Email.Host != "" {
+		senders[ChannelEmail] = NewEmailSender(cfg.Email)
+	}
+	if cfg.Desktop {
+		senders[ChannelDesktop] = NewDesktopSender()
+	}
+	return NewWithSenders(senders, cfg.Rules, logger)
+}
+
+// NewWithSenders creates a notifier delivering to senders by channel name.
+func NewWithSenders(senders map[string]Sender, rules []Rule, logger *slog.Logger) *Notifier {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	if len(rules) == 0 && len(senders) > 0 {
+		channels := make([]string, 0, len(senders))
+		for channel := range senders {
+			channels = append(channels, channel)
+		}
+		slices.Sort(channels)
+		rules = []Rule{{Types: DefaultEvents, Channels: channels}}
+	}
+	return &Notifier{senders: senders, rules: rules, logger: logger}
+}
+
+// Enabled reports whether any channel is configured.
+func (n *Notifier) Enabled() bool {
+	return n != nil && len(n.senders) > 0
+}
+
+// Channels returns the channels event is routed to.
+func (n *Notifier) Channels(event events.Event) []string {
+	var channels []string
+	for _, rule := range n.rules {
+		if !rule.Matches(event) {
+			continue
+		}
+		for _, channel := range rule.Channels {
+			if _, ok := n.senders[channel]; ok && !slices.Contains(channels, channel) {
+				channels = append(channels, channel)
+			}
+		}
+	}
+	return channels
+}
+
+// Notify delivers event to the channels its rules route it to and returns
+// the delivery errors.
+func (n *Notifier) Notify(ctx context.Context, event events.Event) error {
+	if !n.Enabled() {
+		return nil
+	}
+	channels := n.Channels(event)
+	if len(channels) == 0 {
+		return nil
+	}
+	return n.Send(ctx, Render(event), channels...)
+}
+
+// Send delivers message to the named channels.
+func (n *Notifier) Send(ctx context.Context, message Message, channels ...string) error {
+	var errs []error
+	for _, channel := range channels {
+		sender, ok := n.senders[channel]
+		if !ok {
+			errs = append(errs, fmt.Errorf("%s: channel is not configured", channel))
+			continue
+		}
+		sendCtx, cancel := context.WithTimeout(ctx, sendTimeout)
+		err := sender.Send(sendCtx, message)
+		cancel()
+		if err != nil {
+			n.logger.Warn("failed to send notification", "channel", channel, "title", message.Title, "error", err)
+			errs = append(errs, fmt.Errorf("%s: %w", channel, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Subscriber returns an event bus subscriber that notifies about the
+// events it receives. Delivery errors are logged and not returned, so a
+// failing channel never fails the publisher.
+func (n *Notifier) Subscriber() events.Subscriber {
+	return events.Subscriber{
+		ID:    "notifier",
+		Async: true,
+		Filter: func(event events.Event) bool {
+			return len(n.Channels(event)) > 0
+		},
+		Handler: func(ctx context.Context, event events.Event) error {
+			_ = n.Notify(ctx, event)
+			return nil
+		},
+	}
+}
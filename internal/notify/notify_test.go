@@ -0,0 +1,214 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/smtp"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/SamyRai/juleson/internal/events"
+)
+
+type recordingSender struct {
+	messages []Message
+	err      error
+}
+
+func (s *recordingSender) Send(ctx context.Context, message Message) error {
+	s.messages = append(s.messages, message)
+	return s.err
+}
+
+func sessionEvent(eventType events.EventType) events.Event {
+	return events.NewEvent(eventType, "session", events.SessionEventData{
+		SessionID: "session-1",
+		Title:     "Fix tests",
+		SourceID:  "sources/github/acme/widgets",
+		URL:       "https://jules.google.com/session/session-1",
+	}).WithTopic(events.TopicSession)
+}
+
+func TestNotifierDefaultRuleSendsKeyEventsToEveryChannel(t *testing.T) {
+	slack, desktop := &recordingSender{}, &recordingSender{}
+	notifier := NewWithSenders(map[string]Sender{ChannelSlack: slack, ChannelDesktop: desktop}, nil, nil)
+
+	if err := notifier.Notify(context.Background(), sessionEvent(events.EventSessionFailed)); err != nil {
+		t.Fatalf("Notify() error = %v", err)
+	}
+	if err := notifier.Notify(context.Background(), sessionEvent(events.EventSessionCreated)); err != nil {
+		t.Fatalf("Notify() error = %v", err)
+	}
+	for name, sender := range map[string]*recordingSender{"slack": slack, "desktop": desktop} {
+		if len(sender.messages) != 1 || sender.messages[0].Title != "Jules session failed: Fix tests" {
+			t.Fatalf("%s messages = %+v", name, sender.messages)
+		}
+	}
+}
+
+func TestNotifierRoutesByRule(t *testing.T) {
+	senders := map[string]Sender{ChannelSlack: &recordingSender{}, ChannelEmail: &recordingSender{}, ChannelDesktop: &recordingSender{}}
+	notifier := NewWithSenders(senders, []Rule{
+		{Types: []string{"session.*"}, Channels: []string{ChannelDesktop}},
+		{Match: events.EventMatch{MinSeverity: events.SeverityError}, Channels: []string{ChannelSlack, ChannelDesktop}},
+		{Topics: []string{events.TopicGitHub}, Match: events.EventMatch{Repository: "acme/widgets"}, Channels: []string{ChannelEmail}},
+	}, nil)
+
+	tests := []struct {
+		name  string
+		event events.Event
+		want  []string
+	}{
+		{"completed", sessionEvent(events.EventSessionCompleted), []string{ChannelDesktop}},
+		{"failed", sessionEvent(events.EventSessionFailed), []string{ChannelDesktop, ChannelSlack}},
+		{"pr in repo", events.NewEvent(events.EventPRCreated, "session", events.GitHubEventData{Repository: "acme/widgets"}).WithTopic(events.TopicGitHub), []string{ChannelEmail}},
+		{"pr elsewhere", events.NewEvent(events.EventPRCreated, "session", events.GitHubEventData{Repository: "acme/other"}).WithTopic(events.TopicGitHub), nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := notifier.Channels(tt.event); !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("Channels() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNotifierSendReportsChannelErrors(t *testing.T) {
+	failing := &recordingSender{err: errors.New("boom")}
+	notifier := NewWithSenders(map[string]Sender{ChannelSlack: failing}, nil, nil)
+
+	err := notifier.Send(context.Background(), Message{Title: "hi"}, ChannelSlack, ChannelEmail)
+	if err == nil || !strings.Contains(err.Error(), "slack: boom") || !strings.Contains(err.Error(), "email: channel is not configured") {
+		t.Fatalf("Send() error = %v", err)
+	}
+}
+
+func TestValidateConfig(t *testing.T) {
+	err := ValidateConfig(Config{
+		Email: EmailConfig{Host: "smtp.example.com"},
+		Rules: []Rule{
+			{Channels: []string{ChannelSlack}},
+			{Types: []string{"["}, Channels: []string{"pager"}},
+			{},
+		},
+	})
+	if err == nil {
+		t.Fatal("ValidateConfig() error = nil")
+	}
+	for _, want := range []string{
+		"email requires from and to",
+		`rules[0]: channel "slack" is not configured`,
+		`rules[1]: unknown channel "pager"`,
+		`rules[1]: invalid event pattern "["`,
+		"rules[2]: no channels",
+	} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("ValidateConfig() error = %v, want %q", err, want)
+		}
+	}
+	if err := ValidateConfig(Config{Desktop: true, Rules: []Rule{{Channels: []string{ChannelDesktop}}}}); err != nil {
+		t.Fatalf("ValidateConfig() error = %v", err)
+	}
+}
+
+func TestRender(t *testing.T) {
+	plan := Render(sessionEvent(events.EventPlanAwaitingApproval))
+	if plan.Title != "Jules plan awaiting approval: Fix tests" ||
+		!strings.Contains(plan.Body, "juleson sessions plan session-1") ||
+		plan.URL != "https://jules.google.com/session/session-1" {
+		t.Fatalf("Render(plan) = %+v", plan)
+	}
+
+	pr := Render(events.NewEvent(events.EventPRCreated, "session", events.GitHubEventData{
+		Repository: "acme/widgets", PRNumber: 12, PRURL: "https://github.com/acme/widgets/pull/12",
+	}))
+	if pr.Title != "Pull request opened: acme/widgets#12" || pr.URL != "https://github.com/acme/widgets/pull/12" {
+		t.Fatalf("Render(pr) = %+v", pr)
+	}
+
+	breaker := Render(events.NewEvent(events.EventCircuitBreakerOpened, "juleson", events.CircuitBreakerEventData{
+		Name: "jules-api", ResetTimeout: 30 * time.Second,
+	}))
+	if breaker.Title != "Circuit breaker jules-api opened" || !strings.Contains(breaker.Body, "after 30s") || breaker.Severity != events.SeverityWarning {
+		t.Fatalf("Render(breaker) = %+v", breaker)
+	}
+}
+
+func TestSlackSenderPostsWebhook(t *testing.T) {
+	var payload map[string]string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewDecoder(r.Body).Decode(&payload)
+	}))
+	defer server.Close()
+
+	sender := NewSlackSender(SlackConfig{WebhookURL: server.URL})
+	if err := sender.Send(context.Background(), Message{Title: "Done", Body: "All good", URL: "https://example.com"}); err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+	if payload["text"] != "*Done*\nAll good\n<https://example.com>" {
+		t.Fatalf("payload = %v", payload)
+	}
+}
+
+func TestSlackSenderReportsErrorStatus(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "invalid_token", http.StatusForbidden)
+	}))
+	defer server.Close()
+
+	err := NewSlackSender(SlackConfig{WebhookURL: server.URL}).Send(context.Background(), Message{Title: "Done"})
+	if err == nil || !strings.Contains(err.Error(), "403 Forbidden: invalid_token") {
+		t.Fatalf("Send() error = %v", err)
+	}
+}
+
+func TestEmailSenderSendsMail(t *testing.T) {
+	original := sendMail
+	defer func() { sendMail = original }()
+
+	var gotAddr, gotFrom string
+	var gotTo []string
+	var gotMsg []byte
+	var gotAuth smtp.Auth
+	sendMail = func(addr string, auth smtp.Auth, from string, to []string, msg []byte) error {
+		gotAddr, gotAuth, gotFrom, gotTo, gotMsg = addr, auth, from, to, msg
+		return nil
+	}
+
+	sender := NewEmailSender(EmailConfig{Host: "smtp.example.com", Username: "bot", Password: "secret", From: "bot@example.com", To: []string{"dev@example.com"}})
+	if err := sender.Send(context.Background(), Message{Title: "Done", Body: "line one\nline two"}); err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+	if gotAddr != "smtp.example.com:587" || gotAuth == nil || gotFrom != "bot@example.com" || !reflect.DeepEqual(gotTo, []string{"dev@example.com"}) {
+		t.Fatalf("sendMail(%q, %v, %q, %v)", gotAddr, gotAuth, gotFrom, gotTo)
+	}
+	msg := string(gotMsg)
+	if !strings.Contains(msg, "Subject: [juleson] Done\r\n") || !strings.HasSuffix(msg, "\r\n\r\nDone\r\nline one\r\nline two\r\n") {
+		t.Fatalf("message = %q", msg)
+	}
+}
+
+func TestDesktopCommand(t *testing.T) {
+	message := Message{Title: `Say "hi"`, Body: "it's done", Severity: events.SeverityError}
+
+	name, args, err := desktopCommand("linux", message)
+	if err != nil || name != "notify-send" || !reflect.DeepEqual(args, []string{"--app-name=juleson", "--urgency=critical", `Say "hi"`, "it's done"}) {
+		t.Fatalf("linux: %s %v, %v", name, args, err)
+	}
+	name, args, err = desktopCommand("darwin", message)
+	if err != nil || name != "osascript" || args[1] != `display notification "it's done" with title "Say \"hi\""` {
+		t.Fatalf("darwin: %s %v, %v", name, args, err)
+	}
+	name, args, err = desktopCommand("windows", message)
+	if err != nil || name != "powershell" || !strings.Contains(args[3], `'Say "hi"', 'it''s done'`) {
+		t.Fatalf("windows: %s %v, %v", name, args, err)
+	}
+	if _, _, err := desktopCommand("plan9", message); err == nil {
+		t.Fatal("desktopCommand(plan9) error = nil")
+	}
+}
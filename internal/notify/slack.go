@@ -0,0 +1,56 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+// SlackConfig configures delivery to a Slack incoming webhook.
+type SlackConfig struct {
+	WebhookURL string
+}
+
+// SlackSender posts messages to a Slack incoming webhook.
+type SlackSender struct {
+	webhookURL string
+	client     *http.Client
+}
+
+// NewSlackSender creates a sender for the configured webhook.
+func NewSlackSender(cfg SlackConfig) *SlackSender {
+	return &SlackSender{webhookURL: cfg.WebhookURL, client: &http.Client{Timeout: sendTimeout}}
+}
+
+// Send posts message to the webhook.
+func (s *SlackSender) Send(ctx context.Context, message Message) error {
+	text := "*" + message.Title + "*"
+	if message.Body != "" {
+		text += "\n" + message.Body
+	}
+	if message.URL != "" {
+		text += "\n<" + message.URL + ">"
+	}
+	payload, err := json.Marshal(map[string]string{"text": text})
+	if err != nil {
+		return fmt.Errorf("failed to encode Slack message: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.webhookURL, bytes.NewReader(payload))
+	if err != nil {
+		return fmt.Errorf("invalid Slack webhook URL: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to post to Slack: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("slack webhook returned %s: %s", resp.Status, bytes.TrimSpace(body))
+	}
+	return nil
+}
@@ -0,0 +1,210 @@
+package policy
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+)
+
+// ApprovalTTL is how long a pending or granted approval stays valid.
+const ApprovalTTL = 24 * time.Hour
+
+// Approval is a request for a second person to approve an operation.
+type Approval struct {
+	ID         string    `json:"id"`
+	Request    Request   `json:"request"`
+	Requester  string    `json:"requester"`
+	CreatedAt  time.Time `json:"created_at"`
+	ApprovedBy string    `json:"approved_by,omitempty"`
+	ApprovedAt time.Time `json:"approved_at,omitempty"`
+}
+
+// Approved reports whether a second approver granted the request.
+func (a *Approval) Approved() bool {
+	return a.ApprovedBy != ""
+}
+
+func (a *Approval) expired(now time.Time) bool {
+	return now.Sub(a.CreatedAt) > ApprovalTTL
+}
+
+// ApprovalStore keeps approvals in a JSON file that requester and approver
+// share. Approvals are single use and expire after ApprovalTTL.
+type ApprovalStore struct {
+	path string
+	mu   sync.Mutex
+	now  func() time.Time
+}
+
+// NewApprovalStore returns a store backed by the file at path.
+func NewApprovalStore(path string) *ApprovalStore {
+	return &ApprovalStore{path: path, now: time.Now}
+}
+
+// Path returns the backing file.
+func (s *ApprovalStore) Path() string {
+	return s.path
+}
+
+// Request records a pending approval for req.
+func (s *ApprovalStore) Request(req Request, requester string) (*Approval, error) {
+	id, err := newApprovalID()
+	if err != nil {
+		return nil, err
+	}
+	approval := &Approval{ID: id, Request: req, Requester: requester, CreatedAt: s.now()}
+
+	err = s.update(func(approvals map[string]*Approval) error {
+		approvals[id] = approval
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return approval, nil
+}
+
+// Approve grants a pending approval. The approver must differ from the
+// requester.
+func (s *ApprovalStore) Approve(id, approver string) (*Approval, error) {
+	var approved *Approval
+	err := s.update(func(approvals map[string]*Approval) error {
+		approval, ok := approvals[id]
+		if !ok {
+			return fmt.Errorf("approval %s not found or expired", id)
+		}
+		if approver == "" || approver == approval.Requester {
+			return fmt.Errorf("approval %s must be granted by someone other than %s", id, approval.Requester)
+		}
+		if approval.Approved() {
+			return fmt.Errorf("approval %s was already granted by %s", id, approval.ApprovedBy)
+		}
+		approval.ApprovedBy = approver
+		approval.ApprovedAt = s.now()
+		approved = approval
+		return nil
+	})
+	return approved, err
+}
+
+// Reject withdraws a pending approval so it can no longer be granted. Like
+// approving, rejecting is for someone other than the requester.
+func (s *ApprovalStore) Reject(id, rejecter string) (*Approval, error) {
+	var rejected *Approval
+	err := s.update(func(approvals map[string]*Approval) error {
+		approval, ok := approvals[id]
+		if !ok {
+			return fmt.Errorf("approval %s not found or expired", id)
+		}
+		if rejecter == "" || rejecter == approval.Requester {
+			return fmt.Errorf("approval %s must be rejected by someone other than %s", id, approval.Requester)
+		}
+		if approval.Approved() {
+			return fmt.Errorf("approval %s was already granted by %s", id, approval.ApprovedBy)
+		}
+		delete(approvals, id)
+		rejected = approval
+		return nil
+	})
+	return rejected, err
+}
+
+// Consume checks that id approves req for requester and removes it so it
+// cannot be reused.
+func (s *ApprovalStore) Consume(id string, req Request, requester string) error {
+	return s.update(func(approvals map[string]*Approval) error {
+		approval, ok := approvals[id]
+		if !ok {
+			return fmt.Errorf("approval %s not found or expired", id)
+		}
+		if !approval.Approved() {
+			return fmt.Errorf("approval %s is still waiting for a second approver", id)
+		}
+		if approval.Requester != requester {
+			return fmt.Errorf("approval %s was requested by %s", id, approval.Requester)
+		}
+		if approval.Request != req {
+			return fmt.Errorf("approval %s is for %s, not %s", id, approval.Request, req)
+		}
+		delete(approvals, id)
+		return nil
+	})
+}
+
+// Pending returns unexpired approvals that have not been granted, oldest
+// first.
+func (s *ApprovalStore) Pending() ([]*Approval, error) {
+	var pending []*Approval
+	err := s.update(func(approvals map[string]*Approval) error {
+		for _, approval := range approvals {
+			if !approval.Approved() {
+				pending = append(pending, approval)
+			}
+		}
+		return nil
+	})
+	sort.Slice(pending, func(i, j int) bool { return pending[i].CreatedAt.Before(pending[j].CreatedAt) })
+	return pending, err
+}
+
+// update loads the file, drops expired approvals, applies fn, and writes the
+// result back atomically.
+func (s *ApprovalStore) update(fn func(map[string]*Approval) error) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	approvals := map[string]*Approval{}
+	data, err := os.ReadFile(s.path)
+	switch {
+	case errors.Is(err, fs.ErrNotExist):
+	case err != nil:
+		return fmt.Errorf("failed to read approvals: %w", err)
+	default:
+		if err := json.Unmarshal(data, &approvals); err != nil {
+			return fmt.Errorf("failed to parse approvals: %w", err)
+		}
+	}
+
+	now := s.now()
+	for id, approval := range approvals {
+		if approval.expired(now) {
+			delete(approvals, id)
+		}
+	}
+
+	if err := fn(approvals); err != nil {
+		return err
+	}
+
+	data, err = json.MarshalIndent(approvals, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode approvals: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(s.path), 0o755); err != nil {
+		return fmt.Errorf("failed to create approvals directory: %w", err)
+	}
+	tmp := s.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return fmt.Errorf("failed to write approvals: %w", err)
+	}
+	if err := os.Rename(tmp, s.path); err != nil {
+		return fmt.Errorf("failed to write approvals: %w", err)
+	}
+	return nil
+}
+
+func newApprovalID() (string, error) {
+	buf := make([]byte, 4)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("failed to generate approval ID: %w", err)
+	}
+	return hex.EncodeToString(buf), nil
+}
@@ -0,0 +1,56 @@
+package policy
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrBudgetExceeded is wrapped by errors for operations that would go over a
+// configured budget.
+var ErrBudgetExceeded = errors.New("budget exceeded")
+
+// Budget limits.
+const (
+	LimitSessionsPerDay = "sessions_per_day"
+	LimitFilesChanged   = "files_changed"
+)
+
+// Budget caps how much work Jules is asked to do. Zero fields are unlimited.
+type Budget struct {
+	// MaxSessionsPerDay caps the sessions created in any 24 hours.
+	MaxSessionsPerDay int
+	// MaxFilesChanged caps the files a single patch apply may change.
+	MaxFilesChanged int
+}
+
+// BudgetExceededError reports the limit an operation would exceed.
+type BudgetExceededError struct {
+	Limit string
+	// Used is the consumption the operation would bring the limit to.
+	Used int
+	Max  int
+}
+
+func (e *BudgetExceededError) Error() string {
+	return fmt.Sprintf("%s: %s would reach %d, limit is %d", ErrBudgetExceeded, e.Limit, e.Used, e.Max)
+}
+
+func (e *BudgetExceededError) Unwrap() error { return ErrBudgetExceeded }
+
+// CheckSessions checks that creating n sessions, after created in the last
+// 24 hours, stays within budget.
+func (b Budget) CheckSessions(created, n int) error {
+	if b.MaxSessionsPerDay > 0 && created+n > b.MaxSessionsPerDay {
+		return &BudgetExceededError{Limit: LimitSessionsPerDay, Used: created + n, Max: b.MaxSessionsPerDay}
+	}
+	return nil
+}
+
+// CheckFilesChanged checks that a patch apply changing files stays within
+// budget.
+func (b Budget) CheckFilesChanged(files int) error {
+	if b.MaxFilesChanged > 0 && files > b.MaxFilesChanged {
+		return &BudgetExceededError{Limit: LimitFilesChanged, Used: files, Max: b.MaxFilesChanged}
+	}
+	return nil
+}
@@ -0,0 +1,239 @@
+// Package policy decides whether risky operations may run. Rules from config
+// allow or deny an operation outright, require interactive confirmation, or
+// require a second person to approve it first. Rules can be scoped to the tool
+// or command that performs the operation and to the repository it targets.
+package policy
+
+import (
+	"errors"
+	"fmt"
+	"path"
+	"strings"
+)
+
+// Decision is the outcome of evaluating a request.
+type Decision string
+
+// Decisions, from least to most restrictive.
+const (
+	Allow           Decision = "allow"
+	Confirm         Decision = "confirm"
+	RequireApproval Decision = "approve"
+	Deny            Decision = "deny"
+)
+
+// Operation names a risky operation guarded by policy.
+type Operation string
+
+// Guarded operations.
+const (
+	OpDockerExec      Operation = "docker_exec"
+	OpPatchApplyForce Operation = "patch_apply_force"
+	OpAutoApprovePlan Operation = "auto_approve_plan"
+	OpDeleteSession   Operation = "delete_session"
+	OpCleanCache      Operation = "clean_cache"
+	OpK8sApply        Operation = "k8s_apply"
+	OpK8sRestart      Operation = "k8s_restart"
+	OpMigrationRisk   Operation = "migration_risk"
+)
+
+// Operations returns every guarded operation in display order.
+func Operations() []Operation {
+	return []Operation{OpDockerExec, OpPatchApplyForce, OpAutoApprovePlan, OpDeleteSession, OpCleanCache, OpK8sApply, OpK8sRestart, OpMigrationRisk}
+}
+
+// defaultDecisions apply when no rule matches. Auto-approved plans keep the
+// Jules default and are allowed unless a rule says otherwise.
+var defaultDecisions = map[Operation]Decision{
+	OpDockerExec:      Confirm,
+	OpPatchApplyForce: Confirm,
+	OpAutoApprovePlan: Allow,
+	OpDeleteSession:   Confirm,
+	OpCleanCache:      Confirm,
+	OpK8sApply:        Confirm,
+	OpK8sRestart:      Confirm,
+	OpMigrationRisk:   Confirm,
+}
+
+// ErrDenied is returned when policy forbids an operation.
+var ErrDenied = errors.New("denied by policy")
+
+// Rule maps requests to a decision. Empty or "*" fields match anything; Tool
+// and Repo accept path.Match globs such as "sessions *" or "my-org/*".
+type Rule struct {
+	Operation string
+	Tool      string
+	Repo      string
+	Decision  Decision
+}
+
+// Request describes an operation about to run.
+type Request struct {
+	Operation Operation
+	// Tool is the MCP tool or CLI command performing the operation, for
+	// example "delete_session" or "sessions delete".
+	Tool string
+	// Repo is the owner/name repository the operation targets, if known.
+	Repo string
+	// Target identifies the affected resource, such as a session ID.
+	Target string
+}
+
+func (r Request) String() string {
+	s := string(r.Operation)
+	if r.Target != "" {
+		s += " on " + r.Target
+	}
+	if r.Repo != "" {
+		s += " in " + r.Repo
+	}
+	return s
+}
+
+// Engine evaluates requests against an ordered list of rules.
+type Engine struct {
+	rules []Rule
+}
+
+// New validates rules and returns an engine. The first matching rule wins.
+func New(rules []Rule) (*Engine, error) {
+	for i, rule := range rules {
+		if err := ValidateRule(rule); err != nil {
+			return nil, fmt.Errorf("policy rule %d: %w", i, err)
+		}
+	}
+	return &Engine{rules: rules}, nil
+}
+
+// ValidateRule reports an unknown operation, decision, or malformed glob.
+func ValidateRule(rule Rule) error {
+	if _, err := ParseDecision(string(rule.Decision)); err != nil {
+		return err
+	}
+	if rule.Operation != "" && rule.Operation != "*" {
+		if _, ok := defaultDecisions[Operation(rule.Operation)]; !ok {
+			return fmt.Errorf("unknown operation %q", rule.Operation)
+		}
+	}
+	for _, pattern := range []string{rule.Tool, rule.Repo} {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return fmt.Errorf("invalid pattern %q: %w", pattern, err)
+		}
+	}
+	return nil
+}
+
+// ParseDecision parses allow, confirm, approve, or deny.
+func ParseDecision(s string) (Decision, error) {
+	switch d := Decision(strings.ToLower(s)); d {
+	case Allow, Confirm, RequireApproval, Deny:
+		return d, nil
+	}
+	return "", fmt.Errorf("invalid decision %q: expected allow, confirm, approve, or deny", s)
+}
+
+// Decide returns the decision of the first matching rule, or the built-in
+// default for the operation.
+func (e *Engine) Decide(req Request) Decision {
+	if e != nil {
+		for _, rule := range e.rules {
+			if rule.matches(req) {
+				return Decision(strings.ToLower(string(rule.Decision)))
+			}
+		}
+	}
+	if decision, ok := defaultDecisions[req.Operation]; ok {
+		return decision
+	}
+	return Allow
+}
+
+func (r Rule) matches(req Request) bool {
+	if r.Operation != "" && r.Operation != "*" && Operation(r.Operation) != req.Operation {
+		return false
+	}
+	return globMatch(r.Tool, req.Tool) && globMatch(strings.ToLower(r.Repo), strings.ToLower(req.Repo))
+}
+
+func globMatch(pattern, value string) bool {
+	if pattern == "" || pattern == "*" {
+		return true
+	}
+	ok, err := path.Match(pattern, value)
+	return err == nil && ok
+}
+
+// Check carries what the caller knows about a request when enforcing policy.
+type Check struct {
+	Request
+	// Confirmed is true when the caller already confirmed, for example with
+	// --force or an MCP confirm=true argument.
+	Confirmed bool
+	// Prompt asks the user to confirm. Nil means no interactive terminal.
+	Prompt func(question string) (bool, error)
+	// ApprovalID is a second-approver approval presented with the request.
+	ApprovalID string
+	// Requester identifies who is asking, for approval bookkeeping.
+	Requester string
+}
+
+// Enforce returns nil when the request may proceed. Confirm decisions are
+// satisfied by Confirmed or an interactive prompt; approve decisions need an
+// approval granted by someone other than the requester.
+func (e *Engine) Enforce(check Check, approvals *ApprovalStore) error {
+	switch e.Decide(check.Request) {
+	case Allow:
+		return nil
+	case Deny:
+		return fmt.Errorf("%s: %w", check.Request, ErrDenied)
+	case Confirm:
+		if check.Confirmed {
+			return nil
+		}
+		if check.Prompt == nil {
+			return fmt.Errorf("%s requires confirmation", check.Request)
+		}
+		ok, err := check.Prompt(fmt.Sprintf("Policy requires confirmation for %s. Continue?", check.Request))
+		if err != nil {
+			return fmt.Errorf("confirmation failed: %w", err)
+		}
+		if !ok {
+			return fmt.Errorf("%s was not confirmed", check.Request)
+		}
+		return nil
+	case RequireApproval:
+		if approvals == nil {
+			return fmt.Errorf("%s requires a second approver but no approval store is configured", check.Request)
+		}
+		if check.ApprovalID != "" {
+			return approvals.Consume(check.ApprovalID, check.Request, check.Requester)
+		}
+		pending, err := approvals.Request(check.Request, check.Requester)
+		if err != nil {
+			return err
+		}
+		return &ApprovalRequiredError{Approval: pending}
+	}
+	return nil
+}
+
+// ApprovalRequiredError is returned when a second approver must approve the
+// request before it is retried with the approval ID.
+type ApprovalRequiredError struct {
+	Approval *Approval
+}
+
+func (e *ApprovalRequiredError) Error() string {
+	return fmt.Sprintf("%s requires a second approver: ask another user to run 'juleson policy approve %s', then retry with approval %s",
+		e.Approval.Request, e.Approval.ID, e.Approval.ID)
+}
+
+// RepoFromSource returns owner/name for a Jules source name such as
+// sources/github/owner/name, or an empty string.
+func RepoFromSource(source string) string {
+	parts := strings.Split(strings.TrimPrefix(source, "sources/"), "/")
+	if len(parts) == 3 && parts[0] == "github" {
+		return parts[1] + "/" + parts[2]
+	}
+	return ""
+}
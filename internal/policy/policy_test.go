@@ -0,0 +1,179 @@
+package policy
+
+import (
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDecideFirstMatchingRuleWins(t *testing.T) {
+	engine, err := New([]Rule{
+		{Operation: "delete_session", Tool: "delete_session", Repo: "acme/prod-*", Decision: Deny},
+		{Operation: "delete_session", Repo: "acme/*", Decision: RequireApproval},
+		{Operation: "*", Tool: "sessions *", Decision: Allow},
+	})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	tests := []struct {
+		req  Request
+		want Decision
+	}{
+		{Request{Operation: OpDeleteSession, Tool: "delete_session", Repo: "acme/prod-api"}, Deny},
+		{Request{Operation: OpDeleteSession, Tool: "sessions delete", Repo: "ACME/web"}, RequireApproval},
+		{Request{Operation: OpPatchApplyForce, Tool: "sessions apply"}, Allow},
+		{Request{Operation: OpPatchApplyForce, Tool: "dev clean"}, Confirm},
+		{Request{Operation: OpAutoApprovePlan, Tool: "create_session"}, Allow},
+	}
+	for _, tt := range tests {
+		if got := engine.Decide(tt.req); got != tt.want {
+			t.Errorf("Decide(%s via %s) = %s, want %s", tt.req, tt.req.Tool, got, tt.want)
+		}
+	}
+}
+
+func TestNewRejectsInvalidRules(t *testing.T) {
+	for _, rule := range []Rule{
+		{Operation: "format_disk", Decision: Deny},
+		{Operation: "docker_exec", Decision: "maybe"},
+		{Operation: "docker_exec", Repo: "[", Decision: Deny},
+	} {
+		if _, err := New([]Rule{rule}); err == nil {
+			t.Errorf("New(%+v) should fail", rule)
+		}
+	}
+}
+
+func TestEnforceConfirmAndDeny(t *testing.T) {
+	engine, _ := New([]Rule{{Operation: "clean_cache", Decision: Deny}})
+
+	err := engine.Enforce(Check{Request: Request{Operation: OpCleanCache}, Confirmed: true}, nil)
+	if !errors.Is(err, ErrDenied) {
+		t.Errorf("deny rule: err = %v, want ErrDenied", err)
+	}
+
+	req := Request{Operation: OpDeleteSession, Target: "s1"}
+	if err := engine.Enforce(Check{Request: req}, nil); err == nil {
+		t.Error("confirm without prompt should fail")
+	}
+	if err := engine.Enforce(Check{Request: req, Confirmed: true}, nil); err != nil {
+		t.Errorf("pre-confirmed request: %v", err)
+	}
+	prompted := ""
+	err = engine.Enforce(Check{Request: req, Prompt: func(q string) (bool, error) {
+		prompted = q
+		return false, nil
+	}}, nil)
+	if err == nil || !strings.Contains(prompted, "delete_session on s1") {
+		t.Errorf("declined prompt: err = %v, prompt = %q", err, prompted)
+	}
+}
+
+func TestEnforceSecondApprover(t *testing.T) {
+	engine, _ := New([]Rule{{Operation: "patch_apply_force", Decision: RequireApproval}})
+	store := NewApprovalStore(filepath.Join(t.TempDir(), "approvals.json"))
+	req := Request{Operation: OpPatchApplyForce, Tool: "sessions apply", Target: "s1"}
+
+	err := engine.Enforce(Check{Request: req, Requester: "alice"}, store)
+	var pending *ApprovalRequiredError
+	if !errors.As(err, &pending) {
+		t.Fatalf("err = %v, want ApprovalRequiredError", err)
+	}
+	id := pending.Approval.ID
+
+	if err := engine.Enforce(Check{Request: req, Requester: "alice", ApprovalID: id}, store); err == nil {
+		t.Error("unapproved approval should not be accepted")
+	}
+	if _, err := store.Approve(id, "alice"); err == nil {
+		t.Error("requester must not approve their own request")
+	}
+	if _, err := store.Approve(id, "bob"); err != nil {
+		t.Fatalf("Approve() error = %v", err)
+	}
+
+	other := req
+	other.Target = "s2"
+	if err := engine.Enforce(Check{Request: other, Requester: "alice", ApprovalID: id}, store); err == nil {
+		t.Error("approval must match the request")
+	}
+	if err := engine.Enforce(Check{Request: req, Requester: "alice", ApprovalID: id}, store); err != nil {
+		t.Fatalf("approved request: %v", err)
+	}
+	if err := engine.Enforce(Check{Request: req, Requester: "alice", ApprovalID: id}, store); err == nil {
+		t.Error("approvals are single use")
+	}
+}
+
+func TestRejectApproval(t *testing.T) {
+	store := NewApprovalStore(filepath.Join(t.TempDir(), "approvals.json"))
+	approval, err := store.Request(Request{Operation: OpDeleteSession, Target: "s1"}, "alice")
+	if err != nil {
+		t.Fatalf("Request() error = %v", err)
+	}
+
+	if _, err := store.Reject(approval.ID, "alice"); err == nil {
+		t.Error("requester must not reject their own request")
+	}
+	if _, err := store.Reject(approval.ID, "bob"); err != nil {
+		t.Fatalf("Reject() error = %v", err)
+	}
+	if pending, _ := store.Pending(); len(pending) != 0 {
+		t.Errorf("pending = %d, want 0", len(pending))
+	}
+	if _, err := store.Approve(approval.ID, "bob"); err == nil {
+		t.Error("rejected approval should not be granted")
+	}
+}
+
+func TestApprovalsExpire(t *testing.T) {
+	store := NewApprovalStore(filepath.Join(t.TempDir(), "approvals.json"))
+	now := time.Now()
+	store.now = func() time.Time { return now }
+
+	if _, err := store.Request(Request{Operation: OpDockerExec}, "alice"); err != nil {
+		t.Fatalf("Request() error = %v", err)
+	}
+	pending, _ := store.Pending()
+	if len(pending) != 1 {
+		t.Fatalf("pending = %d, want 1", len(pending))
+	}
+
+	now = now.Add(ApprovalTTL + time.Minute)
+	pending, _ = store.Pending()
+	if len(pending) != 0 {
+		t.Errorf("expired approvals should be dropped, got %d", len(pending))
+	}
+}
+
+func TestRepoFromSource(t *testing.T) {
+	if got := RepoFromSource("sources/github/acme/web"); got != "acme/web" {
+		t.Errorf("RepoFromSource = %q", got)
+	}
+	if got := RepoFromSource("repoless"); got != "" {
+		t.Errorf("RepoFromSource(repoless) = %q", got)
+	}
+}
+
+func TestBudget(t *testing.T) {
+	budget := Budget{MaxSessionsPerDay: 5, MaxFilesChanged: 10}
+	if err := budget.CheckSessions(4, 1); err != nil {
+		t.Fatalf("CheckSessions(4, 1) = %v", err)
+	}
+	err := budget.CheckSessions(4, 2)
+	var exceeded *BudgetExceededError
+	if !errors.As(err, &exceeded) || !errors.Is(err, ErrBudgetExceeded) {
+		t.Fatalf("CheckSessions(4, 2) = %v", err)
+	}
+	if exceeded.Limit != LimitSessionsPerDay || exceeded.Used != 6 || exceeded.Max != 5 {
+		t.Fatalf("exceeded = %+v", exceeded)
+	}
+	if err := budget.CheckFilesChanged(11); !errors.Is(err, ErrBudgetExceeded) {
+		t.Fatalf("CheckFilesChanged(11) = %v", err)
+	}
+	if err := (Budget{}).CheckSessions(100, 100); err != nil {
+		t.Fatalf("zero budget limited sessions: %v", err)
+	}
+}
@@ -0,0 +1,25 @@
+// Package analyze holds the juleson analyze commands, which report on the
+// health of a project's code and dependencies.
+package analyze
+
+import (
+	"github.com/SamyRai/juleson/internal/config"
+	"github.com/spf13/cobra"
+)
+
+// NewAnalyzeCommand creates the analyze command.
+func NewAnalyzeCommand(cfg *config.Config) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "analyze",
+		Short: "Analyze project dependencies and code health",
+		Long: "Audit a project's dependencies for known vulnerabilities and license policy violations, and rank " +
+			"the files most worth refactoring.",
+	}
+
+	cmd.AddCommand(newDepsCommand(cfg))
+	cmd.AddCommand(newHotspotsCommand())
+	cmd.AddCommand(newMonorepoCommand())
+	cmd.AddCommand(newProjectCommand())
+
+	return cmd
+}
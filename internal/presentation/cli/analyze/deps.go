@@ -0,0 +1,126 @@
+package analyze
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/SamyRai/juleson/internal/config"
+	"github.com/SamyRai/juleson/internal/intelligence"
+	"github.com/SamyRai/juleson/pkg/osv"
+	"github.com/SamyRai/juleson/pkg/sarif"
+	"github.com/spf13/cobra"
+)
+
+// newDepsCommand creates the deps command.
+func newDepsCommand(cfg *config.Config) *cobra.Command {
+	var (
+		jsonOutput      bool
+		format          string
+		offline         bool
+		allowedLicenses []string
+	)
+
+	cmd := &cobra.Command{
+		Use:   "deps [path]",
+		Short: "Audit dependencies for vulnerabilities and license violations",
+		Long: "Read go.mod, package.json, and requirements*.txt files, look up known vulnerabilities of pinned " +
+			"versions in OSV (analysis.osv_url), and check the licenses found in the Go module cache, node_modules, " +
+			"or a virtualenv against analysis.allowed_licenses. Exits non-zero on vulnerabilities or license " +
+			"violations.",
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			path := "."
+			if len(args) > 0 {
+				path = args[0]
+			}
+			format, err := outputFormat(format, jsonOutput)
+			if err != nil {
+				return err
+			}
+
+			options := intelligence.DependencyAuditOptions{AllowedLicenses: cfg.Analysis.AllowedLicenses}
+			if cmd.Flags().Changed("allow-license") {
+				options.AllowedLicenses = allowedLicenses
+			}
+			if !offline {
+				options.OSV = osv.NewClient(cfg.Analysis.OSVURL, nil)
+			}
+
+			audit, err := intelligence.AuditDependencies(context.Background(), path, options)
+			if err != nil {
+				return fmt.Errorf("dependency audit failed: %w", err)
+			}
+
+			switch format {
+			case formatJSON:
+				encoder := json.NewEncoder(os.Stdout)
+				encoder.SetIndent("", "  ")
+				if err := encoder.Encode(audit); err != nil {
+					return err
+				}
+			case formatSARIF:
+				if err := writeSARIF(path, func(b *sarif.Builder, prefix string) {
+					intelligence.AddDependencyResults(b, prefix, audit)
+				}); err != nil {
+					return err
+				}
+			default:
+				printDependencyAudit(audit)
+			}
+			if audit.Failed() {
+				cmd.SilenceUsage = true
+				return fmt.Errorf("found %d vulnerable dependencies and %d license violations", audit.Vulnerable, audit.LicenseViolations)
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVar(&jsonOutput, "json", false, "Print the audit as JSON (same as --format json)")
+	cmd.Flags().StringVar(&format, "format", formatText, "Output format: text, json, or sarif")
+	cmd.Flags().BoolVar(&offline, "offline", false, "Skip the OSV vulnerability lookup")
+	cmd.Flags().StringSliceVar(&allowedLicenses, "allow-license", nil, "Allowed SPDX license (repeatable; overrides analysis.allowed_licenses)")
+
+	return cmd
+}
+
+func printDependencyAudit(audit *intelligence.DependencyAudit) {
+	fmt.Printf("Dependencies: %d (%d unpinned)\n", len(audit.Dependencies), audit.Unpinned)
+	for _, dep := range audit.Dependencies {
+		for _, vuln := range dep.Vulnerabilities {
+			fmt.Printf("❌ %s %s (%s): %s %s", dep.Name, dep.Version, dep.Ecosystem, vuln.ID, vuln.Severity)
+			if vuln.Summary != "" {
+				fmt.Printf(" - %s", vuln.Summary)
+			}
+			if len(vuln.Fixed) > 0 {
+				fmt.Printf(" (fixed in %s)", strings.Join(vuln.Fixed, ", "))
+			}
+			fmt.Println()
+		}
+		if dep.LicenseStatus == intelligence.LicenseDenied {
+			fmt.Printf("⚖️  %s (%s): license %s is not allowed\n", dep.Name, dep.Manifest, dep.License)
+		}
+	}
+
+	if !audit.VulnerabilitiesChecked {
+		fmt.Println("Vulnerabilities: not checked (--offline)")
+	} else {
+		fmt.Printf("Vulnerable: %d\n", audit.Vulnerable)
+	}
+	unknown := 0
+	for _, dep := range audit.Dependencies {
+		if dep.LicenseStatus == intelligence.LicenseUnknown {
+			unknown++
+		}
+	}
+	if !audit.LicensesChecked {
+		fmt.Println("Licenses: not checked (set analysis.allowed_licenses)")
+	} else {
+		fmt.Printf("License violations: %d (%d unknown)\n", audit.LicenseViolations, unknown)
+	}
+	if !audit.Failed() {
+		fmt.Println("✅ No vulnerable dependencies or license violations found")
+	}
+}
@@ -0,0 +1,52 @@
+package analyze
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/SamyRai/juleson/internal/presentation/cli/core"
+	"github.com/SamyRai/juleson/pkg/git"
+	"github.com/SamyRai/juleson/pkg/sarif"
+)
+
+// Output formats of the analyze commands.
+const (
+	formatText  = "text"
+	formatJSON  = "json"
+	formatSARIF = "sarif"
+)
+
+// outputFormat validates --format, with --json as a shorthand for json.
+func outputFormat(format string, jsonOutput bool) (string, error) {
+	if jsonOutput {
+		if format != formatText && format != formatJSON {
+			return "", fmt.Errorf("--json conflicts with --format %s", format)
+		}
+		return formatJSON, nil
+	}
+	switch format {
+	case formatText, formatJSON, formatSARIF:
+		return format, nil
+	}
+	return "", fmt.Errorf("unknown format %q: use text, json, or sarif", format)
+}
+
+// writeSARIF prints a SARIF log built by add. Paths are made relative to the
+// enclosing git repository, as code scanning expects; add receives the
+// prefix to join to paths relative to root.
+func writeSARIF(root string, add func(b *sarif.Builder, prefix string)) error {
+	prefix := ""
+	if abs, err := filepath.EvalSymlinks(root); err == nil {
+		abs, _ = filepath.Abs(abs)
+		if repo, err := git.Open(context.Background(), abs); err == nil {
+			if rel, err := filepath.Rel(repo.Root, abs); err == nil && rel != "." {
+				prefix = filepath.ToSlash(rel)
+			}
+		}
+	}
+	builder := sarif.NewBuilder("juleson", core.Version, "https://github.com/SamyRai/juleson")
+	add(builder, prefix)
+	return builder.Log().Write(os.Stdout)
+}
@@ -0,0 +1,100 @@
+package analyze
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+	"time"
+
+	"github.com/SamyRai/juleson/internal/intelligence"
+	"github.com/spf13/cobra"
+)
+
+// newHotspotsCommand creates the hotspots command.
+func newHotspotsCommand() *cobra.Command {
+	var (
+		days         int
+		top          int
+		coverProfile string
+		htmlPath     string
+		jsonOutput   bool
+		noCache      bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "hotspots [path]",
+		Short: "Rank Go files by churn, complexity, and coverage",
+		Long: "Combine git churn with cyclomatic complexity, and statement coverage from a go test -coverprofile " +
+			"file when given, to rank the Go files most worth refactoring. Score is commits times total " +
+			"complexity, weighted up to twice as heavily for untested code.",
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			path := "."
+			if len(args) > 0 {
+				path = args[0]
+			}
+			options := intelligence.HotspotOptions{CoverProfile: coverProfile, Limit: top}
+			if days > 0 {
+				options.Since = time.Now().AddDate(0, 0, -days)
+			}
+			if !noCache {
+				options.Cache = intelligence.OpenProjectCache(path)
+			}
+
+			report, err := intelligence.AnalyzeHotspots(context.Background(), path, options)
+			if err != nil {
+				return fmt.Errorf("hotspot analysis failed: %w", err)
+			}
+			if err := options.Cache.Save(); err != nil {
+				slog.Warn("Failed to save analysis cache", "error", err)
+			}
+
+			if htmlPath != "" {
+				f, err := os.Create(htmlPath)
+				if err != nil {
+					return err
+				}
+				if err := report.WriteHTML(f); err != nil {
+					_ = f.Close()
+					return err
+				}
+				if err := f.Close(); err != nil {
+					return err
+				}
+			}
+			if jsonOutput {
+				encoder := json.NewEncoder(os.Stdout)
+				encoder.SetIndent("", "  ")
+				return encoder.Encode(report)
+			}
+
+			if len(report.Hotspots) == 0 {
+				fmt.Println("No changed Go files in this period")
+			} else {
+				fmt.Printf("%-8s %-7s %-10s %-8s %s\n", "SCORE", "COMMITS", "COMPLEXITY", "COVERAGE", "FILE")
+				for _, hotspot := range report.Hotspots {
+					coverage := "-"
+					if hotspot.Coverage != nil {
+						coverage = fmt.Sprintf("%.0f%%", *hotspot.Coverage*100)
+					}
+					fmt.Printf("%-8.0f %-7d %-10d %-8s %s\n", hotspot.Score, hotspot.Commits, hotspot.Complexity, coverage, hotspot.File)
+				}
+			}
+			if htmlPath != "" {
+				fmt.Printf("\n📄 HTML report written to %s\n", htmlPath)
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().IntVar(&days, "days", 180, "Count churn over this many days (0 for all history)")
+	cmd.Flags().IntVar(&top, "top", 20, "Show only the top N hotspots (0 for all)")
+	cmd.Flags().StringVar(&coverProfile, "coverprofile", "", "Go cover profile to weight untested code")
+	cmd.Flags().StringVar(&htmlPath, "html", "", "Also write an HTML heatmap report to this file")
+	cmd.Flags().BoolVar(&jsonOutput, "json", false, "Print the report as JSON")
+	cmd.Flags().BoolVar(&noCache, "no-cache", false, "Re-parse every file instead of reusing cached results")
+
+	return cmd
+}
@@ -0,0 +1,92 @@
+package analyze
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/SamyRai/juleson/internal/intelligence"
+	"github.com/SamyRai/juleson/internal/presentation/cli/core"
+	"github.com/spf13/cobra"
+)
+
+// newMonorepoCommand creates the monorepo command.
+func newMonorepoCommand() *cobra.Command {
+	var (
+		since      string
+		jsonOutput bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "monorepo [path]",
+		Short: "List a repository's sub-projects and those affected by changes",
+		Long: "Detect the sub-projects of a monorepo from go.work, nested go.mod files, npm, yarn, and pnpm " +
+			"workspaces, and nx projects, with the sub-projects each one depends on. With --since, also list " +
+			"the sub-projects containing the files changed since a commit, and those depending on them; " +
+			"orchestrate workflows run with --projects use the same scoping.",
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			path := "."
+			if len(args) > 0 {
+				path = args[0]
+			}
+			ctx := context.Background()
+			m, err := intelligence.DetectMonorepo(ctx, path)
+			if err != nil {
+				return fmt.Errorf("monorepo detection failed: %w", err)
+			}
+			var changed []string
+			var affected []intelligence.SubProject
+			if since != "" {
+				changed, err = core.MonorepoChanges(ctx, m, since)
+				if err != nil {
+					return err
+				}
+				affected = m.Affected(changed)
+			}
+
+			if jsonOutput {
+				encoder := json.NewEncoder(os.Stdout)
+				encoder.SetIndent("", "  ")
+				if since == "" {
+					return encoder.Encode(m)
+				}
+				return encoder.Encode(map[string]interface{}{
+					"monorepo":      m,
+					"since":         since,
+					"changed_files": changed,
+					"affected":      affected,
+				})
+			}
+
+			if len(m.Tools) > 0 {
+				fmt.Printf("Tools: %s\n", strings.Join(m.Tools, ", "))
+			}
+			if len(m.Projects) == 0 {
+				fmt.Println("No Go module or JavaScript package found")
+				return nil
+			}
+			fmt.Printf("%-6s %-30s %-30s %s\n", "KIND", "DIR", "NAME", "DEPENDS ON")
+			for _, project := range m.Projects {
+				fmt.Printf("%-6s %-30s %-30s %s\n", project.Kind, project.Dir, project.Name, strings.Join(project.DependsOn, ", "))
+			}
+			if since != "" {
+				fmt.Printf("\nAffected since %s (%d changed file(s)):\n", since, len(changed))
+				if len(affected) == 0 {
+					fmt.Println("  none")
+				}
+				for _, project := range affected {
+					fmt.Printf("  %s\n", project.Dir)
+				}
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&since, "since", "", "List the sub-projects affected by changes since this commit or branch")
+	cmd.Flags().BoolVar(&jsonOutput, "json", false, "Print the sub-projects as JSON")
+
+	return cmd
+}
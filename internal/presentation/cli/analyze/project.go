@@ -0,0 +1,76 @@
+package analyze
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+
+	"github.com/SamyRai/juleson/internal/intelligence"
+	"github.com/SamyRai/juleson/pkg/sarif"
+	"github.com/spf13/cobra"
+)
+
+// newProjectCommand creates the project command.
+func newProjectCommand() *cobra.Command {
+	var (
+		coverProfile string
+		jsonOutput   bool
+		format       string
+		noCache      bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "project [path]",
+		Short: "Summarize a project's languages, frameworks, tests, and issues",
+		Long: "Summarize what a project is written in, the frameworks its manifests depend on, its test files " +
+			"and coverage, code smells, and possible committed secrets. This is the context sessions create " +
+			"--with-intel adds to planning prompts.",
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			path := "."
+			if len(args) > 0 {
+				path = args[0]
+			}
+			format, err := outputFormat(format, jsonOutput)
+			if err != nil {
+				return err
+			}
+			smells := intelligence.DefaultSmellOptions()
+			options := intelligence.ProjectContextOptions{CoverProfile: coverProfile, Smells: &smells}
+			if !noCache {
+				options.Cache = intelligence.OpenProjectCache(path)
+			}
+
+			project, err := intelligence.AnalyzeProject(context.Background(), path, options)
+			if err != nil {
+				return fmt.Errorf("project analysis failed: %w", err)
+			}
+			if err := options.Cache.Save(); err != nil {
+				slog.Warn("Failed to save analysis cache", "error", err)
+			}
+
+			switch format {
+			case formatJSON:
+				encoder := json.NewEncoder(os.Stdout)
+				encoder.SetIndent("", "  ")
+				return encoder.Encode(project)
+			case formatSARIF:
+				return writeSARIF(path, func(b *sarif.Builder, prefix string) {
+					intelligence.AddSmellResults(b, prefix, project.Smells)
+					intelligence.AddSecretResults(b, prefix, project.Secrets)
+				})
+			}
+			fmt.Print(project.PromptContext())
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&coverProfile, "coverprofile", "", "Go cover profile to report statement coverage")
+	cmd.Flags().BoolVar(&jsonOutput, "json", false, "Print the full project context as JSON (same as --format json)")
+	cmd.Flags().StringVar(&format, "format", formatText, "Output format: text, json, or sarif (smells and secrets)")
+	cmd.Flags().BoolVar(&noCache, "no-cache", false, "Re-parse every file instead of reusing cached results")
+
+	return cmd
+}
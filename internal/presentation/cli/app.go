@@ -2,6 +2,7 @@ package cli
 
 import (
 	"github.com/SamyRai/juleson/internal/config"
+	"github.com/SamyRai/juleson/internal/presentation/cli/analyze"
 	"github.com/SamyRai/juleson/internal/presentation/cli/core"
 	"github.com/SamyRai/juleson/internal/presentation/cli/dev"
 	"github.com/SamyRai/juleson/internal/presentation/cli/github"
@@ -55,6 +56,7 @@ func (a *App) setupCommands() {
 		Long:    "A CLI and MCP server for operating Google's Jules coding-agent sessions",
 		Version: core.Version,
 	}
+	a.rootCmd.PersistentFlags().Bool("offline", false, "Use fake Jules and GitHub APIs with demo data (also JULESON_OFFLINE=1)")
 	a.rootCmd.SetVersionTemplate(core.FormatVersion(core.GetVersionInfo()))
 
 	a.rootCmd.SetUsageTemplate(`Usage:{{if .Runnable}}
@@ -83,12 +85,37 @@ Additional help topics:{{range .Commands}}{{if .IsAdditionalHelpTopicCommand}}
 	// Core commands
 	a.rootCmd.AddCommand(core.NewSetupCommand())
 	a.rootCmd.AddCommand(core.NewSourcesCommand(a.container.Config()))
+	a.rootCmd.AddCommand(core.NewWorkspaceCommand())
 	a.rootCmd.AddCommand(core.NewActivitiesCommand(a.container.Config()))
 	a.rootCmd.AddCommand(core.NewCompletionCommand())
 	a.rootCmd.AddCommand(core.NewVersionCommand())
+	a.rootCmd.AddCommand(core.NewSelfUpdateCommand(a.container.Config()))
 	a.rootCmd.AddCommand(core.NewConfigCommand(a.container.Config()))
+	a.rootCmd.AddCommand(core.NewAuthCommand(a.container.Config()))
+	a.rootCmd.AddCommand(core.NewDecryptCommand())
+	a.rootCmd.AddCommand(core.NewDoctorCommand(a.container.Config()))
+	a.rootCmd.AddCommand(core.NewStatusCommand(a.container.Config()))
+	a.rootCmd.AddCommand(core.NewAuditCommand(a.container.Config()))
+	a.rootCmd.AddCommand(core.NewHistoryCommand(a.container.Config()))
+	a.rootCmd.AddCommand(core.NewGCCommand(a.container.Config()))
+	a.rootCmd.AddCommand(core.NewExportCommand(a.container.Config()))
+	a.rootCmd.AddCommand(core.NewImportCommand(a.container.Config()))
+	a.rootCmd.AddCommand(core.NewInsightsCommand(a.container.Config()))
+	a.rootCmd.AddCommand(core.NewReviewCommand(a.container.Config()))
+	a.rootCmd.AddCommand(core.NewOrchestrateCommand(a.container.Config()))
+	a.rootCmd.AddCommand(core.NewEventsCommand(a.container.Config()))
+	a.rootCmd.AddCommand(core.NewNotifyCommand(a.container.Config()))
+	a.rootCmd.AddCommand(core.NewAlertsCommand(a.container.Config()))
+	a.rootCmd.AddCommand(core.NewIntegrationsCommand(a.container.Config()))
+	a.rootCmd.AddCommand(core.NewVCSCommand(a.container.Config()))
+	a.rootCmd.AddCommand(core.NewCICommand(a.container.Config()))
+	a.rootCmd.AddCommand(core.NewPolicyCommand(a.container.Config()))
+	a.rootCmd.AddCommand(core.NewWatchCommand(a.container.Config()))
+	a.rootCmd.AddCommand(core.NewHooksCommand(a.container.Config()))
+	a.rootCmd.AddCommand(core.NewDockerCommand())
 	a.rootCmd.AddCommand(core.NewInitCommand(a.formatters.ConfigGen.GenerateProjectConfig))
 	a.rootCmd.AddCommand(core.NewTemplateCommand(
+		a.container.Config(),
 		a.container.TemplateManager,
 		core.DisplayTemplates,
 		core.DisplayTemplateDetails,
@@ -98,7 +125,10 @@ Additional help topics:{{range .Commands}}{{if .IsAdditionalHelpTopicCommand}}
 
 	// Vertical Slices
 	a.rootCmd.AddCommand(sessions.NewSessionsCommand(a.container.Config()))
+	a.rootCmd.AddCommand(sessions.NewPlanCommand(a.container.Config()))
 	a.rootCmd.AddCommand(github.NewPRCommand(a.container.Config()))
-	a.rootCmd.AddCommand(dev.NewDevCommand())
+	a.rootCmd.AddCommand(github.NewGitHubCommand(a.container.Config()))
+	a.rootCmd.AddCommand(dev.NewDevCommand(a.container.Config()))
+	a.rootCmd.AddCommand(analyze.NewAnalyzeCommand(a.container.Config()))
 	a.rootCmd.AddCommand(mcpcli.NewCommand(a.container.Config()))
 }
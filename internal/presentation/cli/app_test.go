@@ -14,6 +14,7 @@ func TestCoreCommandsDoNotRequireJulesAPIKeyForHelp(t *testing.T) {
 		{"--version"},
 		{"version"},
 		{"sessions", "--help"},
+		{"plan", "--help"},
 		{"sources", "--help"},
 		{"activities", "--help"},
 		{"official", "--help"},
@@ -9,7 +9,6 @@ import (
 
 	"github.com/SamyRai/go-jules"
 	"github.com/SamyRai/juleson/internal/config"
-	"github.com/SamyRai/juleson/internal/logger"
 
 	"github.com/spf13/cobra"
 )
@@ -54,7 +53,7 @@ func NewActivitiesCommand(cfg *config.Config) *cobra.Command {
 // listActivities lists all activities in a session.
 func ListActivities(cfg *config.Config, sessionID string, sinceValue, cursorOutput string) error {
 	// Initialize Jules client
-	julesClient := jules.NewClient(cfg.Jules.APIKey, jules.WithBaseURL(cfg.Jules.BaseURL), jules.WithTimeout(cfg.Jules.Timeout), jules.WithRetryAttempts(cfg.Jules.RetryAttempts), jules.WithDebugLog(cfg.Jules.DebugLog), jules.WithLogger(logger.New(logger.Config{Debug: cfg.Jules.DebugLog})))
+	julesClient := NewJulesClient(cfg)
 
 	ctx := context.Background()
 
@@ -147,7 +146,7 @@ func ListActivities(cfg *config.Config, sessionID string, sinceValue, cursorOutp
 // getActivity gets details for a specific activity.
 func getActivity(cfg *config.Config, sessionID string, activityID string) error {
 	// Initialize Jules client
-	julesClient := jules.NewClient(cfg.Jules.APIKey, jules.WithBaseURL(cfg.Jules.BaseURL), jules.WithTimeout(cfg.Jules.Timeout), jules.WithRetryAttempts(cfg.Jules.RetryAttempts), jules.WithDebugLog(cfg.Jules.DebugLog), jules.WithLogger(logger.New(logger.Config{Debug: cfg.Jules.DebugLog})))
+	julesClient := NewJulesClient(cfg)
 
 	ctx := context.Background()
 
@@ -0,0 +1,184 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/SamyRai/juleson/internal/alerts"
+	"github.com/SamyRai/juleson/internal/config"
+	"github.com/SamyRai/juleson/internal/events"
+	"github.com/spf13/cobra"
+)
+
+// NewAlertEngine creates an engine for cfg's alert rules. Without emit,
+// alerts go straight to the notification channels and event sinks.
+func NewAlertEngine(cfg *config.Config, emit alerts.Emitter) *alerts.Engine {
+	if emit == nil {
+		emit = func(ctx context.Context, event events.Event) {
+			Notify(cfg, event)
+			ExportEvent(cfg, event)
+		}
+	}
+	return alerts.NewEngine(cfg.Alerts.AlertOptions(), emit)
+}
+
+// StartAlerts evaluates cfg's alert rules until ctx is done: event rules
+// against the events published to coordinator, and metric rules against its
+// queue and circuit breakers and the Jules API circuit breaker. Alerts are
+// published to coordinator, whose subscribers deliver them. Without a
+// coordinator, only the Jules API circuit breaker is watched.
+func StartAlerts(ctx context.Context, cfg *config.Config, coordinator *events.EventCoordinator) error {
+	var emit alerts.Emitter
+	if coordinator != nil {
+		emit = func(ctx context.Context, event events.Event) { _ = coordinator.PublishEvent(ctx, event) }
+	}
+	engine := NewAlertEngine(cfg, emit)
+	if !engine.Enabled() {
+		return nil
+	}
+	if coordinator != nil {
+		if err := coordinator.Subscribe(events.TopicAll, engine.Subscriber()); err != nil {
+			return err
+		}
+	}
+	go engine.Watch(ctx, func() map[string]float64 {
+		return alerts.CoordinatorMetrics(coordinator, julesBreaker())
+	})
+	return nil
+}
+
+// NewAlertsCommand creates the alerts command.
+func NewAlertsCommand(cfg *config.Config) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "alerts",
+		Short: "List and test alert rules",
+		Long: `Alert rules raise an alert.fired event, sent to notification channels and
+event sinks, when events or metrics cross a threshold: more than N session
+failures within a window, a message queue backlog, or a circuit breaker left
+open. See the alerts section of the configuration.`,
+	}
+
+	cmd.AddCommand(newAlertsListCommand(cfg))
+	cmd.AddCommand(newAlertsTestCommand(cfg))
+	return cmd
+}
+
+func newAlertsListCommand(cfg *config.Config) *cobra.Command {
+	return &cobra.Command{
+		Use:   "list",
+		Short: "List alert rules",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			rules := cfg.Alerts.AlertOptions().Rules
+			if len(rules) == 0 {
+				fmt.Fprintln(cmd.OutOrStdout(), "No alert rules are configured")
+				return nil
+			}
+			w := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 0, 2, ' ', 0)
+			fmt.Fprintln(w, "NAME\tSEVERITY\tCONDITION")
+			for _, rule := range rules {
+				fmt.Fprintf(w, "%s\t%s\t%s\n", rule.Name, rule.Severity, rule.Condition())
+			}
+			return w.Flush()
+		},
+	}
+}
+
+func newAlertsTestCommand(cfg *config.Config) *cobra.Command {
+	var file string
+
+	cmd := &cobra.Command{
+		Use:   "test [rule]",
+		Short: "Send a test alert, or replay an event journal against the rules",
+		Long: `Send a test alert for a rule to the notification channels and event sinks it
+would reach. With --file, replay an event journal, such as one written by
+'template run --split --events', against the event rules instead and print
+the alerts they would have raised, without sending them.`,
+		Example: `  juleson alerts test session-failures
+  juleson alerts test --file run-events.jsonl`,
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			rules := cfg.Alerts.AlertOptions().Rules
+			if len(rules) == 0 {
+				return fmt.Errorf("no alert rules are configured; set alerts.rules")
+			}
+			if file != "" {
+				return replayAlerts(cmd, cfg, file, args)
+			}
+			if len(args) == 0 {
+				return fmt.Errorf("name the rule to test")
+			}
+			var rule *alerts.Rule
+			for i := range rules {
+				if rules[i].Name == args[0] {
+					rule = &rules[i]
+				}
+			}
+			if rule == nil {
+				return fmt.Errorf("no alert rule named %s", args[0])
+			}
+			event := alerts.NewAlert(*rule, "Test alert: "+rule.Condition(), rule.Threshold)
+			notifier := NewNotifier(cfg)
+			channels := notifier.Channels(event)
+			if err := notifier.Notify(cmd.Context(), event); err != nil {
+				return fmt.Errorf("failed to send test alert: %w", err)
+			}
+			ExportEvent(cfg, event)
+			if len(channels) == 0 {
+				fmt.Fprintf(cmd.OutOrStdout(), "⚠️  %s alerts are not routed to any notification channel\n", rule.Name)
+			} else {
+				fmt.Fprintf(cmd.OutOrStdout(), "✅ Sent a test alert for %s to %s\n", rule.Name, strings.Join(channels, ", "))
+			}
+			return nil
+		},
+	}
+	cmd.Flags().StringVar(&file, "file", "", "Event journal to replay against the event rules")
+	return cmd
+}
+
+// replayAlerts prints the alerts the event rules raise over a journal,
+// limited to the named rule if one is given.
+func replayAlerts(cmd *cobra.Command, cfg *config.Config, file string, args []string) error {
+	options := cfg.Alerts.AlertOptions()
+	if len(args) == 1 {
+		var selected []alerts.Rule
+		for _, rule := range options.Rules {
+			if rule.Name == args[0] {
+				selected = append(selected, rule)
+			}
+		}
+		if len(selected) == 0 {
+			return fmt.Errorf("no alert rule named %s", args[0])
+		}
+		options.Rules = selected
+	}
+	store, err := OpenEventJournal(cfg, file)
+	if err != nil {
+		return err
+	}
+	engine := alerts.NewEngine(options, nil)
+	var fired []events.Event
+	err = store.Replay(cmd.Context(), func(event events.Event) error {
+		for _, alert := range engine.Observe(cmd.Context(), event) {
+			alert.Timestamp = event.Timestamp
+			fired = append(fired, alert)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	if len(fired) == 0 {
+		fmt.Fprintf(cmd.OutOrStdout(), "No alerts would have been raised by %d event(s)\n", store.Count())
+		return nil
+	}
+	w := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "TIME\tRULE\tMESSAGE")
+	for _, alert := range fired {
+		data, _ := events.DecodeData[events.AlertEventData](alert)
+		fmt.Fprintf(w, "%s\t%s\t%s\n", alert.Timestamp.Local().Format("2006-01-02 15:04:05"), data.Rule, data.Message)
+	}
+	return w.Flush()
+}
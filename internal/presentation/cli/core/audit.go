@@ -0,0 +1,344 @@
+package core
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/SamyRai/juleson/internal/config"
+	"github.com/SamyRai/juleson/internal/events"
+	"github.com/SamyRai/juleson/internal/logger"
+	"github.com/spf13/cobra"
+)
+
+// Audit sources identify the surface a mutating operation came from.
+const (
+	AuditSourceCLI = "cli"
+	AuditSourceMCP = "mcp"
+)
+
+// Audited actions.
+const (
+	AuditSessionCreate      = "session.create"
+	AuditSessionApprovePlan = "session.approve_plan"
+	AuditSessionMessage     = "session.message"
+	AuditSessionPlanRevised = "session.plan_revised"
+	AuditSessionDelete      = "session.delete"
+	AuditPatchApply         = "patch.apply"
+	AuditPRMerge            = "github.pr.merge"
+	AuditPRLabel            = "github.pr.label"
+	AuditPRCreate           = "github.pr.create"
+	AuditPipelineTrigger    = "github.pipeline.trigger"
+	AuditReleaseCreate      = "github.release.create"
+	AuditReleaseUpload      = "github.release.upload"
+	AuditDockerRun          = "docker.run"
+	AuditK8sApply           = "k8s.apply"
+	AuditK8sRestart         = "k8s.rollout_restart"
+	AuditGitBranch          = "git.branch"
+	AuditGitCommit          = "git.commit"
+	AuditGitCheckout        = "git.checkout"
+	AuditGitStash           = "git.stash"
+	AuditMCPDenied          = "mcp.tool.denied"
+)
+
+var (
+	auditMu sync.Mutex
+	// auditStores are the open audit journals by path; an MCP server with
+	// tenants writes one per tenant.
+	auditStores = map[string]*events.EventStore{}
+)
+
+// AuditLogPath returns the audit journal path from cfg, defaulting to
+// audit.jsonl in the user config directory.
+func AuditLogPath(cfg *config.Config) (string, error) {
+	if cfg.Audit.Path != "" {
+		return cfg.Audit.Path, nil
+	}
+	configDir, err := os.UserConfigDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to locate user config directory: %w", err)
+	}
+	return filepath.Join(configDir, "juleson", "audit.jsonl"), nil
+}
+
+// RecordAudit appends a mutating operation and its outcome to the audit log.
+// Failures to write the log are reported as warnings and never fail the
+// operation itself.
+func RecordAudit(cfg *config.Config, source, action, target string, opErr error, details map[string]interface{}) {
+	RecordAuditAs(cfg, source, "", action, target, opErr, details)
+}
+
+// RecordAuditAs records an operation like RecordAudit, attributed to actor,
+// such as the holder of the MCP token that called a tool.
+func RecordAuditAs(cfg *config.Config, source, actor, action, target string, opErr error, details map[string]interface{}) {
+	if cfg == nil || !cfg.Audit.Enabled {
+		return
+	}
+	data := events.AuditData{
+		Actor:   actor,
+		Action:  action,
+		Target:  target,
+		Success: opErr == nil,
+		Details: details,
+	}
+	if opErr != nil {
+		data.Error = opErr.Error()
+	}
+
+	event := events.NewAuditEvent(source, data)
+	store, err := openAuditStore(cfg)
+	if err == nil {
+		err = store.Store(event)
+	}
+	if err != nil {
+		logger.For(logger.SubsystemEvents).Warn("failed to write audit log", "action", action, "error", err)
+	}
+	ExportEvent(cfg, event)
+}
+
+func openAuditStore(cfg *config.Config) (*events.EventStore, error) {
+	path, err := AuditLogPath(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	auditMu.Lock()
+	defer auditMu.Unlock()
+	if store, ok := auditStores[path]; ok {
+		return store, nil
+	}
+	cipher, err := eventCipher(cfg)
+	if err != nil {
+		return nil, err
+	}
+	store, err := events.OpenAuditLog(path, cipher, logger.For(logger.SubsystemEvents))
+	if err != nil {
+		return nil, err
+	}
+	auditStores[path] = store
+	return store, nil
+}
+
+// NewAuditCommand creates the audit command.
+func NewAuditCommand(cfg *config.Config) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "audit",
+		Short: "Inspect the audit log of mutating operations",
+		Long: `Juleson records every mutating operation from the CLI and the MCP server -
+session create, plan approval, messages, and deletion, patch apply, pull request
+merges, release changes, and sandboxed container runs - in an append-only JSONL audit log.`,
+	}
+
+	cmd.AddCommand(newAuditListCommand(cfg))
+	cmd.AddCommand(newAuditExportCommand(cfg))
+
+	return cmd
+}
+
+func newAuditListCommand(cfg *config.Config) *cobra.Command {
+	var (
+		since    string
+		action   string
+		jsonMode bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "list",
+		Short: "List audit log entries",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			entries, err := loadAuditEntries(cfg, since, action)
+			if err != nil {
+				return err
+			}
+			if jsonMode {
+				return writeAuditJSONL(cmd.OutOrStdout(), entries)
+			}
+			return printAuditEntries(cmd.OutOrStdout(), entries)
+		},
+	}
+	cmd.Flags().StringVar(&since, "since", "", "Only list entries at or after this time: a duration such as 24h or 7d, a date, or RFC3339")
+	cmd.Flags().StringVar(&action, "action", "", "Only list actions with this prefix, e.g. session or github.pr.merge")
+	cmd.Flags().BoolVar(&jsonMode, "json", false, "Print entries as JSON lines")
+
+	return cmd
+}
+
+func newAuditExportCommand(cfg *config.Config) *cobra.Command {
+	var (
+		since  string
+		action string
+		format string
+		output string
+	)
+
+	cmd := &cobra.Command{
+		Use:   "export",
+		Short: "Export audit log entries as JSONL or CSV",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			entries, err := loadAuditEntries(cfg, since, action)
+			if err != nil {
+				return err
+			}
+
+			out := cmd.OutOrStdout()
+			if output != "" && output != "-" {
+				file, err := os.OpenFile(output, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0o600)
+				if err != nil {
+					return fmt.Errorf("failed to create export file: %w", err)
+				}
+				defer file.Close()
+				out = file
+			}
+
+			switch format {
+			case "jsonl":
+				err = writeAuditJSONL(out, entries)
+			case "csv":
+				err = writeAuditCSV(out, entries)
+			default:
+				return fmt.Errorf("unknown export format %q: expected jsonl or csv", format)
+			}
+			if err != nil {
+				return err
+			}
+			if output != "" && output != "-" {
+				fmt.Fprintf(cmd.ErrOrStderr(), "Exported %d audit entries to %s\n", len(entries), output)
+			}
+			return nil
+		},
+	}
+	cmd.Flags().StringVar(&since, "since", "", "Only export entries at or after this time: a duration such as 24h or 7d, a date, or RFC3339")
+	cmd.Flags().StringVar(&action, "action", "", "Only export actions with this prefix")
+	cmd.Flags().StringVar(&format, "format", "jsonl", "Export format: jsonl or csv")
+	cmd.Flags().StringVarP(&output, "output", "o", "", "Write to this file instead of stdout")
+
+	return cmd
+}
+
+// auditEntry is one decoded audit log record.
+type auditEntry struct {
+	events.AuditData
+	Time   time.Time `json:"time"`
+	Source string    `json:"source"`
+	ID     string    `json:"id"`
+}
+
+func loadAuditEntries(cfg *config.Config, since, action string) ([]auditEntry, error) {
+	cutoff, err := parseAuditSince(since, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	store, err := openAuditStore(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open audit log: %w", err)
+	}
+
+	var entries []auditEntry
+	for _, stored := range events.AuditEntries(store, cutoff) {
+		data, err := events.DecodeAuditData(stored.Event)
+		if err != nil {
+			return nil, err
+		}
+		if action != "" && data.Action != action && !strings.HasPrefix(data.Action, action+".") {
+			continue
+		}
+		entries = append(entries, auditEntry{
+			AuditData: data,
+			Time:      stored.Timestamp,
+			Source:    stored.Source,
+			ID:        stored.ID,
+		})
+	}
+	return entries, nil
+}
+
+// parseAuditSince accepts a duration before now (including a d suffix for
+// days), a YYYY-MM-DD date, or an RFC3339 timestamp. Empty means no cutoff.
+func parseAuditSince(value string, now time.Time) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+	if days, ok := strings.CutSuffix(value, "d"); ok {
+		if n, err := strconv.Atoi(days); err == nil && n >= 0 {
+			return now.AddDate(0, 0, -n), nil
+		}
+	}
+	if d, err := time.ParseDuration(value); err == nil {
+		return now.Add(-d), nil
+	}
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	if t, err := time.ParseInLocation("2006-01-02", value, time.Local); err == nil {
+		return t, nil
+	}
+	return time.Time{}, fmt.Errorf("invalid --since %q: use a duration such as 24h or 7d, a date, or RFC3339", value)
+}
+
+func printAuditEntries(w io.Writer, entries []auditEntry) error {
+	if len(entries) == 0 {
+		fmt.Fprintln(w, "No audit entries found.")
+		return nil
+	}
+	for _, entry := range entries {
+		result := "ok"
+		if !entry.Success {
+			result = "failed: " + entry.Error
+		}
+		source := entry.Source
+		if entry.Actor != "" {
+			source += ":" + entry.Actor
+		}
+		fmt.Fprintf(w, "%s  %-4s  %-22s  %-30s  %s\n",
+			entry.Time.Local().Format("2006-01-02 15:04:05"), source, entry.Action, entry.Target, result)
+	}
+	return nil
+}
+
+func writeAuditJSONL(w io.Writer, entries []auditEntry) error {
+	encoder := json.NewEncoder(w)
+	for _, entry := range entries {
+		if err := encoder.Encode(entry); err != nil {
+			return fmt.Errorf("failed to write audit entry: %w", err)
+		}
+	}
+	return nil
+}
+
+func writeAuditCSV(w io.Writer, entries []auditEntry) error {
+	writer := csv.NewWriter(w)
+	if err := writer.Write([]string{"time", "source", "actor", "action", "target", "success", "error", "details"}); err != nil {
+		return err
+	}
+	for _, entry := range entries {
+		details := ""
+		if len(entry.Details) > 0 {
+			raw, err := json.Marshal(entry.Details)
+			if err != nil {
+				return fmt.Errorf("failed to encode audit details: %w", err)
+			}
+			details = string(raw)
+		}
+		if err := writer.Write([]string{
+			entry.Time.Format(time.RFC3339),
+			entry.Source,
+			entry.Actor,
+			entry.Action,
+			entry.Target,
+			strconv.FormatBool(entry.Success),
+			entry.Error,
+			details,
+		}); err != nil {
+			return err
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
@@ -0,0 +1,106 @@
+package core
+
+import (
+	"bytes"
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/SamyRai/juleson/internal/config"
+	"github.com/SamyRai/juleson/internal/policy"
+)
+
+func TestRecordAuditAndList(t *testing.T) {
+	cfg := &config.Config{Audit: config.AuditConfig{
+		Enabled: true,
+		Path:    filepath.Join(t.TempDir(), "audit.jsonl"),
+	}}
+
+	RecordAudit(cfg, AuditSourceCLI, AuditSessionCreate, "sessions/1", nil, map[string]interface{}{"source": "sources/github/o/r"})
+	RecordAudit(cfg, AuditSourceMCP, AuditPatchApply, "sessions/1", errors.New("conflict"), nil)
+	RecordAudit(cfg, AuditSourceCLI, AuditPRMerge, "https://github.com/o/r/pull/1", nil, nil)
+
+	cmd := NewAuditCommand(cfg)
+	var out bytes.Buffer
+	cmd.SetOut(&out)
+	cmd.SetArgs([]string{"list", "--since", "1h", "--action", "session"})
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("audit list: %v", err)
+	}
+	output := out.String()
+	if !strings.Contains(output, AuditSessionCreate) || strings.Contains(output, AuditPRMerge) {
+		t.Errorf("unexpected list output:\n%s", output)
+	}
+
+	out.Reset()
+	cmd = NewAuditCommand(cfg)
+	cmd.SetOut(&out)
+	cmd.SetArgs([]string{"export", "--format", "csv"})
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("audit export: %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if len(lines) != 4 {
+		t.Fatalf("export lines = %d, want header and 3 entries:\n%s", len(lines), out.String())
+	}
+	if !strings.Contains(lines[2], "patch.apply") || !strings.Contains(lines[2], "false,conflict") {
+		t.Errorf("failed patch apply not exported correctly: %s", lines[2])
+	}
+}
+
+func TestRecordAuditDisabled(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.jsonl")
+	RecordAudit(&config.Config{Audit: config.AuditConfig{Path: path}}, AuditSourceCLI, AuditSessionDelete, "sessions/1", nil, nil)
+
+	if matches, _ := filepath.Glob(path); len(matches) != 0 {
+		t.Errorf("disabled audit log wrote %v", matches)
+	}
+}
+
+func TestParseAuditSince(t *testing.T) {
+	now := time.Date(2026, 5, 10, 12, 0, 0, 0, time.UTC)
+	tests := map[string]time.Time{
+		"":                     {},
+		"24h":                  now.Add(-24 * time.Hour),
+		"7d":                   now.AddDate(0, 0, -7),
+		"2026-05-01T08:00:00Z": time.Date(2026, 5, 1, 8, 0, 0, 0, time.UTC),
+	}
+	for input, want := range tests {
+		got, err := parseAuditSince(input, now)
+		if err != nil {
+			t.Fatalf("parseAuditSince(%q): %v", input, err)
+		}
+		if !got.Equal(want) {
+			t.Errorf("parseAuditSince(%q) = %v, want %v", input, got, want)
+		}
+	}
+	if _, err := parseAuditSince("last week", now); err == nil {
+		t.Error("expected error for invalid --since")
+	}
+}
+
+func TestCheckSessionBudget(t *testing.T) {
+	cfg := &config.Config{
+		Audit:  config.AuditConfig{Enabled: true, Path: filepath.Join(t.TempDir(), "audit.jsonl")},
+		Policy: config.PolicyConfig{Budget: config.BudgetConfig{MaxSessionsPerDay: 2}},
+	}
+	RecordAudit(cfg, AuditSourceCLI, AuditSessionCreate, "sessions/1", nil, nil)
+	RecordAudit(cfg, AuditSourceCLI, AuditSessionCreate, "sources/github/o/r", errors.New("quota"), nil)
+
+	if err := CheckSessionBudget(cfg, AuditSourceCLI, 1); err != nil {
+		t.Fatalf("second session refused: %v", err)
+	}
+	err := CheckSessionBudget(cfg, AuditSourceMCP, 2)
+	if !errors.Is(err, policy.ErrBudgetExceeded) {
+		t.Fatalf("err = %v, want budget exceeded", err)
+	}
+	entries, err := loadAuditEntries(cfg, "", AuditBudgetExceeded)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 || entries[0].Details["limit"] != policy.LimitSessionsPerDay {
+		t.Errorf("budget event not recorded: %+v", entries)
+	}
+}
@@ -0,0 +1,253 @@
+package core
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/SamyRai/juleson/internal/config"
+	"github.com/SamyRai/juleson/internal/presentation/views/theme"
+	"github.com/SamyRai/juleson/internal/secrets"
+	"github.com/spf13/cobra"
+)
+
+// credentialEnvVars maps secret keys to the environment variables that
+// override them.
+var credentialEnvVars = map[string]string{
+	secrets.KeyJulesAPIKey:  "JULES_API_KEY",
+	secrets.KeyGitHubToken:  "GITHUB_TOKEN",
+	secrets.KeyGeminiAPIKey: "GEMINI_API_KEY",
+}
+
+// NewAuthCommand creates the auth command.
+func NewAuthCommand(cfg *config.Config) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "auth",
+		Short: "Manage stored API credentials",
+		Long: `Store Jules, GitHub, and Gemini credentials in the operating system keychain
+(macOS Keychain, Secret Service, or Windows Credential Manager). When no keychain
+is available, credentials are kept in an encrypted file in the user config
+directory instead of plaintext config or environment files.`,
+	}
+
+	cmd.AddCommand(newAuthLoginCommand())
+	cmd.AddCommand(newAuthStatusCommand(cfg))
+	cmd.AddCommand(newAuthLogoutCommand())
+
+	return cmd
+}
+
+func newAuthLoginCommand() *cobra.Command {
+	var (
+		values    = map[string]*string{}
+		fromEnv   bool
+		storeKind string
+	)
+
+	cmd := &cobra.Command{
+		Use:   "login",
+		Short: "Store API credentials",
+		Long: `Store API credentials securely. Pass values with flags, import them from the
+environment with --from-env, or omit both to be prompted for each credential.
+Leave a prompt empty to keep the current value.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			store, err := openSecretStore(storeKind)
+			if err != nil {
+				return err
+			}
+
+			provided := map[string]string{}
+			for key, value := range values {
+				if *value != "" {
+					provided[key] = *value
+				}
+			}
+			if fromEnv {
+				for _, key := range secrets.Keys() {
+					if value := os.Getenv(credentialEnvVars[key]); value != "" && provided[key] == "" {
+						provided[key] = value
+					}
+				}
+			}
+			if len(provided) == 0 && !fromEnv {
+				for _, key := range secrets.Keys() {
+					value, err := theme.InputSecret(fmt.Sprintf("%s (leave empty to skip)", secrets.Describe(key)))
+					if err != nil {
+						return fmt.Errorf("failed to read %s: %w", secrets.Describe(key), err)
+					}
+					if value = strings.TrimSpace(value); value != "" {
+						provided[key] = value
+					}
+				}
+			}
+			if len(provided) == 0 {
+				return fmt.Errorf("no credentials provided")
+			}
+
+			return storeCredentials(cmd.OutOrStdout(), store, provided)
+		},
+	}
+
+	values[secrets.KeyJulesAPIKey] = cmd.Flags().String("jules-api-key", "", "Jules API key to store")
+	values[secrets.KeyGitHubToken] = cmd.Flags().String("github-token", "", "GitHub token to store")
+	values[secrets.KeyGeminiAPIKey] = cmd.Flags().String("gemini-api-key", "", "Gemini API key to store")
+	cmd.Flags().BoolVar(&fromEnv, "from-env", false, "Import JULES_API_KEY, GITHUB_TOKEN, and GEMINI_API_KEY from the environment")
+	cmd.Flags().StringVar(&storeKind, "store", "auto", "Credential store: auto, keychain, or file")
+
+	return cmd
+}
+
+func newAuthStatusCommand(cfg *config.Config) *cobra.Command {
+	return &cobra.Command{
+		Use:   "status",
+		Short: "Show where each credential is loaded from",
+		Long:  "Show whether each credential is configured and where it comes from. Secret values are never printed.",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			store, err := openSecretStore("auto")
+			if err != nil {
+				return err
+			}
+
+			out := cmd.OutOrStdout()
+			fmt.Fprintf(out, "🔐 Credential store: %s\n\n", store.Name())
+			for _, key := range secrets.Keys() {
+				fmt.Fprintf(out, "%s\n", describeCredentialSource(cfg, store, key))
+			}
+			return nil
+		},
+	}
+}
+
+func newAuthLogoutCommand() *cobra.Command {
+	var keys []string
+
+	cmd := &cobra.Command{
+		Use:   "logout",
+		Short: "Remove stored credentials",
+		Long:  "Remove credentials from the keychain and the encrypted file store. Environment variables and config values are not changed.",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(keys) == 0 {
+				keys = secrets.Keys()
+			}
+			for _, key := range keys {
+				if !secrets.IsKnownKey(key) {
+					return fmt.Errorf("unknown credential %q (expected one of: %s)", key, strings.Join(secrets.Keys(), ", "))
+				}
+			}
+
+			dir, err := secrets.DefaultDir()
+			if err != nil {
+				return err
+			}
+			stores := []secrets.Store{secrets.NewFileStore(dir)}
+			if keychain := secrets.NewKeychainStore(); keychain.Available() {
+				stores = append([]secrets.Store{keychain}, stores...)
+			}
+			chain := secrets.NewChainStore(stores...)
+
+			for _, key := range keys {
+				if err := chain.Delete(key); err != nil {
+					return err
+				}
+				fmt.Fprintf(cmd.OutOrStdout(), "🗑️  Removed %s\n", secrets.Describe(key))
+			}
+			return nil
+		},
+	}
+	cmd.Flags().StringSliceVar(&keys, "key", nil, "Credential to remove: jules_api_key, github_token, or gemini_api_key (default: all)")
+
+	return cmd
+}
+
+// openSecretStore returns the store selected by kind.
+func openSecretStore(kind string) (secrets.Store, error) {
+	switch kind {
+	case "", "auto":
+		return secrets.Default()
+	case "keychain":
+		keychain := secrets.NewKeychainStore()
+		if !keychain.Available() {
+			return nil, fmt.Errorf("%s tooling is not installed; use --store file", keychain.Name())
+		}
+		return keychain, nil
+	case "file":
+		dir, err := secrets.DefaultDir()
+		if err != nil {
+			return nil, err
+		}
+		return secrets.NewFileStore(dir), nil
+	default:
+		return nil, fmt.Errorf("unknown store %q: expected auto, keychain, or file", kind)
+	}
+}
+
+func storeCredentials(out io.Writer, store secrets.Store, values map[string]string) error {
+	for _, key := range secrets.Keys() {
+		value, ok := values[key]
+		if !ok {
+			continue
+		}
+
+		holder := store
+		if chain, isChain := store.(*secrets.ChainStore); isChain {
+			used, err := chain.SetWithStore(key, value)
+			if err != nil {
+				return err
+			}
+			holder = used
+		} else if err := store.Set(key, value); err != nil {
+			return err
+		}
+
+		fmt.Fprintf(out, "✅ Stored %s in %s\n", secrets.Describe(key), holder.Name())
+	}
+	return nil
+}
+
+// describeCredentialSource reports where a credential is resolved from,
+// mirroring the config precedence: config file, environment, then store.
+func describeCredentialSource(cfg *config.Config, store secrets.Store, key string) string {
+	name := secrets.Describe(key)
+	envVar := credentialEnvVars[key]
+	envValue := os.Getenv(envVar)
+
+	var (
+		storedValue string
+		holder      secrets.Store
+		err         error
+	)
+	if chain, isChain := store.(*secrets.ChainStore); isChain {
+		storedValue, holder, err = chain.Locate(key)
+	} else {
+		storedValue, err = store.Get(key)
+		holder = store
+	}
+	if err != nil && !errors.Is(err, secrets.ErrNotFound) {
+		return fmt.Sprintf("⚠️  %s: store error: %v", name, err)
+	}
+
+	var effective string
+	if cfg != nil {
+		switch key {
+		case secrets.KeyJulesAPIKey:
+			effective = cfg.Jules.APIKey
+		case secrets.KeyGitHubToken:
+			effective = cfg.GitHub.Token
+		}
+	}
+
+	switch {
+	case effective != "" && effective != envValue && effective != storedValue:
+		return fmt.Sprintf("⚠️  %s: plaintext config file (run 'juleson auth login' to move it to the keychain)", name)
+	case envValue != "" && storedValue != "":
+		return fmt.Sprintf("✅ %s: %s (overrides value stored in %s)", name, envVar, holder.Name())
+	case envValue != "":
+		return fmt.Sprintf("✅ %s: %s environment variable", name, envVar)
+	case storedValue != "":
+		return fmt.Sprintf("✅ %s: stored in %s", name, holder.Name())
+	default:
+		return fmt.Sprintf("❌ %s: not configured", name)
+	}
+}
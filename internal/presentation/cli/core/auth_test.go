@@ -0,0 +1,48 @@
+package core
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/SamyRai/juleson/internal/config"
+	"github.com/SamyRai/juleson/internal/secrets"
+)
+
+func TestStoreCredentialsAndDescribeSource(t *testing.T) {
+	t.Setenv("JULES_API_KEY", "")
+	t.Setenv("GITHUB_TOKEN", "")
+	t.Setenv("GEMINI_API_KEY", "")
+
+	store := secrets.NewFileStore(t.TempDir())
+
+	var out bytes.Buffer
+	if err := storeCredentials(&out, store, map[string]string{
+		secrets.KeyJulesAPIKey: "stored-jules-key",
+	}); err != nil {
+		t.Fatalf("storeCredentials() error = %v", err)
+	}
+	if strings.Contains(out.String(), "stored-jules-key") {
+		t.Fatalf("output leaked secret: %q", out.String())
+	}
+
+	cfg := &config.Config{
+		Jules:  config.JulesConfig{APIKey: "stored-jules-key"},
+		GitHub: config.GitHubConfig{Token: "plaintext-token"},
+	}
+
+	if got := describeCredentialSource(cfg, store, secrets.KeyJulesAPIKey); !strings.Contains(got, "stored in encrypted file") {
+		t.Errorf("jules source = %q, want encrypted file", got)
+	}
+	if got := describeCredentialSource(cfg, store, secrets.KeyGitHubToken); !strings.Contains(got, "plaintext config file") {
+		t.Errorf("github source = %q, want plaintext config warning", got)
+	}
+	if got := describeCredentialSource(cfg, store, secrets.KeyGeminiAPIKey); !strings.Contains(got, "not configured") {
+		t.Errorf("gemini source = %q, want not configured", got)
+	}
+
+	t.Setenv("GEMINI_API_KEY", "env-gemini")
+	if got := describeCredentialSource(cfg, store, secrets.KeyGeminiAPIKey); !strings.Contains(got, "GEMINI_API_KEY environment variable") {
+		t.Errorf("gemini source = %q, want environment", got)
+	}
+}
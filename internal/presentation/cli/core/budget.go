@@ -0,0 +1,126 @@
+package core
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/SamyRai/juleson/internal/config"
+	julessessions "github.com/SamyRai/juleson/internal/jules/sessions"
+	"github.com/SamyRai/juleson/internal/policy"
+	"github.com/spf13/cobra"
+)
+
+// AuditBudgetExceeded records an operation refused by policy.budget.
+const AuditBudgetExceeded = "budget.exceeded"
+
+// SessionsCreatedToday counts the sessions created successfully in the last
+// 24 hours according to the audit log.
+func SessionsCreatedToday(cfg *config.Config) (int, error) {
+	if !cfg.Audit.Enabled {
+		return 0, nil
+	}
+	entries, err := loadAuditEntries(cfg, "24h", AuditSessionCreate)
+	if err != nil {
+		return 0, err
+	}
+	created := 0
+	for _, entry := range entries {
+		if entry.Action == AuditSessionCreate && entry.Success {
+			created++
+		}
+	}
+	return created, nil
+}
+
+// CheckSessionBudget checks that creating n more sessions stays within
+// policy.budget.max_sessions_per_day. An exceeded budget is recorded in the
+// audit log.
+func CheckSessionBudget(cfg *config.Config, source string, n int) error {
+	budget := cfg.Policy.PolicyBudget()
+	if budget.MaxSessionsPerDay == 0 {
+		return nil
+	}
+	created, err := SessionsCreatedToday(cfg)
+	if err != nil {
+		return fmt.Errorf("failed to count sessions for the budget: %w", err)
+	}
+	return recordBudget(cfg, source, "sessions", budget.CheckSessions(created, n))
+}
+
+// CheckFilesBudget checks that a patch apply changing files stays within
+// policy.budget.max_files_changed. An exceeded budget is recorded in the
+// audit log.
+func CheckFilesBudget(cfg *config.Config, source, target string, files int) error {
+	return recordBudget(cfg, source, target, cfg.Policy.PolicyBudget().CheckFilesChanged(files))
+}
+
+func recordBudget(cfg *config.Config, source, target string, err error) error {
+	var exceeded *policy.BudgetExceededError
+	if errors.As(err, &exceeded) {
+		RecordAudit(cfg, source, AuditBudgetExceeded, target, err, map[string]interface{}{
+			"limit": exceeded.Limit,
+			"used":  exceeded.Used,
+			"max":   exceeded.Max,
+		})
+	}
+	return err
+}
+
+// CheckEstimate requires confirmation for work estimated to take longer
+// than policy.budget.confirm_above.
+func CheckEstimate(cfg *config.Config, estimate julessessions.Estimate, confirmed bool) error {
+	limit := cfg.Policy.Budget.ConfirmAbove
+	if limit == 0 || confirmed || estimate.Total <= limit {
+		return nil
+	}
+	return fmt.Errorf("estimated %s of session time exceeds policy.budget.confirm_above (%s); pass --confirm to start anyway",
+		estimate.Total.Round(time.Minute), limit)
+}
+
+// PrintEstimate writes an estimate in the form shown by --estimate.
+func PrintEstimate(w io.Writer, cfg *config.Config, estimate julessessions.Estimate) {
+	fmt.Fprintf(w, "📊 Estimate: %d session(s), %d phase(s) each\n", estimate.Sessions, estimate.Phases)
+	if estimate.Samples == 0 {
+		fmt.Fprintln(w, "   No completed sessions to estimate session time from.")
+	} else {
+		fmt.Fprintf(w, "   Session time: ~%s each, ~%s total (median of %d completed session(s))\n",
+			estimate.PerSession.Round(time.Minute), estimate.Total.Round(time.Minute), estimate.Samples)
+	}
+	if limit := cfg.Policy.Budget.ConfirmAbove; limit > 0 && estimate.Total > limit {
+		fmt.Fprintf(w, "   Exceeds policy.budget.confirm_above (%s); starting requires --confirm.\n", limit)
+	}
+}
+
+func newPolicyBudgetCommand(cfg *config.Config) *cobra.Command {
+	return &cobra.Command{
+		Use:   "budget",
+		Short: "Show budget limits and how much of them is used",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			budget := cfg.Policy.PolicyBudget()
+			out := cmd.OutOrStdout()
+
+			created, err := SessionsCreatedToday(cfg)
+			if err != nil {
+				return err
+			}
+			fmt.Fprintf(out, "%-20s %d used in the last 24h, limit %s\n", policy.LimitSessionsPerDay, created, budgetLimit(budget.MaxSessionsPerDay))
+			fmt.Fprintf(out, "%-20s limit %s per patch apply\n", policy.LimitFilesChanged, budgetLimit(budget.MaxFilesChanged))
+			confirmAbove := "none"
+			if cfg.Policy.Budget.ConfirmAbove > 0 {
+				confirmAbove = cfg.Policy.Budget.ConfirmAbove.String()
+			}
+			fmt.Fprintf(out, "%-20s %s of estimated session time\n", "confirm_above", confirmAbove)
+			return nil
+		},
+	}
+}
+
+func budgetLimit(max int) string {
+	if max == 0 {
+		return "none"
+	}
+	return fmt.Sprint(max)
+}
@@ -0,0 +1,109 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/SamyRai/juleson/internal/ci"
+	"github.com/SamyRai/juleson/internal/vcs"
+)
+
+// fakeCIProvider lists pipelines from runs, keyed by project and workflow.
+type fakeCIProvider struct {
+	runs      map[string][]ci.Pipeline
+	active    atomic.Int32
+	maxActive atomic.Int32
+}
+
+func (p *fakeCIProvider) Kind() ci.Kind { return ci.KindGitHub }
+
+func (p *fakeCIProvider) ListPipelines(ctx context.Context, project, ref string, limit int) ([]ci.Pipeline, error) {
+	return p.ListWorkflowPipelines(ctx, project, "", ref, limit)
+}
+
+func (p *fakeCIProvider) ListWorkflowPipelines(_ context.Context, project, workflow, _ string, limit int) ([]ci.Pipeline, error) {
+	active := p.active.Add(1)
+	defer p.active.Add(-1)
+	for {
+		seen := p.maxActive.Load()
+		if active <= seen || p.maxActive.CompareAndSwap(seen, active) {
+			break
+		}
+	}
+	time.Sleep(10 * time.Millisecond)
+
+	runs, ok := p.runs[project+"/"+workflow]
+	if !ok {
+		return nil, errors.New("no such workflow")
+	}
+	return runs[:min(limit, len(runs))], nil
+}
+
+func (p *fakeCIProvider) GetPipeline(context.Context, string, int64) (*ci.Pipeline, error) {
+	return nil, vcs.ErrNotSupported
+}
+
+func (p *fakeCIProvider) TriggerPipeline(context.Context, string, ci.Trigger) (*ci.Pipeline, error) {
+	return nil, vcs.ErrNotSupported
+}
+
+func (p *fakeCIProvider) PipelineLogs(context.Context, string, int64, io.Writer) error {
+	return vcs.ErrNotSupported
+}
+
+func (p *fakeCIProvider) ListArtifacts(context.Context, string, int64) ([]ci.Artifact, error) {
+	return nil, vcs.ErrNotSupported
+}
+
+func (p *fakeCIProvider) DownloadArtifact(context.Context, string, ci.Artifact, io.Writer) error {
+	return vcs.ErrNotSupported
+}
+
+func TestListCIPipelinesMergesTargets(t *testing.T) {
+	at := func(hour int) time.Time { return time.Date(2026, 10, 1, hour, 0, 0, 0, time.UTC) }
+	provider := &fakeCIProvider{runs: map[string][]ci.Pipeline{
+		"acme/api/ci.yml":      {{ID: 1, CreatedAt: at(9)}, {ID: 2, CreatedAt: at(3)}},
+		"acme/api/deploy.yml":  {{ID: 3, CreatedAt: at(8)}},
+		"acme/web/ci.yml":      {{ID: 4, CreatedAt: at(7)}, {ID: 5, CreatedAt: at(6)}},
+		"acme/web/deploy.yml":  {{ID: 6, CreatedAt: at(10)}},
+		"acme/docs/ci.yml":     {{ID: 7, CreatedAt: at(1)}},
+		"acme/docs/deploy.yml": {},
+	}}
+	targets := []ciTarget{{"acme/api", provider}, {"acme/web", provider}, {"acme/docs", provider}}
+
+	pipelines, err := listCIPipelines(context.Background(), targets, []string{"ci.yml", "deploy.yml"}, "", 4, 2)
+	if err != nil {
+		t.Fatalf("listCIPipelines() error = %v", err)
+	}
+	var got []string
+	for _, pipeline := range pipelines {
+		got = append(got, fmt.Sprintf("%s#%d", pipeline.Repo, pipeline.ID))
+	}
+	want := []string{"acme/web#6", "acme/api#1", "acme/api#3", "acme/web#4"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("listCIPipelines() = %v, want the 4 most recent runs %v", got, want)
+	}
+	if maxActive := provider.maxActive.Load(); maxActive > 2 {
+		t.Errorf("%d listings ran at once, want at most 2", maxActive)
+	}
+
+	_, err = listCIPipelines(context.Background(), targets, []string{"release.yml"}, "", 4, 2)
+	if err == nil {
+		t.Fatal("listCIPipelines() of a missing workflow succeeded")
+	}
+}
+
+func TestListCIPipelinesSingleTarget(t *testing.T) {
+	provider := &fakeCIProvider{runs: map[string][]ci.Pipeline{"acme/api/": {{ID: 1}, {ID: 2}}}}
+
+	pipelines, err := listCIPipelines(context.Background(), []ciTarget{{"acme/api", provider}}, nil, "", 10, 4)
+	if err != nil || len(pipelines) != 2 || pipelines[0].Repo != "" {
+		t.Fatalf("listCIPipelines() = %+v, %v, want the provider's order without repositories", pipelines, err)
+	}
+}
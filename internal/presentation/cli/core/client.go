@@ -1,18 +1,128 @@
 package core
 
 import (
+	"context"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
 	"github.com/SamyRai/go-jules"
 	"github.com/SamyRai/juleson/internal/config"
+	ghclient "github.com/SamyRai/juleson/internal/github"
 	"github.com/SamyRai/juleson/internal/logger"
 )
 
+// NewJulesClient creates a Jules API client. Requests are rate limited and
+// retried with jittered exponential backoff by the transport, where the
+// timeout applies to each attempt, so the client's own retries are off.
+// Prompts and plans to approve pass the content guard first.
 func NewJulesClient(cfg *config.Config) *jules.Client {
+	applyJulesClientSettings(cfg)
+	var transport http.RoundTripper = newRetryTransport(&rateLimitedTransport{limiter: julesRateLimiter}, cfg.Jules.Timeout)
+	transport = &guardTransport{guard: NewGuard(cfg), next: transport, logger: logger.For(logger.SubsystemJules)}
 	return jules.NewClient(
 		cfg.Jules.APIKey,
 		jules.WithBaseURL(cfg.Jules.BaseURL),
-		jules.WithTimeout(cfg.Jules.Timeout),
-		jules.WithRetryAttempts(cfg.Jules.RetryAttempts),
+		jules.WithHTTPClient(&http.Client{Transport: transport}),
+		jules.WithRetryAttempts(0),
+		jules.WithUserAgent(UserAgent()),
 		jules.WithDebugLog(cfg.Jules.DebugLog),
-		jules.WithLogger(logger.New(logger.Config{Debug: cfg.Jules.DebugLog})),
+		jules.WithLogger(logger.For(logger.SubsystemJules)),
 	)
 }
+
+// applyJulesClientSettings applies the rate limit, retry policy, and circuit
+// breaker settings shared by all Jules clients.
+func applyJulesClientSettings(cfg *config.Config) {
+	julesRateLimiter.SetRate(cfg.Jules.RateLimit)
+	setJulesRetryPolicy(RetryPolicyFromConfig(cfg))
+	julesBreaker().Configure(cfg.CircuitBreaker.MaxFailures, cfg.CircuitBreaker.Timeout, cfg.CircuitBreaker.ResetTimeout)
+	breakerConfig.Store(cfg)
+}
+
+// julesRateLimiter is shared by every Jules client in the process so the
+// limit applies across commands, MCP tools, and config reloads.
+var julesRateLimiter = &rateLimiter{}
+
+// rateLimiter spaces requests evenly to stay under a requests-per-second
+// budget. A zero rate disables limiting.
+type rateLimiter struct {
+	mu       sync.Mutex
+	interval time.Duration
+	next     time.Time
+}
+
+// SetRate changes the limit in requests per second.
+func (l *rateLimiter) SetRate(perSecond float64) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if perSecond <= 0 {
+		l.interval = 0
+		return
+	}
+	l.interval = time.Duration(float64(time.Second) / perSecond)
+}
+
+// Wait blocks until the next request may be sent.
+func (l *rateLimiter) Wait(ctx context.Context) error {
+	l.mu.Lock()
+	if l.interval == 0 {
+		l.mu.Unlock()
+		return nil
+	}
+	now := time.Now()
+	if l.next.Before(now) {
+		l.next = now
+	}
+	delay := l.next.Sub(now)
+	l.next = l.next.Add(l.interval)
+	l.mu.Unlock()
+
+	if delay <= 0 {
+		return nil
+	}
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+type rateLimitedTransport struct {
+	limiter *rateLimiter
+}
+
+func (t *rateLimitedTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if err := t.limiter.Wait(req.Context()); err != nil {
+		return nil, err
+	}
+	return http.DefaultTransport.RoundTrip(req)
+}
+
+// NewGitHubClient creates a GitHub client for host using the matching
+// github.hosts entry, or the top-level github settings when host is empty or
+// not configured. A configured base URL selects GitHub Enterprise Server.
+func NewGitHubClient(cfg *config.Config, host string, julesClient *jules.Client) (*ghclient.Client, error) {
+	hostCfg := cfg.GitHub.ForHost(host)
+	if hostCfg.Token == "" {
+		if host != "" && host != "github.com" {
+			return nil, fmt.Errorf("GitHub client not configured for %s - set a token in github.hosts or GH_ENTERPRISE_TOKEN", host)
+		}
+		return nil, fmt.Errorf("GitHub client not configured - please set GITHUB_TOKEN")
+	}
+
+	client := ghclient.NewClient(hostCfg.Token, julesClient)
+	if hostCfg.BaseURL != "" {
+		var err error
+		client, err = ghclient.NewEnterpriseClient(hostCfg.Token, hostCfg.BaseURL, hostCfg.UploadURL, julesClient)
+		if err != nil {
+			return nil, err
+		}
+	}
+	client.Client.UserAgent = UserAgent()
+	return client, nil
+}
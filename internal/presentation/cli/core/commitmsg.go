@@ -0,0 +1,72 @@
+package core
+
+import (
+	"context"
+	"os"
+
+	"github.com/SamyRai/go-jules"
+	"github.com/SamyRai/juleson/internal/commitmsg"
+	"github.com/SamyRai/juleson/internal/config"
+	"github.com/SamyRai/juleson/internal/gemini"
+	"github.com/SamyRai/juleson/internal/jules/workspace"
+	"github.com/SamyRai/juleson/internal/logger"
+)
+
+// Environment variables that override gemini.replay and gemini.cassette,
+// such as JULESON_GEMINI_REPLAY=replay JULESON_GEMINI_CASSETTE=run.json to
+// reproduce a recorded run.
+const (
+	GeminiReplayEnv   = "JULESON_GEMINI_REPLAY"
+	GeminiCassetteEnv = "JULESON_GEMINI_CASSETTE"
+)
+
+// NewGeminiClient creates a Gemini client, or returns nil when no Gemini API
+// key is configured and responses are not replayed.
+func NewGeminiClient(cfg *config.Config) *gemini.Client {
+	geminiConfig := gemini.Config{
+		APIKey:         cfg.Gemini.APIKey,
+		Model:          cfg.Gemini.Model,
+		Fallbacks:      cfg.Gemini.FallbackModels,
+		Models:         cfg.Gemini.Models,
+		Routes:         cfg.Gemini.Routes,
+		EmbeddingModel: cfg.Gemini.EmbeddingModel,
+		BaseURL:        cfg.Gemini.BaseURL,
+		Timeout:        cfg.Gemini.Timeout,
+		Temperature:    cfg.Gemini.Temperature,
+		CacheTTL:       cfg.Gemini.CacheTTL,
+		Replay:         cfg.Gemini.Replay,
+		Cassette:       cfg.Gemini.Cassette,
+		Logger:         logger.For(logger.SubsystemGemini),
+	}
+	if replay := os.Getenv(GeminiReplayEnv); replay != "" {
+		geminiConfig.Replay = replay
+	}
+	if cassette := os.Getenv(GeminiCassetteEnv); cassette != "" {
+		geminiConfig.Cassette = cassette
+	}
+	if geminiConfig.CacheTTL > 0 {
+		// Without a cache directory responses are not cached.
+		geminiConfig.CacheDir, _ = gemini.DefaultCacheDir()
+	}
+	return gemini.NewClient(geminiConfig)
+}
+
+// DescribeSessions writes a conventional-commit message for the changes of
+// each session, within the scope of options, and a CHANGELOG fragment for
+// all of them. Gemini writes them when it is configured and useGemini is
+// set.
+func DescribeSessions(ctx context.Context, cfg *config.Config, client *jules.Client, sessionIDs []string, options *workspace.PatchApplicationOptions, useGemini bool) (*commitmsg.Result, error) {
+	changesets := make([]commitmsg.Changeset, 0, len(sessionIDs))
+	for _, sessionID := range sessionIDs {
+		changeset, err := commitmsg.LoadChangeset(ctx, client, sessionID, options)
+		if err != nil {
+			return nil, err
+		}
+		changesets = append(changesets, changeset)
+	}
+	var model *gemini.Client
+	if useGemini {
+		model = NewGeminiClient(cfg)
+	}
+	return commitmsg.Generate(ctx, model, changesets)
+}
@@ -0,0 +1,41 @@
+package core
+
+import (
+	"context"
+	"testing"
+
+	"github.com/SamyRai/go-jules"
+	"github.com/SamyRai/juleson/internal/commitmsg"
+	"github.com/SamyRai/juleson/internal/config"
+	"github.com/SamyRai/juleson/internal/jules/julestest"
+	"github.com/SamyRai/juleson/internal/jules/workspace"
+)
+
+func TestDescribeSessions(t *testing.T) {
+	server := julestest.NewServer()
+	defer server.Close()
+	server.AddSession(jules.Session{ID: "s1", Title: "Document setup"})
+	server.AddActivities("s1", jules.Activity{Artifacts: []jules.Artifact{{ChangeSet: &jules.ChangeSet{GitPatch: &jules.GitPatch{
+		UnidiffPatch: "diff --git a/docs/setup.md b/docs/setup.md\n--- a/docs/setup.md\n+++ b/docs/setup.md\n@@ -1 +1 @@\n-old\n+new\n" +
+			"diff --git a/main.go b/main.go\n--- a/main.go\n+++ b/main.go\n@@ -1 +1 @@\n-a\n+b\n",
+	}}}}})
+	server.AddSession(jules.Session{ID: "empty", Title: "Nothing yet"})
+
+	// Gemini is configured, but useGemini is off.
+	cfg := &config.Config{Gemini: config.GeminiConfig{APIKey: "unused"}}
+	ctx := context.Background()
+	result, err := DescribeSessions(ctx, cfg, server.Client(), []string{"s1"}, &workspace.PatchApplicationOptions{Only: []string{"docs/"}}, false)
+	if err != nil {
+		t.Fatalf("DescribeSessions: %v", err)
+	}
+	if result.Source != commitmsg.SourceHeuristic {
+		t.Errorf("source = %q", result.Source)
+	}
+	if got := result.Commits[0].Header(); got != "docs: document setup" {
+		t.Errorf("header = %q, want the docs-only selection described", got)
+	}
+
+	if _, err := DescribeSessions(ctx, cfg, server.Client(), []string{"s1", "empty"}, nil, false); err == nil {
+		t.Fatal("DescribeSessions of a session without changes succeeded")
+	}
+}
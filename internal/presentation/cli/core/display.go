@@ -44,4 +44,27 @@ func DisplayTemplateDetails(template *templates.Template) {
 			fmt.Printf("     Depends on: %s\n", strings.Join(task.DependsOn, ", "))
 		}
 	}
+
+	if variables := template.Variables(); len(variables) > 0 {
+		fmt.Println("\nVariables:")
+		for _, variable := range variables {
+			fmt.Printf("  %s", variable.Name)
+			if variable.Required {
+				fmt.Print(" (required)")
+			}
+			if variable.Type != "" {
+				fmt.Printf(" <%s>", variable.Type)
+			}
+			if len(variable.Enum) > 0 {
+				fmt.Printf(" {%s}", strings.Join(variable.Enum, "|"))
+			}
+			if variable.Default != "" {
+				fmt.Printf(" [default: %s]", variable.Default)
+			}
+			fmt.Println()
+			if variable.Description != "" {
+				fmt.Printf("     %s\n", variable.Description)
+			}
+		}
+	}
 }
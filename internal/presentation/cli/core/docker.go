@@ -0,0 +1,47 @@
+package core
+
+import (
+	"github.com/SamyRai/juleson/pkg/docker"
+	"github.com/spf13/cobra"
+)
+
+// NewDockerCommand creates the docker command.
+func NewDockerCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "docker",
+		Short: "Inspect containers through the Docker Engine API",
+		Long: `Talk to the Docker daemon from DOCKER_HOST or the default socket. The docker CLI
+does not need to be installed.`,
+	}
+
+	cmd.AddCommand(newDockerLogsCommand())
+
+	return cmd
+}
+
+func newDockerLogsCommand() *cobra.Command {
+	var opts docker.LogsOptions
+
+	cmd := &cobra.Command{
+		Use:   "logs <container>",
+		Short: "Show or follow a container's logs",
+		Example: `  juleson docker logs my-test-db --tail 50
+  juleson docker logs -f --since 10m my-test-db`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cli, err := docker.NewClient()
+			if err != nil {
+				return err
+			}
+			defer cli.Close()
+
+			return cli.Logs(cmd.Context(), args[0], opts, cmd.OutOrStdout(), cmd.ErrOrStderr())
+		},
+	}
+	cmd.Flags().BoolVarP(&opts.Follow, "follow", "f", false, "Keep streaming new output until the container stops")
+	cmd.Flags().StringVar(&opts.Since, "since", "", "Only show logs after this time: a duration such as 10m, RFC3339, or a Unix timestamp")
+	cmd.Flags().StringVarP(&opts.Tail, "tail", "n", "all", "Number of lines to show from the end of the logs")
+	cmd.Flags().BoolVarP(&opts.Timestamps, "timestamps", "t", false, "Prefix each line with its timestamp")
+
+	return cmd
+}
@@ -0,0 +1,231 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/SamyRai/go-jules"
+	"github.com/SamyRai/juleson/internal/config"
+	"github.com/spf13/cobra"
+)
+
+// doctorLookPath is replaced in tests.
+var doctorLookPath = exec.LookPath
+
+type checkStatus int
+
+const (
+	checkPass checkStatus = iota
+	checkWarn
+	checkFail
+)
+
+// doctorCheck is the outcome of one diagnostic.
+type doctorCheck struct {
+	Name   string
+	Detail string
+	Fix    string
+	Status checkStatus
+}
+
+// doctorTool describes an external binary Juleson shells out to.
+type doctorTool struct {
+	Name     string
+	Purpose  string
+	Fix      string
+	Required bool
+}
+
+var doctorTools = []doctorTool{
+	{Name: "git", Purpose: "project sync, patches, and repository detection", Required: true, Fix: "Install git from https://git-scm.com/downloads"},
+	{Name: "go", Purpose: "complexity and dependency analysis, dev commands", Fix: "Install Go from https://go.dev/dl/"},
+	{Name: "golangci-lint", Purpose: "juleson dev lint", Fix: "Install from https://golangci-lint.run/welcome/install/"},
+	{Name: "docker", Purpose: "container workflows", Fix: "Install Docker from https://docs.docker.com/get-docker/"},
+	{Name: "gofumpt", Purpose: "juleson dev fmt --gofumpt", Fix: "go install mvdan.cc/gofumpt@latest"},
+}
+
+// NewDoctorCommand creates the doctor command.
+func NewDoctorCommand(cfg *config.Config) *cobra.Command {
+	var offline bool
+
+	cmd := &cobra.Command{
+		Use:   "doctor",
+		Short: "Diagnose configuration, credentials, and required tools",
+		Long: `Check the Juleson configuration for errors, verify the Jules API key and GitHub
+token with lightweight API calls, and report missing external tools with the
+command or link that fixes each problem. Secret values are never printed.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			checks := configChecks(cfg)
+			checks = append(checks, credentialChecks(cmd.Context(), cfg, offline)...)
+			checks = append(checks, toolChecks()...)
+
+			if failed := printDoctorReport(cmd.OutOrStdout(), checks); failed > 0 {
+				return fmt.Errorf("%d check(s) failed", failed)
+			}
+			return nil
+		},
+	}
+	cmd.Flags().BoolVar(&offline, "offline", false, "Skip checks that call the Jules and GitHub APIs")
+
+	return cmd
+}
+
+func configChecks(cfg *config.Config) []doctorCheck {
+	var checks []doctorCheck
+
+	file := config.File()
+	if file == "" {
+		checks = append(checks, doctorCheck{
+			Name:   "Config file",
+			Status: checkWarn,
+			Detail: "no juleson.yaml found; using defaults and environment variables",
+			Fix:    "Run 'juleson setup' or copy configs/juleson.example.yaml",
+		})
+	} else {
+		checks = append(checks, doctorCheck{Name: "Config file", Status: checkPass, Detail: file})
+	}
+
+	if err := cfg.Validate(); err != nil {
+		checks = append(checks, doctorCheck{
+			Name:   "Config values",
+			Status: checkFail,
+			Detail: strings.ReplaceAll(err.Error(), "\n", "; "),
+			Fix:    "Correct the listed keys; see docs/CONFIGURATION.md",
+		})
+	} else {
+		checks = append(checks, doctorCheck{Name: "Config values", Status: checkPass, Detail: "valid"})
+	}
+
+	if unknown := config.UnknownKeys(); len(unknown) > 0 {
+		checks = append(checks, doctorCheck{
+			Name:   "Config keys",
+			Status: checkWarn,
+			Detail: "unrecognized: " + strings.Join(unknown, ", "),
+			Fix:    "Check these keys for typos; they are ignored",
+		})
+	}
+
+	return checks
+}
+
+func credentialChecks(ctx context.Context, cfg *config.Config, offline bool) []doctorCheck {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	julesCheck := doctorCheck{Name: "Jules API key"}
+	switch {
+	case cfg.Jules.APIKey == "":
+		julesCheck.Status = checkFail
+		julesCheck.Detail = "not configured"
+		julesCheck.Fix = "Run 'juleson auth login' or set JULES_API_KEY"
+	case offline:
+		julesCheck.Status = checkPass
+		julesCheck.Detail = "configured (not verified, --offline)"
+	default:
+		if err := verifyJulesAPIKey(ctx, cfg); err != nil {
+			julesCheck.Status = checkFail
+			julesCheck.Detail = fmt.Sprintf("rejected by %s: %v", cfg.Jules.BaseURL, err)
+			julesCheck.Fix = "Create a new key at https://jules.google.com/settings and run 'juleson auth login'"
+		} else {
+			julesCheck.Status = checkPass
+			julesCheck.Detail = "valid"
+		}
+	}
+
+	githubCheck := doctorCheck{Name: "GitHub token"}
+	switch {
+	case cfg.GitHub.Token == "":
+		githubCheck.Status = checkWarn
+		githubCheck.Detail = "not configured; Jules-created PR commands are unavailable"
+		githubCheck.Fix = "Run 'juleson auth login --github-token TOKEN' or set GITHUB_TOKEN"
+	case offline:
+		githubCheck.Status = checkPass
+		githubCheck.Detail = "configured (not verified, --offline)"
+	default:
+		login, err := verifyGitHubToken(ctx, cfg)
+		if err != nil {
+			githubCheck.Status = checkFail
+			githubCheck.Detail = fmt.Sprintf("rejected: %v", err)
+			githubCheck.Fix = "Create a token at https://github.com/settings/tokens with repo scope"
+		} else {
+			githubCheck.Status = checkPass
+			githubCheck.Detail = "authenticated as " + login
+		}
+	}
+
+	return []doctorCheck{julesCheck, githubCheck}
+}
+
+func verifyJulesAPIKey(ctx context.Context, cfg *config.Config) error {
+	ctx, cancel := context.WithTimeout(ctx, 15*time.Second)
+	defer cancel()
+
+	_, err := NewJulesClient(cfg).Sources().List(ctx, &jules.ListSourcesOptions{PageSize: 1})
+	return err
+}
+
+func verifyGitHubToken(ctx context.Context, cfg *config.Config) (string, error) {
+	ctx, cancel := context.WithTimeout(ctx, 15*time.Second)
+	defer cancel()
+
+	client, err := NewGitHubClient(cfg, "", nil)
+	if err != nil {
+		return "", err
+	}
+	user, _, err := client.Users.Get(ctx, "")
+	if err != nil {
+		return "", err
+	}
+	return user.GetLogin(), nil
+}
+
+func toolChecks() []doctorCheck {
+	checks := make([]doctorCheck, 0, len(doctorTools))
+	for _, tool := range doctorTools {
+		check := doctorCheck{Name: tool.Name}
+		if path, err := doctorLookPath(tool.Name); err == nil {
+			check.Status = checkPass
+			check.Detail = path
+		} else {
+			check.Status = checkWarn
+			if tool.Required {
+				check.Status = checkFail
+			}
+			check.Detail = "not found in PATH; needed for " + tool.Purpose
+			check.Fix = tool.Fix
+		}
+		checks = append(checks, check)
+	}
+	return checks
+}
+
+// printDoctorReport writes the checks and returns the number of failures.
+func printDoctorReport(out io.Writer, checks []doctorCheck) int {
+	fmt.Fprintln(out, "🩺 Juleson doctor")
+	fmt.Fprintln(out, "")
+
+	failed, warned := 0, 0
+	for _, check := range checks {
+		icon := "✅"
+		switch check.Status {
+		case checkWarn:
+			icon = "⚠️ "
+			warned++
+		case checkFail:
+			icon = "❌"
+			failed++
+		}
+		fmt.Fprintf(out, "%s %s: %s\n", icon, check.Name, check.Detail)
+		if check.Fix != "" {
+			fmt.Fprintf(out, "   Fix: %s\n", check.Fix)
+		}
+	}
+
+	fmt.Fprintf(out, "\n%d passed, %d warning(s), %d failed\n", len(checks)-failed-warned, warned, failed)
+	return failed
+}
@@ -0,0 +1,84 @@
+package core
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/SamyRai/juleson/internal/config"
+)
+
+func TestDoctorCommandOffline(t *testing.T) {
+	oldLookPath := doctorLookPath
+	t.Cleanup(func() { doctorLookPath = oldLookPath })
+	doctorLookPath = func(name string) (string, error) {
+		if name == "golangci-lint" {
+			return "", errors.New("not found")
+		}
+		return "/usr/bin/" + name, nil
+	}
+
+	cfg := &config.Config{
+		Jules:  config.JulesConfig{APIKey: "secret-jules-key", BaseURL: "https://jules.googleapis.com/v1alpha"},
+		GitHub: config.GitHubConfig{Token: "secret-github-token"},
+	}
+
+	cmd := NewDoctorCommand(cfg)
+	var out bytes.Buffer
+	cmd.SetOut(&out)
+	cmd.SetArgs([]string{"--offline"})
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("doctor --offline: %v\n%s", err, out.String())
+	}
+
+	output := out.String()
+	for _, want := range []string{
+		"✅ Config values: valid",
+		"✅ Jules API key: configured (not verified, --offline)",
+		"✅ git: /usr/bin/git",
+		"⚠️  golangci-lint: not found in PATH",
+		"Fix: Install from https://golangci-lint.run",
+	} {
+		if !strings.Contains(output, want) {
+			t.Errorf("output missing %q:\n%s", want, output)
+		}
+	}
+	for _, secret := range []string{"secret-jules-key", "secret-github-token"} {
+		if strings.Contains(output, secret) {
+			t.Errorf("output leaked %q", secret)
+		}
+	}
+}
+
+func TestDoctorCommandFailsOnInvalidConfigAndMissingGit(t *testing.T) {
+	oldLookPath := doctorLookPath
+	t.Cleanup(func() { doctorLookPath = oldLookPath })
+	doctorLookPath = func(name string) (string, error) {
+		return "", errors.New("not found")
+	}
+
+	cfg := &config.Config{
+		Jules: config.JulesConfig{BaseURL: "not-a-url"},
+	}
+
+	cmd := NewDoctorCommand(cfg)
+	var out bytes.Buffer
+	cmd.SetOut(&out)
+	cmd.SetArgs([]string{"--offline"})
+	err := cmd.Execute()
+	if err == nil {
+		t.Fatal("expected doctor to fail")
+	}
+
+	output := out.String()
+	for _, want := range []string{
+		"❌ Config values: invalid jules.base_url",
+		"❌ Jules API key: not configured",
+		"❌ git: not found in PATH",
+	} {
+		if !strings.Contains(output, want) {
+			t.Errorf("output missing %q:\n%s", want, output)
+		}
+	}
+}
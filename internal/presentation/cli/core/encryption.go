@@ -0,0 +1,113 @@
+package core
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/SamyRai/juleson/internal/config"
+	"github.com/SamyRai/juleson/internal/events"
+	"github.com/SamyRai/juleson/internal/jules/workspace"
+	"github.com/SamyRai/juleson/internal/secrets"
+	"github.com/spf13/cobra"
+)
+
+var (
+	atRestMu     sync.Mutex
+	atRestCipher *secrets.Cipher
+)
+
+// AtRestCipher returns the cipher of encryption.enabled, or nil when it is
+// off. The key is loaded once per process.
+func AtRestCipher(cfg *config.Config) (*secrets.Cipher, error) {
+	if cfg == nil || !cfg.Encryption.Enabled {
+		return nil, nil
+	}
+	return loadAtRestCipher()
+}
+
+func loadAtRestCipher() (*secrets.Cipher, error) {
+	atRestMu.Lock()
+	defer atRestMu.Unlock()
+	if atRestCipher != nil {
+		return atRestCipher, nil
+	}
+	store, err := secrets.Default()
+	if err != nil {
+		return nil, err
+	}
+	key, err := secrets.EncryptionKey(store)
+	if err != nil {
+		return nil, err
+	}
+	cipher, err := secrets.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	atRestCipher = cipher
+	return cipher, nil
+}
+
+// eventCipher returns the cipher of cfg for event journals, as a nil
+// interface when encryption is off.
+func eventCipher(cfg *config.Config) (secrets.Sealer, error) {
+	cipher, err := AtRestCipher(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("encryption: %w", err)
+	}
+	if cipher == nil {
+		return nil, nil
+	}
+	return cipher, nil
+}
+
+// NewDecryptCommand creates the decrypt command.
+func NewDecryptCommand() *cobra.Command {
+	var output string
+
+	cmd := &cobra.Command{
+		Use:   "decrypt FILE",
+		Short: "Decrypt a file encrypted at rest",
+		Long: `Decrypt a downloaded artifact (FILE.enc), or an audit log, run history, or
+projection snapshot written with encryption.enabled, to stdout or --output.
+The key is JULESON_ENCRYPTION_KEY or the one kept in the keychain, as when the
+file was written.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			input, err := os.Open(args[0])
+			if err != nil {
+				return fmt.Errorf("failed to read %s: %w", args[0], err)
+			}
+			defer input.Close()
+			cipher, err := loadAtRestCipher()
+			if err != nil {
+				return fmt.Errorf("encryption: %w", err)
+			}
+
+			w := cmd.OutOrStdout()
+			if output != "" {
+				file, err := os.OpenFile(output, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0o600)
+				if err != nil {
+					return fmt.Errorf("failed to create %s: %w", output, err)
+				}
+				defer file.Close()
+				w = file
+			}
+
+			if strings.HasSuffix(args[0], workspace.EncryptedSuffix) {
+				if err := secrets.OpenStream(cipher, input, w); err != nil {
+					return fmt.Errorf("%s: %w", args[0], err)
+				}
+				return nil
+			}
+			if err := events.DecryptRecords(cipher, input, w); err != nil {
+				return fmt.Errorf("%s: %w", args[0], err)
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVarP(&output, "output", "o", "", "Write the plaintext to this file instead of stdout")
+	return cmd
+}
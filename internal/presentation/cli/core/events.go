@@ -0,0 +1,260 @@
+package core
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/SamyRai/juleson/internal/config"
+	"github.com/SamyRai/juleson/internal/events"
+	"github.com/SamyRai/juleson/internal/logger"
+	"github.com/SamyRai/juleson/internal/sinks"
+	"github.com/spf13/cobra"
+)
+
+// NewEventsCommand creates the events command.
+func NewEventsCommand(cfg *config.Config) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "events",
+		Short: "Inspect event journals",
+		Long: `Event journals are append-only JSONL files of events, such as the audit log
+and the files written by 'template run --split --events'. Events can also be
+exported to Kafka, webhooks, and files; see the events section of the
+configuration.`,
+	}
+
+	cmd.AddCommand(newEventsQueryCommand(cfg))
+	cmd.AddCommand(newEventsSinksCommand(cfg))
+
+	return cmd
+}
+
+func newEventsQueryCommand(cfg *config.Config) *cobra.Command {
+	var (
+		file     string
+		query    events.Query
+		since    string
+		until    string
+		jsonMode bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "query",
+		Short: "Query events by type, topic, session, and time",
+		Long: `Query events in a journal, oldest first. Without --file the audit log is
+queried. Results are paged with --limit and --offset.`,
+		Example: `  juleson events query --file run-events.jsonl --type 'task.*'
+  juleson events query --file run-events.jsonl --session 123 --json
+  juleson events query --since 24h --type audit.recorded --limit 20 --offset 20`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			now := time.Now()
+			var err error
+			if query.Since, err = parseEventsTime("--since", since, now); err != nil {
+				return err
+			}
+			if query.Until, err = parseEventsTime("--until", until, now); err != nil {
+				return err
+			}
+			if file == "" {
+				if file, err = AuditLogPath(cfg); err != nil {
+					return err
+				}
+			}
+
+			store, err := OpenEventJournal(cfg, file)
+			if err != nil {
+				return err
+			}
+			result := store.Query(query)
+			if jsonMode {
+				return writeEventsJSONL(cmd.OutOrStdout(), result.Events)
+			}
+			printEventsResult(cmd.OutOrStdout(), query, result)
+			return nil
+		},
+	}
+	cmd.Flags().StringVar(&file, "file", "", "Event journal to query (default: the audit log)")
+	cmd.Flags().StringArrayVar(&query.Types, "type", nil, "Only events whose type matches this pattern, e.g. session.* (repeatable)")
+	cmd.Flags().StringVar(&query.Topic, "topic", "", "Only events on this topic, e.g. session or task")
+	cmd.Flags().StringVar(&query.SessionID, "session", "", "Only events about this session")
+	cmd.Flags().StringVar(&since, "since", "", "Only events at or after this time: a duration such as 24h or 7d, a date, or RFC3339")
+	cmd.Flags().StringVar(&until, "until", "", "Only events before this time, in the same forms as --since")
+	cmd.Flags().IntVar(&query.Limit, "limit", 50, "Maximum number of events to show")
+	cmd.Flags().IntVar(&query.Offset, "offset", 0, "Skip this many matching events")
+	cmd.Flags().BoolVar(&jsonMode, "json", false, "Print events as JSON lines")
+
+	return cmd
+}
+
+// NewEventExporter creates an exporter to cfg's event sinks. Close it to
+// deliver the events still queued.
+func NewEventExporter(cfg *config.Config) *sinks.Exporter {
+	log := logger.For(logger.SubsystemEvents)
+	exporter, err := sinks.NewExporter(cfg.Events.SinkOptions(), log)
+	if err != nil {
+		log.Warn("events will not be exported", "error", err)
+		return nil
+	}
+	return exporter
+}
+
+// ExportEvent delivers event to the event sinks that select it. Failures
+// are logged and never fail the operation that produced the event.
+func ExportEvent(cfg *config.Config, event events.Event) {
+	if cfg == nil || len(cfg.Events.Sinks) == 0 {
+		return
+	}
+	exporter := NewEventExporter(cfg)
+	exporter.Export(event)
+	if err := exporter.Close(context.Background()); err != nil {
+		logger.For(logger.SubsystemEvents).Warn("failed to export event", "type", event.Type, "error", err)
+	}
+}
+
+func newEventsSinksCommand(cfg *config.Config) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "sinks",
+		Short: "List and test event sinks",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			options := cfg.Events.SinkOptions()
+			if len(options) == 0 {
+				fmt.Fprintln(cmd.OutOrStdout(), "No event sinks are configured")
+				return nil
+			}
+			w := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 0, 2, ' ', 0)
+			fmt.Fprintln(w, "NAME\tTYPE\tEVENTS")
+			for _, sink := range options {
+				selection := "all"
+				if len(sink.Topics) > 0 || len(sink.Types) > 0 {
+					selection = strings.Join(slices.Concat(sink.Topics, sink.Types), ", ")
+				}
+				fmt.Fprintf(w, "%s\t%s\t%s\n", sink.Name, sink.Type, selection)
+			}
+			return w.Flush()
+		},
+	}
+	cmd.AddCommand(newEventsSinksTestCommand(cfg))
+	return cmd
+}
+
+func newEventsSinksTestCommand(cfg *config.Config) *cobra.Command {
+	var names []string
+
+	cmd := &cobra.Command{
+		Use:   "test",
+		Short: "Send a test event to event sinks",
+		Long:  "Send a system.test event to every configured sink, or to those given with --sink, regardless of their event selection.",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			var selected []sinks.Config
+			for _, sink := range cfg.Events.SinkOptions() {
+				if len(names) == 0 || slices.Contains(names, sink.Name) {
+					sink.Topics, sink.Types = nil, nil
+					selected = append(selected, sink)
+				}
+			}
+			if len(selected) == 0 {
+				return fmt.Errorf("no event sinks are configured; set events.sinks")
+			}
+			for _, name := range names {
+				if !slices.ContainsFunc(selected, func(sink sinks.Config) bool { return sink.Name == name }) {
+					return fmt.Errorf("no event sink named %s", name)
+				}
+			}
+			exporter, err := sinks.NewExporter(selected, logger.For(logger.SubsystemEvents))
+			if err != nil {
+				return err
+			}
+			exporter.Export(events.NewEvent("system.test", "juleson", map[string]string{"message": "Events from Juleson reach this sink."}).WithTopic(events.TopicSystem))
+			if err := exporter.Close(cmd.Context()); err != nil {
+				return fmt.Errorf("failed to send test event: %w", err)
+			}
+			sent := make([]string, 0, len(selected))
+			for _, sink := range selected {
+				sent = append(sent, sink.Name)
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "✅ Sent a test event to %s\n", strings.Join(sent, ", "))
+			return nil
+		},
+	}
+	cmd.Flags().StringSliceVar(&names, "sink", nil, "Sink to test, by name (repeatable)")
+	return cmd
+}
+
+// OpenEventJournal loads an existing event journal for reading, decrypting
+// it with the key of encryption.enabled.
+func OpenEventJournal(cfg *config.Config, path string) (*events.EventStore, error) {
+	if _, err := os.Stat(path); errors.Is(err, fs.ErrNotExist) {
+		return nil, fmt.Errorf("no event journal at %s", path)
+	} else if err != nil {
+		return nil, fmt.Errorf("failed to open event journal: %w", err)
+	}
+	cipher, err := eventCipher(cfg)
+	if err != nil {
+		return nil, err
+	}
+	store, err := events.NewEventStore(&events.EventStoreConfig{
+		StorageDir:  filepath.Dir(path),
+		JournalPath: path,
+		Cipher:      cipher,
+	}, logger.For(logger.SubsystemEvents))
+	if errors.Is(err, events.ErrEncrypted) {
+		return nil, fmt.Errorf("%s: %w; set encryption.enabled", path, err)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to open event journal: %w", err)
+	}
+	return store, nil
+}
+
+// parseEventsTime parses --since and --until like audit --since.
+func parseEventsTime(flag, value string, now time.Time) (time.Time, error) {
+	t, err := parseAuditSince(value, now)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid %s %q: use a duration such as 24h or 7d, a date, or RFC3339", flag, value)
+	}
+	return t, nil
+}
+
+func printEventsResult(w io.Writer, query events.Query, result events.QueryResult) {
+	if result.Total == 0 {
+		fmt.Fprintln(w, "No events found.")
+		return
+	}
+	for _, event := range result.Events {
+		session := events.SessionKey(event.Event)
+		if session == "" {
+			session = "-"
+		}
+		fmt.Fprintf(w, "%s  %-22s  %-13s  %-20s  %s\n",
+			event.Timestamp.Local().Format("2006-01-02 15:04:05"), event.Type, event.Topic, session, event.ID)
+	}
+	if len(result.Events) < result.Total {
+		fmt.Fprintf(w, "\nShowing %d-%d of %d events.", query.Offset+1, query.Offset+len(result.Events), result.Total)
+		if result.NextOffset > 0 {
+			fmt.Fprintf(w, " Next page: --offset %d", result.NextOffset)
+		}
+		fmt.Fprintln(w)
+	}
+}
+
+func writeEventsJSONL(w io.Writer, stored []events.StoredEvent) error {
+	encoder := json.NewEncoder(w)
+	for _, event := range stored {
+		if err := encoder.Encode(event.Event); err != nil {
+			return fmt.Errorf("failed to write event: %w", err)
+		}
+	}
+	return nil
+}
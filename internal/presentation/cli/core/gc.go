@@ -0,0 +1,329 @@
+package core
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/SamyRai/juleson/internal/config"
+	"github.com/SamyRai/juleson/internal/events"
+	"github.com/SamyRai/juleson/internal/jules/workspace"
+	"github.com/SamyRai/juleson/internal/logger"
+	"github.com/SamyRai/juleson/internal/secrets"
+	"github.com/spf13/cobra"
+)
+
+// GCResult is what retention removed, or would remove, from one target.
+type GCResult struct {
+	Target    string `json:"target"`
+	Path      string `json:"path"`
+	Removed   int    `json:"removed"`
+	Reclaimed int64  `json:"reclaimed_bytes"`
+}
+
+// CollectGarbage applies the retention of cfg, to its audit log, run
+// history, and artifact directories, and to the audit log and run history
+// of each MCP tenant. With dryRun nothing is removed and the results report
+// what would be. Targets that fail are reported in the error and do not stop
+// the others.
+func CollectGarbage(cfg *config.Config, now time.Time, dryRun bool) ([]GCResult, error) {
+	var (
+		results []GCResult
+		errs    []error
+	)
+	scopes := []struct {
+		label string
+		cfg   *config.Config
+	}{{"", cfg}}
+	for _, tenant := range cfg.MCP.Tenants {
+		scoped, err := cfg.TenantStorage(tenant)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("tenant %s: %w", tenant.Name, err))
+			continue
+		}
+		scopes = append(scopes, struct {
+			label string
+			cfg   *config.Config
+		}{" (" + tenant.Name + ")", scoped})
+	}
+
+	for _, scope := range scopes {
+		cipher, err := eventCipher(scope.cfg)
+		if err != nil {
+			return nil, err
+		}
+		for _, journal := range []struct {
+			target string
+			path   func(*config.Config) (string, error)
+			rule   config.RetentionRuleConfig
+		}{
+			{"audit log", AuditLogPath, cfg.Retention.Audit},
+			{"run history", HistoryPath, cfg.Retention.History},
+		} {
+			if !journal.rule.Enabled() {
+				continue
+			}
+			path, err := journal.path(scope.cfg)
+			if err != nil {
+				errs = append(errs, err)
+				continue
+			}
+			result, err := compactJournal(journal.target+scope.label, path, cipher, journal.rule, now, dryRun)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("%s: %w", path, err))
+			}
+			results = append(results, result)
+		}
+	}
+
+	if cfg.Retention.Artifacts.Enabled() {
+		for _, dir := range cfg.Retention.Artifacts.Dirs {
+			dir = os.ExpandEnv(dir)
+			result, err := pruneArtifacts(dir, cfg.Retention.Artifacts.RetentionRuleConfig, now, dryRun)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("%s: %w", dir, err))
+			}
+			results = append(results, result)
+		}
+	}
+	return results, errors.Join(errs...)
+}
+
+// compactJournal compacts the journal at path by rule. The projection
+// snapshots beside a compacted journal still hold the removed events, so
+// they are removed too and rebuilt on next use.
+func compactJournal(target, path string, cipher secrets.Sealer, rule config.RetentionRuleConfig, now time.Time, dryRun bool) (GCResult, error) {
+	result := GCResult{Target: target, Path: path}
+	var cutoff time.Time
+	if rule.MaxAge > 0 {
+		cutoff = now.Add(-rule.MaxAge)
+	}
+	stats, err := events.CompactJournal(path, cipher, cutoff, int64(rule.MaxSizeMB)<<20, dryRun)
+	if err != nil {
+		return result, err
+	}
+	result.Removed = stats.Removed
+	result.Reclaimed = stats.ReclaimedBytes
+	if stats.Removed == 0 {
+		return result, nil
+	}
+
+	snapshots := path + ".projections"
+	size, err := dirSize(snapshots)
+	if err != nil {
+		return result, err
+	}
+	result.Reclaimed += size
+	if !dryRun {
+		if err := os.RemoveAll(snapshots); err != nil {
+			return result, fmt.Errorf("failed to remove projection snapshots: %w", err)
+		}
+		forgetJournal(path)
+	}
+	return result, nil
+}
+
+// forgetJournal drops the cached audit and run history stores of the
+// journal at path, so their next use reloads it as compacted.
+func forgetJournal(path string) {
+	auditMu.Lock()
+	delete(auditStores, path)
+	auditMu.Unlock()
+	historyMu.Lock()
+	delete(historyStores, path)
+	historyMu.Unlock()
+}
+
+// pruneArtifacts removes the files under dir modified before rule's
+// MaxAge, then the oldest until at most MaxSizeMB remain. Directories and
+// downloads still in progress are kept.
+func pruneArtifacts(dir string, rule config.RetentionRuleConfig, now time.Time, dryRun bool) (GCResult, error) {
+	result := GCResult{Target: "artifacts", Path: dir}
+	type artifactFile struct {
+		path     string
+		size     int64
+		modified time.Time
+	}
+	var (
+		files []artifactFile
+		total int64
+	)
+	err := filepath.WalkDir(dir, func(path string, entry fs.DirEntry, err error) error {
+		if errors.Is(err, fs.ErrNotExist) && path == dir {
+			return fs.SkipAll
+		}
+		if err != nil || !entry.Type().IsRegular() || strings.HasSuffix(path, workspace.PartialSuffix) {
+			return err
+		}
+		info, err := entry.Info()
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		files = append(files, artifactFile{path: path, size: info.Size(), modified: info.ModTime()})
+		total += info.Size()
+		return nil
+	})
+	if err != nil {
+		return result, fmt.Errorf("failed to list artifacts: %w", err)
+	}
+	sort.Slice(files, func(i, j int) bool { return files[i].modified.Before(files[j].modified) })
+
+	maxBytes := int64(rule.MaxSizeMB) << 20
+	for _, file := range files {
+		expired := rule.MaxAge > 0 && now.Sub(file.modified) > rule.MaxAge
+		oversized := maxBytes > 0 && total > maxBytes
+		if !expired && !oversized {
+			break
+		}
+		if !dryRun {
+			// A file removed meanwhile, such as by another gc, is gone all
+			// the same.
+			if err := os.Remove(file.path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+				return result, fmt.Errorf("failed to remove artifact: %w", err)
+			}
+		}
+		result.Removed++
+		result.Reclaimed += file.size
+		total -= file.size
+	}
+	return result, nil
+}
+
+// dirSize returns the size of the files under dir, or zero when it does
+// not exist.
+func dirSize(dir string) (int64, error) {
+	var size int64
+	err := filepath.WalkDir(dir, func(path string, entry fs.DirEntry, err error) error {
+		if errors.Is(err, fs.ErrNotExist) && path == dir {
+			return fs.SkipAll
+		}
+		if err != nil || entry.IsDir() {
+			return err
+		}
+		info, err := entry.Info()
+		if err != nil {
+			return err
+		}
+		size += info.Size()
+		return nil
+	})
+	return size, err
+}
+
+// StartRetention applies cfg's retention every retention.interval until
+// ctx is done.
+func StartRetention(ctx context.Context, cfg *config.Config) {
+	if cfg.Retention.Interval <= 0 {
+		return
+	}
+	log := logger.For(logger.SubsystemEvents)
+	go func() {
+		ticker := time.NewTicker(cfg.Retention.Interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case now := <-ticker.C:
+				results, err := CollectGarbage(cfg, now, false)
+				for _, result := range results {
+					if result.Removed > 0 {
+						log.Info("retention compacted", "target", result.Target, "path", result.Path, "removed", result.Removed, "reclaimed_bytes", result.Reclaimed)
+					}
+				}
+				if err != nil {
+					log.Warn("retention failed", "error", err)
+				}
+			}
+		}
+	}()
+}
+
+// NewGCCommand creates the gc command.
+func NewGCCommand(cfg *config.Config) *cobra.Command {
+	var (
+		dryRun     bool
+		jsonOutput bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "gc",
+		Short: "Apply retention to the audit log, run history, and artifacts",
+		Long: `Remove audit log and run history entries, and downloaded artifacts, beyond the
+age and size limits of the retention section of the configuration, along with
+the projection snapshots of compacted journals. The journals of MCP tenants
+are compacted too. With --dry-run, report the space that would be reclaimed
+without removing anything.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			results, err := CollectGarbage(cfg, time.Now(), dryRun)
+			if jsonOutput {
+				if encodeErr := writeGCJSON(cmd.OutOrStdout(), results, dryRun); encodeErr != nil {
+					return encodeErr
+				}
+				return err
+			}
+			printGCResults(cmd.OutOrStdout(), results, dryRun)
+			return err
+		},
+	}
+
+	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "Report reclaimable space without removing anything")
+	cmd.Flags().BoolVar(&jsonOutput, "json", false, "Output as JSON")
+	return cmd
+}
+
+func writeGCJSON(w io.Writer, results []GCResult, dryRun bool) error {
+	if results == nil {
+		results = []GCResult{}
+	}
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(map[string]interface{}{"dry_run": dryRun, "results": results})
+}
+
+func printGCResults(w io.Writer, results []GCResult, dryRun bool) {
+	if len(results) == 0 {
+		fmt.Fprintln(w, "No retention is configured; see the retention section of the configuration.")
+		return
+	}
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "TARGET\tPATH\tREMOVED\tRECLAIMED")
+	var total int64
+	for _, result := range results {
+		fmt.Fprintf(tw, "%s\t%s\t%d\t%s\n", result.Target, result.Path, result.Removed, formatBytes(result.Reclaimed))
+		total += result.Reclaimed
+	}
+	_ = tw.Flush()
+	if dryRun {
+		fmt.Fprintf(w, "\n%s reclaimable; run without --dry-run to remove it\n", formatBytes(total))
+		return
+	}
+	fmt.Fprintf(w, "\n%s reclaimed\n", formatBytes(total))
+}
+
+// formatBytes formats n bytes with a binary unit.
+func formatBytes(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	div, exp := int64(unit), 0
+	for m := n / unit; m >= unit; m /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(n)/float64(div), "KMGTPE"[exp])
+}
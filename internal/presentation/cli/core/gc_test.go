@@ -0,0 +1,105 @@
+package core
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/SamyRai/juleson/internal/config"
+	"github.com/SamyRai/juleson/internal/events"
+)
+
+func TestCollectGarbage(t *testing.T) {
+	dir := t.TempDir()
+	auditPath := filepath.Join(dir, "audit.jsonl")
+	artifacts := filepath.Join(dir, "artifacts")
+	cfg := &config.Config{
+		Audit: config.AuditConfig{Enabled: true, Path: auditPath},
+		Retention: config.RetentionConfig{
+			Audit: config.RetentionRuleConfig{MaxAge: 30 * 24 * time.Hour},
+			Artifacts: config.ArtifactRetentionConfig{
+				RetentionRuleConfig: config.RetentionRuleConfig{MaxAge: 7 * 24 * time.Hour},
+				Dirs:                []string{artifacts},
+			},
+		},
+	}
+
+	now := time.Now()
+	store, err := events.OpenAuditLog(auditPath, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, age := range []time.Duration{90, 45, 1} {
+		event := events.NewAuditEvent(AuditSourceCLI, events.AuditData{Action: AuditSessionCreate})
+		event.Timestamp = now.Add(-age * 24 * time.Hour)
+		if err := store.Store(event); err != nil {
+			t.Fatal(err)
+		}
+	}
+	snapshots := auditPath + ".projections"
+	if err := os.MkdirAll(snapshots, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(snapshots, "timelines.snapshot.json"), []byte("{}"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(artifacts, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	old, recent := filepath.Join(artifacts, "patch_0.diff"), filepath.Join(artifacts, "patch_1.diff")
+	partial := filepath.Join(artifacts, "patch_2.diff.part")
+	for _, file := range []string{old, recent, partial} {
+		if err := os.WriteFile(file, []byte("diff"), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, file := range []string{old, partial} {
+		if err := os.Chtimes(file, now.Add(-10*24*time.Hour), now.Add(-10*24*time.Hour)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	cmd := NewGCCommand(cfg)
+	var out bytes.Buffer
+	cmd.SetOut(&out)
+	cmd.SetArgs([]string{"--dry-run"})
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("gc --dry-run: %v", err)
+	}
+	if !strings.Contains(out.String(), "reclaimable") {
+		t.Errorf("dry run output lacks reclaimable space:\n%s", out.String())
+	}
+	if _, err := os.Stat(old); err != nil {
+		t.Errorf("dry run removed an artifact: %v", err)
+	}
+
+	results, err := CollectGarbage(cfg, now, false)
+	if err != nil {
+		t.Fatalf("CollectGarbage() error = %v", err)
+	}
+	if len(results) != 2 || results[0].Removed != 2 || results[1].Removed != 1 {
+		t.Fatalf("CollectGarbage() = %+v, want 2 audit entries and 1 artifact removed", results)
+	}
+	if _, err := os.Stat(snapshots); !os.IsNotExist(err) {
+		t.Errorf("projection snapshots of the compacted audit log were kept: %v", err)
+	}
+	if _, err := os.Stat(old); !os.IsNotExist(err) {
+		t.Errorf("expired artifact was kept: %v", err)
+	}
+	if _, err := os.Stat(recent); err != nil {
+		t.Errorf("recent artifact was removed: %v", err)
+	}
+	if _, err := os.Stat(partial); err != nil {
+		t.Errorf("download in progress was removed: %v", err)
+	}
+	reopened, err := events.OpenAuditLog(auditPath, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if count := reopened.Count(); count != 1 {
+		t.Errorf("audit log holds %d entries after gc, want 1", count)
+	}
+}
@@ -0,0 +1,145 @@
+package core
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/SamyRai/go-jules"
+	"github.com/SamyRai/juleson/internal/config"
+	"github.com/SamyRai/juleson/internal/guard"
+)
+
+// maxPlanPages bounds the activity pages read to find the plan being
+// approved.
+const maxPlanPages = 20
+
+// NewGuard creates the content guard configured by cfg.
+func NewGuard(cfg *config.Config) *guard.Guard {
+	return guard.New(cfg.Guard.GuardOptions())
+}
+
+// guardTransport runs every prompt sent to Jules, for a new session or as a
+// message, through the content guard, and the plan of a session before it
+// is approved. Checking at the transport covers the CLI, MCP tools, and
+// automations alike.
+type guardTransport struct {
+	guard  *guard.Guard
+	next   http.RoundTripper
+	logger *slog.Logger
+}
+
+func (t *guardTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Method != http.MethodPost {
+		return t.next.RoundTrip(req)
+	}
+	path := req.URL.Path
+	var (
+		findings []guard.Finding
+		err      error
+	)
+	switch {
+	case strings.HasSuffix(path, "/sessions"), strings.HasSuffix(path, ":sendMessage"):
+		subject := "the prompt of a new session"
+		if session, ok := strings.CutSuffix(path, ":sendMessage"); ok {
+			subject = "the message to session " + session[strings.LastIndex(session, "/")+1:]
+		}
+		var prompt string
+		if req, prompt, err = readPrompt(req); err != nil {
+			return nil, err
+		}
+		findings, err = t.guard.Check(guard.DirectionPrompt, subject, prompt)
+	case strings.HasSuffix(path, ":approvePlan"):
+		session := strings.TrimSuffix(path, ":approvePlan")
+		var plan string
+		if plan, err = t.latestPlan(req, session); err != nil {
+			return nil, fmt.Errorf("failed to read the plan to approve: %w", err)
+		}
+		findings, err = t.guard.Check(guard.DirectionOutput, "the plan of session "+session[strings.LastIndex(session, "/")+1:], plan)
+	}
+	if err != nil {
+		return nil, err
+	}
+	for _, finding := range findings {
+		t.logger.Warn("content guard flagged a request", "path", path, "finding", finding.String())
+	}
+	return t.next.RoundTrip(req)
+}
+
+// readPrompt returns a copy of req, whose body can be read again, and the
+// prompt in its body.
+func readPrompt(req *http.Request) (*http.Request, string, error) {
+	if req.Body == nil {
+		return req, "", nil
+	}
+	body, err := io.ReadAll(req.Body)
+	req.Body.Close()
+	if err != nil {
+		return nil, "", err
+	}
+	req = req.Clone(req.Context())
+	req.Body = io.NopCloser(bytes.NewReader(body))
+	req.GetBody = func() (io.ReadCloser, error) { return io.NopCloser(bytes.NewReader(body)), nil }
+	var payload struct {
+		Prompt string `json:"prompt"`
+	}
+	_ = json.Unmarshal(body, &payload)
+	return req, payload.Prompt, nil
+}
+
+// latestPlan returns the steps of the last plan Jules generated for the
+// session at path, one per line.
+func (t *guardTransport) latestPlan(req *http.Request, path string) (string, error) {
+	var plan *jules.Plan
+	pageToken := ""
+	for range maxPlanPages {
+		query := url.Values{"pageSize": {"100"}}
+		if pageToken != "" {
+			query.Set("pageToken", pageToken)
+		}
+		listURL := *req.URL
+		listURL.Path = path + "/activities"
+		listURL.RawPath = ""
+		listURL.RawQuery = query.Encode()
+		list, err := http.NewRequestWithContext(req.Context(), http.MethodGet, listURL.String(), nil)
+		if err != nil {
+			return "", err
+		}
+		list.Header = req.Header.Clone()
+		list.Header.Del("Content-Type")
+		resp, err := t.next.RoundTrip(list)
+		if err != nil {
+			return "", err
+		}
+		var page jules.ActivitiesResponse
+		err = json.NewDecoder(resp.Body).Decode(&page)
+		resp.Body.Close()
+		if resp.StatusCode/100 != 2 {
+			return "", fmt.Errorf("listing activities returned %s", resp.Status)
+		}
+		if err != nil {
+			return "", err
+		}
+		for _, activity := range page.Activities {
+			if activity.PlanGenerated != nil {
+				plan = &activity.PlanGenerated.Plan
+			}
+		}
+		if pageToken = page.NextPageToken; pageToken == "" {
+			break
+		}
+	}
+	if plan == nil {
+		return "", nil
+	}
+	var text strings.Builder
+	for _, step := range plan.Steps {
+		fmt.Fprintf(&text, "%s\n%s\n", step.Title, step.Description)
+	}
+	return text.String(), nil
+}
@@ -0,0 +1,270 @@
+package core
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"text/tabwriter"
+	"time"
+
+	"github.com/SamyRai/go-jules"
+	"github.com/SamyRai/juleson/internal/config"
+	"github.com/SamyRai/juleson/internal/events"
+	"github.com/SamyRai/juleson/internal/logger"
+	"github.com/spf13/cobra"
+)
+
+var (
+	historyMu sync.Mutex
+	// historyStores are the open run journals by path, one per MCP tenant.
+	historyStores = map[string]*events.EventStore{}
+)
+
+// HistoryPath returns the run history journal path from cfg, defaulting to
+// history.jsonl in the user config directory.
+func HistoryPath(cfg *config.Config) (string, error) {
+	if cfg.History.Path != "" {
+		return cfg.History.Path, nil
+	}
+	configDir, err := os.UserConfigDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to locate user config directory: %w", err)
+	}
+	return filepath.Join(configDir, "juleson", "history.jsonl"), nil
+}
+
+// RecordRunHistory subscribes the run history journal to coordinator, so
+// every event of a run is kept for 'juleson history'. Failures to write the
+// journal are reported as warnings and never fail the run.
+func RecordRunHistory(cfg *config.Config, coordinator *events.EventCoordinator) error {
+	if !cfg.History.Enabled {
+		return nil
+	}
+	store, err := openHistoryStore(cfg)
+	if err != nil {
+		logger.For(logger.SubsystemEvents).Warn("failed to open run history", "error", err)
+		return nil
+	}
+	return coordinator.Subscribe(events.TopicAll, events.Subscriber{
+		ID:     "run-history",
+		Filter: func(event events.Event) bool { return events.RunKey(event) != "" },
+		Handler: func(ctx context.Context, event events.Event) error {
+			if err := store.Store(event); err != nil {
+				logger.For(logger.SubsystemEvents).Warn("failed to write run history", "event", event.Type, "error", err)
+			}
+			return nil
+		},
+	})
+}
+
+func openHistoryStore(cfg *config.Config) (*events.EventStore, error) {
+	path, err := HistoryPath(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	historyMu.Lock()
+	defer historyMu.Unlock()
+	if store, ok := historyStores[path]; ok {
+		return store, nil
+	}
+	cipher, err := eventCipher(cfg)
+	if err != nil {
+		return nil, err
+	}
+	store, err := events.OpenRunHistory(path, cipher, logger.For(logger.SubsystemEvents))
+	if err != nil {
+		return nil, err
+	}
+	historyStores[path] = store
+	return store, nil
+}
+
+// NewHistoryCommand creates the history command.
+func NewHistoryCommand(cfg *config.Config) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "history",
+		Short: "Inspect past template runs",
+		Long: `Every 'template run --split' is recorded in the run history: its template,
+duration and outcome, each task's result, and the Jules sessions and pull
+requests it created. Runs are rebuilt from the history journal, using a
+snapshot kept next to it so only new events are replayed.`,
+	}
+
+	cmd.AddCommand(newHistoryListCommand(cfg))
+	cmd.AddCommand(newHistoryShowCommand(cfg))
+
+	return cmd
+}
+
+func newHistoryListCommand(cfg *config.Config) *cobra.Command {
+	var (
+		workflow string
+		limit    int
+		jsonMode bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "list",
+		Short: "List runs, most recent first",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			history, err := loadRunHistory(cmd.Context(), cfg)
+			if err != nil {
+				return err
+			}
+			runs := []events.RunRecord{}
+			for _, run := range history.Sorted() {
+				if workflow != "" && run.Workflow != workflow {
+					continue
+				}
+				if limit > 0 && len(runs) == limit {
+					break
+				}
+				runs = append(runs, run)
+			}
+			if jsonMode {
+				return writeHistoryJSON(cmd.OutOrStdout(), runs)
+			}
+			if len(runs) == 0 {
+				fmt.Fprintln(cmd.OutOrStdout(), "No runs recorded")
+				return nil
+			}
+			w := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 0, 2, ' ', 0)
+			fmt.Fprintln(w, "RUN\tTEMPLATE\tSTATUS\tSTARTED\tDURATION\tTASKS\tSESSIONS\tPRS")
+			for _, run := range runs {
+				completed, _ := run.TaskCounts()
+				fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%d/%d\t%d\t%d\n", run.ID, run.Workflow, run.Status,
+					run.Started.Local().Format("2006-01-02 15:04"), formatRunDuration(run.Duration),
+					completed, run.TotalTasks, len(run.Sessions), len(run.PullRequests))
+			}
+			return w.Flush()
+		},
+	}
+	cmd.Flags().StringVar(&workflow, "template", "", "Only list runs of this template")
+	cmd.Flags().IntVar(&limit, "limit", 20, "Maximum number of runs (0 for all)")
+	cmd.Flags().BoolVar(&jsonMode, "json", false, "Print machine-readable JSON")
+	return cmd
+}
+
+func newHistoryShowCommand(cfg *config.Config) *cobra.Command {
+	var (
+		reportPath string
+		jsonMode   bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "show <run-id>",
+		Short: "Show a run's tasks, sessions, and pull requests",
+		Long: `Show a run's tasks, sessions, and pull requests. --report writes a shareable
+Markdown or HTML report instead, adding each session's latest plan, diff
+stats, and review findings from Jules and the decisions recorded in the
+audit log.`,
+		Example: `  juleson history show 20261016-091500-a1b2c3
+  juleson history show 20261016-091500-a1b2c3 --report run.html`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			run, err := LoadRunRecord(cmd.Context(), cfg, args[0])
+			if err != nil {
+				return err
+			}
+			if reportPath != "" {
+				var client *jules.Client
+				if cfg.Jules.APIKey != "" {
+					client = NewJulesClient(cfg)
+				}
+				if err := WriteRunReport(cmd.Context(), cfg, client, run, reportPath); err != nil {
+					return err
+				}
+				fmt.Fprintf(cmd.OutOrStdout(), "📝 Run report written to %s\n", reportPath)
+				return nil
+			}
+			if jsonMode {
+				return writeHistoryJSON(cmd.OutOrStdout(), run)
+			}
+			printRunRecord(cmd.OutOrStdout(), run)
+			return nil
+		},
+	}
+	cmd.Flags().StringVar(&reportPath, "report", "", "Write a run report to this file (.html for HTML, otherwise Markdown)")
+	cmd.Flags().BoolVar(&jsonMode, "json", false, "Print machine-readable JSON")
+	return cmd
+}
+
+// loadRunHistory rebuilds the run history projection of the history
+// journal. A missing journal is an empty history.
+func loadRunHistory(ctx context.Context, cfg *config.Config) (events.RunHistory, error) {
+	path, err := HistoryPath(cfg)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := os.Stat(path); errors.Is(err, fs.ErrNotExist) {
+		return events.RunHistory{}, nil
+	}
+	store, err := OpenEventJournal(cfg, path)
+	if err != nil {
+		return nil, err
+	}
+	projection := events.NewRunHistoryProjection()
+	if err := events.NewProjector(store, path+".projections", projection).Rebuild(ctx); err != nil {
+		return nil, err
+	}
+	return projection.State(), nil
+}
+
+func printRunRecord(w io.Writer, run events.RunRecord) {
+	completed, failed := run.TaskCounts()
+	fmt.Fprintf(w, "Run:       %s\n", run.ID)
+	fmt.Fprintf(w, "Template:  %s\n", run.Workflow)
+	fmt.Fprintf(w, "Status:    %s\n", run.Status)
+	fmt.Fprintf(w, "Started:   %s\n", run.Started.Local().Format("2006-01-02 15:04:05"))
+	if !run.Finished.IsZero() {
+		fmt.Fprintf(w, "Finished:  %s (%s)\n", run.Finished.Local().Format("2006-01-02 15:04:05"), formatRunDuration(run.Duration))
+	}
+	fmt.Fprintf(w, "Tasks:     %d completed, %d failed, %d total\n", completed, failed, run.TotalTasks)
+	if run.Error != "" {
+		fmt.Fprintf(w, "Error:     %s\n", run.Error)
+	}
+
+	if len(run.Tasks) > 0 {
+		fmt.Fprintln(w)
+		tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(tw, "TASK\tSTATUS\tSESSION\tDURATION\tERROR")
+		for _, task := range run.Tasks {
+			fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\n", task.Name, task.Status, task.SessionID, formatRunDuration(task.Duration), task.Error)
+		}
+		_ = tw.Flush()
+	}
+	if len(run.Sessions) > 0 {
+		fmt.Fprintln(w)
+		tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(tw, "SESSION\tTASK\tSTATE\tURL")
+		for _, session := range run.Sessions {
+			fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n", session.ID, session.Task, session.State, session.URL)
+		}
+		_ = tw.Flush()
+	}
+	if len(run.PullRequests) > 0 {
+		fmt.Fprintf(w, "\nPull requests:\n  %s\n", strings.Join(run.PullRequests, "\n  "))
+	}
+}
+
+func formatRunDuration(d time.Duration) string {
+	if d == 0 {
+		return "-"
+	}
+	return d.Round(time.Second).String()
+}
+
+func writeHistoryJSON(w io.Writer, value any) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(value)
+}
@@ -0,0 +1,285 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/SamyRai/juleson/internal/config"
+	"github.com/SamyRai/juleson/internal/hooks"
+	"github.com/SamyRai/juleson/internal/intelligence"
+	"github.com/SamyRai/juleson/pkg/git"
+	"github.com/spf13/cobra"
+)
+
+// NewHooksCommand creates the hooks command.
+func NewHooksCommand(cfg *config.Config) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "hooks",
+		Short: "Run juleson checks from git pre-commit and pre-push hooks",
+		Long: `Install git hooks that check what a commit or push adds before it leaves
+the working tree. The checks are configured per hook in the hooks section of
+the configuration:
+
+  secrets  scan the added lines for credentials (blocks)
+  smells   report complex, long, and wide functions in changed Go files
+  tests    run the tests of the packages the changes affect (blocks)
+  review   ask Gemini to review the diff, when Gemini is configured
+
+Bypass the hooks with git --no-verify, or skip checks for one command with
+JULESON_SKIP_HOOKS=all or a list such as JULESON_SKIP_HOOKS=review,tests.`,
+	}
+
+	cmd.AddCommand(newHooksInstallCommand())
+	cmd.AddCommand(newHooksUninstallCommand())
+	cmd.AddCommand(newHooksStatusCommand(cfg))
+	cmd.AddCommand(newHooksRunCommand(cfg))
+
+	return cmd
+}
+
+func newHooksInstallCommand() *cobra.Command {
+	var (
+		names []string
+		force bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "install",
+		Short: "Install the pre-commit and pre-push hooks in this repository",
+		Long: `Write pre-commit and pre-push hooks that run 'juleson hooks run'. Hooks
+not written by juleson are left alone unless --force is given, in which case
+they are kept aside and restored by 'juleson hooks uninstall'.`,
+		Example: `  juleson hooks install
+  juleson hooks install --hook pre-commit
+  juleson hooks install --force`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+			repo, err := git.Open(ctx, ".")
+			if err != nil {
+				return err
+			}
+			executable, err := os.Executable()
+			if err != nil {
+				return fmt.Errorf("failed to find the juleson executable: %w", err)
+			}
+			if resolved, err := filepath.EvalSymlinks(executable); err == nil {
+				executable = resolved
+			}
+			installed, err := hooks.Install(ctx, repo, names, executable, force)
+			for _, hook := range installed {
+				fmt.Fprintf(cmd.OutOrStdout(), "✅ Installed %s hook: %s\n", hook.Hook, hook.Path)
+				if hook.Backup != "" {
+					fmt.Fprintf(cmd.OutOrStdout(), "   The previous hook was moved to %s\n", hook.Backup)
+				}
+			}
+			return err
+		},
+	}
+
+	cmd.Flags().StringSliceVar(&names, "hook", hooks.Names, "Hooks to install: pre-commit, pre-push")
+	cmd.Flags().BoolVar(&force, "force", false, "Replace hooks not written by juleson, keeping them aside")
+
+	return cmd
+}
+
+func newHooksUninstallCommand() *cobra.Command {
+	var names []string
+
+	cmd := &cobra.Command{
+		Use:   "uninstall",
+		Short: "Remove the hooks juleson installed, restoring replaced hooks",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+			repo, err := git.Open(ctx, ".")
+			if err != nil {
+				return err
+			}
+			removed, err := hooks.Uninstall(ctx, repo, names)
+			for _, path := range removed {
+				fmt.Fprintf(cmd.OutOrStdout(), "🗑️  Removed %s\n", path)
+			}
+			if err == nil && len(removed) == 0 {
+				fmt.Fprintln(cmd.OutOrStdout(), "No juleson hooks installed.")
+			}
+			return err
+		},
+	}
+
+	cmd.Flags().StringSliceVar(&names, "hook", hooks.Names, "Hooks to remove: pre-commit, pre-push")
+
+	return cmd
+}
+
+func newHooksStatusCommand(cfg *config.Config) *cobra.Command {
+	return &cobra.Command{
+		Use:   "status",
+		Short: "Show the installed hooks and their checks",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+			repo, err := git.Open(ctx, ".")
+			if err != nil {
+				return err
+			}
+			states, err := hooks.State(ctx, repo)
+			if err != nil {
+				return err
+			}
+			for _, hook := range hooks.Names {
+				fmt.Fprintf(cmd.OutOrStdout(), "%-11s %-10s %s\n", hook, states[hook], strings.Join(hookChecks(cfg, hook), ", "))
+			}
+			return nil
+		},
+	}
+}
+
+// hookChecks returns the configured checks of hook.
+func hookChecks(cfg *config.Config, hook string) []string {
+	checks := cfg.Hooks.PreCommit
+	if hook == hooks.PrePush {
+		checks = cfg.Hooks.PrePush
+	}
+	if len(checks) == 0 {
+		checks = hooks.DefaultChecks[hook]
+	}
+	return checks
+}
+
+// HookOptions configures RunHook.
+type HookOptions struct {
+	Hook string
+	Dir  string
+	// Checks override the configured checks.
+	Checks []string
+	Skip   []string
+	// Refs are the refs being pushed, as git gives them to pre-push.
+	Refs io.Reader
+	JSON bool
+}
+
+// RunHook runs the checks of a hook on the staged changes, or on the
+// commits being pushed, and returns an error when one blocks.
+func RunHook(ctx context.Context, cfg *config.Config, out io.Writer, options HookOptions) error {
+	checks := options.Checks
+	if len(checks) == 0 {
+		checks = hookChecks(cfg, options.Hook)
+	}
+	if err := hooks.ValidateChecks(append(append([]string(nil), checks...), options.Skip...)); err != nil {
+		return err
+	}
+	repo, err := git.Open(ctx, options.Dir)
+	if err != nil {
+		return err
+	}
+	var changes *hooks.Changes
+	switch options.Hook {
+	case hooks.PreCommit:
+		changes, err = hooks.StagedChanges(ctx, repo)
+	case hooks.PrePush:
+		refs := options.Refs
+		if refs == nil {
+			refs = strings.NewReader("")
+		}
+		changes, err = hooks.PushChanges(ctx, repo, refs)
+	default:
+		return fmt.Errorf("unknown hook %q; hooks are %s", options.Hook, strings.Join(hooks.Names, ", "))
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read the changes: %w", err)
+	}
+
+	smells := intelligence.DefaultSmellOptions()
+	// Unused exports need the whole module and are often added ahead of use.
+	smells.UnusedExports = false
+	runOptions := hooks.Options{Checks: checks, Skip: options.Skip, Strict: cfg.Hooks.Strict, Smells: smells}
+	if !options.JSON {
+		runOptions.Output = out
+	}
+	for _, check := range checks {
+		if check == hooks.CheckReview {
+			runOptions.Gemini = NewGeminiClient(cfg)
+		}
+	}
+	report := hooks.Run(ctx, repo, options.Hook, changes, runOptions)
+
+	if options.JSON {
+		if err := writeJSON(out, report); err != nil {
+			return err
+		}
+	} else {
+		printHookReport(out, report)
+	}
+	if report.Failed() {
+		var failed []string
+		for _, result := range report.Results {
+			if result.Status == hooks.StatusFailed {
+				failed = append(failed, result.Check)
+			}
+		}
+		return fmt.Errorf("%s blocked by %s; fix the findings, or bypass with --no-verify", options.Hook, strings.Join(failed, ", "))
+	}
+	return nil
+}
+
+func printHookReport(out io.Writer, report *hooks.Report) {
+	fmt.Fprintf(out, "juleson %s: %d changed file(s)\n", report.Hook, report.Files)
+	icons := map[string]string{
+		hooks.StatusPassed:  "✅",
+		hooks.StatusFailed:  "❌",
+		hooks.StatusWarned:  "⚠️ ",
+		hooks.StatusSkipped: "⏭️ ",
+	}
+	for _, result := range report.Results {
+		line := fmt.Sprintf("%s %-8s %s", icons[result.Status], result.Check, result.Status)
+		if len(result.Findings) > 0 {
+			line += fmt.Sprintf(", %d finding(s)", len(result.Findings))
+		}
+		if result.Note != "" {
+			line += ": " + result.Note
+		}
+		fmt.Fprintf(out, "%s (%s)\n", line, result.Duration.Round(time.Millisecond))
+		for _, finding := range result.Findings {
+			fmt.Fprintf(out, "     %s\n", strings.ReplaceAll(finding.String(), "\n", "\n     "))
+		}
+	}
+}
+
+func newHooksRunCommand(cfg *config.Config) *cobra.Command {
+	options := HookOptions{Dir: "."}
+
+	cmd := &cobra.Command{
+		Use:   "run <pre-commit|pre-push>",
+		Short: "Run a hook's checks, as the installed hooks do",
+		Long: `Run the checks of a hook: on the staged changes for pre-commit, and on the
+commits being pushed, read from standard input as git passes them, for
+pre-push. Exits non-zero when a blocking check finds a problem.`,
+		Example: `  juleson hooks run pre-commit
+  juleson hooks run pre-commit --check review
+  juleson hooks run pre-commit --skip tests`,
+		Args: cobra.RangeArgs(1, 3),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			// git passes pre-push the remote name and URL, which are not
+			// needed: the refs on standard input say what is pushed.
+			options.Hook = args[0]
+			options.Skip = append(options.Skip, hooks.ParseSkip(os.Getenv(hooks.SkipEnv))...)
+			if options.Hook == hooks.PrePush {
+				options.Refs = cmd.InOrStdin()
+			}
+			cmd.SilenceUsage = true
+			return RunHook(cmd.Context(), cfg, cmd.OutOrStdout(), options)
+		},
+	}
+
+	cmd.Flags().StringSliceVar(&options.Checks, "check", nil, "Checks to run instead of the configured ones (secrets, smells, tests, review)")
+	cmd.Flags().StringSliceVar(&options.Skip, "skip", nil, "Checks to skip")
+	cmd.Flags().BoolVar(&options.JSON, "json", false, "Print the report as JSON")
+
+	return cmd
+}
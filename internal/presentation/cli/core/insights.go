@@ -0,0 +1,129 @@
+package core
+
+import (
+	"fmt"
+	"io"
+	"text/tabwriter"
+	"time"
+
+	"github.com/SamyRai/juleson/internal/config"
+	"github.com/SamyRai/juleson/internal/events"
+	"github.com/SamyRai/juleson/internal/insights"
+	"github.com/spf13/cobra"
+)
+
+// NewInsightsCommand creates the insights command.
+func NewInsightsCommand(cfg *config.Config) *cobra.Command {
+	var (
+		since    string
+		template string
+		jsonMode bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "insights",
+		Short: "Show which templates, tasks, and decisions go with successful runs",
+		Long: `Analyze the run history of 'template run --split': the success rate and
+average duration of each template and of each task, and so of its prompt, and
+for each decision recorded in the audit log, such as plan approvals, plan
+revisions, and messages, the success rate of tasks whose session got it
+compared with tasks whose session did not.`,
+		Example: `  juleson insights
+  juleson insights --since 30d --template refactor --json`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cutoff, err := parseEventsTime("--since", since, time.Now())
+			if err != nil {
+				return err
+			}
+			history, err := loadRunHistory(cmd.Context(), cfg)
+			if err != nil {
+				return err
+			}
+			var runs []events.RunRecord
+			for _, run := range history {
+				if run.Started.Before(cutoff) || (template != "" && run.Workflow != template) {
+					continue
+				}
+				runs = append(runs, run)
+			}
+			decisions, err := sessionDecisions(cfg)
+			if err != nil {
+				return err
+			}
+
+			result := insights.Compute(runs, decisions)
+			if jsonMode {
+				return writeHistoryJSON(cmd.OutOrStdout(), result)
+			}
+			printInsights(cmd.OutOrStdout(), result)
+			return nil
+		},
+	}
+	cmd.Flags().StringVar(&since, "since", "", "Only analyze runs started after this duration (such as 30d), date, or RFC3339 time")
+	cmd.Flags().StringVar(&template, "template", "", "Only analyze runs of this template")
+	cmd.Flags().BoolVar(&jsonMode, "json", false, "Print machine-readable JSON")
+	return cmd
+}
+
+// sessionDecisions maps session IDs to the audited actions taken on them,
+// other than their creation.
+func sessionDecisions(cfg *config.Config) (map[string][]string, error) {
+	decisions := map[string][]string{}
+	if !cfg.Audit.Enabled {
+		return decisions, nil
+	}
+	entries, err := loadAuditEntries(cfg, "", "session")
+	if err != nil {
+		return nil, err
+	}
+	for _, entry := range entries {
+		if entry.Action == AuditSessionCreate || entry.Target == "" {
+			continue
+		}
+		decisions[entry.Target] = append(decisions[entry.Target], entry.Action)
+	}
+	return decisions, nil
+}
+
+func printInsights(w io.Writer, result insights.Insights) {
+	if result.Runs == 0 {
+		fmt.Fprintln(w, "No finished runs recorded")
+		return
+	}
+	fmt.Fprintf(w, "Analyzed %d run(s)\n\n", result.Runs)
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "TEMPLATE\tRUNS\tSUCCESS\tAVG DURATION")
+	for _, template := range result.Templates {
+		fmt.Fprintf(tw, "%s\t%d\t%s\t%s\n", template.Template, template.Attempts, formatRate(template.Outcome), formatRunDuration(template.AverageDuration))
+	}
+	_ = tw.Flush()
+
+	if len(result.Tasks) > 0 {
+		fmt.Fprintln(w)
+		tw = tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(tw, "TEMPLATE\tTASK\tATTEMPTS\tSUCCESS\tAVG DURATION\tLAST ERROR")
+		for _, task := range result.Tasks {
+			fmt.Fprintf(tw, "%s\t%s\t%d\t%s\t%s\t%s\n", task.Template, task.Task, task.Attempts, formatRate(task.Outcome), formatRunDuration(task.AverageDuration), task.LastError)
+		}
+		_ = tw.Flush()
+	}
+
+	if len(result.Decisions) > 0 {
+		fmt.Fprintln(w)
+		tw = tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(tw, "DECISION\tTASKS WITH\tSUCCESS WITH\tSUCCESS WITHOUT\tLIFT")
+		for _, decision := range result.Decisions {
+			fmt.Fprintf(tw, "%s\t%d\t%s\t%s\t%+.0f pts\n", decision.Action, decision.With.Attempts, formatRate(decision.With), formatRate(decision.Without), decision.Lift()*100)
+		}
+		_ = tw.Flush()
+	}
+}
+
+func formatRate(outcome insights.Outcome) string {
+	if outcome.Attempts == 0 {
+		return "-"
+	}
+	return fmt.Sprintf("%.0f%% (%d/%d)", outcome.SuccessRate()*100, outcome.Succeeded, outcome.Attempts)
+}
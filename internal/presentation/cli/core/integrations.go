@@ -0,0 +1,129 @@
+package core
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/SamyRai/juleson/internal/config"
+	"github.com/SamyRai/juleson/internal/events"
+	"github.com/SamyRai/juleson/internal/integrations"
+	"github.com/SamyRai/juleson/internal/logger"
+	"github.com/spf13/cobra"
+)
+
+// IssuesPath returns the path of the store recording which issues mirror
+// which sessions, defaulting to issues.json in the user config directory.
+func IssuesPath(cfg *config.Config) (string, error) {
+	if cfg.Integrations.IssuesPath != "" {
+		return cfg.Integrations.IssuesPath, nil
+	}
+	configDir, err := os.UserConfigDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to locate user config directory: %w", err)
+	}
+	return filepath.Join(configDir, "juleson", "issues.json"), nil
+}
+
+// NewIssueMirror creates a mirror to cfg's issue trackers.
+func NewIssueMirror(cfg *config.Config) *integrations.Mirror {
+	log := logger.For(logger.SubsystemEvents)
+	trackers := cfg.Integrations.IntegrationOptions().Trackers()
+	if len(trackers) == 0 {
+		return integrations.NewMirror(nil, nil, log)
+	}
+	path, err := IssuesPath(cfg)
+	if err != nil {
+		log.Warn("issue links will not be remembered", "error", err)
+	}
+	return integrations.NewMirror(trackers, integrations.NewStore(path), log)
+}
+
+// MirrorEvent mirrors event to the configured issue trackers. Failures are
+// logged and never fail the operation that produced the event.
+func MirrorEvent(cfg *config.Config, event events.Event) {
+	if cfg == nil {
+		return
+	}
+	if err := NewIssueMirror(cfg).Handle(context.Background(), event); err != nil {
+		logger.For(logger.SubsystemEvents).Warn("failed to mirror event to issue trackers", "type", event.Type, "error", err)
+	}
+}
+
+// NewIntegrationsCommand creates the integrations command.
+func NewIntegrationsCommand(cfg *config.Config) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "integrations",
+		Short: "Show issues mirroring Jules sessions",
+		Long: `Juleson mirrors split template run tasks and Jules sessions to Jira or Linear
+issues: it creates an issue when a task starts or a session is created, links
+the session and its pull requests, and moves the issue as the work finishes.
+See the integrations section of the configuration.`,
+	}
+
+	cmd.AddCommand(newIntegrationsIssuesCommand(cfg))
+	return cmd
+}
+
+func newIntegrationsIssuesCommand(cfg *config.Config) *cobra.Command {
+	var jsonOutput bool
+
+	cmd := &cobra.Command{
+		Use:   "issues [session-id]",
+		Short: "List the issues mirroring sessions",
+		Args:  cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			path, err := IssuesPath(cfg)
+			if err != nil {
+				return err
+			}
+			sessions := integrations.NewStore(path).Sessions()
+			if len(args) == 1 {
+				issues, ok := sessions[args[0]]
+				if !ok {
+					return fmt.Errorf("session %s is not mirrored to any issue", args[0])
+				}
+				sessions = map[string]integrations.SessionIssues{args[0]: issues}
+			}
+			if jsonOutput {
+				encoder := json.NewEncoder(cmd.OutOrStdout())
+				encoder.SetIndent("", "  ")
+				return encoder.Encode(sessions)
+			}
+			if len(sessions) == 0 {
+				fmt.Fprintln(cmd.OutOrStdout(), "No sessions are mirrored to issues")
+				return nil
+			}
+			ids := make([]string, 0, len(sessions))
+			for id := range sessions {
+				ids = append(ids, id)
+			}
+			sort.Strings(ids)
+			for _, id := range ids {
+				entry := sessions[id]
+				trackers := make([]string, 0, len(entry.Issues))
+				for tracker := range entry.Issues {
+					trackers = append(trackers, tracker)
+				}
+				sort.Strings(trackers)
+				refs := make([]string, 0, len(trackers))
+				for _, tracker := range trackers {
+					ref := entry.Issues[tracker]
+					refs = append(refs, fmt.Sprintf("%s %s %s", tracker, ref.Key, ref.URL))
+				}
+				line := id + ": " + strings.Join(refs, ", ")
+				if entry.Task != "" {
+					line += " (task " + entry.Task + ")"
+				}
+				fmt.Fprintln(cmd.OutOrStdout(), line)
+			}
+			return nil
+		},
+	}
+	cmd.Flags().BoolVar(&jsonOutput, "json", false, "Output as JSON")
+	return cmd
+}
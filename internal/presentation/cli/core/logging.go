@@ -0,0 +1,37 @@
+package core
+
+import (
+	"io"
+
+	"github.com/SamyRai/juleson/internal/config"
+	"github.com/SamyRai/juleson/internal/logger"
+)
+
+// SetupLogging configures the global logger from the log section of cfg.
+// out receives logs when no sinks are configured.
+func SetupLogging(cfg *config.Config, out io.Writer) error {
+	return logger.Setup(LogOptions(cfg, out))
+}
+
+// LogOptions converts the log config into logger options.
+func LogOptions(cfg *config.Config, out io.Writer) logger.Options {
+	opts := logger.Options{
+		Output:     out,
+		Level:      cfg.Log.Level,
+		Format:     cfg.Log.Format,
+		Subsystems: cfg.Log.Subsystems,
+		Debug:      cfg.Jules.DebugLog,
+	}
+	for _, sink := range cfg.Log.Sinks {
+		opts.Sinks = append(opts.Sinks, logger.SinkOptions{
+			Type:       sink.Type,
+			Path:       sink.Path,
+			Address:    sink.Address,
+			Format:     sink.Format,
+			Level:      sink.Level,
+			MaxSizeMB:  sink.MaxSizeMB,
+			MaxBackups: sink.MaxBackups,
+		})
+	}
+	return opts
+}
@@ -0,0 +1,135 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync/atomic"
+
+	"github.com/SamyRai/juleson/internal/config"
+	"github.com/SamyRai/juleson/internal/events"
+	"github.com/SamyRai/juleson/internal/logger"
+	"github.com/SamyRai/juleson/internal/notify"
+	"github.com/spf13/cobra"
+)
+
+// NewNotifier creates a notifier for cfg's notification settings.
+func NewNotifier(cfg *config.Config) *notify.Notifier {
+	return notify.New(cfg.Notifications.NotifyOptions(), logger.For(logger.SubsystemEvents))
+}
+
+// Notify sends event to the notification channels configured for it.
+// Failures are logged by the notifier and never fail the operation that
+// produced the event.
+func Notify(cfg *config.Config, event events.Event) {
+	if cfg == nil {
+		return
+	}
+	_ = NewNotifier(cfg).Notify(context.Background(), event)
+}
+
+// breakerConfig is the configuration the Jules circuit breaker notifies
+// with. It is replaced whenever runtime settings are applied, so config
+// reloads change where the breaker's notifications go.
+var breakerConfig atomic.Pointer[config.Config]
+
+// notifyBreakerStateChange reports the Jules circuit breaker opening.
+func notifyBreakerStateChange(name string, previous, state events.CircuitState) {
+	cfg := breakerConfig.Load()
+	if state != events.StateOpen || cfg == nil {
+		return
+	}
+	notifier := NewNotifier(cfg)
+	if !notifier.Enabled() {
+		return
+	}
+	event := events.NewEvent(events.EventCircuitBreakerOpened, "juleson", events.CircuitBreakerEventData{
+		Name:          name,
+		State:         string(state),
+		PreviousState: string(previous),
+		ResetTimeout:  cfg.CircuitBreaker.ResetTimeout,
+	}).WithTopic(events.TopicSystem)
+	// The breaker calls back while holding its lock; deliver without it.
+	go func() { _ = notifier.Notify(context.Background(), event) }()
+}
+
+// NewNotifyCommand creates the notify command.
+func NewNotifyCommand(cfg *config.Config) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "notify",
+		Short: "Check notification channels and routing",
+		Long: `Juleson notifies configured Slack, email, and desktop channels about key
+events: sessions completing or failing, plans awaiting approval, pull requests
+opened, and the Jules API circuit breaker opening. See the notifications
+section of the configuration.`,
+	}
+
+	cmd.AddCommand(newNotifyTestCommand(cfg))
+	cmd.AddCommand(newNotifyRouteCommand(cfg))
+	return cmd
+}
+
+func newNotifyTestCommand(cfg *config.Config) *cobra.Command {
+	var channels []string
+
+	cmd := &cobra.Command{
+		Use:   "test",
+		Short: "Send a test notification",
+		Long:  "Send a test notification to every configured channel, or to those given with --channel.",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			options := cfg.Notifications.NotifyOptions()
+			configured := options.Channels()
+			if len(configured) == 0 {
+				return fmt.Errorf("no notification channels are configured; set notifications.slack, notifications.email, or notifications.desktop")
+			}
+			if len(channels) == 0 {
+				channels = configured
+			}
+			notifier := notify.New(options, logger.For(logger.SubsystemEvents))
+			message := notify.Message{Title: "Juleson test notification", Body: "Notifications from Juleson reach this channel."}
+			if err := notifier.Send(cmd.Context(), message, channels...); err != nil {
+				return fmt.Errorf("failed to send test notification: %w", err)
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "✅ Sent a test notification to %s\n", strings.Join(channels, ", "))
+			return nil
+		},
+	}
+	cmd.Flags().StringSliceVar(&channels, "channel", nil, "Channel to test: slack, email, or desktop (repeatable)")
+	return cmd
+}
+
+func newNotifyRouteCommand(cfg *config.Config) *cobra.Command {
+	var topic, repo, severity string
+
+	cmd := &cobra.Command{
+		Use:   "route <event-type>",
+		Short: "Show the channels an event would be sent to",
+		Example: `  juleson notify route session.failed
+  juleson notify route github.pr.created --topic github --repo acme/widgets`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			event := events.NewEvent(events.EventType(args[0]), "juleson", nil).WithTopic(topic)
+			if repo != "" {
+				event = event.WithMetadata("repository", repo)
+			}
+			if severity != "" {
+				if _, err := events.ParseSeverity(severity); err != nil {
+					return err
+				}
+				event = event.WithMetadata("severity", severity)
+			}
+			channels := NewNotifier(cfg).Channels(event)
+			if len(channels) == 0 {
+				fmt.Fprintf(cmd.OutOrStdout(), "%s is not sent to any channel\n", args[0])
+				return nil
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "%s is sent to %s\n", args[0], strings.Join(channels, ", "))
+			return nil
+		},
+	}
+	cmd.Flags().StringVar(&topic, "topic", "", "Event topic, such as session or github")
+	cmd.Flags().StringVar(&repo, "repo", "", "Repository the event concerns, as owner/name")
+	cmd.Flags().StringVar(&severity, "severity", "", "Event severity: info, warning, or error (default: from the event type)")
+	return cmd
+}
@@ -0,0 +1,162 @@
+package core
+
+import (
+	"os"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/SamyRai/go-jules"
+	"github.com/SamyRai/juleson/internal/config"
+	"github.com/SamyRai/juleson/internal/github/githubtest"
+	"github.com/SamyRai/juleson/internal/jules/julestest"
+)
+
+// OfflineEnv enables offline mode when set to a true value.
+const OfflineEnv = "JULESON_OFFLINE"
+
+// Offline mode identity and demo repository.
+const (
+	offlineAPIKey = "offline"
+	offlineLogin  = "demo-user"
+	offlineOwner  = "juleson-demo"
+	offlineRepo   = "webapp"
+)
+
+// OfflineRequested reports whether args include --offline, before any "--",
+// or JULESON_OFFLINE is set to a true value.
+func OfflineRequested(args []string) bool {
+	if end := slices.Index(args, "--"); end >= 0 {
+		args = args[:end]
+	}
+	for _, arg := range args {
+		if arg == "--offline" || arg == "--offline=true" {
+			return true
+		}
+	}
+	enabled, _ := strconv.ParseBool(os.Getenv(OfflineEnv))
+	return enabled
+}
+
+// StartOffline starts fake Jules and GitHub APIs seeded with demo data and
+// points cfg, and every configuration loaded later, at them. Audit logging
+// is disabled so demo activity stays out of the real journal. The returned
+// function stops the fakes.
+func StartOffline(cfg *config.Config) func() {
+	julesServer := julestest.NewServer()
+	julesServer.APIKey = offlineAPIKey
+	julesServer.Simulate = true
+	githubServer := githubtest.NewServer(offlineLogin)
+	seedOfflineDemo(julesServer, githubServer)
+
+	override := func(cfg *config.Config) {
+		cfg.Jules.APIKey = offlineAPIKey
+		cfg.Jules.BaseURL = julesServer.URL()
+		cfg.GitHub.Token = offlineAPIKey
+		cfg.GitHub.BaseURL = githubServer.URL()
+		cfg.GitHub.UploadURL = githubServer.UploadURL()
+		cfg.GitHub.Hosts = nil
+		cfg.Audit.Enabled = false
+	}
+	override(cfg)
+	config.AddOverride(override)
+
+	return func() {
+		julesServer.Close()
+		githubServer.Close()
+	}
+}
+
+// seedOfflineDemo adds a demo repository and sessions in each stage of
+// their lifecycle.
+func seedOfflineDemo(julesServer *julestest.Server, githubServer *githubtest.Server) {
+	githubServer.AddRepository(offlineOwner, offlineRepo, "main")
+	source := julesServer.AddSource(offlineOwner, offlineRepo, "main")
+	sourceContext := &jules.SourceContext{
+		Source:            source.Name,
+		GithubRepoContext: &jules.GithubRepoContext{StartingBranch: "main"},
+	}
+	now := time.Now().UTC()
+
+	diff := "diff --git a/signup.go b/signup.go\n" +
+		"--- a/signup.go\n" +
+		"+++ b/signup.go\n" +
+		"@@ -10,3 +10,6 @@ func Signup(email string) error {\n" +
+		"+\tif !strings.Contains(email, \"@\") {\n" +
+		"+\t\treturn ErrInvalidEmail\n" +
+		"+\t}\n" +
+		" \treturn store.CreateUser(email)\n"
+	pr := githubServer.AddPullRequest(offlineOwner, offlineRepo, "Validate email addresses on signup", "jules/validate-signup", diff)
+	completed := julesServer.AddSession(jules.Session{
+		ID:            "demo-completed",
+		Title:         "Validate email addresses on signup",
+		Prompt:        "Reject signups with malformed email addresses and add tests.",
+		State:         jules.SessionStateCompleted,
+		SourceContext: sourceContext,
+		CreateTime:    now.Add(-3 * time.Hour),
+	})
+	julesServer.SetOutputs(completed.ID, jules.Output{PullRequest: &jules.PullRequest{
+		URL:     pr.GetHTMLURL(),
+		Title:   pr.GetTitle(),
+		BaseRef: "main",
+		HeadRef: "jules/validate-signup",
+	}})
+	julesServer.AddActivities(completed.ID,
+		jules.Activity{Originator: jules.ActivityOriginatorAgent, PlanGenerated: &jules.PlanGenerated{Plan: jules.Plan{
+			ID: "demo-plan-1",
+			Steps: []jules.Step{
+				{Title: "Add email validation to Signup", Index: 0},
+				{Title: "Cover malformed addresses in signup_test.go", Index: 1},
+			},
+		}}},
+		jules.Activity{Originator: jules.ActivityOriginatorUser, PlanApproved: &jules.PlanApproved{PlanID: "demo-plan-1"}},
+		jules.Activity{Originator: jules.ActivityOriginatorAgent, ProgressUpdated: &jules.ProgressUpdated{Title: "Tests pass"},
+			Artifacts: []jules.Artifact{{ChangeSet: &jules.ChangeSet{Source: source.Name, GitPatch: &jules.GitPatch{
+				UnidiffPatch:           diff,
+				SuggestedCommitMessage: "Validate email addresses on signup",
+			}}}}},
+		jules.Activity{Originator: jules.ActivityOriginatorSystem, SessionCompleted: &jules.SessionCompleted{}},
+	)
+
+	awaiting := julesServer.AddSession(jules.Session{
+		ID:                  "demo-awaiting-approval",
+		Title:               "Upgrade the logging library",
+		Prompt:              "Upgrade the logging library to the latest major version.",
+		State:               jules.SessionStatePlanning,
+		SourceContext:       sourceContext,
+		RequirePlanApproval: true,
+		CreateTime:          now.Add(-time.Hour),
+	})
+	julesServer.PostPlan(awaiting.ID, jules.Plan{
+		ID: "demo-plan-2",
+		Steps: []jules.Step{
+			{Title: "Bump the logging module", Index: 0},
+			{Title: "Replace deprecated calls", Index: 1},
+			{Title: "Run the test suite", Index: 2},
+		},
+	})
+
+	inProgress := julesServer.AddSession(jules.Session{
+		ID:            "demo-in-progress",
+		Title:         "Document the release process",
+		Prompt:        "Write docs/RELEASING.md describing how releases are cut.",
+		State:         jules.SessionStateInProgress,
+		SourceContext: sourceContext,
+		CreateTime:    now.Add(-20 * time.Minute),
+	})
+	julesServer.AddActivities(inProgress.ID,
+		jules.Activity{Originator: jules.ActivityOriginatorAgent, ProgressUpdated: &jules.ProgressUpdated{Title: "Reading the release workflow"}},
+	)
+
+	failed := julesServer.AddSession(jules.Session{
+		ID:            "demo-failed",
+		Title:         "Fix the memory leak in the worker pool",
+		Prompt:        "Find and fix the memory leak reported in the worker pool.",
+		State:         jules.SessionStateFailed,
+		SourceContext: sourceContext,
+		CreateTime:    now.Add(-2 * time.Hour),
+	})
+	julesServer.AddActivities(failed.ID,
+		jules.Activity{Originator: jules.ActivityOriginatorSystem, SessionFailed: &jules.SessionFailed{Reason: "Could not reproduce the leak"}},
+	)
+}
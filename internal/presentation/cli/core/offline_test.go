@@ -0,0 +1,70 @@
+package core
+
+import (
+	"context"
+	"testing"
+
+	"github.com/SamyRai/go-jules"
+	"github.com/SamyRai/juleson/internal/config"
+)
+
+func TestOfflineRequested(t *testing.T) {
+	tests := []struct {
+		name string
+		env  string
+		args []string
+		want bool
+	}{
+		{name: "flag", args: []string{"sessions", "list", "--offline"}, want: true},
+		{name: "flag with value", args: []string{"--offline=true", "status"}, want: true},
+		{name: "after dashes", args: []string{"official", "--", "--offline"}},
+		{name: "env", env: "1", args: []string{"status"}, want: true},
+		{name: "env false", env: "false", args: []string{"status"}},
+		{name: "neither", args: []string{"status"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv(OfflineEnv, tt.env)
+			if got := OfflineRequested(tt.args); got != tt.want {
+				t.Errorf("OfflineRequested(%q) = %v, want %v", tt.args, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestStartOfflineServesDemoData(t *testing.T) {
+	cfg := &config.Config{}
+	cfg.Audit.Enabled = true
+	stop := StartOffline(cfg)
+	defer stop()
+
+	if cfg.Jules.APIKey == "" || cfg.GitHub.Token == "" || cfg.Audit.Enabled {
+		t.Fatalf("offline config = %+v", cfg)
+	}
+
+	ctx := context.Background()
+	client := NewJulesClient(cfg)
+	response, err := client.Sessions().List(ctx, nil)
+	if err != nil {
+		t.Fatalf("List sessions: %v", err)
+	}
+	if len(response.Sessions) != 4 {
+		t.Fatalf("demo sessions = %d, want 4", len(response.Sessions))
+	}
+
+	session, err := client.Sessions().Create(ctx, &jules.CreateSessionRequest{
+		Prompt:        "Add a README",
+		SourceContext: response.Sessions[0].SourceContext,
+	})
+	if err != nil {
+		t.Fatalf("Create session: %v", err)
+	}
+	if session.State != jules.SessionStateCompleted {
+		t.Errorf("simulated session state = %s, want COMPLETED", session.State)
+	}
+
+	login, err := verifyGitHubToken(ctx, cfg)
+	if err != nil || login != offlineLogin {
+		t.Errorf("verifyGitHubToken = %q, %v", login, err)
+	}
+}
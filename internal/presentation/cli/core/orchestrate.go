@@ -0,0 +1,340 @@
+package core
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"strings"
+	"sync"
+
+	"github.com/SamyRai/go-jules"
+	"github.com/SamyRai/juleson/internal/config"
+	"github.com/SamyRai/juleson/internal/intelligence"
+	julessessions "github.com/SamyRai/juleson/internal/jules/sessions"
+	"github.com/SamyRai/juleson/internal/jules/workspace"
+	"github.com/SamyRai/juleson/internal/policy"
+	"github.com/SamyRai/juleson/internal/testgen"
+	"github.com/spf13/cobra"
+)
+
+// NewOrchestrateCommand creates the orchestrate command, which runs built-in
+// workflows spanning several Jules sessions.
+func NewOrchestrateCommand(cfg *config.Config) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "orchestrate",
+		Short: "Run built-in workflows that span several Jules sessions",
+	}
+	cmd.AddCommand(newTestCoverageCommand(cfg))
+	cmd.AddCommand(newDepsUpgradeCommand(cfg))
+	cmd.AddCommand(newMigrateCommand(cfg))
+	return cmd
+}
+
+// TestCoverageOptions configures `orchestrate test-coverage`.
+type TestCoverageOptions struct {
+	// Dir is the Go module to raise the coverage of.
+	Dir    string
+	Source string
+	// Target is the coverage to reach, in percent.
+	Target        float64
+	MaxSessions   int
+	MaxRounds     int
+	Parallel      int
+	MinStatements int
+	// Checks replace `go test` of the package when validating a session's
+	// tests.
+	Checks []string
+	// Projects runs the workflow in each Go module of a monorepo at Dir, or
+	// with Since only in those affected by changes since that commit.
+	Projects bool
+	Since    string
+	// project is the module's directory relative to the repository root
+	// when the workflow runs in one module of a monorepo.
+	project    *intelligence.SubProject
+	ApprovalID string
+	DryRun     bool
+	JSON       bool
+}
+
+func newTestCoverageCommand(cfg *config.Config) *cobra.Command {
+	options := TestCoverageOptions{Dir: ".", Source: "."}
+
+	cmd := &cobra.Command{
+		Use:   "test-coverage",
+		Short: "Have Jules write tests, package by package, until coverage reaches a target",
+		Long: `Measure the statement coverage of the Go module in the current directory with
+go test -coverprofile, then create a Jules session for each of the packages
+with the most uncovered statements, asking for tests of that package only.
+
+When a session completes, the test files it changed are applied in a
+temporary worktree and the package's tests run there; only passing tests are
+merged into the working tree. Coverage is then measured again, and further
+rounds run until the target is reached, --max-sessions sessions have been
+created, --max-rounds rounds have run, or no package is left to improve. Each
+package gets at most two sessions, and packages with fewer than
+--min-statements statements are skipped.
+
+In a monorepo, --projects runs the workflow in each Go module of the go.work
+file, or of the nested go.mod files, one after the other, with sessions scoped
+to the module, and ends with a combined report. --since limits it to the
+modules containing files changed since a commit and the modules that depend
+on them.
+
+Sessions run without plan approval, which the policy may require an approval
+for. --dry-run measures coverage and lists the packages the first round would
+work on, without creating sessions.`,
+		Example: `  juleson orchestrate test-coverage --target 80
+  juleson orchestrate test-coverage --target 70 --projects --since origin/main
+  juleson orchestrate test-coverage --target 70 --max-sessions 4 --parallel 1
+  juleson orchestrate test-coverage --target 80 --dry-run`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return RunTestCoverage(cmd.Context(), cfg, cmd.OutOrStdout(), options)
+		},
+	}
+	cmd.Flags().Float64Var(&options.Target, "target", 80, "Coverage to reach, in percent")
+	cmd.Flags().IntVar(&options.MaxSessions, "max-sessions", testgen.DefaultMaxSessions, "Maximum Jules sessions to create")
+	cmd.Flags().IntVar(&options.MaxRounds, "max-rounds", testgen.DefaultMaxRounds, "Maximum rounds of sessions")
+	cmd.Flags().IntVar(&options.Parallel, "parallel", testgen.DefaultParallel, "Packages to work on at a time")
+	cmd.Flags().IntVar(&options.MinStatements, "min-statements", testgen.DefaultMinStatements, "Skip packages with fewer statements")
+	cmd.Flags().StringVar(&options.Source, "source", options.Source, "Jules source ID, or . for the current repository")
+	cmd.Flags().StringArrayVar(&options.Checks, "check", nil, "Command that validates a session's tests (repeatable; default go test of the package)")
+	cmd.Flags().BoolVar(&options.Projects, "projects", false, "Run the workflow in each Go module of a monorepo")
+	cmd.Flags().StringVar(&options.Since, "since", "", "With --projects, only modules affected by changes since this commit or branch")
+	cmd.Flags().StringVar(&options.ApprovalID, "approval", "", "Approval ID granted by a second approver when policy requires one")
+	cmd.Flags().BoolVar(&options.DryRun, "dry-run", false, "Measure coverage and list the packages to work on without creating sessions")
+	cmd.Flags().BoolVar(&options.JSON, "json", false, "Print the result as JSON")
+	return cmd
+}
+
+// RunTestCoverage runs the test-coverage workflow.
+func RunTestCoverage(ctx context.Context, cfg *config.Config, out io.Writer, options TestCoverageOptions) error {
+	if options.Target <= 0 || options.Target > 100 {
+		return fmt.Errorf("--target must be between 0 and 100, got %g", options.Target)
+	}
+	if options.Since != "" && !options.Projects {
+		return fmt.Errorf("--since requires --projects")
+	}
+	if options.Projects {
+		return runTestCoverageProjects(ctx, cfg, out, options)
+	}
+	target := options.Target / 100
+	progress := func(line string) { fmt.Fprintln(out, line) }
+	if options.JSON {
+		progress = nil
+	}
+	measure := func(ctx context.Context) (*intelligence.CoverageReport, error) {
+		return intelligence.MeasureCoverage(ctx, options.Dir)
+	}
+
+	if options.DryRun {
+		report, err := measure(ctx)
+		if err != nil {
+			return err
+		}
+		candidates := testgen.Candidates(report, target, options.MinStatements, nil)
+		if options.JSON {
+			return writeJSON(out, map[string]interface{}{"coverage": report.Coverage(), "target": target, "candidates": candidates})
+		}
+		fmt.Fprintf(out, "Coverage: %.1f%% of %d statements (target %.1f%%)\n", report.Coverage()*100, report.Statements, options.Target)
+		if report.Coverage() >= target {
+			fmt.Fprintln(out, "The target is already reached.")
+			return nil
+		}
+		if len(candidates) == 0 {
+			fmt.Fprintln(out, "No package below the target has enough statements to work on.")
+			return nil
+		}
+		fmt.Fprintln(out, "The first round would write tests for:")
+		for _, pkg := range candidates[:min(len(candidates), max(options.Parallel, 1))] {
+			fmt.Fprintf(out, "  %s: %.1f%% covered, %d statements uncovered\n", pkg.Dir, pkg.Coverage()*100, pkg.Uncovered())
+		}
+		return nil
+	}
+
+	result, err := testCoverage(ctx, cfg, options, progress)
+	if options.JSON && result != nil {
+		if jsonErr := writeJSON(out, result); jsonErr != nil {
+			return jsonErr
+		}
+		return err
+	}
+	if err != nil {
+		return err
+	}
+	status := "✅"
+	if !result.Reached() {
+		status = "⚠️ "
+	}
+	fmt.Fprintf(out, "\n%s Coverage %.1f%% → %.1f%% (target %.1f%%) with %d session(s): %s\n",
+		status, result.Start*100, result.Final*100, options.Target, result.Sessions, result.Stopped)
+	return nil
+}
+
+// testCoverage creates the sessions of the test-coverage workflow until it
+// stops. Progress may be nil.
+func testCoverage(ctx context.Context, cfg *config.Config, options TestCoverageOptions, progress func(string)) (*testgen.Result, error) {
+	target := options.Target / 100
+	measure := func(ctx context.Context) (*intelligence.CoverageReport, error) {
+		return intelligence.MeasureCoverage(ctx, options.Dir)
+	}
+
+	client := NewJulesClient(cfg)
+	requestOptions, err := orchestrateRequestOptions(ctx, cfg, client, options.Source, options.Dir, "orchestrate test-coverage", options.ApprovalID)
+	if err != nil {
+		return nil, err
+	}
+
+	// module is the module path, known after the first measurement.
+	var module string
+	measured := func(ctx context.Context) (*intelligence.CoverageReport, error) {
+		report, err := measure(ctx)
+		if report != nil {
+			module = report.Module
+		}
+		return report, err
+	}
+	// applyMu applies one session at a time, since each adds and removes a
+	// worktree of the same repository and merges into the working tree.
+	var applyMu sync.Mutex
+	write := func(ctx context.Context, pkg intelligence.PackageCoverage, target float64) testgen.Attempt {
+		var attempt testgen.Attempt
+		attempt.SessionID, attempt.Applied, attempt.Error = writePackageTests(ctx, cfg, client, requestOptions, module, options.project, pkg, target, options.Checks, &applyMu)
+		return attempt
+	}
+
+	return testgen.Run(ctx, testgen.Options{
+		Target:        target,
+		MaxSessions:   options.MaxSessions,
+		MaxRounds:     options.MaxRounds,
+		Parallel:      options.Parallel,
+		MinStatements: options.MinStatements,
+	}, measured, write, progress)
+}
+
+// writePackageTests creates a session that writes tests for pkg, waits for
+// it, and merges its test files when the checks pass on them. project, when
+// set, is the monorepo module pkg belongs to. It returns the session ID,
+// whether the tests were merged, and why not.
+func writePackageTests(ctx context.Context, cfg *config.Config, client *jules.Client, requestOptions julessessions.CreateSessionRequestOptions, module string, project *intelligence.SubProject, pkg intelligence.PackageCoverage, target float64, checks []string, applyMu *sync.Mutex) (string, bool, string) {
+	requestOptions.Prompt = testgen.Prompt(module, pkg, target)
+	requestOptions.Title = "Test coverage: " + pkg.Dir
+	defaultCheck := "go test " + testgen.TestPattern(pkg.Dir)
+	if project != nil {
+		requestOptions.Prompt = ScopePrompt(requestOptions.Prompt, *project)
+		requestOptions.Title = "Test coverage: " + path.Join(project.Dir, pkg.Dir)
+		defaultCheck = "go test -C " + project.Dir + " " + testgen.TestPattern(pkg.Dir)
+	}
+	session, err := runOrchestratedSession(ctx, cfg, client, requestOptions, map[string]interface{}{
+		"workflow": "test-coverage",
+		"package":  pkg.Dir,
+	})
+	if session == nil {
+		return "", false, err.Error()
+	}
+	if err != nil {
+		return session.ID, false, err.Error()
+	}
+
+	if len(checks) == 0 {
+		checks = []string{defaultCheck}
+	}
+	applyMu.Lock()
+	defer applyMu.Unlock()
+	patchOptions := &workspace.PatchApplicationOptions{WorkingDir: ".", Only: []string{"*_test.go"}}
+	result, err := workspace.ApplySessionPatchesIsolated(ctx, client, session.ID, workspace.IsolatedApplyOptions{
+		Patch:  patchOptions,
+		Checks: checks,
+	})
+	if err == nil && (result == nil || result.Patch == nil || result.Patch.PatchesApplied == 0) {
+		err = testgen.ErrNoChanges
+	}
+	details := map[string]interface{}{"workflow": "test-coverage", "package": pkg.Dir}
+	if result != nil {
+		details["files_modified"] = result.FilesMerged
+	}
+	RecordAudit(cfg, AuditSourceCLI, AuditPatchApply, session.ID, err, details)
+	if err != nil {
+		var failed []string
+		if result != nil {
+			for _, check := range result.Checks {
+				if !check.Success {
+					failed = append(failed, check.Command)
+				}
+			}
+		}
+		if len(failed) > 0 {
+			return session.ID, false, "checks failed: " + strings.Join(failed, ", ")
+		}
+		if errors.Is(err, testgen.ErrNoChanges) {
+			return session.ID, false, err.Error()
+		}
+		return session.ID, false, fmt.Sprintf("failed to apply tests: %v", err)
+	}
+	return session.ID, true, ""
+}
+
+// orchestrateRequestOptions resolves the source of a workflow's sessions and
+// returns their request options. The sessions run without plan approval, so
+// the policy must allow it for tool.
+func orchestrateRequestOptions(ctx context.Context, cfg *config.Config, client *jules.Client, source, dir, tool, approvalID string) (julessessions.CreateSessionRequestOptions, error) {
+	sourceName := julessessions.NormalizeSourceID(source)
+	if source == "." {
+		resolved, err := workspace.ResolveSource(ctx, client, dir)
+		if err != nil {
+			return julessessions.CreateSessionRequestOptions{}, err
+		}
+		sourceName = resolved
+	}
+	if err := EnforcePolicy(cfg, policy.Check{
+		Request: policy.Request{
+			Operation: policy.OpAutoApprovePlan,
+			Tool:      tool,
+			Repo:      policy.RepoFromSource(sourceName),
+		},
+		ApprovalID: approvalID,
+	}, true); err != nil {
+		return julessessions.CreateSessionRequestOptions{}, err
+	}
+	requestOptions := julessessions.CreateSessionRequestOptions{Source: sourceName}
+	julessessions.ApplyDefaults(&requestOptions, cfg.Sessions, true)
+	requestOptions.RequirePlanApproval = false
+	return requestOptions, nil
+}
+
+// runOrchestratedSession creates a session within the session budget and
+// waits for it to complete. It returns the session whenever one was created,
+// also when it failed.
+func runOrchestratedSession(ctx context.Context, cfg *config.Config, client *jules.Client, requestOptions julessessions.CreateSessionRequestOptions, details map[string]interface{}) (*jules.Session, error) {
+	if err := CheckSessionBudget(cfg, AuditSourceCLI, 1); err != nil {
+		return nil, err
+	}
+	req, err := julessessions.BuildCreateSessionRequest(requestOptions)
+	if err != nil {
+		return nil, err
+	}
+	session, err := client.Sessions().Create(ctx, req)
+	auditTarget := requestOptions.Source
+	if session != nil {
+		auditTarget = session.ID
+	}
+	details["source"] = requestOptions.Source
+	RecordAudit(cfg, AuditSourceCLI, AuditSessionCreate, auditTarget, err, details)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create session: %w", err)
+	}
+	completed, err := julessessions.WaitForSession(ctx, client, session.ID, templateTaskPollInterval, nil)
+	if completed != nil {
+		session = completed
+	}
+	return session, err
+}
+
+func writeJSON(w io.Writer, value interface{}) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(value)
+}
@@ -0,0 +1,288 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+
+	"github.com/SamyRai/go-jules"
+	"github.com/SamyRai/juleson/internal/config"
+	"github.com/SamyRai/juleson/internal/deps"
+	julessessions "github.com/SamyRai/juleson/internal/jules/sessions"
+	"github.com/SamyRai/juleson/internal/jules/workspace"
+	"github.com/SamyRai/juleson/internal/vcs"
+	"github.com/spf13/cobra"
+)
+
+// defaultDepsUpgradeSessions bounds the groups one deps-upgrade run works on.
+const defaultDepsUpgradeSessions = 5
+
+// DepsUpgradeOptions configures `orchestrate deps-upgrade`.
+type DepsUpgradeOptions struct {
+	Dir    string
+	Source string
+	// Ecosystems limits detection to go or npm; empty means both.
+	Ecosystems []string
+	// Major also upgrades across major versions, one pull request each.
+	Major       bool
+	MaxSessions int
+	Parallel    int
+	// Checks validate each group's changes; empty runs the project's build
+	// and tests.
+	Checks []string
+	// Repo, Remote, and Base are where the pull requests are opened.
+	Repo       string
+	Remote     string
+	Base       string
+	NoPR       bool
+	ApprovalID string
+	DryRun     bool
+	JSON       bool
+}
+
+// DepsUpgradeResult is the outcome of one group's upgrade.
+type DepsUpgradeResult struct {
+	Group     deps.Group `json:"group"`
+	SessionID string     `json:"sessionId,omitempty"`
+	Branch    string     `json:"branch,omitempty"`
+	Commit    string     `json:"commit,omitempty"`
+	URL       string     `json:"url,omitempty"`
+	Error     string     `json:"error,omitempty"`
+}
+
+func newDepsUpgradeCommand(cfg *config.Config) *cobra.Command {
+	options := DepsUpgradeOptions{Dir: ".", Source: ".", Remote: "origin"}
+
+	cmd := &cobra.Command{
+		Use:   "deps-upgrade",
+		Short: "Have Jules upgrade outdated dependencies and open a pull request per group",
+		Long: `Find the outdated direct dependencies of the project in the current
+directory with go list -u and npm outdated, and group them: the patch and
+minor upgrades of each ecosystem together, and, with --major, each major
+upgrade alone. Minor upgrades before v1 count as major.
+
+Each group gets a Jules session that makes the upgrades after reading the
+changelogs of the versions skipped. When it completes, its changes are
+applied in a temporary worktree and the project's build and tests run there
+(--check replaces them). Changes that pass are committed to the branch
+juleson/deps/<group>, which is pushed to --remote, and a pull or merge request
+is opened for it. The working tree is never changed. With --no-pr the branch
+is only committed locally.
+
+Sessions run without plan approval, which the policy may require an approval
+for. --dry-run lists the groups without creating sessions.`,
+		Example: `  juleson orchestrate deps-upgrade
+  juleson orchestrate deps-upgrade --major --ecosystem npm
+  juleson orchestrate deps-upgrade --dry-run`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return RunDepsUpgrade(cmd.Context(), cfg, cmd.OutOrStdout(), options)
+		},
+	}
+	cmd.Flags().StringArrayVar(&options.Ecosystems, "ecosystem", nil, "Ecosystem to upgrade: go or npm (repeatable; default both)")
+	cmd.Flags().BoolVar(&options.Major, "major", false, "Also upgrade across major versions, one pull request each")
+	cmd.Flags().IntVar(&options.MaxSessions, "max-sessions", defaultDepsUpgradeSessions, "Maximum groups to upgrade, one Jules session each")
+	cmd.Flags().IntVar(&options.Parallel, "parallel", 2, "Groups to work on at a time")
+	cmd.Flags().StringArrayVar(&options.Checks, "check", nil, "Command that validates a group's changes (repeatable; default the project's build and tests)")
+	cmd.Flags().StringVar(&options.Source, "source", options.Source, "Jules source ID, or . for the current repository")
+	cmd.Flags().StringVar(&options.Repo, "repo", "", "Repository to open pull requests in (default: the origin remote's)")
+	cmd.Flags().StringVar(&options.Remote, "remote", options.Remote, "Remote to push branches to")
+	cmd.Flags().StringVar(&options.Base, "base", "", "Branch to merge into (default: the repository's default branch)")
+	cmd.Flags().BoolVar(&options.NoPR, "no-pr", false, "Commit each group to a local branch without pushing it or opening a pull request")
+	cmd.Flags().StringVar(&options.ApprovalID, "approval", "", "Approval ID granted by a second approver when policy requires one")
+	cmd.Flags().BoolVar(&options.DryRun, "dry-run", false, "List the upgrade groups without creating sessions")
+	cmd.Flags().BoolVar(&options.JSON, "json", false, "Print the result as JSON")
+	return cmd
+}
+
+// RunDepsUpgrade runs the deps-upgrade workflow.
+func RunDepsUpgrade(ctx context.Context, cfg *config.Config, out io.Writer, options DepsUpgradeOptions) error {
+	for _, ecosystem := range options.Ecosystems {
+		if ecosystem != deps.Go && ecosystem != deps.NPM {
+			return fmt.Errorf("unknown ecosystem %q: use go or npm", ecosystem)
+		}
+	}
+	outdated, err := deps.Detect(ctx, options.Dir, options.Ecosystems)
+	if err != nil {
+		return err
+	}
+	groups := deps.GroupUpgrades(deps.Plan(outdated, options.Major))
+	if options.MaxSessions > 0 && len(groups) > options.MaxSessions {
+		groups = groups[:options.MaxSessions]
+	}
+
+	if options.DryRun || len(groups) == 0 {
+		if options.JSON {
+			return writeJSON(out, groups)
+		}
+		if len(groups) == 0 {
+			fmt.Fprintln(out, "✅ No dependencies to upgrade.")
+			return nil
+		}
+		for _, group := range groups {
+			fmt.Fprintf(out, "%s → %s\n", group.Name, group.Branch())
+			for _, upgrade := range group.Upgrades {
+				fmt.Fprintf(out, "  %s %s → %s (%s)\n", upgrade.Name, upgrade.Current, upgrade.Target, upgrade.Bump)
+			}
+		}
+		return nil
+	}
+
+	client := NewJulesClient(cfg)
+	requestOptions, err := orchestrateRequestOptions(ctx, cfg, client, options.Source, options.Dir, "orchestrate deps-upgrade", options.ApprovalID)
+	if err != nil {
+		return err
+	}
+	var repo VCSRepo
+	var provider vcs.Provider
+	if !options.NoPR {
+		if repo, err = ResolveVCSRepo(ctx, cfg, options.Repo); err != nil {
+			return err
+		}
+		if provider, err = NewVCSProvider(cfg, repo); err != nil {
+			return err
+		}
+	}
+
+	parallel := max(options.Parallel, 1)
+	results := make([]DepsUpgradeResult, len(groups))
+	// applyMu applies one session at a time, since each adds and removes a
+	// worktree of the same repository.
+	var applyMu, outMu sync.Mutex
+	semaphore := make(chan struct{}, parallel)
+	var wg sync.WaitGroup
+	for i, group := range groups {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			semaphore <- struct{}{}
+			defer func() { <-semaphore }()
+			result := upgradeGroup(ctx, cfg, client, requestOptions, group, options, &applyMu)
+			if result.Error == "" && provider != nil {
+				cr, err := provider.CreateChangeRequest(ctx, repo.Path, vcs.NewChangeRequest{
+					Title:        depsCommitHeader(group),
+					Description:  depsDescription(group, result.SessionID, options.Checks),
+					SourceBranch: result.Branch,
+					TargetBranch: options.Base,
+				})
+				RecordAudit(cfg, AuditSourceCLI, AuditPRCreate, repo.Path, err, map[string]interface{}{
+					"provider": string(provider.Kind()),
+					"source":   result.Branch,
+					"target":   options.Base,
+					"workflow": "deps-upgrade",
+				})
+				if err != nil {
+					result.Error = fmt.Sprintf("pushed %s but failed to open a pull request: %v", result.Branch, err)
+				} else {
+					result.URL = cr.URL
+				}
+			}
+			results[i] = result
+			if !options.JSON {
+				outMu.Lock()
+				printDepsUpgradeResult(out, result, options.NoPR)
+				outMu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+
+	failed := 0
+	for _, result := range results {
+		if result.Error != "" {
+			failed++
+		}
+	}
+	if options.JSON {
+		if err := writeJSON(out, results); err != nil {
+			return err
+		}
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d of %d upgrade groups failed", failed, len(results))
+	}
+	return nil
+}
+
+// upgradeGroup has a session make a group's upgrades and commits them to
+// the group's branch when the checks pass on them.
+func upgradeGroup(ctx context.Context, cfg *config.Config, client *jules.Client, requestOptions julessessions.CreateSessionRequestOptions, group deps.Group, options DepsUpgradeOptions, applyMu *sync.Mutex) DepsUpgradeResult {
+	result := DepsUpgradeResult{Group: group}
+	requestOptions.Prompt = deps.Prompt(group)
+	requestOptions.Title = "Dependency upgrade: " + group.Name
+	session, err := runOrchestratedSession(ctx, cfg, client, requestOptions, map[string]interface{}{
+		"workflow": "deps-upgrade",
+		"group":    group.Name,
+	})
+	if session != nil {
+		result.SessionID = session.ID
+	}
+	if err != nil {
+		result.Error = err.Error()
+		return result
+	}
+
+	remote := options.Remote
+	if options.NoPR {
+		remote = ""
+	}
+	applyMu.Lock()
+	defer applyMu.Unlock()
+	applied, err := workspace.ApplySessionPatchesIsolated(ctx, client, session.ID, workspace.IsolatedApplyOptions{
+		Patch:         &workspace.PatchApplicationOptions{WorkingDir: options.Dir},
+		Checks:        options.Checks,
+		Branch:        group.Branch(),
+		CommitMessage: depsCommitHeader(group) + "\n\n" + depsDescription(group, session.ID, options.Checks),
+		Remote:        remote,
+	})
+	details := map[string]interface{}{"workflow": "deps-upgrade", "group": group.Name, "branch": group.Branch()}
+	if applied != nil {
+		details["commit"] = applied.Commit
+		details["pushed"] = applied.Pushed
+	}
+	RecordAudit(cfg, AuditSourceCLI, AuditPatchApply, session.ID, err, details)
+	if err != nil {
+		if applied != nil {
+			for _, check := range applied.Checks {
+				if !check.Success {
+					result.Error = "check failed: " + check.Command
+					return result
+				}
+			}
+		}
+		result.Error = fmt.Sprintf("failed to commit the upgrade: %v", err)
+		return result
+	}
+	result.Branch, result.Commit = group.Branch(), applied.Commit
+	return result
+}
+
+func depsCommitHeader(group deps.Group) string {
+	return "chore(deps): " + group.Title()
+}
+
+func depsDescription(group deps.Group, sessionID string, checks []string) string {
+	var b strings.Builder
+	b.WriteString(deps.Description(group))
+	fmt.Fprintf(&b, "\nMade by Jules session %s, prompted to read the changelogs of the versions skipped.", sessionID)
+	if len(checks) > 0 {
+		fmt.Fprintf(&b, " Validated with `%s`.", strings.Join(checks, "`, `"))
+	} else {
+		b.WriteString(" The project's build and tests passed.")
+	}
+	b.WriteString("\n")
+	return b.String()
+}
+
+func printDepsUpgradeResult(out io.Writer, result DepsUpgradeResult, noPR bool) {
+	switch {
+	case result.Error != "":
+		fmt.Fprintf(out, "❌ %s: %s\n", result.Group.Name, result.Error)
+	case noPR:
+		fmt.Fprintf(out, "✅ %s: committed to %s\n", result.Group.Name, result.Branch)
+	default:
+		fmt.Fprintf(out, "✅ %s: %s\n", result.Group.Name, result.URL)
+	}
+}
@@ -0,0 +1,275 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/SamyRai/go-jules"
+	"github.com/SamyRai/juleson/internal/config"
+	julessessions "github.com/SamyRai/juleson/internal/jules/sessions"
+	"github.com/SamyRai/juleson/internal/jules/workspace"
+	"github.com/SamyRai/juleson/internal/migrate"
+	"github.com/SamyRai/juleson/internal/policy"
+	"github.com/spf13/cobra"
+)
+
+// MigrateOptions configures `orchestrate migrate`.
+type MigrateOptions struct {
+	From   string
+	To     string
+	Dir    string
+	Source string
+	// StartBranch is the branch the first phase starts from; empty is the
+	// session default for Jules and HEAD locally.
+	StartBranch string
+	Remote      string
+	// Checks run between phases; empty runs migrate.DefaultChecks.
+	Checks []string
+	// Yes confirms continuing past risk points the policy asks to confirm.
+	Yes            bool
+	ApprovalID     string
+	RiskApprovalID string
+	DryRun         bool
+	JSON           bool
+}
+
+// MigratePhaseResult is the outcome of one phase.
+type MigratePhaseResult struct {
+	Phase     migrate.Phase `json:"phase"`
+	SessionID string        `json:"sessionId,omitempty"`
+	Branch    string        `json:"branch,omitempty"`
+	Commit    string        `json:"commit,omitempty"`
+	// Risks are the plan's and the changes' reasons to review the phase.
+	Risks []string `json:"risks,omitempty"`
+	Error string   `json:"error,omitempty"`
+}
+
+func newMigrateCommand(cfg *config.Config) *cobra.Command {
+	options := MigrateOptions{Dir: ".", Source: ".", Remote: "origin"}
+
+	cmd := &cobra.Command{
+		Use:   "migrate",
+		Short: "Have Jules migrate to a new language or framework version, one phase at a time",
+		Long: `Split a migration into phases, one per Go minor release or per major
+release of anything else, such as go1.21 → go1.22 → go1.23 or react@16 →
+react@17 → react@18, and run a Jules session for each in turn.
+
+Each phase's changes are applied in a temporary worktree on top of the
+previous phase and compiled there (go build and go vet for Go modules, the
+detected test command otherwise; --check replaces them). Changes that pass
+are committed to the branch juleson/migrate/<version> and pushed to --remote,
+so the next phase's session starts from them. The working tree is never
+changed.
+
+Phases are risk points when the release changes behavior (such as Go 1.22's
+per-iteration loop variables) or when the changes delete files or touch many
+of them. The migration then pauses before the next phase for the
+migration_risk policy, which asks for confirmation by default; --yes
+confirms, and an approve rule needs --risk-approval. Changes that add possible secrets stop the migration, as they
+block the session review. A stopped migration is resumed with --from set to
+the last phase done and --start-branch set to its branch.
+
+Sessions run without plan approval, which the policy may require an approval
+for. --dry-run prints the phases without creating sessions.`,
+		Example: `  juleson orchestrate migrate --from go1.21 --to go1.23
+  juleson orchestrate migrate --from react@16 --to react@18 --check "npm run build"
+  juleson orchestrate migrate --from go1.22 --to go1.23 --start-branch juleson/migrate/go1.22`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return RunMigrate(cmd.Context(), cfg, cmd.OutOrStdout(), options)
+		},
+	}
+	cmd.Flags().StringVar(&options.From, "from", "", "Current version, such as go1.21 or react@17")
+	cmd.Flags().StringVar(&options.To, "to", "", "Version to migrate to, such as go1.23 or react@18")
+	cmd.Flags().StringVar(&options.StartBranch, "start-branch", "", "Branch the first phase starts from, to resume a migration")
+	cmd.Flags().StringVar(&options.Remote, "remote", options.Remote, "Remote to push phase branches to")
+	cmd.Flags().StringArrayVar(&options.Checks, "check", nil, "Command run between phases (repeatable; default go build and go vet, or the detected test command)")
+	cmd.Flags().StringVar(&options.Source, "source", options.Source, "Jules source ID, or . for the current repository")
+	cmd.Flags().BoolVarP(&options.Yes, "yes", "y", false, "Continue past risk points the policy asks to confirm")
+	cmd.Flags().StringVar(&options.ApprovalID, "approval", "", "Approval ID for running sessions without plan approval, when policy requires one")
+	cmd.Flags().StringVar(&options.RiskApprovalID, "risk-approval", "", "Approval ID for continuing past a risk point, when policy requires one")
+	cmd.Flags().BoolVar(&options.DryRun, "dry-run", false, "Print the phases without creating sessions")
+	cmd.Flags().BoolVar(&options.JSON, "json", false, "Print the result as JSON")
+	_ = cmd.MarkFlagRequired("from")
+	_ = cmd.MarkFlagRequired("to")
+	return cmd
+}
+
+// RunMigrate runs the migrate workflow.
+func RunMigrate(ctx context.Context, cfg *config.Config, out io.Writer, options MigrateOptions) error {
+	plan, err := migrate.NewPlan(options.From, options.To)
+	if err != nil {
+		return err
+	}
+	if options.DryRun {
+		if options.JSON {
+			return writeJSON(out, plan)
+		}
+		printMigratePlan(out, plan)
+		return nil
+	}
+	if len(options.Checks) == 0 {
+		options.Checks = migrate.DefaultChecks(options.Dir)
+	}
+
+	client := NewJulesClient(cfg)
+	requestOptions, err := orchestrateRequestOptions(ctx, cfg, client, options.Source, options.Dir, "orchestrate migrate", options.ApprovalID)
+	if err != nil {
+		return err
+	}
+	repo := policy.RepoFromSource(requestOptions.Source)
+	branch := options.StartBranch
+	if branch != "" {
+		requestOptions.StartingBranch = branch
+	}
+	say := func(format string, args ...interface{}) {
+		if !options.JSON {
+			fmt.Fprintf(out, format, args...)
+		}
+	}
+
+	var results []MigratePhaseResult
+	finish := func(err error) error {
+		if options.JSON {
+			if jsonErr := writeJSON(out, results); jsonErr != nil {
+				return jsonErr
+			}
+		}
+		return err
+	}
+	for _, phase := range plan.Phases {
+		say("Phase %d/%d: %s → %s\n", phase.Number, len(plan.Phases), phase.From, phase.To)
+		result, err := runMigratePhase(ctx, cfg, client, plan, phase, requestOptions, branch, options)
+		results = append(results, result)
+		if err != nil {
+			if result.Branch != "" {
+				err = fmt.Errorf("%w\nPhase %d is committed to %s; review it, then resume with: juleson orchestrate migrate --from %s --to %s --start-branch %s",
+					err, phase.Number, result.Branch, phase.To, plan.To, result.Branch)
+			}
+			return finish(err)
+		}
+		say("  ✅ %s at %s (session %s)\n", result.Branch, shortCommit(result.Commit), result.SessionID)
+
+		if len(result.Risks) > 0 {
+			say("  ⚠️  Risk points:\n")
+			for _, risk := range result.Risks {
+				say("     - %s\n", risk)
+			}
+		}
+		// Risks are gated before the next phase builds on the changes; the
+		// last phase's are left to the review of its branch.
+		if len(result.Risks) > 0 && phase.Number < len(plan.Phases) {
+			err := EnforcePolicy(cfg, policy.Check{
+				Request: policy.Request{
+					Operation: policy.OpMigrationRisk,
+					Tool:      "orchestrate migrate",
+					Repo:      repo,
+					Target:    result.Branch,
+				},
+				Confirmed:  options.Yes,
+				ApprovalID: options.RiskApprovalID,
+			}, !options.JSON)
+			if err != nil {
+				return finish(fmt.Errorf("paused after phase %d: %w\nReview %s, then resume with: juleson orchestrate migrate --from %s --to %s --start-branch %s",
+					phase.Number, err, result.Branch, phase.To, plan.To, result.Branch))
+			}
+		}
+		branch = result.Branch
+		requestOptions.StartingBranch = branch
+	}
+	say("\n✅ Migrated from %s to %s on %s\n", plan.From, plan.To, branch)
+	return finish(nil)
+}
+
+// runMigratePhase has a session carry out a phase on top of base and
+// commits the changes to the phase's branch when the checks pass.
+func runMigratePhase(ctx context.Context, cfg *config.Config, client *jules.Client, plan *migrate.Plan, phase migrate.Phase, requestOptions julessessions.CreateSessionRequestOptions, base string, options MigrateOptions) (MigratePhaseResult, error) {
+	result := MigratePhaseResult{Phase: phase, Risks: append([]string(nil), phase.Risks...)}
+	requestOptions.Prompt = migrate.Prompt(plan, phase)
+	requestOptions.Title = fmt.Sprintf("Migrate to %s (phase %d/%d)", phase.To, phase.Number, len(plan.Phases))
+	session, err := runOrchestratedSession(ctx, cfg, client, requestOptions, map[string]interface{}{
+		"workflow": "migrate",
+		"phase":    phase.To,
+	})
+	if session != nil {
+		result.SessionID = session.ID
+	}
+	if err != nil {
+		result.Error = err.Error()
+		return result, err
+	}
+
+	changes, err := workspace.GetSessionChanges(ctx, client, session.ID)
+	if err != nil {
+		result.Error = err.Error()
+		return result, err
+	}
+	if changes.TotalPatches == 0 {
+		err := fmt.Errorf("session %s produced no changes for phase %d", session.ID, phase.Number)
+		result.Error = err.Error()
+		return result, err
+	}
+	blockers, risks := migrate.Review(changes)
+	result.Risks = append(result.Risks, risks...)
+	if len(blockers) > 0 {
+		err := fmt.Errorf("phase %d is blocked: %s", phase.Number, strings.Join(blockers, "; "))
+		result.Error = err.Error()
+		return result, err
+	}
+
+	applied, err := workspace.ApplySessionPatchesIsolated(ctx, client, session.ID, workspace.IsolatedApplyOptions{
+		Patch:         &workspace.PatchApplicationOptions{WorkingDir: options.Dir},
+		Checks:        options.Checks,
+		Base:          base,
+		Branch:        phase.Branch(),
+		CommitMessage: migrateCommitMessage(plan, phase, session.ID),
+		Remote:        options.Remote,
+	})
+	details := map[string]interface{}{"workflow": "migrate", "phase": phase.To, "branch": phase.Branch()}
+	if applied != nil {
+		details["commit"] = applied.Commit
+		details["pushed"] = applied.Pushed
+	}
+	RecordAudit(cfg, AuditSourceCLI, AuditPatchApply, session.ID, err, details)
+	if applied != nil && applied.Commit != "" {
+		result.Branch, result.Commit = phase.Branch(), applied.Commit
+	}
+	if err != nil {
+		if applied != nil {
+			for _, check := range applied.Checks {
+				if !check.Success {
+					err = fmt.Errorf("phase %d failed %s:\n%s", phase.Number, check.Command, strings.TrimSpace(check.Summary))
+					break
+				}
+			}
+		}
+		result.Error = err.Error()
+		return result, err
+	}
+	return result, nil
+}
+
+func migrateCommitMessage(plan *migrate.Plan, phase migrate.Phase, sessionID string) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "chore: migrate from %s to %s\n\n", phase.From, phase.To)
+	fmt.Fprintf(&b, "Phase %d of %d of the migration from %s to %s, made by Jules session %s.\n", phase.Number, len(plan.Phases), plan.From, plan.To, sessionID)
+	return b.String()
+}
+
+func printMigratePlan(out io.Writer, plan *migrate.Plan) {
+	fmt.Fprintf(out, "Migration from %s to %s in %d phase(s):\n", plan.From, plan.To, len(plan.Phases))
+	for _, phase := range plan.Phases {
+		fmt.Fprintf(out, "\n%d. %s → %s (branch %s)\n", phase.Number, phase.From, phase.To, phase.Branch())
+		if phase.ReleaseNotes != "" {
+			fmt.Fprintf(out, "   Release notes: %s\n", phase.ReleaseNotes)
+		}
+		for _, note := range phase.Notes {
+			fmt.Fprintf(out, "   - %s\n", note)
+		}
+		for _, risk := range phase.Risks {
+			fmt.Fprintf(out, "   ⚠️  %s\n", risk)
+		}
+	}
+}
@@ -0,0 +1,195 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+
+	"github.com/SamyRai/juleson/internal/config"
+	"github.com/SamyRai/juleson/internal/intelligence"
+	"github.com/SamyRai/juleson/internal/testgen"
+	"github.com/SamyRai/juleson/pkg/git"
+)
+
+// ProjectRun is the outcome of a workflow in one sub-project of a monorepo.
+type ProjectRun struct {
+	Project intelligence.SubProject `json:"project"`
+	Result  *testgen.Result         `json:"result,omitempty"`
+	Error   string                  `json:"error,omitempty"`
+}
+
+// MonorepoChanges returns the files changed since base, including
+// uncommitted and untracked ones, as slash-separated paths relative to the
+// monorepo root. Files outside the root are left out.
+func MonorepoChanges(ctx context.Context, m *intelligence.Monorepo, base string) ([]string, error) {
+	repo, err := git.Open(ctx, m.Root)
+	if err != nil {
+		return nil, err
+	}
+	files, err := repo.ChangedFiles(ctx, base)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list changes since %s: %w", base, err)
+	}
+	root, err := filepath.EvalSymlinks(m.Root)
+	if err != nil {
+		return nil, err
+	}
+	repoRoot, err := filepath.EvalSymlinks(repo.Root)
+	if err != nil {
+		return nil, err
+	}
+	prefix, err := filepath.Rel(repoRoot, root)
+	if err != nil {
+		return nil, err
+	}
+	prefix = filepath.ToSlash(prefix)
+	var changed []string
+	for _, file := range files {
+		if prefix == "." {
+			changed = append(changed, file)
+		} else if strings.HasPrefix(file, prefix+"/") {
+			changed = append(changed, strings.TrimPrefix(file, prefix+"/"))
+		}
+	}
+	return changed, nil
+}
+
+// ScopePrompt confines a session prompt written for one sub-project to that
+// sub-project's directory of the monorepo.
+func ScopePrompt(prompt string, project intelligence.SubProject) string {
+	if project.Dir == "." {
+		return prompt
+	}
+	name := project.Dir
+	if project.Name != "" {
+		name = fmt.Sprintf("%s (%s)", project.Name, project.Dir)
+	}
+	return fmt.Sprintf("This repository is a monorepo. The task below is about the sub-project %s only: "+
+		"paths in it are relative to the directory %s, commands run there, and no file outside it may change.\n\n%s",
+		name, project.Dir, prompt)
+}
+
+// monorepoProjects detects the sub-projects of kind in the monorepo at dir,
+// only those affected by changes since since when it is set.
+func monorepoProjects(ctx context.Context, dir, kind, since string) (*intelligence.Monorepo, []intelligence.SubProject, error) {
+	m, err := intelligence.DetectMonorepo(ctx, dir)
+	if err != nil {
+		return nil, nil, fmt.Errorf("monorepo detection failed: %w", err)
+	}
+	projects := m.Projects
+	if since != "" {
+		changed, err := MonorepoChanges(ctx, m, since)
+		if err != nil {
+			return nil, nil, err
+		}
+		projects = m.Affected(changed)
+	}
+	var selected []intelligence.SubProject
+	for _, project := range projects {
+		if project.Kind == kind {
+			selected = append(selected, project)
+		}
+	}
+	return m, selected, nil
+}
+
+// runTestCoverageProjects runs the test-coverage workflow in each Go module
+// of the monorepo at options.Dir and reports them together.
+func runTestCoverageProjects(ctx context.Context, cfg *config.Config, out io.Writer, options TestCoverageOptions) error {
+	m, projects, err := monorepoProjects(ctx, options.Dir, intelligence.ProjectGo, options.Since)
+	if err != nil {
+		return err
+	}
+	if len(projects) == 0 {
+		if options.JSON {
+			return writeJSON(out, []ProjectRun{})
+		}
+		if options.Since != "" {
+			fmt.Fprintf(out, "No Go module is affected by changes since %s.\n", options.Since)
+		} else {
+			fmt.Fprintln(out, "No Go module found.")
+		}
+		return nil
+	}
+
+	var runs []ProjectRun
+	for _, project := range projects {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		projectOptions := options
+		projectOptions.Projects, projectOptions.Since = false, ""
+		projectOptions.Dir = filepath.Join(m.Root, filepath.FromSlash(project.Dir))
+		if m.IsMonorepo() {
+			projectOptions.project = &project
+		}
+		if !options.JSON {
+			fmt.Fprintf(out, "\n== %s (%s) ==\n", project.Dir, project.Name)
+		}
+		if options.DryRun {
+			// The dry run prints its own report for each module.
+			if err := RunTestCoverage(ctx, cfg, out, projectOptions); err != nil {
+				return err
+			}
+			continue
+		}
+		var progress func(string)
+		if !options.JSON {
+			progress = func(line string) { fmt.Fprintln(out, line) }
+		}
+		run := ProjectRun{Project: project}
+		var err error
+		run.Result, err = testCoverage(ctx, cfg, projectOptions, progress)
+		if err != nil {
+			run.Error = err.Error()
+		}
+		if !options.JSON {
+			printProjectCoverage(out, run)
+		}
+		runs = append(runs, run)
+	}
+	if options.DryRun {
+		return nil
+	}
+	if options.JSON {
+		return writeJSON(out, runs)
+	}
+	printCombinedCoverage(out, runs, options.Target)
+	return nil
+}
+
+func printProjectCoverage(out io.Writer, run ProjectRun) {
+	if run.Result != nil {
+		fmt.Fprintf(out, "%s: coverage %.1f%% → %.1f%% with %d session(s): %s\n",
+			run.Project.Dir, run.Result.Start*100, run.Result.Final*100, run.Result.Sessions, run.Result.Stopped)
+	}
+	if run.Error != "" {
+		fmt.Fprintf(out, "❌ %s: %s\n", run.Project.Dir, run.Error)
+	}
+}
+
+// printCombinedCoverage prints one line per module and the totals.
+func printCombinedCoverage(out io.Writer, runs []ProjectRun, target float64) {
+	fmt.Fprintf(out, "\nCombined report (target %.1f%%):\n", target)
+	fmt.Fprintf(out, "  %-30s %-8s %-8s %-8s %s\n", "MODULE", "START", "FINAL", "SESSIONS", "STOPPED")
+	sessions, reached := 0, 0
+	for _, run := range runs {
+		if run.Result == nil {
+			fmt.Fprintf(out, "  %-30s %-8s %-8s %-8s %s\n", run.Project.Dir, "-", "-", "-", run.Error)
+			continue
+		}
+		sessions += run.Result.Sessions
+		if run.Result.Reached() {
+			reached++
+		}
+		fmt.Fprintf(out, "  %-30s %-8s %-8s %-8d %s\n", run.Project.Dir,
+			fmt.Sprintf("%.1f%%", run.Result.Start*100), fmt.Sprintf("%.1f%%", run.Result.Final*100), run.Result.Sessions, run.Result.Stopped)
+	}
+	status := "✅"
+	if reached < len(runs) {
+		status = "⚠️ "
+	}
+	fmt.Fprintf(out, "\n%s %d of %d module(s) reached the target with %d session(s)\n", status, reached, len(runs), sessions)
+}
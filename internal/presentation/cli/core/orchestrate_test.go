@@ -0,0 +1,107 @@
+package core
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/SamyRai/juleson/internal/config"
+	"github.com/SamyRai/juleson/internal/intelligence"
+)
+
+func TestRunMigrateDryRun(t *testing.T) {
+	var out bytes.Buffer
+	err := RunMigrate(context.Background(), &config.Config{}, &out, MigrateOptions{From: "go1.21", To: "go1.23", DryRun: true})
+	if err != nil {
+		t.Fatalf("RunMigrate: %v", err)
+	}
+	for _, want := range []string{
+		"Migration from go1.21 to go1.23 in 2 phase(s)",
+		"1. go1.21 → go1.22 (branch juleson/migrate/go1.22)",
+		"2. go1.22 → go1.23 (branch juleson/migrate/go1.23)",
+		"⚠️  loop variables become per-iteration",
+	} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("output is missing %q:\n%s", want, out.String())
+		}
+	}
+
+	if err := RunMigrate(context.Background(), &config.Config{}, &out, MigrateOptions{From: "go1.23", To: "go1.21", DryRun: true}); err == nil {
+		t.Error("RunMigrate to an older version succeeded")
+	}
+}
+
+func TestRunTestCoverageRejectsTarget(t *testing.T) {
+	for _, target := range []float64{0, 120} {
+		err := RunTestCoverage(context.Background(), &config.Config{}, &bytes.Buffer{}, TestCoverageOptions{Target: target, DryRun: true})
+		if err == nil || !strings.Contains(err.Error(), "--target") {
+			t.Errorf("target %g: error = %v", target, err)
+		}
+	}
+}
+
+func TestMonorepoChangesAndScope(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+	ctx := context.Background()
+	repo := t.TempDir()
+	write := func(name, content string) {
+		t.Helper()
+		p := filepath.Join(repo, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(p, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	git := func(args ...string) {
+		t.Helper()
+		if out, err := exec.Command("git", append([]string{"-C", repo}, args...)...).CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v: %s", args, err, out)
+		}
+	}
+	write("README.md", "readme\n")
+	write("services/go.work", "go 1.25\n\nuse (\n\t./api\n\t./lib\n)\n")
+	write("services/api/go.mod", "module example.com/api\n\ngo 1.25\n\nrequire example.com/lib v0.0.0\n")
+	write("services/lib/go.mod", "module example.com/lib\n\ngo 1.25\n")
+	write("services/lib/lib.go", "package lib\n")
+	git("init", "--quiet")
+	git("add", ".")
+	git("-c", "user.name=Dev", "-c", "user.email=dev@example.com", "-c", "commit.gpgsign=false", "commit", "--quiet", "-m", "initial")
+	write("README.md", "changed\n")
+	write("services/lib/lib.go", "package lib\n\nfunc F() {}\n")
+
+	m, projects, err := monorepoProjects(ctx, filepath.Join(repo, "services"), intelligence.ProjectGo, "HEAD")
+	if err != nil {
+		t.Fatalf("monorepoProjects: %v", err)
+	}
+	changed, err := MonorepoChanges(ctx, m, "HEAD")
+	if err != nil {
+		t.Fatalf("MonorepoChanges: %v", err)
+	}
+	if strings.Join(changed, ",") != "lib/lib.go" {
+		t.Errorf("changed = %v, want only the file under the monorepo root", changed)
+	}
+	if len(projects) != 2 || projects[0].Dir != "api" || projects[1].Dir != "lib" {
+		t.Errorf("affected projects = %+v, want lib and its dependent api", projects)
+	}
+
+	prompt := ScopePrompt("Write tests.", projects[1])
+	if !strings.Contains(prompt, "sub-project example.com/lib (lib) only") || !strings.HasSuffix(prompt, "\n\nWrite tests.") {
+		t.Errorf("ScopePrompt() = %q", prompt)
+	}
+	if got := ScopePrompt("Write tests.", intelligence.SubProject{Dir: "."}); got != "Write tests." {
+		t.Errorf("ScopePrompt(root) = %q", got)
+	}
+
+	err = RunTestCoverage(ctx, &config.Config{}, &bytes.Buffer{}, TestCoverageOptions{Target: 80, Since: "HEAD", DryRun: true})
+	if err == nil || !strings.Contains(err.Error(), "--projects") {
+		t.Errorf("--since without --projects: error = %v", err)
+	}
+}
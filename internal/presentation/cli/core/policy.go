@@ -0,0 +1,174 @@
+package core
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"os/user"
+	"path/filepath"
+	"strings"
+
+	"github.com/SamyRai/juleson/internal/config"
+	ghclient "github.com/SamyRai/juleson/internal/github"
+	"github.com/SamyRai/juleson/internal/policy"
+	"github.com/SamyRai/juleson/internal/presentation/views/theme"
+	"github.com/mattn/go-isatty"
+	"github.com/spf13/cobra"
+)
+
+// ApprovalsPath returns the second-approver file from cfg, defaulting to
+// approvals.json in the user config directory.
+func ApprovalsPath(cfg *config.Config) (string, error) {
+	if cfg.Policy.ApprovalsPath != "" {
+		return cfg.Policy.ApprovalsPath, nil
+	}
+	configDir, err := os.UserConfigDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to locate user config directory: %w", err)
+	}
+	return filepath.Join(configDir, "juleson", "approvals.json"), nil
+}
+
+// EnforcePolicy checks a risky operation against the configured policy.
+// Confirmations are prompted for when stdin is a terminal; MCP callers pass
+// interactive=false and rely on check.Confirmed.
+func EnforcePolicy(cfg *config.Config, check policy.Check, interactive bool) error {
+	engine, err := policy.New(cfg.Policy.PolicyRules())
+	if err != nil {
+		return err
+	}
+	path, err := ApprovalsPath(cfg)
+	if err != nil {
+		return err
+	}
+	if check.Requester == "" {
+		check.Requester = currentUser()
+	}
+	if check.Prompt == nil && interactive && isatty.IsTerminal(os.Stdin.Fd()) {
+		check.Prompt = func(question string) (bool, error) {
+			return theme.Confirm(question, false)
+		}
+	}
+	return engine.Enforce(check, policy.NewApprovalStore(path))
+}
+
+// RepoForDir returns owner/name for the origin remote of the git repository
+// at dir, or an empty string when it cannot be determined.
+func RepoForDir(cfg *config.Config, dir string) string {
+	output, err := exec.Command("git", "-C", dir, "remote", "get-url", "origin").Output()
+	if err != nil {
+		return ""
+	}
+	repo, err := ghclient.NewGitRemoteParser(cfg.GitHub.EnterpriseHosts()...).ParseGitHubURL(strings.TrimSpace(string(output)))
+	if err != nil {
+		return ""
+	}
+	return repo.Owner + "/" + repo.Name
+}
+
+func currentUser() string {
+	if u, err := user.Current(); err == nil && u.Username != "" {
+		return u.Username
+	}
+	if name := os.Getenv("USER"); name != "" {
+		return name
+	}
+	return os.Getenv("USERNAME")
+}
+
+// NewPolicyCommand creates the policy command.
+func NewPolicyCommand(cfg *config.Config) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "policy",
+		Short: "Inspect policy decisions and approve risky operations",
+		Long: `Risky operations - forced patch apply, auto-approved plans, session deletion,
+cache cleaning, and container execution - are checked against policy.rules
+before they run. A rule allows or denies the operation, requires confirmation,
+or requires a second person to approve it with 'juleson policy approve'.
+policy.budget caps the sessions created per day and the files a patch may change.`,
+	}
+
+	cmd.AddCommand(newPolicyCheckCommand(cfg))
+	cmd.AddCommand(newPolicyPendingCommand(cfg))
+	cmd.AddCommand(newPolicyApproveCommand(cfg))
+	cmd.AddCommand(newPolicyBudgetCommand(cfg))
+
+	return cmd
+}
+
+func newPolicyCheckCommand(cfg *config.Config) *cobra.Command {
+	var tool, repo string
+
+	cmd := &cobra.Command{
+		Use:   "check [operation]",
+		Short: "Show the decision for each operation",
+		Args:  cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			engine, err := policy.New(cfg.Policy.PolicyRules())
+			if err != nil {
+				return err
+			}
+			operations := policy.Operations()
+			if len(args) == 1 {
+				operations = []policy.Operation{policy.Operation(args[0])}
+			}
+			for _, op := range operations {
+				decision := engine.Decide(policy.Request{Operation: op, Tool: tool, Repo: repo})
+				fmt.Fprintf(cmd.OutOrStdout(), "%-20s %s\n", op, decision)
+			}
+			return nil
+		},
+	}
+	cmd.Flags().StringVar(&tool, "tool", "", "MCP tool or CLI command performing the operation, e.g. delete_session or \"sessions apply\"")
+	cmd.Flags().StringVar(&repo, "repo", "", "Target repository as owner/name")
+
+	return cmd
+}
+
+func newPolicyPendingCommand(cfg *config.Config) *cobra.Command {
+	return &cobra.Command{
+		Use:   "pending",
+		Short: "List operations waiting for a second approver",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			path, err := ApprovalsPath(cfg)
+			if err != nil {
+				return err
+			}
+			pending, err := policy.NewApprovalStore(path).Pending()
+			if err != nil {
+				return err
+			}
+			out := cmd.OutOrStdout()
+			if len(pending) == 0 {
+				fmt.Fprintln(out, "No pending approvals.")
+				return nil
+			}
+			for _, approval := range pending {
+				fmt.Fprintf(out, "%s  %s  requested by %s at %s\n",
+					approval.ID, approval.Request, approval.Requester, approval.CreatedAt.Local().Format("2006-01-02 15:04"))
+			}
+			return nil
+		},
+	}
+}
+
+func newPolicyApproveCommand(cfg *config.Config) *cobra.Command {
+	return &cobra.Command{
+		Use:   "approve <approval-id>",
+		Short: "Approve an operation requested by someone else",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			path, err := ApprovalsPath(cfg)
+			if err != nil {
+				return err
+			}
+			approval, err := policy.NewApprovalStore(path).Approve(args[0], currentUser())
+			if err != nil {
+				return err
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "✅ Approved %s for %s. Retry with --approval %s.\n",
+				approval.Request, approval.Requester, approval.ID)
+			return nil
+		},
+	}
+}
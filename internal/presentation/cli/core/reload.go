@@ -0,0 +1,105 @@
+package core
+
+import (
+	"context"
+	"log/slog"
+	"strings"
+
+	"github.com/SamyRai/juleson/internal/config"
+	"github.com/SamyRai/juleson/internal/events"
+	"github.com/SamyRai/juleson/internal/logger"
+)
+
+// reloadableKeys lists the config key prefixes applied without a restart.
+// Log sinks and format require a restart because open files and connections
+// are owned by the running logger.
+var reloadableKeys = []string{
+	"log.level", "log.subsystems", "jules.debug_log", "jules.rate_limit",
+	"jules.retry_attempts", "jules.retry_backoff", "jules.retry_max_backoff", "jules.retry_jitter",
+	"circuit_breaker.",
+}
+
+// ApplyRuntimeSettings applies the settings that long-running processes can
+// change at runtime: the global and per-subsystem log levels and the Jules
+// API rate limit, retry policy, and circuit breaker.
+func ApplyRuntimeSettings(cfg *config.Config) {
+	level := slog.LevelInfo
+	if cfg.Jules.DebugLog {
+		level = slog.LevelDebug
+	}
+	if cfg.Log.Level != "" {
+		if parsed, err := logger.ParseLevel(cfg.Log.Level); err == nil {
+			level = parsed
+		}
+	}
+	logger.SetLevel(level)
+	if err := logger.SetSubsystemLevels(cfg.Log.Subsystems); err != nil {
+		slog.Warn("ignoring invalid subsystem log levels", "error", err)
+	}
+	applyJulesClientSettings(cfg)
+}
+
+// WatchConfig reloads cfg whenever its config file changes until ctx is
+// cancelled. Reloadable settings are copied into cfg and applied; other
+// changes are logged as requiring a restart. When coordinator is non-nil its
+// circuit breakers are reconfigured and an EventConfigReloaded event is
+// published.
+func WatchConfig(ctx context.Context, cfg *config.Config, coordinator *events.EventCoordinator) error {
+	watcher := config.NewWatcher(cfg, logger.For(logger.SubsystemConfig))
+	if watcher.Path() == "" {
+		slog.Debug("no config file loaded; hot reload disabled")
+		return nil
+	}
+
+	watcher.OnReload(func(_, next *config.Config, changed []string) {
+		applied, needRestart := splitReloadableKeys(changed)
+		if len(applied) > 0 {
+			cfg.Log = next.Log
+			cfg.Jules.DebugLog = next.Jules.DebugLog
+			cfg.Jules.RateLimit = next.Jules.RateLimit
+			cfg.Jules.RetryAttempts = next.Jules.RetryAttempts
+			cfg.Jules.RetryBackoff = next.Jules.RetryBackoff
+			cfg.Jules.RetryMaxBackoff = next.Jules.RetryMaxBackoff
+			cfg.Jules.RetryJitter = next.Jules.RetryJitter
+			cfg.CircuitBreaker = next.CircuitBreaker
+			ApplyRuntimeSettings(cfg)
+		}
+		if len(needRestart) > 0 {
+			slog.Warn("config changes require a restart to take effect", "keys", needRestart)
+		}
+		if coordinator == nil {
+			return
+		}
+
+		coordinator.ConfigureCircuitBreakers(cfg.CircuitBreaker.MaxFailures, cfg.CircuitBreaker.Timeout, cfg.CircuitBreaker.ResetTimeout)
+		event := events.NewEvent(events.EventConfigReloaded, "config-watcher", events.ConfigReloadedData{
+			Path:        watcher.Path(),
+			ChangedKeys: changed,
+			Applied:     applied,
+			NeedRestart: needRestart,
+		}).WithTopic(events.TopicConfig)
+		if err := coordinator.PublishEvent(ctx, event); err != nil {
+			slog.Warn("failed to publish config reload event", "error", err)
+		}
+	})
+
+	return watcher.Run(ctx)
+}
+
+func splitReloadableKeys(changed []string) (applied, needRestart []string) {
+	for _, key := range changed {
+		reloadable := false
+		for _, prefix := range reloadableKeys {
+			if key == prefix || (strings.HasSuffix(prefix, ".") && strings.HasPrefix(key, prefix)) {
+				reloadable = true
+				break
+			}
+		}
+		if reloadable {
+			applied = append(applied, key)
+		} else {
+			needRestart = append(needRestart, key)
+		}
+	}
+	return applied, needRestart
+}
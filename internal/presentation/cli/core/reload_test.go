@@ -0,0 +1,52 @@
+package core
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestSplitReloadableKeys(t *testing.T) {
+	applied, needRestart := splitReloadableKeys([]string{
+		"log.level",
+		"log.sinks",
+		"jules.rate_limit",
+		"jules.base_url",
+		"circuit_breaker.max_failures",
+		"jules.debug_log",
+	})
+
+	wantApplied := []string{"log.level", "jules.rate_limit", "circuit_breaker.max_failures", "jules.debug_log"}
+	if !reflect.DeepEqual(applied, wantApplied) {
+		t.Errorf("applied = %v, want %v", applied, wantApplied)
+	}
+	if !reflect.DeepEqual(needRestart, []string{"log.sinks", "jules.base_url"}) {
+		t.Errorf("needRestart = %v, want [log.sinks jules.base_url]", needRestart)
+	}
+}
+
+func TestRateLimiterSpacesRequests(t *testing.T) {
+	limiter := &rateLimiter{}
+	limiter.SetRate(20)
+
+	ctx := context.Background()
+	start := time.Now()
+	for i := 0; i < 3; i++ {
+		if err := limiter.Wait(ctx); err != nil {
+			t.Fatalf("Wait() error = %v", err)
+		}
+	}
+	if elapsed := time.Since(start); elapsed < 90*time.Millisecond {
+		t.Errorf("three requests at 20/s took %v, want at least 100ms", elapsed)
+	}
+
+	limiter.SetRate(0)
+	start = time.Now()
+	for i := 0; i < 100; i++ {
+		_ = limiter.Wait(ctx)
+	}
+	if elapsed := time.Since(start); elapsed > 50*time.Millisecond {
+		t.Errorf("unlimited waits took %v", elapsed)
+	}
+}
@@ -0,0 +1,121 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/SamyRai/go-jules"
+	"github.com/SamyRai/juleson/internal/config"
+	"github.com/SamyRai/juleson/internal/events"
+	julessessions "github.com/SamyRai/juleson/internal/jules/sessions"
+	"github.com/SamyRai/juleson/internal/report"
+)
+
+// LoadRunRecord returns a run from the run history, or the most recent run
+// when runID is empty.
+func LoadRunRecord(ctx context.Context, cfg *config.Config, runID string) (events.RunRecord, error) {
+	history, err := loadRunHistory(ctx, cfg)
+	if err != nil {
+		return events.RunRecord{}, err
+	}
+	if runID == "" {
+		runs := history.Sorted()
+		if len(runs) == 0 {
+			return events.RunRecord{}, fmt.Errorf("no runs recorded")
+		}
+		return runs[0], nil
+	}
+	run, ok := history[runID]
+	if !ok {
+		return events.RunRecord{}, fmt.Errorf("no run %s in the history", runID)
+	}
+	return run, nil
+}
+
+// BuildRunReport reports on a run. With a client, each session's latest
+// plan, changes, and review findings are fetched from Jules; a session that
+// cannot be reviewed is reported without them. Decisions come from the audit
+// log.
+func BuildRunReport(ctx context.Context, cfg *config.Config, client *jules.Client, run events.RunRecord) *report.Report {
+	r := &report.Report{Run: run, Generated: time.Now()}
+	sessionIDs := make([]string, 0, len(run.Sessions))
+	for _, recorded := range run.Sessions {
+		session := report.Session{ID: recorded.ID, Task: recorded.Task, State: recorded.State, URL: recorded.URL}
+		if client != nil {
+			reviewSessionForReport(ctx, client, &session)
+		}
+		r.Sessions = append(r.Sessions, session)
+		sessionIDs = append(sessionIDs, recorded.ID)
+	}
+	r.Decisions = runDecisions(cfg, run, sessionIDs)
+	return r
+}
+
+func reviewSessionForReport(ctx context.Context, client *jules.Client, session *report.Session) {
+	review, err := julessessions.BuildSessionReview(ctx, client, julessessions.ReviewRequest{SessionID: session.ID})
+	if err != nil {
+		session.Error = err.Error()
+		return
+	}
+	if review.Session.State != "" {
+		session.State = string(review.Session.State)
+	}
+	if session.URL == "" {
+		session.URL = review.Session.URL
+	}
+	if review.LatestPlan != nil {
+		for _, step := range review.LatestPlan.Steps {
+			session.Plan = append(session.Plan, step.Title)
+		}
+	}
+	for _, output := range review.Outputs {
+		if output.PullRequest != nil && output.PullRequest.URL != "" {
+			session.PullRequests = append(session.PullRequests, output.PullRequest.URL)
+		}
+	}
+
+	preview := review.PatchPreview
+	session.Files = preview.Files
+	session.Findings = append(session.Findings, preview.Warnings...)
+	for _, finding := range preview.SecretFindings {
+		session.Findings = append(session.Findings, fmt.Sprintf("possible secret (%s) in %s:%d", finding.Description, finding.File, finding.Line))
+	}
+	if len(preview.BaseCommitMismatches) > 0 {
+		session.Findings = append(session.Findings, "patch base commit does not match the local HEAD")
+	}
+	if preview.Error != "" {
+		session.Findings = append(session.Findings, "patch preview failed: "+preview.Error)
+	}
+}
+
+// runDecisions returns the audited operations on a run's sessions since the
+// run started, other than their creation.
+func runDecisions(cfg *config.Config, run events.RunRecord, sessionIDs []string) []report.Decision {
+	if !cfg.Audit.Enabled || len(sessionIDs) == 0 {
+		return nil
+	}
+	entries, err := loadAuditEntries(cfg, "", "")
+	if err != nil {
+		return nil
+	}
+	var decisions []report.Decision
+	for _, entry := range entries {
+		if entry.Action == AuditSessionCreate || entry.Time.Before(run.Started) || !slices.Contains(sessionIDs, entry.Target) {
+			continue
+		}
+		outcome := "ok"
+		if !entry.Success {
+			outcome = "failed: " + entry.Error
+		}
+		decisions = append(decisions, report.Decision{Time: entry.Time, Session: entry.Target, Action: entry.Action, Outcome: outcome})
+	}
+	return decisions
+}
+
+// WriteRunReport writes a report on a run to path, as HTML for .html and
+// .htm and as Markdown otherwise.
+func WriteRunReport(ctx context.Context, cfg *config.Config, client *jules.Client, run events.RunRecord, path string) error {
+	return BuildRunReport(ctx, cfg, client, run).WriteFile(path)
+}
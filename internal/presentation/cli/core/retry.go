@@ -0,0 +1,339 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"net"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/SamyRai/juleson/internal/config"
+	"github.com/SamyRai/juleson/internal/events"
+	julessessions "github.com/SamyRai/juleson/internal/jules/sessions"
+	"github.com/SamyRai/juleson/internal/logger"
+)
+
+// RetryPolicy controls how failed Jules API requests are retried.
+type RetryPolicy struct {
+	// Attempts is the number of retries after the first request.
+	Attempts int
+	// Backoff is the delay before the first retry; each retry doubles it.
+	Backoff time.Duration
+	// MaxBackoff caps a computed delay and a Retry-After header, which is
+	// otherwise capped at maxRetryAfter.
+	MaxBackoff time.Duration
+	// Jitter shortens each computed delay by a random fraction up to this
+	// value, from 0 to 1.
+	Jitter float64
+}
+
+// RetryPolicyFromConfig returns the retry policy configured under jules.
+func RetryPolicyFromConfig(cfg *config.Config) RetryPolicy {
+	return RetryPolicy{
+		Attempts:   cfg.Jules.RetryAttempts,
+		Backoff:    cfg.Jules.RetryBackoff,
+		MaxBackoff: cfg.Jules.RetryMaxBackoff,
+		Jitter:     cfg.Jules.RetryJitter,
+	}
+}
+
+// delay returns the wait before retry number attempt, counting from 1.
+func (p RetryPolicy) delay(attempt int, jitter float64) time.Duration {
+	backoff := p.Backoff
+	if backoff <= 0 {
+		backoff = time.Second
+	}
+	delay := backoff << min(attempt-1, 30)
+	if delay <= 0 || (p.MaxBackoff > 0 && delay > p.MaxBackoff) {
+		delay = p.MaxBackoff
+	}
+	if p.Jitter > 0 {
+		delay -= time.Duration(float64(delay) * min(p.Jitter, 1) * jitter)
+	}
+	return delay
+}
+
+type retryPolicyKey struct{}
+
+// WithRetryPolicy overrides the retry policy for Jules API calls made with
+// ctx. A zero policy disables retries.
+func WithRetryPolicy(ctx context.Context, policy RetryPolicy) context.Context {
+	return context.WithValue(ctx, retryPolicyKey{}, policy)
+}
+
+// maxRetryAfter caps a Retry-After header when the policy has no
+// MaxBackoff, so a broken server cannot stall a command for hours.
+const maxRetryAfter = time.Minute
+
+// RetryReason classifies a failed request that may succeed when retried.
+type RetryReason string
+
+// Retry reasons.
+const (
+	// RetryRateLimited is an HTTP 429 response.
+	RetryRateLimited RetryReason = "rate_limited"
+	// RetryServerError is an HTTP 5xx response other than 501.
+	RetryServerError RetryReason = "server_error"
+	// RetryNetwork is a request that failed without a response.
+	RetryNetwork RetryReason = "network"
+)
+
+// classifyRetry returns why req failed, if it failed with a rate limit, a
+// server error, or a network error, and whether it may be retried. A
+// request that is not idempotent, such as creating a session, may have
+// been processed by a server that then failed or timed out, so it is only
+// retried when rate limited or when it was never sent.
+func classifyRetry(req *http.Request, resp *http.Response, err error) (RetryReason, bool) {
+	switch {
+	case err != nil:
+		if req.Context().Err() != nil {
+			return "", false
+		}
+		return RetryNetwork, idempotent(req.Method) || notSent(err)
+	case resp.StatusCode == http.StatusTooManyRequests:
+		return RetryRateLimited, true
+	case resp.StatusCode >= 500 && resp.StatusCode != http.StatusNotImplemented:
+		return RetryServerError, idempotent(req.Method)
+	default:
+		return "", false
+	}
+}
+
+// idempotent reports whether sending a request of method twice has the
+// effect of sending it once.
+func idempotent(method string) bool {
+	switch method {
+	case "", http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodPut, http.MethodDelete, http.MethodTrace:
+		return true
+	default:
+		return false
+	}
+}
+
+// notSent reports whether err failed a request before any of it reached
+// the server, while connecting.
+func notSent(err error) bool {
+	var opErr *net.OpError
+	return errors.As(err, &opErr) && opErr.Op == "dial"
+}
+
+// retryAfter parses a Retry-After header in seconds or as an HTTP date,
+// capped at limit.
+func retryAfter(resp *http.Response, now time.Time, limit time.Duration) time.Duration {
+	wait := parseRetryAfter(resp, now)
+	if limit <= 0 {
+		limit = maxRetryAfter
+	}
+	return min(wait, limit)
+}
+
+func parseRetryAfter(resp *http.Response, now time.Time) time.Duration {
+	if resp == nil {
+		return 0
+	}
+	value := resp.Header.Get("Retry-After")
+	if value == "" {
+		return 0
+	}
+	if seconds, err := strconv.Atoi(value); err == nil && seconds > 0 {
+		return time.Duration(seconds) * time.Second
+	}
+	if at, err := http.ParseTime(value); err == nil && at.After(now) {
+		return at.Sub(now)
+	}
+	return 0
+}
+
+// julesRetryPolicy and julesBreaker are shared by every Jules client in the
+// process, like julesRateLimiter, so config reloads apply to all of them.
+var (
+	julesRetryMu     sync.RWMutex
+	julesRetryPolicy = RetryPolicy{Attempts: 3, Backoff: time.Second, MaxBackoff: 30 * time.Second, Jitter: 0.2}
+	// julesBreaker is created on first use so it logs through the
+	// configured logger.
+	julesBreaker = sync.OnceValue(func() *events.CircuitBreaker {
+		config := events.DefaultCircuitBreakerConfig("jules-api")
+		config.OnStateChange = func(previous, state events.CircuitState) {
+			notifyBreakerStateChange(config.Name, previous, state)
+		}
+		return events.NewCircuitBreaker(config, logger.For(logger.SubsystemJules))
+	})
+)
+
+func setJulesRetryPolicy(policy RetryPolicy) {
+	julesRetryMu.Lock()
+	defer julesRetryMu.Unlock()
+	julesRetryPolicy = policy
+}
+
+func currentJulesRetryPolicy() RetryPolicy {
+	julesRetryMu.RLock()
+	defer julesRetryMu.RUnlock()
+	return julesRetryPolicy
+}
+
+// JulesCircuitBreaker returns the circuit breaker guarding Jules API calls.
+// It opens after repeated server errors or network failures that outlast
+// their retries.
+func JulesCircuitBreaker() *events.CircuitBreaker {
+	return julesBreaker()
+}
+
+// errServiceFailure tells the circuit breaker that a request failed with a
+// server or network error after its last retry.
+var errServiceFailure = errors.New("jules API request failed after retries")
+
+// retryTransport retries Jules API requests that failed with a rate limit,
+// or idempotent ones that failed with a server or network error, and reports server and network
+// failures that outlast their retries to a circuit breaker. Each attempt
+// gets its own timeout.
+type retryTransport struct {
+	next    http.RoundTripper
+	timeout time.Duration
+	policy  func() RetryPolicy
+	breaker *events.CircuitBreaker
+	sleep   func(ctx context.Context, d time.Duration) error
+	jitter  func() float64
+}
+
+func newRetryTransport(next http.RoundTripper, timeout time.Duration) *retryTransport {
+	return &retryTransport{
+		next:    next,
+		timeout: timeout,
+		policy:  currentJulesRetryPolicy,
+		breaker: julesBreaker(),
+		sleep:   sleepContext,
+		jitter:  rand.Float64,
+	}
+}
+
+func (t *retryTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	policy := t.policy()
+	if override, ok := req.Context().Value(retryPolicyKey{}).(RetryPolicy); ok {
+		policy = override
+	}
+
+	var (
+		resp *http.Response
+		err  error
+		ran  bool
+	)
+	breakerErr := t.breaker.Execute(req.Context(), func(context.Context) error {
+		ran = true
+		var reason RetryReason
+		resp, reason, err = t.roundTrip(req, policy)
+		if reason == RetryServerError || reason == RetryNetwork {
+			return errServiceFailure
+		}
+		return nil
+	})
+	if !ran {
+		return nil, fmt.Errorf("%w: %w", julessessions.ErrUnavailable, breakerErr)
+	}
+	return resp, err
+}
+
+// roundTrip sends req until it succeeds, fails for good, or runs out of
+// retries. The reason is set when the final attempt failed with a rate
+// limit, a server error, or a network error.
+func (t *retryTransport) roundTrip(req *http.Request, policy RetryPolicy) (*http.Response, RetryReason, error) {
+	ctx := req.Context()
+	log := logger.For(logger.SubsystemJules)
+
+	for attempt := 0; ; attempt++ {
+		resp, err := t.attempt(req, attempt)
+
+		reason, retryable := classifyRetry(req, resp, err)
+		if !retryable {
+			return resp, reason, err
+		}
+		if attempt >= policy.Attempts || (req.Body != nil && req.GetBody == nil) {
+			return resp, reason, err
+		}
+
+		delay := policy.delay(attempt+1, t.jitter())
+		if wait := retryAfter(resp, time.Now(), policy.MaxBackoff); wait > 0 {
+			delay = wait
+		}
+		if resp != nil {
+			_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+			resp.Body.Close()
+		}
+		log.Debug("retrying Jules API request",
+			"method", req.Method,
+			"path", req.URL.Path,
+			"reason", reason,
+			"attempt", attempt+1,
+			"delay", delay)
+		if err := t.sleep(ctx, delay); err != nil {
+			return nil, "", err
+		}
+	}
+}
+
+// attempt sends one attempt of req, bounded by the per-attempt timeout
+// until its response body is closed.
+func (t *retryTransport) attempt(req *http.Request, attempt int) (*http.Response, error) {
+	attemptReq, err := rewind(req, attempt)
+	if err != nil {
+		return nil, err
+	}
+	if t.timeout <= 0 {
+		return t.next.RoundTrip(attemptReq)
+	}
+
+	ctx, cancel := context.WithTimeout(req.Context(), t.timeout)
+	resp, err := t.next.RoundTrip(attemptReq.WithContext(ctx))
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+	resp.Body = &cancelOnClose{ReadCloser: resp.Body, cancel: cancel}
+	return resp, nil
+}
+
+// cancelOnClose releases an attempt's timeout when its body is closed.
+type cancelOnClose struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (b *cancelOnClose) Close() error {
+	err := b.ReadCloser.Close()
+	b.cancel()
+	return err
+}
+
+// rewind returns the request to send for an attempt, with a fresh body
+// after the first.
+func rewind(req *http.Request, attempt int) (*http.Request, error) {
+	if attempt == 0 || req.Body == nil || req.GetBody == nil {
+		return req, nil
+	}
+	body, err := req.GetBody()
+	if err != nil {
+		return nil, fmt.Errorf("failed to rewind request body: %w", err)
+	}
+	clone := req.Clone(req.Context())
+	clone.Body = body
+	return clone, nil
+}
+
+func sleepContext(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return ctx.Err()
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
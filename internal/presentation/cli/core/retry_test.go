@@ -0,0 +1,206 @@
+package core
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/SamyRai/juleson/internal/events"
+)
+
+func newTestRetryTransport(policy RetryPolicy, slept *[]time.Duration) *retryTransport {
+	return &retryTransport{
+		next:    http.DefaultTransport,
+		policy:  func() RetryPolicy { return policy },
+		breaker: events.NewCircuitBreaker(&events.CircuitBreakerConfig{Name: "test", MaxFailures: 1, ResetTimeout: time.Hour}, nil),
+		sleep: func(_ context.Context, d time.Duration) error {
+			*slept = append(*slept, d)
+			return nil
+		},
+		jitter: func() float64 { return 0 },
+	}
+}
+
+func TestRetryTransportBacksOffOnServerErrors(t *testing.T) {
+	var hits atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if string(body) != "payload" {
+			t.Errorf("attempt %d body = %q", hits.Load()+1, body)
+		}
+		if hits.Add(1) < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	var slept []time.Duration
+	client := &http.Client{Transport: newTestRetryTransport(RetryPolicy{Attempts: 3, Backoff: 10 * time.Millisecond}, &slept)}
+	req, _ := http.NewRequest(http.MethodPut, server.URL, strings.NewReader("payload"))
+	resp, err := client.Do(req)
+	if err != nil {
+		t.Fatalf("Do() error = %v", err)
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK || hits.Load() != 3 {
+		t.Errorf("status = %d after %d requests, want 200 after 3", resp.StatusCode, hits.Load())
+	}
+	if want := []time.Duration{10 * time.Millisecond, 20 * time.Millisecond}; !reflect.DeepEqual(slept, want) {
+		t.Errorf("backoff = %v, want %v", slept, want)
+	}
+}
+
+func TestRetryTransportRetriesPostOnlyWhenUnprocessed(t *testing.T) {
+	var hits atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if hits.Add(1) == 2 {
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	// post sends a POST through a transport of its own, since failures
+	// open the test breaker.
+	post := func(url string) (*http.Response, []time.Duration, error) {
+		var slept []time.Duration
+		client := &http.Client{Transport: newTestRetryTransport(RetryPolicy{Attempts: 3, Backoff: time.Millisecond}, &slept)}
+		resp, err := client.Post(url, "application/json", strings.NewReader("{}"))
+		if err == nil {
+			resp.Body.Close()
+		}
+		return resp, slept, err
+	}
+
+	resp, _, err := post(server.URL)
+	if err != nil {
+		t.Fatalf("Post() error = %v", err)
+	}
+	if resp.StatusCode != http.StatusServiceUnavailable || hits.Load() != 1 {
+		t.Errorf("status = %d after %d requests, want a 503 that creates no duplicate", resp.StatusCode, hits.Load())
+	}
+
+	if _, _, err := post(server.URL); err != nil {
+		t.Fatalf("Post() error = %v", err)
+	}
+	if hits.Load() != 3 {
+		t.Errorf("a rate-limited POST was sent %d times, want 2", hits.Load()-1)
+	}
+
+	closed := httptest.NewServer(http.NotFoundHandler())
+	closed.Close()
+	_, slept, err := post(closed.URL)
+	if err == nil {
+		t.Fatal("Post() to a closed server succeeded")
+	}
+	if len(slept) != 3 {
+		t.Errorf("a POST that could not connect was retried %d times, want 3", len(slept))
+	}
+}
+
+func TestRetryTransportHonorsRetryAfterAndOverrides(t *testing.T) {
+	var hits atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+		w.Header().Set("Retry-After", "7")
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	defer server.Close()
+
+	var slept []time.Duration
+	transport := newTestRetryTransport(RetryPolicy{Attempts: 1, Backoff: time.Millisecond}, &slept)
+	client := &http.Client{Transport: transport}
+
+	resp, err := client.Get(server.URL)
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusTooManyRequests || hits.Load() != 2 {
+		t.Errorf("status = %d after %d requests, want 429 after 2", resp.StatusCode, hits.Load())
+	}
+	if !reflect.DeepEqual(slept, []time.Duration{7 * time.Second}) {
+		t.Errorf("backoff = %v, want Retry-After of 7s", slept)
+	}
+	if state := transport.breaker.GetState(); state != events.StateClosed {
+		t.Errorf("rate limits opened the circuit breaker: %s", state)
+	}
+
+	slept = nil
+	server.Config.Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+		w.Header().Set("Retry-After", "86400")
+		w.WriteHeader(http.StatusTooManyRequests)
+	})
+	transport.policy = func() RetryPolicy { return RetryPolicy{Attempts: 1, MaxBackoff: 30 * time.Second} }
+	resp, err = client.Get(server.URL)
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	resp.Body.Close()
+	if !reflect.DeepEqual(slept, []time.Duration{30 * time.Second}) {
+		t.Errorf("backoff = %v, want Retry-After capped at 30s", slept)
+	}
+
+	req, _ := http.NewRequestWithContext(WithRetryPolicy(context.Background(), RetryPolicy{}), http.MethodGet, server.URL, nil)
+	resp, err = client.Do(req)
+	if err != nil {
+		t.Fatalf("Do() error = %v", err)
+	}
+	resp.Body.Close()
+	if hits.Load() != 5 {
+		t.Errorf("override without retries sent %d requests, want 1", hits.Load()-4)
+	}
+}
+
+func TestRetryTransportOpensCircuitBreaker(t *testing.T) {
+	var hits atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer server.Close()
+
+	var slept []time.Duration
+	transport := newTestRetryTransport(RetryPolicy{Attempts: 1}, &slept)
+	client := &http.Client{Transport: transport}
+
+	resp, err := client.Get(server.URL)
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusBadGateway || transport.breaker.GetState() != events.StateOpen {
+		t.Fatalf("status = %d, breaker = %s; want 502 and an open breaker", resp.StatusCode, transport.breaker.GetState())
+	}
+
+	if _, err := client.Get(server.URL); err == nil || !strings.Contains(err.Error(), "jules API unavailable") {
+		t.Errorf("Get() with open breaker error = %v", err)
+	}
+	if hits.Load() != 2 {
+		t.Errorf("server received %d requests, want 2", hits.Load())
+	}
+}
+
+func TestRetryPolicyDelay(t *testing.T) {
+	policy := RetryPolicy{Backoff: time.Second, MaxBackoff: 5 * time.Second, Jitter: 0.5}
+	if got := policy.delay(2, 0); got != 2*time.Second {
+		t.Errorf("delay(2) = %v, want 2s", got)
+	}
+	if got := policy.delay(4, 0); got != 5*time.Second {
+		t.Errorf("delay(4) = %v, want the 5s cap", got)
+	}
+	if got := policy.delay(1, 1); got != 500*time.Millisecond {
+		t.Errorf("delay(1) with full jitter = %v, want 500ms", got)
+	}
+}
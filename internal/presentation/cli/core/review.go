@@ -0,0 +1,203 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"text/tabwriter"
+
+	"github.com/SamyRai/go-jules"
+	"github.com/SamyRai/juleson/internal/config"
+	julessessions "github.com/SamyRai/juleson/internal/jules/sessions"
+	"github.com/SamyRai/juleson/internal/policy"
+	"github.com/SamyRai/juleson/internal/presentation/tui/review"
+	"github.com/mattn/go-isatty"
+	"github.com/spf13/cobra"
+)
+
+// Messages sent to Jules when a queued session is decided without a plan
+// approval.
+const (
+	reviewRejectPlanMessage   = "I am rejecting this plan. Please propose a different approach."
+	reviewApproveReplyMessage = "Yes, please proceed."
+	reviewRejectReplyMessage  = "No, please do not proceed with that."
+)
+
+// NewReviewCommand creates the review command.
+func NewReviewCommand(cfg *config.Config) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "review",
+		Short: "Decide on items waiting for a human",
+	}
+	cmd.AddCommand(newReviewQueueCommand(cfg))
+	return cmd
+}
+
+func newReviewQueueCommand(cfg *config.Config) *cobra.Command {
+	var (
+		jsonMode      bool
+		rejectMessage string
+	)
+
+	cmd := &cobra.Command{
+		Use:   "queue",
+		Short: "List and decide plans, questions, and approvals waiting for a human",
+		Long: `Gather everything waiting for a human: Jules sessions whose plan awaits
+approval, Jules sessions waiting for an answer, and policy-gated operations
+waiting for a second approver.
+
+In a terminal the queue is interactive: move with the arrow keys or j and k,
+press a to approve and r to reject, and q to quit. Approving a plan approves
+it; rejecting it asks Jules for a different plan. For a question, approving
+and rejecting answer yes and no. Session decisions are recorded in the audit
+log. Otherwise, and with --json, the queue is printed.`,
+		Example: `  juleson review queue
+  juleson review queue --json`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+			client := NewJulesClient(cfg)
+			items, err := ReviewQueue(ctx, cfg, client)
+			if err != nil {
+				return err
+			}
+
+			if jsonMode {
+				return writeHistoryJSON(cmd.OutOrStdout(), items)
+			}
+			if len(items) == 0 {
+				fmt.Fprintln(cmd.OutOrStdout(), "Nothing is waiting for review.")
+				return nil
+			}
+			if !isatty.IsTerminal(os.Stdin.Fd()) || !isatty.IsTerminal(os.Stdout.Fd()) {
+				printReviewQueue(cmd.OutOrStdout(), items)
+				return nil
+			}
+
+			decided, err := review.Run(items, func(item review.Item, approve bool) error {
+				return DecideReviewItem(ctx, cfg, client, item, approve, rejectMessage)
+			})
+			if err != nil {
+				return err
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "Decided %d of %d item(s).\n", decided, len(items))
+			return nil
+		},
+	}
+	cmd.Flags().BoolVar(&jsonMode, "json", false, "Print the queue as JSON")
+	cmd.Flags().StringVar(&rejectMessage, "reject-message", reviewRejectPlanMessage, "Message sent to Jules when a plan is rejected")
+	return cmd
+}
+
+// ReviewQueue returns the items waiting for a human, oldest first: sessions
+// waiting for plan approval or for an answer, and pending policy approvals.
+func ReviewQueue(ctx context.Context, cfg *config.Config, client *jules.Client) ([]review.Item, error) {
+	items := []review.Item{}
+
+	sessions, err := client.Sessions().ListAll(ctx, 100, "")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list sessions: %w", julessessions.Classify(err))
+	}
+	for _, session := range sessions {
+		var kind review.Kind
+		switch {
+		case session.Archived:
+			continue
+		case session.State == jules.SessionStateAwaitingPlanApproval:
+			kind = review.KindPlan
+		case session.State == jules.SessionStateAwaitingUserFeedback:
+			kind = review.KindFeedback
+		default:
+			continue
+		}
+		title := session.Title
+		if title == "" {
+			title = session.Prompt
+		}
+		items = append(items, review.Item{
+			Kind:    kind,
+			ID:      session.ID,
+			Title:   title,
+			Detail:  session.Prompt,
+			URL:     session.URL,
+			Created: session.UpdateTime,
+		})
+	}
+
+	path, err := ApprovalsPath(cfg)
+	if err != nil {
+		return nil, err
+	}
+	pending, err := policy.NewApprovalStore(path).Pending()
+	if err != nil {
+		return nil, err
+	}
+	for _, approval := range pending {
+		items = append(items, review.Item{
+			Kind:    review.KindApproval,
+			ID:      approval.ID,
+			Title:   approval.Request.String(),
+			Detail:  "Requested by " + approval.Requester,
+			Created: approval.CreatedAt,
+		})
+	}
+
+	slices.SortStableFunc(items, func(a, b review.Item) int { return a.Created.Compare(b.Created) })
+	return items, nil
+}
+
+// DecideReviewItem approves or rejects a queued item. rejectMessage is sent to
+// Jules when a plan is rejected.
+func DecideReviewItem(ctx context.Context, cfg *config.Config, client *jules.Client, item review.Item, approve bool, rejectMessage string) error {
+	switch item.Kind {
+	case review.KindPlan:
+		if approve {
+			err := client.Sessions().ApprovePlan(ctx, item.ID)
+			RecordAudit(cfg, AuditSourceCLI, AuditSessionApprovePlan, item.ID, err, nil)
+			if err != nil {
+				return fmt.Errorf("failed to approve plan: %w", julessessions.Classify(err))
+			}
+			return nil
+		}
+		return sendReviewMessage(ctx, cfg, client, item.ID, rejectMessage)
+	case review.KindFeedback:
+		if approve {
+			return sendReviewMessage(ctx, cfg, client, item.ID, reviewApproveReplyMessage)
+		}
+		return sendReviewMessage(ctx, cfg, client, item.ID, reviewRejectReplyMessage)
+	case review.KindApproval:
+		path, err := ApprovalsPath(cfg)
+		if err != nil {
+			return err
+		}
+		store := policy.NewApprovalStore(path)
+		if approve {
+			_, err = store.Approve(item.ID, currentUser())
+		} else {
+			_, err = store.Reject(item.ID, currentUser())
+		}
+		return err
+	default:
+		return fmt.Errorf("unknown review item kind %q", item.Kind)
+	}
+}
+
+func sendReviewMessage(ctx context.Context, cfg *config.Config, client *jules.Client, sessionID, message string) error {
+	err := client.Sessions().SendMessage(ctx, sessionID, &jules.SendMessageRequest{Prompt: message})
+	RecordAudit(cfg, AuditSourceCLI, AuditSessionMessage, sessionID, err, map[string]interface{}{"review": true})
+	if err != nil {
+		return fmt.Errorf("failed to send message: %w", julessessions.Classify(err))
+	}
+	return nil
+}
+
+func printReviewQueue(w io.Writer, items []review.Item) {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "KIND\tID\tWAITING SINCE\tTITLE")
+	for _, item := range items {
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n", item.Kind, item.ID, item.Created.Local().Format("2006-01-02 15:04"), item.Title)
+	}
+	_ = tw.Flush()
+}
@@ -0,0 +1,57 @@
+package core
+
+import (
+	"context"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/SamyRai/go-jules"
+	"github.com/SamyRai/juleson/internal/config"
+	"github.com/SamyRai/juleson/internal/jules/julestest"
+	"github.com/SamyRai/juleson/internal/policy"
+	"github.com/SamyRai/juleson/internal/presentation/tui/review"
+)
+
+func TestReviewQueue(t *testing.T) {
+	server := julestest.NewServer()
+	defer server.Close()
+	client := server.Client()
+	server.AddSession(jules.Session{ID: "plan", Title: "Add tests", State: jules.SessionStateAwaitingPlanApproval})
+	server.AddSession(jules.Session{ID: "question", State: jules.SessionStateAwaitingUserFeedback, Prompt: "Fix the build"})
+	server.AddSession(jules.Session{ID: "busy", State: jules.SessionStateInProgress})
+
+	cfg := &config.Config{Policy: config.PolicyConfig{ApprovalsPath: filepath.Join(t.TempDir(), "approvals.json")}}
+	approval, err := policy.NewApprovalStore(cfg.Policy.ApprovalsPath).Request(policy.Request{Operation: policy.OpDeleteSession, Target: "old"}, "someone-else")
+	if err != nil {
+		t.Fatalf("Request: %v", err)
+	}
+
+	ctx := context.Background()
+	items, err := ReviewQueue(ctx, cfg, client)
+	if err != nil {
+		t.Fatalf("ReviewQueue: %v", err)
+	}
+	kinds := map[string]review.Kind{}
+	for _, item := range items {
+		kinds[item.ID] = item.Kind
+	}
+	if len(items) != 3 || kinds["plan"] != review.KindPlan || kinds["question"] != review.KindFeedback || kinds[approval.ID] != review.KindApproval {
+		t.Fatalf("items = %+v", items)
+	}
+	if !slices.IsSortedFunc(items, func(a, b review.Item) int { return a.Created.Compare(b.Created) }) {
+		t.Errorf("queue is not oldest first: %+v", items)
+	}
+
+	for _, item := range items {
+		if err := DecideReviewItem(ctx, cfg, client, item, item.Kind != review.KindApproval, reviewRejectPlanMessage); err != nil {
+			t.Errorf("DecideReviewItem(%s): %v", item.ID, err)
+		}
+	}
+	if session, _ := server.Session("plan"); session.State != jules.SessionStateInProgress {
+		t.Errorf("approved plan state = %s", session.State)
+	}
+	if pending, _ := policy.NewApprovalStore(cfg.Policy.ApprovalsPath).Pending(); len(pending) != 0 {
+		t.Errorf("rejected approval still pending: %+v", pending)
+	}
+}
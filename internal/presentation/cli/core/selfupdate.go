@@ -0,0 +1,197 @@
+package core
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"github.com/SamyRai/juleson/internal/config"
+	ghclient "github.com/SamyRai/juleson/internal/github"
+	"github.com/SamyRai/juleson/internal/selfupdate"
+	"github.com/spf13/cobra"
+	"golang.org/x/mod/semver"
+)
+
+// Repository that publishes Juleson releases.
+const (
+	releaseOwner = "SamyRai"
+	releaseRepo  = "juleson"
+)
+
+// NewSelfUpdateCommand creates the self-update command.
+func NewSelfUpdateCommand(cfg *config.Config) *cobra.Command {
+	var (
+		channelName string
+		checkOnly   bool
+		force       bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "self-update",
+		Short: "Update Juleson to the latest release",
+		Long: `Download the latest GitHub release for this platform and replace the running
+executable with it.
+
+The archive is checked against the release's checksums.txt. Builds made by the
+release workflow also verify the ed25519 signature of checksums.txt and refuse
+releases without one; other builds say so and skip that check. The stable channel installs published
+releases; edge also installs prereleases.`,
+		Example: `  juleson self-update
+  juleson self-update --check
+  juleson self-update --channel edge`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			channel, err := selfupdate.ParseChannel(channelName)
+			if err != nil {
+				return err
+			}
+			updater, err := newSelfUpdater(cfg.GitHub)
+			if err != nil {
+				return err
+			}
+			out := cmd.OutOrStdout()
+
+			candidate, err := updater.Check(cmd.Context(), channel, Version)
+			if err != nil {
+				return fmt.Errorf("failed to check for updates: %w", err)
+			}
+			if !candidate.Newer && !force {
+				if Version == "dev" || Version == "" {
+					fmt.Fprintf(out, "This is a development build; %s is the latest %s release. Use --force to install it.\n", candidate.Version(), channel)
+				} else {
+					fmt.Fprintf(out, "✅ Juleson %s is up to date (%s channel).\n", Version, channel)
+				}
+				return nil
+			}
+			if checkOnly {
+				fmt.Fprintf(out, "⬆️  Juleson %s is available (current: %s). Run 'juleson self-update' to install it.\n", candidate.Version(), Version)
+				return nil
+			}
+
+			executable, err := os.Executable()
+			if err != nil {
+				return fmt.Errorf("failed to locate the running executable: %w", err)
+			}
+			if resolved, err := filepath.EvalSymlinks(executable); err == nil {
+				executable = resolved
+			}
+
+			fmt.Fprintf(out, "⬇️  Downloading Juleson %s (%s)...\n", candidate.Version(), candidate.Asset.Name)
+			result, err := updater.Apply(cmd.Context(), candidate, executable)
+			if err != nil {
+				if errors.Is(err, os.ErrPermission) {
+					return fmt.Errorf("%w; rerun with permission to write %s", err, filepath.Dir(executable))
+				}
+				return err
+			}
+			if !result.SignatureVerified {
+				fmt.Fprintln(out, "⚠️  Checksum verified; the signature was not checked because this build has no release public key.")
+			}
+			fmt.Fprintf(out, "✅ Updated %s from %s to %s.\n", result.Executable, Version, result.Version)
+			return nil
+		},
+	}
+	cmd.Flags().StringVar(&channelName, "channel", string(selfupdate.ChannelStable), "Release channel: stable or edge")
+	cmd.Flags().BoolVar(&checkOnly, "check", false, "Only report whether an update is available")
+	cmd.Flags().BoolVar(&force, "force", false, "Install the latest release even when it is not newer")
+
+	return cmd
+}
+
+// newSelfUpdater creates an updater for Juleson's releases. The GitHub token
+// is used only when it is for github.com.
+func newSelfUpdater(cfg config.GitHubConfig) (*selfupdate.Updater, error) {
+	client := ghclient.NewPublicClient()
+	if cfg.Token != "" && cfg.BaseURL == "" {
+		client = ghclient.NewClient(cfg.Token, nil)
+	}
+	client.Client.UserAgent = UserAgent()
+	updater := &selfupdate.Updater{
+		Releases: client.Releases,
+		Owner:    releaseOwner,
+		Repo:     releaseRepo,
+	}
+	if UpdatePublicKey != "" {
+		key, err := selfupdate.ParsePublicKey(UpdatePublicKey)
+		if err != nil {
+			return nil, err
+		}
+		updater.PublicKey = key
+	}
+	return updater, nil
+}
+
+// UpdateCheckEnv disables the update check of 'juleson version' when set to
+// a true value.
+const UpdateCheckEnv = "JULESON_NO_UPDATE_CHECK"
+
+// updateCheckInterval is how long a release check is reused.
+const updateCheckInterval = 24 * time.Hour
+
+// updateCheck is the cached result of the last release check.
+type updateCheck struct {
+	CheckedAt time.Time `json:"checked_at"`
+	Latest    string    `json:"latest"`
+}
+
+// UpdateHint returns a line suggesting self-update when a newer stable
+// release exists, checking GitHub at most once a day. It returns an empty
+// string for development builds, in offline mode, when JULESON_NO_UPDATE_CHECK
+// is set, and when the check fails.
+func UpdateHint(ctx context.Context) string {
+	if !semver.IsValid(Version) || OfflineRequested(os.Args[1:]) {
+		return ""
+	}
+	if disabled, _ := strconv.ParseBool(os.Getenv(UpdateCheckEnv)); disabled {
+		return ""
+	}
+	path, err := updateCheckPath()
+	if err != nil {
+		return ""
+	}
+	latest := latestRelease(ctx, path, time.Now())
+	if latest == "" || semver.Compare(latest, Version) <= 0 {
+		return ""
+	}
+	return fmt.Sprintf("⬆️  Juleson %s is available; run 'juleson self-update' to install it.", latest)
+}
+
+func updateCheckPath() (string, error) {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "juleson", "update-check.json"), nil
+}
+
+// latestRelease returns the latest stable release, from the cache at path
+// when it is fresh.
+func latestRelease(ctx context.Context, path string, now time.Time) string {
+	var cached updateCheck
+	if data, err := os.ReadFile(path); err == nil && json.Unmarshal(data, &cached) == nil &&
+		now.Sub(cached.CheckedAt) < updateCheckInterval {
+		return cached.Latest
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 2*time.Second)
+	defer cancel()
+	updater, err := newSelfUpdater(config.GitHubConfig{})
+	if err != nil {
+		return ""
+	}
+	candidate, err := updater.Check(ctx, selfupdate.ChannelStable, Version)
+	if err != nil {
+		return ""
+	}
+	if data, err := json.Marshal(updateCheck{CheckedAt: now, Latest: candidate.Version()}); err == nil {
+		if os.MkdirAll(filepath.Dir(path), 0o755) == nil {
+			_ = os.WriteFile(path, data, 0o644)
+		}
+	}
+	return candidate.Version()
+}
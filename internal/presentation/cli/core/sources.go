@@ -6,7 +6,6 @@ import (
 
 	"github.com/SamyRai/go-jules"
 	"github.com/SamyRai/juleson/internal/config"
-	"github.com/SamyRai/juleson/internal/logger"
 
 	"github.com/spf13/cobra"
 )
@@ -45,6 +44,7 @@ Examples:
 	listCmd.Flags().StringP("filter", "f", "", "Filter sources by exact name (e.g., 'name=sources/github/owner/repo')")
 
 	sourcesCmd.AddCommand(listCmd)
+	sourcesCmd.AddCommand(newSourcesConnectCommand(cfg))
 
 	// Get source
 	sourcesCmd.AddCommand(&cobra.Command{
@@ -62,7 +62,7 @@ Examples:
 
 // listSources lists all connected sources.
 func listSources(cfg *config.Config, filter string) error {
-	julesClient := jules.NewClient(cfg.Jules.APIKey, jules.WithBaseURL(cfg.Jules.BaseURL), jules.WithTimeout(cfg.Jules.Timeout), jules.WithRetryAttempts(cfg.Jules.RetryAttempts), jules.WithDebugLog(cfg.Jules.DebugLog), jules.WithLogger(logger.New(logger.Config{Debug: cfg.Jules.DebugLog})))
+	julesClient := NewJulesClient(cfg)
 
 	ctx := context.Background()
 
@@ -86,7 +86,7 @@ func FormatSourcesList(response *jules.SourcesResponse) string {
 	output += fmt.Sprintf("📚 Connected Sources (%d total)\n\n", len(sources))
 
 	if len(sources) == 0 {
-		output += "No sources connected. Connect repositories via the Jules web UI at https://jules.google.com\n"
+		output += "No sources connected. Connect repositories via the Jules web UI at https://jules.google.com or with 'juleson sources connect'\n"
 		return output
 	}
 
@@ -137,7 +137,7 @@ func FormatSourcesList(response *jules.SourcesResponse) string {
 
 // getSource gets details for a specific source.
 func getSource(cfg *config.Config, sourceID string) error {
-	julesClient := jules.NewClient(cfg.Jules.APIKey, jules.WithBaseURL(cfg.Jules.BaseURL), jules.WithTimeout(cfg.Jules.Timeout), jules.WithRetryAttempts(cfg.Jules.RetryAttempts), jules.WithDebugLog(cfg.Jules.DebugLog), jules.WithLogger(logger.New(logger.Config{Debug: cfg.Jules.DebugLog})))
+	julesClient := NewJulesClient(cfg)
 
 	ctx := context.Background()
 
@@ -0,0 +1,120 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/SamyRai/go-jules"
+	"github.com/SamyRai/juleson/internal/config"
+	"github.com/SamyRai/juleson/internal/jules/workspace"
+	"github.com/spf13/cobra"
+)
+
+// sourceConnectInterval is how often 'sources connect --wait' checks for the
+// source.
+var sourceConnectInterval = 10 * time.Second
+
+func newSourcesConnectCommand(cfg *config.Config) *cobra.Command {
+	var wait time.Duration
+
+	cmd := &cobra.Command{
+		Use:   "connect [owner/repo]",
+		Short: "Connect a GitHub repository as a Jules source",
+		Long: `Check that a GitHub repository is connected to Jules, and remember its source
+for 'sessions create .' and 'plan'. The repository defaults to the origin
+remote of the current directory.
+
+Jules connects repositories through its GitHub app, which the API cannot
+install. When the repository is not connected yet, the steps to connect it
+are printed; with --wait, the command then waits for the source to appear.
+
+Examples:
+  juleson sources connect
+  juleson sources connect acme/widgets --wait 5m`,
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			owner, repo, err := connectTarget(cmd.Context(), args)
+			if err != nil {
+				return err
+			}
+			return connectSource(cmd.Context(), cmd.OutOrStdout(), NewJulesClient(cfg), owner, repo, wait)
+		},
+	}
+	cmd.Flags().DurationVar(&wait, "wait", 0, "Wait up to this long for the repository to be connected")
+	return cmd
+}
+
+// connectTarget returns the repository named by args, or the origin remote
+// of the current directory.
+func connectTarget(ctx context.Context, args []string) (string, string, error) {
+	if len(args) == 0 {
+		owner, repo, err := workspace.GitRemoteOwnerRepo(ctx, ".")
+		if err != nil {
+			return "", "", fmt.Errorf("%w (pass owner/repo)", err)
+		}
+		return owner, repo, nil
+	}
+	slug := strings.TrimPrefix(strings.TrimPrefix(args[0], "sources/"), "github/")
+	owner, repo, ok := strings.Cut(slug, "/")
+	if !ok || owner == "" || repo == "" || strings.Contains(repo, "/") {
+		return "", "", fmt.Errorf("invalid repository %q: expected owner/repo", args[0])
+	}
+	return owner, repo, nil
+}
+
+// connectSource finds the source for owner/repo, printing how to connect it
+// and waiting up to wait when it is missing, and caches it.
+func connectSource(ctx context.Context, out io.Writer, client *jules.Client, owner, repo string, wait time.Duration) error {
+	source, err := workspace.FindSource(ctx, client, owner, repo)
+	if errors.Is(err, workspace.ErrSourceNotConnected) {
+		printConnectSteps(out, owner, repo)
+		if wait <= 0 {
+			return err
+		}
+		fmt.Fprintf(out, "\n⏳ Waiting up to %s for %s/%s to be connected...\n", wait, owner, repo)
+		source, err = waitForSource(ctx, client, owner, repo, wait)
+	}
+	if err != nil {
+		return err
+	}
+
+	if err := workspace.CacheSource(owner, repo, source.Name); err != nil {
+		fmt.Fprintf(out, "⚠️  Could not cache the source: %v\n", err)
+	}
+	fmt.Fprintf(out, "✅ %s/%s is connected as %s\n", owner, repo, source.Name)
+	fmt.Fprintf(out, "💡 Start a session with 'juleson sessions create %s \"Prompt\"'\n", source.ID)
+	return nil
+}
+
+func printConnectSteps(out io.Writer, owner, repo string) {
+	fmt.Fprintf(out, "🔌 %s/%s is not connected to Jules yet. To connect it:\n", owner, repo)
+	fmt.Fprintln(out, "  1. Sign in at https://jules.google.com with the GitHub account that can access it.")
+	fmt.Fprintf(out, "  2. Choose to add a repository, and grant the Jules GitHub app access to %s/%s.\n", owner, repo)
+	fmt.Fprintf(out, "     Existing installations are managed at https://github.com/settings/installations\n")
+	fmt.Fprintf(out, "     (or https://github.com/organizations/%s/settings/installations).\n", owner)
+	fmt.Fprintf(out, "  3. Run 'juleson sources connect %s/%s' again, or pass --wait to wait here.\n", owner, repo)
+}
+
+// waitForSource polls for the source until it is connected or wait elapses.
+func waitForSource(ctx context.Context, client *jules.Client, owner, repo string, wait time.Duration) (*jules.Source, error) {
+	ctx, cancel := context.WithTimeout(ctx, wait)
+	defer cancel()
+	ticker := time.NewTicker(sourceConnectInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil, fmt.Errorf("%w: %s/%s after waiting %s", workspace.ErrSourceNotConnected, owner, repo, wait)
+		case <-ticker.C:
+		}
+		source, err := workspace.FindSource(ctx, client, owner, repo)
+		if errors.Is(err, workspace.ErrSourceNotConnected) || (err != nil && ctx.Err() != nil) {
+			continue
+		}
+		return source, err
+	}
+}
@@ -0,0 +1,73 @@
+package core
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/SamyRai/juleson/internal/jules/julestest"
+	"github.com/SamyRai/juleson/internal/jules/workspace"
+)
+
+func TestConnectTarget(t *testing.T) {
+	for _, arg := range []string{"acme/widgets", "github/acme/widgets", "sources/github/acme/widgets"} {
+		owner, repo, err := connectTarget(context.Background(), []string{arg})
+		if err != nil || owner != "acme" || repo != "widgets" {
+			t.Errorf("connectTarget(%q) = %q, %q, %v", arg, owner, repo, err)
+		}
+	}
+	for _, arg := range []string{"widgets", "acme/", "acme/widgets/extra"} {
+		if _, _, err := connectTarget(context.Background(), []string{arg}); err == nil {
+			t.Errorf("connectTarget(%q) succeeded", arg)
+		}
+	}
+}
+
+func TestConnectSource(t *testing.T) {
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+	server := julestest.NewServer()
+	defer server.Close()
+	server.AddSource("acme", "widgets", "main")
+	client := server.Client()
+
+	var out bytes.Buffer
+	if err := connectSource(context.Background(), &out, client, "acme", "widgets", 0); err != nil {
+		t.Fatalf("connectSource: %v", err)
+	}
+	if !strings.Contains(out.String(), "is connected as sources/github/acme/widgets") {
+		t.Errorf("output = %q", out.String())
+	}
+	if source, ok := workspace.CachedSource("Acme", "Widgets"); !ok || source != "sources/github/acme/widgets" {
+		t.Errorf("CachedSource = %q, %v", source, ok)
+	}
+
+	out.Reset()
+	err := connectSource(context.Background(), &out, client, "acme", "gadgets", 0)
+	if !errors.Is(err, workspace.ErrSourceNotConnected) {
+		t.Fatalf("connectSource for a missing repository: err = %v", err)
+	}
+	if !strings.Contains(out.String(), "grant the Jules GitHub app access to acme/gadgets") {
+		t.Errorf("output does not explain how to connect:\n%s", out.String())
+	}
+}
+
+func TestConnectSourceWaits(t *testing.T) {
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+	defer func(interval time.Duration) { sourceConnectInterval = interval }(sourceConnectInterval)
+	sourceConnectInterval = 10 * time.Millisecond
+
+	server := julestest.NewServer()
+	defer server.Close()
+	time.AfterFunc(30*time.Millisecond, func() { server.AddSource("acme", "gadgets", "main") })
+
+	var out bytes.Buffer
+	if err := connectSource(context.Background(), &out, server.Client(), "acme", "gadgets", 5*time.Second); err != nil {
+		t.Fatalf("connectSource: %v", err)
+	}
+	if _, ok := workspace.CachedSource("acme", "gadgets"); !ok {
+		t.Error("source was not cached after waiting")
+	}
+}
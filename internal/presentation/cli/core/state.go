@@ -0,0 +1,501 @@
+package core
+
+import (
+	"archive/tar"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/SamyRai/juleson/internal/config"
+	"github.com/SamyRai/juleson/internal/events"
+	"github.com/SamyRai/juleson/internal/secrets"
+	"github.com/klauspost/compress/zstd"
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+)
+
+// stateFormat is the layout version of state bundles. Bundles of a newer
+// format are refused.
+const stateFormat = 1
+
+// stateManifestName is the first entry of a state bundle.
+const stateManifestName = "manifest.json"
+
+// StateManifest describes a state bundle.
+type StateManifest struct {
+	Format    int       `json:"format"`
+	Version   string    `json:"juleson_version"`
+	CreatedAt time.Time `json:"created_at"`
+	Files     []string  `json:"files"`
+	// Redacted are the config keys left out as secrets.
+	Redacted []string `json:"redacted,omitempty"`
+}
+
+// stateEntry is a file or directory of the state, and its name in bundles.
+type stateEntry struct {
+	name string
+	path string
+	// journal entries are decrypted into bundles and encrypted with cipher
+	// on import.
+	journal bool
+	dir     bool
+	cipher  secrets.Sealer
+}
+
+// stateEntries returns the state of cfg: its config file, audit log, run
+// history, approvals, issue links, and custom templates, and the journals
+// of each MCP tenant under tenants/NAME.
+func stateEntries(cfg *config.Config) ([]stateEntry, error) {
+	var entries []stateEntry
+	if file := config.File(); file != "" {
+		entries = append(entries, stateEntry{name: "config.yaml", path: file})
+	}
+	scoped, err := scopeEntries("", cfg)
+	if err != nil {
+		return nil, err
+	}
+	entries = append(entries, scoped...)
+	if customPath, err := customTemplatesPath(cfg); err == nil {
+		entries = append(entries, stateEntry{name: "templates", path: customPath, dir: true})
+	}
+	for _, tenant := range cfg.MCP.Tenants {
+		scoped, err := tenantEntries(cfg, tenant)
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, scoped...)
+	}
+	return entries, nil
+}
+
+// tenantEntries returns the state of tenant, named under tenants/NAME.
+func tenantEntries(cfg *config.Config, tenant config.MCPTenantConfig) ([]stateEntry, error) {
+	scoped, err := cfg.TenantStorage(tenant)
+	if err != nil {
+		return nil, fmt.Errorf("tenant %s: %w", tenant.Name, err)
+	}
+	return scopeEntries(path.Join("tenants", tenant.Name), scoped)
+}
+
+// scopeEntries returns the journals and stores of cfg, named under prefix.
+func scopeEntries(prefix string, cfg *config.Config) ([]stateEntry, error) {
+	cipher, err := eventCipher(cfg)
+	if err != nil {
+		return nil, err
+	}
+	var entries []stateEntry
+	for _, store := range []struct {
+		name    string
+		path    func(*config.Config) (string, error)
+		journal bool
+	}{
+		{"audit.jsonl", AuditLogPath, true},
+		{"history.jsonl", HistoryPath, true},
+		{"approvals.json", ApprovalsPath, false},
+		{"issues.json", IssuesPath, false},
+	} {
+		storePath, err := store.path(cfg)
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, stateEntry{
+			name:    path.Join(prefix, store.name),
+			path:    storePath,
+			journal: store.journal,
+			cipher:  cipher,
+		})
+	}
+	return entries, nil
+}
+
+// ExportState writes the state of cfg to w as a zstd-compressed tar. The
+// config file is included without its secrets, and encrypted journals are
+// decrypted, so the bundle can be imported on another machine. Projection
+// snapshots are left out; they are rebuilt from the journals.
+func ExportState(cfg *config.Config, w io.Writer, now time.Time) (StateManifest, error) {
+	manifest := StateManifest{Format: stateFormat, Version: Version, CreatedAt: now.UTC()}
+	entries, err := stateEntries(cfg)
+	if err != nil {
+		return manifest, err
+	}
+
+	// Files are read before writing, so the manifest can list them first.
+	type stateFile struct {
+		name     string
+		data     []byte
+		modified time.Time
+	}
+	var files []stateFile
+	add := func(name, filePath string, entry stateEntry) error {
+		data, err := os.ReadFile(filePath)
+		if err != nil {
+			return err
+		}
+		info, err := os.Stat(filePath)
+		if err != nil {
+			return err
+		}
+		switch {
+		case entry.name == "config.yaml":
+			data, manifest.Redacted, err = RedactConfig(data)
+		case entry.journal:
+			var plain bytes.Buffer
+			err = events.DecryptRecords(entry.cipher, bytes.NewReader(data), &plain)
+			data = plain.Bytes()
+		}
+		if err != nil {
+			return fmt.Errorf("%s: %w", filePath, err)
+		}
+		files = append(files, stateFile{name: name, data: data, modified: info.ModTime()})
+		manifest.Files = append(manifest.Files, name)
+		return nil
+	}
+	for _, entry := range entries {
+		if !entry.dir {
+			if err := add(entry.name, entry.path, entry); err != nil && !errors.Is(err, fs.ErrNotExist) {
+				return manifest, fmt.Errorf("failed to read %s: %w", entry.name, err)
+			}
+			continue
+		}
+		err := filepath.WalkDir(entry.path, func(filePath string, d fs.DirEntry, err error) error {
+			if errors.Is(err, fs.ErrNotExist) && filePath == entry.path {
+				return fs.SkipAll
+			}
+			if err != nil || !d.Type().IsRegular() {
+				return err
+			}
+			rel, err := filepath.Rel(entry.path, filePath)
+			if err != nil {
+				return err
+			}
+			return add(path.Join(entry.name, filepath.ToSlash(rel)), filePath, entry)
+		})
+		if err != nil {
+			return manifest, fmt.Errorf("failed to read %s: %w", entry.name, err)
+		}
+	}
+
+	zw, err := zstd.NewWriter(w)
+	if err != nil {
+		return manifest, err
+	}
+	tw := tar.NewWriter(zw)
+	write := func(name string, data []byte, modified time.Time) error {
+		header := &tar.Header{Name: name, Mode: 0o600, Size: int64(len(data)), ModTime: modified, Typeflag: tar.TypeReg}
+		if err := tw.WriteHeader(header); err != nil {
+			return err
+		}
+		_, err := tw.Write(data)
+		return err
+	}
+	manifestData, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return manifest, err
+	}
+	if err := write(stateManifestName, manifestData, manifest.CreatedAt); err != nil {
+		return manifest, fmt.Errorf("failed to write state bundle: %w", err)
+	}
+	for _, file := range files {
+		if err := write(file.name, file.data, file.modified); err != nil {
+			return manifest, fmt.Errorf("failed to write state bundle: %w", err)
+		}
+	}
+	if err := tw.Close(); err != nil {
+		return manifest, fmt.Errorf("failed to write state bundle: %w", err)
+	}
+	if err := zw.Close(); err != nil {
+		return manifest, fmt.Errorf("failed to write state bundle: %w", err)
+	}
+	return manifest, nil
+}
+
+// ImportState restores a bundle written by ExportState into the locations
+// cfg configures. Tenants the bundle holds but cfg lacks are restored to
+// their default data directories. Existing files are only replaced with
+// force; otherwise nothing is written when any would be. Journals are
+// encrypted when cfg enables encryption. It returns the bundle's manifest
+// and the paths written.
+func ImportState(cfg *config.Config, r io.Reader, force bool) (StateManifest, []string, error) {
+	var manifest StateManifest
+	zr, err := zstd.NewReader(r)
+	if err != nil {
+		return manifest, nil, fmt.Errorf("failed to read state bundle: %w", err)
+	}
+	defer zr.Close()
+	tr := tar.NewReader(zr)
+
+	header, err := tr.Next()
+	if err != nil || header.Name != stateManifestName {
+		return manifest, nil, fmt.Errorf("not a juleson state bundle: it does not start with %s", stateManifestName)
+	}
+	if err := json.NewDecoder(tr).Decode(&manifest); err != nil {
+		return manifest, nil, fmt.Errorf("invalid %s: %w", stateManifestName, err)
+	}
+	if manifest.Format > stateFormat {
+		return manifest, nil, fmt.Errorf("state bundle format %d is newer than this juleson (%d); upgrade juleson to import it", manifest.Format, stateFormat)
+	}
+
+	entries, err := stateEntries(cfg)
+	if err != nil {
+		return manifest, nil, err
+	}
+	if !hasConfigEntry(entries) {
+		entries = append(entries, stateEntry{name: "config.yaml", path: filepath.Join("configs", "juleson.yaml")})
+	}
+
+	// The bundle is staged in memory, so conflicts are found before
+	// anything is written.
+	type stagedFile struct {
+		path  string
+		data  []byte
+		entry stateEntry
+	}
+	var staged []stagedFile
+	for {
+		header, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return manifest, nil, fmt.Errorf("failed to read state bundle: %w", err)
+		}
+		if header.Typeflag != tar.TypeReg {
+			continue
+		}
+		entry, dest, err := resolveStateFile(cfg, entries, header.Name)
+		if err != nil {
+			return manifest, nil, err
+		}
+		data, err := io.ReadAll(tr)
+		if err != nil {
+			return manifest, nil, fmt.Errorf("failed to read %s: %w", header.Name, err)
+		}
+		staged = append(staged, stagedFile{path: dest, data: data, entry: entry})
+	}
+
+	if !force {
+		var existing []string
+		for _, file := range staged {
+			if _, err := os.Stat(file.path); err == nil {
+				existing = append(existing, file.path)
+			}
+		}
+		if len(existing) > 0 {
+			return manifest, nil, fmt.Errorf("would replace %s; use --force to replace existing state", strings.Join(existing, ", "))
+		}
+	}
+
+	var written []string
+	for _, file := range staged {
+		data := file.data
+		if file.entry.journal && file.entry.cipher != nil {
+			var sealed bytes.Buffer
+			if err := events.EncryptRecords(file.entry.cipher, bytes.NewReader(data), &sealed); err != nil {
+				return manifest, written, fmt.Errorf("%s: %w", file.path, err)
+			}
+			data = sealed.Bytes()
+		}
+		if err := os.MkdirAll(filepath.Dir(file.path), 0o755); err != nil {
+			return manifest, written, fmt.Errorf("failed to create %s: %w", filepath.Dir(file.path), err)
+		}
+		if err := os.WriteFile(file.path, data, 0o600); err != nil {
+			return manifest, written, fmt.Errorf("failed to write %s: %w", file.path, err)
+		}
+		if file.entry.journal {
+			// Snapshots of the replaced journal no longer match it.
+			if err := os.RemoveAll(file.path + ".projections"); err != nil {
+				return manifest, written, fmt.Errorf("failed to remove projection snapshots: %w", err)
+			}
+		}
+		written = append(written, file.path)
+	}
+	return manifest, written, nil
+}
+
+func hasConfigEntry(entries []stateEntry) bool {
+	for _, entry := range entries {
+		if entry.name == "config.yaml" {
+			return true
+		}
+	}
+	return false
+}
+
+// resolveStateFile returns the entry of the bundle file name and the path
+// it is restored to.
+func resolveStateFile(cfg *config.Config, entries []stateEntry, name string) (stateEntry, string, error) {
+	clean := path.Clean(name)
+	if path.IsAbs(clean) || clean == ".." || strings.HasPrefix(clean, "../") {
+		return stateEntry{}, "", fmt.Errorf("state bundle entry %q is outside the bundle", name)
+	}
+	if rest, ok := strings.CutPrefix(clean, "tenants/"); ok {
+		tenantName, _, _ := strings.Cut(rest, "/")
+		if _, ok := cfg.MCP.Tenant(tenantName); !ok {
+			scoped, err := tenantEntries(cfg, config.MCPTenantConfig{Name: tenantName})
+			if err != nil {
+				return stateEntry{}, "", err
+			}
+			entries = scoped
+		}
+	}
+	for _, entry := range entries {
+		if clean == entry.name && !entry.dir {
+			return entry, entry.path, nil
+		}
+		if rel, ok := strings.CutPrefix(clean, entry.name+"/"); ok && entry.dir {
+			return entry, filepath.Join(entry.path, filepath.FromSlash(rel)), nil
+		}
+	}
+	return stateEntry{}, "", fmt.Errorf("state bundle entry %q has no place in this configuration", name)
+}
+
+// secretConfigKeys are the config keys whose values are credentials.
+var secretConfigKeys = map[string]bool{
+	"api_key":       true,
+	"jules_api_key": true,
+	"github_token":  true,
+	"token":         true,
+	"secret":        true,
+	"password":      true,
+	"webhook_url":   true,
+	"headers":       true,
+}
+
+// envReference matches values that only name an environment variable,
+// such as ${JULESON_MCP_TOKEN}, which are kept.
+var envReference = regexp.MustCompile(`^\$(\{[A-Za-z_][A-Za-z0-9_]*\}|[A-Za-z_][A-Za-z0-9_]*)$`)
+
+// RedactConfig returns the YAML config data without the values of its
+// secret keys, and those keys. Values that only reference an environment
+// variable are kept, as are comments.
+func RedactConfig(data []byte) ([]byte, []string, error) {
+	var root yaml.Node
+	if err := yaml.Unmarshal(data, &root); err != nil {
+		return nil, nil, fmt.Errorf("failed to parse config: %w", err)
+	}
+	if len(root.Content) == 0 {
+		return data, nil, nil
+	}
+	var redacted []string
+	redactNode(root.Content[0], "", &redacted)
+	out, err := yaml.Marshal(&root)
+	if err != nil {
+		return nil, nil, err
+	}
+	return out, redacted, nil
+}
+
+func redactNode(node *yaml.Node, prefix string, redacted *[]string) {
+	switch node.Kind {
+	case yaml.MappingNode:
+		kept := node.Content[:0]
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			key, value := node.Content[i], node.Content[i+1]
+			name := key.Value
+			if prefix != "" {
+				name = prefix + "." + key.Value
+			}
+			if secretConfigKeys[key.Value] && !emptyOrEnvReference(value) {
+				*redacted = append(*redacted, name)
+				continue
+			}
+			redactNode(value, name, redacted)
+			kept = append(kept, key, value)
+		}
+		node.Content = kept
+	case yaml.SequenceNode:
+		for i, item := range node.Content {
+			redactNode(item, prefix+"["+strconv.Itoa(i)+"]", redacted)
+		}
+	}
+}
+
+func emptyOrEnvReference(node *yaml.Node) bool {
+	if node.Kind != yaml.ScalarNode {
+		return len(node.Content) == 0
+	}
+	return node.Value == "" || envReference.MatchString(strings.TrimSpace(node.Value))
+}
+
+// NewExportCommand creates the export command.
+func NewExportCommand(cfg *config.Config) *cobra.Command {
+	return &cobra.Command{
+		Use:   "export FILE",
+		Short: "Export config, journals, and templates to a state bundle",
+		Long: `Write the state of juleson to FILE, a zstd-compressed tar such as
+state.tar.zst: the config file without its secrets, the audit log, run
+history, approvals, issue links, custom templates, and the journals of MCP
+tenants. Journals encrypted at rest are decrypted, so the bundle can be
+imported on another machine or attached to a bug report; it holds session
+prompts and repository names, so share it with care.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			file, err := os.OpenFile(args[0], os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0o600)
+			if err != nil {
+				return fmt.Errorf("failed to create state bundle: %w", err)
+			}
+			manifest, err := ExportState(cfg, file, time.Now())
+			if closeErr := file.Close(); err == nil {
+				err = closeErr
+			}
+			if err != nil {
+				_ = os.Remove(args[0])
+				return err
+			}
+			out := cmd.OutOrStdout()
+			fmt.Fprintf(out, "✅ Exported %d files to %s\n", len(manifest.Files), args[0])
+			if len(manifest.Redacted) > 0 {
+				fmt.Fprintf(out, "Left out secrets: %s\n", strings.Join(manifest.Redacted, ", "))
+			}
+			return nil
+		},
+	}
+}
+
+// NewImportCommand creates the import command.
+func NewImportCommand(cfg *config.Config) *cobra.Command {
+	var force bool
+
+	cmd := &cobra.Command{
+		Use:   "import FILE",
+		Short: "Import a state bundle written by export",
+		Long: `Restore a state bundle written by 'juleson export' into the locations the
+current configuration uses. Nothing is written if any file already exists,
+unless --force is given. Secrets are not part of bundles: set them again
+with 'juleson auth login' or environment variables.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			file, err := os.Open(args[0])
+			if err != nil {
+				return fmt.Errorf("failed to open state bundle: %w", err)
+			}
+			defer func() { _ = file.Close() }()
+			manifest, written, err := ImportState(cfg, file, force)
+			if err != nil {
+				return err
+			}
+			out := cmd.OutOrStdout()
+			fmt.Fprintf(out, "✅ Imported %d files exported by juleson %s at %s\n", len(written), manifest.Version, manifest.CreatedAt.Format(time.RFC3339))
+			for _, path := range written {
+				fmt.Fprintf(out, "  %s\n", path)
+			}
+			if len(manifest.Redacted) > 0 {
+				fmt.Fprintf(out, "Secrets to set again: %s\n", strings.Join(manifest.Redacted, ", "))
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVar(&force, "force", false, "Replace existing files")
+	return cmd
+}
@@ -0,0 +1,117 @@
+package core
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/SamyRai/juleson/internal/config"
+)
+
+// stateConfig returns a config keeping its state under dir.
+func stateConfig(dir string) *config.Config {
+	return &config.Config{
+		Audit:        config.AuditConfig{Enabled: true, Path: filepath.Join(dir, "audit.jsonl")},
+		History:      config.HistoryConfig{Enabled: true, Path: filepath.Join(dir, "history.jsonl")},
+		Policy:       config.PolicyConfig{ApprovalsPath: filepath.Join(dir, "approvals.json")},
+		Integrations: config.IntegrationsConfig{IssuesPath: filepath.Join(dir, "issues.json")},
+		Templates:    config.TemplatesConfig{EnableCustom: true, CustomPath: filepath.Join(dir, "templates")},
+		MCP: config.MCPConfig{Tenants: []config.MCPTenantConfig{
+			{Name: "team-a", DataDir: filepath.Join(dir, "team-a")},
+		}},
+	}
+}
+
+func TestExportImportState(t *testing.T) {
+	src, dst := t.TempDir(), t.TempDir()
+	files := map[string]string{
+		"audit.jsonl":               `{"id":"a-1","type":"audit.recorded"}` + "\n",
+		"history.jsonl":             `{"id":"h-1","type":"session.created"}` + "\n",
+		"approvals.json":            `{"approvals":[]}`,
+		"templates/go/review.yaml":  "name: review\n",
+		"team-a/audit.jsonl":        `{"id":"t-1","type":"audit.recorded"}` + "\n",
+		"team-a/audit.jsonl.ignore": "not state",
+	}
+	for name, content := range files {
+		path := filepath.Join(src, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var bundle bytes.Buffer
+	manifest, err := ExportState(stateConfig(src), &bundle, time.Now())
+	if err != nil {
+		t.Fatalf("ExportState: %v", err)
+	}
+	if len(manifest.Files) != 5 {
+		t.Errorf("exported files = %v, want 5", manifest.Files)
+	}
+
+	cfg := stateConfig(dst)
+	_, written, err := ImportState(cfg, bytes.NewReader(bundle.Bytes()), false)
+	if err != nil {
+		t.Fatalf("ImportState: %v", err)
+	}
+	if len(written) != 5 {
+		t.Errorf("imported files = %v, want 5", written)
+	}
+	for name, want := range files {
+		got, err := os.ReadFile(filepath.Join(dst, filepath.FromSlash(name)))
+		if strings.HasSuffix(name, ".ignore") {
+			if err == nil {
+				t.Errorf("%s was imported", name)
+			}
+			continue
+		}
+		if err != nil || string(got) != want {
+			t.Errorf("%s = %q, %v; want %q", name, got, err, want)
+		}
+	}
+
+	if _, _, err := ImportState(cfg, bytes.NewReader(bundle.Bytes()), false); err == nil || !strings.Contains(err.Error(), "--force") {
+		t.Errorf("importing over existing state = %v, want a --force error", err)
+	}
+	if _, _, err := ImportState(cfg, bytes.NewReader(bundle.Bytes()), true); err != nil {
+		t.Errorf("importing with force: %v", err)
+	}
+}
+
+func TestRedactConfig(t *testing.T) {
+	data := []byte(`# Juleson
+jules:
+  api_key: jk-secret
+  base_url: https://jules.googleapis.com/v1alpha
+github:
+  token: ${GITHUB_TOKEN}
+mcp:
+  tokens:
+    - name: ci-bot
+      token: plain-secret
+      token_sha256: abc123
+      role: operator
+`)
+	out, redacted, err := RedactConfig(data)
+	if err != nil {
+		t.Fatalf("RedactConfig: %v", err)
+	}
+	if strings.Join(redacted, ",") != "jules.api_key,mcp.tokens[0].token" {
+		t.Errorf("redacted = %v", redacted)
+	}
+	for _, secret := range []string{"jk-secret", "plain-secret"} {
+		if strings.Contains(string(out), secret) {
+			t.Errorf("redacted config holds %s:\n%s", secret, out)
+		}
+	}
+	for _, kept := range []string{"# Juleson", "${GITHUB_TOKEN}", "token_sha256: abc123", "base_url:"} {
+		if !strings.Contains(string(out), kept) {
+			t.Errorf("redacted config lacks %s:\n%s", kept, out)
+		}
+	}
+}
@@ -0,0 +1,130 @@
+package core
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/SamyRai/juleson/internal/config"
+	"github.com/SamyRai/juleson/internal/health"
+	"github.com/spf13/cobra"
+)
+
+// NewStatusCommand creates the status command.
+func NewStatusCommand(cfg *config.Config) *cobra.Command {
+	var verbose, jsonMode bool
+
+	cmd := &cobra.Command{
+		Use:   "status",
+		Short: "Report the health of Juleson's components",
+		Long: `Check the configuration, Jules API reachability, and GitHub token validity,
+the same checks 'mcp serve' serves on /readyz when health.listen is set.
+Only problems are listed unless --verbose is given. Exits non-zero when a
+required component is down.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			report := health.NewChecker(HealthComponents(cfg)...).Check(cmd.Context(), false)
+			if jsonMode {
+				encoder := json.NewEncoder(cmd.OutOrStdout())
+				encoder.SetIndent("", "  ")
+				if err := encoder.Encode(report); err != nil {
+					return err
+				}
+			} else {
+				printStatusReport(cmd.OutOrStdout(), report, verbose)
+			}
+			if !report.Ready() {
+				return fmt.Errorf("juleson is not ready")
+			}
+			return nil
+		},
+	}
+	cmd.Flags().BoolVarP(&verbose, "verbose", "v", false, "List every component with its detail and check duration")
+	cmd.Flags().BoolVar(&jsonMode, "json", false, "Print the health report as JSON")
+
+	return cmd
+}
+
+// HealthComponents returns the health components for the configuration,
+// the Jules API, and the GitHub token.
+func HealthComponents(cfg *config.Config) []health.Component {
+	return []health.Component{
+		{
+			Name:     "config",
+			Live:     true,
+			Required: true,
+			Check: func(context.Context) health.Result {
+				if err := cfg.Validate(); err != nil {
+					return health.Down(strings.ReplaceAll(err.Error(), "\n", "; "))
+				}
+				return health.Up("valid")
+			},
+		},
+		{
+			Name:     "jules-api",
+			Required: true,
+			Check: func(ctx context.Context) health.Result {
+				if cfg.Jules.APIKey == "" {
+					return health.Down("API key not configured")
+				}
+				if err := verifyJulesAPIKey(ctx, cfg); err != nil {
+					return health.Down(fmt.Sprintf("%s: %v", cfg.Jules.BaseURL, err))
+				}
+				return health.Up("reachable at " + cfg.Jules.BaseURL)
+			},
+		},
+		{
+			Name: "github-token",
+			Check: func(ctx context.Context) health.Result {
+				if cfg.GitHub.Token == "" {
+					return health.Degraded("not configured; Jules-created PR commands are unavailable")
+				}
+				login, err := verifyGitHubToken(ctx, cfg)
+				if err != nil {
+					return health.Down(fmt.Sprintf("rejected: %v", err))
+				}
+				return health.Up("authenticated as " + login)
+			},
+		},
+	}
+}
+
+func printStatusReport(out io.Writer, report health.Report, verbose bool) {
+	state := "ready"
+	if !report.Ready() {
+		state = "not ready"
+	}
+	fmt.Fprintf(out, "%s Juleson is %s (%s)\n", statusIcon(report.Status), state, report.Status)
+
+	for _, component := range report.Components {
+		if !verbose && component.Status == health.StatusUp {
+			continue
+		}
+		fmt.Fprintf(out, "%s %s: %s\n", statusIcon(component.Status), component.Name, component.Detail)
+		if verbose {
+			var traits []string
+			if component.Required {
+				traits = append(traits, "required")
+			}
+			if component.Live {
+				traits = append(traits, "live")
+			}
+			traits = append(traits, "checked in "+component.Duration.Round(time.Millisecond).String())
+			fmt.Fprintf(out, "   %s\n", strings.Join(traits, ", "))
+		}
+	}
+}
+
+func statusIcon(status health.Status) string {
+	switch status {
+	case health.StatusUp:
+		return "✅"
+	case health.StatusDegraded:
+		return "⚠️ "
+	default:
+		return "❌"
+	}
+}
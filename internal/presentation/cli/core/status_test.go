@@ -0,0 +1,35 @@
+package core
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/SamyRai/juleson/internal/config"
+)
+
+func TestStatusCommandReportsMissingCredentials(t *testing.T) {
+	cfg := &config.Config{Jules: config.JulesConfig{BaseURL: "https://jules.googleapis.com/v1alpha"}}
+
+	cmd := NewStatusCommand(cfg)
+	var out bytes.Buffer
+	cmd.SetOut(&out)
+	cmd.SetErr(&out)
+	cmd.SetArgs([]string{"--verbose"})
+	if err := cmd.Execute(); err == nil {
+		t.Fatalf("status succeeded without a Jules API key:\n%s", out.String())
+	}
+
+	output := out.String()
+	for _, want := range []string{
+		"❌ Juleson is not ready (down)",
+		"✅ config: valid",
+		"❌ jules-api: API key not configured",
+		"⚠️  github-token: not configured",
+		"required, live, checked in",
+	} {
+		if !strings.Contains(output, want) {
+			t.Errorf("output missing %q:\n%s", want, output)
+		}
+	}
+}
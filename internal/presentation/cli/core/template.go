@@ -1,19 +1,30 @@
 package core
 
 import (
+	"context"
 	"fmt"
+	"os"
+	"strings"
 
+	"github.com/SamyRai/go-jules"
+	"github.com/SamyRai/juleson/internal/config"
+	julessessions "github.com/SamyRai/juleson/internal/jules/sessions"
+	"github.com/SamyRai/juleson/internal/jules/workspace"
+	"github.com/SamyRai/juleson/internal/policy"
+	"github.com/SamyRai/juleson/internal/presentation/views/theme"
 	"github.com/SamyRai/juleson/internal/templates"
+	"github.com/mattn/go-isatty"
 
 	"github.com/spf13/cobra"
 )
 
 // NewTemplateCommand creates the template command.
-func NewTemplateCommand(initializeTemplateManager func() (*templates.Manager, error), displayTemplates func([]templates.RegistryTemplate), displayTemplateDetails func(*templates.Template)) *cobra.Command {
+func NewTemplateCommand(cfg *config.Config, initializeTemplateManager func() (*templates.Manager, error), displayTemplates func([]templates.RegistryTemplate), displayTemplateDetails func(*templates.Template)) *cobra.Command {
 	templateCmd := &cobra.Command{
-		Use:   "template",
-		Short: "Manage templates",
-		Long:  "List, create, and manage Jules automation templates",
+		Use:     "template",
+		Aliases: []string{"templates"},
+		Short:   "Manage and run templates",
+		Long:    "List, create, install, and run Jules automation templates",
 	}
 
 	// List templates
@@ -114,5 +125,300 @@ func NewTemplateCommand(initializeTemplateManager func() (*templates.Manager, er
 		},
 	})
 
+	templateCmd.AddCommand(newTemplateRunCommand(cfg, initializeTemplateManager))
+	templateCmd.AddCommand(newTemplateInstallCommand(cfg))
+	templateCmd.AddCommand(newTemplateUninstallCommand(cfg))
+	templateCmd.AddCommand(newTemplateInstalledCommand(cfg))
+
 	return templateCmd
 }
+
+// TemplateRunOptions configures a template run.
+type TemplateRunOptions struct {
+	Vars                map[string]string
+	Task                string
+	Title               string
+	StartingBranch      string
+	AutomationMode      string
+	ApprovalID          string
+	NoSource            bool
+	RequirePlanApproval bool
+	// PlanApprovalSet reports whether RequirePlanApproval was given
+	// explicitly; otherwise the configured session defaults decide.
+	PlanApprovalSet bool
+	DryRun          bool
+	// Estimate prints the estimated session time instead of running.
+	Estimate bool
+	// Confirm starts work estimated above policy.budget.confirm_above.
+	Confirm bool
+	// Split runs each task as its own session in dependency order.
+	Split bool
+	// Verify applies each split task's changes in a temporary worktree and
+	// runs Checks there before dependent tasks start.
+	Verify bool
+	Checks []string
+	// EventsPath records a split run's workflow, task, and session events
+	// as JSONL.
+	EventsPath string
+	// ReportPath receives a Markdown or HTML report when a split run ends.
+	ReportPath string
+}
+
+func newTemplateRunCommand(cfg *config.Config, initializeTemplateManager func() (*templates.Manager, error)) *cobra.Command {
+	var (
+		options TemplateRunOptions
+		vars    []string
+		varFile string
+	)
+
+	cmd := &cobra.Command{
+		Use:   "run [template-name] [source-id]",
+		Short: "Create a Jules session from a template",
+		Long: `Render a template's task prompts with its variables and create a Jules session. All tasks become
+ordered phases of one session unless --task selects one. The source defaults to the one inferred from the
+git origin remote; templates with requires_approval always require plan approval.
+
+Variables come from --var-file and --var, with --var taking precedence. Values are checked against each
+variable's declared type and enum. Missing required variables are prompted for when stdin is a terminal
+and are an error otherwise.
+
+--split runs each task as its own session instead. A task starts once the sessions of the tasks it
+depends on have completed, and independent tasks run in parallel up to the template's
+max_concurrent_tasks (one for the sequential strategy). If a session fails, tasks depending on it are
+skipped. --verify also applies each completed task's patches in a temporary worktree of the current
+repository and runs the --check commands there; a task whose changes do not apply or pass counts as
+failed. The worktree is discarded and the working tree is never changed. Split runs publish workflow,
+task, and session events; --events appends them to a JSONL file. Each split run is recorded in the run
+history ('juleson history'), and --report writes a Markdown or HTML report of it when it ends.
+
+--estimate prints the expected session time, the median of recently completed sessions, without creating
+a session. When policy.budget.confirm_above is set, runs estimated above it require --confirm.
+
+Examples:
+  juleson template run test-generation --var FocusAreas=internal/config --dry-run
+  juleson template run code-cleanup --estimate
+  juleson template run test-generation --var-file vars.yaml
+  juleson template run code-cleanup sources/github/owner/repo --require-plan-approval
+  juleson template run test-generation --task analyze_test_coverage
+  juleson template run test-generation --split`,
+		Args: cobra.RangeArgs(1, 2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			options.Vars = map[string]string{}
+			options.PlanApprovalSet = cmd.Flags().Changed("require-plan-approval")
+			if varFile != "" {
+				values, err := templates.LoadVariableFile(varFile)
+				if err != nil {
+					return err
+				}
+				options.Vars = values
+			}
+			for _, v := range vars {
+				name, value, ok := strings.Cut(v, "=")
+				if !ok || name == "" {
+					return fmt.Errorf("invalid --var %q: expected NAME=VALUE", v)
+				}
+				options.Vars[name] = value
+			}
+			sourceID := "."
+			if len(args) > 1 {
+				sourceID = args[1]
+			}
+
+			templateManager, err := initializeTemplateManager()
+			if err != nil {
+				return fmt.Errorf("failed to initialize template manager: %w", err)
+			}
+			template, err := templateManager.LoadTemplate(args[0])
+			if err != nil {
+				return fmt.Errorf("failed to load template: %w", err)
+			}
+			if err := promptTemplateVariables(template, options.Vars); err != nil {
+				return err
+			}
+			return runTemplate(cfg, template, sourceID, options)
+		},
+	}
+
+	cmd.Flags().StringArrayVar(&vars, "var", nil, "Template variable as NAME=VALUE (repeatable; overrides --var-file)")
+	cmd.Flags().StringVar(&varFile, "var-file", "", "YAML file mapping variable names to values")
+	cmd.Flags().StringVar(&options.Task, "task", "", "Run only this task instead of every phase")
+	cmd.Flags().BoolVar(&options.DryRun, "dry-run", false, "Print the rendered prompt without creating a session")
+	cmd.Flags().BoolVar(&options.Estimate, "estimate", false, "Print the estimated session time without creating a session")
+	cmd.Flags().BoolVar(&options.Split, "split", false, "Run each task as its own session, in dependency order and in parallel where independent")
+	cmd.Flags().BoolVar(&options.Verify, "verify", false, "With --split, validate each task's changes in a temporary worktree before dependent tasks start")
+	cmd.Flags().StringArrayVar(&options.Checks, "check", nil, "Validation command for --verify (repeatable; default build, vet, and test for Go)")
+	cmd.Flags().StringVar(&options.EventsPath, "events", "", "With --split, append the run's workflow, task, and session events to this JSONL file")
+	cmd.Flags().StringVar(&options.ReportPath, "report", "", "With --split, write a run report to this file when the run ends (.html for HTML, otherwise Markdown)")
+	cmd.Flags().BoolVar(&options.Confirm, "confirm", false, "Start even if the estimate exceeds policy.budget.confirm_above")
+	cmd.Flags().BoolVar(&options.NoSource, "no-source", false, "Create a repoless session")
+	cmd.Flags().StringVar(&options.Title, "title", "", "Session title (default: the template name)")
+	cmd.Flags().StringVar(&options.StartingBranch, "starting-branch", "", "Starting branch for source-backed sessions")
+	cmd.Flags().BoolVar(&options.RequirePlanApproval, "require-plan-approval", false, "Require explicit plan approval before Jules starts work")
+	cmd.Flags().StringVar(&options.AutomationMode, "automation-mode", "", "Automation mode such as AUTO_CREATE_PR")
+	cmd.Flags().StringVar(&options.ApprovalID, "approval", "", "Approval ID granted by a second approver when policy requires one")
+
+	return cmd
+}
+
+// promptTemplateVariables asks for the required variables values leave
+// empty. Without a terminal nothing is asked, and rendering reports the
+// missing variables with their schema.
+func promptTemplateVariables(template *templates.Template, values map[string]string) error {
+	missing := template.MissingVariables(values)
+	if len(missing) == 0 || !isatty.IsTerminal(os.Stdin.Fd()) {
+		return nil
+	}
+	for _, variable := range missing {
+		var value string
+		var err error
+		if len(variable.Enum) > 0 {
+			value, err = theme.Select(variable.Schema(), variable.Enum)
+		} else {
+			value, err = theme.InputValidated(variable.Schema(), "", func(value string) error {
+				if value == "" {
+					return fmt.Errorf("%s is required", variable.Name)
+				}
+				return variable.Validate(value)
+			})
+		}
+		if err != nil {
+			return fmt.Errorf("failed to read %s: %w", variable.Name, err)
+		}
+		values[variable.Name] = value
+	}
+	return nil
+}
+
+func runTemplate(cfg *config.Config, template *templates.Template, sourceID string, options TemplateRunOptions) error {
+	if options.Split && options.Task != "" {
+		return fmt.Errorf("--split and --task cannot be combined")
+	}
+	if options.Verify && (!options.Split || options.NoSource) {
+		return fmt.Errorf("--verify requires --split and a source-backed session")
+	}
+	if options.EventsPath != "" && !options.Split {
+		return fmt.Errorf("--events requires --split")
+	}
+	if options.ReportPath != "" && !options.Split {
+		return fmt.Errorf("--report requires --split")
+	}
+	var (
+		prompt      string
+		taskPrompts map[string]string
+		err         error
+	)
+	if options.Split {
+		taskPrompts, err = renderTaskPrompts(template, options.Vars)
+	} else {
+		prompt, err = template.RenderPrompt(options.Vars, options.Task)
+	}
+	if err != nil {
+		return err
+	}
+	if options.DryRun {
+		if options.Split {
+			return printTaskPrompts(template, taskPrompts)
+		}
+		fmt.Print(prompt)
+		return nil
+	}
+
+	ctx := context.Background()
+	julesClient := NewJulesClient(cfg)
+	sessions, phases := 1, len(template.Tasks)
+	if options.Task != "" {
+		phases = 1
+	}
+	if options.Split {
+		sessions, phases = len(template.Tasks), 1
+	}
+	if options.Estimate || cfg.Policy.Budget.ConfirmAbove > 0 {
+		estimate, err := julessessions.EstimateFromHistory(ctx, julesClient, sessions, phases)
+		if err != nil {
+			return err
+		}
+		if options.Estimate {
+			PrintEstimate(os.Stdout, cfg, estimate)
+			return nil
+		}
+		if err := CheckEstimate(cfg, estimate, options.Confirm); err != nil {
+			return err
+		}
+	}
+	sourceName := julessessions.NormalizeSourceID(sourceID)
+	if !options.NoSource && sourceID == "." {
+		source, err := workspace.ResolveSource(ctx, julesClient, ".")
+		if err != nil {
+			return err
+		}
+		sourceName = source
+	}
+	if options.Title == "" {
+		options.Title = template.Metadata.Name
+		if options.Task != "" {
+			options.Title += ": " + options.Task
+		}
+	}
+	requestOptions := julessessions.CreateSessionRequestOptions{
+		Source:              sourceName,
+		NoSource:            options.NoSource,
+		StartingBranch:      options.StartingBranch,
+		RequirePlanApproval: options.RequirePlanApproval,
+		AutomationMode:      options.AutomationMode,
+	}
+	julessessions.ApplyDefaults(&requestOptions, cfg.Sessions, options.PlanApprovalSet)
+	requireApproval := requestOptions.RequirePlanApproval || template.Config.RequiresApproval
+	requestOptions.RequirePlanApproval = requireApproval
+
+	buildRequest := func(prompt, title string) (*jules.CreateSessionRequest, error) {
+		requestOptions := requestOptions
+		requestOptions.Prompt, requestOptions.Title = prompt, title
+		req, err := julessessions.BuildCreateSessionRequest(requestOptions)
+		if err == julessessions.ErrStartingBranchRequiresSource {
+			return nil, fmt.Errorf("--starting-branch requires a source-backed session")
+		}
+		return req, err
+	}
+	req, err := buildRequest(prompt, options.Title)
+	if err != nil {
+		return err
+	}
+	if !requireApproval {
+		if err := EnforcePolicy(cfg, policy.Check{
+			Request: policy.Request{
+				Operation: policy.OpAutoApprovePlan,
+				Tool:      "template run",
+				Repo:      policy.RepoFromSource(sourceName),
+			},
+			ApprovalID: options.ApprovalID,
+		}, true); err != nil {
+			return fmt.Errorf("%w (pass --require-plan-approval to review the plan first)", err)
+		}
+	}
+
+	if err := CheckSessionBudget(cfg, AuditSourceCLI, sessions); err != nil {
+		return err
+	}
+	if options.Split {
+		return runTemplateSplit(ctx, cfg, julesClient, template, taskPrompts, sourceName, requireApproval, options, buildRequest)
+	}
+	fmt.Printf("🚀 Creating Jules session from template %s...\n", template.Metadata.Name)
+	session, err := julesClient.Sessions().Create(ctx, req)
+	target := sourceName
+	if session != nil {
+		target = session.ID
+	}
+	RecordAudit(cfg, AuditSourceCLI, AuditSessionCreate, target, err, map[string]interface{}{"source": sourceName, "template": template.Metadata.Name})
+	if err != nil {
+		return fmt.Errorf("failed to create session: %w", err)
+	}
+
+	fmt.Printf("✅ Session created: %s\n", session.ID)
+	if session.URL != "" {
+		fmt.Printf("URL: %s\n", session.URL)
+	}
+	if requireApproval {
+		fmt.Printf("💡 Review the plan with 'juleson sessions plans %s' and approve it with 'juleson sessions approve %s'\n", session.ID, session.ID)
+	}
+	return nil
+}
@@ -0,0 +1,176 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/SamyRai/juleson/internal/config"
+	"github.com/SamyRai/juleson/internal/templates"
+
+	"github.com/spf13/cobra"
+)
+
+// customTemplatesPath returns the directory template packages install into.
+func customTemplatesPath(cfg *config.Config) (string, error) {
+	if !cfg.Templates.EnableCustom || cfg.Templates.CustomPath == "" {
+		return "", fmt.Errorf("custom templates are not enabled or path not configured")
+	}
+	return cfg.Templates.CustomPath, nil
+}
+
+func newTemplateInstallCommand(cfg *config.Config) *cobra.Command {
+	var (
+		options  templates.InstallOptions
+		indexURL string
+	)
+
+	cmd := &cobra.Command{
+		Use:   "install [source[@version]]",
+		Short: "Install templates from a git repository or the template index",
+		Long: `Install the templates in a git repository into the custom templates path. The source is a
+repository such as github.com/org/templates, a git URL, a local path, or a package name from the template
+index (templates.index_url or --index). Append @version to pin a branch or tag; the commit installed and a
+checksum of its templates are recorded in templates.lock.yaml. Without arguments, every package in the lock
+file is reinstalled at its pinned commit and checked against its checksum.
+
+Custom templates outside the installed packages override installed templates of the same name, and
+installed templates override built-in ones.
+
+Examples:
+  juleson template install github.com/org/templates@v1.2.0
+  juleson template install go-quality --index https://example.com/templates/index.yaml
+  juleson template install`,
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			customPath, err := customTemplatesPath(cfg)
+			if err != nil {
+				return err
+			}
+			ctx := context.Background()
+
+			if len(args) == 0 {
+				installed, err := templates.InstallLocked(ctx, customPath)
+				if err != nil {
+					return fmt.Errorf("failed to install locked packages: %w", err)
+				}
+				for _, pkg := range installed {
+					fmt.Printf("✅ Installed %s at %s\n", pkg.Name, shortCommit(pkg.Commit))
+				}
+				if len(installed) == 0 {
+					fmt.Println("No packages in the lock file.")
+				}
+				return nil
+			}
+
+			var source string
+			source, options.Version = templates.ParsePackageSpec(args[0])
+			if templates.IsIndexName(source) {
+				if indexURL == "" {
+					indexURL = cfg.Templates.IndexURL
+				}
+				if indexURL == "" {
+					return fmt.Errorf("%s is not a repository and no template index is configured (set templates.index_url or --index)", source)
+				}
+				index, err := templates.LoadIndex(ctx, indexURL)
+				if err != nil {
+					return err
+				}
+				entry, err := index.Lookup(source)
+				if err != nil {
+					return err
+				}
+				if options.Name == "" {
+					options.Name = entry.Name
+				}
+				// An index checksum only applies to the version it lists.
+				if options.Version == "" || options.Version == entry.Version {
+					options.Version = entry.Version
+					if options.Checksum == "" {
+						options.Checksum = entry.Checksum
+					}
+				}
+				source = entry.Source
+			}
+
+			pkg, err := templates.Install(ctx, customPath, source, options)
+			if err != nil {
+				return fmt.Errorf("failed to install templates: %w", err)
+			}
+			fmt.Printf("✅ Installed %d template(s) from %s as %s at %s\n", len(pkg.Templates), pkg.Source, pkg.Name, shortCommit(pkg.Commit))
+			fmt.Printf("Checksum: %s\n", pkg.Checksum)
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&options.Name, "name", "", "Package name (default: the repository name)")
+	cmd.Flags().StringVar(&options.Checksum, "checksum", "", "Expected sha256:<hex> checksum of the package's templates")
+	cmd.Flags().StringVar(&indexURL, "index", "", "Template index URL or file (default: templates.index_url)")
+
+	return cmd
+}
+
+func newTemplateUninstallCommand(cfg *config.Config) *cobra.Command {
+	return &cobra.Command{
+		Use:   "uninstall [package]",
+		Short: "Remove an installed template package",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			customPath, err := customTemplatesPath(cfg)
+			if err != nil {
+				return err
+			}
+			if err := templates.Uninstall(customPath, args[0]); err != nil {
+				return err
+			}
+			fmt.Printf("✅ Uninstalled %s\n", args[0])
+			return nil
+		},
+	}
+}
+
+func newTemplateInstalledCommand(cfg *config.Config) *cobra.Command {
+	return &cobra.Command{
+		Use:   "installed",
+		Short: "List installed template packages",
+		Long:  "List the template packages in the lock file and whether their files still match the locked checksum",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			customPath, err := customTemplatesPath(cfg)
+			if err != nil {
+				return err
+			}
+			lock, err := templates.ReadLockFile(customPath)
+			if err != nil {
+				return err
+			}
+			if len(lock.Packages) == 0 {
+				fmt.Println("No template packages installed.")
+				return nil
+			}
+
+			w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+			fmt.Fprintln(w, "NAME\tSOURCE\tVERSION\tCOMMIT\tTEMPLATES\tSTATUS")
+			for _, pkg := range lock.Packages {
+				status := "ok"
+				if err := templates.VerifyPackage(customPath, pkg); err != nil {
+					status = "modified or missing"
+				}
+				version := pkg.Version
+				if version == "" {
+					version = "-"
+				}
+				fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%d\t%s\n", pkg.Name, pkg.Source, version, shortCommit(pkg.Commit), len(pkg.Templates), status)
+			}
+			return w.Flush()
+		},
+	}
+}
+
+func shortCommit(commit string) string {
+	if len(commit) > 12 {
+		return commit[:12]
+	}
+	return commit
+}
@@ -0,0 +1,312 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/SamyRai/go-jules"
+	"github.com/SamyRai/juleson/internal/config"
+	"github.com/SamyRai/juleson/internal/events"
+	julessessions "github.com/SamyRai/juleson/internal/jules/sessions"
+	"github.com/SamyRai/juleson/internal/jules/workspace"
+	"github.com/SamyRai/juleson/internal/logger"
+	"github.com/SamyRai/juleson/internal/templates"
+)
+
+// templateTaskPollInterval is how often split template runs check on the
+// sessions they are waiting for.
+const templateTaskPollInterval = 30 * time.Second
+
+// renderTaskPrompts renders every task's prompt on its own, keyed by task
+// name.
+func renderTaskPrompts(template *templates.Template, values map[string]string) (map[string]string, error) {
+	prompts := make(map[string]string, len(template.Tasks))
+	for _, task := range template.Tasks {
+		prompt, err := template.RenderPrompt(values, task.Name)
+		if err != nil {
+			return nil, err
+		}
+		prompts[task.Name] = prompt
+	}
+	return prompts, nil
+}
+
+func printTaskPrompts(template *templates.Template, prompts map[string]string) error {
+	tasks, err := template.OrderedTasks()
+	if err != nil {
+		return err
+	}
+	for i, task := range tasks {
+		fmt.Printf("## Session %d: %s", i+1, task.Name)
+		if len(task.DependsOn) > 0 {
+			fmt.Printf(" (after %s)", strings.Join(task.DependsOn, ", "))
+		}
+		fmt.Printf("\n\n%s\n\n", strings.TrimSpace(prompts[task.Name]))
+	}
+	return nil
+}
+
+// runTemplateSplit runs each task as its own session, starting a task once
+// the sessions of its dependencies have completed.
+func runTemplateSplit(ctx context.Context, cfg *config.Config, client *jules.Client, template *templates.Template, prompts map[string]string, sourceName string, requireApproval bool, options TemplateRunOptions, buildRequest func(prompt, title string) (*jules.CreateSessionRequest, error)) error {
+	coordinator, err := newRunEventCoordinator(ctx, options.EventsPath)
+	if err != nil {
+		return err
+	}
+	exporter := NewEventExporter(cfg)
+	runID := events.NewRunID()
+	// record follows the run for its report.
+	var recordMu sync.Mutex
+	record := events.NewRunHistoryProjection()
+	defer func() {
+		_ = coordinator.Shutdown(context.WithoutCancel(ctx))
+		// After shutdown, so the run's last events are delivered.
+		_ = exporter.Close(context.WithoutCancel(ctx))
+		if options.ReportPath != "" {
+			recordMu.Lock()
+			run := record.State()[runID]
+			recordMu.Unlock()
+			if err := WriteRunReport(context.WithoutCancel(ctx), cfg, client, run, options.ReportPath); err != nil {
+				fmt.Printf("⚠️  %v\n", err)
+			} else {
+				fmt.Printf("📝 Run report written to %s\n", options.ReportPath)
+			}
+		}
+	}()
+	if options.ReportPath != "" {
+		err := coordinator.Subscribe(events.TopicAll, events.Subscriber{
+			ID:     "run-report",
+			Filter: func(event events.Event) bool { return events.RunKey(event) == runID },
+			Handler: func(ctx context.Context, event events.Event) error {
+				recordMu.Lock()
+				defer recordMu.Unlock()
+				return record.Apply(event)
+			},
+		})
+		if err != nil {
+			return err
+		}
+	}
+	if notifier := NewNotifier(cfg); notifier.Enabled() {
+		if err := coordinator.Subscribe(events.TopicAll, notifier.Subscriber()); err != nil {
+			return err
+		}
+	}
+	if mirror := NewIssueMirror(cfg); mirror.Enabled() {
+		if err := coordinator.Subscribe(events.TopicAll, mirror.Subscriber()); err != nil {
+			return err
+		}
+	}
+	if exporter.Enabled() {
+		if err := coordinator.Subscribe(events.TopicAll, exporter.Subscriber()); err != nil {
+			return err
+		}
+	}
+	alertsCtx, stopAlerts := context.WithCancel(ctx)
+	defer stopAlerts()
+	if err := StartAlerts(alertsCtx, cfg, coordinator); err != nil {
+		return err
+	}
+	if err := RecordRunHistory(cfg, coordinator); err != nil {
+		return err
+	}
+	emit := runEventEmitter{coordinator: coordinator, name: template.Metadata.Name, runID: runID}
+	started := time.Now()
+	emit.workflow(ctx, events.EventWorkflowStarted, len(template.Tasks), nil, 0)
+
+	concurrency := template.Concurrency()
+	fmt.Printf("🚀 Running %d task(s) of template %s as separate sessions, up to %d at a time (run %s)\n", len(template.Tasks), template.Metadata.Name, concurrency, emit.runID)
+
+	// verifyMu runs one verification at a time, since each adds and
+	// removes a worktree of the same repository.
+	var mu, verifyMu sync.Mutex
+	sessionIDs := make(map[string]string, len(template.Tasks))
+	results, runErr := template.RunTasks(ctx, concurrency, func(ctx context.Context, task templates.TemplateTask) (err error) {
+		taskStarted := time.Now()
+		sessionID := ""
+		emit.task(ctx, events.EventTaskStarted, task, sessionID, nil, 0)
+		defer func() {
+			eventType := events.EventTaskCompleted
+			if err != nil {
+				eventType = events.EventTaskFailed
+			}
+			emit.task(ctx, eventType, task, sessionID, err, time.Since(taskStarted))
+		}()
+
+		req, err := buildRequest(prompts[task.Name], options.Title+": "+task.Name)
+		if err != nil {
+			return err
+		}
+		session, err := client.Sessions().Create(ctx, req)
+		target := sourceName
+		if session != nil {
+			target = session.ID
+		}
+		RecordAudit(cfg, AuditSourceCLI, AuditSessionCreate, target, err, map[string]interface{}{
+			"source":   sourceName,
+			"template": template.Metadata.Name,
+			"task":     task.Name,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to create session: %w", err)
+		}
+		sessionID = session.ID
+		emit.session(ctx, session, sourceName, task.Name)
+		mu.Lock()
+		sessionIDs[task.Name] = session.ID
+		mu.Unlock()
+		fmt.Printf("▶️  %s: session %s\n", task.Name, session.ID)
+
+		final, err := julessessions.WaitForSession(ctx, client, session.ID, templateTaskPollInterval, func(state jules.SessionState) {
+			fmt.Printf("   %s: %s\n", task.Name, state)
+			if state == jules.SessionStateAwaitingPlanApproval && requireApproval {
+				fmt.Printf("   💡 Approve the plan with 'juleson sessions approve %s'\n", session.ID)
+				awaiting := *session
+				awaiting.State = state
+				emit.sessionState(ctx, &awaiting)
+			}
+		})
+		if final != nil && final.State.IsTerminal() {
+			emit.sessionState(ctx, final)
+		}
+		if err != nil || !options.Verify {
+			return err
+		}
+		verifyMu.Lock()
+		defer verifyMu.Unlock()
+		return verifyTaskSession(ctx, client, task.Name, session.ID, options.Checks)
+	})
+
+	if runErr != nil {
+		emit.workflow(ctx, events.EventWorkflowFailed, len(template.Tasks), runErr, time.Since(started))
+	} else {
+		emit.workflow(ctx, events.EventWorkflowCompleted, len(template.Tasks), nil, time.Since(started))
+	}
+	if results != nil {
+		fmt.Println()
+		for _, result := range results {
+			switch {
+			case result.Err == nil:
+				fmt.Printf("✅ %s: %s\n", result.Task, sessionIDs[result.Task])
+			case errors.Is(result.Err, templates.ErrTaskSkipped):
+				fmt.Printf("⏭️  %s: skipped\n", result.Task)
+			default:
+				fmt.Printf("❌ %s: %v\n", result.Task, result.Err)
+			}
+		}
+	}
+	return runErr
+}
+
+// newRunEventCoordinator starts an event coordinator for a split run. With a
+// path, every event is appended to it as JSONL.
+func newRunEventCoordinator(ctx context.Context, path string) (*events.EventCoordinator, error) {
+	return newEventCoordinator(ctx, &events.CoordinatorConfig{Logger: logger.For(logger.SubsystemEvents)}, path)
+}
+
+// newEventCoordinator starts an event coordinator with config, appending
+// every event to path when it is set.
+func newEventCoordinator(ctx context.Context, config *events.CoordinatorConfig, path string) (*events.EventCoordinator, error) {
+	if path != "" {
+		config.EnableStore = true
+		config.EventStoreConfig = &events.EventStoreConfig{StorageDir: filepath.Dir(path), JournalPath: path}
+	}
+	coordinator, err := events.NewEventCoordinator(config)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open events file: %w", err)
+	}
+	if err := coordinator.Start(ctx); err != nil {
+		return nil, err
+	}
+	return coordinator, nil
+}
+
+// runEventEmitter publishes a split run's events, each tagged with the
+// run's ID. Publishing failures are logged by the coordinator and never fail
+// the run.
+type runEventEmitter struct {
+	coordinator *events.EventCoordinator
+	name        string
+	runID       string
+}
+
+func (e runEventEmitter) publish(ctx context.Context, event events.Event) {
+	_ = e.coordinator.PublishEvent(ctx, event.WithMetadata("run_id", e.runID))
+}
+
+func (e runEventEmitter) workflow(ctx context.Context, eventType events.EventType, tasks int, err error, duration time.Duration) {
+	data := events.WorkflowEventData{WorkflowName: e.name, TotalPhases: tasks, Success: err == nil, Duration: duration}
+	if err != nil {
+		data.Error = err.Error()
+	}
+	e.publish(ctx, events.NewEvent(eventType, "workflow", data).WithTopic(events.TopicOrchestration))
+}
+
+func (e runEventEmitter) task(ctx context.Context, eventType events.EventType, task templates.TemplateTask, sessionID string, err error, duration time.Duration) {
+	data := events.TaskEventData{
+		TaskID:   task.Name,
+		TaskName: task.Description,
+		Status:   strings.TrimPrefix(string(eventType), "task."),
+		Duration: duration,
+		Metadata: map[string]interface{}{"workflow": e.name},
+	}
+	if sessionID != "" {
+		data.Metadata["session_id"] = sessionID
+	}
+	if err != nil {
+		data.Error = err.Error()
+	}
+	e.publish(ctx, events.NewEvent(eventType, "task", data).WithTopic(events.TopicTask))
+}
+
+func (e runEventEmitter) session(ctx context.Context, session *jules.Session, sourceName, taskName string) {
+	e.publish(ctx, events.NewEvent(events.EventSessionCreated, "session", events.SessionEventData{
+		SessionID: session.ID,
+		State:     string(session.State),
+		Title:     session.Title,
+		SourceID:  sourceName,
+		URL:       session.URL,
+		Metadata:  map[string]interface{}{"workflow": e.name, "task": taskName},
+	}).WithTopic(events.TopicSession))
+}
+
+// sessionState publishes the events for a session having reached its state.
+func (e runEventEmitter) sessionState(ctx context.Context, session *jules.Session) {
+	for _, event := range julessessions.StateEvents(session) {
+		e.publish(ctx, event.WithMetadata("workflow", e.name))
+	}
+}
+
+// verifyTaskSession applies a completed session's patches in a temporary
+// worktree of the current repository and runs checks there, without
+// changing the working tree.
+func verifyTaskSession(ctx context.Context, client *jules.Client, taskName, sessionID string, checks []string) error {
+	result, err := workspace.ApplySessionPatchesIsolated(ctx, client, sessionID, workspace.IsolatedApplyOptions{
+		Patch:  &workspace.PatchApplicationOptions{WorkingDir: ".", DryRun: true},
+		Checks: checks,
+	})
+	if result != nil && result.Patch != nil {
+		fmt.Printf("   %s: %d patch(es), %d file(s) changed\n", taskName, result.Patch.PatchesApplied, len(result.Patch.FilesModified))
+		for _, patchErr := range result.Patch.Errors {
+			fmt.Printf("   %s: ❌ %s\n", taskName, patchErr)
+		}
+	}
+	if result != nil {
+		for _, check := range result.Checks {
+			status := "✅"
+			if !check.Success {
+				status = "❌"
+			}
+			fmt.Printf("   %s: %s %s\n", taskName, status, check.Command)
+		}
+	}
+	if err != nil {
+		return fmt.Errorf("verification failed: %w", err)
+	}
+	return nil
+}
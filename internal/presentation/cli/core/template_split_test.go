@@ -0,0 +1,66 @@
+package core
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"strings"
+	"testing"
+
+	"github.com/SamyRai/go-jules"
+	"github.com/SamyRai/juleson/internal/jules/julestest"
+)
+
+// initVerifyRepo makes a temporary git repository with notes.txt the
+// working directory of the test.
+func initVerifyRepo(t *testing.T) {
+	t.Helper()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+	dir := t.TempDir()
+	t.Chdir(dir)
+	if err := os.WriteFile("notes.txt", []byte("old\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	for _, args := range [][]string{
+		{"init", "--quiet"},
+		{"-c", "user.email=dev@example.com", "-c", "user.name=Dev", "-c", "commit.gpgsign=false", "add", "."},
+		{"-c", "user.email=dev@example.com", "-c", "user.name=Dev", "-c", "commit.gpgsign=false", "commit", "--quiet", "-m", "initial"},
+	} {
+		if out, err := exec.Command("git", args...).CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v\n%s", args, err, out)
+		}
+	}
+}
+
+func TestVerifyTaskSession(t *testing.T) {
+	server := julestest.NewServer()
+	defer server.Close()
+	server.AddSession(jules.Session{ID: "s1", Title: "Update notes", State: jules.SessionStateCompleted})
+	server.AddActivities("s1", jules.Activity{Artifacts: []jules.Artifact{{ChangeSet: &jules.ChangeSet{GitPatch: &jules.GitPatch{
+		UnidiffPatch: "diff --git a/notes.txt b/notes.txt\n--- a/notes.txt\n+++ b/notes.txt\n@@ -1 +1 @@\n-old\n+new\n",
+	}}}}})
+	ctx := context.Background()
+
+	t.Run("patch applies", func(t *testing.T) {
+		initVerifyRepo(t)
+		if err := verifyTaskSession(ctx, server.Client(), "notes", "s1", []string{"grep -q new notes.txt"}); err != nil {
+			t.Fatalf("verifyTaskSession: %v", err)
+		}
+		if data, _ := os.ReadFile("notes.txt"); string(data) != "old\n" {
+			t.Errorf("notes.txt = %q; verification changed the working tree", data)
+		}
+	})
+
+	t.Run("failing check", func(t *testing.T) {
+		initVerifyRepo(t)
+		err := verifyTaskSession(ctx, server.Client(), "notes", "s1", []string{"grep -q old notes.txt"})
+		if err == nil || !strings.Contains(err.Error(), "verification failed") {
+			t.Fatalf("verifyTaskSession = %v, want a verification failure", err)
+		}
+		if data, _ := os.ReadFile("notes.txt"); string(data) != "old\n" {
+			t.Errorf("notes.txt = %q; verification changed the working tree", data)
+		}
+	})
+}
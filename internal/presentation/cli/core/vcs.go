@@ -0,0 +1,471 @@
+package core
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/SamyRai/go-jules"
+	"github.com/SamyRai/juleson/internal/config"
+	"github.com/SamyRai/juleson/internal/jules/workspace"
+	"github.com/SamyRai/juleson/internal/vcs"
+	"github.com/spf13/cobra"
+)
+
+// bitbucketHost is the host of Bitbucket Cloud.
+const bitbucketHost = "bitbucket.org"
+
+// VCSRepo is a repository and the provider hosting it.
+type VCSRepo struct {
+	Kind vcs.Kind
+	// Host is empty for github.com, bitbucket.org, and the configured GitLab
+	// host.
+	Host string
+	Path string
+}
+
+// NewVCSProvider creates the provider for repo.
+func NewVCSProvider(cfg *config.Config, repo VCSRepo) (vcs.Provider, error) {
+	switch repo.Kind {
+	case vcs.KindBitbucket:
+		if cfg.Bitbucket.Token == "" {
+			return nil, fmt.Errorf("Bitbucket client not configured - please set BITBUCKET_TOKEN")
+		}
+		return vcs.NewBitbucketProvider(cfg.Bitbucket.BaseURL, cfg.Bitbucket.Username, cfg.Bitbucket.Token), nil
+	case vcs.KindGitLab:
+		if cfg.GitLab.Token == "" {
+			return nil, fmt.Errorf("GitLab client not configured - please set GITLAB_TOKEN")
+		}
+		if repo.Host != "" && repo.Host != cfg.GitLab.HostName() {
+			return nil, fmt.Errorf("GitLab client not configured for %s - set gitlab.host", repo.Host)
+		}
+		return vcs.NewGitLabProvider(cfg.GitLab.APIURL(), cfg.GitLab.Token), nil
+	}
+	client, err := NewGitHubClient(cfg, repo.Host, nil)
+	if err != nil {
+		return nil, err
+	}
+	return vcs.NewGitHubProvider(client.Client), nil
+}
+
+// ResolveVCSRepo returns the repository named by value, such as owner/name
+// or HOST/group/name, or else the one of the current directory: its
+// workspace entry, or its origin remote. Repositories without a host are on
+// GitLab or Bitbucket when they match gitlab.repos or bitbucket.repos, and
+// on GitHub otherwise.
+func ResolveVCSRepo(ctx context.Context, cfg *config.Config, value string) (VCSRepo, error) {
+	if value != "" {
+		return classifyVCSRepo(cfg, "", value), nil
+	}
+	if entry, ok := workspace.LookupRepo("."); ok {
+		switch {
+		case entry.GitLab != "":
+			repo := classifyVCSRepo(cfg, "", entry.GitLab)
+			repo.Kind = vcs.KindGitLab
+			return repo, nil
+		case entry.Bitbucket != "":
+			return VCSRepo{Kind: vcs.KindBitbucket, Path: strings.Trim(entry.Bitbucket, "/")}, nil
+		case entry.GitHub != "":
+			repo := classifyVCSRepo(cfg, "", entry.GitHub)
+			repo.Kind = vcs.KindGitHub
+			return repo, nil
+		}
+	}
+	remoteURL, err := workspace.GitRemoteURL(ctx, ".")
+	if err != nil {
+		return VCSRepo{}, fmt.Errorf("%w (pass --repo)", err)
+	}
+	host, path, err := vcs.ParseRemoteURL(remoteURL)
+	if err != nil {
+		return VCSRepo{}, err
+	}
+	if host != cfg.GitLab.HostName() && host != "github.com" && host != bitbucketHost && !isGitHubHost(cfg, host) {
+		return VCSRepo{}, fmt.Errorf("origin remote host %s is not github.com, bitbucket.org, a github.hosts entry, or gitlab.host", host)
+	}
+	return classifyVCSRepo(cfg, host, path), nil
+}
+
+// ChangeRequestRepo returns the repository and provider of a pull or merge
+// request URL.
+func ChangeRequestRepo(cfg *config.Config, rawURL string) (VCSRepo, int, error) {
+	ref, err := vcs.ParseChangeRequestURL(rawURL)
+	if err != nil {
+		return VCSRepo{}, 0, err
+	}
+	repo := VCSRepo{Kind: ref.Kind, Path: ref.Repo}
+	if ref.Host != "github.com" && ref.Host != bitbucketHost && ref.Host != cfg.GitLab.HostName() {
+		repo.Host = ref.Host
+	}
+	return repo, ref.Number, nil
+}
+
+// SessionChangeRequest returns the pull request opened by a Jules session
+// and the provider hosting it.
+func SessionChangeRequest(ctx context.Context, cfg *config.Config, session *jules.Session) (vcs.Provider, *vcs.ChangeRequest, error) {
+	prURL := session.URL
+	for _, output := range session.Outputs {
+		if output.PullRequest != nil && output.PullRequest.URL != "" {
+			prURL = output.PullRequest.URL
+			break
+		}
+	}
+	if prURL == "" {
+		return nil, nil, fmt.Errorf("session has no URL - PR may not be created yet")
+	}
+	repo, number, err := ChangeRequestRepo(cfg, prURL)
+	if err != nil {
+		return nil, nil, err
+	}
+	provider, err := NewVCSProvider(cfg, repo)
+	if err != nil {
+		return nil, nil, err
+	}
+	cr, err := provider.GetChangeRequest(ctx, repo.Path, number)
+	if err != nil {
+		return nil, nil, err
+	}
+	return provider, cr, nil
+}
+
+func classifyVCSRepo(cfg *config.Config, host, path string) VCSRepo {
+	path = strings.Trim(path, "/")
+	if host == "" {
+		if first, rest, ok := strings.Cut(path, "/"); ok && strings.Contains(first, ".") && strings.Contains(rest, "/") {
+			host, path = strings.ToLower(first), rest
+		}
+	}
+	repo := VCSRepo{Kind: vcs.KindGitHub, Host: host, Path: path}
+	switch {
+	case host == cfg.GitLab.HostName() || (host == "" && cfg.GitLab.Hosts(path)):
+		repo.Kind = vcs.KindGitLab
+	case host == bitbucketHost || (host == "" && cfg.Bitbucket.Hosts(path)):
+		repo.Kind = vcs.KindBitbucket
+	}
+	if host == "github.com" || host == bitbucketHost || host == cfg.GitLab.HostName() {
+		repo.Host = ""
+	}
+	return repo
+}
+
+func isGitHubHost(cfg *config.Config, host string) bool {
+	for _, entry := range cfg.GitHub.Hosts {
+		if strings.EqualFold(entry.Host, host) {
+			return true
+		}
+	}
+	return false
+}
+
+// NewVCSCommand creates the vcs command.
+func NewVCSCommand(cfg *config.Config) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "vcs",
+		Short: "Work with GitHub, GitLab, or Bitbucket change requests and issues",
+		Long: `The vcs commands work the same on GitHub, GitLab, and Bitbucket Cloud. The
+repository comes from --repo, the current directory's workspace entry, or its
+origin remote; repositories without a host are on GitLab or Bitbucket when
+they match gitlab.repos or bitbucket.repos. Pipelines are under 'juleson ci'.`,
+	}
+	var repo string
+	cmd.PersistentFlags().StringVar(&repo, "repo", "", "Repository as owner/name or HOST/owner/name (default: current directory)")
+
+	resolve := func(cmd *cobra.Command) (VCSRepo, vcs.Provider, error) {
+		target, err := ResolveVCSRepo(cmd.Context(), cfg, repo)
+		if err != nil {
+			return VCSRepo{}, nil, err
+		}
+		provider, err := NewVCSProvider(cfg, target)
+		return target, provider, err
+	}
+
+	cmd.AddCommand(newVCSChangeCommand(cfg, resolve))
+	cmd.AddCommand(newVCSIssueCommand(resolve))
+	return cmd
+}
+
+type vcsResolver func(cmd *cobra.Command) (VCSRepo, vcs.Provider, error)
+
+func newVCSChangeCommand(cfg *config.Config, resolve vcsResolver) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "mr",
+		Aliases: []string{"pr"},
+		Short:   "List, open, show, diff, label, or merge pull or merge requests",
+		Long: `The get, diff, label, and merge subcommands take a pull or merge request URL,
+or a number in the repository of --repo or the current directory.`,
+	}
+
+	// target resolves a URL or a number to the change request's provider.
+	target := func(cmd *cobra.Command, arg string) (VCSRepo, vcs.Provider, int, error) {
+		if number, err := strconv.Atoi(strings.TrimLeft(arg, "#!")); err == nil {
+			repo, provider, err := resolve(cmd)
+			return repo, provider, number, err
+		}
+		repo, number, err := ChangeRequestRepo(cfg, arg)
+		if err != nil {
+			return VCSRepo{}, nil, 0, err
+		}
+		provider, err := NewVCSProvider(cfg, repo)
+		return repo, provider, number, err
+	}
+
+	var state string
+	var limit int
+	var listJSON bool
+	list := &cobra.Command{
+		Use:   "list",
+		Short: "List pull or merge requests",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			repo, provider, err := resolve(cmd)
+			if err != nil {
+				return err
+			}
+			changes, err := provider.ListChangeRequests(cmd.Context(), repo.Path, state, limit)
+			if err != nil {
+				return err
+			}
+			if listJSON {
+				return writeVCSJSON(cmd, changes)
+			}
+			if len(changes) == 0 {
+				fmt.Fprintf(cmd.OutOrStdout(), "No %s change requests found for %s\n", state, repo.Path)
+				return nil
+			}
+			w := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 0, 2, ' ', 0)
+			fmt.Fprintln(w, "NUMBER\tSTATE\tBRANCH\tAUTHOR\tTITLE")
+			for _, cr := range changes {
+				fmt.Fprintf(w, "%d\t%s\t%s → %s\t%s\t%s\n", cr.Number, cr.State, cr.SourceBranch, cr.TargetBranch, cr.Author, cr.Title)
+			}
+			return w.Flush()
+		},
+	}
+	list.Flags().StringVar(&state, "state", vcs.StateOpen, "State: open, closed, merged, or all")
+	list.Flags().IntVarP(&limit, "limit", "l", 20, "Maximum number of change requests")
+	list.Flags().BoolVar(&listJSON, "json", false, "Output as JSON")
+
+	var request vcs.NewChangeRequest
+	var sessionIDs []string
+	create := &cobra.Command{
+		Use:   "create",
+		Short: "Open a pull or merge request",
+		Long: `With --session, a title and description left out are written from the
+sessions' changes: a conventional-commit header and a CHANGELOG fragment, by
+Gemini when it is configured.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(sessionIDs) > 0 && (request.Title == "" || request.Description == "") {
+				result, err := DescribeSessions(cmd.Context(), cfg, NewJulesClient(cfg), sessionIDs, nil, true)
+				if err != nil {
+					return err
+				}
+				if result.Warning != "" {
+					fmt.Fprintf(cmd.ErrOrStderr(), "⚠️  Gemini failed, deriving the description from the changes: %s\n", result.Warning)
+				}
+				title, body := result.PullRequest()
+				if request.Title == "" {
+					request.Title = title
+				}
+				if request.Description == "" {
+					request.Description = body
+				}
+			}
+			if request.Title == "" || request.SourceBranch == "" {
+				return fmt.Errorf("--title (or --session) and --source are required")
+			}
+			repo, provider, err := resolve(cmd)
+			if err != nil {
+				return err
+			}
+			cr, err := provider.CreateChangeRequest(cmd.Context(), repo.Path, request)
+			RecordAudit(cfg, AuditSourceCLI, AuditPRCreate, repo.Path, err, map[string]interface{}{
+				"provider": string(provider.Kind()),
+				"source":   request.SourceBranch,
+				"target":   request.TargetBranch,
+			})
+			if err != nil {
+				return err
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "✅ Opened %s#%d: %s\n", repo.Path, cr.Number, cr.URL)
+			return nil
+		},
+	}
+	create.Flags().StringVar(&request.Title, "title", "", "Title")
+	create.Flags().StringVar(&request.Description, "body", "", "Description")
+	create.Flags().StringVar(&request.SourceBranch, "source", "", "Branch with the changes")
+	create.Flags().StringVar(&request.TargetBranch, "target", "", "Branch to merge into (default: the repository's default branch)")
+	create.Flags().StringArrayVar(&sessionIDs, "session", nil, "Jules session whose changes the request carries, to write the title and description from (repeatable)")
+
+	var jsonOutput bool
+	get := &cobra.Command{
+		Use:   "get <url-or-number>",
+		Short: "Show a pull or merge request",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			repo, provider, number, err := target(cmd, args[0])
+			if err != nil {
+				return err
+			}
+			cr, err := provider.GetChangeRequest(cmd.Context(), repo.Path, number)
+			if err != nil {
+				return err
+			}
+			if jsonOutput {
+				return writeVCSJSON(cmd, cr)
+			}
+			out := cmd.OutOrStdout()
+			fmt.Fprintf(out, "📝 %s #%d: %s\n", provider.Kind(), cr.Number, cr.Title)
+			fmt.Fprintf(out, "Repository: %s\n", cr.Repo)
+			fmt.Fprintf(out, "Branch: %s → %s\n", cr.SourceBranch, cr.TargetBranch)
+			fmt.Fprintf(out, "Author: %s\n", cr.Author)
+			fmt.Fprintf(out, "Status: %s\n", ChangeRequestStatus(cr))
+			fmt.Fprintf(out, "URL: %s\n", cr.URL)
+			return nil
+		},
+	}
+	get.Flags().BoolVar(&jsonOutput, "json", false, "Output as JSON")
+
+	diff := &cobra.Command{
+		Use:   "diff <url-or-number>",
+		Short: "Show the diff of a pull or merge request",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			repo, provider, number, err := target(cmd, args[0])
+			if err != nil {
+				return err
+			}
+			diff, err := provider.ChangeRequestDiff(cmd.Context(), repo.Path, number)
+			if err != nil {
+				return err
+			}
+			fmt.Fprint(cmd.OutOrStdout(), diff)
+			return nil
+		},
+	}
+
+	label := &cobra.Command{
+		Use:   "label <url-or-number> <label>...",
+		Short: "Add labels to a pull or merge request",
+		Args:  cobra.MinimumNArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			repo, provider, number, err := target(cmd, args[0])
+			if err != nil {
+				return err
+			}
+			err = provider.AddLabels(cmd.Context(), repo.Path, number, args[1:])
+			RecordAudit(cfg, AuditSourceCLI, AuditPRLabel, fmt.Sprintf("%s#%d", repo.Path, number), err, map[string]interface{}{
+				"provider": string(provider.Kind()),
+				"labels":   args[1:],
+			})
+			if err != nil {
+				return err
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "✅ Labeled %s#%d: %s\n", repo.Path, number, strings.Join(args[1:], ", "))
+			return nil
+		},
+	}
+
+	var method string
+	var yes bool
+	merge := &cobra.Command{
+		Use:   "merge <url-or-number>",
+		Short: "Merge a pull or merge request",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			repo, provider, number, err := target(cmd, args[0])
+			if err != nil {
+				return err
+			}
+			if method == "" {
+				method = cfg.GitHub.PR.DefaultMergeMethod
+				if method == "" {
+					method = "squash"
+				}
+			}
+			if !yes && !confirmVCSAction(fmt.Sprintf("Merge %s#%d with %s?", repo.Path, number, method)) {
+				fmt.Fprintln(cmd.OutOrStdout(), "Merge canceled.")
+				return nil
+			}
+			err = provider.MergeChangeRequest(cmd.Context(), repo.Path, number, method)
+			RecordAudit(cfg, AuditSourceCLI, AuditPRMerge, fmt.Sprintf("%s#%d", repo.Path, number), err, map[string]interface{}{
+				"provider": string(provider.Kind()),
+				"method":   method,
+			})
+			if err != nil {
+				return err
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "✅ Merged %s#%d\n", repo.Path, number)
+			return nil
+		},
+	}
+	merge.Flags().StringVarP(&method, "method", "m", "", "Merge method: merge, squash, or rebase (GitLab and Bitbucket: merge or squash)")
+	merge.Flags().BoolVarP(&yes, "yes", "y", false, "Merge without confirmation")
+
+	cmd.AddCommand(list, create, get, diff, label, merge)
+	return cmd
+}
+
+// ChangeRequestStatus describes whether a change request can be merged.
+func ChangeRequestStatus(cr *vcs.ChangeRequest) string {
+	switch {
+	case cr.State == vcs.StateMerged:
+		return "✅ Merged"
+	case cr.State == vcs.StateClosed:
+		return "❌ Closed"
+	case !cr.Mergeable:
+		return "⚠️  Cannot merge (conflicts or failing checks)"
+	default:
+		return "🟢 Ready to merge"
+	}
+}
+
+func newVCSIssueCommand(resolve vcsResolver) *cobra.Command {
+	var title, body string
+	var labels []string
+
+	create := &cobra.Command{
+		Use:   "create",
+		Short: "Open an issue",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if title == "" {
+				return fmt.Errorf("--title is required")
+			}
+			repo, provider, err := resolve(cmd)
+			if err != nil {
+				return err
+			}
+			issue, err := provider.CreateIssue(cmd.Context(), repo.Path, title, body, labels)
+			if err != nil {
+				return err
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "✅ Opened %s#%d: %s\n", repo.Path, issue.Number, issue.URL)
+			return nil
+		},
+	}
+	create.Flags().StringVar(&title, "title", "", "Issue title")
+	create.Flags().StringVar(&body, "body", "", "Issue description")
+	create.Flags().StringSliceVar(&labels, "label", nil, "Label to add (repeatable)")
+
+	cmd := &cobra.Command{Use: "issue", Short: "Manage issues"}
+	cmd.AddCommand(create)
+	return cmd
+}
+
+func confirmVCSAction(prompt string) bool {
+	fmt.Printf("%s (y/N): ", prompt)
+	var response string
+	if err := ScanPromptValue(&response); err != nil {
+		return false
+	}
+	response = strings.ToLower(strings.TrimSpace(response))
+	return response == "y" || response == "yes"
+}
+
+func writeVCSJSON(cmd *cobra.Command, value any) error {
+	encoder := json.NewEncoder(cmd.OutOrStdout())
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(value)
+}
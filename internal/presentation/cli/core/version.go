@@ -3,6 +3,8 @@ package core
 import (
 	"fmt"
 	"runtime"
+	"runtime/debug"
+	"strings"
 
 	"github.com/spf13/cobra"
 )
@@ -15,6 +17,10 @@ var (
 	BuildDate = "unknown"
 	// GitCommit is the git commit hash (set at build time).
 	GitCommit = "unknown"
+	// UpdatePublicKey is the base64 ed25519 key that signs release
+	// checksums (set at build time). self-update verifies signatures only
+	// when it is set.
+	UpdatePublicKey = ""
 	// JulesAPIVersion is the Jules API version.
 	JulesAPIVersion = "v1alpha"
 )
@@ -51,10 +57,64 @@ func GetVersionInfo() VersionInfo {
 	}
 }
 
-// runVersion displays version information.
+// FillBuildInfo fills in version metadata not set with -ldflags from the
+// module and VCS information Go embeds, as in binaries built with
+// "go install" or a plain "go build".
+func FillBuildInfo() {
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return
+	}
+	if (Version == "dev" || Version == "") && info.Main.Version != "" && info.Main.Version != "(devel)" {
+		Version = info.Main.Version
+	}
+	modified := false
+	for _, setting := range info.Settings {
+		switch setting.Key {
+		case "vcs.revision":
+			if GitCommit == "unknown" || GitCommit == "" {
+				GitCommit = setting.Value
+			}
+		case "vcs.time":
+			if BuildDate == "unknown" || BuildDate == "" {
+				BuildDate = setting.Value
+			}
+		case "vcs.modified":
+			modified = setting.Value == "true"
+		}
+	}
+	if modified && len(GitCommit) == 40 {
+		GitCommit += "-dirty"
+	}
+}
+
+// UserAgent returns the User-Agent sent on Jules and GitHub API requests,
+// e.g. "juleson/v1.2.0 (commit 1a2b3c4d5e6f; built 2026-01-02T03:04:05Z; linux/amd64)".
+func UserAgent() string {
+	details := []string{}
+	if GitCommit != "" && GitCommit != "unknown" {
+		hash, dirty := strings.CutSuffix(GitCommit, "-dirty")
+		commit := "commit " + shortCommit(hash)
+		if dirty {
+			commit += "-dirty"
+		}
+		details = append(details, commit)
+	}
+	if BuildDate != "" && BuildDate != "unknown" {
+		details = append(details, "built "+BuildDate)
+	}
+	details = append(details, runtime.GOOS+"/"+runtime.GOARCH)
+	return fmt.Sprintf("juleson/%s (%s)", Version, strings.Join(details, "; "))
+}
+
+// runVersion displays version information and, when a newer release is
+// known, how to install it.
 func runVersion(cmd *cobra.Command, args []string) error {
 	info := GetVersionInfo()
-	fmt.Print(FormatVersion(info))
+	fmt.Fprint(cmd.OutOrStdout(), FormatVersion(info))
+	if hint := UpdateHint(cmd.Context()); hint != "" {
+		fmt.Fprintf(cmd.OutOrStdout(), "\n%s\n", hint)
+	}
 	return nil
 }
 
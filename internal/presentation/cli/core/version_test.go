@@ -0,0 +1,57 @@
+package core
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+	"time"
+)
+
+func TestUserAgent(t *testing.T) {
+	defer func(version, commit, date string) {
+		Version, GitCommit, BuildDate = version, commit, date
+	}(Version, GitCommit, BuildDate)
+	platform := runtime.GOOS + "/" + runtime.GOARCH
+
+	Version, GitCommit, BuildDate = "v1.2.0", "0123456789abcdef0123456789abcdef01234567-dirty", "2026-01-02T03:04:05Z"
+	want := "juleson/v1.2.0 (commit 0123456789ab-dirty; built 2026-01-02T03:04:05Z; " + platform + ")"
+	if got := UserAgent(); got != want {
+		t.Errorf("UserAgent() = %q, want %q", got, want)
+	}
+
+	Version, GitCommit, BuildDate = "dev", "unknown", "unknown"
+	if got, want := UserAgent(), "juleson/dev ("+platform+")"; got != want {
+		t.Errorf("UserAgent() = %q, want %q", got, want)
+	}
+}
+
+func TestUpdateHintSkipsDevelopmentBuilds(t *testing.T) {
+	defer func(version string) { Version = version }(Version)
+	Version = "dev"
+	if hint := UpdateHint(context.Background()); hint != "" {
+		t.Errorf("UpdateHint() = %q, want none for a development build", hint)
+	}
+}
+
+func TestUpdateHintDisabledByEnv(t *testing.T) {
+	defer func(version string) { Version = version }(Version)
+	Version = "v1.0.0"
+	t.Setenv(UpdateCheckEnv, "1")
+	if hint := UpdateHint(context.Background()); hint != "" {
+		t.Errorf("UpdateHint() = %q, want none with %s set", hint, UpdateCheckEnv)
+	}
+}
+
+func TestLatestReleaseUsesFreshCache(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "update-check.json")
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	cache := `{"checked_at":"2026-03-01T08:00:00Z","latest":"v9.9.9"}`
+	if err := os.WriteFile(path, []byte(cache), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if got := latestRelease(context.Background(), path, now); got != "v9.9.9" {
+		t.Errorf("latestRelease() = %q, want the cached v9.9.9", got)
+	}
+}
@@ -0,0 +1,394 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"os/signal"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/SamyRai/juleson/internal/config"
+	"github.com/SamyRai/juleson/internal/events"
+	"github.com/SamyRai/juleson/internal/filewatch"
+	"github.com/SamyRai/juleson/internal/jules/workspace"
+	"github.com/SamyRai/juleson/internal/logger"
+	"github.com/spf13/cobra"
+)
+
+// watchQueue is the message queue juleson watch runs triggers from, one at
+// a time.
+const watchQueue = "watch"
+
+// ownChangeGrace is how long after a trigger finishes changes are still
+// taken for its own, covering the delay before the watcher sees them.
+const ownChangeGrace = 250 * time.Millisecond
+
+// WatchOptions configures RunWatch.
+type WatchOptions struct {
+	Dir string
+	// OnChange are trigger names from watch.triggers or juleson command
+	// lines; empty runs every configured trigger.
+	OnChange []string
+	// Include limits the watched files to these patterns.
+	Include []string
+	// Debounce overrides watch.debounce.
+	Debounce   time.Duration
+	EventsPath string
+}
+
+// WatchTrigger is an action juleson watch runs when matching files change:
+// a juleson command line (Args) or another program (Command).
+type WatchTrigger struct {
+	Name    string
+	Paths   []string
+	Args    []string
+	Command []string
+}
+
+// Matches returns the changes of files matching the trigger's paths.
+func (t WatchTrigger) Matches(changes []filewatch.Change) []filewatch.Change {
+	if len(t.Paths) == 0 {
+		return changes
+	}
+	var matched []filewatch.Change
+	for _, change := range changes {
+		if workspace.MatchPath(t.Paths, change.Path) {
+			matched = append(matched, change)
+		}
+	}
+	return matched
+}
+
+// String returns the command line the trigger runs.
+func (t WatchTrigger) String() string {
+	if len(t.Args) > 0 {
+		return "juleson " + strings.Join(t.Args, " ")
+	}
+	return strings.Join(t.Command, " ")
+}
+
+// WatchTriggers resolves --on-change values, each the name of a trigger in
+// watch.triggers or a juleson command line, with or without the leading
+// "juleson". Without values, every configured trigger is returned.
+func WatchTriggers(cfg *config.Config, onChange []string) ([]WatchTrigger, error) {
+	configured := make(map[string]WatchTrigger, len(cfg.Watch.Triggers))
+	var all []WatchTrigger
+	for _, trigger := range cfg.Watch.Triggers {
+		t := WatchTrigger{Name: trigger.Name, Paths: trigger.Paths, Command: strings.Fields(trigger.Command)}
+		if trigger.Run != "" {
+			t.Args = julesonArgs(trigger.Run)
+		}
+		configured[trigger.Name] = t
+		all = append(all, t)
+	}
+	if len(onChange) == 0 {
+		return all, nil
+	}
+	var triggers []WatchTrigger
+	for _, value := range onChange {
+		if t, ok := configured[value]; ok {
+			triggers = append(triggers, t)
+			continue
+		}
+		args := julesonArgs(value)
+		if len(args) == 0 {
+			return nil, fmt.Errorf("--on-change must name a trigger or a juleson command, got %q", value)
+		}
+		triggers = append(triggers, WatchTrigger{Name: strings.Join(args, " "), Args: args})
+	}
+	return triggers, nil
+}
+
+// julesonArgs splits a juleson command line on whitespace, dropping a
+// leading "juleson".
+func julesonArgs(line string) []string {
+	args := strings.Fields(line)
+	if len(args) > 0 && args[0] == "juleson" {
+		args = args[1:]
+	}
+	return args
+}
+
+// watchTriggerState collects a trigger's changed files until its queued
+// message runs, and remembers when it last ran.
+type watchTriggerState struct {
+	mu     sync.Mutex
+	files  []string
+	queued bool
+	// busyFrom and busyUntil bound the trigger's last run; busyUntil is
+	// zero while it runs.
+	busyFrom, busyUntil time.Time
+}
+
+// add records the changes not made while the trigger ran and reports
+// whether a message must be enqueued for them.
+func (s *watchTriggerState) add(changes []filewatch.Change) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	added := false
+	for _, change := range changes {
+		if s.own(change.Time) {
+			continue
+		}
+		added = true
+		if !containsString(s.files, change.Path) {
+			s.files = append(s.files, change.Path)
+		}
+	}
+	if !added || s.queued {
+		return false
+	}
+	s.queued = true
+	return true
+}
+
+func (s *watchTriggerState) own(t time.Time) bool {
+	if s.busyFrom.IsZero() || t.Before(s.busyFrom) {
+		return false
+	}
+	return s.busyUntil.IsZero() || t.Before(s.busyUntil.Add(ownChangeGrace))
+}
+
+// start takes the files waiting for a run.
+func (s *watchTriggerState) start() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	files := s.files
+	s.files, s.queued = nil, false
+	s.busyFrom, s.busyUntil = time.Now(), time.Time{}
+	return files
+}
+
+func (s *watchTriggerState) finish() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.busyUntil = time.Now()
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// RunWatch watches the working tree until ctx is cancelled. Each debounced
+// batch of changes is published as change.detected events, and every
+// trigger matching a changed file is enqueued on the watch queue, whose
+// single worker runs the triggers one at a time. Changes made while a
+// trigger runs, including its own edits, do not fire it again.
+func RunWatch(ctx context.Context, cfg *config.Config, out io.Writer, options WatchOptions) error {
+	triggers, err := WatchTriggers(cfg, options.OnChange)
+	if err != nil {
+		return err
+	}
+	debounce := cfg.Watch.Debounce
+	if options.Debounce > 0 {
+		debounce = options.Debounce
+	}
+	watcher, err := filewatch.New(options.Dir, filewatch.Options{
+		Debounce: debounce,
+		Include:  options.Include,
+		Ignore:   cfg.Watch.Ignore,
+		Logger:   logger.For(logger.SubsystemEvents),
+	})
+	if err != nil {
+		return err
+	}
+	executable, err := os.Executable()
+	if err != nil {
+		return fmt.Errorf("failed to find the juleson executable: %w", err)
+	}
+
+	coordinator, err := newEventCoordinator(ctx, &events.CoordinatorConfig{
+		Logger:      logger.For(logger.SubsystemEvents),
+		EnableQueue: true,
+		// Triggers run once; a failure is reported, not retried.
+		QueueConfig: &events.QueueConfig{MaxQueueSize: 100, MaxRetries: 1, DLQMaxSize: 100},
+	}, options.EventsPath)
+	if err != nil {
+		return err
+	}
+	exporter := NewEventExporter(cfg)
+	defer func() {
+		_ = coordinator.Shutdown(context.WithoutCancel(ctx))
+		_ = exporter.Close(context.WithoutCancel(ctx))
+	}()
+	if notifier := NewNotifier(cfg); notifier.Enabled() {
+		if err := coordinator.Subscribe(events.TopicAll, notifier.Subscriber()); err != nil {
+			return err
+		}
+	}
+	if exporter.Enabled() {
+		if err := coordinator.Subscribe(events.TopicAll, exporter.Subscriber()); err != nil {
+			return err
+		}
+	}
+	if err := StartAlerts(ctx, cfg, coordinator); err != nil {
+		return err
+	}
+
+	states := make(map[string]*watchTriggerState, len(triggers))
+	byName := make(map[string]WatchTrigger, len(triggers))
+	for _, trigger := range triggers {
+		states[trigger.Name] = &watchTriggerState{}
+		byName[trigger.Name] = trigger
+	}
+	// out is written by the watcher and the worker.
+	var outMu sync.Mutex
+	printf := func(format string, args ...interface{}) {
+		outMu.Lock()
+		defer outMu.Unlock()
+		fmt.Fprintf(out, format, args...)
+	}
+
+	if err := coordinator.CreateQueue(watchQueue, 100); err != nil {
+		return err
+	}
+	if _, err := coordinator.RegisterWorker(watchQueue, func(_ context.Context, msg events.Message) error {
+		name, _ := msg.Payload.(string)
+		trigger, ok := byName[name]
+		if !ok || ctx.Err() != nil {
+			return nil
+		}
+		state := states[name]
+		files := state.start()
+		defer state.finish()
+		return runWatchTrigger(ctx, coordinator, out, &outMu, executable, watcher.Root(), trigger, files)
+	}); err != nil {
+		return err
+	}
+
+	if len(triggers) == 0 {
+		printf("👀 Watching %s; no triggers, publishing file-change events only\n", watcher.Root())
+	} else {
+		printf("👀 Watching %s with %d trigger(s); press Ctrl+C to stop\n", watcher.Root(), len(triggers))
+		for _, trigger := range triggers {
+			printf("   %s: %s\n", trigger.Name, trigger)
+		}
+	}
+
+	return watcher.Run(ctx, func(changes []filewatch.Change) {
+		printf("📝 %d file(s) changed\n", len(changes))
+		for _, change := range changes {
+			event := events.NewEvent(events.EventChangeDetected, "watch", events.ChangeEventData{
+				FilePath:   change.Path,
+				ChangeType: change.Type,
+			}).WithTopic(events.TopicChange)
+			_ = coordinator.PublishEvent(ctx, event)
+		}
+		for _, trigger := range triggers {
+			matched := trigger.Matches(changes)
+			if len(matched) == 0 || !states[trigger.Name].add(matched) {
+				continue
+			}
+			if err := coordinator.EnqueueMessage(events.Message{Type: "watch.trigger", Queue: watchQueue, Payload: trigger.Name}); err != nil {
+				printf("❌ %s: %v\n", trigger.Name, err)
+			}
+		}
+	})
+}
+
+// runWatchTrigger runs a trigger in root with the changed files in the
+// JULESON_CHANGED_FILES environment variable, one per line, publishing
+// workflow events around it.
+func runWatchTrigger(ctx context.Context, coordinator *events.EventCoordinator, out io.Writer, outMu *sync.Mutex, executable, root string, trigger WatchTrigger, files []string) error {
+	args := trigger.Command
+	if len(trigger.Args) > 0 {
+		args = append([]string{executable}, trigger.Args...)
+	}
+	publish := func(eventType events.EventType, err error, duration time.Duration) {
+		data := events.WorkflowEventData{WorkflowName: trigger.Name, Success: err == nil, Duration: duration}
+		if err != nil {
+			data.Error = err.Error()
+		}
+		event := events.NewEvent(eventType, "watch", data).WithTopic(events.TopicOrchestration).WithMetadata("files", files)
+		_ = coordinator.PublishEvent(ctx, event)
+	}
+
+	outMu.Lock()
+	fmt.Fprintf(out, "▶️  %s (%d changed file(s))\n", trigger.Name, len(files))
+	outMu.Unlock()
+	publish(events.EventWorkflowStarted, nil, 0)
+	started := time.Now()
+
+	cmd := exec.CommandContext(ctx, args[0], args[1:]...)
+	cmd.Dir = root
+	cmd.Env = append(os.Environ(), "JULESON_WATCH_TRIGGER="+trigger.Name, "JULESON_CHANGED_FILES="+strings.Join(files, "\n"))
+	output, err := cmd.CombinedOutput()
+	duration := time.Since(started)
+
+	outMu.Lock()
+	defer outMu.Unlock()
+	if len(output) > 0 {
+		out.Write(output) //nolint:errcheck
+	}
+	if err != nil {
+		publish(events.EventWorkflowFailed, err, duration)
+		fmt.Fprintf(out, "❌ %s failed after %s: %v\n", trigger.Name, duration.Round(time.Millisecond), err)
+		return err
+	}
+	publish(events.EventWorkflowCompleted, nil, duration)
+	fmt.Fprintf(out, "✅ %s finished in %s\n", trigger.Name, duration.Round(time.Millisecond))
+	return nil
+}
+
+// NewWatchCommand creates the watch command.
+func NewWatchCommand(cfg *config.Config) *cobra.Command {
+	var options WatchOptions
+
+	cmd := &cobra.Command{
+		Use:   "watch [path]",
+		Short: "Run workflows when files in the working tree change",
+		Long: `Watch the working tree and, once changes settle for the debounce interval,
+run the triggers matching the changed files. Each --on-change names a trigger
+from watch.triggers in the configuration or is a juleson command line; without
+--on-change every configured trigger is used.
+
+Every changed file is published as a change.detected event, and each trigger
+run as workflow events, to the configured notifications, event sinks, and the
+--events file. Triggers are queued and run one at a time from the working
+tree root, with the changed files, one per line, in JULESON_CHANGED_FILES.
+Changes made while a trigger runs, including its own edits, do not fire it
+again. Version control, dependency, and build directories are never watched.`,
+		Example: `  juleson watch --on-change 'template run code-cleanup'
+  juleson watch --include '*.go' --on-change 'dev test' --debounce 2s
+  juleson watch --on-change lint-and-fix --events watch-events.jsonl`,
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			options.Dir = "."
+			if len(args) > 0 {
+				options.Dir = args[0]
+			}
+			triggers, err := WatchTriggers(cfg, options.OnChange)
+			if err != nil {
+				return err
+			}
+			for _, trigger := range triggers {
+				if len(trigger.Args) == 0 {
+					continue
+				}
+				if found, _, err := cmd.Root().Find(trigger.Args); err != nil || found == cmd.Root() {
+					return fmt.Errorf("trigger %q: %q is not a juleson command or a trigger in watch.triggers", trigger.Name, trigger.Args[0])
+				}
+			}
+			ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+			defer stop()
+			return RunWatch(ctx, cfg, cmd.OutOrStdout(), options)
+		},
+	}
+
+	cmd.Flags().StringArrayVar(&options.OnChange, "on-change", nil, "Trigger name from watch.triggers, or juleson command line, to run on changes (repeatable)")
+	cmd.Flags().StringArrayVar(&options.Include, "include", nil, "Only watch files matching this glob, such as '*.go' or 'docs/' (repeatable)")
+	cmd.Flags().DurationVar(&options.Debounce, "debounce", 0, "How long changes must settle before triggers run (default watch.debounce)")
+	cmd.Flags().StringVar(&options.EventsPath, "events", "", "Append the file-change and trigger events to this JSONL file")
+
+	return cmd
+}
@@ -0,0 +1,153 @@
+package core
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/SamyRai/juleson/internal/config"
+	"github.com/SamyRai/juleson/internal/filewatch"
+)
+
+func TestWatchTriggers(t *testing.T) {
+	cfg := &config.Config{Watch: config.WatchConfig{Triggers: []config.WatchTriggerConfig{
+		{Name: "lint-and-fix", Paths: []string{"*.go"}, Run: "juleson template run code-cleanup"},
+		{Name: "docs", Paths: []string{"docs/"}, Command: "make docs"},
+	}}}
+
+	all, err := WatchTriggers(cfg, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(all) != 2 || !reflect.DeepEqual(all[0].Args, []string{"template", "run", "code-cleanup"}) || !reflect.DeepEqual(all[1].Command, []string{"make", "docs"}) {
+		t.Fatalf("configured triggers = %+v", all)
+	}
+
+	triggers, err := WatchTriggers(cfg, []string{"docs", "dev test"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(triggers) != 2 || triggers[0].Name != "docs" || triggers[1].String() != "juleson dev test" || triggers[1].Paths != nil {
+		t.Fatalf("triggers = %+v", triggers)
+	}
+	if _, err := WatchTriggers(cfg, []string{"juleson"}); err == nil {
+		t.Error("expected an error for an empty command line")
+	}
+
+	changes := []filewatch.Change{{Path: "main.go"}, {Path: "docs/guide.md"}, {Path: "internal/x/x.go"}}
+	if matched := all[0].Matches(changes); len(matched) != 2 || matched[1].Path != "internal/x/x.go" {
+		t.Errorf("lint-and-fix matched %+v", matched)
+	}
+	if matched := all[1].Matches(changes); len(matched) != 1 || matched[0].Path != "docs/guide.md" {
+		t.Errorf("docs matched %+v", matched)
+	}
+}
+
+func TestWatchTriggerStateSkipsOwnChanges(t *testing.T) {
+	var state watchTriggerState
+	now := time.Now()
+	if !state.add([]filewatch.Change{{Path: "a.go", Time: now}}) {
+		t.Fatal("first change did not enqueue the trigger")
+	}
+	if state.add([]filewatch.Change{{Path: "b.go", Time: now}, {Path: "a.go", Time: now}}) {
+		t.Error("a queued trigger was enqueued again")
+	}
+	if files := state.start(); !reflect.DeepEqual(files, []string{"a.go", "b.go"}) {
+		t.Errorf("files = %v", files)
+	}
+	if state.add([]filewatch.Change{{Path: "a.go", Time: time.Now()}}) {
+		t.Error("a change made while the trigger ran fired it")
+	}
+	state.finish()
+	if state.add([]filewatch.Change{{Path: "a.go", Time: time.Now()}}) {
+		t.Error("a change seen right after the run fired it")
+	}
+	if !state.add([]filewatch.Change{{Path: "a.go", Time: time.Now().Add(time.Second)}}) {
+		t.Error("a later change did not fire the trigger")
+	}
+}
+
+func TestRunWatch(t *testing.T) {
+	root := t.TempDir()
+	journal := filepath.Join(t.TempDir(), "events.jsonl")
+	cfg := &config.Config{Watch: config.WatchConfig{Triggers: []config.WatchTriggerConfig{
+		{Name: "mark", Paths: []string{"*.go"}, Command: "touch marker.go"},
+	}}}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	var out syncBuffer
+	done := make(chan error, 1)
+	go func() {
+		done <- RunWatch(ctx, cfg, &out, WatchOptions{Dir: root, Debounce: 100 * time.Millisecond, EventsPath: journal})
+	}()
+	waitFor := func(what string, ok func() bool) {
+		t.Helper()
+		deadline := time.Now().Add(10 * time.Second)
+		for !ok() {
+			if time.Now().After(deadline) {
+				cancel()
+				t.Fatalf("timed out waiting for %s; output:\n%s", what, out.String())
+			}
+			time.Sleep(50 * time.Millisecond)
+		}
+	}
+	waitFor("the watcher", func() bool { return strings.Contains(out.String(), "Watching") })
+	time.Sleep(200 * time.Millisecond)
+
+	if err := os.WriteFile(filepath.Join(root, "main.go"), []byte("package main\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	waitFor("the trigger", func() bool { return strings.Contains(out.String(), "✅ mark finished") })
+	// The marker the trigger touched must not fire it again.
+	time.Sleep(time.Second)
+	cancel()
+	if err := <-done; err != nil {
+		t.Fatalf("RunWatch: %v", err)
+	}
+	if n := strings.Count(out.String(), "▶️  mark"); n != 1 {
+		t.Errorf("trigger ran %d times; output:\n%s", n, out.String())
+	}
+
+	data, err := os.ReadFile(journal)
+	if err != nil {
+		t.Fatal(err)
+	}
+	types := map[string]int{}
+	for _, line := range strings.Split(strings.TrimSpace(string(data)), "\n") {
+		var event struct {
+			Type string `json:"type"`
+		}
+		if err := json.Unmarshal([]byte(line), &event); err != nil {
+			t.Fatalf("bad journal line %q: %v", line, err)
+		}
+		types[event.Type]++
+	}
+	if types["change.detected"] < 2 || types["workflow.started"] != 1 || types["workflow.completed"] != 1 {
+		t.Errorf("journal event types = %v", types)
+	}
+}
+
+// syncBuffer is a bytes.Buffer safe for the watcher and a test to share.
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
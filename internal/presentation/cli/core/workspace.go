@@ -0,0 +1,196 @@
+package core
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"text/tabwriter"
+
+	"github.com/SamyRai/juleson/internal/jules/workspace"
+	"github.com/spf13/cobra"
+)
+
+// NewWorkspaceCommand creates the workspace command.
+func NewWorkspaceCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "workspace",
+		Short: "Map local repositories to Jules sources and GitHub repos",
+		Long: `A workspace maps local directories to Jules sources and GitHub repositories in a
+` + workspace.FileName + ` file. Inside a workspace, 'sessions create .', 'plan', and the
+'github' commands use the entry for the current directory instead of looking up
+the git origin remote, and 'sessions create --workspace' starts one session per
+repository.`,
+	}
+
+	cmd.AddCommand(newWorkspaceInitCommand())
+	cmd.AddCommand(newWorkspaceAddCommand())
+	cmd.AddCommand(newWorkspaceRemoveCommand())
+	cmd.AddCommand(newWorkspaceListCommand())
+	return cmd
+}
+
+func newWorkspaceInitCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "init [dir]",
+		Short: "Create a workspace file",
+		Args:  cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			dir := "."
+			if len(args) == 1 {
+				dir = args[0]
+			}
+			ws, err := workspace.Init(dir)
+			if err != nil {
+				return err
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "✅ Created %s\n", filepath.Join(ws.Root, workspace.FileName))
+			fmt.Fprintln(cmd.OutOrStdout(), "💡 Add repositories with 'juleson workspace add <dir>...'")
+			return nil
+		},
+	}
+}
+
+func newWorkspaceAddCommand() *cobra.Command {
+	var source, repo, gitlab, bitbucket string
+
+	cmd := &cobra.Command{
+		Use:   "add <dir>...",
+		Short: "Add repositories to the workspace",
+		Long: `Add local repositories to the enclosing workspace. The Jules source and GitHub
+repository come from each directory's git origin remote unless --source and
+--repo are given, which is only possible for a single directory. Repositories
+hosted on GitLab or Bitbucket are added with --gitlab or --bitbucket; Jules
+cannot work on them, but the 'vcs' commands can.
+
+Examples:
+  juleson workspace add api web
+  juleson workspace add services/billing --source github/acme/billing --repo ghe.example.com/acme/billing
+  juleson workspace add infra --gitlab acme/platform/infra
+  juleson workspace add mobile --bitbucket acme/mobile`,
+		Args: cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			hosted := gitlab != "" || bitbucket != ""
+			if (source != "" || repo != "" || hosted) && len(args) > 1 {
+				return fmt.Errorf("--source, --repo, --gitlab, and --bitbucket apply to a single directory")
+			}
+			if hosted && (source != "" || repo != "" || (gitlab != "" && bitbucket != "")) {
+				return fmt.Errorf("--gitlab and --bitbucket cannot be combined with each other, --source, or --repo")
+			}
+			ws, err := findWorkspace()
+			if err != nil {
+				return err
+			}
+			for _, dir := range args {
+				entry := workspace.Repo{Source: source, GitHub: repo, GitLab: gitlab, Bitbucket: bitbucket}
+				if !hosted && (source == "" || repo == "") {
+					remote, err := workspace.DescribeRemote(cmd.Context(), dir)
+					if err != nil && source == "" && repo == "" {
+						return fmt.Errorf("%w (pass --source and --repo)", err)
+					}
+					if entry.Source == "" {
+						entry.Source = remote.Source
+					}
+					if entry.GitHub == "" {
+						entry.GitHub = remote.GitHub
+					}
+				}
+				added, err := ws.Add(dir, entry)
+				if err != nil {
+					return err
+				}
+				fmt.Fprintf(cmd.OutOrStdout(), "✅ Added %s (source: %s, repo: %s)\n", added.Path, valueOrNone(added.Source), valueOrNone(workspaceRepoName(added)))
+			}
+			return ws.Save()
+		},
+	}
+	cmd.Flags().StringVar(&source, "source", "", "Jules source, such as github/owner/repo")
+	cmd.Flags().StringVar(&repo, "repo", "", "GitHub repository as owner/name or HOST/owner/name")
+	cmd.Flags().StringVar(&gitlab, "gitlab", "", "GitLab repository as group/name or HOST/group/name")
+	cmd.Flags().StringVar(&bitbucket, "bitbucket", "", "Bitbucket Cloud repository as workspace/name")
+	return cmd
+}
+
+func newWorkspaceRemoveCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "remove <dir>...",
+		Short: "Remove repositories from the workspace",
+		Args:  cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ws, err := findWorkspace()
+			if err != nil {
+				return err
+			}
+			for _, dir := range args {
+				removed, err := ws.Remove(dir)
+				if err != nil {
+					return err
+				}
+				if !removed {
+					return fmt.Errorf("%s is not in the workspace", dir)
+				}
+				fmt.Fprintf(cmd.OutOrStdout(), "✅ Removed %s\n", dir)
+			}
+			return ws.Save()
+		},
+	}
+}
+
+func newWorkspaceListCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "list",
+		Short: "List the workspace's repositories",
+		Long:  "List the repositories in the enclosing workspace. The one containing the current directory is marked with *.",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ws, err := findWorkspace()
+			if err != nil {
+				return err
+			}
+			out := cmd.OutOrStdout()
+			fmt.Fprintf(out, "Workspace: %s\n", ws.Root)
+			if len(ws.Repos) == 0 {
+				fmt.Fprintln(out, "No repositories. Add them with 'juleson workspace add <dir>...'")
+				return nil
+			}
+			current, inRepo := ws.Lookup(".")
+			w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+			fmt.Fprintln(w, "\tPATH\tSOURCE\tREPO")
+			for _, repo := range ws.Repos {
+				marker := ""
+				if inRepo && repo.Path == current.Path {
+					marker = "*"
+				}
+				fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", marker, repo.Path, valueOrNone(repo.Source), valueOrNone(workspaceRepoName(repo)))
+			}
+			return w.Flush()
+		},
+	}
+}
+
+// workspaceRepoName returns the repository of a workspace entry, with
+// GitLab and Bitbucket repositories marked.
+func workspaceRepoName(repo workspace.Repo) string {
+	switch {
+	case repo.GitLab != "":
+		return "gitlab:" + repo.GitLab
+	case repo.Bitbucket != "":
+		return "bitbucket:" + repo.Bitbucket
+	}
+	return repo.GitHub
+}
+
+// findWorkspace loads the workspace enclosing the current directory.
+func findWorkspace() (*workspace.Workspace, error) {
+	ws, err := workspace.Find(".")
+	if errors.Is(err, workspace.ErrNoWorkspace) {
+		return nil, fmt.Errorf("%w; run 'juleson workspace init' first", err)
+	}
+	return ws, err
+}
+
+func valueOrNone(value string) string {
+	if value == "" {
+		return "-"
+	}
+	return value
+}
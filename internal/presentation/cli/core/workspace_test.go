@@ -0,0 +1,59 @@
+package core
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func runWorkspaceCommand(t *testing.T, args ...string) (string, error) {
+	t.Helper()
+	cmd := NewWorkspaceCommand()
+	var out bytes.Buffer
+	cmd.SetOut(&out)
+	cmd.SetErr(&out)
+	cmd.SetArgs(args)
+	err := cmd.Execute()
+	return out.String(), err
+}
+
+func TestWorkspaceCommands(t *testing.T) {
+	root := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(root, "api"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	t.Chdir(root)
+
+	if _, err := runWorkspaceCommand(t, "list"); err == nil || !strings.Contains(err.Error(), "workspace init") {
+		t.Fatalf("list without a workspace: err = %v, want a hint to run init", err)
+	}
+	if _, err := runWorkspaceCommand(t, "init"); err != nil {
+		t.Fatalf("init: %v", err)
+	}
+	if _, err := runWorkspaceCommand(t, "add", "api", "web", "--source", "github/acme/api"); err == nil {
+		t.Fatal("add with --source and several directories succeeded")
+	}
+	if _, err := runWorkspaceCommand(t, "add", "api", "--source", "github/acme/api", "--repo", "acme/api"); err != nil {
+		t.Fatalf("add: %v", err)
+	}
+
+	t.Chdir(filepath.Join(root, "api"))
+	out, err := runWorkspaceCommand(t, "list")
+	if err != nil {
+		t.Fatalf("list: %v", err)
+	}
+	for _, want := range []string{"Workspace: " + root, "*  api", "sources/github/acme/api", "acme/api"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("list output missing %q:\n%s", want, out)
+		}
+	}
+
+	if _, err := runWorkspaceCommand(t, "remove", "."); err != nil {
+		t.Fatalf("remove: %v", err)
+	}
+	if out, _ := runWorkspaceCommand(t, "list"); !strings.Contains(out, "No repositories") {
+		t.Errorf("list after remove = %q, want no repositories", out)
+	}
+}
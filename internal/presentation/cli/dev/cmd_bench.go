@@ -0,0 +1,126 @@
+package dev
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+
+	"github.com/SamyRai/juleson/internal/config"
+	"github.com/SamyRai/juleson/internal/logger"
+	"github.com/SamyRai/juleson/pkg/build"
+	"github.com/SamyRai/juleson/pkg/builder"
+	"github.com/spf13/cobra"
+)
+
+func (h *CommandHandler) BenchCmd() *cobra.Command {
+	var (
+		pattern      string
+		benchtime    string
+		count        int
+		saveBaseline bool
+		jsonOutput   bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "bench [packages...]",
+		Short: "Run benchmarks and check them against their budgets",
+		Long: "Run the event bus, queue, and store benchmarks, or those of the given packages, and fail when a " +
+			"benchmark exceeds its budget in bench.budgets or regresses from the saved baseline by more than " +
+			"bench.max_regression percent. --save-baseline records the results as the new baseline.",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			options := benchOptions(h.cfg)
+			if cmd.Flags().Changed("bench") {
+				options.Config.Pattern = pattern
+			}
+			if cmd.Flags().Changed("benchtime") {
+				options.Config.Benchtime = benchtime
+			}
+			if cmd.Flags().Changed("count") {
+				options.Config.Count = count
+			}
+			if len(args) > 0 {
+				options.Config.Packages = args
+			}
+
+			if saveBaseline && options.Baseline == "" {
+				return fmt.Errorf("--save-baseline needs bench.baseline to be set")
+			}
+
+			slog.Info("Running benchmarks...", "packages", options.Config.Packages)
+			report, err := h.svc.RunBenchmarks(context.Background(), options)
+			if err != nil {
+				return fmt.Errorf("benchmarks failed: %w", err)
+			}
+			if saveBaseline {
+				if err := build.SaveBenchBaseline(options.Baseline, report.Results); err != nil {
+					return fmt.Errorf("failed to save the benchmark baseline: %w", err)
+				}
+				logger.Success(slog.Default(), "Benchmark baseline saved", "path", options.Baseline)
+			}
+
+			if jsonOutput {
+				encoder := json.NewEncoder(os.Stdout)
+				encoder.SetIndent("", "  ")
+				if err := encoder.Encode(report); err != nil {
+					return err
+				}
+			} else {
+				for _, result := range report.Results {
+					fmt.Printf("%-45s %12.0f ns/op %10.0f B/op %8.1f allocs/op\n",
+						result.Name, result.NsPerOp, result.BytesPerOp, result.AllocsPerOp)
+				}
+				for _, violation := range report.Violations {
+					fmt.Printf("❌ %s\n", violation)
+				}
+			}
+			if !report.Passed() {
+				return fmt.Errorf("%d benchmark measurement(s) over budget", len(report.Violations))
+			}
+			if !jsonOutput {
+				fmt.Printf("\n✅ %s\n", report.String())
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&pattern, "bench", ".", "Run only benchmarks matching this regular expression (default: bench.pattern)")
+	cmd.Flags().StringVar(&benchtime, "benchtime", "", "Run each benchmark for this long or this many times, such as 2s or 1000x (default: bench.benchtime)")
+	cmd.Flags().IntVar(&count, "count", 1, "Run each benchmark this many times and average the results (default: bench.count)")
+	cmd.Flags().BoolVar(&saveBaseline, "save-baseline", false, "Save the results to bench.baseline for later runs to compare against")
+	cmd.Flags().BoolVar(&jsonOutput, "json", false, "Print the results as JSON")
+
+	return cmd
+}
+
+// benchOptions returns the benchmarks and budgets configured in the bench
+// section of cfg.
+func benchOptions(cfg *config.Config) builder.BenchOptions {
+	options := builder.BenchOptions{Config: builder.DefaultBenchConfig()}
+	if cfg == nil {
+		return options
+	}
+	bench := cfg.Bench
+	if len(bench.Packages) > 0 {
+		options.Config.Packages = bench.Packages
+	}
+	if bench.Pattern != "" {
+		options.Config.Pattern = bench.Pattern
+	}
+	if bench.Count > 0 {
+		options.Config.Count = bench.Count
+	}
+	options.Config.Benchtime = bench.Benchtime
+	options.Baseline = bench.Baseline
+	options.MaxRegression = bench.MaxRegression
+	for _, budget := range bench.Budgets {
+		options.Budgets = append(options.Budgets, build.BenchBudget{
+			Name:           budget.Name,
+			MaxNsPerOp:     budget.MaxNsPerOp,
+			MaxBytesPerOp:  budget.MaxBytesPerOp,
+			MaxAllocsPerOp: budget.MaxAllocsPerOp,
+		})
+	}
+	return options
+}
@@ -6,6 +6,8 @@ import (
 	"log/slog"
 
 	"github.com/SamyRai/juleson/internal/logger"
+	"github.com/SamyRai/juleson/internal/policy"
+	"github.com/SamyRai/juleson/internal/presentation/cli/core"
 	"github.com/SamyRai/juleson/pkg/builder"
 	"github.com/spf13/cobra"
 )
@@ -24,6 +26,13 @@ func (h *CommandHandler) CleanCmd() *cobra.Command {
 		Long:  "Clean build artifacts, caches, and generated files",
 		RunE: func(cmd *cobra.Command, args []string) error {
 			ctx := context.Background()
+			if all || cache || modCache {
+				if err := core.EnforcePolicy(h.cfg, policy.Check{
+					Request: policy.Request{Operation: policy.OpCleanCache, Tool: "dev clean"},
+				}, true); err != nil {
+					return err
+				}
+			}
 			slog.Info("Cleaning...")
 
 			_, err := h.svc.CleanArtifacts(ctx, builder.CleanOptions{
@@ -174,7 +174,8 @@ func (h *CommandHandler) CheckCmd() *cobra.Command {
 	return &cobra.Command{
 		Use:   "check",
 		Short: "Run all quality checks",
-		Long:  "Run formatting, linting, and tests",
+		Long: "Run formatting, linting, tests, and the build, plus the benchmarks when bench.budgets or " +
+			"bench.max_regression is configured",
 		RunE: func(cmd *cobra.Command, args []string) error {
 			ctx := context.Background()
 
@@ -184,14 +185,20 @@ func (h *CommandHandler) CheckCmd() *cobra.Command {
 			config := builder.DefaultTestConfig()
 			config.Cover = true
 			config.CoverProfile = "coverage.out"
+			bench := h.cfg != nil && h.cfg.Bench.Enabled()
+			if bench {
+				fmt.Println("\n⏱️  Running benchmarks...")
+			}
 			fmt.Println("\n🔨 Building binaries...")
 
 			summary, err := h.svc.RunQualityChecks(ctx, builder.QualityOptions{
-				Format:     true,
-				Lint:       true,
-				Test:       true,
-				TestConfig: config,
-				Build:      true,
+				Format:       true,
+				Lint:         true,
+				Test:         true,
+				TestConfig:   config,
+				Bench:        bench,
+				BenchOptions: benchOptions(h.cfg),
+				Build:        true,
 			})
 			if err != nil {
 				return err
@@ -200,6 +207,9 @@ func (h *CommandHandler) CheckCmd() *cobra.Command {
 			if summary.TestResult != nil {
 				fmt.Printf("✅ %s\n", summary.TestResult.String())
 			}
+			if summary.BenchReport != nil {
+				fmt.Printf("✅ %s\n", summary.BenchReport.String())
+			}
 
 			fmt.Println("\n🎉 All checks passed!")
 			return nil
@@ -11,7 +11,10 @@ import (
 
 // newCheckComplexityCommand creates the check-complexity command.
 func newCheckComplexityCommand() *cobra.Command {
-	var threshold int
+	var (
+		threshold   int
+		incremental bool
+	)
 
 	cmd := &cobra.Command{
 		Use:   "check-complexity [path]",
@@ -26,7 +29,22 @@ func newCheckComplexityCommand() *cobra.Command {
 
 			slog.Info("Analyzing code complexity...")
 
-			results, err := intelligence.AnalyzeComplexity(context.Background(), path)
+			var (
+				results []intelligence.FunctionComplexity
+				err     error
+			)
+			if incremental {
+				cache := intelligence.OpenProjectCache(path)
+				results, err = intelligence.AnalyzeComplexityIncremental(context.Background(), path, cache)
+				if saveErr := cache.Save(); saveErr != nil {
+					slog.Warn("Failed to save analysis cache", "error", saveErr)
+				}
+				if hits, misses := cache.Stats(); err == nil {
+					slog.Info("Incremental analysis", "cached_files", hits, "analyzed_files", misses)
+				}
+			} else {
+				results, err = intelligence.AnalyzeComplexity(context.Background(), path)
+			}
 			if err != nil {
 				return fmt.Errorf("complexity analysis failed: %w", err)
 			}
@@ -53,6 +71,7 @@ func newCheckComplexityCommand() *cobra.Command {
 	}
 
 	cmd.Flags().IntVar(&threshold, "threshold", 10, "Minimum complexity score to report")
+	cmd.Flags().BoolVar(&incremental, "incremental", false, "Analyze syntax per file and reuse cached results for unchanged files")
 
 	return cmd
 }
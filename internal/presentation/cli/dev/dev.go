@@ -1,12 +1,13 @@
 package dev
 
 import (
+	"github.com/SamyRai/juleson/internal/config"
 	"github.com/SamyRai/juleson/pkg/builder"
 	"github.com/spf13/cobra"
 )
 
 // NewDevCommand creates the dev command for developer tools.
-func NewDevCommand() *cobra.Command {
+func NewDevCommand(cfg *config.Config) *cobra.Command {
 	devCmd := &cobra.Command{
 		Use:   "dev",
 		Short: "Developer tools and build commands",
@@ -15,7 +16,7 @@ func NewDevCommand() *cobra.Command {
 
 	// We pass a default dev builder to the commands here
 	svc := builder.NewService(builder.DefaultConfig("dev", "", ""))
-	handler := NewCommandHandler(svc)
+	handler := NewCommandHandler(cfg, svc)
 
 	devCmd.AddCommand(handler.BuildCmd())
 	devCmd.AddCommand(handler.TestCmd())
@@ -24,12 +25,16 @@ func NewDevCommand() *cobra.Command {
 	devCmd.AddCommand(handler.CleanCmd())
 	devCmd.AddCommand(handler.ModCmd())
 	devCmd.AddCommand(handler.CheckCmd())
+	devCmd.AddCommand(handler.BenchCmd())
 	devCmd.AddCommand(handler.InstallCmd())
 	devCmd.AddCommand(handler.ReleaseCmd())
 
 	// Add existing commands from complexity.go and deps.go which are un-refactored
 	devCmd.AddCommand(newCheckComplexityCommand())
 	devCmd.AddCommand(newDepsCommand())
+	devCmd.AddCommand(newImpactCommand())
+	devCmd.AddCommand(newSmellsCommand())
+	devCmd.AddCommand(newSecretsCommand())
 
 	return devCmd
 }
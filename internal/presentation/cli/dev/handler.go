@@ -1,15 +1,17 @@
 package dev
 
 import (
+	"github.com/SamyRai/juleson/internal/config"
 	"github.com/SamyRai/juleson/pkg/builder"
 )
 
 // CommandHandler encapsulates the dependencies for dev commands.
 type CommandHandler struct {
+	cfg *config.Config
 	svc *builder.Service
 }
 
 // NewCommandHandler creates a new handler.
-func NewCommandHandler(svc *builder.Service) *CommandHandler {
-	return &CommandHandler{svc: svc}
+func NewCommandHandler(cfg *config.Config, svc *builder.Service) *CommandHandler {
+	return &CommandHandler{cfg: cfg, svc: svc}
 }
@@ -0,0 +1,108 @@
+package dev
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+
+	"github.com/SamyRai/juleson/internal/intelligence"
+	"github.com/SamyRai/juleson/pkg/git"
+	"github.com/spf13/cobra"
+)
+
+// newImpactCommand creates the impact command.
+func newImpactCommand() *cobra.Command {
+	var (
+		path       string
+		jsonOutput bool
+		noCache    bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "impact [changed-file...]",
+		Short: "Show packages and tests affected by changed files",
+		Long: "Build the Go, JavaScript/TypeScript, and Python dependency graph of a project and list what " +
+			"the changed files affect, with test commands scoped to them. Without arguments, the files " +
+			"changed in the git working tree are used.",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := context.Background()
+			files := args
+			if len(files) == 0 {
+				changed, err := workingTreeChanges(ctx, path)
+				if err != nil {
+					return err
+				}
+				files = changed
+			}
+
+			var cache *intelligence.AnalysisCache
+			if !noCache {
+				cache = intelligence.OpenProjectCache(path)
+			}
+			graph, err := intelligence.BuildProjectGraphCached(ctx, path, cache)
+			if err != nil {
+				return fmt.Errorf("dependency analysis failed: %w", err)
+			}
+			if err := cache.Save(); err != nil {
+				slog.Warn("Failed to save analysis cache", "error", err)
+			}
+			impact := graph.ImpactAnalysis(files)
+
+			if jsonOutput {
+				encoder := json.NewEncoder(os.Stdout)
+				encoder.SetIndent("", "  ")
+				return encoder.Encode(impact)
+			}
+			fmt.Printf("Changed: %d file(s), %d package(s)/module(s)\n", len(impact.ChangedFiles), len(impact.Changed))
+			fmt.Printf("Affected (%d):\n", len(impact.Affected))
+			for _, id := range impact.Affected {
+				fmt.Printf("  %s\n", id)
+			}
+			if len(impact.Unmapped) > 0 {
+				fmt.Printf("Not in the graph (%d):\n", len(impact.Unmapped))
+				for _, file := range impact.Unmapped {
+					fmt.Printf("  %s\n", file)
+				}
+			}
+			if commands := impact.TestCommands(); len(commands) > 0 {
+				fmt.Println("Test commands:")
+				for _, command := range commands {
+					fmt.Printf("  %s\n", command)
+				}
+			} else {
+				fmt.Println("No affected tests.")
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&path, "path", ".", "Project root")
+	cmd.Flags().BoolVar(&jsonOutput, "json", false, "Print the impact analysis as JSON")
+	cmd.Flags().BoolVar(&noCache, "no-cache", false, "Re-read every file instead of reusing cached imports")
+
+	return cmd
+}
+
+// workingTreeChanges returns absolute paths of the files changed in the git
+// working tree containing dir.
+func workingTreeChanges(ctx context.Context, dir string) ([]string, error) {
+	repo, err := git.Open(ctx, dir)
+	if err != nil {
+		return nil, err
+	}
+	status, err := repo.Status(ctx)
+	if err != nil {
+		return nil, err
+	}
+	files := make([]string, 0, len(status.Files))
+	for _, file := range status.Files {
+		files = append(files, filepath.Join(repo.Root, filepath.FromSlash(file.Path)))
+		if file.OrigPath != "" {
+			files = append(files, filepath.Join(repo.Root, filepath.FromSlash(file.OrigPath)))
+		}
+	}
+	return files, nil
+}
@@ -0,0 +1,59 @@
+package dev
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/SamyRai/juleson/internal/intelligence"
+	"github.com/spf13/cobra"
+)
+
+// newSecretsCommand creates the secrets command.
+func newSecretsCommand() *cobra.Command {
+	var jsonOutput bool
+
+	cmd := &cobra.Command{
+		Use:   "secrets [path]",
+		Short: "Scan project files for leaked credentials",
+		Long: "Scan text files for likely credentials such as cloud keys, API tokens, and private keys, using " +
+			"gitleaks-style provider patterns plus an entropy check for generic assignments. Add " +
+			"juleson:allow-secret or gitleaks:allow to a line to ignore it. Exits non-zero when secrets are found.",
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			path := "."
+			if len(args) > 0 {
+				path = args[0]
+			}
+
+			findings, err := intelligence.ScanProjectSecrets(context.Background(), path)
+			if err != nil {
+				return fmt.Errorf("secret scan failed: %w", err)
+			}
+
+			if jsonOutput {
+				encoder := json.NewEncoder(os.Stdout)
+				encoder.SetIndent("", "  ")
+				if err := encoder.Encode(findings); err != nil {
+					return err
+				}
+			} else if len(findings) == 0 {
+				fmt.Println("✅ No secrets found")
+			} else {
+				for _, finding := range findings {
+					fmt.Printf("%s:%d: %s: %s (%s)\n", finding.File, finding.Line, finding.RuleID, finding.Description, finding.Match)
+				}
+			}
+			if len(findings) > 0 {
+				cmd.SilenceUsage = true
+				return fmt.Errorf("found %d possible secret(s)", len(findings))
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVar(&jsonOutput, "json", false, "Print findings as JSON")
+
+	return cmd
+}
@@ -0,0 +1,71 @@
+package dev
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+
+	"github.com/SamyRai/juleson/internal/intelligence"
+	"github.com/spf13/cobra"
+)
+
+// newSmellsCommand creates the smells command.
+func newSmellsCommand() *cobra.Command {
+	options := intelligence.DefaultSmellOptions()
+	var (
+		jsonOutput bool
+		noCache    bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "smells [path]",
+		Short: "Find complex, long, and wide functions and unused exports",
+		Long: "Analyze Go syntax with the standard library parser, without external tools, and report functions " +
+			"over the complexity, length, and parameter thresholds, plus exports of internal packages that no " +
+			"other package uses. A threshold of 0 disables its check.",
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			path := "."
+			if len(args) > 0 {
+				path = args[0]
+			}
+			if !noCache {
+				options.Cache = intelligence.OpenProjectCache(path)
+			}
+
+			smells, err := intelligence.DetectSmells(context.Background(), path, options)
+			if err != nil {
+				return fmt.Errorf("smell detection failed: %w", err)
+			}
+			if err := options.Cache.Save(); err != nil {
+				slog.Warn("Failed to save analysis cache", "error", err)
+			}
+
+			if jsonOutput {
+				encoder := json.NewEncoder(os.Stdout)
+				encoder.SetIndent("", "  ")
+				return encoder.Encode(smells)
+			}
+			if len(smells) == 0 {
+				fmt.Println("✅ No smells found")
+				return nil
+			}
+			for _, smell := range smells {
+				fmt.Printf("%s:%d: %s: %s\n", smell.File, smell.Line, smell.Kind, smell.Message)
+			}
+			fmt.Printf("\n⚠️  Found %d smell(s)\n", len(smells))
+			return nil
+		},
+	}
+
+	cmd.Flags().IntVar(&options.MaxComplexity, "max-complexity", options.MaxComplexity, "Maximum cyclomatic complexity per function")
+	cmd.Flags().IntVar(&options.MaxLines, "max-lines", options.MaxLines, "Maximum lines per function")
+	cmd.Flags().IntVar(&options.MaxParams, "max-params", options.MaxParams, "Maximum parameters per function")
+	cmd.Flags().BoolVar(&options.UnusedExports, "unused-exports", options.UnusedExports, "Report unused exports of internal packages")
+	cmd.Flags().BoolVar(&jsonOutput, "json", false, "Print smells as JSON")
+	cmd.Flags().BoolVar(&noCache, "no-cache", false, "Re-parse every file instead of reusing cached results")
+
+	return cmd
+}
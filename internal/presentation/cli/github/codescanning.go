@@ -0,0 +1,108 @@
+package github
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/SamyRai/juleson/internal/config"
+	ghclient "github.com/SamyRai/juleson/internal/github"
+	"github.com/SamyRai/juleson/pkg/git"
+	"github.com/spf13/cobra"
+)
+
+func newCodeScanningCommand(cfg *config.Config) *cobra.Command {
+	var repoSlug string
+
+	cmd := &cobra.Command{
+		Use:   "code-scanning",
+		Short: "Upload SARIF results to GitHub code scanning",
+		Long: `Upload SARIF logs, such as those from juleson analyze --format sarif, to GitHub code scanning.
+
+Examples:
+  juleson analyze project --format sarif > juleson.sarif
+  juleson github code-scanning upload juleson.sarif`,
+	}
+	cmd.PersistentFlags().StringVar(&repoSlug, "repo", "", "Repository as owner/name or HOST/owner/name (default: from the workspace or the git origin remote)")
+
+	cmd.AddCommand(newCodeScanningUploadCommand(cfg, &repoSlug))
+
+	return cmd
+}
+
+func newCodeScanningUploadCommand(cfg *config.Config, repoSlug *string) *cobra.Command {
+	var opts ghclient.UploadSARIFOptions
+
+	cmd := &cobra.Command{
+		Use:   "upload <file.sarif>",
+		Short: "Upload a SARIF log for a commit",
+		Long: "Upload a SARIF log for a commit. The commit and ref default to GITHUB_SHA and GITHUB_REF in GitHub " +
+			"Actions, and to the current branch elsewhere. The token needs the security_events scope.",
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			data, err := os.ReadFile(args[0])
+			if err != nil {
+				return err
+			}
+			if err := resolveUploadCommit(context.Background(), &opts); err != nil {
+				return err
+			}
+			client, owner, repo, err := releaseTarget(cfg, *repoSlug)
+			if err != nil {
+				return err
+			}
+
+			id, err := client.CodeScanning.UploadSARIF(context.Background(), owner, repo, data, opts)
+			if err != nil {
+				return err
+			}
+			out := cmd.OutOrStdout()
+			fmt.Fprintf(out, "✅ Uploaded %s to %s/%s for %s (%s)\n", args[0], owner, repo, opts.Ref, opts.CommitSHA)
+			if id != "" {
+				fmt.Fprintf(out, "Upload ID: %s\n", id)
+			}
+			return nil
+		},
+	}
+	cmd.Flags().StringVar(&opts.CommitSHA, "sha", "", "Commit the results belong to (default: GITHUB_SHA or HEAD)")
+	cmd.Flags().StringVar(&opts.Ref, "ref", "", "Full Git ref such as refs/heads/main (default: GITHUB_REF or the current branch)")
+	cmd.Flags().StringVar(&opts.ToolName, "tool-name", "", "Tool name to record with the analysis")
+
+	return cmd
+}
+
+// resolveUploadCommit fills in the commit and ref from the GitHub Actions
+// environment, then from the current branch.
+func resolveUploadCommit(ctx context.Context, opts *ghclient.UploadSARIFOptions) error {
+	if opts.CommitSHA == "" {
+		opts.CommitSHA = os.Getenv("GITHUB_SHA")
+	}
+	if opts.Ref == "" {
+		opts.Ref = os.Getenv("GITHUB_REF")
+	}
+	if opts.CommitSHA != "" && opts.Ref != "" {
+		return nil
+	}
+
+	repo, err := git.Open(ctx, ".")
+	if err != nil {
+		return fmt.Errorf("pass --sha and --ref outside a git repository: %w", err)
+	}
+	branches, err := repo.Branches(ctx)
+	if err != nil {
+		return err
+	}
+	for _, branch := range branches {
+		if !branch.Current {
+			continue
+		}
+		if opts.CommitSHA == "" {
+			opts.CommitSHA = branch.Commit
+		}
+		if opts.Ref == "" {
+			opts.Ref = "refs/heads/" + branch.Name
+		}
+		return nil
+	}
+	return fmt.Errorf("HEAD is detached; pass --sha and --ref")
+}
@@ -5,13 +5,11 @@ import (
 	"fmt"
 	"strings"
 
-	"github.com/SamyRai/juleson/internal/logger"
 	"github.com/SamyRai/juleson/internal/presentation/cli/core"
 
 	"github.com/SamyRai/go-jules"
 	"github.com/SamyRai/juleson/internal/config"
-	ghclient "github.com/SamyRai/juleson/internal/github"
-	"github.com/google/go-github/v76/github"
+	"github.com/SamyRai/juleson/internal/vcs"
 	"github.com/spf13/cobra"
 )
 
@@ -63,6 +61,18 @@ Shows the actual code changes that would be merged.`,
 	RunE: runPRDiff,
 }
 
+// prLabelCmd represents the pr label command.
+var prLabelCmd = &cobra.Command{
+	Use:   "label <session-id> [label...]",
+	Short: "Label the pull request from a Jules session",
+	Long: `Add labels to the pull request created by a Jules session. Without labels, the
+sessions.defaults or sessions.repos labels configured for the pull request's
+repository are added. Jules opens pull requests itself, so labels are applied
+afterwards.`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: runPRLabel,
+}
+
 var (
 	prListLimit   int
 	prMergeMethod string
@@ -75,6 +85,7 @@ func init() {
 	prCmd.AddCommand(prGetCmd)
 	prCmd.AddCommand(prMergeCmd)
 	prCmd.AddCommand(prDiffCmd)
+	prCmd.AddCommand(prLabelCmd)
 
 	// Add flags
 	prListCmd.Flags().IntVarP(&prListLimit, "limit", "l", 10, "Maximum number of PRs to list")
@@ -88,12 +99,7 @@ func runPRList(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("failed to load config: %w", err)
 	}
 
-	julesClient := jules.NewClient(cfg.Jules.APIKey, jules.WithBaseURL(cfg.Jules.BaseURL), jules.WithTimeout(cfg.Jules.Timeout), jules.WithRetryAttempts(cfg.Jules.RetryAttempts), jules.WithDebugLog(cfg.Jules.DebugLog), jules.WithLogger(logger.New(logger.Config{Debug: cfg.Jules.DebugLog})))
-
-	ghClient := ghclient.NewClient(cfg.GitHub.Token, julesClient)
-	if ghClient == nil {
-		return fmt.Errorf("GitHub client not configured - please set GITHUB_TOKEN")
-	}
+	julesClient := core.NewJulesClient(cfg)
 
 	ctx := context.Background()
 
@@ -114,7 +120,7 @@ func runPRList(cmd *cobra.Command, args []string) error {
 		}
 
 		// Try to get PR for this session
-		pr, err := ghClient.PullRequests.GetSessionPullRequest(ctx, session.ID)
+		_, pr, err := core.SessionChangeRequest(ctx, cfg, &session)
 		if err != nil {
 			// Skip sessions without PRs or with errors
 			continue
@@ -132,45 +138,46 @@ func runPRList(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
-func runPRGet(cmd *cobra.Command, args []string) error {
-	sessionID := args[0]
+// sessionPullRequest returns the pull request of a session and the provider
+// hosting it.
+func sessionPullRequest(ctx context.Context, cfg *config.Config, sessionID string) (vcs.Provider, *vcs.ChangeRequest, error) {
+	julesClient := core.NewJulesClient(cfg)
 
-	cfg, err := config.Load()
+	session, err := julesClient.Sessions().Get(ctx, sessionID)
 	if err != nil {
-		return fmt.Errorf("failed to load config: %w", err)
+		return nil, nil, fmt.Errorf("failed to get session %s: %w", sessionID, err)
 	}
 
-	julesClient := jules.NewClient(cfg.Jules.APIKey, jules.WithBaseURL(cfg.Jules.BaseURL), jules.WithTimeout(cfg.Jules.Timeout), jules.WithRetryAttempts(cfg.Jules.RetryAttempts), jules.WithDebugLog(cfg.Jules.DebugLog), jules.WithLogger(logger.New(logger.Config{Debug: cfg.Jules.DebugLog})))
-
-	ghClient := ghclient.NewClient(cfg.GitHub.Token, julesClient)
-	if ghClient == nil {
-		return fmt.Errorf("GitHub client not configured - please set GITHUB_TOKEN")
+	provider, pr, err := core.SessionChangeRequest(ctx, cfg, session)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get PR for session %s: %w", sessionID, err)
 	}
+	return provider, pr, nil
+}
 
-	ctx := context.Background()
+func runPRGet(cmd *cobra.Command, args []string) error {
+	sessionID := args[0]
 
-	// Get session details
-	_, err = julesClient.Sessions().Get(ctx, sessionID)
+	cfg, err := config.Load()
 	if err != nil {
-		return fmt.Errorf("failed to get session %s: %w", sessionID, err)
+		return fmt.Errorf("failed to load config: %w", err)
 	}
 
-	// Get PR details
-	pr, err := ghClient.PullRequests.GetSessionPullRequest(ctx, sessionID)
+	_, pr, err := sessionPullRequest(context.Background(), cfg, sessionID)
 	if err != nil {
-		return fmt.Errorf("failed to get PR for session %s: %w", sessionID, err)
+		return err
 	}
 
-	fmt.Printf("📝 Pull Request #%d\n", pr.GetNumber())
-	fmt.Printf("Title: %s\n", pr.GetTitle())
-	fmt.Printf("Repository: %s\n", pr.GetBase().GetRepo().GetFullName())
-	fmt.Printf("Branch: %s → %s\n", pr.GetHead().GetRef(), pr.GetBase().GetRef())
-	fmt.Printf("Author: %s\n", pr.GetUser().GetLogin())
-	fmt.Printf("Status: %s\n", getPRStatus(pr))
-	fmt.Printf("URL: %s\n", pr.GetHTMLURL())
+	fmt.Printf("📝 Pull Request #%d\n", pr.Number)
+	fmt.Printf("Title: %s\n", pr.Title)
+	fmt.Printf("Repository: %s\n", pr.Repo)
+	fmt.Printf("Branch: %s → %s\n", pr.SourceBranch, pr.TargetBranch)
+	fmt.Printf("Author: %s\n", pr.Author)
+	fmt.Printf("Status: %s\n", core.ChangeRequestStatus(pr))
+	fmt.Printf("URL: %s\n", pr.URL)
 
-	if pr.GetBody() != "" {
-		fmt.Printf("\nDescription:\n%s\n", pr.GetBody())
+	if pr.Description != "" {
+		fmt.Printf("\nDescription:\n%s\n", pr.Description)
 	}
 
 	return nil
@@ -184,24 +191,17 @@ func runPRMerge(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("failed to load config: %w", err)
 	}
 
-	julesClient := jules.NewClient(cfg.Jules.APIKey, jules.WithBaseURL(cfg.Jules.BaseURL), jules.WithTimeout(cfg.Jules.Timeout), jules.WithRetryAttempts(cfg.Jules.RetryAttempts), jules.WithDebugLog(cfg.Jules.DebugLog), jules.WithLogger(logger.New(logger.Config{Debug: cfg.Jules.DebugLog})))
-
-	ghClient := ghclient.NewClient(cfg.GitHub.Token, julesClient)
-	if ghClient == nil {
-		return fmt.Errorf("GitHub client not configured - please set GITHUB_TOKEN")
-	}
-
 	ctx := context.Background()
 
 	// Get PR details first
-	pr, err := ghClient.PullRequests.GetSessionPullRequest(ctx, sessionID)
+	provider, pr, err := sessionPullRequest(ctx, cfg, sessionID)
 	if err != nil {
-		return fmt.Errorf("failed to get PR for session %s: %w", sessionID, err)
+		return err
 	}
 
 	// Check if PR can be merged
-	if !pr.GetMergeable() {
-		return fmt.Errorf("PR #%d cannot be merged - it may have conflicts or failing checks", pr.GetNumber())
+	if !pr.Mergeable {
+		return fmt.Errorf("PR #%d cannot be merged - it may have conflicts or failing checks", pr.Number)
 	}
 
 	// Determine merge method
@@ -214,8 +214,8 @@ func runPRMerge(cmd *cobra.Command, args []string) error {
 	}
 
 	// Confirm merge
-	fmt.Printf("🔄 Merging PR #%d: %s\n", pr.GetNumber(), pr.GetTitle())
-	fmt.Printf("Repository: %s\n", pr.GetBase().GetRepo().GetFullName())
+	fmt.Printf("🔄 Merging PR #%d: %s\n", pr.Number, pr.Title)
+	fmt.Printf("Repository: %s\n", pr.Repo)
 	fmt.Printf("Method: %s\n", mergeMethod)
 
 	if !confirmAction("Are you sure you want to merge this PR?") {
@@ -224,12 +224,16 @@ func runPRMerge(cmd *cobra.Command, args []string) error {
 	}
 
 	// Perform merge
-	err = ghClient.PullRequests.MergePullRequest(ctx, pr.GetHTMLURL(), mergeMethod)
+	err = provider.MergeChangeRequest(ctx, pr.Repo, pr.Number, mergeMethod)
+	core.RecordAudit(cfg, core.AuditSourceCLI, core.AuditPRMerge, pr.URL, err, map[string]interface{}{
+		"session_id": sessionID,
+		"method":     mergeMethod,
+	})
 	if err != nil {
 		return fmt.Errorf("failed to merge PR: %w", err)
 	}
 
-	fmt.Printf("✅ Successfully merged PR #%d\n", pr.GetNumber())
+	fmt.Printf("✅ Successfully merged PR #%d\n", pr.Number)
 	return nil
 }
 
@@ -241,28 +245,21 @@ func runPRDiff(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("failed to load config: %w", err)
 	}
 
-	julesClient := jules.NewClient(cfg.Jules.APIKey, jules.WithBaseURL(cfg.Jules.BaseURL), jules.WithTimeout(cfg.Jules.Timeout), jules.WithRetryAttempts(cfg.Jules.RetryAttempts), jules.WithDebugLog(cfg.Jules.DebugLog), jules.WithLogger(logger.New(logger.Config{Debug: cfg.Jules.DebugLog})))
-
-	ghClient := ghclient.NewClient(cfg.GitHub.Token, julesClient)
-	if ghClient == nil {
-		return fmt.Errorf("GitHub client not configured - please set GITHUB_TOKEN")
-	}
-
 	ctx := context.Background()
 
 	// Get PR details
-	pr, err := ghClient.PullRequests.GetSessionPullRequest(ctx, sessionID)
+	provider, pr, err := sessionPullRequest(ctx, cfg, sessionID)
 	if err != nil {
-		return fmt.Errorf("failed to get PR for session %s: %w", sessionID, err)
+		return err
 	}
 
-	fmt.Printf("📋 Diff for PR #%d: %s\n", pr.GetNumber(), pr.GetTitle())
-	fmt.Printf("Repository: %s\n", pr.GetBase().GetRepo().GetFullName())
-	fmt.Printf("Branch: %s → %s\n", pr.GetHead().GetRef(), pr.GetBase().GetRef())
+	fmt.Printf("📋 Diff for PR #%d: %s\n", pr.Number, pr.Title)
+	fmt.Printf("Repository: %s\n", pr.Repo)
+	fmt.Printf("Branch: %s → %s\n", pr.SourceBranch, pr.TargetBranch)
 	fmt.Println("================================================================")
 
 	// Get the actual diff
-	diff, err := ghClient.PullRequests.GetPullRequestDiff(ctx, sessionID)
+	diff, err := provider.ChangeRequestDiff(ctx, pr.Repo, pr.Number)
 	if err != nil {
 		return fmt.Errorf("failed to get PR diff: %w", err)
 	}
@@ -276,28 +273,51 @@ func runPRDiff(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
-// Helper functions
+func runPRLabel(cmd *cobra.Command, args []string) error {
+	sessionID := args[0]
 
-func displayPR(session jules.Session, pr *github.PullRequest) {
-	fmt.Printf("\n⚡ Session: %s\n", session.ID)
-	fmt.Printf("📝 PR #%d: %s\n", pr.GetNumber(), pr.GetTitle())
-	fmt.Printf("📁 Repository: %s\n", pr.GetBase().GetRepo().GetFullName())
-	fmt.Printf("🌿 Branch: %s → %s\n", pr.GetHead().GetRef(), pr.GetBase().GetRef())
-	fmt.Printf("📊 Status: %s\n", getPRStatus(pr))
-	fmt.Printf("🔗 URL: %s\n", pr.GetHTMLURL())
-}
+	cfg, err := config.Load()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
 
-func getPRStatus(pr *github.PullRequest) string {
-	if pr.GetMerged() {
-		return "✅ Merged"
+	ctx := context.Background()
+
+	provider, pr, err := sessionPullRequest(ctx, cfg, sessionID)
+	if err != nil {
+		return err
 	}
-	if pr.GetState() == "closed" {
-		return "❌ Closed"
+
+	labels := args[1:]
+	if len(labels) == 0 {
+		labels = cfg.Sessions.For(pr.Repo).Labels
+		if len(labels) == 0 {
+			return fmt.Errorf("no labels given and none configured for %s in sessions.defaults or sessions.repos", pr.Repo)
+		}
 	}
-	if !pr.GetMergeable() {
-		return "⚠️  Cannot merge (conflicts or failing checks)"
+
+	err = provider.AddLabels(ctx, pr.Repo, pr.Number, labels)
+	core.RecordAudit(cfg, core.AuditSourceCLI, core.AuditPRLabel, pr.URL, err, map[string]interface{}{
+		"session_id": sessionID,
+		"labels":     labels,
+	})
+	if err != nil {
+		return err
 	}
-	return "🟢 Ready to merge"
+
+	fmt.Printf("✅ Labeled PR #%d: %s\n", pr.Number, strings.Join(labels, ", "))
+	return nil
+}
+
+// Helper functions
+
+func displayPR(session jules.Session, pr *vcs.ChangeRequest) {
+	fmt.Printf("\n⚡ Session: %s\n", session.ID)
+	fmt.Printf("📝 PR #%d: %s\n", pr.Number, pr.Title)
+	fmt.Printf("📁 Repository: %s\n", pr.Repo)
+	fmt.Printf("🌿 Branch: %s → %s\n", pr.SourceBranch, pr.TargetBranch)
+	fmt.Printf("📊 Status: %s\n", core.ChangeRequestStatus(pr))
+	fmt.Printf("🔗 URL: %s\n", pr.URL)
 }
 
 // NewPRCommand creates the pr command.
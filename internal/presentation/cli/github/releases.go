@@ -0,0 +1,272 @@
+package github
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/SamyRai/juleson/internal/config"
+	ghclient "github.com/SamyRai/juleson/internal/github"
+	"github.com/SamyRai/juleson/internal/jules/workspace"
+	"github.com/SamyRai/juleson/internal/presentation/cli/core"
+	"github.com/spf13/cobra"
+)
+
+// NewGitHubCommand creates the github command.
+func NewGitHubCommand(cfg *config.Config) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "github",
+		Short: "Manage GitHub releases and code scanning for Juleson-driven repositories",
+		Long:  "GitHub repository operations that complement Jules session workflows.",
+	}
+
+	cmd.AddCommand(newReleasesCommand(cfg))
+	cmd.AddCommand(newCodeScanningCommand(cfg))
+
+	return cmd
+}
+
+func newReleasesCommand(cfg *config.Config) *cobra.Command {
+	var repoSlug string
+
+	cmd := &cobra.Command{
+		Use:   "releases",
+		Short: "Create, list, and upload assets to GitHub releases",
+		Long: `Manage GitHub releases for the current repository or the one passed with --repo.
+
+Examples:
+  juleson github releases list --limit 5
+  juleson github releases latest
+  juleson github releases create v0.3.0 --changelog --draft
+  juleson github releases upload v0.3.0 dist/juleson-linux-amd64.tar.gz`,
+	}
+	cmd.PersistentFlags().StringVar(&repoSlug, "repo", "", "Repository as owner/name or HOST/owner/name (default: from the workspace or the git origin remote)")
+
+	cmd.AddCommand(newReleasesListCommand(cfg, &repoSlug))
+	cmd.AddCommand(newReleasesLatestCommand(cfg, &repoSlug))
+	cmd.AddCommand(newReleasesCreateCommand(cfg, &repoSlug))
+	cmd.AddCommand(newReleasesUploadCommand(cfg, &repoSlug))
+
+	return cmd
+}
+
+func newReleasesListCommand(cfg *config.Config, repoSlug *string) *cobra.Command {
+	var limit int
+
+	cmd := &cobra.Command{
+		Use:   "list",
+		Short: "List recent releases",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client, owner, repo, err := releaseTarget(cfg, *repoSlug)
+			if err != nil {
+				return err
+			}
+
+			releases, err := client.Releases.ListReleases(context.Background(), owner, repo, limit)
+			if err != nil {
+				return err
+			}
+
+			out := cmd.OutOrStdout()
+			fmt.Fprintf(out, "🏷️  Releases for %s/%s\n", owner, repo)
+			if len(releases) == 0 {
+				fmt.Fprintln(out, "No releases found.")
+				return nil
+			}
+			for _, release := range releases {
+				fmt.Fprintln(out, formatReleaseLine(release))
+			}
+			return nil
+		},
+	}
+	cmd.Flags().IntVarP(&limit, "limit", "l", 10, "Maximum number of releases to list")
+
+	return cmd
+}
+
+func newReleasesLatestCommand(cfg *config.Config, repoSlug *string) *cobra.Command {
+	return &cobra.Command{
+		Use:   "latest",
+		Short: "Show the latest published release",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client, owner, repo, err := releaseTarget(cfg, *repoSlug)
+			if err != nil {
+				return err
+			}
+
+			release, err := client.Releases.LatestRelease(context.Background(), owner, repo)
+			if err != nil {
+				return err
+			}
+
+			displayRelease(cmd, release)
+			return nil
+		},
+	}
+}
+
+func newReleasesCreateCommand(cfg *config.Config, repoSlug *string) *cobra.Command {
+	var (
+		opts   ghclient.CreateReleaseOptions
+		assets []string
+	)
+
+	cmd := &cobra.Command{
+		Use:   "create <tag>",
+		Short: "Create a release",
+		Long: `Create a GitHub release for a tag. With --changelog, pull requests merged since
+the latest release are appended to the release notes. Files passed with --asset
+are uploaded after the release is created.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client, owner, repo, err := releaseTarget(cfg, *repoSlug)
+			if err != nil {
+				return err
+			}
+
+			ctx := context.Background()
+			opts.TagName = args[0]
+			release, err := client.Releases.CreateRelease(ctx, owner, repo, opts)
+			core.RecordAudit(cfg, core.AuditSourceCLI, core.AuditReleaseCreate, owner+"/"+repo+"@"+opts.TagName, err, map[string]interface{}{
+				"draft":      opts.Draft,
+				"prerelease": opts.Prerelease,
+			})
+			if err != nil {
+				return err
+			}
+
+			fmt.Fprintf(cmd.OutOrStdout(), "✅ Created release %s\n", release.TagName)
+			if err := uploadReleaseAssets(cmd, cfg, client, owner, repo, release, assets); err != nil {
+				return err
+			}
+			displayRelease(cmd, release)
+			return nil
+		},
+	}
+	cmd.Flags().StringVar(&opts.Name, "title", "", "Release title (default: the tag name)")
+	cmd.Flags().StringVar(&opts.Target, "target", "", "Branch or commit to tag when the tag does not exist yet")
+	cmd.Flags().StringVar(&opts.Body, "notes", "", "Release notes")
+	cmd.Flags().BoolVar(&opts.Draft, "draft", false, "Create the release as a draft")
+	cmd.Flags().BoolVar(&opts.Prerelease, "prerelease", false, "Mark the release as a prerelease")
+	cmd.Flags().BoolVar(&opts.Changelog, "changelog", false, "Append pull requests merged since the latest release")
+	cmd.Flags().StringArrayVar(&assets, "asset", nil, "File or glob to upload as a release asset (repeatable)")
+
+	return cmd
+}
+
+func newReleasesUploadCommand(cfg *config.Config, repoSlug *string) *cobra.Command {
+	return &cobra.Command{
+		Use:   "upload <tag> <file-or-glob>...",
+		Short: "Upload assets to an existing release",
+		Args:  cobra.MinimumNArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client, owner, repo, err := releaseTarget(cfg, *repoSlug)
+			if err != nil {
+				return err
+			}
+
+			release, err := client.Releases.GetReleaseByTag(context.Background(), owner, repo, args[0])
+			if err != nil {
+				return err
+			}
+
+			return uploadReleaseAssets(cmd, cfg, client, owner, repo, release, args[1:])
+		},
+	}
+}
+
+// releaseTarget builds a GitHub client and resolves the repository to operate on.
+// repoSlug accepts owner/name or HOST/owner/name for GitHub Enterprise hosts.
+// Without one, the workspace entry for the current directory is used, then
+// the git origin remote.
+func releaseTarget(cfg *config.Config, repoSlug string) (*ghclient.Client, string, string, error) {
+	var host, owner, repo string
+
+	if repoSlug == "" {
+		if entry, ok := workspace.LookupRepo("."); ok {
+			repoSlug = entry.GitHub
+		}
+	}
+
+	if repoSlug != "" {
+		parts := strings.Split(repoSlug, "/")
+		if len(parts) == 3 {
+			host, parts = parts[0], parts[1:]
+		}
+		if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+			return nil, "", "", fmt.Errorf("invalid --repo %q: expected owner/name or HOST/owner/name", repoSlug)
+		}
+		owner, repo = parts[0], parts[1]
+	} else {
+		detected, err := ghclient.NewGitRemoteParser(cfg.GitHub.EnterpriseHosts()...).GetRepoFromGitRemote()
+		if err != nil {
+			return nil, "", "", fmt.Errorf("failed to detect repository (use --repo owner/name): %w", err)
+		}
+		host, owner, repo = detected.Host, detected.Owner, detected.Name
+	}
+
+	client, err := core.NewGitHubClient(cfg, host, nil)
+	if err != nil {
+		return nil, "", "", err
+	}
+	return client, owner, repo, nil
+}
+
+// uploadReleaseAssets expands patterns and uploads every matching file.
+func uploadReleaseAssets(cmd *cobra.Command, cfg *config.Config, client *ghclient.Client, owner, repo string, release *ghclient.Release, patterns []string) error {
+	var files []string
+	for _, pattern := range patterns {
+		matches, err := filepath.Glob(pattern)
+		if err != nil {
+			return fmt.Errorf("invalid asset pattern %q: %w", pattern, err)
+		}
+		if len(matches) == 0 {
+			return fmt.Errorf("no files match asset pattern %q", pattern)
+		}
+		files = append(files, matches...)
+	}
+
+	for _, file := range files {
+		asset, err := client.Releases.UploadAsset(context.Background(), owner, repo, release.ID, file)
+		core.RecordAudit(cfg, core.AuditSourceCLI, core.AuditReleaseUpload, owner+"/"+repo+"@"+release.TagName, err, map[string]interface{}{"file": file})
+		if err != nil {
+			return err
+		}
+		release.Assets = append(release.Assets, asset)
+		fmt.Fprintf(cmd.OutOrStdout(), "📦 Uploaded %s (%d bytes)\n", asset.Name, asset.Size)
+	}
+
+	return nil
+}
+
+func displayRelease(cmd *cobra.Command, release *ghclient.Release) {
+	out := cmd.OutOrStdout()
+	fmt.Fprintln(out, formatReleaseLine(release))
+	if release.URL != "" {
+		fmt.Fprintf(out, "🔗 URL: %s\n", release.URL)
+	}
+	for _, asset := range release.Assets {
+		fmt.Fprintf(out, "   📦 %s (%d bytes, %d downloads)\n", asset.Name, asset.Size, asset.DownloadCount)
+	}
+	if release.Body != "" {
+		fmt.Fprintf(out, "\n%s\n", release.Body)
+	}
+}
+
+func formatReleaseLine(release *ghclient.Release) string {
+	line := fmt.Sprintf("🏷️  %s", release.TagName)
+	if release.Name != "" && release.Name != release.TagName {
+		line += fmt.Sprintf(" - %s", release.Name)
+	}
+	if release.Draft {
+		line += " [draft]"
+	}
+	if release.Prerelease {
+		line += " [prerelease]"
+	}
+	if release.PublishedAt != "" {
+		line += fmt.Sprintf(" (published %s)", release.PublishedAt)
+	}
+	return line
+}
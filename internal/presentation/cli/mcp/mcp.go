@@ -4,8 +4,10 @@ import (
 	"context"
 	"fmt"
 	"os"
+	"strings"
 
 	"github.com/SamyRai/juleson/internal/config"
+	"github.com/SamyRai/juleson/internal/health"
 	"github.com/SamyRai/juleson/internal/logger"
 	jmcp "github.com/SamyRai/juleson/internal/mcp"
 	"github.com/SamyRai/juleson/internal/presentation/cli/core"
@@ -16,14 +18,19 @@ func NewCommand(cfg *config.Config) *cobra.Command {
 	cmd := &cobra.Command{
 		Use:   "mcp",
 		Short: "Run the Juleson MCP server",
-		Long:  "Run the Juleson MCP server over stdio for Jules session and developer workflow tools.",
+		Long:  "Run the Juleson MCP server over stdio or HTTP for Jules session and developer workflow tools.",
 	}
 
-	var version bool
+	var (
+		version      bool
+		healthListen string
+		httpListen   string
+	)
 	serveCmd := &cobra.Command{
 		Use:   "serve",
-		Short: "Serve MCP over stdio",
-		Long:  "Serve the Juleson MCP server over stdin/stdout. Diagnostics are written to stderr.",
+		Short: "Serve MCP over stdio or HTTP",
+		Long: `Serve the Juleson MCP server over stdin/stdout, or over the streamable HTTP
+transport with --http. Diagnostics are written to stderr.`,
 		RunE: func(cmd *cobra.Command, args []string) error {
 			if version {
 				info := core.GetVersionInfo()
@@ -31,11 +38,39 @@ func NewCommand(cfg *config.Config) *cobra.Command {
 				return nil
 			}
 			// Redirect logger to stderr to avoid corrupting MCP JSON-RPC over stdout
-			logger.SetupGlobalWithOutput(cfg.Jules.DebugLog, os.Stderr)
-			return jmcp.RunStdio(context.Background(), cfg)
+			for _, sink := range cfg.Log.Sinks {
+				if strings.EqualFold(sink.Type, "stdout") {
+					return fmt.Errorf("log.sinks: stdout is reserved for MCP JSON-RPC; use stderr, file, or syslog")
+				}
+			}
+			if err := core.SetupLogging(cfg, os.Stderr); err != nil {
+				return fmt.Errorf("failed to set up logging: %w", err)
+			}
+			defer logger.Close()
+			core.ApplyRuntimeSettings(cfg)
+
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			if healthListen == "" {
+				healthListen = cfg.Health.Listen
+			}
+			if healthListen != "" {
+				checker := health.NewChecker(core.HealthComponents(cfg)...)
+				go func() {
+					if err := checker.Serve(ctx, healthListen, logger.For(logger.SubsystemMCP)); err != nil {
+						logger.For(logger.SubsystemMCP).Error("health endpoints disabled", "error", err)
+					}
+				}()
+			}
+			if httpListen != "" {
+				return jmcp.RunHTTP(ctx, cfg, httpListen)
+			}
+			return jmcp.RunStdio(ctx, cfg)
 		},
 	}
 	serveCmd.Flags().BoolVar(&version, "version", false, "Print version and exit without starting the MCP server")
+	serveCmd.Flags().StringVar(&healthListen, "health-listen", "", "Serve /healthz and /readyz on this address, e.g. 127.0.0.1:8081 (default: health.listen)")
+	serveCmd.Flags().StringVar(&httpListen, "http", "", "Serve MCP over streamable HTTP on this address, e.g. 127.0.0.1:8080, instead of stdio")
 	cmd.AddCommand(serveCmd)
 
 	return cmd
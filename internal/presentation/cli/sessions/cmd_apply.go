@@ -12,13 +12,28 @@ func (h *CommandHandler) ApplyCmd() *cobra.Command {
 		applyActivityID        string
 		applyArtifactIndex     int
 		applyAllowBaseMismatch bool
+		applyAllowSecrets      bool
+		applyAllowUnsafe       bool
+		applyForce             bool
+		applyApprovalID        string
+		applyIsolate           bool
+		applyKeepWorktree      bool
+		applyChecks            []string
+		applyOnly              []string
+		applyInteractive       bool
 	)
 
 	applyCmd := &cobra.Command{
 		Use:   "apply [session-id] [project-path]",
 		Short: "Preview or apply session patches",
-		Long:  "Preview session patches by default. Pass --confirm to apply after a clean-worktree check.",
-		Args:  cobra.ExactArgs(2),
+		Long: `Preview session patches by default. Pass --confirm to apply after a clean-worktree check.
+
+--only keeps the changes to files matching a glob, such as 'internal/...' or
+'*.go'; a pattern ending in /, /**, or /... matches a directory.
+--interactive asks about each hunk in turn, as git add --patch does.`,
+		Example: `  juleson sessions apply SESSION_ID . --confirm
+  juleson sessions apply SESSION_ID . --only 'internal/...' --interactive --confirm`,
+		Args: cobra.ExactArgs(2),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			return applySessionChanges(h.cfg, args[0], args[1], ApplySessionOptions{
 				Confirm:           applyConfirm,
@@ -27,6 +42,15 @@ func (h *CommandHandler) ApplyCmd() *cobra.Command {
 				ArtifactIndex:     applyArtifactIndex,
 				HasArtifactIndex:  cmd.Flags().Changed("artifact-index"),
 				AllowBaseMismatch: applyAllowBaseMismatch,
+				AllowSecrets:      applyAllowSecrets,
+				AllowUnsafe:       applyAllowUnsafe,
+				Force:             applyForce,
+				ApprovalID:        applyApprovalID,
+				Isolate:           applyIsolate,
+				KeepWorktree:      applyKeepWorktree,
+				Checks:            applyChecks,
+				Only:              applyOnly,
+				Interactive:       applyInteractive,
 			})
 		},
 	}
@@ -36,6 +60,17 @@ func (h *CommandHandler) ApplyCmd() *cobra.Command {
 	applyCmd.Flags().StringVar(&applyActivityID, "activity-id", "", "Apply only changes from this activity ID or resource name")
 	applyCmd.Flags().IntVar(&applyArtifactIndex, "artifact-index", 0, "Apply only this artifact index within the selected scope")
 	applyCmd.Flags().BoolVar(&applyAllowBaseMismatch, "allow-base-mismatch", false, "Allow applying when a patch baseCommitId differs from target HEAD")
+	applyCmd.Flags().BoolVar(&applyAllowSecrets, "allow-secrets", false, "Allow applying patches that add likely API keys, tokens, or private keys")
+	applyCmd.Flags().BoolVar(&applyAllowUnsafe, "allow-unsafe", false, "Allow applying patches the content guard blocks, such as ones that delete .git or pipe downloads to a shell")
+
+	applyCmd.Flags().BoolVar(&applyForce, "force", false, "Fall back to a three-way merge when patches do not apply cleanly (subject to policy)")
+	applyCmd.Flags().StringVar(&applyApprovalID, "approval", "", "Approval ID granted by a second approver when policy requires one")
+
+	applyCmd.Flags().BoolVar(&applyIsolate, "isolate", false, "Apply and validate in a temporary git worktree, merging back only if validation passes")
+	applyCmd.Flags().StringArrayVar(&applyChecks, "check", nil, "Validation command to run in the worktree (repeatable; default build, vet, and test for Go)")
+	applyCmd.Flags().StringArrayVar(&applyOnly, "only", nil, "Apply only changes to files matching this glob (repeatable)")
+	applyCmd.Flags().BoolVarP(&applyInteractive, "interactive", "i", false, "Choose the hunks to apply one at a time")
+	applyCmd.Flags().BoolVar(&applyKeepWorktree, "keep-worktree", false, "Keep the temporary worktree when isolated apply or validation fails")
 
 	return applyCmd
 }
@@ -13,13 +13,17 @@ func (h *CommandHandler) BatchCmd() *cobra.Command {
 		batchGroupTitle     string
 		batchStartingBranch string
 		batchAutomationMode string
+		batchEstimate       bool
+		batchConfirm        bool
 	)
 
 	batchCmd := &cobra.Command{
 		Use:   "batch [source-id] [task-file-or-prompt]",
 		Short: "Create parallel sessions for one task",
-		Long:  "Create 1-5 parallel Jules sessions for the same source and task. Batch sessions require plan approval by default.",
-		Args:  cobra.ExactArgs(2),
+		Long: `Create 1-5 parallel Jules sessions for the same source and task. Batch sessions require plan approval by default.
+--estimate prints the expected session time from recently completed sessions; batches estimated above
+policy.budget.confirm_above require --confirm.`,
+		Args: cobra.ExactArgs(2),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			return batchCreateSessions(h.cfg, args[0], args[1], BatchSessionOptions{
 				Parallel:       batchParallel,
@@ -28,6 +32,8 @@ func (h *CommandHandler) BatchCmd() *cobra.Command {
 				GroupTitle:     batchGroupTitle,
 				StartingBranch: batchStartingBranch,
 				AutomationMode: batchAutomationMode,
+				Estimate:       batchEstimate,
+				Confirm:        batchConfirm,
 			})
 		},
 	}
@@ -38,6 +44,8 @@ func (h *CommandHandler) BatchCmd() *cobra.Command {
 	batchCmd.Flags().StringVar(&batchGroupTitle, "group-title", "", "Optional group title to include in prompts and output")
 	batchCmd.Flags().StringVar(&batchStartingBranch, "starting-branch", "", "Starting branch for the source-backed sessions")
 	batchCmd.Flags().StringVar(&batchAutomationMode, "automation-mode", "", "Automation mode such as AUTO_CREATE_PR")
+	batchCmd.Flags().BoolVar(&batchEstimate, "estimate", false, "Print the estimated session time without creating sessions")
+	batchCmd.Flags().BoolVar(&batchConfirm, "confirm", false, "Start even if the estimate exceeds policy.budget.confirm_above")
 
 	return batchCmd
 }
@@ -0,0 +1,35 @@
+package sessions
+
+import (
+	"github.com/spf13/cobra"
+)
+
+// ChangelogCmd returns the command for writing commit messages and a
+// CHANGELOG fragment for session changes.
+func (h *CommandHandler) ChangelogCmd() *cobra.Command {
+	var options ChangelogOptions
+
+	changelogCmd := &cobra.Command{
+		Use:     "changelog <session-id>...",
+		Aliases: []string{"commit-message"},
+		Short:   "Write conventional-commit messages and a CHANGELOG fragment for session changes",
+		Long: `Write one conventional-commit message per session and a CHANGELOG fragment
+covering all of them. Gemini writes them, with structured output, when
+gemini.api_key or GEMINI_API_KEY is set; otherwise, or with --no-ai, they are
+derived from the session titles, Jules' suggested commit messages, and the
+paths changed.`,
+		Example: `  juleson sessions changelog SESSION_ID
+  juleson sessions changelog SESSION_A SESSION_B --changelog-file changes.md
+  juleson sessions changelog SESSION_ID --only 'internal/...' --json`,
+		Args: cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return writeSessionChangelog(cmd.Context(), h.cfg, cmd.OutOrStdout(), args, options)
+		},
+	}
+	changelogCmd.Flags().StringArrayVar(&options.Only, "only", nil, "Describe only changes to files matching this glob (repeatable)")
+	changelogCmd.Flags().BoolVar(&options.NoAI, "no-ai", false, "Derive the messages from the changes without Gemini")
+	changelogCmd.Flags().StringVar(&options.ChangelogFile, "changelog-file", "", "Also write the CHANGELOG fragment to this file")
+	changelogCmd.Flags().BoolVar(&options.JSON, "json", false, "Print machine-readable JSON")
+
+	return changelogCmd
+}
@@ -8,17 +8,46 @@ import (
 
 // CreateCmd returns the command for creating a session.
 func (h *CommandHandler) CreateCmd() *cobra.Command {
-	var createNoSource bool
+	var createNoSource, createWorkspace bool
 	createOptions := CreateSessionOptions{}
 
 	createCmd := &cobra.Command{
 		Use:   "create [source-id] [prompt]",
 		Short: "Create a new session",
-		Long:  "Create a new Jules session with a repository source, or pass --no-source for a repoless session",
-		Args:  cobra.RangeArgs(0, 2),
+		Long: `Create a new Jules session with a repository source, or pass --no-source for a repoless session.
+A source of "." uses the workspace entry or git origin remote of the current directory, and
+--workspace creates the session in every repository of the enclosing workspace.
+
+--attach and --attach-diff embed file contents and diffs in the prompt, within --context-budget
+tokens (default about 24k, or 96 KiB). Files that do not fit whole are packed: Go, JavaScript,
+TypeScript, and Python files are split by top-level declaration, and each keeps its imports, an
+outline of every symbol, and the declarations most relevant to the prompt, ranked with Gemini
+embeddings when Gemini is configured. Long diffs keep whole files, and attachments past the budget
+are left out with a warning.
+
+Example:
+  juleson sessions create . --prompt-file task.md --attach internal/api/handler.go --attach-diff HEAD~3`,
+		Args: cobra.RangeArgs(0, 2),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			options := createOptions
 			options.NoSource = createNoSource
+			options.PlanApprovalSet = cmd.Flags().Changed("require-plan-approval")
+
+			if createWorkspace {
+				if createNoSource || options.WithIntel {
+					return fmt.Errorf("--workspace cannot be combined with --no-source or --with-intel")
+				}
+				if options.PromptFile != "" {
+					if len(args) != 0 {
+						return fmt.Errorf("--workspace with --prompt-file does not accept positional arguments")
+					}
+					return createWorkspaceSessions(h.cfg, "", options)
+				}
+				if len(args) != 1 {
+					return fmt.Errorf("--workspace accepts exactly one prompt argument, or use --prompt-file")
+				}
+				return createWorkspaceSessions(h.cfg, args[0], options)
+			}
 
 			if createNoSource {
 				if options.PromptFile != "" {
@@ -49,11 +78,16 @@ func (h *CommandHandler) CreateCmd() *cobra.Command {
 	}
 
 	createCmd.Flags().BoolVar(&createNoSource, "no-source", false, "Create a repoless session without sourceContext")
+	createCmd.Flags().BoolVar(&createWorkspace, "workspace", false, "Create one session per repository in the enclosing workspace")
 	createCmd.Flags().StringVar(&createOptions.PromptFile, "prompt-file", "", "Read the session prompt from a file")
 	createCmd.Flags().StringVar(&createOptions.Title, "title", "", "Optional session title")
 	createCmd.Flags().StringVar(&createOptions.StartingBranch, "starting-branch", "", "Starting branch for source-backed sessions")
 	createCmd.Flags().BoolVar(&createOptions.RequirePlanApproval, "require-plan-approval", false, "Require explicit plan approval before Jules starts work")
 	createCmd.Flags().StringVar(&createOptions.AutomationMode, "automation-mode", "", "Automation mode such as AUTO_CREATE_PR")
+	createCmd.Flags().StringVar(&createOptions.ApprovalID, "approval", "", "Approval ID granted by a second approver when policy requires one")
+	createCmd.Flags().StringArrayVar(&createOptions.Attach, "attach", nil, "Embed a file's contents in the prompt (repeatable)")
+	createCmd.Flags().StringArrayVar(&createOptions.AttachDiffs, "attach-diff", nil, "Embed the diff of the working tree against a revision, such as HEAD~3 (repeatable)")
+	createCmd.Flags().IntVar(&createOptions.ContextBudget, "context-budget", 0, "Tokens the attachments may take (default about 24k)")
 	createCmd.Flags().BoolVar(&createOptions.WithIntel, "with-intel", false, "Analyze and attach codebase complexity and dependency graph to the prompt")
 
 	return createCmd
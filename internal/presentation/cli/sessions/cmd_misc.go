@@ -1,6 +1,7 @@
 package sessions
 
 import (
+	"github.com/SamyRai/juleson/internal/jules/workspace"
 	"github.com/spf13/cobra"
 )
 
@@ -91,7 +92,10 @@ func (h *CommandHandler) MessageCmd() *cobra.Command {
 
 // DeleteCmd returns the command for deleting a session.
 func (h *CommandHandler) DeleteCmd() *cobra.Command {
-	var deleteForce bool
+	var (
+		deleteForce      bool
+		deleteApprovalID string
+	)
 
 	deleteCmd := &cobra.Command{
 		Use:   "delete [session-id]",
@@ -99,10 +103,11 @@ func (h *CommandHandler) DeleteCmd() *cobra.Command {
 		Long:  "Delete a Jules session. Without --force, type the session ID to confirm.",
 		Args:  cobra.ExactArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
-			return deleteSession(h.cfg, args[0], deleteForce)
+			return deleteSession(h.cfg, args[0], deleteForce, deleteApprovalID)
 		},
 	}
 	deleteCmd.Flags().BoolVar(&deleteForce, "force", false, "Delete without interactive confirmation")
+	deleteCmd.Flags().StringVar(&deleteApprovalID, "approval", "", "Approval ID granted by a second approver when policy requires one")
 
 	return deleteCmd
 }
@@ -122,62 +127,94 @@ func (h *CommandHandler) OutputsCmd() *cobra.Command {
 
 // DownloadCmd returns the command for downloading session artifacts.
 func (h *CommandHandler) DownloadCmd() *cobra.Command {
-	return &cobra.Command{
+	var parallel int
+	cmd := &cobra.Command{
 		Use:   "download [session-id] [output-dir]",
 		Short: "Download all artifacts from a session",
-		Long:  "Download all artifacts (patches, outputs, media) from all activities in a session",
-		Args:  cobra.RangeArgs(1, 2),
+		Long: `Download all artifacts (patches, outputs, media) from all activities in a session.
+
+Artifacts are written several at a time and verified against their SHA-256
+checksums. Files that already hold the same content are skipped, and a
+download interrupted part way through a large file resumes where it stopped.`,
+		Args: cobra.RangeArgs(1, 2),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			outputDir := "."
 			if len(args) > 1 {
 				outputDir = args[1]
 			}
-			return downloadSessionArtifacts(h.cfg, args[0], outputDir)
+			return downloadSessionArtifacts(h.cfg, args[0], outputDir, parallel)
 		},
 	}
+	cmd.Flags().IntVar(&parallel, "parallel", workspace.DefaultArtifactConcurrency, "Number of artifacts to write at once")
+	return cmd
 }
 
 // DownloadActivityCmd returns the command for downloading activity artifacts.
 func (h *CommandHandler) DownloadActivityCmd() *cobra.Command {
-	return &cobra.Command{
+	var parallel int
+	cmd := &cobra.Command{
 		Use:   "download-activity [session-id] [activity-id] [output-dir]",
 		Short: "Download artifacts from a specific activity",
-		Long:  "Download all artifacts from a specific activity within a session",
+		Long:  "Download all artifacts from a specific activity within a session, resuming and verifying them like download",
 		Args:  cobra.RangeArgs(2, 3),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			outputDir := "."
 			if len(args) > 2 {
 				outputDir = args[2]
 			}
-			return downloadActivityArtifacts(h.cfg, args[0], args[1], outputDir)
+			return downloadActivityArtifacts(h.cfg, args[0], args[1], outputDir, parallel)
 		},
 	}
+	cmd.Flags().IntVar(&parallel, "parallel", workspace.DefaultArtifactConcurrency, "Number of artifacts to write at once")
+	return cmd
 }
 
 // PreviewCmd returns the command for previewing session artifacts.
 func (h *CommandHandler) PreviewCmd() *cobra.Command {
-	return &cobra.Command{
+	var options previewOptions
+	cmd := &cobra.Command{
 		Use:   "preview [session-id]",
 		Short: "Preview all artifacts from a session",
-		Long:  "Display artifacts (diffs, outputs, media info) from all activities in a session without downloading",
-		Args:  cobra.ExactArgs(1),
+		Long: `Display artifacts (diffs, outputs, media info) from all activities in a session without downloading.
+
+Diffs are shown unified or, with --side-by-side, side by side, with colors,
+syntax-highlighted context, and the changed words of each line highlighted.
+On a terminal the output goes through $JULESON_PAGER, $PAGER, or less, where
+n and N jump between files. A configured diff.tool, or difftastic or delta
+when installed, renders diffs instead unless diff.force_native is set or
+--side-by-side is passed.`,
+		Example: `  juleson sessions preview SESSION_ID
+  juleson sessions preview SESSION_ID --side-by-side --file '*.go' --file docs/`,
+		Args: cobra.ExactArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
-			return previewSessionArtifacts(h.cfg, args[0])
+			return previewSessionArtifacts(h.cfg, args[0], options)
 		},
 	}
+	addPreviewFlags(cmd, &options)
+	return cmd
 }
 
 // PreviewActivityCmd returns the command for previewing activity artifacts.
 func (h *CommandHandler) PreviewActivityCmd() *cobra.Command {
-	return &cobra.Command{
+	var options previewOptions
+	cmd := &cobra.Command{
 		Use:   "preview-activity [session-id] [activity-id]",
 		Short: "Preview artifacts from a specific activity",
-		Long:  "Display artifacts from a specific activity within a session without downloading",
+		Long:  "Display artifacts from a specific activity within a session without downloading. Diffs are rendered as by 'sessions preview'.",
 		Args:  cobra.ExactArgs(2),
 		RunE: func(cmd *cobra.Command, args []string) error {
-			return previewActivityArtifacts(h.cfg, args[0], args[1])
+			return previewActivityArtifacts(h.cfg, args[0], args[1], options)
 		},
 	}
+	addPreviewFlags(cmd, &options)
+	return cmd
+}
+
+func addPreviewFlags(cmd *cobra.Command, options *previewOptions) {
+	cmd.Flags().BoolVar(&options.SideBySide, "side-by-side", false, "Show diffs side by side instead of unified")
+	cmd.Flags().StringArrayVar(&options.Files, "file", nil, "Only show changes to files matching this glob, such as '*.go' or docs/ (repeatable)")
+	cmd.Flags().BoolVar(&options.NoPager, "no-pager", false, "Do not page the output")
+	cmd.Flags().BoolVar(&options.NoColor, "no-color", false, "Do not color the output")
 }
 
 // AutocleanCmd returns the command for autocleaning sessions.
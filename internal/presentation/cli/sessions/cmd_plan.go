@@ -0,0 +1,76 @@
+package sessions
+
+import (
+	"time"
+
+	"github.com/SamyRai/juleson/internal/config"
+	"github.com/spf13/cobra"
+)
+
+// NewPlanCommand creates the plan command, which asks Jules for a plan
+// without starting any work.
+func NewPlanCommand(cfg *config.Config) *cobra.Command {
+	options := PlanGoalOptions{}
+
+	cmd := &cobra.Command{
+		Use:   "plan [goal]",
+		Short: "Plan a goal with Jules without executing it",
+		Long: `Analyze the project, create a Jules session that requires plan approval, and print the plan Jules
+generates as ordered steps. On a terminal you then accept the plan, ask Jules to revise it with feedback,
+or reject it and delete the session; nothing changes until the plan is accepted.
+--output saves the plan as a template whose tasks are the steps, so it can be edited and run later with
+'juleson template run'.
+
+Examples:
+  juleson plan "Add retry logic to the HTTP client" --project .
+  juleson plan "Split the config package" --output templates/custom/planning/split-config.yaml`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return planGoal(cfg, args[0], options)
+		},
+	}
+
+	cmd.Flags().StringVar(&options.ProjectPath, "project", ".", "Project to analyze; its origin remote selects the source")
+	cmd.Flags().StringVar(&options.Source, "source", "", "Jules source ID (default: inferred from the project's origin remote)")
+	cmd.Flags().StringVar(&options.StartingBranch, "starting-branch", "", "Starting branch for the session")
+	cmd.Flags().StringVarP(&options.Output, "output", "o", "", "Save the plan as a template YAML file")
+	cmd.Flags().DurationVar(&options.Timeout, "timeout", 15*time.Minute, "How long to wait for the plan")
+	cmd.Flags().BoolVar(&options.JSON, "json", false, "Print the session and plan as JSON")
+	cmd.Flags().BoolVar(&options.NoReview, "no-review", false, "Print the plan without asking to accept, modify, or reject it")
+
+	return cmd
+}
+
+// PlanCmd returns the command for reviewing a session's plan.
+func (h *CommandHandler) PlanCmd() *cobra.Command {
+	options := SessionPlanOptions{}
+
+	cmd := &cobra.Command{
+		Use:   "plan [session-id]",
+		Short: "Review, approve, or reject a session's plan",
+		Long: `Show the latest plan of a session with each step's details and what changed from the plan
+before it. On a terminal you then approve the plan, comment on it or on one step, or reject it; comments
+and rejections are sent to Jules, which generates a new plan to review in turn. Jules approves whole
+plans, so step feedback is how individual steps get changed.
+
+Without a terminal, use --approve, or --reject or --comment with an optional --step.
+
+Examples:
+  juleson sessions plan 123456
+  juleson sessions plan 123456 --comment "Keep the public API unchanged" --step 2
+  juleson sessions plan 123456 --reject "This should be a config change, not new code"`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return reviewSessionPlan(h.cfg, args[0], options)
+		},
+	}
+
+	cmd.Flags().BoolVar(&options.Approve, "approve", false, "Approve the plan")
+	cmd.Flags().StringVar(&options.Reject, "reject", "", "Reject the plan with this reason and ask Jules for a new one")
+	cmd.Flags().StringVar(&options.Comment, "comment", "", "Ask Jules to revise the plan with this comment")
+	cmd.Flags().IntVar(&options.Step, "step", 0, "Step number --reject or --comment is about")
+	cmd.Flags().DurationVar(&options.Timeout, "timeout", 15*time.Minute, "How long to wait for a new plan on a terminal")
+	cmd.Flags().BoolVar(&options.JSON, "json", false, "Print machine-readable JSON")
+
+	return cmd
+}
@@ -10,6 +10,7 @@ func (h *CommandHandler) ReviewCmd() *cobra.Command {
 		reviewActivityID    string
 		reviewArtifactIndex int
 		reviewJSON          bool
+		reviewSARIF         bool
 	)
 
 	reviewCmd := &cobra.Command{
@@ -23,6 +24,7 @@ func (h *CommandHandler) ReviewCmd() *cobra.Command {
 				ArtifactIndex:    reviewArtifactIndex,
 				HasArtifactIndex: cmd.Flags().Changed("artifact-index"),
 				JSON:             reviewJSON,
+				SARIF:            reviewSARIF,
 			})
 		},
 	}
@@ -30,6 +32,8 @@ func (h *CommandHandler) ReviewCmd() *cobra.Command {
 	reviewCmd.Flags().StringVar(&reviewActivityID, "activity-id", "", "Review patches only from this activity ID or resource name")
 	reviewCmd.Flags().IntVar(&reviewArtifactIndex, "artifact-index", 0, "Review only this artifact index within the selected scope")
 	reviewCmd.Flags().BoolVar(&reviewJSON, "json", false, "Print machine-readable JSON")
+	reviewCmd.Flags().BoolVar(&reviewSARIF, "sarif", false, "Print secrets, blockers, and warnings as a SARIF log for code scanning")
+	reviewCmd.MarkFlagsMutuallyExclusive("json", "sarif")
 
 	return reviewCmd
 }
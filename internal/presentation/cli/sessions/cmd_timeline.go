@@ -0,0 +1,31 @@
+package sessions
+
+import (
+	"github.com/spf13/cobra"
+)
+
+// TimelineCmd returns the command for showing a session's event timeline.
+func (h *CommandHandler) TimelineCmd() *cobra.Command {
+	var (
+		timelineFiles []string
+		timelineJSON  bool
+	)
+
+	timelineCmd := &cobra.Command{
+		Use:   "timeline [session-id]",
+		Short: "Show a session's timeline from recorded events",
+		Long: `Show what happened to a session - creation, plan approval, messages, patch
+applies, and split template task events - rebuilt from event journals. The
+audit log is read by default; --file reads other journals, such as those
+written by 'template run --split --events'. Timelines are rebuilt from
+snapshots kept next to each journal, so only new events are replayed.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return showSessionTimeline(cmd.OutOrStdout(), h.cfg, args[0], timelineFiles, timelineJSON)
+		},
+	}
+	timelineCmd.Flags().StringArrayVar(&timelineFiles, "file", nil, "Event journal to read (repeatable; default: the audit log)")
+	timelineCmd.Flags().BoolVar(&timelineJSON, "json", false, "Print machine-readable JSON")
+
+	return timelineCmd
+}
@@ -2,6 +2,7 @@ package sessions
 
 import (
 	"bytes"
+	"encoding/json"
 	"github.com/SamyRai/juleson/internal/presentation/cli/core"
 	"io"
 	"net/http"
@@ -86,6 +87,52 @@ func TestShowSessionPlansLatestJSON(t *testing.T) {
 	}
 }
 
+func TestReviewSessionPlanSendsStepComment(t *testing.T) {
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+
+	created := time.Date(2026, 5, 27, 9, 0, 0, 0, time.UTC)
+	httpmock.RegisterResponder("GET", "https://jules.googleapis.com/v1alpha/sessions/session-1/activities?pageSize=100",
+		httpmock.NewJsonResponderOrPanic(200, jules.ActivitiesResponse{
+			Activities: []jules.Activity{{ID: "plan", CreateTime: created, PlanGenerated: &jules.PlanGenerated{Plan: jules.Plan{
+				ID:    "plan-1",
+				Steps: []jules.Step{{Title: "Inspect"}, {Title: "Patch", Description: "Apply the fix"}},
+			}}}},
+		}))
+	var sent jules.SendMessageRequest
+	httpmock.RegisterResponder("POST", "https://jules.googleapis.com/v1alpha/sessions/session-1:sendMessage",
+		func(req *http.Request) (*http.Response, error) {
+			if err := json.NewDecoder(req.Body).Decode(&sent); err != nil {
+				t.Fatalf("decode message: %v", err)
+			}
+			return httpmock.NewStringResponse(200, "{}"), nil
+		})
+
+	output := captureStdout(t, func() {
+		err := reviewSessionPlan(operatorTestConfig(), "session-1", SessionPlanOptions{Comment: "Keep the API", Step: 2})
+		if err != nil {
+			t.Fatalf("reviewSessionPlan returned error: %v", err)
+		}
+	})
+	if want := `Revise step 2 of the plan ("Patch") before starting work: Keep the API`; sent.Prompt != want {
+		t.Errorf("sent %q, want %q", sent.Prompt, want)
+	}
+	if !strings.Contains(output, "Apply the fix") || !strings.Contains(output, "Feedback sent") {
+		t.Errorf("output unexpected:\n%s", output)
+	}
+}
+
+func TestReviewSessionPlanRejectsConflictingActions(t *testing.T) {
+	err := reviewSessionPlan(operatorTestConfig(), "session-1", SessionPlanOptions{Approve: true, Reject: "no"})
+	if err == nil || !strings.Contains(err.Error(), "mutually exclusive") {
+		t.Fatalf("err = %v, want mutually exclusive", err)
+	}
+	err = reviewSessionPlan(operatorTestConfig(), "session-1", SessionPlanOptions{Step: 1})
+	if err == nil || !strings.Contains(err.Error(), "--step requires") {
+		t.Fatalf("err = %v, want --step requires", err)
+	}
+}
+
 func TestActivitiesListShowsIDAndName(t *testing.T) {
 	httpmock.Activate()
 	defer httpmock.DeactivateAndReset()
@@ -23,6 +23,8 @@ func NewSessionsCommand(cfg *config.Config) *cobra.Command {
 	sessionsCmd.AddCommand(handler.StatusCmd())
 	sessionsCmd.AddCommand(handler.GetCmd())
 	sessionsCmd.AddCommand(handler.PlansCmd())
+	sessionsCmd.AddCommand(handler.PlanCmd())
+	sessionsCmd.AddCommand(handler.TimelineCmd())
 	sessionsCmd.AddCommand(handler.ReviewCmd())
 	sessionsCmd.AddCommand(handler.MessageCmd())
 	sessionsCmd.AddCommand(handler.DeleteCmd())
@@ -35,6 +37,7 @@ func NewSessionsCommand(cfg *config.Config) *cobra.Command {
 	sessionsCmd.AddCommand(handler.PreviewCmd())
 	sessionsCmd.AddCommand(handler.PreviewActivityCmd())
 	sessionsCmd.AddCommand(handler.AutocleanCmd())
+	sessionsCmd.AddCommand(handler.ChangelogCmd())
 
 	return sessionsCmd
 }
@@ -33,7 +33,7 @@ func listSessionArtifacts(cfg *config.Config, sessionID string) error {
 		if manifest.FileCount > 0 {
 			fmt.Printf("  Files: %d\n", manifest.FileCount)
 			for _, file := range manifest.Files {
-				fmt.Printf("    %s (+%d -%d)\n", file.Path, file.LinesAdded, file.LinesRemoved)
+				fmt.Printf("    %s (+%d -%d)\n", file.Label(), file.LinesAdded, file.LinesRemoved)
 			}
 		} else if manifest.Empty {
 			fmt.Printf("  Empty changeset: no diff content\n")
@@ -87,8 +87,21 @@ func showSessionOutputs(cfg *config.Config, sessionID string) error {
 	return nil
 }
 
+// encryptArtifacts makes options save artifacts encrypted when
+// encryption.enabled is set.
+func encryptArtifacts(cfg *config.Config, options *workspace.ArtifactDownloadOptions) error {
+	cipher, err := core.AtRestCipher(cfg)
+	if err != nil {
+		return fmt.Errorf("encryption: %w", err)
+	}
+	if cipher != nil {
+		options.Cipher = cipher
+	}
+	return nil
+}
+
 // downloadSessionArtifacts downloads all artifacts from all activities in a session.
-func downloadSessionArtifacts(cfg *config.Config, sessionID string, outputDir string) error {
+func downloadSessionArtifacts(cfg *config.Config, sessionID string, outputDir string, parallel int) error {
 	julesClient := core.NewJulesClient(cfg)
 	ctx := context.Background()
 
@@ -100,6 +113,11 @@ func downloadSessionArtifacts(cfg *config.Config, sessionID string, outputDir st
 		DestinationDir: outputDir,
 		CreateDir:      true,
 		Overwrite:      false,
+		Concurrency:    parallel,
+		Progress:       printArtifactProgress,
+	}
+	if err := encryptArtifacts(cfg, options); err != nil {
+		return err
 	}
 
 	downloadedFiles, err := workspace.DownloadAllSessionArtifacts(ctx, julesClient, sessionID, options)
@@ -122,7 +140,7 @@ func downloadSessionArtifacts(cfg *config.Config, sessionID string, outputDir st
 }
 
 // downloadActivityArtifacts downloads all artifacts from a specific activity.
-func downloadActivityArtifacts(cfg *config.Config, sessionID string, activityID string, outputDir string) error {
+func downloadActivityArtifacts(cfg *config.Config, sessionID string, activityID string, outputDir string, parallel int) error {
 	julesClient := core.NewJulesClient(cfg)
 	ctx := context.Background()
 
@@ -135,6 +153,11 @@ func downloadActivityArtifacts(cfg *config.Config, sessionID string, activityID
 		DestinationDir: outputDir,
 		CreateDir:      true,
 		Overwrite:      false,
+		Concurrency:    parallel,
+		Progress:       printArtifactProgress,
+	}
+	if err := encryptArtifacts(cfg, options); err != nil {
+		return err
 	}
 
 	downloadedFiles, err := workspace.DownloadArtifactFromActivity(ctx, julesClient, sessionID, activityID, options)
@@ -156,13 +179,33 @@ func downloadActivityArtifacts(cfg *config.Config, sessionID string, activityID
 	return nil
 }
 
+// printArtifactProgress prints a line as each artifact file completes or
+// fails.
+func printArtifactProgress(progress workspace.ArtifactProgress) {
+	switch {
+	case progress.Err != nil:
+		fmt.Printf("  ❌ %s: %v\n", progress.File, progress.Err)
+	case progress.Skipped:
+		fmt.Printf("  ⏭️  %s already downloaded\n", progress.File)
+	case progress.Done && progress.Resumed > 0:
+		fmt.Printf("  ✓ %s (%d bytes, resumed at %d, sha256 %s)\n", progress.File, progress.Total, progress.Resumed, progress.SHA256[:12])
+	case progress.Done:
+		fmt.Printf("  ✓ %s (%d bytes, sha256 %s)\n", progress.File, progress.Total, progress.SHA256[:12])
+	}
+}
+
 // previewSessionArtifacts previews all artifacts from all activities in a session.
-func previewSessionArtifacts(cfg *config.Config, sessionID string) error {
+func previewSessionArtifacts(cfg *config.Config, sessionID string, options previewOptions) error {
 	julesClient := core.NewJulesClient(cfg)
 	ctx := context.Background()
+	p, err := newPreviewer(cfg, options)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = p.flush() }()
 
-	fmt.Printf("👁️  Previewing artifacts from session: %s\n", sessionID)
-	fmt.Println(strings.Repeat("=", 60))
+	fmt.Fprintf(p.w, "👁️  Previewing artifacts from session: %s\n", sessionID)
+	fmt.Fprintln(p.w, strings.Repeat("=", 60))
 
 	response, err := julesClient.Activities().List(ctx, sessionID, &jules.ListActivitiesOptions{PageSize: 100})
 	if err != nil {
@@ -171,17 +214,17 @@ func previewSessionArtifacts(cfg *config.Config, sessionID string) error {
 	activities := response.Activities
 
 	if len(activities) == 0 {
-		fmt.Println("📭 No activities found in this session.")
-		return nil
+		fmt.Fprintln(p.w, "📭 No activities found in this session.")
+		return p.flush()
 	}
 
 	totalArtifacts := 0
 	for i, activity := range activities {
 		if len(activity.Artifacts) > 0 {
-			fmt.Printf("\n📋 Activity %d: %s\n", i+1, activity.ID)
-			err := previewActivityArtifactsContent(cfg, activity.Artifacts)
+			fmt.Fprintf(p.w, "\n📋 Activity %d: %s\n", i+1, activity.ID)
+			err := p.artifacts(activity.Artifacts)
 			if err != nil {
-				fmt.Printf("⚠️  Failed to preview activity %s: %v\n", activity.ID, err)
+				fmt.Fprintf(p.w, "⚠️  Failed to preview activity %s: %v\n", activity.ID, err)
 			} else {
 				totalArtifacts += len(activity.Artifacts)
 			}
@@ -189,22 +232,27 @@ func previewSessionArtifacts(cfg *config.Config, sessionID string) error {
 	}
 
 	if totalArtifacts == 0 {
-		fmt.Println("📭 No artifacts found in this session.")
+		fmt.Fprintln(p.w, "📭 No artifacts found in this session.")
 	} else {
-		fmt.Printf("\n✅ Previewed %d artifact(s) total\n", totalArtifacts)
+		fmt.Fprintf(p.w, "\n✅ Previewed %d artifact(s) total\n", totalArtifacts)
 	}
 
-	return nil
+	return p.flush()
 }
 
 // previewActivityArtifacts previews all artifacts from a specific activity.
-func previewActivityArtifacts(cfg *config.Config, sessionID string, activityID string) error {
+func previewActivityArtifacts(cfg *config.Config, sessionID string, activityID string, options previewOptions) error {
 	julesClient := core.NewJulesClient(cfg)
 	ctx := context.Background()
+	p, err := newPreviewer(cfg, options)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = p.flush() }()
 
-	fmt.Printf("👁️  Previewing artifacts from activity: %s\n", activityID)
-	fmt.Printf("📁 Session: %s\n", sessionID)
-	fmt.Println(strings.Repeat("=", 60))
+	fmt.Fprintf(p.w, "👁️  Previewing artifacts from activity: %s\n", activityID)
+	fmt.Fprintf(p.w, "📁 Session: %s\n", sessionID)
+	fmt.Fprintln(p.w, strings.Repeat("=", 60))
 
 	activity, err := julesClient.Activities().Get(ctx, sessionID, activityID)
 	if err != nil {
@@ -212,15 +260,15 @@ func previewActivityArtifacts(cfg *config.Config, sessionID string, activityID s
 	}
 
 	if len(activity.Artifacts) == 0 {
-		fmt.Println("📭 No artifacts found in this activity.")
-		return nil
+		fmt.Fprintln(p.w, "📭 No artifacts found in this activity.")
+		return p.flush()
 	}
 
-	err = previewActivityArtifactsContent(cfg, activity.Artifacts)
+	err = p.artifacts(activity.Artifacts)
 	if err != nil {
 		return err
 	}
 
-	fmt.Printf("\n✅ Previewed %d artifact(s)\n", len(activity.Artifacts))
-	return nil
+	fmt.Fprintf(p.w, "\n✅ Previewed %d artifact(s)\n", len(activity.Artifacts))
+	return p.flush()
 }
@@ -8,6 +8,7 @@ import (
 	"github.com/SamyRai/go-jules"
 	"github.com/SamyRai/juleson/internal/config"
 	julessessions "github.com/SamyRai/juleson/internal/jules/sessions"
+	"github.com/SamyRai/juleson/internal/policy"
 )
 
 func autocleanSessions(cfg *config.Config) error {
@@ -47,7 +48,16 @@ func autocleanSessions(cfg *config.Config) error {
 
 		if merged {
 			fmt.Printf("   ✅ Patch is verified as MERGED! Deleting remote session...\n")
-			if delErr := julesClient.Sessions().Delete(ctx, session.ID); delErr != nil {
+			if err := core.EnforcePolicy(cfg, policy.Check{
+				Request:   policy.Request{Operation: policy.OpDeleteSession, Tool: "sessions autoclean", Repo: sessionRepo(session), Target: session.ID},
+				Confirmed: true,
+			}, false); err != nil {
+				fmt.Printf("   ⛔ %v\n\n", err)
+				continue
+			}
+			delErr := julesClient.Sessions().Delete(ctx, session.ID)
+			core.RecordAudit(cfg, core.AuditSourceCLI, core.AuditSessionDelete, session.ID, delErr, map[string]interface{}{"reason": "autoclean"})
+			if delErr != nil {
 				fmt.Printf("   ❌ Failed to delete session %s: %v\n", session.ID, delErr)
 			} else {
 				fmt.Printf("   🗑️  Deleted session %s.\n", session.ID)
@@ -61,3 +71,10 @@ func autocleanSessions(cfg *config.Config) error {
 	fmt.Println("🎉 Autoclean complete!")
 	return nil
 }
+
+func sessionRepo(session jules.Session) string {
+	if session.SourceContext == nil {
+		return ""
+	}
+	return policy.RepoFromSource(session.SourceContext.Source)
+}
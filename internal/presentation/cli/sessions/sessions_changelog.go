@@ -0,0 +1,94 @@
+package sessions
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/SamyRai/go-jules"
+	"github.com/SamyRai/juleson/internal/commitmsg"
+	"github.com/SamyRai/juleson/internal/config"
+	"github.com/SamyRai/juleson/internal/jules/workspace"
+	"github.com/SamyRai/juleson/internal/presentation/cli/core"
+)
+
+// ChangelogOptions configures `sessions changelog`.
+type ChangelogOptions struct {
+	Only          []string
+	NoAI          bool
+	ChangelogFile string
+	JSON          bool
+}
+
+func writeSessionChangelog(ctx context.Context, cfg *config.Config, out io.Writer, sessionIDs []string, options ChangelogOptions) error {
+	result, err := core.DescribeSessions(ctx, cfg, core.NewJulesClient(cfg), sessionIDs, &workspace.PatchApplicationOptions{Only: options.Only}, !options.NoAI)
+	if err != nil {
+		return err
+	}
+	if options.ChangelogFile != "" {
+		if err := os.WriteFile(options.ChangelogFile, []byte(result.Changelog), 0o644); err != nil {
+			return fmt.Errorf("failed to write CHANGELOG fragment: %w", err)
+		}
+	}
+	if options.JSON {
+		encoder := json.NewEncoder(out)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(result)
+	}
+	printCommitMessages(out, result)
+	fmt.Fprintln(out, "\nCHANGELOG fragment:")
+	if result.Changelog == "" {
+		fmt.Fprintln(out, "  (no user-facing changes)")
+	} else {
+		fmt.Fprintln(out, indent(strings.TrimRight(result.Changelog, "\n")))
+	}
+	if options.ChangelogFile != "" {
+		fmt.Fprintf(out, "\nWrote the CHANGELOG fragment to %s\n", options.ChangelogFile)
+	}
+	return nil
+}
+
+// printCommitMessages prints each generated commit message under its
+// session, noting where the messages came from.
+func printCommitMessages(out io.Writer, result *commitmsg.Result) {
+	if result.Warning != "" {
+		fmt.Fprintf(out, "⚠️  Gemini failed, deriving messages from the changes: %s\n", result.Warning)
+	}
+	source := "derived from the changes"
+	if result.Source == commitmsg.SourceGemini {
+		source = "written by " + result.Model
+	}
+	for i, commit := range result.Commits {
+		if i > 0 {
+			fmt.Fprintln(out)
+		}
+		fmt.Fprintf(out, "Commit message for session %s (%s):\n", commit.SessionID, source)
+		fmt.Fprintln(out, indent(commit.String()))
+	}
+}
+
+// indent indents the non-empty lines of text by two spaces.
+func indent(text string) string {
+	lines := strings.Split(text, "\n")
+	for i, line := range lines {
+		if line != "" {
+			lines[i] = "  " + line
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// suggestCommitMessage prints the commit message for applied session
+// changes. Failing to write one does not fail the apply.
+func suggestCommitMessage(ctx context.Context, cfg *config.Config, client *jules.Client, sessionID string, options *workspace.PatchApplicationOptions) {
+	result, err := core.DescribeSessions(ctx, cfg, client, []string{sessionID}, options, true)
+	if err != nil {
+		fmt.Printf("⚠️  Could not write a commit message: %v\n", err)
+		return
+	}
+	fmt.Println()
+	printCommitMessages(os.Stdout, result)
+}
@@ -3,15 +3,19 @@ package sessions
 import (
 	"context"
 	"fmt"
+	"os"
 	"strings"
 	"time"
 
+	"github.com/SamyRai/go-jules"
+	"github.com/SamyRai/juleson/internal/contextpack"
 	"github.com/SamyRai/juleson/internal/intelligence"
 	"github.com/SamyRai/juleson/internal/presentation/cli/core"
 
 	"github.com/SamyRai/juleson/internal/config"
 	julessessions "github.com/SamyRai/juleson/internal/jules/sessions"
 	"github.com/SamyRai/juleson/internal/jules/workspace"
+	"github.com/SamyRai/juleson/internal/policy"
 )
 
 func createSession(cfg *config.Config, sourceID string, prompt string, options CreateSessionOptions) error {
@@ -24,10 +28,24 @@ func createSession(cfg *config.Config, sourceID string, prompt string, options C
 		}
 		prompt = loadedPrompt
 	}
+	prompt, err := attachContext(ctx, cfg, prompt, options)
+	if err != nil {
+		return err
+	}
 
 	if options.WithIntel {
 		fmt.Printf("🧠 Analyzing codebase intelligence...\n")
 
+		// Collect project context
+		smells := intelligence.DefaultSmellOptions()
+		cache := intelligence.OpenProjectCache(".")
+		if project, err := intelligence.AnalyzeProject(ctx, ".", intelligence.ProjectContextOptions{Cache: cache, Smells: &smells}); err == nil {
+			prompt += "\n\n" + project.PromptContext()
+			_ = cache.Save()
+		} else {
+			fmt.Printf("⚠️  Could not attach project context: %v\n", err)
+		}
+
 		// Collect Dependencies
 		if graph, err := intelligence.AnalyzeDependencies(ctx, "."); err == nil {
 			prompt += "\n\n### Project Dependency Graph\n"
@@ -62,11 +80,11 @@ func createSession(cfg *config.Config, sourceID string, prompt string, options C
 	}
 	sourceName := julessessions.NormalizeSourceID(sourceID)
 	if !options.NoSource && sourceID == "." {
-		source, err := workspace.InferSourceFromGitRemote(ctx, julesClient, ".")
+		source, err := workspace.ResolveSource(ctx, julesClient, ".")
 		if err != nil {
 			return err
 		}
-		sourceName = source.Name
+		sourceName = source
 	}
 
 	fmt.Printf("🚀 Creating new Jules session...\n")
@@ -77,7 +95,7 @@ func createSession(cfg *config.Config, sourceID string, prompt string, options C
 	}
 	fmt.Printf("Prompt: %s\n\n", prompt)
 
-	req, err := julessessions.BuildCreateSessionRequest(julessessions.CreateSessionRequestOptions{
+	requestOptions := julessessions.CreateSessionRequestOptions{
 		Prompt:              prompt,
 		Source:              sourceName,
 		NoSource:            options.NoSource,
@@ -85,7 +103,9 @@ func createSession(cfg *config.Config, sourceID string, prompt string, options C
 		StartingBranch:      options.StartingBranch,
 		RequirePlanApproval: options.RequirePlanApproval,
 		AutomationMode:      options.AutomationMode,
-	})
+	}
+	julessessions.ApplyDefaults(&requestOptions, cfg.Sessions, options.PlanApprovalSet)
+	req, err := julessessions.BuildCreateSessionRequest(requestOptions)
 	if err == julessessions.ErrStartingBranchRequiresSource {
 		return fmt.Errorf("--starting-branch requires a source-backed session")
 	}
@@ -93,7 +113,24 @@ func createSession(cfg *config.Config, sourceID string, prompt string, options C
 		return err
 	}
 
+	if !requestOptions.RequirePlanApproval {
+		if err := core.EnforcePolicy(cfg, policy.Check{
+			Request: policy.Request{
+				Operation: policy.OpAutoApprovePlan,
+				Tool:      "sessions create",
+				Repo:      policy.RepoFromSource(sourceName),
+			},
+			ApprovalID: options.ApprovalID,
+		}, true); err != nil {
+			return fmt.Errorf("%w (pass --require-plan-approval to review the plan first)", err)
+		}
+	}
+
+	if err := core.CheckSessionBudget(cfg, core.AuditSourceCLI, 1); err != nil {
+		return err
+	}
 	session, err := julesClient.Sessions().Create(ctx, req)
+	recordSessionCreate(cfg, session, sourceName, err)
 	if err != nil {
 		return fmt.Errorf("failed to create session: %w", err)
 	}
@@ -114,6 +151,108 @@ func createSession(cfg *config.Config, sourceID string, prompt string, options C
 	return nil
 }
 
+// attachContext embeds the files and diffs requested in options in prompt.
+// Files that do not fit the context budget are packed: each keeps an outline
+// of its symbols and the parts most relevant to the prompt, ranked with
+// Gemini embeddings when Gemini is configured.
+func attachContext(ctx context.Context, cfg *config.Config, prompt string, options CreateSessionOptions) (string, error) {
+	budget := options.ContextBudget
+	if budget <= 0 {
+		budget = julessessions.DefaultMaxAttachmentsBytes / contextpack.BytesPerToken
+	}
+	composeOptions := julessessions.ComposeOptions{
+		MaxAttachmentBytes:  budget * contextpack.BytesPerToken / 3,
+		MaxAttachmentsBytes: budget * contextpack.BytesPerToken,
+	}
+
+	var diffs []julessessions.Attachment
+	diffTokens := 0
+	for _, base := range options.AttachDiffs {
+		attachment, err := julessessions.LoadDiffAttachment(ctx, ".", base)
+		if err != nil {
+			return "", err
+		}
+		diffs = append(diffs, attachment)
+		diffTokens += min(contextpack.EstimateTokens(attachment.Content), composeOptions.MaxAttachmentBytes/contextpack.BytesPerToken)
+	}
+	var attachments []julessessions.Attachment
+	if len(options.Attach) > 0 {
+		languages := map[string]string{}
+		var files []contextpack.File
+		for _, path := range options.Attach {
+			attachment, err := julessessions.LoadFileAttachment(path)
+			if err != nil {
+				return "", err
+			}
+			languages[attachment.Label] = attachment.Language
+			files = append(files, contextpack.File{Path: attachment.Label, Content: attachment.Content})
+		}
+		var ranker contextpack.Ranker = contextpack.LexicalRanker{}
+		if client := core.NewGeminiClient(cfg); client != nil {
+			ranker = contextpack.EmbeddingRanker{Embed: client.Embed}
+		}
+		// Diffs keep at least half of the budget.
+		packed := contextpack.Pack(ctx, prompt, files, contextpack.Options{
+			Budget:        budget - min(diffTokens, budget/2),
+			MaxFileTokens: composeOptions.MaxAttachmentBytes / contextpack.BytesPerToken,
+			Ranker:        ranker,
+		})
+		for _, note := range packed.Notes {
+			fmt.Printf("⚠️  Attachments %s\n", note)
+		}
+		for _, file := range packed.Files {
+			if !file.Whole {
+				fmt.Printf("📦 Attached %s: %d symbol(s) in full, %d outlined\n", file.Path, len(file.Included), len(file.Outlined))
+			}
+			attachments = append(attachments, julessessions.Attachment{Label: file.Path, Language: languages[file.Path], Content: file.Content})
+		}
+		for _, path := range packed.Omitted {
+			fmt.Printf("⚠️  Attachment left out %s: not even its outline fits the context budget\n", path)
+		}
+	}
+	attachments = append(attachments, diffs...)
+
+	prompt, notes := julessessions.ComposePrompt(prompt, attachments, composeOptions)
+	for _, note := range notes {
+		fmt.Printf("⚠️  Attachment %s\n", note)
+	}
+	return prompt, nil
+}
+
+// createWorkspaceSessions creates a session with the same prompt in every
+// repository of the enclosing workspace, continuing past failures.
+func createWorkspaceSessions(cfg *config.Config, prompt string, options CreateSessionOptions) error {
+	ws, err := workspace.Find(".")
+	if err != nil {
+		return err
+	}
+	if len(ws.Repos) == 0 {
+		return fmt.Errorf("workspace %s has no repositories", ws.Root)
+	}
+
+	julesClient := core.NewJulesClient(cfg)
+	failed := 0
+	for _, repo := range ws.Repos {
+		fmt.Printf("📁 %s\n", repo.Path)
+		source := repo.Source
+		var err error
+		if source == "" {
+			source, err = workspace.ResolveSource(context.Background(), julesClient, ws.Dir(repo))
+		}
+		if err == nil {
+			err = createSession(cfg, source, prompt, options)
+		}
+		if err != nil {
+			fmt.Printf("❌ %s: %v\n\n", repo.Path, err)
+			failed++
+		}
+	}
+	if failed > 0 {
+		return fmt.Errorf("failed to create sessions for %d of %d repositories", failed, len(ws.Repos))
+	}
+	return nil
+}
+
 func batchCreateSessions(cfg *config.Config, sourceID, taskFileOrPrompt string, options BatchSessionOptions) error {
 	if options.Parallel < 1 || options.Parallel > 5 {
 		return fmt.Errorf("--parallel must be between 1 and 5")
@@ -126,6 +265,19 @@ func batchCreateSessions(cfg *config.Config, sourceID, taskFileOrPrompt string,
 
 	julesClient := core.NewJulesClient(cfg)
 	ctx := context.Background()
+	if options.Estimate || cfg.Policy.Budget.ConfirmAbove > 0 {
+		estimate, err := julessessions.EstimateFromHistory(ctx, julesClient, options.Parallel, 1)
+		if err != nil {
+			return err
+		}
+		if options.Estimate {
+			core.PrintEstimate(os.Stdout, cfg, estimate)
+			return nil
+		}
+		if err := core.CheckEstimate(cfg, estimate, options.Confirm); err != nil {
+			return err
+		}
+	}
 	sourceName := julessessions.NormalizeSourceID(sourceID)
 	if options.BatchID == "" {
 		options.BatchID = "batch-" + time.Now().UTC().Format("20060102150405")
@@ -134,6 +286,10 @@ func batchCreateSessions(cfg *config.Config, sourceID, taskFileOrPrompt string,
 		options.GroupTitle = options.Title
 	}
 
+	if err := core.CheckSessionBudget(cfg, core.AuditSourceCLI, options.Parallel); err != nil {
+		return err
+	}
+
 	fmt.Printf("🚀 Creating %d parallel Jules session(s)\n", options.Parallel)
 	fmt.Printf("Batch ID: %s\n", options.BatchID)
 	if options.GroupTitle != "" {
@@ -153,19 +309,22 @@ func batchCreateSessions(cfg *config.Config, sourceID, taskFileOrPrompt string,
 			batchPrompt += fmt.Sprintf("Group title: %s\n", options.GroupTitle)
 		}
 		batchPrompt += fmt.Sprintf("Parallel run: %d/%d\n\n%s", i, options.Parallel, prompt)
-		req, err := julessessions.BuildCreateSessionRequest(julessessions.CreateSessionRequestOptions{
+		requestOptions := julessessions.CreateSessionRequestOptions{
 			Prompt:              batchPrompt,
 			Title:               title,
 			RequirePlanApproval: true,
 			AutomationMode:      options.AutomationMode,
 			Source:              sourceName,
 			StartingBranch:      options.StartingBranch,
-		})
+		}
+		julessessions.ApplyDefaults(&requestOptions, cfg.Sessions, true)
+		req, err := julessessions.BuildCreateSessionRequest(requestOptions)
 		if err != nil {
 			return err
 		}
 
 		session, err := julesClient.Sessions().Create(ctx, req)
+		recordSessionCreate(cfg, session, sourceName, err)
 		if err != nil {
 			return fmt.Errorf("created %d/%d sessions before failure: %w", i-1, options.Parallel, err)
 		}
@@ -178,3 +337,18 @@ func batchCreateSessions(cfg *config.Config, sourceID, taskFileOrPrompt string,
 
 	return nil
 }
+
+// recordSessionCreate audits a session creation, mirrors the new session to
+// the configured issue trackers, and exports it to event sinks.
+func recordSessionCreate(cfg *config.Config, session *jules.Session, source string, err error) {
+	target := source
+	if session != nil {
+		target = session.ID
+	}
+	core.RecordAudit(cfg, core.AuditSourceCLI, core.AuditSessionCreate, target, err, map[string]interface{}{"source": source})
+	if err == nil && session != nil {
+		event := julessessions.CreatedEvent(session)
+		core.MirrorEvent(cfg, event)
+		core.ExportEvent(cfg, event)
+	}
+}
@@ -11,6 +11,7 @@ import (
 	"github.com/SamyRai/go-jules"
 	"github.com/SamyRai/juleson/internal/config"
 	julessessions "github.com/SamyRai/juleson/internal/jules/sessions"
+	"github.com/SamyRai/juleson/internal/policy"
 	"github.com/SamyRai/juleson/internal/presentation/views"
 )
 
@@ -20,8 +21,19 @@ type CreateSessionOptions struct {
 	StartingBranch      string
 	AutomationMode      string
 	NoSource            bool
+	ApprovalID          string
 	RequirePlanApproval bool
-	WithIntel           bool
+	// PlanApprovalSet reports whether RequirePlanApproval was given
+	// explicitly; otherwise the configured session defaults decide.
+	PlanApprovalSet bool
+	WithIntel       bool
+	// Attach lists files whose contents are embedded in the prompt.
+	Attach []string
+	// AttachDiffs lists revisions whose diff against the working tree is
+	// embedded in the prompt.
+	AttachDiffs []string
+	// ContextBudget is the tokens attachments may take; zero is the default.
+	ContextBudget int
 }
 
 type BatchSessionOptions struct {
@@ -31,15 +43,30 @@ type BatchSessionOptions struct {
 	StartingBranch string
 	AutomationMode string
 	Parallel       int
+	// Estimate prints the estimated session time instead of creating sessions.
+	Estimate bool
+	// Confirm starts batches estimated above policy.budget.confirm_above.
+	Confirm bool
 }
 
 type ApplySessionOptions struct {
 	ActivityID        string
+	ApprovalID        string
 	ArtifactIndex     int
 	Confirm           bool
 	AllowDirty        bool
+	Force             bool
 	HasArtifactIndex  bool
 	AllowBaseMismatch bool
+	AllowSecrets      bool
+	AllowUnsafe       bool
+	Isolate           bool
+	KeepWorktree      bool
+	Checks            []string
+	// Only applies just the changes to files matching these globs.
+	Only []string
+	// Interactive asks which hunks to apply.
+	Interactive bool
 }
 
 func approveSessionPlan(cfg *config.Config, sessionID string) error {
@@ -56,8 +83,9 @@ func approveSessionPlan(cfg *config.Config, sessionID string) error {
 	fmt.Printf("✅ Approving plan for session: %s\n", sessionID)
 
 	err = julesClient.Sessions().ApprovePlan(ctx, sessionID)
+	core.RecordAudit(cfg, core.AuditSourceCLI, core.AuditSessionApprovePlan, sessionID, err, nil)
 	if err != nil {
-		return fmt.Errorf("failed to approve plan: %w", err)
+		return fmt.Errorf("failed to approve plan: %w", julessessions.Classify(err))
 	}
 
 	fmt.Println("✅ Plan approved successfully!")
@@ -65,7 +93,7 @@ func approveSessionPlan(cfg *config.Config, sessionID string) error {
 
 	return nil
 }
-func deleteSession(cfg *config.Config, sessionID string, force bool) error {
+func deleteSession(cfg *config.Config, sessionID string, force bool, approvalID string) error {
 	julesClient := core.NewJulesClient(cfg)
 
 	if !force {
@@ -82,7 +110,18 @@ func deleteSession(cfg *config.Config, sessionID string, force bool) error {
 		}
 	}
 
-	if err := julesClient.Sessions().Delete(context.Background(), sessionID); err != nil {
+	// The typed session ID or --force satisfies a confirm decision.
+	if err := core.EnforcePolicy(cfg, policy.Check{
+		Request:    policy.Request{Operation: policy.OpDeleteSession, Tool: "sessions delete", Target: sessionID},
+		Confirmed:  true,
+		ApprovalID: approvalID,
+	}, false); err != nil {
+		return err
+	}
+
+	err := julesClient.Sessions().Delete(context.Background(), sessionID)
+	core.RecordAudit(cfg, core.AuditSourceCLI, core.AuditSessionDelete, sessionID, err, nil)
+	if err != nil {
 		return fmt.Errorf("failed to delete session: %w", err)
 	}
 
@@ -329,8 +368,9 @@ func sendSessionMessage(cfg *config.Config, sessionID string, message string) er
 	}
 
 	err := julesClient.Sessions().SendMessage(ctx, sessionID, req)
+	core.RecordAudit(cfg, core.AuditSourceCLI, core.AuditSessionMessage, sessionID, err, nil)
 	if err != nil {
-		return fmt.Errorf("failed to send message: %w", err)
+		return fmt.Errorf("failed to send message: %w", julessessions.Classify(err))
 	}
 
 	fmt.Println("✅ Message sent successfully!")
@@ -1,33 +1,47 @@
 package sessions
 
 import (
+	"bufio"
 	"context"
 	"fmt"
-	"github.com/SamyRai/juleson/internal/presentation/cli/core"
+	"io"
+	"os"
 	"strings"
 	"time"
 
+	"github.com/SamyRai/juleson/internal/presentation/cli/core"
+
 	"github.com/SamyRai/go-jules"
 	"github.com/SamyRai/juleson/internal/config"
+	"github.com/SamyRai/juleson/internal/guard"
 	julessessions "github.com/SamyRai/juleson/internal/jules/sessions"
 	"github.com/SamyRai/juleson/internal/jules/workspace"
+	"github.com/SamyRai/juleson/internal/policy"
 	"github.com/SamyRai/juleson/internal/presentation/tui/conflict"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/huh"
+	"github.com/mattn/go-isatty"
 )
 
 func applySessionChanges(cfg *config.Config, sessionID, projectPath string, options ApplySessionOptions) error {
 	julesClient := core.NewJulesClient(cfg)
 	ctx := context.Background()
 
+	// Isolated applies leave the tree alone until validation passes, and the
+	// merge refuses to touch conflicting local changes, so dirty trees are fine.
 	preparation, err := julessessions.PreparePatchApplication(ctx, julessessions.PatchRequest{
 		WorkingDir:        projectPath,
 		Confirm:           options.Confirm,
-		AllowDirty:        options.AllowDirty,
+		AllowDirty:        options.AllowDirty || options.Isolate,
+		Force:             options.Force,
 		ActivityID:        options.ActivityID,
 		ArtifactIndex:     options.ArtifactIndex,
 		HasArtifactIndex:  options.HasArtifactIndex,
 		AllowBaseMismatch: options.AllowBaseMismatch,
+		AllowSecrets:      options.AllowSecrets,
+		AllowUnsafe:       options.AllowUnsafe,
+		Guard:             core.NewGuard(cfg),
+		Only:              options.Only,
 	})
 	if err != nil {
 		if preparation != nil && preparation.Blocker != "" {
@@ -36,9 +50,40 @@ func applySessionChanges(cfg *config.Config, sessionID, projectPath string, opti
 		return err
 	}
 	patchOptions := preparation.Options
+	if options.Interactive {
+		if !isatty.IsTerminal(os.Stdin.Fd()) {
+			return fmt.Errorf("--interactive needs a terminal")
+		}
+		hunks, err := workspace.ListSessionHunks(ctx, julesClient, sessionID, patchOptions)
+		if err != nil {
+			return err
+		}
+		keys, err := chooseHunks(os.Stdin, os.Stdout, hunks)
+		if err != nil {
+			return err
+		}
+		if len(keys) == 0 {
+			return fmt.Errorf("no hunks selected; nothing to apply")
+		}
+		patchOptions.SelectHunk = workspace.SelectHunkKeys(keys)
+	}
 	changes, previewErr := workspace.PreviewSessionPatchesWithOptions(ctx, julesClient, sessionID, patchOptions)
 	if changes != nil {
 		printSessionChangesSummary(changes)
+		if len(changes.SecretFindings) > 0 && !preparation.DryRun && !options.AllowSecrets {
+			return fmt.Errorf("refusing to apply: patches add %d possible secret(s); remove them or pass --allow-secrets", len(changes.SecretFindings))
+		}
+		if len(changes.GuardFindings) > 0 && !preparation.DryRun && !options.AllowUnsafe && patchOptions.Guard.Mode(guard.DirectionOutput) == guard.ModeBlock {
+			return fmt.Errorf("refusing to apply: patches add %d dangerous instruction(s); remove them or pass --allow-unsafe", len(changes.GuardFindings))
+		}
+		if !preparation.DryRun {
+			if err := core.CheckFilesBudget(cfg, core.AuditSourceCLI, sessionID, len(changes.Files)); err != nil {
+				return fmt.Errorf("refusing to apply: %w", err)
+			}
+		}
+	}
+	if options.Isolate {
+		return applySessionChangesIsolated(ctx, cfg, julesClient, sessionID, projectPath, preparation, options)
 	}
 	if preparation.DryRun {
 		if previewErr != nil {
@@ -51,7 +96,22 @@ func applySessionChanges(cfg *config.Config, sessionID, projectPath string, opti
 		return fmt.Errorf("refusing to apply because preview failed: %w", previewErr)
 	}
 
+	if patchOptions.Force {
+		if err := core.EnforcePolicy(cfg, policy.Check{
+			Request: policy.Request{
+				Operation: policy.OpPatchApplyForce,
+				Tool:      "sessions apply",
+				Repo:      core.RepoForDir(cfg, projectPath),
+				Target:    sessionID,
+			},
+			ApprovalID: options.ApprovalID,
+		}, true); err != nil {
+			return err
+		}
+	}
+
 	result, err := workspace.ApplySessionPatches(ctx, julesClient, sessionID, patchOptions)
+	auditPatchApply(cfg, sessionID, patchOptions, result, err)
 	if err != nil {
 		return fmt.Errorf("failed to apply session patches: %w", err)
 	}
@@ -69,13 +129,83 @@ func applySessionChanges(cfg *config.Config, sessionID, projectPath string, opti
 			Run()
 
 		if err == nil && resolve {
-			return resolveConflictAgentically(ctx, julesClient, sessionID, projectPath, patchOptions)
+			return resolveConflictAgentically(ctx, cfg, julesClient, sessionID, projectPath, patchOptions)
 		}
 
 		return fmt.Errorf("some patches failed")
 	}
 
 	fmt.Printf("\n✅ Applied %d patch(es) touching %d file(s).\n", result.PatchesApplied, len(result.FilesModified))
+	suggestCommitMessage(ctx, cfg, julesClient, sessionID, patchOptions)
+	return nil
+}
+
+// applySessionChangesIsolated applies and validates patches in a temporary
+// worktree. Without --confirm the validated changes are not merged.
+func applySessionChangesIsolated(ctx context.Context, cfg *config.Config, client *jules.Client, sessionID, projectPath string, preparation *julessessions.PatchPreparation, options ApplySessionOptions) error {
+	patchOptions := preparation.Options
+	if patchOptions.Force && !preparation.DryRun {
+		if err := core.EnforcePolicy(cfg, policy.Check{
+			Request: policy.Request{
+				Operation: policy.OpPatchApplyForce,
+				Tool:      "sessions apply",
+				Repo:      core.RepoForDir(cfg, projectPath),
+				Target:    sessionID,
+			},
+			ApprovalID: options.ApprovalID,
+		}, true); err != nil {
+			return err
+		}
+	}
+
+	fmt.Println("\nApplying patches in a temporary worktree...")
+	result, err := workspace.ApplySessionPatchesIsolated(ctx, client, sessionID, workspace.IsolatedApplyOptions{
+		Patch:        patchOptions,
+		Checks:       options.Checks,
+		KeepWorktree: options.KeepWorktree,
+	})
+	if result != nil && result.Patch != nil {
+		for _, warning := range result.Patch.Warnings {
+			fmt.Printf("⚠️  %s\n", warning)
+		}
+		for _, patchErr := range result.Patch.Errors {
+			fmt.Printf("❌ %s\n", patchErr)
+		}
+	}
+	if result != nil {
+		for _, check := range result.Checks {
+			status := "✅"
+			if !check.Success {
+				status = "❌"
+			}
+			fmt.Printf("%s %s\n", status, check.Command)
+			if !check.Success {
+				fmt.Println(strings.TrimSpace(check.Output))
+			}
+		}
+		if result.WorktreePath != "" {
+			fmt.Printf("Worktree kept at %s\n", result.WorktreePath)
+		}
+	}
+	if !preparation.DryRun {
+		var patchResult *workspace.PatchApplicationResult
+		if result != nil && result.Patch != nil {
+			merged := *result.Patch
+			merged.FilesModified = result.FilesMerged
+			patchResult = &merged
+		}
+		auditPatchApply(cfg, sessionID, patchOptions, patchResult, err)
+	}
+	if err != nil {
+		return fmt.Errorf("isolated apply failed: %w", err)
+	}
+
+	if preparation.DryRun {
+		fmt.Printf("\nValidation passed. Re-run with --confirm to merge the changes into %s.\n", projectPath)
+		return nil
+	}
+	fmt.Printf("\n✅ Validated and merged %d patch(es) touching %d file(s).\n", result.Patch.PatchesApplied, len(result.FilesMerged))
+	suggestCommitMessage(ctx, cfg, client, sessionID, patchOptions)
 	return nil
 }
 
@@ -88,7 +218,7 @@ func printSessionChangesSummary(changes *workspace.SessionChanges) {
 	}
 	fmt.Printf("Patch summary: %d patch(es), %d file(s), +%d -%d\n", changes.TotalPatches, len(changes.Files), totalAdded, totalRemoved)
 	for _, file := range changes.Files {
-		fmt.Printf("  %s (+%d -%d)\n", file.Path, file.LinesAdded, file.LinesRemoved)
+		fmt.Printf("  %s (+%d -%d)\n", file.Label(), file.LinesAdded, file.LinesRemoved)
 	}
 	for _, message := range changes.SuggestedCommitMessages {
 		fmt.Printf("Suggested commit message: %s\n", message)
@@ -96,9 +226,15 @@ func printSessionChangesSummary(changes *workspace.SessionChanges) {
 	for _, warning := range changes.Warnings {
 		fmt.Printf("Warning: %s\n", warning)
 	}
+	for _, finding := range changes.SecretFindings {
+		fmt.Printf("Possible secret: %s:%d %s (%s)\n", finding.File, finding.Line, finding.Description, finding.Match)
+	}
+	for _, finding := range changes.GuardFindings {
+		fmt.Printf("Dangerous instruction: %s\n", finding)
+	}
 }
 
-func resolveConflictAgentically(ctx context.Context, client *jules.Client, sessionID, projectPath string, patchOptions *workspace.PatchApplicationOptions) error {
+func resolveConflictAgentically(ctx context.Context, cfg *config.Config, client *jules.Client, sessionID, projectPath string, patchOptions *workspace.PatchApplicationOptions) error {
 	// For simplicity, we get the last patch details to send
 	changes, err := workspace.GetSessionChangesWithOptions(ctx, client, sessionID, patchOptions)
 	if err != nil || changes == nil || len(changes.Files) == 0 {
@@ -155,6 +291,7 @@ func resolveConflictAgentically(ctx context.Context, client *jules.Client, sessi
 	}
 
 	err = client.Sessions().SendMessage(ctx, sessionID, req)
+	core.RecordAudit(cfg, core.AuditSourceCLI, core.AuditSessionMessage, sessionID, err, map[string]interface{}{"reason": "conflict_resolution"})
 	if err != nil {
 		return fmt.Errorf("failed to send resolution request to agent: %w", err)
 	}
@@ -233,3 +370,72 @@ func handleResolutionResponse(ctx context.Context, client *jules.Client, session
 
 	return nil
 }
+
+func auditPatchApply(cfg *config.Config, sessionID string, options *workspace.PatchApplicationOptions, result *workspace.PatchApplicationResult, err error) {
+	details := map[string]interface{}{"force": options.Force}
+	if result != nil {
+		details["patches_applied"] = result.PatchesApplied
+		details["files_modified"] = result.FilesModified
+		if len(result.Errors) > 0 {
+			details["errors"] = result.Errors
+			if err == nil {
+				err = fmt.Errorf("%d patch(es) failed to apply", len(result.Errors))
+			}
+		}
+	}
+	core.RecordAudit(cfg, core.AuditSourceCLI, core.AuditPatchApply, sessionID, err, details)
+}
+
+// chooseHunks asks about each hunk in turn, as git add --patch does, and
+// returns the keys of the hunks to apply.
+func chooseHunks(in io.Reader, out io.Writer, hunks []workspace.PatchHunk) ([]string, error) {
+	reader := bufio.NewReader(in)
+	var keys []string
+	// skipFile is the file whose remaining hunks were all accepted or all
+	// rejected.
+	skipFile, acceptFile := "", false
+	for i, hunk := range hunks {
+		fileKey := fmt.Sprintf("%s/%d/%s", hunk.ActivityID, hunk.ArtifactIndex, hunk.File)
+		if fileKey == skipFile {
+			if acceptFile {
+				keys = append(keys, hunk.Key())
+			}
+			continue
+		}
+
+		fmt.Fprintf(out, "\n%s (%d/%d)\n", hunk.File, hunk.Index+1, hunk.Count)
+		if hunk.Header == "" {
+			fmt.Fprintln(out, "(mode, rename, or binary change)")
+		} else {
+			fmt.Fprintln(out, hunk.Header)
+			fmt.Fprint(out, hunk.Text)
+		}
+		for {
+			fmt.Fprintf(out, "(%d/%d) Apply this hunk [y,n,a,d,q,?]? ", i+1, len(hunks))
+			answer, err := reader.ReadString('\n')
+			if err != nil && answer == "" {
+				if err == io.EOF {
+					return keys, nil
+				}
+				return nil, fmt.Errorf("failed to read answer: %w", err)
+			}
+			switch strings.TrimSpace(strings.ToLower(answer)) {
+			case "y":
+				keys = append(keys, hunk.Key())
+			case "n":
+			case "a":
+				keys = append(keys, hunk.Key())
+				skipFile, acceptFile = fileKey, true
+			case "d":
+				skipFile, acceptFile = fileKey, false
+			case "q":
+				return keys, nil
+			default:
+				fmt.Fprintln(out, "y - apply this hunk\nn - skip this hunk\na - apply this and the remaining hunks of the file\nd - skip this and the remaining hunks of the file\nq - apply only the hunks chosen so far")
+				continue
+			}
+			break
+		}
+	}
+	return keys, nil
+}
@@ -8,6 +8,7 @@ import (
 	"time"
 
 	"github.com/SamyRai/go-jules"
+	"github.com/SamyRai/juleson/internal/jules/workspace"
 	"github.com/jarcoal/httpmock"
 )
 
@@ -59,3 +60,29 @@ func TestHandleResolutionResponse(t *testing.T) {
 		t.Errorf("Missing patch message in output: %s", output)
 	}
 }
+
+func TestChooseHunks(t *testing.T) {
+	hunks := []workspace.PatchHunk{
+		{ActivityID: "a", File: "one.go", Index: 0, Count: 3, Header: "@@ -1 +1 @@"},
+		{ActivityID: "a", File: "one.go", Index: 1, Count: 3, Header: "@@ -5 +5 @@"},
+		{ActivityID: "a", File: "one.go", Index: 2, Count: 3, Header: "@@ -9 +9 @@"},
+		{ActivityID: "a", File: "two.go", Index: 0, Count: 2, Header: "@@ -1 +1 @@"},
+		{ActivityID: "a", File: "two.go", Index: 1, Count: 2, Header: "@@ -4 +4 @@"},
+		{ActivityID: "a", File: "three.go", Index: 0, Count: 1, Header: "@@ -1 +1 @@"},
+	}
+
+	// n, then a for the rest of one.go; ? then d for two.go; three.go is
+	// never asked about after q.
+	var out strings.Builder
+	keys, err := chooseHunks(strings.NewReader("n\na\n?\nd\nq\n"), &out, hunks)
+	if err != nil {
+		t.Fatalf("chooseHunks: %v", err)
+	}
+	want := []string{"a/0/one.go#1", "a/0/one.go#2"}
+	if strings.Join(keys, ",") != strings.Join(want, ",") {
+		t.Errorf("keys = %v, want %v", keys, want)
+	}
+	if !strings.Contains(out.String(), "d - skip this and the remaining hunks of the file") {
+		t.Errorf("? did not print help:\n%s", out.String())
+	}
+}
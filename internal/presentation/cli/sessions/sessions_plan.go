@@ -0,0 +1,474 @@
+package sessions
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/SamyRai/go-jules"
+	"github.com/SamyRai/juleson/internal/config"
+	"github.com/SamyRai/juleson/internal/events"
+	"github.com/SamyRai/juleson/internal/intelligence"
+	julessessions "github.com/SamyRai/juleson/internal/jules/sessions"
+	"github.com/SamyRai/juleson/internal/jules/workspace"
+	"github.com/SamyRai/juleson/internal/presentation/cli/core"
+	"github.com/SamyRai/juleson/internal/presentation/views/theme"
+	"github.com/SamyRai/juleson/internal/templates"
+	"github.com/mattn/go-isatty"
+	"gopkg.in/yaml.v3"
+)
+
+// PlanGoalOptions configures a planning-only session.
+type PlanGoalOptions struct {
+	ProjectPath    string
+	Source         string
+	StartingBranch string
+	Output         string
+	Timeout        time.Duration
+	JSON           bool
+	// NoReview prints the plan without asking whether to accept it.
+	NoReview bool
+}
+
+// planPollInterval is how often plan waits check for a generated plan.
+const planPollInterval = 10 * time.Second
+
+type planGoalResult struct {
+	Session  *jules.Session             `json:"session"`
+	Plan     *julessessions.PlanSummary `json:"plan"`
+	Template string                     `json:"template"`
+	Output   string                     `json:"output,omitempty"`
+}
+
+// planGoal creates a session that must have its plan approved, waits for
+// Jules to plan the goal, and prints the steps. On a terminal the plan is
+// then accepted, revised with feedback, or rejected; nothing runs until it
+// is accepted.
+func planGoal(cfg *config.Config, goal string, options PlanGoalOptions) error {
+	ctx := context.Background()
+	julesClient := core.NewJulesClient(cfg)
+
+	prompt := goal + "\n\nPlan this change as small, ordered steps that can each be reviewed on their own. " +
+		"Do not change anything until the plan is approved."
+	smells := intelligence.DefaultSmellOptions()
+	cache := intelligence.OpenProjectCache(options.ProjectPath)
+	project, err := intelligence.AnalyzeProject(ctx, options.ProjectPath, intelligence.ProjectContextOptions{Cache: cache, Smells: &smells})
+	if err != nil {
+		return fmt.Errorf("project analysis failed: %w", err)
+	}
+	_ = cache.Save()
+	prompt += "\n\n" + project.PromptContext()
+
+	sourceName := julessessions.NormalizeSourceID(options.Source)
+	if options.Source == "" {
+		source, err := workspace.ResolveSource(ctx, julesClient, options.ProjectPath)
+		if err != nil {
+			return err
+		}
+		sourceName = source
+	}
+	requestOptions := julessessions.CreateSessionRequestOptions{
+		Prompt:              prompt,
+		Source:              sourceName,
+		Title:               "Plan: " + truncate(goal, 60),
+		StartingBranch:      options.StartingBranch,
+		RequirePlanApproval: true,
+	}
+	julessessions.ApplyDefaults(&requestOptions, cfg.Sessions, true)
+	req, err := julessessions.BuildCreateSessionRequest(requestOptions)
+	if err != nil {
+		return err
+	}
+
+	if err := core.CheckSessionBudget(cfg, core.AuditSourceCLI, 1); err != nil {
+		return err
+	}
+	session, err := julesClient.Sessions().Create(ctx, req)
+	recordSessionCreate(cfg, session, sourceName, err)
+	if err != nil {
+		return fmt.Errorf("failed to create session: %w", err)
+	}
+	if !options.JSON {
+		fmt.Printf("🧭 Session %s is planning: %s\n", session.ID, goal)
+		fmt.Printf("⏳ Waiting up to %s for the plan...\n\n", options.Timeout)
+	}
+
+	name := templates.PlanTemplateName(goal)
+	interactive := !options.JSON && !options.NoReview && isatty.IsTerminal(os.Stdin.Fd())
+	previousPlanID := ""
+	for {
+		waitCtx, cancel := context.WithTimeout(ctx, options.Timeout)
+		plan, err := julessessions.WaitForPlan(waitCtx, julesClient, session.ID, previousPlanID, planPollInterval)
+		cancel()
+		if err != nil {
+			return fmt.Errorf("%w (check later with 'juleson sessions plans %s')", err, session.ID)
+		}
+		var adaptations []templates.PlanAdaptation
+		if options.Output != "" {
+			adaptations, err = savePlanTemplate(options.Output, name, goal, previousPlanID, plan)
+			if err != nil {
+				return err
+			}
+		}
+		if options.JSON {
+			return printJSON(planGoalResult{Session: session, Plan: plan, Template: name, Output: options.Output})
+		}
+		printPlanSteps(plan)
+		if previousPlanID != "" && plan.Changes != nil {
+			printPlanChanges("", *plan.Changes)
+			auditPlanRevision(cfg, session.ID, previousPlanID, plan, adaptations)
+		}
+		if len(adaptations) > 0 {
+			printPlanAdaptations(options.Output, adaptations)
+			exportPlanAdapted(cfg, session.ID, previousPlanID, plan.PlanID, name, adaptations)
+		}
+		fmt.Println()
+		if !interactive {
+			printPlanNextSteps(session.ID, name, options.Output)
+			return nil
+		}
+
+		decision, err := theme.Select("Review the plan", []string{planAccept, planModify, planReject, planLater})
+		if err != nil {
+			return fmt.Errorf("failed to read decision: %w", err)
+		}
+		switch decision {
+		case planAccept:
+			return approveSessionPlan(cfg, session.ID)
+		case planReject:
+			return deleteSession(cfg, session.ID, true, "")
+		case planModify:
+			feedback, err := theme.InputString("What should change?", "")
+			if err != nil {
+				return fmt.Errorf("failed to read feedback: %w", err)
+			}
+			if strings.TrimSpace(feedback) == "" {
+				continue
+			}
+			if err := sendPlanFeedback(ctx, cfg, julesClient, session.ID, *plan, julessessions.PlanFeedback{Message: feedback}); err != nil {
+				return err
+			}
+			fmt.Printf("⏳ Waiting for the revised plan...\n\n")
+			previousPlanID = plan.PlanID
+		default:
+			printPlanNextSteps(session.ID, name, options.Output)
+			return nil
+		}
+	}
+}
+
+// Choices offered when reviewing a plan interactively.
+const (
+	planAccept = "Accept and start work"
+	planModify = "Modify: ask Jules to revise the plan"
+	planReject = "Reject and delete the session"
+	planLater  = "Decide later"
+)
+
+// SessionPlanOptions configures reviewing the plan of an existing session.
+type SessionPlanOptions struct {
+	// Reject and Comment are feedback that asks Jules for a new plan.
+	Reject  string
+	Comment string
+	// Step is the 1-based step Reject or Comment is about, or 0 for the
+	// whole plan.
+	Step    int
+	Timeout time.Duration
+	Approve bool
+	JSON    bool
+}
+
+// reviewSessionPlan prints a session's latest plan, what changed from the
+// one before it, and then approves it or sends feedback as options ask. On a
+// terminal without those options the reviewer decides interactively and
+// revised plans are reviewed in turn.
+func reviewSessionPlan(cfg *config.Config, sessionID string, options SessionPlanOptions) error {
+	actions := 0
+	for _, set := range []bool{options.Approve, options.Reject != "", options.Comment != ""} {
+		if set {
+			actions++
+		}
+	}
+	if actions > 1 {
+		return fmt.Errorf("--approve, --reject, and --comment are mutually exclusive")
+	}
+	if options.Step != 0 && options.Reject == "" && options.Comment == "" {
+		return fmt.Errorf("--step requires --reject or --comment")
+	}
+
+	ctx := context.Background()
+	julesClient := core.NewJulesClient(cfg)
+	activities, err := julesClient.Activities().ListAll(ctx, sessionID, 100)
+	if err != nil {
+		return fmt.Errorf("failed to list activities: %w", julessessions.Classify(err))
+	}
+	plan := julessessions.LatestPlanSummary(julessessions.ExtractPlanSummaries(activities))
+	if plan == nil {
+		return fmt.Errorf("session %s has no plan yet (wait for one with 'juleson sessions watch %s')", sessionID, sessionID)
+	}
+	if options.JSON && actions == 0 {
+		return printJSON(plan)
+	}
+
+	interactive := actions == 0 && !options.JSON && isatty.IsTerminal(os.Stdin.Fd())
+	for {
+		if !options.JSON {
+			printPlanSteps(plan)
+			if plan.Changes != nil && !plan.Changes.IsEmpty() {
+				printPlanChanges("", *plan.Changes)
+			}
+			fmt.Println()
+		}
+		if plan.Approved {
+			if options.Approve || options.Reject != "" || options.Comment != "" {
+				return fmt.Errorf("plan %s is already approved", plan.PlanID)
+			}
+			fmt.Println("✅ This plan is approved.")
+			return nil
+		}
+
+		var feedback julessessions.PlanFeedback
+		switch {
+		case options.Approve:
+			return approveSessionPlan(cfg, sessionID)
+		case options.Reject != "":
+			feedback = julessessions.PlanFeedback{Message: options.Reject, Step: options.Step, Reject: true}
+		case options.Comment != "":
+			feedback = julessessions.PlanFeedback{Message: options.Comment, Step: options.Step}
+		case !interactive:
+			fmt.Printf("💡 Approve it with 'juleson sessions plan %s --approve', or ask for a new plan with --reject or --comment\n", sessionID)
+			return nil
+		default:
+			decision, chosen, err := askPlanDecision(plan)
+			if err != nil {
+				return err
+			}
+			switch decision {
+			case planAccept:
+				return approveSessionPlan(cfg, sessionID)
+			case planLater:
+				fmt.Printf("💡 Review it again with 'juleson sessions plan %s'\n", sessionID)
+				return nil
+			}
+			if strings.TrimSpace(chosen.Message) == "" {
+				continue
+			}
+			feedback = chosen
+		}
+
+		if err := sendPlanFeedback(ctx, cfg, julesClient, sessionID, *plan, feedback); err != nil {
+			return err
+		}
+		if !interactive {
+			if options.JSON {
+				return printJSON(map[string]string{"session_id": sessionID, "plan_id": plan.PlanID, "status": "feedback_sent"})
+			}
+			fmt.Printf("💬 Feedback sent. Review the new plan with 'juleson sessions plan %s'\n", sessionID)
+			return nil
+		}
+
+		fmt.Printf("⏳ Waiting up to %s for the new plan...\n\n", options.Timeout)
+		waitCtx, cancel := context.WithTimeout(ctx, options.Timeout)
+		revised, err := julessessions.WaitForPlan(waitCtx, julesClient, sessionID, plan.PlanID, planPollInterval)
+		cancel()
+		if err != nil {
+			return fmt.Errorf("%w (check later with 'juleson sessions plan %s')", err, sessionID)
+		}
+		if revised.Changes != nil {
+			auditPlanRevision(cfg, sessionID, plan.PlanID, revised, nil)
+		}
+		plan = revised
+	}
+}
+
+// Choices offered when reviewing an existing session's plan, besides
+// planAccept and planLater.
+const (
+	planComment     = "Comment on the plan"
+	planCommentStep = "Comment on a step"
+	planRejectNew   = "Reject: ask Jules for a new plan"
+)
+
+// askPlanDecision asks the reviewer what to do with plan. For feedback
+// choices it also reads the feedback, which is empty if none was given.
+func askPlanDecision(plan *julessessions.PlanSummary) (string, julessessions.PlanFeedback, error) {
+	var feedback julessessions.PlanFeedback
+	decision, err := theme.Select("Review the plan", []string{planAccept, planComment, planCommentStep, planRejectNew, planLater})
+	if err != nil {
+		return "", feedback, fmt.Errorf("failed to read decision: %w", err)
+	}
+	question := "What should change?"
+	switch decision {
+	case planAccept, planLater:
+		return decision, feedback, nil
+	case planCommentStep:
+		steps := make([]string, len(plan.Steps))
+		for i, step := range plan.Steps {
+			steps[i] = fmt.Sprintf("%d. %s", i+1, step.Title)
+		}
+		step, err := theme.Select("Which step?", steps)
+		if err != nil {
+			return "", feedback, fmt.Errorf("failed to read step: %w", err)
+		}
+		feedback.Step = slices.Index(steps, step) + 1
+		question = fmt.Sprintf("What should change in step %d?", feedback.Step)
+	case planRejectNew:
+		feedback.Reject = true
+		question = "Why is the plan rejected?"
+	}
+	feedback.Message, err = theme.InputString(question, "")
+	if err != nil {
+		return "", feedback, fmt.Errorf("failed to read feedback: %w", err)
+	}
+	return decision, feedback, nil
+}
+
+// sendPlanFeedback sends feedback on plan to Jules, which answers with a new
+// plan.
+func sendPlanFeedback(ctx context.Context, cfg *config.Config, client *jules.Client, sessionID string, plan julessessions.PlanSummary, feedback julessessions.PlanFeedback) error {
+	prompt, err := feedback.Prompt(plan)
+	if err != nil {
+		return err
+	}
+	err = client.Sessions().SendMessage(ctx, sessionID, &jules.SendMessageRequest{Prompt: prompt})
+	core.RecordAudit(cfg, core.AuditSourceCLI, core.AuditSessionMessage, sessionID, err, map[string]interface{}{
+		"plan":   plan.PlanID,
+		"reject": feedback.Reject,
+		"step":   feedback.Step,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to send message: %w", julessessions.Classify(err))
+	}
+	return nil
+}
+
+// auditPlanRevision records how plan changed from previousPlanID and how
+// the saved plan template was adapted to it.
+func auditPlanRevision(cfg *config.Config, sessionID, previousPlanID string, plan *julessessions.PlanSummary, adaptations []templates.PlanAdaptation) {
+	details := map[string]interface{}{
+		"plan":          plan.PlanID,
+		"previous_plan": previousPlanID,
+		"added":         plan.Changes.Added,
+		"removed":       plan.Changes.Removed,
+		"moved":         plan.Changes.Moved,
+		"edited":        plan.Changes.Edited,
+	}
+	if len(adaptations) > 0 {
+		details["adaptations"] = adaptations
+	}
+	core.RecordAudit(cfg, core.AuditSourceCLI, core.AuditSessionPlanRevised, sessionID, nil, details)
+}
+
+// exportPlanAdapted exports the plan.adapted event for a saved plan template
+// adapted to a revised plan.
+func exportPlanAdapted(cfg *config.Config, sessionID, previousPlanID, planID, name string, adaptations []templates.PlanAdaptation) {
+	data := events.PlanAdaptedData{SessionID: sessionID, PlanID: planID, PreviousPlanID: previousPlanID, Template: name}
+	for _, adaptation := range adaptations {
+		data.Adaptations = append(data.Adaptations, events.PlanAdaptationData(adaptation))
+	}
+	core.ExportEvent(cfg, events.NewEvent(events.EventPlanAdapted, "plan", data).WithTopic(events.TopicSession))
+}
+
+// savePlanTemplate saves plan as a template at path. For a revised plan the
+// template already saved there is adapted instead, so edits to the tasks
+// the revision keeps survive, and the adaptations are returned and added to
+// its revisions.
+func savePlanTemplate(path, name, goal, previousPlanID string, plan *julessessions.PlanSummary) ([]templates.PlanAdaptation, error) {
+	steps := make([]templates.PlanStep, 0, len(plan.Steps))
+	for _, step := range plan.Steps {
+		steps = append(steps, templates.PlanStep{Title: step.Title, Description: step.Description})
+	}
+	template := templates.NewPlanTemplate(name, goal, steps)
+	var adaptations []templates.PlanAdaptation
+	if previousPlanID != "" {
+		data, err := os.ReadFile(path)
+		switch {
+		case err == nil:
+			var saved templates.Template
+			if err := yaml.Unmarshal(data, &saved); err != nil {
+				return nil, fmt.Errorf("failed to read saved plan %s: %w", path, err)
+			}
+			adaptations = templates.AdaptPlanTemplate(&saved, steps)
+			saved.Revisions = append(saved.Revisions, templates.PlanRevision{
+				Plan:         plan.PlanID,
+				PreviousPlan: previousPlanID,
+				Time:         time.Now().UTC(),
+				Adaptations:  adaptations,
+			})
+			template = &saved
+		case !errors.Is(err, fs.ErrNotExist):
+			return nil, fmt.Errorf("failed to read saved plan: %w", err)
+		}
+	}
+
+	data, err := yaml.Marshal(template)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal plan: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, fmt.Errorf("failed to create output directory: %w", err)
+	}
+	if err := os.WriteFile(path, data, 0600); err != nil {
+		return nil, fmt.Errorf("failed to write plan: %w", err)
+	}
+	return adaptations, nil
+}
+
+// printPlanAdaptations prints how the template saved at path was adapted to
+// a revised plan.
+func printPlanAdaptations(path string, adaptations []templates.PlanAdaptation) {
+	fmt.Printf("🧩 Adapted the template in %s:\n", path)
+	markers := map[string]string{
+		templates.PlanAdaptationAdd:    "+",
+		templates.PlanAdaptationRemove: "-",
+		templates.PlanAdaptationMove:   "↕",
+		templates.PlanAdaptationEdit:   "~",
+	}
+	for _, adaptation := range adaptations {
+		fmt.Printf("  %s %s (%s)\n", markers[adaptation.Action], adaptation.Task, adaptation.Step)
+	}
+}
+
+func printPlanNextSteps(sessionID, name, output string) {
+	fmt.Printf("💡 Approve it to start work: juleson sessions approve %s\n", sessionID)
+	if output != "" {
+		fmt.Printf("💡 Saved as template '%s' in %s. To edit the plan instead, move it into the custom templates\n", name, output)
+		fmt.Printf("   path, run 'juleson template run %s', and delete this session with 'juleson sessions delete %s'\n", name, sessionID)
+	}
+}
+
+// printPlanChanges prints how a plan differs from the previous one, with
+// each line indented by indent.
+func printPlanChanges(indent string, changes julessessions.PlanChanges) {
+	fmt.Printf("%s🔁 Changes from the previous plan: %s\n", indent, changes)
+	for _, change := range []struct {
+		steps  []string
+		marker string
+	}{{changes.Added, "+"}, {changes.Removed, "-"}, {changes.Moved, "↕"}, {changes.Edited, "~"}} {
+		for _, title := range change.steps {
+			fmt.Printf("%s  %s %s\n", indent, change.marker, title)
+		}
+	}
+}
+
+// printPlanSteps prints a plan's steps in order with the step each one
+// follows.
+func printPlanSteps(plan *julessessions.PlanSummary) {
+	fmt.Printf("📋 Plan %s (%d steps)\n", plan.PlanID, len(plan.Steps))
+	for i, step := range plan.Steps {
+		fmt.Printf("  %d. %s", i+1, step.Title)
+		if i > 0 {
+			fmt.Printf(" (after %d)", i)
+		}
+		fmt.Println()
+		if step.Description != "" {
+			fmt.Printf("     %s\n", step.Description)
+		}
+	}
+}
@@ -0,0 +1,47 @@
+package sessions
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	julessessions "github.com/SamyRai/juleson/internal/jules/sessions"
+	"github.com/SamyRai/juleson/internal/templates"
+	"gopkg.in/yaml.v3"
+)
+
+func TestSavePlanTemplateAdaptsRevisedPlans(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "plan.yaml")
+	first := &julessessions.PlanSummary{PlanID: "p1", Steps: []julessessions.PlanStepSummary{{Title: "Inspect"}, {Title: "Fix"}}}
+	if adaptations, err := savePlanTemplate(path, "plan-fix", "Fix it", "", first); err != nil || adaptations != nil {
+		t.Fatalf("savePlanTemplate(first) = %v, %v", adaptations, err)
+	}
+
+	// An edit made to the saved template survives the revision.
+	data, _ := os.ReadFile(path)
+	if err := os.WriteFile(path, []byte(strings.Replace(string(data), "requires_approval: false", "requires_approval: true", 1)), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	revised := &julessessions.PlanSummary{PlanID: "p2", Steps: []julessessions.PlanStepSummary{{Title: "Inspect"}, {Title: "Test"}}}
+	adaptations, err := savePlanTemplate(path, "plan-fix", "Fix it", "p1", revised)
+	if err != nil {
+		t.Fatalf("savePlanTemplate(revised): %v", err)
+	}
+	if len(adaptations) != 2 || adaptations[0].Action != templates.PlanAdaptationRemove || adaptations[1].Action != templates.PlanAdaptationAdd {
+		t.Fatalf("adaptations = %+v", adaptations)
+	}
+
+	data, _ = os.ReadFile(path)
+	var saved templates.Template
+	if err := yaml.Unmarshal(data, &saved); err != nil {
+		t.Fatal(err)
+	}
+	if len(saved.Tasks) != 2 || !saved.Tasks[0].RequiresApproval || saved.Tasks[1].Name != "step_3" {
+		t.Errorf("tasks = %+v", saved.Tasks)
+	}
+	if len(saved.Revisions) != 1 || saved.Revisions[0].Plan != "p2" || saved.Revisions[0].PreviousPlan != "p1" || len(saved.Revisions[0].Adaptations) != 2 {
+		t.Errorf("revisions = %+v", saved.Revisions)
+	}
+}
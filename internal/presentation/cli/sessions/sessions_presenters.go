@@ -3,158 +3,184 @@ package sessions
 import (
 	"context"
 	"fmt"
+	"io"
 	"os"
 	"strings"
 
 	"github.com/SamyRai/go-jules"
 	"github.com/SamyRai/juleson/internal/config"
+	diffview "github.com/SamyRai/juleson/internal/presentation/views/diff"
 	"github.com/SamyRai/juleson/pkg/build"
-	"github.com/alecthomas/chroma/v2/quick"
-	"github.com/bluekeyes/go-gitdiff/gitdiff"
+	"github.com/charmbracelet/x/term"
+	"github.com/mattn/go-isatty"
 )
 
-// previewActivityArtifactsContent displays artifact content based on type.
-func previewActivityArtifactsContent(cfg *config.Config, artifacts []jules.Artifact) error {
+// previewOptions control how previews render diffs.
+type previewOptions struct {
+	SideBySide bool
+	Files      []string
+	NoPager    bool
+	NoColor    bool
+}
+
+// previewer writes a preview. Output streams through the pager as it is
+// produced, so a huge patch is never buffered, except when an external diff
+// tool draws the diffs on the terminal itself.
+type previewer struct {
+	cfg      *config.Config
+	options  previewOptions
+	diffTool string
+	color    bool
+	width    int
+	w        io.Writer
+	pager    io.WriteCloser
+}
+
+func newPreviewer(cfg *config.Config, options previewOptions) (*previewer, error) {
+	p := &previewer{cfg: cfg, options: options, width: diffview.DefaultWidth}
+	terminal := isatty.IsTerminal(os.Stdout.Fd())
+	p.color = terminal && !options.NoColor && os.Getenv("NO_COLOR") == ""
+	if width, _, err := term.GetSize(os.Stdout.Fd()); err == nil && width > 0 {
+		p.width = width
+	}
+	if !cfg.Diff.ForceNative && !options.SideBySide {
+		p.diffTool = externalDiffTool(cfg)
+	}
+	if p.diffTool != "" || options.NoPager {
+		p.w = os.Stdout
+		return p, nil
+	}
+	pager, err := diffview.NewPager(os.Stdout)
+	if err != nil {
+		return nil, fmt.Errorf("failed to start pager: %w", err)
+	}
+	p.pager = pager
+	p.w = pager
+	return p, nil
+}
+
+// externalDiffTool returns the configured diff tool, or difftastic or delta
+// when installed.
+func externalDiffTool(cfg *config.Config) string {
+	if cfg.Diff.Tool != "" {
+		return cfg.Diff.Tool
+	}
+	for _, tool := range []string{"difftastic", "delta"} {
+		if path, err := build.LookPath(tool); err == nil {
+			return path
+		}
+	}
+	return ""
+}
+
+// flush ends the preview and waits for the user to quit the pager. It may
+// be called more than once.
+func (p *previewer) flush() error {
+	if p.pager == nil {
+		return nil
+	}
+	pager := p.pager
+	p.pager = nil
+	return pager.Close()
+}
+
+// artifacts displays artifact content based on type.
+func (p *previewer) artifacts(artifacts []jules.Artifact) error {
 	for i, artifact := range artifacts {
-		fmt.Printf("\n  📄 Artifact %d:\n", i+1)
+		fmt.Fprintf(p.w, "\n  📄 Artifact %d:\n", i+1)
 
 		// Handle different artifact types
 		if artifact.BashOutput != nil {
-			previewBashOutput(artifact.BashOutput)
+			p.bashOutput(artifact.BashOutput)
 		} else if artifact.ChangeSet != nil && artifact.ChangeSet.GitPatch != nil {
-			err := previewGitPatch(cfg, artifact.ChangeSet.GitPatch)
+			err := p.gitPatch(artifact.ChangeSet.GitPatch)
 			if err != nil {
-				fmt.Printf("    ⚠️  Failed to preview git patch: %v\n", err)
+				fmt.Fprintf(p.w, "    ⚠️  Failed to preview git patch: %v\n", err)
 			}
 		} else if artifact.Media != nil {
-			previewMedia(artifact.Media)
+			p.media(artifact.Media)
 		} else {
-			fmt.Printf("    📄 Unknown artifact type\n")
+			fmt.Fprintf(p.w, "    📄 Unknown artifact type\n")
 		}
 	}
 	return nil
 }
 
-// previewBashOutput displays bash command output.
-func previewBashOutput(output *jules.BashOutput) error {
-	fmt.Printf("    🖥️  Bash Output:\n")
-	fmt.Printf("    Command: %s\n", output.Command)
-	fmt.Printf("    Exit Code: %d\n", output.ExitCode)
-
-	// Truncate output if too long
-	content := output.Output
-	if len(content) > 1000 {
-		content = content[:1000] + "\n... (truncated)"
-	}
-
-	fmt.Printf("    Output:\n")
-	fmt.Printf("    ```\n")
-	for _, line := range strings.Split(content, "\n") {
-		fmt.Printf("    %s\n", line)
+// bashOutput displays bash command output.
+func (p *previewer) bashOutput(output *jules.BashOutput) {
+	fmt.Fprintf(p.w, "    🖥️  Bash Output:\n")
+	fmt.Fprintf(p.w, "    Command: %s\n", output.Command)
+	fmt.Fprintf(p.w, "    Exit Code: %d\n", output.ExitCode)
+
+	// The whole output is shown; the pager scrolls through long output.
+	fmt.Fprintf(p.w, "    Output:\n")
+	fmt.Fprintf(p.w, "    ```\n")
+	for line := range strings.Lines(output.Output) {
+		fmt.Fprintf(p.w, "    %s\n", strings.TrimSuffix(line, "\n"))
 	}
-	fmt.Printf("    ```\n")
-	return nil
+	fmt.Fprintf(p.w, "    ```\n")
 }
 
-// previewGitPatch displays git diff content.
-func previewGitPatch(cfg *config.Config, patch *jules.GitPatch) error {
-	fmt.Printf("    🔀 Git Patch:\n")
+// gitPatch displays git diff content.
+func (p *previewer) gitPatch(patch *jules.GitPatch) error {
+	fmt.Fprintf(p.w, "    🔀 Git Patch:\n")
 
 	if patch.SuggestedCommitMessage != "" {
-		fmt.Printf("    Commit Message: %s\n", patch.SuggestedCommitMessage)
+		fmt.Fprintf(p.w, "    Commit Message: %s\n", patch.SuggestedCommitMessage)
 	}
 
 	if patch.BaseCommitID != "" {
-		fmt.Printf("    Base Commit: %s\n", patch.BaseCommitID)
+		fmt.Fprintf(p.w, "    Base Commit: %s\n", patch.BaseCommitID)
 	}
 
 	if patch.UnidiffPatch == "" {
-		fmt.Printf("    No diff content.\n")
+		fmt.Fprintf(p.w, "    No diff content.\n")
 		return nil
 	}
 
-	if !cfg.Diff.ForceNative {
-		var diffTool string
-		if cfg.Diff.Tool != "" {
-			diffTool = cfg.Diff.Tool
-		} else {
-			// Look for common diff pagers
-			if path, err := build.LookPath("difftastic"); err == nil {
-				diffTool = path
-			} else if path, err := build.LookPath("delta"); err == nil {
-				diffTool = path
-			}
-		}
-
-		if diffTool != "" {
-			err := build.RunDiffTool(context.Background(), diffTool, patch.UnidiffPatch)
-			if err != nil {
-				fmt.Printf("    ⚠️  Diff tool exited with error: %v\n", err)
-			}
-			return nil
-		}
-	}
-
-	// Fallback to native text diff
-	fmt.Printf("    Diff:\n")
-
-	// Parse the patch using go-gitdiff
-	files, _, err := gitdiff.Parse(strings.NewReader(patch.UnidiffPatch))
-	if err == nil && len(files) > 0 {
-		var b strings.Builder
-		for _, file := range files {
-			fmt.Fprintf(&b, "diff --git a/%s b/%s\n", file.OldName, file.NewName)
-			for _, fragment := range file.TextFragments {
-				b.WriteString(fragment.Header())
-				for _, line := range fragment.Lines {
-					switch line.Op {
-					case gitdiff.OpAdd:
-						b.WriteString("+")
-					case gitdiff.OpDelete:
-						b.WriteString("-")
-					case gitdiff.OpContext:
-						b.WriteString(" ")
-					}
-					b.WriteString(line.Line)
-				}
-			}
-		}
-
-		err = quick.Highlight(os.Stdout, b.String(), "diff", "terminal256", "monokai")
+	if p.diffTool != "" {
+		filtered, err := diffview.Filter(patch.UnidiffPatch, p.options.Files)
 		if err != nil {
-			// Fallback if highlight fails
-			fmt.Println(b.String())
+			return err
+		}
+		if err := build.RunDiffTool(context.Background(), p.diffTool, filtered); err != nil {
+			fmt.Fprintf(p.w, "    ⚠️  Diff tool exited with error: %v\n", err)
 		}
 		return nil
 	}
 
-	// Fallback if parsing fails
-	fmt.Printf("    ```diff\n")
-	// Split into lines and add proper indentation
-	lines := strings.Split(patch.UnidiffPatch, "\n")
-	for _, line := range lines {
-		if len(line) > 120 { // Truncate very long lines
-			line = line[:120] + "..."
-		}
-		fmt.Printf("    %s\n", line)
+	layout := diffview.LayoutUnified
+	if p.options.SideBySide {
+		layout = diffview.LayoutSideBySide
+	}
+	fmt.Fprintln(p.w)
+	// Files are parsed as they are rendered; ones that cannot be parsed
+	// are shown as they are.
+	shown, err := diffview.RenderReader(p.w, strings.NewReader(patch.UnidiffPatch), diffview.Options{
+		Layout: layout,
+		Color:  p.color,
+		Width:  p.width,
+		Files:  p.options.Files,
+	})
+	if err != nil {
+		return err
+	}
+	if shown == 0 && len(p.options.Files) > 0 {
+		fmt.Fprintf(p.w, "    No changed files match %s\n", strings.Join(p.options.Files, ", "))
 	}
-	fmt.Printf("    ```\n")
-
 	return nil
 }
 
-// previewMedia displays media artifact information.
-func previewMedia(media *jules.Media) error {
-	fmt.Printf("    🖼️  Media:\n")
-	fmt.Printf("    Type: %s\n", media.MimeType)
-	fmt.Printf("    Size: %d bytes\n", len(media.Data))
+// media displays media artifact information.
+func (p *previewer) media(media *jules.Media) {
+	fmt.Fprintf(p.w, "    🖼️  Media:\n")
+	fmt.Fprintf(p.w, "    Type: %s\n", media.MimeType)
+	fmt.Fprintf(p.w, "    Size: %d bytes\n", len(media.Data))
 
 	// Don't display binary data, just metadata
 	if strings.Contains(media.MimeType, "image/") {
-		fmt.Printf("    📷 Image data (base64 encoded)\n")
+		fmt.Fprintf(p.w, "    📷 Image data (base64 encoded)\n")
 	} else {
-		fmt.Printf("    📄 Binary data\n")
+		fmt.Fprintf(p.w, "    📄 Binary data\n")
 	}
-
-	return nil
 }
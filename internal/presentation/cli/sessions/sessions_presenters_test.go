@@ -2,8 +2,6 @@ package sessions
 
 import (
 	"bytes"
-	"io"
-	"os"
 	"strings"
 	"testing"
 
@@ -72,7 +70,7 @@ func TestCLIWakePolicyUsesConfiguredDefault(t *testing.T) {
 	}
 }
 
-func TestPreviewGitPatch_Fallback(t *testing.T) {
+func TestPreviewGitPatch_Native(t *testing.T) {
 	cfg := &config.Config{
 		Diff: config.DiffConfig{
 			ForceNative: true, // Bypass external pagers
@@ -82,26 +80,42 @@ func TestPreviewGitPatch_Fallback(t *testing.T) {
 		UnidiffPatch: "--- a/test.txt\n+++ b/test.txt\n@@ -1 +1 @@\n-old\n+new\n",
 	}
 
-	// Capture stdout
-	old := os.Stdout
-	r, w, _ := os.Pipe()
-	os.Stdout = w
-
-	err := previewGitPatch(cfg, patch)
-
-	w.Close()
-	os.Stdout = old
 	var buf bytes.Buffer
-	io.Copy(&buf, r)
-
+	p, err := newPreviewer(cfg, previewOptions{})
 	if err != nil {
-		t.Fatalf("previewGitPatch failed: %v", err)
+		t.Fatal(err)
+	}
+	p.w = &buf
+	p.color = false
+
+	if err := p.gitPatch(patch); err != nil {
+		t.Fatalf("gitPatch failed: %v", err)
 	}
 	output := buf.String()
 	if !strings.Contains(output, "Git Patch") {
 		t.Errorf("Expected 'Git Patch' in output, got: %s", output)
 	}
-	if !strings.Contains(output, "+new") {
-		t.Errorf("Expected diff content '+new' in output")
+	if !strings.Contains(output, "+ new") || !strings.Contains(output, "▶ [1/1] test.txt +1 -1") {
+		t.Errorf("Expected rendered diff in output, got: %s", output)
+	}
+
+	buf.Reset()
+	p.options.Files = []string{"*.go"}
+	if err := p.gitPatch(patch); err != nil {
+		t.Fatalf("gitPatch failed: %v", err)
+	}
+	if !strings.Contains(buf.String(), "No changed files match *.go") {
+		t.Errorf("Expected filter message, got: %s", buf.String())
+	}
+}
+
+func TestPreviewBashOutputIsNotTruncated(t *testing.T) {
+	var buf bytes.Buffer
+	p := &previewer{w: &buf}
+	p.bashOutput(&jules.BashOutput{Command: "go test ./...", Output: strings.Repeat("ok\n", 1000) + "PASS"})
+
+	output := buf.String()
+	if strings.Contains(output, "truncated") || strings.Count(output, "    ok\n") != 1000 || !strings.Contains(output, "    PASS\n") {
+		t.Errorf("bash output was not shown in full:\n%s", output)
 	}
 }
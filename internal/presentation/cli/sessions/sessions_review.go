@@ -11,6 +11,7 @@ import (
 
 	"github.com/SamyRai/juleson/internal/config"
 	julessessions "github.com/SamyRai/juleson/internal/jules/sessions"
+	"github.com/SamyRai/juleson/pkg/sarif"
 )
 
 type ReviewSessionOptions struct {
@@ -18,6 +19,7 @@ type ReviewSessionOptions struct {
 	ArtifactIndex    int
 	HasArtifactIndex bool
 	JSON             bool
+	SARIF            bool
 }
 
 func showSessionPlans(cfg *config.Config, sessionID string, latestOnly, jsonOutput bool) error {
@@ -60,6 +62,11 @@ func reviewSession(cfg *config.Config, sessionID, projectPath string, options Re
 	if options.JSON {
 		return printJSON(review)
 	}
+	if options.SARIF {
+		builder := sarif.NewBuilder("juleson", core.Version, "https://github.com/SamyRai/juleson")
+		review.AddSARIFResults(builder)
+		return builder.Log().Write(os.Stdout)
+	}
 	printSessionReview(review)
 	return nil
 }
@@ -90,6 +97,9 @@ func printPlanSummaries(sessionID string, plans []julessessions.PlanSummary) {
 		if plan.ApprovalActivityID != "" {
 			fmt.Printf("   Approval Activity ID: %s\n", plan.ApprovalActivityID)
 		}
+		if plan.Changes != nil {
+			printPlanChanges("   ", *plan.Changes)
+		}
 		fmt.Printf("   Steps (%d):\n", len(plan.Steps))
 		for stepIndex, step := range plan.Steps {
 			fmt.Printf("     %d. %s\n", stepIndex+1, step.Title)
@@ -118,6 +128,7 @@ func printSessionReview(review *julessessions.SessionReview) {
 	printReviewOutputs(review)
 	printReviewArtifacts(review)
 	printReviewPatchPreview(review)
+	printReviewImpact(review)
 	printReviewWorktree(review)
 
 	printStringList("Warnings", review.Warnings)
@@ -168,16 +179,32 @@ func printReviewArtifacts(review *julessessions.SessionReview) {
 func printReviewPatchPreview(review *julessessions.SessionReview) {
 	fmt.Printf("\nPatch preview: %s\n", review.PatchPreview.Summary)
 	for _, file := range review.PatchPreview.Files {
-		fmt.Printf("  %s (+%d -%d)\n", file.Path, file.LinesAdded, file.LinesRemoved)
+		fmt.Printf("  %s (+%d -%d)\n", file.Label(), file.LinesAdded, file.LinesRemoved)
 	}
 	for _, message := range review.PatchPreview.SuggestedCommitMessages {
 		fmt.Printf("  Suggested commit: %s\n", message)
 	}
+	for _, finding := range review.PatchPreview.SecretFindings {
+		fmt.Printf("  Possible secret: %s:%d %s (%s)\n", finding.File, finding.Line, finding.Description, finding.Match)
+	}
+	for _, finding := range review.PatchPreview.GuardFindings {
+		fmt.Printf("  Dangerous instruction: %s\n", finding)
+	}
 	if review.PatchPreview.Error != "" {
 		fmt.Printf("  Preview error: %s\n", review.PatchPreview.Error)
 	}
 }
 
+func printReviewImpact(review *julessessions.SessionReview) {
+	if review.Impact == nil {
+		return
+	}
+	fmt.Printf("\nImpact: %d changed, %d affected, %d test target(s)\n", len(review.Impact.Changed), len(review.Impact.Affected), len(review.Impact.TestTargets))
+	for _, id := range review.Impact.Affected {
+		fmt.Printf("  %s\n", id)
+	}
+}
+
 func printReviewWorktree(review *julessessions.SessionReview) {
 	fmt.Printf("\nWorktree: %s\n", review.Worktree.WorkingDir)
 	switch {
@@ -0,0 +1,65 @@
+package sessions
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+
+	"github.com/SamyRai/juleson/internal/config"
+	"github.com/SamyRai/juleson/internal/events"
+	"github.com/SamyRai/juleson/internal/presentation/cli/core"
+)
+
+func showSessionTimeline(w io.Writer, cfg *config.Config, sessionID string, files []string, jsonOutput bool) error {
+	if len(files) == 0 {
+		path, err := core.AuditLogPath(cfg)
+		if err != nil {
+			return err
+		}
+		files = []string{path}
+	}
+
+	timeline, err := loadSessionTimeline(context.Background(), cfg, sessionID, files)
+	if err != nil {
+		return err
+	}
+	if jsonOutput {
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(map[string]interface{}{"session_id": sessionID, "timeline": timeline})
+	}
+
+	if len(timeline) == 0 {
+		fmt.Fprintf(w, "No recorded events for session %s.\n", sessionID)
+		return nil
+	}
+	fmt.Fprintf(w, "🕒 Timeline for session %s\n\n", sessionID)
+	for _, entry := range timeline {
+		fmt.Fprintf(w, "%s  %-20s  %s\n", entry.Time.Local().Format("2006-01-02 15:04:05"), entry.Type, entry.Summary)
+	}
+	return nil
+}
+
+// loadSessionTimeline rebuilds the session timelines projection of each
+// journal, resuming from the snapshots kept beside it, and merges the
+// session's entries by time.
+func loadSessionTimeline(ctx context.Context, cfg *config.Config, sessionID string, files []string) ([]events.TimelineEntry, error) {
+	var timeline []events.TimelineEntry
+	for _, file := range files {
+		store, err := core.OpenEventJournal(cfg, file)
+		if err != nil {
+			return nil, err
+		}
+		projection := events.NewSessionTimelinesProjection()
+		if err := events.NewProjector(store, file+".projections", projection).Rebuild(ctx); err != nil {
+			return nil, fmt.Errorf("failed to rebuild timeline from %s: %w", file, err)
+		}
+		timeline = append(timeline, projection.State()[sessionID]...)
+	}
+	sort.SliceStable(timeline, func(i, j int) bool {
+		return timeline[i].Time.Before(timeline[j].Time)
+	})
+	return timeline, nil
+}
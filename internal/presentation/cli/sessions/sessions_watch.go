@@ -11,6 +11,7 @@ import (
 
 	"github.com/SamyRai/go-jules"
 	"github.com/SamyRai/juleson/internal/config"
+	"github.com/SamyRai/juleson/internal/events"
 	julessessions "github.com/SamyRai/juleson/internal/jules/sessions"
 	"github.com/SamyRai/juleson/internal/presentation/views"
 )
@@ -58,6 +59,12 @@ func watchSession(cfg *config.Config, sessionID, intervalValue, timeoutValue str
 
 	ticker := time.NewTicker(interval)
 	defer ticker.Stop()
+	exporter := core.NewEventExporter(cfg)
+	defer func() { _ = exporter.Close(context.WithoutCancel(ctx)) }()
+	alertEngine := core.NewAlertEngine(cfg, func(ctx context.Context, event events.Event) {
+		core.Notify(cfg, event)
+		exporter.Export(event)
+	})
 
 	seenActivities := map[string]bool{}
 	for {
@@ -74,6 +81,12 @@ func watchSession(cfg *config.Config, sessionID, intervalValue, timeoutValue str
 			hasStateBaseline = true
 		} else if update.State != baselineState {
 			stateChanged = true
+			for _, event := range julessessions.StateEvents(update.Session) {
+				core.Notify(cfg, event)
+				core.MirrorEvent(cfg, event)
+				exporter.Export(event)
+				alertEngine.Observe(ctx, event)
+			}
 		}
 		wake := julessessions.EvaluateWatchWake(wakePolicy, update.UpdateType, stateChanged)
 		if wakeOnAgentMessage {
@@ -124,6 +137,7 @@ type sessionWatchUpdate struct {
 	NextAction           string
 	NextCursor           time.Time
 	State                jules.SessionState
+	Session              *jules.Session
 	HasJulesAgentMessage bool
 }
 
@@ -149,6 +163,7 @@ func printSessionWatchUpdate(ctx context.Context, client *jules.Client, sessionI
 		NextAction:           cliNextAction(snapshot, sessionID, statusText),
 		NextCursor:           snapshot.NextCursor,
 		State:                session.State,
+		Session:              session,
 		HasJulesAgentMessage: snapshot.HasJulesAgentMessage,
 	}
 
@@ -0,0 +1,179 @@
+// Package review is an interactive queue of items waiting for a human
+// decision, navigated with the keyboard.
+package review
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// Kind is what an item waits for.
+type Kind string
+
+const (
+	// KindPlan is a Jules session waiting for its plan to be approved.
+	KindPlan Kind = "plan"
+	// KindFeedback is a Jules session waiting for an answer from the user.
+	KindFeedback Kind = "feedback"
+	// KindApproval is a policy-gated operation waiting for a second approver.
+	KindApproval Kind = "approval"
+)
+
+// Item is one decision waiting in the queue.
+type Item struct {
+	Kind    Kind      `json:"kind"`
+	ID      string    `json:"id"`
+	Title   string    `json:"title"`
+	Detail  string    `json:"detail,omitempty"`
+	URL     string    `json:"url,omitempty"`
+	Created time.Time `json:"created"`
+}
+
+// DecideFunc approves or rejects an item.
+type DecideFunc func(item Item, approve bool) error
+
+type decidedMsg struct {
+	item    Item
+	approve bool
+	err     error
+}
+
+// Model is the bubbletea model of the queue. Up and down (or k and j) move
+// the selection, a approves, r rejects, and q quits.
+type Model struct {
+	items    []Item
+	cursor   int
+	decide   DecideFunc
+	busy     bool
+	status   string
+	decided  int
+	quitting bool
+}
+
+// NewModel returns a queue of items decided with decide.
+func NewModel(items []Item, decide DecideFunc) Model {
+	return Model{items: items, decide: decide}
+}
+
+// Decided returns how many items were approved or rejected.
+func (m Model) Decided() int {
+	return m.decided
+}
+
+// Init implements tea.Model.
+func (m Model) Init() tea.Cmd {
+	return nil
+}
+
+// Update implements tea.Model.
+func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case decidedMsg:
+		m.busy = false
+		if msg.err != nil {
+			m.status = fmt.Sprintf("❌ %s: %v", msg.item.ID, msg.err)
+			return m, nil
+		}
+		m.decided++
+		verb := "Approved"
+		if !msg.approve {
+			verb = "Rejected"
+		}
+		m.status = fmt.Sprintf("✅ %s %s", verb, msg.item.ID)
+		m.remove(msg.item)
+		if len(m.items) == 0 {
+			m.quitting = true
+			return m, tea.Quit
+		}
+		return m, nil
+
+	case tea.KeyMsg:
+		switch msg.String() {
+		case "ctrl+c", "q", "esc":
+			m.quitting = true
+			return m, tea.Quit
+		}
+		if m.busy || len(m.items) == 0 {
+			return m, nil
+		}
+		switch msg.String() {
+		case "up", "k":
+			if m.cursor > 0 {
+				m.cursor--
+			}
+		case "down", "j":
+			if m.cursor < len(m.items)-1 {
+				m.cursor++
+			}
+		case "a", "y":
+			return m.start(true)
+		case "r", "n":
+			return m.start(false)
+		}
+	}
+	return m, nil
+}
+
+func (m Model) start(approve bool) (tea.Model, tea.Cmd) {
+	item := m.items[m.cursor]
+	m.busy = true
+	m.status = fmt.Sprintf("⏳ %s...", item.ID)
+	decide := m.decide
+	return m, func() tea.Msg {
+		return decidedMsg{item: item, approve: approve, err: decide(item, approve)}
+	}
+}
+
+func (m *Model) remove(item Item) {
+	for i, queued := range m.items {
+		if queued.Kind == item.Kind && queued.ID == item.ID {
+			m.items = append(m.items[:i], m.items[i+1:]...)
+			break
+		}
+	}
+	if m.cursor >= len(m.items) && m.cursor > 0 {
+		m.cursor = len(m.items) - 1
+	}
+}
+
+// View implements tea.Model.
+func (m Model) View() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Review queue (%d waiting)\n\n", len(m.items))
+	for i, item := range m.items {
+		cursor := "  "
+		if i == m.cursor {
+			cursor = "> "
+		}
+		fmt.Fprintf(&b, "%s%-9s %s  %s\n", cursor, item.Kind, item.ID, item.Title)
+	}
+	if m.cursor < len(m.items) {
+		selected := m.items[m.cursor]
+		if selected.Detail != "" {
+			fmt.Fprintf(&b, "\n%s\n", selected.Detail)
+		}
+		if selected.URL != "" {
+			fmt.Fprintf(&b, "%s\n", selected.URL)
+		}
+	}
+	if m.status != "" {
+		fmt.Fprintf(&b, "\n%s\n", m.status)
+	}
+	if !m.quitting {
+		b.WriteString("\n↑/k up • ↓/j down • a approve • r reject • q quit\n")
+	}
+	return b.String()
+}
+
+// Run shows the queue until every item is decided or the user quits, and
+// returns how many items were decided.
+func Run(items []Item, decide DecideFunc) (int, error) {
+	final, err := tea.NewProgram(NewModel(items, decide)).Run()
+	if err != nil {
+		return 0, err
+	}
+	return final.(Model).Decided(), nil
+}
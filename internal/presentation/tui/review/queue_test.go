@@ -0,0 +1,74 @@
+package review
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func key(r rune) tea.KeyMsg {
+	return tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{r}}
+}
+
+func testItems() []Item {
+	return []Item{
+		{Kind: KindPlan, ID: "s1", Title: "Add tests"},
+		{Kind: KindApproval, ID: "a1", Title: "delete_session on s2"},
+	}
+}
+
+func TestModelNavigation(t *testing.T) {
+	var m tea.Model = NewModel(testItems(), nil)
+	m, _ = m.Update(key('j'))
+	m, _ = m.Update(key('j'))
+	if got := m.(Model).cursor; got != 1 {
+		t.Errorf("cursor after j j = %d, want 1", got)
+	}
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyUp})
+	if got := m.(Model).cursor; got != 0 {
+		t.Errorf("cursor after up = %d, want 0", got)
+	}
+	if !strings.Contains(m.View(), "> plan      s1") {
+		t.Errorf("view does not mark the selection:\n%s", m.View())
+	}
+}
+
+func TestModelDecide(t *testing.T) {
+	var decided []string
+	decide := func(item Item, approve bool) error {
+		if item.ID == "a1" {
+			return errors.New("not allowed")
+		}
+		decided = append(decided, item.ID)
+		return nil
+	}
+
+	var m tea.Model = NewModel(testItems(), decide)
+	m, cmd := m.Update(key('a'))
+	if cmd == nil {
+		t.Fatal("approve should return a command")
+	}
+	m, _ = m.Update(cmd())
+	model := m.(Model)
+	if len(model.items) != 1 || model.Decided() != 1 || len(decided) != 1 {
+		t.Fatalf("after approve: items = %d, decided = %d", len(model.items), model.Decided())
+	}
+
+	m, cmd = m.Update(key('r'))
+	m, _ = m.Update(cmd())
+	model = m.(Model)
+	if len(model.items) != 1 || !strings.Contains(model.View(), "not allowed") {
+		t.Errorf("failed decision should keep the item and show the error:\n%s", model.View())
+	}
+}
+
+func TestModelQuitsWhenEmpty(t *testing.T) {
+	var m tea.Model = NewModel(testItems()[:1], func(Item, bool) error { return nil })
+	m, cmd := m.Update(key('r'))
+	_, cmd = m.Update(cmd())
+	if cmd == nil {
+		t.Error("deciding the last item should quit")
+	}
+}
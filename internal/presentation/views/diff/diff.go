@@ -0,0 +1,348 @@
+// Package diff renders unified patches for the terminal: unified or side by
+// side, with colors, syntax highlighting of context lines, and word-level
+// highlights of changed lines.
+package diff
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/SamyRai/juleson/internal/jules/workspace"
+	"github.com/bluekeyes/go-gitdiff/gitdiff"
+)
+
+// Layout is how changes are laid out.
+type Layout string
+
+const (
+	// LayoutUnified shows removed lines above the added lines that replace
+	// them.
+	LayoutUnified Layout = "unified"
+	// LayoutSideBySide shows the old file on the left and the new on the
+	// right.
+	LayoutSideBySide Layout = "side-by-side"
+)
+
+// FileMarker starts every file header so a pager search can jump between
+// files.
+const FileMarker = "▶"
+
+// DefaultWidth is the side-by-side width when the terminal width is unknown.
+const DefaultWidth = 160
+
+const tabWidth = 4
+
+// Options control rendering.
+type Options struct {
+	Layout Layout
+	// Color enables ANSI colors and syntax highlighting.
+	Color bool
+	// Width is the total side-by-side width; zero means DefaultWidth.
+	Width int
+	// Files keeps only files matching one of these globs, as in
+	// workspace.MatchPath. Empty keeps every file.
+	Files []string
+}
+
+// Parse parses a unified patch and keeps the files matching patterns.
+func Parse(patch string, patterns []string) ([]*gitdiff.File, error) {
+	files, _, err := gitdiff.Parse(strings.NewReader(patch))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse patch: %w", err)
+	}
+	if !strings.HasPrefix(patch, "diff --git ") && !strings.Contains(patch, "\ndiff --git ") {
+		for _, file := range files {
+			trimPrefixes(file)
+		}
+	}
+	if len(patterns) == 0 {
+		return files, nil
+	}
+	var matched []*gitdiff.File
+	for _, file := range files {
+		if workspace.MatchPath(patterns, fileName(file)) {
+			matched = append(matched, file)
+		}
+	}
+	return matched, nil
+}
+
+// Filter returns the part of patch that changes files matching patterns, for
+// external diff tools. Files are parsed one at a time.
+func Filter(patch string, patterns []string) (string, error) {
+	if len(patterns) == 0 {
+		return patch, nil
+	}
+	var b strings.Builder
+	for section, err := range Sections(strings.NewReader(patch)) {
+		if err != nil {
+			return "", err
+		}
+		files, err := Parse(section, patterns)
+		if err != nil {
+			return "", err
+		}
+		if len(files) > 0 {
+			b.WriteString(section)
+		}
+	}
+	return b.String(), nil
+}
+
+// Render writes the patch and returns how many files it showed.
+func Render(w io.Writer, patch string, opts Options) (int, error) {
+	return RenderReader(w, strings.NewReader(patch), opts)
+}
+
+// RenderReader writes the patch read from r and returns how many files it
+// showed. Files are parsed and rendered one at a time, so a huge patch is
+// never held in memory parsed; r is read twice, first to count the files.
+// A file that cannot be parsed is shown as it is.
+func RenderReader(w io.Writer, r io.ReadSeeker, opts Options) (int, error) {
+	total := 0
+	for section, err := range Sections(r) {
+		if err != nil {
+			return 0, err
+		}
+		files, _ := Parse(section, opts.Files)
+		total += len(files)
+	}
+	if _, err := r.Seek(0, io.SeekStart); err != nil {
+		return 0, err
+	}
+
+	if opts.Width <= 0 {
+		opts.Width = DefaultWidth
+	}
+	renderer := &renderer{w: w, opts: opts}
+	shown := 0
+	for section, err := range Sections(r) {
+		if err != nil {
+			return shown, err
+		}
+		files, err := Parse(section, opts.Files)
+		if err != nil {
+			renderer.raw(section, err)
+			continue
+		}
+		for _, file := range files {
+			shown++
+			renderer.file(file, shown, total)
+		}
+	}
+	return shown, nil
+}
+
+type renderer struct {
+	w    io.Writer
+	opts Options
+	// syntax highlights context lines of the current file, when colored.
+	syntax *highlighter
+}
+
+func (r *renderer) file(file *gitdiff.File, index, total int) {
+	var added, removed int64
+	for _, fragment := range file.TextFragments {
+		added += fragment.LinesAdded
+		removed += fragment.LinesDeleted
+	}
+	name := fileName(file)
+	label := name
+	switch {
+	case file.IsRename:
+		label = fmt.Sprintf("%s → %s", file.OldName, file.NewName)
+	case file.IsNew:
+		label += " (new)"
+	case file.IsDelete:
+		label += " (deleted)"
+	}
+	if index > 1 {
+		fmt.Fprintln(r.w)
+	}
+	fmt.Fprintf(r.w, "%s %s %s %s\n", FileMarker,
+		r.paint(styleDim, fmt.Sprintf("[%d/%d]", index, total)),
+		r.paint(styleHeader, label),
+		r.paint(styleAdded, fmt.Sprintf("+%d", added))+" "+r.paint(styleRemoved, fmt.Sprintf("-%d", removed)))
+
+	if file.IsBinary {
+		fmt.Fprintln(r.w, r.paint(styleDim, "  binary file"))
+		return
+	}
+	r.syntax = nil
+	if r.opts.Color {
+		r.syntax = newHighlighter(name)
+	}
+	for _, fragment := range file.TextFragments {
+		r.fragment(fragment)
+	}
+}
+
+// raw shows a part of the patch that could not be parsed as it is.
+func (r *renderer) raw(section string, err error) {
+	fmt.Fprintln(r.w, r.paint(styleDim, fmt.Sprintf("  showing unparsed patch text: %v", err)))
+	for _, line := range strings.SplitAfter(section, "\n") {
+		if line != "" {
+			fmt.Fprintf(r.w, "  %s\n", cleanLine(line))
+		}
+	}
+}
+
+func (r *renderer) fragment(fragment *gitdiff.TextFragment) {
+	header := fmt.Sprintf("@@ -%d,%d +%d,%d @@", fragment.OldPosition, fragment.OldLines, fragment.NewPosition, fragment.NewLines)
+	if fragment.Comment != "" {
+		header += " " + fragment.Comment
+	}
+	fmt.Fprintln(r.w, r.paint(styleHunk, header))
+
+	oldLine, newLine := fragment.OldPosition, fragment.NewPosition
+	lines := fragment.Lines
+	for i := 0; i < len(lines); {
+		if lines[i].Op == gitdiff.OpContext {
+			r.context(oldLine, newLine, lines[i].Line)
+			oldLine++
+			newLine++
+			i++
+			continue
+		}
+		// A change block is removed lines followed by added lines.
+		var removed, added []string
+		for ; i < len(lines) && lines[i].Op == gitdiff.OpDelete; i++ {
+			removed = append(removed, lines[i].Line)
+		}
+		for ; i < len(lines) && lines[i].Op == gitdiff.OpAdd; i++ {
+			added = append(added, lines[i].Line)
+		}
+		r.change(oldLine, newLine, removed, added)
+		oldLine += int64(len(removed))
+		newLine += int64(len(added))
+	}
+}
+
+func (r *renderer) context(oldLine, newLine int64, text string) {
+	text = cleanLine(text)
+	if r.opts.Layout == LayoutSideBySide {
+		width := r.columnWidth()
+		cell := truncate(text, width)
+		left := r.lineNumber(oldLine) + " " + r.highlight(cell) + strings.Repeat(" ", width-runeLen(cell))
+		fmt.Fprintf(r.w, "%s %s %s %s\n", left, r.paint(styleDim, "│"), r.lineNumber(newLine), r.highlight(cell))
+		return
+	}
+	fmt.Fprintf(r.w, "%s %s   %s\n", r.lineNumber(oldLine), r.lineNumber(newLine), r.highlight(text))
+}
+
+// change renders a change block. Removed and added lines are paired in order,
+// and each pair highlights the words that differ.
+func (r *renderer) change(oldLine, newLine int64, removed, added []string) {
+	pairs := max(len(removed), len(added))
+	oldSegments := make([][]segment, len(removed))
+	newSegments := make([][]segment, len(added))
+	for i := range pairs {
+		switch {
+		case i < len(removed) && i < len(added):
+			oldSegments[i], newSegments[i] = wordDiff(cleanLine(removed[i]), cleanLine(added[i]))
+		case i < len(removed):
+			oldSegments[i] = []segment{{text: cleanLine(removed[i])}}
+		default:
+			newSegments[i] = []segment{{text: cleanLine(added[i])}}
+		}
+	}
+
+	if r.opts.Layout == LayoutSideBySide {
+		width := r.columnWidth()
+		blank := strings.Repeat(" ", 6+width)
+		for i := range pairs {
+			left, right := blank, ""
+			if i < len(removed) {
+				segments := truncateSegments(oldSegments[i], width)
+				left = r.lineNumber(oldLine+int64(i)) + " " + r.segments(segments, styleRemoved, styleRemovedWord) + strings.Repeat(" ", width-segmentsLen(segments))
+			}
+			if i < len(added) {
+				right = r.lineNumber(newLine+int64(i)) + " " + r.segments(truncateSegments(newSegments[i], width), styleAdded, styleAddedWord)
+			}
+			fmt.Fprintf(r.w, "%s %s %s\n", left, r.paint(styleDim, "│"), right)
+		}
+		return
+	}
+
+	blank := strings.Repeat(" ", 5)
+	for i, segments := range oldSegments {
+		fmt.Fprintf(r.w, "%s %s %s %s\n", r.lineNumber(oldLine+int64(i)), blank, r.paint(styleRemoved, "-"), r.segments(segments, styleRemoved, styleRemovedWord))
+	}
+	for i, segments := range newSegments {
+		fmt.Fprintf(r.w, "%s %s %s %s\n", blank, r.lineNumber(newLine+int64(i)), r.paint(styleAdded, "+"), r.segments(segments, styleAdded, styleAddedWord))
+	}
+}
+
+func (r *renderer) columnWidth() int {
+	// Each side has a five-digit line number and a space; a separator sits
+	// between them.
+	return max((r.opts.Width-3)/2-6, 10)
+}
+
+func (r *renderer) lineNumber(n int64) string {
+	return r.paint(styleDim, fmt.Sprintf("%5d", n))
+}
+
+func (r *renderer) segments(segments []segment, line, word string) string {
+	var b strings.Builder
+	for _, s := range segments {
+		if s.changed {
+			b.WriteString(r.paint(word, s.text))
+		} else {
+			b.WriteString(r.paint(line, s.text))
+		}
+	}
+	return b.String()
+}
+
+func (r *renderer) highlight(text string) string {
+	if r.syntax == nil {
+		return text
+	}
+	return r.syntax.line(text)
+}
+
+func (r *renderer) paint(style, text string) string {
+	if !r.opts.Color || text == "" {
+		return text
+	}
+	return style + text + styleReset
+}
+
+func fileName(file *gitdiff.File) string {
+	if file.IsDelete {
+		return file.OldName
+	}
+	return file.NewName
+}
+
+// trimPrefixes drops the a/ or b/ prefix that patches without a git header
+// keep in their file names.
+func trimPrefixes(file *gitdiff.File) {
+	for _, name := range []*string{&file.OldName, &file.NewName} {
+		if trimmed, ok := strings.CutPrefix(*name, "a/"); ok {
+			*name = trimmed
+		} else if trimmed, ok := strings.CutPrefix(*name, "b/"); ok {
+			*name = trimmed
+		}
+	}
+}
+
+// cleanLine drops the line ending and expands tabs so columns line up.
+func cleanLine(line string) string {
+	line = strings.TrimRight(line, "\r\n")
+	return strings.ReplaceAll(line, "\t", strings.Repeat(" ", tabWidth))
+}
+
+func runeLen(text string) int {
+	return len([]rune(text))
+}
+
+func truncate(text string, width int) string {
+	runes := []rune(text)
+	if len(runes) <= width {
+		return text
+	}
+	return string(runes[:width-1]) + "…"
+}
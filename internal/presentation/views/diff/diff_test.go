@@ -0,0 +1,154 @@
+package diff
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+const testPatch = `diff --git a/main.go b/main.go
+--- a/main.go
++++ b/main.go
+@@ -1,3 +1,3 @@
+ package main
+-var greeting = "hello"
++var greeting = "hello, world"
+ func main() {}
+diff --git a/docs/README.md b/docs/README.md
+--- a/docs/README.md
++++ b/docs/README.md
+@@ -1 +1,2 @@
+ # Title
++More text
+`
+
+func TestRenderUnified(t *testing.T) {
+	var out bytes.Buffer
+	n, err := Render(&out, testPatch, Options{Layout: LayoutUnified})
+	if err != nil || n != 2 {
+		t.Fatalf("Render() = %d, %v", n, err)
+	}
+	for _, want := range []string{
+		"▶ [1/2] main.go +1 -1",
+		"▶ [2/2] docs/README.md +1 -0",
+		`    2       - var greeting = "hello"`,
+		`          2 + var greeting = "hello, world"`,
+		"@@ -1,3 +1,3 @@",
+	} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("output missing %q:\n%s", want, out.String())
+		}
+	}
+	if strings.Contains(out.String(), "\x1b[") {
+		t.Error("uncolored output contains escape sequences")
+	}
+}
+
+func TestRenderSideBySide(t *testing.T) {
+	var out bytes.Buffer
+	if _, err := Render(&out, testPatch, Options{Layout: LayoutSideBySide, Width: 80, Files: []string{"*.go"}}); err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(out.String(), "README") {
+		t.Errorf("--file filter kept README.md:\n%s", out.String())
+	}
+	var changed string
+	for _, line := range strings.Split(out.String(), "\n") {
+		if strings.Contains(line, "hello, world") {
+			changed = line
+		}
+	}
+	left, right, ok := strings.Cut(changed, "│")
+	if !ok || !strings.Contains(left, `"hello"`) || !strings.Contains(right, `"hello, world"`) {
+		t.Errorf("changed row = %q", changed)
+	}
+}
+
+func TestRenderColorHighlightsWords(t *testing.T) {
+	var out bytes.Buffer
+	if _, err := Render(&out, testPatch, Options{Color: true}); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(out.String(), styleAddedWord+", world") {
+		t.Errorf("changed words are not highlighted:\n%q", out.String())
+	}
+}
+
+func TestWordDiff(t *testing.T) {
+	oldSegments, newSegments := wordDiff("return a + b", "return a - b")
+	if len(oldSegments) != 3 || !oldSegments[1].changed || oldSegments[1].text != "+" {
+		t.Errorf("old segments = %+v", oldSegments)
+	}
+	if len(newSegments) != 3 || newSegments[1].text != "-" || newSegments[0].text != "return a " {
+		t.Errorf("new segments = %+v", newSegments)
+	}
+}
+
+func TestFilter(t *testing.T) {
+	filtered, err := Filter(testPatch, []string{"docs/"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(filtered, "main.go") || !strings.Contains(filtered, "+More text") {
+		t.Errorf("Filter() =\n%s", filtered)
+	}
+}
+
+func TestSections(t *testing.T) {
+	// The removed line "-- b" looks like a traditional file header, and
+	// the git file has no hunks.
+	patch := "commit message\n" +
+		"--- a/one.txt\n+++ b/one.txt\n@@ -1,2 +1 @@\n--- b\n a\n" +
+		"--- a/two.txt\n+++ b/two.txt\n@@ -1 +1 @@\n-x\n+y\n" +
+		"diff --git a/old.bin b/new.bin\nsimilarity index 100%\nrename from old.bin\nrename to new.bin\n" +
+		testPatch
+	var sections []string
+	for section, err := range Sections(strings.NewReader(patch)) {
+		if err != nil {
+			t.Fatal(err)
+		}
+		sections = append(sections, section)
+	}
+	if len(sections) != 5 {
+		t.Fatalf("Sections() = %d sections:\n%q", len(sections), sections)
+	}
+	if strings.Join(sections, "") != patch {
+		t.Error("sections do not add up to the patch")
+	}
+	if !strings.HasPrefix(sections[0], "commit message\n--- a/one.txt") || !strings.HasSuffix(sections[0], "--- b\n a\n") {
+		t.Errorf("first section = %q", sections[0])
+	}
+	if !strings.HasPrefix(sections[3], "diff --git a/main.go") {
+		t.Errorf("fourth section = %q", sections[3])
+	}
+}
+
+func TestRenderReaderLargePatch(t *testing.T) {
+	var patch strings.Builder
+	for i := range 500 {
+		fmt.Fprintf(&patch, "diff --git a/f%d.go b/f%d.go\n--- a/f%d.go\n+++ b/f%d.go\n@@ -1 +1 @@\n-old\n+new\n", i, i, i, i)
+	}
+	var out bytes.Buffer
+	n, err := RenderReader(&out, strings.NewReader(patch.String()), Options{Files: []string{"f4*.go"}})
+	if err != nil || n != 111 {
+		t.Fatalf("RenderReader() = %d, %v", n, err)
+	}
+	if !strings.Contains(out.String(), "▶ [1/111] f4.go") || !strings.Contains(out.String(), "▶ [111/111] f499.go") {
+		t.Errorf("file headers are not numbered over the matching files:\n%s", out.String())
+	}
+}
+
+func TestRenderShowsUnparsedFileAsIs(t *testing.T) {
+	patch := "diff --git a/bad.go b/bad.go\n--- a/bad.go\n+++ b/bad.go\n@@ -1,2 +1,2 @@\n-x\n" + testPatch
+	var out bytes.Buffer
+	n, err := Render(&out, patch, Options{})
+	if err != nil || n != 2 {
+		t.Fatalf("Render() = %d, %v", n, err)
+	}
+	for _, want := range []string{"showing unparsed patch text", "  -x", "▶ [2/2] docs/README.md"} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("output missing %q:\n%s", want, out.String())
+		}
+	}
+}
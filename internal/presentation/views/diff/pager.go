@@ -0,0 +1,102 @@
+package diff
+
+import (
+	"errors"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/mattn/go-isatty"
+)
+
+// PagerCommand returns the pager from JULESON_PAGER or PAGER, defaulting to
+// less. An empty result means no pager; "cat" disables paging too.
+func PagerCommand() []string {
+	pager := os.Getenv("JULESON_PAGER")
+	if pager == "" {
+		pager = os.Getenv("PAGER")
+	}
+	if pager == "" {
+		pager = "less"
+	}
+	fields := strings.Fields(pager)
+	if len(fields) == 0 || filepath.Base(fields[0]) == "cat" {
+		return nil
+	}
+	return fields
+}
+
+// Page writes content to out, through the pager when out is a terminal. For
+// less, the file markers are searched for so n and N jump between files.
+func Page(out *os.File, content string) error {
+	pager, err := NewPager(out)
+	if err != nil {
+		return err
+	}
+	if _, err := io.WriteString(pager, content); err != nil {
+		_ = pager.Close()
+		return err
+	}
+	return pager.Close()
+}
+
+// NewPager starts the pager for out, like Page, and returns a writer that
+// streams to it, so output is shown while it is produced instead of being
+// buffered. Close waits for the user to quit the pager. When out is not a
+// terminal or no pager is available, writes go to out directly.
+func NewPager(out *os.File) (io.WriteCloser, error) {
+	pager := PagerCommand()
+	if pager == nil || !isatty.IsTerminal(out.Fd()) {
+		return nopCloser{out}, nil
+	}
+
+	args := pager[1:]
+	if filepath.Base(pager[0]) == "less" {
+		args = append(args, "-R", "-F", "-X", "--pattern="+FileMarker)
+	}
+	cmd := exec.Command(pager[0], args...)
+	cmd.Stdout = out
+	cmd.Stderr = os.Stderr
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return nil, err
+	}
+	if err := cmd.Start(); err != nil {
+		if errors.Is(err, exec.ErrNotFound) {
+			return nopCloser{out}, nil
+		}
+		return nil, err
+	}
+	return &pagerWriter{cmd: cmd, stdin: stdin}, nil
+}
+
+// pagerWriter feeds a running pager.
+type pagerWriter struct {
+	cmd   *exec.Cmd
+	stdin io.WriteCloser
+	// quit is set once the pager stops reading, after which output is
+	// dropped.
+	quit bool
+}
+
+func (p *pagerWriter) Write(data []byte) (int, error) {
+	if p.quit {
+		return len(data), nil
+	}
+	if _, err := p.stdin.Write(data); err != nil {
+		// The user quit the pager before the end of the output.
+		p.quit = true
+	}
+	return len(data), nil
+}
+
+func (p *pagerWriter) Close() error {
+	_ = p.stdin.Close()
+	return p.cmd.Wait()
+}
+
+type nopCloser struct{ io.Writer }
+
+func (nopCloser) Close() error { return nil }
@@ -0,0 +1,113 @@
+package diff
+
+import (
+	"bufio"
+	"errors"
+	"io"
+	"iter"
+	"strconv"
+	"strings"
+)
+
+// Sections splits a unified patch into the text of each file, so a large
+// patch can be parsed and rendered one file at a time. A hunk's line counts
+// are followed, so removed lines that look like file headers stay in their
+// file. Text before the first file is yielded with it.
+func Sections(r io.Reader) iter.Seq2[string, error] {
+	return func(yield func(string, error) bool) {
+		reader := bufio.NewReader(r)
+		var (
+			section strings.Builder
+			// header is set once the section has a file header, and hunk
+			// once it has a hunk.
+			header, hunk     bool
+			oldLeft, newLeft int64
+		)
+		flush := func() bool {
+			if section.Len() == 0 {
+				return true
+			}
+			text := section.String()
+			section.Reset()
+			header, hunk = false, false
+			return yield(text, nil)
+		}
+
+		for {
+			line, err := reader.ReadString('\n')
+			if line != "" {
+				if (oldLeft > 0 || newLeft > 0) && !strings.ContainsRune(" -+\\\n", rune(line[0])) {
+					// A hunk shorter than its header says ends here.
+					oldLeft, newLeft = 0, 0
+				}
+				switch {
+				case oldLeft > 0 || newLeft > 0:
+					switch line[0] {
+					case '-':
+						oldLeft--
+					case '+':
+						newLeft--
+					case '\\':
+					default:
+						oldLeft--
+						newLeft--
+					}
+				case strings.HasPrefix(line, "diff --git "):
+					if header && !flush() {
+						return
+					}
+					header = true
+				case strings.HasPrefix(line, "--- "):
+					// Before the first hunk, this is the old name line of
+					// the current file's header.
+					if hunk && !flush() {
+						return
+					}
+					header = true
+				case strings.HasPrefix(line, "@@ "):
+					// A malformed header is left for the parser to
+					// report.
+					if oldLines, newLines, ok := hunkLines(line); ok {
+						oldLeft, newLeft = oldLines, newLines
+						hunk = true
+					}
+				}
+				section.WriteString(line)
+			}
+			if errors.Is(err, io.EOF) {
+				flush()
+				return
+			}
+			if err != nil {
+				yield("", err)
+				return
+			}
+		}
+	}
+}
+
+// hunkLines returns the old and new line counts of a hunk header such as
+// "@@ -1,3 +1,4 @@".
+func hunkLines(line string) (int64, int64, bool) {
+	fields := strings.Fields(line)
+	if len(fields) < 3 || !strings.HasPrefix(fields[1], "-") || !strings.HasPrefix(fields[2], "+") {
+		return 0, 0, false
+	}
+	oldLines, ok := rangeLines(fields[1][1:])
+	if !ok {
+		return 0, 0, false
+	}
+	newLines, ok := rangeLines(fields[2][1:])
+	return oldLines, newLines, ok
+}
+
+// rangeLines returns the line count of a hunk range "start,count", which is
+// one when the count is left out.
+func rangeLines(hunkRange string) (int64, bool) {
+	_, count, found := strings.Cut(hunkRange, ",")
+	if !found {
+		return 1, true
+	}
+	n, err := strconv.ParseInt(count, 10, 64)
+	return n, err == nil && n >= 0
+}
@@ -0,0 +1,52 @@
+package diff
+
+import (
+	"strings"
+
+	"github.com/alecthomas/chroma/v2"
+	"github.com/alecthomas/chroma/v2/formatters"
+	"github.com/alecthomas/chroma/v2/lexers"
+	"github.com/alecthomas/chroma/v2/styles"
+)
+
+// ANSI styles of the diff.
+const (
+	styleReset       = "\x1b[0m"
+	styleHeader      = "\x1b[1;36m"
+	styleHunk        = "\x1b[35m"
+	styleDim         = "\x1b[2m"
+	styleAdded       = "\x1b[32m"
+	styleRemoved     = "\x1b[31m"
+	styleAddedWord   = "\x1b[1;30;42m"
+	styleRemovedWord = "\x1b[1;30;41m"
+)
+
+// highlighter syntax-highlights lines of one file. Lines are highlighted one
+// at a time, so constructs spanning lines, such as block comments, are only
+// approximated.
+type highlighter struct {
+	lexer chroma.Lexer
+	style *chroma.Style
+}
+
+// newHighlighter returns a highlighter for the language of filename, or nil
+// when the language is unknown.
+func newHighlighter(filename string) *highlighter {
+	lexer := lexers.Match(filename)
+	if lexer == nil {
+		return nil
+	}
+	return &highlighter{lexer: chroma.Coalesce(lexer), style: styles.Get("monokai")}
+}
+
+func (h *highlighter) line(text string) string {
+	iterator, err := h.lexer.Tokenise(nil, text)
+	if err != nil {
+		return text
+	}
+	var b strings.Builder
+	if err := formatters.TTY256.Format(&b, h.style, iterator); err != nil {
+		return text
+	}
+	return strings.TrimRight(b.String(), "\n")
+}
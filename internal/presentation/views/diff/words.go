@@ -0,0 +1,120 @@
+package diff
+
+import "unicode"
+
+// maxWordTokens bounds the word diff of a line pair; longer lines are
+// highlighted as a whole.
+const maxWordTokens = 400
+
+// segment is a run of a line that is either shared with the other line of
+// its pair or changed.
+type segment struct {
+	text    string
+	changed bool
+}
+
+// wordDiff splits a removed and an added line into segments, marking the
+// words that are not in their longest common subsequence as changed.
+func wordDiff(oldLine, newLine string) ([]segment, []segment) {
+	oldTokens, newTokens := tokenize(oldLine), tokenize(newLine)
+	if len(oldTokens) > maxWordTokens || len(newTokens) > maxWordTokens {
+		return []segment{{text: oldLine, changed: true}}, []segment{{text: newLine, changed: true}}
+	}
+
+	// lengths[i][j] is the LCS length of oldTokens[i:] and newTokens[j:].
+	lengths := make([][]int, len(oldTokens)+1)
+	for i := range lengths {
+		lengths[i] = make([]int, len(newTokens)+1)
+	}
+	for i := len(oldTokens) - 1; i >= 0; i-- {
+		for j := len(newTokens) - 1; j >= 0; j-- {
+			if oldTokens[i] == newTokens[j] {
+				lengths[i][j] = lengths[i+1][j+1] + 1
+			} else {
+				lengths[i][j] = max(lengths[i+1][j], lengths[i][j+1])
+			}
+		}
+	}
+
+	var oldSegments, newSegments []segment
+	i, j := 0, 0
+	for i < len(oldTokens) || j < len(newTokens) {
+		switch {
+		case i < len(oldTokens) && j < len(newTokens) && oldTokens[i] == newTokens[j]:
+			oldSegments = appendSegment(oldSegments, oldTokens[i], false)
+			newSegments = appendSegment(newSegments, newTokens[j], false)
+			i++
+			j++
+		case j < len(newTokens) && (i == len(oldTokens) || lengths[i][j+1] >= lengths[i+1][j]):
+			newSegments = appendSegment(newSegments, newTokens[j], true)
+			j++
+		default:
+			oldSegments = appendSegment(oldSegments, oldTokens[i], true)
+			i++
+		}
+	}
+	return oldSegments, newSegments
+}
+
+// tokenize splits a line into words, runs of spaces, and single punctuation
+// characters.
+func tokenize(line string) []string {
+	var tokens []string
+	runes := []rune(line)
+	for start := 0; start < len(runes); {
+		end := start + 1
+		switch {
+		case isWord(runes[start]):
+			for end < len(runes) && isWord(runes[end]) {
+				end++
+			}
+		case unicode.IsSpace(runes[start]):
+			for end < len(runes) && unicode.IsSpace(runes[end]) {
+				end++
+			}
+		}
+		tokens = append(tokens, string(runes[start:end]))
+		start = end
+	}
+	return tokens
+}
+
+func isWord(r rune) bool {
+	return r == '_' || unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+func appendSegment(segments []segment, text string, changed bool) []segment {
+	if n := len(segments); n > 0 && segments[n-1].changed == changed {
+		segments[n-1].text += text
+		return segments
+	}
+	return append(segments, segment{text: text, changed: changed})
+}
+
+func segmentsLen(segments []segment) int {
+	n := 0
+	for _, s := range segments {
+		n += runeLen(s.text)
+	}
+	return n
+}
+
+// truncateSegments shortens segments to width runes, ending with an
+// ellipsis when anything was cut.
+func truncateSegments(segments []segment, width int) []segment {
+	if segmentsLen(segments) <= width {
+		return segments
+	}
+	var kept []segment
+	remaining := width - 1
+	for _, s := range segments {
+		runes := []rune(s.text)
+		if len(runes) >= remaining {
+			kept = append(kept, segment{text: string(runes[:remaining]) + "…", changed: s.changed})
+			break
+		}
+		kept = append(kept, s)
+		remaining -= len(runes)
+	}
+	return kept
+}
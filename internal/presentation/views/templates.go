@@ -102,5 +102,28 @@ func (f *TemplateFormatter) FormatDetails(template *templates.Template) string {
 		}
 	}
 
+	if variables := template.Variables(); len(variables) > 0 {
+		sb.WriteString("\n" + theme.StepStyle.Render(fmt.Sprintf("Variables (%d)", len(variables))) + "\n")
+		for _, variable := range variables {
+			fmt.Fprintf(&sb, "  %s", valStyle.Render(variable.Name))
+			if variable.Required {
+				sb.WriteString(" " + labelStyle.Render("(required)"))
+			}
+			if variable.Type != "" {
+				sb.WriteString(" " + labelStyle.Render(variable.Type))
+			}
+			if len(variable.Enum) > 0 {
+				fmt.Fprintf(&sb, " %s %s", labelStyle.Render("one of:"), strings.Join(variable.Enum, ", "))
+			}
+			if variable.Default != "" {
+				fmt.Fprintf(&sb, " %s %s", labelStyle.Render("default:"), variable.Default)
+			}
+			sb.WriteString("\n")
+			if variable.Description != "" {
+				fmt.Fprintf(&sb, "     %s\n", variable.Description)
+			}
+		}
+	}
+
 	return sb.String()
 }
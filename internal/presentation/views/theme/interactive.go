@@ -25,6 +25,30 @@ func InputString(title string, placeholder string) (string, error) {
 	return result, err
 }
 
+// InputValidated prompts the user for a string input until validate
+// accepts it.
+func InputValidated(title string, placeholder string, validate func(string) error) (string, error) {
+	var result string
+	err := huh.NewInput().
+		Title(title).
+		Placeholder(placeholder).
+		Validate(validate).
+		Value(&result).
+		Run()
+	return result, err
+}
+
+// Select prompts the user to choose one of options.
+func Select(title string, options []string) (string, error) {
+	var result string
+	err := huh.NewSelect[string]().
+		Title(title).
+		Options(huh.NewOptions(options...)...).
+		Value(&result).
+		Run()
+	return result, err
+}
+
 // InputSecret prompts the user for a sensitive string.
 func InputSecret(title string) (string, error) {
 	var result string
@@ -0,0 +1,140 @@
+// Package rbac maps the bearer tokens of Juleson's network surfaces to the
+// roles that decide what their holders may do: viewers read, operators
+// also run mutating operations, and admins also run dangerous ones such as
+// deleting sessions, running containers, and changing clusters.
+package rbac
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"fmt"
+	"strings"
+)
+
+// Role grants a set of operations. Each role includes those of the roles
+// below it.
+type Role string
+
+// Roles, from least to most privileged.
+const (
+	RoleViewer   Role = "viewer"
+	RoleOperator Role = "operator"
+	RoleAdmin    Role = "admin"
+)
+
+// ParseRole parses a role name.
+func ParseRole(name string) (Role, error) {
+	role := Role(strings.ToLower(strings.TrimSpace(name)))
+	if role.rank() == 0 {
+		return "", fmt.Errorf("unknown role %q (use viewer, operator, or admin)", name)
+	}
+	return role, nil
+}
+
+// Allows reports whether r includes the operations of required.
+func (r Role) Allows(required Role) bool {
+	return r.rank() > 0 && r.rank() >= required.rank()
+}
+
+func (r Role) rank() int {
+	switch r {
+	case RoleViewer:
+		return 1
+	case RoleOperator:
+		return 2
+	case RoleAdmin:
+		return 3
+	default:
+		return 0
+	}
+}
+
+// Token is a configured bearer token, kept as its SHA-256.
+type Token struct {
+	// Name identifies the token's holder, such as in the audit log.
+	Name string
+	// SHA256 is the hex SHA-256 of the token.
+	SHA256 string
+	Role   Role
+	// Tenant is the tenant the holder acts as. Empty means none.
+	Tenant string
+}
+
+// HashToken returns the hex SHA-256 of token, as Token.SHA256 holds it.
+func HashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// Caller is the holder of a verified token.
+type Caller struct {
+	Name   string
+	Role   Role
+	Tenant string
+}
+
+// Tokens verifies bearer tokens against the configured ones.
+type Tokens struct {
+	sums    [][]byte
+	callers []Caller
+}
+
+// NewTokens returns a verifier for tokens. Names must be unique so audit
+// entries identify one holder.
+func NewTokens(tokens []Token) (*Tokens, error) {
+	verifier := &Tokens{}
+	names := make(map[string]bool, len(tokens))
+	for _, token := range tokens {
+		if token.Name == "" {
+			return nil, fmt.Errorf("token name is required")
+		}
+		if names[token.Name] {
+			return nil, fmt.Errorf("duplicate token name %q", token.Name)
+		}
+		names[token.Name] = true
+		sum, err := hex.DecodeString(token.SHA256)
+		if err != nil || len(sum) != sha256.Size {
+			return nil, fmt.Errorf("token %s: invalid SHA-256 %q", token.Name, token.SHA256)
+		}
+		if token.Role.rank() == 0 {
+			return nil, fmt.Errorf("token %s: unknown role %q (use viewer, operator, or admin)", token.Name, token.Role)
+		}
+		verifier.sums = append(verifier.sums, sum)
+		verifier.callers = append(verifier.callers, Caller{Name: token.Name, Role: token.Role, Tenant: token.Tenant})
+	}
+	return verifier, nil
+}
+
+// Len returns the number of configured tokens.
+func (t *Tokens) Len() int {
+	return len(t.sums)
+}
+
+// Verify returns the holder of token. Every configured token is compared,
+// in constant time, so the time taken does not reveal which one matched.
+func (t *Tokens) Verify(token string) (Caller, bool) {
+	sum := sha256.Sum256([]byte(token))
+	var caller Caller
+	found := false
+	for i, expected := range t.sums {
+		if subtle.ConstantTimeCompare(sum[:], expected) == 1 {
+			caller, found = t.callers[i], true
+		}
+	}
+	return caller, found
+}
+
+type callerKey struct{}
+
+// WithCaller returns a context carrying caller.
+func WithCaller(ctx context.Context, caller Caller) context.Context {
+	return context.WithValue(ctx, callerKey{}, caller)
+}
+
+// CallerFrom returns the caller carried by ctx, if any.
+func CallerFrom(ctx context.Context) (Caller, bool) {
+	caller, ok := ctx.Value(callerKey{}).(Caller)
+	return caller, ok
+}
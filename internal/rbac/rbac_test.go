@@ -0,0 +1,70 @@
+package rbac
+
+import (
+	"context"
+	"testing"
+)
+
+func TestRoleAllows(t *testing.T) {
+	tests := []struct {
+		role, required Role
+		want           bool
+	}{
+		{RoleViewer, RoleViewer, true},
+		{RoleViewer, RoleOperator, false},
+		{RoleOperator, RoleViewer, true},
+		{RoleOperator, RoleAdmin, false},
+		{RoleAdmin, RoleAdmin, true},
+		{Role("root"), RoleViewer, false},
+	}
+	for _, tt := range tests {
+		if got := tt.role.Allows(tt.required); got != tt.want {
+			t.Errorf("%q.Allows(%q) = %v, want %v", tt.role, tt.required, got, tt.want)
+		}
+	}
+	if role, err := ParseRole(" Operator "); err != nil || role != RoleOperator {
+		t.Errorf("ParseRole() = %q, %v", role, err)
+	}
+	if _, err := ParseRole("root"); err == nil {
+		t.Error("ParseRole(root) succeeded")
+	}
+}
+
+func TestTokensVerify(t *testing.T) {
+	tokens, err := NewTokens([]Token{
+		{Name: "dashboard", SHA256: HashToken("view-secret"), Role: RoleViewer},
+		{Name: "ci-bot", SHA256: HashToken("ops-secret"), Role: RoleOperator},
+	})
+	if err != nil {
+		t.Fatalf("NewTokens() error = %v", err)
+	}
+	if caller, ok := tokens.Verify("ops-secret"); !ok || caller != (Caller{Name: "ci-bot", Role: RoleOperator}) {
+		t.Errorf("Verify(ops-secret) = %+v, %v", caller, ok)
+	}
+	if caller, ok := tokens.Verify("wrong"); ok {
+		t.Errorf("Verify(wrong) = %+v, want no caller", caller)
+	}
+}
+
+func TestNewTokensRejectsInvalidTokens(t *testing.T) {
+	for _, token := range [][]Token{
+		{{SHA256: HashToken("a"), Role: RoleViewer}},
+		{{Name: "a", SHA256: "not-hex", Role: RoleViewer}},
+		{{Name: "a", SHA256: HashToken("a"), Role: "root"}},
+		{{Name: "a", SHA256: HashToken("a"), Role: RoleViewer}, {Name: "a", SHA256: HashToken("b"), Role: RoleAdmin}},
+	} {
+		if _, err := NewTokens(token); err == nil {
+			t.Errorf("NewTokens(%+v) succeeded", token)
+		}
+	}
+}
+
+func TestCallerContext(t *testing.T) {
+	if _, ok := CallerFrom(context.Background()); ok {
+		t.Fatal("CallerFrom(empty context) found a caller")
+	}
+	ctx := WithCaller(context.Background(), Caller{Name: "ci-bot", Role: RoleOperator})
+	if caller, ok := CallerFrom(ctx); !ok || caller.Name != "ci-bot" {
+		t.Fatalf("CallerFrom() = %+v, %v", caller, ok)
+	}
+}
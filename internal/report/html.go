@@ -0,0 +1,120 @@
+package report
+
+import (
+	"html/template"
+	"io"
+)
+
+var htmlTemplate = template.Must(template.New("report").Funcs(template.FuncMap{
+	"duration": formatDuration,
+}).Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>Run report: {{.Run.Workflow}}</title>
+<style>
+body { font-family: system-ui, sans-serif; margin: 2rem; color: #222; max-width: 60rem; }
+table { border-collapse: collapse; width: 100%; margin: 0.5rem 0 1rem; }
+th, td { padding: 0.35rem 0.6rem; text-align: left; border-bottom: 1px solid #ddd; }
+td.num { text-align: right; }
+code, td.file { font-family: ui-monospace, monospace; }
+.completed { color: #1a7f37; }
+.failed { color: #cf222e; }
+section { border-top: 1px solid #ddd; margin-top: 1.5rem; }
+</style>
+</head>
+<body>
+<h1>Run report: {{.Run.Workflow}}</h1>
+<ul>
+<li><strong>Run:</strong> <code>{{.Run.ID}}</code></li>
+<li><strong>Status:</strong> <span class="{{.Run.Status}}">{{.Run.Status}}</span></li>
+<li><strong>Started:</strong> {{.Run.Started.Local.Format "2006-01-02 15:04:05"}}</li>
+<li><strong>Duration:</strong> {{duration .Run.Duration}}</li>
+<li><strong>Tasks:</strong> {{.TaskSummary}}</li>
+{{- if .Run.Error}}
+<li><strong>Error:</strong> {{.Run.Error}}</li>
+{{- end}}
+</ul>
+{{- if .Run.Tasks}}
+<h2>Tasks</h2>
+<table>
+<tr><th>Task</th><th>Status</th><th>Session</th><th>Duration</th><th>Error</th></tr>
+{{- range .Run.Tasks}}
+<tr><td>{{.Name}}</td><td class="{{.Status}}">{{.Status}}</td><td><code>{{.SessionID}}</code></td><td>{{duration .Duration}}</td><td>{{.Error}}</td></tr>
+{{- end}}
+</table>
+{{- end}}
+{{- if .Sessions}}
+<h2>Sessions</h2>
+{{- range .Sessions}}
+<section>
+<h3><code>{{.ID}}</code>{{if .Task}} ({{.Task}}){{end}}</h3>
+<ul>
+{{- if .State}}
+<li><strong>State:</strong> {{.State}}</li>
+{{- end}}
+{{- if .URL}}
+<li><strong>Link:</strong> <a href="{{.URL}}">{{.URL}}</a></li>
+{{- end}}
+{{- range .PullRequests}}
+<li><strong>Pull request:</strong> <a href="{{.}}">{{.}}</a></li>
+{{- end}}
+{{- if .Error}}
+<li><strong>Details unavailable:</strong> {{.Error}}</li>
+{{- end}}
+</ul>
+{{- if .Plan}}
+<h4>Plan</h4>
+<ol>
+{{- range .Plan}}
+<li>{{.}}</li>
+{{- end}}
+</ol>
+{{- end}}
+{{- if .Files}}
+<h4>Changes: {{.DiffStat}}</h4>
+<table>
+<tr><th>File</th><th>Added</th><th>Removed</th></tr>
+{{- range .Files}}
+<tr><td class="file">{{.Label}}</td><td class="num">{{.LinesAdded}}</td><td class="num">{{.LinesRemoved}}</td></tr>
+{{- end}}
+</table>
+{{- end}}
+{{- if .Findings}}
+<h4>Review findings</h4>
+<ul>
+{{- range .Findings}}
+<li>{{.}}</li>
+{{- end}}
+</ul>
+{{- end}}
+</section>
+{{- end}}
+{{- end}}
+{{- if .Decisions}}
+<h2>Decisions</h2>
+<table>
+<tr><th>Time</th><th>Session</th><th>Action</th><th>Outcome</th></tr>
+{{- range .Decisions}}
+<tr><td>{{.Time.Local.Format "2006-01-02 15:04:05"}}</td><td><code>{{.Session}}</code></td><td>{{.Action}}</td><td>{{.Outcome}}</td></tr>
+{{- end}}
+</table>
+{{- end}}
+{{- with .PullRequests}}
+<h2>Pull requests</h2>
+<ul>
+{{- range .}}
+<li><a href="{{.}}">{{.}}</a></li>
+{{- end}}
+</ul>
+{{- end}}
+<hr>
+<p>Generated by Juleson on {{.Generated.Local.Format "2006-01-02 15:04"}}.</p>
+</body>
+</html>
+`))
+
+// WriteHTML renders the report as a standalone HTML page.
+func (r *Report) WriteHTML(w io.Writer) error {
+	return htmlTemplate.Execute(w, r)
+}
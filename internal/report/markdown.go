@@ -0,0 +1,99 @@
+package report
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// WriteMarkdown renders the report as GitHub-flavored Markdown.
+func (r *Report) WriteMarkdown(w io.Writer) error {
+	b := bufio.NewWriter(w)
+	run := r.Run
+
+	fmt.Fprintf(b, "# Run report: %s\n\n", run.Workflow)
+	fmt.Fprintf(b, "- **Run:** `%s`\n", run.ID)
+	fmt.Fprintf(b, "- **Status:** %s\n", run.Status)
+	fmt.Fprintf(b, "- **Started:** %s\n", run.Started.Local().Format("2006-01-02 15:04:05"))
+	fmt.Fprintf(b, "- **Duration:** %s\n", formatDuration(run.Duration))
+	fmt.Fprintf(b, "- **Tasks:** %s\n", r.TaskSummary())
+	if run.Error != "" {
+		fmt.Fprintf(b, "- **Error:** %s\n", markdownText(run.Error))
+	}
+
+	if len(run.Tasks) > 0 {
+		fmt.Fprintf(b, "\n## Tasks\n\n| Task | Status | Session | Duration | Error |\n| --- | --- | --- | --- | --- |\n")
+		for _, task := range run.Tasks {
+			fmt.Fprintf(b, "| %s | %s | %s | %s | %s |\n", markdownCell(task.Name), task.Status, markdownCell(task.SessionID), formatDuration(task.Duration), markdownCell(task.Error))
+		}
+	}
+
+	if len(r.Sessions) > 0 {
+		fmt.Fprintf(b, "\n## Sessions\n")
+	}
+	for _, session := range r.Sessions {
+		fmt.Fprintf(b, "\n### %s", markdownText(session.ID))
+		if session.Task != "" {
+			fmt.Fprintf(b, " (%s)", markdownText(session.Task))
+		}
+		fmt.Fprintf(b, "\n\n")
+		if session.State != "" {
+			fmt.Fprintf(b, "- **State:** %s\n", session.State)
+		}
+		if session.URL != "" {
+			fmt.Fprintf(b, "- **Link:** <%s>\n", session.URL)
+		}
+		for _, pr := range session.PullRequests {
+			fmt.Fprintf(b, "- **Pull request:** <%s>\n", pr)
+		}
+		if session.Error != "" {
+			fmt.Fprintf(b, "- **Details unavailable:** %s\n", markdownText(session.Error))
+		}
+		if len(session.Plan) > 0 {
+			fmt.Fprintf(b, "\n**Plan**\n\n")
+			for i, step := range session.Plan {
+				fmt.Fprintf(b, "%d. %s\n", i+1, markdownText(step))
+			}
+		}
+		if len(session.Files) > 0 {
+			fmt.Fprintf(b, "\n**Changes:** %s\n\n| File | Added | Removed |\n| --- | ---: | ---: |\n", session.DiffStat())
+			for _, file := range session.Files {
+				fmt.Fprintf(b, "| %s | %d | %d |\n", markdownCell(file.Label()), file.LinesAdded, file.LinesRemoved)
+			}
+		}
+		if len(session.Findings) > 0 {
+			fmt.Fprintf(b, "\n**Review findings**\n\n")
+			for _, finding := range session.Findings {
+				fmt.Fprintf(b, "- %s\n", markdownText(finding))
+			}
+		}
+	}
+
+	if len(r.Decisions) > 0 {
+		fmt.Fprintf(b, "\n## Decisions\n\n| Time | Session | Action | Outcome |\n| --- | --- | --- | --- |\n")
+		for _, decision := range r.Decisions {
+			fmt.Fprintf(b, "| %s | %s | %s | %s |\n", decision.Time.Local().Format("2006-01-02 15:04:05"), markdownCell(decision.Session), markdownCell(decision.Action), markdownCell(decision.Outcome))
+		}
+	}
+
+	if prs := r.PullRequests(); len(prs) > 0 {
+		fmt.Fprintf(b, "\n## Pull requests\n\n")
+		for _, pr := range prs {
+			fmt.Fprintf(b, "- <%s>\n", pr)
+		}
+	}
+
+	fmt.Fprintf(b, "\n---\n\nGenerated by Juleson on %s.\n", r.Generated.Local().Format("2006-01-02 15:04"))
+	return b.Flush()
+}
+
+// markdownText keeps text on one line.
+func markdownText(text string) string {
+	return strings.Join(strings.Fields(text), " ")
+}
+
+// markdownCell keeps text on one line and escapes table separators.
+func markdownCell(text string) string {
+	return strings.ReplaceAll(markdownText(text), "|", `\|`)
+}
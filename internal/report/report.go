@@ -0,0 +1,143 @@
+// Package report renders shareable Markdown and HTML reports of template
+// runs: the run's outcome and tasks, each session's plan, diff stats, and
+// review findings, the decisions taken along the way, and links to the
+// sessions and pull requests.
+package report
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/SamyRai/juleson/internal/events"
+	"github.com/SamyRai/juleson/internal/jules/workspace"
+)
+
+// Report formats.
+const (
+	FormatMarkdown = "markdown"
+	FormatHTML     = "html"
+)
+
+// Report is a run and what its sessions did.
+type Report struct {
+	Run       events.RunRecord
+	Generated time.Time
+	Sessions  []Session
+	Decisions []Decision
+}
+
+// Session is one session of a run.
+type Session struct {
+	ID    string
+	Task  string
+	State string
+	URL   string
+	// Plan holds the titles of the latest plan's steps.
+	Plan  []string
+	Files []workspace.FileChange
+	// Findings are the review's warnings about the session's patches, such
+	// as possible secrets or a base commit mismatch.
+	Findings     []string
+	PullRequests []string
+	// Error says why the plan and changes are missing, if they are.
+	Error string
+}
+
+// LinesChanged sums the lines added and removed across the files.
+func (s Session) LinesChanged() (added, removed int) {
+	for _, file := range s.Files {
+		added += file.LinesAdded
+		removed += file.LinesRemoved
+	}
+	return added, removed
+}
+
+// DiffStat summarizes the changes, such as "3 file(s), +40 −12".
+func (s Session) DiffStat() string {
+	added, removed := s.LinesChanged()
+	return fmt.Sprintf("%d file(s), +%d −%d", len(s.Files), added, removed)
+}
+
+// Decision is an operation taken on a session during the run, such as a plan
+// approval or a message, from the audit log.
+type Decision struct {
+	Time    time.Time
+	Session string
+	Action  string
+	Outcome string
+}
+
+// TaskSummary counts the run's tasks, such as "2 completed, 1 failed, 3
+// total".
+func (r *Report) TaskSummary() string {
+	completed, failed := r.Run.TaskCounts()
+	return fmt.Sprintf("%d completed, %d failed, %d total", completed, failed, r.Run.TotalTasks)
+}
+
+// FormatFor returns the format of a report file by its extension: HTML for
+// .html and .htm, Markdown otherwise.
+func FormatFor(path string) string {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".html", ".htm":
+		return FormatHTML
+	default:
+		return FormatMarkdown
+	}
+}
+
+// Render returns the report in format.
+func (r *Report) Render(format string) (string, error) {
+	var b strings.Builder
+	var err error
+	switch format {
+	case FormatMarkdown, "":
+		err = r.WriteMarkdown(&b)
+	case FormatHTML:
+		err = r.WriteHTML(&b)
+	default:
+		return "", fmt.Errorf("unknown report format %q (use markdown or html)", format)
+	}
+	return b.String(), err
+}
+
+// WriteFile writes the report to path in the format its extension implies.
+func (r *Report) WriteFile(path string) error {
+	content, err := r.Render(FormatFor(path))
+	if err != nil {
+		return err
+	}
+	if dir := filepath.Dir(path); dir != "." {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			return fmt.Errorf("failed to create report directory: %w", err)
+		}
+	}
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		return fmt.Errorf("failed to write report: %w", err)
+	}
+	return nil
+}
+
+// PullRequests returns the run's pull requests, including those only known
+// from its sessions' outputs.
+func (r *Report) PullRequests() []string {
+	prs := append([]string(nil), r.Run.PullRequests...)
+	for _, session := range r.Sessions {
+		for _, pr := range session.PullRequests {
+			if !slices.Contains(prs, pr) {
+				prs = append(prs, pr)
+			}
+		}
+	}
+	return prs
+}
+
+func formatDuration(d time.Duration) string {
+	if d == 0 {
+		return "-"
+	}
+	return d.Round(time.Second).String()
+}
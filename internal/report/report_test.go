@@ -0,0 +1,104 @@
+package report
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/SamyRai/juleson/internal/events"
+	"github.com/SamyRai/juleson/internal/jules/workspace"
+)
+
+func sampleReport() *Report {
+	started := time.Date(2026, 10, 1, 9, 0, 0, 0, time.UTC)
+	return &Report{
+		Run: events.RunRecord{
+			ID:         "20261001-090000-abcdef",
+			Workflow:   "refactor",
+			Status:     events.RunStatusFailed,
+			Started:    started,
+			Duration:   90 * time.Second,
+			Error:      "1 task failed",
+			TotalTasks: 2,
+			Tasks: []events.RunTask{
+				{Name: "api", Status: "completed", SessionID: "s1", Duration: time.Minute},
+				{Name: "web", Status: "failed", Error: "exit | 1"},
+			},
+			PullRequests: []string{"https://github.com/acme/api/pull/7"},
+		},
+		Generated: started.Add(time.Hour),
+		Sessions: []Session{{
+			ID:           "s1",
+			Task:         "api",
+			State:        "COMPLETED",
+			URL:          "https://jules.google.com/session/s1",
+			Plan:         []string{"Extract <handler>", "Add tests"},
+			Files:        []workspace.FileChange{{Path: "api.go", LinesAdded: 30, LinesRemoved: 4}, {Path: "api_test.go", LinesAdded: 12}},
+			Findings:     []string{"possible secret (AWS key) in api.go:3"},
+			PullRequests: []string{"https://github.com/acme/api/pull/7", "https://github.com/acme/api/pull/8"},
+		}},
+		Decisions: []Decision{{Time: started.Add(time.Minute), Session: "s1", Action: "session.approve_plan", Outcome: "ok"}},
+	}
+}
+
+func TestWriteMarkdown(t *testing.T) {
+	content, err := sampleReport().Render(FormatMarkdown)
+	if err != nil {
+		t.Fatalf("Render() error = %v", err)
+	}
+	for _, want := range []string{
+		"# Run report: refactor",
+		"- **Tasks:** 1 completed, 1 failed, 2 total",
+		`| web | failed |  | - | exit \| 1 |`,
+		"### s1 (api)",
+		"1. Extract <handler>",
+		"**Changes:** 2 file(s), +42 −4",
+		"| api.go | 30 | 4 |",
+		"- possible secret (AWS key) in api.go:3",
+		"| session.approve_plan | ok |",
+		"- <https://github.com/acme/api/pull/8>",
+	} {
+		if !strings.Contains(content, want) {
+			t.Errorf("Markdown report missing %q:\n%s", want, content)
+		}
+	}
+	if strings.Count(content, "- <https://github.com/acme/api/pull/7>") != 1 {
+		t.Errorf("pull request listed more than once:\n%s", content)
+	}
+}
+
+func TestWriteHTML(t *testing.T) {
+	content, err := sampleReport().Render(FormatHTML)
+	if err != nil {
+		t.Fatalf("Render() error = %v", err)
+	}
+	for _, want := range []string{
+		"<title>Run report: refactor</title>",
+		"<li>Extract &lt;handler&gt;</li>",
+		"<h4>Changes: 2 file(s), &#43;42 −4</h4>",
+		`<a href="https://github.com/acme/api/pull/8">`,
+	} {
+		if !strings.Contains(content, want) {
+			t.Errorf("HTML report missing %q:\n%s", want, content)
+		}
+	}
+	if _, err := sampleReport().Render("pdf"); err == nil {
+		t.Error("Render(pdf) succeeded")
+	}
+}
+
+func TestWriteFilePicksFormatByExtension(t *testing.T) {
+	dir := t.TempDir()
+	for name, prefix := range map[string]string{"out/run.html": "<!DOCTYPE html>", "run.md": "# Run report"} {
+		path := filepath.Join(dir, name)
+		if err := sampleReport().WriteFile(path); err != nil {
+			t.Fatalf("WriteFile(%s) error = %v", name, err)
+		}
+		data, err := os.ReadFile(path)
+		if err != nil || !strings.HasPrefix(string(data), prefix) {
+			t.Errorf("%s starts with %.20q, want %q (%v)", name, data, prefix, err)
+		}
+	}
+}
@@ -0,0 +1,441 @@
+// Package sandbox runs commands for MCP clients inside throwaway Docker
+// containers. Images and executables must be allow-listed, arguments are
+// validated before anything reaches the Docker daemon, and every container gets
+// CPU, memory, process, and time limits with networking off unless enabled.
+package sandbox
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/SamyRai/juleson/pkg/docker"
+)
+
+// Limits on what a client may pass to a sandboxed command.
+const (
+	MaxArgs      = 256
+	MaxArgLength = 4096
+	// MaxOutputBytes caps captured stdout and stderr each.
+	MaxOutputBytes = 1 << 20
+)
+
+// WorkspacePath is where the host directory is mounted in the container.
+const WorkspacePath = "/workspace"
+
+// Defaults applied by New for unset limits.
+const (
+	DefaultCPUs      = 2
+	DefaultMemoryMB  = 2048
+	DefaultPidsLimit = 512
+	DefaultTimeout   = 10 * time.Minute
+)
+
+var (
+	imagePattern = regexp.MustCompile(`^[a-z0-9][a-z0-9._/-]*(:[A-Za-z0-9_][A-Za-z0-9_.-]{0,127})?(@sha256:[a-f0-9]{64})?$`)
+	envKey       = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+)
+
+// Config controls what the sandbox allows.
+type Config struct {
+	// Images are path.Match globs such as "golang:*". Images without a tag
+	// are matched as name:latest.
+	Images []string
+	// Commands are the executables a client may run, matched exactly.
+	Commands []string
+	CPUs     float64
+	MemoryMB int
+	// PidsLimit caps processes in the container.
+	PidsLimit int
+	// Timeout is the default and maximum run time.
+	Timeout time.Duration
+	// Network allows clients to request network access. Containers run with
+	// --network none otherwise.
+	Network bool
+	// WritableWorkspace mounts the workspace read-write instead of read-only.
+	WritableWorkspace bool
+	// Root bounds the host directories that may be mounted. Empty means the
+	// current working directory.
+	Root string
+}
+
+// Spec describes one sandboxed run.
+type Spec struct {
+	Image   string
+	Command []string
+	// Dir is the host directory mounted at WorkspacePath. Empty means Root.
+	Dir     string
+	Env     map[string]string
+	Network bool
+	// Timeout shortens the configured timeout; longer values are capped.
+	Timeout time.Duration
+}
+
+// Result is the outcome of a run. A non-zero exit code is not an error.
+type Result struct {
+	Image       string   `json:"image"`
+	ImageID     string   `json:"image_id,omitempty"`
+	ContainerID string   `json:"container_id,omitempty"`
+	Command     []string `json:"command"`
+	// State is the container status when it stopped, normally "exited".
+	State      string `json:"state,omitempty"`
+	ExitCode   int    `json:"exit_code"`
+	OOMKilled  bool   `json:"oom_killed,omitempty"`
+	Stdout     string `json:"stdout"`
+	Stderr     string `json:"stderr"`
+	DurationMS int64  `json:"duration_ms"`
+	TimedOut   bool   `json:"timed_out"`
+	Truncated  bool   `json:"truncated"`
+}
+
+// Runner pulls images and runs containers. *docker.Client implements it.
+type Runner interface {
+	EnsureImage(ctx context.Context, ref string, progress io.Writer) (string, error)
+	Run(ctx context.Context, opts docker.RunOptions) (*docker.RunResult, error)
+}
+
+// Sandbox validates specs and runs them through the Docker Engine API.
+type Sandbox struct {
+	cfg Config
+	// runner defaults to a client for the daemon in the environment.
+	runner Runner
+}
+
+// New validates cfg, fills unset limits with defaults, and resolves Root.
+func New(cfg Config) (*Sandbox, error) {
+	if err := ValidateConfig(cfg); err != nil {
+		return nil, err
+	}
+	if cfg.CPUs == 0 {
+		cfg.CPUs = DefaultCPUs
+	}
+	if cfg.MemoryMB == 0 {
+		cfg.MemoryMB = DefaultMemoryMB
+	}
+	if cfg.PidsLimit == 0 {
+		cfg.PidsLimit = DefaultPidsLimit
+	}
+	if cfg.Timeout == 0 {
+		cfg.Timeout = DefaultTimeout
+	}
+	root := cfg.Root
+	if root == "" {
+		wd, err := os.Getwd()
+		if err != nil {
+			return nil, fmt.Errorf("failed to determine sandbox root: %w", err)
+		}
+		root = wd
+	}
+	resolved, err := resolveDir(root)
+	if err != nil {
+		return nil, fmt.Errorf("invalid sandbox root: %w", err)
+	}
+	cfg.Root = resolved
+	return &Sandbox{cfg: cfg}, nil
+}
+
+// ValidateConfig reports malformed image patterns, commands, and negative
+// limits.
+func ValidateConfig(cfg Config) error {
+	var errs []error
+	for _, pattern := range cfg.Images {
+		if _, err := path.Match(pattern, ""); err != nil || pattern == "" {
+			errs = append(errs, fmt.Errorf("invalid image pattern %q", pattern))
+		}
+	}
+	for _, command := range cfg.Commands {
+		if command == "" || strings.HasPrefix(command, "-") || strings.ContainsAny(command, " \t\n\x00") {
+			errs = append(errs, fmt.Errorf("invalid command %q", command))
+		}
+	}
+	if cfg.CPUs < 0 || cfg.MemoryMB < 0 || cfg.PidsLimit < 0 || cfg.Timeout < 0 {
+		errs = append(errs, errors.New("limits must not be negative"))
+	}
+	return errors.Join(errs...)
+}
+
+// Config returns the effective configuration.
+func (s *Sandbox) Config() Config {
+	return s.cfg
+}
+
+// Validate checks spec against the allow-lists and argument limits.
+func (s *Sandbox) Validate(spec Spec) error {
+	if !imagePattern.MatchString(spec.Image) {
+		return fmt.Errorf("invalid image reference %q", spec.Image)
+	}
+	if !s.imageAllowed(spec.Image) {
+		return fmt.Errorf("image %q is not allowed; allowed images: %s", spec.Image, listOrNone(s.cfg.Images))
+	}
+	if len(spec.Command) == 0 {
+		return errors.New("command is required")
+	}
+	if len(spec.Command) > MaxArgs {
+		return fmt.Errorf("command has %d arguments, limit is %d", len(spec.Command), MaxArgs)
+	}
+	for i, arg := range spec.Command {
+		if err := validateArg(arg); err != nil {
+			return fmt.Errorf("argument %d: %w", i, err)
+		}
+	}
+	if !contains(s.cfg.Commands, spec.Command[0]) {
+		return fmt.Errorf("command %q is not allowed; allowed commands: %s", spec.Command[0], listOrNone(s.cfg.Commands))
+	}
+	for key, value := range spec.Env {
+		if !envKey.MatchString(key) {
+			return fmt.Errorf("invalid environment variable name %q", key)
+		}
+		if err := validateArg(value); err != nil {
+			return fmt.Errorf("environment variable %s: %w", key, err)
+		}
+	}
+	if spec.Network && !s.cfg.Network {
+		return errors.New("network access is disabled; set sandbox.network to allow it")
+	}
+	if spec.Timeout < 0 {
+		return errors.New("timeout must not be negative")
+	}
+	if _, err := s.workspace(spec.Dir); err != nil {
+		return err
+	}
+	return nil
+}
+
+// RunOptions returns the container settings for spec, running it in a
+// container called name.
+func (s *Sandbox) RunOptions(spec Spec, name string) (docker.RunOptions, error) {
+	if err := s.Validate(spec); err != nil {
+		return docker.RunOptions{}, err
+	}
+	dir, err := s.workspace(spec.Dir)
+	if err != nil {
+		return docker.RunOptions{}, err
+	}
+
+	env := make([]string, 0, len(spec.Env))
+	for _, key := range sortedKeys(spec.Env) {
+		env = append(env, key+"="+spec.Env[key])
+	}
+	return docker.RunOptions{
+		Name:       name,
+		Image:      spec.Image,
+		Cmd:        spec.Command,
+		Env:        env,
+		WorkingDir: WorkspacePath,
+		Mounts: []docker.Mount{
+			{Source: dir, Target: WorkspacePath, ReadOnly: !s.cfg.WritableWorkspace},
+		},
+		Resources: docker.Resources{
+			NanoCPUs:    int64(s.cfg.CPUs * 1e9),
+			MemoryBytes: int64(s.cfg.MemoryMB) << 20,
+			PidsLimit:   int64(s.cfg.PidsLimit),
+		},
+		NetworkDisabled: !spec.Network,
+		CapDrop:         []string{"ALL"},
+		SecurityOpt:     []string{"no-new-privileges"},
+	}, nil
+}
+
+// Run executes spec in a fresh container and waits for it to exit, pulling
+// the image first if needed. The container is removed when it exits or the
+// timeout expires.
+func (s *Sandbox) Run(ctx context.Context, spec Spec) (*Result, error) {
+	name, err := containerName()
+	if err != nil {
+		return nil, err
+	}
+	stdout := &limitedBuffer{limit: MaxOutputBytes}
+	stderr := &limitedBuffer{limit: MaxOutputBytes}
+	opts, err := s.RunOptions(spec, name)
+	if err != nil {
+		return nil, err
+	}
+	opts.Stdout, opts.Stderr = stdout, stderr
+
+	runner := s.runner
+	if runner == nil {
+		client, err := docker.NewClient()
+		if err != nil {
+			return nil, err
+		}
+		defer client.Close()
+		runner = client
+	}
+
+	timeout := s.cfg.Timeout
+	if spec.Timeout > 0 && spec.Timeout < timeout {
+		timeout = spec.Timeout
+	}
+	runCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	start := time.Now()
+	if _, err := runner.EnsureImage(runCtx, spec.Image, nil); err != nil && runCtx.Err() == nil {
+		return nil, err
+	}
+	var run *docker.RunResult
+	if runCtx.Err() == nil {
+		run, err = runner.Run(runCtx, opts)
+	}
+	result := &Result{
+		Image:      spec.Image,
+		Command:    spec.Command,
+		Stdout:     stdout.String(),
+		Stderr:     stderr.String(),
+		DurationMS: time.Since(start).Milliseconds(),
+		Truncated:  stdout.truncated || stderr.truncated,
+	}
+	if run != nil {
+		result.ContainerID = run.ContainerID
+		result.ImageID = run.ImageID
+		result.State = run.State
+		result.ExitCode = run.ExitCode
+		result.OOMKilled = run.OOMKilled
+	}
+
+	if runCtx.Err() != nil {
+		result.ExitCode = -1
+		if ctx.Err() != nil {
+			return result, ctx.Err()
+		}
+		result.TimedOut = true
+		return result, nil
+	}
+	return result, err
+}
+
+func (s *Sandbox) imageAllowed(image string) bool {
+	ref := image
+	if base, _, ok := strings.Cut(ref, "@"); ok {
+		ref = base
+	}
+	if !strings.Contains(path.Base(ref), ":") {
+		ref += ":latest"
+	}
+	for _, pattern := range s.cfg.Images {
+		if ok, _ := path.Match(pattern, ref); ok {
+			return true
+		}
+		if ok, _ := path.Match(pattern, image); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// workspace resolves dir and checks that it is inside Root.
+func (s *Sandbox) workspace(dir string) (string, error) {
+	if dir == "" {
+		return s.cfg.Root, nil
+	}
+	if !filepath.IsAbs(dir) {
+		dir = filepath.Join(s.cfg.Root, dir)
+	}
+	resolved, err := resolveDir(dir)
+	if err != nil {
+		return "", fmt.Errorf("invalid workspace: %w", err)
+	}
+	rel, err := filepath.Rel(s.cfg.Root, resolved)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("workspace %s is outside the sandbox root %s", dir, s.cfg.Root)
+	}
+	if strings.Contains(resolved, ",") {
+		return "", fmt.Errorf("workspace %s contains a comma", resolved)
+	}
+	return resolved, nil
+}
+
+func resolveDir(dir string) (string, error) {
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		return "", err
+	}
+	resolved, err := filepath.EvalSymlinks(abs)
+	if err != nil {
+		return "", err
+	}
+	info, err := os.Stat(resolved)
+	if err != nil {
+		return "", err
+	}
+	if !info.IsDir() {
+		return "", fmt.Errorf("%s is not a directory", dir)
+	}
+	return resolved, nil
+}
+
+func validateArg(arg string) error {
+	if len(arg) > MaxArgLength {
+		return fmt.Errorf("longer than %d bytes", MaxArgLength)
+	}
+	if !utf8.ValidString(arg) {
+		return errors.New("not valid UTF-8")
+	}
+	if strings.ContainsRune(arg, 0) {
+		return errors.New("contains a NUL byte")
+	}
+	return nil
+}
+
+func containerName() (string, error) {
+	buf := make([]byte, 6)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("failed to generate container name: %w", err)
+	}
+	return "juleson-sandbox-" + hex.EncodeToString(buf), nil
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+func listOrNone(values []string) string {
+	if len(values) == 0 {
+		return "none"
+	}
+	return strings.Join(values, ", ")
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// limitedBuffer keeps the first limit bytes written and discards the rest.
+type limitedBuffer struct {
+	bytes.Buffer
+	limit     int
+	truncated bool
+}
+
+func (b *limitedBuffer) Write(p []byte) (int, error) {
+	if room := b.limit - b.Len(); room < len(p) {
+		b.truncated = true
+		if room > 0 {
+			b.Buffer.Write(p[:room])
+		}
+		return len(p), nil
+	}
+	return b.Buffer.Write(p)
+}
@@ -0,0 +1,176 @@
+package sandbox
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/SamyRai/juleson/pkg/docker"
+)
+
+func newTestSandbox(t *testing.T, cfg Config) (*Sandbox, string) {
+	t.Helper()
+	root := t.TempDir()
+	if err := os.Mkdir(filepath.Join(root, "app"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	cfg.Root = root
+	if cfg.Images == nil {
+		cfg.Images = []string{"golang:*", "alpine"}
+	}
+	if cfg.Commands == nil {
+		cfg.Commands = []string{"go", "echo"}
+	}
+	s, err := New(cfg)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	return s, s.Config().Root
+}
+
+func TestValidate(t *testing.T) {
+	s, root := newTestSandbox(t, Config{})
+	outside := t.TempDir()
+
+	tests := []struct {
+		name    string
+		spec    Spec
+		wantErr string
+	}{
+		{"allowed", Spec{Image: "golang:1.23", Command: []string{"go", "test", "./..."}}, ""},
+		{"untagged image matches latest", Spec{Image: "alpine", Command: []string{"echo", "hi"}}, ""},
+		{"relative dir", Spec{Image: "golang:1.23", Command: []string{"go", "vet"}, Dir: "app"}, ""},
+		{"image not allowed", Spec{Image: "ubuntu:24.04", Command: []string{"echo"}}, "not allowed"},
+		{"flag as image", Spec{Image: "--privileged", Command: []string{"echo"}}, "invalid image"},
+		{"command not allowed", Spec{Image: "alpine", Command: []string{"sh", "-c", "id"}}, "not allowed"},
+		{"absolute command path", Spec{Image: "alpine", Command: []string{"/bin/echo"}}, "not allowed"},
+		{"empty command", Spec{Image: "alpine"}, "required"},
+		{"nul byte", Spec{Image: "alpine", Command: []string{"echo", "a\x00b"}}, "NUL"},
+		{"long argument", Spec{Image: "alpine", Command: []string{"echo", strings.Repeat("a", MaxArgLength+1)}}, "longer than"},
+		{"bad env name", Spec{Image: "alpine", Command: []string{"echo"}, Env: map[string]string{"A=B": "c"}}, "environment"},
+		{"network disabled", Spec{Image: "alpine", Command: []string{"echo"}, Network: true}, "network"},
+		{"dir outside root", Spec{Image: "alpine", Command: []string{"echo"}, Dir: outside}, "outside"},
+		{"dir escapes root", Spec{Image: "alpine", Command: []string{"echo"}, Dir: filepath.Join(root, "..")}, "outside"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := s.Validate(tt.spec)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("Validate() error = %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("Validate() error = %v, want containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestRunOptionsApplyLimits(t *testing.T) {
+	s, root := newTestSandbox(t, Config{CPUs: 1.5, MemoryMB: 512, PidsLimit: 64})
+
+	opts, err := s.RunOptions(Spec{
+		Image:   "golang:1.23",
+		Command: []string{"go", "test", "./..."},
+		Env:     map[string]string{"GOFLAGS": "-mod=mod", "CGO_ENABLED": "0"},
+	}, "juleson-sandbox-test")
+	if err != nil {
+		t.Fatalf("RunOptions() error = %v", err)
+	}
+
+	want := docker.RunOptions{
+		Name:       "juleson-sandbox-test",
+		Image:      "golang:1.23",
+		Cmd:        []string{"go", "test", "./..."},
+		Env:        []string{"CGO_ENABLED=0", "GOFLAGS=-mod=mod"},
+		WorkingDir: "/workspace",
+		Mounts:     []docker.Mount{{Source: root, Target: "/workspace", ReadOnly: true}},
+		Resources: docker.Resources{
+			NanoCPUs:    1_500_000_000,
+			MemoryBytes: 512 << 20,
+			PidsLimit:   64,
+		},
+		NetworkDisabled: true,
+		CapDrop:         []string{"ALL"},
+		SecurityOpt:     []string{"no-new-privileges"},
+	}
+	if !reflect.DeepEqual(opts, want) {
+		t.Errorf("RunOptions() =\n%+v\nwant\n%+v", opts, want)
+	}
+}
+
+func TestNewRejectsInvalidConfig(t *testing.T) {
+	for _, cfg := range []Config{
+		{Images: []string{"["}},
+		{Commands: []string{"--rm"}},
+		{Commands: []string{"go test"}},
+		{MemoryMB: -1},
+	} {
+		if _, err := New(cfg); err == nil {
+			t.Errorf("New(%+v) should fail", cfg)
+		}
+	}
+}
+
+type fakeRunner struct {
+	result *docker.RunResult
+	block  bool
+}
+
+func (f *fakeRunner) EnsureImage(context.Context, string, io.Writer) (string, error) {
+	return "sha256:abc", nil
+}
+
+func (f *fakeRunner) Run(ctx context.Context, opts docker.RunOptions) (*docker.RunResult, error) {
+	if f.block {
+		<-ctx.Done()
+		return &docker.RunResult{ContainerID: "c1"}, ctx.Err()
+	}
+	fmt.Fprint(opts.Stdout, "ok")
+	return f.result, nil
+}
+
+func TestRunReportsContainerResult(t *testing.T) {
+	s, _ := newTestSandbox(t, Config{})
+	s.runner = &fakeRunner{result: &docker.RunResult{ContainerID: "c1", ImageID: "sha256:abc", State: "exited", ExitCode: 2}}
+
+	result, err := s.Run(context.Background(), Spec{Image: "alpine", Command: []string{"echo", "hi"}})
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	if result.ExitCode != 2 || result.State != "exited" || result.ImageID != "sha256:abc" || result.Stdout != "ok" {
+		t.Errorf("result = %+v", result)
+	}
+}
+
+func TestRunTimeout(t *testing.T) {
+	s, _ := newTestSandbox(t, Config{Timeout: 50 * time.Millisecond})
+	s.runner = &fakeRunner{block: true}
+
+	result, err := s.Run(context.Background(), Spec{Image: "alpine", Command: []string{"echo", "hi"}})
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	if !result.TimedOut || result.ExitCode != -1 || result.ContainerID != "c1" {
+		t.Errorf("result = %+v, want timed out", result)
+	}
+}
+
+func TestLimitedBufferTruncates(t *testing.T) {
+	b := &limitedBuffer{limit: 4}
+	n, err := b.Write([]byte("abcdef"))
+	if err != nil || n != 6 {
+		t.Fatalf("Write() = %d, %v", n, err)
+	}
+	if b.String() != "abcd" || !b.truncated {
+		t.Errorf("buffer = %q truncated=%v", b.String(), b.truncated)
+	}
+}
@@ -0,0 +1,80 @@
+package secrets
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ChainStore reads from the first store that has a value and writes to the
+// first store that accepts the write, so a keychain can fall back to the
+// encrypted file store.
+type ChainStore struct {
+	stores []Store
+}
+
+// NewChainStore creates a store that tries each backend in order.
+func NewChainStore(stores ...Store) *ChainStore {
+	return &ChainStore{stores: stores}
+}
+
+// Name returns the name of the primary backend.
+func (c *ChainStore) Name() string {
+	if len(c.stores) == 0 {
+		return "none"
+	}
+	return c.stores[0].Name()
+}
+
+// Get returns the first value found across the chained stores.
+func (c *ChainStore) Get(key string) (string, error) {
+	value, _, err := c.Locate(key)
+	return value, err
+}
+
+// Locate returns the value and the store that holds it.
+func (c *ChainStore) Locate(key string) (string, Store, error) {
+	var errs []error
+	for _, store := range c.stores {
+		value, err := store.Get(key)
+		if err == nil {
+			return value, store, nil
+		}
+		if !errors.Is(err, ErrNotFound) {
+			errs = append(errs, fmt.Errorf("%s: %w", store.Name(), err))
+		}
+	}
+	if len(errs) > 0 {
+		return "", nil, errors.Join(append([]error{ErrNotFound}, errs...)...)
+	}
+	return "", nil, ErrNotFound
+}
+
+// Set writes to the first store that succeeds.
+func (c *ChainStore) Set(key, value string) error {
+	_, err := c.SetWithStore(key, value)
+	return err
+}
+
+// SetWithStore writes to the first store that succeeds and returns it.
+func (c *ChainStore) SetWithStore(key, value string) (Store, error) {
+	var errs []error
+	for _, store := range c.stores {
+		if err := store.Set(key, value); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", store.Name(), err))
+			continue
+		}
+		return store, nil
+	}
+	return nil, fmt.Errorf("failed to store %s: %w", Describe(key), errors.Join(errs...))
+}
+
+// Delete removes the key from every chained store.
+func (c *ChainStore) Delete(key string) error {
+	var errs []error
+	for _, store := range c.stores {
+		if err := store.Delete(key); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", store.Name(), err))
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,200 @@
+package secrets
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// ChunkSize is the most plaintext sealed in one chunk of a chunked stream.
+const ChunkSize = 1 << 20
+
+// chunkMagic starts every chunked stream.
+var chunkMagic = []byte("JSC1")
+
+// chunkHeaderSize is the sealed chunk index and final flag before the
+// plaintext of each chunk.
+const chunkHeaderSize = 9
+
+// maxSealedChunk bounds the sealed size of a chunk read back, so a corrupt
+// length does not allocate gigabytes.
+const maxSealedChunk = ChunkSize + chunkHeaderSize + 1024
+
+// ChunkWriter encrypts a stream a chunk at a time, so neither writing nor
+// reading it holds more than a chunk in memory, and an interrupted write
+// can resume after its last complete chunk. Each chunk is a big-endian
+// uint32 length and the sealed chunk index, final flag, and plaintext, so
+// chunks cannot be reordered or dropped unnoticed. Close writes the final
+// chunk.
+type ChunkWriter struct {
+	w      io.Writer
+	sealer Sealer
+	next   uint64
+	buf    []byte
+	closed bool
+}
+
+// NewChunkWriter starts a chunked stream on w.
+func NewChunkWriter(w io.Writer, sealer Sealer) (*ChunkWriter, error) {
+	if _, err := w.Write(chunkMagic); err != nil {
+		return nil, err
+	}
+	return ResumeChunkWriter(w, sealer, 0), nil
+}
+
+// ResumeChunkWriter continues a chunked stream on w after its first chunks,
+// as counted by ChunkReader.Chunks.
+func ResumeChunkWriter(w io.Writer, sealer Sealer, chunks uint64) *ChunkWriter {
+	return &ChunkWriter{w: w, sealer: sealer, next: chunks, buf: make([]byte, 0, ChunkSize)}
+}
+
+// Write buffers p and writes every chunk it fills. A full chunk is only
+// written once more data follows it, since the last one must be final.
+func (cw *ChunkWriter) Write(p []byte) (int, error) {
+	if cw.closed {
+		return 0, errors.New("chunk writer is closed")
+	}
+	written := 0
+	for len(p) > 0 {
+		if len(cw.buf) == ChunkSize {
+			if err := cw.seal(false); err != nil {
+				return written, err
+			}
+		}
+		n := copy(cw.buf[len(cw.buf):ChunkSize], p)
+		cw.buf = cw.buf[:len(cw.buf)+n]
+		p = p[n:]
+		written += n
+	}
+	return written, nil
+}
+
+// Close writes the buffered data as the final chunk. It does not close the
+// underlying writer.
+func (cw *ChunkWriter) Close() error {
+	if cw.closed {
+		return nil
+	}
+	cw.closed = true
+	return cw.seal(true)
+}
+
+func (cw *ChunkWriter) seal(final bool) error {
+	plaintext := make([]byte, chunkHeaderSize, chunkHeaderSize+len(cw.buf))
+	binary.BigEndian.PutUint64(plaintext, cw.next)
+	if final {
+		plaintext[8] = 1
+	}
+	plaintext = append(plaintext, cw.buf...)
+	sealed, err := cw.sealer.Seal(plaintext)
+	if err != nil {
+		return fmt.Errorf("failed to encrypt chunk %d: %w", cw.next, err)
+	}
+	frame := binary.BigEndian.AppendUint32(make([]byte, 0, 4+len(sealed)), uint32(len(sealed)))
+	if _, err := cw.w.Write(append(frame, sealed...)); err != nil {
+		return err
+	}
+	cw.next++
+	cw.buf = cw.buf[:0]
+	return nil
+}
+
+// ErrNotChunked is returned when data is not a chunked stream.
+var ErrNotChunked = errors.New("not a chunked encrypted stream")
+
+// ChunkReader decrypts a stream written by ChunkWriter a chunk at a time.
+type ChunkReader struct {
+	r      io.Reader
+	sealer Sealer
+	chunks uint64
+	offset int64
+	final  bool
+}
+
+// NewChunkReader reads the chunked stream from r.
+func NewChunkReader(r io.Reader, sealer Sealer) *ChunkReader {
+	return &ChunkReader{r: r, sealer: sealer}
+}
+
+// Next returns the plaintext of the next chunk, and io.EOF after the final
+// one. A stream that ends before its final chunk fails with
+// io.ErrUnexpectedEOF.
+func (cr *ChunkReader) Next() ([]byte, error) {
+	if cr.final {
+		return nil, io.EOF
+	}
+	if cr.offset == 0 {
+		magic := make([]byte, len(chunkMagic))
+		if _, err := io.ReadFull(cr.r, magic); err != nil || !bytes.Equal(magic, chunkMagic) {
+			return nil, ErrNotChunked
+		}
+		cr.offset = int64(len(chunkMagic))
+	}
+
+	var length [4]byte
+	if _, err := io.ReadFull(cr.r, length[:]); err != nil {
+		return nil, unexpectedEOF(err)
+	}
+	size := binary.BigEndian.Uint32(length[:])
+	if size > maxSealedChunk {
+		return nil, fmt.Errorf("chunk %d is %d bytes, more than %d", cr.chunks, size, maxSealedChunk)
+	}
+	sealed := make([]byte, size)
+	if _, err := io.ReadFull(cr.r, sealed); err != nil {
+		return nil, unexpectedEOF(err)
+	}
+	plaintext, err := cr.sealer.Open(sealed)
+	if err != nil {
+		return nil, fmt.Errorf("chunk %d: %w", cr.chunks, err)
+	}
+	if len(plaintext) < chunkHeaderSize || binary.BigEndian.Uint64(plaintext) != cr.chunks {
+		return nil, fmt.Errorf("chunk %d is out of order", cr.chunks)
+	}
+
+	cr.final = plaintext[8] == 1
+	cr.chunks++
+	cr.offset += int64(len(length) + len(sealed))
+	return plaintext[chunkHeaderSize:], nil
+}
+
+// Chunks returns how many chunks were read.
+func (cr *ChunkReader) Chunks() uint64 {
+	return cr.chunks
+}
+
+// Offset returns how many bytes of the stream the chunks read take.
+func (cr *ChunkReader) Offset() int64 {
+	return cr.offset
+}
+
+// Final reports whether the final chunk was read.
+func (cr *ChunkReader) Final() bool {
+	return cr.final
+}
+
+func unexpectedEOF(err error) error {
+	if errors.Is(err, io.EOF) {
+		return io.ErrUnexpectedEOF
+	}
+	return err
+}
+
+// OpenStream writes the plaintext of the chunked stream read from r to w.
+// Data that does not start like a chunked stream fails with ErrNotChunked.
+func OpenStream(sealer Sealer, r io.Reader, w io.Writer) error {
+	chunks := NewChunkReader(r, sealer)
+	for {
+		plaintext, err := chunks.Next()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if _, err := w.Write(plaintext); err != nil {
+			return err
+		}
+	}
+}
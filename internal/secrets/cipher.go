@@ -0,0 +1,109 @@
+package secrets
+
+import (
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// EnvEncryptionKey holds a base64 AES-256 key for data at rest, such as
+// one a KMS agent injects. It takes precedence over the stored key.
+const EnvEncryptionKey = "JULESON_ENCRYPTION_KEY"
+
+// KeyEncryption is the store key of the data-at-rest encryption key. It is
+// generated on first use and is not listed by Keys, since it is not an API
+// credential.
+const KeyEncryption = "encryption_key"
+
+// Sealer encrypts and decrypts data at rest. Event journals, snapshots,
+// and downloaded artifacts take one, and Cipher implements it.
+type Sealer interface {
+	Seal(plaintext []byte) ([]byte, error)
+	Open(sealed []byte) ([]byte, error)
+}
+
+// Cipher encrypts data at rest, such as event journals and downloaded
+// artifacts, with AES-256-GCM. Sealed data is the random nonce followed by
+// the ciphertext.
+type Cipher struct {
+	aead cipher.AEAD
+}
+
+// NewCipher creates a cipher from a 32-byte key.
+func NewCipher(key []byte) (*Cipher, error) {
+	aead, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+	return &Cipher{aead: aead}, nil
+}
+
+// Seal encrypts plaintext.
+func (c *Cipher) Seal(plaintext []byte) ([]byte, error) {
+	nonce := make([]byte, c.aead.NonceSize(), c.aead.NonceSize()+len(plaintext)+c.aead.Overhead())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, fmt.Errorf("failed to generate nonce: %w", err)
+	}
+	return c.aead.Seal(nonce, nonce, plaintext, nil), nil
+}
+
+// Open decrypts data sealed by Seal with the same key.
+func (c *Cipher) Open(sealed []byte) ([]byte, error) {
+	if len(sealed) < c.aead.NonceSize() {
+		return nil, errors.New("encrypted data is truncated")
+	}
+	nonce, ciphertext := sealed[:c.aead.NonceSize()], sealed[c.aead.NonceSize():]
+	plaintext, err := c.aead.Open(nil, nonce, ciphertext, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt: wrong key or corrupt data: %w", err)
+	}
+	return plaintext, nil
+}
+
+// EncryptionKey returns the data-at-rest key from EnvEncryptionKey, or
+// from store, where a new key is generated and kept when there is none.
+func EncryptionKey(store Store) ([]byte, error) {
+	if encoded := strings.TrimSpace(os.Getenv(EnvEncryptionKey)); encoded != "" {
+		key, err := decodeEncryptionKey(encoded)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", EnvEncryptionKey, err)
+		}
+		return key, nil
+	}
+
+	encoded, err := store.Get(KeyEncryption)
+	if err == nil {
+		key, err := decodeEncryptionKey(encoded)
+		if err != nil {
+			return nil, fmt.Errorf("stored encryption key: %w", err)
+		}
+		return key, nil
+	}
+	if !errors.Is(err, ErrNotFound) {
+		return nil, fmt.Errorf("failed to read encryption key: %w", err)
+	}
+
+	key := make([]byte, keySize)
+	if _, err := rand.Read(key); err != nil {
+		return nil, fmt.Errorf("failed to generate encryption key: %w", err)
+	}
+	if err := store.Set(KeyEncryption, base64.StdEncoding.EncodeToString(key)); err != nil {
+		return nil, fmt.Errorf("failed to store encryption key: %w", err)
+	}
+	return key, nil
+}
+
+func decodeEncryptionKey(encoded string) ([]byte, error) {
+	key, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, fmt.Errorf("invalid base64: %w", err)
+	}
+	if len(key) != keySize {
+		return nil, fmt.Errorf("key must be %d bytes, got %d", keySize, len(key))
+	}
+	return key, nil
+}
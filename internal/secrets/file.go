@@ -0,0 +1,199 @@
+package secrets
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+const (
+	secretsFileName = "secrets.enc"
+	keyFileName     = "secrets.key"
+	keySize         = 32
+)
+
+// FileStore keeps credentials in an AES-256-GCM encrypted file. The
+// encryption key is stored next to it with owner-only permissions, which keeps
+// secrets out of plaintext config files, backups of juleson.yaml, and shell
+// history.
+type FileStore struct {
+	dir string
+	mu  sync.Mutex
+}
+
+// NewFileStore creates an encrypted file store rooted at dir.
+func NewFileStore(dir string) *FileStore {
+	return &FileStore{dir: dir}
+}
+
+// Name returns the backend name.
+func (s *FileStore) Name() string {
+	return "encrypted file"
+}
+
+// Path returns the location of the encrypted secrets file.
+func (s *FileStore) Path() string {
+	return filepath.Join(s.dir, secretsFileName)
+}
+
+// Get returns a stored value.
+func (s *FileStore) Get(key string) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	values, err := s.read()
+	if err != nil {
+		return "", err
+	}
+	value, ok := values[key]
+	if !ok || value == "" {
+		return "", ErrNotFound
+	}
+	return value, nil
+}
+
+// Set stores or replaces a value.
+func (s *FileStore) Set(key, value string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	values, err := s.read()
+	if err != nil {
+		return err
+	}
+	values[key] = value
+	return s.write(values)
+}
+
+// Delete removes a value.
+func (s *FileStore) Delete(key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	values, err := s.read()
+	if err != nil {
+		return err
+	}
+	if _, ok := values[key]; !ok {
+		return nil
+	}
+	delete(values, key)
+	if len(values) == 0 {
+		if err := os.Remove(s.Path()); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return fmt.Errorf("failed to remove secrets file: %w", err)
+		}
+		return nil
+	}
+	return s.write(values)
+}
+
+func (s *FileStore) read() (map[string]string, error) {
+	values := make(map[string]string)
+
+	ciphertext, err := os.ReadFile(s.Path())
+	if errors.Is(err, fs.ErrNotExist) {
+		return values, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read secrets file: %w", err)
+	}
+
+	key, err := os.ReadFile(filepath.Join(s.dir, keyFileName))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read secrets key: %w", err)
+	}
+
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+	if len(ciphertext) < gcm.NonceSize() {
+		return nil, fmt.Errorf("secrets file is corrupt")
+	}
+	nonce, sealed := ciphertext[:gcm.NonceSize()], ciphertext[gcm.NonceSize():]
+	plaintext, err := gcm.Open(nil, nonce, sealed, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt secrets file: %w", err)
+	}
+
+	if err := json.Unmarshal(plaintext, &values); err != nil {
+		return nil, fmt.Errorf("failed to parse secrets file: %w", err)
+	}
+	return values, nil
+}
+
+func (s *FileStore) write(values map[string]string) error {
+	if err := os.MkdirAll(s.dir, 0o700); err != nil {
+		return fmt.Errorf("failed to create secrets directory: %w", err)
+	}
+
+	key, err := s.loadOrCreateKey()
+	if err != nil {
+		return err
+	}
+	gcm, err := newGCM(key)
+	if err != nil {
+		return err
+	}
+
+	plaintext, err := json.Marshal(values)
+	if err != nil {
+		return fmt.Errorf("failed to encode secrets: %w", err)
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return fmt.Errorf("failed to generate nonce: %w", err)
+	}
+
+	ciphertext := gcm.Seal(nonce, nonce, plaintext, nil)
+	tmp := s.Path() + ".tmp"
+	if err := os.WriteFile(tmp, ciphertext, 0o600); err != nil {
+		return fmt.Errorf("failed to write secrets file: %w", err)
+	}
+	if err := os.Rename(tmp, s.Path()); err != nil {
+		return fmt.Errorf("failed to replace secrets file: %w", err)
+	}
+	return nil
+}
+
+func (s *FileStore) loadOrCreateKey() ([]byte, error) {
+	path := filepath.Join(s.dir, keyFileName)
+	key, err := os.ReadFile(path)
+	if err == nil {
+		return key, nil
+	}
+	if !errors.Is(err, fs.ErrNotExist) {
+		return nil, fmt.Errorf("failed to read secrets key: %w", err)
+	}
+
+	key = make([]byte, keySize)
+	if _, err := rand.Read(key); err != nil {
+		return nil, fmt.Errorf("failed to generate secrets key: %w", err)
+	}
+	if err := os.WriteFile(path, key, 0o600); err != nil {
+		return nil, fmt.Errorf("failed to write secrets key: %w", err)
+	}
+	return key, nil
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	if len(key) != keySize {
+		return nil, fmt.Errorf("secrets key has invalid length %d", len(key))
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize cipher: %w", err)
+	}
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize cipher: %w", err)
+	}
+	return gcm, nil
+}
@@ -0,0 +1,168 @@
+package secrets
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os/exec"
+	"runtime"
+	"strings"
+)
+
+// commandRunner executes a keychain helper and returns its stdout.
+type commandRunner func(stdin string, name string, args ...string) (string, error)
+
+// KeychainStore stores credentials in the operating system keychain through
+// its native command-line tooling: security on macOS, secret-tool
+// (libsecret/Secret Service) on Linux, and the Windows PasswordVault through
+// PowerShell.
+type KeychainStore struct {
+	goos     string
+	run      commandRunner
+	lookPath func(string) (string, error)
+}
+
+// NewKeychainStore creates a keychain store for the current platform.
+func NewKeychainStore() *KeychainStore {
+	return &KeychainStore{
+		goos:     runtime.GOOS,
+		run:      runCommand,
+		lookPath: exec.LookPath,
+	}
+}
+
+// Name returns the backend name.
+func (k *KeychainStore) Name() string {
+	switch k.goos {
+	case "darwin":
+		return "macOS Keychain"
+	case "windows":
+		return "Windows Credential Manager"
+	default:
+		return "Secret Service keyring"
+	}
+}
+
+// Available reports whether the platform keychain tooling is installed.
+func (k *KeychainStore) Available() bool {
+	_, err := k.lookPath(k.tool())
+	return err == nil
+}
+
+func (k *KeychainStore) tool() string {
+	switch k.goos {
+	case "darwin":
+		return "security"
+	case "windows":
+		return "powershell"
+	default:
+		return "secret-tool"
+	}
+}
+
+// Get returns a stored value.
+func (k *KeychainStore) Get(key string) (string, error) {
+	var (
+		out string
+		err error
+	)
+	switch k.goos {
+	case "darwin":
+		out, err = k.run("", "security", "find-generic-password", "-s", ServiceName, "-a", key, "-w")
+	case "windows":
+		out, err = k.run("", "powershell", "-NoProfile", "-NonInteractive", "-Command", windowsVaultScript+
+			fmt.Sprintf("try { $c = $v.Retrieve('%s', '%s'); $c.RetrievePassword(); $c.Password } catch { exit 44 }", ServiceName, key))
+	default:
+		out, err = k.run("", "secret-tool", "lookup", "service", ServiceName, "account", key)
+	}
+	if err != nil {
+		// Every backend signals a missing item with a non-zero exit status.
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) {
+			return "", ErrNotFound
+		}
+		return "", err
+	}
+
+	value := strings.TrimRight(out, "\r\n")
+	if value == "" {
+		return "", ErrNotFound
+	}
+	return value, nil
+}
+
+// Set stores or replaces a value.
+func (k *KeychainStore) Set(key, value string) error {
+	var err error
+	switch k.goos {
+	case "darwin":
+		// security has no stdin mode for passwords; -U updates existing items.
+		_, err = k.run("", "security", "add-generic-password", "-U", "-s", ServiceName, "-a", key, "-w", value)
+	case "windows":
+		_, err = k.run(value, "powershell", "-NoProfile", "-NonInteractive", "-Command", windowsVaultScript+
+			fmt.Sprintf("$p = [Console]::In.ReadLine(); try { $v.Remove($v.Retrieve('%s', '%s')) } catch {}; $v.Add((New-Object Windows.Security.Credentials.PasswordCredential('%s', '%s', $p)))", ServiceName, key, ServiceName, key))
+	default:
+		_, err = k.run(value, "secret-tool", "store", "--label", fmt.Sprintf("Juleson %s", Describe(key)), "service", ServiceName, "account", key)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to store %s in %s: %w", Describe(key), k.Name(), err)
+	}
+	return nil
+}
+
+// Delete removes a value.
+func (k *KeychainStore) Delete(key string) error {
+	if _, err := k.Get(key); errors.Is(err, ErrNotFound) {
+		return nil
+	}
+
+	var err error
+	switch k.goos {
+	case "darwin":
+		_, err = k.run("", "security", "delete-generic-password", "-s", ServiceName, "-a", key)
+	case "windows":
+		_, err = k.run("", "powershell", "-NoProfile", "-NonInteractive", "-Command", windowsVaultScript+
+			fmt.Sprintf("$v.Remove($v.Retrieve('%s', '%s'))", ServiceName, key))
+	default:
+		_, err = k.run("", "secret-tool", "clear", "service", ServiceName, "account", key)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to remove %s from %s: %w", Describe(key), k.Name(), err)
+	}
+	return nil
+}
+
+const windowsVaultScript = "[void][Windows.Security.Credentials.PasswordVault,Windows.Security.Credentials,ContentType=WindowsRuntime]; $v = New-Object Windows.Security.Credentials.PasswordVault; "
+
+func runCommand(stdin string, name string, args ...string) (string, error) {
+	cmd := exec.Command(name, args...)
+	if stdin != "" {
+		cmd.Stdin = strings.NewReader(stdin)
+	}
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		var exitErr *exec.ExitError
+		if msg := strings.TrimSpace(stderr.String()); msg != "" && errors.As(err, &exitErr) {
+			return "", &commandError{exitErr: exitErr, stderr: msg}
+		}
+		return "", err
+	}
+	return stdout.String(), nil
+}
+
+// commandError keeps helper stderr for diagnostics while still unwrapping to
+// the underlying exit error.
+type commandError struct {
+	exitErr *exec.ExitError
+	stderr  string
+}
+
+func (e *commandError) Error() string {
+	return fmt.Sprintf("%v: %s", e.exitErr, e.stderr)
+}
+
+func (e *commandError) Unwrap() error {
+	return e.exitErr
+}
@@ -0,0 +1,107 @@
+// Package secrets stores Juleson API credentials outside of plaintext
+// configuration. Credentials live in the operating system keychain when one is
+// available and fall back to an encrypted file in the user config directory.
+package secrets
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// ServiceName is the keychain service that Juleson credentials are stored under.
+const ServiceName = "juleson"
+
+// Credential keys managed by Juleson.
+const (
+	KeyJulesAPIKey  = "jules_api_key"
+	KeyGitHubToken  = "github_token"
+	KeyGeminiAPIKey = "gemini_api_key"
+)
+
+// ErrNotFound is returned when a credential is not present in a store.
+var ErrNotFound = errors.New("secret not found")
+
+// Store persists credentials by key.
+type Store interface {
+	// Name identifies the backend in user-facing output.
+	Name() string
+	// Get returns the stored value or ErrNotFound.
+	Get(key string) (string, error)
+	// Set stores or replaces a value.
+	Set(key, value string) error
+	// Delete removes a value. Deleting a missing key is not an error.
+	Delete(key string) error
+}
+
+// Keys returns every credential key Juleson manages, in display order.
+func Keys() []string {
+	return []string{KeyJulesAPIKey, KeyGitHubToken, KeyGeminiAPIKey}
+}
+
+// Describe returns a human-readable name for a credential key.
+func Describe(key string) string {
+	switch key {
+	case KeyJulesAPIKey:
+		return "Jules API key"
+	case KeyGitHubToken:
+		return "GitHub token"
+	case KeyGeminiAPIKey:
+		return "Gemini API key"
+	default:
+		return key
+	}
+}
+
+// IsKnownKey reports whether key is managed by Juleson.
+func IsKnownKey(key string) bool {
+	for _, known := range Keys() {
+		if key == known {
+			return true
+		}
+	}
+	return false
+}
+
+// DefaultDir returns the directory used by the encrypted file store.
+func DefaultDir() (string, error) {
+	if dir := os.Getenv("JULESON_SECRETS_DIR"); dir != "" {
+		return dir, nil
+	}
+	configDir, err := os.UserConfigDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to locate user config directory: %w", err)
+	}
+	return filepath.Join(configDir, "juleson"), nil
+}
+
+// Default returns the preferred store: the OS keychain when its tooling is
+// available, otherwise the encrypted file store.
+func Default() (Store, error) {
+	dir, err := DefaultDir()
+	if err != nil {
+		return nil, err
+	}
+	file := NewFileStore(dir)
+
+	keychain := NewKeychainStore()
+	if !keychain.Available() {
+		return file, nil
+	}
+	return NewChainStore(keychain, file), nil
+}
+
+// Lookup reads a credential from the default store. It returns an empty
+// string when the credential is missing or no store is usable.
+func Lookup(key string) string {
+	store, err := Default()
+	if err != nil {
+		return ""
+	}
+	value, err := store.Get(key)
+	if err != nil {
+		return ""
+	}
+	return value
+}
@@ -0,0 +1,203 @@
+package secrets
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFileStoreRoundTrip(t *testing.T) {
+	dir := t.TempDir()
+	store := NewFileStore(dir)
+
+	_, err := store.Get(KeyJulesAPIKey)
+	assert.ErrorIs(t, err, ErrNotFound)
+
+	require.NoError(t, store.Set(KeyJulesAPIKey, "jules-secret"))
+	require.NoError(t, store.Set(KeyGitHubToken, "gh-secret"))
+
+	value, err := NewFileStore(dir).Get(KeyJulesAPIKey)
+	require.NoError(t, err)
+	assert.Equal(t, "jules-secret", value)
+
+	raw, err := os.ReadFile(store.Path())
+	require.NoError(t, err)
+	assert.NotContains(t, string(raw), "jules-secret")
+
+	info, err := os.Stat(filepath.Join(dir, keyFileName))
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0o600), info.Mode().Perm())
+
+	require.NoError(t, store.Delete(KeyJulesAPIKey))
+	_, err = store.Get(KeyJulesAPIKey)
+	assert.ErrorIs(t, err, ErrNotFound)
+
+	require.NoError(t, store.Delete(KeyGitHubToken))
+	_, err = os.Stat(store.Path())
+	assert.True(t, errors.Is(err, os.ErrNotExist))
+}
+
+func TestFileStoreRejectsTamperedFile(t *testing.T) {
+	dir := t.TempDir()
+	store := NewFileStore(dir)
+	require.NoError(t, store.Set(KeyJulesAPIKey, "jules-secret"))
+
+	raw, err := os.ReadFile(store.Path())
+	require.NoError(t, err)
+	raw[len(raw)-1] ^= 0xff
+	require.NoError(t, os.WriteFile(store.Path(), raw, 0o600))
+
+	_, err = store.Get(KeyJulesAPIKey)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "decrypt")
+}
+
+type memoryStore struct {
+	name   string
+	values map[string]string
+	setErr error
+}
+
+func (m *memoryStore) Name() string { return m.name }
+
+func (m *memoryStore) Get(key string) (string, error) {
+	if value, ok := m.values[key]; ok {
+		return value, nil
+	}
+	return "", ErrNotFound
+}
+
+func (m *memoryStore) Set(key, value string) error {
+	if m.setErr != nil {
+		return m.setErr
+	}
+	m.values[key] = value
+	return nil
+}
+
+func (m *memoryStore) Delete(key string) error {
+	delete(m.values, key)
+	return nil
+}
+
+func TestChainStoreFallsBack(t *testing.T) {
+	keychain := &memoryStore{name: "keychain", values: map[string]string{}, setErr: errors.New("locked")}
+	file := &memoryStore{name: "file", values: map[string]string{KeyGitHubToken: "from-file"}}
+	chain := NewChainStore(keychain, file)
+
+	store, err := chain.SetWithStore(KeyJulesAPIKey, "jules-secret")
+	require.NoError(t, err)
+	assert.Equal(t, "file", store.Name())
+
+	value, holder, err := chain.Locate(KeyGitHubToken)
+	require.NoError(t, err)
+	assert.Equal(t, "from-file", value)
+	assert.Equal(t, "file", holder.Name())
+
+	require.NoError(t, chain.Delete(KeyGitHubToken))
+	_, err = chain.Get(KeyGitHubToken)
+	assert.ErrorIs(t, err, ErrNotFound)
+}
+
+func TestKeychainStoreCommands(t *testing.T) {
+	var calls []string
+	store := &KeychainStore{
+		goos: "linux",
+		run: func(stdin string, name string, args ...string) (string, error) {
+			calls = append(calls, stdin+"|"+name+" "+strings.Join(args, " "))
+			return "stored-value\n", nil
+		},
+		lookPath: func(string) (string, error) { return "/usr/bin/secret-tool", nil },
+	}
+
+	assert.True(t, store.Available())
+	require.NoError(t, store.Set(KeyGeminiAPIKey, "gemini-secret"))
+	value, err := store.Get(KeyGeminiAPIKey)
+	require.NoError(t, err)
+	assert.Equal(t, "stored-value", value)
+
+	assert.Equal(t, []string{
+		"gemini-secret|secret-tool store --label Juleson Gemini API key service juleson account gemini_api_key",
+		"|secret-tool lookup service juleson account gemini_api_key",
+	}, calls)
+}
+
+func TestEncryptionKeyAndCipher(t *testing.T) {
+	t.Setenv(EnvEncryptionKey, "")
+	store := NewFileStore(t.TempDir())
+
+	key, err := EncryptionKey(store)
+	require.NoError(t, err)
+	again, err := EncryptionKey(store)
+	require.NoError(t, err)
+	assert.Equal(t, key, again, "the generated key is kept")
+
+	c, err := NewCipher(key)
+	require.NoError(t, err)
+	sealed, err := c.Seal([]byte("diff --git a/.env b/.env"))
+	require.NoError(t, err)
+	assert.NotContains(t, string(sealed), ".env")
+	plaintext, err := c.Open(sealed)
+	require.NoError(t, err)
+	assert.Equal(t, "diff --git a/.env b/.env", string(plaintext))
+
+	t.Setenv(EnvEncryptionKey, "AAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAA=")
+	kms, err := EncryptionKey(store)
+	require.NoError(t, err)
+	other, err := NewCipher(kms)
+	require.NoError(t, err)
+	_, err = other.Open(sealed)
+	assert.Error(t, err, "a different key must not open the data")
+
+	t.Setenv(EnvEncryptionKey, "c2hvcnQ=")
+	_, err = EncryptionKey(store)
+	assert.ErrorContains(t, err, "key must be 32 bytes")
+}
+
+func TestChunkWriterRoundTrip(t *testing.T) {
+	c, err := NewCipher(make([]byte, 32))
+	require.NoError(t, err)
+	content := bytes.Repeat([]byte("0123456789abcdef"), ChunkSize/16*2+1)
+
+	var stream bytes.Buffer
+	writer, err := NewChunkWriter(&stream, c)
+	require.NoError(t, err)
+	_, err = writer.Write(content)
+	require.NoError(t, err)
+	require.NoError(t, writer.Close())
+
+	var plaintext bytes.Buffer
+	require.NoError(t, OpenStream(c, bytes.NewReader(stream.Bytes()), &plaintext))
+	assert.Equal(t, content, plaintext.Bytes())
+
+	// A stream cut after a complete chunk is incomplete, not shorter.
+	reader := NewChunkReader(bytes.NewReader(stream.Bytes()), c)
+	_, err = reader.Next()
+	require.NoError(t, err)
+	truncated := stream.Bytes()[:reader.Offset()]
+	err = OpenStream(c, bytes.NewReader(truncated), io.Discard)
+	assert.ErrorIs(t, err, io.ErrUnexpectedEOF)
+
+	// Resuming after that chunk completes the same stream.
+	resumed := bytes.NewBuffer(append([]byte(nil), truncated...))
+	writer = ResumeChunkWriter(resumed, c, reader.Chunks())
+	_, err = writer.Write(content[ChunkSize:])
+	require.NoError(t, err)
+	require.NoError(t, writer.Close())
+	plaintext.Reset()
+	require.NoError(t, OpenStream(c, resumed, &plaintext))
+	assert.Equal(t, content, plaintext.Bytes())
+
+	// Data sealed whole is not a chunked stream.
+	sealed, err := c.Seal([]byte("whole"))
+	require.NoError(t, err)
+	sealed = append([]byte("JSC0"), sealed[4:]...)
+	assert.ErrorIs(t, OpenStream(c, bytes.NewReader(sealed), io.Discard), ErrNotChunked)
+}
@@ -0,0 +1,356 @@
+// Package selfupdate replaces the running Juleson binary with one from a
+// GitHub release. Downloads are checked against the release's
+// checksums.txt, whose ed25519 signature in checksums.txt.sig is verified
+// when a release public key is known, and the executable is swapped in
+// with a rename so an interrupted update leaves the old binary working.
+package selfupdate
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	ghclient "github.com/SamyRai/juleson/internal/github"
+	"golang.org/x/mod/semver"
+)
+
+// Channel selects which releases an update considers.
+type Channel string
+
+// Release channels.
+const (
+	// ChannelStable considers published releases that are not prereleases.
+	ChannelStable Channel = "stable"
+	// ChannelEdge also considers prereleases.
+	ChannelEdge Channel = "edge"
+)
+
+// ParseChannel parses a channel name.
+func ParseChannel(name string) (Channel, error) {
+	switch channel := Channel(strings.ToLower(strings.TrimSpace(name))); channel {
+	case ChannelStable, ChannelEdge:
+		return channel, nil
+	case "":
+		return ChannelStable, nil
+	default:
+		return "", fmt.Errorf("unknown release channel %q (want stable or edge)", name)
+	}
+}
+
+// Release asset names.
+const (
+	ChecksumsAsset = "checksums.txt"
+	SignatureAsset = "checksums.txt.sig"
+)
+
+// ErrNoRelease means no release on the channel has an asset for this
+// platform.
+var ErrNoRelease = errors.New("no release found")
+
+// ReleaseLister lists a repository's releases, newest first.
+type ReleaseLister interface {
+	ListReleases(ctx context.Context, owner, repo string, limit int) ([]*ghclient.Release, error)
+}
+
+// Updater finds and installs releases of a repository.
+type Updater struct {
+	Releases ReleaseLister
+	Owner    string
+	Repo     string
+	// HTTPClient downloads assets (default http.DefaultClient).
+	HTTPClient *http.Client
+	// PublicKey verifies checksums.txt.sig, and releases without one are
+	// refused. When nil, signatures are not checked.
+	PublicKey ed25519.PublicKey
+	// GOOS and GOARCH select the asset (default the running platform).
+	GOOS   string
+	GOARCH string
+}
+
+// Candidate is the release an update would install.
+type Candidate struct {
+	Release *ghclient.Release
+	// Asset is the archive for this platform.
+	Asset *ghclient.ReleaseAsset
+	// Newer reports whether the release is newer than the current version.
+	Newer bool
+}
+
+// Version returns the candidate's tag.
+func (c *Candidate) Version() string {
+	return c.Release.TagName
+}
+
+// Check returns the newest release on channel with an asset for this
+// platform, and whether it is newer than current. A current version that is
+// not a semantic version, such as "dev", is never older.
+func (u *Updater) Check(ctx context.Context, channel Channel, current string) (*Candidate, error) {
+	releases, err := u.Releases.ListReleases(ctx, u.Owner, u.Repo, 30)
+	if err != nil {
+		return nil, err
+	}
+	name := u.AssetName()
+	for _, release := range releases {
+		if release.Draft || (release.Prerelease && channel != ChannelEdge) || !semver.IsValid(release.TagName) {
+			continue
+		}
+		for _, asset := range release.Assets {
+			if asset.Name == name {
+				newer := semver.IsValid(current) && semver.Compare(release.TagName, current) > 0
+				return &Candidate{Release: release, Asset: asset, Newer: newer}, nil
+			}
+		}
+	}
+	return nil, fmt.Errorf("%w on the %s channel with %s", ErrNoRelease, channel, name)
+}
+
+// AssetName returns the release archive for the platform.
+func (u *Updater) AssetName() string {
+	goos, goarch := u.platform()
+	if goos == "windows" {
+		return fmt.Sprintf("juleson-%s-%s.zip", goos, goarch)
+	}
+	return fmt.Sprintf("juleson-%s-%s.tar.gz", goos, goarch)
+}
+
+func (u *Updater) platform() (string, string) {
+	goos, goarch := u.GOOS, u.GOARCH
+	if goos == "" {
+		goos = runtime.GOOS
+	}
+	if goarch == "" {
+		goarch = runtime.GOARCH
+	}
+	return goos, goarch
+}
+
+// Result describes an installed update.
+type Result struct {
+	Version           string
+	Executable        string
+	SignatureVerified bool
+}
+
+// Apply downloads the candidate, verifies it, and replaces executable with
+// the binary it contains.
+func (u *Updater) Apply(ctx context.Context, candidate *Candidate, executable string) (Result, error) {
+	result := Result{Version: candidate.Version(), Executable: executable}
+
+	checksums, err := u.download(ctx, candidate.Release, ChecksumsAsset)
+	if err != nil {
+		return result, err
+	}
+	if u.PublicKey != nil {
+		// A build that knows the release key never installs an unsigned
+		// release, since stripping the signature would otherwise bypass it.
+		if !hasAsset(candidate.Release, SignatureAsset) {
+			return result, fmt.Errorf("release %s is unsigned: it has no %s, and this build only installs signed releases", candidate.Version(), SignatureAsset)
+		}
+		signature, err := u.download(ctx, candidate.Release, SignatureAsset)
+		if err != nil {
+			return result, err
+		}
+		if err := VerifySignature(u.PublicKey, checksums, signature); err != nil {
+			return result, err
+		}
+		result.SignatureVerified = true
+	}
+	want, err := ChecksumFor(checksums, candidate.Asset.Name)
+	if err != nil {
+		return result, err
+	}
+
+	archive, err := u.download(ctx, candidate.Release, candidate.Asset.Name)
+	if err != nil {
+		return result, err
+	}
+	if got := sha256.Sum256(archive); hex.EncodeToString(got[:]) != want {
+		return result, fmt.Errorf("checksum mismatch for %s: got %x, want %s", candidate.Asset.Name, got, want)
+	}
+
+	binary, err := extractBinary(candidate.Asset.Name, archive)
+	if err != nil {
+		return result, err
+	}
+	if err := replaceExecutable(executable, binary); err != nil {
+		return result, err
+	}
+	return result, nil
+}
+
+// hasAsset reports whether release has an asset called name.
+func hasAsset(release *ghclient.Release, name string) bool {
+	for _, asset := range release.Assets {
+		if asset.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+func (u *Updater) download(ctx context.Context, release *ghclient.Release, name string) ([]byte, error) {
+	var asset *ghclient.ReleaseAsset
+	for _, candidate := range release.Assets {
+		if candidate.Name == name {
+			asset = candidate
+			break
+		}
+	}
+	if asset == nil {
+		return nil, fmt.Errorf("release %s has no %s", release.TagName, name)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, asset.DownloadURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	client := u.HTTPClient
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to download %s: %w", name, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to download %s: %s", name, resp.Status)
+	}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, 512<<20))
+	if err != nil {
+		return nil, fmt.Errorf("failed to download %s: %w", name, err)
+	}
+	return data, nil
+}
+
+// ParsePublicKey decodes a base64 ed25519 public key.
+func ParsePublicKey(encoded string) (ed25519.PublicKey, error) {
+	key, err := base64.StdEncoding.DecodeString(strings.TrimSpace(encoded))
+	if err != nil || len(key) != ed25519.PublicKeySize {
+		return nil, fmt.Errorf("invalid release public key: want %d base64-encoded bytes", ed25519.PublicKeySize)
+	}
+	return ed25519.PublicKey(key), nil
+}
+
+// VerifySignature checks a base64 or raw ed25519 signature of checksums.
+func VerifySignature(key ed25519.PublicKey, checksums, signature []byte) error {
+	if decoded, err := base64.StdEncoding.DecodeString(strings.TrimSpace(string(signature))); err == nil {
+		signature = decoded
+	}
+	if !ed25519.Verify(key, checksums, signature) {
+		return fmt.Errorf("%s signature does not match the release public key", ChecksumsAsset)
+	}
+	return nil
+}
+
+// ChecksumFor returns the SHA-256 listed for name in a sha256sum-format
+// checksums file. Entries may include a directory.
+func ChecksumFor(checksums []byte, name string) (string, error) {
+	scanner := bufio.NewScanner(bytes.NewReader(checksums))
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 2 && path.Base(strings.TrimPrefix(fields[1], "*")) == name {
+			return strings.ToLower(fields[0]), nil
+		}
+	}
+	return "", fmt.Errorf("%s lists no checksum for %s", ChecksumsAsset, name)
+}
+
+// extractBinary returns the juleson executable in a release archive.
+func extractBinary(name string, archive []byte) ([]byte, error) {
+	if strings.HasSuffix(name, ".zip") {
+		reader, err := zip.NewReader(bytes.NewReader(archive), int64(len(archive)))
+		if err != nil {
+			return nil, fmt.Errorf("failed to open %s: %w", name, err)
+		}
+		for _, file := range reader.File {
+			if strings.HasPrefix(path.Base(file.Name), "juleson") && strings.HasSuffix(file.Name, ".exe") {
+				rc, err := file.Open()
+				if err != nil {
+					return nil, err
+				}
+				defer rc.Close()
+				return io.ReadAll(rc)
+			}
+		}
+		return nil, fmt.Errorf("%s contains no juleson executable", name)
+	}
+
+	gz, err := gzip.NewReader(bytes.NewReader(archive))
+	if err != nil {
+		return nil, fmt.Errorf("failed to open %s: %w", name, err)
+	}
+	defer gz.Close()
+	reader := tar.NewReader(gz)
+	for {
+		header, err := reader.Next()
+		if errors.Is(err, io.EOF) {
+			return nil, fmt.Errorf("%s contains no juleson executable", name)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", name, err)
+		}
+		if header.Typeflag == tar.TypeReg && path.Base(header.Name) == "juleson" {
+			return io.ReadAll(reader)
+		}
+	}
+}
+
+// replaceExecutable writes binary next to executable and renames it into
+// place, keeping the file mode. Windows cannot overwrite a running
+// executable, so there the old one is first moved aside to NAME.old.
+func replaceExecutable(executable string, binary []byte) error {
+	info, err := os.Stat(executable)
+	if err != nil {
+		return fmt.Errorf("failed to stat %s: %w", executable, err)
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(executable), "."+filepath.Base(executable)+".new-*")
+	if err != nil {
+		return fmt.Errorf("cannot write next to %s: %w", executable, err)
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(binary); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write the new binary: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write the new binary: %w", err)
+	}
+	if err := os.Chmod(tmp.Name(), info.Mode().Perm()); err != nil {
+		return fmt.Errorf("failed to set permissions on the new binary: %w", err)
+	}
+
+	if runtime.GOOS == "windows" {
+		old := executable + ".old"
+		_ = os.Remove(old)
+		if err := os.Rename(executable, old); err != nil {
+			return fmt.Errorf("failed to move %s aside: %w", executable, err)
+		}
+		if err := os.Rename(tmp.Name(), executable); err != nil {
+			_ = os.Rename(old, executable)
+			return fmt.Errorf("failed to replace %s: %w", executable, err)
+		}
+		return nil
+	}
+	if err := os.Rename(tmp.Name(), executable); err != nil {
+		return fmt.Errorf("failed to replace %s: %w", executable, err)
+	}
+	return nil
+}
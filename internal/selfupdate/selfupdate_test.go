@@ -0,0 +1,237 @@
+package selfupdate
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	ghclient "github.com/SamyRai/juleson/internal/github"
+)
+
+type fakeReleases []*ghclient.Release
+
+func (f fakeReleases) ListReleases(context.Context, string, string, int) ([]*ghclient.Release, error) {
+	return f, nil
+}
+
+// releaseFixture serves one linux/amd64 release whose archive holds binary.
+type releaseFixture struct {
+	server  *httptest.Server
+	assets  map[string][]byte
+	release *ghclient.Release
+	key     ed25519.PrivateKey
+}
+
+func newReleaseFixture(t *testing.T, tag string, binary []byte) *releaseFixture {
+	t.Helper()
+	_, key, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f := &releaseFixture{assets: map[string][]byte{}, key: key}
+	f.server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		data, ok := f.assets[strings.TrimPrefix(r.URL.Path, "/")]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		_, _ = w.Write(data)
+	}))
+	t.Cleanup(f.server.Close)
+
+	archive := tarGz(t, "juleson", binary)
+	sum := sha256.Sum256(archive)
+	checksums := fmt.Sprintf("%x  ./binaries-linux-amd64/juleson-linux-amd64.tar.gz\n", sum)
+	f.assets["juleson-linux-amd64.tar.gz"] = archive
+	f.assets[ChecksumsAsset] = []byte(checksums)
+	f.assets[SignatureAsset] = []byte(base64.StdEncoding.EncodeToString(ed25519.Sign(key, []byte(checksums))))
+
+	f.release = &ghclient.Release{TagName: tag}
+	for name := range f.assets {
+		f.release.Assets = append(f.release.Assets, &ghclient.ReleaseAsset{Name: name, DownloadURL: f.server.URL + "/" + name})
+	}
+	return f
+}
+
+func (f *releaseFixture) updater(releases ...*ghclient.Release) *Updater {
+	return &Updater{
+		Releases:  fakeReleases(releases),
+		PublicKey: f.key.Public().(ed25519.PublicKey),
+		GOOS:      "linux",
+		GOARCH:    "amd64",
+	}
+}
+
+func tarGz(t *testing.T, name string, content []byte) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gz)
+	if err := tw.WriteHeader(&tar.Header{Name: name, Mode: 0o755, Size: int64(len(content)), Typeflag: tar.TypeReg}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := tw.Write(content); err != nil {
+		t.Fatal(err)
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := gz.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestCheckSelectsReleaseByChannel(t *testing.T) {
+	f := newReleaseFixture(t, "v1.3.0-rc.1", []byte("new"))
+	prerelease := f.release
+	prerelease.Prerelease = true
+	stable := &ghclient.Release{TagName: "v1.2.0", Assets: prerelease.Assets}
+	draft := &ghclient.Release{TagName: "v1.4.0", Draft: true, Assets: prerelease.Assets}
+	updater := f.updater(draft, prerelease, stable)
+	ctx := context.Background()
+
+	tests := []struct {
+		channel Channel
+		current string
+		want    string
+		newer   bool
+	}{
+		{channel: ChannelStable, current: "v1.1.0", want: "v1.2.0", newer: true},
+		{channel: ChannelStable, current: "v1.2.0", want: "v1.2.0"},
+		{channel: ChannelEdge, current: "v1.2.0", want: "v1.3.0-rc.1", newer: true},
+		{channel: ChannelStable, current: "dev", want: "v1.2.0"},
+	}
+	for _, tt := range tests {
+		candidate, err := updater.Check(ctx, tt.channel, tt.current)
+		if err != nil {
+			t.Fatalf("Check(%s, %s): %v", tt.channel, tt.current, err)
+		}
+		if candidate.Version() != tt.want || candidate.Newer != tt.newer {
+			t.Errorf("Check(%s, %s) = %s newer=%v, want %s newer=%v", tt.channel, tt.current, candidate.Version(), candidate.Newer, tt.want, tt.newer)
+		}
+	}
+
+	updater.GOOS = "plan9"
+	if _, err := updater.Check(ctx, ChannelStable, "v1.0.0"); !errors.Is(err, ErrNoRelease) {
+		t.Errorf("Check without a platform asset = %v, want ErrNoRelease", err)
+	}
+}
+
+func TestApplyReplacesExecutable(t *testing.T) {
+	f := newReleaseFixture(t, "v1.2.0", []byte("new binary"))
+	updater := f.updater(f.release)
+	ctx := context.Background()
+
+	executable := filepath.Join(t.TempDir(), "jsn")
+	if err := os.WriteFile(executable, []byte("old binary"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	candidate, err := updater.Check(ctx, ChannelStable, "v1.0.0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	result, err := updater.Apply(ctx, candidate, executable)
+	if err != nil {
+		t.Fatalf("Apply: %v", err)
+	}
+	if !result.SignatureVerified || result.Version != "v1.2.0" {
+		t.Errorf("result = %+v", result)
+	}
+	data, err := os.ReadFile(executable)
+	if err != nil || string(data) != "new binary" {
+		t.Fatalf("executable = %q, %v", data, err)
+	}
+	if info, err := os.Stat(executable); err != nil || info.Mode().Perm() != 0o755 {
+		t.Errorf("executable mode = %v, %v", info.Mode(), err)
+	}
+	entries, _ := os.ReadDir(filepath.Dir(executable))
+	if len(entries) != 1 {
+		t.Errorf("temporary files left behind: %v", entries)
+	}
+}
+
+func TestApplyRejectsTamperedReleases(t *testing.T) {
+	ctx := context.Background()
+	tests := []struct {
+		name   string
+		tamper func(f *releaseFixture, u *Updater)
+		want   string
+	}{
+		{
+			name:   "archive",
+			tamper: func(f *releaseFixture, _ *Updater) { f.assets["juleson-linux-amd64.tar.gz"] = []byte("evil") },
+			want:   "checksum mismatch",
+		},
+		{
+			name: "checksums",
+			tamper: func(f *releaseFixture, _ *Updater) {
+				f.assets[ChecksumsAsset] = append(f.assets[ChecksumsAsset], "# edited\n"...)
+			},
+			want: "signature does not match",
+		},
+		{
+			name: "unsigned",
+			tamper: func(f *releaseFixture, _ *Updater) {
+				delete(f.assets, SignatureAsset)
+				f.release.Assets = slices.DeleteFunc(f.release.Assets, func(asset *ghclient.ReleaseAsset) bool {
+					return asset.Name == SignatureAsset
+				})
+			},
+			want: "is unsigned",
+		},
+		{
+			name: "other key",
+			tamper: func(_ *releaseFixture, u *Updater) {
+				u.PublicKey, _, _ = ed25519.GenerateKey(nil)
+			},
+			want: "signature does not match",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newReleaseFixture(t, "v1.2.0", []byte("new binary"))
+			updater := f.updater(f.release)
+			tt.tamper(f, updater)
+
+			executable := filepath.Join(t.TempDir(), "juleson")
+			if err := os.WriteFile(executable, []byte("old binary"), 0o755); err != nil {
+				t.Fatal(err)
+			}
+			candidate, err := updater.Check(ctx, ChannelStable, "v1.0.0")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if _, err := updater.Apply(ctx, candidate, executable); err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Fatalf("Apply error = %v, want %q", err, tt.want)
+			}
+			if data, _ := os.ReadFile(executable); string(data) != "old binary" {
+				t.Errorf("executable was replaced: %q", data)
+			}
+		})
+	}
+}
+
+func TestParseChannel(t *testing.T) {
+	for input, want := range map[string]Channel{"": ChannelStable, "stable": ChannelStable, "EDGE": ChannelEdge} {
+		if got, err := ParseChannel(input); err != nil || got != want {
+			t.Errorf("ParseChannel(%q) = %q, %v", input, got, err)
+		}
+	}
+	if _, err := ParseChannel("nightly"); err == nil {
+		t.Error("ParseChannel accepted nightly")
+	}
+}
@@ -48,7 +48,7 @@ func (c *Container) julesClientLocked() *jules.Client {
 		if c.config.Jules.APIKey == "" {
 			return nil // Return nil to indicate client is not available
 		}
-		c.julesClient = jules.NewClient(c.config.Jules.APIKey, jules.WithBaseURL(c.config.Jules.BaseURL), jules.WithTimeout(c.config.Jules.Timeout), jules.WithRetryAttempts(c.config.Jules.RetryAttempts), jules.WithDebugLog(c.config.Jules.DebugLog), jules.WithLogger(logger.New(logger.Config{Debug: c.config.Jules.DebugLog})))
+		c.julesClient = jules.NewClient(c.config.Jules.APIKey, jules.WithBaseURL(c.config.Jules.BaseURL), jules.WithTimeout(c.config.Jules.Timeout), jules.WithRetryAttempts(c.config.Jules.RetryAttempts), jules.WithDebugLog(c.config.Jules.DebugLog), jules.WithLogger(logger.For(logger.SubsystemJules)))
 
 	}
 
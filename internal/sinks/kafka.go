@@ -0,0 +1,90 @@
+package sinks
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/SamyRai/juleson/internal/events"
+)
+
+// kafkaContentType is the embedded JSON format of the Kafka REST Proxy v2
+// API, served by the Confluent REST Proxy and the Redpanda HTTP Proxy.
+const kafkaContentType = "application/vnd.kafka.json.v2+json"
+
+// KafkaSink produces batches to a Kafka topic through a REST Proxy. Each
+// event is one record, keyed by its session when it has one so a session's
+// events land on one partition in order.
+type KafkaSink struct {
+	url      string
+	username string
+	password string
+	client   *http.Client
+}
+
+// NewKafkaSink creates a sink producing to cfg.Topic through the proxy at
+// cfg.URL.
+func NewKafkaSink(cfg Config) *KafkaSink {
+	return &KafkaSink{
+		url:      strings.TrimSuffix(cfg.URL, "/") + "/topics/" + url.PathEscape(cfg.Topic),
+		username: cfg.Username,
+		password: cfg.Password,
+		client:   &http.Client{Timeout: sendTimeout},
+	}
+}
+
+type kafkaRecord struct {
+	Key   string       `json:"key,omitempty"`
+	Value events.Event `json:"value"`
+}
+
+// Write produces a batch.
+func (s *KafkaSink) Write(ctx context.Context, batch []events.Event) error {
+	records := make([]kafkaRecord, 0, len(batch))
+	for _, event := range batch {
+		records = append(records, kafkaRecord{Key: events.SessionKey(event), Value: event})
+	}
+	payload, err := json.Marshal(map[string][]kafkaRecord{"records": records})
+	if err != nil {
+		return fmt.Errorf("failed to encode events: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.url, bytes.NewReader(payload))
+	if err != nil {
+		return fmt.Errorf("invalid Kafka REST Proxy URL: %w", err)
+	}
+	req.Header.Set("Content-Type", kafkaContentType)
+	req.Header.Set("Accept", "application/vnd.kafka.v2+json")
+	if s.username != "" {
+		req.SetBasicAuth(s.username, s.password)
+	}
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to produce events: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("Kafka REST Proxy returned %s: %s", resp.Status, bytes.TrimSpace(body))
+	}
+	// The proxy reports records it could not produce per offset.
+	var result struct {
+		Offsets []struct {
+			ErrorCode *int   `json:"error_code"`
+			Error     string `json:"error"`
+		} `json:"offsets"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil
+	}
+	for _, offset := range result.Offsets {
+		if offset.ErrorCode != nil {
+			return fmt.Errorf("Kafka REST Proxy rejected a record: %s", offset.Error)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,355 @@
+// Package sinks exports events to external systems — Kafka, HTTPS
+// webhooks, and newline-delimited JSON files — in batches, retrying failed
+// deliveries.
+package sinks
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/url"
+	"path"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/SamyRai/juleson/internal/events"
+)
+
+// Sink types.
+const (
+	TypeKafka   = "kafka"
+	TypeWebhook = "webhook"
+	TypeFile    = "file"
+)
+
+// Defaults for unset batching and retry settings.
+const (
+	DefaultBatchSize     = 100
+	DefaultFlushInterval = 5 * time.Second
+	DefaultMaxRetries    = 3
+	DefaultRetryBackoff  = time.Second
+)
+
+// sendTimeout bounds each delivery attempt.
+const sendTimeout = 30 * time.Second
+
+// Sink writes batches of events to an external system.
+type Sink interface {
+	Write(ctx context.Context, batch []events.Event) error
+}
+
+// Config configures one sink and the events it receives.
+type Config struct {
+	Name string
+	// Type is kafka, webhook, or file.
+	Type string
+	// URL is the Kafka REST Proxy or the webhook endpoint.
+	URL string
+	// Topic is the Kafka topic.
+	Topic    string
+	Username string
+	Password string
+	// Headers are added to webhook requests.
+	Headers map[string]string
+	// Secret signs webhook bodies in the X-Juleson-Signature header.
+	Secret string
+	// Path is the file events are appended to.
+	Path string
+
+	// Topics and Types select the events sent; empty selects every event.
+	// Types are event type patterns, such as "session.*".
+	Topics []string
+	Types  []string
+
+	BatchSize     int
+	FlushInterval time.Duration
+	MaxRetries    int
+	RetryBackoff  time.Duration
+}
+
+// Matches reports whether the sink receives event.
+func (c Config) Matches(event events.Event) bool {
+	if len(c.Topics) > 0 && !slices.Contains(c.Topics, event.Topic) {
+		return false
+	}
+	return len(c.Types) == 0 || slices.ContainsFunc(c.Types, func(pattern string) bool {
+		ok, _ := path.Match(pattern, string(event.Type))
+		return ok
+	})
+}
+
+// ValidateConfig checks that each sink is complete and uniquely named.
+func ValidateConfig(configs []Config) error {
+	var errs []error
+	names := map[string]bool{}
+	for i, cfg := range configs {
+		label := fmt.Sprintf("sinks[%d]", i)
+		if cfg.Name == "" {
+			errs = append(errs, fmt.Errorf("%s: name is required", label))
+		} else if names[cfg.Name] {
+			errs = append(errs, fmt.Errorf("%s: duplicate name %q", label, cfg.Name))
+		}
+		names[cfg.Name] = true
+		switch cfg.Type {
+		case TypeKafka:
+			if err := validateURL(cfg.URL, false); err != nil {
+				errs = append(errs, fmt.Errorf("%s: invalid Kafka REST Proxy URL: %w", label, err))
+			}
+			if cfg.Topic == "" {
+				errs = append(errs, fmt.Errorf("%s: kafka sinks require a topic", label))
+			}
+		case TypeWebhook:
+			if err := validateURL(cfg.URL, true); err != nil {
+				errs = append(errs, fmt.Errorf("%s: invalid webhook URL: %w", label, err))
+			}
+		case TypeFile:
+			if cfg.Path == "" {
+				errs = append(errs, fmt.Errorf("%s: file sinks require a path", label))
+			}
+		default:
+			errs = append(errs, fmt.Errorf("%s: unknown type %q (use kafka, webhook, or file)", label, cfg.Type))
+		}
+		for _, pattern := range cfg.Types {
+			if _, err := path.Match(pattern, ""); err != nil || pattern == "" {
+				errs = append(errs, fmt.Errorf("%s: invalid event pattern %q", label, pattern))
+			}
+		}
+		if cfg.BatchSize < 0 || cfg.FlushInterval < 0 || cfg.MaxRetries < 0 || cfg.RetryBackoff < 0 {
+			errs = append(errs, fmt.Errorf("%s: batching and retry settings must not be negative", label))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// validateURL requires an absolute http or https URL, and https for remote
+// hosts when secure is set.
+func validateURL(raw string, secure bool) error {
+	u, err := url.Parse(raw)
+	if err != nil {
+		return err
+	}
+	if (u.Scheme != "https" && u.Scheme != "http") || u.Host == "" {
+		return fmt.Errorf("%q is not an http or https URL", raw)
+	}
+	if secure && u.Scheme == "http" && !isLoopback(u.Hostname()) {
+		return fmt.Errorf("%q must use https", raw)
+	}
+	return nil
+}
+
+func isLoopback(host string) bool {
+	if host == "localhost" {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
+// New creates the sink a configuration describes.
+func New(cfg Config) (Sink, error) {
+	switch cfg.Type {
+	case TypeKafka:
+		return NewKafkaSink(cfg), nil
+	case TypeWebhook:
+		return NewWebhookSink(cfg), nil
+	case TypeFile:
+		return NewFileSink(cfg.Path), nil
+	default:
+		return nil, fmt.Errorf("unknown sink type %q", cfg.Type)
+	}
+}
+
+// Exporter batches events for each sink. Queued events are written once
+// BatchSize of them are waiting, and at least every FlushInterval; failed
+// batches are retried MaxRetries times with exponential backoff, then
+// logged and dropped.
+type Exporter struct {
+	routes []*route
+	logger *slog.Logger
+	wg     sync.WaitGroup
+	stop   chan struct{}
+	once   sync.Once
+}
+
+type route struct {
+	config Config
+	sink   Sink
+
+	mu      sync.Mutex
+	pending []events.Event
+	// sending serializes writes so batches reach the sink in order.
+	sending sync.Mutex
+	flush   chan struct{}
+}
+
+// NewExporter creates an exporter for the configured sinks.
+func NewExporter(configs []Config, logger *slog.Logger) (*Exporter, error) {
+	sinks := make([]Sink, 0, len(configs))
+	for _, cfg := range configs {
+		sink, err := New(cfg)
+		if err != nil {
+			return nil, fmt.Errorf("sink %s: %w", cfg.Name, err)
+		}
+		sinks = append(sinks, sink)
+	}
+	return NewExporterWithSinks(configs, sinks, logger), nil
+}
+
+// NewExporterWithSinks creates an exporter writing to sinks[i] the events
+// configs[i] selects.
+func NewExporterWithSinks(configs []Config, sinks []Sink, logger *slog.Logger) *Exporter {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	e := &Exporter{logger: logger, stop: make(chan struct{})}
+	for i, cfg := range configs {
+		if cfg.BatchSize == 0 {
+			cfg.BatchSize = DefaultBatchSize
+		}
+		if cfg.FlushInterval == 0 {
+			cfg.FlushInterval = DefaultFlushInterval
+		}
+		if cfg.MaxRetries == 0 {
+			cfg.MaxRetries = DefaultMaxRetries
+		}
+		if cfg.RetryBackoff == 0 {
+			cfg.RetryBackoff = DefaultRetryBackoff
+		}
+		r := &route{config: cfg, sink: sinks[i], flush: make(chan struct{}, 1)}
+		e.routes = append(e.routes, r)
+		e.wg.Add(1)
+		go e.run(r)
+	}
+	return e
+}
+
+// Enabled reports whether any sink is configured.
+func (e *Exporter) Enabled() bool {
+	return e != nil && len(e.routes) > 0
+}
+
+// Export queues event for the sinks that select it.
+func (e *Exporter) Export(event events.Event) {
+	if !e.Enabled() {
+		return
+	}
+	for _, r := range e.routes {
+		if !r.config.Matches(event) {
+			continue
+		}
+		r.mu.Lock()
+		r.pending = append(r.pending, event)
+		full := len(r.pending) >= r.config.BatchSize
+		r.mu.Unlock()
+		if full {
+			select {
+			case r.flush <- struct{}{}:
+			default:
+			}
+		}
+	}
+}
+
+// Flush writes every queued event and returns the errors of batches that
+// could not be delivered.
+func (e *Exporter) Flush(ctx context.Context) error {
+	if !e.Enabled() {
+		return nil
+	}
+	var errs []error
+	for _, r := range e.routes {
+		if err := e.send(ctx, r); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Close stops the exporter after flushing queued events.
+func (e *Exporter) Close(ctx context.Context) error {
+	if !e.Enabled() {
+		return nil
+	}
+	e.once.Do(func() { close(e.stop) })
+	e.wg.Wait()
+	return e.Flush(ctx)
+}
+
+// Subscriber returns an event bus subscriber exporting every event.
+func (e *Exporter) Subscriber() events.Subscriber {
+	return events.Subscriber{
+		ID: "event-sinks",
+		Filter: func(event events.Event) bool {
+			for _, r := range e.routes {
+				if r.config.Matches(event) {
+					return true
+				}
+			}
+			return false
+		},
+		Handler: func(ctx context.Context, event events.Event) error {
+			e.Export(event)
+			return nil
+		},
+	}
+}
+
+// run writes a route's batches when they fill up or their interval passes.
+func (e *Exporter) run(r *route) {
+	defer e.wg.Done()
+	ticker := time.NewTicker(r.config.FlushInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-e.stop:
+			return
+		case <-ticker.C:
+		case <-r.flush:
+		}
+		if err := e.send(context.Background(), r); err != nil {
+			e.logger.Warn("failed to export events", "sink", r.config.Name, "error", err)
+		}
+	}
+}
+
+// send writes a route's queued events in batches of at most BatchSize.
+func (e *Exporter) send(ctx context.Context, r *route) error {
+	r.sending.Lock()
+	defer r.sending.Unlock()
+	for {
+		r.mu.Lock()
+		n := min(len(r.pending), r.config.BatchSize)
+		batch := r.pending[:n:n]
+		r.pending = r.pending[n:]
+		r.mu.Unlock()
+		if len(batch) == 0 {
+			return nil
+		}
+		if err := e.write(ctx, r, batch); err != nil {
+			return fmt.Errorf("sink %s dropped %d event(s): %w", r.config.Name, len(batch), err)
+		}
+	}
+}
+
+// write writes a batch, retrying with exponential backoff.
+func (e *Exporter) write(ctx context.Context, r *route, batch []events.Event) error {
+	backoff := r.config.RetryBackoff
+	for attempt := 0; ; attempt++ {
+		attemptCtx, cancel := context.WithTimeout(ctx, sendTimeout)
+		err := r.sink.Write(attemptCtx, batch)
+		cancel()
+		if err == nil || attempt >= r.config.MaxRetries {
+			return err
+		}
+		e.logger.Debug("retrying event export", "sink", r.config.Name, "attempt", attempt+1, "error", err)
+		select {
+		case <-ctx.Done():
+			return err
+		case <-time.After(backoff):
+		}
+		backoff *= 2
+	}
+}
@@ -0,0 +1,231 @@
+package sinks
+
+import (
+	"bufio"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/SamyRai/juleson/internal/events"
+)
+
+type recordingSink struct {
+	mu      sync.Mutex
+	batches [][]events.Event
+	// failures fail this many writes before succeeding; -1 fails them all.
+	failures int
+	attempts int
+}
+
+func (s *recordingSink) Write(ctx context.Context, batch []events.Event) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.attempts++
+	if s.failures != 0 {
+		if s.failures > 0 {
+			s.failures--
+		}
+		return errors.New("unavailable")
+	}
+	s.batches = append(s.batches, batch)
+	return nil
+}
+
+func (s *recordingSink) batchSizes() []int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	sizes := make([]int, 0, len(s.batches))
+	for _, batch := range s.batches {
+		sizes = append(sizes, len(batch))
+	}
+	return sizes
+}
+
+func sessionEvent(eventType events.EventType, sessionID string) events.Event {
+	return events.NewEvent(eventType, "session", events.SessionEventData{SessionID: sessionID, Title: "Fix tests"}).WithTopic(events.TopicSession)
+}
+
+func TestExporterBatchesSelectedEvents(t *testing.T) {
+	sink := &recordingSink{}
+	exporter := NewExporterWithSinks([]Config{{Name: "sessions", Types: []string{"session.*"}, BatchSize: 2, FlushInterval: time.Hour}}, []Sink{sink}, nil)
+
+	exporter.Export(sessionEvent(events.EventSessionCreated, "1"))
+	exporter.Export(events.NewEvent(events.EventTaskStarted, "run", nil).WithTopic(events.TopicTask))
+	exporter.Export(sessionEvent(events.EventSessionCompleted, "1"))
+	exporter.Export(sessionEvent(events.EventSessionCreated, "2"))
+
+	deadline := time.Now().Add(2 * time.Second)
+	for len(sink.batchSizes()) == 0 && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+	if err := exporter.Close(context.Background()); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+	if sizes := sink.batchSizes(); len(sizes) != 2 || sizes[0] != 2 || sizes[1] != 1 {
+		t.Fatalf("batch sizes = %v, want [2 1]", sizes)
+	}
+}
+
+func TestExporterRetries(t *testing.T) {
+	flaky := &recordingSink{failures: 2}
+	down := &recordingSink{failures: -1}
+	exporter := NewExporterWithSinks([]Config{
+		{Name: "flaky", FlushInterval: time.Hour, RetryBackoff: time.Millisecond},
+		{Name: "down", FlushInterval: time.Hour, MaxRetries: 1, RetryBackoff: time.Millisecond},
+	}, []Sink{flaky, down}, nil)
+	defer exporter.Close(context.Background())
+
+	exporter.Export(sessionEvent(events.EventSessionFailed, "1"))
+	err := exporter.Flush(context.Background())
+	if err == nil || !strings.Contains(err.Error(), "sink down dropped 1 event(s): unavailable") || strings.Contains(err.Error(), "flaky") {
+		t.Fatalf("Flush() error = %v", err)
+	}
+	if flaky.attempts != 3 || len(flaky.batchSizes()) != 1 {
+		t.Errorf("flaky sink attempts = %d, batches = %v", flaky.attempts, flaky.batchSizes())
+	}
+	if down.attempts != 2 {
+		t.Errorf("down sink attempts = %d, want 2", down.attempts)
+	}
+}
+
+func TestWebhookSink(t *testing.T) {
+	var body []byte
+	var header http.Header
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ = io.ReadAll(r.Body)
+		header = r.Header
+	}))
+	defer server.Close()
+
+	sink := NewWebhookSink(Config{URL: server.URL, Headers: map[string]string{"Authorization": "Bearer token"}, Secret: "s3cret"})
+	if err := sink.Write(context.Background(), []events.Event{sessionEvent(events.EventSessionCreated, "1")}); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+	mac := hmac.New(sha256.New, []byte("s3cret"))
+	mac.Write(body)
+	if got, want := header.Get(SignatureHeader), "sha256="+hex.EncodeToString(mac.Sum(nil)); got != want {
+		t.Errorf("signature = %q, want %q", got, want)
+	}
+	if header.Get("Authorization") != "Bearer token" {
+		t.Errorf("Authorization = %q", header.Get("Authorization"))
+	}
+	var payload struct {
+		Events []events.Event `json:"events"`
+	}
+	if err := json.Unmarshal(body, &payload); err != nil || len(payload.Events) != 1 || payload.Events[0].Type != events.EventSessionCreated {
+		t.Fatalf("body = %s, %v", body, err)
+	}
+}
+
+func TestKafkaSink(t *testing.T) {
+	var request *http.Request
+	var body struct {
+		Records []struct {
+			Key   string       `json:"key"`
+			Value events.Event `json:"value"`
+		} `json:"records"`
+	}
+	reject := false
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		request = r
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		if reject {
+			w.Write([]byte(`{"offsets":[{"partition":0,"offset":7},{"error_code":40401,"error":"record too large"}]}`))
+			return
+		}
+		w.Write([]byte(`{"offsets":[{"partition":0,"offset":7},{"partition":1,"offset":3}]}`))
+	}))
+	defer server.Close()
+
+	sink := NewKafkaSink(Config{URL: server.URL + "/", Topic: "juleson-events", Username: "juleson", Password: "pw"})
+	batch := []events.Event{sessionEvent(events.EventSessionCreated, "42"), events.NewEvent(events.EventTaskStarted, "run", nil).WithTopic(events.TopicTask)}
+	if err := sink.Write(context.Background(), batch); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+	if request.URL.Path != "/topics/juleson-events" || request.Header.Get("Content-Type") != kafkaContentType {
+		t.Errorf("request = %s %s", request.URL.Path, request.Header.Get("Content-Type"))
+	}
+	if user, password, ok := request.BasicAuth(); !ok || user != "juleson" || password != "pw" {
+		t.Errorf("basic auth = %q, %q, %v", user, password, ok)
+	}
+	if len(body.Records) != 2 || body.Records[0].Key != "42" || body.Records[1].Key != "" || body.Records[1].Value.Type != events.EventTaskStarted {
+		t.Fatalf("records = %+v", body.Records)
+	}
+
+	reject = true
+	if err := sink.Write(context.Background(), batch); err == nil || !strings.Contains(err.Error(), "record too large") {
+		t.Fatalf("Write() error = %v", err)
+	}
+}
+
+func TestFileSinkAppendsJSONLines(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "export", "events.jsonl")
+	sink := NewFileSink(path)
+	for _, id := range []string{"1", "2"} {
+		if err := sink.Write(context.Background(), []events.Event{sessionEvent(events.EventSessionCreated, id)}); err != nil {
+			t.Fatalf("Write() error = %v", err)
+		}
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+	var sessions []string
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		var event events.Event
+		if err := json.Unmarshal(scanner.Bytes(), &event); err != nil {
+			t.Fatalf("line %q: %v", scanner.Text(), err)
+		}
+		sessions = append(sessions, events.SessionKey(event))
+	}
+	if strings.Join(sessions, ",") != "1,2" {
+		t.Fatalf("sessions = %v", sessions)
+	}
+}
+
+func TestValidateConfig(t *testing.T) {
+	valid := []Config{
+		{Name: "kafka", Type: TypeKafka, URL: "http://kafka-rest:8082", Topic: "juleson"},
+		{Name: "hook", Type: TypeWebhook, URL: "https://hooks.acme.dev/juleson"},
+		{Name: "local", Type: TypeWebhook, URL: "http://localhost:9000"},
+		{Name: "file", Type: TypeFile, Path: "events.jsonl", Types: []string{"session.*"}},
+	}
+	if err := ValidateConfig(valid); err != nil {
+		t.Fatalf("ValidateConfig(valid) error = %v", err)
+	}
+
+	err := ValidateConfig([]Config{
+		{Name: "kafka", Type: TypeKafka, URL: "kafka:9092"},
+		{Name: "kafka", Type: TypeWebhook, URL: "http://hooks.acme.dev"},
+		{Type: "s3", Types: []string{"["}, BatchSize: -1},
+	})
+	for _, want := range []string{
+		"sinks[0]: invalid Kafka REST Proxy URL",
+		"sinks[0]: kafka sinks require a topic",
+		`sinks[1]: duplicate name "kafka"`,
+		`sinks[1]: invalid webhook URL: "http://hooks.acme.dev" must use https`,
+		"sinks[2]: name is required",
+		`sinks[2]: unknown type "s3"`,
+		`sinks[2]: invalid event pattern "["`,
+		"sinks[2]: batching and retry settings must not be negative",
+	} {
+		if err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("ValidateConfig() error = %v, want %q", err, want)
+		}
+	}
+}
@@ -0,0 +1,63 @@
+package sinks
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/SamyRai/juleson/internal/events"
+)
+
+// SignatureHeader carries the HMAC-SHA256 of a webhook body, as
+// "sha256=HEX", when the sink has a secret.
+const SignatureHeader = "X-Juleson-Signature"
+
+// WebhookSink posts batches to an HTTPS endpoint as {"events": [...]}.
+type WebhookSink struct {
+	url     string
+	headers map[string]string
+	secret  string
+	client  *http.Client
+}
+
+// NewWebhookSink creates a sink posting to cfg.URL.
+func NewWebhookSink(cfg Config) *WebhookSink {
+	return &WebhookSink{url: cfg.URL, headers: cfg.Headers, secret: cfg.Secret, client: &http.Client{Timeout: sendTimeout}}
+}
+
+// Write posts a batch.
+func (s *WebhookSink) Write(ctx context.Context, batch []events.Event) error {
+	payload, err := json.Marshal(map[string][]events.Event{"events": batch})
+	if err != nil {
+		return fmt.Errorf("failed to encode events: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.url, bytes.NewReader(payload))
+	if err != nil {
+		return fmt.Errorf("invalid webhook URL: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for name, value := range s.headers {
+		req.Header.Set(name, value)
+	}
+	if s.secret != "" {
+		mac := hmac.New(sha256.New, []byte(s.secret))
+		mac.Write(payload)
+		req.Header.Set(SignatureHeader, "sha256="+hex.EncodeToString(mac.Sum(nil)))
+	}
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to post events: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("webhook returned %s: %s", resp.Status, bytes.TrimSpace(body))
+	}
+	return nil
+}
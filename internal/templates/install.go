@@ -0,0 +1,400 @@
+package templates
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/SamyRai/juleson/pkg/git"
+	"gopkg.in/yaml.v3"
+)
+
+// Installed template packages live under the custom templates path:
+// PackagesDir holds one directory per package and LockFileName pins each to
+// the commit and checksum it was installed at. Custom templates outside
+// PackagesDir override installed ones of the same name.
+const (
+	PackagesDir  = ".packages"
+	LockFileName = "templates.lock.yaml"
+)
+
+// maxIndexBytes caps the size of a template index.
+const maxIndexBytes = 1 << 20
+
+var packageNamePattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]*$`)
+
+// InstalledPackage is a template package recorded in the lock file.
+type InstalledPackage struct {
+	Name   string `yaml:"name"`
+	Source string `yaml:"source"`
+	// Version is the branch or tag requested at install time, if any.
+	Version  string `yaml:"version,omitempty"`
+	Commit   string `yaml:"commit"`
+	Checksum string `yaml:"checksum"`
+	// Templates are the template files, relative to the package directory.
+	Templates   []string  `yaml:"templates"`
+	InstalledAt time.Time `yaml:"installed_at"`
+}
+
+// LockFile records the installed template packages.
+type LockFile struct {
+	Packages []InstalledPackage `yaml:"packages"`
+}
+
+// ReadLockFile reads the lock file under customPath. A missing file is an
+// empty lock file.
+func ReadLockFile(customPath string) (*LockFile, error) {
+	data, err := os.ReadFile(filepath.Join(customPath, LockFileName))
+	if os.IsNotExist(err) {
+		return &LockFile{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read lock file: %w", err)
+	}
+	var lock LockFile
+	if err := yaml.Unmarshal(data, &lock); err != nil {
+		return nil, fmt.Errorf("failed to parse lock file: %w", err)
+	}
+	return &lock, nil
+}
+
+func (l *LockFile) write(customPath string) error {
+	slices.SortFunc(l.Packages, func(a, b InstalledPackage) int { return strings.Compare(a.Name, b.Name) })
+	data, err := yaml.Marshal(l)
+	if err != nil {
+		return fmt.Errorf("failed to marshal lock file: %w", err)
+	}
+	if err := os.WriteFile(filepath.Join(customPath, LockFileName), data, 0600); err != nil {
+		return fmt.Errorf("failed to write lock file: %w", err)
+	}
+	return nil
+}
+
+// Package returns the installed package with the given name.
+func (l *LockFile) Package(name string) (InstalledPackage, bool) {
+	i := slices.IndexFunc(l.Packages, func(p InstalledPackage) bool { return p.Name == name })
+	if i < 0 {
+		return InstalledPackage{}, false
+	}
+	return l.Packages[i], true
+}
+
+// IndexEntry is a package listed in a template index.
+type IndexEntry struct {
+	Name        string `yaml:"name"`
+	Source      string `yaml:"source"`
+	Version     string `yaml:"version,omitempty"`
+	Checksum    string `yaml:"checksum,omitempty"`
+	Description string `yaml:"description,omitempty"`
+}
+
+// Index lists installable template packages by name.
+type Index struct {
+	Packages []IndexEntry `yaml:"packages"`
+}
+
+// LoadIndex reads a template index from an http(s) URL or a local file.
+func LoadIndex(ctx context.Context, location string) (*Index, error) {
+	var data []byte
+	if strings.HasPrefix(location, "https://") || strings.HasPrefix(location, "http://") {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, location, nil)
+		if err != nil {
+			return nil, err
+		}
+		client := &http.Client{Timeout: 30 * time.Second}
+		resp, err := client.Do(req)
+		if err != nil {
+			return nil, fmt.Errorf("failed to fetch template index: %w", err)
+		}
+		defer resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			return nil, fmt.Errorf("failed to fetch template index: %s", resp.Status)
+		}
+		data, err = io.ReadAll(io.LimitReader(resp.Body, maxIndexBytes))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read template index: %w", err)
+		}
+	} else {
+		var err error
+		data, err = os.ReadFile(location)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read template index: %w", err)
+		}
+	}
+	var index Index
+	if err := yaml.Unmarshal(data, &index); err != nil {
+		return nil, fmt.Errorf("failed to parse template index: %w", err)
+	}
+	return &index, nil
+}
+
+// Lookup returns the index entry with the given name.
+func (i *Index) Lookup(name string) (IndexEntry, error) {
+	for _, entry := range i.Packages {
+		if entry.Name == name {
+			return entry, nil
+		}
+	}
+	return IndexEntry{}, fmt.Errorf("package '%s' not found in template index", name)
+}
+
+// ParsePackageSpec splits "source@version" into its parts. The version is
+// optional; an @ before the last slash, as in git@host:org/repo, is part of
+// the source.
+func ParsePackageSpec(spec string) (source, version string) {
+	slash := strings.LastIndex(spec, "/")
+	if at := strings.LastIndex(spec, "@"); at > slash && at > 0 {
+		return spec[:at], spec[at+1:]
+	}
+	return spec, ""
+}
+
+// IsIndexName reports whether a package source is a name to look up in the
+// template index rather than a repository.
+func IsIndexName(source string) bool {
+	return !strings.ContainsAny(source, "/:\\") && source != "." && source != ".."
+}
+
+// RepositoryURL turns a package source such as github.com/org/templates
+// into a URL git can fetch. URLs, scp-style addresses, and local paths are
+// returned unchanged.
+func RepositoryURL(source string) string {
+	if strings.Contains(source, "://") || strings.HasPrefix(source, "git@") ||
+		filepath.IsAbs(source) || strings.HasPrefix(source, ".") {
+		return source
+	}
+	return "https://" + source
+}
+
+// InstallOptions configures Install.
+type InstallOptions struct {
+	// Name is the package name; it defaults to the repository name.
+	Name string
+	// Version is a branch or tag to install; empty means the default branch.
+	Version string
+	// Commit pins the exact commit to install and takes precedence over
+	// Version, which is then only recorded.
+	Commit string
+	// Checksum is the expected "sha256:<hex>" of the package's templates.
+	Checksum string
+}
+
+// Install fetches the templates in a git repository into customPath and
+// records the package in the lock file. Every template in the repository
+// must validate, and when a checksum is given it must match, or nothing is
+// installed. Installing a package again replaces it.
+func Install(ctx context.Context, customPath, source string, options InstallOptions) (*InstalledPackage, error) {
+	if customPath == "" {
+		return nil, fmt.Errorf("custom templates path is not configured")
+	}
+	name := options.Name
+	if name == "" {
+		name = strings.TrimSuffix(filepath.Base(strings.TrimRight(filepath.ToSlash(source), "/")), ".git")
+		if i := strings.LastIndexAny(name, ":/"); i >= 0 {
+			name = name[i+1:]
+		}
+	}
+	if !packageNamePattern.MatchString(name) {
+		return nil, fmt.Errorf("invalid package name %q", name)
+	}
+
+	tmp, err := os.MkdirTemp("", "juleson-templates-")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(tmp)
+
+	checkout := filepath.Join(tmp, "src")
+	rev := options.Commit
+	if rev == "" {
+		rev = options.Version
+	}
+	commit, err := git.FetchRevision(ctx, RepositoryURL(source), checkout, rev)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch %s: %w", source, err)
+	}
+	files, err := findPackageTemplates(checkout)
+	if err != nil {
+		return nil, err
+	}
+	if len(files) == 0 {
+		return nil, fmt.Errorf("no templates found in %s", source)
+	}
+	checksum, err := packageChecksum(checkout, files)
+	if err != nil {
+		return nil, err
+	}
+	if options.Checksum != "" && options.Checksum != checksum {
+		return nil, fmt.Errorf("checksum mismatch for %s: expected %s, got %s", source, options.Checksum, checksum)
+	}
+
+	packagesDir := filepath.Join(customPath, PackagesDir)
+	staging := filepath.Join(packagesDir, "."+name+".tmp")
+	if err := os.RemoveAll(staging); err != nil {
+		return nil, err
+	}
+	for _, file := range files {
+		if err := copyFile(filepath.Join(checkout, file), filepath.Join(staging, file)); err != nil {
+			os.RemoveAll(staging)
+			return nil, err
+		}
+	}
+	dest := filepath.Join(packagesDir, name)
+	if err := os.RemoveAll(dest); err != nil {
+		return nil, err
+	}
+	if err := os.Rename(staging, dest); err != nil {
+		return nil, fmt.Errorf("failed to install package: %w", err)
+	}
+
+	lock, err := ReadLockFile(customPath)
+	if err != nil {
+		return nil, err
+	}
+	pkg := InstalledPackage{
+		Name:        name,
+		Source:      source,
+		Version:     options.Version,
+		Commit:      commit,
+		Checksum:    checksum,
+		Templates:   files,
+		InstalledAt: time.Now().UTC().Truncate(time.Second),
+	}
+	lock.Packages = slices.DeleteFunc(lock.Packages, func(p InstalledPackage) bool { return p.Name == name })
+	lock.Packages = append(lock.Packages, pkg)
+	if err := lock.write(customPath); err != nil {
+		return nil, err
+	}
+	return &pkg, nil
+}
+
+// InstallLocked reinstalls every package in the lock file at its pinned
+// commit, failing if any package's checksum has changed.
+func InstallLocked(ctx context.Context, customPath string) ([]InstalledPackage, error) {
+	lock, err := ReadLockFile(customPath)
+	if err != nil {
+		return nil, err
+	}
+	installed := make([]InstalledPackage, 0, len(lock.Packages))
+	for _, locked := range lock.Packages {
+		pkg, err := Install(ctx, customPath, locked.Source, InstallOptions{
+			Name:     locked.Name,
+			Version:  locked.Version,
+			Commit:   locked.Commit,
+			Checksum: locked.Checksum,
+		})
+		if err != nil {
+			return installed, err
+		}
+		installed = append(installed, *pkg)
+	}
+	return installed, nil
+}
+
+// Uninstall removes an installed package and its lock file entry.
+func Uninstall(customPath, name string) error {
+	lock, err := ReadLockFile(customPath)
+	if err != nil {
+		return err
+	}
+	if _, ok := lock.Package(name); !ok {
+		return fmt.Errorf("package '%s' is not installed", name)
+	}
+	if err := os.RemoveAll(filepath.Join(customPath, PackagesDir, name)); err != nil {
+		return fmt.Errorf("failed to remove package: %w", err)
+	}
+	lock.Packages = slices.DeleteFunc(lock.Packages, func(p InstalledPackage) bool { return p.Name == name })
+	return lock.write(customPath)
+}
+
+// VerifyPackage checks that an installed package's templates still match
+// the checksum in the lock file.
+func VerifyPackage(customPath string, pkg InstalledPackage) error {
+	checksum, err := packageChecksum(filepath.Join(customPath, PackagesDir, pkg.Name), pkg.Templates)
+	if err != nil {
+		return err
+	}
+	if checksum != pkg.Checksum {
+		return fmt.Errorf("package '%s' has been modified: checksum %s, locked %s", pkg.Name, checksum, pkg.Checksum)
+	}
+	return nil
+}
+
+// findPackageTemplates returns the slash-separated paths of the template
+// files in dir. YAML files without metadata.name, such as CI configuration,
+// are not templates; files with one must validate.
+func findPackageTemplates(dir string) ([]string, error) {
+	var files []string
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if path != dir && strings.HasPrefix(d.Name(), ".") {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !d.Type().IsRegular() || !isYAML(path) {
+			return nil
+		}
+		template, err := readTemplateFile(path)
+		if err != nil {
+			return err
+		}
+		if template.Metadata.Name == "" {
+			return nil
+		}
+		if err := validateTemplate(template); err != nil {
+			return fmt.Errorf("template %s: %w", template.Metadata.Name, err)
+		}
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		files = append(files, filepath.ToSlash(rel))
+		return nil
+	})
+	slices.Sort(files)
+	return files, err
+}
+
+// packageChecksum hashes the names and contents of files, in order.
+func packageChecksum(dir string, files []string) (string, error) {
+	hash := sha256.New()
+	for _, file := range files {
+		data, err := os.ReadFile(filepath.Join(dir, filepath.FromSlash(file)))
+		if err != nil {
+			return "", fmt.Errorf("failed to read template file: %w", err)
+		}
+		fmt.Fprintf(hash, "%s\x00%d\x00", file, len(data))
+		hash.Write(data)
+	}
+	return "sha256:" + hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+func copyFile(src, dst string) error {
+	data, err := os.ReadFile(src)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(dst), 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(dst, data, 0600)
+}
+
+func isYAML(path string) bool {
+	ext := strings.ToLower(filepath.Ext(path))
+	return ext == ".yaml" || ext == ".yml"
+}
@@ -0,0 +1,159 @@
+package templates
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const packageTemplate = `metadata:
+  name: %s
+  version: "1.0.0"
+  description: %s
+  category: testing
+tasks:
+  - name: run
+    type: analysis
+    description: Run it
+    jules_prompt: Do the thing.
+`
+
+// newTemplateRepo creates a git repository with one template, tagged v1,
+// and returns its path.
+func newTemplateRepo(t *testing.T) string {
+	t.Helper()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+	dir := filepath.Join(t.TempDir(), "team-templates")
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "testing"), 0o755))
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, ".github"), 0o755))
+	writeTemplate(t, filepath.Join(dir, "testing", "test-generation.yaml"), "test-generation", "Team test generation")
+	require.NoError(t, os.WriteFile(filepath.Join(dir, ".github", "ci.yaml"), []byte("on: push\n"), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "index.yaml"), []byte("packages: []\n"), 0o644))
+	gitRun(t, dir, "init", "--quiet", "--initial-branch=main")
+	gitRun(t, dir, "add", ".")
+	gitRun(t, dir, "-c", "user.email=dev@example.com", "-c", "user.name=Dev", "-c", "commit.gpgsign=false", "commit", "--quiet", "-m", "v1")
+	gitRun(t, dir, "tag", "v1")
+	return dir
+}
+
+func writeTemplate(t *testing.T, path, name, description string) {
+	t.Helper()
+	require.NoError(t, os.WriteFile(path, []byte(fmt.Sprintf(packageTemplate, name, description)), 0o644))
+}
+
+func gitRun(t *testing.T, dir string, args ...string) {
+	t.Helper()
+	out, err := exec.Command("git", append([]string{"-C", dir}, args...)...).CombinedOutput()
+	require.NoError(t, err, string(out))
+}
+
+func TestParsePackageSpec(t *testing.T) {
+	for spec, want := range map[string][2]string{
+		"github.com/org/templates":          {"github.com/org/templates", ""},
+		"github.com/org/templates@v1.2.0":   {"github.com/org/templates", "v1.2.0"},
+		"git@github.com:org/templates.git":  {"git@github.com:org/templates.git", ""},
+		"git@github.com:org/templates@main": {"git@github.com:org/templates", "main"},
+		"go-quality@v2":                     {"go-quality", "v2"},
+	} {
+		source, version := ParsePackageSpec(spec)
+		assert.Equal(t, want, [2]string{source, version}, spec)
+	}
+	assert.True(t, IsIndexName("go-quality"))
+	assert.False(t, IsIndexName("github.com/org/templates"))
+	assert.Equal(t, "https://github.com/org/templates", RepositoryURL("github.com/org/templates"))
+	assert.Equal(t, "/srv/templates", RepositoryURL("/srv/templates"))
+}
+
+func TestInstallPinsAndVerifies(t *testing.T) {
+	repo := newTemplateRepo(t)
+	customPath := t.TempDir()
+	ctx := context.Background()
+
+	pkg, err := Install(ctx, customPath, repo, InstallOptions{Version: "v1"})
+	require.NoError(t, err)
+	assert.Equal(t, "team-templates", pkg.Name)
+	assert.Equal(t, []string{"testing/test-generation.yaml"}, pkg.Templates)
+	assert.Len(t, pkg.Commit, 40)
+
+	lock, err := ReadLockFile(customPath)
+	require.NoError(t, err)
+	locked, ok := lock.Package("team-templates")
+	require.True(t, ok)
+	assert.Equal(t, pkg.Checksum, locked.Checksum)
+	assert.Equal(t, "v1", locked.Version)
+
+	_, err = Install(ctx, customPath, repo, InstallOptions{Name: "other", Checksum: "sha256:00"})
+	assert.ErrorContains(t, err, "checksum mismatch")
+	lock, err = ReadLockFile(customPath)
+	require.NoError(t, err)
+	_, ok = lock.Package("other")
+	assert.False(t, ok)
+
+	// The installed template overrides the builtin of the same name.
+	manager, err := NewManager("../../templates/builtin", customPath, true)
+	require.NoError(t, err)
+	template, err := manager.LoadTemplate("test-generation")
+	require.NoError(t, err)
+	assert.Equal(t, "Team test generation", template.Metadata.Description)
+
+	// A local custom template overrides the installed one.
+	writeTemplate(t, filepath.Join(customPath, "mine.yaml"), "test-generation", "Local test generation")
+	manager, err = NewManager("../../templates/builtin", customPath, true)
+	require.NoError(t, err)
+	template, err = manager.LoadTemplate("test-generation")
+	require.NoError(t, err)
+	assert.Equal(t, "Local test generation", template.Metadata.Description)
+	require.NoError(t, os.Remove(filepath.Join(customPath, "mine.yaml")))
+
+	// Modified package files fail verification and are skipped.
+	installedFile := filepath.Join(customPath, PackagesDir, "team-templates", "testing", "test-generation.yaml")
+	writeTemplate(t, installedFile, "test-generation", "Tampered")
+	assert.ErrorContains(t, VerifyPackage(customPath, locked), "has been modified")
+	manager, err = NewManager("../../templates/builtin", customPath, true)
+	require.NoError(t, err)
+	template, err = manager.LoadTemplate("test-generation")
+	require.NoError(t, err)
+	assert.NotEqual(t, "Tampered", template.Metadata.Description)
+
+	// Restoring from the lock file reinstalls the pinned commit.
+	restored, err := InstallLocked(ctx, customPath)
+	require.NoError(t, err)
+	require.Len(t, restored, 1)
+	assert.Equal(t, locked.Commit, restored[0].Commit)
+	assert.NoError(t, VerifyPackage(customPath, restored[0]))
+
+	require.NoError(t, Uninstall(customPath, "team-templates"))
+	assert.NoDirExists(t, filepath.Join(customPath, PackagesDir, "team-templates"))
+	assert.ErrorContains(t, Uninstall(customPath, "team-templates"), "not installed")
+}
+
+func TestInstallRejectsInvalidTemplates(t *testing.T) {
+	repo := newTemplateRepo(t)
+	require.NoError(t, os.WriteFile(filepath.Join(repo, "broken.yaml"), []byte("metadata:\n  name: broken\n"), 0o644))
+	gitRun(t, repo, "add", ".")
+	gitRun(t, repo, "-c", "user.email=dev@example.com", "-c", "user.name=Dev", "-c", "commit.gpgsign=false", "commit", "--quiet", "-m", "broken")
+
+	_, err := Install(context.Background(), t.TempDir(), repo, InstallOptions{})
+	assert.ErrorContains(t, err, "template broken")
+}
+
+func TestLoadIndex(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "index.yaml")
+	require.NoError(t, os.WriteFile(path, []byte("packages:\n  - name: go-quality\n    source: github.com/org/templates\n    version: v1.2.0\n"), 0o644))
+
+	index, err := LoadIndex(context.Background(), path)
+	require.NoError(t, err)
+	entry, err := index.Lookup("go-quality")
+	require.NoError(t, err)
+	assert.Equal(t, "v1.2.0", entry.Version)
+	_, err = index.Lookup("missing")
+	assert.ErrorContains(t, err, "not found")
+}
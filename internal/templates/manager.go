@@ -2,9 +2,12 @@ package templates
 
 import (
 	"fmt"
+	"log/slog"
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
+	gotemplate "text/template"
 
 	"gopkg.in/yaml.v3"
 )
@@ -27,7 +30,7 @@ func NewManager(templatesDir string, customPath string, enableCustom bool) (*Man
 
 	manager.stores = append(manager.stores, NewEmbeddedStore(templatesDir))
 	if enableCustom && customPath != "" {
-		manager.stores = append(manager.stores, NewCustomStore(customPath))
+		manager.stores = append(manager.stores, NewInstalledStore(customPath), NewCustomStore(customPath))
 	}
 
 	for _, store := range manager.stores {
@@ -36,10 +39,18 @@ func NewManager(templatesDir string, customPath string, enableCustom bool) (*Man
 			if _, ok := store.(*EmbeddedStore); ok {
 				return nil, fmt.Errorf("failed to load registry: %w", err)
 			}
-			fmt.Printf("Warning: failed to load custom templates: %v\n", err)
+			// Stdout may carry the MCP protocol, so warn through the logger.
+			slog.Warn("Failed to load custom templates", "error", err)
 			continue
 		}
-		manager.registry.Templates = append(manager.registry.Templates, reg.Templates...)
+		// Later stores override earlier ones: installed packages replace
+		// builtins, and local custom templates replace both.
+		for _, t := range reg.Templates {
+			manager.registry.Templates = slices.DeleteFunc(manager.registry.Templates, func(existing RegistryTemplate) bool {
+				return existing.Name == t.Name
+			})
+			manager.registry.Templates = append(manager.registry.Templates, t)
+		}
 	}
 
 	return manager, nil
@@ -66,12 +77,9 @@ func (m *Manager) LoadTemplate(name string) (*Template, error) {
 	var template *Template
 	var err error
 
+	builtin := strings.HasPrefix(registryTemplate.File, "builtin/")
 	for _, store := range m.stores {
-		if _, ok := store.(*EmbeddedStore); ok && strings.HasPrefix(registryTemplate.File, "builtin/") {
-			template, err = store.LoadTemplate(registryTemplate.File)
-			break
-		}
-		if _, ok := store.(*CustomStore); ok && !strings.HasPrefix(registryTemplate.File, "builtin/") {
+		if _, embedded := store.(*EmbeddedStore); embedded == builtin {
 			template, err = store.LoadTemplate(registryTemplate.File)
 			break
 		}
@@ -131,6 +139,10 @@ func (m *Manager) SearchTemplates(query string) []RegistryTemplate {
 
 // ValidateTemplate validates a template.
 func (m *Manager) ValidateTemplate(template *Template) error {
+	return validateTemplate(template)
+}
+
+func validateTemplate(template *Template) error {
 	if template.Metadata.Name == "" {
 		return fmt.Errorf("template name is required")
 	}
@@ -153,6 +165,22 @@ func (m *Manager) ValidateTemplate(template *Template) error {
 		if task.JulesPrompt == "" {
 			return fmt.Errorf("task %d: jules_prompt is required", i)
 		}
+		if _, err := gotemplate.New(task.Name).Parse(task.JulesPrompt); err != nil {
+			return fmt.Errorf("task %d: invalid jules_prompt: %w", i, err)
+		}
+	}
+	declared := map[string]bool{}
+	for _, variable := range template.DeclaredVariables {
+		if err := variable.validateDeclaration(); err != nil {
+			return err
+		}
+		if declared[variable.Name] {
+			return fmt.Errorf("duplicate variable %s", variable.Name)
+		}
+		declared[variable.Name] = true
+	}
+	if _, err := template.OrderedTasks(); err != nil {
+		return err
 	}
 	return nil
 }
@@ -2,12 +2,17 @@ package templates
 
 // Template represents a Jules automation template.
 type Template struct {
-	Metadata   TemplateMetadata   `yaml:"metadata"`
-	Config     TemplateConfig     `yaml:"config"`
-	Context    TemplateContext    `yaml:"context"`
-	Tasks      []TemplateTask     `yaml:"tasks"`
-	Validation TemplateValidation `yaml:"validation"`
-	Output     TemplateOutput     `yaml:"output"`
+	Metadata TemplateMetadata `yaml:"metadata"`
+	Config   TemplateConfig   `yaml:"config"`
+	Context  TemplateContext  `yaml:"context"`
+	// DeclaredVariables describes prompt variables; see Variables.
+	DeclaredVariables []TemplateVariable `yaml:"variables,omitempty"`
+	Tasks             []TemplateTask     `yaml:"tasks"`
+	Validation        TemplateValidation `yaml:"validation"`
+	Output            TemplateOutput     `yaml:"output"`
+	// Revisions records how a template saved from a Jules plan was adapted
+	// to each revised plan; see AdaptPlanTemplate.
+	Revisions []PlanRevision `yaml:"revisions,omitempty"`
 }
 
 // TemplateMetadata contains template metadata.
@@ -29,6 +34,18 @@ type TemplateConfig struct {
 	BackupEnabled      bool   `yaml:"backup_enabled"`
 }
 
+// TemplateVariable is a value substituted into task prompts as {{.Name}}.
+// Type is string, int, number, or bool; with Enum set, values must be one
+// of its entries.
+type TemplateVariable struct {
+	Name        string   `yaml:"name"`
+	Description string   `yaml:"description,omitempty"`
+	Type        string   `yaml:"type,omitempty"`
+	Enum        []string `yaml:"enum,omitempty"`
+	Default     string   `yaml:"default,omitempty"`
+	Required    bool     `yaml:"required,omitempty"`
+}
+
 // TemplateContext contains context extraction rules.
 type TemplateContext struct {
 	ProjectAnalysis []string             `yaml:"project_analysis"`
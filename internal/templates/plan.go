@@ -0,0 +1,183 @@
+package templates
+
+import (
+	"fmt"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// PlanStep is one step of a generated plan.
+type PlanStep struct {
+	Title       string
+	Description string
+}
+
+// NewPlanTemplate turns a plan into a template with one task per step, each
+// depending on the step before it, so a plan can be saved, edited, and run
+// later with template run. The template requires plan approval when run.
+func NewPlanTemplate(name, goal string, steps []PlanStep) *Template {
+	template := &Template{
+		Metadata: TemplateMetadata{
+			Name:        name,
+			Version:     "1.0.0",
+			Description: goal,
+			Author:      "Jules plan",
+			Category:    "planning",
+			Tags:        []string{"plan"},
+		},
+		Config: TemplateConfig{
+			Strategy:           "sequential",
+			MaxConcurrentTasks: 1,
+			RequiresApproval:   true,
+		},
+		Output: TemplateOutput{Format: "markdown"},
+	}
+	for i, step := range steps {
+		template.Tasks = append(template.Tasks, planTask(fmt.Sprintf("step_%d", i+1), step))
+	}
+	chainPlanTasks(template.Tasks)
+	return template
+}
+
+func planTask(name string, step PlanStep) TemplateTask {
+	return TemplateTask{
+		Name:        name,
+		Type:        "implementation",
+		Description: step.Title,
+		JulesPrompt: planPrompt(step),
+	}
+}
+
+func planPrompt(step PlanStep) string {
+	return escapeTemplateText(strings.TrimSpace(step.Title + "\n\n" + step.Description))
+}
+
+// chainPlanTasks makes each task depend on the one before it.
+func chainPlanTasks(tasks []TemplateTask) {
+	for i := range tasks {
+		tasks[i].DependsOn = nil
+		if i > 0 {
+			tasks[i].DependsOn = []string{tasks[i-1].Name}
+		}
+	}
+}
+
+// Actions of a PlanAdaptation.
+const (
+	PlanAdaptationAdd    = "add"
+	PlanAdaptationRemove = "remove"
+	PlanAdaptationMove   = "move"
+	PlanAdaptationEdit   = "edit"
+)
+
+// PlanAdaptation is one change AdaptPlanTemplate made to a plan template.
+type PlanAdaptation struct {
+	// Action is add, remove, move, or edit.
+	Action string `yaml:"action" json:"action"`
+	// Task is the name of the task added, removed, moved, or edited.
+	Task string `yaml:"task" json:"task"`
+	// Step is the title of the plan step the task runs.
+	Step string `yaml:"step" json:"step"`
+}
+
+// PlanRevision records the adaptations made to a plan template when Jules
+// revised its plan.
+type PlanRevision struct {
+	Plan         string           `yaml:"plan"`
+	PreviousPlan string           `yaml:"previous_plan,omitempty"`
+	Time         time.Time        `yaml:"time"`
+	Adaptations  []PlanAdaptation `yaml:"adaptations,omitempty"`
+}
+
+// AdaptPlanTemplate changes the tasks of a template made by NewPlanTemplate
+// to follow a revised plan and returns what changed. Tasks are matched to
+// steps by title, ignoring case, so a kept task keeps its name and any
+// edits other than its prompt: steps no longer planned are removed, new
+// steps are added under unused step_N names, kept steps that changed place
+// are moved, and kept steps whose details changed get the new prompt. The
+// tasks are then chained in the revised order again. Jules plans have no
+// priorities and do not say which steps were merged, so a merge shows as
+// the merged steps removed and the result added or edited.
+func AdaptPlanTemplate(template *Template, steps []PlanStep) []PlanAdaptation {
+	key := func(title string) string { return strings.ToLower(strings.TrimSpace(title)) }
+	pending := make(map[string][]int, len(template.Tasks))
+	used := make(map[string]bool, len(template.Tasks))
+	for i, task := range template.Tasks {
+		pending[key(task.Description)] = append(pending[key(task.Description)], i)
+		used[task.Name] = true
+	}
+
+	matched := make([]int, len(steps))
+	kept := make([]bool, len(template.Tasks))
+	for i, step := range steps {
+		matched[i] = -1
+		if candidates := pending[key(step.Title)]; len(candidates) > 0 {
+			matched[i] = candidates[0]
+			kept[candidates[0]] = true
+			pending[key(step.Title)] = candidates[1:]
+		}
+	}
+
+	var adaptations []PlanAdaptation
+	var keptOrder []int
+	for i, task := range template.Tasks {
+		if kept[i] {
+			keptOrder = append(keptOrder, i)
+		} else {
+			adaptations = append(adaptations, PlanAdaptation{Action: PlanAdaptationRemove, Task: task.Name, Step: task.Description})
+		}
+	}
+
+	tasks := make([]TemplateTask, 0, len(steps))
+	next, position := len(template.Tasks)+1, 0
+	for i, step := range steps {
+		if matched[i] < 0 {
+			name := fmt.Sprintf("step_%d", next)
+			for ; used[name]; name = fmt.Sprintf("step_%d", next) {
+				next++
+			}
+			used[name] = true
+			tasks = append(tasks, planTask(name, step))
+			adaptations = append(adaptations, PlanAdaptation{Action: PlanAdaptationAdd, Task: name, Step: step.Title})
+			continue
+		}
+		task := template.Tasks[matched[i]]
+		if keptOrder[position] != matched[i] {
+			adaptations = append(adaptations, PlanAdaptation{Action: PlanAdaptationMove, Task: task.Name, Step: step.Title})
+		}
+		position++
+		if prompt := planPrompt(step); task.JulesPrompt != prompt {
+			task.Description = step.Title
+			task.JulesPrompt = prompt
+			adaptations = append(adaptations, PlanAdaptation{Action: PlanAdaptationEdit, Task: task.Name, Step: step.Title})
+		}
+		tasks = append(tasks, task)
+	}
+	chainPlanTasks(tasks)
+	template.Tasks = tasks
+	return adaptations
+}
+
+// PlanTemplateName derives a template name from a goal, such as
+// "plan-add-retry-logic" for "Add retry logic!".
+func PlanTemplateName(goal string) string {
+	var b strings.Builder
+	b.WriteString("plan")
+	words := strings.FieldsFunc(strings.ToLower(goal), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	for i, word := range words {
+		if i == 6 {
+			break
+		}
+		b.WriteString("-" + word)
+	}
+	return b.String()
+}
+
+// escapeTemplateText quotes template delimiters so plan text renders
+// verbatim.
+func escapeTemplateText(text string) string {
+	return strings.ReplaceAll(text, "{{", `{{"{{"}}`)
+}
@@ -0,0 +1,71 @@
+package templates
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+)
+
+func TestNewPlanTemplate(t *testing.T) {
+	name := PlanTemplateName("Add retry logic to the {{HTTP}} client!")
+	assert.Equal(t, "plan-add-retry-logic-to-the-http", name)
+
+	template := NewPlanTemplate(name, "Add retry logic", []PlanStep{
+		{Title: "Inspect the client", Description: "Find where {{.Requests}} are sent."},
+		{Title: "Add retries"},
+	})
+	require.NoError(t, validateTemplate(template))
+	assert.Equal(t, []string{"step_1"}, template.Tasks[1].DependsOn)
+
+	// The saved template loads back and renders the plan text verbatim.
+	data, err := yaml.Marshal(template)
+	require.NoError(t, err)
+	var loaded Template
+	require.NoError(t, yaml.Unmarshal(data, &loaded))
+	assert.Empty(t, loaded.Variables())
+	prompt, err := loaded.RenderPrompt(nil, "step_1")
+	require.NoError(t, err)
+	assert.Equal(t, "Inspect the client\n\nFind where {{.Requests}} are sent.", prompt)
+}
+
+func TestAdaptPlanTemplate(t *testing.T) {
+	template := NewPlanTemplate("plan-retries", "Add retries", []PlanStep{
+		{Title: "Inspect the client"},
+		{Title: "Add retries"},
+		{Title: "Write tests"},
+	})
+	template.Tasks[0].RequiresApproval = true
+
+	adaptations := AdaptPlanTemplate(template, []PlanStep{
+		{Title: "Write tests"},
+		{Title: "inspect the client", Description: "Start with transport.go."},
+		{Title: "Document retries"},
+	})
+	assert.Equal(t, []PlanAdaptation{
+		{Action: PlanAdaptationRemove, Task: "step_2", Step: "Add retries"},
+		{Action: PlanAdaptationMove, Task: "step_3", Step: "Write tests"},
+		{Action: PlanAdaptationMove, Task: "step_1", Step: "inspect the client"},
+		{Action: PlanAdaptationEdit, Task: "step_1", Step: "inspect the client"},
+		{Action: PlanAdaptationAdd, Task: "step_4", Step: "Document retries"},
+	}, adaptations)
+
+	require.NoError(t, validateTemplate(template))
+	names := make([]string, len(template.Tasks))
+	for i, task := range template.Tasks {
+		names[i] = task.Name
+	}
+	assert.Equal(t, []string{"step_3", "step_1", "step_4"}, names)
+	assert.Empty(t, template.Tasks[0].DependsOn)
+	assert.Equal(t, []string{"step_3"}, template.Tasks[1].DependsOn)
+	assert.Equal(t, []string{"step_1"}, template.Tasks[2].DependsOn)
+	assert.True(t, template.Tasks[1].RequiresApproval, "kept tasks keep their edits")
+	assert.Equal(t, "inspect the client\n\nStart with transport.go.", template.Tasks[1].JulesPrompt)
+
+	assert.Empty(t, AdaptPlanTemplate(template, []PlanStep{
+		{Title: "Write tests"},
+		{Title: "inspect the client", Description: "Start with transport.go."},
+		{Title: "Document retries"},
+	}))
+}
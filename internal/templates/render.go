@@ -0,0 +1,209 @@
+package templates
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+	"text/template"
+)
+
+// DefaultProjectPath fills {{.ProjectPath}} when no value is given; Jules
+// sessions work on a checkout of the source, so this refers to its root.
+const DefaultProjectPath = "the repository root"
+
+// variablePattern finds the variables a prompt refers to.
+var variablePattern = regexp.MustCompile(`\{\{-?\s*\.([A-Za-z_][A-Za-z0-9_]*)\s*-?\}\}`)
+
+// Variables returns every variable the template's prompts refer to, merged
+// with the declared ones and sorted by name. Undeclared variables are
+// optional and default to empty, except ProjectPath.
+func (t *Template) Variables() []TemplateVariable {
+	byName := map[string]TemplateVariable{}
+	for _, task := range t.Tasks {
+		for _, m := range variablePattern.FindAllStringSubmatch(task.JulesPrompt, -1) {
+			byName[m[1]] = TemplateVariable{Name: m[1]}
+		}
+	}
+	if _, ok := byName["ProjectPath"]; ok {
+		byName["ProjectPath"] = TemplateVariable{Name: "ProjectPath", Description: "Path the tasks work on", Default: DefaultProjectPath}
+	}
+	for _, declared := range t.DeclaredVariables {
+		byName[declared.Name] = declared
+	}
+
+	variables := make([]TemplateVariable, 0, len(byName))
+	for _, variable := range byName {
+		variables = append(variables, variable)
+	}
+	slices.SortFunc(variables, func(a, b TemplateVariable) int { return strings.Compare(a.Name, b.Name) })
+	return variables
+}
+
+// ResolveVariables applies defaults to values, checks that every required
+// variable is set, and validates values against their type and enum. Values
+// for unknown variables are an error, which catches typos.
+func (t *Template) ResolveVariables(values map[string]string) (map[string]string, error) {
+	variables := t.Variables()
+	resolved := make(map[string]string, len(variables))
+	for name := range values {
+		if !slices.ContainsFunc(variables, func(v TemplateVariable) bool { return v.Name == name }) {
+			return nil, fmt.Errorf("template '%s' has no variable %s", t.Metadata.Name, name)
+		}
+	}
+	var missing []string
+	for _, variable := range variables {
+		value, ok := values[variable.Name]
+		if !ok || value == "" {
+			value = variable.Default
+		}
+		if value == "" {
+			if variable.Required {
+				variable.Required = false
+				missing = append(missing, variable.Schema())
+			}
+		} else if err := variable.Validate(value); err != nil {
+			return nil, fmt.Errorf("template '%s': %w", t.Metadata.Name, err)
+		}
+		resolved[variable.Name] = value
+	}
+	if len(missing) > 0 {
+		return nil, fmt.Errorf("template '%s' requires %s", t.Metadata.Name, strings.Join(missing, "; "))
+	}
+	return resolved, nil
+}
+
+// OrderedTasks returns the tasks in dependency order, keeping the file order
+// where dependencies allow. It fails on unknown dependencies and cycles.
+func (t *Template) OrderedTasks() ([]TemplateTask, error) {
+	index := make(map[string]int, len(t.Tasks))
+	for i, task := range t.Tasks {
+		if _, ok := index[task.Name]; ok {
+			return nil, fmt.Errorf("duplicate task %s", task.Name)
+		}
+		index[task.Name] = i
+	}
+	for _, task := range t.Tasks {
+		for _, dependency := range task.DependsOn {
+			if _, ok := index[dependency]; !ok {
+				return nil, fmt.Errorf("task %s depends on unknown task %s", task.Name, dependency)
+			}
+		}
+	}
+
+	ordered := make([]TemplateTask, 0, len(t.Tasks))
+	done := make(map[string]bool, len(t.Tasks))
+	for len(ordered) < len(t.Tasks) {
+		progressed := false
+		for _, task := range t.Tasks {
+			if done[task.Name] || !allDone(task.DependsOn, done) {
+				continue
+			}
+			ordered = append(ordered, task)
+			done[task.Name] = true
+			progressed = true
+			break
+		}
+		if !progressed {
+			return nil, fmt.Errorf("task dependencies form a cycle: %s", strings.Join(t.dependencyCycle(done), " -> "))
+		}
+	}
+	return ordered, nil
+}
+
+// dependencyCycle returns a cycle among the tasks not in done, starting and
+// ending with the same task.
+func (t *Template) dependencyCycle(done map[string]bool) []string {
+	dependsOn := make(map[string][]string, len(t.Tasks))
+	for _, task := range t.Tasks {
+		dependsOn[task.Name] = task.DependsOn
+	}
+	// Every remaining task waits on another remaining task, so following
+	// unfinished dependencies must revisit a task.
+	var path []string
+	seen := map[string]int{}
+	name := ""
+	for _, task := range t.Tasks {
+		if !done[task.Name] {
+			name = task.Name
+			break
+		}
+	}
+	for {
+		if i, ok := seen[name]; ok {
+			return append(path[i:], name)
+		}
+		seen[name] = len(path)
+		path = append(path, name)
+		for _, dependency := range dependsOn[name] {
+			if !done[dependency] {
+				name = dependency
+				break
+			}
+		}
+	}
+}
+
+func allDone(names []string, done map[string]bool) bool {
+	for _, name := range names {
+		if !done[name] {
+			return false
+		}
+	}
+	return true
+}
+
+// RenderPrompt renders the prompt for a Jules session. With a task name only
+// that task's prompt is rendered; otherwise every task becomes a phase of one
+// prompt, in dependency order, followed by the post-execution checks.
+func (t *Template) RenderPrompt(values map[string]string, taskName string) (string, error) {
+	variables, err := t.ResolveVariables(values)
+	if err != nil {
+		return "", err
+	}
+	tasks, err := t.OrderedTasks()
+	if err != nil {
+		return "", err
+	}
+	if taskName != "" {
+		i := slices.IndexFunc(tasks, func(task TemplateTask) bool { return task.Name == taskName })
+		if i < 0 {
+			return "", fmt.Errorf("template '%s' has no task %s", t.Metadata.Name, taskName)
+		}
+		return renderTaskPrompt(tasks[i], variables)
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "# %s\n\n", t.Metadata.Description)
+	if len(tasks) > 1 {
+		fmt.Fprintf(&b, "Work through these %d phases in order. Finish each phase before starting the next.\n\n", len(tasks))
+	}
+	for i, task := range tasks {
+		prompt, err := renderTaskPrompt(task, variables)
+		if err != nil {
+			return "", err
+		}
+		fmt.Fprintf(&b, "## Phase %d: %s\n\n", i+1, task.Description)
+		b.WriteString(strings.TrimSpace(prompt))
+		b.WriteString("\n\n")
+	}
+	if len(t.Validation.PostExecution) > 0 {
+		b.WriteString("## Before Finishing\n\nConfirm each of these checks passes:\n")
+		for _, check := range t.Validation.PostExecution {
+			fmt.Fprintf(&b, "- %s\n", strings.ReplaceAll(check, "_", " "))
+		}
+	}
+	return strings.TrimSpace(b.String()) + "\n", nil
+}
+
+func renderTaskPrompt(task TemplateTask, variables map[string]string) (string, error) {
+	parsed, err := template.New(task.Name).Option("missingkey=zero").Parse(task.JulesPrompt)
+	if err != nil {
+		return "", fmt.Errorf("task %s: %w", task.Name, err)
+	}
+	var b strings.Builder
+	if err := parsed.Execute(&b, variables); err != nil {
+		return "", fmt.Errorf("task %s: %w", task.Name, err)
+	}
+	return b.String(), nil
+}
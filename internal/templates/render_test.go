@@ -0,0 +1,69 @@
+package templates
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRenderPrompt(t *testing.T) {
+	template := &Template{
+		Metadata:          TemplateMetadata{Name: "demo", Description: "Demo change"},
+		DeclaredVariables: []TemplateVariable{{Name: "Target", Required: true}, {Name: "Style", Default: "gofmt"}},
+		Tasks: []TemplateTask{
+			{Name: "apply", Description: "Apply it", JulesPrompt: "Apply to {{.Target}} in {{.ProjectPath}} using {{.Style}}.", DependsOn: []string{"plan"}},
+			{Name: "plan", Description: "Plan it", JulesPrompt: "Plan {{.Target}}{{.Extra}}."},
+		},
+		Validation: TemplateValidation{PostExecution: []string{"run_tests"}},
+	}
+
+	names := []string{}
+	for _, variable := range template.Variables() {
+		names = append(names, variable.Name)
+	}
+	assert.Equal(t, []string{"Extra", "ProjectPath", "Style", "Target"}, names)
+
+	_, err := template.RenderPrompt(nil, "")
+	assert.ErrorContains(t, err, "requires Target")
+	_, err = template.RenderPrompt(map[string]string{"Target": "x", "Typo": "y"}, "")
+	assert.ErrorContains(t, err, "no variable Typo")
+
+	prompt, err := template.RenderPrompt(map[string]string{"Target": "pkg/api"}, "")
+	require.NoError(t, err)
+	assert.Equal(t, "# Demo change\n\n"+
+		"Work through these 2 phases in order. Finish each phase before starting the next.\n\n"+
+		"## Phase 1: Plan it\n\nPlan pkg/api.\n\n"+
+		"## Phase 2: Apply it\n\nApply to pkg/api in the repository root using gofmt.\n\n"+
+		"## Before Finishing\n\nConfirm each of these checks passes:\n- run tests\n", prompt)
+
+	prompt, err = template.RenderPrompt(map[string]string{"Target": "pkg/api", "ProjectPath": "svc"}, "apply")
+	require.NoError(t, err)
+	assert.Equal(t, "Apply to pkg/api in svc using gofmt.", prompt)
+}
+
+func TestOrderedTasksRejectsBadDependencies(t *testing.T) {
+	cycle := &Template{Tasks: []TemplateTask{{Name: "a", DependsOn: []string{"b"}}, {Name: "b", DependsOn: []string{"a"}}}}
+	_, err := cycle.OrderedTasks()
+	assert.ErrorContains(t, err, "cycle")
+
+	unknown := &Template{Tasks: []TemplateTask{{Name: "a", DependsOn: []string{"missing"}}}}
+	_, err = unknown.OrderedTasks()
+	assert.ErrorContains(t, err, "unknown task missing")
+}
+
+func TestBuiltinTemplatesRender(t *testing.T) {
+	manager, err := NewManager("../../templates/builtin", "", false)
+	require.NoError(t, err)
+
+	for _, entry := range manager.ListTemplates() {
+		template, err := manager.LoadTemplate(entry.Name)
+		require.NoError(t, err, entry.Name)
+		prompt, err := template.RenderPrompt(nil, "")
+		require.NoError(t, err, entry.Name)
+		assert.NotContains(t, prompt, "{{", entry.Name)
+		assert.NotContains(t, prompt, "<no value>", entry.Name)
+		assert.True(t, strings.HasPrefix(prompt, "# "), entry.Name)
+	}
+}
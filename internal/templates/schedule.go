@@ -0,0 +1,117 @@
+package templates
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// TaskResult is the outcome of one task run by RunTasks.
+type TaskResult struct {
+	Task string
+	// Err is the task's error. Tasks skipped because a dependency failed,
+	// or because the run was cancelled, have ErrTaskSkipped.
+	Err error
+}
+
+// ErrTaskSkipped is the result of tasks that were never started.
+var ErrTaskSkipped = errors.New("skipped")
+
+// Concurrency returns how many independent tasks may run at once: one for
+// the sequential strategy, otherwise max_concurrent_tasks (at least one).
+func (t *Template) Concurrency() int {
+	if t.Config.Strategy == "sequential" || t.Config.MaxConcurrentTasks < 1 {
+		return 1
+	}
+	return t.Config.MaxConcurrentTasks
+}
+
+// RunTasks runs every task with run once the tasks it depends on have
+// succeeded, running up to concurrency independent tasks at a time. After a
+// task fails, tasks that depend on it are skipped while independent ones
+// continue. Results are returned in dependency order; the error joins the
+// failed tasks' errors.
+func (t *Template) RunTasks(ctx context.Context, concurrency int, run func(context.Context, TemplateTask) error) ([]TaskResult, error) {
+	ordered, err := t.OrderedTasks()
+	if err != nil {
+		return nil, err
+	}
+	if concurrency < 1 {
+		concurrency = 1
+	}
+
+	var (
+		mu       sync.Mutex
+		wg       sync.WaitGroup
+		finished = make(chan struct{}, len(ordered))
+		results  = make(map[string]error, len(ordered))
+		running  = make(map[string]bool, len(ordered))
+	)
+	for {
+		mu.Lock()
+		if len(results) == len(ordered) {
+			mu.Unlock()
+			break
+		}
+		for _, task := range ordered {
+			if _, ok := results[task.Name]; ok || running[task.Name] {
+				continue
+			}
+			if skip := ctx.Err() != nil || anyFailed(task.DependsOn, results); skip {
+				results[task.Name] = ErrTaskSkipped
+				continue
+			}
+			if len(running) == concurrency || !allSucceeded(task.DependsOn, results) {
+				continue
+			}
+			running[task.Name] = true
+			wg.Add(1)
+			go func(task TemplateTask) {
+				defer wg.Done()
+				err := run(ctx, task)
+				mu.Lock()
+				delete(running, task.Name)
+				results[task.Name] = err
+				mu.Unlock()
+				finished <- struct{}{}
+			}(task)
+		}
+		waiting := len(running) > 0
+		mu.Unlock()
+		if !waiting {
+			continue
+		}
+		<-finished
+	}
+	wg.Wait()
+
+	var errs []error
+	out := make([]TaskResult, 0, len(ordered))
+	for _, task := range ordered {
+		result := TaskResult{Task: task.Name, Err: results[task.Name]}
+		if result.Err != nil && !errors.Is(result.Err, ErrTaskSkipped) {
+			errs = append(errs, fmt.Errorf("task %s: %w", task.Name, result.Err))
+		}
+		out = append(out, result)
+	}
+	return out, errors.Join(errs...)
+}
+
+func allSucceeded(names []string, results map[string]error) bool {
+	for _, name := range names {
+		if err, ok := results[name]; !ok || err != nil {
+			return false
+		}
+	}
+	return true
+}
+
+func anyFailed(names []string, results map[string]error) bool {
+	for _, name := range names {
+		if err, ok := results[name]; ok && err != nil {
+			return true
+		}
+	}
+	return false
+}
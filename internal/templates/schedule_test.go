@@ -0,0 +1,83 @@
+package templates
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRunTasksHonorsDependencies(t *testing.T) {
+	template := &Template{Tasks: []TemplateTask{
+		{Name: "report", DependsOn: []string{"lint", "test"}},
+		{Name: "lint", DependsOn: []string{"analyze"}},
+		{Name: "test", DependsOn: []string{"analyze"}},
+		{Name: "analyze"},
+	}}
+
+	var (
+		mu       sync.Mutex
+		started  []string
+		inFlight int
+		maxSeen  int
+	)
+	lintAndTest := make(chan struct{})
+	var once sync.Once
+	results, err := template.RunTasks(context.Background(), 2, func(ctx context.Context, task TemplateTask) error {
+		mu.Lock()
+		started = append(started, task.Name)
+		inFlight++
+		maxSeen = max(maxSeen, inFlight)
+		both := task.Name != "analyze" && task.Name != "report" && inFlight == 2
+		mu.Unlock()
+		if both {
+			once.Do(func() { close(lintAndTest) })
+		}
+		if task.Name == "lint" || task.Name == "test" {
+			<-lintAndTest
+		}
+		mu.Lock()
+		inFlight--
+		mu.Unlock()
+		return nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "analyze", started[0])
+	assert.Equal(t, "report", started[3])
+	assert.Equal(t, 2, maxSeen, "independent tasks should run in parallel")
+	assert.Len(t, results, 4)
+}
+
+func TestRunTasksSkipsDependentsOfFailures(t *testing.T) {
+	template := &Template{Tasks: []TemplateTask{
+		{Name: "build"},
+		{Name: "deploy", DependsOn: []string{"build"}},
+		{Name: "docs"},
+	}}
+	results, err := template.RunTasks(context.Background(), 1, func(ctx context.Context, task TemplateTask) error {
+		if task.Name == "build" {
+			return errors.New("compile error")
+		}
+		return nil
+	})
+	assert.ErrorContains(t, err, "task build: compile error")
+	assert.Equal(t, []TaskResult{
+		{Task: "build", Err: errors.New("compile error")},
+		{Task: "deploy", Err: ErrTaskSkipped},
+		{Task: "docs"},
+	}, results)
+}
+
+func TestRunTasksReportsCycles(t *testing.T) {
+	template := &Template{Tasks: []TemplateTask{
+		{Name: "a"},
+		{Name: "b", DependsOn: []string{"a", "d"}},
+		{Name: "c", DependsOn: []string{"b"}},
+		{Name: "d", DependsOn: []string{"c"}},
+	}}
+	_, err := template.RunTasks(context.Background(), 1, func(context.Context, TemplateTask) error { return nil })
+	assert.EqualError(t, err, "task dependencies form a cycle: b -> d -> c -> b")
+}
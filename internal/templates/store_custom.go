@@ -30,7 +30,15 @@ func (s *CustomStore) LoadRegistry() (*Registry, error) {
 		if err != nil {
 			return err
 		}
-		if d.IsDir() || !strings.HasSuffix(strings.ToLower(path), ".yaml") {
+		// Dot directories hold installed packages, which InstalledStore
+		// loads after verifying them.
+		if d.IsDir() {
+			if path != s.customPath && strings.HasPrefix(d.Name(), ".") {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !strings.HasSuffix(strings.ToLower(path), ".yaml") || d.Name() == LockFileName {
 			return nil
 		}
 
@@ -63,6 +71,10 @@ func (s *CustomStore) LoadRegistry() (*Registry, error) {
 }
 
 func (s *CustomStore) LoadTemplate(filePath string) (*Template, error) {
+	return readTemplateFile(filePath)
+}
+
+func readTemplateFile(filePath string) (*Template, error) {
 	data, err := os.ReadFile(filePath)
 	if err != nil {
 		return nil, fmt.Errorf("failed to read custom template file: %w", err)
@@ -0,0 +1,62 @@
+package templates
+
+import (
+	"log/slog"
+	"path/filepath"
+)
+
+// InstalledStore loads the template packages recorded in the lock file under
+// the custom templates path. Packages whose files no longer match their
+// locked checksum are skipped.
+type InstalledStore struct {
+	customPath string
+}
+
+func NewInstalledStore(customPath string) *InstalledStore {
+	return &InstalledStore{customPath: customPath}
+}
+
+func (s *InstalledStore) LoadRegistry() (*Registry, error) {
+	lock, err := ReadLockFile(s.customPath)
+	if err != nil {
+		return nil, err
+	}
+
+	registry := &Registry{}
+	for _, pkg := range lock.Packages {
+		if err := VerifyPackage(s.customPath, pkg); err != nil {
+			slog.Warn("Skipping installed template package", "package", pkg.Name, "error", err)
+			continue
+		}
+		for _, file := range pkg.Templates {
+			path := filepath.Join(s.customPath, PackagesDir, pkg.Name, filepath.FromSlash(file))
+			template, err := s.LoadTemplate(path)
+			if err != nil {
+				slog.Warn("Skipping installed template", "package", pkg.Name, "file", file, "error", err)
+				continue
+			}
+			registry.Templates = append(registry.Templates, RegistryTemplate{
+				Name:         template.Metadata.Name,
+				Version:      template.Metadata.Version,
+				Category:     template.Metadata.Category,
+				Description:  template.Metadata.Description,
+				Author:       template.Metadata.Author,
+				Tags:         template.Metadata.Tags,
+				File:         path,
+				Dependencies: []string{},
+				Compatibility: RegistryCompatibility{
+					Languages:  []string{"all"},
+					Frameworks: []string{"all"},
+				},
+				Features:          []string{"installed", pkg.Name},
+				Complexity:        "custom",
+				EstimatedDuration: "custom",
+			})
+		}
+	}
+	return registry, nil
+}
+
+func (s *InstalledStore) LoadTemplate(filePath string) (*Template, error) {
+	return readTemplateFile(filePath)
+}
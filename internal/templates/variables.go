@@ -0,0 +1,145 @@
+package templates
+
+import (
+	"fmt"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Variable types. Values are always substituted as text; the type only
+// constrains what is accepted.
+const (
+	VariableString = "string"
+	VariableInt    = "int"
+	VariableNumber = "number"
+	VariableBool   = "bool"
+)
+
+// Validate checks a value against the variable's type and enum.
+func (v TemplateVariable) Validate(value string) error {
+	var err error
+	switch v.Type {
+	case "", VariableString:
+	case VariableInt:
+		_, err = strconv.Atoi(value)
+	case VariableNumber:
+		_, err = strconv.ParseFloat(value, 64)
+	case VariableBool:
+		_, err = strconv.ParseBool(value)
+	default:
+		return fmt.Errorf("variable %s has unknown type %q", v.Name, v.Type)
+	}
+	if err != nil {
+		return fmt.Errorf("variable %s must be %s, got %q", v.Name, article(v.Type), value)
+	}
+	if len(v.Enum) > 0 && !slices.Contains(v.Enum, value) {
+		return fmt.Errorf("variable %s must be one of %s, got %q", v.Name, strings.Join(v.Enum, ", "), value)
+	}
+	return nil
+}
+
+// Schema describes the variable for error messages and prompts, such as
+// "Level (required int, one of 1, 2): how deep to go".
+func (v TemplateVariable) Schema() string {
+	var kind []string
+	if v.Required {
+		kind = append(kind, "required")
+	}
+	if v.Type != "" && v.Type != VariableString {
+		kind = append(kind, v.Type)
+	}
+	var constraints []string
+	if len(kind) > 0 {
+		constraints = append(constraints, strings.Join(kind, " "))
+	}
+	if len(v.Enum) > 0 {
+		constraints = append(constraints, "one of "+strings.Join(v.Enum, ", "))
+	}
+
+	schema := v.Name
+	if len(constraints) > 0 {
+		schema += " (" + strings.Join(constraints, ", ") + ")"
+	}
+	if v.Description != "" {
+		schema += ": " + v.Description
+	}
+	return schema
+}
+
+// validateDeclaration checks a declared variable's type, enum, and default.
+func (v TemplateVariable) validateDeclaration() error {
+	if v.Name == "" {
+		return fmt.Errorf("variable name is required")
+	}
+	switch v.Type {
+	case "", VariableString, VariableInt, VariableNumber, VariableBool:
+	default:
+		return fmt.Errorf("variable %s has unknown type %q", v.Name, v.Type)
+	}
+	for _, option := range v.Enum {
+		if err := (TemplateVariable{Name: v.Name, Type: v.Type}).Validate(option); err != nil {
+			return fmt.Errorf("enum: %w", err)
+		}
+	}
+	if v.Default != "" {
+		if err := v.Validate(v.Default); err != nil {
+			return fmt.Errorf("default: %w", err)
+		}
+	}
+	return nil
+}
+
+// MissingVariables returns the required variables that values and the
+// defaults leave empty, so callers can ask for them before rendering.
+func (t *Template) MissingVariables(values map[string]string) []TemplateVariable {
+	var missing []TemplateVariable
+	for _, variable := range t.Variables() {
+		if variable.Required && values[variable.Name] == "" && variable.Default == "" {
+			missing = append(missing, variable)
+		}
+	}
+	return missing
+}
+
+// LoadVariableFile reads variable values from a YAML mapping of names to
+// scalars. Numbers and booleans are converted to their text form.
+func LoadVariableFile(path string) (map[string]string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read variable file: %w", err)
+	}
+	var raw map[string]yaml.Node
+	if err := yaml.Unmarshal(data, &raw); err != nil {
+		return nil, fmt.Errorf("failed to parse variable file %s: %w", path, err)
+	}
+	values := make(map[string]string, len(raw))
+	for name, node := range raw {
+		switch node.Kind {
+		case yaml.ScalarNode:
+			if node.Tag == "!!null" {
+				values[name] = ""
+			} else {
+				values[name] = node.Value
+			}
+		default:
+			return nil, fmt.Errorf("variable file %s: %s must be a single value", path, name)
+		}
+	}
+	return values, nil
+}
+
+func article(typ string) string {
+	switch typ {
+	case VariableInt:
+		return "an integer"
+	case VariableNumber:
+		return "a number"
+	case VariableBool:
+		return "true or false"
+	}
+	return typ
+}
@@ -0,0 +1,79 @@
+package templates
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVariableValidate(t *testing.T) {
+	level := TemplateVariable{Name: "Level", Type: VariableInt, Enum: []string{"1", "2"}}
+	assert.NoError(t, level.Validate("2"))
+	assert.ErrorContains(t, level.Validate("two"), "must be an integer")
+	assert.ErrorContains(t, level.Validate("3"), "must be one of 1, 2")
+	assert.ErrorContains(t, TemplateVariable{Name: "Strict", Type: VariableBool}.Validate("maybe"), "true or false")
+	assert.NoError(t, TemplateVariable{Name: "Ratio", Type: VariableNumber}.Validate("0.5"))
+
+	level.Required = true
+	level.Description = "how deep to go"
+	assert.Equal(t, "Level (required int, one of 1, 2): how deep to go", level.Schema())
+	assert.Equal(t, "Name", TemplateVariable{Name: "Name"}.Schema())
+}
+
+func TestResolveVariablesChecksSchema(t *testing.T) {
+	template := &Template{
+		Metadata: TemplateMetadata{Name: "demo"},
+		DeclaredVariables: []TemplateVariable{
+			{Name: "Level", Type: VariableInt, Required: true, Description: "how deep to go"},
+			{Name: "Mode", Enum: []string{"fast", "thorough"}, Default: "fast"},
+		},
+		Tasks: []TemplateTask{{Name: "run", JulesPrompt: "{{.Level}} {{.Mode}}"}},
+	}
+
+	missing := template.MissingVariables(map[string]string{"Mode": "thorough"})
+	require.Len(t, missing, 1)
+	assert.Equal(t, "Level", missing[0].Name)
+	assert.Empty(t, template.MissingVariables(map[string]string{"Level": "3"}))
+
+	_, err := template.ResolveVariables(nil)
+	assert.EqualError(t, err, "template 'demo' requires Level (int): how deep to go")
+	_, err = template.ResolveVariables(map[string]string{"Level": "3", "Mode": "slow"})
+	assert.ErrorContains(t, err, "Mode must be one of fast, thorough")
+
+	resolved, err := template.ResolveVariables(map[string]string{"Level": "3"})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"Level": "3", "Mode": "fast"}, resolved)
+}
+
+func TestValidateTemplateChecksVariableDeclarations(t *testing.T) {
+	base := Template{
+		Metadata: TemplateMetadata{Name: "demo", Version: "1.0.0", Category: "testing"},
+		Tasks:    []TemplateTask{{Name: "run", Type: "analysis", JulesPrompt: "Go."}},
+	}
+	for name, variables := range map[string][]TemplateVariable{
+		"unknown type":       {{Name: "A", Type: "list"}},
+		"must be an integer": {{Name: "A", Type: VariableInt, Enum: []string{"x"}}},
+		"must be one of":     {{Name: "A", Enum: []string{"x"}, Default: "y"}},
+		"duplicate variable": {{Name: "A"}, {Name: "A"}},
+	} {
+		template := base
+		template.DeclaredVariables = variables
+		assert.ErrorContains(t, validateTemplate(&template), name)
+	}
+}
+
+func TestLoadVariableFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "vars.yaml")
+	require.NoError(t, os.WriteFile(path, []byte("Target: pkg/api\nLevel: 2\nStrict: true\nEmpty:\n"), 0o644))
+
+	values, err := LoadVariableFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"Target": "pkg/api", "Level": "2", "Strict": "true", "Empty": ""}, values)
+
+	require.NoError(t, os.WriteFile(path, []byte("Targets:\n  - a\n"), 0o644))
+	_, err = LoadVariableFile(path)
+	assert.ErrorContains(t, err, "Targets must be a single value")
+}
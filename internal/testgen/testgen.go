@@ -0,0 +1,234 @@
+// Package testgen raises the test coverage of a Go module by having Jules
+// write tests for its least covered packages, one session per package,
+// measuring again after each round until a target is reached or the budget
+// is spent.
+package testgen
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/SamyRai/juleson/internal/intelligence"
+)
+
+// Defaults used when Options leaves a field at zero.
+const (
+	DefaultMaxSessions   = 10
+	DefaultMaxRounds     = 5
+	DefaultParallel      = 2
+	DefaultMinStatements = 10
+)
+
+// maxAttemptsPerPackage bounds the sessions spent on one package, so a
+// package Jules cannot test does not take the whole budget.
+const maxAttemptsPerPackage = 2
+
+// Stop reasons reported in Result.Stopped.
+const (
+	StopTargetReached = "target reached"
+	StopBudgetSpent   = "session budget spent"
+	StopRoundLimit    = "round limit reached"
+	StopNoCandidates  = "no packages left to improve"
+)
+
+// ErrNoChanges reports that a session finished without changes to apply.
+var ErrNoChanges = errors.New("session produced no changes")
+
+// Options configures a run.
+type Options struct {
+	// Target is the module coverage to reach, from 0 to 1.
+	Target float64
+	// MaxSessions bounds the Jules sessions created across all rounds.
+	MaxSessions int
+	MaxRounds   int
+	// Parallel is the number of packages worked on in each round.
+	Parallel int
+	// MinStatements skips packages with fewer statements, whose tests would
+	// barely move the total. A negative value skips none.
+	MinStatements int
+}
+
+// Measure returns the current coverage of the module.
+type Measure func(ctx context.Context) (*intelligence.CoverageReport, error)
+
+// WriteTests has Jules write tests for a package and applies them when they
+// pass locally. It reports the session it created, if any, in the attempt.
+type WriteTests func(ctx context.Context, pkg intelligence.PackageCoverage, target float64) Attempt
+
+// Attempt is one session writing tests for a package.
+type Attempt struct {
+	Dir       string `json:"dir"`
+	SessionID string `json:"sessionId,omitempty"`
+	// Before is the package coverage when the attempt started, from 0 to 1.
+	Before float64 `json:"before"`
+	// Applied reports whether the tests were merged into the module.
+	Applied bool   `json:"applied"`
+	Error   string `json:"error,omitempty"`
+}
+
+// Round is one measurement and the attempts made after it.
+type Round struct {
+	Number   int       `json:"number"`
+	Coverage float64   `json:"coverage"`
+	Attempts []Attempt `json:"attempts"`
+}
+
+// Result summarizes a run.
+type Result struct {
+	Target   float64 `json:"target"`
+	Start    float64 `json:"start"`
+	Final    float64 `json:"final"`
+	Sessions int     `json:"sessions"`
+	Rounds   []Round `json:"rounds"`
+	// Report is the per-package coverage of the last measurement.
+	Report  *intelligence.CoverageReport `json:"report,omitempty"`
+	Stopped string                       `json:"stopped"`
+}
+
+// Reached reports whether the run reached its target.
+func (r *Result) Reached() bool {
+	return r.Stopped == StopTargetReached
+}
+
+// Run measures coverage and, until the target is reached, works on the
+// packages with the most uncovered statements, Parallel at a time. Progress
+// receives a line for each step; it may be nil.
+func Run(ctx context.Context, options Options, measure Measure, write WriteTests, progress func(string)) (*Result, error) {
+	if options.Target <= 0 || options.Target > 1 {
+		return nil, fmt.Errorf("target must be between 0 and 100%%, got %g%%", options.Target*100)
+	}
+	if options.MaxSessions <= 0 {
+		options.MaxSessions = DefaultMaxSessions
+	}
+	if options.MaxRounds <= 0 {
+		options.MaxRounds = DefaultMaxRounds
+	}
+	if options.Parallel <= 0 {
+		options.Parallel = DefaultParallel
+	}
+	if options.MinStatements == 0 {
+		options.MinStatements = DefaultMinStatements
+	}
+	if progress == nil {
+		progress = func(string) {}
+	}
+
+	result := &Result{Target: options.Target}
+	attempts := map[string]int{}
+	for round := 1; ; round++ {
+		report, err := measure(ctx)
+		if err != nil {
+			return result, fmt.Errorf("failed to measure coverage: %w", err)
+		}
+		result.Report, result.Final = report, report.Coverage()
+		if round == 1 {
+			result.Start = result.Final
+		}
+		progress(fmt.Sprintf("Round %d: coverage %.1f%% of %d statements (target %.1f%%)", round, result.Final*100, report.Statements, options.Target*100))
+
+		switch {
+		case result.Final >= options.Target:
+			result.Stopped = StopTargetReached
+		case result.Sessions >= options.MaxSessions:
+			result.Stopped = StopBudgetSpent
+		case round > options.MaxRounds:
+			result.Stopped = StopRoundLimit
+		}
+		if result.Stopped != "" {
+			return result, nil
+		}
+
+		candidates := Candidates(report, options.Target, options.MinStatements, attempts)
+		if len(candidates) == 0 {
+			result.Stopped = StopNoCandidates
+			return result, nil
+		}
+		limit := min(options.Parallel, options.MaxSessions-result.Sessions, len(candidates))
+		candidates = candidates[:limit]
+
+		current := Round{Number: round, Coverage: result.Final, Attempts: make([]Attempt, len(candidates))}
+		var wg sync.WaitGroup
+		for i, pkg := range candidates {
+			attempts[pkg.Dir]++
+			progress(fmt.Sprintf("  %s: %.1f%% covered, %d statements uncovered; writing tests", pkg.Dir, pkg.Coverage()*100, pkg.Uncovered()))
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				attempt := write(ctx, pkg, options.Target)
+				attempt.Dir, attempt.Before = pkg.Dir, pkg.Coverage()
+				current.Attempts[i] = attempt
+			}()
+		}
+		wg.Wait()
+		for _, attempt := range current.Attempts {
+			if attempt.SessionID != "" {
+				result.Sessions++
+			}
+			switch {
+			case attempt.Applied:
+				progress(fmt.Sprintf("  %s: applied tests from session %s", attempt.Dir, attempt.SessionID))
+			default:
+				progress(fmt.Sprintf("  %s: %s", attempt.Dir, attempt.Error))
+			}
+		}
+		result.Rounds = append(result.Rounds, current)
+		if err := ctx.Err(); err != nil {
+			return result, err
+		}
+	}
+}
+
+// Candidates returns the packages worth writing tests for, most uncovered
+// statements first: those below target with at least minStatements
+// statements and fewer than the allowed attempts so far.
+func Candidates(report *intelligence.CoverageReport, target float64, minStatements int, attempts map[string]int) []intelligence.PackageCoverage {
+	var candidates []intelligence.PackageCoverage
+	for _, pkg := range report.Packages {
+		if pkg.Coverage() >= target || pkg.Statements < minStatements || attempts[pkg.Dir] >= maxAttemptsPerPackage {
+			continue
+		}
+		candidates = append(candidates, pkg)
+	}
+	slices.SortStableFunc(candidates, func(a, b intelligence.PackageCoverage) int {
+		return b.Uncovered() - a.Uncovered()
+	})
+	return candidates
+}
+
+// Prompt asks Jules to write tests for a package.
+func Prompt(module string, pkg intelligence.PackageCoverage, target float64) string {
+	importPath := module
+	if pkg.Dir != "." {
+		importPath += "/" + pkg.Dir
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, "Write Go tests for the package %s in the directory %s.\n\n", importPath, pkg.Dir)
+	fmt.Fprintf(&b, "Its statement coverage is %.1f%% (%d of %d statements); raise it to at least %.0f%%.\n",
+		pkg.Coverage()*100, pkg.Covered, pkg.Statements, target*100)
+	if !pkg.HasTests {
+		b.WriteString("The package has no tests yet.\n")
+	}
+	b.WriteString(`
+- Only add or change _test.go files in that directory; do not change the code
+  under test. If a test finds a bug, skip it with t.Skip and explain the bug.
+- Follow the style of the repository's existing tests and use only the test
+  libraries it already depends on.
+- Prefer table-driven tests of the exported behavior, including error paths.
+- Tests must be deterministic, must not need network access, and must pass
+  with go test ` + TestPattern(pkg.Dir) + `
+`)
+	return b.String()
+}
+
+// TestPattern returns the go test package pattern for a package directory
+// and the packages below it.
+func TestPattern(dir string) string {
+	if dir == "." {
+		return "."
+	}
+	return "./" + dir + "/..."
+}
@@ -0,0 +1,127 @@
+package testgen
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/SamyRai/juleson/internal/intelligence"
+)
+
+// fakeModule is a module whose packages reach full coverage when tests are
+// written for them, except for those listed in failing.
+type fakeModule struct {
+	mu       sync.Mutex
+	packages []intelligence.PackageCoverage
+	failing  map[string]bool
+	written  []string
+}
+
+func (m *fakeModule) measure(context.Context) (*intelligence.CoverageReport, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	report := &intelligence.CoverageReport{Packages: slices.Clone(m.packages)}
+	for _, pkg := range m.packages {
+		report.Covered += pkg.Covered
+		report.Statements += pkg.Statements
+	}
+	return report, nil
+}
+
+func (m *fakeModule) write(_ context.Context, pkg intelligence.PackageCoverage, _ float64) Attempt {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.written = append(m.written, pkg.Dir)
+	attempt := Attempt{SessionID: "session-" + pkg.Dir}
+	if m.failing[pkg.Dir] {
+		attempt.Error = "tests failed"
+		return attempt
+	}
+	for i := range m.packages {
+		if m.packages[i].Dir == pkg.Dir {
+			m.packages[i].Covered = m.packages[i].Statements
+		}
+	}
+	attempt.Applied = true
+	return attempt
+}
+
+func newFakeModule() *fakeModule {
+	return &fakeModule{packages: []intelligence.PackageCoverage{
+		{Dir: "api", Covered: 10, Statements: 100},
+		{Dir: "db", Covered: 0, Statements: 50},
+		{Dir: "tiny", Covered: 0, Statements: 3},
+		{Dir: "util", Covered: 45, Statements: 50},
+	}, failing: map[string]bool{}}
+}
+
+func TestRunReachesTarget(t *testing.T) {
+	module := newFakeModule()
+	var lines []string
+	result, err := Run(context.Background(), Options{Target: 0.7, Parallel: 1}, module.measure, module.write, func(line string) { lines = append(lines, line) })
+	if err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	if !result.Reached() || result.Sessions != 1 || len(result.Rounds) != 1 {
+		t.Fatalf("result = %+v", result)
+	}
+	// api has the most uncovered statements, and covering it is enough.
+	if module.written[0] != "api" {
+		t.Errorf("written = %v, want api first", module.written)
+	}
+	if result.Start != 55.0/203 || result.Final != 145.0/203 {
+		t.Errorf("start = %v, final = %v", result.Start, result.Final)
+	}
+	if !strings.Contains(strings.Join(lines, "\n"), "api: applied tests from session session-api") {
+		t.Errorf("progress = %q", lines)
+	}
+}
+
+func TestRunStopsWhenBudgetIsSpent(t *testing.T) {
+	module := newFakeModule()
+	module.failing["api"], module.failing["db"] = true, true
+	result, err := Run(context.Background(), Options{Target: 0.95, MaxSessions: 3, Parallel: 2}, module.measure, module.write, nil)
+	if err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	if result.Stopped != StopBudgetSpent || result.Sessions != 3 {
+		t.Fatalf("stopped = %q after %d sessions", result.Stopped, result.Sessions)
+	}
+	// Round two retries api, which has the most uncovered statements, with
+	// the one session left.
+	slices.Sort(module.written)
+	if got := strings.Join(module.written, ","); got != "api,api,db" {
+		t.Errorf("written = %s", got)
+	}
+}
+
+func TestRunStopsWithoutCandidates(t *testing.T) {
+	module := newFakeModule()
+	module.failing["api"], module.failing["db"], module.failing["util"] = true, true, true
+	result, err := Run(context.Background(), Options{Target: 0.95, MaxSessions: 100, Parallel: 3}, module.measure, module.write, nil)
+	if err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	// Each package is tried twice; tiny is below MinStatements.
+	if result.Stopped != StopNoCandidates || result.Sessions != 6 {
+		t.Fatalf("stopped = %q after %d sessions", result.Stopped, result.Sessions)
+	}
+}
+
+func TestRunRejectsBadTarget(t *testing.T) {
+	module := newFakeModule()
+	if _, err := Run(context.Background(), Options{Target: 1.5}, module.measure, module.write, nil); err == nil {
+		t.Fatal("Run with a 150% target succeeded")
+	}
+}
+
+func TestPrompt(t *testing.T) {
+	prompt := Prompt("example.com/app", intelligence.PackageCoverage{Dir: "internal/db", Covered: 1, Statements: 4}, 0.8)
+	for _, want := range []string{"example.com/app/internal/db", "25.0% (1 of 4 statements)", "at least 80%", "no tests yet", "go test ./internal/db/..."} {
+		if !strings.Contains(prompt, want) {
+			t.Errorf("prompt is missing %q:\n%s", want, prompt)
+		}
+	}
+}
@@ -0,0 +1,489 @@
+package vcs
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// BitbucketDefaultBaseURL is the Bitbucket Cloud API.
+const BitbucketDefaultBaseURL = "https://api.bitbucket.org/2.0"
+
+// bitbucketWebURL is where Bitbucket Cloud pipelines are shown.
+const bitbucketWebURL = "https://bitbucket.org"
+
+// bitbucketPageSize is the largest page Bitbucket returns for pull requests.
+const bitbucketPageSize = 50
+
+// BitbucketProvider performs operations through the Bitbucket Cloud API,
+// with pipelines being Bitbucket Pipelines identified by build number.
+// Bitbucket has no pull request labels and no API for pipeline artifacts.
+type BitbucketProvider struct {
+	baseURL  string
+	username string
+	token    string
+	client   *http.Client
+}
+
+// NewBitbucketProvider creates a provider for the API at baseURL. With a
+// username, token is an app password used for basic authentication;
+// otherwise it is a repository, project, or workspace access token.
+func NewBitbucketProvider(baseURL, username, token string) *BitbucketProvider {
+	if baseURL == "" {
+		baseURL = BitbucketDefaultBaseURL
+	}
+	return &BitbucketProvider{
+		baseURL:  strings.TrimSuffix(baseURL, "/"),
+		username: username,
+		token:    token,
+		client:   &http.Client{Timeout: 60 * time.Second},
+	}
+}
+
+// Kind returns KindBitbucket.
+func (p *BitbucketProvider) Kind() Kind { return KindBitbucket }
+
+type bitbucketPullRequest struct {
+	ID          int    `json:"id"`
+	Title       string `json:"title"`
+	Description string `json:"description"`
+	State       string `json:"state"`
+	Author      struct {
+		DisplayName string `json:"display_name"`
+		Nickname    string `json:"nickname"`
+	} `json:"author"`
+	Source struct {
+		Branch struct {
+			Name string `json:"name"`
+		} `json:"branch"`
+	} `json:"source"`
+	Destination struct {
+		Branch struct {
+			Name string `json:"name"`
+		} `json:"branch"`
+	} `json:"destination"`
+	Links struct {
+		HTML struct {
+			Href string `json:"href"`
+		} `json:"html"`
+	} `json:"links"`
+}
+
+// changeRequest converts a pull request. Bitbucket does not report whether
+// a pull request can be merged, so open pull requests are taken as
+// mergeable.
+func (pr bitbucketPullRequest) changeRequest(repo string) *ChangeRequest {
+	state := StateClosed
+	switch pr.State {
+	case "OPEN":
+		state = StateOpen
+	case "MERGED":
+		state = StateMerged
+	}
+	author := pr.Author.Nickname
+	if author == "" {
+		author = pr.Author.DisplayName
+	}
+	return &ChangeRequest{
+		Number:       pr.ID,
+		Title:        pr.Title,
+		Description:  pr.Description,
+		URL:          pr.Links.HTML.Href,
+		Repo:         repo,
+		SourceBranch: pr.Source.Branch.Name,
+		TargetBranch: pr.Destination.Branch.Name,
+		Author:       author,
+		State:        state,
+		Mergeable:    state == StateOpen,
+	}
+}
+
+// ListChangeRequests lists pull requests, most recently created first.
+// Declined and superseded pull requests are closed.
+func (p *BitbucketProvider) ListChangeRequests(ctx context.Context, repo, state string, limit int) ([]ChangeRequest, error) {
+	path, err := p.repoPath(repo)
+	if err != nil {
+		return nil, err
+	}
+	if err := validState(state); err != nil {
+		return nil, err
+	}
+	query := url.Values{"sort": {"-created_on"}, "pagelen": {strconv.Itoa(min(max(limit, 1), bitbucketPageSize))}}
+	switch state {
+	case StateOpen:
+		query["state"] = []string{"OPEN"}
+	case StateMerged:
+		query["state"] = []string{"MERGED"}
+	case StateClosed:
+		query["state"] = []string{"DECLINED", "SUPERSEDED"}
+	default:
+		query["state"] = []string{"OPEN", "MERGED", "DECLINED", "SUPERSEDED"}
+	}
+	next := path + "/pullrequests?" + query.Encode()
+	var result []ChangeRequest
+	for next != "" && len(result) < limit {
+		var page struct {
+			Values []bitbucketPullRequest `json:"values"`
+			Next   string                 `json:"next"`
+		}
+		if err := p.do(ctx, http.MethodGet, next, nil, &page); err != nil {
+			return nil, fmt.Errorf("failed to list pull requests: %w", err)
+		}
+		for _, pr := range page.Values {
+			if len(result) == limit {
+				break
+			}
+			result = append(result, *pr.changeRequest(repo))
+		}
+		next = page.Next
+	}
+	return result, nil
+}
+
+// CreateChangeRequest opens a pull request. Without a target branch,
+// Bitbucket uses the repository's main branch.
+func (p *BitbucketProvider) CreateChangeRequest(ctx context.Context, repo string, request NewChangeRequest) (*ChangeRequest, error) {
+	path, err := p.repoPath(repo)
+	if err != nil {
+		return nil, err
+	}
+	body := map[string]any{
+		"title":       request.Title,
+		"description": request.Description,
+		"source":      map[string]any{"branch": map[string]string{"name": request.SourceBranch}},
+	}
+	if request.TargetBranch != "" {
+		body["destination"] = map[string]any{"branch": map[string]string{"name": request.TargetBranch}}
+	}
+	var pr bitbucketPullRequest
+	if err := p.do(ctx, http.MethodPost, path+"/pullrequests", body, &pr); err != nil {
+		return nil, fmt.Errorf("failed to create pull request: %w", err)
+	}
+	return pr.changeRequest(repo), nil
+}
+
+// GetChangeRequest returns a pull request.
+func (p *BitbucketProvider) GetChangeRequest(ctx context.Context, repo string, number int) (*ChangeRequest, error) {
+	path, err := p.repoPath(repo)
+	if err != nil {
+		return nil, err
+	}
+	var pr bitbucketPullRequest
+	if err := p.do(ctx, http.MethodGet, fmt.Sprintf("%s/pullrequests/%d", path, number), nil, &pr); err != nil {
+		return nil, fmt.Errorf("failed to get pull request: %w", err)
+	}
+	return pr.changeRequest(repo), nil
+}
+
+// MergeChangeRequest merges a pull request with a merge commit or by
+// squashing; Bitbucket has no rebase merge.
+func (p *BitbucketProvider) MergeChangeRequest(ctx context.Context, repo string, number int, method string) error {
+	path, err := p.repoPath(repo)
+	if err != nil {
+		return err
+	}
+	strategy := "squash"
+	switch method {
+	case "", "squash":
+	case "merge":
+		strategy = "merge_commit"
+	default:
+		return fmt.Errorf("Bitbucket merges with merge or squash, not %s", method)
+	}
+	body := map[string]string{"merge_strategy": strategy}
+	if err := p.do(ctx, http.MethodPost, fmt.Sprintf("%s/pullrequests/%d/merge", path, number), body, nil); err != nil {
+		return fmt.Errorf("failed to merge PR: %w", err)
+	}
+	return nil
+}
+
+// ChangeRequestDiff returns a pull request's diff.
+func (p *BitbucketProvider) ChangeRequestDiff(ctx context.Context, repo string, number int) (string, error) {
+	path, err := p.repoPath(repo)
+	if err != nil {
+		return "", err
+	}
+	var diff bytes.Buffer
+	if err := p.download(ctx, fmt.Sprintf("%s/pullrequests/%d/diff", path, number), &diff); err != nil {
+		return "", fmt.Errorf("failed to get PR diff: %w", err)
+	}
+	return diff.String(), nil
+}
+
+// AddLabels returns ErrNotSupported; Bitbucket pull requests have no labels.
+func (p *BitbucketProvider) AddLabels(ctx context.Context, repo string, number int, labels []string) error {
+	return fmt.Errorf("Bitbucket pull request labels are %w", ErrNotSupported)
+}
+
+// CreateIssue opens an issue in the repository's issue tracker, which has
+// no labels.
+func (p *BitbucketProvider) CreateIssue(ctx context.Context, repo, title, body string, labels []string) (*Issue, error) {
+	path, err := p.repoPath(repo)
+	if err != nil {
+		return nil, err
+	}
+	if len(labels) > 0 {
+		return nil, fmt.Errorf("Bitbucket issue labels are %w", ErrNotSupported)
+	}
+	request := map[string]any{"title": title, "content": map[string]string{"raw": body}}
+	var issue struct {
+		ID    int    `json:"id"`
+		Title string `json:"title"`
+		Links struct {
+			HTML struct {
+				Href string `json:"href"`
+			} `json:"html"`
+		} `json:"links"`
+	}
+	if err := p.do(ctx, http.MethodPost, path+"/issues", request, &issue); err != nil {
+		return nil, fmt.Errorf("failed to create issue: %w", err)
+	}
+	return &Issue{Number: issue.ID, Title: issue.Title, URL: issue.Links.HTML.Href}, nil
+}
+
+type bitbucketPipeline struct {
+	BuildNumber int64 `json:"build_number"`
+	State       struct {
+		Name   string `json:"name"`
+		Result *struct {
+			Name string `json:"name"`
+		} `json:"result"`
+	} `json:"state"`
+	Target struct {
+		RefName string `json:"ref_name"`
+		Commit  struct {
+			Hash string `json:"hash"`
+		} `json:"commit"`
+		Selector struct {
+			Pattern string `json:"pattern"`
+		} `json:"selector"`
+	} `json:"target"`
+	CreatedOn time.Time `json:"created_on"`
+}
+
+func (p bitbucketPipeline) pipeline(repo string) Pipeline {
+	status := p.State.Name
+	if p.State.Result != nil && p.State.Result.Name != "" {
+		status = p.State.Result.Name
+	}
+	return Pipeline{
+		ID:        p.BuildNumber,
+		Name:      p.Target.Selector.Pattern,
+		Ref:       p.Target.RefName,
+		SHA:       p.Target.Commit.Hash,
+		Status:    strings.ToLower(status),
+		URL:       fmt.Sprintf("%s/%s/pipelines/results/%d", bitbucketWebURL, repo, p.BuildNumber),
+		CreatedAt: p.CreatedOn,
+	}
+}
+
+// ListPipelines lists pipelines, newest first. Bitbucket cannot filter by
+// branch, so the most recent pipelines are filtered by ref.
+func (p *BitbucketProvider) ListPipelines(ctx context.Context, repo, ref string, limit int) ([]Pipeline, error) {
+	path, err := p.repoPath(repo)
+	if err != nil {
+		return nil, err
+	}
+	query := url.Values{"sort": {"-created_on"}, "pagelen": {"100"}}
+	var page struct {
+		Values []bitbucketPipeline `json:"values"`
+	}
+	if err := p.do(ctx, http.MethodGet, path+"/pipelines/?"+query.Encode(), nil, &page); err != nil {
+		return nil, fmt.Errorf("failed to list pipelines: %w", err)
+	}
+	result := make([]Pipeline, 0, min(len(page.Values), max(limit, 1)))
+	for _, pipeline := range page.Values {
+		if ref != "" && pipeline.Target.RefName != ref {
+			continue
+		}
+		result = append(result, pipeline.pipeline(repo))
+		if len(result) == limit {
+			break
+		}
+	}
+	return result, nil
+}
+
+// GetPipeline returns a pipeline by build number.
+func (p *BitbucketProvider) GetPipeline(ctx context.Context, repo string, id int64) (*Pipeline, error) {
+	path, err := p.repoPath(repo)
+	if err != nil {
+		return nil, err
+	}
+	var pipeline bitbucketPipeline
+	if err := p.do(ctx, http.MethodGet, fmt.Sprintf("%s/pipelines/%d", path, id), nil, &pipeline); err != nil {
+		return nil, fmt.Errorf("failed to get pipeline: %w", err)
+	}
+	result := pipeline.pipeline(repo)
+	return &result, nil
+}
+
+// TriggerPipeline runs the pipeline of a branch, or the custom pipeline
+// named by the workflow, with the variables as pipeline variables.
+func (p *BitbucketProvider) TriggerPipeline(ctx context.Context, repo string, trigger PipelineTrigger) (*Pipeline, error) {
+	path, err := p.repoPath(repo)
+	if err != nil {
+		return nil, err
+	}
+	target := map[string]any{"type": "pipeline_ref_target", "ref_type": "branch", "ref_name": trigger.Ref}
+	if trigger.Workflow != "" {
+		target["selector"] = map[string]string{"type": "custom", "pattern": trigger.Workflow}
+	}
+	body := map[string]any{"target": target}
+	if len(trigger.Variables) > 0 {
+		variables := make([]map[string]string, 0, len(trigger.Variables))
+		for key, value := range trigger.Variables {
+			variables = append(variables, map[string]string{"key": key, "value": value})
+		}
+		body["variables"] = variables
+	}
+	var pipeline bitbucketPipeline
+	if err := p.do(ctx, http.MethodPost, path+"/pipelines/", body, &pipeline); err != nil {
+		return nil, fmt.Errorf("failed to trigger pipeline: %w", err)
+	}
+	result := pipeline.pipeline(repo)
+	return &result, nil
+}
+
+// PipelineLogs writes the logs of a pipeline's steps.
+func (p *BitbucketProvider) PipelineLogs(ctx context.Context, repo string, id int64, w io.Writer) error {
+	path, err := p.repoPath(repo)
+	if err != nil {
+		return err
+	}
+	var steps struct {
+		Values []struct {
+			UUID string `json:"uuid"`
+			Name string `json:"name"`
+		} `json:"values"`
+	}
+	if err := p.do(ctx, http.MethodGet, fmt.Sprintf("%s/pipelines/%d/steps/", path, id), nil, &steps); err != nil {
+		return fmt.Errorf("failed to list pipeline steps: %w", err)
+	}
+	for _, step := range steps.Values {
+		if err := writeLogHeader(w, step.Name); err != nil {
+			return err
+		}
+		logPath := fmt.Sprintf("%s/pipelines/%d/steps/%s/log", path, id, url.PathEscape(step.UUID))
+		if err := p.download(ctx, logPath, w); err != nil {
+			return fmt.Errorf("failed to get log of %s: %w", step.Name, err)
+		}
+	}
+	return nil
+}
+
+// ListArtifacts returns ErrNotSupported.
+func (p *BitbucketProvider) ListArtifacts(ctx context.Context, repo string, pipelineID int64) ([]Artifact, error) {
+	return nil, fmt.Errorf("Bitbucket pipeline artifacts are %w", ErrNotSupported)
+}
+
+// DownloadArtifact returns ErrNotSupported.
+func (p *BitbucketProvider) DownloadArtifact(ctx context.Context, repo string, artifact Artifact, w io.Writer) error {
+	return fmt.Errorf("Bitbucket pipeline artifacts are %w", ErrNotSupported)
+}
+
+func (p *BitbucketProvider) repoPath(repo string) (string, error) {
+	workspace, slug, ok := strings.Cut(repo, "/")
+	if !ok || workspace == "" || slug == "" || strings.Contains(slug, "/") {
+		return "", fmt.Errorf("invalid Bitbucket repository %q (use workspace/name)", repo)
+	}
+	return "/repositories/" + url.PathEscape(workspace) + "/" + url.PathEscape(slug), nil
+}
+
+// request builds a request for path, or for an absolute URL such as the
+// next page of a listing.
+func (p *BitbucketProvider) request(ctx context.Context, method, path string, body any) (*http.Request, error) {
+	var reader io.Reader
+	if body != nil {
+		payload, err := json.Marshal(body)
+		if err != nil {
+			return nil, err
+		}
+		reader = bytes.NewReader(payload)
+	}
+	target := path
+	if !strings.HasPrefix(path, "https://") && !strings.HasPrefix(path, "http://") {
+		target = p.baseURL + path
+	}
+	req, err := http.NewRequestWithContext(ctx, method, target, reader)
+	if err != nil {
+		return nil, err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	switch {
+	case p.username != "":
+		req.SetBasicAuth(p.username, p.token)
+	case p.token != "":
+		req.Header.Set("Authorization", "Bearer "+p.token)
+	}
+	return req, nil
+}
+
+func (p *BitbucketProvider) do(ctx context.Context, method, path string, body, out any) error {
+	req, err := p.request(ctx, method, path, body)
+	if err != nil {
+		return err
+	}
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if err := bitbucketError(resp); err != nil {
+		return err
+	}
+	if out == nil {
+		return nil
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("failed to decode Bitbucket response: %w", err)
+	}
+	return nil
+}
+
+func (p *BitbucketProvider) download(ctx context.Context, path string, w io.Writer) error {
+	req, err := p.request(ctx, http.MethodGet, path, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if err := bitbucketError(resp); err != nil {
+		return err
+	}
+	_, err = io.Copy(w, resp.Body)
+	return err
+}
+
+// bitbucketError returns the error in an unsuccessful response.
+func bitbucketError(resp *http.Response) error {
+	if resp.StatusCode/100 == 2 {
+		return nil
+	}
+	data, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+	var body struct {
+		Error struct {
+			Message string `json:"message"`
+		} `json:"error"`
+	}
+	if json.Unmarshal(data, &body) == nil && body.Error.Message != "" {
+		return fmt.Errorf("Bitbucket returned %s: %s", resp.Status, body.Error.Message)
+	}
+	if len(bytes.TrimSpace(data)) == 0 {
+		return errors.New("Bitbucket returned " + resp.Status)
+	}
+	return fmt.Errorf("Bitbucket returned %s: %s", resp.Status, bytes.TrimSpace(data))
+}
@@ -0,0 +1,396 @@
+package vcs
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/google/go-github/v76/github"
+)
+
+// gitHubPageSize is the largest page the GitHub API returns.
+const gitHubPageSize = 100
+
+// GitHubProvider performs operations through the GitHub API, with pipelines
+// being GitHub Actions workflow runs.
+type GitHubProvider struct {
+	client *github.Client
+}
+
+// NewGitHubProvider creates a provider using client.
+func NewGitHubProvider(client *github.Client) *GitHubProvider {
+	return &GitHubProvider{client: client}
+}
+
+// Kind returns KindGitHub.
+func (p *GitHubProvider) Kind() Kind { return KindGitHub }
+
+// GetChangeRequest returns a pull request.
+func (p *GitHubProvider) GetChangeRequest(ctx context.Context, repo string, number int) (*ChangeRequest, error) {
+	owner, name, err := splitGitHubRepo(repo)
+	if err != nil {
+		return nil, err
+	}
+	pr, _, err := p.client.PullRequests.Get(ctx, owner, name, number)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get pull request: %w", err)
+	}
+	return gitHubChangeRequest(pr), nil
+}
+
+// ListChangeRequests lists pull requests, most recently created first.
+func (p *GitHubProvider) ListChangeRequests(ctx context.Context, repo, state string, limit int) ([]ChangeRequest, error) {
+	owner, name, err := splitGitHubRepo(repo)
+	if err != nil {
+		return nil, err
+	}
+	if err := validState(state); err != nil {
+		return nil, err
+	}
+	listState := state
+	if state == StateMerged {
+		listState = StateClosed
+	}
+	var result []ChangeRequest
+	opts := &github.PullRequestListOptions{State: listState, ListOptions: github.ListOptions{PerPage: gitHubPageSize}}
+	for {
+		prs, resp, err := p.client.PullRequests.List(ctx, owner, name, opts)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list pull requests: %w", err)
+		}
+		for _, pr := range prs {
+			if state == StateMerged && pr.MergedAt == nil {
+				continue
+			}
+			result = append(result, *gitHubChangeRequest(pr))
+			if len(result) == limit {
+				return result, nil
+			}
+		}
+		if resp.NextPage == 0 {
+			return result, nil
+		}
+		opts.Page = resp.NextPage
+	}
+}
+
+// CreateChangeRequest opens a pull request.
+func (p *GitHubProvider) CreateChangeRequest(ctx context.Context, repo string, request NewChangeRequest) (*ChangeRequest, error) {
+	owner, name, err := splitGitHubRepo(repo)
+	if err != nil {
+		return nil, err
+	}
+	base := request.TargetBranch
+	if base == "" {
+		repository, _, err := p.client.Repositories.Get(ctx, owner, name)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get default branch: %w", err)
+		}
+		base = repository.GetDefaultBranch()
+	}
+	pr, _, err := p.client.PullRequests.Create(ctx, owner, name, &github.NewPullRequest{
+		Title: github.Ptr(request.Title),
+		Body:  github.Ptr(request.Description),
+		Head:  github.Ptr(request.SourceBranch),
+		Base:  github.Ptr(base),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create pull request: %w", err)
+	}
+	return gitHubChangeRequest(pr), nil
+}
+
+func gitHubChangeRequest(pr *github.PullRequest) *ChangeRequest {
+	state := pr.GetState()
+	if pr.GetMerged() || pr.MergedAt != nil {
+		state = StateMerged
+	}
+	return &ChangeRequest{
+		Number:       pr.GetNumber(),
+		Title:        pr.GetTitle(),
+		Description:  pr.GetBody(),
+		URL:          pr.GetHTMLURL(),
+		Repo:         pr.GetBase().GetRepo().GetFullName(),
+		SourceBranch: pr.GetHead().GetRef(),
+		TargetBranch: pr.GetBase().GetRef(),
+		Author:       pr.GetUser().GetLogin(),
+		State:        state,
+		Mergeable:    pr.GetMergeable(),
+	}
+}
+
+// MergeChangeRequest merges a pull request.
+func (p *GitHubProvider) MergeChangeRequest(ctx context.Context, repo string, number int, method string) error {
+	owner, name, err := splitGitHubRepo(repo)
+	if err != nil {
+		return err
+	}
+	if method == "" {
+		method = "squash"
+	}
+	if _, _, err := p.client.PullRequests.Merge(ctx, owner, name, number, "", &github.PullRequestOptions{MergeMethod: method}); err != nil {
+		return fmt.Errorf("failed to merge PR: %w", err)
+	}
+	return nil
+}
+
+// ChangeRequestDiff returns a pull request's diff.
+func (p *GitHubProvider) ChangeRequestDiff(ctx context.Context, repo string, number int) (string, error) {
+	owner, name, err := splitGitHubRepo(repo)
+	if err != nil {
+		return "", err
+	}
+	diff, _, err := p.client.PullRequests.GetRaw(ctx, owner, name, number, github.RawOptions{Type: github.Diff})
+	if err != nil {
+		return "", fmt.Errorf("failed to get PR diff: %w", err)
+	}
+	return diff, nil
+}
+
+// AddLabels adds labels to a pull request.
+func (p *GitHubProvider) AddLabels(ctx context.Context, repo string, number int, labels []string) error {
+	owner, name, err := splitGitHubRepo(repo)
+	if err != nil {
+		return err
+	}
+	if _, _, err := p.client.Issues.AddLabelsToIssue(ctx, owner, name, number, labels); err != nil {
+		return fmt.Errorf("failed to add labels: %w", err)
+	}
+	return nil
+}
+
+// CreateIssue opens an issue.
+func (p *GitHubProvider) CreateIssue(ctx context.Context, repo, title, body string, labels []string) (*Issue, error) {
+	owner, name, err := splitGitHubRepo(repo)
+	if err != nil {
+		return nil, err
+	}
+	request := &github.IssueRequest{Title: github.Ptr(title), Body: github.Ptr(body)}
+	if len(labels) > 0 {
+		request.Labels = &labels
+	}
+	issue, _, err := p.client.Issues.Create(ctx, owner, name, request)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create issue: %w", err)
+	}
+	return &Issue{Number: issue.GetNumber(), Title: issue.GetTitle(), URL: issue.GetHTMLURL()}, nil
+}
+
+// ListPipelines lists workflow runs, following pages until limit runs are
+// listed. A limit of zero or less lists every run.
+func (p *GitHubProvider) ListPipelines(ctx context.Context, repo, ref string, limit int) ([]Pipeline, error) {
+	owner, name, err := splitGitHubRepo(repo)
+	if err != nil {
+		return nil, err
+	}
+	return listWorkflowRuns(ref, limit, func(opts *github.ListWorkflowRunsOptions) (*github.WorkflowRuns, *github.Response, error) {
+		return p.client.Actions.ListRepositoryWorkflowRuns(ctx, owner, name, opts)
+	})
+}
+
+// ListWorkflowPipelines lists the runs of one workflow, given by file name
+// such as ci.yml or by ID, like ListPipelines.
+func (p *GitHubProvider) ListWorkflowPipelines(ctx context.Context, repo, workflow, ref string, limit int) ([]Pipeline, error) {
+	owner, name, err := splitGitHubRepo(repo)
+	if err != nil {
+		return nil, err
+	}
+	if id, err := strconv.ParseInt(workflow, 10, 64); err == nil {
+		return listWorkflowRuns(ref, limit, func(opts *github.ListWorkflowRunsOptions) (*github.WorkflowRuns, *github.Response, error) {
+			return p.client.Actions.ListWorkflowRunsByID(ctx, owner, name, id, opts)
+		})
+	}
+	return listWorkflowRuns(ref, limit, func(opts *github.ListWorkflowRunsOptions) (*github.WorkflowRuns, *github.Response, error) {
+		return p.client.Actions.ListWorkflowRunsByFileName(ctx, owner, name, workflow, opts)
+	})
+}
+
+// listWorkflowRuns pages through the runs returned by list.
+func listWorkflowRuns(ref string, limit int, list func(*github.ListWorkflowRunsOptions) (*github.WorkflowRuns, *github.Response, error)) ([]Pipeline, error) {
+	perPage := gitHubPageSize
+	if limit > 0 {
+		perPage = min(limit, gitHubPageSize)
+	}
+	opts := &github.ListWorkflowRunsOptions{Branch: ref, ListOptions: github.ListOptions{PerPage: perPage}}
+	var pipelines []Pipeline
+	for {
+		runs, resp, err := list(opts)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list workflow runs: %w", err)
+		}
+		for _, run := range runs.WorkflowRuns {
+			pipelines = append(pipelines, gitHubPipeline(run))
+			if len(pipelines) == limit {
+				return pipelines, nil
+			}
+		}
+		if resp.NextPage == 0 {
+			return pipelines, nil
+		}
+		opts.Page = resp.NextPage
+	}
+}
+
+// GetPipeline returns a workflow run.
+func (p *GitHubProvider) GetPipeline(ctx context.Context, repo string, id int64) (*Pipeline, error) {
+	owner, name, err := splitGitHubRepo(repo)
+	if err != nil {
+		return nil, err
+	}
+	run, _, err := p.client.Actions.GetWorkflowRunByID(ctx, owner, name, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get workflow run: %w", err)
+	}
+	pipeline := gitHubPipeline(run)
+	return &pipeline, nil
+}
+
+// TriggerPipeline dispatches the workflow_dispatch event of a workflow, with
+// the variables as its inputs. GitHub does not return the run it starts, so
+// the pipeline is nil.
+func (p *GitHubProvider) TriggerPipeline(ctx context.Context, repo string, trigger PipelineTrigger) (*Pipeline, error) {
+	owner, name, err := splitGitHubRepo(repo)
+	if err != nil {
+		return nil, err
+	}
+	if trigger.Workflow == "" {
+		return nil, fmt.Errorf("GitHub needs the workflow to run, such as ci.yml")
+	}
+	event := github.CreateWorkflowDispatchEventRequest{Ref: trigger.Ref}
+	if len(trigger.Variables) > 0 {
+		event.Inputs = make(map[string]interface{}, len(trigger.Variables))
+		for key, value := range trigger.Variables {
+			event.Inputs[key] = value
+		}
+	}
+	if id, err := strconv.ParseInt(trigger.Workflow, 10, 64); err == nil {
+		_, err = p.client.Actions.CreateWorkflowDispatchEventByID(ctx, owner, name, id, event)
+		if err != nil {
+			return nil, fmt.Errorf("failed to dispatch workflow: %w", err)
+		}
+		return nil, nil
+	}
+	if _, err := p.client.Actions.CreateWorkflowDispatchEventByFileName(ctx, owner, name, trigger.Workflow, event); err != nil {
+		return nil, fmt.Errorf("failed to dispatch workflow: %w", err)
+	}
+	return nil, nil
+}
+
+func gitHubPipeline(run *github.WorkflowRun) Pipeline {
+	status := run.GetConclusion()
+	if status == "" {
+		status = run.GetStatus()
+	}
+	return Pipeline{
+		ID:        run.GetID(),
+		Name:      run.GetName(),
+		Ref:       run.GetHeadBranch(),
+		SHA:       run.GetHeadSHA(),
+		Status:    status,
+		URL:       run.GetHTMLURL(),
+		CreatedAt: run.GetCreatedAt().Time,
+	}
+}
+
+// ListArtifacts lists a workflow run's artifacts.
+func (p *GitHubProvider) ListArtifacts(ctx context.Context, repo string, pipelineID int64) ([]Artifact, error) {
+	owner, name, err := splitGitHubRepo(repo)
+	if err != nil {
+		return nil, err
+	}
+	var artifacts []Artifact
+	opts := &github.ListOptions{PerPage: gitHubPageSize}
+	for {
+		list, resp, err := p.client.Actions.ListWorkflowRunArtifacts(ctx, owner, name, pipelineID, opts)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list artifacts: %w", err)
+		}
+		for _, artifact := range list.Artifacts {
+			artifacts = append(artifacts, Artifact{ID: artifact.GetID(), Name: artifact.GetName(), Size: artifact.GetSizeInBytes()})
+		}
+		if resp.NextPage == 0 {
+			return artifacts, nil
+		}
+		opts.Page = resp.NextPage
+	}
+}
+
+// DownloadArtifact writes an artifact's zip archive to w.
+func (p *GitHubProvider) DownloadArtifact(ctx context.Context, repo string, artifact Artifact, w io.Writer) error {
+	owner, name, err := splitGitHubRepo(repo)
+	if err != nil {
+		return err
+	}
+	location, _, err := p.client.Actions.DownloadArtifact(ctx, owner, name, artifact.ID, 1)
+	if err != nil {
+		return fmt.Errorf("failed to locate artifact %s: %w", artifact.Name, err)
+	}
+	if err := fetch(ctx, location.String(), w); err != nil {
+		return fmt.Errorf("failed to download artifact %s: %w", artifact.Name, err)
+	}
+	return nil
+}
+
+// PipelineLogs writes the logs of a workflow run's jobs.
+func (p *GitHubProvider) PipelineLogs(ctx context.Context, repo string, id int64, w io.Writer) error {
+	owner, name, err := splitGitHubRepo(repo)
+	if err != nil {
+		return err
+	}
+	var jobs []*github.WorkflowJob
+	opts := &github.ListWorkflowJobsOptions{ListOptions: github.ListOptions{PerPage: gitHubPageSize}}
+	for {
+		page, resp, err := p.client.Actions.ListWorkflowJobs(ctx, owner, name, id, opts)
+		if err != nil {
+			return fmt.Errorf("failed to list workflow jobs: %w", err)
+		}
+		jobs = append(jobs, page.Jobs...)
+		if resp.NextPage == 0 {
+			break
+		}
+		opts.Page = resp.NextPage
+	}
+	for _, job := range jobs {
+		if err := writeLogHeader(w, job.GetName()); err != nil {
+			return err
+		}
+		location, _, err := p.client.Actions.GetWorkflowJobLogs(ctx, owner, name, job.GetID(), 1)
+		if err != nil {
+			return fmt.Errorf("failed to locate logs of %s: %w", job.GetName(), err)
+		}
+		if err := fetch(ctx, location.String(), w); err != nil {
+			return fmt.Errorf("failed to download logs of %s: %w", job.GetName(), err)
+		}
+	}
+	return nil
+}
+
+// fetch writes the body of an unauthenticated GET, such as of a signed
+// download URL, to w.
+func fetch(ctx context.Context, rawURL string, w io.Writer) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, rawURL, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("download returned %s", resp.Status)
+	}
+	_, err = io.Copy(w, resp.Body)
+	return err
+}
+
+func splitGitHubRepo(repo string) (string, string, error) {
+	owner, name, ok := strings.Cut(repo, "/")
+	if !ok || owner == "" || name == "" || strings.Contains(name, "/") {
+		return "", "", fmt.Errorf("invalid GitHub repository %q (use owner/name)", repo)
+	}
+	return owner, name, nil
+}
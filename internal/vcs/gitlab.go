@@ -0,0 +1,440 @@
+package vcs
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// GitLabDefaultBaseURL is the API of gitlab.com.
+const GitLabDefaultBaseURL = "https://gitlab.com/api/v4"
+
+// gitLabPageSize is the largest page GitLab returns.
+const gitLabPageSize = 100
+
+// GitLabProvider performs operations through the GitLab REST API, with
+// change requests being merge requests.
+type GitLabProvider struct {
+	baseURL string
+	token   string
+	client  *http.Client
+}
+
+// NewGitLabProvider creates a provider for the API at baseURL, such as
+// https://gitlab.example.com/api/v4, authenticating with a personal,
+// project, or group access token.
+func NewGitLabProvider(baseURL, token string) *GitLabProvider {
+	if baseURL == "" {
+		baseURL = GitLabDefaultBaseURL
+	}
+	return &GitLabProvider{baseURL: strings.TrimSuffix(baseURL, "/"), token: token, client: &http.Client{Timeout: 60 * time.Second}}
+}
+
+// Kind returns KindGitLab.
+func (p *GitLabProvider) Kind() Kind { return KindGitLab }
+
+type gitLabMergeRequest struct {
+	IID          int    `json:"iid"`
+	Title        string `json:"title"`
+	Description  string `json:"description"`
+	WebURL       string `json:"web_url"`
+	SourceBranch string `json:"source_branch"`
+	TargetBranch string `json:"target_branch"`
+	State        string `json:"state"`
+	Author       struct {
+		Username string `json:"username"`
+	} `json:"author"`
+	DetailedMergeStatus string `json:"detailed_merge_status"`
+	MergeStatus         string `json:"merge_status"`
+}
+
+// GetChangeRequest returns a merge request by its project-scoped number.
+func (p *GitLabProvider) GetChangeRequest(ctx context.Context, repo string, number int) (*ChangeRequest, error) {
+	var mr gitLabMergeRequest
+	if err := p.do(ctx, http.MethodGet, p.mergeRequestPath(repo, number), nil, &mr); err != nil {
+		return nil, fmt.Errorf("failed to get merge request: %w", err)
+	}
+	return mr.changeRequest(repo), nil
+}
+
+// ListChangeRequests lists merge requests, most recently created first.
+func (p *GitLabProvider) ListChangeRequests(ctx context.Context, repo, state string, limit int) ([]ChangeRequest, error) {
+	if err := validState(state); err != nil {
+		return nil, err
+	}
+	if state == StateOpen {
+		state = "opened"
+	}
+	query := url.Values{"state": {state}, "per_page": {strconv.Itoa(min(max(limit, 1), gitLabPageSize))}, "order_by": {"created_at"}, "sort": {"desc"}}
+	var mrs []gitLabMergeRequest
+	if err := p.do(ctx, http.MethodGet, p.projectPath(repo)+"/merge_requests?"+query.Encode(), nil, &mrs); err != nil {
+		return nil, fmt.Errorf("failed to list merge requests: %w", err)
+	}
+	result := make([]ChangeRequest, 0, len(mrs))
+	for _, mr := range mrs {
+		result = append(result, *mr.changeRequest(repo))
+	}
+	return result, nil
+}
+
+// CreateChangeRequest opens a merge request.
+func (p *GitLabProvider) CreateChangeRequest(ctx context.Context, repo string, request NewChangeRequest) (*ChangeRequest, error) {
+	target := request.TargetBranch
+	if target == "" {
+		var project struct {
+			DefaultBranch string `json:"default_branch"`
+		}
+		if err := p.do(ctx, http.MethodGet, p.projectPath(repo), nil, &project); err != nil {
+			return nil, fmt.Errorf("failed to get default branch: %w", err)
+		}
+		target = project.DefaultBranch
+	}
+	body := map[string]string{
+		"title":         request.Title,
+		"description":   request.Description,
+		"source_branch": request.SourceBranch,
+		"target_branch": target,
+	}
+	var mr gitLabMergeRequest
+	if err := p.do(ctx, http.MethodPost, p.projectPath(repo)+"/merge_requests", body, &mr); err != nil {
+		return nil, fmt.Errorf("failed to create merge request: %w", err)
+	}
+	return mr.changeRequest(repo), nil
+}
+
+func (mr gitLabMergeRequest) changeRequest(repo string) *ChangeRequest {
+	state := mr.State
+	if state == "opened" || state == "locked" {
+		state = StateOpen
+	}
+	return &ChangeRequest{
+		Number:       mr.IID,
+		Title:        mr.Title,
+		Description:  mr.Description,
+		URL:          mr.WebURL,
+		Repo:         repo,
+		SourceBranch: mr.SourceBranch,
+		TargetBranch: mr.TargetBranch,
+		Author:       mr.Author.Username,
+		State:        state,
+		Mergeable:    mr.DetailedMergeStatus == "mergeable" || (mr.DetailedMergeStatus == "" && mr.MergeStatus == "can_be_merged"),
+	}
+}
+
+// MergeChangeRequest merges a merge request. GitLab applies the project's
+// merge method, so only merge and squash are accepted.
+func (p *GitLabProvider) MergeChangeRequest(ctx context.Context, repo string, number int, method string) error {
+	body := map[string]bool{}
+	switch method {
+	case "", "squash":
+		body["squash"] = true
+	case "merge":
+	default:
+		return fmt.Errorf("GitLab merges with the project's merge method; use merge or squash, not %s", method)
+	}
+	if err := p.do(ctx, http.MethodPut, p.mergeRequestPath(repo, number)+"/merge", body, nil); err != nil {
+		return fmt.Errorf("failed to merge MR: %w", err)
+	}
+	return nil
+}
+
+// ChangeRequestDiff returns a merge request's changes as a unified diff.
+func (p *GitLabProvider) ChangeRequestDiff(ctx context.Context, repo string, number int) (string, error) {
+	var b strings.Builder
+	for page := 1; ; page++ {
+		var diffs []struct {
+			OldPath     string `json:"old_path"`
+			NewPath     string `json:"new_path"`
+			Diff        string `json:"diff"`
+			NewFile     bool   `json:"new_file"`
+			DeletedFile bool   `json:"deleted_file"`
+		}
+		path := fmt.Sprintf("%s/diffs?per_page=%d&page=%d", p.mergeRequestPath(repo, number), gitLabPageSize, page)
+		if err := p.do(ctx, http.MethodGet, path, nil, &diffs); err != nil {
+			return "", fmt.Errorf("failed to get MR diff: %w", err)
+		}
+		for _, diff := range diffs {
+			oldPath, newPath := "a/"+diff.OldPath, "b/"+diff.NewPath
+			if diff.NewFile {
+				oldPath = "/dev/null"
+			}
+			if diff.DeletedFile {
+				newPath = "/dev/null"
+			}
+			fmt.Fprintf(&b, "diff --git a/%s b/%s\n--- %s\n+++ %s\n%s", diff.OldPath, diff.NewPath, oldPath, newPath, diff.Diff)
+			if diff.Diff != "" && !strings.HasSuffix(diff.Diff, "\n") {
+				b.WriteString("\n")
+			}
+		}
+		if len(diffs) < gitLabPageSize {
+			return b.String(), nil
+		}
+	}
+}
+
+// AddLabels adds labels to a merge request.
+func (p *GitLabProvider) AddLabels(ctx context.Context, repo string, number int, labels []string) error {
+	body := map[string]string{"add_labels": strings.Join(labels, ",")}
+	if err := p.do(ctx, http.MethodPut, p.mergeRequestPath(repo, number), body, nil); err != nil {
+		return fmt.Errorf("failed to add labels: %w", err)
+	}
+	return nil
+}
+
+// CreateIssue opens an issue.
+func (p *GitLabProvider) CreateIssue(ctx context.Context, repo, title, body string, labels []string) (*Issue, error) {
+	request := map[string]string{"title": title, "description": body}
+	if len(labels) > 0 {
+		request["labels"] = strings.Join(labels, ",")
+	}
+	var issue struct {
+		IID    int    `json:"iid"`
+		Title  string `json:"title"`
+		WebURL string `json:"web_url"`
+	}
+	if err := p.do(ctx, http.MethodPost, p.projectPath(repo)+"/issues", request, &issue); err != nil {
+		return nil, fmt.Errorf("failed to create issue: %w", err)
+	}
+	return &Issue{Number: issue.IID, Title: issue.Title, URL: issue.WebURL}, nil
+}
+
+// ListPipelines lists pipelines, newest first.
+func (p *GitLabProvider) ListPipelines(ctx context.Context, repo, ref string, limit int) ([]Pipeline, error) {
+	query := url.Values{"per_page": {strconv.Itoa(min(max(limit, 1), gitLabPageSize))}, "order_by": {"id"}, "sort": {"desc"}}
+	if ref != "" {
+		query.Set("ref", ref)
+	}
+	var pipelines []gitLabPipeline
+	if err := p.do(ctx, http.MethodGet, p.projectPath(repo)+"/pipelines?"+query.Encode(), nil, &pipelines); err != nil {
+		return nil, fmt.Errorf("failed to list pipelines: %w", err)
+	}
+	result := make([]Pipeline, 0, len(pipelines))
+	for _, pipeline := range pipelines {
+		result = append(result, pipeline.pipeline())
+	}
+	return result, nil
+}
+
+// GetPipeline returns a pipeline.
+func (p *GitLabProvider) GetPipeline(ctx context.Context, repo string, id int64) (*Pipeline, error) {
+	var pipeline gitLabPipeline
+	if err := p.do(ctx, http.MethodGet, fmt.Sprintf("%s/pipelines/%d", p.projectPath(repo), id), nil, &pipeline); err != nil {
+		return nil, fmt.Errorf("failed to get pipeline: %w", err)
+	}
+	result := pipeline.pipeline()
+	return &result, nil
+}
+
+// TriggerPipeline runs the project's pipeline for a branch or tag, with the
+// variables as CI/CD variables.
+func (p *GitLabProvider) TriggerPipeline(ctx context.Context, repo string, trigger PipelineTrigger) (*Pipeline, error) {
+	if trigger.Workflow != "" {
+		return nil, fmt.Errorf("GitLab runs the project's pipeline; a workflow is %w", ErrNotSupported)
+	}
+	type variable struct {
+		Key   string `json:"key"`
+		Value string `json:"value"`
+	}
+	body := struct {
+		Ref       string     `json:"ref"`
+		Variables []variable `json:"variables,omitempty"`
+	}{Ref: trigger.Ref}
+	for key, value := range trigger.Variables {
+		body.Variables = append(body.Variables, variable{Key: key, Value: value})
+	}
+	var pipeline gitLabPipeline
+	if err := p.do(ctx, http.MethodPost, p.projectPath(repo)+"/pipeline", body, &pipeline); err != nil {
+		return nil, fmt.Errorf("failed to trigger pipeline: %w", err)
+	}
+	result := pipeline.pipeline()
+	return &result, nil
+}
+
+type gitLabPipeline struct {
+	ID        int64     `json:"id"`
+	Name      string    `json:"name"`
+	Ref       string    `json:"ref"`
+	SHA       string    `json:"sha"`
+	Status    string    `json:"status"`
+	WebURL    string    `json:"web_url"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+func (p gitLabPipeline) pipeline() Pipeline {
+	return Pipeline{
+		ID:        p.ID,
+		Name:      p.Name,
+		Ref:       p.Ref,
+		SHA:       p.SHA,
+		Status:    p.Status,
+		URL:       p.WebURL,
+		CreatedAt: p.CreatedAt,
+	}
+}
+
+type gitLabJob struct {
+	ID            int64  `json:"id"`
+	Name          string `json:"name"`
+	ArtifactsFile *struct {
+		Size int64 `json:"size"`
+	} `json:"artifacts_file"`
+}
+
+// pipelineJobs lists the jobs of a pipeline.
+func (p *GitLabProvider) pipelineJobs(ctx context.Context, repo string, pipelineID int64) ([]gitLabJob, error) {
+	var result []gitLabJob
+	for page := 1; ; page++ {
+		var jobs []gitLabJob
+		path := fmt.Sprintf("%s/pipelines/%d/jobs?per_page=%d&page=%d", p.projectPath(repo), pipelineID, gitLabPageSize, page)
+		if err := p.do(ctx, http.MethodGet, path, nil, &jobs); err != nil {
+			return nil, fmt.Errorf("failed to list pipeline jobs: %w", err)
+		}
+		result = append(result, jobs...)
+		if len(jobs) < gitLabPageSize {
+			return result, nil
+		}
+	}
+}
+
+// ListArtifacts lists the artifact archives of a pipeline's jobs. Each
+// artifact is identified by its job.
+func (p *GitLabProvider) ListArtifacts(ctx context.Context, repo string, pipelineID int64) ([]Artifact, error) {
+	jobs, err := p.pipelineJobs(ctx, repo, pipelineID)
+	if err != nil {
+		return nil, err
+	}
+	var artifacts []Artifact
+	for _, job := range jobs {
+		if job.ArtifactsFile != nil {
+			artifacts = append(artifacts, Artifact{ID: job.ID, Name: job.Name, Size: job.ArtifactsFile.Size})
+		}
+	}
+	return artifacts, nil
+}
+
+// PipelineLogs writes the traces of a pipeline's jobs.
+func (p *GitLabProvider) PipelineLogs(ctx context.Context, repo string, id int64, w io.Writer) error {
+	jobs, err := p.pipelineJobs(ctx, repo, id)
+	if err != nil {
+		return err
+	}
+	for _, job := range jobs {
+		if err := writeLogHeader(w, job.Name); err != nil {
+			return err
+		}
+		if err := p.download(ctx, fmt.Sprintf("%s/jobs/%d/trace", p.projectPath(repo), job.ID), w); err != nil {
+			return fmt.Errorf("failed to get log of %s: %w", job.Name, err)
+		}
+	}
+	return nil
+}
+
+// DownloadArtifact writes a job's artifacts archive to w.
+func (p *GitLabProvider) DownloadArtifact(ctx context.Context, repo string, artifact Artifact, w io.Writer) error {
+	if err := p.download(ctx, fmt.Sprintf("%s/jobs/%d/artifacts", p.projectPath(repo), artifact.ID), w); err != nil {
+		return fmt.Errorf("failed to download artifact %s: %w", artifact.Name, err)
+	}
+	return nil
+}
+
+// download writes the body of a GET to w.
+func (p *GitLabProvider) download(ctx context.Context, path string, w io.Writer) error {
+	req, err := p.request(ctx, http.MethodGet, path, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if err := gitLabError(resp); err != nil {
+		return err
+	}
+	_, err = io.Copy(w, resp.Body)
+	return err
+}
+
+func (p *GitLabProvider) projectPath(repo string) string {
+	return "/projects/" + url.PathEscape(repo)
+}
+
+func (p *GitLabProvider) mergeRequestPath(repo string, number int) string {
+	return fmt.Sprintf("%s/merge_requests/%d", p.projectPath(repo), number)
+}
+
+func (p *GitLabProvider) request(ctx context.Context, method, path string, body any) (*http.Request, error) {
+	var reader io.Reader
+	if body != nil {
+		payload, err := json.Marshal(body)
+		if err != nil {
+			return nil, err
+		}
+		reader = bytes.NewReader(payload)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, p.baseURL+path, reader)
+	if err != nil {
+		return nil, err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if p.token != "" {
+		req.Header.Set("PRIVATE-TOKEN", p.token)
+	}
+	return req, nil
+}
+
+func (p *GitLabProvider) do(ctx context.Context, method, path string, body, out any) error {
+	req, err := p.request(ctx, method, path, body)
+	if err != nil {
+		return err
+	}
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if err := gitLabError(resp); err != nil {
+		return err
+	}
+	if out == nil {
+		return nil
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("failed to decode GitLab response: %w", err)
+	}
+	return nil
+}
+
+// gitLabError returns the error in an unsuccessful response.
+func gitLabError(resp *http.Response) error {
+	if resp.StatusCode/100 == 2 {
+		return nil
+	}
+	data, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+	var body struct {
+		Message any    `json:"message"`
+		Error   string `json:"error"`
+	}
+	if json.Unmarshal(data, &body) == nil {
+		switch {
+		case body.Message != nil:
+			return fmt.Errorf("GitLab returned %s: %v", resp.Status, body.Message)
+		case body.Error != "":
+			return fmt.Errorf("GitLab returned %s: %s", resp.Status, body.Error)
+		}
+	}
+	if len(bytes.TrimSpace(data)) == 0 {
+		return errors.New("GitLab returned " + resp.Status)
+	}
+	return fmt.Errorf("GitLab returned %s: %s", resp.Status, bytes.TrimSpace(data))
+}
@@ -0,0 +1,231 @@
+// Package vcs abstracts the code hosting operations Juleson automates, such
+// as merging change requests and downloading pipeline artifacts, over GitHub
+// GitLab, and Bitbucket Cloud.
+package vcs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Kind names a provider.
+type Kind string
+
+// Supported providers.
+const (
+	KindGitHub    Kind = "github"
+	KindGitLab    Kind = "gitlab"
+	KindBitbucket Kind = "bitbucket"
+)
+
+// ErrNotSupported is returned for operations a provider has no API for.
+var ErrNotSupported = errors.New("not supported by this provider")
+
+// ParseKind parses a provider name.
+func ParseKind(name string) (Kind, error) {
+	switch Kind(strings.ToLower(strings.TrimSpace(name))) {
+	case KindGitHub:
+		return KindGitHub, nil
+	case KindGitLab:
+		return KindGitLab, nil
+	case KindBitbucket:
+		return KindBitbucket, nil
+	default:
+		return "", fmt.Errorf("unknown VCS provider %q (use github, gitlab, or bitbucket)", name)
+	}
+}
+
+// Change request states.
+const (
+	StateOpen   = "open"
+	StateClosed = "closed"
+	StateMerged = "merged"
+)
+
+// StateAll selects change requests in any state.
+const StateAll = "all"
+
+// ChangeRequest is a GitHub or Bitbucket pull request, or a GitLab merge
+// request.
+type ChangeRequest struct {
+	Number       int    `json:"number"`
+	Title        string `json:"title"`
+	Description  string `json:"description,omitempty"`
+	URL          string `json:"url"`
+	Repo         string `json:"repo"`
+	SourceBranch string `json:"source_branch"`
+	TargetBranch string `json:"target_branch"`
+	Author       string `json:"author,omitempty"`
+	// State is open, closed, or merged.
+	State     string `json:"state"`
+	Mergeable bool   `json:"mergeable"`
+}
+
+// NewChangeRequest describes a change request to open.
+type NewChangeRequest struct {
+	Title        string
+	Description  string
+	SourceBranch string
+	// TargetBranch defaults to the repository's default branch.
+	TargetBranch string
+}
+
+// Issue is an issue in a repository.
+type Issue struct {
+	Number int    `json:"number"`
+	Title  string `json:"title"`
+	URL    string `json:"url"`
+}
+
+// Pipeline is a GitHub Actions workflow run, or a GitLab or Bitbucket
+// pipeline.
+type Pipeline struct {
+	// Repo is set when pipelines of several repositories are listed
+	// together.
+	Repo string `json:"repo,omitempty"`
+	ID   int64  `json:"id"`
+	Name string `json:"name,omitempty"`
+	Ref  string `json:"ref"`
+	SHA  string `json:"sha"`
+	// Status is the provider's status, such as success or failure.
+	Status    string    `json:"status"`
+	URL       string    `json:"url"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// PipelineTrigger describes a pipeline to start.
+type PipelineTrigger struct {
+	Ref string
+	// Workflow is the GitHub workflow file or ID, or the Bitbucket custom
+	// pipeline, to run. GitLab runs the project's pipeline for Ref.
+	Workflow  string
+	Variables map[string]string
+}
+
+// Artifact is a file produced by a pipeline.
+type Artifact struct {
+	ID   int64  `json:"id"`
+	Name string `json:"name"`
+	Size int64  `json:"size"`
+}
+
+// Provider performs code hosting operations on repositories of one host.
+// Repositories are paths such as owner/name or group/subgroup/name.
+// Operations a host has no API for return ErrNotSupported.
+type Provider interface {
+	Kind() Kind
+	// ListChangeRequests lists the most recent change requests in state
+	// open, closed, merged, or all.
+	ListChangeRequests(ctx context.Context, repo, state string, limit int) ([]ChangeRequest, error)
+	CreateChangeRequest(ctx context.Context, repo string, request NewChangeRequest) (*ChangeRequest, error)
+	GetChangeRequest(ctx context.Context, repo string, number int) (*ChangeRequest, error)
+	// MergeChangeRequest merges with method merge, squash, or rebase.
+	MergeChangeRequest(ctx context.Context, repo string, number int, method string) error
+	ChangeRequestDiff(ctx context.Context, repo string, number int) (string, error)
+	AddLabels(ctx context.Context, repo string, number int, labels []string) error
+	CreateIssue(ctx context.Context, repo, title, body string, labels []string) (*Issue, error)
+	// ListPipelines lists the most recent pipelines, of ref when it is set.
+	ListPipelines(ctx context.Context, repo, ref string, limit int) ([]Pipeline, error)
+	GetPipeline(ctx context.Context, repo string, id int64) (*Pipeline, error)
+	// TriggerPipeline starts a pipeline. It returns a nil pipeline when the
+	// host does not report the pipeline it started, as on GitHub.
+	TriggerPipeline(ctx context.Context, repo string, trigger PipelineTrigger) (*Pipeline, error)
+	// PipelineLogs writes the log of each of a pipeline's jobs to w, each
+	// under a "==> JOB <==" header.
+	PipelineLogs(ctx context.Context, repo string, id int64, w io.Writer) error
+	ListArtifacts(ctx context.Context, repo string, pipelineID int64) ([]Artifact, error)
+	// DownloadArtifact writes the artifact's archive to w.
+	DownloadArtifact(ctx context.Context, repo string, artifact Artifact, w io.Writer) error
+}
+
+// ChangeRequestRef locates a change request by its web URL.
+type ChangeRequestRef struct {
+	Kind   Kind
+	Host   string
+	Repo   string
+	Number int
+}
+
+// ParseChangeRequestURL parses a pull request URL, such as
+// https://github.com/owner/name/pull/12, or a merge request URL, such as
+// https://gitlab.com/group/name/-/merge_requests/7, or a Bitbucket pull
+// request URL, such as https://bitbucket.org/workspace/name/pull-requests/3.
+func ParseChangeRequestURL(rawURL string) (ChangeRequestRef, error) {
+	parsed, err := url.Parse(strings.TrimSpace(rawURL))
+	if err != nil || parsed.Host == "" {
+		return ChangeRequestRef{}, fmt.Errorf("invalid change request URL: %s", rawURL)
+	}
+	path := strings.Trim(parsed.Path, "/")
+	ref := ChangeRequestRef{Host: strings.ToLower(parsed.Host)}
+	var number string
+	if repo, rest, ok := strings.Cut(path, "/-/merge_requests/"); ok {
+		ref.Kind, ref.Repo, number = KindGitLab, repo, rest
+	} else {
+		parts := strings.Split(path, "/")
+		if len(parts) < 4 || (parts[2] != "pull" && parts[2] != "pull-requests") {
+			return ChangeRequestRef{}, fmt.Errorf("invalid change request URL: %s", rawURL)
+		}
+		ref.Kind, ref.Repo, number = KindGitHub, parts[0]+"/"+parts[1], parts[3]
+		if parts[2] == "pull-requests" {
+			ref.Kind = KindBitbucket
+		}
+	}
+	number, _, _ = strings.Cut(number, "/")
+	ref.Number, err = strconv.Atoi(number)
+	if err != nil || ref.Number <= 0 || ref.Repo == "" {
+		return ChangeRequestRef{}, fmt.Errorf("invalid change request URL: %s", rawURL)
+	}
+	return ref, nil
+}
+
+// ParseRemoteURL returns the host and repository path of a git remote URL
+// in HTTPS, SSH, or scp-like form.
+func ParseRemoteURL(remoteURL string) (host, repo string, err error) {
+	remoteURL = strings.TrimSpace(remoteURL)
+	switch {
+	case strings.Contains(remoteURL, "://"):
+		parsed, parseErr := url.Parse(remoteURL)
+		if parseErr != nil {
+			return "", "", fmt.Errorf("invalid git remote URL: %s", remoteURL)
+		}
+		host, repo = parsed.Hostname(), parsed.Path
+	case strings.Contains(remoteURL, ":"):
+		host, repo, _ = strings.Cut(remoteURL, ":")
+		if at := strings.LastIndex(host, "@"); at >= 0 {
+			host = host[at+1:]
+		}
+	}
+	repo = strings.TrimSuffix(strings.Trim(repo, "/"), ".git")
+	if host == "" || !strings.Contains(repo, "/") {
+		return "", "", fmt.Errorf("unsupported git remote URL: %s", remoteURL)
+	}
+	return strings.ToLower(host), repo, nil
+}
+
+// writeLogHeader starts the log of a job in PipelineLogs output.
+func writeLogHeader(w io.Writer, job string) error {
+	_, err := fmt.Fprintf(w, "==> %s <==\n", job)
+	return err
+}
+
+// validState checks a change request state filter.
+func validState(state string) error {
+	switch state {
+	case StateOpen, StateClosed, StateMerged, StateAll:
+		return nil
+	default:
+		return fmt.Errorf("invalid state %q (use open, closed, merged, or all)", state)
+	}
+}
+
+var (
+	_ Provider = (*GitHubProvider)(nil)
+	_ Provider = (*GitLabProvider)(nil)
+	_ Provider = (*BitbucketProvider)(nil)
+)
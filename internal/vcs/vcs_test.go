@@ -0,0 +1,327 @@
+package vcs
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/google/go-github/v76/github"
+)
+
+func TestParseChangeRequestURL(t *testing.T) {
+	tests := []struct {
+		url  string
+		want ChangeRequestRef
+	}{
+		{"https://github.com/acme/widgets/pull/12", ChangeRequestRef{Kind: KindGitHub, Host: "github.com", Repo: "acme/widgets", Number: 12}},
+		{"https://GHE.acme.dev/acme/widgets/pull/3/files", ChangeRequestRef{Kind: KindGitHub, Host: "ghe.acme.dev", Repo: "acme/widgets", Number: 3}},
+		{"https://gitlab.com/platform/tools/cli/-/merge_requests/7", ChangeRequestRef{Kind: KindGitLab, Host: "gitlab.com", Repo: "platform/tools/cli", Number: 7}},
+		{"https://gitlab.acme.dev/acme/api/-/merge_requests/9/diffs", ChangeRequestRef{Kind: KindGitLab, Host: "gitlab.acme.dev", Repo: "acme/api", Number: 9}},
+		{"https://bitbucket.org/acme/mobile/pull-requests/4/overview", ChangeRequestRef{Kind: KindBitbucket, Host: "bitbucket.org", Repo: "acme/mobile", Number: 4}},
+	}
+	for _, tt := range tests {
+		got, err := ParseChangeRequestURL(tt.url)
+		if err != nil || got != tt.want {
+			t.Errorf("ParseChangeRequestURL(%q) = %+v, %v, want %+v", tt.url, got, err, tt.want)
+		}
+	}
+	for _, bad := range []string{"acme/widgets#12", "https://github.com/acme/widgets/issues/12", "https://gitlab.com/acme/api/-/merge_requests/x"} {
+		if _, err := ParseChangeRequestURL(bad); err == nil {
+			t.Errorf("ParseChangeRequestURL(%q) succeeded", bad)
+		}
+	}
+}
+
+func TestParseRemoteURL(t *testing.T) {
+	tests := []struct {
+		remote, host, repo string
+	}{
+		{"https://github.com/acme/widgets.git", "github.com", "acme/widgets"},
+		{"git@gitlab.com:platform/tools/cli.git", "gitlab.com", "platform/tools/cli"},
+		{"ssh://git@GitLab.acme.dev:2222/acme/api", "gitlab.acme.dev", "acme/api"},
+	}
+	for _, tt := range tests {
+		host, repo, err := ParseRemoteURL(tt.remote)
+		if err != nil || host != tt.host || repo != tt.repo {
+			t.Errorf("ParseRemoteURL(%q) = %q, %q, %v", tt.remote, host, repo, err)
+		}
+	}
+	if _, _, err := ParseRemoteURL("/srv/git/widgets"); err == nil {
+		t.Error("ParseRemoteURL(local path) succeeded")
+	}
+}
+
+func TestParseKind(t *testing.T) {
+	if kind, err := ParseKind(" GitLab "); err != nil || kind != KindGitLab {
+		t.Fatalf("ParseKind() = %q, %v", kind, err)
+	}
+	if _, err := ParseKind("gitea"); err == nil {
+		t.Fatal("ParseKind(gitea) succeeded")
+	}
+}
+
+func TestGitLabProvider(t *testing.T) {
+	var requests []string
+	var bodies []map[string]any
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r.Method+" "+r.URL.EscapedPath())
+		if r.Header.Get("PRIVATE-TOKEN") != "glpat-secret" {
+			http.Error(w, `{"message":"401 Unauthorized"}`, http.StatusUnauthorized)
+			return
+		}
+		if r.Body != nil && r.Method != http.MethodGet {
+			var body map[string]any
+			_ = json.NewDecoder(r.Body).Decode(&body)
+			bodies = append(bodies, body)
+		}
+		switch r.Method + " " + r.URL.EscapedPath() {
+		case "GET /api/v4/projects/platform%2Fcli/merge_requests/7":
+			_, _ = w.Write([]byte(`{"iid":7,"title":"Fix lint","web_url":"https://gitlab.com/platform/cli/-/merge_requests/7",
+				"source_branch":"jules/lint","target_branch":"main","state":"opened","author":{"username":"bot"},
+				"detailed_merge_status":"mergeable"}`))
+		case "GET /api/v4/projects/platform%2Fcli/merge_requests/7/diffs":
+			_, _ = w.Write([]byte(`[{"old_path":"a.go","new_path":"a.go","diff":"@@ -1 +1 @@\n-x\n+y"},
+				{"old_path":"b.go","new_path":"b.go","new_file":true,"diff":"@@ -0,0 +1 @@\n+z\n"}]`))
+		case "GET /api/v4/projects/platform%2Fcli/pipelines":
+			if r.URL.Query().Get("ref") != "main" || r.URL.Query().Get("per_page") != "5" {
+				http.Error(w, `{"error":"bad query"}`, http.StatusBadRequest)
+				return
+			}
+			_, _ = w.Write([]byte(`[{"id":42,"ref":"main","sha":"abc","status":"failed","web_url":"https://gitlab.com/p/42","created_at":"2026-10-01T12:00:00Z"}]`))
+		case "GET /api/v4/projects/platform%2Fcli/pipelines/42/jobs":
+			_, _ = w.Write([]byte(`[{"id":100,"name":"test","artifacts_file":{"size":2048}},{"id":101,"name":"lint"}]`))
+		case "GET /api/v4/projects/platform%2Fcli/jobs/100/artifacts":
+			_, _ = w.Write([]byte("zip-bytes"))
+		case "PUT /api/v4/projects/platform%2Fcli/merge_requests/8/merge":
+			http.Error(w, `{"message":"405 Method Not Allowed"}`, http.StatusMethodNotAllowed)
+		default:
+			_, _ = w.Write([]byte(`{"iid":3,"title":"Flaky test","web_url":"https://gitlab.com/platform/cli/-/issues/3"}`))
+		}
+	}))
+	defer server.Close()
+
+	provider := NewGitLabProvider(server.URL+"/api/v4/", "glpat-secret")
+	ctx := context.Background()
+	repo := "platform/cli"
+
+	cr, err := provider.GetChangeRequest(ctx, repo, 7)
+	if err != nil || cr.State != StateOpen || !cr.Mergeable || cr.SourceBranch != "jules/lint" || cr.Author != "bot" {
+		t.Fatalf("GetChangeRequest() = %+v, %v", cr, err)
+	}
+	diff, err := provider.ChangeRequestDiff(ctx, repo, 7)
+	wantDiff := "diff --git a/a.go b/a.go\n--- a/a.go\n+++ b/a.go\n@@ -1 +1 @@\n-x\n+y\n" +
+		"diff --git a/b.go b/b.go\n--- /dev/null\n+++ b/b.go\n@@ -0,0 +1 @@\n+z\n"
+	if err != nil || diff != wantDiff {
+		t.Fatalf("ChangeRequestDiff() = %q, %v", diff, err)
+	}
+	if err := provider.MergeChangeRequest(ctx, repo, 7, "rebase"); err == nil {
+		t.Fatal("MergeChangeRequest(rebase) succeeded")
+	}
+	if err := provider.MergeChangeRequest(ctx, repo, 7, ""); err != nil {
+		t.Fatalf("MergeChangeRequest() error = %v", err)
+	}
+	if err := provider.MergeChangeRequest(ctx, repo, 8, "merge"); err == nil || !strings.Contains(err.Error(), "405 Method Not Allowed") {
+		t.Fatalf("MergeChangeRequest(not allowed) error = %v", err)
+	}
+	if err := provider.AddLabels(ctx, repo, 7, []string{"jules", "bot"}); err != nil {
+		t.Fatalf("AddLabels() error = %v", err)
+	}
+	issue, err := provider.CreateIssue(ctx, repo, "Flaky test", "details", nil)
+	if err != nil || issue.Number != 3 {
+		t.Fatalf("CreateIssue() = %+v, %v", issue, err)
+	}
+
+	pipelines, err := provider.ListPipelines(ctx, repo, "main", 5)
+	if err != nil || len(pipelines) != 1 || pipelines[0].ID != 42 || pipelines[0].Status != "failed" {
+		t.Fatalf("ListPipelines() = %+v, %v", pipelines, err)
+	}
+	artifacts, err := provider.ListArtifacts(ctx, repo, 42)
+	if err != nil || !reflect.DeepEqual(artifacts, []Artifact{{ID: 100, Name: "test", Size: 2048}}) {
+		t.Fatalf("ListArtifacts() = %+v, %v", artifacts, err)
+	}
+	var archive bytes.Buffer
+	if err := provider.DownloadArtifact(ctx, repo, artifacts[0], &archive); err != nil || archive.String() != "zip-bytes" {
+		t.Fatalf("DownloadArtifact() = %q, %v", archive.String(), err)
+	}
+
+	wantBodies := []map[string]any{
+		{"squash": true},
+		{},
+		{"add_labels": "jules,bot"},
+		{"title": "Flaky test", "description": "details"},
+	}
+	if !reflect.DeepEqual(bodies, wantBodies) {
+		t.Fatalf("bodies = %v, want %v", bodies, wantBodies)
+	}
+	if requests[2] != "PUT /api/v4/projects/platform%2Fcli/merge_requests/7/merge" {
+		t.Fatalf("requests = %q", requests)
+	}
+
+	if _, err := NewGitLabProvider(server.URL+"/api/v4", "wrong").GetChangeRequest(ctx, repo, 7); err == nil || !strings.Contains(err.Error(), "401 Unauthorized") {
+		t.Fatalf("GetChangeRequest(unauthorized) error = %v", err)
+	}
+}
+
+func TestBitbucketProvider(t *testing.T) {
+	var requests []string
+	var bodies []map[string]any
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r.Method+" "+r.URL.Path)
+		if r.Header.Get("Authorization") != "Bearer bb-token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			_, _ = w.Write([]byte(`{"type":"error","error":{"message":"Access token expired"}}`))
+			return
+		}
+		if r.Method == http.MethodPost {
+			var body map[string]any
+			_ = json.NewDecoder(r.Body).Decode(&body)
+			bodies = append(bodies, body)
+		}
+		const pr = `{"id":4,"title":"Fix lint","state":"OPEN","author":{"nickname":"bot"},
+			"source":{"branch":{"name":"jules/lint"}},"destination":{"branch":{"name":"main"}},
+			"links":{"html":{"href":"https://bitbucket.org/acme/mobile/pull-requests/4"}}}`
+		switch r.Method + " " + r.URL.Path {
+		case "GET /repositories/acme/mobile/pullrequests":
+			if r.URL.Query().Get("page") == "2" {
+				_, _ = w.Write([]byte(`{"values":[{"id":2,"title":"Old","state":"OPEN"}]}`))
+				return
+			}
+			if got := r.URL.Query()["state"]; !reflect.DeepEqual(got, []string{"OPEN"}) {
+				t.Errorf("state query = %q", got)
+			}
+			_, _ = w.Write([]byte(`{"values":[` + pr + `],"next":"` + server.URL + `/repositories/acme/mobile/pullrequests?page=2"}`))
+		case "GET /repositories/acme/mobile/pullrequests/4", "POST /repositories/acme/mobile/pullrequests":
+			_, _ = w.Write([]byte(pr))
+		case "GET /repositories/acme/mobile/pullrequests/4/diff":
+			_, _ = w.Write([]byte("diff --git a/a.go b/a.go\n"))
+		case "POST /repositories/acme/mobile/pipelines/":
+			_, _ = w.Write([]byte(`{"build_number":17,"state":{"name":"PENDING"},"target":{"ref_name":"main","selector":{"pattern":"nightly"}}}`))
+		case "GET /repositories/acme/mobile/pipelines/17":
+			_, _ = w.Write([]byte(`{"build_number":17,"state":{"name":"COMPLETED","result":{"name":"FAILED"}},"target":{"ref_name":"main","commit":{"hash":"abc"}}}`))
+		case "GET /repositories/acme/mobile/pipelines/":
+			_, _ = w.Write([]byte(`{"values":[{"build_number":18,"target":{"ref_name":"dev"}},{"build_number":17,"target":{"ref_name":"main"}}]}`))
+		default:
+			w.WriteHeader(http.StatusOK)
+		}
+	}))
+	defer server.Close()
+
+	provider := NewBitbucketProvider(server.URL, "", "bb-token")
+	ctx := context.Background()
+	repo := "acme/mobile"
+
+	changes, err := provider.ListChangeRequests(ctx, repo, StateOpen, 5)
+	if err != nil || len(changes) != 2 || changes[0].Number != 4 || changes[1].Number != 2 {
+		t.Fatalf("ListChangeRequests() = %+v, %v", changes, err)
+	}
+	cr, err := provider.CreateChangeRequest(ctx, repo, NewChangeRequest{Title: "Fix lint", SourceBranch: "jules/lint"})
+	if err != nil || cr.State != StateOpen || !cr.Mergeable || cr.TargetBranch != "main" || cr.Author != "bot" {
+		t.Fatalf("CreateChangeRequest() = %+v, %v", cr, err)
+	}
+	if err := provider.MergeChangeRequest(ctx, repo, 4, "merge"); err != nil {
+		t.Fatalf("MergeChangeRequest() error = %v", err)
+	}
+	if err := provider.MergeChangeRequest(ctx, repo, 4, "rebase"); err == nil {
+		t.Fatal("MergeChangeRequest(rebase) succeeded")
+	}
+	if diff, err := provider.ChangeRequestDiff(ctx, repo, 4); err != nil || diff != "diff --git a/a.go b/a.go\n" {
+		t.Fatalf("ChangeRequestDiff() = %q, %v", diff, err)
+	}
+	if err := provider.AddLabels(ctx, repo, 4, []string{"jules"}); !errors.Is(err, ErrNotSupported) {
+		t.Fatalf("AddLabels() error = %v", err)
+	}
+	if _, err := provider.ListArtifacts(ctx, repo, 17); !errors.Is(err, ErrNotSupported) {
+		t.Fatalf("ListArtifacts() error = %v", err)
+	}
+
+	pipeline, err := provider.TriggerPipeline(ctx, repo, PipelineTrigger{Ref: "main", Workflow: "nightly", Variables: map[string]string{"ENV": "staging"}})
+	if err != nil || pipeline.ID != 17 || pipeline.Status != "pending" || pipeline.URL != "https://bitbucket.org/acme/mobile/pipelines/results/17" {
+		t.Fatalf("TriggerPipeline() = %+v, %v", pipeline, err)
+	}
+	pipeline, err = provider.GetPipeline(ctx, repo, 17)
+	if err != nil || pipeline.Status != "failed" || pipeline.SHA != "abc" {
+		t.Fatalf("GetPipeline() = %+v, %v", pipeline, err)
+	}
+	pipelines, err := provider.ListPipelines(ctx, repo, "main", 5)
+	if err != nil || len(pipelines) != 1 || pipelines[0].ID != 17 {
+		t.Fatalf("ListPipelines() = %+v, %v", pipelines, err)
+	}
+
+	wantBodies := []map[string]any{
+		{"title": "Fix lint", "description": "", "source": map[string]any{"branch": map[string]any{"name": "jules/lint"}}},
+		{"merge_strategy": "merge_commit"},
+		{
+			"target": map[string]any{
+				"type": "pipeline_ref_target", "ref_type": "branch", "ref_name": "main",
+				"selector": map[string]any{"type": "custom", "pattern": "nightly"},
+			},
+			"variables": []any{map[string]any{"key": "ENV", "value": "staging"}},
+		},
+	}
+	if !reflect.DeepEqual(bodies, wantBodies) {
+		t.Fatalf("bodies = %v, want %v", bodies, wantBodies)
+	}
+
+	if _, err := NewBitbucketProvider(server.URL, "", "old").GetChangeRequest(ctx, repo, 4); err == nil || !strings.Contains(err.Error(), "Access token expired") {
+		t.Fatalf("GetChangeRequest(unauthorized) error = %v", err)
+	}
+	if _, err := provider.GetChangeRequest(ctx, "acme", 4); err == nil {
+		t.Fatal("GetChangeRequest(invalid repo) succeeded")
+	}
+}
+
+func TestGitHubProviderListPipelinesPaginates(t *testing.T) {
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/repos/acme/widgets/actions/runs" && r.URL.Path != "/repos/acme/widgets/actions/workflows/ci.yml/runs" {
+			t.Errorf("unexpected request %s", r.URL.Path)
+			http.NotFound(w, r)
+			return
+		}
+		page := r.URL.Query().Get("page")
+		if page == "" {
+			page = "1"
+		}
+		if page != "3" {
+			next, _ := strconv.Atoi(page)
+			w.Header().Set("Link", fmt.Sprintf(`<%s%s?page=%d>; rel="next"`, server.URL, r.URL.Path, next+1))
+		}
+		var runs []map[string]any
+		for i := range 2 {
+			runs = append(runs, map[string]any{"id": i + 1, "name": "CI page " + page, "status": "completed", "conclusion": "success"})
+		}
+		_ = json.NewEncoder(w).Encode(map[string]any{"total_count": 6, "workflow_runs": runs})
+	}))
+	defer server.Close()
+
+	client := github.NewClient(nil)
+	client.BaseURL, _ = url.Parse(server.URL + "/")
+	provider := NewGitHubProvider(client)
+	ctx := context.Background()
+
+	all, err := provider.ListPipelines(ctx, "acme/widgets", "", 0)
+	if err != nil {
+		t.Fatalf("ListPipelines() error = %v", err)
+	}
+	if len(all) != 6 || all[5].Name != "CI page 3" {
+		t.Fatalf("ListPipelines(limit 0) = %+v, want the 6 runs of all 3 pages", all)
+	}
+	limited, err := provider.ListPipelines(ctx, "acme/widgets", "", 3)
+	if err != nil || len(limited) != 3 || limited[2].Name != "CI page 2" {
+		t.Fatalf("ListPipelines(limit 3) = %+v, %v", limited, err)
+	}
+	workflow, err := provider.ListWorkflowPipelines(ctx, "acme/widgets", "ci.yml", "", 0)
+	if err != nil || len(workflow) != 6 {
+		t.Fatalf("ListWorkflowPipelines() = %+v, %v", workflow, err)
+	}
+}
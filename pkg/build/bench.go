@@ -0,0 +1,307 @@
+package build
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// BenchConfig selects the benchmarks a Bencher runs.
+type BenchConfig struct {
+	WorkingDir string
+	// Pattern is the -bench regular expression (default ".").
+	Pattern   string
+	Benchtime string
+	Packages  []string
+	Count     int
+	Timeout   time.Duration
+}
+
+// BenchResult is one benchmark's measurements, averaged over its runs.
+type BenchResult struct {
+	// Name is the benchmark name without the GOMAXPROCS suffix, such as
+	// "BenchmarkBusPublish/subscribers=4".
+	Name        string  `json:"name"`
+	Package     string  `json:"package,omitempty"`
+	Runs        int     `json:"runs"`
+	Iterations  int64   `json:"iterations"`
+	NsPerOp     float64 `json:"ns_per_op"`
+	BytesPerOp  float64 `json:"bytes_per_op"`
+	AllocsPerOp float64 `json:"allocs_per_op"`
+}
+
+// BenchBudget caps the cost of the benchmarks whose names match Name, a
+// path.Match pattern such as "BenchmarkBusPublish/*". Zero limits are not
+// checked.
+type BenchBudget struct {
+	Name           string
+	MaxNsPerOp     float64
+	MaxBytesPerOp  float64
+	MaxAllocsPerOp float64
+}
+
+// BenchViolation is a benchmark measurement over its budget or regressed
+// from its baseline.
+type BenchViolation struct {
+	Name   string  `json:"name"`
+	Metric string  `json:"metric"`
+	Value  float64 `json:"value"`
+	Limit  float64 `json:"limit"`
+	Reason string  `json:"reason"`
+}
+
+func (v BenchViolation) String() string {
+	return fmt.Sprintf("%s: %s %.4g %s (limit %.4g)", v.Name, v.Metric, v.Value, v.Reason, v.Limit)
+}
+
+// BenchReport is the outcome of a benchmark run.
+type BenchReport struct {
+	Results    []BenchResult    `json:"results"`
+	Violations []BenchViolation `json:"violations,omitempty"`
+	Output     string           `json:"-"`
+	Duration   time.Duration    `json:"duration"`
+}
+
+// Passed reports whether every benchmark is within its budgets.
+func (r *BenchReport) Passed() bool {
+	return len(r.Violations) == 0
+}
+
+func (r *BenchReport) String() string {
+	if r == nil {
+		return "no benchmark result"
+	}
+	if !r.Passed() {
+		return fmt.Sprintf("%d benchmark(s) ran in %s, %d over budget", len(r.Results), r.Duration.Round(time.Millisecond), len(r.Violations))
+	}
+	return fmt.Sprintf("%d benchmark(s) within budget in %s", len(r.Results), r.Duration.Round(time.Millisecond))
+}
+
+// Bencher runs Go benchmarks and parses their results.
+type Bencher struct {
+	config BenchConfig
+}
+
+// DefaultBenchConfig benchmarks the event bus, queue, and store.
+func DefaultBenchConfig() BenchConfig {
+	return BenchConfig{
+		Pattern:  ".",
+		Packages: []string{"./internal/events"},
+		Count:    1,
+		Timeout:  10 * time.Minute,
+	}
+}
+
+func NewBencher(config BenchConfig) *Bencher {
+	return &Bencher{config: config}
+}
+
+// Run runs the benchmarks, without the packages' tests, and returns their
+// results. Budgets are checked by the caller.
+func (b *Bencher) Run(ctx context.Context) (*BenchReport, error) {
+	start := time.Now()
+	pattern := b.config.Pattern
+	if pattern == "" {
+		pattern = "."
+	}
+	args := []string{"test", "-run", "^$", "-bench", pattern, "-benchmem"}
+	if b.config.Benchtime != "" {
+		args = append(args, "-benchtime", b.config.Benchtime)
+	}
+	if b.config.Count > 0 {
+		args = append(args, "-count", strconv.Itoa(b.config.Count))
+	}
+	if b.config.Timeout > 0 {
+		args = append(args, "-timeout", b.config.Timeout.String())
+	}
+	packages := b.config.Packages
+	if len(packages) == 0 {
+		packages = DefaultBenchConfig().Packages
+	}
+	args = append(args, packages...)
+
+	cmd := exec.CommandContext(ctx, "go", args...)
+	cmd.Dir = b.config.WorkingDir
+	out, err := cmd.CombinedOutput()
+	report := &BenchReport{Output: string(out), Duration: time.Since(start)}
+	if err != nil {
+		return report, fmt.Errorf("%w: %s", err, strings.TrimSpace(report.Output))
+	}
+	report.Results = ParseBenchOutput(report.Output)
+	if len(report.Results) == 0 {
+		return report, fmt.Errorf("no benchmark matches %q in %s", pattern, strings.Join(packages, " "))
+	}
+	return report, nil
+}
+
+// benchLine matches a result line of go test -bench -benchmem, such as
+// "BenchmarkBusPublish-8   1000000   1043 ns/op   208 B/op   3 allocs/op".
+var benchLine = regexp.MustCompile(`^(Benchmark\S+?)(?:-\d+)?\s+(\d+)\s+(.*)$`)
+
+// ParseBenchOutput returns the results in go test -bench output, averaging
+// repeated runs of a benchmark, in the order they first appear.
+func ParseBenchOutput(output string) []BenchResult {
+	var (
+		results []BenchResult
+		index   = map[string]int{}
+		pkg     string
+	)
+	scanner := bufio.NewScanner(strings.NewReader(output))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if rest, ok := strings.CutPrefix(line, "pkg: "); ok {
+			pkg = rest
+			continue
+		}
+		match := benchLine.FindStringSubmatch(line)
+		if match == nil {
+			continue
+		}
+		iterations, err := strconv.ParseInt(match[2], 10, 64)
+		if err != nil {
+			continue
+		}
+		run := BenchResult{Name: match[1], Package: pkg, Runs: 1, Iterations: iterations}
+		fields := strings.Fields(match[3])
+		for i := 0; i+1 < len(fields); i += 2 {
+			value, err := strconv.ParseFloat(fields[i], 64)
+			if err != nil {
+				continue
+			}
+			switch fields[i+1] {
+			case "ns/op":
+				run.NsPerOp = value
+			case "B/op":
+				run.BytesPerOp = value
+			case "allocs/op":
+				run.AllocsPerOp = value
+			}
+		}
+
+		key := pkg + " " + run.Name
+		i, seen := index[key]
+		if !seen {
+			index[key] = len(results)
+			results = append(results, run)
+			continue
+		}
+		result := &results[i]
+		n := float64(result.Runs)
+		result.NsPerOp = (result.NsPerOp*n + run.NsPerOp) / (n + 1)
+		result.BytesPerOp = (result.BytesPerOp*n + run.BytesPerOp) / (n + 1)
+		result.AllocsPerOp = (result.AllocsPerOp*n + run.AllocsPerOp) / (n + 1)
+		result.Iterations += run.Iterations
+		result.Runs++
+	}
+	return results
+}
+
+// CheckBudgets returns the measurements of results over the first budget
+// whose name matches each benchmark.
+func CheckBudgets(results []BenchResult, budgets []BenchBudget) []BenchViolation {
+	var violations []BenchViolation
+	for _, result := range results {
+		i := slices.IndexFunc(budgets, func(budget BenchBudget) bool {
+			matched, _ := path.Match(budget.Name, result.Name)
+			return matched
+		})
+		if i < 0 {
+			continue
+		}
+		budget := budgets[i]
+		for _, check := range []struct {
+			metric       string
+			value, limit float64
+		}{
+			{"ns/op", result.NsPerOp, budget.MaxNsPerOp},
+			{"B/op", result.BytesPerOp, budget.MaxBytesPerOp},
+			{"allocs/op", result.AllocsPerOp, budget.MaxAllocsPerOp},
+		} {
+			if check.limit > 0 && check.value > check.limit {
+				violations = append(violations, BenchViolation{
+					Name: result.Name, Metric: check.metric, Value: check.value, Limit: check.limit,
+					Reason: "over budget",
+				})
+			}
+		}
+	}
+	return violations
+}
+
+// CheckRegressions returns the measurements of results more than
+// maxRegression percent worse than the same benchmark in baseline.
+// Allocations may not grow at all past the tolerance, so a new allocation
+// in a zero-allocation path is always a regression.
+func CheckRegressions(results, baseline []BenchResult, maxRegression float64) []BenchViolation {
+	if maxRegression <= 0 {
+		return nil
+	}
+	previous := map[string]BenchResult{}
+	for _, result := range baseline {
+		previous[result.Package+" "+result.Name] = result
+	}
+	var violations []BenchViolation
+	for _, result := range results {
+		base, ok := previous[result.Package+" "+result.Name]
+		if !ok {
+			continue
+		}
+		for _, check := range []struct {
+			metric      string
+			value, base float64
+		}{
+			{"ns/op", result.NsPerOp, base.NsPerOp},
+			{"B/op", result.BytesPerOp, base.BytesPerOp},
+			{"allocs/op", result.AllocsPerOp, base.AllocsPerOp},
+		} {
+			limit := check.base * (1 + maxRegression/100)
+			if check.value > limit && (check.base > 0 || check.metric == "allocs/op") {
+				violations = append(violations, BenchViolation{
+					Name: result.Name, Metric: check.metric, Value: check.value, Limit: limit,
+					Reason: fmt.Sprintf("regressed more than %g%% from baseline %.4g", maxRegression, check.base),
+				})
+			}
+		}
+	}
+	return violations
+}
+
+// LoadBenchBaseline reads results saved by SaveBenchBaseline. A missing
+// file is an empty baseline.
+func LoadBenchBaseline(file string) ([]BenchResult, error) {
+	data, err := os.ReadFile(file)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var results []BenchResult
+	if err := json.Unmarshal(data, &results); err != nil {
+		return nil, fmt.Errorf("invalid benchmark baseline %s: %w", file, err)
+	}
+	return results, nil
+}
+
+// SaveBenchBaseline writes results to file for later runs to compare
+// against.
+func SaveBenchBaseline(file string, results []BenchResult) error {
+	data, err := json.MarshalIndent(results, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(file), 0o755); err != nil {
+		return err
+	}
+	return os.WriteFile(file, append(data, '\n'), 0o644)
+}
@@ -0,0 +1,88 @@
+package build
+
+import (
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const benchOutput = `goos: linux
+goarch: amd64
+pkg: github.com/SamyRai/juleson/internal/events
+cpu: Intel(R) Xeon(R) Processor
+BenchmarkBusPublish/subscribers=1-8     	  800000	      1500 ns/op	     470 B/op	      10 allocs/op
+BenchmarkBusPublish/subscribers=1-8     	 1000000	      1300 ns/op	     470 B/op	      10 allocs/op
+BenchmarkStoreAppend/journal-8          	   50000	     20773 ns/op	    2905 B/op	      31 allocs/op
+BenchmarkPriorityQueuePushPop           	 5000000	       203.9 ns/op
+PASS
+ok  	github.com/SamyRai/juleson/internal/events	6.314s
+`
+
+func TestParseBenchOutput(t *testing.T) {
+	pkg := "github.com/SamyRai/juleson/internal/events"
+	want := []BenchResult{
+		{Name: "BenchmarkBusPublish/subscribers=1", Package: pkg, Runs: 2, Iterations: 1800000, NsPerOp: 1400, BytesPerOp: 470, AllocsPerOp: 10},
+		{Name: "BenchmarkStoreAppend/journal", Package: pkg, Runs: 1, Iterations: 50000, NsPerOp: 20773, BytesPerOp: 2905, AllocsPerOp: 31},
+		{Name: "BenchmarkPriorityQueuePushPop", Package: pkg, Runs: 1, Iterations: 5000000, NsPerOp: 203.9},
+	}
+	if got := ParseBenchOutput(benchOutput); !reflect.DeepEqual(got, want) {
+		t.Fatalf("ParseBenchOutput() = %+v, want %+v", got, want)
+	}
+}
+
+func TestCheckBudgets(t *testing.T) {
+	results := ParseBenchOutput(benchOutput)
+	violations := CheckBudgets(results, []BenchBudget{
+		{Name: "BenchmarkBusPublish/*", MaxNsPerOp: 1000, MaxAllocsPerOp: 10},
+		{Name: "BenchmarkStoreAppend/*", MaxAllocsPerOp: 20},
+		// Only the first matching budget applies.
+		{Name: "BenchmarkStoreAppend/journal", MaxNsPerOp: 1},
+	})
+	var got []string
+	for _, violation := range violations {
+		got = append(got, violation.Name+" "+violation.Metric)
+	}
+	want := []string{"BenchmarkBusPublish/subscribers=1 ns/op", "BenchmarkStoreAppend/journal allocs/op"}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("violations = %q, want %q", got, want)
+	}
+}
+
+func TestCheckRegressions(t *testing.T) {
+	results := ParseBenchOutput(benchOutput)
+	file := filepath.Join(t.TempDir(), "bench", "baseline.json")
+	baseline := []BenchResult{
+		{Name: "BenchmarkBusPublish/subscribers=1", Package: results[0].Package, NsPerOp: 1300, BytesPerOp: 470, AllocsPerOp: 10},
+		{Name: "BenchmarkStoreAppend/journal", Package: results[1].Package, NsPerOp: 10000, BytesPerOp: 2905, AllocsPerOp: 31},
+		{Name: "BenchmarkPriorityQueuePushPop", Package: results[2].Package, NsPerOp: 200, AllocsPerOp: 0},
+	}
+	if err := SaveBenchBaseline(file, baseline); err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := LoadBenchBaseline(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	violations := CheckRegressions(results, loaded, 10)
+	if len(violations) != 1 || violations[0].Name != "BenchmarkStoreAppend/journal" || violations[0].Metric != "ns/op" {
+		t.Fatalf("violations = %+v, want the store append time", violations)
+	}
+	if !strings.Contains(violations[0].String(), "regressed more than 10% from baseline") {
+		t.Fatalf("violation = %q", violations[0])
+	}
+
+	// A new allocation in an allocation-free benchmark is always a
+	// regression.
+	results[2].AllocsPerOp = 1
+	if violations := CheckRegressions(results[2:], loaded, 10); len(violations) != 1 || violations[0].Metric != "allocs/op" {
+		t.Fatalf("violations = %+v, want the new allocation", violations)
+	}
+	if violations := CheckRegressions(results, loaded, 0); violations != nil {
+		t.Fatalf("violations without a regression limit = %+v", violations)
+	}
+	if missing, err := LoadBenchBaseline(filepath.Join(t.TempDir(), "missing.json")); err != nil || missing != nil {
+		t.Fatalf("LoadBenchBaseline(missing) = %v, %v", missing, err)
+	}
+}
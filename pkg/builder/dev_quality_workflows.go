@@ -3,6 +3,7 @@ package builder
 import (
 	"context"
 	"fmt"
+	"strings"
 
 	"github.com/SamyRai/juleson/pkg/build"
 )
@@ -11,17 +12,31 @@ type QualityOptions struct {
 	TestConfig   build.TestConfig
 	BuildOptions BuildOptions
 	LintConfig   build.LintConfig
+	BenchOptions BenchOptions
 	FormatPaths  []string
 	Format       bool
 	UseGofumpt   bool
 	Lint         bool
 	Test         bool
+	Bench        bool
 	Build        bool
 }
 
+// BenchOptions selects the benchmarks to run and what they are checked
+// against.
+type BenchOptions struct {
+	Config  build.BenchConfig
+	Budgets []build.BenchBudget
+	// Baseline is the file of saved results MaxRegression, a percentage,
+	// is measured from. Zero MaxRegression skips the comparison.
+	Baseline      string
+	MaxRegression float64
+}
+
 type QualitySummary struct {
 	LintResult   *build.LintResult
 	TestResult   *build.TestResult
+	BenchReport  *build.BenchReport
 	BuildSummary *BuildSummary
 	Checks       []string
 }
@@ -38,6 +53,28 @@ func (s *Service) GenerateCoverageHTML(ctx context.Context, config build.TestCon
 	return build.NewTester(config).GenerateCoverageHTML(ctx, outputPath)
 }
 
+// RunBenchmarks runs the benchmarks and records the results over their
+// budgets or regressed from the baseline as violations in the report.
+func (s *Service) RunBenchmarks(ctx context.Context, options BenchOptions) (*build.BenchReport, error) {
+	report, err := build.NewBencher(options.Config).Run(ctx)
+	if err != nil {
+		return report, err
+	}
+	report.Violations = build.CheckBudgets(report.Results, options.Budgets)
+	if options.MaxRegression > 0 && options.Baseline != "" {
+		baseline, err := build.LoadBenchBaseline(options.Baseline)
+		if err != nil {
+			return report, err
+		}
+		report.Violations = append(report.Violations, build.CheckRegressions(report.Results, baseline, options.MaxRegression)...)
+	}
+	return report, nil
+}
+
+func DefaultBenchConfig() build.BenchConfig {
+	return build.DefaultBenchConfig()
+}
+
 func (s *Service) LintWithResult(ctx context.Context, config build.LintConfig) *build.LintResult {
 	return build.NewLinter(config).LintWithResult(ctx)
 }
@@ -84,6 +121,22 @@ func (s *Service) RunQualityChecks(ctx context.Context, options QualityOptions)
 		summary.Checks = append(summary.Checks, "Tests")
 	}
 
+	if options.Bench {
+		report, err := s.RunBenchmarks(ctx, options.BenchOptions)
+		summary.BenchReport = report
+		if err != nil {
+			return summary, fmt.Errorf("benchmarks failed: %w", err)
+		}
+		if !report.Passed() {
+			violations := make([]string, 0, len(report.Violations))
+			for _, violation := range report.Violations {
+				violations = append(violations, violation.String())
+			}
+			return summary, fmt.Errorf("benchmarks over budget:\n  %s", strings.Join(violations, "\n  "))
+		}
+		summary.Checks = append(summary.Checks, "Benchmarks")
+	}
+
 	if options.Build {
 		buildOptions := options.BuildOptions
 		if buildOptions.Target == "" {
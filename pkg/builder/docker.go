@@ -4,13 +4,24 @@ import (
 	"context"
 	"fmt"
 	"os"
+
+	"github.com/SamyRai/juleson/pkg/docker"
+	"github.com/moby/term"
 )
 
 // DockerBuild builds the Docker image.
 func (s *Service) DockerBuild(ctx context.Context) error {
-	if err := s.runCommand(ctx, "docker", "build", "-t", s.config.DockerImage, "."); err != nil {
+	cli, err := docker.NewClient()
+	if err != nil {
+		return err
+	}
+	defer cli.Close()
+
+	imageID, err := cli.BuildImage(ctx, docker.BuildOptions{ContextDir: ".", Tags: []string{s.config.DockerImage}}, s.stdout)
+	if err != nil {
 		return fmt.Errorf("docker build failed: %w", err)
 	}
+	fmt.Fprintf(s.stdout, "Built %s (%s)\n", s.config.DockerImage, imageID)
 
 	return nil
 }
@@ -22,21 +33,7 @@ func (s *Service) DockerRun(ctx context.Context, args []string) error {
 		return err
 	}
 
-	workDir, err := os.Getwd()
-	if err != nil {
-		workDir = "."
-	}
-
-	dockerArgs := []string{
-		"run", "--rm", "-it",
-		"-e", "JULES_API_KEY=" + os.Getenv("JULES_API_KEY"),
-		"-v", fmt.Sprintf("%s:/workspace", workDir),
-		"-w", "/workspace",
-		s.config.DockerImage,
-	}
-	dockerArgs = append(dockerArgs, args...)
-
-	if err := s.runCommand(ctx, "docker", dockerArgs...); err != nil {
+	if err := s.runContainer(ctx, args); err != nil {
 		return fmt.Errorf("docker run failed: %w", err)
 	}
 
@@ -50,23 +47,52 @@ func (s *Service) DockerRunCLI(ctx context.Context, args []string) error {
 		return err
 	}
 
+	cmd := append([]string{"./" + s.config.BinaryCLI}, args...)
+	if err := s.runContainer(ctx, cmd); err != nil {
+		return fmt.Errorf("docker run CLI failed: %w", err)
+	}
+
+	return nil
+}
+
+// runContainer runs cmd in the project image with the working directory
+// mounted at /workspace. Stdin is attached, with a TTY when it is a terminal.
+func (s *Service) runContainer(ctx context.Context, cmd []string) error {
+	cli, err := docker.NewClient()
+	if err != nil {
+		return err
+	}
+	defer cli.Close()
+
 	workDir, err := os.Getwd()
 	if err != nil {
 		workDir = "."
 	}
 
-	dockerArgs := []string{
-		"run", "--rm", "-it",
-		"-e", "JULES_API_KEY=" + os.Getenv("JULES_API_KEY"),
-		"-v", fmt.Sprintf("%s:/workspace", workDir),
-		"-w", "/workspace",
-		s.config.DockerImage,
-		"./" + s.config.BinaryCLI,
+	opts := docker.RunOptions{
+		Image:      s.config.DockerImage,
+		Cmd:        cmd,
+		Env:        []string{"JULES_API_KEY=" + os.Getenv("JULES_API_KEY")},
+		WorkingDir: "/workspace",
+		Mounts:     []docker.Mount{{Source: workDir, Target: "/workspace"}},
+		Stdin:      os.Stdin,
+		Stdout:     s.stdout,
+		Stderr:     s.stderr,
+	}
+	if fd, isTerminal := term.GetFdInfo(os.Stdin); isTerminal {
+		state, err := term.SetRawTerminal(fd)
+		if err == nil {
+			defer func() { _ = term.RestoreTerminal(fd, state) }()
+			opts.TTY = true
+		}
 	}
-	dockerArgs = append(dockerArgs, args...)
 
-	if err := s.runCommand(ctx, "docker", dockerArgs...); err != nil {
-		return fmt.Errorf("docker run CLI failed: %w", err)
+	result, err := cli.Run(ctx, opts)
+	if err != nil {
+		return err
+	}
+	if result.ExitCode != 0 {
+		return fmt.Errorf("container %s exited with code %d", shortID(result.ContainerID), result.ExitCode)
 	}
 
 	return nil
@@ -79,14 +105,21 @@ func (s *Service) DockerPush(ctx context.Context) error {
 		return err
 	}
 
-	if err := s.runCommand(ctx, "docker", "push", s.config.DockerImage); err != nil {
+	cli, err := docker.NewClient()
+	if err != nil {
+		return err
+	}
+	defer cli.Close()
+
+	if err := cli.PushImage(ctx, s.config.DockerImage, s.stdout); err != nil {
 		return fmt.Errorf("docker push failed: %w", err)
 	}
 
 	return nil
 }
 
-// DockerComposeUp starts services with docker-compose.
+// DockerComposeUp starts services with docker-compose. Compose has no Engine
+// API equivalent, so this still needs the docker-compose binary.
 func (s *Service) DockerComposeUp(ctx context.Context) error {
 	if err := s.runCommand(ctx, "docker-compose", "up", "--build"); err != nil {
 		return fmt.Errorf("docker-compose up failed: %w", err)
@@ -106,12 +139,25 @@ func (s *Service) DockerComposeDown(ctx context.Context) error {
 
 // DockerClean cleans Docker artifacts.
 func (s *Service) DockerClean(ctx context.Context) error {
-	if err := s.runCommand(ctx, "docker", "system", "prune", "-f"); err != nil {
+	cli, err := docker.NewClient()
+	if err != nil {
+		return err
+	}
+	defer cli.Close()
+
+	report, err := cli.Prune(ctx)
+	if err != nil {
 		return fmt.Errorf("docker system prune failed: %w", err)
 	}
+	fmt.Fprintf(s.stdout, "Removed %d containers, %d images, and %d networks; reclaimed %d bytes\n",
+		report.Containers, report.Images, report.Networks, report.SpaceReclaimed)
 
-	// Try to remove the image (ignore errors if it doesn't exist)
-	_ = s.runCommand(ctx, "docker", "rmi", s.config.DockerImage)
+	return cli.RemoveImage(ctx, s.config.DockerImage)
+}
 
-	return nil
+func shortID(id string) string {
+	if len(id) > 12 {
+		return id[:12]
+	}
+	return id
 }
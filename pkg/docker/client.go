@@ -0,0 +1,248 @@
+// Package docker talks to the Docker Engine API directly instead of shelling
+// out to the docker CLI. It returns structured results - image IDs, container
+// states, and exit codes - honors context cancellation, and works wherever the
+// daemon socket is reachable even if the CLI is not installed. The connection
+// is configured from DOCKER_HOST, DOCKER_API_VERSION, DOCKER_CERT_PATH, and
+// DOCKER_TLS_VERIFY like the CLI.
+package docker
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	cerrdefs "github.com/containerd/errdefs"
+	"github.com/cpuguy83/dockercfg"
+	"github.com/distribution/reference"
+	"github.com/moby/go-archive"
+	"github.com/moby/moby/api/pkg/authconfig"
+	"github.com/moby/moby/api/types/jsonstream"
+	"github.com/moby/moby/api/types/registry"
+	"github.com/moby/moby/client"
+	"github.com/moby/moby/client/pkg/jsonmessage"
+	"github.com/moby/patternmatcher/ignorefile"
+)
+
+// Client wraps the Docker Engine API client.
+type Client struct {
+	api *client.Client
+}
+
+// NewClient connects to the daemon configured in the environment. The API
+// version is negotiated on the first request.
+func NewClient() (*Client, error) {
+	api, err := client.New(client.FromEnv)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create Docker client: %w", err)
+	}
+	return &Client{api: api}, nil
+}
+
+// Close releases the underlying connections.
+func (c *Client) Close() error {
+	return c.api.Close()
+}
+
+// Ping checks that the daemon is reachable.
+func (c *Client) Ping(ctx context.Context) error {
+	if _, err := c.api.Ping(ctx, client.PingOptions{}); err != nil {
+		return fmt.Errorf("docker daemon is not reachable: %w", err)
+	}
+	return nil
+}
+
+// BuildOptions configures an image build.
+type BuildOptions struct {
+	// ContextDir is sent to the daemon as the build context, minus the
+	// patterns in its .dockerignore.
+	ContextDir string
+	// Dockerfile is relative to ContextDir. Empty means Dockerfile.
+	Dockerfile string
+	Tags       []string
+}
+
+// BuildImage builds an image and returns its ID. Build output is written to
+// progress, which may be nil.
+func (c *Client) BuildImage(ctx context.Context, opts BuildOptions, progress io.Writer) (string, error) {
+	excludes, err := readDockerignore(opts.ContextDir)
+	if err != nil {
+		return "", err
+	}
+	buildContext, err := archive.TarWithOptions(opts.ContextDir, &archive.TarOptions{ExcludePatterns: excludes})
+	if err != nil {
+		return "", fmt.Errorf("failed to archive build context: %w", err)
+	}
+	defer buildContext.Close()
+
+	resp, err := c.api.ImageBuild(ctx, buildContext, client.ImageBuildOptions{
+		Tags:       opts.Tags,
+		Dockerfile: opts.Dockerfile,
+		Remove:     true,
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to build image: %w", err)
+	}
+	defer resp.Body.Close()
+
+	var imageID string
+	err = jsonmessage.DisplayStream(resp.Body, writerOrDiscard(progress), jsonmessage.WithAuxCallback(func(msg jsonstream.Message) {
+		imageID = auxImageID(msg)
+	}))
+	if err != nil {
+		return "", fmt.Errorf("failed to build image: %w", err)
+	}
+	return imageID, nil
+}
+
+// EnsureImage returns the ID of ref, pulling it first if it is not present
+// locally.
+func (c *Client) EnsureImage(ctx context.Context, ref string, progress io.Writer) (string, error) {
+	inspect, err := c.api.ImageInspect(ctx, ref)
+	if err == nil {
+		return inspect.ID, nil
+	}
+	if !cerrdefs.IsNotFound(err) {
+		return "", fmt.Errorf("failed to inspect image %s: %w", ref, err)
+	}
+
+	resp, err := c.api.ImagePull(ctx, ref, client.ImagePullOptions{RegistryAuth: registryAuth(ref)})
+	if err != nil {
+		return "", fmt.Errorf("failed to pull image %s: %w", ref, err)
+	}
+	if err := jsonmessage.DisplayMessages(resp.JSONMessages(ctx), writerOrDiscard(progress)); err != nil {
+		return "", fmt.Errorf("failed to pull image %s: %w", ref, err)
+	}
+	inspect, err = c.api.ImageInspect(ctx, ref)
+	if err != nil {
+		return "", fmt.Errorf("failed to inspect image %s: %w", ref, err)
+	}
+	return inspect.ID, nil
+}
+
+// PushImage pushes ref using credentials from the docker config file or its
+// credential helpers.
+func (c *Client) PushImage(ctx context.Context, ref string, progress io.Writer) error {
+	resp, err := c.api.ImagePush(ctx, ref, client.ImagePushOptions{RegistryAuth: registryAuth(ref)})
+	if err != nil {
+		return fmt.Errorf("failed to push image %s: %w", ref, err)
+	}
+	if err := jsonmessage.DisplayMessages(resp.JSONMessages(ctx), writerOrDiscard(progress)); err != nil {
+		return fmt.Errorf("failed to push image %s: %w", ref, err)
+	}
+	return nil
+}
+
+// RemoveImage removes ref. A missing image is not an error.
+func (c *Client) RemoveImage(ctx context.Context, ref string) error {
+	_, err := c.api.ImageRemove(ctx, ref, client.ImageRemoveOptions{PruneChildren: true})
+	if err != nil && !cerrdefs.IsNotFound(err) {
+		return fmt.Errorf("failed to remove image %s: %w", ref, err)
+	}
+	return nil
+}
+
+// PruneReport summarizes what Prune removed.
+type PruneReport struct {
+	Containers     int    `json:"containers"`
+	Images         int    `json:"images"`
+	Networks       int    `json:"networks"`
+	SpaceReclaimed uint64 `json:"space_reclaimed"`
+}
+
+// Prune removes stopped containers, unused networks, dangling images, and
+// the build cache, like docker system prune.
+func (c *Client) Prune(ctx context.Context) (*PruneReport, error) {
+	report := &PruneReport{}
+
+	containers, err := c.api.ContainerPrune(ctx, client.ContainerPruneOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to prune containers: %w", err)
+	}
+	report.Containers = len(containers.Report.ContainersDeleted)
+	report.SpaceReclaimed += containers.Report.SpaceReclaimed
+
+	networks, err := c.api.NetworkPrune(ctx, client.NetworkPruneOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to prune networks: %w", err)
+	}
+	report.Networks = len(networks.Report.NetworksDeleted)
+
+	images, err := c.api.ImagePrune(ctx, client.ImagePruneOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to prune images: %w", err)
+	}
+	report.Images = len(images.Report.ImagesDeleted)
+	report.SpaceReclaimed += images.Report.SpaceReclaimed
+
+	cache, err := c.api.BuildCachePrune(ctx, client.BuildCachePruneOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to prune build cache: %w", err)
+	}
+	report.SpaceReclaimed += cache.Report.SpaceReclaimed
+
+	return report, nil
+}
+
+func readDockerignore(dir string) ([]string, error) {
+	file, err := os.Open(filepath.Join(dir, ".dockerignore"))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read .dockerignore: %w", err)
+	}
+	defer file.Close()
+	patterns, err := ignorefile.ReadAll(file)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read .dockerignore: %w", err)
+	}
+	return patterns, nil
+}
+
+// auxImageID extracts the image ID from the aux message a build ends with.
+func auxImageID(msg jsonstream.Message) string {
+	if msg.Aux == nil {
+		return ""
+	}
+	var aux struct {
+		ID string `json:"ID"`
+	}
+	if err := json.Unmarshal(*msg.Aux, &aux); err != nil {
+		return ""
+	}
+	return aux.ID
+}
+
+// registryAuth returns encoded credentials for the registry hosting ref, or
+// an empty string for anonymous access.
+func registryAuth(ref string) string {
+	named, err := reference.ParseNormalizedNamed(ref)
+	if err != nil {
+		return ""
+	}
+	host := dockercfg.ResolveRegistryHost(reference.Domain(named))
+	username, password, err := dockercfg.GetRegistryCredentials(host)
+	if err != nil || (username == "" && password == "") {
+		return ""
+	}
+	auth := registry.AuthConfig{Username: username, Password: password, ServerAddress: host}
+	if username == "" {
+		auth = registry.AuthConfig{IdentityToken: password, ServerAddress: host}
+	}
+	encoded, err := authconfig.Encode(auth)
+	if err != nil {
+		return ""
+	}
+	return encoded
+}
+
+func writerOrDiscard(w io.Writer) io.Writer {
+	if w == nil {
+		return io.Discard
+	}
+	return w
+}
@@ -0,0 +1,172 @@
+package docker
+
+import (
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/moby/moby/api/types/container"
+	"github.com/moby/moby/api/types/jsonstream"
+	"github.com/moby/moby/api/types/mount"
+	"github.com/moby/moby/client"
+)
+
+// fakeDaemon serves a minimal Engine API and records request paths.
+type fakeDaemon struct {
+	mu       sync.Mutex
+	requests []string
+	pulled   bool
+}
+
+func (d *fakeDaemon) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	path := r.URL.Path
+	if i := strings.Index(path[1:], "/"); strings.HasPrefix(path, "/v1.") && i > 0 {
+		path = path[i+1:]
+	}
+	d.mu.Lock()
+	d.requests = append(d.requests, r.Method+" "+path)
+	pulled := d.pulled
+	d.mu.Unlock()
+
+	w.Header().Set("Api-Version", "1.47")
+	switch {
+	case path == "/_ping":
+		_, _ = w.Write([]byte("OK"))
+	case path == "/images/alpine:3.20/json":
+		if !pulled {
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`{"message":"No such image: alpine:3.20"}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"Id":"sha256:abc"}`))
+	case path == "/images/create":
+		d.mu.Lock()
+		d.pulled = true
+		d.mu.Unlock()
+		_, _ = w.Write([]byte(`{"status":"Pulling from library/alpine"}` + "\n" + `{"status":"Download complete"}` + "\n"))
+	case path == "/containers/c1/json":
+		_, _ = w.Write([]byte(`{"Id":"c1","Config":{"Tty":false}}`))
+	case path == "/containers/c1/logs":
+		if r.URL.Query().Get("tail") != "5" || r.URL.Query().Get("since") == "" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		_, _ = w.Write(logFrame(1, "build ok\n"))
+		_, _ = w.Write(logFrame(2, "warning\n"))
+	case path == "/containers/prune":
+		_, _ = w.Write([]byte(`{"ContainersDeleted":["a","b"],"SpaceReclaimed":100}`))
+	case path == "/networks/prune":
+		_, _ = w.Write([]byte(`{"NetworksDeleted":["n"]}`))
+	case path == "/images/prune":
+		_, _ = w.Write([]byte(`{"ImagesDeleted":[{"Deleted":"sha256:1"}],"SpaceReclaimed":50}`))
+	case path == "/build/prune":
+		_, _ = w.Write([]byte(`{"CachesDeleted":[],"SpaceReclaimed":25}`))
+	default:
+		w.WriteHeader(http.StatusNotFound)
+		_, _ = w.Write([]byte(`{"message":"unexpected request"}`))
+	}
+}
+
+// logFrame encodes one frame of a multiplexed log stream.
+func logFrame(stream byte, payload string) []byte {
+	header := []byte{stream, 0, 0, 0, 0, 0, 0, 0}
+	binary.BigEndian.PutUint32(header[4:], uint32(len(payload)))
+	return append(header, payload...)
+}
+
+func newTestClient(t *testing.T, handler http.Handler) *Client {
+	t.Helper()
+	srv := httptest.NewServer(handler)
+	t.Cleanup(srv.Close)
+	api, err := client.New(client.WithHost("tcp://"+strings.TrimPrefix(srv.URL, "http://")), client.WithHTTPClient(srv.Client()))
+	if err != nil {
+		t.Fatalf("client.New() error = %v", err)
+	}
+	t.Cleanup(func() { _ = api.Close() })
+	return &Client{api: api}
+}
+
+func TestEnsureImagePullsMissingImage(t *testing.T) {
+	daemon := &fakeDaemon{}
+	cli := newTestClient(t, daemon)
+
+	id, err := cli.EnsureImage(context.Background(), "alpine:3.20", nil)
+	if err != nil {
+		t.Fatalf("EnsureImage() error = %v", err)
+	}
+	if id != "sha256:abc" {
+		t.Errorf("image ID = %q, want sha256:abc", id)
+	}
+	if !daemon.pulled {
+		t.Errorf("image was not pulled; requests = %v", daemon.requests)
+	}
+}
+
+func TestLogsDemultiplexesStreams(t *testing.T) {
+	cli := newTestClient(t, &fakeDaemon{})
+
+	var stdout, stderr strings.Builder
+	err := cli.Logs(context.Background(), "c1", LogsOptions{Since: "10m", Tail: "5"}, &stdout, &stderr)
+	if err != nil {
+		t.Fatalf("Logs() error = %v", err)
+	}
+	if stdout.String() != "build ok\n" || stderr.String() != "warning\n" {
+		t.Errorf("stdout = %q, stderr = %q", stdout.String(), stderr.String())
+	}
+}
+
+func TestPruneSumsReports(t *testing.T) {
+	cli := newTestClient(t, &fakeDaemon{})
+
+	report, err := cli.Prune(context.Background())
+	if err != nil {
+		t.Fatalf("Prune() error = %v", err)
+	}
+	want := &PruneReport{Containers: 2, Images: 1, Networks: 1, SpaceReclaimed: 175}
+	if !reflect.DeepEqual(report, want) {
+		t.Errorf("Prune() = %+v, want %+v", report, want)
+	}
+}
+
+func TestHostConfigAppliesLimits(t *testing.T) {
+	host := hostConfig(RunOptions{
+		Mounts:          []Mount{{Source: "/src", Target: "/workspace", ReadOnly: true}},
+		Resources:       Resources{NanoCPUs: 1e9, MemoryBytes: 256 << 20, PidsLimit: 32},
+		NetworkDisabled: true,
+		CapDrop:         []string{"ALL"},
+	})
+
+	if host.NetworkMode != "none" || host.Memory != 256<<20 || host.MemorySwap != 256<<20 || host.NanoCPUs != 1e9 {
+		t.Errorf("host config = %+v", host)
+	}
+	if host.PidsLimit == nil || *host.PidsLimit != 32 {
+		t.Errorf("PidsLimit = %v, want 32", host.PidsLimit)
+	}
+	wantMounts := []mount.Mount{{Type: mount.TypeBind, Source: "/src", Target: "/workspace", ReadOnly: true}}
+	if !reflect.DeepEqual(host.Mounts, wantMounts) {
+		t.Errorf("Mounts = %+v, want %+v", host.Mounts, wantMounts)
+	}
+
+	if cfg := containerConfig(RunOptions{Image: "alpine", Cmd: []string{"true"}}); cfg.OpenStdin || cfg.Tty {
+		t.Errorf("container config without stdin = %+v", cfg)
+	}
+	if got := hostConfig(RunOptions{}); got.NetworkMode != "" || got.PidsLimit != nil || !reflect.DeepEqual(got.Resources, container.Resources{}) {
+		t.Errorf("default host config = %+v", got)
+	}
+}
+
+func TestAuxImageID(t *testing.T) {
+	raw := json.RawMessage(`{"ID":"sha256:def"}`)
+	if got := auxImageID(jsonstream.Message{Aux: &raw}); got != "sha256:def" {
+		t.Errorf("auxImageID() = %q", got)
+	}
+	if got := auxImageID(jsonstream.Message{Stream: "Step 1/2"}); got != "" {
+		t.Errorf("auxImageID() without aux = %q", got)
+	}
+}
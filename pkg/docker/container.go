@@ -0,0 +1,242 @@
+package docker
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/moby/moby/api/pkg/stdcopy"
+	"github.com/moby/moby/api/types/container"
+	"github.com/moby/moby/api/types/mount"
+	"github.com/moby/moby/client"
+)
+
+// Mount binds a host directory into a container.
+type Mount struct {
+	Source   string
+	Target   string
+	ReadOnly bool
+}
+
+// Resources limits a container. Zero values leave the daemon defaults.
+type Resources struct {
+	// NanoCPUs is the CPU quota in billionths of a CPU.
+	NanoCPUs    int64
+	MemoryBytes int64
+	PidsLimit   int64
+}
+
+// RunOptions describes a container to create, run to completion, and remove.
+type RunOptions struct {
+	// Name is optional; the daemon generates one when empty.
+	Name       string
+	Image      string
+	Cmd        []string
+	Env        []string
+	WorkingDir string
+	Mounts     []Mount
+	Resources  Resources
+	// NetworkDisabled runs the container with no network.
+	NetworkDisabled bool
+	CapDrop         []string
+	SecurityOpt     []string
+	// TTY allocates a pseudo-terminal; output then arrives on Stdout only.
+	TTY bool
+	// Stdin, if set, is copied to the container until it is exhausted.
+	Stdin  io.Reader
+	Stdout io.Writer
+	Stderr io.Writer
+}
+
+// RunResult describes a finished container.
+type RunResult struct {
+	ContainerID string `json:"container_id"`
+	ImageID     string `json:"image_id"`
+	// State is the container status when it stopped, normally "exited".
+	State     string `json:"state"`
+	ExitCode  int    `json:"exit_code"`
+	OOMKilled bool   `json:"oom_killed"`
+}
+
+// Run creates a container from opts, streams its output, and waits for it to
+// exit. The container is always removed afterwards; cancelling ctx kills it.
+// A non-zero exit code is reported in the result, not as an error.
+func (c *Client) Run(ctx context.Context, opts RunOptions) (*RunResult, error) {
+	created, err := c.api.ContainerCreate(ctx, client.ContainerCreateOptions{
+		Name:       opts.Name,
+		Config:     containerConfig(opts),
+		HostConfig: hostConfig(opts),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create container: %w", err)
+	}
+	result := &RunResult{ContainerID: created.ID}
+	defer c.removeContainer(created.ID)
+
+	attach, err := c.api.ContainerAttach(ctx, created.ID, client.ContainerAttachOptions{
+		Stream: true,
+		Stdin:  opts.Stdin != nil,
+		Stdout: true,
+		Stderr: true,
+	})
+	if err != nil {
+		return result, fmt.Errorf("failed to attach to container: %w", err)
+	}
+	defer attach.Close()
+
+	outputDone := make(chan error, 1)
+	go func() {
+		stdout, stderr := writerOrDiscard(opts.Stdout), writerOrDiscard(opts.Stderr)
+		var err error
+		if opts.TTY {
+			_, err = io.Copy(stdout, attach.Reader)
+		} else {
+			_, err = stdcopy.StdCopy(stdout, stderr, attach.Reader)
+		}
+		outputDone <- err
+	}()
+	if opts.Stdin != nil {
+		go func() {
+			_, _ = io.Copy(attach.Conn, opts.Stdin)
+			_ = attach.CloseWrite()
+		}()
+	}
+
+	wait := c.api.ContainerWait(ctx, created.ID, client.ContainerWaitOptions{Condition: container.WaitConditionNextExit})
+	if _, err := c.api.ContainerStart(ctx, created.ID, client.ContainerStartOptions{}); err != nil {
+		return result, fmt.Errorf("failed to start container: %w", err)
+	}
+
+	select {
+	case status := <-wait.Result:
+		result.ExitCode = int(status.StatusCode)
+		if status.Error != nil && status.Error.Message != "" {
+			return result, fmt.Errorf("container failed: %s", status.Error.Message)
+		}
+	case err := <-wait.Error:
+		if ctx.Err() != nil {
+			return result, ctx.Err()
+		}
+		return result, fmt.Errorf("failed waiting for container: %w", err)
+	}
+
+	select {
+	case <-outputDone:
+	case <-ctx.Done():
+		return result, ctx.Err()
+	}
+
+	inspect, err := c.api.ContainerInspect(ctx, created.ID, client.ContainerInspectOptions{})
+	if err != nil {
+		return result, fmt.Errorf("failed to inspect container: %w", err)
+	}
+	result.ImageID = inspect.Container.Image
+	if state := inspect.Container.State; state != nil {
+		result.State = string(state.Status)
+		result.OOMKilled = state.OOMKilled
+	}
+	return result, nil
+}
+
+// removeContainer force-removes a container on a fresh context so cleanup
+// still happens after the caller's context is cancelled.
+func (c *Client) removeContainer(id string) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	_, _ = c.api.ContainerRemove(ctx, id, client.ContainerRemoveOptions{Force: true, RemoveVolumes: true})
+}
+
+func containerConfig(opts RunOptions) *container.Config {
+	return &container.Config{
+		Image:           opts.Image,
+		Cmd:             opts.Cmd,
+		Env:             opts.Env,
+		WorkingDir:      opts.WorkingDir,
+		Tty:             opts.TTY,
+		AttachStdin:     opts.Stdin != nil,
+		OpenStdin:       opts.Stdin != nil,
+		StdinOnce:       opts.Stdin != nil,
+		AttachStdout:    true,
+		AttachStderr:    true,
+		NetworkDisabled: opts.NetworkDisabled,
+	}
+}
+
+func hostConfig(opts RunOptions) *container.HostConfig {
+	host := &container.HostConfig{
+		CapDrop:     opts.CapDrop,
+		SecurityOpt: opts.SecurityOpt,
+		Resources: container.Resources{
+			NanoCPUs: opts.Resources.NanoCPUs,
+			Memory:   opts.Resources.MemoryBytes,
+		},
+	}
+	if opts.Resources.MemoryBytes > 0 {
+		// Equal memory and swap limits disable swap.
+		host.Resources.MemorySwap = opts.Resources.MemoryBytes
+	}
+	if opts.Resources.PidsLimit > 0 {
+		limit := opts.Resources.PidsLimit
+		host.Resources.PidsLimit = &limit
+	}
+	if opts.NetworkDisabled {
+		host.NetworkMode = "none"
+	}
+	for _, m := range opts.Mounts {
+		host.Mounts = append(host.Mounts, mount.Mount{
+			Type:     mount.TypeBind,
+			Source:   m.Source,
+			Target:   m.Target,
+			ReadOnly: m.ReadOnly,
+		})
+	}
+	return host
+}
+
+// LogsOptions selects which container logs to read.
+type LogsOptions struct {
+	// Follow keeps streaming until the container stops or ctx is cancelled.
+	Follow bool
+	// Since is a duration before now such as 10m, an RFC3339 time, or a Unix
+	// timestamp.
+	Since string
+	// Tail is the number of lines from the end of the logs, or "all".
+	Tail       string
+	Timestamps bool
+}
+
+// Logs copies a container's logs to stdout and stderr. Output from containers
+// with a TTY arrives on stdout only.
+func (c *Client) Logs(ctx context.Context, containerID string, opts LogsOptions, stdout, stderr io.Writer) error {
+	inspect, err := c.api.ContainerInspect(ctx, containerID, client.ContainerInspectOptions{})
+	if err != nil {
+		return fmt.Errorf("failed to inspect container %s: %w", containerID, err)
+	}
+
+	logs, err := c.api.ContainerLogs(ctx, containerID, client.ContainerLogsOptions{
+		ShowStdout: true,
+		ShowStderr: true,
+		Follow:     opts.Follow,
+		Since:      opts.Since,
+		Tail:       opts.Tail,
+		Timestamps: opts.Timestamps,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to read logs for container %s: %w", containerID, err)
+	}
+	defer logs.Close()
+
+	if inspect.Container.Config != nil && inspect.Container.Config.Tty {
+		_, err = io.Copy(writerOrDiscard(stdout), logs)
+	} else {
+		_, err = stdcopy.StdCopy(writerOrDiscard(stdout), writerOrDiscard(stderr), logs)
+	}
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read logs for container %s: %w", containerID, err)
+	}
+	return nil
+}
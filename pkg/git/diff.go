@@ -0,0 +1,316 @@
+package git
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	gogit "github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/filemode"
+	fdiff "github.com/go-git/go-git/v5/plumbing/format/diff"
+	"github.com/go-git/go-git/v5/plumbing/format/index"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/go-git/go-git/v5/utils/diff"
+	"github.com/sergi/go-diff/diffmatchpatch"
+)
+
+// diffFile is one file on a side of a diff.
+type diffFile struct {
+	path string
+	hash plumbing.Hash
+	mode filemode.FileMode
+	read func() ([]byte, error)
+}
+
+func (f *diffFile) Hash() plumbing.Hash     { return f.hash }
+func (f *diffFile) Mode() filemode.FileMode { return f.mode }
+func (f *diffFile) Path() string            { return f.path }
+
+// diffSide is the files of a commit, the index, or the working tree, by
+// slash-separated path.
+type diffSide map[string]*diffFile
+
+func blobReader(repo *gogit.Repository, hash plumbing.Hash) func() ([]byte, error) {
+	return func() ([]byte, error) {
+		blob, err := repo.BlobObject(hash)
+		if err != nil {
+			return nil, err
+		}
+		reader, err := blob.Reader()
+		if err != nil {
+			return nil, err
+		}
+		defer reader.Close()
+		var buf bytes.Buffer
+		_, err = buf.ReadFrom(reader)
+		return buf.Bytes(), err
+	}
+}
+
+// treeSide returns the files of the commit rev names, or none for
+// EmptyTree.
+func treeSide(repo *gogit.Repository, rev string) (diffSide, error) {
+	side := diffSide{}
+	if rev == EmptyTree {
+		return side, nil
+	}
+	commit, err := resolve(repo, rev)
+	if err != nil {
+		return nil, err
+	}
+	tree, err := commit.Tree()
+	if err != nil {
+		return nil, err
+	}
+	err = tree.Files().ForEach(func(file *object.File) error {
+		side[file.Name] = &diffFile{path: file.Name, hash: file.Hash, mode: file.Mode, read: blobReader(repo, file.Hash)}
+		return nil
+	})
+	return side, err
+}
+
+// headSide returns the files at HEAD, or none before the first commit.
+func headSide(repo *gogit.Repository) (diffSide, error) {
+	if _, err := repo.Head(); errors.Is(err, plumbing.ErrReferenceNotFound) {
+		return diffSide{}, nil
+	}
+	return treeSide(repo, "HEAD")
+}
+
+// indexSide returns the staged files. While git runs a hook it may point
+// GIT_INDEX_FILE at a temporary index, such as for git commit -a, and that
+// index is read instead.
+func indexSide(repo *gogit.Repository) (diffSide, error) {
+	var idx *index.Index
+	if name := os.Getenv("GIT_INDEX_FILE"); name != "" {
+		file, err := os.Open(name)
+		if err != nil {
+			return nil, err
+		}
+		defer file.Close()
+		idx = &index.Index{}
+		if err := index.NewDecoder(file).Decode(idx); err != nil {
+			return nil, err
+		}
+	} else {
+		var err error
+		if idx, err = repo.Storer.Index(); err != nil {
+			return nil, err
+		}
+	}
+	side := make(diffSide, len(idx.Entries))
+	for _, entry := range idx.Entries {
+		// Unmerged entries have a conflict stage; index.Merged is not the
+		// zero stage of merged ones.
+		if entry.Stage != 0 {
+			continue
+		}
+		side[entry.Name] = &diffFile{path: entry.Name, hash: entry.Hash, mode: entry.Mode, read: blobReader(repo, entry.Hash)}
+	}
+	return side, nil
+}
+
+// worktreeSide returns the tracked files of the working tree: staged, with
+// the working tree's deletions and modifications applied.
+func worktreeSide(root string, worktree *gogit.Worktree, staged diffSide) (diffSide, error) {
+	status, err := worktree.Status()
+	if err != nil {
+		return nil, err
+	}
+	side := make(diffSide, len(staged))
+	for name, file := range staged {
+		side[name] = file
+	}
+	for name, file := range status {
+		if staged[name] == nil {
+			continue
+		}
+		switch file.Worktree {
+		case gogit.Deleted:
+			delete(side, name)
+		case gogit.Modified:
+			content, info, err := readWorktreeFile(root, name)
+			if errors.Is(err, os.ErrNotExist) {
+				delete(side, name)
+				continue
+			}
+			if err != nil {
+				return nil, err
+			}
+			mode, err := filemode.NewFromOSFileMode(info.Mode())
+			if err != nil {
+				return nil, err
+			}
+			side[name] = &diffFile{
+				path: name,
+				hash: plumbing.ComputeHash(plumbing.BlobObject, content),
+				mode: mode,
+				read: func() ([]byte, error) { return content, nil },
+			}
+		}
+	}
+	return side, nil
+}
+
+// changedPaths returns the sorted paths under paths that differ between
+// from and to.
+func changedPaths(from, to diffSide, paths []string) []string {
+	paths = cleanPaths(paths)
+	var changed []string
+	for name, file := range from {
+		if other := to[name]; (other == nil || other.hash != file.hash || other.mode != file.mode) && inPaths(name, paths) {
+			changed = append(changed, name)
+		}
+	}
+	for name := range to {
+		if from[name] == nil && inPaths(name, paths) {
+			changed = append(changed, name)
+		}
+	}
+	sort.Strings(changed)
+	return changed
+}
+
+// cleanPaths turns paths relative to the working tree root into cleaned,
+// slash-separated prefixes.
+func cleanPaths(paths []string) []string {
+	cleaned := make([]string, 0, len(paths))
+	for _, p := range paths {
+		cleaned = append(cleaned, path.Clean(filepath.ToSlash(p)))
+	}
+	return cleaned
+}
+
+// inPaths reports whether name is one of paths or under one of them. No
+// paths match everything.
+func inPaths(name string, paths []string) bool {
+	if len(paths) == 0 {
+		return true
+	}
+	for _, p := range paths {
+		if p == "." || name == p || strings.HasPrefix(name, p+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+// unifiedDiff renders the changes from from to to under paths as a unified
+// diff with contextLines lines of context, or git's default of three.
+func unifiedDiff(ctx context.Context, from, to diffSide, paths []string, contextLines int) ([]byte, error) {
+	if contextLines <= 0 {
+		contextLines = fdiff.DefaultContextLines
+	}
+	var patch filePatches
+	for _, name := range changedPaths(from, to, paths) {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		filePatch, err := newFilePatch(from[name], to[name])
+		if err != nil {
+			return nil, err
+		}
+		patch = append(patch, filePatch)
+	}
+	var out bytes.Buffer
+	if err := fdiff.NewUnifiedEncoder(&out, contextLines).Encode(patch); err != nil {
+		return nil, err
+	}
+	return out.Bytes(), nil
+}
+
+type filePatches []fdiff.FilePatch
+
+func (p filePatches) FilePatches() []fdiff.FilePatch { return p }
+func (p filePatches) Message() string                { return "" }
+
+// filePatch is the change to one file. from is nil for an added file and to
+// for a deleted one.
+type filePatch struct {
+	from, to *diffFile
+	binary   bool
+	chunks   []fdiff.Chunk
+}
+
+func (p *filePatch) IsBinary() bool        { return p.binary }
+func (p *filePatch) Chunks() []fdiff.Chunk { return p.chunks }
+
+func (p *filePatch) Files() (fdiff.File, fdiff.File) {
+	var from, to fdiff.File
+	if p.from != nil {
+		from = p.from
+	}
+	if p.to != nil {
+		to = p.to
+	}
+	return from, to
+}
+
+func newFilePatch(from, to *diffFile) (*filePatch, error) {
+	patch := &filePatch{from: from, to: to}
+	before, err := readSide(from)
+	if err != nil {
+		return nil, err
+	}
+	after, err := readSide(to)
+	if err != nil {
+		return nil, err
+	}
+	if isBinary(before) || isBinary(after) {
+		patch.binary = true
+		return patch, nil
+	}
+	for _, d := range diff.Do(string(before), string(after)) {
+		op := fdiff.Equal
+		switch d.Type {
+		case diffmatchpatch.DiffInsert:
+			op = fdiff.Add
+		case diffmatchpatch.DiffDelete:
+			op = fdiff.Delete
+		}
+		patch.chunks = append(patch.chunks, chunk{content: d.Text, op: op})
+	}
+	return patch, nil
+}
+
+func readSide(file *diffFile) ([]byte, error) {
+	if file == nil {
+		return nil, nil
+	}
+	return file.read()
+}
+
+type chunk struct {
+	content string
+	op      fdiff.Operation
+}
+
+func (c chunk) Content() string       { return c.content }
+func (c chunk) Type() fdiff.Operation { return c.op }
+
+// lineChanges counts the lines added and deleted from before to after, or
+// none when either is binary.
+func lineChanges(before, after []byte) (added, deleted int) {
+	if isBinary(before) || isBinary(after) {
+		return 0, 0
+	}
+	for _, d := range diff.Do(string(before), string(after)) {
+		lines := strings.Count(d.Text, "\n")
+		if !strings.HasSuffix(d.Text, "\n") {
+			lines++
+		}
+		switch d.Type {
+		case diffmatchpatch.DiffInsert:
+			added += lines
+		case diffmatchpatch.DiffDelete:
+			deleted += lines
+		}
+	}
+	return added, deleted
+}
@@ -0,0 +1,1029 @@
+// Package git manages a local working tree: status, diffs, branches, commits,
+// checkouts, stashes, and history. Status, diffs, branches, commits,
+// checkouts, and history are read and written in process with go-git, so no
+// git binary, hook, alias, or external diff driver runs for them; commits are
+// not signed. go-git cannot stash, resolve paths in linked worktrees, or
+// fetch a single commit, so stashes, GitPath, and FetchRevision run the git
+// binary with argument lists, never through a shell, with refs after
+// --end-of-options so they cannot be read as flags.
+package git
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	gogit "github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/config"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/go-git/go-git/v5/plumbing/storer"
+)
+
+// MaxDiffBytes caps the diff text returned by Diff.
+const MaxDiffBytes = 1 << 20
+
+// Repo is a git working tree.
+type Repo struct {
+	// Root is the top-level directory of the working tree.
+	Root string
+
+	repo *gogit.Repository
+}
+
+// Open finds the working tree containing dir.
+func Open(ctx context.Context, dir string) (*Repo, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	repo, err := openRepository(dir)
+	if err != nil {
+		return nil, fmt.Errorf("not a git repository: %s", dir)
+	}
+	worktree, err := repo.Worktree()
+	if err != nil {
+		return nil, fmt.Errorf("not a git repository: %s", dir)
+	}
+	root := worktree.Filesystem.Root()
+	if resolved, err := filepath.EvalSymlinks(root); err == nil {
+		root = resolved
+	}
+	return &Repo{Root: root, repo: repo}, nil
+}
+
+func openRepository(dir string) (*gogit.Repository, error) {
+	return gogit.PlainOpenWithOptions(dir, &gogit.PlainOpenOptions{DetectDotGit: true, EnableDotGitCommonDir: true})
+}
+
+// open returns the repository and its working tree.
+func (r *Repo) open(ctx context.Context) (*gogit.Repository, *gogit.Worktree, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, nil, err
+	}
+	repo := r.repo
+	if repo == nil {
+		var err error
+		if repo, err = openRepository(r.Root); err != nil {
+			return nil, nil, fmt.Errorf("not a git repository: %s", r.Root)
+		}
+	}
+	worktree, err := repo.Worktree()
+	if err != nil {
+		return nil, nil, err
+	}
+	return repo, worktree, nil
+}
+
+// FetchRevision checks out one revision of the repository at url into dir,
+// which must be empty or missing, without history. rev is a branch, tag, or
+// commit; empty means the remote's HEAD. It returns the commit checked out.
+func FetchRevision(ctx context.Context, url, dir, rev string) (string, error) {
+	if url == "" || strings.HasPrefix(url, "-") {
+		return "", fmt.Errorf("invalid repository URL %q", url)
+	}
+	if rev == "" {
+		rev = "HEAD"
+	}
+	if err := ValidateRef(rev); err != nil {
+		return "", err
+	}
+	if _, err := run(ctx, ".", "init", "--quiet", "--end-of-options", dir); err != nil {
+		return "", err
+	}
+	repo := &Repo{Root: dir}
+	if _, err := repo.git(ctx, "fetch", "--quiet", "--depth=1", "--no-tags", "--end-of-options", url, rev); err != nil {
+		return "", err
+	}
+	if _, err := repo.git(ctx, "checkout", "--quiet", "--detach", "FETCH_HEAD"); err != nil {
+		return "", err
+	}
+	out, err := repo.git(ctx, "rev-parse", "HEAD")
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(out)), nil
+}
+
+// Error is a failed git command with its output.
+type Error struct {
+	Args   []string
+	Output string
+	Err    error
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("git %s failed: %v: %s", e.Args[0], e.Err, e.Output)
+}
+
+func (e *Error) Unwrap() error { return e.Err }
+
+// command prepares git in dir with stable output that never waits on a
+// prompt, editor, or pager.
+func command(ctx context.Context, dir string, args ...string) *exec.Cmd {
+	cmd := exec.CommandContext(ctx, "git", args...)
+	cmd.Dir = dir
+	cmd.Env = append(cmd.Environ(), "GIT_TERMINAL_PROMPT=0", "GIT_EDITOR=true", "GIT_PAGER=cat", "LC_ALL=C")
+	return cmd
+}
+
+func run(ctx context.Context, dir string, args ...string) ([]byte, error) {
+	cmd := command(ctx, dir, args...)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return stdout.Bytes(), &Error{Args: args, Output: strings.TrimSpace(stderr.String() + stdout.String()), Err: err}
+	}
+	return stdout.Bytes(), nil
+}
+
+func (r *Repo) git(ctx context.Context, args ...string) ([]byte, error) {
+	return run(ctx, r.Root, args...)
+}
+
+// ValidateRef rejects names git would parse as options or refuse as refs.
+func ValidateRef(name string) error {
+	if name == "" {
+		return errors.New("ref is required")
+	}
+	if strings.HasPrefix(name, "-") || strings.ContainsAny(name, " \t\n\x00") {
+		return fmt.Errorf("invalid ref %q", name)
+	}
+	return nil
+}
+
+// resolve returns the commit rev names.
+func resolve(repo *gogit.Repository, rev string) (*object.Commit, error) {
+	hash, err := repo.ResolveRevision(plumbing.Revision(rev))
+	if err != nil {
+		return nil, fmt.Errorf("unknown revision %q: %w", rev, err)
+	}
+	commit, err := repo.CommitObject(*hash)
+	if err != nil {
+		return nil, fmt.Errorf("%q is not a commit: %w", rev, err)
+	}
+	return commit, nil
+}
+
+// FileStatus is one changed path. Index and Worktree use the porcelain
+// letters: M modified, A added, D deleted, R renamed, C copied, U unmerged,
+// ? untracked, and . unchanged.
+type FileStatus struct {
+	Path     string `json:"path"`
+	OrigPath string `json:"orig_path,omitempty"`
+	Index    string `json:"index"`
+	Worktree string `json:"worktree"`
+}
+
+// Status describes the branch and changed paths of the working tree.
+type Status struct {
+	Branch   string       `json:"branch"`
+	Head     string       `json:"head"`
+	Upstream string       `json:"upstream,omitempty"`
+	Ahead    int          `json:"ahead"`
+	Behind   int          `json:"behind"`
+	Clean    bool         `json:"clean"`
+	Files    []FileStatus `json:"files"`
+}
+
+// Status reports the branch, its upstream, and the changed paths, tracked
+// ones first and each group by path, like git status --porcelain=v2. The
+// branch is "(detached)" at a detached HEAD and the head "(initial)" before
+// the first commit. Renames show as a deletion and an addition.
+func (r *Repo) Status(ctx context.Context) (*Status, error) {
+	repo, worktree, err := r.open(ctx)
+	if err != nil {
+		return nil, err
+	}
+	files, err := worktree.Status()
+	if err != nil {
+		return nil, err
+	}
+	status := &Status{Files: []FileStatus{}}
+	for path, file := range files {
+		if file.Staging == gogit.Unmodified && file.Worktree == gogit.Unmodified {
+			continue
+		}
+		status.Files = append(status.Files, FileStatus{Path: path, Index: statusLetter(file.Staging), Worktree: statusLetter(file.Worktree)})
+	}
+	sort.Slice(status.Files, func(i, j int) bool {
+		a, b := status.Files[i], status.Files[j]
+		if (a.Index == "?") != (b.Index == "?") {
+			return b.Index == "?"
+		}
+		return a.Path < b.Path
+	})
+	status.Clean = len(status.Files) == 0
+
+	head, err := repo.Reference(plumbing.HEAD, false)
+	if err != nil {
+		return nil, err
+	}
+	if head.Type() != plumbing.SymbolicReference {
+		status.Branch, status.Head = "(detached)", head.Hash().String()
+		return status, nil
+	}
+	status.Branch = head.Target().Short()
+	branch, err := repo.Reference(head.Target(), true)
+	if errors.Is(err, plumbing.ErrReferenceNotFound) {
+		status.Head = "(initial)"
+		return status, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	status.Head = branch.Hash().String()
+
+	cfg, err := repo.Config()
+	if err != nil {
+		return nil, err
+	}
+	upstream, ref := upstreamOf(cfg, status.Branch)
+	if upstream == "" {
+		return status, nil
+	}
+	status.Upstream = upstream
+	if tracked, err := repo.Reference(ref, true); err == nil {
+		status.Ahead, status.Behind, err = aheadBehind(repo, branch.Hash(), tracked.Hash())
+		if err != nil {
+			return nil, err
+		}
+	}
+	return status, nil
+}
+
+func statusLetter(code gogit.StatusCode) string {
+	if code == gogit.Unmodified {
+		return "."
+	}
+	return string(code)
+}
+
+// upstreamOf returns the short name of the upstream of branch, such as
+// "origin/main", and the ref that tracks it, or "" when it has none.
+func upstreamOf(cfg *config.Config, branch string) (string, plumbing.ReferenceName) {
+	tracking, ok := cfg.Branches[branch]
+	if !ok || tracking.Remote == "" || tracking.Merge == "" {
+		return "", ""
+	}
+	if tracking.Remote == "." {
+		return tracking.Merge.Short(), tracking.Merge
+	}
+	ref := plumbing.NewRemoteReferenceName(tracking.Remote, tracking.Merge.Short())
+	return ref.Short(), ref
+}
+
+// aheadBehind counts the commits reachable from local but not upstream, and
+// from upstream but not local.
+func aheadBehind(repo *gogit.Repository, local, upstream plumbing.Hash) (int, int, error) {
+	mine, err := ancestors(repo, local, nil)
+	if err != nil {
+		return 0, 0, err
+	}
+	theirs, err := ancestors(repo, upstream, nil)
+	if err != nil {
+		return 0, 0, err
+	}
+	ahead, behind := 0, 0
+	for hash := range mine {
+		if !theirs[hash] {
+			ahead++
+		}
+	}
+	for hash := range theirs {
+		if !mine[hash] {
+			behind++
+		}
+	}
+	return ahead, behind, nil
+}
+
+// ancestors adds hash and the commits reachable from it to seen, skipping
+// history already there, and returns seen.
+func ancestors(repo *gogit.Repository, hash plumbing.Hash, seen map[plumbing.Hash]bool) (map[plumbing.Hash]bool, error) {
+	if seen == nil {
+		seen = map[plumbing.Hash]bool{}
+	}
+	if seen[hash] {
+		return seen, nil
+	}
+	commit, err := repo.CommitObject(hash)
+	if err != nil {
+		return nil, err
+	}
+	err = object.NewCommitPreorderIter(commit, seen, nil).ForEach(func(c *object.Commit) error {
+		seen[c.Hash] = true
+		return nil
+	})
+	return seen, err
+}
+
+// DiffOptions selects what Diff compares.
+type DiffOptions struct {
+	// Staged compares the index with HEAD, or with Base when set.
+	Staged bool
+	// Base compares against a commit instead of the index.
+	Base string
+	// Target, with Base, compares Base with this commit instead of the
+	// working tree.
+	Target string
+	// Paths limits the diff to these paths.
+	Paths []string
+	// Context is the number of context lines; zero means git's default.
+	Context int
+}
+
+// Diff returns a unified diff, truncated to MaxDiffBytes. The boolean reports
+// truncation. Like git diff, untracked files are left out and renames show
+// as a deletion and an addition.
+func (r *Repo) Diff(ctx context.Context, opts DiffOptions) (string, bool, error) {
+	if opts.Base != "" {
+		if err := ValidateRef(opts.Base); err != nil {
+			return "", false, err
+		}
+		if opts.Target != "" {
+			if err := ValidateRef(opts.Target); err != nil {
+				return "", false, err
+			}
+		}
+	}
+	repo, worktree, err := r.open(ctx)
+	if err != nil {
+		return "", false, err
+	}
+
+	var from, to diffSide
+	switch {
+	case opts.Base != "" && opts.Target != "":
+		if from, err = treeSide(repo, opts.Base); err == nil {
+			to, err = treeSide(repo, opts.Target)
+		}
+	case opts.Base != "":
+		if from, err = treeSide(repo, opts.Base); err == nil {
+			to, err = indexSide(repo)
+		}
+		if err == nil && !opts.Staged {
+			to, err = worktreeSide(r.Root, worktree, to)
+		}
+	case opts.Staged:
+		if from, err = headSide(repo); err == nil {
+			to, err = indexSide(repo)
+		}
+	default:
+		if from, err = indexSide(repo); err == nil {
+			to, err = worktreeSide(r.Root, worktree, from)
+		}
+	}
+	if err != nil {
+		return "", false, err
+	}
+
+	out, err := unifiedDiff(ctx, from, to, opts.Paths, opts.Context)
+	if err != nil {
+		return "", false, err
+	}
+	if len(out) > MaxDiffBytes {
+		return string(out[:MaxDiffBytes]), true, nil
+	}
+	return string(out), false, nil
+}
+
+// EmptyTree is the ID of the tree with no files, the base to diff a root
+// commit against.
+const EmptyTree = "4b825dc642cb6eb9a060e54bf8d69288fbee4904"
+
+// OutgoingBase returns what pushing commit to a new remote branch adds to
+// the remotes: the parent of its oldest commit not on any remote-tracking
+// branch, EmptyTree when none of its history is, or commit itself when all
+// of it is.
+func (r *Repo) OutgoingBase(ctx context.Context, commit string) (string, error) {
+	if err := ValidateRef(commit); err != nil {
+		return "", err
+	}
+	repo, _, err := r.open(ctx)
+	if err != nil {
+		return "", err
+	}
+	tip, err := resolve(repo, commit)
+	if err != nil {
+		return "", err
+	}
+
+	pushed := map[plumbing.Hash]bool{}
+	refs, err := repo.References()
+	if err != nil {
+		return "", err
+	}
+	err = refs.ForEach(func(ref *plumbing.Reference) error {
+		if !ref.Name().IsRemote() {
+			return nil
+		}
+		resolved, err := repo.Reference(ref.Name(), true)
+		if err != nil {
+			return nil
+		}
+		if _, err := repo.CommitObject(resolved.Hash()); err != nil {
+			return nil
+		}
+		_, err = ancestors(repo, resolved.Hash(), pushed)
+		return err
+	})
+	if err != nil {
+		return "", err
+	}
+
+	outgoing := map[plumbing.Hash]*object.Commit{}
+	err = object.NewCommitPreorderIter(tip, pushed, nil).ForEach(func(c *object.Commit) error {
+		outgoing[c.Hash] = c
+		return ctx.Err()
+	})
+	if err != nil {
+		return "", err
+	}
+	if len(outgoing) == 0 {
+		return commit, nil
+	}
+	// The oldest outgoing commit is the earliest one whose parents are all
+	// pushed.
+	var oldest *object.Commit
+	for _, c := range outgoing {
+		first := true
+		for _, parent := range c.ParentHashes {
+			if outgoing[parent] != nil {
+				first = false
+				break
+			}
+		}
+		if first && (oldest == nil || c.Committer.When.Before(oldest.Committer.When)) {
+			oldest = c
+		}
+	}
+	if len(oldest.ParentHashes) == 0 {
+		return EmptyTree, nil
+	}
+	return oldest.ParentHashes[0].String(), nil
+}
+
+// GitPath returns the absolute path of name in the repository's git
+// directory, such as "hooks", following core.hooksPath and linked worktrees.
+func (r *Repo) GitPath(ctx context.Context, name string) (string, error) {
+	out, err := r.git(ctx, "rev-parse", "--git-path", name)
+	if err != nil {
+		return "", err
+	}
+	path := strings.TrimSpace(string(out))
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(r.Root, path)
+	}
+	return path, nil
+}
+
+// ChangedFiles returns the slash-separated paths, relative to Root, of the
+// files that differ between base and the working tree, including untracked
+// files.
+func (r *Repo) ChangedFiles(ctx context.Context, base string) ([]string, error) {
+	if err := ValidateRef(base); err != nil {
+		return nil, err
+	}
+	repo, worktree, err := r.open(ctx)
+	if err != nil {
+		return nil, err
+	}
+	from, err := treeSide(repo, base)
+	if err != nil {
+		return nil, err
+	}
+	to, err := indexSide(repo)
+	if err != nil {
+		return nil, err
+	}
+	if to, err = worktreeSide(r.Root, worktree, to); err != nil {
+		return nil, err
+	}
+	files := changedPaths(from, to, nil)
+
+	status, err := worktree.Status()
+	if err != nil {
+		return nil, err
+	}
+	var untracked []string
+	for path, file := range status {
+		if file.Worktree == gogit.Untracked && to[path] == nil {
+			untracked = append(untracked, path)
+		}
+	}
+	sort.Strings(untracked)
+	return append(files, untracked...), nil
+}
+
+// Branch is a local branch.
+type Branch struct {
+	Name     string `json:"name"`
+	Commit   string `json:"commit"`
+	Upstream string `json:"upstream,omitempty"`
+	Current  bool   `json:"current"`
+}
+
+// Branches lists local branches by name.
+func (r *Repo) Branches(ctx context.Context) ([]Branch, error) {
+	repo, _, err := r.open(ctx)
+	if err != nil {
+		return nil, err
+	}
+	cfg, err := repo.Config()
+	if err != nil {
+		return nil, err
+	}
+	head, err := repo.Reference(plumbing.HEAD, false)
+	if err != nil {
+		return nil, err
+	}
+	refs, err := repo.Branches()
+	if err != nil {
+		return nil, err
+	}
+	branches := []Branch{}
+	err = refs.ForEach(func(ref *plumbing.Reference) error {
+		name := ref.Name().Short()
+		upstream, _ := upstreamOf(cfg, name)
+		branches = append(branches, Branch{
+			Name:     name,
+			Commit:   ref.Hash().String(),
+			Upstream: upstream,
+			Current:  head.Type() == plumbing.SymbolicReference && head.Target() == ref.Name(),
+		})
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(branches, func(i, j int) bool { return branches[i].Name < branches[j].Name })
+	return branches, nil
+}
+
+// CreateBranch creates name at start, or at HEAD when start is empty,
+// without switching to it.
+func (r *Repo) CreateBranch(ctx context.Context, name, start string) error {
+	if err := checkBranchName(name); err != nil {
+		return err
+	}
+	if start == "" {
+		start = "HEAD"
+	} else if err := ValidateRef(start); err != nil {
+		return err
+	}
+	repo, _, err := r.open(ctx)
+	if err != nil {
+		return err
+	}
+	commit, err := resolve(repo, start)
+	if err != nil {
+		return err
+	}
+	ref := plumbing.NewBranchReferenceName(name)
+	if _, err := repo.Reference(ref, false); err == nil {
+		return fmt.Errorf("a branch named %q already exists", name)
+	}
+	return repo.Storer.SetReference(plumbing.NewHashReference(ref, commit.Hash))
+}
+
+func checkBranchName(name string) error {
+	if err := ValidateRef(name); err != nil {
+		return err
+	}
+	if err := plumbing.NewBranchReferenceName(name).Validate(); err != nil {
+		return fmt.Errorf("invalid branch name %q", name)
+	}
+	return nil
+}
+
+// CommitOptions configures Commit.
+type CommitOptions struct {
+	Message string
+	// Paths are staged before committing. Empty commits what is staged.
+	Paths []string
+	// All stages modifications and deletions of tracked files.
+	All        bool
+	AllowEmpty bool
+}
+
+// Commit records a commit by the configured user.name and user.email and
+// returns its hash. No hooks run and the commit is not signed.
+func (r *Repo) Commit(ctx context.Context, opts CommitOptions) (string, error) {
+	if strings.TrimSpace(opts.Message) == "" {
+		return "", errors.New("commit message is required")
+	}
+	_, worktree, err := r.open(ctx)
+	if err != nil {
+		return "", err
+	}
+	for _, path := range opts.Paths {
+		rel, err := r.relative(path)
+		if err != nil {
+			return "", err
+		}
+		if _, err := worktree.Add(rel); err != nil {
+			return "", fmt.Errorf("failed to stage %s: %w", path, err)
+		}
+	}
+	hash, err := worktree.Commit(strings.TrimSpace(opts.Message)+"\n", &gogit.CommitOptions{
+		All:               opts.All,
+		AllowEmptyCommits: opts.AllowEmpty,
+	})
+	if errors.Is(err, gogit.ErrEmptyCommit) {
+		return "", errors.New("nothing to commit")
+	}
+	if err != nil {
+		return "", err
+	}
+	return hash.String(), nil
+}
+
+// relative returns path relative to Root, refusing paths outside it.
+func (r *Repo) relative(path string) (string, error) {
+	if filepath.IsAbs(path) {
+		rel, err := filepath.Rel(r.Root, path)
+		if err != nil {
+			return "", err
+		}
+		path = rel
+	}
+	path = filepath.Clean(path)
+	if path == ".." || strings.HasPrefix(path, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("%s is outside the repository", path)
+	}
+	return path, nil
+}
+
+// CheckoutOptions configures Checkout.
+type CheckoutOptions struct {
+	// Ref is a branch, or any commit when Detach is set.
+	Ref string
+	// Create creates Ref as a new branch, starting at Start or HEAD.
+	Create bool
+	Start  string
+	Detach bool
+}
+
+// Checkout switches branches. It refuses while tracked files have local
+// changes or an untracked file is in the way, so uncommitted work is never
+// discarded.
+func (r *Repo) Checkout(ctx context.Context, opts CheckoutOptions) error {
+	if opts.Create {
+		if err := checkBranchName(opts.Ref); err != nil {
+			return err
+		}
+		if opts.Start != "" {
+			if err := ValidateRef(opts.Start); err != nil {
+				return err
+			}
+		}
+	} else if err := ValidateRef(opts.Ref); err != nil {
+		return err
+	}
+	repo, worktree, err := r.open(ctx)
+	if err != nil {
+		return err
+	}
+
+	checkout := &gogit.CheckoutOptions{}
+	var target *object.Commit
+	switch {
+	case opts.Create:
+		start := opts.Start
+		if start == "" {
+			start = "HEAD"
+		}
+		if target, err = resolve(repo, start); err != nil {
+			return err
+		}
+		checkout.Branch, checkout.Create, checkout.Hash = plumbing.NewBranchReferenceName(opts.Ref), true, target.Hash
+	case opts.Detach:
+		if target, err = resolve(repo, opts.Ref); err != nil {
+			return err
+		}
+		checkout.Hash = target.Hash
+	default:
+		checkout.Branch = plumbing.NewBranchReferenceName(opts.Ref)
+		branch, err := repo.Reference(checkout.Branch, true)
+		if err != nil {
+			return fmt.Errorf("no branch named %q", opts.Ref)
+		}
+		if target, err = repo.CommitObject(branch.Hash()); err != nil {
+			return err
+		}
+	}
+
+	status, err := worktree.Status()
+	if err != nil {
+		return err
+	}
+	tree, err := target.Tree()
+	if err != nil {
+		return err
+	}
+	for path, file := range status {
+		switch {
+		case file.Worktree == gogit.Untracked && file.Staging == gogit.Untracked:
+			if _, err := tree.File(path); err == nil {
+				return fmt.Errorf("untracked file %s would be overwritten by checking out %s", path, opts.Ref)
+			}
+		case file.Staging != gogit.Unmodified || file.Worktree != gogit.Unmodified:
+			return fmt.Errorf("local changes to %s would be overwritten by checking out %s; commit or stash them first", path, opts.Ref)
+		}
+	}
+	return worktree.Checkout(checkout)
+}
+
+// Stash is one stash entry.
+type Stash struct {
+	Ref     string `json:"ref"`
+	Message string `json:"message"`
+}
+
+// StashPush stashes local changes and reports whether anything was stashed.
+func (r *Repo) StashPush(ctx context.Context, message string, includeUntracked bool) (bool, error) {
+	before, err := r.Stashes(ctx)
+	if err != nil {
+		return false, err
+	}
+	args := []string{"stash", "push"}
+	if includeUntracked {
+		args = append(args, "--include-untracked")
+	}
+	if message != "" {
+		args = append(args, "--message", message)
+	}
+	if _, err := r.git(ctx, args...); err != nil {
+		return false, err
+	}
+	after, err := r.Stashes(ctx)
+	if err != nil {
+		return false, err
+	}
+	return len(after) > len(before), nil
+}
+
+// StashPop applies a stash and drops it when it applied cleanly. Empty ref
+// means the latest stash.
+func (r *Repo) StashPop(ctx context.Context, ref string) error {
+	return r.stash(ctx, "pop", ref)
+}
+
+// StashApply applies a stash and keeps it.
+func (r *Repo) StashApply(ctx context.Context, ref string) error {
+	return r.stash(ctx, "apply", ref)
+}
+
+// StashDrop deletes a stash.
+func (r *Repo) StashDrop(ctx context.Context, ref string) error {
+	return r.stash(ctx, "drop", ref)
+}
+
+func (r *Repo) stash(ctx context.Context, action, ref string) error {
+	args := []string{"stash", action}
+	if ref != "" {
+		if !strings.HasPrefix(ref, "stash@{") || !strings.HasSuffix(ref, "}") {
+			return fmt.Errorf("invalid stash %q; use stash@{N}", ref)
+		}
+		args = append(args, ref)
+	}
+	_, err := r.git(ctx, args...)
+	return err
+}
+
+// Stashes lists stash entries, newest first.
+func (r *Repo) Stashes(ctx context.Context) ([]Stash, error) {
+	out, err := r.git(ctx, "stash", "list", "--format=%gd%x00%gs")
+	if err != nil {
+		return nil, err
+	}
+	stashes := []Stash{}
+	for _, line := range strings.Split(strings.TrimSpace(string(out)), "\n") {
+		if ref, message, ok := strings.Cut(line, "\x00"); ok {
+			stashes = append(stashes, Stash{Ref: ref, Message: message})
+		}
+	}
+	return stashes, nil
+}
+
+// Commit is one log entry.
+type Commit struct {
+	Hash    string    `json:"hash"`
+	Author  string    `json:"author"`
+	Email   string    `json:"email"`
+	Date    time.Time `json:"date"`
+	Subject string    `json:"subject"`
+}
+
+// LogOptions configures Log.
+type LogOptions struct {
+	// Ref is where history starts; empty means HEAD.
+	Ref string
+	// Paths limits history to commits touching these paths.
+	Paths []string
+	// MaxCount limits the number of commits; zero means 20.
+	MaxCount int
+}
+
+// Log lists commits, newest first.
+func (r *Repo) Log(ctx context.Context, opts LogOptions) ([]Commit, error) {
+	count := opts.MaxCount
+	if count <= 0 {
+		count = 20
+	}
+	ref := opts.Ref
+	if ref == "" {
+		ref = "HEAD"
+	} else if err := ValidateRef(ref); err != nil {
+		return nil, err
+	}
+	repo, _, err := r.open(ctx)
+	if err != nil {
+		return nil, err
+	}
+	start, err := resolve(repo, ref)
+	if err != nil {
+		return nil, err
+	}
+	logOptions := &gogit.LogOptions{From: start.Hash, Order: gogit.LogOrderCommitterTime}
+	if len(opts.Paths) > 0 {
+		paths := cleanPaths(opts.Paths)
+		logOptions.PathFilter = func(path string) bool { return inPaths(path, paths) }
+	}
+	iter, err := repo.Log(logOptions)
+	if err != nil {
+		return nil, err
+	}
+	defer iter.Close()
+
+	commits := []Commit{}
+	err = iter.ForEach(func(c *object.Commit) error {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		subject, _, _ := strings.Cut(strings.TrimSpace(c.Message), "\n")
+		commits = append(commits, Commit{Hash: c.Hash.String(), Author: c.Author.Name, Email: c.Author.Email, Date: c.Author.When, Subject: subject})
+		if len(commits) == count {
+			return storer.ErrStop
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return commits, nil
+}
+
+// FileChurn counts how often a file changed.
+type FileChurn struct {
+	Commits int `json:"commits"`
+	Added   int `json:"added"`
+	Deleted int `json:"deleted"`
+}
+
+// ChurnOptions configures Churn.
+type ChurnOptions struct {
+	// Since limits history to commits after this time; zero means all.
+	Since time.Time
+	// Paths limits history to these paths.
+	Paths []string
+}
+
+// Churn counts the non-merge commits and lines changed per file, keyed by
+// path relative to the working tree root. Renames count as a deletion and an
+// addition, and binary changes as commits without lines.
+func (r *Repo) Churn(ctx context.Context, opts ChurnOptions) (map[string]FileChurn, error) {
+	repo, _, err := r.open(ctx)
+	if err != nil {
+		return nil, err
+	}
+	head, err := resolve(repo, "HEAD")
+	if err != nil {
+		return nil, err
+	}
+	logOptions := &gogit.LogOptions{From: head.Hash}
+	if !opts.Since.IsZero() {
+		logOptions.Since = &opts.Since
+	}
+	iter, err := repo.Log(logOptions)
+	if err != nil {
+		return nil, err
+	}
+	defer iter.Close()
+
+	paths := cleanPaths(opts.Paths)
+	churn := map[string]FileChurn{}
+	err = iter.ForEach(func(c *object.Commit) error {
+		if len(c.ParentHashes) > 1 {
+			return nil
+		}
+		lines, err := commitLines(ctx, c, paths)
+		if err != nil {
+			return err
+		}
+		for path, changed := range lines {
+			file := churn[path]
+			file.Commits++
+			file.Added += changed[0]
+			file.Deleted += changed[1]
+			churn[path] = file
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return churn, nil
+}
+
+// commitLines returns the lines added and deleted by a commit in each file
+// under paths.
+func commitLines(ctx context.Context, c *object.Commit, paths []string) (map[string][2]int, error) {
+	tree, err := c.Tree()
+	if err != nil {
+		return nil, err
+	}
+	var parent *object.Tree
+	if len(c.ParentHashes) == 1 {
+		first, err := c.Parent(0)
+		if err != nil {
+			return nil, err
+		}
+		if parent, err = first.Tree(); err != nil {
+			return nil, err
+		}
+	}
+	changes, err := object.DiffTreeWithOptions(ctx, parent, tree, &object.DiffTreeOptions{})
+	if err != nil {
+		return nil, err
+	}
+	lines := make(map[string][2]int, len(changes))
+	for _, change := range changes {
+		path := change.To.Name
+		if path == "" {
+			path = change.From.Name
+		}
+		if !inPaths(path, paths) {
+			continue
+		}
+		from, to, err := change.Files()
+		if err != nil {
+			return nil, err
+		}
+		before, err := fileContent(from)
+		if err != nil {
+			return nil, err
+		}
+		after, err := fileContent(to)
+		if err != nil {
+			return nil, err
+		}
+		added, deleted := lineChanges(before, after)
+		lines[path] = [2]int{added, deleted}
+	}
+	return lines, nil
+}
+
+func fileContent(file *object.File) ([]byte, error) {
+	if file == nil {
+		return nil, nil
+	}
+	reader, err := file.Reader()
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+	var buf bytes.Buffer
+	_, err = buf.ReadFrom(reader)
+	return buf.Bytes(), err
+}
+
+// isBinary reports whether content looks binary to git: a NUL byte in its
+// first 8000 bytes.
+func isBinary(content []byte) bool {
+	return bytes.IndexByte(content[:min(len(content), 8000)], 0) >= 0
+}
+
+// readWorktreeFile reads path under root the way git hashes it: the link
+// target for a symlink and the content otherwise.
+func readWorktreeFile(root, path string) ([]byte, os.FileInfo, error) {
+	name := filepath.Join(root, filepath.FromSlash(path))
+	info, err := os.Lstat(name)
+	if err != nil {
+		return nil, nil, err
+	}
+	if info.Mode()&os.ModeSymlink != 0 {
+		target, err := os.Readlink(name)
+		return []byte(target), info, err
+	}
+	content, err := os.ReadFile(name)
+	return content, info, err
+}
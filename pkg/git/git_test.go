@@ -0,0 +1,302 @@
+package git
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func newTestRepo(t *testing.T) *Repo {
+	t.Helper()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+	dir := t.TempDir()
+	for _, args := range [][]string{
+		{"init", "--quiet", "--initial-branch=main"},
+		{"config", "user.email", "dev@example.com"},
+		{"config", "user.name", "Dev"},
+		{"config", "commit.gpgsign", "false"},
+	} {
+		if out, err := exec.Command("git", append([]string{"-C", dir}, args...)...).CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v: %s", args, err, out)
+		}
+	}
+	writeFile(t, dir, "main.go", "package main\n")
+
+	repo, err := Open(context.Background(), dir)
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	if _, err := repo.Commit(context.Background(), CommitOptions{Message: "initial", Paths: []string{"."}}); err != nil {
+		t.Fatalf("Commit() error = %v", err)
+	}
+	return repo
+}
+
+func writeFile(t *testing.T, dir, name, content string) {
+	t.Helper()
+	if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestStatusAndDiff(t *testing.T) {
+	ctx := context.Background()
+	repo := newTestRepo(t)
+	writeFile(t, repo.Root, "main.go", "package main\n\nfunc main() {}\n")
+	writeFile(t, repo.Root, "new file.txt", "hello\n")
+
+	status, err := repo.Status(ctx)
+	if err != nil {
+		t.Fatalf("Status() error = %v", err)
+	}
+	if status.Branch != "main" || status.Clean || len(status.Files) != 2 {
+		t.Fatalf("status = %+v", status)
+	}
+	if f := status.Files[0]; f.Path != "main.go" || f.Index != "." || f.Worktree != "M" {
+		t.Errorf("modified file = %+v", f)
+	}
+	if f := status.Files[1]; f.Path != "new file.txt" || f.Index != "?" {
+		t.Errorf("untracked file = %+v", f)
+	}
+
+	diff, truncated, err := repo.Diff(ctx, DiffOptions{})
+	if err != nil || truncated {
+		t.Fatalf("Diff() = %v, %v", truncated, err)
+	}
+	if !strings.Contains(diff, "+func main() {}") {
+		t.Errorf("diff = %q", diff)
+	}
+	if diff, _, _ := repo.Diff(ctx, DiffOptions{Staged: true}); diff != "" {
+		t.Errorf("staged diff = %q, want empty", diff)
+	}
+	if _, _, err := repo.Diff(ctx, DiffOptions{Base: "--output=/tmp/x"}); err == nil {
+		t.Error("Diff() should reject option-like base")
+	}
+}
+
+func TestChangedFiles(t *testing.T) {
+	ctx := context.Background()
+	repo := newTestRepo(t)
+	base, err := repo.Commit(ctx, CommitOptions{Message: "empty", AllowEmpty: true})
+	if err != nil {
+		t.Fatalf("Commit() error = %v", err)
+	}
+	if err := os.MkdirAll(filepath.Join(repo.Root, "pkg", "a"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	writeFile(t, repo.Root, "pkg/a/a.go", "package a\n")
+	if _, err := repo.Commit(ctx, CommitOptions{Message: "Add a", Paths: []string{"pkg"}}); err != nil {
+		t.Fatalf("Commit() error = %v", err)
+	}
+	writeFile(t, repo.Root, "main.go", "package main\n\nfunc main() {}\n")
+	writeFile(t, repo.Root, "notes.txt", "new\n")
+
+	files, err := repo.ChangedFiles(ctx, base)
+	if err != nil {
+		t.Fatalf("ChangedFiles() error = %v", err)
+	}
+	want := []string{"main.go", "pkg/a/a.go", "notes.txt"}
+	if strings.Join(files, ",") != strings.Join(want, ",") {
+		t.Errorf("ChangedFiles() = %v, want %v", files, want)
+	}
+	if _, err := repo.ChangedFiles(ctx, "--output=x"); err == nil {
+		t.Error("ChangedFiles() should reject refs that look like flags")
+	}
+}
+
+func TestOutgoingBaseAndGitPath(t *testing.T) {
+	ctx := context.Background()
+	repo := newTestRepo(t)
+	writeFile(t, repo.Root, "a.txt", "a\n")
+	first, err := repo.Commit(ctx, CommitOptions{Message: "Add a", Paths: []string{"a.txt"}})
+	if err != nil {
+		t.Fatalf("Commit() error = %v", err)
+	}
+	writeFile(t, repo.Root, "a.txt", "b\n")
+	second, err := repo.Commit(ctx, CommitOptions{Message: "Change a", All: true})
+	if err != nil {
+		t.Fatalf("Commit() error = %v", err)
+	}
+
+	if base, err := repo.OutgoingBase(ctx, second); err != nil || base != EmptyTree {
+		t.Errorf("OutgoingBase() without remotes = %q, %v, want the empty tree", base, err)
+	}
+	if out, err := exec.Command("git", "-C", repo.Root, "update-ref", "refs/remotes/origin/main", first).CombinedOutput(); err != nil {
+		t.Fatalf("update-ref: %v: %s", err, out)
+	}
+	base, err := repo.OutgoingBase(ctx, second)
+	if err != nil || base != first {
+		t.Fatalf("OutgoingBase() = %q, %v, want %s", base, err, first)
+	}
+	diff, _, err := repo.Diff(ctx, DiffOptions{Base: base, Target: second})
+	if err != nil || !strings.Contains(diff, "-a\n+b") {
+		t.Errorf("Diff() = %q, %v", diff, err)
+	}
+	if base, err := repo.OutgoingBase(ctx, first); err != nil || base != first {
+		t.Errorf("OutgoingBase() of a pushed commit = %q, %v", base, err)
+	}
+
+	hooks, err := repo.GitPath(ctx, "hooks")
+	if err != nil || hooks != filepath.Join(repo.Root, ".git", "hooks") {
+		t.Errorf("GitPath() = %q, %v", hooks, err)
+	}
+}
+
+func TestBranchCheckoutCommitAndLog(t *testing.T) {
+	ctx := context.Background()
+	repo := newTestRepo(t)
+
+	if err := repo.Checkout(ctx, CheckoutOptions{Ref: "feature", Create: true}); err != nil {
+		t.Fatalf("Checkout(create) error = %v", err)
+	}
+	writeFile(t, repo.Root, "feature.go", "package main\n")
+	hash, err := repo.Commit(ctx, CommitOptions{Message: "Add feature\n\nBody text.", Paths: []string{"feature.go"}})
+	if err != nil {
+		t.Fatalf("Commit() error = %v", err)
+	}
+
+	commits, err := repo.Log(ctx, LogOptions{MaxCount: 5})
+	if err != nil {
+		t.Fatalf("Log() error = %v", err)
+	}
+	if len(commits) != 2 || commits[0].Hash != hash || commits[0].Subject != "Add feature" || commits[0].Author != "Dev" {
+		t.Errorf("commits = %+v", commits)
+	}
+
+	branches, err := repo.Branches(ctx)
+	if err != nil {
+		t.Fatalf("Branches() error = %v", err)
+	}
+	if len(branches) != 2 || branches[0].Name != "feature" || !branches[0].Current || branches[1].Current {
+		t.Errorf("branches = %+v", branches)
+	}
+
+	if err := repo.CreateBranch(ctx, "bad..name", ""); err == nil {
+		t.Error("CreateBranch() should reject invalid names")
+	}
+	if err := repo.Checkout(ctx, CheckoutOptions{Ref: "main"}); err != nil {
+		t.Fatalf("Checkout(main) error = %v", err)
+	}
+	if _, err := repo.Commit(ctx, CommitOptions{Message: " "}); err == nil {
+		t.Error("Commit() should require a message")
+	}
+
+	// Checkout never discards local changes.
+	writeFile(t, repo.Root, "main.go", "package main // changed\n")
+	if err := repo.Checkout(ctx, CheckoutOptions{Ref: "feature"}); err == nil || !strings.Contains(err.Error(), "main.go") {
+		t.Errorf("Checkout() with local changes error = %v", err)
+	}
+	writeFile(t, repo.Root, "main.go", "package main\n")
+	writeFile(t, repo.Root, "feature.go", "package untracked\n")
+	if err := repo.Checkout(ctx, CheckoutOptions{Ref: "feature"}); err == nil || !strings.Contains(err.Error(), "feature.go") {
+		t.Errorf("Checkout() over an untracked file error = %v", err)
+	}
+	if data, _ := os.ReadFile(filepath.Join(repo.Root, "feature.go")); string(data) != "package untracked\n" {
+		t.Errorf("feature.go = %q; checkout overwrote it", data)
+	}
+}
+
+func TestCommitSkipsHooks(t *testing.T) {
+	ctx := context.Background()
+	repo := newTestRepo(t)
+	writeFile(t, repo.Root, ".git/hooks/pre-commit", "#!/bin/sh\nexit 1\n")
+	if err := os.Chmod(filepath.Join(repo.Root, ".git", "hooks", "pre-commit"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	writeFile(t, repo.Root, "main.go", "package main // changed\n")
+	if _, err := repo.Commit(ctx, CommitOptions{Message: "Change main", All: true}); err != nil {
+		t.Fatalf("Commit() ran the failing pre-commit hook: %v", err)
+	}
+	if status, _ := repo.Status(ctx); !status.Clean {
+		t.Errorf("status after commit = %+v", status)
+	}
+}
+
+func TestChurn(t *testing.T) {
+	ctx := context.Background()
+	repo := newTestRepo(t)
+	writeFile(t, repo.Root, "main.go", "package main\n\nfunc main() {}\n")
+	if _, err := repo.Commit(ctx, CommitOptions{Message: "Add main", Paths: []string{"main.go"}}); err != nil {
+		t.Fatalf("Commit() error = %v", err)
+	}
+
+	churn, err := repo.Churn(ctx, ChurnOptions{})
+	if err != nil {
+		t.Fatalf("Churn() error = %v", err)
+	}
+	if got := churn["main.go"]; got != (FileChurn{Commits: 2, Added: 3, Deleted: 0}) {
+		t.Errorf("churn[main.go] = %+v", got)
+	}
+
+	churn, err = repo.Churn(ctx, ChurnOptions{Since: time.Now().Add(time.Hour)})
+	if err != nil || len(churn) != 0 {
+		t.Errorf("Churn(future) = %+v, %v", churn, err)
+	}
+}
+
+func TestStash(t *testing.T) {
+	ctx := context.Background()
+	repo := newTestRepo(t)
+
+	stashed, err := repo.StashPush(ctx, "nothing", false)
+	if err != nil || stashed {
+		t.Fatalf("StashPush() on clean tree = %v, %v", stashed, err)
+	}
+
+	writeFile(t, repo.Root, "main.go", "package main // changed\n")
+	stashed, err = repo.StashPush(ctx, "wip", false)
+	if err != nil || !stashed {
+		t.Fatalf("StashPush() = %v, %v", stashed, err)
+	}
+	stashes, err := repo.Stashes(ctx)
+	if err != nil || len(stashes) != 1 || stashes[0].Ref != "stash@{0}" || !strings.Contains(stashes[0].Message, "wip") {
+		t.Fatalf("Stashes() = %+v, %v", stashes, err)
+	}
+	if err := repo.StashPop(ctx, "HEAD"); err == nil {
+		t.Error("StashPop() should reject non-stash refs")
+	}
+	if err := repo.StashPop(ctx, ""); err != nil {
+		t.Fatalf("StashPop() error = %v", err)
+	}
+	if status, _ := repo.Status(ctx); status.Clean {
+		t.Error("changes were not restored")
+	}
+}
+
+func TestFetchRevision(t *testing.T) {
+	remote := newTestRepo(t)
+	ctx := context.Background()
+	first, err := remote.git(ctx, "rev-parse", "HEAD")
+	if err != nil {
+		t.Fatal(err)
+	}
+	writeFile(t, remote.Root, "main.go", "package main\n\nfunc main() {}\n")
+	if _, err := remote.Commit(ctx, CommitOptions{Message: "second", Paths: []string{"."}}); err != nil {
+		t.Fatal(err)
+	}
+
+	dir := filepath.Join(t.TempDir(), "checkout")
+	commit, err := FetchRevision(ctx, remote.Root, dir, strings.TrimSpace(string(first)))
+	if err != nil {
+		t.Fatalf("FetchRevision() error = %v", err)
+	}
+	if commit != strings.TrimSpace(string(first)) {
+		t.Errorf("commit = %s, want %s", commit, first)
+	}
+	if data, _ := os.ReadFile(filepath.Join(dir, "main.go")); string(data) != "package main\n" {
+		t.Errorf("main.go = %q", data)
+	}
+
+	if _, err := FetchRevision(ctx, remote.Root, filepath.Join(t.TempDir(), "head"), ""); err != nil {
+		t.Errorf("FetchRevision(HEAD) error = %v", err)
+	}
+	if _, err := FetchRevision(ctx, "--upload-pack=x", t.TempDir(), ""); err == nil {
+		t.Error("FetchRevision accepted an option as URL")
+	}
+}
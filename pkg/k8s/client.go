@@ -0,0 +1,527 @@
+// Package k8s is a small Kubernetes API client for checking the manifests
+// Jules sessions produce: server-side apply, pod status and logs, and
+// deployment rollout restarts. Clusters, credentials, and the default
+// namespace come from a kubeconfig context, and requests go straight to the
+// API server's REST endpoints.
+package k8s
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// FieldManager identifies Juleson as the owner of fields it applies.
+const FieldManager = "juleson"
+
+// Options selects the kubeconfig, context, and default namespace.
+type Options struct {
+	// Kubeconfig is the kubeconfig path. Empty means $KUBECONFIG or
+	// ~/.kube/config.
+	Kubeconfig string
+	// Context is the kubeconfig context. Empty means the current context.
+	Context string
+	// Namespace overrides the context's namespace.
+	Namespace string
+}
+
+// Client talks to one cluster through a kubeconfig context.
+type Client struct {
+	cfg  *restConfig
+	http *http.Client
+
+	mu        sync.Mutex
+	resources map[string][]apiResource
+}
+
+// NewClient resolves opts against the kubeconfig.
+func NewClient(opts Options) (*Client, error) {
+	cfg, err := loadConfig(opts.Kubeconfig, opts.Context)
+	if err != nil {
+		return nil, err
+	}
+	if opts.Namespace != "" {
+		cfg.namespace = opts.Namespace
+	}
+	return &Client{
+		cfg:       cfg,
+		http:      &http.Client{Transport: cfg.transport},
+		resources: make(map[string][]apiResource),
+	}, nil
+}
+
+// Context returns the kubeconfig context in use.
+func (c *Client) Context() string {
+	return c.cfg.context
+}
+
+// Namespace returns the default namespace for namespaced objects.
+func (c *Client) Namespace() string {
+	if c.cfg.namespace == "" {
+		return "default"
+	}
+	return c.cfg.namespace
+}
+
+// StatusError is an error response from the API server.
+type StatusError struct {
+	Code    int
+	Reason  string
+	Message string
+}
+
+func (e *StatusError) Error() string {
+	if e.Reason != "" {
+		return fmt.Sprintf("kubernetes API: %s (%s)", e.Message, e.Reason)
+	}
+	return "kubernetes API: " + e.Message
+}
+
+// IsNotFound reports whether err is a 404 from the API server.
+func IsNotFound(err error) bool {
+	var status *StatusError
+	return errors.As(err, &status) && status.Code == http.StatusNotFound
+}
+
+// do sends a request and returns the response for 2xx statuses.
+func (c *Client) do(ctx context.Context, method, path string, query url.Values, contentType string, body []byte) (*http.Response, error) {
+	u := c.cfg.server + path
+	if len(query) > 0 {
+		u += "?" + query.Encode()
+	}
+	var reader io.Reader
+	if body != nil {
+		reader = bytes.NewReader(body)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, u, reader)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/json")
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	if err := c.cfg.authorize(ctx, req); err != nil {
+		return nil, err
+	}
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("kubernetes API request failed: %w", err)
+	}
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return resp, nil
+	}
+	defer resp.Body.Close()
+
+	data, _ := io.ReadAll(io.LimitReader(resp.Body, 64<<10))
+	status := &StatusError{Code: resp.StatusCode}
+	var apiStatus struct {
+		Reason  string `json:"reason"`
+		Message string `json:"message"`
+	}
+	if json.Unmarshal(data, &apiStatus) == nil && apiStatus.Message != "" {
+		status.Reason, status.Message = apiStatus.Reason, apiStatus.Message
+	} else {
+		status.Message = strings.TrimSpace(resp.Status + " " + string(data))
+	}
+	return nil, status
+}
+
+func (c *Client) getJSON(ctx context.Context, path string, query url.Values, out interface{}) error {
+	resp, err := c.do(ctx, http.MethodGet, path, query, "", nil)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	return json.NewDecoder(resp.Body).Decode(out)
+}
+
+// apiResource is one entry of a discovery APIResourceList.
+type apiResource struct {
+	Name       string `json:"name"`
+	Kind       string `json:"kind"`
+	Namespaced bool   `json:"namespaced"`
+}
+
+func groupVersionPath(apiVersion string) string {
+	if strings.Contains(apiVersion, "/") {
+		return "/apis/" + apiVersion
+	}
+	return "/api/" + apiVersion
+}
+
+// resource finds the REST resource for kind in apiVersion through discovery.
+func (c *Client) resource(ctx context.Context, apiVersion, kind string) (apiResource, error) {
+	c.mu.Lock()
+	resources, ok := c.resources[apiVersion]
+	c.mu.Unlock()
+	if !ok {
+		var list struct {
+			Resources []apiResource `json:"resources"`
+		}
+		if err := c.getJSON(ctx, groupVersionPath(apiVersion), nil, &list); err != nil {
+			if IsNotFound(err) {
+				return apiResource{}, fmt.Errorf("API version %s is not served by the cluster", apiVersion)
+			}
+			return apiResource{}, err
+		}
+		resources = list.Resources
+		c.mu.Lock()
+		c.resources[apiVersion] = resources
+		c.mu.Unlock()
+	}
+	for _, r := range resources {
+		if r.Kind == kind && !strings.Contains(r.Name, "/") {
+			return r, nil
+		}
+	}
+	return apiResource{}, fmt.Errorf("kind %s is not served in %s", kind, apiVersion)
+}
+
+// Object is a decoded manifest document.
+type Object map[string]interface{}
+
+func (o Object) str(key string) string {
+	s, _ := o[key].(string)
+	return s
+}
+
+func (o Object) metadata() map[string]interface{} {
+	m, _ := o["metadata"].(map[string]interface{})
+	return m
+}
+
+// Name returns metadata.name.
+func (o Object) Name() string {
+	s, _ := o.metadata()["name"].(string)
+	return s
+}
+
+// Namespace returns metadata.namespace.
+func (o Object) Namespace() string {
+	s, _ := o.metadata()["namespace"].(string)
+	return s
+}
+
+// ParseManifests decodes multi-document YAML or JSON. Empty documents are
+// skipped and List kinds are expanded to their items.
+func ParseManifests(data []byte) ([]Object, error) {
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	var objects []Object
+	for i := 1; ; i++ {
+		// Decoding into a plain map keeps nested maps as
+		// map[string]interface{} rather than Object.
+		var doc map[string]interface{}
+		err := dec.Decode(&doc)
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("document %d: %w", i, err)
+		}
+		if len(doc) == 0 {
+			continue
+		}
+		obj := Object(doc)
+		if items, ok := obj["items"].([]interface{}); ok && strings.HasSuffix(obj.str("kind"), "List") {
+			for _, item := range items {
+				if m, ok := item.(map[string]interface{}); ok {
+					objects = append(objects, Object(m))
+				}
+			}
+			continue
+		}
+		objects = append(objects, obj)
+	}
+	for i, obj := range objects {
+		if obj.str("apiVersion") == "" || obj.str("kind") == "" || obj.Name() == "" {
+			return nil, fmt.Errorf("object %d needs apiVersion, kind, and metadata.name", i+1)
+		}
+	}
+	if len(objects) == 0 {
+		return nil, fmt.Errorf("manifest has no objects")
+	}
+	return objects, nil
+}
+
+// ApplyOptions configures Apply.
+type ApplyOptions struct {
+	// Namespace is used for namespaced objects without metadata.namespace.
+	// Empty means the client's namespace.
+	Namespace string
+	// DryRun validates and admits the objects without persisting them.
+	DryRun bool
+	// Force takes ownership of fields managed by someone else instead of
+	// failing with a conflict.
+	Force bool
+}
+
+// AppliedObject reports the outcome of applying one object.
+type AppliedObject struct {
+	APIVersion string `json:"api_version"`
+	Kind       string `json:"kind"`
+	Namespace  string `json:"namespace,omitempty"`
+	Name       string `json:"name"`
+	// Action is created or configured.
+	Action string `json:"action"`
+}
+
+func (a AppliedObject) String() string {
+	if a.Namespace != "" {
+		return fmt.Sprintf("%s %s/%s", a.Kind, a.Namespace, a.Name)
+	}
+	return a.Kind + " " + a.Name
+}
+
+// Apply creates or updates objects with server-side apply, in order. It stops
+// at the first failure and returns the objects applied before it.
+func (c *Client) Apply(ctx context.Context, objects []Object, opts ApplyOptions) ([]AppliedObject, error) {
+	query := url.Values{"fieldManager": {FieldManager}}
+	if opts.Force {
+		query.Set("force", "true")
+	}
+	if opts.DryRun {
+		query.Set("dryRun", "All")
+	}
+	namespace := opts.Namespace
+	if namespace == "" {
+		namespace = c.Namespace()
+	}
+
+	applied := make([]AppliedObject, 0, len(objects))
+	for _, obj := range objects {
+		result := AppliedObject{APIVersion: obj.str("apiVersion"), Kind: obj.str("kind"), Name: obj.Name()}
+		res, err := c.resource(ctx, result.APIVersion, result.Kind)
+		if err != nil {
+			return applied, fmt.Errorf("%s: %w", result, err)
+		}
+		path := groupVersionPath(result.APIVersion)
+		if res.Namespaced {
+			result.Namespace = obj.Namespace()
+			if result.Namespace == "" {
+				result.Namespace = namespace
+			}
+			path += "/namespaces/" + url.PathEscape(result.Namespace)
+		}
+		path += "/" + res.Name + "/" + url.PathEscape(result.Name)
+
+		body, err := json.Marshal(obj)
+		if err != nil {
+			return applied, fmt.Errorf("%s: %w", result, err)
+		}
+		resp, err := c.do(ctx, http.MethodPatch, path, query, "application/apply-patch+yaml", body)
+		if err != nil {
+			return applied, fmt.Errorf("failed to apply %s: %w", result, err)
+		}
+		_ = resp.Body.Close()
+		result.Action = "configured"
+		if resp.StatusCode == http.StatusCreated {
+			result.Action = "created"
+		}
+		applied = append(applied, result)
+	}
+	return applied, nil
+}
+
+// PodStatus summarizes a pod like kubectl get pods.
+type PodStatus struct {
+	Name      string `json:"name"`
+	Namespace string `json:"namespace"`
+	Phase     string `json:"phase"`
+	// Ready is ready/total containers, for example 1/2.
+	Ready    string `json:"ready"`
+	Restarts int    `json:"restarts"`
+	// Reason explains a pod that is not running cleanly, such as
+	// CrashLoopBackOff or ImagePullBackOff.
+	Reason    string     `json:"reason,omitempty"`
+	Message   string     `json:"message,omitempty"`
+	Node      string     `json:"node,omitempty"`
+	StartTime *time.Time `json:"start_time,omitempty"`
+}
+
+// PodListOptions filters Pods.
+type PodListOptions struct {
+	// LabelSelector is a label query such as app=api.
+	LabelSelector string
+	// Name limits the result to one pod.
+	Name string
+}
+
+type pod struct {
+	Metadata struct {
+		Name      string `json:"name"`
+		Namespace string `json:"namespace"`
+	} `json:"metadata"`
+	Spec struct {
+		NodeName string `json:"nodeName"`
+	} `json:"spec"`
+	Status struct {
+		Phase             string            `json:"phase"`
+		Reason            string            `json:"reason"`
+		Message           string            `json:"message"`
+		StartTime         *time.Time        `json:"startTime"`
+		ContainerStatuses []containerStatus `json:"containerStatuses"`
+	} `json:"status"`
+}
+
+type containerState struct {
+	Reason  string `json:"reason"`
+	Message string `json:"message"`
+}
+
+type containerStatus struct {
+	Ready        bool `json:"ready"`
+	RestartCount int  `json:"restartCount"`
+	State        struct {
+		Waiting    *containerState `json:"waiting"`
+		Terminated *containerState `json:"terminated"`
+	} `json:"state"`
+}
+
+// Pods lists pods in namespace, or the client's namespace when empty.
+func (c *Client) Pods(ctx context.Context, namespace string, opts PodListOptions) ([]PodStatus, error) {
+	if namespace == "" {
+		namespace = c.Namespace()
+	}
+	query := url.Values{}
+	if opts.LabelSelector != "" {
+		query.Set("labelSelector", opts.LabelSelector)
+	}
+	if opts.Name != "" {
+		query.Set("fieldSelector", "metadata.name="+opts.Name)
+	}
+	var list struct {
+		Items []pod `json:"items"`
+	}
+	if err := c.getJSON(ctx, "/api/v1/namespaces/"+url.PathEscape(namespace)+"/pods", query, &list); err != nil {
+		return nil, fmt.Errorf("failed to list pods: %w", err)
+	}
+
+	pods := make([]PodStatus, 0, len(list.Items))
+	for _, p := range list.Items {
+		status := PodStatus{
+			Name:      p.Metadata.Name,
+			Namespace: p.Metadata.Namespace,
+			Phase:     p.Status.Phase,
+			Reason:    p.Status.Reason,
+			Message:   p.Status.Message,
+			Node:      p.Spec.NodeName,
+			StartTime: p.Status.StartTime,
+		}
+		ready := 0
+		for _, cs := range p.Status.ContainerStatuses {
+			status.Restarts += cs.RestartCount
+			if cs.Ready {
+				ready++
+				continue
+			}
+			state := cs.State.Waiting
+			if state == nil {
+				state = cs.State.Terminated
+			}
+			if state != nil && status.Reason == "" {
+				status.Reason, status.Message = state.Reason, state.Message
+			}
+		}
+		status.Ready = fmt.Sprintf("%d/%d", ready, len(p.Status.ContainerStatuses))
+		pods = append(pods, status)
+	}
+	return pods, nil
+}
+
+// LogsOptions configures Logs.
+type LogsOptions struct {
+	// Container is required for pods with more than one container.
+	Container string
+	// TailLines limits output to the last lines; zero means all.
+	TailLines int
+	// Since limits output to recent logs; zero means all.
+	Since time.Duration
+	// Previous reads the logs of the last terminated container instance.
+	Previous   bool
+	Timestamps bool
+	// LimitBytes caps the output; zero means no limit.
+	LimitBytes int
+}
+
+// Logs copies a pod's logs to w.
+func (c *Client) Logs(ctx context.Context, namespace, podName string, opts LogsOptions, w io.Writer) error {
+	if namespace == "" {
+		namespace = c.Namespace()
+	}
+	query := url.Values{}
+	if opts.Container != "" {
+		query.Set("container", opts.Container)
+	}
+	if opts.TailLines > 0 {
+		query.Set("tailLines", strconv.Itoa(opts.TailLines))
+	}
+	if opts.Since > 0 {
+		query.Set("sinceSeconds", strconv.Itoa(int(opts.Since.Seconds())))
+	}
+	if opts.Previous {
+		query.Set("previous", "true")
+	}
+	if opts.Timestamps {
+		query.Set("timestamps", "true")
+	}
+	if opts.LimitBytes > 0 {
+		query.Set("limitBytes", strconv.Itoa(opts.LimitBytes))
+	}
+
+	path := "/api/v1/namespaces/" + url.PathEscape(namespace) + "/pods/" + url.PathEscape(podName) + "/log"
+	resp, err := c.do(ctx, http.MethodGet, path, query, "", nil)
+	if err != nil {
+		return fmt.Errorf("failed to read logs of pod %s: %w", podName, err)
+	}
+	defer resp.Body.Close()
+	if _, err := io.Copy(w, resp.Body); err != nil {
+		return fmt.Errorf("failed to read logs of pod %s: %w", podName, err)
+	}
+	return nil
+}
+
+// restartedAtAnnotation is the pod template annotation kubectl rollout
+// restart sets.
+const restartedAtAnnotation = "kubectl.kubernetes.io/restartedAt"
+
+// RolloutRestart restarts a deployment's pods the way kubectl rollout
+// restart does, by stamping the pod template. It returns the stamp.
+func (c *Client) RolloutRestart(ctx context.Context, namespace, deployment string) (time.Time, error) {
+	if namespace == "" {
+		namespace = c.Namespace()
+	}
+	now := time.Now().UTC().Truncate(time.Second)
+	patch, err := json.Marshal(map[string]interface{}{
+		"spec": map[string]interface{}{
+			"template": map[string]interface{}{
+				"metadata": map[string]interface{}{
+					"annotations": map[string]string{restartedAtAnnotation: now.Format(time.RFC3339)},
+				},
+			},
+		},
+	})
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	path := "/apis/apps/v1/namespaces/" + url.PathEscape(namespace) + "/deployments/" + url.PathEscape(deployment)
+	resp, err := c.do(ctx, http.MethodPatch, path, url.Values{"fieldManager": {FieldManager}}, "application/strategic-merge-patch+json", patch)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("failed to restart deployment %s: %w", deployment, err)
+	}
+	_ = resp.Body.Close()
+	return now, nil
+}
@@ -0,0 +1,239 @@
+package k8s
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// fakeAPIServer serves discovery, apply, pod, and deployment endpoints and
+// records requests.
+type fakeAPIServer struct {
+	mu       sync.Mutex
+	requests []string
+	bodies   map[string]string
+}
+
+func (s *fakeAPIServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, _ := io.ReadAll(r.Body)
+	s.mu.Lock()
+	s.requests = append(s.requests, r.Method+" "+r.URL.RequestURI())
+	if s.bodies == nil {
+		s.bodies = make(map[string]string)
+	}
+	s.bodies[r.URL.Path] = string(body)
+	s.mu.Unlock()
+
+	if r.Header.Get("Authorization") != "Bearer secret" {
+		w.WriteHeader(http.StatusUnauthorized)
+		_, _ = w.Write([]byte(`{"kind":"Status","message":"Unauthorized","reason":"Unauthorized"}`))
+		return
+	}
+	switch {
+	case r.URL.Path == "/api/v1":
+		_, _ = w.Write([]byte(`{"resources":[{"name":"namespaces","kind":"Namespace","namespaced":false},{"name":"pods","kind":"Pod","namespaced":true},{"name":"pods/log","kind":"Pod","namespaced":true}]}`))
+	case r.URL.Path == "/apis/apps/v1":
+		_, _ = w.Write([]byte(`{"resources":[{"name":"deployments","kind":"Deployment","namespaced":true}]}`))
+	case r.Method == http.MethodPatch && r.URL.Path == "/api/v1/namespaces/demo":
+		w.WriteHeader(http.StatusCreated)
+		_, _ = w.Write([]byte(`{}`))
+	case r.Method == http.MethodPatch && r.URL.Path == "/apis/apps/v1/namespaces/demo/deployments/api":
+		_, _ = w.Write([]byte(`{}`))
+	case r.URL.Path == "/api/v1/namespaces/demo/pods":
+		_, _ = w.Write([]byte(`{"items":[
+			{"metadata":{"name":"api-1","namespace":"demo"},"spec":{"nodeName":"n1"},"status":{"phase":"Running","containerStatuses":[
+				{"ready":true,"restartCount":0,"state":{"running":{}}},
+				{"ready":false,"restartCount":3,"state":{"waiting":{"reason":"CrashLoopBackOff","message":"back-off"}}}]}}]}`))
+	case r.URL.Path == "/api/v1/namespaces/demo/pods/api-1/log":
+		_, _ = w.Write([]byte("listening on :8080\n"))
+	default:
+		w.WriteHeader(http.StatusNotFound)
+		_, _ = w.Write([]byte(`{"kind":"Status","message":"the server could not find the requested resource","reason":"NotFound"}`))
+	}
+}
+
+func newTestClient(t *testing.T, handler http.Handler) *Client {
+	t.Helper()
+	srv := httptest.NewServer(handler)
+	t.Cleanup(srv.Close)
+
+	kubeconfig := filepath.Join(t.TempDir(), "config")
+	data := `apiVersion: v1
+kind: Config
+current-context: dev
+clusters:
+- name: dev
+  cluster:
+    server: ` + srv.URL + `
+users:
+- name: dev
+  user:
+    token: secret
+contexts:
+- name: dev
+  context:
+    cluster: dev
+    user: dev
+    namespace: demo
+- name: other
+  context:
+    cluster: missing
+    user: dev
+`
+	if err := os.WriteFile(kubeconfig, []byte(data), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	cli, err := NewClient(Options{Kubeconfig: kubeconfig})
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+	return cli
+}
+
+func TestNewClientSelectsContext(t *testing.T) {
+	cli := newTestClient(t, &fakeAPIServer{})
+	if cli.Context() != "dev" || cli.Namespace() != "demo" {
+		t.Errorf("context = %q, namespace = %q", cli.Context(), cli.Namespace())
+	}
+
+	path := filepath.Join(cli.cfg.baseDir, "config")
+	names, current, err := Contexts(path)
+	if err != nil {
+		t.Fatalf("Contexts() error = %v", err)
+	}
+	if !reflect.DeepEqual(names, []string{"dev", "other"}) || current != "dev" {
+		t.Errorf("Contexts() = %v, %q", names, current)
+	}
+	if _, err := NewClient(Options{Kubeconfig: path, Context: "other"}); err == nil || !strings.Contains(err.Error(), "no server") {
+		t.Errorf("NewClient(other) error = %v", err)
+	}
+	if _, err := NewClient(Options{Kubeconfig: path, Context: "prod"}); err == nil || !strings.Contains(err.Error(), "not found") {
+		t.Errorf("NewClient(prod) error = %v", err)
+	}
+}
+
+func TestParseManifests(t *testing.T) {
+	objects, err := ParseManifests([]byte(`---
+apiVersion: v1
+kind: Namespace
+metadata:
+  name: demo
+---
+---
+apiVersion: v1
+kind: List
+items:
+- apiVersion: apps/v1
+  kind: Deployment
+  metadata:
+    name: api
+`))
+	if err != nil {
+		t.Fatalf("ParseManifests() error = %v", err)
+	}
+	if len(objects) != 2 || objects[0].Name() != "demo" || objects[1].str("kind") != "Deployment" {
+		t.Errorf("objects = %v", objects)
+	}
+
+	for _, manifest := range []string{"", "kind: Pod\nmetadata:\n  name: x\n", "a: [\n"} {
+		if _, err := ParseManifests([]byte(manifest)); err == nil {
+			t.Errorf("ParseManifests(%q) should fail", manifest)
+		}
+	}
+}
+
+func TestApplyUsesServerSideApply(t *testing.T) {
+	server := &fakeAPIServer{}
+	cli := newTestClient(t, server)
+	objects, err := ParseManifests([]byte(`apiVersion: v1
+kind: Namespace
+metadata:
+  name: demo
+---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: api
+spec:
+  replicas: 2
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	applied, err := cli.Apply(context.Background(), objects, ApplyOptions{DryRun: true})
+	if err != nil {
+		t.Fatalf("Apply() error = %v", err)
+	}
+	want := []AppliedObject{
+		{APIVersion: "v1", Kind: "Namespace", Name: "demo", Action: "created"},
+		{APIVersion: "apps/v1", Kind: "Deployment", Namespace: "demo", Name: "api", Action: "configured"},
+	}
+	if !reflect.DeepEqual(applied, want) {
+		t.Errorf("Apply() = %+v, want %+v", applied, want)
+	}
+	if !slices.Contains(server.requests, "PATCH /apis/apps/v1/namespaces/demo/deployments/api?dryRun=All&fieldManager=juleson") {
+		t.Errorf("requests = %v", server.requests)
+	}
+	var body map[string]interface{}
+	if err := json.Unmarshal([]byte(server.bodies["/apis/apps/v1/namespaces/demo/deployments/api"]), &body); err != nil || body["kind"] != "Deployment" {
+		t.Errorf("apply body = %v, %v", body, err)
+	}
+
+	_, err = cli.Apply(context.Background(), []Object{{"apiVersion": "batch/v1", "kind": "Job", "metadata": map[string]interface{}{"name": "x"}}}, ApplyOptions{})
+	if err == nil || !strings.Contains(err.Error(), "not served") {
+		t.Errorf("Apply(unknown group) error = %v", err)
+	}
+}
+
+func TestPodsSummarizesStatus(t *testing.T) {
+	cli := newTestClient(t, &fakeAPIServer{})
+
+	pods, err := cli.Pods(context.Background(), "", PodListOptions{LabelSelector: "app=api"})
+	if err != nil {
+		t.Fatalf("Pods() error = %v", err)
+	}
+	if len(pods) != 1 {
+		t.Fatalf("pods = %+v", pods)
+	}
+	got := pods[0]
+	if got.Ready != "1/2" || got.Restarts != 3 || got.Reason != "CrashLoopBackOff" || got.Node != "n1" {
+		t.Errorf("pod = %+v", got)
+	}
+
+	var logs strings.Builder
+	if err := cli.Logs(context.Background(), "", "api-1", LogsOptions{TailLines: 10}, &logs); err != nil {
+		t.Fatalf("Logs() error = %v", err)
+	}
+	if logs.String() != "listening on :8080\n" {
+		t.Errorf("logs = %q", logs.String())
+	}
+}
+
+func TestRolloutRestartPatchesTemplate(t *testing.T) {
+	server := &fakeAPIServer{}
+	cli := newTestClient(t, server)
+
+	stamp, err := cli.RolloutRestart(context.Background(), "demo", "api")
+	if err != nil {
+		t.Fatalf("RolloutRestart() error = %v", err)
+	}
+	body := server.bodies["/apis/apps/v1/namespaces/demo/deployments/api"]
+	if !strings.Contains(body, restartedAtAnnotation) || !strings.Contains(body, stamp.Format("2006-01-02T15:04:05Z")) {
+		t.Errorf("patch = %s", body)
+	}
+
+	_, err = cli.RolloutRestart(context.Background(), "demo", "missing")
+	if !IsNotFound(err) {
+		t.Errorf("RolloutRestart(missing) error = %v, want not found", err)
+	}
+}
@@ -0,0 +1,291 @@
+package k8s
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// kubeconfig holds the parts of a kubeconfig file the client uses.
+type kubeconfig struct {
+	CurrentContext string `yaml:"current-context"`
+	Clusters       []struct {
+		Name    string `yaml:"name"`
+		Cluster struct {
+			Server                   string `yaml:"server"`
+			CertificateAuthority     string `yaml:"certificate-authority"`
+			CertificateAuthorityData string `yaml:"certificate-authority-data"`
+			InsecureSkipTLSVerify    bool   `yaml:"insecure-skip-tls-verify"`
+			TLSServerName            string `yaml:"tls-server-name"`
+		} `yaml:"cluster"`
+	} `yaml:"clusters"`
+	Users []struct {
+		Name string   `yaml:"name"`
+		User authInfo `yaml:"user"`
+	} `yaml:"users"`
+	Contexts []struct {
+		Name    string `yaml:"name"`
+		Context struct {
+			Cluster   string `yaml:"cluster"`
+			User      string `yaml:"user"`
+			Namespace string `yaml:"namespace"`
+		} `yaml:"context"`
+	} `yaml:"contexts"`
+}
+
+type authInfo struct {
+	ClientCertificate     string `yaml:"client-certificate"`
+	ClientCertificateData string `yaml:"client-certificate-data"`
+	ClientKey             string `yaml:"client-key"`
+	ClientKeyData         string `yaml:"client-key-data"`
+	Token                 string `yaml:"token"`
+	TokenFile             string `yaml:"tokenFile"`
+	Username              string `yaml:"username"`
+	Password              string `yaml:"password"`
+	Exec                  *struct {
+		APIVersion string   `yaml:"apiVersion"`
+		Command    string   `yaml:"command"`
+		Args       []string `yaml:"args"`
+		Env        []struct {
+			Name  string `yaml:"name"`
+			Value string `yaml:"value"`
+		} `yaml:"env"`
+	} `yaml:"exec"`
+}
+
+// KubeconfigPath returns path when set, otherwise the first entry of
+// $KUBECONFIG, otherwise ~/.kube/config.
+func KubeconfigPath(path string) string {
+	if path != "" {
+		return path
+	}
+	for _, p := range filepath.SplitList(os.Getenv("KUBECONFIG")) {
+		if p != "" {
+			return p
+		}
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return filepath.Join(".kube", "config")
+	}
+	return filepath.Join(home, ".kube", "config")
+}
+
+// Contexts lists the context names in the kubeconfig at path and the current
+// context.
+func Contexts(path string) ([]string, string, error) {
+	kc, _, err := readKubeconfig(path)
+	if err != nil {
+		return nil, "", err
+	}
+	names := make([]string, 0, len(kc.Contexts))
+	for _, c := range kc.Contexts {
+		names = append(names, c.Name)
+	}
+	return names, kc.CurrentContext, nil
+}
+
+func readKubeconfig(path string) (*kubeconfig, string, error) {
+	path = KubeconfigPath(path)
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to read kubeconfig: %w", err)
+	}
+	var kc kubeconfig
+	if err := yaml.Unmarshal(data, &kc); err != nil {
+		return nil, "", fmt.Errorf("failed to parse kubeconfig %s: %w", path, err)
+	}
+	return &kc, filepath.Dir(path), nil
+}
+
+// restConfig is a resolved cluster endpoint and its credentials.
+type restConfig struct {
+	context   string
+	server    string
+	namespace string
+	transport *http.Transport
+	auth      authInfo
+	baseDir   string
+
+	// execMu guards execCached, the token from an exec credential plugin,
+	// which is reused for the life of the client.
+	execMu     sync.Mutex
+	execCached string
+}
+
+// loadConfig resolves contextName, or the current context when empty, from
+// the kubeconfig at path.
+func loadConfig(path, contextName string) (*restConfig, error) {
+	kc, baseDir, err := readKubeconfig(path)
+	if err != nil {
+		return nil, err
+	}
+	if contextName == "" {
+		contextName = kc.CurrentContext
+	}
+	if contextName == "" {
+		return nil, fmt.Errorf("kubeconfig has no current-context; choose a context")
+	}
+
+	cfg := &restConfig{context: contextName, baseDir: baseDir}
+	var clusterName, userName string
+	found := false
+	for _, c := range kc.Contexts {
+		if c.Name == contextName {
+			clusterName, userName, cfg.namespace = c.Context.Cluster, c.Context.User, c.Context.Namespace
+			found = true
+			break
+		}
+	}
+	if !found {
+		return nil, fmt.Errorf("context %q not found in kubeconfig", contextName)
+	}
+
+	tlsConfig := &tls.Config{MinVersion: tls.VersionTLS12}
+	found = false
+	for _, c := range kc.Clusters {
+		if c.Name != clusterName {
+			continue
+		}
+		found = true
+		cfg.server = strings.TrimSuffix(c.Cluster.Server, "/")
+		tlsConfig.InsecureSkipVerify = c.Cluster.InsecureSkipTLSVerify
+		tlsConfig.ServerName = c.Cluster.TLSServerName
+		ca, err := dataOrFile(c.Cluster.CertificateAuthorityData, c.Cluster.CertificateAuthority, baseDir)
+		if err != nil {
+			return nil, fmt.Errorf("cluster %s certificate authority: %w", clusterName, err)
+		}
+		if len(ca) > 0 {
+			pool := x509.NewCertPool()
+			if !pool.AppendCertsFromPEM(ca) {
+				return nil, fmt.Errorf("cluster %s certificate authority has no PEM certificates", clusterName)
+			}
+			tlsConfig.RootCAs = pool
+		}
+	}
+	if !found || cfg.server == "" {
+		return nil, fmt.Errorf("cluster %q for context %q has no server", clusterName, contextName)
+	}
+
+	for _, u := range kc.Users {
+		if u.Name == userName {
+			cfg.auth = u.User
+			break
+		}
+	}
+	cert, err := dataOrFile(cfg.auth.ClientCertificateData, cfg.auth.ClientCertificate, baseDir)
+	if err != nil {
+		return nil, fmt.Errorf("user %s client certificate: %w", userName, err)
+	}
+	key, err := dataOrFile(cfg.auth.ClientKeyData, cfg.auth.ClientKey, baseDir)
+	if err != nil {
+		return nil, fmt.Errorf("user %s client key: %w", userName, err)
+	}
+	if len(cert) > 0 || len(key) > 0 {
+		pair, err := tls.X509KeyPair(cert, key)
+		if err != nil {
+			return nil, fmt.Errorf("user %s client certificate: %w", userName, err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{pair}
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = tlsConfig
+	cfg.transport = transport
+	return cfg, nil
+}
+
+// dataOrFile returns base64-decoded data, or the contents of file resolved
+// relative to baseDir.
+func dataOrFile(data, file, baseDir string) ([]byte, error) {
+	if data != "" {
+		return base64.StdEncoding.DecodeString(data)
+	}
+	if file == "" {
+		return nil, nil
+	}
+	if !filepath.IsAbs(file) {
+		file = filepath.Join(baseDir, file)
+	}
+	return os.ReadFile(file)
+}
+
+// authorize sets credentials on req: a static or file token, basic auth, or a
+// token from an exec credential plugin.
+func (c *restConfig) authorize(ctx context.Context, req *http.Request) error {
+	switch {
+	case c.auth.Token != "":
+		req.Header.Set("Authorization", "Bearer "+c.auth.Token)
+	case c.auth.TokenFile != "":
+		token, err := dataOrFile("", c.auth.TokenFile, c.baseDir)
+		if err != nil {
+			return fmt.Errorf("failed to read token file: %w", err)
+		}
+		req.Header.Set("Authorization", "Bearer "+strings.TrimSpace(string(token)))
+	case c.auth.Username != "":
+		req.SetBasicAuth(c.auth.Username, c.auth.Password)
+	case c.auth.Exec != nil:
+		c.execMu.Lock()
+		defer c.execMu.Unlock()
+		if c.execCached == "" {
+			token, err := c.execToken(ctx)
+			if err != nil {
+				return err
+			}
+			c.execCached = token
+		}
+		req.Header.Set("Authorization", "Bearer "+c.execCached)
+	}
+	return nil
+}
+
+// execToken runs the exec credential plugin and returns its bearer token.
+// Plugins that only return client certificates are not supported.
+func (c *restConfig) execToken(ctx context.Context) (string, error) {
+	plugin := c.auth.Exec
+	ctx, cancel := context.WithTimeout(ctx, time.Minute)
+	defer cancel()
+
+	info, _ := json.Marshal(map[string]interface{}{
+		"apiVersion": plugin.APIVersion,
+		"kind":       "ExecCredential",
+		"spec":       map[string]bool{"interactive": false},
+	})
+	cmd := exec.CommandContext(ctx, plugin.Command, plugin.Args...)
+	cmd.Env = append(os.Environ(), "KUBERNETES_EXEC_INFO="+string(info))
+	for _, env := range plugin.Env {
+		cmd.Env = append(cmd.Env, env.Name+"="+env.Value)
+	}
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("credential plugin %s failed: %w: %s", plugin.Command, err, strings.TrimSpace(stderr.String()))
+	}
+
+	var cred struct {
+		Status struct {
+			Token string `json:"token"`
+		} `json:"status"`
+	}
+	if err := json.Unmarshal(out, &cred); err != nil {
+		return "", fmt.Errorf("credential plugin %s returned invalid output: %w", plugin.Command, err)
+	}
+	if cred.Status.Token == "" {
+		return "", fmt.Errorf("credential plugin %s returned no token", plugin.Command)
+	}
+	return cred.Status.Token, nil
+}
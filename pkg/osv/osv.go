@@ -0,0 +1,194 @@
+// Package osv is a small client for the OSV vulnerability database
+// (https://osv.dev). It batches package version queries and fetches the
+// details of the vulnerabilities they match.
+package osv
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// DefaultBaseURL is the public OSV API.
+const DefaultBaseURL = "https://api.osv.dev"
+
+// maxBatchQueries is the OSV limit on queries per batch request.
+const maxBatchQueries = 1000
+
+// Ecosystems used by Juleson's dependency audit.
+const (
+	EcosystemGo   = "Go"
+	EcosystemNPM  = "npm"
+	EcosystemPyPI = "PyPI"
+)
+
+// Package identifies a package in an ecosystem.
+type Package struct {
+	Name      string `json:"name"`
+	Ecosystem string `json:"ecosystem"`
+}
+
+// Query asks for the vulnerabilities affecting one package version.
+type Query struct {
+	Package Package `json:"package"`
+	Version string  `json:"version,omitempty"`
+}
+
+// Severity is a scored severity such as a CVSS vector.
+type Severity struct {
+	Type  string `json:"type"`
+	Score string `json:"score"`
+}
+
+// Event is one point in an affected range.
+type Event struct {
+	Introduced string `json:"introduced,omitempty"`
+	Fixed      string `json:"fixed,omitempty"`
+}
+
+// Range is a span of affected versions.
+type Range struct {
+	Type   string  `json:"type"`
+	Events []Event `json:"events"`
+}
+
+// Affected lists the affected versions of one package.
+type Affected struct {
+	Package Package `json:"package"`
+	Ranges  []Range `json:"ranges,omitempty"`
+}
+
+// Vulnerability is an OSV record, reduced to the fields Juleson reports.
+type Vulnerability struct {
+	ID               string     `json:"id"`
+	Summary          string     `json:"summary,omitempty"`
+	Aliases          []string   `json:"aliases,omitempty"`
+	Severity         []Severity `json:"severity,omitempty"`
+	Affected         []Affected `json:"affected,omitempty"`
+	DatabaseSpecific struct {
+		Severity string `json:"severity,omitempty"`
+	} `json:"database_specific"`
+}
+
+// SeverityLabel returns the database's severity rating, such as HIGH or
+// MODERATE, or UNKNOWN when the record has none.
+func (v *Vulnerability) SeverityLabel() string {
+	if v.DatabaseSpecific.Severity != "" {
+		return strings.ToUpper(v.DatabaseSpecific.Severity)
+	}
+	return "UNKNOWN"
+}
+
+// FixedVersions returns the versions that fix the vulnerability for pkg.
+func (v *Vulnerability) FixedVersions(pkg Package) []string {
+	var fixed []string
+	for _, affected := range v.Affected {
+		if affected.Package.Ecosystem != pkg.Ecosystem || affected.Package.Name != pkg.Name {
+			continue
+		}
+		for _, r := range affected.Ranges {
+			for _, event := range r.Events {
+				if event.Fixed != "" {
+					fixed = append(fixed, event.Fixed)
+				}
+			}
+		}
+	}
+	return fixed
+}
+
+// Client talks to an OSV API server.
+type Client struct {
+	baseURL string
+	http    *http.Client
+}
+
+// NewClient returns a client for baseURL, or DefaultBaseURL when empty. A nil
+// httpClient uses one with a 30 second timeout.
+func NewClient(baseURL string, httpClient *http.Client) *Client {
+	if baseURL == "" {
+		baseURL = DefaultBaseURL
+	}
+	if httpClient == nil {
+		httpClient = &http.Client{Timeout: 30 * time.Second}
+	}
+	return &Client{baseURL: strings.TrimRight(baseURL, "/"), http: httpClient}
+}
+
+// QueryBatch returns, for each query, the IDs of the vulnerabilities that
+// affect it. Results are in query order.
+func (c *Client) QueryBatch(ctx context.Context, queries []Query) ([][]string, error) {
+	results := make([][]string, 0, len(queries))
+	for start := 0; start < len(queries); start += maxBatchQueries {
+		batch := queries[start:min(start+maxBatchQueries, len(queries))]
+		var response struct {
+			Results []struct {
+				Vulns []struct {
+					ID string `json:"id"`
+				} `json:"vulns"`
+			} `json:"results"`
+		}
+		if err := c.do(ctx, http.MethodPost, "/v1/querybatch", map[string][]Query{"queries": batch}, &response); err != nil {
+			return nil, err
+		}
+		if len(response.Results) != len(batch) {
+			return nil, fmt.Errorf("osv: got %d results for %d queries", len(response.Results), len(batch))
+		}
+		for _, result := range response.Results {
+			ids := make([]string, 0, len(result.Vulns))
+			for _, vuln := range result.Vulns {
+				ids = append(ids, vuln.ID)
+			}
+			results = append(results, ids)
+		}
+	}
+	return results, nil
+}
+
+// GetVulnerability fetches the full record for id.
+func (c *Client) GetVulnerability(ctx context.Context, id string) (*Vulnerability, error) {
+	var vuln Vulnerability
+	if err := c.do(ctx, http.MethodGet, "/v1/vulns/"+url.PathEscape(id), nil, &vuln); err != nil {
+		return nil, err
+	}
+	return &vuln, nil
+}
+
+func (c *Client) do(ctx context.Context, method, path string, body, out any) error {
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reader = bytes.NewReader(data)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, c.baseURL+path, reader)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return fmt.Errorf("osv: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		message, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+		return fmt.Errorf("osv: %s %s: %s: %s", method, path, resp.Status, strings.TrimSpace(string(message)))
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("osv: decode %s: %w", path, err)
+	}
+	return nil
+}
@@ -0,0 +1,69 @@
+package osv
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestQueryBatchAndGetVulnerability(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == http.MethodPost && r.URL.Path == "/v1/querybatch":
+			var body struct {
+				Queries []Query `json:"queries"`
+			}
+			if err := json.NewDecoder(r.Body).Decode(&body); err != nil || len(body.Queries) != 2 {
+				http.Error(w, "bad request", http.StatusBadRequest)
+				return
+			}
+			if body.Queries[0].Package != (Package{Name: "golang.org/x/net", Ecosystem: EcosystemGo}) || body.Queries[0].Version != "0.1.0" {
+				http.Error(w, "unexpected query", http.StatusBadRequest)
+				return
+			}
+			_, _ = w.Write([]byte(`{"results":[{"vulns":[{"id":"GO-2023-0001"}]},{}]}`))
+		case r.Method == http.MethodGet && r.URL.Path == "/v1/vulns/GO-2023-0001":
+			_, _ = w.Write([]byte(`{
+				"id":"GO-2023-0001","summary":"Request smuggling","aliases":["CVE-2023-0001"],
+				"affected":[{"package":{"name":"golang.org/x/net","ecosystem":"Go"},
+					"ranges":[{"type":"SEMVER","events":[{"introduced":"0"},{"fixed":"0.7.0"}]}]}],
+				"database_specific":{"severity":"high"}}`))
+		default:
+			http.Error(w, `{"message":"not found"}`, http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL+"/", nil)
+	ctx := context.Background()
+	results, err := client.QueryBatch(ctx, []Query{
+		{Package: Package{Name: "golang.org/x/net", Ecosystem: EcosystemGo}, Version: "0.1.0"},
+		{Package: Package{Name: "left-pad", Ecosystem: EcosystemNPM}, Version: "1.3.0"},
+	})
+	if err != nil {
+		t.Fatalf("QueryBatch() error = %v", err)
+	}
+	if want := [][]string{{"GO-2023-0001"}, {}}; !reflect.DeepEqual(results, want) {
+		t.Fatalf("QueryBatch() = %v, want %v", results, want)
+	}
+
+	vuln, err := client.GetVulnerability(ctx, "GO-2023-0001")
+	if err != nil {
+		t.Fatalf("GetVulnerability() error = %v", err)
+	}
+	if vuln.SeverityLabel() != "HIGH" {
+		t.Errorf("SeverityLabel() = %q", vuln.SeverityLabel())
+	}
+	if fixed := vuln.FixedVersions(Package{Name: "golang.org/x/net", Ecosystem: EcosystemGo}); !reflect.DeepEqual(fixed, []string{"0.7.0"}) {
+		t.Errorf("FixedVersions() = %v", fixed)
+	}
+
+	_, err = client.GetVulnerability(ctx, "MISSING")
+	if err == nil || !strings.Contains(err.Error(), "404") {
+		t.Errorf("GetVulnerability(MISSING) error = %v, want 404", err)
+	}
+}
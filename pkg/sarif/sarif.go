@@ -0,0 +1,163 @@
+// Package sarif writes SARIF 2.1.0 logs
+// (https://docs.oasis-open.org/sarif/sarif/v2.1.0/), the static analysis
+// format accepted by GitHub code scanning. It covers the subset Juleson
+// reports: one run per tool, rules, and results located by file and line.
+package sarif
+
+import (
+	"encoding/json"
+	"io"
+	"slices"
+)
+
+// Version and Schema identify the SARIF format written by this package.
+const (
+	Version = "2.1.0"
+	Schema  = "https://json.schemastore.org/sarif-2.1.0.json"
+)
+
+// Result levels.
+const (
+	LevelError   = "error"
+	LevelWarning = "warning"
+	LevelNote    = "note"
+)
+
+// Log is a SARIF log file.
+type Log struct {
+	Schema  string `json:"$schema"`
+	Version string `json:"version"`
+	Runs    []Run  `json:"runs"`
+}
+
+// Run is the output of one analysis tool.
+type Run struct {
+	Tool    Tool     `json:"tool"`
+	Results []Result `json:"results"`
+}
+
+// Tool describes the analysis tool of a run.
+type Tool struct {
+	Driver Driver `json:"driver"`
+}
+
+// Driver is the tool component that produced the results.
+type Driver struct {
+	Name           string `json:"name"`
+	Version        string `json:"version,omitempty"`
+	InformationURI string `json:"informationUri,omitempty"`
+	Rules          []Rule `json:"rules"`
+}
+
+// Rule describes one kind of result.
+type Rule struct {
+	ID                   string        `json:"id"`
+	ShortDescription     Message       `json:"shortDescription"`
+	Help                 *Message      `json:"help,omitempty"`
+	DefaultConfiguration *RuleDefaults `json:"defaultConfiguration,omitempty"`
+	// Properties holds tags and the security-severity score that code
+	// scanning uses to rank security results.
+	Properties map[string]any `json:"properties,omitempty"`
+}
+
+// RuleDefaults sets the default level of a rule's results.
+type RuleDefaults struct {
+	Level string `json:"level"`
+}
+
+// Message is a plain text message.
+type Message struct {
+	Text string `json:"text"`
+}
+
+// Result is one finding.
+type Result struct {
+	RuleID    string     `json:"ruleId"`
+	RuleIndex int        `json:"ruleIndex"`
+	Level     string     `json:"level"`
+	Message   Message    `json:"message"`
+	Locations []Location `json:"locations,omitempty"`
+}
+
+// Location is where a result was found.
+type Location struct {
+	PhysicalLocation PhysicalLocation `json:"physicalLocation"`
+}
+
+// PhysicalLocation is a file and an optional region in it.
+type PhysicalLocation struct {
+	ArtifactLocation ArtifactLocation `json:"artifactLocation"`
+	Region           *Region          `json:"region,omitempty"`
+}
+
+// ArtifactLocation is a file path relative to the repository root.
+type ArtifactLocation struct {
+	URI string `json:"uri"`
+}
+
+// Region is a span of lines; lines start at 1.
+type Region struct {
+	StartLine int `json:"startLine"`
+}
+
+// Builder collects the rules and results of one tool run.
+type Builder struct {
+	run Run
+}
+
+// NewBuilder starts a run for the named tool.
+func NewBuilder(name, version, informationURI string) *Builder {
+	return &Builder{run: Run{
+		Tool:    Tool{Driver: Driver{Name: name, Version: version, InformationURI: informationURI, Rules: []Rule{}}},
+		Results: []Result{},
+	}}
+}
+
+// AddRule registers a rule unless one with the same ID exists.
+func (b *Builder) AddRule(rule Rule) {
+	if b.ruleIndex(rule.ID) < 0 {
+		b.run.Tool.Driver.Rules = append(b.run.Tool.Driver.Rules, rule)
+	}
+}
+
+// AddResult records a finding of a registered rule at file and line. An
+// empty file leaves the result without a location, and a line below 1
+// without a region. An empty level uses the rule's default.
+func (b *Builder) AddResult(ruleID, level, message, file string, line int) {
+	index := b.ruleIndex(ruleID)
+	if index < 0 {
+		b.AddRule(Rule{ID: ruleID, ShortDescription: Message{Text: ruleID}})
+		index = len(b.run.Tool.Driver.Rules) - 1
+	}
+	if level == "" {
+		level = LevelWarning
+		if defaults := b.run.Tool.Driver.Rules[index].DefaultConfiguration; defaults != nil {
+			level = defaults.Level
+		}
+	}
+	result := Result{RuleID: ruleID, RuleIndex: index, Level: level, Message: Message{Text: message}}
+	if file != "" {
+		location := Location{PhysicalLocation: PhysicalLocation{ArtifactLocation: ArtifactLocation{URI: file}}}
+		if line > 0 {
+			location.PhysicalLocation.Region = &Region{StartLine: line}
+		}
+		result.Locations = []Location{location}
+	}
+	b.run.Results = append(b.run.Results, result)
+}
+
+// Log returns a log holding the run built so far.
+func (b *Builder) Log() *Log {
+	return &Log{Schema: Schema, Version: Version, Runs: []Run{b.run}}
+}
+
+func (b *Builder) ruleIndex(id string) int {
+	return slices.IndexFunc(b.run.Tool.Driver.Rules, func(rule Rule) bool { return rule.ID == id })
+}
+
+// Write encodes the log as indented JSON.
+func (l *Log) Write(w io.Writer) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(l)
+}
@@ -0,0 +1,41 @@
+package sarif
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+)
+
+func TestBuilder(t *testing.T) {
+	b := NewBuilder("juleson", "1.0.0", "https://example.com")
+	b.AddRule(Rule{ID: "a", ShortDescription: Message{Text: "A"}, DefaultConfiguration: &RuleDefaults{Level: LevelError}})
+	b.AddRule(Rule{ID: "a", ShortDescription: Message{Text: "duplicate"}})
+	b.AddResult("a", "", "found a", "src/a.go", 3)
+	b.AddResult("b", LevelNote, "found b", "", 0)
+
+	var buf bytes.Buffer
+	if err := b.Log().Write(&buf); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+	var log Log
+	if err := json.Unmarshal(buf.Bytes(), &log); err != nil {
+		t.Fatalf("invalid JSON: %v", err)
+	}
+	if log.Version != Version || log.Schema != Schema || len(log.Runs) != 1 {
+		t.Fatalf("log = %+v", log)
+	}
+	run := log.Runs[0]
+	if len(run.Tool.Driver.Rules) != 2 || run.Tool.Driver.Rules[0].ShortDescription.Text != "A" {
+		t.Errorf("rules = %+v", run.Tool.Driver.Rules)
+	}
+	if len(run.Results) != 2 {
+		t.Fatalf("results = %+v", run.Results)
+	}
+	first, second := run.Results[0], run.Results[1]
+	if first.Level != LevelError || first.RuleIndex != 0 || first.Locations[0].PhysicalLocation.ArtifactLocation.URI != "src/a.go" || first.Locations[0].PhysicalLocation.Region.StartLine != 3 {
+		t.Errorf("first result = %+v", first)
+	}
+	if second.Level != LevelNote || second.RuleIndex != 1 || second.Locations != nil {
+		t.Errorf("second result = %+v", second)
+	}
+}
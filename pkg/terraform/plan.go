@@ -0,0 +1,163 @@
+package terraform
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// Resource change actions, as summarized from terraform's action lists.
+const (
+	ActionCreate  = "create"
+	ActionUpdate  = "update"
+	ActionDelete  = "delete"
+	ActionReplace = "replace"
+	ActionRead    = "read"
+	ActionForget  = "forget"
+	ActionNoOp    = "no-op"
+)
+
+// ChangeCounts counts resource changes by action.
+type ChangeCounts struct {
+	Create  int `json:"create"`
+	Update  int `json:"update"`
+	Delete  int `json:"delete"`
+	Replace int `json:"replace"`
+	Read    int `json:"read"`
+	Forget  int `json:"forget"`
+}
+
+// ResourceChange is one resource the plan would change.
+type ResourceChange struct {
+	Address  string `json:"address"`
+	Type     string `json:"type"`
+	Provider string `json:"provider"`
+	Action   string `json:"action"`
+	// Reason explains replacements and deletions, such as
+	// replace_because_tainted.
+	Reason string `json:"reason,omitempty"`
+
+	// after is the planned state, used by Review.
+	after interface{}
+}
+
+// PlanSummary summarizes terraform show -json output for a saved plan.
+type PlanSummary struct {
+	TerraformVersion string           `json:"terraform_version"`
+	Counts           ChangeCounts     `json:"counts"`
+	Changes          []ResourceChange `json:"changes"`
+	// Outputs maps changed root module outputs to their action.
+	Outputs map[string]string `json:"outputs,omitempty"`
+	// Drift counts resources changed outside terraform since the last apply.
+	Drift      int   `json:"drift"`
+	DurationMS int64 `json:"duration_ms"`
+}
+
+// HasChanges reports whether applying the plan would change anything.
+func (s *PlanSummary) HasChanges() bool {
+	return len(s.Changes) > 0 || len(s.Outputs) > 0
+}
+
+// String formats the summary like terraform's closing plan line.
+func (s *PlanSummary) String() string {
+	if !s.HasChanges() {
+		return "No changes."
+	}
+	c := s.Counts
+	line := fmt.Sprintf("Plan: %d to add, %d to change, %d to destroy", c.Create+c.Replace, c.Update, c.Delete+c.Replace)
+	if c.Replace > 0 {
+		line += fmt.Sprintf(" (%d replaced)", c.Replace)
+	}
+	return line + "."
+}
+
+type jsonPlan struct {
+	TerraformVersion string `json:"terraform_version"`
+	ResourceChanges  []struct {
+		Address      string `json:"address"`
+		Mode         string `json:"mode"`
+		Type         string `json:"type"`
+		ProviderName string `json:"provider_name"`
+		ActionReason string `json:"action_reason"`
+		Change       struct {
+			Actions []string    `json:"actions"`
+			After   interface{} `json:"after"`
+		} `json:"change"`
+	} `json:"resource_changes"`
+	OutputChanges map[string]struct {
+		Actions []string `json:"actions"`
+	} `json:"output_changes"`
+	ResourceDrift []json.RawMessage `json:"resource_drift"`
+}
+
+// summarizeActions collapses terraform's action list, where a replacement is
+// delete and create in either order.
+func summarizeActions(actions []string) string {
+	if len(actions) == 2 {
+		return ActionReplace
+	}
+	if len(actions) == 1 {
+		return actions[0]
+	}
+	return ActionNoOp
+}
+
+// ParsePlan summarizes terraform show -json output for a saved plan.
+// Unchanged resources are left out.
+func ParsePlan(data []byte) (*PlanSummary, error) {
+	var plan jsonPlan
+	if err := json.Unmarshal(data, &plan); err != nil {
+		return nil, fmt.Errorf("failed to parse terraform plan: %w", err)
+	}
+	summary := &PlanSummary{
+		TerraformVersion: plan.TerraformVersion,
+		Changes:          []ResourceChange{},
+		Drift:            len(plan.ResourceDrift),
+	}
+	for _, rc := range plan.ResourceChanges {
+		action := summarizeActions(rc.Change.Actions)
+		switch action {
+		case ActionCreate:
+			summary.Counts.Create++
+		case ActionUpdate:
+			summary.Counts.Update++
+		case ActionDelete:
+			summary.Counts.Delete++
+		case ActionReplace:
+			summary.Counts.Replace++
+		case ActionRead:
+			summary.Counts.Read++
+		case ActionForget:
+			summary.Counts.Forget++
+		default:
+			continue
+		}
+		summary.Changes = append(summary.Changes, ResourceChange{
+			Address:  rc.Address,
+			Type:     rc.Type,
+			Provider: rc.ProviderName,
+			Action:   action,
+			Reason:   rc.ActionReason,
+			after:    rc.Change.After,
+		})
+	}
+	for name, oc := range plan.OutputChanges {
+		if action := summarizeActions(oc.Actions); action != ActionNoOp {
+			if summary.Outputs == nil {
+				summary.Outputs = make(map[string]string)
+			}
+			summary.Outputs[name] = action
+		}
+	}
+	return summary, nil
+}
+
+// typeContains reports whether a resource type contains any of words.
+func typeContains(resourceType string, words ...string) bool {
+	for _, w := range words {
+		if strings.Contains(resourceType, w) {
+			return true
+		}
+	}
+	return false
+}
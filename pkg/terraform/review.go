@@ -0,0 +1,141 @@
+package terraform
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+)
+
+// Finding severities.
+const (
+	SeverityHigh   = "high"
+	SeverityMedium = "medium"
+)
+
+// Review verdicts, from least to most restrictive.
+const (
+	// VerdictPass means nothing in the plan needs a second look.
+	VerdictPass = "pass"
+	// VerdictReview means a person should read the findings before applying.
+	VerdictReview = "review"
+	// VerdictBlock means the plan destroys data or exposes resources publicly
+	// and must not be applied without explicit sign-off.
+	VerdictBlock = "block"
+)
+
+// Finding is one concern about a planned change.
+type Finding struct {
+	Severity string `json:"severity"`
+	Rule     string `json:"rule"`
+	Address  string `json:"address"`
+	Message  string `json:"message"`
+}
+
+// Review is the security gate's verdict on a plan.
+type Review struct {
+	Verdict  string    `json:"verdict"`
+	Findings []Finding `json:"findings"`
+}
+
+// stateful resource type fragments whose deletion loses data.
+var statefulTypes = []string{
+	"db_", "_database", "rds_", "sql_", "bucket", "storage_account", "disk", "volume",
+	"dynamodb", "elasticache", "redis", "efs_", "filestore", "kms_", "secret", "dns_zone", "route53_zone",
+}
+
+// sensitiveTypes fragments identify access-control and network-boundary
+// resources.
+var sensitiveTypes = []string{
+	"iam", "role", "policy", "security_group", "firewall", "network_acl", "kms_", "secret", "access",
+}
+
+// ReviewPlan checks a plan for destroyed data, access-control changes, and
+// public exposure. Any high-severity finding blocks the plan.
+func ReviewPlan(summary *PlanSummary) *Review {
+	review := &Review{Verdict: VerdictPass, Findings: []Finding{}}
+	add := func(severity, rule string, rc ResourceChange, format string, args ...interface{}) {
+		review.Findings = append(review.Findings, Finding{
+			Severity: severity,
+			Rule:     rule,
+			Address:  rc.Address,
+			Message:  fmt.Sprintf(format, args...),
+		})
+		if severity == SeverityHigh {
+			review.Verdict = VerdictBlock
+		} else if review.Verdict == VerdictPass {
+			review.Verdict = VerdictReview
+		}
+	}
+
+	for _, rc := range summary.Changes {
+		switch rc.Action {
+		case ActionDelete, ActionReplace:
+			verb := "destroys"
+			if rc.Action == ActionReplace {
+				verb = "replaces"
+			}
+			if typeContains(rc.Type, statefulTypes...) {
+				add(SeverityHigh, "destroy-stateful", rc, "%s %s, which may hold data", verb, rc.Type)
+			} else {
+				add(SeverityMedium, "destroy", rc, "%s %s", verb, rc.Type)
+			}
+		case ActionForget:
+			add(SeverityMedium, "forget", rc, "removes %s from state without destroying it", rc.Type)
+		}
+		if rc.Action == ActionRead || rc.Action == ActionDelete || rc.Action == ActionForget {
+			continue
+		}
+		if typeContains(rc.Type, sensitiveTypes...) {
+			add(SeverityMedium, "access-control", rc, "%s %s changes access control or a network boundary", rc.Action, rc.Type)
+		}
+		ingress := typeContains(rc.Type, "ingress", "firewall")
+		if attrs, ok := rc.after.(map[string]interface{}); ok && attrs["type"] == "ingress" {
+			ingress = true
+		}
+		walk(rc.after, "", func(path string, value interface{}) {
+			key := path[strings.LastIndex(path, ".")+1:]
+			switch v := value.(type) {
+			case string:
+				switch {
+				case (v == "0.0.0.0/0" || v == "::/0") && (ingress || strings.Contains(path, "ingress")):
+					add(SeverityHigh, "public-ingress", rc, "allows inbound traffic from %s at %s", v, path)
+				case key == "acl" && (v == "public-read" || v == "public-read-write"):
+					add(SeverityHigh, "public-access", rc, "sets %s to %s", path, v)
+				case v == "allUsers" || v == "allAuthenticatedUsers":
+					add(SeverityHigh, "public-access", rc, "grants access to %s at %s", v, path)
+				}
+			case bool:
+				switch {
+				case key == "publicly_accessible" && v:
+					add(SeverityHigh, "public-access", rc, "makes the resource publicly accessible")
+				case !v && (key == "block_public_acls" || key == "block_public_policy" || key == "ignore_public_acls" || key == "restrict_public_buckets"):
+					add(SeverityMedium, "public-access", rc, "disables %s", key)
+				case !v && (key == "storage_encrypted" || key == "encrypted"):
+					add(SeverityMedium, "encryption", rc, "disables encryption at %s", path)
+				}
+			}
+		})
+	}
+	return review
+}
+
+// walk calls fn for every scalar in value with its dotted path.
+func walk(value interface{}, path string, fn func(path string, value interface{})) {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for _, key := range slices.Sorted(maps.Keys(v)) {
+			p := key
+			if path != "" {
+				p = path + "." + key
+			}
+			walk(v[key], p, fn)
+		}
+	case []interface{}:
+		for i, child := range v {
+			walk(child, fmt.Sprintf("%s[%d]", path, i), fn)
+		}
+	default:
+		fn(path, v)
+	}
+}
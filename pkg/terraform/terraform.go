@@ -0,0 +1,199 @@
+// Package terraform runs terraform validate and plan in a project directory
+// and turns their JSON output into summaries that can be reviewed before
+// anything is applied. OpenTofu is used when terraform is not installed.
+package terraform
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+)
+
+// Runner runs the terraform binary in one directory.
+type Runner struct {
+	// Binary is the terraform or tofu executable.
+	Binary string
+	// Dir is the root module directory.
+	Dir string
+}
+
+// NewRunner finds terraform, or tofu, on PATH.
+func NewRunner(dir string) (*Runner, error) {
+	for _, name := range []string{"terraform", "tofu"} {
+		if path, err := exec.LookPath(name); err == nil {
+			return &Runner{Binary: path, Dir: dir}, nil
+		}
+	}
+	return nil, fmt.Errorf("terraform is not installed; install terraform or tofu")
+}
+
+// run executes the binary and returns its stdout and stderr.
+func (r *Runner) run(ctx context.Context, args ...string) ([]byte, []byte, error) {
+	cmd := exec.CommandContext(ctx, r.Binary, args...)
+	cmd.Dir = r.Dir
+	cmd.Env = append(os.Environ(), "TF_IN_AUTOMATION=1", "TF_INPUT=0")
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	err := cmd.Run()
+	return stdout.Bytes(), stderr.Bytes(), err
+}
+
+// Init runs terraform init when the directory has not been initialized.
+// Without backend, state backends are skipped, which is enough to validate.
+func (r *Runner) Init(ctx context.Context, backend bool) error {
+	if _, err := os.Stat(filepath.Join(r.Dir, ".terraform")); err == nil {
+		return nil
+	}
+	args := []string{"init", "-input=false", "-no-color"}
+	if !backend {
+		args = append(args, "-backend=false")
+	}
+	stdout, stderr, err := r.run(ctx, args...)
+	if err != nil {
+		return fmt.Errorf("terraform init failed: %w: %s", err, strings.TrimSpace(string(stderr)+string(stdout)))
+	}
+	return nil
+}
+
+// Diagnostic is an error or warning reported by terraform.
+type Diagnostic struct {
+	Severity string `json:"severity"`
+	Summary  string `json:"summary"`
+	Detail   string `json:"detail,omitempty"`
+	File     string `json:"file,omitempty"`
+	Line     int    `json:"line,omitempty"`
+}
+
+// ValidateResult is the outcome of terraform validate.
+type ValidateResult struct {
+	Valid        bool         `json:"valid"`
+	ErrorCount   int          `json:"error_count"`
+	WarningCount int          `json:"warning_count"`
+	Diagnostics  []Diagnostic `json:"diagnostics"`
+	DurationMS   int64        `json:"duration_ms"`
+}
+
+type jsonDiagnostic struct {
+	Severity string `json:"severity"`
+	Summary  string `json:"summary"`
+	Detail   string `json:"detail"`
+	Range    *struct {
+		Filename string `json:"filename"`
+		Start    struct {
+			Line int `json:"line"`
+		} `json:"start"`
+	} `json:"range"`
+}
+
+func (d jsonDiagnostic) diagnostic() Diagnostic {
+	out := Diagnostic{Severity: d.Severity, Summary: d.Summary, Detail: d.Detail}
+	if d.Range != nil {
+		out.File, out.Line = d.Range.Filename, d.Range.Start.Line
+	}
+	return out
+}
+
+// Validate initializes the directory without a backend if needed and runs
+// terraform validate.
+func (r *Runner) Validate(ctx context.Context) (*ValidateResult, error) {
+	start := time.Now()
+	if err := r.Init(ctx, false); err != nil {
+		return nil, err
+	}
+	// validate exits non-zero for invalid configuration but still prints
+	// its JSON report.
+	stdout, stderr, err := r.run(ctx, "validate", "-json", "-no-color")
+	result, parseErr := ParseValidate(stdout)
+	if parseErr != nil {
+		if err != nil {
+			return nil, fmt.Errorf("terraform validate failed: %w: %s", err, strings.TrimSpace(string(stderr)))
+		}
+		return nil, parseErr
+	}
+	result.DurationMS = time.Since(start).Milliseconds()
+	return result, nil
+}
+
+// ParseValidate decodes the output of terraform validate -json.
+func ParseValidate(data []byte) (*ValidateResult, error) {
+	var raw struct {
+		Valid        bool             `json:"valid"`
+		ErrorCount   int              `json:"error_count"`
+		WarningCount int              `json:"warning_count"`
+		Diagnostics  []jsonDiagnostic `json:"diagnostics"`
+	}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, fmt.Errorf("failed to parse terraform validate output: %w", err)
+	}
+	result := &ValidateResult{
+		Valid:        raw.Valid,
+		ErrorCount:   raw.ErrorCount,
+		WarningCount: raw.WarningCount,
+		Diagnostics:  make([]Diagnostic, 0, len(raw.Diagnostics)),
+	}
+	for _, d := range raw.Diagnostics {
+		result.Diagnostics = append(result.Diagnostics, d.diagnostic())
+	}
+	return result, nil
+}
+
+// PlanOptions configures Plan.
+type PlanOptions struct {
+	// VarFiles are passed as -var-file, relative to the directory.
+	VarFiles []string
+	// Vars are passed as -var name=value.
+	Vars map[string]string
+	// Destroy plans the destruction of all managed resources.
+	Destroy bool
+}
+
+// Plan initializes the directory if needed, writes a plan to a temporary
+// file, and summarizes terraform show -json for it. State is not locked and
+// nothing is applied.
+func (r *Runner) Plan(ctx context.Context, opts PlanOptions) (*PlanSummary, error) {
+	start := time.Now()
+	if err := r.Init(ctx, true); err != nil {
+		return nil, err
+	}
+	tmp, err := os.MkdirTemp("", "juleson-tfplan-")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(tmp)
+	planFile := filepath.Join(tmp, "plan.tfplan")
+
+	args := []string{"plan", "-input=false", "-no-color", "-lock=false", "-out=" + planFile}
+	if opts.Destroy {
+		args = append(args, "-destroy")
+	}
+	for _, f := range opts.VarFiles {
+		args = append(args, "-var-file="+f)
+	}
+	for _, name := range slices.Sorted(maps.Keys(opts.Vars)) {
+		args = append(args, "-var", name+"="+opts.Vars[name])
+	}
+	stdout, stderr, err := r.run(ctx, args...)
+	if err != nil {
+		return nil, fmt.Errorf("terraform plan failed: %w: %s", err, strings.TrimSpace(string(stderr)+string(stdout)))
+	}
+
+	stdout, stderr, err = r.run(ctx, "show", "-json", "-no-color", planFile)
+	if err != nil {
+		return nil, fmt.Errorf("terraform show failed: %w: %s", err, strings.TrimSpace(string(stderr)))
+	}
+	summary, err := ParsePlan(stdout)
+	if err != nil {
+		return nil, err
+	}
+	summary.DurationMS = time.Since(start).Milliseconds()
+	return summary, nil
+}
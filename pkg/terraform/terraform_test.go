@@ -0,0 +1,118 @@
+package terraform
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+)
+
+const testPlan = `{
+  "format_version": "1.2",
+  "terraform_version": "1.9.5",
+  "resource_changes": [
+    {"address": "aws_instance.web", "mode": "managed", "type": "aws_instance", "provider_name": "registry.terraform.io/hashicorp/aws",
+     "change": {"actions": ["no-op"], "after": {}}},
+    {"address": "aws_s3_bucket.logs", "mode": "managed", "type": "aws_s3_bucket", "provider_name": "registry.terraform.io/hashicorp/aws",
+     "change": {"actions": ["create"], "after": {"bucket": "logs", "acl": "public-read"}}},
+    {"address": "aws_security_group.web", "mode": "managed", "type": "aws_security_group", "provider_name": "registry.terraform.io/hashicorp/aws",
+     "change": {"actions": ["update"], "after": {"ingress": [{"cidr_blocks": ["0.0.0.0/0"], "from_port": 22}], "egress": [{"cidr_blocks": ["0.0.0.0/0"]}]}}},
+    {"address": "aws_db_instance.main", "mode": "managed", "type": "aws_db_instance", "provider_name": "registry.terraform.io/hashicorp/aws",
+     "action_reason": "replace_because_cannot_update",
+     "change": {"actions": ["delete", "create"], "after": {"publicly_accessible": false}}},
+    {"address": "null_resource.old", "mode": "managed", "type": "null_resource", "provider_name": "registry.terraform.io/hashicorp/null",
+     "change": {"actions": ["delete"], "after": null}}
+  ],
+  "output_changes": {"url": {"actions": ["update"]}, "id": {"actions": ["no-op"]}},
+  "resource_drift": [{"address": "aws_instance.web"}]
+}`
+
+func TestParsePlan(t *testing.T) {
+	summary, err := ParsePlan([]byte(testPlan))
+	if err != nil {
+		t.Fatalf("ParsePlan() error = %v", err)
+	}
+	want := ChangeCounts{Create: 1, Update: 1, Delete: 1, Replace: 1}
+	if summary.Counts != want {
+		t.Errorf("Counts = %+v, want %+v", summary.Counts, want)
+	}
+	if len(summary.Changes) != 4 || summary.Changes[2].Action != ActionReplace || summary.Changes[2].Reason != "replace_because_cannot_update" {
+		t.Errorf("Changes = %+v", summary.Changes)
+	}
+	if len(summary.Outputs) != 1 || summary.Outputs["url"] != ActionUpdate || summary.Drift != 1 {
+		t.Errorf("Outputs = %v, Drift = %d", summary.Outputs, summary.Drift)
+	}
+	if got := summary.String(); got != "Plan: 2 to add, 1 to change, 2 to destroy (1 replaced)." {
+		t.Errorf("String() = %q", got)
+	}
+
+	if _, err := ParsePlan([]byte("not json")); err == nil {
+		t.Error("ParsePlan() should fail on invalid JSON")
+	}
+}
+
+func TestReviewPlan(t *testing.T) {
+	summary, err := ParsePlan([]byte(testPlan))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	review := ReviewPlan(summary)
+	if review.Verdict != VerdictBlock {
+		t.Errorf("Verdict = %q, want block", review.Verdict)
+	}
+	rules := map[string]string{}
+	for _, f := range review.Findings {
+		rules[f.Address+" "+f.Rule] = f.Severity
+	}
+	want := map[string]string{
+		"aws_s3_bucket.logs public-access":      SeverityHigh,
+		"aws_security_group.web access-control": SeverityMedium,
+		"aws_security_group.web public-ingress": SeverityHigh,
+		"aws_db_instance.main destroy-stateful": SeverityHigh,
+		"null_resource.old destroy":             SeverityMedium,
+	}
+	if len(rules) != len(want) {
+		t.Errorf("findings = %+v", review.Findings)
+	}
+	for key, severity := range want {
+		if rules[key] != severity {
+			t.Errorf("finding %q = %q, want %q", key, rules[key], severity)
+		}
+	}
+
+	clean := &PlanSummary{Changes: []ResourceChange{{Address: "null_resource.a", Type: "null_resource", Action: ActionCreate}}}
+	if got := ReviewPlan(clean); got.Verdict != VerdictPass || len(got.Findings) != 0 {
+		t.Errorf("ReviewPlan(clean) = %+v", got)
+	}
+}
+
+func TestValidateWithFakeBinary(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses a shell script")
+	}
+	dir := t.TempDir()
+	if err := os.Mkdir(filepath.Join(dir, ".terraform"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	bin := filepath.Join(t.TempDir(), "terraform")
+	script := `#!/bin/sh
+echo '{"valid":false,"error_count":1,"warning_count":0,"diagnostics":[{"severity":"error","summary":"Unsupported argument","range":{"filename":"main.tf","start":{"line":3}}}]}'
+exit 1
+`
+	if err := os.WriteFile(bin, []byte(script), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	result, err := (&Runner{Binary: bin, Dir: dir}).Validate(context.Background())
+	if err != nil {
+		t.Fatalf("Validate() error = %v", err)
+	}
+	if result.Valid || result.ErrorCount != 1 || len(result.Diagnostics) != 1 {
+		t.Fatalf("result = %+v", result)
+	}
+	if d := result.Diagnostics[0]; d.File != "main.tf" || d.Line != 3 || d.Severity != "error" {
+		t.Errorf("diagnostic = %+v", d)
+	}
+}
@@ -5,15 +5,21 @@ import (
 	"bytes"
 	"compress/gzip"
 	"fmt"
+	"go/parser"
+	"go/token"
 	"io"
+	"io/fs"
 	"net/http"
 	"net/http/httptest"
 	"os"
 	"os/exec"
 	"path/filepath"
 	"runtime"
+	"slices"
 	"strings"
 	"testing"
+
+	"golang.org/x/mod/modfile"
 )
 
 func TestInstallShellHelpAndSyntax(t *testing.T) {
@@ -147,8 +153,10 @@ func TestInstallationDocsReferenceInstallableAssets(t *testing.T) {
 			t.Fatal(err)
 		}
 		text := string(contents)
-		if strings.Contains(text, "cmd/juleson-mcp") {
-			t.Fatalf("%s still references removed cmd/juleson-mcp path", file)
+		for _, removed := range []string{"cmd/juleson-mcp", "cmd/jules-cli"} {
+			if strings.Contains(text, removed) {
+				t.Fatalf("%s still references removed %s path", file, removed)
+			}
 		}
 		if strings.Contains(text, "Go 1.23+") || strings.Contains(text, "Go 1.24+") || strings.Contains(text, "Go 1.23 or higher") || strings.Contains(text, "Go 1.24 or higher") {
 			t.Fatalf("%s still references an outdated Go prerequisite", file)
@@ -156,6 +164,63 @@ func TestInstallationDocsReferenceInstallableAssets(t *testing.T) {
 	}
 }
 
+// TestModuleIsGoInstallable guards "go install
+// github.com/SamyRai/juleson/cmd/juleson@latest": it fails for modules with
+// replace or exclude directives, and cmd/juleson is the only user-facing
+// entrypoint; cmd/builder and cmd/juleson-e2e are development tools.
+func TestModuleIsGoInstallable(t *testing.T) {
+	root := ".."
+	data, err := os.ReadFile(filepath.Join(root, "go.mod"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	mod, err := modfile.ParseLax("go.mod", data, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := mod.Module.Mod.Path; got != "github.com/SamyRai/juleson" {
+		t.Fatalf("module path = %q, want github.com/SamyRai/juleson", got)
+	}
+	if len(mod.Replace) > 0 || len(mod.Exclude) > 0 {
+		t.Fatal("go.mod has replace or exclude directives, which break go install @latest")
+	}
+
+	entries, err := os.ReadDir(filepath.Join(root, "cmd"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, entry := range entries {
+		if entry.IsDir() && !slices.Contains([]string{"juleson", "builder", "juleson-e2e"}, entry.Name()) {
+			t.Errorf("unexpected entrypoint cmd/%s; add commands to cmd/juleson instead", entry.Name())
+		}
+	}
+
+	err = filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() && (d.Name() == ".git" || d.Name() == "vendor") {
+			return filepath.SkipDir
+		}
+		if d.IsDir() || !strings.HasSuffix(path, ".go") {
+			return nil
+		}
+		file, err := parser.ParseFile(token.NewFileSet(), path, nil, parser.ImportsOnly)
+		if err != nil {
+			return err
+		}
+		for _, spec := range file.Imports {
+			if strings.HasPrefix(strings.Trim(spec.Path.Value, `"`), "jules-automation") {
+				t.Errorf("%s imports the stale jules-automation module path", path)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
 func installerPlatform() (goos, goarch string, ok bool) {
 	switch runtime.GOOS {
 	case "linux", "darwin":